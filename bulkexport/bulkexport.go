@@ -0,0 +1,206 @@
+// Package bulkexport writes daily partitions of the core tables as parquet files to a gcs or s3 bucket, together with a
+// manifest per day, so that the data can be loaded into BigQuery or other tools without access to the database.
+//
+// The files of a day are written to <table>/day=<day>/<table>.parquet (hive partitioning), the manifest of a day to
+// manifests/day=<day>.json and manifests/latest.json points to the last exported day.
+package bulkexport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/logging"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+var logger = logging.NewLogger("bulkexport")
+
+// Manifest lists the files of an exported day
+type Manifest struct {
+	Network     string          `json:"network"`
+	Day         uint64          `json:"day"`
+	FirstSlot   uint64          `json:"first_slot"`
+	LastSlot    uint64          `json:"last_slot"`
+	GeneratedTs time.Time       `json:"generated_ts"`
+	Files       []*ManifestFile `json:"files"`
+}
+
+// ManifestFile is a parquet file of a manifest
+type ManifestFile struct {
+	Table  string `json:"table"`
+	Path   string `json:"path"`
+	Rows   int64  `json:"rows"`
+	Bytes  int    `json:"bytes"`
+	Sha256 string `json:"sha256"`
+}
+
+// Exporter exports the configured tables to a store
+type Exporter struct {
+	store  Store
+	tables []*table
+}
+
+// NewExporter creates an exporter for the destination and tables of cfg.BulkExport, all tables are exported if no
+// tables are configured
+func NewExporter(ctx context.Context, cfg *types.Config) (*Exporter, error) {
+	if cfg.BulkExport.Destination == "" {
+		return nil, fmt.Errorf("no bulk export destination configured")
+	}
+	store, err := NewStore(ctx, cfg.BulkExport.Destination, cfg.BulkExport.S3Region)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exporter{store: store}
+	if len(cfg.BulkExport.Tables) == 0 {
+		e.tables = tables
+		return e, nil
+	}
+	for _, name := range cfg.BulkExport.Tables {
+		found := false
+		for _, t := range tables {
+			if t.name == name {
+				e.tables = append(e.tables, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown bulk export table %v", name)
+		}
+	}
+	return e, nil
+}
+
+// LastExportedDay returns the last day that has been exported, false is returned if no day has been exported yet
+func LastExportedDay() (uint64, bool, error) {
+	var day *uint64
+	err := db.DB.Get(&day, "SELECT MAX(day) FROM bulk_export_status")
+	if err != nil || day == nil {
+		return 0, false, err
+	}
+	return *day, true, nil
+}
+
+// ExportDay writes the files and the manifest of the day, exporting a day again overwrites its files
+func (e *Exporter) ExportDay(ctx context.Context, day uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("bulk_export_day").Observe(time.Since(start).Seconds())
+	}()
+
+	firstSlot, nextSlot := daySlots(day)
+	manifest := &Manifest{
+		Network:     utils.Config.Chain.Network,
+		Day:         day,
+		FirstSlot:   firstSlot,
+		LastSlot:    nextSlot - 1,
+		GeneratedTs: time.Now().UTC(),
+		Files:       make([]*ManifestFile, 0, len(e.tables)),
+	}
+
+	for _, t := range e.tables {
+		tableStart := time.Now()
+		data, rows, err := exportTable(ctx, t, day)
+		if err != nil {
+			return fmt.Errorf("error exporting table %v of day %v: %w", t.name, day, err)
+		}
+
+		name := fmt.Sprintf("%v/day=%v/%v.parquet", t.name, day, t.name)
+		err = e.store.Put(ctx, name, "application/vnd.apache.parquet", data)
+		if err != nil {
+			return fmt.Errorf("error uploading %v: %w", name, err)
+		}
+		checksum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, &ManifestFile{
+			Table:  t.name,
+			Path:   name,
+			Rows:   rows,
+			Bytes:  len(data),
+			Sha256: hex.EncodeToString(checksum[:]),
+		})
+		logger.Infof("exported %v rows of table %v of day %v, took %v", rows, t.name, day, time.Since(tableStart))
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = e.store.Put(ctx, fmt.Sprintf("manifests/day=%v.json", day), "application/json", manifestData)
+	if err != nil {
+		return fmt.Errorf("error uploading manifest of day %v: %w", day, err)
+	}
+
+	_, err = db.DB.Exec(`
+		INSERT INTO bulk_export_status (day, exported_ts) VALUES ($1, NOW())
+		ON CONFLICT (day) DO UPDATE SET exported_ts = excluded.exported_ts`, day)
+	if err != nil {
+		return err
+	}
+
+	// latest.json is only moved forward, re-exporting an older day must not hide the newer days
+	lastDay, _, err := LastExportedDay()
+	if err != nil {
+		return err
+	}
+	if day >= lastDay {
+		err = e.store.Put(ctx, "manifests/latest.json", "application/json", manifestData)
+		if err != nil {
+			return fmt.Errorf("error uploading latest manifest: %w", err)
+		}
+	}
+
+	logger.Infof("exported day %v, took %v", day, time.Since(start))
+	return nil
+}
+
+// exportTable streams the rows of the day into a snappy compressed parquet file
+func exportTable(ctx context.Context, t *table, day uint64) ([]byte, int64, error) {
+	buf := &bytes.Buffer{}
+	pw, err := writer.NewParquetWriterFromWriter(buf, t.newRow(), 4)
+	if err != nil {
+		return nil, 0, err
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	query, args := t.query(day)
+	rows, err := db.DB.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		row := t.newRow()
+		err = rows.StructScan(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		err = pw.Write(reflect.ValueOf(row).Elem().Interface())
+		if err != nil {
+			return nil, 0, err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	err = pw.WriteStop()
+	if err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), count, nil
+}
@@ -0,0 +1,79 @@
+package bulkexport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// Store writes the exported files to an object storage bucket, the credentials are taken from the environment
+// (GOOGLE_APPLICATION_CREDENTIALS for gcs, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or the instance role for s3)
+type Store interface {
+	Put(ctx context.Context, name, contentType string, data []byte) error
+}
+
+// NewStore returns the store of a destination url of the form gs://bucket/prefix or s3://bucket/prefix
+func NewStore(ctx context.Context, destination, s3Region string) (Store, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination %v: %v", destination, err)
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "gs":
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &gcsStore{bucket: client.Bucket(u.Host), prefix: prefix}, nil
+	case "s3":
+		sess, err := session.NewSession(&aws.Config{Region: aws.String(s3Region)})
+		if err != nil {
+			return nil, err
+		}
+		return &s3Store{uploader: s3manager.NewUploader(sess), bucket: u.Host, prefix: prefix}, nil
+	default:
+		return nil, fmt.Errorf("unsupported destination %v, expected gs://bucket/prefix or s3://bucket/prefix", destination)
+	}
+}
+
+type gcsStore struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+func (s *gcsStore) Put(ctx context.Context, name, contentType string, data []byte) error {
+	w := s.bucket.Object(path.Join(s.prefix, name)).NewWriter(ctx)
+	w.ContentType = contentType
+	_, err := w.Write(data)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+type s3Store struct {
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func (s *s3Store) Put(ctx context.Context, name, contentType string, data []byte) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(path.Join(s.prefix, name)),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(data),
+	})
+	return err
+}
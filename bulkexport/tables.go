@@ -0,0 +1,168 @@
+package bulkexport
+
+import (
+	"eth2-exporter/utils"
+)
+
+// table is a table that is exported as one parquet file per day, query selects the rows of a day into values of the
+// type returned by newRow, whose parquet tags define the schema of the file
+type table struct {
+	name   string
+	newRow func() interface{}
+	query  func(day uint64) (string, []interface{})
+}
+
+// daySlots returns the first slot of the day and the first slot of the next day
+func daySlots(day uint64) (uint64, uint64) {
+	slotsPerDay := utils.EpochsPerDay() * utils.Config.Chain.SlotsPerEpoch
+	return day * slotsPerDay, (day + 1) * slotsPerDay
+}
+
+var tables = []*table{
+	{
+		name:   "blocks",
+		newRow: func() interface{} { return new(blockRow) },
+		query: func(day uint64) (string, []interface{}) {
+			firstSlot, nextSlot := daySlots(day)
+			return `
+				SELECT
+					epoch, slot,
+					'0x' || encode(blockroot, 'hex') AS blockroot,
+					'0x' || encode(parentroot, 'hex') AS parentroot,
+					'0x' || encode(stateroot, 'hex') AS stateroot,
+					proposer, status,
+					COALESCE(graffiti_text, '') AS graffiti_text,
+					attestationscount, depositscount, voluntaryexitscount, proposerslashingscount, attesterslashingscount,
+					syncaggregate_participation,
+					exec_block_number,
+					'0x' || encode(exec_block_hash, 'hex') AS exec_block_hash,
+					'0x' || encode(exec_fee_recipient, 'hex') AS exec_fee_recipient,
+					exec_gas_used, exec_gas_limit, exec_base_fee_per_gas, exec_transactions_count
+				FROM blocks
+				WHERE slot >= $1 AND slot < $2
+				ORDER BY slot`, []interface{}{firstSlot, nextSlot}
+		},
+	},
+	{
+		name:   "attestation_aggregates",
+		newRow: func() interface{} { return new(attestationAggregateRow) },
+		query: func(day uint64) (string, []interface{}) {
+			firstSlot, nextSlot := daySlots(day)
+			return `
+				SELECT
+					block_slot,
+					'0x' || encode(block_root, 'hex') AS block_root,
+					COUNT(*) AS aggregates,
+					SUM(cardinality(validators)) AS included_votes,
+					MIN(block_slot - slot) AS min_inclusion_distance,
+					AVG(block_slot - slot) AS avg_inclusion_distance
+				FROM blocks_attestations
+				WHERE block_slot >= $1 AND block_slot < $2
+				GROUP BY block_slot, block_root
+				ORDER BY block_slot`, []interface{}{firstSlot, nextSlot}
+		},
+	},
+	{
+		name:   "validator_stats",
+		newRow: func() interface{} { return new(validatorStatsRow) },
+		query: func(day uint64) (string, []interface{}) {
+			return `
+				SELECT
+					validatorindex, day,
+					start_balance, end_balance, min_balance, max_balance,
+					start_effective_balance, end_effective_balance, min_effective_balance, max_effective_balance,
+					missed_attestations, orphaned_attestations,
+					participated_sync, missed_sync, orphaned_sync,
+					proposed_blocks, missed_blocks, orphaned_blocks,
+					attester_slashings, proposer_slashings,
+					deposits, deposits_amount
+				FROM validator_stats
+				WHERE day = $1
+				ORDER BY validatorindex`, []interface{}{day}
+		},
+	},
+	{
+		// the minipools are not historized, the partition of a day holds the state at the time of the export
+		name:   "rocketpool_minipools",
+		newRow: func() interface{} { return new(rocketpoolMinipoolRow) },
+		query: func(day uint64) (string, []interface{}) {
+			return `
+				SELECT
+					'0x' || encode(address, 'hex') AS address,
+					'0x' || encode(pubkey, 'hex') AS pubkey,
+					'0x' || encode(node_address, 'hex') AS node_address,
+					node_fee, deposit_type, status,
+					(EXTRACT(EPOCH FROM status_time) * 1000)::bigint AS status_time
+				FROM rocketpool_minipools
+				ORDER BY address`, nil
+		},
+	},
+}
+
+type blockRow struct {
+	Epoch                      int64   `db:"epoch" parquet:"name=epoch, type=INT64"`
+	Slot                       int64   `db:"slot" parquet:"name=slot, type=INT64"`
+	BlockRoot                  string  `db:"blockroot" parquet:"name=blockroot, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ParentRoot                 string  `db:"parentroot" parquet:"name=parentroot, type=BYTE_ARRAY, convertedtype=UTF8"`
+	StateRoot                  string  `db:"stateroot" parquet:"name=stateroot, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Proposer                   int64   `db:"proposer" parquet:"name=proposer, type=INT64"`
+	Status                     string  `db:"status" parquet:"name=status, type=BYTE_ARRAY, convertedtype=UTF8"`
+	GraffitiText               string  `db:"graffiti_text" parquet:"name=graffiti_text, type=BYTE_ARRAY, convertedtype=UTF8"`
+	AttestationsCount          int32   `db:"attestationscount" parquet:"name=attestationscount, type=INT32"`
+	DepositsCount              int32   `db:"depositscount" parquet:"name=depositscount, type=INT32"`
+	VoluntaryExitsCount        int32   `db:"voluntaryexitscount" parquet:"name=voluntaryexitscount, type=INT32"`
+	ProposerSlashingsCount     int32   `db:"proposerslashingscount" parquet:"name=proposerslashingscount, type=INT32"`
+	AttesterSlashingsCount     int32   `db:"attesterslashingscount" parquet:"name=attesterslashingscount, type=INT32"`
+	SyncAggregateParticipation float64 `db:"syncaggregate_participation" parquet:"name=syncaggregate_participation, type=DOUBLE"`
+	ExecBlockNumber            *int64  `db:"exec_block_number" parquet:"name=exec_block_number, type=INT64, repetitiontype=OPTIONAL"`
+	ExecBlockHash              *string `db:"exec_block_hash" parquet:"name=exec_block_hash, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	ExecFeeRecipient           *string `db:"exec_fee_recipient" parquet:"name=exec_fee_recipient, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	ExecGasUsed                *int64  `db:"exec_gas_used" parquet:"name=exec_gas_used, type=INT64, repetitiontype=OPTIONAL"`
+	ExecGasLimit               *int64  `db:"exec_gas_limit" parquet:"name=exec_gas_limit, type=INT64, repetitiontype=OPTIONAL"`
+	ExecBaseFeePerGas          *int64  `db:"exec_base_fee_per_gas" parquet:"name=exec_base_fee_per_gas, type=INT64, repetitiontype=OPTIONAL"`
+	ExecTransactionsCount      int32   `db:"exec_transactions_count" parquet:"name=exec_transactions_count, type=INT32"`
+}
+
+type attestationAggregateRow struct {
+	BlockSlot            int64   `db:"block_slot" parquet:"name=block_slot, type=INT64"`
+	BlockRoot            string  `db:"block_root" parquet:"name=block_root, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Aggregates           int64   `db:"aggregates" parquet:"name=aggregates, type=INT64"`
+	IncludedVotes        int64   `db:"included_votes" parquet:"name=included_votes, type=INT64"`
+	MinInclusionDistance int64   `db:"min_inclusion_distance" parquet:"name=min_inclusion_distance, type=INT64"`
+	AvgInclusionDistance float64 `db:"avg_inclusion_distance" parquet:"name=avg_inclusion_distance, type=DOUBLE"`
+}
+
+type validatorStatsRow struct {
+	Validatorindex        int64  `db:"validatorindex" parquet:"name=validatorindex, type=INT64"`
+	Day                   int64  `db:"day" parquet:"name=day, type=INT64"`
+	StartBalance          *int64 `db:"start_balance" parquet:"name=start_balance, type=INT64, repetitiontype=OPTIONAL"`
+	EndBalance            *int64 `db:"end_balance" parquet:"name=end_balance, type=INT64, repetitiontype=OPTIONAL"`
+	MinBalance            *int64 `db:"min_balance" parquet:"name=min_balance, type=INT64, repetitiontype=OPTIONAL"`
+	MaxBalance            *int64 `db:"max_balance" parquet:"name=max_balance, type=INT64, repetitiontype=OPTIONAL"`
+	StartEffectiveBalance *int64 `db:"start_effective_balance" parquet:"name=start_effective_balance, type=INT64, repetitiontype=OPTIONAL"`
+	EndEffectiveBalance   *int64 `db:"end_effective_balance" parquet:"name=end_effective_balance, type=INT64, repetitiontype=OPTIONAL"`
+	MinEffectiveBalance   *int64 `db:"min_effective_balance" parquet:"name=min_effective_balance, type=INT64, repetitiontype=OPTIONAL"`
+	MaxEffectiveBalance   *int64 `db:"max_effective_balance" parquet:"name=max_effective_balance, type=INT64, repetitiontype=OPTIONAL"`
+	MissedAttestations    *int32 `db:"missed_attestations" parquet:"name=missed_attestations, type=INT32, repetitiontype=OPTIONAL"`
+	OrphanedAttestations  *int32 `db:"orphaned_attestations" parquet:"name=orphaned_attestations, type=INT32, repetitiontype=OPTIONAL"`
+	ParticipatedSync      *int32 `db:"participated_sync" parquet:"name=participated_sync, type=INT32, repetitiontype=OPTIONAL"`
+	MissedSync            *int32 `db:"missed_sync" parquet:"name=missed_sync, type=INT32, repetitiontype=OPTIONAL"`
+	OrphanedSync          *int32 `db:"orphaned_sync" parquet:"name=orphaned_sync, type=INT32, repetitiontype=OPTIONAL"`
+	ProposedBlocks        *int32 `db:"proposed_blocks" parquet:"name=proposed_blocks, type=INT32, repetitiontype=OPTIONAL"`
+	MissedBlocks          *int32 `db:"missed_blocks" parquet:"name=missed_blocks, type=INT32, repetitiontype=OPTIONAL"`
+	OrphanedBlocks        *int32 `db:"orphaned_blocks" parquet:"name=orphaned_blocks, type=INT32, repetitiontype=OPTIONAL"`
+	AttesterSlashings     *int32 `db:"attester_slashings" parquet:"name=attester_slashings, type=INT32, repetitiontype=OPTIONAL"`
+	ProposerSlashings     *int32 `db:"proposer_slashings" parquet:"name=proposer_slashings, type=INT32, repetitiontype=OPTIONAL"`
+	Deposits              *int32 `db:"deposits" parquet:"name=deposits, type=INT32, repetitiontype=OPTIONAL"`
+	DepositsAmount        *int64 `db:"deposits_amount" parquet:"name=deposits_amount, type=INT64, repetitiontype=OPTIONAL"`
+}
+
+type rocketpoolMinipoolRow struct {
+	Address     string  `db:"address" parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Pubkey      string  `db:"pubkey" parquet:"name=pubkey, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NodeAddress string  `db:"node_address" parquet:"name=node_address, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NodeFee     float64 `db:"node_fee" parquet:"name=node_fee, type=DOUBLE"`
+	DepositType string  `db:"deposit_type" parquet:"name=deposit_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Status      string  `db:"status" parquet:"name=status, type=BYTE_ARRAY, convertedtype=UTF8"`
+	StatusTime  *int64  `db:"status_time" parquet:"name=status_time, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL"`
+}
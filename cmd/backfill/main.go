@@ -0,0 +1,99 @@
+package main
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/exporter"
+	"eth2-exporter/logging"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"eth2-exporter/version"
+	"flag"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	configPath := flag.String("config", "", "Path to the config file")
+	startSlot := flag.Uint64("slots.start", 0, "First slot of the range to backfill")
+	endSlot := flag.Uint64("slots.end", 0, "Last slot of the range to backfill")
+	concurrency := flag.Int("concurrency", 4, "Maximum number of epochs that are exported in parallel")
+	force := flag.Bool("force", false, "Re-export epochs even if they have already been backfilled or match the node")
+	timescale := flag.Bool("timescale", false, "Mirror the already exported balances and network stats of the slot range into the timescale hypertables and refresh the continuous aggregates instead of exporting epochs")
+
+	flag.Parse()
+
+	logrus.Printf("version: %v, config file path: %v", version.Version, *configPath)
+	cfg := &types.Config{}
+	err := utils.ReadConfig(cfg, *configPath)
+
+	if err != nil {
+		logrus.Fatalf("error reading config file: %v", err)
+	}
+	utils.Config = cfg
+
+	err = logging.Init(cfg.Logging.Format, cfg.Logging.Level, cfg.Logging.SlowRequestThresholdMs)
+	if err != nil {
+		logrus.Fatalf("error initializing logging: %v", err)
+	}
+
+	db.MustInitDB(cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
+	defer db.DB.Close()
+
+	if *timescale {
+		if *endSlot < *startSlot {
+			logrus.Fatalf("invalid slot range %v-%v", *startSlot, *endSlot)
+		}
+		err = db.BackfillTimescale(utils.EpochOfSlot(*startSlot), utils.EpochOfSlot(*endSlot))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+
+	var rpcClient rpc.Client
+	if utils.Config.Indexer.Node.Type == "prysm" && utils.Config.Indexer.Node.PageSize == 0 {
+		logrus.Printf("setting default rpc page size to 500")
+		utils.Config.Indexer.Node.PageSize = 500
+	}
+
+	nodeEndpoints := append([]string{cfg.Indexer.Node.Host + ":" + cfg.Indexer.Node.Port}, cfg.Indexer.Node.Fallbacks...)
+	nodeClients := make([]rpc.Client, 0, len(nodeEndpoints))
+	for _, endpoint := range nodeEndpoints {
+		if utils.Config.Indexer.Node.Type == "prysm" {
+			client, err := rpc.NewPrysmClient(endpoint)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			nodeClients = append(nodeClients, client)
+		} else if utils.Config.Indexer.Node.Type == "lighthouse" {
+			client, err := rpc.NewLighthouseClient("http://" + endpoint)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			nodeClients = append(nodeClients, client)
+		} else {
+			logrus.Fatalf("invalid note type %v specified. supported node types are prysm and lighthouse", utils.Config.Indexer.Node.Type)
+		}
+	}
+
+	if len(nodeClients) == 1 {
+		rpcClient = nodeClients[0]
+	} else {
+		rpcClient, err = rpc.NewFailoverClient(nodeClients, nodeEndpoints)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	err = exporter.Backfill(rpcClient, exporter.BackfillOptions{
+		StartSlot:   *startSlot,
+		EndSlot:     *endSlot,
+		Concurrency: *concurrency,
+		Force:       *force,
+	})
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
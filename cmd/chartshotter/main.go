@@ -5,6 +5,7 @@ package main
 import (
 	"context"
 	"eth2-exporter/db"
+	"eth2-exporter/logging"
 	"eth2-exporter/services"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -29,6 +30,11 @@ func main() {
 	}
 	utils.Config = cfg
 
+	err = logging.Init(cfg.Logging.Format, cfg.Logging.Level, cfg.Logging.SlowRequestThresholdMs)
+	if err != nil {
+		logrus.Fatalf("error initializing logging: %v", err)
+	}
+
 	db.MustInitDB(cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
 	defer db.DB.Close()
 
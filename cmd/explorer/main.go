@@ -1,21 +1,31 @@
 package main
 
 import (
+	"context"
 	"encoding/hex"
 	"eth2-exporter/db"
 	ethclients "eth2-exporter/ethClients"
+	"eth2-exporter/events"
 	"eth2-exporter/exporter"
+	"eth2-exporter/featureflags"
 	"eth2-exporter/handlers"
+	"eth2-exporter/httpcache"
+	"eth2-exporter/logging"
 	"eth2-exporter/metrics"
+	"eth2-exporter/preflight"
 	"eth2-exporter/price"
+	"eth2-exporter/publisher"
 	"eth2-exporter/rpc"
 	"eth2-exporter/services"
+	"eth2-exporter/tracing"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"eth2-exporter/version"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -45,6 +55,11 @@ func initStripe(http *mux.Router) error {
 
 func main() {
 	configPath := flag.String("config", "config/default.config.yml", "Path to the config file")
+	preflightEnabled := flag.Bool("preflight", true, "Check the databases, nodes and services of the config before starting")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [flags] [check | rocketpool [-dry-run]]\n\nThe check command validates the config and exits.\nThe rocketpool command runs a single update of the rocketpool exporter and exits, with -dry-run the rows are printed as json instead of written to the database.\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	logrus.WithField("config", *configPath).WithField("version", version.Version).Printf("starting")
@@ -55,12 +70,86 @@ func main() {
 	}
 	utils.Config = cfg
 
+	// the incident thresholds are used by the exporter and shown by the frontend
+	if utils.Config.Indexer.NetworkIncidents.ParticipationThreshold == 0 {
+		utils.Config.Indexer.NetworkIncidents.ParticipationThreshold = 0.66
+	}
+	if utils.Config.Indexer.NetworkIncidents.FinalityDelayThreshold == 0 {
+		utils.Config.Indexer.NetworkIncidents.FinalityDelayThreshold = 4
+	}
+
+	err = logging.Init(cfg.Logging.Format, cfg.Logging.Level, cfg.Logging.SlowRequestThresholdMs)
+	if err != nil {
+		logrus.Fatalf("error initializing logging: %v", err)
+	}
+
+	if flag.Arg(0) == "check" {
+		report := preflight.Run(cfg)
+		report.Print(os.Stdout)
+		if report.Failed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	err = utils.ValidateChainConfig(cfg)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if flag.Arg(0) == "rocketpool" {
+		runRocketpoolExporterOnce(cfg, flag.Args()[1:])
+		return
+	}
+	if *preflightEnabled {
+		report := preflight.Run(cfg)
+		if report.Failed() {
+			report.Print(os.Stderr)
+			logrus.Fatalf("preflight checks failed, fix the config or start with -preflight=false")
+		}
+		if report.Warned() {
+			report.Print(os.Stderr)
+			logrus.Warnf("preflight checks passed with warnings")
+		} else {
+			logrus.Infof("preflight checks passed")
+		}
+	}
+
+	utils.OnConfigReload(func(cfg *types.Config) {
+		err := logging.Init(cfg.Logging.Format, cfg.Logging.Level, cfg.Logging.SlowRequestThresholdMs)
+		if err != nil {
+			logrus.WithError(err).Errorf("error applying reloaded logging config")
+		}
+	})
+	go utils.WatchConfig(*configPath)
+
+	shutdownTracing, err := tracing.Init(cfg)
+	if err != nil {
+		logrus.Fatalf("error initializing tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	db.MustInitDB(cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
 	defer db.DB.Close()
+	db.MustInitRepositories()
 
 	db.MustInitFrontendDB(cfg.Frontend.Database.Username, cfg.Frontend.Database.Password, cfg.Frontend.Database.Host, cfg.Frontend.Database.Port, cfg.Frontend.Database.Name, cfg.Frontend.SessionSecret)
 	defer db.FrontendDB.Close()
 
+	if cfg.Chain.Bootstrap.Enabled {
+		db.MustBootstrapSchema(cfg.Chain.Bootstrap.SchemaPath)
+	}
+
+	err = events.Init()
+	if err != nil {
+		logrus.Fatalf("error initializing event bus: %v", err)
+	}
+
+	err = publisher.Init(cfg)
+	if err != nil {
+		logrus.Fatalf("error initializing publisher: %v", err)
+	}
+	defer publisher.Close()
+
 	if utils.Config.Metrics.Enabled {
 		go metrics.MonitorDB(db.DB)
 		DBStr := fmt.Sprintf("%v-%v-%v-%v-%v", cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
@@ -68,32 +157,49 @@ func main() {
 		if DBStr != frontendDBStr {
 			go metrics.MonitorDB(db.FrontendDB)
 		}
+		for name, pool := range db.DBPools() {
+			go metrics.MonitorDBPool(name, pool)
+		}
+		go metrics.MonitorDBPool("frontend", db.FrontendDB)
 	}
 
 	logrus.Infof("database connection established")
-	if utils.Config.Chain.SlotsPerEpoch == 0 || utils.Config.Chain.SecondsPerSlot == 0 {
-		logrus.Fatal("invalid chain configuration specified, you must specify the slots per epoch, seconds per slot and genesis timestamp in the config file")
-	}
 
 	if utils.Config.Indexer.Enabled {
 		var rpcClient rpc.Client
 
-		if utils.Config.Indexer.Node.Type == "prysm" {
-			if utils.Config.Indexer.Node.PageSize == 0 {
-				logrus.Printf("setting default rpc page size to 500")
-				utils.Config.Indexer.Node.PageSize = 500
-			}
-			rpcClient, err = rpc.NewPrysmClient(cfg.Indexer.Node.Host + ":" + cfg.Indexer.Node.Port)
-			if err != nil {
-				logrus.Fatal(err)
+		if utils.Config.Indexer.Node.Type == "prysm" && utils.Config.Indexer.Node.PageSize == 0 {
+			logrus.Printf("setting default rpc page size to 500")
+			utils.Config.Indexer.Node.PageSize = 500
+		}
+
+		nodeEndpoints := append([]string{cfg.Indexer.Node.Host + ":" + cfg.Indexer.Node.Port}, cfg.Indexer.Node.Fallbacks...)
+		nodeClients := make([]rpc.Client, 0, len(nodeEndpoints))
+		for _, endpoint := range nodeEndpoints {
+			if utils.Config.Indexer.Node.Type == "prysm" {
+				client, err := rpc.NewPrysmClient(endpoint)
+				if err != nil {
+					logrus.Fatal(err)
+				}
+				nodeClients = append(nodeClients, client)
+			} else if utils.Config.Indexer.Node.Type == "lighthouse" {
+				client, err := rpc.NewLighthouseClient("http://" + endpoint)
+				if err != nil {
+					logrus.Fatal(err)
+				}
+				nodeClients = append(nodeClients, client)
+			} else {
+				logrus.Fatalf("invalid note type %v specified. supported node types are prysm and lighthouse", utils.Config.Indexer.Node.Type)
 			}
-		} else if utils.Config.Indexer.Node.Type == "lighthouse" {
-			rpcClient, err = rpc.NewLighthouseClient("http://" + cfg.Indexer.Node.Host + ":" + cfg.Indexer.Node.Port)
+		}
+
+		if len(nodeClients) == 1 {
+			rpcClient = nodeClients[0]
+		} else {
+			rpcClient, err = rpc.NewFailoverClient(nodeClients, nodeEndpoints)
 			if err != nil {
 				logrus.Fatal(err)
 			}
-		} else {
-			logrus.Fatalf("invalid note type %v specified. supported node types are prysm and lighthouse", utils.Config.Indexer.Node.Type)
 		}
 
 		if utils.Config.Indexer.OneTimeExport.Enabled {
@@ -117,60 +223,122 @@ func main() {
 			return
 		}
 
-		go services.StartHistoricPriceService()
+		go services.RunAsLeader("historic_price_service", services.StartHistoricPriceService)
 		go exporter.Start(rpcClient)
 	}
 
 	if cfg.Frontend.Enabled {
 
+		err = httpcache.Init(cfg)
+		if err != nil {
+			logrus.Fatalf("error initializing response cache: %v", err)
+		}
+
 		router := mux.NewRouter()
 
+		// the user api is matched before the public api, so only the credentialed cors policy of the user api applies to
+		// its routes
+		apiV1AuthRouter := router.PathPrefix("/api/v1/user").Subrouter()
 		apiV1Router := router.PathPrefix("/api/v1").Subrouter()
 		router.PathPrefix("/api/v1/docs/").Handler(httpSwagger.WrapHandler)
-		apiV1Router.HandleFunc("/epoch/{epoch}", handlers.ApiEpoch).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/epoch/{epoch}/blocks", handlers.ApiEpochBlocks).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/block/{slotOrHash}", handlers.ApiBlock).Methods("GET", "OPTIONS")
+		router.HandleFunc("/feed/{feed:slashings|exits|blocks}.json", handlers.Feed).Methods("GET")
+		apiV1Router.HandleFunc("/epochs", httpcache.Slot(handlers.ApiEpochs)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/epoch/{epoch}", httpcache.Slot(handlers.ApiEpoch)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/epoch/{epoch}/blocks", handlers.ApiFieldsSelection(handlers.ApiBlockFields, httpcache.Slot(handlers.ApiEpochBlocks))).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/epoch/{epoch}/validators", handlers.ApiEpochValidators).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/block/{slotOrHash}", handlers.ApiFieldsSelection(handlers.ApiBlockFields, httpcache.Slot(handlers.ApiBlock))).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/block/{slot}/attestations", handlers.ApiBlockAttestations).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/block/{slot}/deposits", handlers.ApiBlockDeposits).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/block/{slot}/attesterslashings", handlers.ApiBlockAttesterSlashings).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/block/{slot}/proposerslashings", handlers.ApiBlockProposerSlashings).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/block/{slot}/voluntaryexits", handlers.ApiBlockVoluntaryExits).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/slots/tree", httpcache.Slot(handlers.ApiBlockTree)).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/sync_committee/{period}", handlers.ApiSyncCommittee).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/eth1deposit/{txhash}", handlers.ApiEth1Deposit).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/validator/leaderboard", handlers.ApiValidatorLeaderboard).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/validator/{indexOrPubkey}", handlers.ApiValidator).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/leaderboard", httpcache.Epoch(handlers.ApiValidatorLeaderboard)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/depositleaderboard", httpcache.Epoch(handlers.ApiDepositLeaderboard)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}", handlers.ApiFieldsSelection(handlers.ApiValidatorFields, handlers.ApiValidator)).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/balancehistory", handlers.ApiValidatorBalanceHistory).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/performance", handlers.ApiValidatorPerformance).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/attestations", handlers.ApiValidatorAttestations).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/proposals", handlers.ApiValidatorProposals).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/feerecipients", handlers.ApiValidatorFeeRecipients).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/deposits", handlers.ApiValidatorDeposits).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/activation", handlers.ApiValidatorActivationStatus).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/attestationefficiency", handlers.ApiValidatorAttestationEfficiency).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/attestationeffectiveness", handlers.ApiValidatorAttestationEffectiveness).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/attestationcorrectness", handlers.ApiValidatorAttestationCorrectness).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/effectivenesshistory", handlers.ApiValidatorEffectivenessHistory).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/projection", httpcache.Epoch(handlers.ApiValidatorIncomeProjection)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/lifecycle", httpcache.Epoch(handlers.ApiValidatorLifecycle)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/statushistory", httpcache.Epoch(handlers.ApiValidatorStatusHistory)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/propagation", handlers.ApiValidatorBlockPropagation).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/auctions", httpcache.Epoch(handlers.ApiValidatorRelayAuctions)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/peers", httpcache.Epoch(handlers.ApiValidatorPeerComparison)).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/stats/{index}", handlers.ApiValidatorDailyStats).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/eth1/{address}", handlers.ApiValidatorByEth1Address).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/validators/queue", handlers.ApiValidatorQueue).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/graffitiwall", handlers.ApiGraffitiwall).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/chart/{chart}", handlers.ApiChart).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/queue", httpcache.Epoch(handlers.ApiValidatorQueue)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validators", handlers.ApiFieldsSelection(handlers.ApiValidatorFields, handlers.ApiValidatorsBulk)).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/balancehistory", handlers.ApiValidatorsBulkBalanceHistory).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/performance", handlers.ApiValidatorsBulkPerformance).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/compare", handlers.ApiCompare).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/graffitiwall", httpcache.Epoch(handlers.ApiGraffitiwall)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/chart/{chart}", httpcache.Epoch(handlers.ApiChart)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/attestations/aggregation/daily", httpcache.Epoch(handlers.ApiAttestationAggregation)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/rocketpool/reth", featureflags.Require(featureflags.Rocketpool, handlers.ApiRocketpoolRETHHistory)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/rocketpool/nodes/history", featureflags.Require(featureflags.Rocketpool, handlers.ApiRocketpoolNodesHistory)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/charts/custom", handlers.ApiCustomChart).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/deposits/verify", handlers.ApiDepositVerifier).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/deposits/transactions", handlers.ApiDepositTransactions).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/network/decentralization", handlers.ApiDecentralization).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/spec", handlers.ApiSpec).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/decentralization/clusters", handlers.ApiDecentralizationClusters).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/hosting", httpcache.Epoch(handlers.ApiHostingStats)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/economics", httpcache.Epoch(handlers.ApiEconomics)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/economics/chart/{chart}", httpcache.Epoch(handlers.ApiEconomicsChart)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/forecast", handlers.ApiValidatorSetForecast).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/slashing/simulator", httpcache.Epoch(handlers.ApiSlashingSimulator)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/propagation", httpcache.Epoch(handlers.ApiClientBlockPropagation)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/packing", httpcache.Epoch(handlers.ApiClientBlockPacking)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/relays/auctions", handlers.ApiRelayAuctions).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/relays/daily", httpcache.Epoch(handlers.ApiRelayAuctionStats)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/network/relays/builders", httpcache.Epoch(handlers.ApiBuilderMarketShare)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/charts/custom/{id}", handlers.ApiCustomChartByID).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/user/token", handlers.APIGetToken).Methods("POST", "OPTIONS")
 		apiV1Router.HandleFunc("/dashboard/data/balances", handlers.DashboardDataBalance).Methods("GET", "OPTIONS")   // new app versions
 		apiV1Router.HandleFunc("/dashboard/data/balance", handlers.APIDashboardDataBalance).Methods("GET", "OPTIONS") // old app versions
 		apiV1Router.HandleFunc("/dashboard/data/proposals", handlers.DashboardDataProposals).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/dashboard/data/duties", handlers.DashboardDataDuties).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/stripe/webhook", handlers.StripeWebhook).Methods("POST")
+		apiV1Router.HandleFunc("/mail/webhook/{provider}", handlers.MailWebhook).Methods("POST")
 		apiV1Router.HandleFunc("/stats/{apiKey}/{machine}", handlers.ClientStatsPostOld).Methods("POST", "OPTIONS")
 		apiV1Router.HandleFunc("/stats/{apiKey}", handlers.ClientStatsPostOld).Methods("POST", "OPTIONS")
 		apiV1Router.HandleFunc("/client/metrics", handlers.ClientStatsPostNew).Methods("POST", "OPTIONS")
-		apiV1Router.HandleFunc("/app/dashboard", handlers.ApiDashboard).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/app/dashboard", handlers.ApiFieldsSelection(handlers.ApiDashboardFields, handlers.ApiDashboard)).Methods("POST", "OPTIONS")
 
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/widget", handlers.GetMobileWidgetStats).Methods("GET")
-		apiV1Router.Use(utils.CORSMiddleware)
+		apiV1Router.Use(utils.CORSPolicyMiddleware(utils.Config.Frontend.Cors.Api))
+		apiV1Router.Use(handlers.ApiQuotaMiddleware)
 
-		apiV1AuthRouter := apiV1Router.PathPrefix("/user").Subrouter()
 		apiV1AuthRouter.HandleFunc("/mobile/notify/register", handlers.MobileNotificationUpdatePOST).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/mobile/settings", handlers.MobileDeviceSettings).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/mobile/settings", handlers.MobileDeviceSettingsPOST).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/settings/theme", handlers.ApiUserTheme).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/settings/theme", handlers.ApiUserThemePost).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/settings/feerecipient", handlers.ApiUserFeeRecipient).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/settings/feerecipient", handlers.ApiUserFeeRecipientPost).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/test/{channel:email|push}", handlers.ApiUserNotificationsTest).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/validator/saved", handlers.MobileTagedValidators).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/subscription/register", handlers.RegisterMobileSubscriptions).Methods("POST", "OPTIONS")
 
+		apiV1AuthRouter.HandleFunc("/validator/tags", handlers.ApiUserTags).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags", handlers.ApiUserTagCreate).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags/{tag}", handlers.ApiUserTagValidators).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags/{tag}/delete", handlers.ApiUserTagDelete).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags/{tag}/add", handlers.ApiUserTagAddValidators).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags/{tag}/remove", handlers.ApiUserTagRemoveValidators).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags/{tag}/share", handlers.ApiUserTagShare).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/validator/tags/{tag}/unshare", handlers.ApiUserTagUnshare).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/validator/{pubkey}/add", handlers.UserValidatorWatchlistAdd).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/validator/{pubkey}/remove", handlers.UserValidatorWatchlistRemove).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/dashboard/save", handlers.UserDashboardWatchlistAdd).Methods("POST", "OPTIONS")
@@ -182,14 +350,17 @@ func main() {
 		apiV1AuthRouter.HandleFunc("/stats", handlers.ClientStats).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/stats/{offset}/{limit}", handlers.ClientStats).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/ethpool", handlers.RegisterEthpoolSubscription).Methods("POST", "OPTIONS")
-		apiV1AuthRouter.Use(utils.CORSMiddleware)
+		apiV1AuthRouter.HandleFunc("/charts", handlers.ApiCustomChartSave).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.Use(utils.CORSPolicyMiddleware(utils.Config.Frontend.Cors.UserApi))
 		apiV1AuthRouter.Use(utils.AuthorizedAPIMiddleware)
 
 		router.HandleFunc("/api/healthz", handlers.ApiHealthz).Methods("GET", "HEAD")
 		router.HandleFunc("/api/healthz-loadbalancer", handlers.ApiHealthzLoadbalancer).Methods("GET", "HEAD")
+		router.HandleFunc("/status/json", handlers.StatusJSON).Methods("GET", "HEAD")
 
+		featureflags.Init()
 		services.Init() // Init frontend services
-		price.Init()
+		price.Init(utils.Config.Price)
 		ethclients.Init()
 
 		logrus.Infof("frontend services initiated")
@@ -212,28 +383,58 @@ func main() {
 				csrfBytes,
 				csrf.FieldName("CsrfField"),
 				csrf.Secure(!cfg.Frontend.CsrfInsecure),
+				csrf.SameSite(csrf.SameSiteMode(utils.SessionSameSite())), // the modes of csrf have the values of net/http
 				csrf.Path("/"),
 			)
 
-			router.HandleFunc("/", handlers.Index).Methods("GET")
+			if utils.Config.Frontend.PersonalMode.Enabled {
+				router.HandleFunc("/", handlers.PersonalModeIndex).Methods("GET")
+			} else {
+				router.HandleFunc("/", handlers.Index).Methods("GET")
+			}
 			router.HandleFunc("/latestState", handlers.LatestState).Methods("GET")
+			router.HandleFunc("/sse/head", handlers.SseHead).Methods("GET")
 			router.HandleFunc("/launchMetrics", handlers.LaunchMetricsData).Methods("GET")
-			router.HandleFunc("/index/data", handlers.IndexPageData).Methods("GET")
-			router.HandleFunc("/block/{slotOrHash}", handlers.Block).Methods("GET")
+			router.HandleFunc("/index/data", httpcache.Slot(handlers.IndexPageData)).Methods("GET")
+			router.Handle("/block/{slotOrHash}", httpcache.Crawlers(handlers.Block, http.HandlerFunc(handlers.Block))).Methods("GET")
 			router.HandleFunc("/block/{slotOrHash}/deposits", handlers.BlockDepositData).Methods("GET")
 			router.HandleFunc("/block/{slotOrHash}/votes", handlers.BlockVoteData).Methods("GET")
 			router.HandleFunc("/blocks", handlers.Blocks).Methods("GET")
-			router.HandleFunc("/blocks/data", handlers.BlocksData).Methods("GET")
+			router.HandleFunc("/blocks/data", httpcache.Slot(handlers.BlocksData)).Methods("GET")
 			router.HandleFunc("/vis", handlers.Vis).Methods("GET")
 			router.HandleFunc("/charts", handlers.Charts).Methods("GET")
+			router.HandleFunc("/charts/{chart}.{format:png|svg}", utils.AllowEmbedding(handlers.ChartImage)).Methods("GET")
+			router.HandleFunc("/charts/custom/{id}", handlers.CustomChart).Methods("GET")
 			router.HandleFunc("/charts/{chart}", handlers.Chart).Methods("GET")
 			router.HandleFunc("/vis/blocks", handlers.VisBlocks).Methods("GET")
 			router.HandleFunc("/vis/votes", handlers.VisVotes).Methods("GET")
-			router.HandleFunc("/epoch/{epoch}", handlers.Epoch).Methods("GET")
+			router.HandleFunc("/status", handlers.Status).Methods("GET")
+			router.Handle("/csrf", csrfHandler(http.HandlerFunc(handlers.CsrfToken))).Methods("GET")
+			router.Handle("/language", csrfHandler(http.HandlerFunc(handlers.SetLanguage))).Methods("POST")
+			router.Handle("/theme", csrfHandler(http.HandlerFunc(handlers.SetTheme))).Methods("POST")
+			router.HandleFunc("/network/incidents", handlers.NetworkIncidents).Methods("GET")
+			router.HandleFunc("/network/incidents/feed.rss", handlers.NetworkIncidentsFeedRSS).Methods("GET")
+			router.HandleFunc("/network/incidents/feed.json", handlers.NetworkIncidentsFeedJSON).Methods("GET")
+			router.HandleFunc("/network/decentralization", handlers.Decentralization).Methods("GET")
+			router.HandleFunc("/network/hosting", handlers.Hosting).Methods("GET")
+			router.HandleFunc("/network/forecast", handlers.ValidatorSetForecast).Methods("GET")
+			router.HandleFunc("/spec", handlers.Spec).Methods("GET")
+			router.HandleFunc("/widgets/{type:[a-z_]+}.{format:svg|png}", utils.AllowEmbedding(handlers.Widget)).Methods("GET")
+			router.Handle("/epoch/{epoch}", httpcache.Crawlers(handlers.Epoch, http.HandlerFunc(handlers.Epoch))).Methods("GET")
 			router.HandleFunc("/epochs", handlers.Epochs).Methods("GET")
-			router.HandleFunc("/epochs/data", handlers.EpochsData).Methods("GET")
-
-			router.HandleFunc("/validator/{index}", handlers.Validator).Methods("GET")
+			router.HandleFunc("/epochs/data", httpcache.Slot(handlers.EpochsData)).Methods("GET")
+
+			// the validator and dashboard pages issue the csrf tokens of the watchlist and validator name forms, crawlers get
+			// the cached page without the forms
+			router.Handle("/validator/{index}", httpcache.Crawlers(handlers.Validator, csrfHandler(http.HandlerFunc(handlers.Validator)))).Methods("GET")
+			router.HandleFunc("/preview/validator/{index:[0-9]+}.png", handlers.PreviewValidator).Methods("GET")
+			router.HandleFunc("/preview/block/{slot:[0-9]+}.png", handlers.PreviewBlock).Methods("GET")
+			router.HandleFunc("/preview/epoch/{epoch:[0-9]+}.png", handlers.PreviewEpoch).Methods("GET")
+			router.HandleFunc("/robots.txt", handlers.Robots).Methods("GET")
+			if utils.Config.Frontend.Crawlers.Enabled {
+				router.HandleFunc("/sitemap.xml", handlers.SitemapIndex).Methods("GET")
+				router.HandleFunc("/sitemaps/{sitemap:validators|blocks}-{page:[0-9]+}.xml", handlers.Sitemap).Methods("GET")
+			}
 			router.HandleFunc("/validator/{index}/proposedblocks", handlers.ValidatorProposedBlocks).Methods("GET")
 			router.HandleFunc("/validator/{index}/attestations", handlers.ValidatorAttestations).Methods("GET")
 			router.HandleFunc("/validator/{index}/sync", handlers.ValidatorSync).Methods("GET")
@@ -241,27 +442,32 @@ func main() {
 			router.HandleFunc("/validator/{pubkey}/deposits", handlers.ValidatorDeposits).Methods("GET")
 			router.HandleFunc("/validator/{index}/slashings", handlers.ValidatorSlashings).Methods("GET")
 			router.HandleFunc("/validator/{index}/effectiveness", handlers.ValidatorAttestationInclusionEffectiveness).Methods("GET")
-			router.HandleFunc("/validator/{pubkey}/save", handlers.ValidatorSave).Methods("POST")
-			router.HandleFunc("/validator/{pubkey}/add", handlers.UserValidatorWatchlistAdd).Methods("POST")
-			router.HandleFunc("/validator/{pubkey}/remove", handlers.UserValidatorWatchlistRemove).Methods("POST")
+			router.Handle("/validator/{pubkey}/save", csrfHandler(http.HandlerFunc(handlers.ValidatorSave))).Methods("POST")
+			router.HandleFunc("/validator/{pubkey}/ownership/challenge", handlers.ValidatorOwnershipChallenge).Methods("GET")
+			router.Handle("/validator/{pubkey}/ownership", csrfHandler(http.HandlerFunc(handlers.ValidatorOwnershipPost))).Methods("POST")
+			router.Handle("/validator/{pubkey}/hosting", csrfHandler(http.HandlerFunc(handlers.ValidatorHostingPost))).Methods("POST")
+			router.Handle("/validator/{pubkey}/add", csrfHandler(http.HandlerFunc(handlers.UserValidatorWatchlistAdd))).Methods("POST")
+			router.Handle("/validator/{pubkey}/remove", csrfHandler(http.HandlerFunc(handlers.UserValidatorWatchlistRemove))).Methods("POST")
 			router.HandleFunc("/validator/{index}/stats", handlers.ValidatorStatsTable).Methods("GET")
 			router.HandleFunc("/validators", handlers.Validators).Methods("GET")
 			router.HandleFunc("/validators/data", handlers.ValidatorsData).Methods("GET")
 			router.HandleFunc("/validators/slashings", handlers.ValidatorsSlashings).Methods("GET")
 			router.HandleFunc("/validators/slashings/data", handlers.ValidatorsSlashingsData).Methods("GET")
 			router.HandleFunc("/validators/leaderboard", handlers.ValidatorsLeaderboard).Methods("GET")
-			router.HandleFunc("/validators/leaderboard/data", handlers.ValidatorsLeaderboardData).Methods("GET")
+			router.HandleFunc("/validators/leaderboard/data", httpcache.Epoch(handlers.ValidatorsLeaderboardData)).Methods("GET")
 			router.HandleFunc("/validators/streakleaderboard", handlers.ValidatorsStreakLeaderboard).Methods("GET")
-			router.HandleFunc("/validators/streakleaderboard/data", handlers.ValidatorsStreakLeaderboardData).Methods("GET")
+			router.HandleFunc("/validators/streakleaderboard/data", httpcache.Epoch(handlers.ValidatorsStreakLeaderboardData)).Methods("GET")
 			router.HandleFunc("/validators/eth1deposits", handlers.Eth1Deposits).Methods("GET")
 			router.HandleFunc("/validators/eth1deposits/data", handlers.Eth1DepositsData).Methods("GET")
 			router.HandleFunc("/validators/eth1leaderboard", handlers.Eth1DepositsLeaderboard).Methods("GET")
 			router.HandleFunc("/validators/eth1leaderboard/data", handlers.Eth1DepositsLeaderboardData).Methods("GET")
+			router.HandleFunc("/depositleaderboard", handlers.DepositLeaderboard).Methods("GET")
+			router.HandleFunc("/depositleaderboard/data", httpcache.Epoch(handlers.DepositLeaderboardData)).Methods("GET")
 			router.HandleFunc("/validators/eth2deposits", handlers.Eth2Deposits).Methods("GET")
 			router.HandleFunc("/validators/eth2deposits/data", handlers.Eth2DepositsData).Methods("GET")
 
-			router.HandleFunc("/dashboard", handlers.Dashboard).Methods("GET")
-			router.HandleFunc("/dashboard/save", handlers.UserDashboardWatchlistAdd).Methods("POST")
+			router.Handle("/dashboard", csrfHandler(http.HandlerFunc(handlers.Dashboard))).Methods("GET")
+			router.Handle("/dashboard/save", csrfHandler(http.HandlerFunc(handlers.UserDashboardWatchlistAdd))).Methods("POST")
 
 			router.HandleFunc("/dashboard/data/balance", handlers.DashboardDataBalance).Methods("GET")
 			router.HandleFunc("/dashboard/data/proposals", handlers.DashboardDataProposals).Methods("GET")
@@ -269,6 +475,10 @@ func main() {
 			router.HandleFunc("/dashboard/data/validators", handlers.DashboardDataValidators).Methods("GET")
 			router.HandleFunc("/dashboard/data/effectiveness", handlers.DashboardDataEffectiveness).Methods("GET")
 			router.HandleFunc("/dashboard/data/earnings", handlers.DashboardDataEarnings).Methods("GET")
+			router.HandleFunc("/dashboard/data/duties", handlers.DashboardDataDuties).Methods("GET")
+			router.HandleFunc("/dashboard/duties.ics", handlers.DashboardDutiesCalendar).Methods("GET")
+			router.HandleFunc("/dashboard/shared/{shareID}", handlers.DashboardShared).Methods("GET")
+			router.HandleFunc("/dashboard/shared/{shareID}/embed", utils.AllowEmbedding(handlers.DashboardSharedEmbed)).Methods("GET")
 			router.HandleFunc("/graffitiwall", handlers.Graffitiwall).Methods("GET")
 			router.HandleFunc("/calculator", handlers.StakingCalculator).Methods("GET")
 			router.HandleFunc("/search", handlers.Search).Methods("POST")
@@ -282,17 +492,25 @@ func main() {
 
 			router.HandleFunc("/stakingServices", handlers.StakingServices).Methods("GET")
 			router.HandleFunc("/stakingServices", handlers.AddStakingServicePost).Methods("POST")
+			router.HandleFunc("/tools/blsChange", handlers.BLSChange).Methods("GET")
+			router.HandleFunc("/tools/blsChange", handlers.BLSChangePost).Methods("POST")
+			router.HandleFunc("/tools/depositVerifier", handlers.DepositVerifier).Methods("GET")
+			router.HandleFunc("/tools/depositVerifier", handlers.DepositVerifierPost).Methods("POST")
+			router.HandleFunc("/tools/deposit", handlers.DepositTool).Methods("GET")
+			router.HandleFunc("/tools/deposit", handlers.DepositToolPost).Methods("POST")
+			router.HandleFunc("/tools/deposit/track", handlers.DepositToolTrack).Methods("POST")
 
 			router.HandleFunc("/education", handlers.EducationServices).Methods("GET")
 			router.HandleFunc("/ethClients", handlers.EthClientsServices).Methods("GET")
 			router.HandleFunc("/pools", handlers.Pools).Methods("GET")
 			router.HandleFunc("/pools/streak/current", handlers.GetAvgCurrentStreak).Methods("GET")
 			router.HandleFunc("/pools/chart/income_per_eth", handlers.GetIncomePerEthChart).Methods("GET")
-			router.HandleFunc("/pools/rocketpool", handlers.PoolsRocketpool).Methods("GET")
-			router.HandleFunc("/pools/rocketpool/data/minipools", handlers.PoolsRocketpoolDataMinipools).Methods("GET")
-			router.HandleFunc("/pools/rocketpool/data/nodes", handlers.PoolsRocketpoolDataNodes).Methods("GET")
-			router.HandleFunc("/pools/rocketpool/data/dao_proposals", handlers.PoolsRocketpoolDataDAOProposals).Methods("GET")
-			router.HandleFunc("/pools/rocketpool/data/dao_members", handlers.PoolsRocketpoolDataDAOMembers).Methods("GET")
+			router.HandleFunc("/pools/rocketpool", featureflags.Require(featureflags.Rocketpool, handlers.PoolsRocketpool)).Methods("GET")
+			router.HandleFunc("/pools/rocketpool/data/minipools", featureflags.Require(featureflags.Rocketpool, handlers.PoolsRocketpoolDataMinipools)).Methods("GET")
+			router.HandleFunc("/pools/rocketpool/data/nodes", featureflags.Require(featureflags.Rocketpool, handlers.PoolsRocketpoolDataNodes)).Methods("GET")
+			router.HandleFunc("/pools/rocketpool/data/dao_proposals", featureflags.Require(featureflags.Rocketpool, handlers.PoolsRocketpoolDataDAOProposals)).Methods("GET")
+			router.HandleFunc("/pools/rocketpool/data/dao_members", featureflags.Require(featureflags.Rocketpool, handlers.PoolsRocketpoolDataDAOMembers)).Methods("GET")
+			router.HandleFunc("/pools/rocketpool/odao", featureflags.Require(featureflags.Rocketpool, handlers.PoolsRocketpoolODAO)).Methods("GET")
 
 			router.HandleFunc("/advertisewithus", handlers.AdvertiseWithUs).Methods("GET")
 			router.HandleFunc("/advertisewithus", handlers.AdvertiseWithUsPost).Methods("POST")
@@ -338,12 +556,26 @@ func main() {
 			authRouter.HandleFunc("/settings/password", handlers.UserUpdatePasswordPost).Methods("POST")
 			authRouter.HandleFunc("/settings/flags", handlers.UserUpdateFlagsPost).Methods("POST")
 			authRouter.HandleFunc("/settings/delete", handlers.UserDeletePost).Methods("POST")
+			authRouter.HandleFunc("/settings/delete/cancel", handlers.UserCancelDeletionPost).Methods("POST")
+			authRouter.HandleFunc("/settings/export", handlers.UserDataExport).Methods("GET")
 			authRouter.HandleFunc("/settings/email", handlers.UserUpdateEmailPost).Methods("POST")
+			authRouter.HandleFunc("/settings/sessions", handlers.UserSessionsData).Methods("GET")
+			authRouter.HandleFunc("/settings/sessions/{id:[0-9]+}/revoke", handlers.UserSessionRevokePost).Methods("POST")
+			authRouter.HandleFunc("/settings/sessions/revoke-others", handlers.UserSessionsRevokeOthersPost).Methods("POST")
+			authRouter.HandleFunc("/settings/feerecipient", handlers.UserUpdateFeeRecipientPost).Methods("POST")
 			authRouter.HandleFunc("/notifications", handlers.UserNotificationsCenter).Methods("GET")
 			authRouter.HandleFunc("/notifications/data", handlers.UserNotificationsData).Methods("GET")
 			authRouter.HandleFunc("/notifications/subscribe", handlers.UserNotificationsSubscribe).Methods("POST")
 			authRouter.HandleFunc("/notifications/unsubscribe", handlers.UserNotificationsUnsubscribe).Methods("POST")
 			authRouter.HandleFunc("/notifications/bundled/subscribe", handlers.MultipleUsersNotificationsSubscribeWeb).Methods("POST", "OPTIONS")
+			authRouter.HandleFunc("/notifications/rules", handlers.UserNotificationRules).Methods("GET")
+			authRouter.HandleFunc("/notifications/rules", handlers.RequireEntitlement(types.AdvancedNotificationsEntitlement, handlers.UserNotificationRulesAdd)).Methods("POST")
+			authRouter.HandleFunc("/notifications/rules/{id:[0-9]+}/delete", handlers.UserNotificationRulesDelete).Methods("POST")
+			authRouter.HandleFunc("/notifications/log", handlers.UserNotificationsLog).Methods("GET")
+			authRouter.HandleFunc("/notifications/log/{id:[0-9]+}/resend", handlers.UserNotificationsLogResend).Methods("POST")
+			authRouter.HandleFunc("/notifications/test/{channel:email|push}", handlers.UserNotificationsTestPost).Methods("POST")
+			authRouter.HandleFunc("/admin/featureflags", handlers.AdminFeatureFlags).Methods("GET")
+			authRouter.HandleFunc("/admin/featureflags/{name}", handlers.AdminFeatureFlagsUpdate).Methods("POST")
 			authRouter.HandleFunc("/notifications-center", handlers.UserNotificationsCenter).Methods("GET")
 			authRouter.HandleFunc("/notifications-center/removeall", handlers.RemoveAllValidatorsAndUnsubscribe).Methods("POST")
 			authRouter.HandleFunc("/notifications-center/validatorsub", handlers.AddValidatorsAndSubscribe).Methods("POST")
@@ -375,6 +607,14 @@ func main() {
 			router.Use(metrics.HttpMiddleware)
 		}
 
+		if utils.Config.Tracing.Enabled {
+			router.Use(tracing.HTTPMiddleware)
+		}
+
+		router.Use(utils.SecurityHeadersMiddleware)
+		router.Use(utils.PersonalModeMiddleware)
+		router.Use(db.QueryStatsMiddleware)
+
 		n := negroni.New(negroni.NewRecovery())
 
 		// Customize the logging middleware to include a proper module entry for the frontend
@@ -397,12 +637,21 @@ func main() {
 
 		n.UseHandler(router)
 
+		// server-sent events bypass the gzip middleware, it buffers the stream until the response is complete
+		frontendHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/sse/") {
+				router.ServeHTTP(w, r)
+				return
+			}
+			n.ServeHTTP(w, r)
+		})
+
 		srv := &http.Server{
 			Addr:         cfg.Frontend.Server.Host + ":" + cfg.Frontend.Server.Port,
 			WriteTimeout: time.Second * 15,
 			ReadTimeout:  time.Second * 15,
 			IdleTimeout:  time.Second * 60,
-			Handler:      n,
+			Handler:      logging.RequestIDMiddleware(frontendHandler),
 		}
 
 		logrus.Printf("http server listening on %v", srv.Addr)
@@ -412,7 +661,9 @@ func main() {
 			}
 		}()
 	}
-	if utils.Config.Notifications.Enabled {
+	if utils.Config.Notifications.Enabled && utils.Config.Frontend.PersonalMode.Enabled {
+		logrus.Warnf("notifications are not sent in personal mode")
+	} else if utils.Config.Notifications.Enabled {
 		services.InitNotifications()
 	}
 
@@ -435,3 +686,19 @@ func main() {
 
 	logrus.Println("exiting...")
 }
+
+// runRocketpoolExporterOnce runs the rocketpool command, the databases are only connected if the rows are written
+func runRocketpoolExporterOnce(cfg *types.Config, args []string) {
+	fs := flag.NewFlagSet("rocketpool", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Print the rows that would be upserted as json instead of writing them to the database")
+	fs.Parse(args)
+
+	if !*dryRun {
+		db.MustInitDB(cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
+		defer db.DB.Close()
+	}
+	err := exporter.RunRocketpoolExporterOnce(*dryRun, os.Stdout)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"eth2-exporter/bulkexport"
 	"eth2-exporter/db"
+	"eth2-exporter/logging"
+	"eth2-exporter/rpc"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"eth2-exporter/version"
@@ -20,6 +24,10 @@ func main() {
 	statisticsDaysToExport := flag.String("statistics.days", "", "Days to export statistics (will export the day independent if it has been already exported or not")
 	streaksDisabledFlag := flag.Bool("streaks.disabled", false, "Disable exporting streaks")
 	poolsDisabledFlag := flag.Bool("pools.disabled", false, "Disable exporting pools")
+	decentralizationDisabledFlag := flag.Bool("decentralization.disabled", false, "Disable clustering the validators and exporting the decentralization stats")
+	economicsDisabledFlag := flag.Bool("economics.disabled", false, "Disable exporting the daily deposit contract balance, staking ratio, issuance and burn")
+	effectivenessDaysToExport := flag.String("effectiveness.days", "", "Days to recompute the validator effectiveness for with all formulas, e.g. 0-100")
+	bulkExportDays := flag.String("bulkexport.days", "", "Days to write to the bulk export destination (will export the days independent if they have been already exported or not), e.g. 0-100")
 
 	flag.Parse()
 
@@ -32,9 +40,68 @@ func main() {
 	}
 	utils.Config = cfg
 
+	err = logging.Init(cfg.Logging.Format, cfg.Logging.Level, cfg.Logging.SlowRequestThresholdMs)
+	if err != nil {
+		logrus.Fatalf("error initializing logging: %v", err)
+	}
+
 	db.MustInitDB(cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
 	defer db.DB.Close()
 
+	if *effectivenessDaysToExport != "" {
+		s := strings.Split(*effectivenessDaysToExport, "-")
+		if len(s) < 2 {
+			logrus.Fatalf("invalid arg")
+		}
+		firstDay, err := strconv.ParseUint(s[0], 10, 64)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		lastDay, err := strconv.ParseUint(s[1], 10, 64)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		logrus.Infof("exporting validator effectiveness for days %v-%v", firstDay, lastDay)
+		for d := firstDay; d <= lastDay; d++ {
+			err = db.WriteValidatorEffectivenessForDay(d)
+			if err != nil {
+				logrus.Errorf("error exporting validator effectiveness for day %v: %v", d, err)
+			}
+			err = db.WriteValidatorPeerMediansForDay(d)
+			if err != nil {
+				logrus.Errorf("error exporting validator peer medians for day %v: %v", d, err)
+			}
+		}
+		return
+	}
+
+	if *bulkExportDays != "" {
+		s := strings.Split(*bulkExportDays, "-")
+		if len(s) < 2 {
+			logrus.Fatalf("invalid arg")
+		}
+		firstDay, err := strconv.ParseUint(s[0], 10, 64)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		lastDay, err := strconv.ParseUint(s[1], 10, 64)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		exporter, err := bulkexport.NewExporter(context.Background(), cfg)
+		if err != nil {
+			logrus.Fatalf("error initializing bulk export: %v", err)
+		}
+		logrus.Infof("bulk exporting days %v-%v", firstDay, lastDay)
+		for d := firstDay; d <= lastDay; d++ {
+			err = exporter.ExportDay(context.Background(), d)
+			if err != nil {
+				logrus.Errorf("error bulk exporting day %v: %v", d, err)
+			}
+		}
+		return
+	}
+
 	if *statisticsDaysToExport != "" {
 		s := strings.Split(*statisticsDaysToExport, "-")
 		if len(s) < 2 {
@@ -59,6 +126,22 @@ func main() {
 			if err != nil {
 				logrus.Errorf("error exporting stats for day %v: %v", d, err)
 			}
+			err = db.WriteValidatorEffectivenessForDay(uint64(d))
+			if err != nil {
+				logrus.Errorf("error exporting validator effectiveness for day %v: %v", d, err)
+			}
+			err = db.WriteCohortAPRForDay(uint64(d))
+			if err != nil {
+				logrus.Errorf("error exporting cohort apr for day %v: %v", d, err)
+			}
+			err = db.WriteValidatorPeerMediansForDay(uint64(d))
+			if err != nil {
+				logrus.Errorf("error exporting validator peer medians for day %v: %v", d, err)
+			}
+			err = db.WriteEconomicsStatsForDay(uint64(d), nil)
+			if err != nil {
+				logrus.Errorf("error exporting economics stats for day %v: %v", d, err)
+			}
 		}
 		return
 	} else if *statisticsDayToExport >= 0 {
@@ -71,6 +154,22 @@ func main() {
 		if err != nil {
 			logrus.Errorf("error exporting stats for day %v: %v", *statisticsDayToExport, err)
 		}
+		err = db.WriteValidatorEffectivenessForDay(uint64(*statisticsDayToExport))
+		if err != nil {
+			logrus.Errorf("error exporting validator effectiveness for day %v: %v", *statisticsDayToExport, err)
+		}
+		err = db.WriteCohortAPRForDay(uint64(*statisticsDayToExport))
+		if err != nil {
+			logrus.Errorf("error exporting cohort apr for day %v: %v", *statisticsDayToExport, err)
+		}
+		err = db.WriteValidatorPeerMediansForDay(uint64(*statisticsDayToExport))
+		if err != nil {
+			logrus.Errorf("error exporting validator peer medians for day %v: %v", *statisticsDayToExport, err)
+		}
+		err = db.WriteEconomicsStatsForDay(uint64(*statisticsDayToExport), nil)
+		if err != nil {
+			logrus.Errorf("error exporting economics stats for day %v: %v", *statisticsDayToExport, err)
+		}
 		return
 	}
 
@@ -81,6 +180,19 @@ func main() {
 	if !*poolsDisabledFlag {
 		go poolsLoop()
 	}
+	if !*decentralizationDisabledFlag {
+		go decentralizationLoop()
+	}
+	if !*economicsDisabledFlag {
+		go economicsLoop()
+	}
+	if utils.Config.BulkExport.Enabled {
+		exporter, err := bulkexport.NewExporter(context.Background(), cfg)
+		if err != nil {
+			logrus.Fatalf("error initializing bulk export: %v", err)
+		}
+		go bulkExportLoop(exporter)
+	}
 
 	utils.WaitForCtrlC()
 
@@ -89,7 +201,7 @@ func main() {
 
 func statisticsLoop() {
 	for {
-		latestEpoch, err := db.GetLatestEpoch()
+		latestEpoch, err := db.GetLatestEpoch(context.Background())
 		if err != nil {
 			logrus.Errorf("error retreiving latest epoch from the db: %v", err)
 			time.Sleep(time.Minute)
@@ -126,6 +238,18 @@ func statisticsLoop() {
 				if err != nil {
 					logrus.Errorf("error exporting stats for day %v: %v", day, err)
 				}
+				err = db.WriteValidatorEffectivenessForDay(day)
+				if err != nil {
+					logrus.Errorf("error exporting validator effectiveness for day %v: %v", day, err)
+				}
+				err = db.WriteCohortAPRForDay(day)
+				if err != nil {
+					logrus.Errorf("error exporting cohort apr for day %v: %v", day, err)
+				}
+				err = db.WriteValidatorPeerMediansForDay(day)
+				if err != nil {
+					logrus.Errorf("error exporting validator peer medians for day %v: %v", day, err)
+				}
 			}
 		}
 		time.Sleep(time.Minute)
@@ -154,3 +278,120 @@ func poolsLoop() {
 		time.Sleep(time.Minute * 10)
 	}
 }
+
+// decentralizationLoop clusters the validators of every finished day that has not been clustered yet
+func decentralizationLoop() {
+	for {
+		latestEpoch, err := db.GetLatestEpoch(context.Background())
+		if err != nil {
+			logrus.Errorf("error retreiving latest epoch from the db: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+
+		epochsPerDay := utils.EpochsPerDay()
+		if latestEpoch < epochsPerDay {
+			time.Sleep(time.Minute)
+			continue
+		}
+		previousDay := latestEpoch/epochsPerDay - 1
+
+		lastDay, exported, err := db.GetLastDecentralizationStatsDay()
+		if err != nil {
+			logrus.Errorf("error retreiving last decentralization stats day from the db: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+		// the clusters of past days can not be reconstructed (e.g. withdrawal credentials change), so only the
+		// previous day is computed when the stats have never been exported before
+		day := previousDay
+		if exported {
+			day = lastDay + 1
+		}
+
+		for ; day <= previousDay; day++ {
+			err = db.UpdateDecentralizationStatsForDay(day)
+			if err != nil {
+				logrus.Errorf("error updating decentralization stats for day %v: %v", day, err)
+				break
+			}
+		}
+		time.Sleep(time.Minute * 10)
+	}
+}
+
+// economicsLoop exports the economics stats of the days whose validator_stats have been written, starting after the last
+// exported day. The circulating supply is only stored for the latest day as the endpoint returns the current supply.
+func economicsLoop() {
+	for {
+		var lastStatsDay *uint64
+		err := db.DB.Get(&lastStatsDay, "select max(day) from validator_stats_status where status")
+		if err != nil {
+			logrus.Errorf("error retreiving latest exported statistics day from the db: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+
+		lastDay, exported, err := db.GetLastEconomicsStatsDay()
+		if err != nil {
+			logrus.Errorf("error retreiving last economics stats day from the db: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+		day := uint64(0)
+		if exported {
+			day = lastDay + 1
+		}
+
+		for ; lastStatsDay != nil && day <= *lastStatsDay; day++ {
+			var supply *uint64
+			if day == *lastStatsDay && utils.Config.Economics.SupplyEndpoint != "" {
+				s, err := rpc.GetEthSupply(utils.Config.Economics.SupplyEndpoint)
+				if err != nil {
+					logrus.Errorf("error retrieving circulating supply: %v", err)
+				} else {
+					supply = &s
+				}
+			}
+			err = db.WriteEconomicsStatsForDay(day, supply)
+			if err != nil {
+				logrus.Errorf("error exporting economics stats for day %v: %v", day, err)
+				break
+			}
+		}
+		time.Sleep(time.Minute * 10)
+	}
+}
+
+// bulkExportLoop exports the days whose validator_stats have been written, starting after the last exported day
+func bulkExportLoop(exporter *bulkexport.Exporter) {
+	for {
+		var lastStatsDay *uint64
+		err := db.DB.Get(&lastStatsDay, "select max(day) from validator_stats_status where status")
+		if err != nil {
+			logrus.Errorf("error retreiving latest exported statistics day from the db: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+
+		lastExportedDay, exported, err := bulkexport.LastExportedDay()
+		if err != nil {
+			logrus.Errorf("error retreiving latest bulk exported day from the db: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+		day := uint64(0)
+		if exported {
+			day = lastExportedDay + 1
+		}
+
+		for ; lastStatsDay != nil && day <= *lastStatsDay; day++ {
+			err := exporter.ExportDay(context.Background(), day)
+			if err != nil {
+				logrus.Errorf("error bulk exporting day %v: %v", day, err)
+				break
+			}
+		}
+		time.Sleep(time.Minute * 10)
+	}
+}
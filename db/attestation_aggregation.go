@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"sort"
+)
+
+// every unaggregated attestation would have carried its own attestation data and signature
+const attestationDataSize = 128
+const attestationSignatureSize = 96
+
+func saveAttestationAggregationStats(epoch uint64, blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
+	canonicalBlocks := []*types.Block{}
+	for _, slot := range blocks {
+		for _, b := range slot {
+			if b.Status == 1 {
+				canonicalBlocks = append(canonicalBlocks, b)
+			}
+		}
+	}
+	sort.Slice(canonicalBlocks, func(i, j int) bool {
+		return canonicalBlocks[i].Slot < canonicalBlocks[j].Slot
+	})
+
+	type vote struct {
+		slot      uint64
+		committee uint64
+		validator uint64
+	}
+	type committee struct {
+		slot  uint64
+		index uint64
+	}
+
+	// votes are considered redundant if an earlier block of the epoch already included them
+	seenVotes := map[vote]bool{}
+	stats := map[string]*types.AttestationAggregationStats{}
+	committees := map[string]map[committee]bool{}
+
+	for _, b := range canonicalBlocks {
+		client := utils.ClientFromGraffiti(b.Graffiti)
+		if stats[client] == nil {
+			stats[client] = &types.AttestationAggregationStats{Epoch: epoch, Client: client}
+			committees[client] = map[committee]bool{}
+		}
+		s := stats[client]
+		s.Blocks++
+
+		for _, a := range b.Attestations {
+			s.Aggregates++
+			s.Attestations += uint64(len(a.Attesters))
+			if len(a.Attesters) > 1 {
+				s.BytesSaved += uint64(len(a.Attesters)-1) * (attestationDataSize + attestationSignatureSize)
+			}
+			committees[client][committee{a.Data.Slot, a.Data.CommitteeIndex}] = true
+
+			redundant := uint64(0)
+			for _, validator := range a.Attesters {
+				v := vote{a.Data.Slot, a.Data.CommitteeIndex, validator}
+				if seenVotes[v] {
+					redundant++
+					continue
+				}
+				seenVotes[v] = true
+			}
+			s.RedundantAttestations += redundant
+			if redundant == uint64(len(a.Attesters)) {
+				s.RedundantAggregates++
+			}
+		}
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO attestation_aggregation_stats (epoch, client, blocks, aggregates, committees, attestations, redundant_attestations, redundant_aggregates, bytes_saved)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (epoch, client) DO UPDATE SET
+			blocks                 = excluded.blocks,
+			aggregates             = excluded.aggregates,
+			committees             = excluded.committees,
+			attestations           = excluded.attestations,
+			redundant_attestations = excluded.redundant_attestations,
+			redundant_aggregates   = excluded.redundant_aggregates,
+			bytes_saved            = excluded.bytes_saved`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for client, s := range stats {
+		s.Committees = uint64(len(committees[client]))
+		_, err := stmt.Exec(s.Epoch, s.Client, s.Blocks, s.Aggregates, s.Committees, s.Attestations, s.RedundantAttestations, s.RedundantAggregates, s.BytesSaved)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDailyAttestationAggregationStats returns the aggregation statistics per client and day, days are counted since genesis
+func GetDailyAttestationAggregationStats(ctx context.Context, startDay, endDay uint64) ([]*types.AttestationAggregationStats, error) {
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	stats := []*types.AttestationAggregationStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT
+			epoch / $1 AS day,
+			client,
+			SUM(blocks) AS blocks,
+			SUM(aggregates) AS aggregates,
+			SUM(committees) AS committees,
+			SUM(attestations) AS attestations,
+			SUM(redundant_attestations) AS redundant_attestations,
+			SUM(redundant_aggregates) AS redundant_aggregates,
+			SUM(bytes_saved) AS bytes_saved
+		FROM attestation_aggregation_stats
+		WHERE epoch >= $2 * $1 AND epoch < ($3 + 1) * $1
+		GROUP BY day, client
+		ORDER BY day, client`, epochsPerDay, startDay, endDay)
+	return stats, err
+}
@@ -0,0 +1,400 @@
+package db
+
+import (
+	"database/sql"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func init() {
+	RegisterRepositoryDriver("bitfields", openBitfieldRepositories)
+}
+
+// saveAttestationBitfields stores the duties of the epoch and the attestations included in its canonical blocks in the
+// bit vectors of attestation_bitfields. The blocks of an epoch only include attestations of the epoch and of the
+// previous epoch, the attestations of the previous epoch are stored in its included_next vector. Every vector is only
+// written by the export of one epoch and overwritten when the epoch is exported again, e.g. after a reorg.
+func saveAttestationBitfields(data *types.EpochData, tx *sql.Tx) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_save_attestation_bitfields").Observe(time.Since(start).Seconds())
+	}()
+
+	assigned, included, includedPrevious := encodeAttestationBitfields(data)
+	err := saveAttestationBitfield(tx, data.Epoch, assigned, included)
+	if err != nil {
+		return fmt.Errorf("error saving attestation bitfield of epoch %v: %w", data.Epoch, err)
+	}
+	if data.Epoch == 0 {
+		return nil
+	}
+	// the row of the previous epoch is created with empty vectors if it has not been exported
+	_, err = tx.Exec(`
+		INSERT INTO attestation_bitfields (epoch, assigned, included, included_next) VALUES ($1, '', '', $2)
+		ON CONFLICT (epoch) DO UPDATE SET included_next = excluded.included_next`,
+		data.Epoch-1, includedPrevious)
+	if err != nil {
+		return fmt.Errorf("error saving attestation bitfield of epoch %v: %w", data.Epoch-1, err)
+	}
+	return nil
+}
+
+// encodeAttestationBitfields returns the bit vectors of the duties of the epoch, of the attestations of the epoch and
+// of the attestations of the previous epoch included in its canonical blocks. Bit i is the validator with index i.
+func encodeAttestationBitfields(data *types.EpochData) (assigned, included, includedPrevious []byte) {
+	// in personal mode only the bits of the watched validators are set, like only their assignments are stored
+	size := (len(data.Validators) + 7) / 8
+	assigned = make([]byte, size)
+	for _, validator := range data.ValidatorAssignmentes.AttestorAssignments {
+		if int(validator) < size*8 && utils.PersonalModeWatched(validator) {
+			utils.SetBitAtVector(assigned, int(validator))
+		}
+	}
+
+	included = make([]byte, size)
+	includedPrevious = make([]byte, size)
+	for _, slot := range data.Blocks {
+		for _, b := range slot {
+			if b.Status != 1 {
+				continue
+			}
+			for _, a := range b.Attestations {
+				var vector []byte
+				switch a.Data.Slot / utils.Config.Chain.SlotsPerEpoch {
+				case data.Epoch:
+					vector = included
+				case data.Epoch - 1:
+					vector = includedPrevious
+				default:
+					continue
+				}
+				for _, validator := range a.Attesters {
+					if int(validator) < size*8 && utils.PersonalModeWatched(validator) {
+						utils.SetBitAtVector(vector, int(validator))
+					}
+				}
+			}
+		}
+	}
+
+	return assigned, included, includedPrevious
+}
+
+// saveAttestationBitfield overwrites the duties of the epoch and the attestations included in its blocks, the
+// attestations included in the blocks of the next epoch are kept
+func saveAttestationBitfield(tx *sql.Tx, epoch uint64, assigned, included []byte) error {
+	_, err := tx.Exec(`
+		INSERT INTO attestation_bitfields (epoch, assigned, included, included_next) VALUES ($1, $2, $3, '')
+		ON CONFLICT (epoch) DO UPDATE SET assigned = excluded.assigned, included = excluded.included`,
+		epoch, assigned, included)
+	return err
+}
+
+// BackfillAttestationBitfield builds the bit vectors of an epoch from the rows of attestation_assignments_p, e.g.
+// before the rows of the epoch are dropped. The included vector is built from the status of the rows, which covers the
+// attestations included in the blocks of the next epoch as well.
+func BackfillAttestationBitfield(epoch uint64) error {
+	rows := []struct {
+		ValidatorIndex int    `db:"validatorindex"`
+		Status         uint64 `db:"status"`
+	}{}
+	err := IndexerDB.Select(&rows, `
+		SELECT validatorindex, status
+		FROM attestation_assignments_p
+		WHERE week = $1 AND epoch = $2`, epoch/utils.EpochsPerWeekPartition, epoch)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var maxIndex int
+	for _, r := range rows {
+		if r.ValidatorIndex > maxIndex {
+			maxIndex = r.ValidatorIndex
+		}
+	}
+	assigned := make([]byte, maxIndex/8+1)
+	included := make([]byte, maxIndex/8+1)
+	for _, r := range rows {
+		utils.SetBitAtVector(assigned, r.ValidatorIndex)
+		if r.Status == 1 {
+			utils.SetBitAtVector(included, r.ValidatorIndex)
+		}
+	}
+
+	tx, err := IndexerDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = saveAttestationBitfield(tx, epoch, assigned, included)
+	if err != nil {
+		return fmt.Errorf("error saving attestation bitfield of epoch %v: %w", epoch, err)
+	}
+	return tx.Commit()
+}
+
+// getAttestationBitfieldHistory reads the attestation duties of the validators from the bit vectors, ordered by
+// validator index and descending epoch. Only the bytes covering the validators are read from each vector. The slots of
+// the duties are not stored, the status of a duty that was not included is missed once the inclusion window of the
+// next epoch has passed.
+func getAttestationBitfieldHistory(conn *sqlx.DB, indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	if len(indices) == 0 {
+		return []*types.ApiAttestationAssignment{}, nil
+	}
+	sorted := append([]uint64{}, indices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	firstByte := sorted[0] / 8
+	length := sorted[len(sorted)-1]/8 - firstByte + 1
+
+	rows := []*attestationBitfieldRow{}
+	// substring positions start at 1
+	err := conn.Select(&rows, `
+		SELECT
+			epoch,
+			substring(assigned from $1 for $2) AS assigned,
+			substring(included from $1 for $2) AS included,
+			substring(included_next from $1 for $2) AS included_next
+		FROM attestation_bitfields
+		WHERE epoch >= $3 AND epoch <= $4
+		ORDER BY epoch DESC`, firstByte+1, length, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAttestationBitfields(rows, sorted, firstByte, uint64(utils.TimeToEpoch(time.Now()))), nil
+}
+
+// attestationBitfieldRow are the bit vectors of an epoch starting at a byte offset
+type attestationBitfieldRow struct {
+	Epoch        uint64 `db:"epoch"`
+	Assigned     []byte `db:"assigned"`
+	Included     []byte `db:"included"`
+	IncludedNext []byte `db:"included_next"`
+}
+
+// decodeAttestationBitfields returns the attestation duties of the sorted validators in the rows, whose vectors start
+// at byte firstByte, ordered by validator index and the order of the rows
+func decodeAttestationBitfields(rows []*attestationBitfieldRow, sorted []uint64, firstByte, currentEpoch uint64) []*types.ApiAttestationAssignment {
+	history := []*types.ApiAttestationAssignment{}
+	for i, index := range sorted {
+		if i > 0 && index == sorted[i-1] {
+			continue
+		}
+		bit := int(index - firstByte*8)
+		for _, r := range rows {
+			if bit/8 >= len(r.Assigned) || !utils.BitAtVector(r.Assigned, bit) {
+				continue
+			}
+			status := uint64(0)
+			if (bit/8 < len(r.Included) && utils.BitAtVector(r.Included, bit)) || (bit/8 < len(r.IncludedNext) && utils.BitAtVector(r.IncludedNext, bit)) {
+				status = 1
+			} else if r.Epoch+1 < currentEpoch {
+				status = 2
+			}
+			history = append(history, &types.ApiAttestationAssignment{Epoch: r.Epoch, Validatorindex: index, Status: status, Week: r.Epoch / utils.EpochsPerWeekPartition})
+		}
+	}
+	return history
+}
+
+// bitfieldRepositories reads the attestation duties from attestation_bitfields, which keeps the history of the weeks
+// whose attestation_assignments_p rows are dropped
+type bitfieldRepositories struct {
+	db *sqlx.DB
+}
+
+// openBitfieldRepositories reads from the explorer database or, if a dsn is given, from another postgres database
+func openBitfieldRepositories(dsn string) (*Repositories, error) {
+	conn, err := openReadBackendDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &Repositories{Attestations: &bitfieldRepositories{db: conn}}, nil
+}
+
+func (r *bitfieldRepositories) GetAttestationAssignments(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	return getAttestationBitfieldHistory(r.db, indices, startEpoch, endEpoch)
+}
+
+func (r *bitfieldRepositories) GetMissedAttestations(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	history, err := getAttestationBitfieldHistory(r.db, indices, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	missed := []*types.ApiAttestationAssignment{}
+	for _, a := range history {
+		if a.Status == 2 {
+			missed = append(missed, a)
+		}
+	}
+	return missed, nil
+}
+
+// retainedAttestationsRepository reads the epochs that are kept in attestation_assignments_p from the configured read
+// backend and the epochs of the dropped weeks from attestation_bitfields
+type retainedAttestationsRepository struct {
+	retained AttestationsRepository
+	dropped  AttestationsRepository
+}
+
+func (r *retainedAttestationsRepository) GetAttestationAssignments(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	return r.split(startEpoch, endEpoch, func(repository AttestationsRepository, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+		return repository.GetAttestationAssignments(indices, startEpoch, endEpoch)
+	})
+}
+
+func (r *retainedAttestationsRepository) GetMissedAttestations(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	return r.split(startEpoch, endEpoch, func(repository AttestationsRepository, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+		return repository.GetMissedAttestations(indices, startEpoch, endEpoch)
+	})
+}
+
+// split reads the epochs from startEpoch to endEpoch from the repository that keeps them, the assignments are ordered
+// by validator index and descending epoch
+func (r *retainedAttestationsRepository) split(startEpoch, endEpoch uint64, get func(AttestationsRepository, uint64, uint64) ([]*types.ApiAttestationAssignment, error)) ([]*types.ApiAttestationAssignment, error) {
+	return r.splitAt(AttestationAssignmentsRetainedEpoch(), startEpoch, endEpoch, get)
+}
+
+// splitAt reads the epochs from retainedEpoch on from the retained and the epochs before from the dropped repository
+func (r *retainedAttestationsRepository) splitAt(retainedEpoch, startEpoch, endEpoch uint64, get func(AttestationsRepository, uint64, uint64) ([]*types.ApiAttestationAssignment, error)) ([]*types.ApiAttestationAssignment, error) {
+	if startEpoch >= retainedEpoch {
+		return get(r.retained, startEpoch, endEpoch)
+	}
+	if endEpoch < retainedEpoch {
+		return get(r.dropped, startEpoch, endEpoch)
+	}
+
+	assignments, err := get(r.retained, retainedEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := get(r.dropped, startEpoch, retainedEpoch-1)
+	if err != nil {
+		return nil, err
+	}
+	assignments = append(assignments, dropped...)
+	sort.SliceStable(assignments, func(i, j int) bool {
+		if assignments[i].Validatorindex != assignments[j].Validatorindex {
+			return assignments[i].Validatorindex < assignments[j].Validatorindex
+		}
+		return assignments[i].Epoch > assignments[j].Epoch
+	})
+	return assignments, nil
+}
+
+// attestationAssignmentsMinRetention is the history that is kept in attestation_assignments_p independent of the
+// configured retention. The inclusion distances, effectiveness and notifications of the last days are only read from
+// attestation_assignments_p, the slots of the duties are not stored in attestation_bitfields.
+const attestationAssignmentsMinRetention = time.Hour * 24 * 8
+
+// AttestationAssignmentsRetainedEpoch returns the first epoch whose rows are kept in attestation_assignments_p by the
+// configured retention, the history of older epochs is read from attestation_bitfields. It is 0 if the retention is
+// disabled. The rows of older epochs may still exist if the pruner has not dropped their week yet.
+func AttestationAssignmentsRetainedEpoch() uint64 {
+	retention := utils.Config.Indexer.AttestationAssignmentsRetentionWeeks
+	currentWeek := uint64(utils.TimeToEpoch(time.Now())) / utils.EpochsPerWeekPartition
+	if retention == 0 || currentWeek <= retention {
+		return 0
+	}
+	week := currentWeek - retention
+	if minWeek := uint64(utils.TimeToEpoch(time.Now().Add(-attestationAssignmentsMinRetention))) / utils.EpochsPerWeekPartition; minWeek < week {
+		week = minWeek
+	}
+	return week * utils.EpochsPerWeekPartition
+}
+
+// PruneAttestationAssignments drops the weeks of attestation_assignments_p before the retained epoch. Weeks with
+// epochs whose daily statistics, attestation streaks or attestation correctness have not been exported yet are kept,
+// these are computed from the rows.
+func PruneAttestationAssignments() error {
+	epoch := AttestationAssignmentsRetainedEpoch()
+
+	lastStatsDay := sql.NullInt64{}
+	err := IndexerDB.Get(&lastStatsDay, "SELECT MAX(day) FROM validator_stats_status WHERE status")
+	if err != nil {
+		return fmt.Errorf("error retrieving last exported statistics day: %w", err)
+	}
+	if !lastStatsDay.Valid {
+		return nil
+	}
+	if statsEpoch := uint64(lastStatsDay.Int64+1) * utils.EpochsPerDay(); statsEpoch < epoch {
+		epoch = statsEpoch
+	}
+
+	streaksEpoch := sql.NullInt64{}
+	err = IndexerDB.Get(&streaksEpoch, "SELECT MAX(start + length) FROM validator_attestation_streaks")
+	if err != nil {
+		return fmt.Errorf("error retrieving last attestation streaks epoch: %w", err)
+	}
+	if streaksEpoch.Valid && uint64(streaksEpoch.Int64) < epoch {
+		epoch = uint64(streaksEpoch.Int64)
+	}
+
+	if utils.Config.Indexer.AttestationCorrectness.Enabled {
+		lastCorrectnessEpoch, ok, err := GetLastAttestationCorrectnessEpoch()
+		if err != nil {
+			return fmt.Errorf("error retrieving last attestation correctness epoch: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+		if lastCorrectnessEpoch+1 < epoch {
+			epoch = lastCorrectnessEpoch + 1
+		}
+	}
+
+	return DropAttestationAssignmentWeeks(epoch / utils.EpochsPerWeekPartition)
+}
+
+// DropAttestationAssignmentWeeks drops the weeks of attestation_assignments_p before the given week, the bit vectors
+// of a week are backfilled from its rows before it is dropped
+func DropAttestationAssignmentWeeks(beforeWeek uint64) error {
+	weeks := []uint64{}
+	err := IndexerDB.Select(&weeks, `
+		SELECT substring(table_name from 'attestation_assignments_([0-9]+)')::int AS week
+		FROM information_schema.tables
+		WHERE table_name ~ '^attestation_assignments_[0-9]+$'
+		ORDER BY week`)
+	if err != nil {
+		return err
+	}
+	for _, week := range weeks {
+		if week >= beforeWeek {
+			break
+		}
+		// the rows of epochs that were never exported into attestation_bitfields, or only as the previous epoch of an
+		// exported one, have no duties in the bit vectors yet
+		missing := []uint64{}
+		err = IndexerDB.Select(&missing, `
+			SELECT DISTINCT epoch FROM attestation_assignments_p a
+			WHERE week = $1 AND NOT EXISTS (SELECT 1 FROM attestation_bitfields b WHERE b.epoch = a.epoch AND length(b.assigned) > 0)
+			ORDER BY epoch`, week)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			logger.Infof("backfilling attestation bitfields of %v epochs of week %v before dropping its attestation assignments", len(missing), week)
+		}
+		for _, epoch := range missing {
+			err = BackfillAttestationBitfield(epoch)
+			if err != nil {
+				return fmt.Errorf("error backfilling attestation bitfield of epoch %v: %w", epoch, err)
+			}
+		}
+		_, err = IndexerDB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS attestation_assignments_%d", week))
+		if err != nil {
+			return fmt.Errorf("error dropping attestation assignments of week %v: %w", week, err)
+		}
+		logger.Infof("dropped attestation assignments of week %v", week)
+	}
+	return nil
+}
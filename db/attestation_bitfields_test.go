@@ -0,0 +1,318 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// bitfieldTestEpochData returns the data of an epoch with the duties of the assigned validators, the attestations of
+// the epoch and of the previous epoch included in a canonical block and the attestations in an orphaned block
+func bitfieldTestEpochData(epoch uint64, validators int, assigned, included, includedPrevious, orphaned []uint64) *types.EpochData {
+	data := &types.EpochData{
+		Epoch:                 epoch,
+		Validators:            make([]*types.Validator, validators),
+		ValidatorAssignmentes: &types.EpochAssignments{AttestorAssignments: map[string]uint64{}},
+		Blocks:                map[uint64]map[string]*types.Block{},
+	}
+	for i, validator := range assigned {
+		data.ValidatorAssignmentes.AttestorAssignments[fmt.Sprintf("%v-0-%v", epoch*32, i)] = validator
+	}
+	slot := epoch * 32
+	canonical := &types.Block{Status: 1, Slot: slot + 1}
+	canonical.Attestations = append(canonical.Attestations, &types.Attestation{Data: &types.AttestationData{Slot: slot}, Attesters: included})
+	if epoch > 0 {
+		canonical.Attestations = append(canonical.Attestations, &types.Attestation{Data: &types.AttestationData{Slot: slot - 1}, Attesters: includedPrevious})
+	}
+	data.Blocks[slot+1] = map[string]*types.Block{"canonical": canonical}
+	data.Blocks[slot+2] = map[string]*types.Block{"orphaned": {
+		Status:       3,
+		Slot:         slot + 2,
+		Attestations: []*types.Attestation{{Data: &types.AttestationData{Slot: slot}, Attesters: orphaned}},
+	}}
+	return data
+}
+
+// bitfieldSubstring returns the bytes of the vector the query of getAttestationBitfieldHistory selects
+func bitfieldSubstring(vector []byte, firstByte, length uint64) []byte {
+	if firstByte >= uint64(len(vector)) {
+		return []byte{}
+	}
+	end := firstByte + length
+	if end > uint64(len(vector)) {
+		end = uint64(len(vector))
+	}
+	return vector[firstByte:end]
+}
+
+func TestAttestationBitfieldsRoundTrip(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.SlotsPerEpoch = 32
+
+	type duty struct {
+		index  uint64
+		status uint64
+	}
+	tests := []struct {
+		name             string
+		validators       int
+		assigned         []uint64
+		included         []uint64
+		includedNext     []uint64
+		orphaned         []uint64
+		indices          []uint64
+		epochsSinceDuty  uint64
+		want             []duty
+		wantVectorLength int
+	}{
+		{
+			name:             "included in the epoch",
+			validators:       8,
+			assigned:         []uint64{0, 3},
+			included:         []uint64{0, 3},
+			indices:          []uint64{0, 3},
+			epochsSinceDuty:  2,
+			want:             []duty{{0, 1}, {3, 1}},
+			wantVectorLength: 1,
+		},
+		{
+			name:             "included in the next epoch",
+			validators:       8,
+			assigned:         []uint64{5},
+			includedNext:     []uint64{5},
+			indices:          []uint64{5},
+			epochsSinceDuty:  2,
+			want:             []duty{{5, 1}},
+			wantVectorLength: 1,
+		},
+		{
+			name:             "missed once the inclusion window passed",
+			validators:       8,
+			assigned:         []uint64{1, 2},
+			included:         []uint64{1},
+			indices:          []uint64{1, 2},
+			epochsSinceDuty:  2,
+			want:             []duty{{1, 1}, {2, 2}},
+			wantVectorLength: 1,
+		},
+		{
+			name:             "scheduled within the inclusion window",
+			validators:       8,
+			assigned:         []uint64{2},
+			indices:          []uint64{2},
+			epochsSinceDuty:  1,
+			want:             []duty{{2, 0}},
+			wantVectorLength: 1,
+		},
+		{
+			name:             "attestations of orphaned blocks are not included",
+			validators:       8,
+			assigned:         []uint64{4},
+			orphaned:         []uint64{4},
+			indices:          []uint64{4},
+			epochsSinceDuty:  2,
+			want:             []duty{{4, 2}},
+			wantVectorLength: 1,
+		},
+		{
+			name:             "validators without duty are not returned",
+			validators:       16,
+			assigned:         []uint64{9},
+			included:         []uint64{9},
+			indices:          []uint64{8, 9, 10},
+			epochsSinceDuty:  2,
+			want:             []duty{{9, 1}},
+			wantVectorLength: 2,
+		},
+		{
+			name:             "byte boundaries with an offset vector",
+			validators:       1001,
+			assigned:         []uint64{7, 8, 15, 16, 1000},
+			included:         []uint64{8, 16},
+			includedNext:     []uint64{1000},
+			indices:          []uint64{1000, 16, 15, 8},
+			epochsSinceDuty:  2,
+			want:             []duty{{8, 1}, {15, 2}, {16, 1}, {1000, 1}},
+			wantVectorLength: 126,
+		},
+		{
+			name:             "duplicate indices are returned once",
+			validators:       8,
+			assigned:         []uint64{6},
+			included:         []uint64{6},
+			indices:          []uint64{6, 6},
+			epochsSinceDuty:  2,
+			want:             []duty{{6, 1}},
+			wantVectorLength: 1,
+		},
+		{
+			name:             "indices beyond the validator set are ignored",
+			validators:       10,
+			assigned:         []uint64{9, 20},
+			included:         []uint64{9, 20},
+			indices:          []uint64{9, 20},
+			epochsSinceDuty:  2,
+			want:             []duty{{9, 1}},
+			wantVectorLength: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			epoch := uint64(100)
+			assigned, included, _ := encodeAttestationBitfields(bitfieldTestEpochData(epoch, tt.validators, tt.assigned, tt.included, nil, tt.orphaned))
+			_, _, includedNext := encodeAttestationBitfields(bitfieldTestEpochData(epoch+1, tt.validators, nil, nil, tt.includedNext, nil))
+			if len(assigned) != tt.wantVectorLength || len(included) != tt.wantVectorLength || len(includedNext) != tt.wantVectorLength {
+				t.Fatalf("got vector lengths %v, %v and %v, want %v", len(assigned), len(included), len(includedNext), tt.wantVectorLength)
+			}
+
+			sorted := append([]uint64{}, tt.indices...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			firstByte := sorted[0] / 8
+			length := sorted[len(sorted)-1]/8 - firstByte + 1
+			rows := []*attestationBitfieldRow{{
+				Epoch:        epoch,
+				Assigned:     bitfieldSubstring(assigned, firstByte, length),
+				Included:     bitfieldSubstring(included, firstByte, length),
+				IncludedNext: bitfieldSubstring(includedNext, firstByte, length),
+			}}
+
+			got := []duty{}
+			for _, a := range decodeAttestationBitfields(rows, sorted, firstByte, epoch+tt.epochsSinceDuty) {
+				if a.Epoch != epoch || a.Week != epoch/utils.EpochsPerWeekPartition {
+					t.Errorf("got duty of epoch %v in week %v, want epoch %v", a.Epoch, a.Week, epoch)
+				}
+				got = append(got, duty{a.Validatorindex, a.Status})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got duties %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeAttestationBitfieldsOrder(t *testing.T) {
+	// validator 1 attested in both epochs, validator 2 only in the older one, the rows are ordered by descending epoch
+	rows := []*attestationBitfieldRow{
+		{Epoch: 11, Assigned: []byte{0x06}, Included: []byte{0x02}, IncludedNext: []byte{}},
+		{Epoch: 10, Assigned: []byte{0x06}, Included: []byte{0x02}, IncludedNext: []byte{0x04}},
+		// the row of a previous epoch that was not exported has empty vectors
+		{Epoch: 9, Assigned: []byte{}, Included: []byte{}, IncludedNext: []byte{0x06}},
+	}
+	got := []string{}
+	for _, a := range decodeAttestationBitfields(rows, []uint64{1, 2}, 0, 20) {
+		got = append(got, fmt.Sprintf("%v:%v:%v", a.Validatorindex, a.Epoch, a.Status))
+	}
+	want := []string{"1:11:1", "1:10:1", "2:11:2", "2:10:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got duties %v, want %v", got, want)
+	}
+}
+
+// splitTestRepository returns a duty of validators 1 and 2 for every epoch of the requested range
+type splitTestRepository struct {
+	calls *[]string
+	name  string
+}
+
+func (r *splitTestRepository) get(startEpoch, endEpoch uint64) []*types.ApiAttestationAssignment {
+	*r.calls = append(*r.calls, fmt.Sprintf("%v %v-%v", r.name, startEpoch, endEpoch))
+	assignments := []*types.ApiAttestationAssignment{}
+	for _, index := range []uint64{1, 2} {
+		for epoch := endEpoch; epoch+1 > startEpoch; epoch-- {
+			assignments = append(assignments, &types.ApiAttestationAssignment{Validatorindex: index, Epoch: epoch})
+		}
+	}
+	return assignments
+}
+
+func (r *splitTestRepository) GetAttestationAssignments(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	return r.get(startEpoch, endEpoch), nil
+}
+
+func (r *splitTestRepository) GetMissedAttestations(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	return r.get(startEpoch, endEpoch), nil
+}
+
+func TestRetainedAttestationsSplit(t *testing.T) {
+	tests := []struct {
+		name          string
+		retainedEpoch uint64
+		startEpoch    uint64
+		endEpoch      uint64
+		wantCalls     []string
+	}{
+		{
+			name:          "only retained epochs",
+			retainedEpoch: 100,
+			startEpoch:    100,
+			endEpoch:      102,
+			wantCalls:     []string{"retained 100-102"},
+		},
+		{
+			name:          "only dropped epochs",
+			retainedEpoch: 100,
+			startEpoch:    97,
+			endEpoch:      99,
+			wantCalls:     []string{"dropped 97-99"},
+		},
+		{
+			name:          "first epoch before the retained epoch",
+			retainedEpoch: 100,
+			startEpoch:    99,
+			endEpoch:      101,
+			wantCalls:     []string{"retained 100-101", "dropped 99-99"},
+		},
+		{
+			name:          "last epoch is the retained epoch",
+			retainedEpoch: 100,
+			startEpoch:    98,
+			endEpoch:      100,
+			wantCalls:     []string{"retained 100-100", "dropped 98-99"},
+		},
+		{
+			name:          "retention disabled",
+			retainedEpoch: 0,
+			startEpoch:    0,
+			endEpoch:      2,
+			wantCalls:     []string{"retained 0-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := []string{}
+			r := &retainedAttestationsRepository{
+				retained: &splitTestRepository{calls: &calls, name: "retained"},
+				dropped:  &splitTestRepository{calls: &calls, name: "dropped"},
+			}
+			assignments, err := r.splitAt(tt.retainedEpoch, tt.startEpoch, tt.endEpoch, func(repository AttestationsRepository, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+				return repository.GetAttestationAssignments([]uint64{1, 2}, startEpoch, endEpoch)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("got calls %v, want %v", calls, tt.wantCalls)
+			}
+
+			// every epoch of the range is returned once per validator, ordered by validator index and descending epoch
+			want := []string{}
+			for _, index := range []uint64{1, 2} {
+				for epoch := tt.endEpoch; epoch+1 > tt.startEpoch; epoch-- {
+					want = append(want, fmt.Sprintf("%v:%v", index, epoch))
+				}
+			}
+			got := []string{}
+			for _, a := range assignments {
+				got = append(got, fmt.Sprintf("%v:%v", a.Validatorindex, a.Epoch))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got assignments %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// GetLastAttestationCorrectnessEpoch returns the last epoch whose attestation votes have been evaluated, ok is false if
+// no epoch has been evaluated yet
+func GetLastAttestationCorrectnessEpoch() (epoch uint64, ok bool, err error) {
+	var last sql.NullInt64
+	err = DB.Get(&last, "SELECT MAX(epoch) FROM attestation_correctness_stats")
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(last.Int64), last.Valid, nil
+}
+
+// SaveAttestationCorrectness evaluates the votes of the first included attestation of every attestation assignment of
+// the epoch against the canonical chain. The head vote is correct if it is the latest canonical block at the slot of
+// the attestation and the target vote if it is the latest canonical block at the start of the epoch. Attestations with
+// a wrong source can not be included, so every included attestation has a correct source vote. Missed attestations
+// have no correct votes. The epoch must be finalized so that the canonical chain does not change anymore.
+func SaveAttestationCorrectness(epoch uint64) error {
+	slotsPerEpoch := utils.Config.Chain.SlotsPerEpoch
+	startSlot := epoch * slotsPerEpoch
+	endSlot := startSlot + slotsPerEpoch - 1
+
+	// the canonical blocks of the epoch and the last canonical block before it, empty slots vote for the block before
+	canonicalBlocks := []struct {
+		Slot      uint64 `db:"slot"`
+		BlockRoot []byte `db:"blockroot"`
+	}{}
+	err := DB.Select(&canonicalBlocks, `
+		SELECT slot, blockroot
+		FROM blocks
+		WHERE status = '1' AND slot <= $2 AND slot >= (SELECT COALESCE(MAX(slot), 0) FROM blocks WHERE status = '1' AND slot <= $1)
+		ORDER BY slot`, startSlot, endSlot)
+	if err != nil {
+		return fmt.Errorf("error retrieving canonical blocks of epoch %v: %w", epoch, err)
+	}
+	headAt := func(slot uint64) []byte {
+		var root []byte
+		for _, b := range canonicalBlocks {
+			if b.Slot > slot {
+				break
+			}
+			root = b.BlockRoot
+		}
+		return root
+	}
+	targetRoot := headAt(startSlot)
+
+	votes := []struct {
+		Validatorindex  uint64 `db:"validatorindex"`
+		Slot            uint64 `db:"slot"`
+		BeaconBlockRoot []byte `db:"beaconblockroot"`
+		TargetRoot      []byte `db:"target_root"`
+	}{}
+	err = DB.Select(&votes, `
+		SELECT DISTINCT ON (v.validatorindex) v.validatorindex, a.slot, a.beaconblockroot, a.target_root
+		FROM blocks_attestations a
+		INNER JOIN blocks b ON b.slot = a.block_slot AND b.blockroot = a.block_root AND b.status = '1'
+		CROSS JOIN LATERAL UNNEST(a.validators) AS v(validatorindex)
+		WHERE a.slot >= $1 AND a.slot <= $2 AND a.block_slot >= $1 AND a.block_slot <= $3
+		ORDER BY v.validatorindex, a.block_slot`, startSlot, endSlot, endSlot+slotsPerEpoch)
+	if err != nil {
+		return fmt.Errorf("error retrieving attestation votes of epoch %v: %w", epoch, err)
+	}
+
+	validators := make([]int64, len(votes))
+	targetCorrect := make([]bool, len(votes))
+	headCorrect := make([]bool, len(votes))
+	for i, v := range votes {
+		validators[i] = int64(v.Validatorindex)
+		targetCorrect[i] = targetRoot != nil && bytes.Equal(v.TargetRoot, targetRoot)
+		headCorrect[i] = bytes.Equal(v.BeaconBlockRoot, headAt(v.Slot))
+	}
+
+	tx, err := ExporterDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// assignments without an included attestation have no correct votes
+	week := epoch / utils.EpochsPerWeekPartition
+	_, err = tx.Exec(`
+		UPDATE attestation_assignments_p aa SET
+			source_correct = v.validatorindex IS NOT NULL,
+			target_correct = COALESCE(v.target_correct, false),
+			head_correct = COALESCE(v.head_correct, false)
+		FROM attestation_assignments_p a
+		LEFT JOIN UNNEST($3::int[], $4::bool[], $5::bool[]) AS v(validatorindex, target_correct, head_correct) ON v.validatorindex = a.validatorindex
+		WHERE aa.week = $1 AND aa.epoch = $2 AND a.week = $1 AND a.epoch = $2 AND a.validatorindex = aa.validatorindex`,
+		week, epoch, pq.Array(validators), pq.Array(targetCorrect), pq.Array(headCorrect))
+	if err != nil {
+		return fmt.Errorf("error saving attestation correctness of epoch %v: %w", epoch, err)
+	}
+	_, err = tx.Exec(`
+		INSERT INTO attestation_correctness_stats (epoch, assignments, attestations, wrong_source, wrong_target, wrong_head)
+		SELECT
+			$2,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE source_correct),
+			COUNT(*) FILTER (WHERE NOT source_correct),
+			COUNT(*) FILTER (WHERE NOT target_correct),
+			COUNT(*) FILTER (WHERE NOT head_correct)
+		FROM attestation_assignments_p
+		WHERE week = $1 AND epoch = $2
+		ON CONFLICT (epoch) DO UPDATE SET
+			assignments  = excluded.assignments,
+			attestations = excluded.attestations,
+			wrong_source = excluded.wrong_source,
+			wrong_target = excluded.wrong_target,
+			wrong_head   = excluded.wrong_head`, week, epoch)
+	if err != nil {
+		return fmt.Errorf("error saving attestation correctness stats of epoch %v: %w", epoch, err)
+	}
+	return tx.Commit()
+}
+
+// GetDailyAttestationCorrectnessStats returns the attestation correctness statistics per day, days are counted since
+// genesis
+func GetDailyAttestationCorrectnessStats(startDay, endDay uint64) ([]*types.AttestationCorrectnessStats, error) {
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	stats := []*types.AttestationCorrectnessStats{}
+	err := DB.Select(&stats, `
+		SELECT
+			epoch / $1 AS day,
+			SUM(assignments) AS assignments,
+			SUM(attestations) AS attestations,
+			SUM(wrong_source) AS wrong_source,
+			SUM(wrong_target) AS wrong_target,
+			SUM(wrong_head) AS wrong_head
+		FROM attestation_correctness_stats
+		WHERE epoch >= $2 * $1 AND epoch < ($3 + 1) * $1
+		GROUP BY day
+		ORDER BY day`, epochsPerDay, startDay, endDay)
+	return stats, err
+}
+
+// GetValidatorsAttestationCorrectness returns the number of evaluated attestation assignments and wrong votes of the
+// validators since startEpoch, the votes of the dropped weeks of attestation_assignments_p are not stored so the epochs
+// before the retained epoch are skipped
+func GetValidatorsAttestationCorrectness(ctx context.Context, validators []uint64, startEpoch uint64) ([]*types.ValidatorAttestationCorrectness, error) {
+	if retainedEpoch := AttestationAssignmentsRetainedEpoch(); startEpoch < retainedEpoch {
+		startEpoch = retainedEpoch
+	}
+	correctness := []*types.ValidatorAttestationCorrectness{}
+	err := DB.SelectContext(ctx, &correctness, `
+		SELECT
+			validatorindex,
+			COUNT(*) AS epochs,
+			COUNT(*) FILTER (WHERE status <> 1) AS missed,
+			COUNT(*) FILTER (WHERE NOT source_correct) AS wrong_source,
+			COUNT(*) FILTER (WHERE NOT target_correct) AS wrong_target,
+			COUNT(*) FILTER (WHERE NOT head_correct) AS wrong_head
+		FROM attestation_assignments_p
+		WHERE validatorindex = ANY($1) AND week >= $2 / 1575 AND epoch >= $2 AND head_correct IS NOT NULL
+		GROUP BY validatorindex
+		ORDER BY validatorindex`, pq.Array(validators), startEpoch)
+	return correctness, err
+}
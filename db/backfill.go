@@ -0,0 +1,25 @@
+package db
+
+// GetBackfilledEpochs returns the epochs of the given range that have already been backfilled and verified
+func GetBackfilledEpochs(startEpoch, endEpoch uint64) (map[uint64]bool, error) {
+	epochs := []uint64{}
+	err := DB.Select(&epochs, "SELECT epoch FROM backfill_status WHERE epoch >= $1 AND epoch <= $2 AND verified", startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]bool, len(epochs))
+	for _, epoch := range epochs {
+		res[epoch] = true
+	}
+	return res, nil
+}
+
+// SetEpochBackfilled checkpoints the backfill progress of an epoch
+func SetEpochBackfilled(epoch uint64, verified bool) error {
+	_, err := DB.Exec(`
+		INSERT INTO backfill_status (epoch, verified, ts)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (epoch) DO UPDATE SET verified = excluded.verified, ts = excluded.ts`, epoch, verified)
+	return err
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// postgres supports at most 65535 parameters per statement
+const maxStatementParams = 65535
+
+// BatchUpsert inserts the rows into the table within a single transaction.
+// Rows conflicting on conflictKeys update all other columns, if all columns are conflict keys conflicting rows are skipped.
+// The rows are written with multi-row inserts unless the copy method is configured for the table in the bulkWriter config.
+func BatchUpsert(table string, columns, conflictKeys []string, rows [][]interface{}) error {
+	return BatchUpsertContext(context.Background(), table, columns, conflictKeys, rows)
+}
+
+// BatchUpsertContext works like BatchUpsert, the statements are executed with the context so they are traced as part
+// of the span in it. The rows are written with the pool of the exporters.
+func BatchUpsertContext(ctx context.Context, table string, columns, conflictKeys []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tx, err := BeginBulkTx(ctx, ExporterDB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	w := &BulkWriter{Table: table, Columns: columns, ConflictKeys: conflictKeys}
+	err = w.Write(ctx, tx, rows)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BatchUpsertTx works like BatchUpsert but uses the given transaction, the rows are always written with multi-row
+// inserts as COPY requires a BulkTx
+func BatchUpsertTx(tx *sqlx.Tx, table string, columns, conflictKeys []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("error upserting into %v: no columns given", table)
+	}
+	batchSize := 0
+	if cfg, exists := utils.Config.BulkWriter.Tables[table]; exists {
+		batchSize = cfg.BatchSize
+	}
+	return insertRows(context.Background(), tx.Tx, table, columns, upsertConflictClause(columns, conflictKeys), rows, insertBatchSize(batchSize, len(columns)))
+}
+
+// upsertConflictClause returns the conflict clause updating all columns that are not conflict keys
+func upsertConflictClause(columns, conflictKeys []string) string {
+	if len(conflictKeys) == 0 {
+		return ""
+	}
+	isConflictKey := make(map[string]bool, len(conflictKeys))
+	for _, k := range conflictKeys {
+		isConflictKey[k] = true
+	}
+	updates := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if !isConflictKey[c] {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", c, c))
+		}
+	}
+	if len(updates) == 0 {
+		return fmt.Sprintf("on conflict (%s) do nothing", strings.Join(conflictKeys, ", "))
+	}
+	return fmt.Sprintf("on conflict (%s) do update set %s", strings.Join(conflictKeys, ", "), strings.Join(updates, ", "))
+}
+
+// insertRows writes the rows with multi-row inserts of batchSize rows
+func insertRows(ctx context.Context, tx *sql.Tx, table string, columns []string, onConflict string, rows [][]interface{}, batchSize int) error {
+	nArgs := len(columns)
+	for b := 0; b < len(rows); b += batchSize {
+		start := b
+		end := b + batchSize
+		if len(rows) < end {
+			end = len(rows)
+		}
+
+		valueStrings := make([]string, 0, end-start)
+		valueArgs := make([]interface{}, 0, (end-start)*nArgs)
+		placeholders := make([]string, nArgs)
+		for i, row := range rows[start:end] {
+			if len(row) != nArgs {
+				return fmt.Errorf("error upserting into %v: row %v has %v values, expected %v", table, start+i, len(row), nArgs)
+			}
+			for j := range placeholders {
+				placeholders[j] = fmt.Sprintf("$%d", i*nArgs+j+1)
+			}
+			valueStrings = append(valueStrings, "("+strings.Join(placeholders, ",")+")")
+			valueArgs = append(valueArgs, row...)
+		}
+
+		stmt := fmt.Sprintf(`insert into %s (%s) values %s %s`, table, strings.Join(columns, ", "), strings.Join(valueStrings, ","), onConflict)
+		_, err := tx.ExecContext(ctx, stmt, valueArgs...)
+		if err != nil {
+			return fmt.Errorf("error inserting into %v: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/bits"
+
+	"github.com/lib/pq"
+)
+
+// GetLastBlockPackingEpoch returns the last epoch whose block packing has been evaluated, ok is false if no epoch has
+// been evaluated yet
+func GetLastBlockPackingEpoch() (epoch uint64, ok bool, err error) {
+	var last sql.NullInt64
+	err = DB.Get(&last, "SELECT MAX(epoch) FROM blocks_packing")
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(last.Int64), last.Valid, nil
+}
+
+// SaveBlockPacking evaluates how many of the attestations and sync committee signatures available to the proposers of
+// the canonical blocks of the epoch they included. An attestation duty is available to a block if it is within the
+// inclusion window, has not been included by an earlier canonical block and got included eventually, so only valid
+// attestations of online validators are counted. A sync committee signature is available if the member signed in any
+// block of the epoch. The next epoch must be finalized so that all inclusions of the available attestations are known.
+func SaveBlockPacking(epoch uint64) error {
+	slotsPerEpoch := utils.Config.Chain.SlotsPerEpoch
+	startSlot := epoch * slotsPerEpoch
+	endSlot := startSlot + slotsPerEpoch - 1
+
+	// since deneb the attestations of the previous epoch can be included until the end of the current epoch, before they
+	// had to be included within one epoch
+	deneb := utils.Config.Chain.DenebForkEpoch != nil && epoch >= *utils.Config.Chain.DenebForkEpoch
+	available := func(attestedSlot, blockSlot uint64) bool {
+		if attestedSlot >= blockSlot {
+			return false
+		}
+		if deneb {
+			return attestedSlot/slotsPerEpoch+1 >= blockSlot/slotsPerEpoch
+		}
+		return blockSlot-attestedSlot <= slotsPerEpoch
+	}
+
+	blocks := []struct {
+		Slot              uint64 `db:"slot"`
+		BlockRoot         []byte `db:"blockroot"`
+		Proposer          uint64 `db:"proposer"`
+		Graffiti          []byte `db:"graffiti"`
+		SyncAggregateBits []byte `db:"syncaggregate_bits"`
+	}{}
+	err := DB.Select(&blocks, `
+		SELECT slot, blockroot, proposer, graffiti, syncaggregate_bits
+		FROM blocks
+		WHERE status = '1' AND slot >= $1 AND slot <= $2
+		ORDER BY slot`, startSlot, endSlot)
+	if err != nil {
+		return fmt.Errorf("error retrieving canonical blocks of epoch %v: %w", epoch, err)
+	}
+
+	// the number of attestation duties by their slot and the slot of the first canonical block including them
+	inclusions := []struct {
+		AttestedSlot   uint64 `db:"attested_slot"`
+		FirstBlockSlot uint64 `db:"first_block_slot"`
+		Count          uint64 `db:"count"`
+	}{}
+	err = DB.Select(&inclusions, `
+		SELECT attested_slot, first_block_slot, COUNT(*) AS count
+		FROM (
+			SELECT a.slot AS attested_slot, MIN(a.block_slot) AS first_block_slot
+			FROM blocks_attestations a
+			INNER JOIN blocks b ON b.slot = a.block_slot AND b.blockroot = a.block_root AND b.status = '1'
+			CROSS JOIN LATERAL UNNEST(a.validators) AS v(validatorindex)
+			WHERE a.slot >= $1 AND a.slot <= $2 AND a.block_slot >= $1 AND a.block_slot <= $3
+			GROUP BY a.slot, v.validatorindex
+		) i
+		GROUP BY attested_slot, first_block_slot`, int64(startSlot)-int64(2*slotsPerEpoch), endSlot, endSlot+2*slotsPerEpoch)
+	if err != nil {
+		return fmt.Errorf("error retrieving attestation inclusions of epoch %v: %w", epoch, err)
+	}
+
+	var signed []byte
+	for _, b := range blocks {
+		for i := range b.SyncAggregateBits {
+			if i >= len(signed) {
+				signed = append(signed, 0)
+			}
+			signed[i] |= b.SyncAggregateBits[i]
+		}
+	}
+	syncAvailable := uint64(0)
+	for _, s := range signed {
+		syncAvailable += uint64(bits.OnesCount8(s))
+	}
+
+	packing := make([]*types.BlockPacking, 0, len(blocks))
+	proposers := make([]int64, 0, len(blocks))
+	for _, b := range blocks {
+		p := &types.BlockPacking{
+			Slot:      b.Slot,
+			BlockRoot: b.BlockRoot,
+			Epoch:     epoch,
+			Proposer:  b.Proposer,
+			Client:    utils.ClientFromGraffiti(b.Graffiti),
+		}
+		for _, i := range inclusions {
+			if i.FirstBlockSlot == b.Slot {
+				p.AttestationsIncluded += i.Count
+			}
+			if i.FirstBlockSlot >= b.Slot && available(i.AttestedSlot, b.Slot) {
+				p.AttestationsAvailable += i.Count
+			}
+		}
+		if len(b.SyncAggregateBits) > 0 {
+			for _, s := range b.SyncAggregateBits {
+				p.SyncIncluded += uint64(bits.OnesCount8(s))
+			}
+			p.SyncAvailable = syncAvailable
+		}
+		packing = append(packing, p)
+		proposers = append(proposers, int64(b.Proposer))
+	}
+
+	tx, err := ExporterDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM blocks_packing WHERE epoch = $1", epoch)
+	if err != nil {
+		return fmt.Errorf("error deleting block packing of epoch %v: %w", epoch, err)
+	}
+	for _, p := range packing {
+		_, err = tx.Exec(`
+			INSERT INTO blocks_packing (slot, blockroot, epoch, proposer, client, attestations_included, attestations_available, sync_included, sync_available)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			p.Slot, p.BlockRoot, p.Epoch, p.Proposer, p.Client, p.AttestationsIncluded, p.AttestationsAvailable, p.SyncIncluded, p.SyncAvailable)
+		if err != nil {
+			return fmt.Errorf("error saving block packing of slot %v: %w", p.Slot, err)
+		}
+	}
+
+	// the aggregates of the proposers are recomputed from their blocks so that reevaluated epochs are not counted twice
+	_, err = tx.Exec(`
+		INSERT INTO validator_packing (validatorindex, blocks, attestations_included, attestations_available, sync_included, sync_available)
+		SELECT proposer, COUNT(*), SUM(attestations_included), SUM(attestations_available), SUM(sync_included), SUM(sync_available)
+		FROM blocks_packing
+		WHERE proposer = ANY($1)
+		GROUP BY proposer
+		ON CONFLICT (validatorindex) DO UPDATE SET
+			blocks                 = excluded.blocks,
+			attestations_included  = excluded.attestations_included,
+			attestations_available = excluded.attestations_available,
+			sync_included          = excluded.sync_included,
+			sync_available         = excluded.sync_available`, pq.Array(proposers))
+	if err != nil {
+		return fmt.Errorf("error saving validator packing of epoch %v: %w", epoch, err)
+	}
+	return tx.Commit()
+}
+
+// GetBlockPacking returns the packing of the block, nil is returned if it has not been evaluated
+func GetBlockPacking(ctx context.Context, slot uint64, blockRoot []byte) (*types.BlockPacking, error) {
+	packing := &types.BlockPacking{}
+	err := DB.GetContext(ctx, packing, `
+		SELECT slot, blockroot, epoch, proposer, client, attestations_included, attestations_available, sync_included, sync_available
+		FROM blocks_packing
+		WHERE slot = $1 AND blockroot = $2`, slot, blockRoot)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return packing, nil
+}
+
+// GetValidatorPacking returns the packing of all evaluated blocks of the validator, nil is returned if none of its
+// blocks has been evaluated
+func GetValidatorPacking(ctx context.Context, validatorIndex uint64) (*types.ValidatorPacking, error) {
+	packing := &types.ValidatorPacking{}
+	err := DB.GetContext(ctx, packing, `
+		SELECT validatorindex, blocks, attestations_included, attestations_available, sync_included, sync_available
+		FROM validator_packing
+		WHERE validatorindex = $1`, validatorIndex)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return packing, nil
+}
+
+// GetDailyBlockPackingStats returns the packing of the blocks per client and day, days are counted since genesis
+func GetDailyBlockPackingStats(ctx context.Context, startDay, endDay uint64) ([]*types.BlockPackingStats, error) {
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	stats := []*types.BlockPackingStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT
+			epoch / $1 AS day,
+			client,
+			COUNT(*) AS blocks,
+			SUM(attestations_included) AS attestations_included,
+			SUM(attestations_available) AS attestations_available,
+			SUM(sync_included) AS sync_included,
+			SUM(sync_available) AS sync_available
+		FROM blocks_packing
+		WHERE epoch >= $2 * $1 AND epoch < ($3 + 1) * $1
+		GROUP BY day, client
+		ORDER BY day, client`, epochsPerDay, startDay, endDay)
+	return stats, err
+}
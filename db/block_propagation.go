@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// chronicallyLateMinBlocks is the number of observed blocks a proposer needs before it can be flagged as chronically late
+const chronicallyLateMinBlocks = 3
+
+// SaveBlockPropagation will save the time a node first saw a block, later sightings of the same block by the node are ignored
+func SaveBlockPropagation(blockRoot []byte, slot uint64, node string, seenTs time.Time, delay time.Duration) error {
+	_, err := DB.Exec(`
+		INSERT INTO block_propagation (blockroot, slot, node, seen_ts, delay_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (blockroot, node) DO NOTHING`,
+		blockRoot, slot, node, seenTs, delay.Milliseconds())
+	return err
+}
+
+// PruneBlockPropagation deletes the observed blocks of the slots before the given slot and returns the number of deleted rows
+func PruneBlockPropagation(beforeSlot uint64) (int64, error) {
+	res, err := DB.Exec("DELETE FROM block_propagation WHERE slot < $1", beforeSlot)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetProposerBlockPropagation returns the propagation stats of all observed blocks of the proposers given by index or
+// pubkey, proposers without observed blocks are omitted
+func GetProposerBlockPropagation(ctx context.Context, indices []uint64, pubkeys pq.ByteaArray) ([]*types.BlockPropagationStats, error) {
+	rows := []*struct {
+		Proposer uint64 `db:"proposer"`
+		DelayMs  uint64 `db:"delay_ms"`
+	}{}
+	err := DB.SelectContext(ctx, &rows, `
+		SELECT b.proposer, MIN(p.delay_ms) AS delay_ms
+		FROM blocks b
+		INNER JOIN block_propagation p ON p.slot = b.slot AND p.blockroot = b.blockroot
+		LEFT JOIN validators v ON v.validatorindex = b.proposer
+		WHERE b.proposer = ANY($1) OR v.pubkey = ANY($2)
+		GROUP BY b.proposer, b.slot, b.blockroot
+		ORDER BY b.proposer`, pq.Array(indices), pubkeys)
+	if err != nil {
+		return nil, err
+	}
+
+	delays := map[uint64][]uint64{}
+	for _, row := range rows {
+		delays[row.Proposer] = append(delays[row.Proposer], row.DelayMs)
+	}
+
+	stats := make([]*types.BlockPropagationStats, 0, len(delays))
+	for proposer, d := range delays {
+		s := blockPropagationStats(d)
+		proposer := proposer
+		s.Proposer = &proposer
+		s.ChronicallyLate = s.BlocksCount >= chronicallyLateMinBlocks && s.LateBlocksCount*2 >= s.BlocksCount
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return *stats[i].Proposer < *stats[j].Proposer })
+	return stats, nil
+}
+
+// GetClientBlockPropagation returns the propagation stats of the blocks observed since the given slot by consensus
+// client, the client is guessed from the graffiti of the blocks
+func GetClientBlockPropagation(ctx context.Context, fromSlot uint64) ([]*types.BlockPropagationStats, error) {
+	rows := []*struct {
+		Graffiti []byte `db:"graffiti"`
+		DelayMs  uint64 `db:"delay_ms"`
+	}{}
+	err := DB.SelectContext(ctx, &rows, `
+		SELECT b.graffiti, MIN(p.delay_ms) AS delay_ms
+		FROM block_propagation p
+		INNER JOIN blocks b ON b.slot = p.slot AND b.blockroot = p.blockroot
+		WHERE p.slot >= $1
+		GROUP BY b.slot, b.blockroot, b.graffiti`, fromSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	delays := map[string][]uint64{}
+	for _, row := range rows {
+		client := utils.ClientFromGraffiti(row.Graffiti)
+		delays[client] = append(delays[client], row.DelayMs)
+	}
+
+	stats := make([]*types.BlockPropagationStats, 0, len(delays))
+	for client, d := range delays {
+		s := blockPropagationStats(d)
+		s.Client = client
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].BlocksCount > stats[j].BlocksCount })
+	return stats, nil
+}
+
+// blockPropagationStats aggregates the first seen delays of a set of blocks
+func blockPropagationStats(delays []uint64) *types.BlockPropagationStats {
+	s := &types.BlockPropagationStats{BlocksCount: uint64(len(delays))}
+	if len(delays) == 0 {
+		return s
+	}
+
+	lateThresholdMs := uint64(utils.LateBlockThreshold().Milliseconds())
+	sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+	sum := uint64(0)
+	for _, d := range delays {
+		sum += d
+		if d > lateThresholdMs {
+			s.LateBlocksCount++
+		}
+	}
+	s.AvgDelayMs = float64(sum) / float64(len(delays))
+	s.MaxDelayMs = delays[len(delays)-1]
+	if len(delays)%2 == 0 {
+		s.MedianDelayMs = float64(delays[len(delays)/2-1]+delays[len(delays)/2]) / 2
+	} else {
+		s.MedianDelayMs = float64(delays[len(delays)/2])
+	}
+	return s
+}
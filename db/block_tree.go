@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+)
+
+// GetBlockTree returns the proposed and orphaned blocks between startSlot and endSlot (inclusive) with their parent
+// relations. The indexer stores the orphaned headers the node saw next to the canonical blocks, the votes are the latest
+// head votes of each validator among the attestations of the window that were included by a canonical block within an
+// epoch.
+func GetBlockTree(ctx context.Context, startSlot, endSlot uint64) (*types.BlockTree, error) {
+	rows := []struct {
+		Slot                  uint64 `db:"slot"`
+		BlockRoot             []byte `db:"blockroot"`
+		ParentRoot            []byte `db:"parentroot"`
+		Proposer              uint64 `db:"proposer"`
+		Status                string `db:"status"`
+		Votes                 uint64 `db:"votes"`
+		VotesEffectiveBalance uint64 `db:"votes_effective_balance"`
+	}{}
+	err := DB.SelectContext(ctx, &rows, `
+		WITH window_blocks AS (
+			SELECT slot, blockroot, parentroot, proposer, status
+			FROM blocks
+			WHERE slot BETWEEN $1 AND $2 AND status IN ('1', '3')
+		), latest_votes AS (
+			SELECT DISTINCT ON (v.validatorindex) v.validatorindex, a.beaconblockroot
+			FROM blocks_attestations a
+			INNER JOIN blocks b ON b.slot = a.block_slot AND b.blockroot = a.block_root AND b.status = '1'
+			CROSS JOIN LATERAL UNNEST(a.validators) AS v(validatorindex)
+			WHERE a.block_slot BETWEEN $1 AND $2 + $3 AND a.slot BETWEEN $1 AND $2
+			ORDER BY v.validatorindex, a.slot DESC
+		)
+		SELECT
+			wb.slot,
+			wb.blockroot,
+			wb.parentroot,
+			wb.proposer,
+			wb.status,
+			COUNT(lv.validatorindex) AS votes,
+			COALESCE(SUM(val.effectivebalance), 0) AS votes_effective_balance
+		FROM window_blocks wb
+		LEFT JOIN latest_votes lv ON lv.beaconblockroot = wb.blockroot
+		LEFT JOIN validators val ON val.validatorindex = lv.validatorindex
+		GROUP BY wb.slot, wb.blockroot, wb.parentroot, wb.proposer, wb.status
+		ORDER BY wb.slot, wb.blockroot`, startSlot, endSlot, utils.Config.Chain.Phase0.SlotsPerEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &types.BlockTree{
+		StartSlot:     startSlot,
+		EndSlot:       endSlot,
+		Blocks:        make([]*types.BlockTreeNode, len(rows)),
+		OrphanedForks: []*types.BlockTreeFork{},
+	}
+	nodesByRoot := make(map[string]*types.BlockTreeNode, len(rows))
+	for i, r := range rows {
+		node := &types.BlockTreeNode{
+			Slot:                  r.Slot,
+			BlockRoot:             fmt.Sprintf("0x%x", r.BlockRoot),
+			ParentRoot:            fmt.Sprintf("0x%x", r.ParentRoot),
+			Proposer:              r.Proposer,
+			Status:                "canonical",
+			Votes:                 r.Votes,
+			VotesEffectiveBalance: r.VotesEffectiveBalance,
+		}
+		if r.Status == "3" {
+			node.Status = "orphaned"
+		}
+		tree.Blocks[i] = node
+		nodesByRoot[node.BlockRoot] = node
+	}
+
+	// the blocks are ordered by slot, children always come after their parent so the weights can be propagated to the
+	// parents in reverse
+	for i := len(tree.Blocks) - 1; i >= 0; i-- {
+		node := tree.Blocks[i]
+		node.Weight += node.VotesEffectiveBalance
+		if parent, exists := nodesByRoot[node.ParentRoot]; exists {
+			parent.Weight += node.Weight
+		}
+	}
+
+	forksByRoot := map[string]*types.BlockTreeFork{}
+	forkRootOf := map[string]string{}
+	for _, node := range tree.Blocks {
+		if node.Status != "orphaned" {
+			continue
+		}
+		parent, exists := nodesByRoot[node.ParentRoot]
+		if exists && parent.Status == "orphaned" {
+			forkRoot := forkRootOf[parent.BlockRoot]
+			forkRootOf[node.BlockRoot] = forkRoot
+			fork := forksByRoot[forkRoot]
+			fork.Blocks++
+			if node.Slot > fork.EndSlot {
+				fork.EndSlot = node.Slot
+			}
+			continue
+		}
+		fork := &types.BlockTreeFork{
+			Root:      node.BlockRoot,
+			Parent:    node.ParentRoot,
+			StartSlot: node.Slot,
+			EndSlot:   node.Slot,
+			Blocks:    1,
+			Weight:    node.Weight,
+		}
+		forksByRoot[node.BlockRoot] = fork
+		forkRootOf[node.BlockRoot] = node.BlockRoot
+		tree.OrphanedForks = append(tree.OrphanedForks, fork)
+	}
+	return tree, nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SaveBLSChangeSubmission saves a bls to execution change that has been broadcast through the explorer, a later
+// submission for the same validator replaces the previous one
+func SaveBLSChangeSubmission(ctx context.Context, validatorindex uint64, pubkey, address, signature []byte) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO bls_change_submissions (validatorindex, pubkey, address, signature, submitted_ts)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (validatorindex) DO UPDATE SET
+			pubkey       = excluded.pubkey,
+			address      = excluded.address,
+			signature    = excluded.signature,
+			submitted_ts = excluded.submitted_ts`, validatorindex, pubkey, address, signature, time.Now())
+	return err
+}
+
+// GetBLSChanges returns the changes submitted through the explorer with the slot of the block that included them and
+// whether the credentials of the validator have been changed, all submissions are returned if no validators are given
+func GetBLSChanges(ctx context.Context, validators []uint64, limit int) ([]*types.BLSChange, error) {
+	changes := []*types.BLSChange{}
+	err := DB.SelectContext(ctx, &changes, `
+		SELECT
+			s.validatorindex,
+			s.address,
+			s.submitted_ts,
+			COALESCE(bc.block_slot, 0) AS included_slot,
+			COALESCE(get_byte(v.withdrawalcredentials, 0) = 1, false) AS completed
+		FROM bls_change_submissions s
+		LEFT JOIN validators v ON v.validatorindex = s.validatorindex
+		LEFT JOIN LATERAL (
+			SELECT blocks_bls_change.block_slot
+			FROM blocks_bls_change
+			INNER JOIN blocks ON blocks.slot = blocks_bls_change.block_slot AND blocks.blockroot = blocks_bls_change.block_root AND blocks.status = '1'
+			WHERE blocks_bls_change.validatorindex = s.validatorindex
+			ORDER BY blocks_bls_change.block_slot DESC
+			LIMIT 1
+		) bc ON true
+		WHERE cardinality($1::int[]) = 0 OR s.validatorindex = ANY($1)
+		ORDER BY s.submitted_ts DESC
+		LIMIT $2`, pq.Array(validators), limit)
+	return changes, err
+}
+
+// GetValidatorsWithdrawalCredentials returns the withdrawal credentials of the validators by their index
+func GetValidatorsWithdrawalCredentials(ctx context.Context, validators []uint64) (map[uint64][]byte, error) {
+	rows := []struct {
+		Validatorindex        uint64 `db:"validatorindex"`
+		WithdrawalCredentials []byte `db:"withdrawalcredentials"`
+	}{}
+	err := DB.SelectContext(ctx, &rows, "SELECT validatorindex, withdrawalcredentials FROM validators WHERE validatorindex = ANY($1)", pq.Array(validators))
+	if err != nil {
+		return nil, err
+	}
+	credentials := make(map[uint64][]byte, len(rows))
+	for _, r := range rows {
+		credentials[r.Validatorindex] = r.WithdrawalCredentials
+	}
+	return credentials, nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MustBootstrapSchema creates the schema of the sql file in the explorer and the frontend database if they do not
+// contain the validators respectively the users table yet, databases with an existing schema are not touched
+func MustBootstrapSchema(path string) {
+	if path == "" {
+		path = "tables.sql"
+	}
+	err := bootstrapSchema(DB, "validators", path)
+	if err != nil {
+		logger.Fatalf("error bootstrapping schema of the explorer database: %v", err)
+	}
+	err = bootstrapSchema(FrontendDB, "users", path)
+	if err != nil {
+		logger.Fatalf("error bootstrapping schema of the frontend database: %v", err)
+	}
+}
+
+func bootstrapSchema(db *sqlx.DB, table, path string) error {
+	var exists bool
+	err := db.Get(&exists, "SELECT to_regclass($1) IS NOT NULL", "public."+table)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	schema, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading schema %v: %w", path, err)
+	}
+	// without arguments the statements of the file are sent in one simple query
+	_, err = db.Exec(string(schema))
+	if err != nil {
+		return fmt.Errorf("error applying schema %v: %w", path, err)
+	}
+	logger.Infof("created schema %v in a database without %v table", path, table)
+	return nil
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// The methods a BulkWriter can write rows with
+const (
+	BulkWriteMethodInsert = "insert"
+	BulkWriteMethodCopy   = "copy"
+)
+
+// BulkTx is a transaction that BulkWriters can write rows with COPY in. COPY is not available via database/sql, so the
+// transaction is bound to a dedicated connection the pgx connection is taken from.
+type BulkTx struct {
+	*sql.Tx
+	conn *sql.Conn
+}
+
+// BeginBulkTx starts a transaction on a dedicated connection of the pool
+func BeginBulkTx(ctx context.Context, pool *sqlx.DB) (*BulkTx, error) {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &BulkTx{Tx: tx, conn: conn}, nil
+}
+
+// Commit commits the transaction and returns the connection to the pool
+func (tx *BulkTx) Commit() error {
+	err := tx.Tx.Commit()
+	tx.conn.Close()
+	return err
+}
+
+// Rollback aborts the transaction and returns the connection to the pool, it can be deferred as it is a no-op after
+// Commit
+func (tx *BulkTx) Rollback() error {
+	err := tx.Tx.Rollback()
+	tx.conn.Close()
+	return err
+}
+
+// copyFrom runs f with the pgx connection of the transaction
+func (tx *BulkTx) copyFrom(ctx context.Context, f func(conn *pgx.Conn) error) error {
+	return tx.conn.Raw(func(driverConn interface{}) error {
+		// the driver connection can be wrapped, e.g. by the tracing driver
+		for {
+			unwrapper, ok := driverConn.(interface{ Unwrap() driver.Conn })
+			if !ok {
+				break
+			}
+			driverConn = unwrapper.Unwrap()
+		}
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("error writing rows with copy: unsupported driver connection %T", driverConn)
+		}
+		return f(stdlibConn.Conn())
+	})
+}
+
+// BulkWriter upserts rows into a table. Rows conflicting on ConflictKeys update all other columns, unless OnConflict
+// overrides the conflict clause. Method and BatchSize are the defaults of the table, they can be overridden per table
+// via the bulkWriter config.
+//
+// The copy method copies the rows into a temporary table and merges them into the table with a single upsert, which is
+// considerably faster for large volumes. The insert method uses multi-row inserts.
+type BulkWriter struct {
+	Table        string
+	Columns      []string
+	ConflictKeys []string
+	OnConflict   string
+	Method       string
+	BatchSize    int
+}
+
+// Write upserts the rows within the transaction
+func (w *BulkWriter) Write(ctx context.Context, tx *BulkTx, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if len(w.Columns) == 0 {
+		return fmt.Errorf("error upserting into %v: no columns given", w.Table)
+	}
+	for i, row := range rows {
+		if len(row) != len(w.Columns) {
+			return fmt.Errorf("error upserting into %v: row %v has %v values, expected %v", w.Table, i, len(row), len(w.Columns))
+		}
+	}
+
+	method, batchSize := w.config()
+	switch method {
+	case BulkWriteMethodCopy:
+		return w.copyRows(ctx, tx, rows, batchSize)
+	case BulkWriteMethodInsert:
+		return insertRows(ctx, tx.Tx, w.Table, w.Columns, w.onConflict(), rows, batchSize)
+	default:
+		return fmt.Errorf("error upserting into %v: unknown bulk write method %v", w.Table, method)
+	}
+}
+
+// config returns the method and batch size of the table, the batch size of inserts is capped by the parameter limit
+func (w *BulkWriter) config() (string, int) {
+	method := w.Method
+	batchSize := w.BatchSize
+	if cfg, exists := utils.Config.BulkWriter.Tables[w.Table]; exists {
+		if cfg.Method != "" {
+			method = cfg.Method
+		}
+		if cfg.BatchSize > 0 {
+			batchSize = cfg.BatchSize
+		}
+	}
+	if method == "" {
+		method = BulkWriteMethodInsert
+	}
+	if method == BulkWriteMethodInsert {
+		batchSize = insertBatchSize(batchSize, len(w.Columns))
+	}
+	return method, batchSize
+}
+
+// insertBatchSize returns the batch size of multi-row inserts, it defaults to 1000 rows and is capped by the parameter
+// limit
+func insertBatchSize(batchSize, nColumns int) int {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	if maxBatchSize := maxStatementParams / nColumns; batchSize > maxBatchSize {
+		batchSize = maxBatchSize
+	}
+	return batchSize
+}
+
+func (w *BulkWriter) onConflict() string {
+	if w.OnConflict != "" {
+		return w.OnConflict
+	}
+	return upsertConflictClause(w.Columns, w.ConflictKeys)
+}
+
+// copyRows copies the rows into a temporary table and merges them into the table, a batch size of 0 merges all rows at
+// once. The temporary table is dropped at the end of the transaction.
+func (w *BulkWriter) copyRows(ctx context.Context, tx *BulkTx, rows [][]interface{}, batchSize int) error {
+	tmpTable := "bulk_" + w.Table
+	columns := strings.Join(w.Columns, ", ")
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`create temp table if not exists %s on commit drop as select %s from %s with no data`, tmpTable, columns, w.Table))
+	if err != nil {
+		return fmt.Errorf("error creating temporary table of %v: %w", w.Table, err)
+	}
+
+	if batchSize <= 0 {
+		batchSize = len(rows)
+	}
+	for b := 0; b < len(rows); b += batchSize {
+		end := b + batchSize
+		if len(rows) < end {
+			end = len(rows)
+		}
+
+		err = tx.copyFrom(ctx, func(conn *pgx.Conn) error {
+			_, err := conn.CopyFrom(ctx, pgx.Identifier{tmpTable}, w.Columns, pgx.CopyFromRows(rows[b:end]))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error copying into %v: %w", w.Table, err)
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`insert into %s (%s) select %s from %s %s`, w.Table, columns, columns, tmpTable, w.onConflict()))
+		if err != nil {
+			return fmt.Errorf("error merging into %v: %w", w.Table, err)
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`truncate %s`, tmpTable))
+		if err != nil {
+			return fmt.Errorf("error truncating temporary table of %v: %w", w.Table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"time"
+)
+
+// WriteCohortAPRForDay aggregates the validator_stats of the day into the realized APR per activation month. The
+// validator_stats of the day have to be exported before.
+func WriteCohortAPRForDay(day uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_cohort_apr").Observe(time.Since(start).Seconds())
+	}()
+
+	epochsPerDay := utils.EpochsPerDay()
+	firstEpoch := day * epochsPerDay
+	lastEpoch := (day+1)*epochsPerDay - 1
+	secondsPerEpoch := utils.Config.Chain.SlotsPerEpoch * utils.Config.Chain.SecondsPerSlot
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM cohort_apr WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+
+	// the income is the balance change of the day minus the deposits, the apr extrapolates it to a year
+	_, err = tx.Exec(`
+		INSERT INTO cohort_apr (day, cohort, validators, effective_balance, income, apr)
+		SELECT
+			$1,
+			to_char(to_timestamp($4 + v.activationepoch * $5) AT TIME ZONE 'UTC', 'YYYY-MM') AS cohort,
+			COUNT(*),
+			SUM(vs.start_effective_balance),
+			SUM(vs.end_balance - vs.start_balance - COALESCE(vs.deposits_amount, 0)),
+			COALESCE(SUM(vs.end_balance - vs.start_balance - COALESCE(vs.deposits_amount, 0))::float / NULLIF(SUM(vs.start_effective_balance), 0) * 365, 0)
+		FROM validator_stats vs
+			INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+		WHERE vs.day = $1 AND v.activationepoch <= $2 AND v.exitepoch > $3 AND vs.start_effective_balance > 0
+		GROUP BY cohort`, day, firstEpoch, lastEpoch, utils.Config.Chain.GenesisTimestamp, secondsPerEpoch)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	logger.Infof("exported cohort apr for day %v, took %v", day, time.Since(start))
+	return nil
+}
+
+// GetCohortAPR returns the daily apr of all cohorts ordered by cohort and day
+func GetCohortAPR() ([]*types.CohortAPR, error) {
+	stats := []*types.CohortAPR{}
+	err := DB.Select(&stats, `
+		SELECT day, cohort, validators, effective_balance, income, apr
+		FROM cohort_apr
+		ORDER BY cohort, day`)
+	return stats, err
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CustomChartValidatorMetrics are the metrics of custom charts of a set of validators by their expression on validator_stats
+var CustomChartValidatorMetrics = map[string]string{
+	"balance":               "end_balance / 1e9",
+	"effective_balance":     "end_effective_balance / 1e9",
+	"income":                "(end_balance - start_balance - COALESCE(deposits_amount, 0)) / 1e9",
+	"missed_attestations":   "missed_attestations",
+	"orphaned_attestations": "orphaned_attestations",
+	"proposed_blocks":       "proposed_blocks",
+	"missed_blocks":         "missed_blocks",
+	"participated_sync":     "participated_sync",
+	"missed_sync":           "missed_sync",
+	"deposits_amount":       "deposits_amount / 1e9",
+}
+
+// CustomChartNetworkMetrics are the metrics of custom charts of the whole network by their expression on epochs
+var CustomChartNetworkMetrics = map[string]string{
+	"participation_rate": "globalparticipationrate * 100",
+	"validators":         "validatorscount",
+	"average_balance":    "averagevalidatorbalance / 1e9",
+	"total_balance":      "totalvalidatorbalance / 1e9",
+	"eligible_ether":     "eligibleether / 1e9",
+	"voted_ether":        "votedether / 1e9",
+	"blocks":             "blockscount",
+	"attestations":       "attestationscount",
+	"deposits":           "depositscount",
+	"voluntary_exits":    "voluntaryexitscount",
+}
+
+// CustomChartAggregations are the sql aggregate functions a custom chart can combine the values of a day with
+var CustomChartAggregations = map[string]string{
+	"sum": "SUM",
+	"avg": "AVG",
+	"min": "MIN",
+	"max": "MAX",
+}
+
+// GetCustomChartSeries executes the validated chart definition and returns the aggregated value of every day
+func GetCustomChartSeries(ctx context.Context, def *types.CustomChartDefinition) ([]*types.CustomChartPoint, error) {
+	aggregation, exists := CustomChartAggregations[def.Aggregation]
+	if !exists {
+		return nil, fmt.Errorf("invalid aggregation %v", def.Aggregation)
+	}
+
+	points := []*types.CustomChartPoint{}
+	switch def.Scope {
+	case "network":
+		expr, exists := CustomChartNetworkMetrics[def.Metric]
+		if !exists {
+			return nil, fmt.Errorf("invalid network metric %v", def.Metric)
+		}
+		epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+		err := DB.SelectContext(ctx, &points, fmt.Sprintf(`
+			SELECT epoch / $1 AS day, COALESCE(%s(%s), 0) AS value
+			FROM epochs
+			WHERE epoch >= $2 AND epoch < $3
+			GROUP BY day
+			ORDER BY day`, aggregation, expr), epochsPerDay, def.StartDay*epochsPerDay, (def.EndDay+1)*epochsPerDay)
+		return points, err
+	case "validators":
+		expr, exists := CustomChartValidatorMetrics[def.Metric]
+		if !exists {
+			return nil, fmt.Errorf("invalid validator metric %v", def.Metric)
+		}
+		err := DB.SelectContext(ctx, &points, fmt.Sprintf(`
+			SELECT day, COALESCE(%s(%s), 0) AS value
+			FROM validator_stats
+			WHERE validatorindex = ANY($1) AND day >= $2 AND day <= $3
+			GROUP BY day
+			ORDER BY day`, aggregation, expr), pq.Array(def.Validators), def.StartDay, def.EndDay)
+		return points, err
+	default:
+		return nil, fmt.Errorf("invalid scope %v", def.Scope)
+	}
+}
+
+// SaveCustomChart saves the chart definition of the user and returns the id it can be shared by
+func SaveCustomChart(ctx context.Context, userID uint64, def *types.CustomChartDefinition) (string, error) {
+	definition, err := json.Marshal(def)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 8)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+	_, err = FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_custom_charts (id, user_id, definition, created_ts)
+		VALUES ($1, $2, $3, $4)`, id, userID, string(definition), time.Now())
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetCustomChart returns the definition of the saved chart, nil is returned if there is no chart with the id
+func GetCustomChart(ctx context.Context, id string) (*types.CustomChartDefinition, error) {
+	var definition string
+	err := FrontendDB.GetContext(ctx, &definition, "SELECT definition FROM users_custom_charts WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	def := &types.CustomChartDefinition{}
+	err = json.Unmarshal([]byte(definition), def)
+	if err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// GetUserCustomChartCount returns the number of charts the user has saved
+func GetUserCustomChartCount(ctx context.Context, userID uint64) (int, error) {
+	count := 0
+	err := FrontendDB.GetContext(ctx, &count, "SELECT COUNT(*) FROM users_custom_charts WHERE user_id = $1", userID)
+	return count, err
+}
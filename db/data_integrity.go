@@ -0,0 +1,77 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DataIntegrityCheckMissingBlock      = "missing_block"
+	DataIntegrityCheckProposer          = "proposer"
+	DataIntegrityCheckAttestationsCount = "attestations_count"
+	DataIntegrityCheckBalance           = "balance"
+)
+
+// GetRandomFinalizedEpoch returns a random epoch that has been exported and finalized, ok is false if there is none
+func GetRandomFinalizedEpoch() (epoch uint64, ok bool, err error) {
+	var maxEpoch struct {
+		Epoch *uint64 `db:"epoch"`
+	}
+	err = DB.Get(&maxEpoch, "SELECT MAX(epoch) AS epoch FROM epochs WHERE finalized")
+	if err != nil || maxEpoch.Epoch == nil {
+		return 0, false, err
+	}
+	// sampling by offset instead of ORDER BY random() as the latter has to scan the whole epochs table
+	err = DB.Get(&epoch, "SELECT epoch FROM epochs WHERE finalized AND epoch >= floor(random() * $1) ORDER BY epoch LIMIT 1", *maxEpoch.Epoch+1)
+	if err != nil {
+		return 0, false, err
+	}
+	return epoch, true, nil
+}
+
+// GetDataIntegrityBlocks returns the proposed blocks of an epoch as stored in the db
+func GetDataIntegrityBlocks(epoch uint64) ([]*types.DataIntegrityBlock, error) {
+	blocks := []*types.DataIntegrityBlock{}
+	err := DB.Select(&blocks, "SELECT slot, blockroot, proposer, attestationscount FROM blocks WHERE epoch = $1 AND status = '1'", epoch)
+	return blocks, err
+}
+
+// GetValidatorBalancesAtEpoch returns the stored balances of the given validators at an epoch indexed by validatorindex
+func GetValidatorBalancesAtEpoch(epoch uint64, indices []uint64) (map[uint64]uint64, error) {
+	rows := []struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		Balance        uint64 `db:"balance"`
+	}{}
+	err := DB.Select(&rows, "SELECT validatorindex, balance FROM validator_balances_p WHERE week = $1 AND epoch = $2 AND validatorindex = ANY($3::int[])", epoch/1575, epoch, pq.Array(indices))
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[uint64]uint64, len(rows))
+	for _, row := range rows {
+		balances[row.ValidatorIndex] = row.Balance
+	}
+	return balances, nil
+}
+
+// SaveDataIntegrityIssues will save the mismatches found between the db and the beacon-node
+func SaveDataIntegrityIssues(issues []*types.DataIntegrityIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	valueStrings := make([]string, 0, len(issues))
+	valueArgs := make([]interface{}, 0, len(issues)*7)
+	now := time.Now()
+	for i, issue := range issues {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7))
+		valueArgs = append(valueArgs, issue.CheckType, issue.Epoch, issue.Slot, issue.ValidatorIndex, issue.DBValue, issue.NodeValue, now)
+	}
+	_, err := DB.Exec(fmt.Sprintf(`
+		INSERT INTO data_integrity_issues (check_type, epoch, slot, validatorindex, db_value, node_value, ts)
+		VALUES %s`, strings.Join(valueStrings, ",")), valueArgs...)
+	return err
+}
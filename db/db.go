@@ -2,8 +2,11 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"eth2-exporter/logging"
 	"eth2-exporter/metrics"
+	"eth2-exporter/tracing"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
@@ -19,6 +22,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/prysmaticlabs/go-bitfield"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 )
@@ -28,13 +32,56 @@ var DBPGX *pgxpool.Conn
 // DB is a pointer to the explorer-database
 var DB *sqlx.DB
 
-var logger = logrus.StandardLogger().WithField("module", "db")
+// IndexerDB is the pool the indexer writes the epochs and blocks with, it is DB unless a separate pool is configured
+var IndexerDB *sqlx.DB
 
-func mustInitDB(username, password, host, port, name string) *sqlx.DB {
-	dbConn, err := sqlx.Open("pgx", fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", username, password, host, port, name))
+// ExporterDB is the pool of the exporters running next to the indexer, it is DB unless a separate pool is configured
+var ExporterDB *sqlx.DB
+
+var logger = logging.NewLogger("db")
+
+func mustInitDB(username, password, host, port, name string, pool types.DatabasePool) *sqlx.DB {
+	dbConn, err := openDB(fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", username, password, host, port, name), pool)
 	if err != nil {
 		logger.Fatal(err)
 	}
+	return dbConn
+}
+
+// openDB opens a pool of the postgres database of the dsn with the driver and the settings of the configured pools
+func openDB(dsn string, pool types.DatabasePool) (*sqlx.DB, error) {
+	driverName := "pgx"
+	if tracing.Enabled() {
+		tracedDriverName, err := tracing.TracedDriverName(driverName)
+		if err != nil {
+			return nil, err
+		}
+		driverName = tracedDriverName
+	}
+	// queries executed with the context of an http request are counted towards its query budget
+	driverName, err := countedDriverName(driverName)
+	if err != nil {
+		return nil, err
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	switch pool.StatementCacheMode {
+	case "", "prepare":
+	case "describe":
+		dsn += separator + "statement_cache_mode=describe"
+	case "none":
+		dsn += separator + "statement_cache_capacity=0"
+	default:
+		return nil, fmt.Errorf("invalid statement cache mode %v, expected prepare, describe or none", pool.StatementCacheMode)
+	}
+	sqlConn, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	// the bind type of sqlx is derived from the driver name, the traced driver uses the placeholders of pgx
+	dbConn := sqlx.NewDb(sqlConn, "pgx")
 
 	// The golang sql driver does not properly implement PingContext
 	// therefore we use a timer to catch db connection timeouts
@@ -45,18 +92,60 @@ func mustInitDB(username, password, host, port, name string) *sqlx.DB {
 	}()
 	err = dbConn.Ping()
 	if err != nil {
-		logger.Fatal(err)
+		dbConnectionTimeout.Stop()
+		dbConn.Close()
+		return nil, err
 	}
 	dbConnectionTimeout.Stop()
 
 	dbConn.SetConnMaxIdleTime(time.Second * 30)
 	dbConn.SetConnMaxLifetime(time.Second * 60)
+	if pool.MaxOpenConns > 0 {
+		dbConn.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		dbConn.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxIdleTimeSeconds > 0 {
+		dbConn.SetConnMaxIdleTime(time.Second * time.Duration(pool.ConnMaxIdleTimeSeconds))
+	}
+	if pool.ConnMaxLifetimeSeconds > 0 {
+		dbConn.SetConnMaxLifetime(time.Second * time.Duration(pool.ConnMaxLifetimeSeconds))
+	}
 
-	return dbConn
+	return dbConn, nil
 }
 
+// MustInitDB opens the pool of the explorer-database and, if configured, the separate pools of the indexer and the
+// exporters
 func MustInitDB(username, password, host, port, name string) {
-	DB = mustInitDB(username, password, host, port, name)
+	DB = mustInitDB(username, password, host, port, name, utils.Config.Database.Pool)
+	IndexerDB = DB
+	if utils.Config.Database.IndexerPool != (types.DatabasePool{}) {
+		IndexerDB = mustInitDB(username, password, host, port, name, utils.Config.Database.IndexerPool)
+	}
+	ExporterDB = DB
+	if utils.Config.Database.ExporterPool != (types.DatabasePool{}) {
+		ExporterDB = mustInitDB(username, password, host, port, name, utils.Config.Database.ExporterPool)
+	}
+	if utils.Config.ValidatorPubkeyCache.RedisEndpoint != "" {
+		err := initValidatorPubkeysRedis(utils.Config.ValidatorPubkeyCache.RedisEndpoint)
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+}
+
+// DBPools returns the distinct pools of the explorer-database by name
+func DBPools() map[string]*sqlx.DB {
+	pools := map[string]*sqlx.DB{"default": DB}
+	if IndexerDB != DB {
+		pools["indexer"] = IndexerDB
+	}
+	if ExporterDB != DB {
+		pools["exporter"] = ExporterDB
+	}
+	return pools
 }
 
 func GetEth1Deposits(address string, length, start uint64) ([]*types.EthOneDepositsData, error) {
@@ -87,7 +176,7 @@ func GetEth1Deposits(address string, length, start uint64) ([]*types.EthOneDepos
 	return deposits, nil
 }
 
-func GetEth1DepositsJoinEth2Deposits(query string, length, start uint64, orderBy, orderDir string, latestEpoch, validatorOnlineThresholdSlot uint64) ([]*types.EthOneDepositsData, uint64, error) {
+func GetEth1DepositsJoinEth2Deposits(ctx context.Context, query string, length, start uint64, orderBy, orderDir string, latestEpoch, validatorOnlineThresholdSlot uint64) ([]*types.EthOneDepositsData, uint64, error) {
 	deposits := []*types.EthOneDepositsData{}
 
 	if orderDir != "desc" && orderDir != "asc" {
@@ -107,7 +196,7 @@ func GetEth1DepositsJoinEth2Deposits(query string, length, start uint64, orderBy
 	var err error
 	var totalCount uint64
 	if query != "" {
-		err = DB.Get(&totalCount, `
+		err = DB.GetContext(ctx, &totalCount, `
 			SELECT COUNT(*) FROM eth1_deposits as eth1
 			WHERE 
 				ENCODE(eth1.publickey::bytea, 'hex') LIKE LOWER($1)
@@ -116,14 +205,14 @@ func GetEth1DepositsJoinEth2Deposits(query string, length, start uint64, orderBy
 				OR ENCODE(tx_hash::bytea, 'hex') LIKE LOWER($1)
 				OR CAST(eth1.block_number AS text) LIKE LOWER($1)`, query+"%")
 	} else {
-		err = DB.Get(&totalCount, "SELECT COUNT(*) FROM eth1_deposits")
+		err = DB.GetContext(ctx, &totalCount, "SELECT COUNT(*) FROM eth1_deposits")
 	}
 	if err != nil && err != sql.ErrNoRows {
 		return nil, 0, err
 	}
 
 	if query != "" {
-		err = DB.Select(&deposits, fmt.Sprintf(`
+		err = DB.SelectContext(ctx, &deposits, fmt.Sprintf(`
 		SELECT 
 			eth1.tx_hash as tx_hash,
 			eth1.tx_input as tx_input,
@@ -165,7 +254,7 @@ func GetEth1DepositsJoinEth2Deposits(query string, length, start uint64, orderBy
 		LIMIT $1
 		OFFSET $2`, orderBy, orderDir), length, start, latestEpoch, validatorOnlineThresholdSlot, query+"%")
 	} else {
-		err = DB.Select(&deposits, fmt.Sprintf(`
+		err = DB.SelectContext(ctx, &deposits, fmt.Sprintf(`
 		SELECT 
 			eth1.tx_hash as tx_hash,
 			eth1.tx_input as tx_input,
@@ -217,7 +306,7 @@ func GetEth1DepositsCount() (uint64, error) {
 	return deposits, nil
 }
 
-func GetEth1DepositsLeaderboard(query string, length, start uint64, orderBy, orderDir string, latestEpoch uint64) ([]*types.EthOneDepositLeaderboardData, uint64, error) {
+func GetEth1DepositsLeaderboard(ctx context.Context, query string, length, start uint64, orderBy, orderDir string, latestEpoch uint64) ([]*types.EthOneDepositLeaderboardData, uint64, error) {
 	deposits := []*types.EthOneDepositLeaderboardData{}
 
 	if orderDir != "desc" && orderDir != "asc" {
@@ -247,7 +336,7 @@ func GetEth1DepositsLeaderboard(query string, length, start uint64, orderBy, ord
 	var err error
 	var totalCount uint64
 	if query != "" {
-		err = DB.Get(&totalCount, `
+		err = DB.GetContext(ctx, &totalCount, `
 		SELECT
 			COUNT(from_address)
 			FROM
@@ -262,13 +351,13 @@ func GetEth1DepositsLeaderboard(query string, length, start uint64, orderBy, ord
 				) as count
 		`, query+"%")
 	} else {
-		err = DB.Get(&totalCount, "SELECT COUNT(*) FROM (SELECT from_address FROM eth1_deposits GROUP BY from_address) as count")
+		err = DB.GetContext(ctx, &totalCount, "SELECT COUNT(*) FROM (SELECT from_address FROM eth1_deposits GROUP BY from_address) as count")
 	}
 	if err != nil && err != sql.ErrNoRows {
 		return nil, 0, err
 	}
 
-	err = DB.Select(&deposits, fmt.Sprintf(`
+	err = DB.SelectContext(ctx, &deposits, fmt.Sprintf(`
 		SELECT
 			eth1.from_address,
 			SUM(eth1.amount) as amount,
@@ -310,7 +399,7 @@ func GetEth1DepositsLeaderboard(query string, length, start uint64, orderBy, ord
 	return deposits, totalCount, nil
 }
 
-func GetEth2Deposits(query string, length, start uint64, orderBy, orderDir string) ([]*types.EthTwoDepositData, error) {
+func GetEth2Deposits(ctx context.Context, query string, length, start uint64, orderBy, orderDir string) ([]*types.EthTwoDepositData, error) {
 	deposits := []*types.EthTwoDepositData{}
 	// ENCODE(publickey::bytea, 'hex') LIKE $3 OR ENCODE(withdrawalcredentials::bytea, 'hex') LIKE $3 OR
 	if orderDir != "desc" && orderDir != "asc" {
@@ -328,7 +417,7 @@ func GetEth2Deposits(query string, length, start uint64, orderBy, orderDir strin
 	}
 
 	if query != "" {
-		err := DB.Select(&deposits, fmt.Sprintf(`
+		err := DB.SelectContext(ctx, &deposits, fmt.Sprintf(`
 			SELECT 
 				blocks_deposits.block_slot,
 				blocks_deposits.block_index,
@@ -347,7 +436,7 @@ func GetEth2Deposits(query string, length, start uint64, orderBy, orderDir strin
 			return nil, err
 		}
 	} else {
-		err := DB.Select(&deposits, fmt.Sprintf(`
+		err := DB.SelectContext(ctx, &deposits, fmt.Sprintf(`
 			SELECT 
 				blocks_deposits.block_slot,
 				blocks_deposits.block_index,
@@ -369,16 +458,16 @@ func GetEth2Deposits(query string, length, start uint64, orderBy, orderDir strin
 	return deposits, nil
 }
 
-func GetEth2DepositsCount(search string) (uint64, error) {
+func GetEth2DepositsCount(ctx context.Context, search string) (uint64, error) {
 	deposits := uint64(0)
 	var err error
 	if search == "" {
-		err = DB.Get(&deposits, `
+		err = DB.GetContext(ctx, &deposits, `
 		SELECT COUNT(*)
 		FROM blocks_deposits
 		INNER JOIN blocks ON blocks_deposits.block_root = blocks.blockroot AND blocks.status = '1'`)
 	} else {
-		err = DB.Get(&deposits, `
+		err = DB.GetContext(ctx, &deposits, `
 		SELECT COUNT(*)
 		FROM blocks_deposits
 		INNER JOIN blocks ON blocks_deposits.block_root = blocks.blockroot AND blocks.status = '1'
@@ -394,10 +483,10 @@ func GetEth2DepositsCount(search string) (uint64, error) {
 
 	return deposits, nil
 }
-func GetSlashingCount() (uint64, error) {
+func GetSlashingCount(ctx context.Context) (uint64, error) {
 	slashings := uint64(0)
 
-	err := DB.Get(&slashings, `
+	err := DB.GetContext(ctx, &slashings, `
 		SELECT SUM(count)
 		FROM 
 		(
@@ -419,9 +508,9 @@ func GetSlashingCount() (uint64, error) {
 }
 
 // GetLatestEpoch will return the latest epoch from the database
-func GetLatestEpoch() (uint64, error) {
+func GetLatestEpoch(ctx context.Context) (uint64, error) {
 	var epoch uint64
-	err := DB.Get(&epoch, "SELECT COALESCE(MAX(epoch), 0) FROM epochs")
+	err := DB.GetContext(ctx, &epoch, "SELECT COALESCE(MAX(epoch), 0) FROM epochs")
 
 	if err != nil {
 		return 0, fmt.Errorf("error retrieving latest epoch from DB: %v", err)
@@ -468,26 +557,10 @@ func GetBlocks(startEpoch, endEpoch uint64) ([]*types.MinimalBlock, error) {
 	return blocks, nil
 }
 
-// GetValidatorPublicKey will return the public key for a specific validator from the database
-func GetValidatorPublicKey(index uint64) ([]byte, error) {
-	var publicKey []byte
-	err := DB.Get(&publicKey, "SELECT pubkey FROM validators WHERE validatorindex = $1", index)
-
-	return publicKey, err
-}
-
-// GetValidatorIndex will return the validator-index for a public key from the database
-func GetValidatorIndex(publicKey []byte) (uint64, error) {
-	var index uint64
-	err := DB.Get(&index, "SELECT validatorindex FROM validators WHERE pubkey = $1", publicKey)
-
-	return index, err
-}
-
 // GetValidatorDeposits will return eth1- and eth2-deposits for a public key from the database
-func GetValidatorDeposits(publicKey []byte) (*types.ValidatorDeposits, error) {
+func GetValidatorDeposits(ctx context.Context, publicKey []byte) (*types.ValidatorDeposits, error) {
 	deposits := &types.ValidatorDeposits{}
-	err := DB.Select(&deposits.Eth1Deposits, `
+	err := DB.SelectContext(ctx, &deposits.Eth1Deposits, `
 		SELECT tx_hash, tx_input, tx_index, block_number, EXTRACT(epoch FROM block_ts)::INT as block_ts, from_address, publickey, withdrawal_credentials, amount, signature, merkletree_index, valid_signature
 		FROM eth1_deposits WHERE publickey = $1 ORDER BY block_number ASC`, publicKey)
 	if err != nil {
@@ -497,7 +570,7 @@ func GetValidatorDeposits(publicKey []byte) (*types.ValidatorDeposits, error) {
 		deposits.LastEth1DepositTs = deposits.Eth1Deposits[len(deposits.Eth1Deposits)-1].BlockTs
 	}
 
-	err = DB.Select(&deposits.Eth2Deposits, `
+	err = DB.SelectContext(ctx, &deposits.Eth2Deposits, `
 		SELECT blocks_deposits.* FROM blocks_deposits
 		INNER JOIN blocks ON (blocks_deposits.block_root = blocks.blockroot AND blocks.status = '1') OR (blocks_deposits.block_slot = 0 AND blocks_deposits.block_slot = blocks.slot AND blocks_deposits.publickey = $1)
 		WHERE blocks_deposits.publickey = $1`, publicKey)
@@ -507,6 +580,27 @@ func GetValidatorDeposits(publicKey []byte) (*types.ValidatorDeposits, error) {
 	return deposits, nil
 }
 
+// GetValidatorConsolidationTarget will return the index of the validator the validator has requested to be consolidated
+// into, nil is returned if there is no such request
+func GetValidatorConsolidationTarget(ctx context.Context, publicKey []byte) (*uint64, error) {
+	var target uint64
+	err := DB.GetContext(ctx, &target, `
+		SELECT validators.validatorindex
+		FROM blocks_consolidation_requests
+		INNER JOIN blocks ON blocks.slot = blocks_consolidation_requests.block_slot AND blocks.blockroot = blocks_consolidation_requests.block_root AND blocks.status = '1'
+		INNER JOIN validators ON validators.pubkey = blocks_consolidation_requests.target_pubkey
+		WHERE blocks_consolidation_requests.source_pubkey = $1 AND blocks_consolidation_requests.target_pubkey != $1
+		ORDER BY blocks_consolidation_requests.block_slot DESC
+		LIMIT 1`, publicKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
 // UpdateCanonicalBlocks will update the blocks for an epoch range in the database
 func UpdateCanonicalBlocks(startEpoch, endEpoch uint64, blocks []*types.MinimalBlock) error {
 	if len(blocks) == 0 {
@@ -517,7 +611,7 @@ func UpdateCanonicalBlocks(startEpoch, endEpoch uint64, blocks []*types.MinimalB
 		metrics.TaskDuration.WithLabelValues("db_update_canonical_blocks").Observe(time.Since(start).Seconds())
 	}()
 
-	tx, err := DB.Begin()
+	tx, err := IndexerDB.Begin()
 	if err != nil {
 		return fmt.Errorf("error starting db transactions: %v", err)
 	}
@@ -546,7 +640,7 @@ func SetBlockStatus(blocks []*types.CanonBlock) error {
 		return nil
 	}
 
-	tx, err := DB.Begin()
+	tx, err := IndexerDB.Begin()
 	if err != nil {
 		return fmt.Errorf("error starting db transactions: %v", err)
 	}
@@ -580,7 +674,7 @@ func SetBlockStatus(blocks []*types.CanonBlock) error {
 
 // SaveValidatorQueue will save the validator queue into the database
 func SaveValidatorQueue(validators *types.ValidatorQueue) error {
-	_, err := DB.Exec(`
+	_, err := IndexerDB.Exec(`
 		INSERT INTO queue (ts, entering_validators_count, exiting_validators_count)
 		VALUES (date_trunc('hour', now()), $1, $2)
 		ON CONFLICT (ts) DO UPDATE SET
@@ -598,7 +692,7 @@ func SaveBlock(block *types.Block) error {
 	}
 	blocksMap[block.Slot][fmt.Sprintf("%x", block.BlockRoot)] = block
 
-	tx, err := DB.Begin()
+	tx, err := IndexerDB.Begin()
 	if err != nil {
 		return fmt.Errorf("error starting db transactions: %v", err)
 	}
@@ -620,14 +714,16 @@ func SaveBlock(block *types.Block) error {
 }
 
 // SaveEpoch will stave the epoch data into the database
-func SaveEpoch(data *types.EpochData) error {
+func SaveEpoch(data *types.EpochData) (err error) {
 	start := time.Now()
+	ctx, span := tracing.StartSpan(context.Background(), "db.save_epoch", attribute.Int64("epoch", int64(data.Epoch)))
 	defer func() {
+		tracing.EndSpan(span, err)
 		metrics.TaskDuration.WithLabelValues("db_save_epoch").Observe(time.Since(start).Seconds())
 		logger.WithFields(logrus.Fields{"epoch": data.Epoch, "duration": time.Since(start)}).Info("completed saving epoch")
 	}()
 
-	tx, err := DB.Begin()
+	tx, err := BeginBulkTx(ctx, IndexerDB)
 	if err != nil {
 		return fmt.Errorf("error starting db transactions: %w", err)
 	}
@@ -636,7 +732,7 @@ func SaveEpoch(data *types.EpochData) error {
 	logger.WithFields(logrus.Fields{"chainEpoch": utils.TimeToEpoch(time.Now()), "exportEpoch": data.Epoch}).Infof("starting export of epoch %v", data.Epoch)
 
 	logger.Infof("exporting block data")
-	err = saveBlocks(data.Blocks, tx)
+	err = saveBlocks(data.Blocks, tx.Tx)
 	if err != nil {
 		logger.Fatalf("error saving blocks to db: %v", err)
 		return fmt.Errorf("error saving blocks to db: %w", err)
@@ -646,14 +742,14 @@ func SaveEpoch(data *types.EpochData) error {
 		logger.WithFields(logrus.Fields{"exportEpoch": data.Epoch, "chainEpoch": utils.TimeToEpoch(time.Now())}).Infof("skipping exporting validators because epoch is far behind head")
 	} else {
 		logger.Infof("exporting validators")
-		err = saveValidators(data, tx)
+		err = saveValidators(data, tx.Tx)
 		if err != nil {
 			return fmt.Errorf("error saving validators to db: %w", err)
 		}
 	}
 
 	logger.Infof("exporting proposal assignments data")
-	err = saveValidatorProposalAssignments(data.Epoch, data.ValidatorAssignmentes.ProposerAssignments, tx)
+	err = saveValidatorProposalAssignments(data.Epoch, data.ValidatorAssignmentes.ProposerAssignments, tx.Tx)
 	if err != nil {
 		return fmt.Errorf("error saving validator proposal assignments to db: %w", err)
 	}
@@ -664,8 +760,14 @@ func SaveEpoch(data *types.EpochData) error {
 		return fmt.Errorf("error saving validator attestation assignments to db: %w", err)
 	}
 
+	logger.Infof("exporting attestation bitfields")
+	err = saveAttestationBitfields(data, tx.Tx)
+	if err != nil {
+		return fmt.Errorf("error saving attestation bitfields to db: %w", err)
+	}
+
 	logger.Infof("exporting validator balance data")
-	err = saveValidatorBalances(data.Epoch, data.Validators, tx)
+	err = saveValidatorBalances(data.Epoch, personalModeValidators(data.Validators), tx)
 	if err != nil {
 		return fmt.Errorf("error saving validator balances to db: %w", err)
 	}
@@ -675,7 +777,7 @@ func SaveEpoch(data *types.EpochData) error {
 		logger.WithFields(logrus.Fields{"exportEpoch": data.Epoch, "chainEpoch": utils.TimeToEpoch(time.Now())}).Infof("skipping exporting recent validator balance because epoch is far behind head")
 	} else {
 		logger.Infof("exporting recent validator balance")
-		err = saveValidatorBalancesRecent(data.Epoch, data.Validators, tx)
+		err = saveValidatorBalancesRecent(data.Epoch, personalModeValidators(data.Validators), tx)
 		if err != nil {
 			return fmt.Errorf("error saving recent validator balances to db: %w", err)
 		}
@@ -760,10 +862,22 @@ func SaveEpoch(data *types.EpochData) error {
 		return fmt.Errorf("error executing save epoch statement: %w", err)
 	}
 
-	err = saveGraffitiwall(data.Blocks, tx)
+	if utils.Config.Timescale.Enabled {
+		err = saveTimescaleEpoch(data, validatorsCount, validatorBalanceAverage.Uint64(), tx)
+		if err != nil {
+			return fmt.Errorf("error saving epoch to timescale hypertables: %w", err)
+		}
+	}
+
+	err = saveGraffitiwall(data.Blocks, tx.Tx)
 	if err != nil {
 		return fmt.Errorf("error saving graffitiwall: %w", err)
 	}
+
+	err = saveAttestationAggregationStats(data.Epoch, data.Blocks, tx.Tx)
+	if err != nil {
+		return fmt.Errorf("error saving attestation aggregation stats: %w", err)
+	}
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("error committing db transaction: %w", err)
@@ -843,7 +957,12 @@ func saveValidators(data *types.EpochData, tx *sql.Tx) error {
 		metrics.TaskDuration.WithLabelValues("db_save_validators").Observe(time.Since(start).Seconds())
 	}()
 
-	validators := data.Validators
+	validators := personalModeValidators(data.Validators)
+	pubkeys := make([]validatorPubkeyRow, len(validators))
+	for i, v := range validators {
+		pubkeys[i] = validatorPubkeyRow{Index: v.Index, Pubkey: v.PublicKey}
+	}
+	addValidatorPubkeys(pubkeys)
 
 	validatorsByIndex := make(map[uint64]*types.Validator, len(data.Validators))
 	for _, v := range data.Validators {
@@ -877,7 +996,7 @@ func saveValidators(data *types.EpochData, tx *sql.Tx) error {
 	}
 
 	var latestBlock uint64
-	err := DB.Get(&latestBlock, "SELECT COALESCE(MAX(slot), 0) FROM blocks WHERE status = '1'")
+	err := IndexerDB.Get(&latestBlock, "SELECT COALESCE(MAX(slot), 0) FROM blocks WHERE status = '1'")
 	if err != nil {
 		return err
 	}
@@ -1003,6 +1122,20 @@ func saveValidators(data *types.EpochData, tx *sql.Tx) error {
 	return nil
 }
 
+// personalModeValidators returns the validators that are stored, all validators unless the personal mode is enabled
+func personalModeValidators(validators []*types.Validator) []*types.Validator {
+	if !utils.Config.Frontend.PersonalMode.Enabled {
+		return validators
+	}
+	res := make([]*types.Validator, 0, len(utils.Config.Frontend.PersonalMode.Validators))
+	for _, v := range validators {
+		if utils.PersonalModeWatched(v.Index) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 func saveValidatorProposalAssignments(epoch uint64, assignments map[uint64]uint64, tx *sql.Tx) error {
 	start := time.Now()
 	defer func() {
@@ -1028,121 +1161,86 @@ func saveValidatorProposalAssignments(epoch uint64, assignments map[uint64]uint6
 	return nil
 }
 
-func saveValidatorAttestationAssignments(epoch uint64, assignments map[string]uint64, tx *sql.Tx) error {
+// attestationAssignmentsWriter writes the attestation assignments of an epoch, all assignments of an epoch are merged
+// at once by default
+var attestationAssignmentsWriter = &BulkWriter{
+	Table:        "attestation_assignments_p",
+	Columns:      []string{"epoch", "validatorindex", "attesterslot", "committeeindex", "status", "week"},
+	ConflictKeys: []string{"validatorindex", "week", "epoch"},
+	OnConflict:   "ON CONFLICT (validatorindex, week, epoch) DO UPDATE SET attesterslot = EXCLUDED.attesterslot, committeeindex = EXCLUDED.committeeindex",
+	Method:       BulkWriteMethodCopy,
+}
+
+func saveValidatorAttestationAssignments(epoch uint64, assignments map[string]uint64, tx *BulkTx) error {
 	start := time.Now()
 	defer func() {
 		metrics.TaskDuration.WithLabelValues("db_save_attestation_assignments").Observe(time.Since(start).Seconds())
 	}()
 
-	//args := make([][]interface{}, 0, len(assignments))
-	argsWeek := make([][]interface{}, 0, len(assignments))
+	rows := make([][]interface{}, 0, len(assignments))
 	for key, validator := range assignments {
-		keySplit := strings.Split(key, "-")
-		//args = append(args, []interface{}{epoch, validator, keySplit[0], keySplit[1], 0})
-		argsWeek = append(argsWeek, []interface{}{epoch, validator, keySplit[0], keySplit[1], 0, epoch / 1575})
-	}
-
-	batchSize := 10000
-
-	for b := 0; b < len(argsWeek); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(argsWeek) < end {
-			end = len(argsWeek)
+		if !utils.PersonalModeWatched(validator) {
+			continue
 		}
-
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*6)
-		for i, v := range argsWeek[start:end] {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6))
-			valueArgs = append(valueArgs, v...)
+		keySplit := strings.Split(key, "-")
+		attesterSlot, err := strconv.ParseUint(keySplit[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing attester slot of assignment %v: %w", key, err)
 		}
-		stmt := fmt.Sprintf(`
-		INSERT INTO attestation_assignments_p (epoch, validatorindex, attesterslot, committeeindex, status, week)
-		VALUES %s
-		ON CONFLICT (validatorindex, week, epoch) DO UPDATE SET attesterslot = EXCLUDED.attesterslot, committeeindex = EXCLUDED.committeeindex`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
+		committeeIndex, err := strconv.ParseUint(keySplit[1], 10, 64)
 		if err != nil {
-			return fmt.Errorf("error executing save validator attestation assignment statement: %v", err)
+			return fmt.Errorf("error parsing committee index of assignment %v: %w", key, err)
 		}
+		rows = append(rows, []interface{}{epoch, validator, attesterSlot, committeeIndex, 0, epoch / 1575})
 	}
 
+	err := attestationAssignmentsWriter.Write(context.Background(), tx, rows)
+	if err != nil {
+		return fmt.Errorf("error executing save validator attestation assignment statement: %v", err)
+	}
 	return nil
 }
 
-func saveValidatorBalances(epoch uint64, validators []*types.Validator, tx *sql.Tx) error {
+var validatorBalancesWriter = &BulkWriter{
+	Table:        "validator_balances_p",
+	Columns:      []string{"epoch", "validatorindex", "balance", "effectivebalance", "week"},
+	ConflictKeys: []string{"epoch", "validatorindex", "week"},
+	Method:       BulkWriteMethodCopy,
+}
+
+func saveValidatorBalances(epoch uint64, validators []*types.Validator, tx *BulkTx) error {
 	start := time.Now()
 	defer func() {
 		metrics.TaskDuration.WithLabelValues("db_save_validator_balances").Observe(time.Since(start).Seconds())
 	}()
 
-	batchSize := 10000
-
-	for b := 0; b < len(validators); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(validators) < end {
-			end = len(validators)
-		}
-
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*5)
-		for i, v := range validators[start:end] {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*5+1, i*5+2, i*5+3, i*5+4, i*5+5))
-			valueArgs = append(valueArgs, epoch)
-			valueArgs = append(valueArgs, v.Index)
-			valueArgs = append(valueArgs, v.Balance)
-			valueArgs = append(valueArgs, v.EffectiveBalance)
-			valueArgs = append(valueArgs, epoch/1575)
-		}
-		stmt := fmt.Sprintf(`
-		INSERT INTO validator_balances_p (epoch, validatorindex, balance, effectivebalance, week)
-		VALUES %s
-		ON CONFLICT (epoch, validatorindex, week) DO UPDATE SET
-			balance          = EXCLUDED.balance,
-			effectivebalance = EXCLUDED.effectivebalance`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return err
-		}
+	rows := make([][]interface{}, 0, len(validators))
+	for _, v := range validators {
+		rows = append(rows, []interface{}{epoch, v.Index, v.Balance, v.EffectiveBalance, epoch / 1575})
 	}
+	return validatorBalancesWriter.Write(context.Background(), tx, rows)
+}
 
-	return nil
+var validatorBalancesRecentWriter = &BulkWriter{
+	Table:        "validator_balances_recent",
+	Columns:      []string{"epoch", "validatorindex", "balance"},
+	ConflictKeys: []string{"epoch", "validatorindex"},
+	Method:       BulkWriteMethodCopy,
 }
 
-func saveValidatorBalancesRecent(epoch uint64, validators []*types.Validator, tx *sql.Tx) error {
+func saveValidatorBalancesRecent(epoch uint64, validators []*types.Validator, tx *BulkTx) error {
 	start := time.Now()
 	defer func() {
 		metrics.TaskDuration.WithLabelValues("db_save_validator_balances_recent").Observe(time.Since(start).Seconds())
 	}()
 
-	batchSize := 10000
-
-	for b := 0; b < len(validators); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(validators) < end {
-			end = len(validators)
-		}
-
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*3)
-		for i, v := range validators[start:end] {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3))
-			valueArgs = append(valueArgs, epoch)
-			valueArgs = append(valueArgs, v.Index)
-			valueArgs = append(valueArgs, v.Balance)
-		}
-		stmt := fmt.Sprintf(`
-			INSERT INTO validator_balances_recent (epoch, validatorindex, balance)
-			VALUES %s
-			ON CONFLICT (epoch, validatorindex) DO UPDATE SET
-				balance = EXCLUDED.balance`, strings.Join(valueStrings, ","))
-
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return err
-		}
+	rows := make([][]interface{}, 0, len(validators))
+	for _, v := range validators {
+		rows = append(rows, []interface{}{epoch, v.Index, v.Balance})
+	}
+	err := validatorBalancesRecentWriter.Write(context.Background(), tx, rows)
+	if err != nil {
+		return err
 	}
 
 	if epoch > 10 {
@@ -1162,8 +1260,8 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 	}()
 
 	stmtBlock, err := tx.Prepare(`
-		INSERT INTO blocks (epoch, slot, blockroot, parentroot, stateroot, signature, randaoreveal, graffiti, graffiti_text, eth1data_depositroot, eth1data_depositcount, eth1data_blockhash, syncaggregate_bits, syncaggregate_signature, syncaggregate_participation, proposerslashingscount, attesterslashingscount, attestationscount, depositscount, voluntaryexitscount, proposer, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+		INSERT INTO blocks (epoch, slot, blockroot, parentroot, stateroot, signature, randaoreveal, graffiti, graffiti_text, eth1data_depositroot, eth1data_depositcount, eth1data_blockhash, syncaggregate_bits, syncaggregate_signature, syncaggregate_participation, proposerslashingscount, attesterslashingscount, attestationscount, depositscount, voluntaryexitscount, proposer, status, exec_block_hash, exec_block_number, exec_fee_recipient, exec_gas_used, exec_gas_limit, exec_base_fee_per_gas, exec_transactions_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)
 		ON CONFLICT (slot, blockroot) DO NOTHING`)
 	if err != nil {
 		return err
@@ -1215,6 +1313,33 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 	}
 	defer stmtVoluntaryExits.Close()
 
+	stmtBLSChanges, err := tx.Prepare(`
+		INSERT INTO blocks_bls_change (block_slot, block_index, block_root, validatorindex, signature, pubkey, address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (block_slot, block_index) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtBLSChanges.Close()
+
+	stmtConsolidationRequests, err := tx.Prepare(`
+		INSERT INTO blocks_consolidation_requests (block_slot, block_index, block_root, source_address, source_pubkey, target_pubkey)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (block_slot, block_index) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtConsolidationRequests.Close()
+
+	stmtWithdrawals, err := tx.Prepare(`
+		INSERT INTO blocks_withdrawals (block_slot, block_index, block_root, withdrawalindex, validatorindex, address, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (block_slot, block_index) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtWithdrawals.Close()
+
 	stmtProposalAssignments, err := tx.Prepare(`
 		INSERT INTO proposal_assignments (epoch, validatorindex, proposerslot, status)
 		VALUES ($1, $2, $3, $4)
@@ -1244,7 +1369,7 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 			blockLog := logger.WithFields(logrus.Fields{"slot": b.Slot, "blockRoot": fmt.Sprintf("%x", b.BlockRoot)})
 
 			var dbBlockRootHash []byte
-			err := DB.Get(&dbBlockRootHash, "SELECT blockroot FROM blocks WHERE slot = $1 and blockroot = $2", b.Slot, b.BlockRoot)
+			err := IndexerDB.Get(&dbBlockRootHash, "SELECT blockroot FROM blocks WHERE slot = $1 and blockroot = $2", b.Slot, b.BlockRoot)
 			if err == nil && bytes.Compare(dbBlockRootHash, b.BlockRoot) == 0 {
 				blockLog.Infof("skipping export of block as it is already present in the db")
 				continue
@@ -1276,7 +1401,20 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 				syncAggParticipation = b.SyncAggregate.SyncAggregateParticipation
 				// blockLog = blockLog.WithField("syncParticipation", b.SyncAggregate.SyncAggregateParticipation)
 			}
-			_, err = stmtBlock.Exec(b.Slot/utils.Config.Chain.SlotsPerEpoch, b.Slot, b.BlockRoot, b.ParentRoot, b.StateRoot, b.Signature, b.RandaoReveal, b.Graffiti, utils.GraffitiToSring(b.Graffiti), b.Eth1Data.DepositRoot, b.Eth1Data.DepositCount, b.Eth1Data.BlockHash, syncAggBits, syncAggSig, syncAggParticipation, len(b.ProposerSlashings), len(b.AttesterSlashings), len(b.Attestations), len(b.Deposits), len(b.VoluntaryExits), b.Proposer, strconv.FormatUint(b.Status, 10))
+			// execution payload columns stay NULL for blocks before the merge
+			var execBlockHash, execFeeRecipient []byte
+			var execBlockNumber, execGasUsed, execGasLimit, execBaseFeePerGas *uint64
+			execTransactionsCount := uint64(0)
+			if b.ExecutionPayload != nil {
+				execBlockHash = b.ExecutionPayload.BlockHash
+				execBlockNumber = &b.ExecutionPayload.BlockNumber
+				execFeeRecipient = b.ExecutionPayload.FeeRecipient
+				execGasUsed = &b.ExecutionPayload.GasUsed
+				execGasLimit = &b.ExecutionPayload.GasLimit
+				execBaseFeePerGas = &b.ExecutionPayload.BaseFeePerGas
+				execTransactionsCount = b.ExecutionPayload.TransactionsCount
+			}
+			_, err = stmtBlock.Exec(b.Slot/utils.Config.Chain.SlotsPerEpoch, b.Slot, b.BlockRoot, b.ParentRoot, b.StateRoot, b.Signature, b.RandaoReveal, b.Graffiti, utils.GraffitiToSring(b.Graffiti), b.Eth1Data.DepositRoot, b.Eth1Data.DepositCount, b.Eth1Data.BlockHash, syncAggBits, syncAggSig, syncAggParticipation, len(b.ProposerSlashings), len(b.AttesterSlashings), len(b.Attestations), len(b.Deposits), len(b.VoluntaryExits), b.Proposer, strconv.FormatUint(b.Status, 10), execBlockHash, execBlockNumber, execFeeRecipient, execGasUsed, execGasLimit, execBaseFeePerGas, execTransactionsCount)
 			if err != nil {
 				return fmt.Errorf("error executing stmtBlocks for block %v: %w", b.Slot, err)
 			}
@@ -1315,26 +1453,29 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 					return fmt.Errorf("error getting sync_committee participants: bitLen != valLen: %v != %v", bitLen, valLen)
 				}
 				nArgs := 4
-				valueStrings := make([]string, valLen)
-				valueArgs := make([]interface{}, valLen*nArgs)
+				valueStrings := make([]string, 0, valLen)
+				valueArgs := make([]interface{}, 0, valLen*nArgs)
 				for i, valIndex := range b.SyncAggregate.SyncCommitteeValidators {
-					valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d, $%d)", i*nArgs+1, i*nArgs+2, i*nArgs+3, i*nArgs+4)
-					valueArgs[i*nArgs] = b.Slot
-					valueArgs[i*nArgs+1] = valIndex
+					if !utils.PersonalModeWatched(valIndex) {
+						continue
+					}
+					n := len(valueStrings)
+					valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", n*nArgs+1, n*nArgs+2, n*nArgs+3, n*nArgs+4))
+					status := 2
 					if utils.BitAtVector(b.SyncAggregate.SyncCommitteeBits, i) {
-						valueArgs[i*nArgs+2] = 1
-					} else {
-						valueArgs[i*nArgs+2] = 2
+						status = 1
 					}
-					valueArgs[i*nArgs+3] = utils.WeekOfSlot(b.Slot)
+					valueArgs = append(valueArgs, b.Slot, valIndex, status, utils.WeekOfSlot(b.Slot))
 				}
-				stmt := fmt.Sprintf(`
-					INSERT INTO sync_assignments_p (slot, validatorindex, status, week)
-					VALUES %s
-					ON CONFLICT (slot, validatorindex, week) DO UPDATE SET status = excluded.status`, strings.Join(valueStrings, ","))
-				_, err := tx.Exec(stmt, valueArgs...)
-				if err != nil {
-					return fmt.Errorf("error executing sync_assignments insert for block %v: %w", b.Slot, err)
+				if len(valueStrings) > 0 {
+					stmt := fmt.Sprintf(`
+						INSERT INTO sync_assignments_p (slot, validatorindex, status, week)
+						VALUES %s
+						ON CONFLICT (slot, validatorindex, week) DO UPDATE SET status = excluded.status`, strings.Join(valueStrings, ","))
+					_, err := tx.Exec(stmt, valueArgs...)
+					if err != nil {
+						return fmt.Errorf("error executing sync_assignments insert for block %v: %w", b.Slot, err)
+					}
 				}
 			}
 			blockLog.WithField("duration", time.Since(t)).Tracef("sync_assignments_p")
@@ -1345,6 +1486,9 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 				attestingValidators := make([]string, 0, 20000)
 
 				for _, validator := range a.Attesters {
+					if !utils.PersonalModeWatched(validator) {
+						continue
+					}
 					attestationAssignmentsArgsWeek = append(attestationAssignmentsArgsWeek, []interface{}{a.Data.Slot / utils.Config.Chain.SlotsPerEpoch, validator, a.Data.Slot, a.Data.CommitteeIndex, 1, b.Slot, a.Data.Slot / utils.Config.Chain.SlotsPerEpoch / 1575})
 					attestingValidators = append(attestingValidators, strconv.FormatUint(validator, 10))
 				}
@@ -1379,6 +1523,11 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 				// 	return fmt.Errorf("error executing stmtValidatorsLastAttestationSlot for block %v: %w", b.Slot, err)
 				// }
 
+				// in personal mode only the aggregates of the watched validators are stored, the attestation correctness
+				// of their assignments is evaluated from them
+				if utils.Config.Frontend.PersonalMode.Enabled && len(attestingValidators) == 0 {
+					continue
+				}
 				_, err = stmtAttestations.Exec(b.Slot, i, b.BlockRoot, bitfield.Bitlist(a.AggregationBits).Bytes(), pq.Array(a.Attesters), a.Signature, a.Data.Slot, a.Data.CommitteeIndex, a.Data.BeaconBlockRoot, a.Data.Source.Epoch, a.Data.Source.Root, a.Data.Target.Epoch, a.Data.Target.Root)
 				if err != nil {
 					return fmt.Errorf("error executing stmtAttestations for block %v: %w", b.Slot, err)
@@ -1405,6 +1554,35 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sql.Tx) error {
 			blockLog.WithField("duration", time.Since(t)).Tracef("exits")
 			t = time.Now()
 
+			for i, bc := range b.BLSToExecutionChanges {
+				_, err := stmtBLSChanges.Exec(b.Slot, i, b.BlockRoot, bc.Validatorindex, bc.Signature, bc.BlsPubkey, bc.Address)
+				if err != nil {
+					return fmt.Errorf("error executing stmtBLSChanges for block %v: %w", b.Slot, err)
+				}
+			}
+			blockLog.WithField("duration", time.Since(t)).Tracef("bls changes")
+			t = time.Now()
+
+			for i, cr := range b.ConsolidationRequests {
+				_, err := stmtConsolidationRequests.Exec(b.Slot, i, b.BlockRoot, cr.SourceAddress, cr.SourcePubkey, cr.TargetPubkey)
+				if err != nil {
+					return fmt.Errorf("error executing stmtConsolidationRequests for block %v: %w", b.Slot, err)
+				}
+			}
+			blockLog.WithField("duration", time.Since(t)).Tracef("consolidation requests")
+			t = time.Now()
+
+			if b.ExecutionPayload != nil {
+				for i, w := range b.ExecutionPayload.Withdrawals {
+					_, err := stmtWithdrawals.Exec(b.Slot, i, b.BlockRoot, w.Index, w.ValidatorIndex, w.Address, w.Amount)
+					if err != nil {
+						return fmt.Errorf("error executing stmtWithdrawals for block %v: %w", b.Slot, err)
+					}
+				}
+			}
+			blockLog.WithField("duration", time.Since(t)).Tracef("withdrawals")
+			t = time.Now()
+
 			_, err = stmtProposalAssignments.Exec(b.Slot/utils.Config.Chain.SlotsPerEpoch, b.Proposer, b.Slot, b.Status)
 			if err != nil {
 				return fmt.Errorf("error executing stmtProposalAssignments for block %v: %w", b.Slot, err)
@@ -1426,7 +1604,7 @@ func UpdateEpochStatus(stats *types.ValidatorParticipation) error {
 		metrics.TaskDuration.WithLabelValues("db_update_epochs_status").Observe(time.Since(start).Seconds())
 	}()
 
-	_, err := DB.Exec(`
+	_, err := IndexerDB.Exec(`
 		UPDATE epochs SET
 			finalized = $1,
 			eligibleether = $2,
@@ -1440,7 +1618,7 @@ func UpdateEpochStatus(stats *types.ValidatorParticipation) error {
 
 // UpdateEpochFinalization will update finalized-flag of all epochs before the last finalized epoch
 func UpdateEpochFinalization() error {
-	_, err := DB.Exec(`UPDATE epochs SET finalized = true WHERE epoch < (SELECT MAX(epoch) FROM epochs WHERE finalized = true)`)
+	_, err := IndexerDB.Exec(`UPDATE epochs SET finalized = true WHERE epoch < (SELECT MAX(epoch) FROM epochs WHERE finalized = true)`)
 	return err
 }
 
@@ -1458,8 +1636,8 @@ func GetActiveValidatorCount() (uint64, error) {
 	return count, err
 }
 
-func GetValidatorNames() (map[uint64]string, error) {
-	rows, err := DB.Query(`
+func GetValidatorNames(ctx context.Context) (map[uint64]string, error) {
+	rows, err := DB.QueryContext(ctx, `
 		SELECT validatorindex, validator_names.name 
 		FROM validators 
 		LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
@@ -1497,10 +1675,10 @@ func GetPendingValidatorCount() (uint64, error) {
 	return count, nil
 }
 
-func GetTotalEligibleEther() (uint64, error) {
+func GetTotalEligibleEther(ctx context.Context) (uint64, error) {
 	var total uint64
 
-	err := DB.Get(&total, `
+	err := DB.GetContext(ctx, &total, `
 		SELECT eligibleether FROM epochs ORDER BY epoch desc LIMIT 1
 	`)
 	if err == sql.ErrNoRows {
@@ -1636,3 +1814,27 @@ func GetValidatorsGotSlashed(epoch uint64) ([]struct {
 	}
 	return dbResult, nil
 }
+
+// GetValidatorAttestationInclusionEffectiveness returns the attestation inclusion effectiveness in percent of the validator for all epochs after sinceEpoch
+func GetValidatorAttestationInclusionEffectiveness(ctx context.Context, index uint64, sinceEpoch int64) (float64, error) {
+	var avgIncDistance float64
+	err := DB.GetContext(ctx, &avgIncDistance, `
+	SELECT COALESCE(
+		AVG(1 + inclusionslot - COALESCE((
+			SELECT MIN(slot)
+			FROM blocks
+			WHERE slot > aa.attesterslot AND blocks.status = '1'
+		), 0)
+	), 0)
+	FROM attestation_assignments_p aa
+	INNER JOIN blocks ON blocks.slot = aa.inclusionslot AND blocks.status <> '3'
+	WHERE aa.week >= $1 / 1575 AND aa.epoch > $1 AND aa.validatorindex = $2 AND aa.inclusionslot > 0
+	`, sinceEpoch, index)
+	if err != nil {
+		return 0, err
+	}
+	if avgIncDistance == 0 {
+		return 0, nil
+	}
+	return 1.0 / avgIncDistance * 100, nil
+}
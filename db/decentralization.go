@@ -0,0 +1,336 @@
+package db
+
+import (
+	"context"
+	"encoding/hex"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// The dimensions validators are clustered by, the combined dimension merges validators sharing any of the others
+const (
+	ClusterDimensionDepositAddress        = "deposit_address"
+	ClusterDimensionWithdrawalCredentials = "withdrawal_credentials"
+	ClusterDimensionFeeRecipient          = "fee_recipient"
+	ClusterDimensionGraffiti              = "graffiti"
+	ClusterDimensionCombined              = "combined"
+)
+
+// ClusterDimensions are all dimensions in the order they are shown
+var ClusterDimensions = []string{ClusterDimensionCombined, ClusterDimensionDepositAddress, ClusterDimensionWithdrawalCredentials, ClusterDimensionFeeRecipient, ClusterDimensionGraffiti}
+
+// validatorClustersLimit is the number of largest clusters per dimension stored per day
+const validatorClustersLimit = 100
+
+// clusterFeeRecipientLookbackDays is the number of days the fee recipient and graffiti of the last proposal are taken from,
+// the fee recipient only from locally built blocks
+const clusterFeeRecipientLookbackDays = 31
+
+// defaultGraffitiPattern matches the graffiti clients set by default, they do not identify an operator
+var defaultGraffitiPattern = regexp.MustCompile(`(?i)^\s*(lighthouse|prysm|teku|nimbus|lodestar|grandine|erigon|geth|nethermind|besu|reth)?[\s/-]*(v?\d+(\.\d+)*.*)?$`)
+
+type validatorClusterAttributes struct {
+	Validatorindex        uint64 `db:"validatorindex"`
+	EffectiveBalance      uint64 `db:"effectivebalance"`
+	WithdrawalCredentials []byte `db:"withdrawalcredentials"`
+	DepositAddress        []byte `db:"deposit_address"`
+	FeeRecipient          []byte `db:"fee_recipient"`
+	Graffiti              string `db:"graffiti"`
+}
+
+// UpdateDecentralizationStatsForDay clusters the validators active at the end of the day and stores the concentration
+// of the stake per dimension together with the largest clusters
+func UpdateDecentralizationStatsForDay(day uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_decentralization_stats").Observe(time.Since(start).Seconds())
+	}()
+
+	epochsPerDay := utils.EpochsPerDay()
+	lastEpoch := (day+1)*epochsPerDay - 1
+	firstSlot := uint64(0)
+	if day >= clusterFeeRecipientLookbackDays {
+		firstSlot = (day + 1 - clusterFeeRecipientLookbackDays) * epochsPerDay * utils.Config.Chain.SlotsPerEpoch
+	}
+	lastSlot := (lastEpoch+1)*utils.Config.Chain.SlotsPerEpoch - 1
+
+	excludedFeeRecipients, err := decentralizationExcludedFeeRecipients()
+	if err != nil {
+		return err
+	}
+
+	// the fee recipient of blocks of builders is the builder or the relay, which pays the proposer in a separate
+	// transaction (exec_fee_reward_recipient), so only blocks that were neither delivered by a relay nor paid the
+	// proposer separately identify the operator
+	validators := []*validatorClusterAttributes{}
+	err = DB.Select(&validators, `
+		SELECT
+			v.validatorindex,
+			v.effectivebalance,
+			v.withdrawalcredentials,
+			d.from_address AS deposit_address,
+			f.exec_fee_recipient AS fee_recipient,
+			COALESCE(b.graffiti_text, '') AS graffiti
+		FROM validators v
+			LEFT JOIN (
+				SELECT DISTINCT ON (publickey) publickey, from_address
+				FROM eth1_deposits
+				WHERE valid_signature
+				ORDER BY publickey, block_number, merkletree_index
+			) d ON d.publickey = v.pubkey
+			LEFT JOIN (
+				SELECT DISTINCT ON (proposer) proposer, graffiti_text
+				FROM blocks
+				WHERE status = '1' AND slot >= $2 AND slot <= $3
+				ORDER BY proposer, slot DESC
+			) b ON b.proposer = v.validatorindex
+			LEFT JOIN (
+				SELECT DISTINCT ON (b.proposer) b.proposer, b.exec_fee_recipient
+				FROM blocks b
+				WHERE b.status = '1' AND b.slot >= $2 AND b.slot <= $3 AND b.exec_fee_recipient IS NOT NULL
+					AND (b.exec_fee_reward_recipient IS NULL OR b.exec_fee_reward_recipient = b.exec_fee_recipient)
+					AND NOT b.exec_fee_recipient = ANY($4)
+					AND NOT EXISTS (SELECT 1 FROM relay_auctions a WHERE a.slot = b.slot AND a.winning_bid IS NOT NULL)
+				ORDER BY b.proposer, b.slot DESC
+			) f ON f.proposer = v.validatorindex
+		WHERE v.activationepoch <= $1 AND v.exitepoch > $1`, lastEpoch, firstSlot, lastSlot, excludedFeeRecipients)
+	if err != nil {
+		return fmt.Errorf("error retrieving validator cluster attributes: %w", err)
+	}
+
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM validator_clusters WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM decentralization_stats WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+
+	for _, dimension := range ClusterDimensions {
+		clusters := clusterValidators(validators, dimension)
+		stats := computeDecentralizationStats(clusters)
+		stats.Day = day
+		stats.Dimension = dimension
+
+		_, err = tx.Exec(`
+			INSERT INTO decentralization_stats (day, dimension, clusters, validators, stake, gini, nakamoto, top_cluster_share)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			day, dimension, stats.Clusters, stats.Validators, stats.Stake, stats.Gini, stats.Nakamoto, stats.TopClusterShare)
+		if err != nil {
+			return err
+		}
+
+		for i, c := range clusters {
+			if i >= validatorClustersLimit {
+				break
+			}
+			_, err = tx.Exec(`
+				INSERT INTO validator_clusters (day, dimension, cluster, validators, stake)
+				VALUES ($1, $2, $3, $4, $5)`,
+				day, dimension, c.Cluster, c.Validators, c.Stake)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	logger.Infof("updated decentralization stats of %v validators for day %v, took %v", len(validators), day, time.Since(start))
+	return nil
+}
+
+// decentralizationExcludedFeeRecipients returns the configured fee recipients that are not used to cluster validators
+func decentralizationExcludedFeeRecipients() (pq.ByteaArray, error) {
+	addresses := pq.ByteaArray{}
+	for _, a := range utils.Config.Decentralization.ExcludedFeeRecipients {
+		address, err := hex.DecodeString(strings.TrimPrefix(a, "0x"))
+		if err != nil || len(address) != 20 {
+			return nil, fmt.Errorf("invalid excluded fee recipient %v", a)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// clusterValidators groups the validators by the dimension and returns the clusters ordered by stake, validators without
+// a value of the dimension (e.g. no proposal in the lookback period) are not part of any cluster. The combined dimension
+// merges all validators sharing a deposit address, withdrawal credentials, fee recipient or graffiti.
+func clusterValidators(validators []*validatorClusterAttributes, dimension string) []*types.ValidatorCluster {
+	keys := func(v *validatorClusterAttributes) []string {
+		k := make([]string, 0, 4)
+		if (dimension == ClusterDimensionDepositAddress || dimension == ClusterDimensionCombined) && len(v.DepositAddress) > 0 {
+			k = append(k, "deposit_address:0x"+hex.EncodeToString(v.DepositAddress))
+		}
+		if (dimension == ClusterDimensionWithdrawalCredentials || dimension == ClusterDimensionCombined) && len(v.WithdrawalCredentials) > 0 {
+			// execution credentials of the same address are one operator independent of their prefix (0x01 or 0x02)
+			if address := utils.WithdrawalAddressOfCredentials(v.WithdrawalCredentials); address != nil {
+				k = append(k, "withdrawal_address:0x"+hex.EncodeToString(address))
+			} else {
+				k = append(k, "withdrawal_credentials:0x"+hex.EncodeToString(v.WithdrawalCredentials))
+			}
+		}
+		if (dimension == ClusterDimensionFeeRecipient || dimension == ClusterDimensionCombined) && len(v.FeeRecipient) > 0 && !isZeroAddress(v.FeeRecipient) {
+			k = append(k, "fee_recipient:0x"+hex.EncodeToString(v.FeeRecipient))
+		}
+		if (dimension == ClusterDimensionGraffiti || dimension == ClusterDimensionCombined) && !defaultGraffitiPattern.MatchString(v.Graffiti) {
+			k = append(k, "graffiti:"+strings.TrimSpace(v.Graffiti))
+		}
+		return k
+	}
+
+	// union-find over the validators, validators sharing a key are merged into the cluster of the first one
+	parent := make([]int, len(validators))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	firstOfKey := map[string]int{}
+	hasKey := make([]bool, len(validators))
+	label := make([]string, len(validators))
+	for i, v := range validators {
+		for _, k := range keys(v) {
+			hasKey[i] = true
+			if label[i] == "" {
+				label[i] = k
+			}
+			if j, exists := firstOfKey[k]; exists {
+				ri, rj := find(i), find(j)
+				if ri != rj {
+					parent[ri] = rj
+				}
+			} else {
+				firstOfKey[k] = i
+			}
+		}
+	}
+
+	clustersByRoot := map[int]*types.ValidatorCluster{}
+	for i, v := range validators {
+		if !hasKey[i] {
+			continue
+		}
+		root := find(i)
+		c, exists := clustersByRoot[root]
+		if !exists {
+			c = &types.ValidatorCluster{Dimension: dimension, Cluster: label[root]}
+			clustersByRoot[root] = c
+		}
+		c.Validators++
+		c.Stake += v.EffectiveBalance
+	}
+
+	clusters := make([]*types.ValidatorCluster, 0, len(clustersByRoot))
+	for _, c := range clustersByRoot {
+		clusters = append(clusters, c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Stake != clusters[j].Stake {
+			return clusters[i].Stake > clusters[j].Stake
+		}
+		return clusters[i].Cluster < clusters[j].Cluster
+	})
+	return clusters
+}
+
+// computeDecentralizationStats computes the gini coefficient of the stake of the clusters and the nakamoto coefficient,
+// the minimal number of clusters controlling more than a third of the stake (enough to prevent finality)
+func computeDecentralizationStats(clusters []*types.ValidatorCluster) *types.DecentralizationStats {
+	stats := &types.DecentralizationStats{Clusters: uint64(len(clusters))}
+	for _, c := range clusters {
+		stats.Validators += c.Validators
+		stats.Stake += c.Stake
+	}
+	if stats.Stake == 0 {
+		return stats
+	}
+
+	stats.TopClusterShare = float64(clusters[0].Stake) / float64(stats.Stake)
+
+	var cumulative uint64
+	for i, c := range clusters {
+		cumulative += c.Stake
+		if cumulative*3 > stats.Stake {
+			stats.Nakamoto = uint64(i + 1)
+			break
+		}
+	}
+
+	// the clusters are ordered descending, the gini coefficient is computed over the ascending order
+	n := float64(len(clusters))
+	var weighted float64
+	for i, c := range clusters {
+		rank := n - float64(i)
+		weighted += rank * float64(c.Stake)
+	}
+	stats.Gini = 2*weighted/(n*float64(stats.Stake)) - (n+1)/n
+	return stats
+}
+
+func isZeroAddress(address []byte) bool {
+	for _, b := range address {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLastDecentralizationStatsDay returns the last day the decentralization stats have been computed for, false is
+// returned if they have not been computed yet
+func GetLastDecentralizationStatsDay() (uint64, bool, error) {
+	var day *uint64
+	err := DB.Get(&day, "SELECT MAX(day) FROM decentralization_stats")
+	if err != nil || day == nil {
+		return 0, false, err
+	}
+	return *day, true, nil
+}
+
+// GetDecentralizationStats returns the daily decentralization stats of the dimension ordered by day
+func GetDecentralizationStats(ctx context.Context, dimension string) ([]*types.DecentralizationStats, error) {
+	stats := []*types.DecentralizationStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT day, dimension, clusters, validators, stake, gini, nakamoto, top_cluster_share
+		FROM decentralization_stats
+		WHERE dimension = $1
+		ORDER BY day`, dimension)
+	return stats, err
+}
+
+// GetLatestValidatorClusters returns the largest clusters of the dimension of the last computed day
+func GetLatestValidatorClusters(ctx context.Context, dimension string, limit uint64) ([]*types.ValidatorCluster, error) {
+	clusters := []*types.ValidatorCluster{}
+	err := DB.SelectContext(ctx, &clusters, `
+		SELECT c.day, c.dimension, c.cluster, c.validators, c.stake, COALESCE(c.stake::float / NULLIF(s.stake, 0), 0) AS share
+		FROM validator_clusters c
+			INNER JOIN decentralization_stats s ON s.day = c.day AND s.dimension = c.dimension
+		WHERE c.dimension = $1 AND c.day = (SELECT MAX(day) FROM validator_clusters WHERE dimension = $1)
+		ORDER BY c.stake DESC, c.cluster
+		LIMIT $2`, dimension, limit)
+	return clusters, err
+}
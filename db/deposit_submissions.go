@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+
+	"github.com/lib/pq"
+)
+
+// SaveDepositSubmission stores the transaction a deposit of the guided deposit page was sent with
+func SaveDepositSubmission(ctx context.Context, txHash, pubkey []byte) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO deposit_submissions (tx_hash, pubkey, submitted_ts)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (tx_hash, pubkey) DO NOTHING`, txHash, pubkey)
+	return err
+}
+
+// GetDepositSubmissions returns the tracked deposits of the transactions with the eth1 block their deposit was included
+// in and the validator it created, if they exist yet
+func GetDepositSubmissions(ctx context.Context, txHashes [][]byte) ([]*types.DepositSubmission, error) {
+	submissions := []*types.DepositSubmission{}
+	err := DB.SelectContext(ctx, &submissions, `
+		SELECT
+			s.tx_hash,
+			s.pubkey,
+			s.submitted_ts,
+			d.block_number,
+			v.validatorindex,
+			COALESCE(v.status, '') AS status
+		FROM deposit_submissions s
+		LEFT JOIN LATERAL (
+			SELECT block_number FROM eth1_deposits WHERE tx_hash = s.tx_hash AND publickey = s.pubkey AND NOT removed LIMIT 1
+		) d ON true
+		LEFT JOIN validators v ON v.pubkey = s.pubkey
+		WHERE s.tx_hash = ANY($1)
+		ORDER BY s.submitted_ts DESC`, pq.ByteaArray(txHashes))
+	if err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
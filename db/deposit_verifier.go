@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/lib/pq"
+)
+
+// DepositedKey is the deposit and validator state of a public key that already received deposits
+type DepositedKey struct {
+	Publickey             []byte `db:"publickey"`
+	DepositedAmount       uint64 `db:"deposited_amount"`
+	WithdrawalCredentials []byte `db:"withdrawalcredentials"`
+	Validatorindex        *int64 `db:"validatorindex"`
+	Balance               uint64 `db:"balance"`
+	Status                string `db:"status"`
+}
+
+// GetDepositedKeys returns the deposited keys among the public keys by their hex encoding, the withdrawal credentials are
+// the ones of the validator or, if it is not yet on the beacon chain, of its first valid deposit
+func GetDepositedKeys(ctx context.Context, pubkeys [][]byte) (map[string]*DepositedKey, error) {
+	keys := []*DepositedKey{}
+	err := DB.SelectContext(ctx, &keys, `
+		WITH deposits AS (
+			SELECT DISTINCT ON (publickey) publickey, withdrawal_credentials, SUM(amount) OVER (PARTITION BY publickey) AS deposited_amount
+			FROM eth1_deposits
+			WHERE publickey = ANY($1) AND valid_signature
+			ORDER BY publickey, block_number, merkletree_index
+		)
+		SELECT
+			COALESCE(v.pubkey, d.publickey) AS publickey,
+			COALESCE(d.deposited_amount, 0) AS deposited_amount,
+			COALESCE(v.withdrawalcredentials, d.withdrawal_credentials) AS withdrawalcredentials,
+			v.validatorindex,
+			COALESCE(v.balance, 0) AS balance,
+			COALESCE(v.status, '') AS status
+		FROM deposits d
+			FULL OUTER JOIN (SELECT pubkey, withdrawalcredentials, validatorindex, balance, status FROM validators WHERE pubkey = ANY($1)) v ON v.pubkey = d.publickey`, pq.ByteaArray(pubkeys))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]*DepositedKey, len(keys))
+	for _, k := range keys {
+		res[hex.EncodeToString(k.Publickey)] = k
+	}
+	return res, nil
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetDutyCalendar returns the known upcoming proposals and sync committee periods of the validators together with
+// estimates of their next proposal and sync committee period. Proposals are known for the epochs the indexer has
+// exported the proposer assignments of, sync committees one period in advance.
+func GetDutyCalendar(ctx context.Context, validators []uint64, latestEpoch uint64) (*types.DutyCalendar, error) {
+	calendar := &types.DutyCalendar{Events: []*types.DutyCalendarEvent{}}
+	if len(validators) == 0 {
+		return calendar, nil
+	}
+	filter := pq.Array(validators)
+	slotDuration := time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot)
+	currentSlot := utils.TimeToSlot(uint64(time.Now().Unix()))
+
+	proposals := []struct {
+		Validatorindex uint64 `db:"validatorindex"`
+		Proposerslot   uint64 `db:"proposerslot"`
+	}{}
+	err := DB.SelectContext(ctx, &proposals, `
+		SELECT validatorindex, proposerslot
+		FROM proposal_assignments
+		WHERE validatorindex = ANY($1) AND epoch >= $2 AND proposerslot > $3 AND status = 0
+		ORDER BY proposerslot`, filter, latestEpoch, currentSlot)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range proposals {
+		slot := p.Proposerslot
+		start := utils.SlotToTime(slot)
+		calendar.Events = append(calendar.Events, &types.DutyCalendarEvent{
+			Type:       types.DutyCalendarProposal,
+			Validators: []uint64{p.Validatorindex},
+			Start:      start,
+			End:        start.Add(slotDuration),
+			Slot:       &slot,
+		})
+	}
+
+	currentPeriod := utils.SyncPeriodOfEpoch(latestEpoch)
+	syncCommittees := []struct {
+		Period     uint64        `db:"period"`
+		Validators pq.Int64Array `db:"validators"`
+	}{}
+	err = DB.SelectContext(ctx, &syncCommittees, `
+		SELECT period, ARRAY_AGG(DISTINCT validatorindex ORDER BY validatorindex) AS validators
+		FROM sync_committees
+		WHERE validatorindex = ANY($1) AND period >= $2
+		GROUP BY period
+		ORDER BY period`, filter, currentPeriod)
+	if err != nil {
+		return nil, err
+	}
+	knownSyncPeriod := false
+	for _, c := range syncCommittees {
+		period := c.Period
+		members := make([]uint64, len(c.Validators))
+		for i, v := range c.Validators {
+			members[i] = uint64(v)
+		}
+		if period > currentPeriod {
+			knownSyncPeriod = true
+		}
+		calendar.Events = append(calendar.Events, &types.DutyCalendarEvent{
+			Type:       types.DutyCalendarSyncCommittee,
+			Validators: members,
+			Start:      utils.EpochToTime(utils.FirstEpochOfSyncPeriod(period)),
+			End:        utils.EpochToTime(utils.FirstEpochOfSyncPeriod(period + 1)),
+			Period:     &period,
+		})
+	}
+
+	// proposers and sync committee members are sampled proportionally to the effective balance
+	stake := struct {
+		EffectiveBalance      uint64 `db:"effective_balance"`
+		TotalEffectiveBalance uint64 `db:"total_effective_balance"`
+	}{}
+	err = DB.GetContext(ctx, &stake, `
+		SELECT
+			COALESCE(SUM(effectivebalance) FILTER (WHERE validatorindex = ANY($1)), 0) AS effective_balance,
+			COALESCE(SUM(effectivebalance), 0) AS total_effective_balance
+		FROM validators
+		WHERE activationepoch <= $2 AND exitepoch > $2`, filter, latestEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	if stake.EffectiveBalance > 0 && stake.TotalEffectiveBalance > 0 {
+		share := float64(stake.EffectiveBalance) / float64(stake.TotalEffectiveBalance)
+		slotsPerDay := float64(utils.EpochsPerDay() * utils.Config.Chain.SlotsPerEpoch)
+		calendar.ProposalProbabilityPerSlot = share
+		calendar.ExpectedProposalsPerDay = share * slotsPerDay
+		calendar.SyncCommitteeProbabilityPerPeriod = 1 - math.Pow(1-share, float64(utils.Config.Chain.SyncCommitteeSize))
+
+		if len(proposals) == 0 {
+			// the number of slots until the next proposal is geometrically distributed, the expected value is 1/share
+			slot := currentSlot + uint64(math.Ceil(1/share))
+			start := utils.SlotToTime(slot)
+			calendar.Events = append(calendar.Events, &types.DutyCalendarEvent{
+				Type:        types.DutyCalendarEstimatedProposal,
+				Start:       start,
+				End:         start.Add(slotDuration),
+				Slot:        &slot,
+				Probability: calendar.ProposalProbabilityPerSlot,
+			})
+		}
+		if !knownSyncPeriod && calendar.SyncCommitteeProbabilityPerPeriod > 0 {
+			period := currentPeriod + uint64(math.Ceil(1/calendar.SyncCommitteeProbabilityPerPeriod))
+			calendar.Events = append(calendar.Events, &types.DutyCalendarEvent{
+				Type:        types.DutyCalendarEstimatedSyncCommittee,
+				Start:       utils.EpochToTime(utils.FirstEpochOfSyncPeriod(period)),
+				End:         utils.EpochToTime(utils.FirstEpochOfSyncPeriod(period + 1)),
+				Period:      &period,
+				Probability: calendar.SyncCommitteeProbabilityPerPeriod,
+			})
+		}
+	}
+
+	sort.SliceStable(calendar.Events, func(i, j int) bool {
+		return calendar.Events[i].Start.Before(calendar.Events[j].Start)
+	})
+	return calendar, nil
+}
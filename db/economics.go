@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"time"
+)
+
+// WriteEconomicsStatsForDay stores the deposit contract balance, the staked ether, the issuance and the burn of the day.
+// The validator_stats of the day have to be exported before. The circulating supply is only known for the current
+// state of the execution layer and is passed as nil when older days are exported.
+func WriteEconomicsStatsForDay(day uint64, circulatingSupply *uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_economics_stats").Observe(time.Since(start).Seconds())
+	}()
+
+	epochsPerDay := utils.EpochsPerDay()
+	lastEpoch := (day+1)*epochsPerDay - 1
+	firstSlot := day * epochsPerDay * utils.Config.Chain.SlotsPerEpoch
+	lastSlot := (lastEpoch+1)*utils.Config.Chain.SlotsPerEpoch - 1
+	dayEnd := utils.EpochToTime(lastEpoch + 1)
+
+	// the issuance is the balance change of the day minus the deposits plus the withdrawals of the canonical blocks of the
+	// day, which leave the balances without reducing the supply. The burn is converted from wei to gwei.
+	_, err := DB.Exec(`
+		INSERT INTO economics_stats (day, deposit_contract_balance, total_effective_balance, total_balance, circulating_supply, staking_ratio, issuance, burn)
+		SELECT
+			$1,
+			(SELECT COALESCE(SUM(amount), 0) FROM eth1_deposits WHERE block_ts < $3),
+			vs.total_effective_balance,
+			vs.total_balance,
+			$6::bigint,
+			vs.total_effective_balance::float / NULLIF($6::bigint, 0),
+			vs.issuance + (
+				SELECT COALESCE(SUM(w.amount), 0)
+				FROM blocks_withdrawals w
+				INNER JOIN blocks b ON b.slot = w.block_slot AND b.blockroot = w.block_root AND b.status = '1'
+				WHERE w.block_slot BETWEEN $4 AND $5
+			),
+			(
+				SELECT COALESCE(SUM(exec_base_fee_per_gas::numeric * exec_gas_used) / 1e9, 0)::bigint
+				FROM blocks
+				WHERE slot BETWEEN $4 AND $5 AND status = '1' AND exec_block_number IS NOT NULL
+			)
+		FROM (
+			SELECT
+				COALESCE(SUM(vs.end_effective_balance) FILTER (WHERE v.activationepoch <= $2 AND v.exitepoch > $2), 0) AS total_effective_balance,
+				COALESCE(SUM(vs.end_balance), 0) AS total_balance,
+				COALESCE(SUM(COALESCE(vs.end_balance, 0) - COALESCE(vs.start_balance, 0) - COALESCE(vs.deposits_amount, 0)), 0) AS issuance
+			FROM validator_stats vs
+				INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+			WHERE vs.day = $1
+		) vs
+		ON CONFLICT (day) DO UPDATE SET
+			deposit_contract_balance = excluded.deposit_contract_balance,
+			total_effective_balance = excluded.total_effective_balance,
+			total_balance = excluded.total_balance,
+			circulating_supply = COALESCE(excluded.circulating_supply, economics_stats.circulating_supply),
+			staking_ratio = COALESCE(excluded.staking_ratio, economics_stats.staking_ratio),
+			issuance = excluded.issuance,
+			burn = excluded.burn`,
+		day, lastEpoch, dayEnd, firstSlot, lastSlot, circulatingSupply)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("exported economics stats for day %v, took %v", day, time.Since(start))
+	return nil
+}
+
+// GetLastEconomicsStatsDay returns the last exported day, false if no day has been exported yet
+func GetLastEconomicsStatsDay() (uint64, bool, error) {
+	var day *uint64
+	err := DB.Get(&day, "SELECT MAX(day) FROM economics_stats")
+	if err != nil || day == nil {
+		return 0, false, err
+	}
+	return *day, true, nil
+}
+
+// GetEconomicsStats returns the economics stats of all exported days ordered by day
+func GetEconomicsStats(ctx context.Context) ([]*types.EconomicsStats, error) {
+	stats := []*types.EconomicsStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT day, deposit_contract_balance, total_effective_balance, total_balance, circulating_supply, staking_ratio, issuance, burn
+		FROM economics_stats
+		ORDER BY day`)
+	return stats, err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultEffectivenessFormula is the formula the explorer has always used to rate the attestations of validators
+const DefaultEffectivenessFormula = "inclusion_distance"
+
+// EffectivenessFormulas are the formulas the effectiveness of validators is computed with side by side, the
+// expressions can use the columns of effectivenessAssignmentsQuery
+var EffectivenessFormulas = []*types.EffectivenessFormula{
+	{
+		Name:        "inclusion_distance",
+		Title:       "Inclusion Distance",
+		Description: "Inverse of the average distance between the first block the attestation could have been included in and the block it was included in, missed attestations are not considered.",
+		Expression:  "COALESCE(100 / NULLIF(AVG(optimal_distance) FILTER (WHERE inclusionslot > 0), 0), 0)",
+	},
+	{
+		Name:        "altair",
+		Title:       "Altair Weighted",
+		Description: "Share of the maximum attestation reward weight earned per Altair: source (14) if included within 5 slots, target (26) within 32 slots and head (14) in the next slot. Missed attestations earn nothing, included votes are assumed to be correct.",
+		Expression:  "COALESCE(100 * AVG(CASE WHEN inclusionslot > 0 THEN (14 * (delay <= 5)::int + 26 * (delay <= 32)::int + 14 * (delay = 1)::int) / 54.0 ELSE 0 END), 0)",
+	},
+}
+
+// GetEffectivenessFormula returns the formula with the given name or nil if there is no such formula
+func GetEffectivenessFormula(name string) *types.EffectivenessFormula {
+	for _, f := range EffectivenessFormulas {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// effectivenessAssignmentsQuery selects the attestation assignments of the validators in $3 (all if null) from epoch
+// $1 to $2, assignments that were included in an orphaned block are skipped
+const effectivenessAssignmentsQuery = `
+	SELECT
+		aa.validatorindex,
+		aa.inclusionslot,
+		aa.inclusionslot - aa.attesterslot AS delay,
+		1 + aa.inclusionslot - COALESCE((
+			SELECT MIN(slot)
+			FROM blocks
+			WHERE slot > aa.attesterslot AND blocks.status = '1'
+		), 0) AS optimal_distance
+	FROM attestation_assignments_p aa
+	WHERE aa.week >= $1 / 1575 AND aa.week <= $2 / 1575 AND aa.epoch >= $1 AND aa.epoch <= $2
+		AND ($3::int[] IS NULL OR aa.validatorindex = ANY($3))
+		AND (aa.inclusionslot = 0 OR EXISTS (SELECT 1 FROM blocks WHERE blocks.slot = aa.inclusionslot AND blocks.status <> '3'))`
+
+// GetValidatorsEffectiveness computes the effectiveness of the validators from startEpoch to endEpoch with all formulas,
+// the inclusions of the dropped weeks of attestation_assignments_p are not stored so the epochs before the retained
+// epoch are skipped
+func GetValidatorsEffectiveness(ctx context.Context, indices []uint64, startEpoch, endEpoch uint64) (map[uint64][]*types.ValidatorEffectiveness, error) {
+	if retainedEpoch := AttestationAssignmentsRetainedEpoch(); startEpoch < retainedEpoch {
+		startEpoch = retainedEpoch
+	}
+	expressions := make([]string, len(EffectivenessFormulas))
+	for i, f := range EffectivenessFormulas {
+		expressions[i] = f.Expression
+	}
+
+	rows, err := DB.QueryContext(ctx, fmt.Sprintf(`
+		SELECT validatorindex, %s
+		FROM (%s) a
+		GROUP BY validatorindex`, strings.Join(expressions, ", "), effectivenessAssignmentsQuery), startEpoch, endEpoch, pq.Array(indices))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[uint64][]*types.ValidatorEffectiveness{}
+	for rows.Next() {
+		var index uint64
+		values := make([]float64, len(EffectivenessFormulas))
+		dest := []interface{}{&index}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range EffectivenessFormulas {
+			result[index] = append(result[index], &types.ValidatorEffectiveness{Formula: f, Effectiveness: values[i]})
+		}
+	}
+	return result, rows.Err()
+}
+
+// WriteValidatorEffectivenessForDay computes the effectiveness of all validators during the day with all formulas
+func WriteValidatorEffectivenessForDay(day uint64) error {
+	start := time.Now()
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	firstEpoch := day * epochsPerDay
+	lastEpoch := (day+1)*epochsPerDay - 1
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range EffectivenessFormulas {
+		_, err = tx.Exec(fmt.Sprintf(`
+			INSERT INTO validator_effectiveness (validatorindex, day, formula, effectiveness)
+			SELECT validatorindex, $4, $5, %s
+			FROM (%s) a
+			GROUP BY validatorindex
+			ON CONFLICT (validatorindex, day, formula) DO UPDATE SET effectiveness = excluded.effectiveness`, f.Expression, effectivenessAssignmentsQuery),
+			firstEpoch, lastEpoch, nil, day, f.Name)
+		if err != nil {
+			return fmt.Errorf("error computing %v effectiveness: %w", f.Name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	logger.Infof("exported validator effectiveness for day %v, took %v", day, time.Since(start))
+	return nil
+}
+
+// GetValidatorEffectivenessHistory returns the daily effectiveness of the validators according to formula
+func GetValidatorEffectivenessHistory(ctx context.Context, indices []uint64, formula string, startDay uint64) ([]*types.ValidatorEffectivenessDay, error) {
+	history := []*types.ValidatorEffectivenessDay{}
+	err := DB.SelectContext(ctx, &history, `
+		SELECT validatorindex, day, effectiveness
+		FROM validator_effectiveness
+		WHERE validatorindex = ANY($1) AND formula = $2 AND day >= $3
+		ORDER BY validatorindex, day`, pq.Array(indices), formula, startDay)
+	return history, err
+}
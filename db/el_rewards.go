@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+
+	"github.com/lib/pq"
+)
+
+// ElRewardBlock is a canonical block whose execution payload has not been evaluated yet
+type ElRewardBlock struct {
+	Slot             uint64 `db:"slot"`
+	BlockRoot        []byte `db:"blockroot"`
+	ExecBlockNumber  uint64 `db:"exec_block_number"`
+	ExecBlockHash    []byte `db:"exec_block_hash"`
+	ExecFeeRecipient []byte `db:"exec_fee_recipient"`
+	BaseFeePerGas    uint64 `db:"exec_base_fee_per_gas"`
+}
+
+// GetBlocksWithoutElRewards returns the oldest canonical blocks with an execution payload whose el reward has not been
+// stored yet
+func GetBlocksWithoutElRewards(limit uint64) ([]*ElRewardBlock, error) {
+	blocks := []*ElRewardBlock{}
+	err := DB.Select(&blocks, `
+		SELECT slot, blockroot, exec_block_number, exec_block_hash, exec_fee_recipient, COALESCE(exec_base_fee_per_gas, 0) AS exec_base_fee_per_gas
+		FROM blocks
+		WHERE status = '1' AND exec_block_number IS NOT NULL AND exec_fee_reward IS NULL
+		ORDER BY slot
+		LIMIT $1`, limit)
+	return blocks, err
+}
+
+// SaveElReward stores the value the proposer of the block received in wei and the address it was paid to
+func SaveElReward(slot uint64, blockRoot []byte, reward types.Wei, recipient []byte) error {
+	_, err := ExporterDB.Exec("UPDATE blocks SET exec_fee_reward = $3, exec_fee_reward_recipient = $4 WHERE slot = $1 AND blockroot = $2", slot, blockRoot, reward, recipient)
+	return err
+}
+
+// GetValidatorsElRewards returns the sum of the el rewards of the blocks proposed by the validators in total and
+// since the epochs of the last day, week and month
+func GetValidatorsElRewards(ctx context.Context, validators []uint64, lastDayEpoch, lastWeekEpoch, lastMonthEpoch int64) (*types.ValidatorIncomePeriods, error) {
+	rewards := &types.ValidatorIncomePeriods{}
+	err := DB.GetContext(ctx, rewards, `
+		SELECT
+			COALESCE(SUM(exec_fee_reward), 0) AS total,
+			COALESCE(SUM(exec_fee_reward) FILTER (WHERE epoch > $2), 0) AS last_day,
+			COALESCE(SUM(exec_fee_reward) FILTER (WHERE epoch > $3), 0) AS last_week,
+			COALESCE(SUM(exec_fee_reward) FILTER (WHERE epoch > $4), 0) AS last_month
+		FROM blocks
+		WHERE proposer = ANY($1) AND status = '1' AND exec_fee_reward IS NOT NULL`,
+		pq.Array(validators), lastDayEpoch, lastWeekEpoch, lastMonthEpoch)
+	return rewards, err
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"time"
+)
+
+// UpdateEpochsSummary (re)computes the summaries of the epochs between startEpoch and endEpoch from the epochs and blocks
+// tables, it has to be called whenever the epochs, their participation or the status of their blocks change
+func UpdateEpochsSummary(startEpoch, endEpoch uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_epochs_summary").Observe(time.Since(start).Seconds())
+	}()
+
+	_, err := DB.Exec(`
+		INSERT INTO epochs_summary (
+			epoch,
+			validatorscount,
+			totalvalidatorbalance,
+			averagevalidatorbalance,
+			eligibleether,
+			votedether,
+			globalparticipationrate,
+			finalized,
+			attestationscount,
+			depositscount,
+			voluntaryexitscount,
+			proposerslashingscount,
+			attesterslashingscount,
+			scheduledblocks,
+			proposedblocks,
+			missedblocks,
+			orphanedblocks,
+			syncparticipationrate,
+			updated_ts
+		)
+		SELECT
+			e.epoch,
+			e.validatorscount,
+			e.totalvalidatorbalance,
+			e.averagevalidatorbalance,
+			e.eligibleether,
+			e.votedether,
+			e.globalparticipationrate,
+			e.finalized,
+			e.attestationscount,
+			e.depositscount,
+			e.voluntaryexitscount,
+			e.proposerslashingscount,
+			e.attesterslashingscount,
+			COALESCE(b.scheduledblocks, 0),
+			COALESCE(b.proposedblocks, 0),
+			COALESCE(b.missedblocks, 0),
+			COALESCE(b.orphanedblocks, 0),
+			COALESCE(b.syncparticipationrate, 0),
+			NOW()
+		FROM epochs e
+		LEFT JOIN (
+			SELECT
+				epoch,
+				COUNT(*) FILTER (WHERE status = '0') AS scheduledblocks,
+				COUNT(*) FILTER (WHERE status = '1') AS proposedblocks,
+				COUNT(*) FILTER (WHERE status = '2') AS missedblocks,
+				COUNT(*) FILTER (WHERE status = '3') AS orphanedblocks,
+				AVG(syncaggregate_participation) FILTER (WHERE status = '1') AS syncparticipationrate
+			FROM blocks
+			WHERE epoch >= $1 AND epoch <= $2
+			GROUP BY epoch
+		) b ON b.epoch = e.epoch
+		WHERE e.epoch >= $1 AND e.epoch <= $2
+		ON CONFLICT (epoch) DO UPDATE SET
+			validatorscount         = excluded.validatorscount,
+			totalvalidatorbalance   = excluded.totalvalidatorbalance,
+			averagevalidatorbalance = excluded.averagevalidatorbalance,
+			eligibleether           = excluded.eligibleether,
+			votedether              = excluded.votedether,
+			globalparticipationrate = excluded.globalparticipationrate,
+			finalized               = excluded.finalized,
+			attestationscount       = excluded.attestationscount,
+			depositscount           = excluded.depositscount,
+			voluntaryexitscount     = excluded.voluntaryexitscount,
+			proposerslashingscount  = excluded.proposerslashingscount,
+			attesterslashingscount  = excluded.attesterslashingscount,
+			scheduledblocks         = excluded.scheduledblocks,
+			proposedblocks          = excluded.proposedblocks,
+			missedblocks            = excluded.missedblocks,
+			orphanedblocks          = excluded.orphanedblocks,
+			syncparticipationrate   = excluded.syncparticipationrate,
+			updated_ts              = excluded.updated_ts`, startEpoch, endEpoch)
+	return err
+}
+
+// GetEpochsWithoutSummary returns the oldest exported epochs that have not been summarized yet
+func GetEpochsWithoutSummary(limit uint64) ([]uint64, error) {
+	epochs := []uint64{}
+	err := DB.Select(&epochs, `
+		SELECT e.epoch
+		FROM epochs e
+		LEFT JOIN epochs_summary s ON s.epoch = e.epoch
+		WHERE s.epoch IS NULL
+		ORDER BY e.epoch
+		LIMIT $1`, limit)
+	return epochs, err
+}
+
+// GetEpochSummary returns the summary of the epoch or nil if it has not been summarized yet
+func GetEpochSummary(ctx context.Context, epoch uint64) (*types.EpochSummary, error) {
+	summaries := []*types.EpochSummary{}
+	err := DB.SelectContext(ctx, &summaries, `SELECT * FROM epochs_summary WHERE epoch = $1`, epoch)
+	if err != nil || len(summaries) == 0 {
+		return nil, err
+	}
+	return summaries[0], nil
+}
+
+// GetLatestEpochSummary returns the summary of the most recent summarized epoch or nil if no epoch has been summarized yet
+func GetLatestEpochSummary() (*types.EpochSummary, error) {
+	summaries := []*types.EpochSummary{}
+	err := DB.Select(&summaries, `SELECT * FROM epochs_summary ORDER BY epoch DESC LIMIT 1`)
+	if err != nil || len(summaries) == 0 {
+		return nil, err
+	}
+	return summaries[0], nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UpdateEth1Depositors aggregates the eth1-deposits of the given addresses into the depositor leaderboard, all addresses are updated if addresses is nil
+func UpdateEth1Depositors(addresses [][]byte) error {
+	query := `
+		INSERT INTO eth1_depositors (from_address, deposit_count, amount, first_deposit_ts, last_deposit_ts)
+		SELECT from_address, COUNT(*), SUM(amount), MIN(block_ts), MAX(block_ts)
+		FROM eth1_deposits
+		%s
+		GROUP BY from_address
+		ON CONFLICT (from_address) DO UPDATE SET
+			deposit_count = excluded.deposit_count,
+			amount = excluded.amount,
+			first_deposit_ts = excluded.first_deposit_ts,
+			last_deposit_ts = excluded.last_deposit_ts`
+
+	var err error
+	if addresses == nil {
+		_, err = DB.Exec(fmt.Sprintf(query, ""))
+	} else {
+		if len(addresses) == 0 {
+			return nil
+		}
+		_, err = DB.Exec(fmt.Sprintf(query, "WHERE from_address = ANY($1)"), pq.ByteaArray(addresses))
+	}
+	return err
+}
+
+// GetEth1Depositors returns the depositor leaderboard, addresses are labeled with the names of known staking pools
+func GetEth1Depositors(ctx context.Context, query string, length, start uint64, orderBy, orderDir string) ([]*types.Eth1Depositor, uint64, error) {
+	depositors := []*types.Eth1Depositor{}
+
+	if orderDir != "desc" && orderDir != "asc" {
+		orderDir = "desc"
+	}
+	columns := []string{"from_address", "deposit_count", "amount", "first_deposit_ts", "last_deposit_ts"}
+	hasColumn := false
+	for _, column := range columns {
+		if orderBy == column {
+			hasColumn = true
+		}
+	}
+	if !hasColumn {
+		orderBy = "amount"
+	}
+
+	var totalCount uint64
+	err := DB.GetContext(ctx, &totalCount, `
+		SELECT COUNT(*)
+		FROM eth1_depositors d
+		LEFT JOIN LATERAL (SELECT name, category FROM stake_pools_stats WHERE address = ENCODE(d.from_address, 'hex') LIMIT 1) sps ON true
+		WHERE $1 = '' OR ENCODE(d.from_address, 'hex') LIKE LOWER($1) || '%' OR sps.name ILIKE '%' || $1 || '%'`, query)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, 0, err
+	}
+
+	err = DB.SelectContext(ctx, &depositors, fmt.Sprintf(`
+		SELECT
+			d.from_address,
+			COALESCE(sps.name, '') AS name,
+			COALESCE(sps.category, '') AS category,
+			d.deposit_count,
+			d.amount,
+			d.first_deposit_ts,
+			d.last_deposit_ts
+		FROM eth1_depositors d
+		LEFT JOIN LATERAL (SELECT name, category FROM stake_pools_stats WHERE address = ENCODE(d.from_address, 'hex') LIMIT 1) sps ON true
+		WHERE $1 = '' OR ENCODE(d.from_address, 'hex') LIKE LOWER($1) || '%%' OR sps.name ILIKE '%%' || $1 || '%%'
+		ORDER BY d.%s %s
+		LIMIT $2
+		OFFSET $3`, orderBy, orderDir), query, length, start)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, 0, err
+	}
+
+	return depositors, totalCount, nil
+}
+
+// Eth1AddressDeposit is a single deposit of an eth1-address
+type Eth1AddressDeposit struct {
+	FromAddress []byte    `db:"from_address"`
+	Amount      uint64    `db:"amount"`
+	BlockTs     time.Time `db:"block_ts"`
+}
+
+// GetEth1DepositsSince returns the deposits the addresses made after since
+func GetEth1DepositsSince(addresses [][]byte, since time.Time) ([]*Eth1AddressDeposit, error) {
+	deposits := []*Eth1AddressDeposit{}
+	err := DB.Select(&deposits, `
+		SELECT from_address, amount, block_ts
+		FROM eth1_deposits
+		WHERE from_address = ANY($1) AND block_ts > $2
+		ORDER BY block_ts`, pq.ByteaArray(addresses), since)
+	return deposits, err
+}
+
+// GetEth1DepositorsAfter returns up to limit depositors ordered by amount that follow the given depositor in the leaderboard, the leaderboard starts at the top if afterAddress is nil
+func GetEth1DepositorsAfter(ctx context.Context, query string, limit uint64, afterAmount uint64, afterAddress []byte) ([]*types.Eth1Depositor, error) {
+	depositors := []*types.Eth1Depositor{}
+	err := DB.SelectContext(ctx, &depositors, `
+		SELECT
+			d.from_address,
+			COALESCE(sps.name, '') AS name,
+			COALESCE(sps.category, '') AS category,
+			d.deposit_count,
+			d.amount,
+			d.first_deposit_ts,
+			d.last_deposit_ts
+		FROM eth1_depositors d
+		LEFT JOIN LATERAL (SELECT name, category FROM stake_pools_stats WHERE address = ENCODE(d.from_address, 'hex') LIMIT 1) sps ON true
+		WHERE ($1 = '' OR ENCODE(d.from_address, 'hex') LIKE LOWER($1) || '%' OR sps.name ILIKE '%' || $1 || '%')
+			AND ($3::bytea IS NULL OR d.amount < $4 OR (d.amount = $4 AND d.from_address > $3))
+		ORDER BY d.amount DESC, d.from_address
+		LIMIT $2`, query, limit, afterAddress, afterAmount)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return depositors, nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+)
+
+// GetEth1DepositsWithoutValidator returns the aggregated eth1-deposits of all public keys that are not yet known to the beacon chain, ordered by the block of their last deposit
+func GetEth1DepositsWithoutValidator() ([]*types.Eth1PendingDeposit, error) {
+	deposits := []*types.Eth1PendingDeposit{}
+	err := DB.Select(&deposits, `
+		SELECT
+			d.publickey,
+			(ARRAY_AGG(d.from_address ORDER BY d.block_number))[1] AS from_address,
+			COUNT(*) AS deposit_count,
+			SUM(d.amount) AS amount,
+			COALESCE(SUM(d.amount) FILTER (WHERE d.valid_signature), 0) AS valid_amount,
+			MAX(d.block_number) AS last_block_number,
+			MAX(d.block_ts) AS last_deposit_ts
+		FROM eth1_deposits d
+		LEFT JOIN validators v ON v.pubkey = d.publickey
+		WHERE v.pubkey IS NULL AND NOT d.removed
+		GROUP BY d.publickey
+		ORDER BY last_block_number, d.publickey`)
+	return deposits, err
+}
+
+// SaveEth1PendingDeposits replaces the pending deposits with the given ones
+func SaveEth1PendingDeposits(deposits []*types.Eth1PendingDeposit) error {
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM eth1_pending_deposits`)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO eth1_pending_deposits (
+			publickey,
+			from_address,
+			deposit_count,
+			amount,
+			valid_amount,
+			last_block_number,
+			last_deposit_ts,
+			queue_position,
+			estimated_inclusion_epoch,
+			estimated_activation_epoch
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, d := range deposits {
+		_, err = stmt.Exec(d.PublicKey, d.FromAddress, d.DepositCount, d.Amount, d.ValidAmount, d.LastBlockNumber, d.LastDepositTs, d.QueuePosition, d.EstimatedInclusionEpoch, d.EstimatedActivationEpoch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetEth1PendingDeposit returns the pending deposit of the public key, nil is returned if the public key has no pending deposit
+func GetEth1PendingDeposit(ctx context.Context, publicKey []byte) (*types.Eth1PendingDeposit, error) {
+	deposit := &types.Eth1PendingDeposit{}
+	err := DB.GetContext(ctx, deposit, `SELECT * FROM eth1_pending_deposits WHERE publickey = $1`, publicKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"time"
+)
+
+// UpdateExporterStatus marks the last run of the exporter with the given name as successful
+func UpdateExporterStatus(name string) error {
+	_, err := DB.Exec(`
+		INSERT INTO exporter_status (name, last_success_ts)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET last_success_ts = excluded.last_success_ts`,
+		name, time.Now())
+	return err
+}
+
+// GetExporterStatuses returns the last successful run of all exporters that succeeded after since, older rows belong to
+// exporters that are no longer running
+func GetExporterStatuses(since time.Time) ([]*types.ExporterStatus, error) {
+	statuses := []*types.ExporterStatus{}
+	err := DB.Select(&statuses, `SELECT name, last_success_ts FROM exporter_status WHERE last_success_ts > $1 ORDER BY name`, since)
+	return statuses, err
+}
+
+// GetReplicationDelay returns how far the db lags behind its primary, it is 0 if the db is not a replica
+func GetReplicationDelay() (time.Duration, error) {
+	var delay float64
+	err := DB.Get(&delay, `
+		SELECT CASE WHEN pg_is_in_recovery()
+			THEN COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+			ELSE 0 END`)
+	return time.Duration(delay * float64(time.Second)), err
+}
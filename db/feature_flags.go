@@ -0,0 +1,29 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"time"
+)
+
+// GetFeatureFlagOverrides returns the feature flags that have been overridden at runtime
+func GetFeatureFlagOverrides() ([]*types.FeatureFlagOverride, error) {
+	overrides := []*types.FeatureFlagOverride{}
+	err := FrontendDB.Select(&overrides, "SELECT name, enabled, updated_by, updated_ts FROM feature_flags ORDER BY name")
+	return overrides, err
+}
+
+// SetFeatureFlagOverride overrides the configured state of the feature flag
+func SetFeatureFlagOverride(name string, enabled bool, userID uint64) error {
+	_, err := FrontendDB.Exec(`
+		INSERT INTO feature_flags (name, enabled, updated_by, updated_ts)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE SET enabled = excluded.enabled, updated_by = excluded.updated_by, updated_ts = excluded.updated_ts`,
+		name, enabled, userID, time.Now())
+	return err
+}
+
+// DeleteFeatureFlagOverride removes the override of the feature flag, the configured state applies again
+func DeleteFeatureFlagOverride(name string) error {
+	_, err := FrontendDB.Exec("DELETE FROM feature_flags WHERE name = $1", name)
+	return err
+}
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+
+	"github.com/lib/pq"
+)
+
+// GetValidatorFeeRecipients returns the fee recipients the validators used in their proposed blocks, the most recently used fee recipient comes first
+func GetValidatorFeeRecipients(ctx context.Context, indices []uint64) ([]*types.ValidatorFeeRecipient, error) {
+	feeRecipients := []*types.ValidatorFeeRecipient{}
+	err := DB.SelectContext(ctx, &feeRecipients, `
+		SELECT
+			proposer,
+			exec_fee_recipient,
+			COUNT(*) AS blocks_count,
+			MIN(slot) AS first_slot,
+			MAX(slot) AS last_slot
+		FROM blocks
+		WHERE proposer = ANY($1) AND status = '1' AND exec_fee_recipient IS NOT NULL
+		GROUP BY proposer, exec_fee_recipient
+		ORDER BY proposer, last_slot DESC`, pq.Array(indices))
+	return feeRecipients, err
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"eth2-exporter/types"
+)
+
+// GetRecentSlashings returns the most recent slashings that were included in canonical blocks
+func GetRecentSlashings(limit uint64) ([]*types.ValidatorSlashing, error) {
+	slashings := []*types.ValidatorSlashing{}
+	err := DB.Select(&slashings, `
+		SELECT
+			slot,
+			epoch,
+			proposer,
+			slashedvalidator,
+			attestation1_indices,
+			attestation2_indices,
+			type
+		FROM (
+			SELECT
+				blocks.slot,
+				blocks.epoch,
+				blocks.proposer,
+				NULL as slashedvalidator,
+				blocks_attesterslashings.attestation1_indices,
+				blocks_attesterslashings.attestation2_indices,
+				'Attestation Violation'::varchar as type
+			FROM blocks_attesterslashings
+			INNER JOIN blocks on blocks_attesterslashings.block_slot = blocks.slot AND blocks.status = '1'
+			UNION ALL
+			SELECT
+				blocks.slot,
+				blocks.epoch,
+				blocks.proposer,
+				blocks_proposerslashings.proposerindex as slashedvalidator,
+				NULL as attestation1_indices,
+				NULL as attestation2_indices,
+				'Proposer Violation' as type
+			FROM blocks_proposerslashings
+			INNER JOIN blocks on blocks_proposerslashings.block_slot = blocks.slot AND blocks.status = '1'
+		) as query
+		ORDER BY slot desc
+		LIMIT $1`, limit)
+	return slashings, err
+}
+
+// GetRecentVoluntaryExits returns the most recent voluntary exits that were included in canonical blocks
+func GetRecentVoluntaryExits(limit uint64) ([]*types.FeedExit, error) {
+	exits := []*types.FeedExit{}
+	err := DB.Select(&exits, `
+		SELECT blocks_voluntaryexits.block_slot AS slot, blocks_voluntaryexits.epoch, blocks_voluntaryexits.validatorindex
+		FROM blocks_voluntaryexits
+		INNER JOIN blocks ON blocks_voluntaryexits.block_slot = blocks.slot AND blocks.status = '1'
+		ORDER BY blocks_voluntaryexits.block_slot DESC, blocks_voluntaryexits.block_index DESC
+		LIMIT $1`, limit)
+	return exits, err
+}
+
+// GetRecentBlocks returns the most recent blocks, including missed and orphaned ones
+func GetRecentBlocks(limit uint64) ([]*types.FeedBlock, error) {
+	blocks := []*types.FeedBlock{}
+	err := DB.Select(&blocks, `
+		SELECT slot, epoch, proposer, status, blockroot
+		FROM blocks
+		WHERE slot > 0
+		ORDER BY slot DESC
+		LIMIT $1`, limit)
+	return blocks, err
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"errors"
@@ -19,7 +20,7 @@ import (
 var FrontendDB *sqlx.DB
 
 func MustInitFrontendDB(username, password, host, port, name, sessionSecret string) {
-	FrontendDB = mustInitDB(username, password, host, port, name)
+	FrontendDB = mustInitDB(username, password, host, port, name, utils.Config.Frontend.Database.Pool)
 }
 
 // GetUserEmailById returns the email of a user.
@@ -61,41 +62,35 @@ func GetUserApiKeyById(id uint64) (string, error) {
 	return apiKey, err
 }
 
-func GetUserIdByApiKey(apiKey string) (*types.UserWithPremium, error) {
+func GetUserIdByApiKey(ctx context.Context, apiKey string) (*types.UserWithPremium, error) {
 	data := &types.UserWithPremium{}
-	row := FrontendDB.QueryRow("SELECT id, (SELECT product_id from users_app_subscriptions WHERE user_id = users.id AND active = true order by id desc limit 1) FROM users WHERE api_key = $1", apiKey)
+	row := FrontendDB.QueryRowContext(ctx, "SELECT id, (SELECT product_id from users_app_subscriptions WHERE user_id = users.id AND active = true order by id desc limit 1) FROM users WHERE api_key = $1", apiKey)
 	err := row.Scan(&data.ID, &data.Product)
 	return data, err
 }
 
-// DeleteUserById deletes a user.
-func DeleteUserById(id uint64) error {
-	_, err := FrontendDB.Exec("DELETE FROM users WHERE id = $1", id)
-	return err
-}
-
 // UpdatePassword updates the password of a user.
-func UpdatePassword(userId uint64, hash []byte) error {
-	_, err := FrontendDB.Exec("UPDATE users SET password = $1 WHERE id = $2", hash, userId)
+func UpdatePassword(ctx context.Context, userId uint64, hash []byte) error {
+	_, err := FrontendDB.ExecContext(ctx, "UPDATE users SET password = $1 WHERE id = $2", hash, userId)
 	return err
 }
 
 // AddAuthorizeCode registers a code that can be used in exchange for an access token
-func AddAuthorizeCode(userId uint64, code, clientId string, appId uint64) error {
+func AddAuthorizeCode(ctx context.Context, userId uint64, code, clientId string, appId uint64) error {
 	var dbClientID = clientId
 	if len(dbClientID) <= 5 { // remain backwards compatible
 		dbClientID = code
 	}
 	now := time.Now()
 	nowTs := now.Unix()
-	_, err := FrontendDB.Exec("INSERT INTO oauth_codes (user_id, code, app_id, created_ts, client_id) VALUES($1, $2, $3, TO_TIMESTAMP($4), $5) ON CONFLICT (user_id, app_id, client_id) DO UPDATE SET code = $2, created_ts = TO_TIMESTAMP($4), consumed = false", userId, code, appId, nowTs, dbClientID)
+	_, err := FrontendDB.ExecContext(ctx, "INSERT INTO oauth_codes (user_id, code, app_id, created_ts, client_id) VALUES($1, $2, $3, TO_TIMESTAMP($4), $5) ON CONFLICT (user_id, app_id, client_id) DO UPDATE SET code = $2, created_ts = TO_TIMESTAMP($4), consumed = false", userId, code, appId, nowTs, dbClientID)
 	return err
 }
 
 // GetAppNameFromRedirectUri receives an oauth redirect_url and returns the registered app name, if exists
-func GetAppDataFromRedirectUri(callback string) (*types.OAuthAppData, error) {
+func GetAppDataFromRedirectUri(ctx context.Context, callback string) (*types.OAuthAppData, error) {
 	data := []*types.OAuthAppData{}
-	err := FrontendDB.Select(&data, "SELECT id, app_name, redirect_uri, active, owner_id FROM oauth_apps WHERE active = true AND redirect_uri = $1", callback)
+	err := FrontendDB.SelectContext(ctx, &data, "SELECT id, app_name, redirect_uri, active, owner_id FROM oauth_apps WHERE active = true AND redirect_uri = $1", callback)
 	if err != nil {
 		return nil, err
 	}
@@ -107,14 +102,14 @@ func GetAppDataFromRedirectUri(callback string) (*types.OAuthAppData, error) {
 }
 
 // CreateAPIKey creates an API key for the user and saves it to the database
-func CreateAPIKey(userID uint64) error {
+func CreateAPIKey(ctx context.Context, userID uint64) error {
 	type user struct {
 		Password   string
 		RegisterTs time.Time
 		Email      string
 	}
 
-	tx, err := FrontendDB.Begin()
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -122,7 +117,7 @@ func CreateAPIKey(userID uint64) error {
 
 	u := user{}
 
-	row := tx.QueryRow("SELECT register_ts, password, email FROM users where id = $1", userID)
+	row := tx.QueryRowContext(ctx, "SELECT register_ts, password, email FROM users where id = $1", userID)
 	err = row.Scan(&u.RegisterTs, &u.Password, &u.Email)
 	if err != nil {
 		return err
@@ -132,7 +127,7 @@ func CreateAPIKey(userID uint64) error {
 	if err != nil {
 		return err
 	}
-	_, err = tx.Exec("UPDATE users SET api_key = $1 where id = $2", key, userID)
+	_, err = tx.ExecContext(ctx, "UPDATE users SET api_key = $1 where id = $2", key, userID)
 	if err != nil {
 		return err
 	}
@@ -145,9 +140,9 @@ func CreateAPIKey(userID uint64) error {
 }
 
 // GetUserAuthDataByAuthorizationCode checks an oauth code for validity, consumes the code and returns the userId on success
-func GetUserAuthDataByAuthorizationCode(code string) (*types.OAuthCodeData, error) {
+func GetUserAuthDataByAuthorizationCode(ctx context.Context, code string) (*types.OAuthCodeData, error) {
 	var rows []*types.OAuthCodeData
-	err := FrontendDB.Select(&rows, "UPDATE oauth_codes SET consumed = true WHERE code = $1 AND "+
+	err := FrontendDB.SelectContext(ctx, &rows, "UPDATE oauth_codes SET consumed = true WHERE code = $1 AND "+
 		"consumed = false AND created_ts + INTERVAL '35 minutes' > NOW() "+
 		"RETURNING user_id, app_id;", code)
 
@@ -165,9 +160,9 @@ func GetUserAuthDataByAuthorizationCode(code string) (*types.OAuthCodeData, erro
 }
 
 // GetByRefreshToken basically used to confirm the claimed user id with the refresh token. Returns the userId if successfull
-func GetByRefreshToken(claimUserID, claimAppID, claimDeviceID uint64, hashedRefreshToken string) (uint64, error) {
+func GetByRefreshToken(ctx context.Context, claimUserID, claimAppID, claimDeviceID uint64, hashedRefreshToken string) (uint64, error) {
 	var userID uint64
-	err := FrontendDB.Get(&userID,
+	err := FrontendDB.GetContext(ctx, &userID,
 		"SELECT user_id FROM users_devices WHERE user_id = $1 AND "+
 			"refresh_token = $2 AND app_id = $3 AND id = $4 AND active = true", claimUserID, hashedRefreshToken, claimAppID, claimDeviceID)
 
@@ -178,9 +173,9 @@ func GetByRefreshToken(claimUserID, claimAppID, claimDeviceID uint64, hashedRefr
 	return userID, nil
 }
 
-func GetUserMonitorSharingSetting(userID uint64) (bool, error) {
+func GetUserMonitorSharingSetting(ctx context.Context, userID uint64) (bool, error) {
 	var share bool
-	err := FrontendDB.Get(&share,
+	err := FrontendDB.GetContext(ctx, &share,
 		"SELECT share FROM stats_sharing WHERE user_id = $1 ORDER BY id desc limit 1", userID)
 
 	if err != nil {
@@ -193,18 +188,100 @@ func GetUserMonitorSharingSetting(userID uint64) (bool, error) {
 	return share, nil
 }
 
-func SetUserMonitorSharingSetting(userID uint64, share bool) error {
-	_, err := FrontendDB.Exec("INSERT INTO stats_sharing (user_id, share, ts) VALUES($1, $2, 'NOW()')",
+func SetUserMonitorSharingSetting(ctx context.Context, userID uint64, share bool) error {
+	_, err := FrontendDB.ExecContext(ctx, "INSERT INTO stats_sharing (user_id, share, ts) VALUES($1, $2, 'NOW()')",
 		userID, share,
 	)
 
 	return err
 }
 
-func GetUserDevicesByUserID(userID uint64) ([]types.PairedDevice, error) {
+// GetUserTheme returns the theme preference of the user, it is empty if the user has not chosen a theme
+func GetUserTheme(ctx context.Context, userID uint64) (string, error) {
+	var theme string
+	err := FrontendDB.GetContext(ctx, &theme, "SELECT COALESCE(theme, '') FROM users WHERE id = $1", userID)
+	return theme, err
+}
+
+// SetUserTheme saves the theme preference of the user
+func SetUserTheme(ctx context.Context, userID uint64, theme string) error {
+	_, err := FrontendDB.ExecContext(ctx, "UPDATE users SET theme = $1 WHERE id = $2", theme, userID)
+	return err
+}
+
+// SetUserLanguage saves the language preference of the user, it is used for the notification mails
+func SetUserLanguage(ctx context.Context, userID uint64, lang string) error {
+	_, err := FrontendDB.ExecContext(ctx, "UPDATE users SET language = $1 WHERE id = $2", lang, userID)
+	return err
+}
+
+// GetUserLanguagesByIds returns the language preferences of the users, users without preference are omitted
+func GetUserLanguagesByIds(ids []uint64) (map[uint64]string, error) {
+	languagesByID := map[uint64]string{}
+	if len(ids) == 0 {
+		return languagesByID, nil
+	}
+	var rows []struct {
+		ID       uint64 `db:"id"`
+		Language string `db:"language"`
+	}
+	err := FrontendDB.Select(&rows, "SELECT id, language FROM users WHERE id = ANY($1) AND language IS NOT NULL", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		languagesByID[r.ID] = r.Language
+	}
+	return languagesByID, nil
+}
+
+// GetUserFeeRecipient returns the fee recipient the user expects, nil is returned if the user has not configured one
+func GetUserFeeRecipient(ctx context.Context, userID uint64) (*types.UserFeeRecipient, error) {
+	feeRecipient := &types.UserFeeRecipient{}
+	err := FrontendDB.GetContext(ctx, feeRecipient, "SELECT user_id, address, allow_smoothing_pool, updated_ts FROM users_fee_recipients WHERE user_id = $1", userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return feeRecipient, nil
+}
+
+// GetUsersFeeRecipients returns the expected fee recipients of the users by their user id
+func GetUsersFeeRecipients(userIDs []uint64) (map[uint64]*types.UserFeeRecipient, error) {
+	feeRecipients := []*types.UserFeeRecipient{}
+	err := FrontendDB.Select(&feeRecipients, "SELECT user_id, address, allow_smoothing_pool, updated_ts FROM users_fee_recipients WHERE user_id = ANY($1)", pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	feeRecipientsByUserID := make(map[uint64]*types.UserFeeRecipient, len(feeRecipients))
+	for _, f := range feeRecipients {
+		feeRecipientsByUserID[f.UserID] = f
+	}
+	return feeRecipientsByUserID, nil
+}
+
+// SetUserFeeRecipient saves the fee recipient the user expects, an empty address removes it
+func SetUserFeeRecipient(ctx context.Context, userID uint64, address []byte, allowSmoothingPool bool) error {
+	if len(address) == 0 {
+		_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_fee_recipients WHERE user_id = $1", userID)
+		return err
+	}
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_fee_recipients (user_id, address, allow_smoothing_pool, updated_ts)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			address = excluded.address,
+			allow_smoothing_pool = excluded.allow_smoothing_pool,
+			updated_ts = excluded.updated_ts`, userID, address, allowSmoothingPool)
+	return err
+}
+
+func GetUserDevicesByUserID(ctx context.Context, userID uint64) ([]types.PairedDevice, error) {
 	data := []types.PairedDevice{}
 
-	rows, err := FrontendDB.Query(
+	rows, err := FrontendDB.QueryContext(ctx,
 		"SELECT users_devices.id, oauth_apps.app_name, users_devices.device_name, users_devices.active, "+
 			"users_devices.notify_enabled, users_devices.created_ts FROM users_devices "+
 			"left join oauth_apps on users_devices.app_id = oauth_apps.id WHERE users_devices.user_id = $1 order by created_ts desc", userID)
@@ -231,9 +308,9 @@ func GetUserDevicesByUserID(userID uint64) ([]types.PairedDevice, error) {
 }
 
 // InsertUserDevice Insert user device and return device id
-func InsertUserDevice(userID uint64, hashedRefreshToken string, name string, appID uint64) (uint64, error) {
+func InsertUserDevice(ctx context.Context, userID uint64, hashedRefreshToken string, name string, appID uint64) (uint64, error) {
 	var deviceID uint64
-	err := FrontendDB.Get(&deviceID, "INSERT INTO users_devices (user_id, refresh_token, device_name, app_id, created_ts) VALUES($1, $2, $3, $4, 'NOW()') RETURNING id",
+	err := FrontendDB.GetContext(ctx, &deviceID, "INSERT INTO users_devices (user_id, refresh_token, device_name, app_id, created_ts) VALUES($1, $2, $3, $4, 'NOW()') RETURNING id",
 		userID, hashedRefreshToken, name, appID,
 	)
 
@@ -244,15 +321,15 @@ func InsertUserDevice(userID uint64, hashedRefreshToken string, name string, app
 	return deviceID, nil
 }
 
-func MobileNotificatonTokenUpdate(userID, deviceID uint64, notifyToken string) error {
-	_, err := FrontendDB.Exec("UPDATE users_devices SET notification_token = $1 WHERE user_id = $2 AND id = $3;",
+func MobileNotificatonTokenUpdate(ctx context.Context, userID, deviceID uint64, notifyToken string) error {
+	_, err := FrontendDB.ExecContext(ctx, "UPDATE users_devices SET notification_token = $1 WHERE user_id = $2 AND id = $3;",
 		notifyToken, userID, deviceID,
 	)
 	return err
 }
 
 // AddSubscription adds a new subscription to the database.
-func AddSubscription(userID uint64, network string, eventName types.EventName, eventFilter string, eventThreshold float64) error {
+func AddSubscription(ctx context.Context, userID uint64, network string, eventName types.EventName, eventFilter string, eventThreshold float64) error {
 	now := time.Now()
 	nowTs := now.Unix()
 	nowEpoch := utils.TimeToEpoch(now)
@@ -267,7 +344,7 @@ func AddSubscription(userID uint64, network string, eventName types.EventName, e
 		name = strings.ToLower(network) + ":" + string(eventName)
 	}
 
-	_, err := FrontendDB.Exec("INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold) VALUES ($1, $2, $3, TO_TIMESTAMP($4), $5, $6) ON CONFLICT (user_id, event_name, event_filter) DO "+onConflictDo, userID, name, eventFilter, nowTs, nowEpoch, eventThreshold)
+	_, err := FrontendDB.ExecContext(ctx, "INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold) VALUES ($1, $2, $3, TO_TIMESTAMP($4), $5, $6) ON CONFLICT (user_id, event_name, event_filter) DO "+onConflictDo, userID, name, eventFilter, nowTs, nowEpoch, eventThreshold)
 	return err
 }
 
@@ -309,38 +386,38 @@ func AddTestSubscription(userID uint64, network string, eventName types.EventNam
 }
 
 // DeleteSubscription removes a subscription from the database.
-func DeleteSubscription(userID uint64, network string, eventName types.EventName, eventFilter string) error {
+func DeleteSubscription(ctx context.Context, userID uint64, network string, eventName types.EventName, eventFilter string) error {
 	name := string(eventName)
 	if network != "" {
 		name = strings.ToLower(network) + ":" + string(eventName)
 	}
 
-	_, err := FrontendDB.Exec("DELETE FROM users_subscriptions WHERE user_id = $1 and event_name = $2 and event_filter = $3", userID, name, eventFilter)
+	_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_subscriptions WHERE user_id = $1 and event_name = $2 and event_filter = $3", userID, name, eventFilter)
 	return err
 }
 
-func InsertMobileSubscription(userID uint64, paymentDetails types.MobileSubscription, store, receipt string, expiration int64, rejectReson string, extSubscriptionId string) error {
+func InsertMobileSubscription(ctx context.Context, userID uint64, paymentDetails types.MobileSubscription, store, receipt string, expiration int64, rejectReson string, extSubscriptionId string) error {
 	now := time.Now()
 	nowTs := now.Unix()
 	receiptHash := utils.HashAndEncode(receipt)
-	_, err := FrontendDB.Exec("INSERT INTO users_app_subscriptions (user_id, product_id, price_micros, currency, created_at, updated_at, validate_remotely, active, store, receipt, expires_at, reject_reason, receipt_hash, subscription_id) VALUES("+
+	_, err := FrontendDB.ExecContext(ctx, "INSERT INTO users_app_subscriptions (user_id, product_id, price_micros, currency, created_at, updated_at, validate_remotely, active, store, receipt, expires_at, reject_reason, receipt_hash, subscription_id) VALUES("+
 		"$1, $2, $3, $4, TO_TIMESTAMP($5), TO_TIMESTAMP($6), $7, $8, $9, $10, TO_TIMESTAMP($11), $12, $13, $14);",
 		userID, paymentDetails.ProductID, paymentDetails.PriceMicros, paymentDetails.Currency, nowTs, nowTs, paymentDetails.Valid, paymentDetails.Valid, store, receipt, expiration, rejectReson, receiptHash, extSubscriptionId,
 	)
 	return err
 }
 
-func ChangeProductIDFromStripe(stripeSubscriptionID string, productID string) error {
+func ChangeProductIDFromStripe(ctx context.Context, stripeSubscriptionID string, productID string) error {
 	now := time.Now()
 	nowTs := now.Unix()
 
-	tx, err := FrontendDB.Begin()
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("UPDATE users_app_subscriptions SET product_id = $2, updated_at = TO_TIMESTAMP($3) where subscription_id = $1 AND store = 'stripe'", stripeSubscriptionID, productID, nowTs)
+	_, err = tx.ExecContext(ctx, "UPDATE users_app_subscriptions SET product_id = $2, updated_at = TO_TIMESTAMP($3) where subscription_id = $1 AND store = 'stripe'", stripeSubscriptionID, productID, nowTs)
 	if err != nil {
 		return err
 	}
@@ -349,9 +426,9 @@ func ChangeProductIDFromStripe(stripeSubscriptionID string, productID string) er
 	return err
 }
 
-func GetAppSubscriptionCount(userID uint64) (int64, error) {
+func GetAppSubscriptionCount(ctx context.Context, userID uint64) (int64, error) {
 	var count int64
-	row := FrontendDB.QueryRow(
+	row := FrontendDB.QueryRowContext(ctx,
 		"SELECT count(receipt) as count FROM users_app_subscriptions WHERE user_id = $1",
 		userID,
 	)
@@ -364,18 +441,18 @@ type PremiumResult struct {
 	Store   string `db:"store"`
 }
 
-func GetUserPremiumPackage(userID uint64) (PremiumResult, error) {
+func GetUserPremiumPackage(ctx context.Context, userID uint64) (PremiumResult, error) {
 	var pkg PremiumResult
-	err := FrontendDB.Get(&pkg,
+	err := FrontendDB.GetContext(ctx, &pkg,
 		"SELECT COALESCE(product_id, '') as product_id, COALESCE(store, '') as store from users_app_subscriptions WHERE user_id = $1 AND active = true order by id desc",
 		userID,
 	)
 	return pkg, err
 }
 
-func GetUserPremiumSubscription(id uint64) (types.UserPremiumSubscription, error) {
+func GetUserPremiumSubscription(ctx context.Context, id uint64) (types.UserPremiumSubscription, error) {
 	userSub := types.UserPremiumSubscription{}
-	err := FrontendDB.Get(&userSub, "SELECT user_id, store, active, COALESCE(product_id, '') as product_id, COALESCE(reject_reason, '') as reject_reason FROM users_app_subscriptions WHERE user_id = $1 ORDER BY active desc, id desc LIMIT 1", id)
+	err := FrontendDB.GetContext(ctx, &userSub, "SELECT user_id, store, active, COALESCE(product_id, '') as product_id, COALESCE(reject_reason, '') as reject_reason FROM users_app_subscriptions WHERE user_id = $1 ORDER BY active desc, id desc LIMIT 1", id)
 	return userSub, err
 }
 
@@ -389,23 +466,23 @@ func GetAllAppSubscriptions() ([]*types.PremiumData, error) {
 	return data, err
 }
 
-func DisableAllSubscriptionsFromStripeUser(stripeCustomerID string) error {
-	userID, err := StripeGetCustomerUserId(stripeCustomerID)
+func DisableAllSubscriptionsFromStripeUser(ctx context.Context, stripeCustomerID string) error {
+	userID, err := StripeGetCustomerUserId(ctx, stripeCustomerID)
 	if err != nil {
 		return err
 	}
 
 	now := time.Now()
 	nowTs := now.Unix()
-	_, err = FrontendDB.Exec("UPDATE users_app_subscriptions SET active = $1, updated_at = TO_TIMESTAMP($2), expires_at = TO_TIMESTAMP($3), reject_reason = $4 WHERE user_id = $5 AND store = 'stripe';",
+	_, err = FrontendDB.ExecContext(ctx, "UPDATE users_app_subscriptions SET active = $1, updated_at = TO_TIMESTAMP($2), expires_at = TO_TIMESTAMP($3), reject_reason = $4 WHERE user_id = $5 AND store = 'stripe';",
 		false, nowTs, nowTs, "stripe_user_deleted", userID,
 	)
 	return err
 }
 
-func GetUserSubscriptionIDByStripe(stripeSubscriptionID string) (uint64, error) {
+func GetUserSubscriptionIDByStripe(ctx context.Context, stripeSubscriptionID string) (uint64, error) {
 	var subscriptionID uint64
-	row := FrontendDB.QueryRow(
+	row := FrontendDB.QueryRowContext(ctx,
 		"SELECT id from users_app_subscriptions WHERE subscription_id = $1",
 		stripeSubscriptionID,
 	)
@@ -413,10 +490,10 @@ func GetUserSubscriptionIDByStripe(stripeSubscriptionID string) (uint64, error)
 	return subscriptionID, err
 }
 
-func UpdateUserSubscription(id uint64, valid bool, expiration int64, rejectReason string) error {
+func UpdateUserSubscription(ctx context.Context, id uint64, valid bool, expiration int64, rejectReason string) error {
 	now := time.Now()
 	nowTs := now.Unix()
-	_, err := FrontendDB.Exec("UPDATE users_app_subscriptions SET active = $1, updated_at = TO_TIMESTAMP($2), expires_at = TO_TIMESTAMP($3), reject_reason = $4 WHERE id = $5;",
+	_, err := FrontendDB.ExecContext(ctx, "UPDATE users_app_subscriptions SET active = $1, updated_at = TO_TIMESTAMP($2), expires_at = TO_TIMESTAMP($3), reject_reason = $4 WHERE id = $5;",
 		valid, nowTs, expiration, rejectReason, id,
 	)
 	return err
@@ -448,7 +525,7 @@ func GetUserPushTokenByIds(ids []uint64) (map[uint64][]string, error) {
 	return pushByID, nil
 }
 
-func MobileDeviceSettingsUpdate(userID, deviceID uint64, notifyEnabled, active string) (*sql.Rows, error) {
+func MobileDeviceSettingsUpdate(ctx context.Context, userID, deviceID uint64, notifyEnabled, active string) (*sql.Rows, error) {
 	var query = ""
 	var args []interface{}
 
@@ -469,14 +546,14 @@ func MobileDeviceSettingsUpdate(userID, deviceID uint64, notifyEnabled, active s
 		return nil, errors.New("No params for change provided")
 	}
 
-	rows, err := FrontendDB.Query("UPDATE users_devices SET "+query+" WHERE user_id = $1 AND id = $2 RETURNING notify_enabled;",
+	rows, err := FrontendDB.QueryContext(ctx, "UPDATE users_devices SET "+query+" WHERE user_id = $1 AND id = $2 RETURNING notify_enabled;",
 		args...,
 	)
 	return rows, err
 }
 
-func MobileDeviceDelete(userID, deviceID uint64) error {
-	_, err := FrontendDB.Exec("DELETE FROM users_devices WHERE user_id = $1 AND id = $2 AND id != 2;", userID, deviceID)
+func MobileDeviceDelete(ctx context.Context, userID, deviceID uint64) error {
+	_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_devices WHERE user_id = $1 AND id = $2 AND id != 2;", userID, deviceID)
 	return err
 }
 
@@ -489,8 +566,8 @@ func addParamToQuery(query, param string) string {
 	return result
 }
 
-func MobileDeviceSettingsSelect(userID, deviceID uint64) (*sql.Rows, error) {
-	rows, err := FrontendDB.Query("SELECT notify_enabled FROM users_devices WHERE user_id = $1 AND id = $2;",
+func MobileDeviceSettingsSelect(ctx context.Context, userID, deviceID uint64) (*sql.Rows, error) {
+	rows, err := FrontendDB.QueryContext(ctx, "SELECT notify_enabled FROM users_devices WHERE user_id = $1 AND id = $2;",
 		userID, deviceID,
 	)
 	return rows, err
@@ -570,13 +647,13 @@ func CleanupOldMachineStats() error {
 	return nil
 }
 
-func GetStatsMachineCount(userID uint64) (uint64, error) {
+func GetStatsMachineCount(ctx context.Context, userID uint64) (uint64, error) {
 	now := time.Now()
 	nowTs := now.Unix()
 	var day int = int(nowTs / 86400)
 
 	var count uint64
-	row := FrontendDB.QueryRow(
+	row := FrontendDB.QueryRowContext(ctx,
 		"SELECT COUNT(DISTINCT sub.machine) as count FROM (SELECT machine from stats_meta_p WHERE day = $2 AND user_id = $1 AND created_trunc + '15 minutes'::INTERVAL > 'now' LIMIT 15) sub",
 		userID, day,
 	)
@@ -584,7 +661,7 @@ func GetStatsMachineCount(userID uint64) (uint64, error) {
 	return count, err
 }
 
-func GetStatsMachine(userID uint64) ([]string, error) {
+func GetStatsMachine(ctx context.Context, userID uint64) ([]string, error) {
 	now := time.Now()
 	nowTs := now.Unix()
 	var day int = int(nowTs / 86400)
@@ -594,20 +671,20 @@ func GetStatsMachine(userID uint64) ([]string, error) {
 	// log.Println("getting machine for day: ", day)
 
 	var machines []string
-	err := FrontendDB.Select(&machines,
+	err := FrontendDB.SelectContext(ctx, &machines,
 		"SELECT DISTINCT machine from stats_meta_p WHERE day = $2 AND user_id = $1 LIMIT 300",
 		userID, day,
 	)
 	return machines, err
 }
 
-func InsertStatsMeta(tx *sql.Tx, userID uint64, data *types.StatsMeta) (uint64, error) {
+func InsertStatsMeta(ctx context.Context, tx *sql.Tx, userID uint64, data *types.StatsMeta) (uint64, error) {
 	now := time.Now()
 	nowTs := now.Unix()
 	var day int = int(nowTs / 86400)
 
 	var id uint64
-	row := tx.QueryRow(
+	row := tx.QueryRowContext(ctx,
 		"INSERT INTO stats_meta_p (user_id, machine, ts, version, process, created_trunc, exporter_version, day) VALUES($1, $2, TO_TIMESTAMP($3), $4, $5, date_trunc('minute', TO_TIMESTAMP($6)), $7, $8) RETURNING id",
 		userID, data.Machine, data.Timestamp, data.Version, data.Process, nowTs, data.ExporterVersion, day,
 	)
@@ -616,7 +693,7 @@ func InsertStatsMeta(tx *sql.Tx, userID uint64, data *types.StatsMeta) (uint64,
 	return id, err
 }
 
-func CreateNewStatsMetaPartition() error {
+func CreateNewStatsMetaPartition(ctx context.Context) error {
 
 	now := time.Now()
 	nowTs := now.Unix()
@@ -625,12 +702,12 @@ func CreateNewStatsMetaPartition() error {
 	partitionName := "stats_meta_" + strconv.Itoa(day)
 	logger.Info("creating new partition table " + partitionName)
 
-	_, err := FrontendDB.Exec("CREATE TABLE " + partitionName + " PARTITION OF stats_meta_p FOR VALUES IN (" + strconv.Itoa(day) + ")")
+	_, err := FrontendDB.ExecContext(ctx, "CREATE TABLE "+partitionName+" PARTITION OF stats_meta_p FOR VALUES IN ("+strconv.Itoa(day)+")")
 	if err != nil {
 		logger.Errorf("error creating partition %v", err)
 		return err
 	}
-	_, err = FrontendDB.Exec("CREATE UNIQUE INDEX " + partitionName + "_user_id_created_trunc_process_machine_key ON public." + partitionName + " USING btree (user_id, created_trunc, process, machine)")
+	_, err = FrontendDB.ExecContext(ctx, "CREATE UNIQUE INDEX "+partitionName+"_user_id_created_trunc_process_machine_key ON public."+partitionName+" USING btree (user_id, created_trunc, process, machine)")
 	if err != nil {
 		logger.Errorf("error creating index %v", err)
 		return err
@@ -639,9 +716,9 @@ func CreateNewStatsMetaPartition() error {
 	return err
 }
 
-func InsertStatsSystem(tx *sql.Tx, meta_id uint64, data *types.StatsSystem) (uint64, error) {
+func InsertStatsSystem(ctx context.Context, tx *sql.Tx, meta_id uint64, data *types.StatsSystem) (uint64, error) {
 	var id uint64
-	row := tx.QueryRow(
+	row := tx.QueryRowContext(ctx,
 		"INSERT INTO stats_system (meta_id, cpu_cores, cpu_threads, cpu_node_system_seconds_total, "+
 			"cpu_node_user_seconds_total, cpu_node_iowait_seconds_total, cpu_node_idle_seconds_total,"+
 			"memory_node_bytes_total, memory_node_bytes_free, memory_node_bytes_cached, memory_node_bytes_buffers,"+
@@ -659,9 +736,9 @@ func InsertStatsSystem(tx *sql.Tx, meta_id uint64, data *types.StatsSystem) (uin
 	return id, err
 }
 
-func InsertStatsProcessGeneral(tx *sql.Tx, meta_id uint64, data *types.StatsProcess) (uint64, error) {
+func InsertStatsProcessGeneral(ctx context.Context, tx *sql.Tx, meta_id uint64, data *types.StatsProcess) (uint64, error) {
 	var id uint64
-	row := tx.QueryRow(
+	row := tx.QueryRowContext(ctx,
 		"INSERT INTO stats_process (meta_id, cpu_process_seconds_total, memory_process_bytes, client_name, client_version,"+
 			"client_build, sync_eth2_fallback_configured,"+
 			"sync_eth2_fallback_connected"+
@@ -674,9 +751,9 @@ func InsertStatsProcessGeneral(tx *sql.Tx, meta_id uint64, data *types.StatsProc
 	return id, err
 }
 
-func InsertStatsValidator(tx *sql.Tx, general_id uint64, data *types.StatsAdditionalsValidator) (uint64, error) {
+func InsertStatsValidator(ctx context.Context, tx *sql.Tx, general_id uint64, data *types.StatsAdditionalsValidator) (uint64, error) {
 	var id uint64
-	_, err := tx.Exec(
+	_, err := tx.ExecContext(ctx,
 		"INSERT INTO stats_add_validator (general_id, validator_total, validator_active) "+
 			"VALUES($1, $2, $3)",
 		general_id, data.ValidatorTotal, data.ValidatorActive,
@@ -685,9 +762,9 @@ func InsertStatsValidator(tx *sql.Tx, general_id uint64, data *types.StatsAdditi
 	return id, err
 }
 
-func InsertStatsBeaconnode(tx *sql.Tx, general_id uint64, data *types.StatsAdditionalsBeaconnode) (uint64, error) {
+func InsertStatsBeaconnode(ctx context.Context, tx *sql.Tx, general_id uint64, data *types.StatsAdditionalsBeaconnode) (uint64, error) {
 	var id uint64
-	_, err := tx.Exec(
+	_, err := tx.ExecContext(ctx,
 		"INSERT INTO stats_add_beaconnode (general_id, disk_beaconchain_bytes_total, network_libp2p_bytes_total_receive,"+
 			"network_libp2p_bytes_total_transmit, network_peers_connected, sync_eth1_connected, sync_eth2_synced,"+
 			"sync_beacon_head_slot, sync_eth1_fallback_configured, sync_eth1_fallback_connected"+
@@ -699,8 +776,8 @@ func InsertStatsBeaconnode(tx *sql.Tx, general_id uint64, data *types.StatsAddit
 	return id, err
 }
 
-func NewTransaction() (*sql.Tx, error) {
-	return FrontendDB.Begin()
+func NewTransaction(ctx context.Context) (*sql.Tx, error) {
+	return FrontendDB.BeginTx(ctx, nil)
 }
 
 func getMachineStatsGap(resultCount uint64) int {
@@ -731,10 +808,10 @@ func getMaxDay(limit uint64) int {
 	return day - dayRange
 }
 
-func GetStatsValidator(userID, limit, offset uint64) (*sql.Rows, error) {
+func GetStatsValidator(ctx context.Context, userID, limit, offset uint64) (*sql.Rows, error) {
 	gapSize := getMachineStatsGap(limit)
 	maxDay := getMaxDay(limit)
-	row, err := FrontendDB.Query(
+	row, err := FrontendDB.QueryContext(ctx,
 		"SELECT t.* FROM (SELECT client_name, client_version, cpu_process_seconds_total, machine, memory_process_bytes, sync_eth2_fallback_configured, sync_eth2_fallback_connected, ts as timestamp, validator_active, validator_total, row_number() OVER(ORDER BY stats_meta_p.id desc) as row FROM stats_add_validator LEFT JOIN stats_process ON stats_add_validator.general_id = stats_process.id "+
 			" LEFT JOIN stats_meta_p on stats_process.meta_id = stats_meta_p.id "+
 			"WHERE stats_meta_p.day >= $5 AND user_id = $1 AND process = 'validator' ORDER BY stats_meta_p.id desc LIMIT $2 OFFSET $3) t where t.row % $4 = 0",
@@ -743,10 +820,10 @@ func GetStatsValidator(userID, limit, offset uint64) (*sql.Rows, error) {
 	return row, err
 }
 
-func GetStatsNode(userID, limit, offset uint64) (*sql.Rows, error) {
+func GetStatsNode(ctx context.Context, userID, limit, offset uint64) (*sql.Rows, error) {
 	gapSize := getMachineStatsGap(limit)
 	maxDay := getMaxDay(limit)
-	row, err := FrontendDB.Query(
+	row, err := FrontendDB.QueryContext(ctx,
 		"SELECT t.* FROM (SELECT client_name, client_version, cpu_process_seconds_total, machine, memory_process_bytes, sync_eth1_fallback_configured, sync_eth1_fallback_connected, sync_eth2_fallback_configured, sync_eth2_fallback_connected, ts as timestamp, disk_beaconchain_bytes_total, network_libp2p_bytes_total_receive, network_libp2p_bytes_total_transmit, network_peers_connected, sync_eth1_connected, sync_eth2_synced, sync_beacon_head_slot, row_number() OVER(ORDER BY stats_meta_p.id desc) as row FROM stats_add_beaconnode left join stats_process on stats_process.id = stats_add_beaconnode.general_id "+
 			" LEFT JOIN stats_meta_p on stats_process.meta_id = stats_meta_p.id "+
 			"WHERE stats_meta_p.day >= $5 AND user_id = $1 AND process = 'beaconnode' ORDER BY stats_meta_p.id desc LIMIT $2 OFFSET $3) t where t.row % $4 = 0",
@@ -755,10 +832,10 @@ func GetStatsNode(userID, limit, offset uint64) (*sql.Rows, error) {
 	return row, err
 }
 
-func GetStatsSystem(userID, limit, offset uint64) (*sql.Rows, error) {
+func GetStatsSystem(ctx context.Context, userID, limit, offset uint64) (*sql.Rows, error) {
 	gapSize := getMachineStatsGap(limit)
 	maxDay := getMaxDay(limit)
-	row, err := FrontendDB.Query(
+	row, err := FrontendDB.QueryContext(ctx,
 		"SELECT t.* FROM (SELECT cpu_cores, cpu_threads, cpu_node_system_seconds_total, cpu_node_user_seconds_total, cpu_node_iowait_seconds_total, cpu_node_idle_seconds_total, memory_node_bytes_total, memory_node_bytes_free, memory_node_bytes_cached, memory_node_bytes_buffers, disk_node_bytes_total, disk_node_bytes_free, disk_node_io_seconds, disk_node_reads_total, disk_node_writes_total, network_node_bytes_total_receive, network_node_bytes_total_transmit, misc_os, misc_node_boot_ts_seconds, ts as timestamp, machine, row_number() OVER(ORDER BY stats_meta_p.id desc) as row from stats_system"+
 			" LEFT JOIN stats_meta_p on stats_system.meta_id = stats_meta_p.id "+
 			"WHERE stats_meta_p.day >= $5 AND user_id = $1 AND process = 'system' ORDER BY stats_meta_p.id desc LIMIT $2 OFFSET $3) t where t.row % $4 = 0",
@@ -793,7 +870,7 @@ func GetHistoricPrices(currency string) (map[uint64]float64, error) {
 	return dataMap, nil
 }
 
-func GetUserAPIKeyStatistics(apikey *string) (*types.ApiStatistics, error) {
+func GetUserAPIKeyStatistics(ctx context.Context, apikey *string) (*types.ApiStatistics, error) {
 	stats := &types.ApiStatistics{}
 
 	query := fmt.Sprintf(`
@@ -813,7 +890,7 @@ func GetUserAPIKeyStatistics(apikey *string) (*types.ApiStatistics, error) {
 			ts > NOW() - INTERVAL '1 month' AND apikey = $1
 	)`)
 
-	err := FrontendDB.Get(stats, query, apikey)
+	err := FrontendDB.GetContext(ctx, stats, query, apikey)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,282 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// The sources of a hosting declaration, the provider of the metrics source is inferred from the node metrics submissions
+// of the owner
+const (
+	HostingSourceDeclared = "declared"
+	HostingSourceMetrics  = "metrics"
+)
+
+// The providers that can be declared next to the configured ones
+const (
+	HostingProviderSelfHosted = "Self-hosted"
+	HostingProviderOther      = "Other"
+)
+
+// HostingRegions are the regions a hosting can be declared in
+var HostingRegions = []string{"Africa", "Asia", "Europe", "North America", "Oceania", "South America"}
+
+// HostingStatsMinUsers is the minimal number of owners whose validators are aggregated into a published provider and
+// region, smaller groups are merged into the other providers of their region and dropped if that is still too small
+const HostingStatsMinUsers = 3
+
+// HostingProviders returns the providers a hosting can be declared at
+func HostingProviders() []string {
+	providers := make([]string, 0, len(utils.Config.Frontend.HostingProviders)+2)
+	for _, p := range utils.Config.Frontend.HostingProviders {
+		providers = append(providers, p.Name)
+	}
+	return append(providers, HostingProviderSelfHosted, HostingProviderOther)
+}
+
+// SaveValidatorHosting stores the hosting a user declared for a validator, a previous declaration of the user is replaced
+func SaveValidatorHosting(ctx context.Context, hosting *types.ValidatorHosting) error {
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_validator_hosting (user_id, validator_publickey, provider, region, source, updated_ts)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, validator_publickey) DO UPDATE SET
+			provider = excluded.provider,
+			region = excluded.region,
+			source = excluded.source,
+			updated_ts = excluded.updated_ts`,
+		hosting.UserID, hosting.ValidatorPublickey, hosting.Provider, hosting.Region, hosting.Source, hosting.UpdatedTs)
+	return err
+}
+
+// DeleteValidatorHosting removes the hosting the user declared for the validator
+func DeleteValidatorHosting(ctx context.Context, userID uint64, pubkey []byte) error {
+	_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_validator_hosting WHERE user_id = $1 AND validator_publickey = $2", userID, pubkey)
+	return err
+}
+
+// GetValidatorHosting returns the hosting the user declared for the validator, nil is returned if the user has not declared one
+func GetValidatorHosting(ctx context.Context, userID uint64, pubkey []byte) (*types.ValidatorHosting, error) {
+	hosting := &types.ValidatorHosting{}
+	err := FrontendDB.GetContext(ctx, hosting, `
+		SELECT user_id, validator_publickey, provider, region, source, updated_ts
+		FROM users_validator_hosting
+		WHERE user_id = $1 AND validator_publickey = $2`, userID, pubkey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hosting, nil
+}
+
+// UpdateInferredValidatorHosting sets the provider of all validators the user opted in to infer the hosting of from
+// their node metrics submissions
+func UpdateInferredValidatorHosting(ctx context.Context, userID uint64, provider string) error {
+	_, err := FrontendDB.ExecContext(ctx, `
+		UPDATE users_validator_hosting SET provider = $3, updated_ts = $4
+		WHERE user_id = $1 AND source = $2 AND provider <> $3`, userID, HostingSourceMetrics, provider, time.Now())
+	return err
+}
+
+type hostingStatsGroup struct {
+	provider           string
+	region             string
+	users              map[uint64]bool
+	validators         uint64
+	effectiveness      float64
+	missedAttestations uint64
+}
+
+func (g *hostingStatsGroup) merge(o *hostingStatsGroup) {
+	for u := range o.users {
+		g.users[u] = true
+	}
+	g.validators += o.validators
+	g.effectiveness += o.effectiveness
+	g.missedAttestations += o.missedAttestations
+}
+
+// UpdateHostingStatsForDay aggregates the effectiveness and the missed attestations of the day of the validators whose
+// verified owners declared their hosting per provider and region. Only groups of at least HostingStatsMinUsers owners
+// are stored so no single operator can be identified.
+func UpdateHostingStatsForDay(day uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_hosting_stats").Observe(time.Since(start).Seconds())
+	}()
+
+	declarations := []struct {
+		UserID   uint64 `db:"user_id"`
+		Pubkey   []byte `db:"validator_publickey"`
+		Provider string `db:"provider"`
+		Region   string `db:"region"`
+	}{}
+	err := FrontendDB.Select(&declarations, `
+		SELECT DISTINCT ON (h.validator_publickey) h.user_id, h.validator_publickey, h.provider, h.region
+		FROM users_validator_hosting h
+			INNER JOIN users_validator_ownership o ON o.user_id = h.user_id AND o.validator_publickey = h.validator_publickey
+		WHERE h.provider <> ''
+		ORDER BY h.validator_publickey, h.updated_ts DESC`)
+	if err != nil {
+		return fmt.Errorf("error retrieving validator hosting declarations: %w", err)
+	}
+
+	pubkeys := make([][]byte, len(declarations))
+	for i, d := range declarations {
+		pubkeys[i] = d.Pubkey
+	}
+	performance := []struct {
+		Pubkey             []byte  `db:"pubkey"`
+		Effectiveness      float64 `db:"effectiveness"`
+		MissedAttestations uint64  `db:"missed_attestations"`
+	}{}
+	err = DB.Select(&performance, `
+		SELECT v.pubkey, e.effectiveness, COALESCE(s.missed_attestations, 0) AS missed_attestations
+		FROM validators v
+			INNER JOIN validator_effectiveness e ON e.validatorindex = v.validatorindex AND e.day = $2 AND e.formula = $3
+			INNER JOIN validator_stats s ON s.validatorindex = v.validatorindex AND s.day = $2
+		WHERE v.pubkey = ANY($1)`, pq.ByteaArray(pubkeys), day, DefaultEffectivenessFormula)
+	if err != nil {
+		return fmt.Errorf("error retrieving performance of hosted validators: %w", err)
+	}
+	performanceByPubkey := make(map[string]int, len(performance))
+	for i, p := range performance {
+		performanceByPubkey[string(p.Pubkey)] = i
+	}
+
+	groups := map[string]*hostingStatsGroup{}
+	group := func(provider, region string) *hostingStatsGroup {
+		key := provider + "\x00" + region
+		g, exists := groups[key]
+		if !exists {
+			g = &hostingStatsGroup{provider: provider, region: region, users: map[uint64]bool{}}
+			groups[key] = g
+		}
+		return g
+	}
+	for _, d := range declarations {
+		i, exists := performanceByPubkey[string(d.Pubkey)]
+		if !exists {
+			continue
+		}
+		g := group(d.Provider, d.Region)
+		g.users[d.UserID] = true
+		g.validators++
+		g.effectiveness += performance[i].Effectiveness
+		g.missedAttestations += performance[i].MissedAttestations
+	}
+
+	// small groups are merged into the other providers of their region first and into the other providers of all
+	// regions if that is still too small
+	mergeSmallGroups := func(target func(g *hostingStatsGroup) (string, string)) {
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			g := groups[key]
+			provider, region := target(g)
+			if len(g.users) >= HostingStatsMinUsers || g.provider == provider && g.region == region {
+				continue
+			}
+			delete(groups, key)
+			group(provider, region).merge(g)
+		}
+	}
+	mergeSmallGroups(func(g *hostingStatsGroup) (string, string) { return HostingProviderOther, g.region })
+	mergeSmallGroups(func(g *hostingStatsGroup) (string, string) { return HostingProviderOther, "" })
+
+	stats := []*types.HostingStats{}
+	var validators uint64
+	for _, g := range groups {
+		if len(g.users) < HostingStatsMinUsers {
+			continue
+		}
+		validators += g.validators
+		stats = append(stats, &types.HostingStats{
+			Day:                    day,
+			Provider:               g.provider,
+			Region:                 g.region,
+			Validators:             g.validators,
+			Effectiveness:          g.effectiveness / float64(g.validators),
+			MissedAttestationsRate: float64(g.missedAttestations) / float64(g.validators*utils.EpochsPerDay()),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Validators != stats[j].Validators {
+			return stats[i].Validators > stats[j].Validators
+		}
+		return stats[i].Provider+stats[i].Region < stats[j].Provider+stats[j].Region
+	})
+
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM hosting_stats WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+	for _, s := range stats {
+		s.Share = float64(s.Validators) / float64(validators)
+		_, err = tx.Exec(`
+			INSERT INTO hosting_stats (day, provider, region, validators, share, effectiveness, missed_attestations_rate)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			s.Day, s.Provider, s.Region, s.Validators, s.Share, s.Effectiveness, s.MissedAttestationsRate)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	logger.Infof("updated hosting stats of %v validators in %v groups for day %v, took %v", validators, len(stats), day, time.Since(start))
+	return nil
+}
+
+// GetLastHostingStatsDay returns the last day the hosting stats have been computed for, false is returned if they have
+// not been computed yet
+func GetLastHostingStatsDay(ctx context.Context) (uint64, bool, error) {
+	var day *uint64
+	err := DB.GetContext(ctx, &day, "SELECT MAX(day) FROM hosting_stats")
+	if err != nil || day == nil {
+		return 0, false, err
+	}
+	return *day, true, nil
+}
+
+// GetHostingStats returns the daily hosting stats from startDay on ordered by day and size of the group
+func GetHostingStats(ctx context.Context, startDay uint64) ([]*types.HostingStats, error) {
+	stats := []*types.HostingStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT day, provider, region, validators, share, effectiveness, missed_attestations_rate
+		FROM hosting_stats
+		WHERE day >= $1
+		ORDER BY day, validators DESC, provider, region`, startDay)
+	return stats, err
+}
+
+// GetLatestHostingStats returns the hosting stats of the last computed day ordered by the size of the group
+func GetLatestHostingStats(ctx context.Context) ([]*types.HostingStats, error) {
+	stats := []*types.HostingStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT day, provider, region, validators, share, effectiveness, missed_attestations_rate
+		FROM hosting_stats
+		WHERE day = (SELECT MAX(day) FROM hosting_stats)
+		ORDER BY validators DESC, provider, region`)
+	return stats, err
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ValidatorIncomeHistory is the data the income of a validator is projected from
+type ValidatorIncomeHistory struct {
+	Validatorindex   uint64 `db:"validatorindex"`
+	EffectiveBalance uint64 `db:"effectivebalance"`
+	ActivationEpoch  uint64 `db:"activationepoch"`
+	ExitEpoch        uint64 `db:"exitepoch"`
+	Performance31d   int64  `db:"performance31d"`
+}
+
+// GetValidatorIncomeHistory returns the income of the last 31 days of the validator with the given pubkey or, if the
+// pubkey is nil, index. Nil is returned if the validator does not exist.
+func GetValidatorIncomeHistory(ctx context.Context, index uint64, pubkey []byte) (*ValidatorIncomeHistory, error) {
+	condition, arg := "v.validatorindex = $1", interface{}(index)
+	if pubkey != nil {
+		condition, arg = "v.pubkey = $1", pubkey
+	}
+
+	history := &ValidatorIncomeHistory{}
+	err := DB.GetContext(ctx, history, `
+		SELECT
+			v.validatorindex,
+			v.effectivebalance,
+			v.activationepoch,
+			v.exitepoch,
+			COALESCE(vp.performance31d, 0) AS performance31d
+		FROM validators v
+			LEFT JOIN validator_performance vp ON vp.validatorindex = v.validatorindex
+		WHERE `+condition, arg)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetAverageGlobalParticipationRate returns the average participation rate of the network since the given epoch
+func GetAverageGlobalParticipationRate(ctx context.Context, startEpoch uint64) (float64, error) {
+	var rate float64
+	err := DB.GetContext(ctx, &rate, `SELECT COALESCE(AVG(globalparticipationrate), 0) FROM epochs WHERE epoch >= $1 AND eligibleether > 0`, startEpoch)
+	return rate, err
+}
+
+// GetValidatorIncomeSinceDay returns the income of the validator on the days since the given day (inclusive) and the
+// number of days with statistics
+func GetValidatorIncomeSinceDay(ctx context.Context, index uint64, day uint64) (int64, uint64, error) {
+	res := struct {
+		Income int64  `db:"income"`
+		Days   uint64 `db:"days"`
+	}{}
+	err := DB.GetContext(ctx, &res, `
+		SELECT
+			COALESCE(SUM(end_balance - start_balance - COALESCE(deposits_amount, 0)), 0) AS income,
+			COUNT(*) AS days
+		FROM validator_stats
+		WHERE validatorindex = $1 AND day >= $2`, index, day)
+	return res.Income, res.Days, err
+}
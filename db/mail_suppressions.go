@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// AddMailSuppression adds the address to the suppression list, no further mails are sent to suppressed addresses. The
+// first reason of an address is kept.
+func AddMailSuppression(ctx context.Context, email, reason, provider, details string) error {
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO mail_suppressions (email, reason, provider, details, created_ts)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (email) DO NOTHING`,
+		strings.ToLower(email), reason, provider, details, time.Now())
+	return err
+}
+
+// IsMailSuppressed returns true if the address is on the suppression list
+func IsMailSuppressed(email string) (bool, error) {
+	var suppressed bool
+	err := FrontendDB.Get(&suppressed, "SELECT EXISTS (SELECT 1 FROM mail_suppressions WHERE email = $1)", strings.ToLower(email))
+	return suppressed, err
+}
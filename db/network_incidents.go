@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"fmt"
+	"time"
+)
+
+const (
+	NetworkIncidentLowParticipation = "low_participation"
+	NetworkIncidentNonFinality      = "non_finality"
+)
+
+// SaveNetworkParticipation will save the participation rate and the finality delay observed for an epoch
+func SaveNetworkParticipation(epoch uint64, participationRate float64, finalityDelay uint64) error {
+	_, err := DB.Exec(`
+		INSERT INTO network_participation (epoch, participation_rate, finality_delay, ts)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (epoch) DO UPDATE SET
+			participation_rate = excluded.participation_rate,
+			finality_delay = excluded.finality_delay,
+			ts = excluded.ts`,
+		epoch, participationRate, finalityDelay)
+	return err
+}
+
+// GetLastNetworkParticipationEpoch returns the last epoch whose participation has been saved, ok is false if none has
+func GetLastNetworkParticipationEpoch() (epoch uint64, ok bool, err error) {
+	var last sql.NullInt64
+	err = DB.Get(&last, "SELECT MAX(epoch) FROM network_participation")
+	if err != nil || !last.Valid {
+		return 0, false, err
+	}
+	return uint64(last.Int64), true, nil
+}
+
+// UpdateNetworkIncident will open, extend or resolve the incident of the given type depending on whether the incident-condition is met at the given epoch
+func UpdateNetworkIncident(incidentType string, epoch uint64, conditionMet bool, participationRate float64, finalityDelay uint64) error {
+	tx, err := DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var openIncidentID uint64
+	err = tx.Get(&openIncidentID, `SELECT id FROM network_incidents WHERE type = $1 AND resolved = false ORDER BY id DESC LIMIT 1`, incidentType)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error retrieving open %v incident: %w", incidentType, err)
+	}
+	hasOpenIncident := err == nil
+
+	now := time.Now()
+	switch {
+	case conditionMet && !hasOpenIncident:
+		logger.Infof("opening %v incident at epoch %v", incidentType, epoch)
+		_, err = tx.Exec(`
+			INSERT INTO network_incidents (type, start_epoch, end_epoch, min_participation, max_finality_delay, resolved, created_ts, updated_ts)
+			VALUES ($1, $2, $2, $3, $4, false, $5, $5)`,
+			incidentType, epoch, participationRate, finalityDelay, now)
+	case conditionMet && hasOpenIncident:
+		_, err = tx.Exec(`
+			UPDATE network_incidents SET
+				end_epoch = GREATEST(end_epoch, $2),
+				min_participation = LEAST(min_participation, $3),
+				max_finality_delay = GREATEST(max_finality_delay, $4),
+				updated_ts = $5
+			WHERE id = $1`,
+			openIncidentID, epoch, participationRate, finalityDelay, now)
+	case !conditionMet && hasOpenIncident:
+		logger.Infof("resolving %v incident %v at epoch %v", incidentType, openIncidentID, epoch)
+		_, err = tx.Exec(`UPDATE network_incidents SET resolved = true, updated_ts = $2 WHERE id = $1`, openIncidentID, now)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating %v incident: %w", incidentType, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetNetworkIncidents will return the most recent network incidents
+func GetNetworkIncidents(ctx context.Context, limit uint64) ([]*types.NetworkIncident, error) {
+	incidents := []*types.NetworkIncident{}
+	err := DB.SelectContext(ctx, &incidents, `
+		SELECT id, type, start_epoch, end_epoch, min_participation, max_finality_delay, resolved, created_ts, updated_ts
+		FROM network_incidents
+		ORDER BY start_epoch DESC, id DESC
+		LIMIT $1`, limit)
+	return incidents, err
+}
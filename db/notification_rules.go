@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// notificationRuleEventFilter returns the event_filter of the subscription that is used to send the notifications of a rule
+func notificationRuleEventFilter(ruleID uint64) string {
+	return fmt.Sprintf("rule:%d", ruleID)
+}
+
+// AddNotificationRule saves the rule and subscribes the user to it
+func AddNotificationRule(ctx context.Context, rule *types.NotificationRule, network string) (uint64, error) {
+	tx, err := FrontendDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var id uint64
+	err = tx.GetContext(ctx, &id, `
+		INSERT INTO users_notification_rules (user_id, network, name, metric, threshold, window_size, validator_publickey, created_ts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		rule.UserID, network, rule.Name, rule.Metric, rule.Threshold, rule.Window, rule.ValidatorPublickey, now)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting notification rule: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		rule.UserID, strings.ToLower(network)+":"+string(types.CustomRuleEventName), notificationRuleEventFilter(id), now, utils.TimeToEpoch(now), rule.Threshold)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting subscription for notification rule: %w", err)
+	}
+
+	return id, tx.Commit()
+}
+
+// DeleteNotificationRule deletes the rule of the user and its subscription
+func DeleteNotificationRule(ctx context.Context, userID, ruleID uint64, network string) error {
+	tx, err := FrontendDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM users_notification_rules WHERE id = $1 AND user_id = $2 AND network = $3`, ruleID, userID, network)
+	if err != nil {
+		return fmt.Errorf("error deleting notification rule: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM users_subscriptions WHERE user_id = $1 AND event_name = $2 AND event_filter = $3`,
+		userID, strings.ToLower(network)+":"+string(types.CustomRuleEventName), notificationRuleEventFilter(ruleID))
+	if err != nil {
+		return fmt.Errorf("error deleting subscription of notification rule: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetNotificationRules returns the rules of the user on the given network
+func GetNotificationRules(ctx context.Context, userID uint64, network string) ([]*types.NotificationRule, error) {
+	rules := []*types.NotificationRule{}
+	err := FrontendDB.SelectContext(ctx, &rules, `
+		SELECT id, user_id, name, metric, threshold, window_size, validator_publickey, created_ts
+		FROM users_notification_rules
+		WHERE user_id = $1 AND network = $2
+		ORDER BY id`, userID, network)
+	return rules, err
+}
+
+// NotificationRuleSubscription is a rule together with the id of the subscription that is used to send its notifications
+type NotificationRuleSubscription struct {
+	types.NotificationRule
+	SubscriptionID uint64 `db:"subscription_id"`
+}
+
+// GetDueNotificationRules returns all rules on the given network that have not fired within the cooldown
+func GetDueNotificationRules(network string, cooldown time.Duration) ([]*NotificationRuleSubscription, error) {
+	rules := []*NotificationRuleSubscription{}
+	err := FrontendDB.Select(&rules, `
+		SELECT r.id, r.user_id, r.name, r.metric, r.threshold, r.window_size, r.validator_publickey, r.created_ts, us.id AS subscription_id
+		FROM users_notification_rules r
+		INNER JOIN users_subscriptions us ON us.user_id = r.user_id AND us.event_name = $1 AND us.event_filter = 'rule:' || r.id
+		WHERE r.network = $2 AND (us.last_sent_ts IS NULL OR us.last_sent_ts <= $3)`,
+		strings.ToLower(network)+":"+string(types.CustomRuleEventName), network, time.Now().Add(-cooldown))
+	return rules, err
+}
+
+// GetWatchlistPublickeys returns the publickeys of the validators on the watchlist of the user
+func GetWatchlistPublickeys(ctx context.Context, userID uint64, network string) ([][]byte, error) {
+	pubkeys := [][]byte{}
+	err := FrontendDB.SelectContext(ctx, &pubkeys, `
+		SELECT validator_publickey
+		FROM users_validators_tags
+		WHERE user_id = $1 AND tag = $2`, userID, network+":"+string(types.ValidatorTagsWatchlist))
+	return pubkeys, err
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetNotificationsDedup returns the dedup states of the users on the channel by user id and dedup key, states of events
+// that have been quiet since before seenSince are not returned
+func GetNotificationsDedup(userIDs []uint64, channel types.NotificationChannel, seenSince time.Time) (map[uint64]map[string]*types.NotificationDedup, error) {
+	res := map[uint64]map[string]*types.NotificationDedup{}
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	states := []*types.NotificationDedup{}
+	err := FrontendDB.Select(&states, `
+		SELECT user_id, channel, dedup_key, first_seen_ts, last_seen_ts, last_sent_ts, repeats, follow_ups
+		FROM notifications_dedup
+		WHERE user_id = ANY($1) AND channel = $2 AND last_seen_ts >= $3`, pq.Array(userIDs), channel, seenSince)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range states {
+		if res[s.UserID] == nil {
+			res[s.UserID] = map[string]*types.NotificationDedup{}
+		}
+		res[s.UserID][s.DedupKey] = s
+	}
+	return res, nil
+}
+
+// SaveNotificationsDedup upserts the dedup states and deletes the states of events that have been quiet since before
+// seenSince
+func SaveNotificationsDedup(states []*types.NotificationDedup, seenSince time.Time) error {
+	tx, err := FrontendDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range states {
+		_, err = tx.Exec(`
+			INSERT INTO notifications_dedup (user_id, channel, dedup_key, first_seen_ts, last_seen_ts, last_sent_ts, repeats, follow_ups)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (user_id, channel, dedup_key) DO UPDATE SET
+				first_seen_ts = excluded.first_seen_ts,
+				last_seen_ts  = excluded.last_seen_ts,
+				last_sent_ts  = excluded.last_sent_ts,
+				repeats       = excluded.repeats,
+				follow_ups    = excluded.follow_ups`,
+			s.UserID, s.Channel, s.DedupKey, s.FirstSeenTime, s.LastSeenTime, s.LastSentTime, s.Repeats, s.FollowUps)
+		if err != nil {
+			return fmt.Errorf("error saving notifications dedup state: %w", err)
+		}
+	}
+	_, err = tx.Exec("DELETE FROM notifications_dedup WHERE last_seen_ts < $1", seenSince)
+	if err != nil {
+		return fmt.Errorf("error deleting expired notifications dedup states: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetSentNotificationsCount returns the number of distinct notifications sent to the users on the channel since the
+// given time by user id, a push notification that is delivered to several devices is counted once
+func GetSentNotificationsCount(userIDs []uint64, network string, channel types.NotificationChannel, since time.Time) (map[uint64]int, error) {
+	res := map[uint64]int{}
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	rows := []struct {
+		UserID uint64 `db:"user_id"`
+		Count  int    `db:"count"`
+	}{}
+	err := FrontendDB.Select(&rows, `
+		SELECT user_id, COUNT(DISTINCT payload_hash) AS count
+		FROM notifications_log
+		WHERE user_id = ANY($1) AND network = $2 AND channel = $3 AND status = $4 AND created_ts >= $5
+		GROUP BY user_id`, pq.Array(userIDs), network, channel, types.NotificationLogStatusSent, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.UserID] = r.Count
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+	"time"
+)
+
+// SaveNotificationsLog persists the delivery attempts of outgoing notifications
+func SaveNotificationsLog(entries []*types.NotificationLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := FrontendDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Preparex(`
+		INSERT INTO notifications_log (user_id, network, channel, recipient, event_names, subject, payload, payload_hash, status, provider_response, resent_from, created_ts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`)
+	if err != nil {
+		return fmt.Errorf("error preparing notifications log insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		err = stmt.Get(&e.ID, e.UserID, e.Network, e.Channel, e.Recipient, e.EventNames, e.Subject, e.Payload, e.PayloadHash, e.Status, e.ProviderResponse, e.ResentFrom, e.CreatedTime)
+		if err != nil {
+			return fmt.Errorf("error inserting notifications log entry: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetUserNotificationsLog returns the most recent delivery attempts of the notifications of the user
+func GetUserNotificationsLog(ctx context.Context, userID uint64, network string, limit uint64) ([]*types.NotificationLogEntry, error) {
+	entries := []*types.NotificationLogEntry{}
+	err := FrontendDB.SelectContext(ctx, &entries, `
+		SELECT id, user_id, network, channel, recipient, event_names, subject, payload, payload_hash, status, provider_response, resent_from, created_ts
+		FROM notifications_log
+		WHERE user_id = $1 AND network = $2
+		ORDER BY created_ts DESC, id DESC
+		LIMIT $3`, userID, network, limit)
+	return entries, err
+}
+
+// GetLastUserNotificationLogTime returns the time of the latest delivery attempt of a notification of the event to the
+// user, nil if there is none
+func GetLastUserNotificationLogTime(userID uint64, eventName string) (*time.Time, error) {
+	var last *time.Time
+	err := FrontendDB.Get(&last, `
+		SELECT MAX(created_ts)
+		FROM notifications_log
+		WHERE user_id = $1 AND $2 = ANY(event_names)`, userID, eventName)
+	return last, err
+}
+
+// GetNotificationLogEntry returns a single delivery attempt, sql.ErrNoRows is returned if it does not exist
+func GetNotificationLogEntry(id uint64) (*types.NotificationLogEntry, error) {
+	entry := &types.NotificationLogEntry{}
+	err := FrontendDB.Get(entry, `
+		SELECT id, user_id, network, channel, recipient, event_names, subject, payload, payload_hash, status, provider_response, resent_from, created_ts
+		FROM notifications_log
+		WHERE id = $1`, id)
+	return entry, err
+}
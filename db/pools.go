@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"eth2-exporter/metrics"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -41,7 +42,7 @@ func UpdatePoolInfo() {
 		// 	return
 		// }
 		// defer tx.Rollback()
-		latestEpoch, err = GetLatestEpoch()
+		latestEpoch, err = GetLatestEpoch(context.Background())
 		if err != nil {
 			logger.Errorf("error getting latest epoch %v", err)
 		}
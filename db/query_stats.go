@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"eth2-exporter/logging"
+	"eth2-exporter/metrics"
+	"eth2-exporter/utils"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type queryStatsContextKey struct{}
+
+// QueryStats holds the number of queries and the cumulative time spent in them of a single http request. Only queries
+// executed with the context of the request (e.g. DB.SelectContext(r.Context(), ...)) are counted.
+type QueryStats struct {
+	queries  int64
+	duration int64
+}
+
+// Queries returns the number of queries executed so far
+func (s *QueryStats) Queries() int64 {
+	return atomic.LoadInt64(&s.queries)
+}
+
+// Duration returns the cumulative time spent in the queries executed so far
+func (s *QueryStats) Duration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.duration))
+}
+
+func (s *QueryStats) record(d time.Duration) {
+	atomic.AddInt64(&s.queries, 1)
+	atomic.AddInt64(&s.duration, int64(d))
+}
+
+// WithQueryStats returns a context that counts the queries executed with it
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	stats := &QueryStats{}
+	return context.WithValue(ctx, queryStatsContextKey{}, stats), stats
+}
+
+// QueryStatsFromContext returns the stats of the context or nil if its queries are not counted
+func QueryStatsFromContext(ctx context.Context) *QueryStats {
+	stats, _ := ctx.Value(queryStatsContextKey{}).(*QueryStats)
+	return stats
+}
+
+// QueryStatsMiddleware counts the queries of every request and records them per route in the metrics, requests above
+// the query budget configured via logging.requestQueryBudget and logging.requestQueryTimeBudgetMs are logged as warnings
+// with their route. It has to be registered on the router (router.Use) so the matched route is known.
+func QueryStatsMiddleware(next http.Handler) http.Handler {
+	logger := logging.NewLogger("db")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, stats := WithQueryStats(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		route := "UNDEFINED"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		metrics.HttpRequestDBQueries.WithLabelValues(route).Observe(float64(stats.Queries()))
+		metrics.HttpRequestDBDuration.WithLabelValues(route).Observe(stats.Duration().Seconds())
+
+		queries, timeMs := utils.RequestQueryBudgets()
+		queryBudget := int64(queries)
+		timeBudget := time.Millisecond * time.Duration(timeMs)
+		if (queryBudget > 0 && stats.Queries() > queryBudget) || (timeBudget > 0 && stats.Duration() > timeBudget) {
+			logging.WithRequest(logger, r).WithFields(logrus.Fields{
+				"route":             route,
+				"queries":           stats.Queries(),
+				"queryDurationMs":   stats.Duration().Milliseconds(),
+				"queryBudget":       queryBudget,
+				"queryTimeBudgetMs": timeBudget.Milliseconds(),
+			}).Warn("request exceeded its query budget")
+		}
+	})
+}
+
+var countedDrivers = map[string]string{}
+var countedDriversMux = &sync.Mutex{}
+
+// countedDriverName registers a wrapper of the sql driver that records the queries executed with a context carrying
+// QueryStats and returns its name
+func countedDriverName(driverName string) (string, error) {
+	countedDriversMux.Lock()
+	defer countedDriversMux.Unlock()
+
+	if name, exists := countedDrivers[driverName]; exists {
+		return name, nil
+	}
+
+	// sql.Open does not connect, it is only used to look up the registered driver
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return "", err
+	}
+	d := db.Driver()
+	db.Close()
+
+	name := driverName + "-counted"
+	sql.Register(name, &countedDriver{Driver: d})
+	countedDrivers[driverName] = name
+	return name, nil
+}
+
+type countedDriver struct {
+	driver.Driver
+}
+
+func (d *countedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &countedConn{Conn: c}, nil
+}
+
+type countedConn struct {
+	driver.Conn
+}
+
+// Unwrap returns the connection of the wrapped driver, e.g. to use the pgx connection via sql.Conn.Raw
+func (c *countedConn) Unwrap() driver.Conn {
+	return c.Conn
+}
+
+func (c *countedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	stats := QueryStatsFromContext(ctx)
+	if stats == nil {
+		return execer.ExecContext(ctx, query, args)
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	stats.record(time.Since(start))
+	return res, err
+}
+
+func (c *countedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	stats := QueryStatsFromContext(ctx)
+	if stats == nil {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	stats.record(time.Since(start))
+	return rows, err
+}
+
+func (c *countedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &countedStmt{Stmt: stmt}, nil
+}
+
+func (c *countedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *countedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *countedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue passes the argument conversion on to the wrapped driver, pgx supports more types than the default
+// converter of database/sql (e.g. uint64 values above the int64 range)
+func (c *countedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// countedStmt records the executions of a prepared statement with a context carrying QueryStats, database/sql prepares
+// the statement implicitly for queries with arguments if the driver does not support them without
+type countedStmt struct {
+	driver.Stmt
+}
+
+func (s *countedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(namedValues(args))
+	}
+	if stats := QueryStatsFromContext(ctx); stats != nil {
+		stats.record(time.Since(start))
+	}
+	return res, err
+}
+
+func (s *countedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args))
+	}
+	if stats := QueryStatsFromContext(ctx); stats != nil {
+		stats.record(time.Since(start))
+	}
+	return rows, err
+}
+
+// CheckNamedValue passes the argument conversion on to the wrapped statement like countedConn.CheckNamedValue
+func (s *countedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValues returns the values of the arguments for statements that do not support contexts
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return values
+}
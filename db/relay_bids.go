@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+
+	"github.com/lib/pq"
+)
+
+// relayAuctionsQuery selects the auctions with the canonical block of their slot, the value left on the table is null
+// as long as the value the proposer received is unknown
+const relayAuctionsQuery = `
+	SELECT
+		a.slot,
+		b.proposer,
+		a.relay,
+		a.builder_pubkey,
+		a.block_hash,
+		a.winning_bid,
+		a.second_bid,
+		a.top_bid,
+		a.median_bid,
+		a.bids,
+		a.builders,
+		COALESCE(a.winning_bid, b.exec_fee_reward) AS value_received,
+		CASE WHEN b.slot IS NULL OR a.top_bid IS NULL OR COALESCE(a.winning_bid, b.exec_fee_reward) IS NULL THEN NULL
+			ELSE GREATEST(a.top_bid - COALESCE(a.winning_bid, b.exec_fee_reward), 0) END AS value_left_on_table
+	FROM relay_auctions a
+	LEFT JOIN blocks b ON b.slot = a.slot AND b.status = '1'`
+
+// GetLastRelayAuctionSlot returns the latest slot whose auction has been stored, exists is false if none has been stored
+func GetLastRelayAuctionSlot() (slot uint64, exists bool, err error) {
+	last := sql.NullInt64{}
+	err = DB.Get(&last, "SELECT MAX(slot) FROM relay_auctions")
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(last.Int64), last.Valid, nil
+}
+
+// GetLastExportedSlot returns the latest slot whose block has been exported
+func GetLastExportedSlot() (uint64, error) {
+	var slot uint64
+	err := DB.Get(&slot, "SELECT COALESCE(MAX(slot), 0) FROM blocks")
+	return slot, err
+}
+
+// GetSlotExecBlockHash returns the hash of the execution payload of the canonical block of the slot, nil if the slot
+// has no canonical block with an execution payload. exported is false if the block of the slot has not been exported.
+func GetSlotExecBlockHash(slot uint64) (hash []byte, exported bool, err error) {
+	blocks := []struct {
+		Status        string `db:"status"`
+		ExecBlockHash []byte `db:"exec_block_hash"`
+	}{}
+	err = DB.Select(&blocks, "SELECT status, exec_block_hash FROM blocks WHERE slot = $1", slot)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, b := range blocks {
+		if b.Status == "1" {
+			return b.ExecBlockHash, true, nil
+		}
+	}
+	return nil, len(blocks) > 0, nil
+}
+
+// SaveRelayAuction stores the auction of a slot and the highest bids of the builders per relay it was computed from
+func SaveRelayAuction(auction *types.RelayAuction, bids []*types.RelayBid) error {
+	tx, err := ExporterDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, b := range bids {
+		_, err = tx.Exec(`
+			INSERT INTO relay_bids (slot, relay, builder_pubkey, block_hash, value, bids)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (slot, relay, builder_pubkey) DO UPDATE SET
+				block_hash = excluded.block_hash,
+				value = excluded.value,
+				bids = excluded.bids`,
+			b.Slot, b.Relay, b.BuilderPubkey, b.BlockHash, b.Value, b.Bids)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO relay_auctions (slot, relay, builder_pubkey, block_hash, winning_bid, second_bid, top_bid, median_bid, bids, builders)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (slot) DO UPDATE SET
+			relay = excluded.relay,
+			builder_pubkey = excluded.builder_pubkey,
+			block_hash = excluded.block_hash,
+			winning_bid = excluded.winning_bid,
+			second_bid = excluded.second_bid,
+			top_bid = excluded.top_bid,
+			median_bid = excluded.median_bid,
+			bids = excluded.bids,
+			builders = excluded.builders`,
+		auction.Slot, auction.Relay, auction.BuilderPubkey, auction.BlockHash, auction.WinningBid, auction.SecondBid,
+		auction.TopBid, auction.MedianBid, auction.Bids, auction.Builders)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetRelayAuctions returns the auctions of the slots from startSlot to endSlot, latest first
+func GetRelayAuctions(ctx context.Context, startSlot, endSlot uint64) ([]*types.RelayAuction, error) {
+	auctions := []*types.RelayAuction{}
+	err := DB.SelectContext(ctx, &auctions, relayAuctionsQuery+" WHERE a.slot >= $1 AND a.slot <= $2 ORDER BY a.slot DESC", startSlot, endSlot)
+	return auctions, err
+}
+
+// GetDailyRelayAuctionStats returns the auction stats of the days from startDay to endDay, a day has slotsPerDay slots
+func GetDailyRelayAuctionStats(ctx context.Context, startDay, endDay, slotsPerDay uint64) ([]*types.RelayAuctionDayStats, error) {
+	stats := []*types.RelayAuctionDayStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT
+			a.slot / $3 AS day,
+			COUNT(*) AS auctions,
+			COUNT(a.winning_bid) AS relay_blocks,
+			COALESCE(ROUND(AVG(a.winning_bid)), 0) AS avg_winning_bid,
+			COALESCE(ROUND(AVG(a.second_bid) FILTER (WHERE a.winning_bid IS NOT NULL)), 0) AS avg_second_bid,
+			COALESCE(SUM(a.value_left_on_table), 0) AS value_left_on_table
+		FROM (`+relayAuctionsQuery+` WHERE a.slot >= $1 AND a.slot <= $2) a
+		GROUP BY day
+		ORDER BY day`, startDay*slotsPerDay, (endDay+1)*slotsPerDay-1, slotsPerDay)
+	return stats, err
+}
+
+// GetBuilderMarketShare returns the share of the builders of the canonical blocks delivered by relays in the slots from
+// startSlot to endSlot, largest first
+func GetBuilderMarketShare(ctx context.Context, startSlot, endSlot uint64) ([]*types.BuilderMarketShare, error) {
+	shares := []*types.BuilderMarketShare{}
+	err := DB.SelectContext(ctx, &shares, `
+		SELECT builder_pubkey, COUNT(*) AS blocks, COUNT(*)::float / SUM(COUNT(*)) OVER () AS share
+		FROM relay_auctions
+		WHERE slot >= $1 AND slot <= $2 AND winning_bid IS NOT NULL
+		GROUP BY builder_pubkey
+		ORDER BY blocks DESC`, startSlot, endSlot)
+	return shares, err
+}
+
+// GetDailyBuilderMarketShare returns the share of the builders of the canonical blocks delivered by relays per day for
+// the days from startDay to endDay, a day has slotsPerDay slots
+func GetDailyBuilderMarketShare(startDay, endDay, slotsPerDay uint64) ([]*types.BuilderMarketShare, error) {
+	shares := []*types.BuilderMarketShare{}
+	err := DB.Select(&shares, `
+		SELECT
+			slot / $3 AS day,
+			builder_pubkey,
+			COUNT(*) AS blocks,
+			COUNT(*)::float / SUM(COUNT(*)) OVER (PARTITION BY slot / $3) AS share
+		FROM relay_auctions
+		WHERE slot >= $1 AND slot <= $2 AND winning_bid IS NOT NULL
+		GROUP BY day, builder_pubkey
+		ORDER BY day, blocks DESC`, startDay*slotsPerDay, (endDay+1)*slotsPerDay-1, slotsPerDay)
+	return shares, err
+}
+
+// GetProposerAuctionStats sums the auctions of the canonical blocks of the validators with the indices or pubkeys
+func GetProposerAuctionStats(ctx context.Context, indices []uint64, pubkeys pq.ByteaArray) ([]*types.ProposerAuctionStats, error) {
+	stats := []*types.ProposerAuctionStats{}
+	err := DB.SelectContext(ctx, &stats, `
+		SELECT
+			a.proposer,
+			COUNT(*) AS blocks,
+			COUNT(a.winning_bid) AS relay_blocks,
+			COALESCE(SUM(a.value_received), 0) AS value_received,
+			COALESCE(SUM(a.value_left_on_table), 0) AS value_left_on_table
+		FROM (`+relayAuctionsQuery+` WHERE b.proposer IN (
+			SELECT validatorindex FROM validators WHERE validatorindex = ANY($1) OR pubkey = ANY($2)
+		)) a
+		GROUP BY a.proposer
+		ORDER BY a.proposer`, pq.Array(indices), pubkeys)
+	return stats, err
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ValidatorBalancesRepository reads the balance history of the validators (the validator_balances_p table family)
+type ValidatorBalancesRepository interface {
+	// GetBalanceHistory returns the balances of the validators from startEpoch on, ordered by validator index and
+	// descending epoch
+	GetBalanceHistory(indices []uint64, startEpoch uint64) ([]*types.ApiValidatorBalance, error)
+	// GetBalanceHistoryBuckets returns the balances of the validators from startEpoch on downsampled to buckets of
+	// bucketDays days from the daily aggregates, ordered by validator index and descending bucket
+	GetBalanceHistoryBuckets(indices []uint64, startEpoch, bucketDays uint64) ([]*types.ApiValidatorBalanceBucket, error)
+}
+
+// AttestationsRepository reads the attestation duties of the validators (the attestation_assignments_p table family)
+type AttestationsRepository interface {
+	// GetAttestationAssignments returns the attestation assignments of the validators from startEpoch to endEpoch,
+	// ordered by validator index and descending epoch
+	GetAttestationAssignments(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error)
+	// GetMissedAttestations returns the attestation assignments of the validators from startEpoch to endEpoch that
+	// have not been included, ordered by validator index and descending epoch
+	GetMissedAttestations(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error)
+}
+
+// Repositories are the read paths of the api that can be served from an alternative storage backend, nil fields are
+// not supported by the backend
+type Repositories struct {
+	Balances     ValidatorBalancesRepository
+	Attestations AttestationsRepository
+}
+
+// RepositoryDriver opens the repositories of a storage backend with the data source name of the config
+type RepositoryDriver func(dsn string) (*Repositories, error)
+
+var repositoryDrivers = map[string]RepositoryDriver{
+	"postgres": openPostgresRepositories,
+}
+
+// Balances and Attestations are the repositories the api reads from, they are set by MustInitRepositories
+var (
+	Balances     ValidatorBalancesRepository
+	Attestations AttestationsRepository
+)
+
+// RegisterRepositoryDriver makes a storage backend available to the read backends config under the given name, it has
+// to be called before MustInitRepositories
+func RegisterRepositoryDriver(name string, driver RepositoryDriver) {
+	repositoryDrivers[name] = driver
+}
+
+// MustInitRepositories opens the read backends of the config, the explorer database (which has to be initialized
+// before) serves all table families without a configured driver. If the attestation assignments are pruned the epochs
+// of the dropped weeks are read from attestation_bitfields.
+func MustInitRepositories() {
+	cfg := utils.Config.ReadBackends
+
+	balances, err := openRepositories(cfg.Balances.Driver, cfg.Balances.DSN)
+	if err != nil {
+		logger.Fatalf("error opening read backend of the balances: %v", err)
+	}
+	if balances.Balances == nil {
+		logger.Fatalf("read backend driver %v does not support the balances", cfg.Balances.Driver)
+	}
+	Balances = balances.Balances
+
+	attestations, err := openRepositories(cfg.Attestations.Driver, cfg.Attestations.DSN)
+	if err != nil {
+		logger.Fatalf("error opening read backend of the attestations: %v", err)
+	}
+	if attestations.Attestations == nil {
+		logger.Fatalf("read backend driver %v does not support the attestations", cfg.Attestations.Driver)
+	}
+	Attestations = attestations.Attestations
+	if _, ok := Attestations.(*bitfieldRepositories); !ok && utils.Config.Indexer.AttestationAssignmentsRetentionWeeks > 0 {
+		Attestations = &retainedAttestationsRepository{retained: Attestations, dropped: &bitfieldRepositories{db: DB}}
+	}
+}
+
+// readBackendDBs are the pools of the read backends by dsn, backends with the same dsn share a pool
+var readBackendDBs = map[string]*sqlx.DB{}
+
+// openReadBackendDB returns the pool of the explorer database or, if a dsn is given, the pool of the database of the
+// dsn with the settings of the configured pool
+func openReadBackendDB(dsn string) (*sqlx.DB, error) {
+	if dsn == "" {
+		return DB, nil
+	}
+	if conn, ok := readBackendDBs[dsn]; ok {
+		return conn, nil
+	}
+	conn, err := openDB(dsn, utils.Config.Database.Pool)
+	if err != nil {
+		return nil, err
+	}
+	readBackendDBs[dsn] = conn
+	return conn, nil
+}
+
+func openRepositories(driver, dsn string) (*Repositories, error) {
+	if driver == "" {
+		driver = "postgres"
+	}
+	open, ok := repositoryDrivers[driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown read backend driver %v", driver)
+	}
+	logger.Infof("using read backend driver %v", driver)
+	return open(dsn)
+}
+
+// postgresRepositories reads the table families from the postgres schema of tables.sql
+type postgresRepositories struct {
+	db *sqlx.DB
+}
+
+// openPostgresRepositories reads from the explorer database or, if a dsn is given, from another postgres database with
+// the same schema, e.g. a read replica
+func openPostgresRepositories(dsn string) (*Repositories, error) {
+	conn, err := openReadBackendDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	r := &postgresRepositories{db: conn}
+	return &Repositories{Balances: r, Attestations: r}, nil
+}
+
+func (r *postgresRepositories) GetBalanceHistory(indices []uint64, startEpoch uint64) ([]*types.ApiValidatorBalance, error) {
+	balances := []*types.ApiValidatorBalance{}
+	err := r.db.Select(&balances, `
+		SELECT epoch, validatorindex, balance, effectivebalance, week
+		FROM validator_balances_p
+		WHERE validatorindex = ANY($1) AND week >= $2 / 1575 AND epoch >= $2
+		ORDER BY validatorindex, epoch DESC`, pq.Array(indices), startEpoch)
+	return balances, err
+}
+
+// GetBalanceHistoryBuckets downsamples the daily balances of validator_stats, the buckets are aligned to the days
+// since genesis
+func (r *postgresRepositories) GetBalanceHistoryBuckets(indices []uint64, startEpoch, bucketDays uint64) ([]*types.ApiValidatorBalanceBucket, error) {
+	epochsPerDay := utils.EpochsPerDay()
+	buckets := []*types.ApiValidatorBalanceBucket{}
+	err := r.db.Select(&buckets, `
+		SELECT
+			validatorindex,
+			MIN(day) * $3 AS start_epoch,
+			(MAX(day) + 1) * $3 - 1 AS end_epoch,
+			COALESCE((ARRAY_AGG(start_balance ORDER BY day))[1], 0) AS start_balance,
+			COALESCE((ARRAY_AGG(end_balance ORDER BY day DESC))[1], 0) AS end_balance,
+			COALESCE(MIN(min_balance), 0) AS min_balance,
+			COALESCE(MAX(max_balance), 0) AS max_balance,
+			COALESCE((ARRAY_AGG(end_effective_balance ORDER BY day DESC))[1], 0) AS end_effective_balance
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day >= $2::bigint / $3::bigint / $4::bigint * $4::bigint
+		GROUP BY validatorindex, day / $4
+		ORDER BY validatorindex, day / $4 DESC`, pq.Array(indices), startEpoch, epochsPerDay, bucketDays)
+	return buckets, err
+}
+
+func (r *postgresRepositories) GetAttestationAssignments(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	assignments := []*types.ApiAttestationAssignment{}
+	err := r.db.Select(&assignments, `
+		SELECT epoch, validatorindex, attesterslot, committeeindex, status, inclusionslot, week
+		FROM attestation_assignments_p
+		WHERE validatorindex = ANY($1) AND week >= $2 AND week <= $3 AND epoch >= $4 AND epoch <= $5
+		ORDER BY validatorindex, epoch DESC`,
+		pq.Array(indices), startEpoch/utils.EpochsPerWeekPartition, endEpoch/utils.EpochsPerWeekPartition, startEpoch, endEpoch)
+	return assignments, err
+}
+
+func (r *postgresRepositories) GetMissedAttestations(indices []uint64, startEpoch, endEpoch uint64) ([]*types.ApiAttestationAssignment, error) {
+	assignments := []*types.ApiAttestationAssignment{}
+	err := r.db.Select(&assignments, `
+		SELECT epoch, validatorindex, attesterslot, committeeindex, status, inclusionslot, week
+		FROM attestation_assignments_p
+		WHERE validatorindex = ANY($1) AND week >= $2 AND week <= $3 AND epoch >= $4 AND epoch <= $5 AND status = 0
+		ORDER BY validatorindex, epoch DESC`,
+		pq.Array(indices), startEpoch/utils.EpochsPerWeekPartition, endEpoch/utils.EpochsPerWeekPartition, startEpoch, endEpoch)
+	return assignments, err
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"time"
+)
+
+// GetRocketpoolRETHHistory returns the recorded rETH exchange and market rates between start and end ordered by time
+func GetRocketpoolRETHHistory(ctx context.Context, start, end time.Time) ([]*types.RocketpoolRETHRate, error) {
+	rates := []*types.RocketpoolRETHRate{}
+	err := DB.SelectContext(ctx, &rates, `
+		SELECT eth1_block, ts, exchange_rate, market_rate
+		FROM rocketpool_reth_history
+		WHERE ts >= $1 AND ts <= $2
+		ORDER BY ts`, start, end)
+	return rates, err
+}
+
+// GetRocketpoolNodesHistory returns the snapshots of the rpl stake and minipool count of the rocketpool nodes between
+// start and end ordered by time, summed over all nodes or, if nodeAddress is not nil, of that node
+func GetRocketpoolNodesHistory(ctx context.Context, start, end time.Time, nodeAddress []byte) ([]*types.RocketpoolNodesHistory, error) {
+	history := []*types.RocketpoolNodesHistory{}
+	err := DB.SelectContext(ctx, &history, `
+		SELECT
+			eth1_block,
+			MAX(ts) AS ts,
+			COUNT(*) AS nodes,
+			SUM(rpl_stake)::float8 / 1e18 AS rpl_stake,
+			SUM(min_rpl_stake)::float8 / 1e18 AS min_rpl_stake,
+			SUM(max_rpl_stake)::float8 / 1e18 AS max_rpl_stake,
+			SUM(minipool_count) AS minipools
+		FROM rocketpool_nodes_history
+		WHERE ts >= $1 AND ts <= $2 AND ($3::bytea IS NULL OR address = $3)
+		GROUP BY eth1_block
+		ORDER BY eth1_block`, start, end, nodeAddress)
+	return history, err
+}
+
+// GetRocketpoolODAOMembersHealth returns the submission liveness of the oDAO members, missed submissions are counted
+// over the last rounds rounds of each kind. A round is a reference block at least one member submitted values for.
+func GetRocketpoolODAOMembersHealth(ctx context.Context, rounds uint64) ([]*types.RocketpoolODAOMemberHealth, error) {
+	members := []*types.RocketpoolODAOMemberHealth{}
+	err := DB.SelectContext(ctx, &members, `
+		WITH recent_rounds AS (
+			SELECT kind, reference_block, ts
+			FROM (
+				SELECT kind, reference_block, MIN(ts) AS ts, ROW_NUMBER() OVER (PARTITION BY kind ORDER BY reference_block DESC) AS n
+				FROM rocketpool_odao_submissions
+				GROUP BY kind, reference_block
+			) r
+			WHERE n <= $1
+		), member_rounds AS (
+			SELECT
+				m.address,
+				r.kind,
+				EXISTS (
+					SELECT 1 FROM rocketpool_odao_submissions s
+					WHERE s.kind = r.kind AND s.reference_block = r.reference_block AND s.member_address = m.address
+				) AS submitted
+			FROM rocketpool_dao_members m
+			INNER JOIN recent_rounds r ON r.ts >= COALESCE(m.joined_time, to_timestamp(0))
+		)
+		SELECT
+			m.address,
+			m.id,
+			COALESCE(m.joined_time, to_timestamp(0)) AS joined_time,
+			(SELECT MAX(ts) FROM rocketpool_odao_submissions s WHERE s.member_address = m.address AND s.kind = 'prices') AS last_prices_ts,
+			(SELECT MAX(ts) FROM rocketpool_odao_submissions s WHERE s.member_address = m.address AND s.kind = 'balances') AS last_balances_ts,
+			(SELECT COUNT(*) FROM member_rounds mr WHERE mr.address = m.address AND mr.kind = 'prices' AND NOT mr.submitted) AS missed_prices,
+			(SELECT COUNT(*) FROM member_rounds mr WHERE mr.address = m.address AND mr.kind = 'balances' AND NOT mr.submitted) AS missed_balances,
+			(SELECT COUNT(*) FROM member_rounds mr WHERE mr.address = m.address AND mr.kind = 'prices') AS considered_prices,
+			(SELECT COUNT(*) FROM member_rounds mr WHERE mr.address = m.address AND mr.kind = 'balances') AS considered_balances
+		FROM rocketpool_dao_members m
+		ORDER BY m.id`, rounds)
+	return members, err
+}
+
+// GetRocketpoolODAORounds returns the latest limit rounds of submissions of the kind ("prices" or "balances") with the
+// consensus they reached
+func GetRocketpoolODAORounds(ctx context.Context, kind string, limit uint64) ([]*types.RocketpoolODAORound, error) {
+	rounds := []*types.RocketpoolODAORound{}
+	err := DB.SelectContext(ctx, &rounds, `
+		SELECT
+			s.kind,
+			s.reference_block,
+			MIN(s.ts) AS first_submission_ts,
+			COUNT(*) AS submissions,
+			c.eth1_block AS consensus_eth1_block,
+			c.ts AS consensus_ts,
+			COALESCE(c.rpl_price, c.total_eth)::text AS value
+		FROM rocketpool_odao_submissions s
+		LEFT JOIN rocketpool_odao_consensus c ON c.rocketpool_storage_address = s.rocketpool_storage_address AND c.kind = s.kind AND c.reference_block = s.reference_block
+		WHERE s.kind = $1
+		GROUP BY s.kind, s.reference_block, c.eth1_block, c.ts, c.rpl_price, c.total_eth
+		ORDER BY s.reference_block DESC
+		LIMIT $2`, kind, limit)
+	return rounds, err
+}
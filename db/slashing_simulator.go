@@ -0,0 +1,25 @@
+package db
+
+// SlashingNetworkState is the validator set at an epoch and the effective balance of the validators that have been
+// slashed within the slashings window and are not withdrawable yet, balances are in gwei
+type SlashingNetworkState struct {
+	ActiveValidators       uint64  `db:"active_validators"`
+	TotalActiveBalance     uint64  `db:"total_active_balance"`
+	RecentlySlashedBalance uint64  `db:"recently_slashed_balance"`
+	Participation          float64 `db:"participation"`
+}
+
+// GetSlashingNetworkState returns the state of the network the penalties of a slashing at the epoch depend on, the
+// participation is the average of the last day
+func GetSlashingNetworkState(epoch, epochsPerDay uint64) (*SlashingNetworkState, error) {
+	startEpoch := int64(epoch) - int64(epochsPerDay)
+	state := &SlashingNetworkState{}
+	err := DB.Get(state, `
+		SELECT
+			COUNT(*) FILTER (WHERE activationepoch <= $1 AND exitepoch > $1) AS active_validators,
+			COALESCE(SUM(effectivebalance) FILTER (WHERE activationepoch <= $1 AND exitepoch > $1), 0) AS total_active_balance,
+			COALESCE(SUM(effectivebalance) FILTER (WHERE slashed AND withdrawableepoch > $1), 0) AS recently_slashed_balance,
+			(SELECT COALESCE(AVG(globalparticipationrate), 0) FROM epochs WHERE epoch > $2 AND eligibleether > 0) AS participation
+		FROM validators`, epoch, startEpoch)
+	return state, err
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -9,18 +10,18 @@ import (
 )
 
 // StripeRemoveCustomer removes the stripe customer and sets all subscriptions to inactive
-func StripeRemoveCustomer(customerID string) error {
-	tx, err := FrontendDB.Begin()
+func StripeRemoveCustomer(ctx context.Context, customerID string) error {
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	userID, err := StripeGetCustomerUserId(customerID)
+	userID, err := StripeGetCustomerUserId(ctx, customerID)
 	if err == nil {
 		now := time.Now()
 		nowTs := now.Unix()
-		_, err = tx.Exec("UPDATE users_app_subscriptions SET active = $1, updated_at = TO_TIMESTAMP($2), expires_at = TO_TIMESTAMP($3), reject_reason = $4 WHERE user_id = $5 AND store = 'stripe';",
+		_, err = tx.ExecContext(ctx, "UPDATE users_app_subscriptions SET active = $1, updated_at = TO_TIMESTAMP($2), expires_at = TO_TIMESTAMP($3), reject_reason = $4 WHERE user_id = $5 AND store = 'stripe';",
 			false, nowTs, nowTs, "stripe_user_deleted", userID,
 		)
 	} else {
@@ -29,13 +30,13 @@ func StripeRemoveCustomer(customerID string) error {
 	}
 
 	// remove customer id entry from database
-	_, err = tx.Exec("UPDATE users SET stripe_customer_id = NULL WHERE stripe_customer_id = $1", customerID)
+	_, err = tx.ExecContext(ctx, "UPDATE users SET stripe_customer_id = NULL WHERE stripe_customer_id = $1", customerID)
 	if err != nil {
 		return err
 	}
 
 	// set all subscriptions to inactive for the deleted stripe customer
-	_, err = tx.Exec("UPDATE users_stripe_subscriptions SET active = 'f' WHERE stripe_customer_id = $1", customerID)
+	_, err = tx.ExecContext(ctx, "UPDATE users_stripe_subscriptions SET active = 'f' WHERE stripe_customer_id = $1", customerID)
 	if err != nil {
 		return err
 	}
@@ -45,15 +46,15 @@ func StripeRemoveCustomer(customerID string) error {
 }
 
 // StripeCreateSubscription inserts a new subscription
-func StripeCreateSubscription(customerID, priceID, subscriptionID string, payload json.RawMessage) error {
+func StripeCreateSubscription(ctx context.Context, customerID, priceID, subscriptionID string, payload json.RawMessage) error {
 	purchaseGroup := utils.GetPurchaseGroup(priceID)
-	tx, err := FrontendDB.Begin()
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("INSERT INTO users_stripe_subscriptions (subscription_id, customer_id, price_id, active, payload, purchase_group) VALUES ($1, $2, $3, 'f', $4, $5)", subscriptionID, customerID, priceID, payload, purchaseGroup)
+	_, err = tx.ExecContext(ctx, "INSERT INTO users_stripe_subscriptions (subscription_id, customer_id, price_id, active, payload, purchase_group) VALUES ($1, $2, $3, 'f', $4, $5)", subscriptionID, customerID, priceID, payload, purchaseGroup)
 	if err != nil {
 		return err
 	}
@@ -63,15 +64,15 @@ func StripeCreateSubscription(customerID, priceID, subscriptionID string, payloa
 }
 
 // StripeUpdateSubscription inserts a new subscription
-func StripeUpdateSubscription(priceID, subscriptionID string, payload json.RawMessage) error {
+func StripeUpdateSubscription(ctx context.Context, priceID, subscriptionID string, payload json.RawMessage) error {
 	purchaseGroup := utils.GetPurchaseGroup(priceID)
-	tx, err := FrontendDB.Begin()
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("UPDATE users_stripe_subscriptions SET price_id = $2, purchase_group = $4, payload = $3 where subscription_id = $1", subscriptionID, priceID, payload, purchaseGroup)
+	_, err = tx.ExecContext(ctx, "UPDATE users_stripe_subscriptions SET price_id = $2, purchase_group = $4, payload = $3 where subscription_id = $1", subscriptionID, priceID, payload, purchaseGroup)
 	if err != nil {
 		return err
 	}
@@ -81,20 +82,20 @@ func StripeUpdateSubscription(priceID, subscriptionID string, payload json.RawMe
 }
 
 // StripeUpdateSubscriptionStatus sets the status of a subscription
-func StripeUpdateSubscriptionStatus(id string, status bool, payload *json.RawMessage) error {
-	tx, err := FrontendDB.Begin()
+func StripeUpdateSubscriptionStatus(ctx context.Context, id string, status bool, payload *json.RawMessage) error {
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	if payload == nil {
-		_, err = tx.Exec("UPDATE users_stripe_subscriptions SET active = $2 WHERE subscription_id = $1", id, status)
+		_, err = tx.ExecContext(ctx, "UPDATE users_stripe_subscriptions SET active = $2 WHERE subscription_id = $1", id, status)
 		if err != nil {
 			return err
 		}
 	} else {
-		_, err = tx.Exec("UPDATE users_stripe_subscriptions SET active = $2, payload = $3 WHERE subscription_id = $1", id, status, payload)
+		_, err = tx.ExecContext(ctx, "UPDATE users_stripe_subscriptions SET active = $2, payload = $3 WHERE subscription_id = $1", id, status, payload)
 		if err != nil {
 			return err
 		}
@@ -105,22 +106,22 @@ func StripeUpdateSubscriptionStatus(id string, status bool, payload *json.RawMes
 }
 
 // StripeGetUserAPISubscription returns a users current subscription
-func StripeGetUserSubscription(id uint64, purchaseGroup string) (types.UserSubscription, error) {
+func StripeGetUserSubscription(ctx context.Context, id uint64, purchaseGroup string) (types.UserSubscription, error) {
 	userSub := types.UserSubscription{}
-	err := FrontendDB.Get(&userSub, "SELECT id, email, stripe_customer_id, subscription_id, price_id, active, api_key FROM users LEFT JOIN (SELECT * FROM users_stripe_subscriptions WHERE purchase_group = $2 and (payload->'ended_at')::text = 'null') as us ON users.stripe_customer_id = us.customer_id WHERE users.id = $1 ORDER BY active desc LIMIT 1", id, purchaseGroup)
+	err := FrontendDB.GetContext(ctx, &userSub, "SELECT id, email, stripe_customer_id, subscription_id, price_id, active, api_key FROM users LEFT JOIN (SELECT * FROM users_stripe_subscriptions WHERE purchase_group = $2 and (payload->'ended_at')::text = 'null') as us ON users.stripe_customer_id = us.customer_id WHERE users.id = $1 ORDER BY active desc LIMIT 1", id, purchaseGroup)
 	return userSub, err
 }
 
 // StripeGetSubscription returns a subscription given a subscription_id
-func StripeGetSubscription(id string) (*types.StripeSubscription, error) {
+func StripeGetSubscription(ctx context.Context, id string) (*types.StripeSubscription, error) {
 	sub := types.StripeSubscription{}
-	err := FrontendDB.Get(&sub, "SELECT customer_id, subscription_id, price_id, active FROM users_stripe_subscriptions WHERE subscription_id = $1", id)
+	err := FrontendDB.GetContext(ctx, &sub, "SELECT customer_id, subscription_id, price_id, active FROM users_stripe_subscriptions WHERE subscription_id = $1", id)
 	return &sub, err
 }
 
 // StripeUpdateCustomerID adds a stripe customer id to a user. It checks if the user already has a stripe customer id.
-func StripeUpdateCustomerID(email, customerID string) error {
-	tx, err := FrontendDB.Begin()
+func StripeUpdateCustomerID(ctx context.Context, email, customerID string) error {
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -128,7 +129,7 @@ func StripeUpdateCustomerID(email, customerID string) error {
 
 	var currID string
 
-	row := tx.QueryRow("SELECT stripe_customer_id FROM users WHERE email = $1", email)
+	row := tx.QueryRowContext(ctx, "SELECT stripe_customer_id FROM users WHERE email = $1", email)
 	row.Scan(&currID)
 
 	// customer already exists
@@ -141,7 +142,7 @@ func StripeUpdateCustomerID(email, customerID string) error {
 		return fmt.Errorf("error updating stripe customer id, the user already has an id: %v failed to overwrite with: %v", currID, customerID)
 	}
 
-	_, err = tx.Exec("UPDATE users SET stripe_customer_id = $1 WHERE email = $2", customerID, email)
+	_, err = tx.ExecContext(ctx, "UPDATE users SET stripe_customer_id = $1 WHERE email = $2", customerID, email)
 	if err != nil {
 		return err
 	}
@@ -151,14 +152,32 @@ func StripeUpdateCustomerID(email, customerID string) error {
 }
 
 // StripeGetCustomerEmail returns a customers email given their customerID
-func StripeGetCustomerEmail(customerID string) (string, error) {
+func StripeGetCustomerEmail(ctx context.Context, customerID string) (string, error) {
 	email := ""
-	err := FrontendDB.Get(&email, "SELECT email FROM users WHERE stripe_customer_id = $1", customerID)
+	err := FrontendDB.GetContext(ctx, &email, "SELECT email FROM users WHERE stripe_customer_id = $1", customerID)
 	return email, err
 }
 
-func StripeGetCustomerUserId(customerID string) (uint64, error) {
+func StripeGetCustomerUserId(ctx context.Context, customerID string) (uint64, error) {
 	var id uint64 = 0
-	err := FrontendDB.Get(&id, "SELECT id FROM users WHERE stripe_customer_id = $1", customerID)
+	err := FrontendDB.GetContext(ctx, &id, "SELECT id FROM users WHERE stripe_customer_id = $1", customerID)
 	return id, err
 }
+
+// GetApiKeyPriceID returns the price id of the active api subscription of the user with the api key, nil if the user
+// has no api subscription. exists is false if no user has the api key.
+func GetApiKeyPriceID(ctx context.Context, apiKey string) (priceID *string, exists bool, err error) {
+	rows := []struct {
+		PriceID *string `db:"price_id"`
+	}{}
+	err = FrontendDB.SelectContext(ctx, &rows, `
+		SELECT us.price_id
+		FROM users
+		LEFT JOIN (SELECT * FROM users_stripe_subscriptions WHERE purchase_group = $2 AND active AND (payload->'ended_at')::text = 'null') AS us ON users.stripe_customer_id = us.customer_id
+		WHERE users.api_key = $1
+		LIMIT 1`, apiKey, utils.GROUP_API)
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	return rows[0].PriceID, true, nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"eth2-exporter/types"
@@ -19,7 +20,7 @@ type WatchlistEntry struct {
 	Validator_publickey string
 }
 
-func AddToWatchlist(watchlist []WatchlistEntry, network string) error {
+func AddToWatchlist(ctx context.Context, watchlist []WatchlistEntry, network string) error {
 	qry := ""
 	tag := network + ":" + string(types.ValidatorTagsWatchlist)
 	args := make([]interface{}, 0)
@@ -46,31 +47,31 @@ func AddToWatchlist(watchlist []WatchlistEntry, network string) error {
 
 	qry = qry[:len(qry)-1] + " ON CONFLICT (user_id, validator_publickey, tag) DO NOTHING;"
 
-	_, err := FrontendDB.Exec(qry, args...)
+	_, err := FrontendDB.ExecContext(ctx, qry, args...)
 	return err
 }
 
 // RemoveFromWatchlist removes a validator for a given user from the users_validators_tag table
 // It also deletes any subscriptions for that bookmarked validator
-func RemoveFromWatchlist(userId uint64, validator_publickey string, network string) error {
+func RemoveFromWatchlist(ctx context.Context, userId uint64, validator_publickey string, network string) error {
 	key, err := hex.DecodeString(validator_publickey)
 	if err != nil {
 		return err
 	}
-	tx, err := FrontendDB.Begin()
+	tx, err := FrontendDB.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("error starting db transactions: %v", err)
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("DELETE FROM users_subscriptions WHERE user_id = $1 and event_filter = $2 and event_name LIKE ($3 || '%')", userId, validator_publickey, network+":")
+	_, err = tx.ExecContext(ctx, "DELETE FROM users_subscriptions WHERE user_id = $1 and event_filter = $2 and event_name LIKE ($3 || '%')", userId, validator_publickey, network+":")
 	if err != nil {
 		return fmt.Errorf("error deleting subscriptions for validator: %v", err)
 	}
 
 	tag := network + ":" + string(types.ValidatorTagsWatchlist)
 
-	_, err = tx.Exec("DELETE FROM users_validators_tags WHERE user_id = $1 and validator_publickey = $2 and tag = $3", userId, key, tag)
+	_, err = tx.ExecContext(ctx, "DELETE FROM users_validators_tags WHERE user_id = $1 and validator_publickey = $2 and tag = $3", userId, key, tag)
 	if err != nil {
 		return fmt.Errorf("error deleting validator from watchlist: %v", err)
 	}
@@ -89,7 +90,7 @@ type WatchlistFilter struct {
 }
 
 // GetTaggedValidators returns validators that were tagged by a user
-func GetTaggedValidators(filter WatchlistFilter) ([]*types.TaggedValidators, error) {
+func GetTaggedValidators(ctx context.Context, filter WatchlistFilter) ([]*types.TaggedValidators, error) {
 	list := []*types.TaggedValidators{}
 	args := make([]interface{}, 0)
 
@@ -112,7 +113,7 @@ func GetTaggedValidators(filter WatchlistFilter) ([]*types.TaggedValidators, err
 	}
 
 	qry += " ORDER BY validator_publickey desc "
-	err := FrontendDB.Select(&list, qry, args...)
+	err := FrontendDB.SelectContext(ctx, &list, qry, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +128,7 @@ func GetTaggedValidators(filter WatchlistFilter) ([]*types.TaggedValidators, err
 
 	validators := make([]*types.Validator, 0, len(list))
 	if filter.JoinValidators {
-		err := DB.Select(&validators, `SELECT balance, pubkey, validatorindex FROM validators WHERE pubkey = ANY($1) ORDER BY pubkey desc`, *filter.Validators)
+		err := DB.SelectContext(ctx, &validators, `SELECT balance, pubkey, validatorindex FROM validators WHERE pubkey = ANY($1) ORDER BY pubkey desc`, *filter.Validators)
 		if err != nil {
 			return nil, err
 		}
@@ -158,7 +159,7 @@ type GetSubscriptionsFilter struct {
 }
 
 // GetSubscriptions returns the subscriptions filtered by the provided filter.
-func GetSubscriptions(filter GetSubscriptionsFilter) ([]*types.Subscription, error) {
+func GetSubscriptions(ctx context.Context, filter GetSubscriptionsFilter) ([]*types.Subscription, error) {
 	subs := []*types.Subscription{}
 	qry := "SELECT event_name, event_filter, last_sent_ts, last_sent_epoch, created_ts, created_epoch, event_threshold FROM users_subscriptions"
 
@@ -167,7 +168,7 @@ func GetSubscriptions(filter GetSubscriptionsFilter) ([]*types.Subscription, err
 	}
 
 	if filter.EventNames == nil && filter.UserIDs == nil && filter.EventFilters == nil {
-		err := DB.Select(&subs, qry)
+		err := DB.SelectContext(ctx, &subs, qry)
 		return subs, err
 	}
 
@@ -207,7 +208,7 @@ func GetSubscriptions(filter GetSubscriptionsFilter) ([]*types.Subscription, err
 	logger.Infof("user: %v getting subscriptions for query: %v and args: %+v", (*filter.UserIDs)[0], qry, filter)
 	args = append(args, filter.Offset)
 	qry += fmt.Sprintf(" OFFSET $%d", len(args))
-	err := FrontendDB.Select(&subs, qry, args...)
+	err := FrontendDB.SelectContext(ctx, &subs, qry, args...)
 	return subs, err
 }
 
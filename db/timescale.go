@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterRepositoryDriver("timescale", openTimescaleRepositories)
+}
+
+var timescaleBalancesWriter = &BulkWriter{
+	Table:        "validator_balances_ts",
+	Columns:      []string{"ts", "epoch", "validatorindex", "balance", "effectivebalance"},
+	ConflictKeys: []string{"validatorindex", "ts"},
+	Method:       BulkWriteMethodCopy,
+}
+
+// saveTimescaleEpoch mirrors the validator balances and the network stats of the epoch into the hypertables of
+// timescale.sql
+func saveTimescaleEpoch(data *types.EpochData, validatorsCount int, averageValidatorBalance uint64, tx *BulkTx) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_save_timescale_epoch").Observe(time.Since(start).Seconds())
+	}()
+
+	ts := utils.EpochToTime(data.Epoch)
+	rows := make([][]interface{}, 0, len(data.Validators))
+	for _, v := range data.Validators {
+		rows = append(rows, []interface{}{ts, data.Epoch, v.Index, v.Balance, v.EffectiveBalance})
+	}
+	err := timescaleBalancesWriter.Write(context.Background(), tx, rows)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO network_stats_ts (ts, epoch, validatorscount, eligibleether, averagevalidatorbalance, globalparticipationrate)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (ts) DO UPDATE SET
+			validatorscount = excluded.validatorscount,
+			eligibleether = excluded.eligibleether,
+			averagevalidatorbalance = excluded.averagevalidatorbalance,
+			globalparticipationrate = excluded.globalparticipationrate`,
+		ts, data.Epoch, validatorsCount, data.EpochParticipationStats.EligibleEther, averageValidatorBalance,
+		data.EpochParticipationStats.GlobalParticipationRate)
+	return err
+}
+
+// timescaleAggregates are the continuous aggregates of timescale.sql, their policies only refresh the last days
+var timescaleAggregates = []string{"validator_balances_daily", "network_stats_daily"}
+
+// BackfillTimescale mirrors the validator balances and the network stats of the epochs from startEpoch to endEpoch that
+// have been exported before timescale was enabled into the hypertables and refreshes the continuous aggregates over the
+// days of the epochs, rows that have been mirrored already are kept
+func BackfillTimescale(startEpoch, endEpoch uint64) error {
+	secondsPerEpoch := utils.Config.Chain.SecondsPerSlot * utils.Config.Chain.SlotsPerEpoch
+	_, err := ExporterDB.Exec(`
+		INSERT INTO network_stats_ts (ts, epoch, validatorscount, eligibleether, averagevalidatorbalance, globalparticipationrate)
+		SELECT to_timestamp($1 + epoch * $2), epoch, validatorscount, COALESCE(eligibleether, 0), averagevalidatorbalance, COALESCE(globalparticipationrate, 0)
+		FROM epochs
+		WHERE epoch >= $3 AND epoch <= $4
+		ON CONFLICT (ts) DO NOTHING`,
+		utils.Config.Chain.GenesisTimestamp, secondsPerEpoch, startEpoch, endEpoch)
+	if err != nil {
+		return fmt.Errorf("error backfilling network stats: %w", err)
+	}
+
+	start := time.Now()
+	for epoch := startEpoch; epoch <= endEpoch; epoch++ {
+		_, err := ExporterDB.Exec(`
+			INSERT INTO validator_balances_ts (ts, epoch, validatorindex, balance, effectivebalance)
+			SELECT $1, epoch, validatorindex, balance, effectivebalance
+			FROM validator_balances_p
+			WHERE week = $2 AND epoch = $3
+			ON CONFLICT (validatorindex, ts) DO NOTHING`,
+			utils.EpochToTime(epoch), epoch/utils.EpochsPerWeekPartition, epoch)
+		if err != nil {
+			return fmt.Errorf("error backfilling validator balances of epoch %v: %w", epoch, err)
+		}
+		if (epoch-startEpoch)%100 == 0 {
+			logger.WithFields(logrus.Fields{"epoch": epoch, "duration": time.Since(start)}).Infof("backfilled timescale balances of %v of %v epochs", epoch-startEpoch+1, endEpoch-startEpoch+1)
+		}
+	}
+
+	return RefreshTimescaleAggregates(startEpoch, endEpoch)
+}
+
+// RefreshTimescaleAggregates refreshes the continuous aggregates over the days of the epochs from startEpoch to
+// endEpoch. The aggregate policies only refresh the last days, older epochs that are mirrored later (e.g. by a backfill)
+// have to be refreshed explicitly.
+func RefreshTimescaleAggregates(startEpoch, endEpoch uint64) error {
+	windowStart := utils.EpochToTime(startEpoch).UTC().Truncate(24 * time.Hour)
+	windowEnd := utils.EpochToTime(endEpoch + 1).UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	for _, aggregate := range timescaleAggregates {
+		// refresh_continuous_aggregate can not run inside a transaction
+		_, err := ExporterDB.Exec("CALL refresh_continuous_aggregate($1::regclass, $2::timestamptz, $3::timestamptz)", aggregate, windowStart, windowEnd)
+		if err != nil {
+			return fmt.Errorf("error refreshing continuous aggregate %v: %w", aggregate, err)
+		}
+	}
+	return nil
+}
+
+// GetNetworkStatsDaily returns the daily network stats of the network_stats_daily continuous aggregate
+func GetNetworkStatsDaily() ([]*types.NetworkStatsBucket, error) {
+	stats := []*types.NetworkStatsBucket{}
+	err := DB.Select(&stats, `
+		SELECT day AS ts, validatorscount, eligibleether, averagevalidatorbalance, globalparticipationrate
+		FROM network_stats_daily
+		ORDER BY day`)
+	return stats, err
+}
+
+// GetParticipationRateBuckets returns the average participation rate of the epochs before endEpoch in buckets of the
+// given size
+func GetParticipationRateBuckets(bucket time.Duration, endEpoch uint64) ([]*types.NetworkStatsBucket, error) {
+	stats := []*types.NetworkStatsBucket{}
+	err := DB.Select(&stats, `
+		SELECT time_bucket(make_interval(secs => $1), ts) AS ts, AVG(globalparticipationrate) AS globalparticipationrate
+		FROM network_stats_ts
+		WHERE epoch < $2
+		GROUP BY 1
+		ORDER BY 1`, bucket.Seconds(), endEpoch)
+	return stats, err
+}
+
+// timescaleRepositories reads the balances from the validator_balances_ts hypertable, the attestations are not mirrored
+type timescaleRepositories struct {
+	db *sqlx.DB
+}
+
+// openTimescaleRepositories reads from the explorer database or, if a dsn is given, from another database with the
+// hypertables of timescale.sql
+func openTimescaleRepositories(dsn string) (*Repositories, error) {
+	conn, err := openReadBackendDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &Repositories{Balances: &timescaleRepositories{db: conn}}, nil
+}
+
+func (r *timescaleRepositories) GetBalanceHistory(indices []uint64, startEpoch uint64) ([]*types.ApiValidatorBalance, error) {
+	balances := []*types.ApiValidatorBalance{}
+	err := r.db.Select(&balances, `
+		SELECT epoch, validatorindex, balance, effectivebalance, epoch / 1575 AS week
+		FROM validator_balances_ts
+		WHERE validatorindex = ANY($1) AND ts >= $2
+		ORDER BY validatorindex, ts DESC`, pq.Array(indices), utils.EpochToTime(startEpoch))
+	return balances, err
+}
+
+// GetBalanceHistoryBuckets downsamples the continuous aggregate validator_balances_daily, the buckets are aligned to
+// utc days
+func (r *timescaleRepositories) GetBalanceHistoryBuckets(indices []uint64, startEpoch, bucketDays uint64) ([]*types.ApiValidatorBalanceBucket, error) {
+	secondsPerEpoch := utils.Config.Chain.SecondsPerSlot * utils.Config.Chain.SlotsPerEpoch
+	buckets := []*types.ApiValidatorBalanceBucket{}
+	err := r.db.Select(&buckets, `
+		SELECT
+			validatorindex,
+			GREATEST(EXTRACT(EPOCH FROM MIN(day))::bigint - $3, 0) / $4 AS start_epoch,
+			GREATEST(EXTRACT(EPOCH FROM MAX(day) + INTERVAL '1 day')::bigint - $3, $4) / $4 - 1 AS end_epoch,
+			first(start_balance, day) AS start_balance,
+			last(end_balance, day) AS end_balance,
+			MIN(min_balance) AS min_balance,
+			MAX(max_balance) AS max_balance,
+			last(end_effective_balance, day) AS end_effective_balance
+		FROM validator_balances_daily
+		WHERE validatorindex = ANY($1) AND day >= time_bucket(make_interval(days => $5), $2::timestamptz)
+		GROUP BY validatorindex, time_bucket(make_interval(days => $5), day)
+		ORDER BY validatorindex, time_bucket(make_interval(days => $5), day) DESC`,
+		pq.Array(indices), utils.EpochToTime(startEpoch), utils.Config.Chain.GenesisTimestamp, secondsPerEpoch, bucketDays)
+	return buckets, err
+}
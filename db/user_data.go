@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"time"
+)
+
+// userDataExportQueries select all personal data of a user ($1) that is stored in the frontend database, secrets
+// like password hashes and refresh tokens are not exported
+var userDataExportQueries = map[string]string{
+	"profile":              "SELECT email, email_confirmed, register_ts, theme, stripe_customer_id FROM users WHERE id = $1",
+	"api_keys":             "SELECT api_key FROM users WHERE id = $1 AND api_key IS NOT NULL",
+	"watchlist":            "SELECT validator_publickey, tag FROM users_validators_tags WHERE user_id = $1 ORDER BY validator_publickey",
+	"subscriptions":        "SELECT event_name, event_filter, event_threshold, last_sent_ts, last_sent_epoch, created_ts FROM users_subscriptions WHERE user_id = $1 ORDER BY created_ts",
+	"notifications":        "SELECT event_name, event_filter, sent_ts, epoch FROM users_notifications WHERE user_id = $1 ORDER BY sent_ts",
+	"notification_rules":   "SELECT network, name, metric, threshold, window_size, validator_publickey, created_ts FROM users_notification_rules WHERE user_id = $1 ORDER BY id",
+	"devices":              "SELECT device_name, notify_enabled, active, app_id, created_ts FROM users_devices WHERE user_id = $1 ORDER BY id",
+	"sessions":             "SELECT device, user_agent, ip, created_ts, last_seen FROM users_sessions WHERE user_id = $1 ORDER BY id",
+	"clients":              "SELECT client, client_version, notify_enabled, created_ts FROM users_clients WHERE user_id = $1 ORDER BY id",
+	"validator_ownership":  "SELECT validator_publickey, method, address, message, verified_ts FROM users_validator_ownership WHERE user_id = $1 ORDER BY verified_ts",
+	"validator_hosting":    "SELECT validator_publickey, provider, region, source, updated_ts FROM users_validator_hosting WHERE user_id = $1 ORDER BY updated_ts",
+	"custom_charts":        "SELECT id, definition, created_ts FROM users_custom_charts WHERE user_id = $1 ORDER BY created_ts",
+	"fee_recipient":        "SELECT address, allow_smoothing_pool, updated_ts FROM users_fee_recipients WHERE user_id = $1",
+	"app_subscriptions":    "SELECT product_id, price_micros, currency, store, active, created_at, expires_at FROM users_app_subscriptions WHERE user_id = $1 ORDER BY id",
+	"oauth_apps":           "SELECT app_name, redirect_uri, active, created_ts FROM oauth_apps WHERE owner_id = $1 ORDER BY id",
+	"tags":                 "SELECT network, name, share_id, created_ts FROM users_tags WHERE user_id = $1 ORDER BY network, name",
+	"notifications_log":    "SELECT network, channel, recipient, event_names, subject, payload, status, provider_response, created_ts FROM notifications_log WHERE user_id = $1 ORDER BY id",
+	"notifications_dedup":  "SELECT channel, dedup_key, first_seen_ts, last_seen_ts, last_sent_ts, repeats, follow_ups FROM notifications_dedup WHERE user_id = $1 ORDER BY channel, dedup_key",
+	"mail_suppression":     "SELECT reason, provider, details, created_ts FROM mail_suppressions WHERE email = (SELECT email FROM users WHERE id = $1)",
+	"monitoring_sharing":   "SELECT ts, share FROM stats_sharing WHERE user_id = $1 ORDER BY ts",
+	"deletion_request":     "SELECT requested_ts, scheduled_ts FROM users_deletion_requests WHERE user_id = $1",
+	"stripe_subscriptions": "SELECT s.price_id, s.active, s.purchase_group FROM users_stripe_subscriptions s INNER JOIN users u ON u.stripe_customer_id = s.customer_id WHERE u.id = $1",
+}
+
+// GetUserDataExport returns all personal data stored about the user keyed by category
+func GetUserDataExport(ctx context.Context, userID uint64) (map[string]interface{}, error) {
+	export := map[string]interface{}{}
+	for category, query := range userDataExportQueries {
+		rows, err := FrontendDB.QueryContext(ctx, query, userID)
+		if err != nil {
+			return nil, err
+		}
+		data, err := utils.SqlRowsToJSON(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		export[category] = data
+	}
+	return export, nil
+}
+
+// GetUserDeletionRequest returns the pending deletion of the account of the user or nil if there is none
+func GetUserDeletionRequest(ctx context.Context, userID uint64) (*types.UserDeletionRequest, error) {
+	req := &types.UserDeletionRequest{}
+	err := FrontendDB.GetContext(ctx, req, "SELECT user_id, requested_ts, scheduled_ts FROM users_deletion_requests WHERE user_id = $1", userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return req, err
+}
+
+// ScheduleUserDeletion schedules the deletion of the account of the user, an already pending deletion is not postponed
+func ScheduleUserDeletion(ctx context.Context, userID uint64, delay time.Duration) (*types.UserDeletionRequest, error) {
+	now := time.Now()
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_deletion_requests (user_id, requested_ts, scheduled_ts)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING`, userID, now, now.Add(delay))
+	if err != nil {
+		return nil, err
+	}
+	return GetUserDeletionRequest(ctx, userID)
+}
+
+// CancelUserDeletion cancels the pending deletion of the account of the user
+func CancelUserDeletion(ctx context.Context, userID uint64) error {
+	_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_deletion_requests WHERE user_id = $1", userID)
+	return err
+}
+
+// GetDueUserDeletions returns the users whose scheduled account deletion is due, at most limit users are returned
+func GetDueUserDeletions(limit int) ([]types.UserDeletionDue, error) {
+	users := []types.UserDeletionDue{}
+	err := FrontendDB.Select(&users, `
+		SELECT r.user_id, u.stripe_customer_id
+		FROM users_deletion_requests r
+		LEFT JOIN users u ON u.id = r.user_id
+		WHERE r.scheduled_ts <= $1
+		ORDER BY r.scheduled_ts
+		LIMIT $2`, time.Now(), limit)
+	return users, err
+}
+
+// DeleteUserData scrubs all personal data of the user, only anonymized aggregates (the api usage and the
+// size of the watchlist) are kept. The stripe customer of the user has to be deleted before.
+func DeleteUserData(userID uint64) error {
+	tx, err := FrontendDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// lock the request so that multiple frontend instances do not delete the same user concurrently
+	var locked uint64
+	err = tx.Get(&locked, "SELECT user_id FROM users_deletion_requests WHERE user_id = $1 FOR UPDATE SKIP LOCKED", userID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO users_deleted (register_ts, deleted_ts, validators_count, subscriptions_count)
+		SELECT
+			register_ts,
+			$2,
+			(SELECT COUNT(DISTINCT validator_publickey) FROM users_validators_tags WHERE user_id = $1 AND tag = $3),
+			(SELECT COUNT(*) FROM users_subscriptions WHERE user_id = $1)
+		FROM users WHERE id = $1`, userID, time.Now(), utils.GetNetwork()+":"+string(types.ValidatorTagsWatchlist))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO api_statistics (ts, apikey, call, count)
+		SELECT ts, 'deleted', call, SUM(count)
+		FROM api_statistics
+		WHERE apikey = (SELECT api_key FROM users WHERE id = $1)
+		GROUP BY ts, call
+		ON CONFLICT (ts, apikey, call) DO UPDATE SET count = api_statistics.count + excluded.count`, userID)
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		"DELETE FROM api_statistics WHERE apikey = (SELECT api_key FROM users WHERE id = $1)",
+		"DELETE FROM users_validators_tags WHERE user_id = $1",
+		"DELETE FROM users_tags WHERE user_id = $1",
+		"DELETE FROM users_subscriptions WHERE user_id = $1",
+		"DELETE FROM users_notifications WHERE user_id = $1",
+		"DELETE FROM users_notification_rules WHERE user_id = $1",
+		"DELETE FROM notifications_log WHERE user_id = $1",
+		"DELETE FROM notifications_dedup WHERE user_id = $1",
+		"DELETE FROM users_devices WHERE user_id = $1",
+		"DELETE FROM users_sessions WHERE user_id = $1",
+		"DELETE FROM users_clients WHERE user_id = $1",
+		"DELETE FROM users_fee_recipients WHERE user_id = $1",
+		"DELETE FROM users_validator_ownership WHERE user_id = $1",
+		"DELETE FROM users_validator_hosting WHERE user_id = $1",
+		"DELETE FROM users_custom_charts WHERE user_id = $1",
+		"DELETE FROM oauth_codes WHERE user_id = $1",
+		"DELETE FROM oauth_codes WHERE app_id IN (SELECT id FROM oauth_apps WHERE owner_id = $1)",
+		"DELETE FROM oauth_apps WHERE owner_id = $1",
+		"DELETE FROM mail_suppressions WHERE email = (SELECT email FROM users WHERE id = $1)",
+		"DELETE FROM stats_sharing WHERE user_id = $1",
+		// the purchases are kept for accounting but without the receipts that identify the user at the store
+		"UPDATE users_app_subscriptions SET receipt = '' WHERE user_id = $1",
+		"DELETE FROM users WHERE id = $1",
+		"DELETE FROM users_deletion_requests WHERE user_id = $1",
+	}
+	for _, stmt := range statements {
+		_, err = tx.Exec(stmt, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
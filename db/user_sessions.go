@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"time"
+)
+
+// userSessionTouchInterval is the minimum time between two updates of the last seen time of a session
+const userSessionTouchInterval = time.Minute
+
+// CreateUserSession stores a new login of the user and returns the key the session cookie has to carry
+func CreateUserSession(ctx context.Context, userID uint64, device, userAgent, ip string) (string, error) {
+	key := utils.RandomString(40)
+	now := time.Now()
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_sessions (user_id, session_key, device, user_agent, ip, created_ts, last_seen)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)`, userID, key, device, userAgent, ip, now)
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// TouchUserSession returns whether the session with the key is still active for the user, the last seen time and ip
+// address of an active session are updated at most once per userSessionTouchInterval
+func TouchUserSession(ctx context.Context, userID uint64, key, ip string) (bool, error) {
+	var lastSeen time.Time
+	err := FrontendDB.GetContext(ctx, &lastSeen, "SELECT last_seen FROM users_sessions WHERE session_key = $1 AND user_id = $2", key, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if time.Since(lastSeen) < userSessionTouchInterval {
+		return true, nil
+	}
+	_, err = FrontendDB.ExecContext(ctx, "UPDATE users_sessions SET last_seen = $1, ip = $2 WHERE session_key = $3", time.Now(), ip, key)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetUserSessions returns the active sessions of the user, the session with the given key is marked as current
+func GetUserSessions(ctx context.Context, userID uint64, currentKey string) ([]*types.UserSession, error) {
+	rows := []struct {
+		types.UserSession
+		SessionKey string `db:"session_key"`
+	}{}
+	err := FrontendDB.SelectContext(ctx, &rows, `
+		SELECT id, session_key, device, ip, created_ts, last_seen
+		FROM users_sessions
+		WHERE user_id = $1
+		ORDER BY last_seen DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*types.UserSession, 0, len(rows))
+	for i := range rows {
+		s := rows[i].UserSession
+		s.Current = rows[i].SessionKey == currentKey
+		sessions = append(sessions, &s)
+	}
+	return sessions, nil
+}
+
+// RevokeUserSession ends the session with the id of the user
+func RevokeUserSession(ctx context.Context, userID, id uint64) error {
+	res, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_sessions WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeUserSessionByKey ends the session with the key, e.g. on logout
+func RevokeUserSessionByKey(ctx context.Context, key string) error {
+	_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_sessions WHERE session_key = $1", key)
+	return err
+}
+
+// RevokeUserSessions ends all sessions of the user except the one with the given key, all sessions are ended if the
+// key is empty
+func RevokeUserSessions(ctx context.Context, userID uint64, exceptKey string) error {
+	_, err := FrontendDB.ExecContext(ctx, "DELETE FROM users_sessions WHERE user_id = $1 AND session_key != $2", userID, exceptKey)
+	return err
+}
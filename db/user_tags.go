@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrUserTagNotFound is returned when a user tag that does not exist is modified
+var ErrUserTagNotFound = fmt.Errorf("tag not found")
+
+// userTag returns the tag of the validators of a user defined tag in users_validators_tags
+func userTag(network, name string) string {
+	return network + ":" + string(types.ValidatorTagsUserPrefix) + name
+}
+
+// GetUserTags returns the tags the user defined on the network and the number of validators of each tag
+func GetUserTags(ctx context.Context, userID uint64, network string) ([]*types.UserValidatorTag, error) {
+	tags := []*types.UserValidatorTag{}
+	err := FrontendDB.SelectContext(ctx, &tags, `
+		SELECT ut.name, ut.created_ts, ut.share_id, COUNT(uvt.validator_publickey) AS validators
+		FROM users_tags ut
+		LEFT JOIN users_validators_tags uvt ON uvt.user_id = ut.user_id AND uvt.tag = $2 || ':' || $3 || ut.name
+		WHERE ut.user_id = $1 AND ut.network = $2
+		GROUP BY ut.name, ut.created_ts, ut.share_id
+		ORDER BY ut.name`, userID, network, string(types.ValidatorTagsUserPrefix))
+	return tags, err
+}
+
+// CountUserTags returns the number of tags the user defined on the network
+func CountUserTags(ctx context.Context, userID uint64, network string) (uint64, error) {
+	var count uint64
+	err := FrontendDB.GetContext(ctx, &count, `SELECT COUNT(*) FROM users_tags WHERE user_id = $1 AND network = $2`, userID, network)
+	return count, err
+}
+
+// CreateUserTag creates a tag of the user, creating an existing tag is a no-op
+func CreateUserTag(ctx context.Context, userID uint64, network, name string) error {
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_tags (user_id, network, name, created_ts)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, network, name) DO NOTHING`, userID, network, name)
+	return err
+}
+
+// DeleteUserTag deletes the tag of the user and unassigns its validators
+func DeleteUserTag(ctx context.Context, userID uint64, network, name string) error {
+	tx, err := FrontendDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM users_tags WHERE user_id = $1 AND network = $2 AND name = $3`, userID, network, name)
+	if err != nil {
+		return fmt.Errorf("error deleting tag: %w", err)
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		return ErrUserTagNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM users_validators_tags WHERE user_id = $1 AND tag = $2`, userID, userTag(network, name))
+	if err != nil {
+		return fmt.Errorf("error unassigning validators of tag: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ShareUserTag makes the dashboard of the tag public and returns its share id, sharing a tag that is already public
+// returns the existing share id
+func ShareUserTag(ctx context.Context, userID uint64, network, name, shareID string) (string, error) {
+	err := FrontendDB.GetContext(ctx, &shareID, `
+		UPDATE users_tags SET share_id = COALESCE(share_id, $4)
+		WHERE user_id = $1 AND network = $2 AND name = $3
+		RETURNING share_id`, userID, network, name, shareID)
+	if err == sql.ErrNoRows {
+		return "", ErrUserTagNotFound
+	}
+	return shareID, err
+}
+
+// UnshareUserTag makes the dashboard of the tag private again, links shared before stop working
+func UnshareUserTag(ctx context.Context, userID uint64, network, name string) error {
+	res, err := FrontendDB.ExecContext(ctx, `UPDATE users_tags SET share_id = NULL WHERE user_id = $1 AND network = $2 AND name = $3`, userID, network, name)
+	if err != nil {
+		return err
+	}
+	if updated, err := res.RowsAffected(); err == nil && updated == 0 {
+		return ErrUserTagNotFound
+	}
+	return nil
+}
+
+// GetSharedUserTagValidatorIndices returns the owner, the name and the indices of the validators of the public tag with
+// the share id, ErrUserTagNotFound is returned if no tag of the network is shared with the id
+func GetSharedUserTagValidatorIndices(ctx context.Context, shareID, network string) (userID uint64, name string, indices []uint64, err error) {
+	tag := struct {
+		UserID uint64 `db:"user_id"`
+		Name   string `db:"name"`
+	}{}
+	err = FrontendDB.GetContext(ctx, &tag, `SELECT user_id, name FROM users_tags WHERE share_id = $1 AND network = $2`, shareID, network)
+	if err == sql.ErrNoRows {
+		return 0, "", nil, ErrUserTagNotFound
+	}
+	if err != nil {
+		return 0, "", nil, err
+	}
+	indices, err = GetUserTagValidatorIndices(ctx, tag.UserID, network, tag.Name)
+	return tag.UserID, tag.Name, indices, err
+}
+
+// userTagExists returns ErrUserTagNotFound if the user did not create the tag
+func userTagExists(ctx context.Context, userID uint64, network, name string) error {
+	var exists bool
+	err := FrontendDB.GetContext(ctx, &exists, `SELECT true FROM users_tags WHERE user_id = $1 AND network = $2 AND name = $3`, userID, network, name)
+	if err == sql.ErrNoRows {
+		return ErrUserTagNotFound
+	}
+	return err
+}
+
+// GetUserTagValidators returns the public keys of the validators of the tag of the user
+func GetUserTagValidators(ctx context.Context, userID uint64, network, name string) ([][]byte, error) {
+	err := userTagExists(ctx, userID, network, name)
+	if err != nil {
+		return nil, err
+	}
+	pubkeys := [][]byte{}
+	err = FrontendDB.SelectContext(ctx, &pubkeys, `
+		SELECT validator_publickey
+		FROM users_validators_tags
+		WHERE user_id = $1 AND tag = $2
+		ORDER BY validator_publickey`, userID, userTag(network, name))
+	return pubkeys, err
+}
+
+// AddValidatorsToUserTag assigns the validators to the tag of the user, the tag must not end up with more than limit
+// validators
+func AddValidatorsToUserTag(ctx context.Context, userID uint64, network, name string, pubkeys [][]byte, limit int) error {
+	err := userTagExists(ctx, userID, network, name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := FrontendDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting db transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tag := userTag(network, name)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users_validators_tags (user_id, validator_publickey, tag)
+		SELECT $1, pubkey, $2 FROM UNNEST($3::bytea[]) AS pubkey
+		ON CONFLICT (user_id, validator_publickey, tag) DO NOTHING`, userID, tag, pq.ByteaArray(pubkeys))
+	if err != nil {
+		return fmt.Errorf("error assigning validators to tag: %w", err)
+	}
+
+	var count int
+	err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM users_validators_tags WHERE user_id = $1 AND tag = $2`, userID, tag)
+	if err != nil {
+		return fmt.Errorf("error counting validators of tag: %w", err)
+	}
+	if count > limit {
+		return fmt.Errorf("a tag can not have more than %v validators", limit)
+	}
+	return tx.Commit()
+}
+
+// RemoveValidatorsFromUserTag unassigns the validators from the tag of the user
+func RemoveValidatorsFromUserTag(ctx context.Context, userID uint64, network, name string, pubkeys [][]byte) error {
+	err := userTagExists(ctx, userID, network, name)
+	if err != nil {
+		return err
+	}
+	_, err = FrontendDB.ExecContext(ctx, `
+		DELETE FROM users_validators_tags
+		WHERE user_id = $1 AND tag = $2 AND validator_publickey = ANY($3)`, userID, userTag(network, name), pq.ByteaArray(pubkeys))
+	return err
+}
+
+// GetValidatorPubkeys returns the public keys of the validators given by index or public key, unknown validators are
+// omitted
+func GetValidatorPubkeys(ctx context.Context, indices []uint64, pubkeys pq.ByteaArray) ([][]byte, error) {
+	res := [][]byte{}
+	err := DB.SelectContext(ctx, &res, `
+		SELECT pubkey
+		FROM validators
+		WHERE validatorindex = ANY($1) OR pubkey = ANY($2)
+		ORDER BY validatorindex`, pq.Array(indices), pubkeys)
+	return res, err
+}
+
+// GetUserTagValidatorIndices returns the indices of the validators of the tag of the user, validators without an index
+// are omitted
+func GetUserTagValidatorIndices(ctx context.Context, userID uint64, network, name string) ([]uint64, error) {
+	pubkeys, err := GetUserTagValidators(ctx, userID, network, name)
+	if err != nil {
+		return nil, err
+	}
+	return GetValidatorIndicesByPubkeys(ctx, pq.ByteaArray(pubkeys))
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"eth2-exporter/utils"
+
+	"github.com/lib/pq"
+)
+
+// GetNetworkParticipation returns the average global participation rate of the epochs from startEpoch to endEpoch
+func GetNetworkParticipation(startEpoch, endEpoch uint64) (float64, error) {
+	var participation sql.NullFloat64
+	err := DB.Get(&participation, `
+		SELECT AVG(globalparticipationrate)
+		FROM epochs
+		WHERE epoch >= $1 AND epoch <= $2 AND globalparticipationrate > 0`, startEpoch, endEpoch)
+	return participation.Float64, err
+}
+
+// GetWithdrawalSiblingsEffectiveness returns the withdrawal credentials of the validator, the number of the other active
+// validators with the same credentials and how many of them had an attestation effectiveness (in percent) below the
+// threshold on at least one of the days from startDay to endDay. The effectiveness is only evaluated if there are at
+// most maxSiblings other validators, siblings is maxSiblings+1 and dropped 0 otherwise.
+func GetWithdrawalSiblingsEffectiveness(validatorIndex, startDay, endDay uint64, threshold float64, maxSiblings uint64) (credentials []byte, siblings, dropped uint64, err error) {
+	epochsPerDay := utils.EpochsPerDay()
+	err = DB.Get(&credentials, "SELECT withdrawalcredentials FROM validators WHERE validatorindex = $1", validatorIndex)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, 0, nil
+		}
+		return nil, 0, 0, err
+	}
+
+	indices := []uint64{}
+	err = DB.Select(&indices, `
+		SELECT validatorindex
+		FROM validators
+		WHERE withdrawalcredentials = $1 AND validatorindex <> $2 AND activationepoch <= $3 AND exitepoch > $3
+		LIMIT $4`,
+		credentials, validatorIndex, (endDay+1)*epochsPerDay, maxSiblings+1)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	siblings = uint64(len(indices))
+	if siblings == 0 || siblings > maxSiblings {
+		return credentials, siblings, 0, nil
+	}
+
+	err = DB.Get(&dropped, `
+		SELECT COUNT(*)
+		FROM (
+			SELECT validatorindex
+			FROM validator_stats
+			WHERE validatorindex = ANY($1) AND day >= $2 AND day <= $3
+			GROUP BY validatorindex
+			HAVING (1 - MAX(COALESCE(missed_attestations, 0))::float / $4) * 100 < $5
+		) s`,
+		pq.Array(indices), startDay, endDay, epochsPerDay, threshold)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return credentials, siblings, dropped, nil
+}
+
+// GetValidatorRecentGraffiti returns the graffiti of the last canonical blocks proposed by the validator, newest first
+func GetValidatorRecentGraffiti(validatorIndex uint64, limit uint64) ([][]byte, error) {
+	graffiti := [][]byte{}
+	err := DB.Select(&graffiti, `
+		SELECT graffiti
+		FROM blocks
+		WHERE proposer = $1 AND status = '1'
+		ORDER BY slot DESC
+		LIMIT $2`, validatorIndex, limit)
+	return graffiti, err
+}
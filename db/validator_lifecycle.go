@@ -0,0 +1,235 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// validatorLifecycleFarFutureEpoch is the far future epoch as it is stored in the validators table
+const validatorLifecycleFarFutureEpoch = uint64(9223372036854775807)
+
+// The types of the events of a validator lifecycle
+const (
+	ValidatorLifecycleEth1Deposit           = "eth1_deposit"
+	ValidatorLifecycleBeaconDeposit         = "beacon_deposit"
+	ValidatorLifecycleActivationEligibility = "activation_eligibility"
+	ValidatorLifecycleActivation            = "activation"
+	ValidatorLifecycleCredentialChange      = "credential_change"
+	ValidatorLifecycleSlashing              = "slashing"
+	ValidatorLifecycleVoluntaryExit         = "voluntary_exit"
+	ValidatorLifecycleExit                  = "exit"
+	ValidatorLifecycleWithdrawable          = "withdrawable"
+	ValidatorLifecycleFullWithdrawal        = "full_withdrawal"
+)
+
+// GetValidatorLifecycle returns the lifecycle of the validator with the given pubkey or, if the pubkey is nil, index.
+// Nil is returned if the validator does not exist. Events of epochs after latestEpoch (e.g. a scheduled exit) are
+// marked as scheduled. The full withdrawal is only part of the lifecycle once it has been included, see
+// services.EstimateFullWithdrawal for its estimate.
+func GetValidatorLifecycle(ctx context.Context, index uint64, pubkey []byte, latestEpoch uint64) (*types.ValidatorLifecycle, error) {
+	condition, arg := "validatorindex = $1", interface{}(index)
+	if pubkey != nil {
+		condition, arg = "pubkey = $1", pubkey
+	}
+
+	v := struct {
+		Validatorindex             uint64 `db:"validatorindex"`
+		Pubkey                     []byte `db:"pubkey"`
+		WithdrawalCredentials      []byte `db:"withdrawalcredentials"`
+		Balance                    uint64 `db:"balance"`
+		EffectiveBalance           uint64 `db:"effectivebalance"`
+		Slashed                    bool   `db:"slashed"`
+		Status                     string `db:"status"`
+		ActivationEligibilityEpoch uint64 `db:"activationeligibilityepoch"`
+		ActivationEpoch            uint64 `db:"activationepoch"`
+		ExitEpoch                  uint64 `db:"exitepoch"`
+		WithdrawableEpoch          uint64 `db:"withdrawableepoch"`
+	}{}
+	err := DB.GetContext(ctx, &v, `
+		SELECT
+			validatorindex, pubkey, withdrawalcredentials, balance, effectivebalance, slashed, status,
+			activationeligibilityepoch, activationepoch, exitepoch, withdrawableepoch
+		FROM validators
+		WHERE `+condition, arg)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving validator: %w", err)
+	}
+
+	lifecycle := &types.ValidatorLifecycle{
+		Validatorindex:        v.Validatorindex,
+		Pubkey:                fmt.Sprintf("0x%x", v.Pubkey),
+		WithdrawalCredentials: fmt.Sprintf("0x%x", v.WithdrawalCredentials),
+		Status:                v.Status,
+		Slashed:               v.Slashed,
+		Balance:               v.Balance,
+		EffectiveBalance:      v.EffectiveBalance,
+		Events:                []*types.ValidatorLifecycleEvent{},
+	}
+
+	epochEvent := func(eventType string, epoch uint64) *types.ValidatorLifecycleEvent {
+		return &types.ValidatorLifecycleEvent{
+			Type:      eventType,
+			Epoch:     &epoch,
+			Ts:        utils.EpochToTime(epoch),
+			Scheduled: epoch > latestEpoch,
+		}
+	}
+	slotEvent := func(eventType string, slot uint64) *types.ValidatorLifecycleEvent {
+		e := epochEvent(eventType, utils.EpochOfSlot(slot))
+		e.Slot = &slot
+		e.Ts = utils.SlotToTime(slot)
+		return e
+	}
+
+	eth1Deposits := []struct {
+		TxHash                []byte    `db:"tx_hash"`
+		BlockNumber           uint64    `db:"block_number"`
+		BlockTs               time.Time `db:"block_ts"`
+		FromAddress           []byte    `db:"from_address"`
+		Amount                uint64    `db:"amount"`
+		WithdrawalCredentials []byte    `db:"withdrawal_credentials"`
+		ValidSignature        bool      `db:"valid_signature"`
+	}{}
+	err = DB.SelectContext(ctx, &eth1Deposits, `
+		SELECT tx_hash, block_number, block_ts, from_address, amount, withdrawal_credentials, valid_signature
+		FROM eth1_deposits
+		WHERE publickey = $1 AND NOT removed
+		ORDER BY block_number, merkletree_index`, v.Pubkey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving eth1 deposits: %w", err)
+	}
+	for _, d := range eth1Deposits {
+		blockNumber := d.BlockNumber
+		validSignature := d.ValidSignature
+		lifecycle.Events = append(lifecycle.Events, &types.ValidatorLifecycleEvent{
+			Type:                  ValidatorLifecycleEth1Deposit,
+			Ts:                    d.BlockTs,
+			BlockNumber:           &blockNumber,
+			TxHash:                fmt.Sprintf("0x%x", d.TxHash),
+			Address:               fmt.Sprintf("0x%x", d.FromAddress),
+			Amount:                d.Amount,
+			WithdrawalCredentials: fmt.Sprintf("0x%x", d.WithdrawalCredentials),
+			ValidSignature:        &validSignature,
+		})
+	}
+
+	beaconDeposits := []struct {
+		BlockSlot             uint64 `db:"block_slot"`
+		Amount                uint64 `db:"amount"`
+		WithdrawalCredentials []byte `db:"withdrawalcredentials"`
+	}{}
+	err = DB.SelectContext(ctx, &beaconDeposits, `
+		SELECT d.block_slot, d.amount, d.withdrawalcredentials
+		FROM blocks_deposits d
+			INNER JOIN blocks b ON b.slot = d.block_slot AND b.status = '1'
+		WHERE d.publickey = $1
+		ORDER BY d.block_slot, d.block_index`, v.Pubkey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving beacon deposits: %w", err)
+	}
+	for _, d := range beaconDeposits {
+		e := slotEvent(ValidatorLifecycleBeaconDeposit, d.BlockSlot)
+		e.Amount = d.Amount
+		e.WithdrawalCredentials = fmt.Sprintf("0x%x", d.WithdrawalCredentials)
+		lifecycle.Events = append(lifecycle.Events, e)
+	}
+
+	if v.ActivationEligibilityEpoch < validatorLifecycleFarFutureEpoch {
+		lifecycle.Events = append(lifecycle.Events, epochEvent(ValidatorLifecycleActivationEligibility, v.ActivationEligibilityEpoch))
+	}
+	if v.ActivationEpoch < validatorLifecycleFarFutureEpoch {
+		lifecycle.Events = append(lifecycle.Events, epochEvent(ValidatorLifecycleActivation, v.ActivationEpoch))
+	}
+
+	credentialChanges := []struct {
+		BlockSlot uint64 `db:"block_slot"`
+		Address   []byte `db:"address"`
+	}{}
+	err = DB.SelectContext(ctx, &credentialChanges, `
+		SELECT c.block_slot, c.address
+		FROM blocks_bls_change c
+			INNER JOIN blocks b ON b.slot = c.block_slot AND b.status = '1'
+		WHERE c.validatorindex = $1
+		ORDER BY c.block_slot`, v.Validatorindex)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving credential changes: %w", err)
+	}
+	for _, c := range credentialChanges {
+		e := slotEvent(ValidatorLifecycleCredentialChange, c.BlockSlot)
+		e.Address = fmt.Sprintf("0x%x", c.Address)
+		lifecycle.Events = append(lifecycle.Events, e)
+	}
+
+	slashings := []struct {
+		BlockSlot uint64 `db:"block_slot"`
+		Proposer  uint64 `db:"proposer"`
+		Reason    string `db:"reason"`
+	}{}
+	err = DB.SelectContext(ctx, &slashings, `
+		SELECT s.block_slot, b.proposer, 'proposer_slashing' AS reason
+		FROM blocks_proposerslashings s
+			INNER JOIN blocks b ON b.slot = s.block_slot AND b.status = '1'
+		WHERE s.proposerindex = $1
+		UNION ALL
+		SELECT s.block_slot, b.proposer, 'attester_slashing' AS reason
+		FROM blocks_attesterslashings s
+			INNER JOIN blocks b ON b.slot = s.block_slot AND b.status = '1'
+		WHERE $1 = ANY(s.attestation1_indices) AND $1 = ANY(s.attestation2_indices)
+		ORDER BY block_slot`, v.Validatorindex)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving slashings: %w", err)
+	}
+	for _, s := range slashings {
+		proposer := s.Proposer
+		e := slotEvent(ValidatorLifecycleSlashing, s.BlockSlot)
+		e.SlashedBy = &proposer
+		e.Reason = s.Reason
+		lifecycle.Events = append(lifecycle.Events, e)
+	}
+
+	var exitSlots []uint64
+	err = DB.SelectContext(ctx, &exitSlots, `
+		SELECT e.block_slot
+		FROM blocks_voluntaryexits e
+			INNER JOIN blocks b ON b.slot = e.block_slot AND b.status = '1'
+		WHERE e.validatorindex = $1
+		ORDER BY e.block_slot`, v.Validatorindex)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving voluntary exits: %w", err)
+	}
+	for _, slot := range exitSlots {
+		lifecycle.Events = append(lifecycle.Events, slotEvent(ValidatorLifecycleVoluntaryExit, slot))
+	}
+
+	if v.ExitEpoch < validatorLifecycleFarFutureEpoch {
+		lifecycle.Events = append(lifecycle.Events, epochEvent(ValidatorLifecycleExit, v.ExitEpoch))
+	}
+	if v.WithdrawableEpoch < validatorLifecycleFarFutureEpoch {
+		lifecycle.Events = append(lifecycle.Events, epochEvent(ValidatorLifecycleWithdrawable, v.WithdrawableEpoch))
+	}
+	// the sweep only withdraws the balance of validators with execution withdrawal credentials once they are withdrawable
+	if v.WithdrawableEpoch < validatorLifecycleFarFutureEpoch && len(v.WithdrawalCredentials) > 0 && v.WithdrawalCredentials[0] != 0x00 {
+		withdrawal, err := GetValidatorFullWithdrawal(ctx, v.Validatorindex, v.WithdrawableEpoch*utils.Config.Chain.SlotsPerEpoch)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving full withdrawal: %w", err)
+		}
+		if withdrawal != nil {
+			e := slotEvent(ValidatorLifecycleFullWithdrawal, withdrawal.Slot)
+			e.Amount = withdrawal.Amount
+			lifecycle.Events = append(lifecycle.Events, e)
+		}
+	}
+
+	sort.SliceStable(lifecycle.Events, func(i, j int) bool {
+		return lifecycle.Events[i].Ts.Before(lifecycle.Events[j].Ts)
+	})
+	return lifecycle, nil
+}
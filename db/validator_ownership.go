@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+)
+
+// SaveValidatorOwnership stores the verified ownership of a validator, a previous verification of the user is replaced
+func SaveValidatorOwnership(ctx context.Context, ownership *types.ValidatorOwnership) error {
+	_, err := FrontendDB.ExecContext(ctx, `
+		INSERT INTO users_validator_ownership (user_id, validator_publickey, method, address, message, signature, verified_ts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, validator_publickey) DO UPDATE SET
+			method = excluded.method,
+			address = excluded.address,
+			message = excluded.message,
+			signature = excluded.signature,
+			verified_ts = excluded.verified_ts`,
+		ownership.UserID, ownership.ValidatorPublickey, ownership.Method, ownership.Address, ownership.Message, ownership.Signature, ownership.VerifiedTs)
+	return err
+}
+
+// GetValidatorOwnership returns the verified ownership of the validator by the user, nil is returned if the user has not verified it
+func GetValidatorOwnership(ctx context.Context, userID uint64, pubkey []byte) (*types.ValidatorOwnership, error) {
+	ownership := &types.ValidatorOwnership{}
+	err := FrontendDB.GetContext(ctx, ownership, `
+		SELECT user_id, validator_publickey, method, address, message, signature, verified_ts
+		FROM users_validator_ownership
+		WHERE user_id = $1 AND validator_publickey = $2`, userID, pubkey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// IsValidatorOwnerVerified returns true if any user has verified the ownership of the validator
+func IsValidatorOwnerVerified(ctx context.Context, pubkey []byte) (bool, error) {
+	verified := false
+	err := FrontendDB.GetContext(ctx, &verified, "SELECT EXISTS(SELECT 1 FROM users_validator_ownership WHERE validator_publickey = $1)", pubkey)
+	return verified, err
+}
+
+// GetUserVerifiedValidatorCount returns the number of validators whose ownership the user has verified
+func GetUserVerifiedValidatorCount(ctx context.Context, userID uint64) (int, error) {
+	count := 0
+	err := FrontendDB.GetContext(ctx, &count, "SELECT COUNT(*) FROM users_validator_ownership WHERE user_id = $1", userID)
+	return count, err
+}
+
+// GetValidatorWithdrawalCredentials returns the withdrawal credentials of the validator
+func GetValidatorWithdrawalCredentials(ctx context.Context, pubkey []byte) ([]byte, error) {
+	var credentials []byte
+	err := DB.GetContext(ctx, &credentials, "SELECT withdrawalcredentials FROM validators WHERE pubkey = $1", pubkey)
+	return credentials, err
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"strconv"
+	"time"
+)
+
+// ValidatorPeerActivationWindow is the number of epochs of the activation windows validators are grouped in to be
+// compared against the validators activated around the same time, the week of the partitioned tables
+const ValidatorPeerActivationWindow = utils.EpochsPerWeekPartition
+
+// validatorPeerStatsQuery selects the apr, effectiveness and inclusion distance of the validators active during the
+// whole day $1 (epochs $2 to $3) from the validator_stats and validator_effectiveness of the day. The activation window
+// is computed with ValidatorPeerActivationWindow, the inclusion distance is derived from the inclusion distance
+// effectiveness, which is its inverse.
+var validatorPeerStatsQuery = `
+	SELECT
+		vs.validatorindex,
+		v.activationepoch / ` + strconv.FormatUint(ValidatorPeerActivationWindow, 10) + ` AS activation_window,
+		(vs.end_balance - vs.start_balance - COALESCE(vs.deposits_amount, 0))::float / vs.start_effective_balance * 365 AS apr,
+		e.effectiveness,
+		100 / NULLIF(d.effectiveness, 0) AS inclusion_distance
+	FROM validator_stats vs
+		INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+		LEFT JOIN validator_effectiveness e ON e.validatorindex = vs.validatorindex AND e.day = vs.day AND e.formula = '` + DefaultEffectivenessFormula + `'
+		LEFT JOIN validator_effectiveness d ON d.validatorindex = vs.validatorindex AND d.day = vs.day AND d.formula = 'inclusion_distance'
+	WHERE vs.day = $1 AND v.activationepoch <= $2 AND v.exitepoch > $3 AND vs.start_effective_balance > 0`
+
+// WriteValidatorPeerMediansForDay computes the medians of all validators and of every activation window of the day.
+// The validator_stats and validator_effectiveness of the day have to be exported before.
+func WriteValidatorPeerMediansForDay(day uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_peer_medians").Observe(time.Since(start).Seconds())
+	}()
+
+	epochsPerDay := utils.EpochsPerDay()
+	firstEpoch := day * epochsPerDay
+	lastEpoch := (day+1)*epochsPerDay - 1
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM validator_peer_medians WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO validator_peer_medians (day, activation_window, validators, apr, effectiveness, inclusion_distance)
+		SELECT
+			$1,
+			COALESCE(activation_window, -1),
+			COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY apr),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY effectiveness),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY inclusion_distance)
+		FROM (`+validatorPeerStatsQuery+`) a
+		GROUP BY GROUPING SETS ((activation_window), ())`, day, firstEpoch, lastEpoch)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	logger.Infof("exported validator peer medians for day %v, took %v", day, time.Since(start))
+	return nil
+}
+
+// GetValidatorPeerComparison compares the validator against the network and its activation window over the last days
+// with exported medians, nil is returned if the validator does not exist
+func GetValidatorPeerComparison(ctx context.Context, index uint64, days uint64) (*types.ValidatorPeerComparison, error) {
+	var activationEpoch uint64
+	err := DB.GetContext(ctx, &activationEpoch, "SELECT activationepoch FROM validators WHERE validatorindex = $1", index)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	window := activationEpoch / ValidatorPeerActivationWindow
+	comparison := &types.ValidatorPeerComparison{
+		Validatorindex:        index,
+		ActivationWindowStart: window * ValidatorPeerActivationWindow,
+		ActivationWindowEnd:   (window+1)*ValidatorPeerActivationWindow - 1,
+		Days:                  []*types.ValidatorPeerComparisonDay{},
+	}
+
+	var lastDay sql.NullInt64
+	err = DB.GetContext(ctx, &lastDay, "SELECT MAX(day) FROM validator_peer_medians")
+	if err != nil {
+		return nil, err
+	}
+	if !lastDay.Valid {
+		return comparison, nil
+	}
+	firstDay := int64(0)
+	if lastDay.Int64 >= int64(days) {
+		firstDay = lastDay.Int64 - int64(days) + 1
+	}
+
+	medians := []*struct {
+		Day              uint64 `db:"day"`
+		ActivationWindow int64  `db:"activation_window"`
+		Validators       uint64 `db:"validators"`
+		types.ValidatorPeerStats
+	}{}
+	err = DB.SelectContext(ctx, &medians, `
+		SELECT day, activation_window, validators, apr, effectiveness, inclusion_distance
+		FROM validator_peer_medians
+		WHERE day >= $1 AND day <= $2 AND activation_window IN (-1, $3)
+		ORDER BY day`, firstDay, lastDay.Int64, window)
+	if err != nil {
+		return nil, err
+	}
+
+	daysByDay := map[uint64]*types.ValidatorPeerComparisonDay{}
+	for _, m := range medians {
+		d := daysByDay[m.Day]
+		if d == nil {
+			d = &types.ValidatorPeerComparisonDay{Day: m.Day}
+			daysByDay[m.Day] = d
+			comparison.Days = append(comparison.Days, d)
+		}
+		stats := m.ValidatorPeerStats
+		if m.ActivationWindow == -1 {
+			d.Network = &stats
+			comparison.NetworkValidatorsCount = m.Validators
+		} else {
+			d.Cohort = &stats
+			comparison.CohortValidatorsCount = m.Validators
+		}
+	}
+
+	for _, d := range comparison.Days {
+		firstEpoch := d.Day * utils.EpochsPerDay()
+		lastEpoch := (d.Day+1)*utils.EpochsPerDay() - 1
+		stats := []*types.ValidatorPeerStats{}
+		err = DB.SelectContext(ctx, &stats, `
+			SELECT apr, effectiveness, inclusion_distance
+			FROM (`+validatorPeerStatsQuery+`) a
+			WHERE validatorindex = $4`, d.Day, firstEpoch, lastEpoch, index)
+		if err != nil {
+			return nil, err
+		}
+		if len(stats) > 0 {
+			d.Validator = stats[0]
+		}
+	}
+
+	comparison.Validator = averageValidatorPeerStats(comparison.Days, func(d *types.ValidatorPeerComparisonDay) *types.ValidatorPeerStats { return d.Validator })
+	comparison.Cohort = averageValidatorPeerStats(comparison.Days, func(d *types.ValidatorPeerComparisonDay) *types.ValidatorPeerStats { return d.Cohort })
+	comparison.Network = averageValidatorPeerStats(comparison.Days, func(d *types.ValidatorPeerComparisonDay) *types.ValidatorPeerStats { return d.Network })
+	return comparison, nil
+}
+
+// averageValidatorPeerStats averages the stats of the days, nil is returned if none of the days has stats
+func averageValidatorPeerStats(days []*types.ValidatorPeerComparisonDay, get func(*types.ValidatorPeerComparisonDay) *types.ValidatorPeerStats) *types.ValidatorPeerStats {
+	var apr, effectiveness, inclusionDistance float64
+	var count, effectivenessCount, inclusionDistanceCount int
+	for _, d := range days {
+		s := get(d)
+		if s == nil {
+			continue
+		}
+		count++
+		apr += s.APR
+		if s.Effectiveness != nil {
+			effectiveness += *s.Effectiveness
+			effectivenessCount++
+		}
+		if s.InclusionDistance != nil {
+			inclusionDistance += *s.InclusionDistance
+			inclusionDistanceCount++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	avg := &types.ValidatorPeerStats{APR: apr / float64(count)}
+	if effectivenessCount > 0 {
+		e := effectiveness / float64(effectivenessCount)
+		avg.Effectiveness = &e
+	}
+	if inclusionDistanceCount > 0 {
+		i := inclusionDistance / float64(inclusionDistanceCount)
+		avg.InclusionDistance = &i
+	}
+	return avg
+}
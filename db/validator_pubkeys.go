@@ -0,0 +1,335 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/lib/pq"
+)
+
+// validatorPubkeysRedisBatchSize is the number of keys read or written per redis round trip
+const validatorPubkeysRedisBatchSize = 10000
+
+// validatorPubkeys caches the immutable mapping of validator indices to public keys in both directions, it is filled
+// by the indexer when it saves the validators and by the lookups of the other processes
+var validatorPubkeys = &validatorPubkeyCache{byPubkey: map[string]uint64{}}
+
+// validatorPubkeysRedis shares the mapping between the processes, lookups missing in memory are resolved from redis
+// before the database. It is nil if no redis endpoint is configured.
+var validatorPubkeysRedis *redis.Client
+
+type validatorPubkeyCache struct {
+	mu       sync.RWMutex
+	byIndex  [][]byte
+	byPubkey map[string]uint64
+}
+
+type validatorPubkeyRow struct {
+	Index  uint64 `db:"validatorindex"`
+	Pubkey []byte `db:"pubkey"`
+}
+
+// add caches the mapping and returns true if it was not cached before
+func (c *validatorPubkeyCache) add(index uint64, pubkey []byte) bool {
+	if len(pubkey) == 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for uint64(len(c.byIndex)) <= index {
+		c.byIndex = append(c.byIndex, nil)
+	}
+	if c.byIndex[index] != nil {
+		return false
+	}
+	c.byIndex[index] = pubkey
+	c.byPubkey[string(pubkey)] = index
+	return true
+}
+
+func (c *validatorPubkeyCache) pubkey(index uint64) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if index < uint64(len(c.byIndex)) {
+		return c.byIndex[index]
+	}
+	return nil
+}
+
+func (c *validatorPubkeyCache) index(pubkey []byte) (uint64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	index, exists := c.byPubkey[string(pubkey)]
+	return index, exists
+}
+
+// initValidatorPubkeysRedis connects the cache to the redis of the endpoint
+func initValidatorPubkeysRedis(endpoint string) error {
+	client := redis.NewClient(&redis.Options{
+		Addr:         endpoint,
+		ReadTimeout:  time.Second * 5,
+		WriteTimeout: time.Second * 5,
+	})
+	err := client.Ping().Err()
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("error connecting to redis of the validator pubkey cache at %v: %w", endpoint, err)
+	}
+	validatorPubkeysRedis = client
+	return nil
+}
+
+func validatorPubkeyRedisKey(index uint64) string {
+	return fmt.Sprintf("%v:validator:pubkey:%v", utils.GetNetwork(), index)
+}
+
+func validatorIndexRedisKey(pubkey []byte) string {
+	return fmt.Sprintf("%v:validator:index:%x", utils.GetNetwork(), pubkey)
+}
+
+// addValidatorPubkeys caches the mappings in memory and stores the ones that were not cached before in redis, redis
+// is only a cache so its errors are logged
+func addValidatorPubkeys(rows []validatorPubkeyRow) {
+	added := []validatorPubkeyRow{}
+	for _, row := range rows {
+		if validatorPubkeys.add(row.Index, row.Pubkey) {
+			added = append(added, row)
+		}
+	}
+	if validatorPubkeysRedis == nil {
+		return
+	}
+	for start := 0; start < len(added); start += validatorPubkeysRedisBatchSize {
+		end := start + validatorPubkeysRedisBatchSize
+		if end > len(added) {
+			end = len(added)
+		}
+		pairs := make([]interface{}, 0, (end-start)*4)
+		for _, row := range added[start:end] {
+			pairs = append(pairs, validatorPubkeyRedisKey(row.Index), row.Pubkey, validatorIndexRedisKey(row.Pubkey), row.Index)
+		}
+		err := validatorPubkeysRedis.MSet(pairs...).Err()
+		if err != nil {
+			logger.WithError(err).Warnf("error storing validator pubkeys in redis")
+			return
+		}
+	}
+}
+
+// getRedisValidatorPubkeys returns the public keys of the indices that are stored in redis and caches them in memory
+func getRedisValidatorPubkeys(indices []uint64) map[uint64][]byte {
+	pubkeys := map[uint64][]byte{}
+	if validatorPubkeysRedis == nil || len(indices) == 0 {
+		return pubkeys
+	}
+	keys := make([]string, len(indices))
+	for i, index := range indices {
+		keys[i] = validatorPubkeyRedisKey(index)
+	}
+	values, err := validatorPubkeysRedis.MGet(keys...).Result()
+	if err != nil {
+		logger.WithError(err).Warnf("error retrieving validator pubkeys from redis")
+		return pubkeys
+	}
+	for i, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			pubkeys[indices[i]] = []byte(s)
+			validatorPubkeys.add(indices[i], []byte(s))
+		}
+	}
+	return pubkeys
+}
+
+// getRedisValidatorIndices returns the indices of the public keys that are stored in redis and caches them in memory
+func getRedisValidatorIndices(pubkeys [][]byte) map[string]uint64 {
+	indices := map[string]uint64{}
+	if validatorPubkeysRedis == nil || len(pubkeys) == 0 {
+		return indices
+	}
+	keys := make([]string, len(pubkeys))
+	for i, pubkey := range pubkeys {
+		keys[i] = validatorIndexRedisKey(pubkey)
+	}
+	values, err := validatorPubkeysRedis.MGet(keys...).Result()
+	if err != nil {
+		logger.WithError(err).Warnf("error retrieving validator indices from redis")
+		return indices
+	}
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		index, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		indices[string(pubkeys[i])] = index
+		validatorPubkeys.add(index, pubkeys[i])
+	}
+	return indices
+}
+
+// GetValidatorPublicKey will return the public key for a specific validator, sql.ErrNoRows if it does not exist
+func GetValidatorPublicKey(ctx context.Context, index uint64) ([]byte, error) {
+	pubkeys, err := GetValidatorPublicKeys(ctx, []uint64{index})
+	if err != nil {
+		return nil, err
+	}
+	pubkey, exists := pubkeys[index]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return pubkey, nil
+}
+
+// GetValidatorIndex will return the validator-index for a public key, sql.ErrNoRows if it does not exist
+func GetValidatorIndex(ctx context.Context, publicKey []byte) (uint64, error) {
+	indices, err := GetValidatorIndicesOfPubkeys(ctx, pq.ByteaArray{publicKey})
+	if err != nil {
+		return 0, err
+	}
+	index, exists := indices[string(publicKey)]
+	if !exists {
+		return 0, sql.ErrNoRows
+	}
+	return index, nil
+}
+
+// GetValidatorPublicKeys returns the public keys of the validators by index, unknown indices are skipped
+func GetValidatorPublicKeys(ctx context.Context, indices []uint64) (map[uint64][]byte, error) {
+	pubkeys := make(map[uint64][]byte, len(indices))
+	missing := []uint64{}
+	for _, index := range indices {
+		if _, exists := pubkeys[index]; exists {
+			continue
+		}
+		if pubkey := validatorPubkeys.pubkey(index); pubkey != nil {
+			pubkeys[index] = pubkey
+		} else {
+			missing = append(missing, index)
+		}
+	}
+	if len(missing) == 0 {
+		return pubkeys, nil
+	}
+
+	cached := getRedisValidatorPubkeys(missing)
+	remaining := make([]uint64, 0, len(missing)-len(cached))
+	for _, index := range missing {
+		if pubkey, exists := cached[index]; exists {
+			pubkeys[index] = pubkey
+		} else {
+			remaining = append(remaining, index)
+		}
+	}
+	if len(remaining) == 0 {
+		return pubkeys, nil
+	}
+
+	rows := []validatorPubkeyRow{}
+	err := DB.SelectContext(ctx, &rows, "SELECT validatorindex, pubkey FROM validators WHERE validatorindex = ANY($1)", pq.Array(remaining))
+	if err != nil {
+		return nil, err
+	}
+	addValidatorPubkeys(rows)
+	for _, row := range rows {
+		pubkeys[row.Index] = row.Pubkey
+	}
+	return pubkeys, nil
+}
+
+// GetValidatorIndicesOfPubkeys returns the indices of the validators with the given public keys by public key (as
+// string), unknown public keys are skipped
+func GetValidatorIndicesOfPubkeys(ctx context.Context, pubkeys pq.ByteaArray) (map[string]uint64, error) {
+	indices := make(map[string]uint64, len(pubkeys))
+	missing := pq.ByteaArray{}
+	requested := make(map[string]bool, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		if requested[string(pubkey)] {
+			continue
+		}
+		requested[string(pubkey)] = true
+		if index, exists := validatorPubkeys.index(pubkey); exists {
+			indices[string(pubkey)] = index
+		} else {
+			missing = append(missing, pubkey)
+		}
+	}
+	if len(missing) == 0 {
+		return indices, nil
+	}
+
+	cached := getRedisValidatorIndices(missing)
+	remaining := make(pq.ByteaArray, 0, len(missing)-len(cached))
+	for _, pubkey := range missing {
+		if index, exists := cached[string(pubkey)]; exists {
+			indices[string(pubkey)] = index
+		} else {
+			remaining = append(remaining, pubkey)
+		}
+	}
+	if len(remaining) == 0 {
+		return indices, nil
+	}
+
+	rows := []validatorPubkeyRow{}
+	err := DB.SelectContext(ctx, &rows, "SELECT validatorindex, pubkey FROM validators WHERE pubkey = ANY($1)", remaining)
+	if err != nil {
+		return nil, err
+	}
+	addValidatorPubkeys(rows)
+	for _, row := range rows {
+		indices[string(row.Pubkey)] = row.Index
+	}
+	return indices, nil
+}
+
+// GetValidatorIndicesByPubkeys returns the indices of the validators with the given public keys in ascending order,
+// unknown and duplicate public keys are skipped
+func GetValidatorIndicesByPubkeys(ctx context.Context, pubkeys pq.ByteaArray) ([]uint64, error) {
+	byPubkey, err := GetValidatorIndicesOfPubkeys(ctx, pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]uint64, 0, len(byPubkey))
+	for _, index := range byPubkey {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return indices, nil
+}
+
+// GetValidatorIndicesByIndicesOrPubkeys returns the indices of the existing validators given by index or public key
+// in ascending order without duplicates
+func GetValidatorIndicesByIndicesOrPubkeys(ctx context.Context, indices []uint64, pubkeys pq.ByteaArray) ([]uint64, error) {
+	byIndex, err := GetValidatorPublicKeys(ctx, indices)
+	if err != nil {
+		return nil, err
+	}
+	byPubkey, err := GetValidatorIndicesOfPubkeys(ctx, pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[uint64]bool, len(byIndex)+len(byPubkey))
+	resolved := make([]uint64, 0, len(byIndex)+len(byPubkey))
+	for index := range byIndex {
+		seen[index] = true
+		resolved = append(resolved, index)
+	}
+	for _, index := range byPubkey {
+		if !seen[index] {
+			seen[index] = true
+			resolved = append(resolved, index)
+		}
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i] < resolved[j] })
+	return resolved, nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+
+	"github.com/lib/pq"
+)
+
+// GetValidatorSetComparison aggregates the validator_stats and validator_effectiveness of the days startDay to endDay
+// (inclusive) of the validators with one of the indices or pubkeys or one of the (lower case) tags
+func GetValidatorSetComparison(ctx context.Context, indices []uint64, pubkeys pq.ByteaArray, tags []string, startDay, endDay uint64) (*types.ValidatorSetComparison, error) {
+	comparison := &types.ValidatorSetComparison{StartDay: startDay, EndDay: endDay}
+	err := DB.GetContext(ctx, comparison, `
+		WITH set_validators AS (
+			SELECT validatorindex FROM validators WHERE validatorindex = ANY($1) OR pubkey = ANY($2)
+			UNION
+			SELECT v.validatorindex
+			FROM validator_tags t
+				INNER JOIN validators v ON v.pubkey = t.publickey
+			WHERE LOWER(t.tag) = ANY($3)
+		)
+		SELECT
+			COUNT(DISTINCT vs.validatorindex) AS validators,
+			COALESCE(SUM(vs.end_balance - vs.start_balance - COALESCE(vs.deposits_amount, 0)), 0) AS income,
+			COALESCE(
+				SUM(vs.end_balance - vs.start_balance - COALESCE(vs.deposits_amount, 0)) FILTER (WHERE vs.start_effective_balance > 0)::float /
+				NULLIF(SUM(vs.start_effective_balance), 0) * 365, 0) AS apr,
+			AVG(e.effectiveness) AS effectiveness,
+			COALESCE(SUM(vs.missed_attestations), 0) AS missed_attestations,
+			COALESCE(SUM(vs.orphaned_attestations), 0) AS orphaned_attestations,
+			COALESCE(SUM(vs.proposed_blocks), 0) AS proposed_blocks,
+			COALESCE(SUM(vs.missed_blocks), 0) AS missed_blocks,
+			COALESCE(SUM(vs.orphaned_blocks), 0) AS orphaned_blocks,
+			COALESCE(SUM(vs.participated_sync), 0) AS participated_sync,
+			COALESCE(SUM(vs.missed_sync), 0) AS missed_sync,
+			COALESCE(SUM(vs.missed_blocks)::float / NULLIF(SUM(vs.proposed_blocks) + SUM(vs.missed_blocks), 0), 0) AS proposal_miss_rate,
+			COALESCE(SUM(vs.missed_sync)::float / NULLIF(SUM(vs.participated_sync) + SUM(vs.missed_sync), 0), 0) AS sync_miss_rate
+		FROM validator_stats vs
+			INNER JOIN set_validators s ON s.validatorindex = vs.validatorindex
+			LEFT JOIN validator_effectiveness e ON e.validatorindex = vs.validatorindex AND e.day = vs.day AND e.formula = $6
+		WHERE vs.day >= $4 AND vs.day <= $5`,
+		pq.Array(indices), pubkeys, pq.Array(tags), startDay, endDay, DefaultEffectivenessFormula)
+	if err != nil {
+		return nil, err
+	}
+	return comparison, nil
+}
@@ -0,0 +1,31 @@
+package db
+
+// ValidatorSetFlows are the size and the queues of the validator set at an epoch and the deposits and exits since the
+// start epoch of the window the forecast is fitted to, balances are in gwei
+type ValidatorSetFlows struct {
+	ActiveValidators   uint64  `db:"active_validators"`
+	TotalActiveBalance uint64  `db:"total_active_balance"`
+	EnteringQueue      uint64  `db:"entering_queue"`
+	ExitingQueue       uint64  `db:"exiting_queue"`
+	Deposits           uint64  `db:"deposits"`
+	Exits              uint64  `db:"exits"`
+	Participation      float64 `db:"participation"`
+}
+
+// GetValidatorSetFlows returns the validator set at the latest epoch, the validators that became eligible for activation
+// and those whose exit was scheduled after the start epoch. The exit epoch is assigned when an exit is initiated, exits
+// initiated shortly before the start epoch are therefore counted while the exit queue is long.
+func GetValidatorSetFlows(startEpoch, latestEpoch uint64) (*ValidatorSetFlows, error) {
+	flows := &ValidatorSetFlows{}
+	err := DB.Get(flows, `
+		SELECT
+			COUNT(*) FILTER (WHERE activationepoch <= $2 AND exitepoch > $2) AS active_validators,
+			COALESCE(SUM(effectivebalance) FILTER (WHERE activationepoch <= $2 AND exitepoch > $2), 0) AS total_active_balance,
+			COUNT(*) FILTER (WHERE activationeligibilityepoch <= $2 AND activationepoch > $2) AS entering_queue,
+			COUNT(*) FILTER (WHERE exitepoch > $2 AND exitepoch < $3) AS exiting_queue,
+			COUNT(*) FILTER (WHERE activationeligibilityepoch > $1 AND activationeligibilityepoch <= $2) AS deposits,
+			COUNT(*) FILTER (WHERE exitepoch > $1 AND exitepoch < $3) AS exits,
+			(SELECT COALESCE(AVG(globalparticipationrate), 0) FROM epochs WHERE epoch > $1 AND eligibleether > 0) AS participation
+		FROM validators`, startEpoch, latestEpoch, uint64(9223372036854775807))
+	return flows, err
+}
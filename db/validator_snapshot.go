@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+)
+
+// StreamValidatorSetSnapshot reconstructs the validator registry at the epoch from the stored balances, deposits, exits
+// and slashings and calls fn for every validator ordered by index. The snapshot starts after the validator afterIndex
+// if it is not nil and contains at most limit validators, a limit of 0 returns all validators.
+//
+// The validators table only holds the latest state, so the status is derived from the activation and exit epochs as
+// they were known at the epoch: an exit counts as initiated at the slot of the voluntary exit or the slashing that
+// caused it, other exits (e.g. ejections) are assumed to have been initiated at the earliest possible epoch before the
+// exit epoch. Online and offline states can not be reconstructed and are not part of the status.
+func StreamValidatorSetSnapshot(ctx context.Context, epoch uint64, afterIndex *uint64, limit uint64, fn func(*types.ApiValidatorSnapshot) error) error {
+	var limitArg interface{}
+	if limit > 0 {
+		limitArg = limit
+	}
+
+	rows, err := DB.QueryxContext(ctx, `
+		WITH snapshot AS (
+			SELECT
+				v.validatorindex,
+				v.pubkey,
+				v.activationeligibilityepoch,
+				v.activationepoch,
+				v.exitepoch,
+				b.balance,
+				b.effectivebalance,
+				CASE WHEN v.slashed THEN (
+					SELECT MIN(s.block_slot) / $2
+					FROM (
+						SELECT block_slot FROM blocks_proposerslashings WHERE proposerindex = v.validatorindex
+						UNION ALL
+						SELECT block_slot FROM blocks_attesterslashings WHERE v.validatorindex = ANY(attestation1_indices) AND v.validatorindex = ANY(attestation2_indices)
+					) s
+					INNER JOIN blocks ON blocks.slot = s.block_slot AND blocks.status = '1'
+				) END AS slashing_epoch,
+				CASE WHEN v.exitepoch < $3 THEN COALESCE(
+					(
+						SELECT MIN(e.block_slot) / $2
+						FROM blocks_voluntaryexits e
+						INNER JOIN blocks ON blocks.slot = e.block_slot AND blocks.status = '1'
+						WHERE e.validatorindex = v.validatorindex
+					),
+					v.exitepoch - $4
+				) END AS exit_initiated_epoch
+			FROM validator_balances_p b
+			INNER JOIN validators v ON v.validatorindex = b.validatorindex
+			WHERE b.week = $1 / 1575 AND b.epoch = $1 AND ($5::int IS NULL OR b.validatorindex > $5)
+			ORDER BY b.validatorindex
+			LIMIT $6
+		)
+		SELECT
+			$1 AS epoch,
+			validatorindex,
+			'0x' || ENCODE(pubkey, 'hex') AS pubkey,
+			balance,
+			effectivebalance,
+			COALESCE(slashing_epoch <= $1, false) AS slashed,
+			CASE WHEN activationeligibilityepoch <= $1 THEN activationeligibilityepoch END AS activationeligibilityepoch,
+			CASE WHEN activationepoch < $3 AND activationeligibilityepoch <= $1 THEN activationepoch END AS activationepoch,
+			CASE WHEN exit_initiated_epoch <= $1 THEN exitepoch END AS exitepoch,
+			CASE
+				WHEN exitepoch <= $1 AND slashing_epoch <= $1 THEN 'slashed'
+				WHEN exitepoch <= $1 THEN 'exited'
+				WHEN activationeligibilityepoch > $1 THEN 'deposited'
+				WHEN activationepoch > $1 THEN 'pending'
+				WHEN slashing_epoch <= $1 THEN 'slashing'
+				WHEN exit_initiated_epoch <= $1 THEN 'exiting'
+				ELSE 'active'
+			END AS status
+		FROM snapshot
+		ORDER BY validatorindex`,
+		epoch, utils.Config.Chain.Phase0.SlotsPerEpoch, validatorLifecycleFarFutureEpoch, utils.Config.Chain.Phase0.MaxSeedLookahead+1, afterIndex, limitArg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v := &types.ApiValidatorSnapshot{}
+		err = rows.StructScan(v)
+		if err != nil {
+			return err
+		}
+		err = fn(v)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
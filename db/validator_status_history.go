@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+
+	"github.com/lib/pq"
+)
+
+// ValidatorStatuses are the statuses of validator_status_history in the order a validator reaches them
+var ValidatorStatuses = []string{"deposited", "pending", "active", "exiting", "slashing", "exited", "slashed"}
+
+// UpdateValidatorStatusHistory stores the status transitions of all validators that happened between startEpoch and
+// endEpoch (inclusive). The transitions are derived like the statuses of StreamValidatorSetSnapshot: a validator is
+// deposited at its first beacon chain deposit, pending at its activation eligibility, exiting at the slot of its
+// voluntary exit (or the earliest possible epoch before its exit epoch), slashing at the slot of the slashing and
+// exited or slashed at its exit epoch. Transitions that are already stored are updated in case a reorg moved them.
+func UpdateValidatorStatusHistory(startEpoch, endEpoch uint64) (int64, error) {
+	res, err := DB.Exec(`
+		WITH lifecycle AS (
+			SELECT
+				v.validatorindex,
+				v.activationeligibilityepoch,
+				v.activationepoch,
+				v.exitepoch,
+				CASE WHEN v.activationeligibilityepoch >= $1 THEN COALESCE(
+					(
+						SELECT MIN(d.block_slot) / $3
+						FROM blocks_deposits d
+						INNER JOIN blocks ON blocks.slot = d.block_slot AND blocks.status = '1'
+						WHERE d.publickey = v.pubkey
+					),
+					LEAST(v.activationeligibilityepoch, $2)
+				) END AS deposited_epoch,
+				CASE WHEN v.slashed AND v.withdrawableepoch >= $1 THEN (
+					SELECT MIN(s.block_slot) / $3
+					FROM (
+						SELECT block_slot FROM blocks_proposerslashings WHERE proposerindex = v.validatorindex
+						UNION ALL
+						SELECT block_slot FROM blocks_attesterslashings WHERE v.validatorindex = ANY(attestation1_indices) AND v.validatorindex = ANY(attestation2_indices)
+					) s
+					INNER JOIN blocks ON blocks.slot = s.block_slot AND blocks.status = '1'
+				) END AS slashing_epoch,
+				CASE WHEN v.exitepoch < $4 AND v.exitepoch >= $1 THEN COALESCE(
+					(
+						SELECT MIN(e.block_slot) / $3
+						FROM blocks_voluntaryexits e
+						INNER JOIN blocks ON blocks.slot = e.block_slot AND blocks.status = '1'
+						WHERE e.validatorindex = v.validatorindex
+					),
+					v.exitepoch - $5
+				) END AS exit_initiated_epoch
+			FROM validators v
+		)
+		INSERT INTO validator_status_history (validatorindex, status, epoch)
+		SELECT l.validatorindex, t.status, t.epoch
+		FROM lifecycle l
+		CROSS JOIN LATERAL (VALUES
+			('deposited', l.deposited_epoch),
+			('pending', l.activationeligibilityepoch),
+			('active', l.activationepoch),
+			('exiting', CASE WHEN l.exit_initiated_epoch < COALESCE(l.slashing_epoch, $4) THEN l.exit_initiated_epoch END),
+			('slashing', CASE WHEN l.slashing_epoch < l.exitepoch THEN l.slashing_epoch END),
+			('exited', CASE WHEN l.exitepoch < $4 AND COALESCE(l.slashing_epoch, $4) > l.exitepoch THEN l.exitepoch END),
+			('slashed', CASE WHEN l.exitepoch < $4 AND l.slashing_epoch IS NOT NULL THEN GREATEST(l.exitepoch, l.slashing_epoch) END)
+		) AS t(status, epoch)
+		WHERE t.epoch BETWEEN $1 AND $2
+		ON CONFLICT (validatorindex, status) DO UPDATE SET epoch = EXCLUDED.epoch
+		WHERE validator_status_history.epoch <> EXCLUDED.epoch`,
+		startEpoch, endEpoch, utils.Config.Chain.Phase0.SlotsPerEpoch, validatorLifecycleFarFutureEpoch, utils.Config.Chain.Phase0.MaxSeedLookahead+1)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// HasValidatorStatusHistory returns false if no status transitions have been stored yet
+func HasValidatorStatusHistory() (bool, error) {
+	var exists bool
+	err := DB.Get(&exists, `SELECT EXISTS (SELECT 1 FROM validator_status_history)`)
+	return exists, err
+}
+
+// GetValidatorStatusHistory returns the stored status transitions of the validator in the order they happened
+func GetValidatorStatusHistory(ctx context.Context, index uint64) ([]*types.ValidatorStatusTransition, error) {
+	transitions := []*types.ValidatorStatusTransition{}
+	err := DB.SelectContext(ctx, &transitions, `
+		SELECT status, epoch
+		FROM validator_status_history
+		WHERE validatorindex = $1
+		ORDER BY epoch, ARRAY_POSITION($2::text[], status::text)`, index, pq.Array(ValidatorStatuses))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transitions {
+		t.Ts = utils.EpochToTime(t.Epoch)
+	}
+	return transitions, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/types"
+)
+
+// WithdrawalSweepSample is the last withdrawal of a block and the validator the withdrawal sweep withdrew it from
+type WithdrawalSweepSample struct {
+	Slot            uint64 `db:"block_slot"`
+	WithdrawalIndex uint64 `db:"withdrawalindex"`
+	ValidatorIndex  uint64 `db:"validatorindex"`
+}
+
+// GetWithdrawalSweepSamples returns the last withdrawn validator of every canonical block with withdrawals since
+// startSlot ordered by slot, together with the size of the validator registry the sweep wraps around at
+func GetWithdrawalSweepSamples(startSlot uint64) ([]*WithdrawalSweepSample, uint64, error) {
+	samples := []*WithdrawalSweepSample{}
+	err := DB.Select(&samples, `
+		SELECT DISTINCT ON (w.block_slot) w.block_slot, w.withdrawalindex, w.validatorindex
+		FROM blocks_withdrawals w
+		INNER JOIN blocks b ON b.slot = w.block_slot AND b.blockroot = w.block_root AND b.status = '1'
+		WHERE w.block_slot >= $1
+		ORDER BY w.block_slot, w.block_index DESC`, startSlot)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var validatorCount uint64
+	err = DB.Get(&validatorCount, `SELECT COALESCE(MAX(validatorindex) + 1, 0) FROM validators`)
+	if err != nil {
+		return nil, 0, err
+	}
+	return samples, validatorCount, nil
+}
+
+// CountSweptValidators returns the number of validators the withdrawal sweep withdraws from between the validator
+// indices start (inclusive) and end (exclusive), wrapping around the registry if end is before start. Every validator
+// with execution withdrawal credentials and a balance is counted, validators without an excess balance that are skipped
+// by the sweep are not known in advance.
+func CountSweptValidators(ctx context.Context, start, end uint64) (uint64, error) {
+	var count uint64
+	err := DB.GetContext(ctx, &count, `
+		SELECT COUNT(*)
+		FROM validators
+		WHERE balance > 0 AND get_byte(withdrawalcredentials, 0) <> 0
+			AND CASE WHEN $1 <= $2 THEN validatorindex >= $1 AND validatorindex < $2
+				ELSE validatorindex >= $1 OR validatorindex < $2 END`, start, end)
+	return count, err
+}
+
+// GetValidatorFullWithdrawal returns the first withdrawal of the validator that was included once the validator became
+// withdrawable or nil if the sweep has not withdrawn the balance yet
+func GetValidatorFullWithdrawal(ctx context.Context, validatorIndex, withdrawableSlot uint64) (*types.ValidatorFullWithdrawal, error) {
+	withdrawal := &types.ValidatorFullWithdrawal{}
+	err := DB.GetContext(ctx, withdrawal, `
+		SELECT w.block_slot, w.amount
+		FROM blocks_withdrawals w
+		INNER JOIN blocks b ON b.slot = w.block_slot AND b.blockroot = w.block_root AND b.status = '1'
+		WHERE w.validatorindex = $1 AND w.block_slot >= $2
+		ORDER BY w.block_slot
+		LIMIT 1`, validatorIndex, withdrawableSlot)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
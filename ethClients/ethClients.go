@@ -2,6 +2,7 @@ package ethclients
 
 import (
 	"encoding/json"
+	"eth2-exporter/logging"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -9,11 +10,9 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
-var logger = logrus.New().WithField("module", "ethClients")
+var logger = logging.NewLogger("ethClients")
 
 type ethernodesAPIStruct struct {
 	Client string `json:"client"`
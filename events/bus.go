@@ -0,0 +1,73 @@
+package events
+
+import (
+	"eth2-exporter/logging"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sync"
+)
+
+var logger = logging.NewLogger("events")
+
+// Bus transports chain events from the indexer to its consumers, Subscribe returns a channel that receives
+// all events published after the subscription
+type Bus interface {
+	Publish(event *types.ChainEvent) error
+	Subscribe() (<-chan *types.ChainEvent, error)
+	Close() error
+}
+
+var bus Bus
+var busMux = &sync.Mutex{}
+
+// Init creates the event bus configured in utils.Config.EventBus, it defaults to a bus that only delivers events within the process
+func Init() error {
+	busMux.Lock()
+	defer busMux.Unlock()
+
+	if bus != nil {
+		return nil
+	}
+
+	switch utils.Config.EventBus.Type {
+	case "", "local":
+		bus = NewLocalBus()
+	case "postgres":
+		cfg := utils.Config.Database
+		b, err := NewPostgresBus(fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Name))
+		if err != nil {
+			return err
+		}
+		bus = b
+	default:
+		return fmt.Errorf("unknown event bus type %v", utils.Config.EventBus.Type)
+	}
+	logger.Infof("initialized %v event bus", utils.Config.EventBus.Type)
+	return nil
+}
+
+func getBus() Bus {
+	busMux.Lock()
+	defer busMux.Unlock()
+	if bus == nil {
+		bus = NewLocalBus()
+	}
+	return bus
+}
+
+// Publish publishes the events on the event bus, errors are only logged since consumers fall back to their periodic scans
+func Publish(events ...*types.ChainEvent) {
+	b := getBus()
+	for _, e := range events {
+		err := b.Publish(e)
+		if err != nil {
+			logger.WithError(err).Errorf("error publishing %v event", e.Name)
+		}
+	}
+}
+
+// Subscribe subscribes to all events published on the event bus
+func Subscribe() (<-chan *types.ChainEvent, error) {
+	return getBus().Subscribe()
+}
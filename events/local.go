@@ -0,0 +1,50 @@
+package events
+
+import (
+	"eth2-exporter/types"
+	"sync"
+)
+
+// localBusBufferSize is the number of events a slow subscriber can lag behind before events are dropped for it
+const localBusBufferSize = 1000
+
+// LocalBus delivers events to the subscribers of the same process
+type LocalBus struct {
+	subscribers []chan *types.ChainEvent
+	mux         sync.RWMutex
+}
+
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(event *types.ChainEvent) error {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	for _, s := range b.subscribers {
+		select {
+		case s <- event:
+		default:
+			logger.Warnf("dropping %v event for slow subscriber", event.Name)
+		}
+	}
+	return nil
+}
+
+func (b *LocalBus) Subscribe() (<-chan *types.ChainEvent, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	s := make(chan *types.ChainEvent, localBusBufferSize)
+	b.subscribers = append(b.subscribers, s)
+	return s, nil
+}
+
+func (b *LocalBus) Close() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, s := range b.subscribers {
+		close(s)
+	}
+	b.subscribers = nil
+	return nil
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"eth2-exporter/types"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// postgresChannel is the channel of the events, postgres limits the payload of a notification to 8000 bytes
+// which is plenty for a single event
+const postgresChannel = "explorer_chain_events"
+
+// PostgresBus exchanges events between processes via LISTEN/NOTIFY of the explorer database
+type PostgresBus struct {
+	db       *sql.DB
+	listener *pq.Listener
+	local    *LocalBus
+}
+
+func NewPostgresBus(connStr string) (*PostgresBus, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := pq.NewListener(connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.WithError(err).Errorf("error in event bus listener")
+		}
+	})
+	err = listener.Listen(postgresChannel)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	b := &PostgresBus{db: db, listener: listener, local: NewLocalBus()}
+	go b.receive()
+	return b, nil
+}
+
+// receive forwards the notifications of the listener to the local subscribers
+func (b *PostgresBus) receive() {
+	for n := range b.listener.Notify {
+		if n == nil {
+			// the connection has been re-established, events sent in the meantime are lost
+			continue
+		}
+		event := &types.ChainEvent{}
+		err := json.Unmarshal([]byte(n.Extra), event)
+		if err != nil {
+			logger.WithError(err).Errorf("error decoding event")
+			continue
+		}
+		b.local.Publish(event)
+	}
+}
+
+func (b *PostgresBus) Publish(event *types.ChainEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = b.db.Exec("SELECT pg_notify($1, $2)", postgresChannel, string(payload))
+	return err
+}
+
+func (b *PostgresBus) Subscribe() (<-chan *types.ChainEvent, error) {
+	return b.local.Subscribe()
+}
+
+func (b *PostgresBus) Close() error {
+	err := b.listener.Close()
+	b.local.Close()
+	b.db.Close()
+	return err
+}
@@ -0,0 +1,21 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"time"
+)
+
+// attestationAssignmentsPruner drops the weeks of attestation_assignments_p that are older than the configured
+// retention once a day, the attestation history of the dropped weeks stays available in attestation_bitfields
+func attestationAssignmentsPruner() {
+	for {
+		start := time.Now()
+		err := db.PruneAttestationAssignments()
+		if err != nil {
+			logger.Errorf("error pruning attestation assignments: %v", err)
+		} else {
+			logger.WithField("duration", time.Since(start)).Info("attestation assignments pruning completed")
+		}
+		time.Sleep(time.Hour * 24)
+	}
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/rpc"
+	"eth2-exporter/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// attestationCorrectnessUpdater evaluates the attestation votes of every finalized epoch, the canonical chain of an
+// epoch does not change anymore once it is finalized
+func attestationCorrectnessUpdater(client rpc.Client) {
+	slotDuration := time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot)
+
+	for {
+		head, err := client.GetChainHead()
+		if err != nil {
+			logger.Errorf("error getting chainhead when evaluating attestation correctness: %v", err)
+			time.Sleep(slotDuration)
+			continue
+		}
+
+		last, ok, err := db.GetLastAttestationCorrectnessEpoch()
+		if err != nil {
+			logger.Errorf("error retrieving last evaluated attestation correctness epoch: %v", err)
+			time.Sleep(slotDuration)
+			continue
+		}
+		epoch := last + 1
+		if !ok {
+			epoch = utils.Config.Indexer.AttestationCorrectness.StartEpoch
+			if epoch == 0 {
+				epoch = head.FinalizedEpoch
+			}
+		}
+
+		for ; epoch <= head.FinalizedEpoch; epoch++ {
+			start := time.Now()
+			err = db.SaveAttestationCorrectness(epoch)
+			if err != nil {
+				logger.WithFields(logrus.Fields{"error": err, "epoch": epoch}).Errorf("error evaluating attestation correctness")
+				break
+			}
+			metrics.TaskDuration.WithLabelValues("save_attestation_correctness").Observe(time.Since(start).Seconds())
+			logger.WithFields(logrus.Fields{"epoch": epoch, "duration": time.Since(start)}).Debugf("evaluated attestation correctness")
+		}
+
+		time.Sleep(slotDuration)
+	}
+}
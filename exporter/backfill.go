@@ -0,0 +1,165 @@
+package exporter
+
+import (
+	"bytes"
+	"eth2-exporter/db"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BackfillOptions holds the parameters of a backfill run
+type BackfillOptions struct {
+	StartSlot   uint64
+	EndSlot     uint64
+	Concurrency int
+	// Force re-exports epochs even if they have already been backfilled or their blocks match the node
+	Force bool
+}
+
+// Backfill re-indexes all epochs of the given slot range from the beacon-node into the database.
+// Progress is checkpointed per epoch in the backfill_status table so an interrupted run can be resumed,
+// epochs whose blocks in the db already match the node are skipped unless opts.Force is set.
+func Backfill(client rpc.Client, opts BackfillOptions) error {
+	if opts.EndSlot < opts.StartSlot {
+		return fmt.Errorf("invalid slot range %v-%v", opts.StartSlot, opts.EndSlot)
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	startEpoch := utils.EpochOfSlot(opts.StartSlot)
+	endEpoch := utils.EpochOfSlot(opts.EndSlot)
+
+	done, err := db.GetBackfilledEpochs(startEpoch, endEpoch)
+	if err != nil {
+		return fmt.Errorf("error retrieving backfill progress: %w", err)
+	}
+
+	epochs := make([]uint64, 0, endEpoch-startEpoch+1)
+	for epoch := startEpoch; epoch <= endEpoch; epoch++ {
+		if done[epoch] && !opts.Force {
+			continue
+		}
+		epochs = append(epochs, epoch)
+	}
+	logger.Infof("backfilling epochs %v-%v, %v epochs remaining (%v already backfilled)", startEpoch, endEpoch, len(epochs), int(endEpoch-startEpoch+1)-len(epochs))
+
+	// partitions are created upfront as concurrent exports of the same week would race to create them
+	for week := startEpoch / 1575; week <= endEpoch/1575; week++ {
+		ensureEpochPartitions(week * 1575)
+	}
+
+	start := time.Now()
+	sem := make(chan struct{}, opts.Concurrency)
+	wg := &sync.WaitGroup{}
+	mux := &sync.Mutex{}
+	failed := []uint64{}
+
+	for i, epoch := range epochs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, epoch uint64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := backfillEpoch(client, epoch, opts.Force)
+			if err != nil {
+				logger.WithFields(logrus.Fields{"error": err, "epoch": epoch}).Errorf("error backfilling epoch")
+				mux.Lock()
+				failed = append(failed, epoch)
+				mux.Unlock()
+				return
+			}
+			if i%100 == 0 {
+				logger.WithFields(logrus.Fields{"epoch": epoch, "duration": time.Since(start)}).Infof("backfilled %v of %v epochs", i+1, len(epochs))
+			}
+		}(i, epoch)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("backfilling failed for %v epochs: %v", len(failed), failed)
+	}
+	logger.WithFields(logrus.Fields{"duration": time.Since(start)}).Infof("backfilled epochs %v-%v", startEpoch, endEpoch)
+
+	if utils.Config.Timescale.Enabled && len(epochs) > 0 {
+		// the backfilled epochs are mirrored into the hypertables, their days are older than the aggregate policies refresh
+		err = db.RefreshTimescaleAggregates(startEpoch, endEpoch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func backfillEpoch(client rpc.Client, epoch uint64, force bool) error {
+	if !force {
+		matches, err := verifyEpochBlocks(client, epoch)
+		if err != nil {
+			return err
+		}
+		if matches {
+			logger.Infof("skipping export of epoch %v as the blocks in the db match the node", epoch)
+			return db.SetEpochBackfilled(epoch, true)
+		}
+	}
+
+	err := ExportEpoch(epoch, client)
+	if err != nil {
+		return err
+	}
+
+	matches, err := verifyEpochBlocks(client, epoch)
+	if err != nil {
+		return err
+	}
+	if !matches {
+		return fmt.Errorf("blocks of epoch %v in the db do not match the node after export", epoch)
+	}
+	return db.SetEpochBackfilled(epoch, true)
+}
+
+// verifyEpochBlocks returns true if the epoch is present in the db and all of its blocks match the blocks on the node
+func verifyEpochBlocks(client rpc.Client, epoch uint64) (bool, error) {
+	var exported bool
+	err := db.DB.Get(&exported, "SELECT EXISTS(SELECT 1 FROM epochs WHERE epoch = $1)", epoch)
+	if err != nil {
+		return false, fmt.Errorf("error checking if epoch %v has been exported: %w", epoch, err)
+	}
+	if !exported {
+		return false, nil
+	}
+
+	dbBlocks, err := db.GetLastPendingAndProposedBlocks(epoch, epoch)
+	if err != nil {
+		return false, err
+	}
+	nodeBlocks, err := GetLastBlocks(epoch, epoch, client)
+	if err != nil {
+		return false, err
+	}
+
+	blocksBySlot := make(map[uint64]*types.MinimalBlock, len(dbBlocks))
+	for _, block := range dbBlocks {
+		blocksBySlot[block.Slot] = block
+	}
+	for _, block := range nodeBlocks {
+		if !block.Canonical {
+			continue
+		}
+		dbBlock, exists := blocksBySlot[block.Slot]
+		if !exists || !bytes.Equal(dbBlock.BlockRoot, block.BlockRoot) {
+			logger.Infof("block %v of epoch %v in the db does not match the node", block.Slot, epoch)
+			return false, nil
+		}
+	}
+	return true, nil
+}
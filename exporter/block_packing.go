@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/rpc"
+	"eth2-exporter/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// blockPackingUpdater evaluates the packing efficiency of the blocks of every epoch once the next epoch is finalized,
+// the attestations available to the blocks of an epoch can be included until the end of the next epoch
+func blockPackingUpdater(client rpc.Client) {
+	slotDuration := time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot)
+
+	for {
+		head, err := client.GetChainHead()
+		if err != nil {
+			logger.Errorf("error getting chainhead when evaluating block packing: %v", err)
+			time.Sleep(slotDuration)
+			continue
+		}
+
+		last, ok, err := db.GetLastBlockPackingEpoch()
+		if err != nil {
+			logger.Errorf("error retrieving last evaluated block packing epoch: %v", err)
+			time.Sleep(slotDuration)
+			continue
+		}
+		epoch := last + 1
+		if !ok {
+			epoch = utils.Config.Indexer.BlockPacking.StartEpoch
+			if epoch == 0 && head.FinalizedEpoch > 0 {
+				epoch = head.FinalizedEpoch - 1
+			}
+		}
+
+		for ; epoch+1 <= head.FinalizedEpoch; epoch++ {
+			start := time.Now()
+			err = db.SaveBlockPacking(epoch)
+			if err != nil {
+				logger.WithFields(logrus.Fields{"error": err, "epoch": epoch}).Errorf("error evaluating block packing")
+				break
+			}
+			metrics.TaskDuration.WithLabelValues("save_block_packing").Observe(time.Since(start).Seconds())
+			logger.WithFields(logrus.Fields{"epoch": epoch, "duration": time.Since(start)}).Debugf("evaluated block packing")
+		}
+
+		time.Sleep(slotDuration)
+	}
+}
@@ -0,0 +1,79 @@
+package exporter
+
+import (
+	"encoding/hex"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/rpc"
+	"eth2-exporter/utils"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// blockPropagationExporter records when each of the configured beacon nodes first sees a block, the nodes are
+// subscribed independently and resubscribed if their event stream fails
+func blockPropagationExporter() {
+	endpoints := utils.Config.Indexer.BlockPropagation.Endpoints
+	if len(endpoints) == 0 {
+		logger.Warnf("block propagation exporter is enabled but no endpoints are configured")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	for _, endpoint := range endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			subscribeBlockPropagation(endpoint)
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// subscribeBlockPropagation saves the block events of the endpoint, it runs forever
+func subscribeBlockPropagation(endpoint string) {
+	node := blockPropagationNodeName(endpoint)
+	for {
+		logger.Infof("subscribing to block events of node %v", node)
+		err := rpc.SubscribeBlockEvents(endpoint, func(event *rpc.BlockEvent) {
+			saveBlockPropagation(node, event)
+		})
+		logger.WithFields(logrus.Fields{"error": err, "node": node}).Errorf("error receiving block events, resubscribing")
+		time.Sleep(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
+	}
+}
+
+func saveBlockPropagation(node string, event *rpc.BlockEvent) {
+	slot := uint64(event.Slot)
+	// clock drift can place the event slightly before the start of the slot
+	delay := event.SeenAt.Sub(utils.SlotToTime(slot))
+	if delay < 0 {
+		delay = 0
+	}
+
+	blockRoot, err := hex.DecodeString(strings.TrimPrefix(event.Block, "0x"))
+	if err != nil {
+		logger.WithFields(logrus.Fields{"error": err, "node": node, "slot": slot}).Errorf("error decoding block root of block event")
+		return
+	}
+
+	err = db.SaveBlockPropagation(blockRoot, slot, node, event.SeenAt, delay)
+	if err != nil {
+		logger.WithFields(logrus.Fields{"error": err, "node": node, "slot": slot}).Errorf("error saving block propagation")
+		return
+	}
+	metrics.BlockPropagationDelay.WithLabelValues(node).Observe(delay.Seconds())
+}
+
+// blockPropagationNodeName returns the host of the endpoint, the credentials and path of the url are not stored
+func blockPropagationNodeName(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return endpoint
+	}
+	return u.Host
+}
@@ -0,0 +1,30 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultBlockPropagationRetention is the time the observed blocks are kept if no retention is configured
+const defaultBlockPropagationRetention = time.Hour * 24 * 90
+
+// blockPropagationPruner deletes the observed blocks that are older than the configured retention once a day
+func blockPropagationPruner() {
+	for {
+		retention := defaultBlockPropagationRetention
+		if utils.Config.Indexer.BlockPropagation.RetentionDays > 0 {
+			retention = time.Hour * 24 * time.Duration(utils.Config.Indexer.BlockPropagation.RetentionDays)
+		}
+		start := time.Now()
+		deleted, err := db.PruneBlockPropagation(utils.TimeToSlot(uint64(start.Add(-retention).Unix())))
+		if err != nil {
+			logger.Errorf("error pruning block propagation: %v", err)
+		} else {
+			logger.WithFields(logrus.Fields{"deleted": deleted, "duration": time.Since(start)}).Info("block propagation pruning completed")
+		}
+		time.Sleep(time.Hour * 24)
+	}
+}
@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	"bytes"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dataIntegrityVerifier periodically compares a random finalized epoch in the db against the beacon-node
+// and records all mismatches so that silent indexing bugs are noticed
+func dataIntegrityVerifier(client rpc.Client) {
+	if utils.Config.Indexer.DataIntegrity.IntervalSeconds == 0 {
+		utils.Config.Indexer.DataIntegrity.IntervalSeconds = 600
+	}
+	if utils.Config.Indexer.DataIntegrity.BalanceSampleSize == 0 {
+		utils.Config.Indexer.DataIntegrity.BalanceSampleSize = 100
+	}
+
+	interval := time.Second * time.Duration(utils.Config.Indexer.DataIntegrity.IntervalSeconds)
+	for {
+		epoch, ok, err := db.GetRandomFinalizedEpoch()
+		if err != nil {
+			logger.Errorf("error retrieving epoch for data integrity verification: %v", err)
+			time.Sleep(interval)
+			continue
+		}
+		if !ok {
+			time.Sleep(interval)
+			continue
+		}
+
+		err = verifyDataIntegrity(client, epoch)
+		if err != nil {
+			logger.WithFields(logrus.Fields{"error": err, "epoch": epoch}).Errorf("error verifying data integrity")
+		}
+		time.Sleep(interval)
+	}
+}
+
+func verifyDataIntegrity(client rpc.Client, epoch uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("verify_data_integrity").Observe(time.Since(start).Seconds())
+	}()
+
+	data, err := client.GetEpochData(epoch)
+	if err != nil {
+		return fmt.Errorf("error retrieving epoch data from node: %w", err)
+	}
+
+	issues, err := verifyBlockIntegrity(epoch, data)
+	if err != nil {
+		return err
+	}
+	balanceIssues, err := verifyBalanceIntegrity(epoch, data)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, balanceIssues...)
+
+	for _, issue := range issues {
+		metrics.DataIntegrityIssues.WithLabelValues(issue.CheckType).Inc()
+		logger.WithFields(logrus.Fields{"check": issue.CheckType, "epoch": issue.Epoch, "db": issue.DBValue, "node": issue.NodeValue}).Warnf("data integrity issue found")
+	}
+	err = db.SaveDataIntegrityIssues(issues)
+	if err != nil {
+		return fmt.Errorf("error saving data integrity issues: %w", err)
+	}
+
+	logger.Infof("verified data integrity of epoch %v, found %v issues, took %v", epoch, len(issues), time.Since(start))
+	return nil
+}
+
+// verifyBlockIntegrity compares the proposer and attestation count of all canonical blocks of the epoch
+func verifyBlockIntegrity(epoch uint64, data *types.EpochData) ([]*types.DataIntegrityIssue, error) {
+	dbBlocks, err := db.GetDataIntegrityBlocks(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving blocks from db: %w", err)
+	}
+	dbBlocksBySlot := make(map[uint64]*types.DataIntegrityBlock, len(dbBlocks))
+	for _, block := range dbBlocks {
+		dbBlocksBySlot[block.Slot] = block
+	}
+
+	issues := []*types.DataIntegrityIssue{}
+	for slot, blocks := range data.Blocks {
+		for _, block := range blocks {
+			// only proposed blocks carry a proposer and attestations, missed slots are stored with a zero root
+			if !block.Canonical || block.Status != 1 {
+				continue
+			}
+			slot := slot
+
+			metrics.DataIntegrityChecks.WithLabelValues(db.DataIntegrityCheckMissingBlock).Inc()
+			dbBlock, exists := dbBlocksBySlot[slot]
+			if !exists || !bytes.Equal(dbBlock.BlockRoot, block.BlockRoot) {
+				dbValue := ""
+				if exists {
+					dbValue = fmt.Sprintf("%#x", dbBlock.BlockRoot)
+				}
+				issues = append(issues, &types.DataIntegrityIssue{CheckType: db.DataIntegrityCheckMissingBlock, Epoch: epoch, Slot: &slot, DBValue: dbValue, NodeValue: fmt.Sprintf("%#x", block.BlockRoot)})
+				continue
+			}
+
+			metrics.DataIntegrityChecks.WithLabelValues(db.DataIntegrityCheckProposer).Inc()
+			if dbBlock.Proposer != block.Proposer {
+				issues = append(issues, &types.DataIntegrityIssue{CheckType: db.DataIntegrityCheckProposer, Epoch: epoch, Slot: &slot, DBValue: fmt.Sprintf("%v", dbBlock.Proposer), NodeValue: fmt.Sprintf("%v", block.Proposer)})
+			}
+
+			metrics.DataIntegrityChecks.WithLabelValues(db.DataIntegrityCheckAttestationsCount).Inc()
+			if dbBlock.AttestationsCount != uint64(len(block.Attestations)) {
+				issues = append(issues, &types.DataIntegrityIssue{CheckType: db.DataIntegrityCheckAttestationsCount, Epoch: epoch, Slot: &slot, DBValue: fmt.Sprintf("%v", dbBlock.AttestationsCount), NodeValue: fmt.Sprintf("%v", len(block.Attestations))})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// verifyBalanceIntegrity compares the balances of a random sample of validators at the epoch
+func verifyBalanceIntegrity(epoch uint64, data *types.EpochData) ([]*types.DataIntegrityIssue, error) {
+	sampleSize := int(utils.Config.Indexer.DataIntegrity.BalanceSampleSize)
+	if sampleSize > len(data.Validators) {
+		sampleSize = len(data.Validators)
+	}
+
+	sample := make([]*types.Validator, 0, sampleSize)
+	indices := make([]uint64, 0, sampleSize)
+	for _, i := range rand.Perm(len(data.Validators))[:sampleSize] {
+		sample = append(sample, data.Validators[i])
+		indices = append(indices, data.Validators[i].Index)
+	}
+
+	dbBalances, err := db.GetValidatorBalancesAtEpoch(epoch, indices)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving validator balances from db: %w", err)
+	}
+
+	issues := []*types.DataIntegrityIssue{}
+	for _, validator := range sample {
+		metrics.DataIntegrityChecks.WithLabelValues(db.DataIntegrityCheckBalance).Inc()
+		dbBalance, exists := dbBalances[validator.Index]
+		if exists && dbBalance == validator.Balance {
+			continue
+		}
+		index := validator.Index
+		dbValue := ""
+		if exists {
+			dbValue = fmt.Sprintf("%v", dbBalance)
+		}
+		issues = append(issues, &types.DataIntegrityIssue{CheckType: db.DataIntegrityCheckBalance, Epoch: epoch, ValidatorIndex: &index, DBValue: dbValue, NodeValue: fmt.Sprintf("%v", validator.Balance)})
+	}
+	return issues, nil
+}
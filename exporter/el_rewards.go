@@ -0,0 +1,126 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// elRewardsBatchSize is the number of blocks evaluated per run
+const elRewardsBatchSize = 100
+
+// elRewardsExporter stores the value the proposers of the canonical blocks received for the execution payloads in wei,
+// blocks that become canonical later (e.g. after fixing the canonical chain) are picked up in the next run
+func elRewardsExporter() {
+	client, err := rpc.DialEth1(append([]string{utils.Config.Indexer.Eth1Endpoint}, utils.Config.Indexer.Eth1FallbackEndpoints...))
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	for {
+		count, err := exportElRewards(client)
+		if err != nil {
+			logger.WithError(err).Errorf("error exporting el rewards")
+		}
+		if err != nil || count < elRewardsBatchSize {
+			time.Sleep(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
+		}
+	}
+}
+
+func exportElRewards(client *gethRPC.Client) (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("export_el_rewards").Observe(time.Since(start).Seconds())
+	}()
+
+	blocks, err := db.GetBlocksWithoutElRewards(elRewardsBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving blocks without el rewards: %w", err)
+	}
+
+	for _, b := range blocks {
+		reward, recipient, err := getElReward(client, b)
+		if err != nil {
+			return 0, fmt.Errorf("error retrieving el reward of slot %v: %w", b.Slot, err)
+		}
+		err = db.SaveElReward(b.Slot, b.BlockRoot, reward, recipient)
+		if err != nil {
+			return 0, fmt.Errorf("error saving el reward of slot %v: %w", b.Slot, err)
+		}
+	}
+	if len(blocks) > 0 {
+		logger.WithFields(logrus.Fields{"blocks": len(blocks), "duration": time.Since(start)}).Infof("exported el rewards")
+	}
+	return len(blocks), nil
+}
+
+type elRewardReceipt struct {
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	Status            hexutil.Uint64  `json:"status"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+}
+
+type elRewardTransaction struct {
+	Value *hexutil.Big `json:"value"`
+}
+
+// getElReward returns the value the proposer received for the execution payload of the block and the address it was
+// paid to. Builders set themselves as fee recipient of the blocks delivered by relays and pay the proposer with the
+// last transaction of the block, from the fee recipient to the fee recipient of the proposer, the value of that
+// transaction is the reward of the proposer. For all other blocks the reward are the priority fees paid to the fee
+// recipient, every transaction pays gasUsed * (effectiveGasPrice - baseFeePerGas).
+func getElReward(client *gethRPC.Client, b *db.ElRewardBlock) (types.Wei, []byte, error) {
+	receipts := []*elRewardReceipt{}
+	err := client.CallContext(context.Background(), &receipts, "eth_getBlockReceipts", hexutil.EncodeUint64(b.ExecBlockNumber))
+	if err != nil {
+		return types.Wei{}, nil, err
+	}
+
+	baseFee := new(big.Int).SetUint64(b.BaseFeePerGas)
+	reward := new(big.Int)
+	for _, r := range receipts {
+		if !bytes.Equal(r.BlockHash.Bytes(), b.ExecBlockHash) {
+			return types.Wei{}, nil, fmt.Errorf("receipts of block %v belong to block %v, the eth1 endpoint is not in sync", common.BytesToHash(b.ExecBlockHash).Hex(), r.BlockHash.Hex())
+		}
+		if r.EffectiveGasPrice == nil {
+			return types.Wei{}, nil, fmt.Errorf("receipt of block %v has no effective gas price", r.BlockHash.Hex())
+		}
+		tip := new(big.Int).Sub(r.EffectiveGasPrice.ToInt(), baseFee)
+		reward.Add(reward, tip.Mul(tip, new(big.Int).SetUint64(uint64(r.GasUsed))))
+	}
+
+	if len(receipts) > 0 {
+		last := receipts[len(receipts)-1]
+		if last.Status == 1 && last.To != nil && bytes.Equal(last.From.Bytes(), b.ExecFeeRecipient) && !bytes.Equal(last.To.Bytes(), b.ExecFeeRecipient) {
+			tx := &elRewardTransaction{}
+			err := client.CallContext(context.Background(), tx, "eth_getTransactionByHash", last.TransactionHash)
+			if err != nil {
+				return types.Wei{}, nil, err
+			}
+			if tx.Value == nil {
+				return types.Wei{}, nil, fmt.Errorf("transaction %v not found", last.TransactionHash.Hex())
+			}
+			if tx.Value.ToInt().Sign() > 0 {
+				return types.NewWei(tx.Value.ToInt()), last.To.Bytes(), nil
+			}
+		}
+	}
+	return types.NewWei(reward), b.ExecFeeRecipient, nil
+}
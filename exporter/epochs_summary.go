@@ -0,0 +1,45 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// epochsSummaryBackfillBatchSize is the number of epochs summarized per statement while backfilling
+const epochsSummaryBackfillBatchSize = 1000
+
+// updateEpochsSummary recomputes the summaries of the epochs the indexer just exported or updated
+func updateEpochsSummary(startEpoch, endEpoch uint64) {
+	start := time.Now()
+	err := db.UpdateEpochsSummary(startEpoch, endEpoch)
+	if err != nil {
+		logger.WithError(err).Errorf("error updating summary of epochs %v-%v", startEpoch, endEpoch)
+		return
+	}
+	logger.WithFields(logrus.Fields{"startEpoch": startEpoch, "endEpoch": endEpoch, "duration": time.Since(start)}).Infof("updated epochs summary")
+}
+
+// backfillEpochsSummary summarizes the exported epochs that have no summary yet, e.g. after the epochs_summary table has
+// been created or epochs have been exported while the indexer was running an older version
+func backfillEpochsSummary() {
+	for {
+		epochs, err := db.GetEpochsWithoutSummary(epochsSummaryBackfillBatchSize)
+		if err != nil {
+			logger.WithError(err).Errorf("error retrieving epochs without summary")
+			return
+		}
+		if len(epochs) == 0 {
+			return
+		}
+		startEpoch := epochs[0]
+		endEpoch := epochs[len(epochs)-1]
+		logger.Infof("backfilling summary of %v epochs between %v and %v", len(epochs), startEpoch, endEpoch)
+		err = db.UpdateEpochsSummary(startEpoch, endEpoch)
+		if err != nil {
+			logger.WithError(err).Errorf("error backfilling summary of epochs %v-%v", startEpoch, endEpoch)
+			return
+		}
+	}
+}
@@ -3,6 +3,8 @@ package exporter
 import (
 	"context"
 	"eth2-exporter/db"
+	"eth2-exporter/publisher"
+	"eth2-exporter/rpc"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
@@ -16,13 +18,11 @@ import (
 	gethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	gethRPC "github.com/ethereum/go-ethereum/rpc"
-	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
 	contracts "github.com/prysmaticlabs/prysm/contracts/deposit-contract"
 	ethpb "github.com/prysmaticlabs/prysm/proto/prysm/v1alpha1"
 	"github.com/prysmaticlabs/prysm/shared/bytesutil"
 	"github.com/prysmaticlabs/prysm/shared/depositutil"
 	"github.com/prysmaticlabs/prysm/shared/hashutil"
-	"github.com/prysmaticlabs/prysm/shared/params"
 	"github.com/sirupsen/logrus"
 )
 
@@ -44,7 +44,7 @@ func eth1DepositsExporter() {
 	eth1DepositContractAddress = common.HexToAddress(utils.Config.Indexer.Eth1DepositContractAddress)
 	eth1DepositContractFirstBlock = utils.Config.Indexer.Eth1DepositContractFirstBlock
 
-	rpcClient, err := gethRPC.Dial(utils.Config.Indexer.Eth1Endpoint)
+	rpcClient, err := rpc.DialEth1(append([]string{utils.Config.Indexer.Eth1Endpoint}, utils.Config.Indexer.Eth1FallbackEndpoints...))
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -54,11 +54,17 @@ func eth1DepositsExporter() {
 
 	lastFetchedBlock := uint64(0)
 
+	// make sure the depositor leaderboard contains deposits saved before it existed
+	err = db.UpdateEth1Depositors(nil)
+	if err != nil {
+		logger.WithError(err).Errorf("error updating eth1-depositors")
+	}
+
 	for {
 		t0 := time.Now()
 
 		var lastDepositBlock uint64
-		err = db.DB.Get(&lastDepositBlock, "select coalesce(max(block_number),0) from eth1_deposits")
+		err = db.ExporterDB.Get(&lastDepositBlock, "select coalesce(max(block_number),0) from eth1_deposits")
 		if err != nil {
 			logger.WithError(err).Errorf("error retrieving highest block_number of eth1-deposits from db")
 			time.Sleep(time.Second * 5)
@@ -119,6 +125,18 @@ func eth1DepositsExporter() {
 			continue
 		}
 
+		publishDepositEvents(depositsToSave)
+		publisher.PublishDeposits(depositsToSave)
+
+		depositors := make([][]byte, 0, len(depositsToSave))
+		for _, d := range depositsToSave {
+			depositors = append(depositors, d.FromAddress)
+		}
+		err = db.UpdateEth1Depositors(depositors)
+		if err != nil {
+			logger.WithError(err).Errorf("error updating eth1-depositors")
+		}
+
 		// make sure we are progressing even if there are no deposits in the last batch
 		lastFetchedBlock = toBlock
 
@@ -129,6 +147,7 @@ func eth1DepositsExporter() {
 			"toBlock":       toBlock,
 			"depositsSaved": len(depositsToSave),
 		}).Info("exported eth1-deposits")
+		updateExporterStatus("eth1_deposits")
 
 		// progress faster if we are not synced to head yet
 		if blockHeight != toBlock {
@@ -157,40 +176,7 @@ func fetchEth1Deposits(fromBlock, toBlock uint64) (depositsToSave []*types.Eth1D
 	blocksToFetch := []uint64{}
 	txsToFetch := []string{}
 
-	cfg := params.BeaconConfig()
-	domain, err := helpers.ComputeDomain(
-		cfg.DomainDeposit,
-		cfg.GenesisForkVersion,
-		cfg.ZeroHash[:],
-	)
-	if utils.Config.Chain.Network == "zinken" {
-		domain, err = helpers.ComputeDomain(
-			cfg.DomainDeposit,
-			[]byte{0x00, 0x00, 0x00, 0x03},
-			cfg.ZeroHash[:],
-		)
-	}
-	if utils.Config.Chain.Network == "toledo" {
-		domain, err = helpers.ComputeDomain(
-			cfg.DomainDeposit,
-			[]byte{0x00, 0x70, 0x1E, 0xD0},
-			cfg.ZeroHash[:],
-		)
-	}
-	if utils.Config.Chain.Network == "pyrmont" {
-		domain, err = helpers.ComputeDomain(
-			cfg.DomainDeposit,
-			[]byte{0x00, 0x00, 0x20, 0x09},
-			cfg.ZeroHash[:],
-		)
-	}
-	if utils.Config.Chain.Network == "prater" {
-		domain, err = helpers.ComputeDomain(
-			cfg.DomainDeposit,
-			[]byte{0x00, 0x00, 0x10, 0x20},
-			cfg.ZeroHash[:],
-		)
-	}
+	domain, err := utils.DepositDomain()
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +247,7 @@ func fetchEth1Deposits(fromBlock, toBlock uint64) (depositsToSave []*types.Eth1D
 }
 
 func saveEth1Deposits(depositsToSave []*types.Eth1Deposit) error {
-	tx, err := db.DB.Begin()
+	tx, err := db.ExporterDB.Begin()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,97 @@
+package exporter
+
+import (
+	"eth2-exporter/events"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"time"
+)
+
+// publishEpochEvents publishes the proposed blocks and slashings of a recent epoch on the event bus, epochs
+// exported while syncing are skipped since their notifications are outdated anyway
+func publishEpochEvents(data *types.EpochData) {
+	if uint64(utils.TimeToEpoch(time.Now())) > data.Epoch+10 {
+		return
+	}
+
+	chainEvents := []*types.ChainEvent{}
+	for _, slot := range data.Blocks {
+		for _, b := range slot {
+			if b.Status != 1 {
+				continue
+			}
+			ts := utils.SlotToTime(b.Slot).Unix()
+			chainEvents = append(chainEvents, &types.ChainEvent{
+				Name:           types.ChainEventBlockProposed,
+				Epoch:          data.Epoch,
+				Slot:           b.Slot,
+				ValidatorIndex: b.Proposer,
+				Ts:             ts,
+			})
+
+			for _, s := range b.ProposerSlashings {
+				chainEvents = append(chainEvents, &types.ChainEvent{
+					Name:           types.ChainEventValidatorSlashed,
+					Epoch:          data.Epoch,
+					Slot:           b.Slot,
+					ValidatorIndex: s.ProposerIndex,
+					Ts:             ts,
+				})
+			}
+			for _, s := range b.AttesterSlashings {
+				attesters := map[uint64]bool{}
+				for _, i := range s.Attestation1.AttestingIndices {
+					attesters[i] = true
+				}
+				for _, i := range s.Attestation2.AttestingIndices {
+					if !attesters[i] {
+						continue
+					}
+					chainEvents = append(chainEvents, &types.ChainEvent{
+						Name:           types.ChainEventValidatorSlashed,
+						Epoch:          data.Epoch,
+						Slot:           b.Slot,
+						ValidatorIndex: i,
+						Ts:             ts,
+					})
+				}
+			}
+		}
+	}
+
+	events.Publish(chainEvents...)
+}
+
+// publishHeadEvent publishes the current head and its finalized and justified checkpoints on the event bus
+func publishHeadEvent(head *types.ChainHead) {
+	events.Publish(&types.ChainEvent{
+		Name:  types.ChainEventHead,
+		Epoch: head.HeadEpoch,
+		Slot:  head.HeadSlot,
+		Ts:    utils.SlotToTime(head.HeadSlot).Unix(),
+		Checkpoints: &types.ChainCheckpoints{
+			HeadBlockRoot:      head.HeadBlockRoot,
+			FinalizedEpoch:     head.FinalizedEpoch,
+			FinalizedBlockRoot: head.FinalizedBlockRoot,
+			JustifiedEpoch:     head.JustifiedEpoch,
+			JustifiedBlockRoot: head.JustifiedBlockRoot,
+		},
+	})
+}
+
+// publishDepositEvents publishes the eth1-deposits that were just stored on the event bus
+func publishDepositEvents(deposits []*types.Eth1Deposit) {
+	chainEvents := make([]*types.ChainEvent, 0, len(deposits))
+	for _, d := range deposits {
+		if d.Removed {
+			continue
+		}
+		chainEvents = append(chainEvents, &types.ChainEvent{
+			Name:      types.ChainEventDepositSeen,
+			Epoch:     uint64(utils.TimeToEpoch(time.Unix(d.BlockTs, 0))),
+			PublicKey: d.PublicKey,
+			Ts:        d.BlockTs,
+		})
+	}
+	events.Publish(chainEvents...)
+}
@@ -3,8 +3,11 @@ package exporter
 import (
 	"bytes"
 	"eth2-exporter/db"
+	"eth2-exporter/logging"
 	"eth2-exporter/metrics"
+	"eth2-exporter/publisher"
 	"eth2-exporter/rpc"
+	"eth2-exporter/services"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
@@ -15,7 +18,7 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-var logger = logrus.New().WithField("module", "exporter")
+var logger = logging.NewLogger("exporter")
 
 // If exporting an epoch fails for 10 consecutive times exporting this epoch will be disabled
 // This is a workaround for a bug in the prysm archive node that causes epochs without blocks
@@ -24,24 +27,62 @@ var epochBlacklist = make(map[uint64]uint64)
 
 // Start will start the export of data from rpc into the database
 func Start(client rpc.Client) error {
-	go performanceDataUpdater()
-	go networkLivenessUpdater(client)
-	go eth1DepositsExporter()
-	go genesisDepositsExporter()
-	go checkSubscriptions()
-	go cleanupOldMachineStats()
-	go syncCommitteesExporter(client)
-	if utils.Config.SSVExporter.Enabled {
-		go ssvExporter()
+	go services.RunAsLeader("performance_data_updater", performanceDataUpdater)
+	go services.RunAsLeader("network_liveness_updater", func() { networkLivenessUpdater(client) })
+	go services.RunAsLeader("eth1_deposits_exporter", eth1DepositsExporter)
+	go services.RunAsLeader("pending_deposits_exporter", pendingDepositsExporter)
+	go services.RunAsLeader("genesis_deposits_exporter", genesisDepositsExporter)
+	go services.RunAsLeader("check_subscriptions", checkSubscriptions)
+	go services.RunAsLeader("cleanup_old_machine_stats", cleanupOldMachineStats)
+	go services.RunAsLeader("hosting_stats_exporter", hostingStatsExporter)
+	go services.RunAsLeader("sync_committees_exporter", func() { syncCommitteesExporter(client) })
+	startProtocolExporters()
+
+	if utils.Config.Indexer.PubKeyTagsExporter.Enabled {
+		go services.RunAsLeader("pubkey_tags_exporter", UpdatePubkeyTag)
+	}
+
+	if utils.Config.Indexer.NetworkIncidents.Enabled {
+		go services.RunAsLeader("network_incidents_updater", func() { networkIncidentsUpdater(client) })
 	}
-	if utils.Config.RocketpoolExporter.Enabled {
-		go rocketpoolExporter()
+
+	if utils.Config.Indexer.DataIntegrity.Enabled {
+		go services.RunAsLeader("data_integrity_verifier", func() { dataIntegrityVerifier(client) })
 	}
 
-	if utils.Config.Indexer.PubKeyTagsExporter.Enabled {
-		go UpdatePubkeyTag()
+	if utils.Config.Indexer.BlockPropagation.Enabled {
+		go services.RunAsLeader("block_propagation_exporter", blockPropagationExporter)
+		go services.RunAsLeader("block_propagation_pruner", blockPropagationPruner)
+	}
+
+	if utils.Config.Indexer.AttestationCorrectness.Enabled {
+		go services.RunAsLeader("attestation_correctness_updater", func() { attestationCorrectnessUpdater(client) })
+	}
+
+	if utils.Config.Indexer.BlockPacking.Enabled && utils.Config.Frontend.PersonalMode.Enabled {
+		logger.Warnf("block packing is not evaluated in personal mode, only the attestations of the watched validators are stored")
+	} else if utils.Config.Indexer.BlockPacking.Enabled {
+		go services.RunAsLeader("block_packing_updater", func() { blockPackingUpdater(client) })
+	}
+
+	if utils.Config.Indexer.ElRewards.Enabled {
+		go services.RunAsLeader("el_rewards_exporter", elRewardsExporter)
 	}
 
+	if utils.Config.Indexer.RelayBids.Enabled {
+		go services.RunAsLeader("relay_bids_exporter", relayBidsExporter)
+	}
+
+	if utils.Config.Indexer.AttestationAssignmentsRetentionWeeks > 0 {
+		go services.RunAsLeader("attestation_assignments_pruner", attestationAssignmentsPruner)
+	}
+
+	services.RunAsLeader("indexer", func() { index(client) })
+	return nil
+}
+
+// index exports the epochs and blocks of the beacon-node, it runs forever
+func index(client rpc.Client) {
 	// wait until the beacon-node is available
 	for {
 		_, err := client.GetChainHead()
@@ -59,13 +100,15 @@ func Start(client rpc.Client) error {
 			logger.Fatal(err)
 		}
 
+		epochs := make([]uint64, 0, head.HeadEpoch)
 		for epoch := uint64(1); epoch <= head.HeadEpoch; epoch++ {
-			err := ExportEpoch(epoch, client)
-
+			epochs = append(epochs, epoch)
+		}
+		exportEpochs(epochs, client, func(epoch uint64, err error) {
 			if err != nil {
 				logger.Error(err)
 			}
-		}
+		})
 	}
 
 	if utils.Config.Indexer.FixCanonOnStartup {
@@ -109,13 +152,16 @@ func Start(client rpc.Client) error {
 			if epochs[i] != epochs[i+1]-1 && epochs[i] != epochs[i+1] {
 				logger.Println("Epochs between", epochs[i], "and", epochs[i+1], "are missing!")
 
+				missing := make([]uint64, 0, epochs[i+1]-epochs[i]+1)
 				for epoch := epochs[i]; epoch <= epochs[i+1]; epoch++ {
-					err := ExportEpoch(epoch, client)
+					missing = append(missing, epoch)
+				}
+				exportEpochs(missing, client, func(epoch uint64, err error) {
 					if err != nil {
 						logger.Error(err)
 					}
 					logger.Printf("finished export for epoch %v", epoch)
-				}
+				})
 			}
 		}
 	}
@@ -185,16 +231,14 @@ func Start(client rpc.Client) error {
 			return keys[i] < keys[j]
 		})
 
-		for _, epoch := range keys {
-			err = ExportEpoch(epoch, client)
-
+		exportEpochs(keys, client, func(epoch uint64, err error) {
 			if err != nil {
 				logger.Errorf("error exporting epoch: %v", err)
 				if utils.EpochToTime(epoch).Before(time.Now().Add(time.Hour * -24)) {
 					epochBlacklist[epoch]++
 				}
 			}
-		}
+		})
 	}
 
 	if utils.Config.Indexer.UpdateAllEpochStatistics {
@@ -210,6 +254,8 @@ func Start(client rpc.Client) error {
 		}
 	}
 
+	backfillEpochsSummary()
+
 	newBlockChan := client.GetNewBlockChan()
 
 	lastExportedSlot := uint64(0)
@@ -229,10 +275,15 @@ func Start(client rpc.Client) error {
 				}
 			}
 			lastExportedSlot = block.Slot
+
+			head, err := client.GetChainHead()
+			if err != nil {
+				logger.Errorf("error retrieving chain head: %v", err)
+				continue
+			}
+			publishHeadEvent(head)
 		}
 	}
-
-	return nil
 }
 
 // Will ensure the db is fully in sync with the node
@@ -359,16 +410,16 @@ func doFullCheck(client rpc.Client) {
 		return keys[i] < keys[j]
 	})
 
+	toExport := make([]uint64, 0, len(keys))
 	for _, epoch := range keys {
 		if epochBlacklist[epoch] > 3 {
 			logger.Printf("skipping export of epoch %v as it has errored %d times", epoch, epochBlacklist[epoch])
 			continue
 		}
+		toExport = append(toExport, epoch)
+	}
 
-		logger.Printf("exporting epoch %v", epoch)
-
-		err = ExportEpoch(epoch, client)
-
+	exportEpochs(toExport, client, func(epoch uint64, err error) {
 		if err != nil {
 			logger.Errorf("error exporting epoch: %v", err)
 			if utils.EpochToTime(epoch).Before(time.Now().Add(time.Hour * -24)) {
@@ -376,7 +427,7 @@ func doFullCheck(client rpc.Client) {
 			}
 		}
 		logger.Printf("finished export for epoch %v", epoch)
-	}
+	})
 
 	logger.Infof("marking orphaned blocks of epochs %v-%v", startEpoch, head.HeadEpoch)
 	err = MarkOrphanedBlocks(startEpoch, head.HeadEpoch, nodeBlocks)
@@ -385,6 +436,7 @@ func doFullCheck(client rpc.Client) {
 	}
 
 	// Update epoch statistics up to 10 epochs after the last finalized epoch
+	summaryStartEpoch := startEpoch
 	startEpoch = uint64(0)
 	if head.FinalizedEpoch > 10 {
 		startEpoch = head.FinalizedEpoch - 10
@@ -398,6 +450,16 @@ func doFullCheck(client rpc.Client) {
 		logger.Errorf("error updating epoch stratus: %v", err)
 	}
 
+	// Summarize all epochs whose blocks, participation or finalization might have changed in this run
+	if startEpoch < summaryStartEpoch {
+		summaryStartEpoch = startEpoch
+	}
+	if len(keys) > 0 && keys[0] < summaryStartEpoch {
+		summaryStartEpoch = keys[0]
+	}
+	updateEpochsSummary(summaryStartEpoch, head.HeadEpoch)
+	updateValidatorStatusHistory(head.HeadEpoch)
+
 	logger.Infof("exporting validation queue")
 	err = exportValidatorQueue(client)
 	if err != nil {
@@ -405,6 +467,15 @@ func doFullCheck(client rpc.Client) {
 	}
 
 	logger.Infof("finished exporting all new blocks/epochs")
+	updateExporterStatus("indexer")
+}
+
+// updateExporterStatus marks the last run of the exporter as successful, it is shown on the status page
+func updateExporterStatus(name string) {
+	err := db.UpdateExporterStatus(name)
+	if err != nil {
+		logger.WithError(err).Errorf("error updating exporter-status of %v", name)
+	}
 }
 
 // MarkOrphanedBlocks will mark the orphaned blocks in the database
@@ -444,56 +515,132 @@ func GetLastBlocks(startEpoch, endEpoch uint64, client rpc.Client) ([]*types.Min
 
 // ExportEpoch will export an epoch from rpc into the database
 func ExportEpoch(epoch uint64, client rpc.Client) error {
+	data, err := fetchEpoch(epoch, client)
+	if err != nil {
+		return err
+	}
+	return saveEpoch(epoch, data)
+}
+
+// fetchEpoch retrieves the data of an epoch from the node
+func fetchEpoch(epoch uint64, client rpc.Client) (*types.EpochData, error) {
+	start := time.Now()
+	logger.Printf("retrieving data for epoch %v", epoch)
+	data, err := client.GetEpochData(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving epoch data: %v", err)
+	}
+	metrics.TaskDuration.WithLabelValues("rpc_get_epoch_data").Observe(time.Since(start).Seconds())
+	logger.WithFields(logrus.Fields{"duration": time.Since(start), "epoch": epoch}).Info("completed getting epoch-data")
+
+	if len(data.Validators) == 0 {
+		return nil, fmt.Errorf("error retrieving epoch data: no validators received for epoch")
+	}
+	return data, nil
+}
+
+// saveEpoch saves the data of an epoch retrieved by fetchEpoch
+func saveEpoch(epoch uint64, data *types.EpochData) error {
 	start := time.Now()
 	defer func() {
 		metrics.TaskDuration.WithLabelValues("export_epoch").Observe(time.Since(start).Seconds())
 		logger.WithFields(logrus.Fields{"duration": time.Since(start), "epoch": epoch}).Info("completed exporting epoch")
 	}()
 
-	// Check if the partition for the validator_balances and attestation_assignments and sync_assignments table for this epoch exists
+	ensureEpochPartitions(epoch)
+
+	err := db.SaveEpoch(data)
+	if err != nil {
+		return err
+	}
+
+	publishEpochEvents(data)
+	publisher.PublishEpoch(data)
+	return nil
+}
+
+// exportEpochs exports the epochs in the given order, the next epochs are fetched while the previous ones are saved.
+// The epochs are saved sequentially in order as a later epoch overwrites the validator state of an earlier one, done is
+// called with the result of every epoch after it has been saved.
+func exportEpochs(epochs []uint64, client rpc.Client, done func(epoch uint64, err error)) {
+	workers := utils.Config.Indexer.EpochPipeline.FetchWorkers
+	if workers <= 0 {
+		workers = 2
+	}
+	depth := utils.Config.Indexer.EpochPipeline.Depth
+	if depth <= 0 {
+		depth = 3
+	}
+
+	type fetchResult struct {
+		data *types.EpochData
+		err  error
+	}
+	results := make([]chan fetchResult, len(epochs))
+	for i := range results {
+		results[i] = make(chan fetchResult, 1)
+	}
+
+	// a slot is taken before an epoch is fetched and released once it is saved, which bounds the epochs held in memory.
+	// The slots are taken in order, so the next epoch to save is always being fetched.
+	slots := make(chan struct{}, depth)
+	next := make(chan int)
+	go func() {
+		for i := range epochs {
+			slots <- struct{}{}
+			next <- i
+		}
+		close(next)
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range next {
+				data, err := fetchEpoch(epochs[i], client)
+				results[i] <- fetchResult{data: data, err: err}
+			}
+		}()
+	}
+
+	for i, epoch := range epochs {
+		res := <-results[i]
+		err := res.err
+		if err == nil {
+			err = saveEpoch(epoch, res.data)
+		}
+		<-slots
+		done(epoch, err)
+	}
+}
+
+// ensureEpochPartitions checks if the partition for the validator_balances and attestation_assignments and sync_assignments table for this epoch exists and creates it otherwise
+func ensureEpochPartitions(epoch uint64) {
 	var one int
 	logger.Printf("checking partition status for epoch %v", epoch)
 	week := epoch / 1575
-	err := db.DB.Get(&one, fmt.Sprintf("SELECT 1 FROM information_schema.tables WHERE table_name = 'attestation_assignments_%v'", week))
+	err := db.IndexerDB.Get(&one, fmt.Sprintf("SELECT 1 FROM information_schema.tables WHERE table_name = 'attestation_assignments_%v'", week))
 	if err != nil {
 		logger.Infof("creating partition attestation_assignments_%v", week)
-		_, err := db.DB.Exec(fmt.Sprintf("CREATE TABLE attestation_assignments_%v PARTITION OF attestation_assignments_p FOR VALUES IN (%v);", week, week))
+		_, err := db.IndexerDB.Exec(fmt.Sprintf("CREATE TABLE attestation_assignments_%v PARTITION OF attestation_assignments_p FOR VALUES IN (%v);", week, week))
 		if err != nil {
 			logger.Fatalf("unable to create partition attestation_assignments_%v: %v", week, err)
 		}
 	}
-	err = db.DB.Get(&one, fmt.Sprintf("SELECT 1 FROM information_schema.tables WHERE table_name = 'validator_balances_%v'", week))
+	err = db.IndexerDB.Get(&one, fmt.Sprintf("SELECT 1 FROM information_schema.tables WHERE table_name = 'validator_balances_%v'", week))
 	if err != nil {
 		logger.Infof("creating partition validator_balances_%v", week)
-		_, err := db.DB.Exec(fmt.Sprintf("CREATE TABLE validator_balances_%v PARTITION OF validator_balances_p FOR VALUES IN (%v);", week, week))
+		_, err := db.IndexerDB.Exec(fmt.Sprintf("CREATE TABLE validator_balances_%v PARTITION OF validator_balances_p FOR VALUES IN (%v);", week, week))
 		if err != nil {
 			logger.Fatalf("unable to create partition validator_balances_%v: %v", week, err)
 		}
 	}
-	err = db.DB.Get(&one, fmt.Sprintf("SELECT 1 FROM information_schema.tables WHERE table_name = 'sync_assignments_%v'", week))
+	err = db.IndexerDB.Get(&one, fmt.Sprintf("SELECT 1 FROM information_schema.tables WHERE table_name = 'sync_assignments_%v'", week))
 	if err != nil {
 		logger.Infof("creating partition sync_assignments_%v", week)
-		_, err := db.DB.Exec(fmt.Sprintf("CREATE TABLE sync_assignments_%v PARTITION OF sync_assignments_p FOR VALUES IN (%v);", week, week))
+		_, err := db.IndexerDB.Exec(fmt.Sprintf("CREATE TABLE sync_assignments_%v PARTITION OF sync_assignments_p FOR VALUES IN (%v);", week, week))
 		if err != nil {
 			logger.Fatalf("unable to create partition sync_assignments_%v: %v", week, err)
 		}
 	}
-
-	startGetEpochData := time.Now()
-	logger.Printf("retrieving data for epoch %v", epoch)
-	data, err := client.GetEpochData(epoch)
-	if err != nil {
-		return fmt.Errorf("error retrieving epoch data: %v", err)
-	}
-	metrics.TaskDuration.WithLabelValues("rpc_get_epoch_data").Observe(time.Since(startGetEpochData).Seconds())
-	logger.WithFields(logrus.Fields{"duration": time.Since(startGetEpochData), "epoch": epoch}).Info("completed getting epoch-data")
-	logger.Printf("data for epoch %v retrieved, took %v", epoch, time.Since(start))
-
-	if len(data.Validators) == 0 {
-		return fmt.Errorf("error retrieving epoch data: no validators received for epoch")
-	}
-
-	return db.SaveEpoch(data)
 }
 
 func exportValidatorQueue(client rpc.Client) error {
@@ -531,6 +678,7 @@ func performanceDataUpdater() {
 			logger.Errorf("error updating validator performance data: %v", err)
 		} else {
 			logger.Info("validator performance data update completed")
+			updateExporterStatus("validator_performance")
 		}
 		time.Sleep(time.Hour)
 	}
@@ -541,7 +689,7 @@ func updateValidatorPerformance() error {
 	defer func() {
 		metrics.TaskDuration.WithLabelValues("update_validator_performance").Observe(time.Since(start).Seconds())
 	}()
-	tx, err := db.DB.Beginx()
+	tx, err := db.IndexerDB.Beginx()
 	if err != nil {
 		return fmt.Errorf("error starting db transaction: %w", err)
 	}
@@ -713,7 +861,7 @@ func finalityCheckpointsUpdater(client rpc.Client) {
 	t := time.NewTicker(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
 	for range t.C {
 		var prevEpoch uint64
-		err := db.DB.Get(&prevEpoch, `select coalesce(max(epoch),1) from finality_checkpoints`)
+		err := db.IndexerDB.Get(&prevEpoch, `select coalesce(max(epoch),1) from finality_checkpoints`)
 		if err != nil {
 			logger.WithError(err).Errorf("error getting last exported finality_checkpoints from db")
 			continue
@@ -724,7 +872,7 @@ func finalityCheckpointsUpdater(client rpc.Client) {
 			logger.WithFields(logrus.Fields{"error": err, "epoch": nextEpoch}).Errorf("error getting finality_checkpoints from client")
 			continue
 		}
-		_, err = db.DB.Exec(`
+		_, err = db.IndexerDB.Exec(`
 			insert into finality_checkpoints (
 				epoch, 
 				current_justified_epoch, current_justified_root, 
@@ -746,7 +894,7 @@ func finalityCheckpointsUpdater(client rpc.Client) {
 
 func networkLivenessUpdater(client rpc.Client) {
 	var prevHeadEpoch uint64
-	err := db.DB.Get(&prevHeadEpoch, "SELECT COALESCE(MAX(headepoch), 0) FROM network_liveness")
+	err := db.IndexerDB.Get(&prevHeadEpoch, "SELECT COALESCE(MAX(headepoch), 0) FROM network_liveness")
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -773,7 +921,7 @@ func networkLivenessUpdater(client rpc.Client) {
 			continue
 		}
 
-		_, err = db.DB.Exec(`
+		_, err = db.IndexerDB.Exec(`
 			INSERT INTO network_liveness (ts, headepoch, finalizedepoch, justifiedepoch, previousjustifiedepoch)
 			VALUES (NOW(), $1, $2, $3, $4)`,
 			head.HeadEpoch, head.FinalizedEpoch, head.JustifiedEpoch, head.PreviousJustifiedEpoch)
@@ -792,7 +940,7 @@ func genesisDepositsExporter() {
 	for {
 		// check if the beaconchain has started
 		var latestEpoch uint64
-		err := db.DB.Get(&latestEpoch, "SELECT COALESCE(MAX(epoch), 0) FROM epochs")
+		err := db.IndexerDB.Get(&latestEpoch, "SELECT COALESCE(MAX(epoch), 0) FROM epochs")
 		if err != nil {
 			logger.Errorf("error retrieving latest epoch from the database: %v", err)
 			time.Sleep(time.Second * 10)
@@ -806,7 +954,7 @@ func genesisDepositsExporter() {
 
 		// check if genesis-deposits have already been exported
 		var genesisDepositsCount uint64
-		err = db.DB.Get(&genesisDepositsCount, "SELECT COUNT(*) FROM blocks_deposits INNER JOIN blocks ON blocks_deposits.block_root = blocks.blockroot AND blocks.status = '1' WHERE block_slot=0")
+		err = db.IndexerDB.Get(&genesisDepositsCount, "SELECT COUNT(*) FROM blocks_deposits INNER JOIN blocks ON blocks_deposits.block_root = blocks.blockroot AND blocks.status = '1' WHERE block_slot=0")
 		if err != nil {
 			logger.Errorf("error retrieving genesis-deposits-count when exporting genesis-deposits: %v", err)
 			time.Sleep(time.Second * 60)
@@ -820,7 +968,7 @@ func genesisDepositsExporter() {
 
 		// get genesis-validators-count
 		var genesisValidatorsCount uint64
-		err = db.DB.Get(&genesisValidatorsCount, "SELECT validatorscount FROM epochs WHERE epoch=0")
+		err = db.IndexerDB.Get(&genesisValidatorsCount, "SELECT validatorscount FROM epochs WHERE epoch=0")
 		if err != nil {
 			logger.Errorf("error retrieving validatorscount for genesis-epoch when exporting genesis-deposits: %v", err)
 			time.Sleep(time.Second * 60)
@@ -829,7 +977,7 @@ func genesisDepositsExporter() {
 
 		// check if eth1-deposits have already been exported
 		var missingEth1Deposits uint64
-		err = db.DB.Get(&missingEth1Deposits, `
+		err = db.IndexerDB.Get(&missingEth1Deposits, `
 			SELECT COUNT(*)
 			FROM validators v
 			LEFT JOIN ( 
@@ -848,7 +996,7 @@ func genesisDepositsExporter() {
 			continue
 		}
 
-		tx, err := db.DB.Beginx()
+		tx, err := db.IndexerDB.Beginx()
 		if err != nil {
 			logger.Errorf("error beginning db-tx when exporting genesis-deposits: %v", err)
 			time.Sleep(time.Second * 60)
@@ -0,0 +1,46 @@
+package exporter
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"time"
+)
+
+// hostingStatsExporter aggregates the performance of the validators with a declared hosting for every day whose
+// validator statistics have been exported. The declarations of past days are not stored, so only the last exported day
+// is computed when the stats have never been exported before.
+func hostingStatsExporter() {
+	for {
+		var lastStatsDay *uint64
+		err := db.DB.Get(&lastStatsDay, "SELECT MAX(day) FROM validator_stats_status WHERE status")
+		if err != nil {
+			logger.Errorf("error retrieving last exported statistics day: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+		if lastStatsDay == nil {
+			time.Sleep(time.Minute * 10)
+			continue
+		}
+
+		lastDay, exported, err := db.GetLastHostingStatsDay(context.Background())
+		if err != nil {
+			logger.Errorf("error retrieving last hosting stats day: %v", err)
+			time.Sleep(time.Minute)
+			continue
+		}
+		day := *lastStatsDay
+		if exported {
+			day = lastDay + 1
+		}
+
+		for ; day <= *lastStatsDay; day++ {
+			err = db.UpdateHostingStatsForDay(day)
+			if err != nil {
+				logger.Errorf("error updating hosting stats for day %v: %v", day, err)
+				break
+			}
+		}
+		time.Sleep(time.Minute * 10)
+	}
+}
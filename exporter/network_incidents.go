@@ -0,0 +1,104 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+)
+
+// networkIncidentsUpdater records the participation rate and finality delay of every epoch
+// and opens or resolves network incidents whenever the configured thresholds are crossed
+func networkIncidentsUpdater(client rpc.Client) {
+	slotDuration := time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot)
+
+	for {
+		head, err := client.GetChainHead()
+		if err != nil {
+			logger.Errorf("error getting chainhead when updating network incidents: %v", err)
+			time.Sleep(slotDuration)
+			continue
+		}
+
+		// the participation of an epoch is only known once the next epoch has started
+		if head.HeadEpoch > 0 {
+			err = updateNetworkIncidentsSince(client, head)
+			if err != nil {
+				logger.Errorf("error updating network incidents: %v", err)
+			}
+		}
+
+		time.Sleep(slotDuration)
+	}
+}
+
+// updateNetworkIncidentsSince updates the network incidents of all completed epochs after the last recorded epoch, at
+// most a day of epochs is backfilled after a downtime of the exporter
+func updateNetworkIncidentsSince(client rpc.Client, head *types.ChainHead) error {
+	lastEpoch, ok, err := db.GetLastNetworkParticipationEpoch()
+	if err != nil {
+		return fmt.Errorf("error retrieving last recorded network participation: %w", err)
+	}
+
+	endEpoch := head.HeadEpoch - 1
+	startEpoch := endEpoch
+	if ok {
+		if lastEpoch >= endEpoch {
+			return nil
+		}
+		startEpoch = lastEpoch + 1
+	}
+	if endEpoch-startEpoch >= utils.EpochsPerDay() {
+		startEpoch = endEpoch - utils.EpochsPerDay() + 1
+		logger.Warnf("network incidents are not recorded for epochs %v to %v", lastEpoch+1, startEpoch-1)
+	}
+
+	for epoch := startEpoch; epoch <= endEpoch; epoch++ {
+		// the finality delay of past epochs is not known anymore, the delay to the current finalized epoch is its lower bound
+		finalityDelay := uint64(0)
+		if epoch+1 > head.FinalizedEpoch {
+			finalityDelay = epoch + 1 - head.FinalizedEpoch
+		}
+		err = updateNetworkIncidents(client, epoch, finalityDelay)
+		if err != nil {
+			return fmt.Errorf("error updating network incidents of epoch %v: %w", epoch, err)
+		}
+	}
+	return nil
+}
+
+func updateNetworkIncidents(client rpc.Client, epoch, finalityDelay uint64) error {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("update_network_incidents").Observe(time.Since(start).Seconds())
+	}()
+
+	stats, err := client.GetValidatorParticipation(epoch)
+	if err != nil {
+		return fmt.Errorf("error retrieving epoch participation statistics: %w", err)
+	}
+	participationRate := float64(stats.GlobalParticipationRate)
+
+	err = db.SaveNetworkParticipation(epoch, participationRate, finalityDelay)
+	if err != nil {
+		return fmt.Errorf("error saving network participation: %w", err)
+	}
+
+	lowParticipation := participationRate < utils.Config.Indexer.NetworkIncidents.ParticipationThreshold
+	err = db.UpdateNetworkIncident(db.NetworkIncidentLowParticipation, epoch, lowParticipation, participationRate, finalityDelay)
+	if err != nil {
+		return err
+	}
+
+	nonFinality := finalityDelay > utils.Config.Indexer.NetworkIncidents.FinalityDelayThreshold
+	err = db.UpdateNetworkIncident(db.NetworkIncidentNonFinality, epoch, nonFinality, participationRate, finalityDelay)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("updated network incidents for epoch %v (participation: %.4f, finality delay: %v)", epoch, participationRate, finalityDelay)
+	return nil
+}
@@ -0,0 +1,83 @@
+package exporter
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"time"
+)
+
+// pendingDepositsExporter regularly estimates when the eth1-deposits of public keys that are not yet known
+// to the beacon chain will be included and when the resulting validators will be activated
+func pendingDepositsExporter() {
+	for {
+		t0 := time.Now()
+		err := updatePendingDeposits()
+		if err != nil {
+			logger.WithError(err).Errorf("error updating pending deposits")
+		} else {
+			logger.WithField("duration", time.Since(t0)).Info("updated pending deposits")
+			updateExporterStatus("pending_deposits")
+		}
+		time.Sleep(time.Minute)
+	}
+}
+
+func updatePendingDeposits() error {
+	latestEpoch, err := db.GetLatestEpoch(context.Background())
+	if err != nil {
+		return err
+	}
+	activeCount, err := db.GetActiveValidatorCount()
+	if err != nil {
+		return err
+	}
+	// validators that are already known to the beacon chain are ahead of the pending deposits in the activation queue
+	queuedCount, err := db.GetPendingValidatorCount()
+	if err != nil {
+		return err
+	}
+
+	deposits, err := db.GetEth1DepositsWithoutValidator()
+	if err != nil {
+		return err
+	}
+
+	churnLimit := utils.Config.Chain.MinPerEpochChurnLimit
+	if utils.Config.Chain.ChurnLimitQuotient > 0 && activeCount/utils.Config.Chain.ChurnLimitQuotient > churnLimit {
+		churnLimit = activeCount / utils.Config.Chain.ChurnLimitQuotient
+	}
+	if churnLimit == 0 {
+		churnLimit = 4
+	}
+
+	// a deposit is considered by the beacon chain once it is followed by enough eth1-blocks and voted in during the next voting period
+	inclusionDelay := time.Duration(utils.Config.Chain.Eth1FollowDistance*utils.Config.Chain.SecondsPerETH1Block+
+		utils.Config.Chain.EpochsPerEth1VotingPeriod*utils.Config.Chain.SlotsPerEpoch*utils.Config.Chain.SecondsPerSlot) * time.Second
+
+	queuePosition := queuedCount
+	for _, d := range deposits {
+		d.EstimatedInclusionEpoch = uint64(utils.TimeToEpoch(d.LastDepositTs.Add(inclusionDelay)))
+		if d.EstimatedInclusionEpoch <= latestEpoch {
+			d.EstimatedInclusionEpoch = latestEpoch + 1
+		}
+
+		if d.ValidAmount < utils.Config.Chain.MaxEffectiveBalance {
+			continue
+		}
+
+		queuePosition++
+		position := queuePosition
+		d.QueuePosition = &position
+
+		// the validator becomes eligible in the epoch after its inclusion and has to wait for the eligibility to be finalized
+		dequeueEpoch := latestEpoch + position/churnLimit
+		if dequeueEpoch < d.EstimatedInclusionEpoch+3 {
+			dequeueEpoch = d.EstimatedInclusionEpoch + 3
+		}
+		activationEpoch := dequeueEpoch + 1 + utils.Config.Chain.MaxSeedLookahead
+		d.EstimatedActivationEpoch = &activationEpoch
+	}
+
+	return db.SaveEth1PendingDeposits(deposits)
+}
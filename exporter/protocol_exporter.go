@@ -0,0 +1,172 @@
+package exporter
+
+import (
+	"context"
+	"eth2-exporter/logging"
+	"eth2-exporter/metrics"
+	"eth2-exporter/services"
+	"eth2-exporter/tracing"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProtocolExporter exports the data of a staking protocol (e.g. rocketpool, stakewise, obol, diva) into the database.
+// Init is called once to load the previously exported state, afterwards Update and Save are called every update-interval.
+// Update fetches the current state from the protocol, Save persists it, Save is only called if Update succeeded.
+type ProtocolExporter interface {
+	Name() string
+	Init() error
+	Update() error
+	Save() error
+}
+
+// ProtocolExporterRunLogger is optionally implemented by a ProtocolExporter to log with the logger of the current run,
+// SetLogger is called before every Update with a logger carrying the name of the exporter and the id of the run
+type ProtocolExporterRunLogger interface {
+	SetLogger(l *logrus.Entry)
+}
+
+// ProtocolExporterRunContext is optionally implemented by a ProtocolExporter to trace its work, SetRunContext is
+// called before every Update with a context carrying the span of the run
+type ProtocolExporterRunContext interface {
+	SetRunContext(ctx context.Context)
+}
+
+// ProtocolExporterFactory creates a ProtocolExporter from its config
+type ProtocolExporterFactory func(cfg types.ProtocolExporterConfig) (ProtocolExporter, error)
+
+var protocolExporterFactories = map[string]ProtocolExporterFactory{}
+var protocolExporterFactoriesMux = &sync.Mutex{}
+
+// RegisterProtocolExporter makes a protocol exporter available under the given name, it is meant to be called from the init function of the file implementing the exporter.
+// The exporter is started if it is enabled in Config.ProtocolExporters under the same name.
+func RegisterProtocolExporter(name string, factory ProtocolExporterFactory) {
+	protocolExporterFactoriesMux.Lock()
+	defer protocolExporterFactoriesMux.Unlock()
+	if _, exists := protocolExporterFactories[name]; exists {
+		panic(fmt.Sprintf("protocol exporter %v registered twice", name))
+	}
+	protocolExporterFactories[name] = factory
+}
+
+// protocolExporterConfigs returns the configs of the protocol exporters by name, the rocketpool and ssv exporters are
+// also enabled by their own config sections, an entry in Config.ProtocolExporters takes precedence
+func protocolExporterConfigs() map[string]types.ProtocolExporterConfig {
+	configs := map[string]types.ProtocolExporterConfig{}
+	if utils.Config.RocketpoolExporter.Enabled {
+		configs["rocketpool"] = types.ProtocolExporterConfig{Enabled: true, UpdateIntervalSeconds: 60}
+	}
+	if utils.Config.SSVExporter.Enabled {
+		configs["ssv"] = types.ProtocolExporterConfig{Enabled: true, UpdateIntervalSeconds: 600}
+	}
+	for name, cfg := range utils.ProtocolExportersConfig() {
+		configs[name] = cfg
+	}
+	return configs
+}
+
+// startProtocolExporters starts all registered protocol exporters that are enabled in the config
+func startProtocolExporters() {
+	protocolExporterFactoriesMux.Lock()
+	defer protocolExporterFactoriesMux.Unlock()
+
+	configs := protocolExporterConfigs()
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg := configs[name]
+		if !cfg.Enabled {
+			continue
+		}
+		factory, exists := protocolExporterFactories[name]
+		if !exists {
+			logger.Errorf("protocol exporter %v is enabled but not registered", name)
+			continue
+		}
+		e, err := factory(cfg)
+		if err != nil {
+			logger.WithError(err).Errorf("error creating protocol exporter %v", name)
+			continue
+		}
+		interval := protocolExporterInterval(name)
+		logger.Infof("starting protocol exporter %v", name)
+		go services.RunAsLeader("protocol_exporter_"+name, func() { RunProtocolExporter(e, interval) })
+	}
+}
+
+// protocolExporterInterval returns the configured update-interval of the protocol exporter, it may change when the
+// config is reloaded
+func protocolExporterInterval(name string) time.Duration {
+	interval := time.Second * time.Duration(protocolExporterConfigs()[name].UpdateIntervalSeconds)
+	if interval == 0 {
+		interval = time.Minute
+	}
+	return interval
+}
+
+// RunProtocolExporter initializes the exporter and then updates and saves its data every interval, errors are logged and retried
+func RunProtocolExporter(e ProtocolExporter, interval time.Duration) {
+	errorInterval := time.Second * 10
+	name := e.Name()
+
+	for {
+		err := e.Init()
+		if err == nil {
+			break
+		}
+		logger.WithError(err).Errorf("error initializing %v-exporter", name)
+		time.Sleep(errorInterval)
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		t0 := time.Now()
+		runID := logging.NewRunID()
+		runLogger := logger.WithFields(logrus.Fields{"exporter": name, "run": runID})
+		if rl, ok := e.(ProtocolExporterRunLogger); ok {
+			rl.SetLogger(runLogger)
+		}
+		ctx, span := tracing.StartSpan(context.Background(), "exporter.run", attribute.String("exporter", name), attribute.String("run", runID))
+		if rc, ok := e.(ProtocolExporterRunContext); ok {
+			rc.SetRunContext(ctx)
+		}
+		err := e.Update()
+		if err != nil {
+			tracing.EndSpan(span, err)
+			runLogger.WithError(err).Errorf("error updating %v-data", name)
+			time.Sleep(errorInterval)
+			continue
+		}
+		err = e.Save()
+		tracing.EndSpan(span, err)
+		if err != nil {
+			runLogger.WithError(err).Errorf("error saving %v-data", name)
+			time.Sleep(errorInterval)
+			continue
+		}
+
+		metrics.TaskDuration.WithLabelValues(name + "_exporter").Observe(time.Since(t0).Seconds())
+		runLogger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("exported %v-data", name)
+		updateExporterStatus(name)
+		if _, configured := protocolExporterConfigs()[name]; configured {
+			if next := protocolExporterInterval(name); next != interval {
+				runLogger.Infof("changing update-interval of %v-exporter from %v to %v", name, interval, next)
+				interval = next
+				t.Reset(interval)
+			}
+		}
+		<-t.C
+	}
+}
@@ -11,7 +11,7 @@ func UpdatePubkeyTag() {
 	for true {
 		start := time.Now()
 
-		tx, err := db.DB.Beginx()
+		tx, err := db.ExporterDB.Beginx()
 		if err != nil {
 			logger.WithError(err).Error("Error connecting to DB")
 			// return err
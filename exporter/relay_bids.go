@@ -0,0 +1,253 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// relayBidsBatchSize is the number of slots exported per run
+	relayBidsBatchSize = 32
+	// relayBidsHeadDistance is the number of slots behind the latest exported slot the exporter stays, the canonical
+	// block of a slot and the bid traces of the relays are final by then
+	relayBidsHeadDistance = 64
+	// relayBidCutoff is the time after the start of the slot a bid has to be received by the relay to be considered,
+	// later bids cannot have been chosen by the proposer
+	relayBidCutoff = time.Second
+)
+
+var relayClient = &http.Client{Timeout: time.Second * 10}
+
+// relayBidTrace is a bid trace of the data api of a mev-boost relay, a received bid of a builder or a payload delivered
+// to the proposer
+type relayBidTrace struct {
+	Slot          uint64        `json:"slot,string"`
+	BlockHash     hexutil.Bytes `json:"block_hash"`
+	BuilderPubkey hexutil.Bytes `json:"builder_pubkey"`
+	Value         types.Wei     `json:"value"`
+	TimestampMs   uint64        `json:"timestamp_ms,string"`
+}
+
+// relaySlotTraces are the bid traces of a slot of a relay
+type relaySlotTraces struct {
+	relay     string
+	bids      []*relayBidTrace
+	delivered []*relayBidTrace
+	err       error
+}
+
+// relayBidsExporter stores the bids of the builders and the payloads delivered to the proposers of the configured relays
+// per slot and computes the auction of every slot
+func relayBidsExporter() {
+	if len(utils.Config.Indexer.RelayBids.Relays) == 0 {
+		logger.Warnf("relay bids exporter is enabled but no relays are configured")
+		return
+	}
+
+	for {
+		count, err := exportRelayBids()
+		if err != nil {
+			logger.WithError(err).Errorf("error exporting relay bids")
+		}
+		if err != nil || count < relayBidsBatchSize {
+			time.Sleep(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
+		}
+	}
+}
+
+func exportRelayBids() (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("export_relay_bids").Observe(time.Since(start).Seconds())
+	}()
+
+	startSlot := utils.Config.Indexer.RelayBids.StartSlot
+	lastSlot, exists, err := db.GetLastRelayAuctionSlot()
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving last exported relay auction: %w", err)
+	}
+	if exists && lastSlot >= startSlot {
+		startSlot = lastSlot + 1
+	}
+	lastExportedSlot, err := db.GetLastExportedSlot()
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving last exported slot: %w", err)
+	}
+	if lastExportedSlot < relayBidsHeadDistance || startSlot > lastExportedSlot-relayBidsHeadDistance {
+		return 0, nil
+	}
+	endSlot := lastExportedSlot - relayBidsHeadDistance
+	if endSlot-startSlot+1 > relayBidsBatchSize {
+		endSlot = startSlot + relayBidsBatchSize - 1
+	}
+
+	for slot := startSlot; slot <= endSlot; slot++ {
+		err := exportRelayAuction(slot)
+		if err != nil {
+			return 0, fmt.Errorf("error exporting relay auction of slot %v: %w", slot, err)
+		}
+	}
+	count := int(endSlot - startSlot + 1)
+	logger.WithFields(logrus.Fields{"slots": count, "last": endSlot, "duration": time.Since(start)}).Infof("exported relay bids")
+	return count, nil
+}
+
+// exportRelayAuction fetches the bid traces of the slot from all relays and stores the auction. The slot is not stored
+// if a relay cannot be reached or its block has not been exported yet, so it is retried by the next run.
+func exportRelayAuction(slot uint64) error {
+	execBlockHash, exported, err := db.GetSlotExecBlockHash(slot)
+	if err != nil {
+		return fmt.Errorf("error retrieving execution block hash: %w", err)
+	}
+	if !exported {
+		return fmt.Errorf("block of slot %v has not been exported yet", slot)
+	}
+
+	relays := utils.Config.Indexer.RelayBids.Relays
+	traces := make([]*relaySlotTraces, len(relays))
+	wg := sync.WaitGroup{}
+	for i, relay := range relays {
+		wg.Add(1)
+		go func(i int, relay string) {
+			defer wg.Done()
+			traces[i] = getRelaySlotTraces(relay, slot)
+		}(i, relay)
+	}
+	wg.Wait()
+
+	auction := &types.RelayAuction{Slot: slot}
+	bids := []*types.RelayBid{}
+	cutoff := uint64(utils.SlotToTime(slot).Add(relayBidCutoff).UnixNano() / 1e6)
+	for _, t := range traces {
+		if t.err != nil {
+			return fmt.Errorf("error retrieving bid traces of relay %v: %w", t.relay, t.err)
+		}
+	}
+	for _, t := range traces {
+		for _, d := range t.delivered {
+			if execBlockHash == nil || !bytes.Equal(d.BlockHash, execBlockHash) || auction.WinningBid != nil {
+				continue
+			}
+			relay := t.relay
+			value := d.Value
+			auction.Relay = &relay
+			auction.BuilderPubkey = d.BuilderPubkey
+			auction.BlockHash = d.BlockHash
+			auction.WinningBid = &value
+		}
+		bids = append(bids, highestRelayBids(t.relay, slot, t.bids, cutoff)...)
+	}
+
+	// the highest bid of every builder over all relays
+	builderBids := map[string]types.Wei{}
+	for _, b := range bids {
+		auction.Bids += b.Bids
+		key := string(b.BuilderPubkey)
+		if v, ok := builderBids[key]; !ok || b.Value.Int().Cmp(v.Int()) > 0 {
+			builderBids[key] = b.Value
+		}
+	}
+	if auction.BuilderPubkey != nil {
+		// the winning payload might have been submitted after the cutoff
+		key := string(auction.BuilderPubkey)
+		if v, ok := builderBids[key]; !ok || auction.WinningBid.Int().Cmp(v.Int()) > 0 {
+			builderBids[key] = *auction.WinningBid
+		}
+	}
+	auction.Builders = uint64(len(builderBids))
+
+	values := make([]*big.Int, 0, len(builderBids))
+	var second *big.Int
+	for builder, v := range builderBids {
+		values = append(values, v.Int())
+		if builder == string(auction.BuilderPubkey) {
+			continue
+		}
+		if second == nil || v.Int().Cmp(second) > 0 {
+			second = v.Int()
+		}
+	}
+	if len(values) > 0 {
+		sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+		top := types.NewWei(values[len(values)-1])
+		auction.TopBid = &top
+		median := values[len(values)/2]
+		if len(values)%2 == 0 {
+			median = new(big.Int).Add(values[len(values)/2-1], values[len(values)/2])
+			median.Div(median, big.NewInt(2))
+		}
+		medianBid := types.NewWei(median)
+		auction.MedianBid = &medianBid
+	}
+	if second != nil {
+		secondBid := types.NewWei(second)
+		auction.SecondBid = &secondBid
+	}
+
+	return db.SaveRelayAuction(auction, bids)
+}
+
+// highestRelayBids returns the highest bid of every builder of the slot received by the relay before the cutoff (unix
+// milliseconds), bids without a timestamp are considered in time
+func highestRelayBids(relay string, slot uint64, traces []*relayBidTrace, cutoff uint64) []*types.RelayBid {
+	builderBids := map[string]*types.RelayBid{}
+	for _, t := range traces {
+		if t.Slot != slot || (t.TimestampMs != 0 && t.TimestampMs > cutoff) {
+			continue
+		}
+		b, ok := builderBids[string(t.BuilderPubkey)]
+		if !ok {
+			b = &types.RelayBid{Slot: slot, Relay: relay, BuilderPubkey: t.BuilderPubkey}
+			builderBids[string(t.BuilderPubkey)] = b
+		}
+		b.Bids++
+		if b.BlockHash == nil || t.Value.Int().Cmp(b.Value.Int()) > 0 {
+			b.BlockHash = t.BlockHash
+			b.Value = t.Value
+		}
+	}
+
+	bids := make([]*types.RelayBid, 0, len(builderBids))
+	for _, b := range builderBids {
+		bids = append(bids, b)
+	}
+	return bids
+}
+
+// getRelaySlotTraces retrieves the received bids and the delivered payloads of the slot from the data api of the relay
+func getRelaySlotTraces(relay string, slot uint64) *relaySlotTraces {
+	t := &relaySlotTraces{relay: blockPropagationNodeName(relay)}
+	base := strings.TrimSuffix(relay, "/")
+	t.err = getRelayData(fmt.Sprintf("%v/relay/v1/data/bidtraces/builder_blocks_received?slot=%v", base, slot), &t.bids)
+	if t.err != nil {
+		return t
+	}
+	t.err = getRelayData(fmt.Sprintf("%v/relay/v1/data/bidtraces/proposer_payload_delivered?slot=%v", base, slot), &t.delivered)
+	return t
+}
+
+func getRelayData(url string, data interface{}) error {
+	resp, err := relayClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(data)
+}
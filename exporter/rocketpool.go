@@ -1,14 +1,20 @@
 package exporter
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
-	"strings"
 	"time"
 
 	"eth2-exporter/db"
+	"eth2-exporter/rpc"
+	"eth2-exporter/tracing"
+	"eth2-exporter/types"
 	"eth2-exporter/utils"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	gethRPC "github.com/ethereum/go-ethereum/rpc"
@@ -22,37 +28,96 @@ import (
 	"github.com/rocket-pool/rocketpool-go/rocketpool"
 	rpTypes "github.com/rocket-pool/rocketpool-go/types"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
 var rpEth1RPRCClient *gethRPC.Client
 var rpEth1Client *ethclient.Client
 
-func rocketpoolExporter() {
+func init() {
+	RegisterProtocolExporter("rocketpool", newRocketpoolProtocolExporter)
+}
+
+// newRocketpoolProtocolExporter creates the rocketpool exporter of the RocketStorage contract in the storageContractAddress
+// param, it defaults to rocketpoolExporter.storageContractAddress
+func newRocketpoolProtocolExporter(cfg types.ProtocolExporterConfig) (ProtocolExporter, error) {
+	address := cfg.Params["storageContractAddress"]
+	if address == "" {
+		address = utils.Config.RocketpoolExporter.StorageContractAddress
+	}
+	var err error
+	rpEth1RPRCClient, err = rpc.DialEth1(append([]string{utils.Config.Indexer.Eth1Endpoint}, utils.Config.Indexer.Eth1FallbackEndpoints...))
+	if err != nil {
+		return nil, err
+	}
+	rpEth1Client = ethclient.NewClient(rpEth1RPRCClient)
+	return NewRocketpoolExporter(rpEth1Client, address, db.ExporterDB)
+}
+
+// RunRocketpoolExporterOnce runs a single update of the rocketpool exporter instead of the update loop. In dry-run the
+// database is neither read nor written, the rows that would be upserted are printed as json to out instead. The
+// history, rETH rate, oDAO submission and fee recipient steps depend on the previously exported state and are skipped
+// in dry-run.
+func RunRocketpoolExporterOnce(dryRun bool, out io.Writer) error {
 	var err error
-	rpEth1RPRCClient, err = gethRPC.Dial(utils.Config.Indexer.Eth1Endpoint)
+	rpEth1RPRCClient, err = rpc.DialEth1(append([]string{utils.Config.Indexer.Eth1Endpoint}, utils.Config.Indexer.Eth1FallbackEndpoints...))
 	if err != nil {
-		logger.Fatal(err)
+		return err
 	}
 	rpEth1Client = ethclient.NewClient(rpEth1RPRCClient)
-	rpExporter, err := NewRocketpoolExporter(rpEth1Client, utils.Config.RocketpoolExporter.StorageContractAddress, db.DB)
+	var exporterDB *sqlx.DB
+	if !dryRun {
+		exporterDB = db.ExporterDB
+	}
+	rpExporter, err := NewRocketpoolExporter(rpEth1Client, utils.Config.RocketpoolExporter.StorageContractAddress, exporterDB)
+	if err != nil {
+		return err
+	}
+	rpExporter.DryRun = dryRun
+	rpExporter.DryRunOutput = out
+
+	if !dryRun {
+		err = rpExporter.Init()
+		if err != nil {
+			return fmt.Errorf("error initializing rocketpool exporter: %w", err)
+		}
+	}
+	err = rpExporter.Update()
+	if err != nil {
+		return fmt.Errorf("error updating rocketpool exporter: %w", err)
+	}
+	err = rpExporter.Save()
 	if err != nil {
-		logger.Fatal(err)
+		return fmt.Errorf("error saving rocketpool exporter: %w", err)
 	}
-	rpExporter.Run()
+	return nil
 }
 
 type RocketpoolExporter struct {
-	Eth1Client          *ethclient.Client
-	API                 *rocketpool.RocketPool
-	DB                  *sqlx.DB
-	UpdateInterval      time.Duration
-	MinipoolsByAddress  map[string]*RocketpoolMinipool
-	NodesByAddress      map[string]*RocketpoolNode
-	DAOProposalsByID    map[uint64]*RocketpoolDAOProposal
-	DAOMembersByAddress map[string]*RocketpoolDAOMember
+	Eth1Client           *ethclient.Client
+	API                  *rocketpool.RocketPool
+	DB                   *sqlx.DB
+	HistoryStartBlock    uint64
+	HistoryBlockInterval uint64
+	HistoryLastBlock     uint64
+	RethLastTs           time.Time
+	ODAOLastBlock        uint64
+	FeeRecipientLastSlot uint64
+	MinipoolsByAddress   map[string]*RocketpoolMinipool
+	NodesByAddress       map[string]*RocketpoolNode
+	DAOProposalsByID     map[uint64]*RocketpoolDAOProposal
+	DAOMembersByAddress  map[string]*RocketpoolDAOMember
+	// DryRun prints the rows that would be upserted to DryRunOutput instead of writing them, see RunRocketpoolExporterOnce
+	DryRun       bool
+	DryRunOutput io.Writer
+	logger       *logrus.Entry
+	ctx          context.Context
 }
 
+// rpContractCallBatchSize is the number of minipools or nodes whose contract calls are traced as one span
+const rpContractCallBatchSize = 100
+
 func NewRocketpoolExporter(eth1Client *ethclient.Client, storageContractAddressHex string, db *sqlx.DB) (*RocketpoolExporter, error) {
 	rpe := &RocketpoolExporter{}
 	rp, err := rocketpool.NewRocketPool(eth1Client, common.HexToAddress(storageContractAddressHex))
@@ -62,7 +127,13 @@ func NewRocketpoolExporter(eth1Client *ethclient.Client, storageContractAddressH
 	rpe.Eth1Client = eth1Client
 	rpe.API = rp
 	rpe.DB = db
-	rpe.UpdateInterval = time.Second * 60
+	rpe.logger = logger
+	rpe.ctx = context.Background()
+	rpe.HistoryStartBlock = utils.Config.RocketpoolExporter.StorageContractFirstBlock
+	rpe.HistoryBlockInterval = utils.Config.RocketpoolExporter.HistoryBlockInterval
+	if rpe.HistoryBlockInterval == 0 {
+		rpe.HistoryBlockInterval = 7200
+	}
 	rpe.MinipoolsByAddress = map[string]*RocketpoolMinipool{}
 	rpe.NodesByAddress = map[string]*RocketpoolNode{}
 	rpe.DAOProposalsByID = map[uint64]*RocketpoolDAOProposal{}
@@ -93,75 +164,133 @@ func (rp *RocketpoolExporter) Init() error {
 
 func (rp *RocketpoolExporter) InitMinipools() error {
 	dbRes := []RocketpoolMinipool{}
-	err := rp.DB.Select(&dbRes, `select * from rocketpool_minipools`)
+	err := rp.DB.Select(&dbRes, `
+		select address, pubkey, node_address, node_fee, deposit_type, status, coalesce(status_time, to_timestamp(0)) as status_time, coalesce(node_deposit_balance, 0)::text as node_deposit_balance
+		from rocketpool_minipools
+		where rocketpool_storage_address = $1`, rp.API.RocketStorageContract.Address.Bytes())
 	if err != nil {
 		return err
 	}
-	for _, val := range dbRes {
-		rp.MinipoolsByAddress[fmt.Sprintf("%x", val.Address)] = &val
+	for i := range dbRes {
+		rp.MinipoolsByAddress[common.BytesToAddress(dbRes[i].Address).Hex()] = &dbRes[i]
 	}
 	return nil
 }
 
 func (rp *RocketpoolExporter) InitNodes() error {
-	dbRes := []RocketpoolNode{}
-	err := rp.DB.Select(&dbRes, `select * from rocketpool_nodes`)
+	dbRes := []struct {
+		Address          []byte  `db:"address"`
+		TimezoneLocation string  `db:"timezone_location"`
+		RPLStake         string  `db:"rpl_stake"`
+		MinRPLStake      string  `db:"min_rpl_stake"`
+		MaxRPLStake      string  `db:"max_rpl_stake"`
+		FeeDistributor   []byte  `db:"fee_distributor_address"`
+		SmoothingPool    bool    `db:"smoothing_pool_opted_in"`
+		DepositCredit    *string `db:"deposit_credit"`
+		ETHMatched       *string `db:"eth_matched"`
+		ETHProvided      *string `db:"eth_provided"`
+		ETHMatchedLimit  *string `db:"eth_matched_limit"`
+	}{}
+	err := rp.DB.Select(&dbRes, `
+		select address, timezone_location, rpl_stake::text, min_rpl_stake::text, max_rpl_stake::text, fee_distributor_address, smoothing_pool_opted_in,
+			deposit_credit::text, eth_matched::text, eth_provided::text, eth_matched_limit::text
+		from rocketpool_nodes
+		where rocketpool_storage_address = $1`, rp.API.RocketStorageContract.Address.Bytes())
 	if err != nil {
 		return err
 	}
 	for _, val := range dbRes {
-		rp.NodesByAddress[fmt.Sprintf("%x", val.Address)] = &val
+		node := &RocketpoolNode{
+			Address:               val.Address,
+			TimezoneLocation:      val.TimezoneLocation,
+			RPLStake:              new(big.Int),
+			MinRPLStake:           new(big.Int),
+			MaxRPLStake:           new(big.Int),
+			FeeDistributorAddress: val.FeeDistributor,
+			SmoothingPoolOptedIn:  val.SmoothingPool,
+			DepositCredit:         parseNullBigInt(val.DepositCredit),
+			ETHMatched:            parseNullBigInt(val.ETHMatched),
+			ETHProvided:           parseNullBigInt(val.ETHProvided),
+			ETHMatchedLimit:       parseNullBigInt(val.ETHMatchedLimit),
+		}
+		node.RPLStake.SetString(val.RPLStake, 10)
+		node.MinRPLStake.SetString(val.MinRPLStake, 10)
+		node.MaxRPLStake.SetString(val.MaxRPLStake, 10)
+		rp.NodesByAddress[common.BytesToAddress(val.Address).Hex()] = node
 	}
 	return nil
 }
 
 func (rp *RocketpoolExporter) InitDAOProposals() error {
 	dbRes := []RocketpoolDAOProposal{}
-	err := rp.DB.Select(&dbRes, `select * from rocketpool_proposals`)
+	err := rp.DB.Select(&dbRes, `
+		select
+			id, dao, proposer_address, message,
+			coalesce(created_time, to_timestamp(0)) as created_time,
+			coalesce(start_time, to_timestamp(0)) as start_time,
+			coalesce(end_time, to_timestamp(0)) as end_time,
+			coalesce(expiry_time, to_timestamp(0)) as expiry_time,
+			votes_required, votes_for, votes_against, member_voted, member_supported, is_cancelled, is_executed, payload, payload_decoded, state
+		from rocketpool_dao_proposals
+		where rocketpool_storage_address = $1`, rp.API.RocketStorageContract.Address.Bytes())
 	if err != nil {
 		return err
 	}
-	for _, val := range dbRes {
-		rp.DAOProposalsByID[val.ID] = &val
+	for i := range dbRes {
+		rp.DAOProposalsByID[dbRes[i].ID] = &dbRes[i]
 	}
 	return nil
 }
 
 func (rp *RocketpoolExporter) InitDAOMembers() error {
-	dbRes := []RocketpoolDAOMember{}
-	err := rp.DB.Select(&dbRes, `select * from rocketpool_dao_members`)
+	dbRes := []struct {
+		Address                []byte    `db:"address"`
+		ID                     string    `db:"id"`
+		URL                    string    `db:"url"`
+		JoinedTime             time.Time `db:"joined_time"`
+		LastProposalTime       time.Time `db:"last_proposal_time"`
+		RPLBondAmount          string    `db:"rpl_bond_amount"`
+		UnbondedValidatorCount uint64    `db:"unbonded_validator_count"`
+	}{}
+	err := rp.DB.Select(&dbRes, `
+		select
+			address, id, url,
+			coalesce(joined_time, to_timestamp(0)) as joined_time,
+			coalesce(last_proposal_time, to_timestamp(0)) as last_proposal_time,
+			rpl_bond_amount::text, unbonded_validator_count
+		from rocketpool_dao_members
+		where rocketpool_storage_address = $1`, rp.API.RocketStorageContract.Address.Bytes())
 	if err != nil {
 		return err
 	}
 	for _, val := range dbRes {
-		rp.DAOMembersByAddress[fmt.Sprintf("%x", val.Address)] = &val
+		member := &RocketpoolDAOMember{
+			Address:                val.Address,
+			ID:                     val.ID,
+			URL:                    val.URL,
+			JoinedTime:             val.JoinedTime,
+			LastProposalTime:       val.LastProposalTime,
+			RPLBondAmount:          new(big.Int),
+			UnbondedValidatorCount: val.UnbondedValidatorCount,
+		}
+		member.RPLBondAmount.SetString(val.RPLBondAmount, 10)
+		rp.DAOMembersByAddress[common.BytesToAddress(val.Address).Hex()] = member
 	}
 	return nil
 }
 
-func (rp *RocketpoolExporter) Run() error {
-	errorInterval := time.Second * 10
-	t := time.NewTicker(rp.UpdateInterval)
-	defer t.Stop()
-	for {
-		t0 := time.Now()
-		var err error
-		err = rp.Update()
-		if err != nil {
-			logger.WithError(err).Errorf("error updating rocketpool-data")
-			time.Sleep(errorInterval)
-			continue
-		}
-		err = rp.Save()
-		if err != nil {
-			logger.WithError(err).Errorf("error saving rocketpool-data")
-			time.Sleep(errorInterval)
-			continue
-		}
+func (rp *RocketpoolExporter) Name() string {
+	return "rocketpool"
+}
 
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("exported rocketpool-data")
-		<-t.C
-	}
+// SetLogger sets the logger of the current run, see RunProtocolExporter
+func (rp *RocketpoolExporter) SetLogger(l *logrus.Entry) {
+	rp.logger = l
+}
+
+// SetRunContext sets the context carrying the span of the current run, see RunProtocolExporter
+func (rp *RocketpoolExporter) SetRunContext(ctx context.Context) {
+	rp.ctx = ctx
 }
 
 func (rp *RocketpoolExporter) Update() error {
@@ -195,23 +324,62 @@ func (rp *RocketpoolExporter) Save() error {
 	if err != nil {
 		return err
 	}
+	if rp.DryRun {
+		return nil
+	}
+	err = rp.DetectFeeRecipientViolations()
+	if err != nil {
+		rp.logger.WithError(err).Errorf("error detecting rocketpool fee recipient violations")
+	}
+	// the history is best-effort as it depends on an archive node, failing to update it should not block the regular export
+	err = rp.UpdateHistory()
+	if err != nil {
+		rp.logger.WithError(err).Errorf("error updating rocketpool-history")
+	}
+	err = rp.SaveRETHRate()
+	if err != nil {
+		rp.logger.WithError(err).Errorf("error saving rocketpool rETH rate")
+	}
+	err = rp.UpdateODAOSubmissions()
+	if err != nil {
+		rp.logger.WithError(err).Errorf("error updating rocketpool oDAO submissions")
+	}
 	return nil
 }
 
-func (rp *RocketpoolExporter) UpdateMinipools() error {
+func (rp *RocketpoolExporter) UpdateMinipools() (err error) {
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-minipools")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-minipools")
 	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.update_minipools")
+	defer func() { tracing.EndSpan(span, err) }()
 
 	minipoolAddresses, err := minipool.GetMinipoolAddresses(rp.API, nil)
 	if err != nil {
 		return err
 	}
+	span.SetAttributes(attribute.Int("minipools", len(minipoolAddresses)))
+	for i := 0; i < len(minipoolAddresses); i += rpContractCallBatchSize {
+		end := i + rpContractCallBatchSize
+		if end > len(minipoolAddresses) {
+			end = len(minipoolAddresses)
+		}
+		_, batchSpan := tracing.StartSpan(ctx, "rocketpool.update_minipools.batch", attribute.Int("offset", i), attribute.Int("size", end-i))
+		err = rp.updateMinipoolsBatch(minipoolAddresses[i:end])
+		tracing.EndSpan(batchSpan, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rp *RocketpoolExporter) updateMinipoolsBatch(minipoolAddresses []common.Address) error {
 	for _, a := range minipoolAddresses {
 		addrHex := a.Hex()
 		if mp, exists := rp.MinipoolsByAddress[addrHex]; exists {
-			err = mp.Update(rp.API)
+			err := mp.Update(rp.API)
 			if err != nil {
 				return err
 			}
@@ -226,39 +394,127 @@ func (rp *RocketpoolExporter) UpdateMinipools() error {
 	return nil
 }
 
-func (rp *RocketpoolExporter) UpdateNodes() error {
+func (rp *RocketpoolExporter) UpdateNodes() (err error) {
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-nodes")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-nodes")
 	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.update_nodes")
+	defer func() { tracing.EndSpan(span, err) }()
 
 	nodeAddresses, err := node.GetNodeAddresses(rp.API, nil)
 	if err != nil {
 		return err
 	}
+	span.SetAttributes(attribute.Int("nodes", len(nodeAddresses)))
+	feeContracts := rp.getFeeContracts()
+	depositContracts := rp.getDepositContracts()
+	for i := 0; i < len(nodeAddresses); i += rpContractCallBatchSize {
+		end := i + rpContractCallBatchSize
+		if end > len(nodeAddresses) {
+			end = len(nodeAddresses)
+		}
+		_, batchSpan := tracing.StartSpan(ctx, "rocketpool.update_nodes.batch", attribute.Int("offset", i), attribute.Int("size", end-i))
+		err = rp.updateNodesBatch(nodeAddresses[i:end], feeContracts, depositContracts)
+		tracing.EndSpan(batchSpan, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rp *RocketpoolExporter) updateNodesBatch(nodeAddresses []common.Address, feeContracts *rpFeeContracts, depositContracts *rpDepositContracts) error {
 	for _, a := range nodeAddresses {
 		addrHex := a.Hex()
-		if node, exists := rp.NodesByAddress[addrHex]; exists {
-			err = node.Update(rp.API)
+		node, exists := rp.NodesByAddress[addrHex]
+		if exists {
+			err := node.Update(rp.API, nil)
 			if err != nil {
 				return err
 			}
-			continue
+		} else {
+			var err error
+			node, err = NewRocketpoolNode(rp.API, a.Bytes())
+			if err != nil {
+				return err
+			}
+			rp.NodesByAddress[addrHex] = node
 		}
-		node, err := NewRocketpoolNode(rp.API, a.Bytes())
-		if err != nil {
-			return err
+		if feeContracts != nil {
+			err := node.UpdateFeeRecipient(feeContracts)
+			if err != nil {
+				return err
+			}
+		}
+		if depositContracts != nil {
+			err := node.UpdateDepositCredit(depositContracts)
+			if err != nil {
+				return err
+			}
 		}
-		rp.NodesByAddress[addrHex] = node
 	}
 	return nil
 }
 
-func (rp *RocketpoolExporter) UpdateDAOProposals() error {
+// rpFeeContracts are the contracts the fee distributor and the smoothing pool registration of the nodes are read from
+type rpFeeContracts struct {
+	distributorFactory *rocketpool.Contract
+	nodeManager        *rocketpool.Contract
+}
+
+// getFeeContracts returns the contracts of the fee distributors and the smoothing pool, nil is returned before the
+// redstone upgrade deployed them
+func (rp *RocketpoolExporter) getFeeContracts() *rpFeeContracts {
+	distributorFactory, err := rp.API.GetContract("rocketNodeDistributorFactory")
+	if err != nil {
+		rp.logger.WithError(err).Debugf("rocketpool fee distributors not available")
+		return nil
+	}
+	nodeManager, err := rp.API.GetContract("rocketNodeManager")
+	if err != nil {
+		rp.logger.WithError(err).Debugf("rocketpool node manager not available")
+		return nil
+	}
+	return &rpFeeContracts{distributorFactory: distributorFactory, nodeManager: nodeManager}
+}
+
+// rpDepositContracts are the contracts the deposit credit and the matched ETH of the nodes are read from
+type rpDepositContracts struct {
+	nodeDeposit *rocketpool.Contract
+	nodeStaking *rocketpool.Contract
+}
+
+// getDepositContracts returns the contracts of the deposit credit and the matched ETH, nil is returned before the atlas
+// upgrade added the methods
+func (rp *RocketpoolExporter) getDepositContracts() *rpDepositContracts {
+	nodeDeposit, err := rp.API.GetContract("rocketNodeDeposit")
+	if err != nil {
+		rp.logger.WithError(err).Debugf("rocketpool node deposit not available")
+		return nil
+	}
+	nodeStaking, err := rp.API.GetContract("rocketNodeStaking")
+	if err != nil {
+		rp.logger.WithError(err).Debugf("rocketpool node staking not available")
+		return nil
+	}
+	// the contracts exist before the atlas upgrade, but without the methods
+	matched := new(*big.Int)
+	err = nodeStaking.Call(nil, matched, "getNodeETHMatched", common.Address{})
+	if err != nil {
+		rp.logger.WithError(err).Debugf("rocketpool matched ETH not available")
+		return nil
+	}
+	return &rpDepositContracts{nodeDeposit: nodeDeposit, nodeStaking: nodeStaking}
+}
+
+func (rp *RocketpoolExporter) UpdateDAOProposals() (err error) {
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-dao-proposals")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-dao-proposals")
 	}(t0)
+	_, span := tracing.StartSpan(rp.ctx, "rocketpool.update_dao_proposals")
+	defer func() { tracing.EndSpan(span, err) }()
 
 	pc, err := rpDAO.GetProposalCount(rp.API, nil)
 	if err != nil {
@@ -269,16 +525,18 @@ func (rp *RocketpoolExporter) UpdateDAOProposals() error {
 		if err != nil {
 			return err
 		}
-		rp.DAOProposalsByID[i] = p
+		rp.DAOProposalsByID[p.ID] = p
 	}
 	return nil
 }
 
-func (rp *RocketpoolExporter) UpdateDAOMembers() error {
+func (rp *RocketpoolExporter) UpdateDAOMembers() (err error) {
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-dao-members")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-dao-members")
 	}(t0)
+	_, span := tracing.StartSpan(rp.ctx, "rocketpool.update_dao_members")
+	defer func() { tracing.EndSpan(span, err) }()
 
 	members, err := rpDAOTrustedNode.GetMembers(rp.API, nil)
 	if err != nil {
@@ -303,319 +561,235 @@ func (rp *RocketpoolExporter) UpdateDAOMembers() error {
 	return nil
 }
 
-func (rp *RocketpoolExporter) SaveMinipools() error {
+func (rp *RocketpoolExporter) SaveMinipools() (err error) {
 	if len(rp.MinipoolsByAddress) == 0 {
 		return nil
 	}
 
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-minipools")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-minipools")
 	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.save_minipools")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	data := make([]*RocketpoolMinipool, len(rp.MinipoolsByAddress))
-	i := 0
-	for _, mp := range rp.MinipoolsByAddress {
-		data[i] = mp
-		i++
-	}
-
-	tx, err := db.DB.Beginx()
-	if err != nil {
-		return err
+	rows := make([][]interface{}, 0, len(rp.MinipoolsByAddress))
+	for _, d := range rp.MinipoolsByAddress {
+		rows = append(rows, []interface{}{rp.API.RocketStorageContract.Address.Bytes(), d.Address, d.Pubkey, d.Status, d.StatusTime, d.NodeAddress, d.NodeFee, d.DepositType, d.NodeDepositBalance})
 	}
-	defer tx.Rollback()
-
-	nArgs := 8
-	valueStringsArr := make([]string, nArgs)
-	for i := range valueStringsArr {
-		valueStringsArr[i] = "$%d"
-	}
-	valueStringsTpl := "(" + strings.Join(valueStringsArr, ",") + ")"
-	valueStringsArgs := make([]interface{}, nArgs)
-
-	batchSize := 1000
-	for b := 0; b < len(data); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(data) < end {
-			end = len(data)
-		}
-
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*nArgs)
-		for i, d := range data[start:end] {
-			for j := 0; j < nArgs; j++ {
-				valueStringsArgs[j] = i*nArgs + j + 1
-			}
-			valueStrings = append(valueStrings, fmt.Sprintf(valueStringsTpl, valueStringsArgs...))
-			valueArgs = append(valueArgs, rp.API.RocketStorageContract.Address.Bytes())
-			valueArgs = append(valueArgs, d.Address)
-			valueArgs = append(valueArgs, d.Pubkey)
-			valueArgs = append(valueArgs, d.Status)
-			valueArgs = append(valueArgs, d.StatusTime)
-			valueArgs = append(valueArgs, d.NodeAddress)
-			valueArgs = append(valueArgs, d.NodeFee)
-			valueArgs = append(valueArgs, d.DepositType)
-		}
-		stmt := fmt.Sprintf(`insert into rocketpool_minipools (rocketpool_storage_address, address, pubkey, status, status_time, node_address, node_fee, deposit_type) values %s on conflict (rocketpool_storage_address, address) do update set pubkey = excluded.pubkey, status = excluded.status, status_time = excluded.status_time, node_address = excluded.node_address, node_fee = excluded.node_fee, deposit_type = excluded.deposit_type`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return fmt.Errorf("error inserting into rocketpool_minipools: %w", err)
-		}
-	}
-
-	return tx.Commit()
+	return rp.batchUpsert(ctx, "rocketpool_minipools",
+		[]string{"rocketpool_storage_address", "address", "pubkey", "status", "status_time", "node_address", "node_fee", "deposit_type", "node_deposit_balance"},
+		[]string{"rocketpool_storage_address", "address"},
+		rows)
 }
 
-func (rp *RocketpoolExporter) SaveNodes() error {
+func (rp *RocketpoolExporter) SaveNodes() (err error) {
 	if len(rp.NodesByAddress) == 0 {
 		return nil
 	}
 
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-nodes")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-nodes")
 	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.save_nodes")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	rows := make([][]interface{}, 0, len(rp.NodesByAddress))
+	for _, d := range rp.NodesByAddress {
+		rows = append(rows, []interface{}{rp.API.RocketStorageContract.Address.Bytes(), d.Address, d.TimezoneLocation, d.RPLStake.String(), d.MinRPLStake.String(), d.MaxRPLStake.String(), d.FeeDistributorAddress, d.SmoothingPoolOptedIn,
+			nullBigIntString(d.DepositCredit), nullBigIntString(d.ETHMatched), nullBigIntString(d.ETHProvided), nullBigIntString(d.ETHMatchedLimit)})
+	}
+	return rp.batchUpsert(ctx, "rocketpool_nodes",
+		[]string{"rocketpool_storage_address", "address", "timezone_location", "rpl_stake", "min_rpl_stake", "max_rpl_stake", "fee_distributor_address", "smoothing_pool_opted_in",
+			"deposit_credit", "eth_matched", "eth_provided", "eth_matched_limit"},
+		[]string{"rocketpool_storage_address", "address"},
+		rows)
+}
 
-	data := make([]*RocketpoolNode, len(rp.NodesByAddress))
-	i := 0
-	for _, node := range rp.NodesByAddress {
-		data[i] = node
-		i++
-	}
-
-	tx, err := db.DB.Beginx()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	nArgs := 6
-	valueStringsArr := make([]string, nArgs)
-	for i := range valueStringsArr {
-		valueStringsArr[i] = "$%d"
+func (rp *RocketpoolExporter) SaveDAOProposals() (err error) {
+	if len(rp.DAOProposalsByID) == 0 {
+		return nil
 	}
-	valueStringsTpl := "(" + strings.Join(valueStringsArr, ",") + ")"
-	valueStringsArgs := make([]interface{}, nArgs)
 
-	batchSize := 1000
-	for b := 0; b < len(data); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(data) < end {
-			end = len(data)
-		}
+	t0 := time.Now()
+	defer func(t0 time.Time) {
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-dao-proposals")
+	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.save_dao_proposals")
+	defer func() { tracing.EndSpan(span, err) }()
 
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*nArgs)
-		for i, d := range data[start:end] {
-			for j := 0; j < nArgs; j++ {
-				valueStringsArgs[j] = i*nArgs + j + 1
-			}
-			valueStrings = append(valueStrings, fmt.Sprintf(valueStringsTpl, valueStringsArgs...))
-			valueArgs = append(valueArgs, rp.API.RocketStorageContract.Address.Bytes())
-			valueArgs = append(valueArgs, d.Address)
-			valueArgs = append(valueArgs, d.TimezoneLocation)
-			valueArgs = append(valueArgs, d.RPLStake.String())
-			valueArgs = append(valueArgs, d.MinRPLStake.String())
-			valueArgs = append(valueArgs, d.MaxRPLStake.String())
-		}
-		stmt := fmt.Sprintf(`insert into rocketpool_nodes (rocketpool_storage_address, address, timezone_location, rpl_stake, min_rpl_stake, max_rpl_stake) values %s on conflict (rocketpool_storage_address, address) do update set rpl_stake = excluded.rpl_stake, min_rpl_stake = excluded.min_rpl_stake, max_rpl_stake = excluded.max_rpl_stake`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return fmt.Errorf("error inserting into rocketpool_nodes: %w", err)
-		}
+	rows := make([][]interface{}, 0, len(rp.DAOProposalsByID))
+	for _, d := range rp.DAOProposalsByID {
+		rows = append(rows, []interface{}{rp.API.RocketStorageContract.Address.Bytes(), d.ID, d.DAO, d.ProposerAddress, d.Message, d.CreatedTime, d.StartTime, d.EndTime, d.ExpiryTime, d.VotesRequired, d.VotesFor, d.VotesAgainst, d.MemberVoted, d.MemberSupported, d.IsCancelled, d.IsExecuted, d.Payload, d.PayloadDecoded, d.State})
 	}
-
-	return tx.Commit()
+	return rp.batchUpsert(ctx, "rocketpool_dao_proposals",
+		[]string{"rocketpool_storage_address", "id", "dao", "proposer_address", "message", "created_time", "start_time", "end_time", "expiry_time", "votes_required", "votes_for", "votes_against", "member_voted", "member_supported", "is_cancelled", "is_executed", "payload", "payload_decoded", "state"},
+		[]string{"rocketpool_storage_address", "id"},
+		rows)
 }
 
-func (rp *RocketpoolExporter) SaveDAOProposals() error {
-	if len(rp.DAOProposalsByID) == 0 {
+func (rp *RocketpoolExporter) SaveDAOMembers() (err error) {
+	if len(rp.DAOMembersByAddress) == 0 {
 		return nil
 	}
 
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-dao-proposals")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-dao-members")
 	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.save_dao_members")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	data := make([]*RocketpoolDAOProposal, len(rp.DAOProposalsByID))
-	i := 0
-	for _, val := range rp.DAOProposalsByID {
-		data[i] = val
-		i++
+	rows := make([][]interface{}, 0, len(rp.DAOMembersByAddress))
+	for _, d := range rp.DAOMembersByAddress {
+		rows = append(rows, []interface{}{rp.API.RocketStorageContract.Address.Bytes(), d.Address, d.ID, d.URL, d.JoinedTime, d.LastProposalTime, d.RPLBondAmount.String(), d.UnbondedValidatorCount})
 	}
+	return rp.batchUpsert(ctx, "rocketpool_dao_members",
+		[]string{"rocketpool_storage_address", "address", "id", "url", "joined_time", "last_proposal_time", "rpl_bond_amount", "unbonded_validator_count"},
+		[]string{"rocketpool_storage_address", "address"},
+		rows)
+}
 
-	tx, err := db.DB.Beginx()
-	if err != nil {
-		return err
+func (rp *RocketpoolExporter) TagValidators() (err error) {
+	if len(rp.MinipoolsByAddress) == 0 {
+		return nil
 	}
-	defer tx.Rollback()
 
-	nArgs := 18
-	valueStringsArr := make([]string, nArgs)
-	for i := range valueStringsArr {
-		valueStringsArr[i] = "$%d"
-	}
-	valueStringsTpl := "(" + strings.Join(valueStringsArr, ",") + ")"
-	valueStringsArgs := make([]interface{}, nArgs)
+	t0 := time.Now()
+	defer func(t0 time.Time) {
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-validator-tags")
+	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.tag_validators")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	batchSize := 1000
-	for b := 0; b < len(data); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(data) < end {
-			end = len(data)
-		}
+	rows := make([][]interface{}, 0, len(rp.MinipoolsByAddress))
+	for _, d := range rp.MinipoolsByAddress {
+		rows = append(rows, []interface{}{d.Pubkey, "rocketpool"})
+	}
+	return rp.batchUpsert(ctx, "validator_tags", []string{"publickey", "tag"}, []string{"publickey", "tag"}, rows)
+}
 
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*nArgs)
-		for i, d := range data[start:end] {
-			for j := 0; j < nArgs; j++ {
-				valueStringsArgs[j] = i*nArgs + j + 1
+// batchUpsert upserts the rows into the table or prints them in dry-run
+func (rp *RocketpoolExporter) batchUpsert(ctx context.Context, table string, columns, conflictKeys []string, rows [][]interface{}) error {
+	if !rp.DryRun {
+		return db.BatchUpsertContext(ctx, table, columns, conflictKeys, rows)
+	}
+
+	out := struct {
+		Table        string                   `json:"table"`
+		ConflictKeys []string                 `json:"conflict_keys"`
+		Rows         []map[string]interface{} `json:"rows"`
+	}{Table: table, ConflictKeys: conflictKeys, Rows: make([]map[string]interface{}, 0, len(rows))}
+	for _, row := range rows {
+		values := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := row[i].([]byte); ok {
+				values[column] = fmt.Sprintf("0x%x", b)
+			} else {
+				values[column] = row[i]
 			}
-			valueStrings = append(valueStrings, fmt.Sprintf(valueStringsTpl, valueStringsArgs...))
-			valueArgs = append(valueArgs, rp.API.RocketStorageContract.Address.Bytes())
-			valueArgs = append(valueArgs, d.ID)
-			valueArgs = append(valueArgs, d.DAO)
-			valueArgs = append(valueArgs, d.ProposerAddress)
-			valueArgs = append(valueArgs, d.Message)
-			valueArgs = append(valueArgs, d.CreatedTime)
-			valueArgs = append(valueArgs, d.StartTime)
-			valueArgs = append(valueArgs, d.EndTime)
-			valueArgs = append(valueArgs, d.ExpiryTime)
-			valueArgs = append(valueArgs, d.VotesRequired)
-			valueArgs = append(valueArgs, d.VotesFor)
-			valueArgs = append(valueArgs, d.VotesAgainst)
-			valueArgs = append(valueArgs, d.MemberVoted)
-			valueArgs = append(valueArgs, d.MemberSupported)
-			valueArgs = append(valueArgs, d.IsCancelled)
-			valueArgs = append(valueArgs, d.IsExecuted)
-			valueArgs = append(valueArgs, d.Payload)
-			valueArgs = append(valueArgs, d.State)
-		}
-		stmt := fmt.Sprintf(`insert into rocketpool_dao_proposals (rocketpool_storage_address, id, dao, proposer_address, message, created_time, start_time, end_time, expiry_time, votes_required, votes_for, votes_against, member_voted, member_supported, is_cancelled, is_executed, payload, state) values %s on conflict (rocketpool_storage_address, id) do update set dao = excluded.dao, proposer_address = excluded.proposer_address, message = excluded.message, created_time = excluded.created_time, start_time = excluded.start_time, end_time = excluded.end_time, expiry_time = excluded.expiry_time, votes_required = excluded.votes_required, votes_for = excluded.votes_for, votes_against = excluded.votes_against, member_voted = excluded.member_voted, member_supported = excluded.member_supported, is_cancelled = excluded.is_cancelled, is_executed = excluded.is_executed, payload = excluded.payload, state = excluded.state`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return fmt.Errorf("error inserting into rocketpool_dao_proposals: %w", err)
 		}
+		out.Rows = append(out.Rows, values)
 	}
-
-	return tx.Commit()
+	enc := json.NewEncoder(rp.DryRunOutput)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
 }
 
-func (rp *RocketpoolExporter) SaveDAOMembers() error {
-	if len(rp.DAOMembersByAddress) == 0 {
-		return nil
-	}
-
+// UpdateHistory stores a snapshot of the rpl stake and minipool count of all nodes every HistoryBlockInterval eth1-blocks.
+// Missing snapshots since HistoryStartBlock are backfilled by querying the contracts at the historical block, which requires an archive node.
+func (rp *RocketpoolExporter) UpdateHistory() (err error) {
 	t0 := time.Now()
 	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-dao-members")
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Infof("updated rocketpool-history")
 	}(t0)
+	_, span := tracing.StartSpan(rp.ctx, "rocketpool.update_history")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	data := make([]*RocketpoolDAOMember, len(rp.DAOMembersByAddress))
-	i := 0
-	for _, val := range rp.DAOMembersByAddress {
-		data[i] = val
-		i++
+	// blocks without any nodes do not produce rows, so the progress is also kept in memory to skip them on the next run
+	if rp.HistoryLastBlock == 0 {
+		err := rp.DB.Get(&rp.HistoryLastBlock, `select coalesce(max(eth1_block), 0) from rocketpool_nodes_history where rocketpool_storage_address = $1`, rp.API.RocketStorageContract.Address.Bytes())
+		if err != nil {
+			return err
+		}
 	}
-
-	tx, err := db.DB.Beginx()
+	header, err := rp.Eth1Client.HeaderByNumber(context.Background(), nil)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	latestBlock := header.Number.Uint64()
 
-	nArgs := 8
-	valueStringsArr := make([]string, nArgs)
-	for i := range valueStringsArr {
-		valueStringsArr[i] = "$%d"
-	}
-	valueStringsTpl := "(" + strings.Join(valueStringsArr, ",") + ")"
-	valueStringsArgs := make([]interface{}, nArgs)
-
-	batchSize := 1000
-	for b := 0; b < len(data); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(data) < end {
-			end = len(data)
+	nextBlock := rp.HistoryLastBlock + rp.HistoryBlockInterval
+	if rp.HistoryLastBlock == 0 {
+		nextBlock = rp.HistoryStartBlock
+		if nextBlock == 0 {
+			// without a known deployment block there is nothing to backfill, start recording at the latest block
+			nextBlock = latestBlock
 		}
+	}
 
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*nArgs)
-		for i, d := range data[start:end] {
-			for j := 0; j < nArgs; j++ {
-				valueStringsArgs[j] = i*nArgs + j + 1
-			}
-			valueStrings = append(valueStrings, fmt.Sprintf(valueStringsTpl, valueStringsArgs...))
-			valueArgs = append(valueArgs, rp.API.RocketStorageContract.Address.Bytes())
-			valueArgs = append(valueArgs, d.Address)
-			valueArgs = append(valueArgs, d.ID)
-			valueArgs = append(valueArgs, d.URL)
-			valueArgs = append(valueArgs, d.JoinedTime)
-			valueArgs = append(valueArgs, d.LastProposalTime)
-			valueArgs = append(valueArgs, d.RPLBondAmount.String())
-			valueArgs = append(valueArgs, d.UnbondedValidatorCount)
-		}
-		stmt := fmt.Sprintf(`insert into rocketpool_dao_members (rocketpool_storage_address, address, id, url, joined_time, last_proposal_time, rpl_bond_amount, unbonded_validator_count) values %s on conflict (rocketpool_storage_address, address) do update set id = excluded.id, url = excluded.url, joined_time = excluded.joined_time, last_proposal_time = excluded.last_proposal_time, rpl_bond_amount = excluded.rpl_bond_amount, unbonded_validator_count = excluded.unbonded_validator_count`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
+	// limit the number of snapshots per run so a long backfill does not delay the regular updates
+	for i := 0; i < 10 && nextBlock <= latestBlock; i++ {
+		err = rp.SaveNodesHistory(nextBlock)
 		if err != nil {
-			return fmt.Errorf("error inserting into rocketpool_dao_members: %w", err)
+			return fmt.Errorf("error saving rocketpool-history at block %v: %w", nextBlock, err)
 		}
+		rp.HistoryLastBlock = nextBlock
+		nextBlock += rp.HistoryBlockInterval
 	}
-
-	return tx.Commit()
+	return nil
 }
 
-func (rp *RocketpoolExporter) TagValidators() error {
-	if len(rp.MinipoolsByAddress) == 0 {
-		return nil
+// SaveNodesHistory queries the rpl stake and minipool count of all nodes at the given eth1-block and saves them
+func (rp *RocketpoolExporter) SaveNodesHistory(block uint64) error {
+	opts := &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(block)}
+	header, err := rp.Eth1Client.HeaderByNumber(context.Background(), opts.BlockNumber)
+	if err != nil {
+		return err
 	}
-
-	t0 := time.Now()
-	defer func(t0 time.Time) {
-		logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("saved rocketpool-validator-tags")
-	}(t0)
-
-	tx, err := db.DB.Beginx()
+	nodeAddresses, err := node.GetNodeAddresses(rp.API, opts)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
-	data := make([]*RocketpoolMinipool, len(rp.MinipoolsByAddress))
-	i := 0
-	for _, mp := range rp.MinipoolsByAddress {
-		data[i] = mp
-		i++
+	nodes := make([]*RocketpoolNode, len(nodeAddresses))
+	minipoolCounts := make([]uint64, len(nodeAddresses))
+	sem := make(chan struct{}, 10)
+	var wg errgroup.Group
+	for i, a := range nodeAddresses {
+		i, a := i, a
+		wg.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			n := &RocketpoolNode{Address: a.Bytes()}
+			err := n.Update(rp.API, opts)
+			if err != nil {
+				return err
+			}
+			count, err := minipool.GetNodeMinipoolCount(rp.API, a, opts)
+			if err != nil {
+				return err
+			}
+			nodes[i] = n
+			minipoolCounts[i] = count
+			return nil
+		})
 	}
-
-	batchSize := 5000
-	for b := 0; b < len(data); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(data) < end {
-			end = len(data)
-		}
-		n := 1
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*n)
-		for i, d := range data[start:end] {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, 'rocketpool')", i*n+1))
-			valueArgs = append(valueArgs, d.Pubkey)
-		}
-		stmt := fmt.Sprintf(`insert into validator_tags (publickey, tag) values %s on conflict (publickey, tag) do nothing`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return fmt.Errorf("error inserting into validator_tags: %w", err)
-		}
+	err = wg.Wait()
+	if err != nil {
+		return err
 	}
 
-	return tx.Commit()
+	ts := time.Unix(int64(header.Time), 0)
+	rows := make([][]interface{}, len(nodes))
+	for i, d := range nodes {
+		rows[i] = []interface{}{rp.API.RocketStorageContract.Address.Bytes(), block, ts, d.Address, d.RPLStake.String(), d.MinRPLStake.String(), d.MaxRPLStake.String(), minipoolCounts[i]}
+	}
+	return db.BatchUpsert("rocketpool_nodes_history",
+		[]string{"rocketpool_storage_address", "eth1_block", "ts", "address", "rpl_stake", "min_rpl_stake", "max_rpl_stake", "minipool_count"},
+		[]string{"rocketpool_storage_address", "eth1_block", "address"},
+		rows)
 }
 
 type RocketpoolMinipool struct {
@@ -626,6 +800,8 @@ type RocketpoolMinipool struct {
 	DepositType string    `db:"deposit_type"`
 	Status      string    `db:"status"`
 	StatusTime  time.Time `db:"status_time"`
+	// NodeDepositBalance is the ETH bonded by the node in wei, it changes when the bond of a 16-ETH minipool is reduced
+	NodeDepositBalance string `db:"node_deposit_balance"`
 }
 
 func NewRocketpoolMinipool(rp *rocketpool.RocketPool, addr []byte) (*RocketpoolMinipool, error) {
@@ -672,6 +848,7 @@ func (this *RocketpoolMinipool) Update(rp *rocketpool.RocketPool) error {
 	var wg errgroup.Group
 	var status rpTypes.MinipoolStatus
 	var statusTime time.Time
+	var nodeDepositBalance *big.Int
 
 	wg.Go(func() error {
 		var err error
@@ -683,6 +860,11 @@ func (this *RocketpoolMinipool) Update(rp *rocketpool.RocketPool) error {
 		statusTime, err = mp.GetStatusTime(nil)
 		return err
 	})
+	wg.Go(func() error {
+		var err error
+		nodeDepositBalance, err = mp.GetNodeDepositBalance(nil)
+		return err
+	})
 
 	if err := wg.Wait(); err != nil {
 		return err
@@ -690,16 +872,23 @@ func (this *RocketpoolMinipool) Update(rp *rocketpool.RocketPool) error {
 
 	this.Status = status.String()
 	this.StatusTime = statusTime
+	this.NodeDepositBalance = nodeDepositBalance.String()
 
 	return nil
 }
 
 type RocketpoolNode struct {
-	Address          []byte   `db:"address"`
-	TimezoneLocation string   `db:"timezone_location"`
-	RPLStake         *big.Int `db:"rpl_stake"`
-	MinRPLStake      *big.Int `db:"min_rpl_stake"`
-	MaxRPLStake      *big.Int `db:"max_rpl_stake"`
+	Address               []byte   `db:"address"`
+	TimezoneLocation      string   `db:"timezone_location"`
+	RPLStake              *big.Int `db:"rpl_stake"`
+	MinRPLStake           *big.Int `db:"min_rpl_stake"`
+	MaxRPLStake           *big.Int `db:"max_rpl_stake"`
+	FeeDistributorAddress []byte   `db:"fee_distributor_address"`
+	SmoothingPoolOptedIn  bool     `db:"smoothing_pool_opted_in"`
+	DepositCredit         *big.Int `db:"deposit_credit"`
+	ETHMatched            *big.Int `db:"eth_matched"`
+	ETHProvided           *big.Int `db:"eth_provided"`
+	ETHMatchedLimit       *big.Int `db:"eth_matched_limit"`
 }
 
 func NewRocketpoolNode(rp *rocketpool.RocketPool, addr []byte) (*RocketpoolNode, error) {
@@ -711,23 +900,24 @@ func NewRocketpoolNode(rp *rocketpool.RocketPool, addr []byte) (*RocketpoolNode,
 		return nil, err
 	}
 	rpn.TimezoneLocation = tl
-	err = rpn.Update(rp)
+	err = rpn.Update(rp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return rpn, nil
 }
 
-func (this *RocketpoolNode) Update(rp *rocketpool.RocketPool) error {
-	stake, err := node.GetNodeRPLStake(rp, common.BytesToAddress(this.Address), nil)
+// Update fetches the rpl stake of the node, opts can be used to query the state at a specific block (nil for the latest block)
+func (this *RocketpoolNode) Update(rp *rocketpool.RocketPool, opts *bind.CallOpts) error {
+	stake, err := node.GetNodeRPLStake(rp, common.BytesToAddress(this.Address), opts)
 	if err != nil {
 		return err
 	}
-	minStake, err := node.GetNodeMinimumRPLStake(rp, common.BytesToAddress(this.Address), nil)
+	minStake, err := node.GetNodeMinimumRPLStake(rp, common.BytesToAddress(this.Address), opts)
 	if err != nil {
 		return err
 	}
-	maxStake, err := node.GetNodeMaximumRPLStake(rp, common.BytesToAddress(this.Address), nil)
+	maxStake, err := node.GetNodeMaximumRPLStake(rp, common.BytesToAddress(this.Address), opts)
 	if err != nil {
 		return err
 	}
@@ -737,6 +927,77 @@ func (this *RocketpoolNode) Update(rp *rocketpool.RocketPool) error {
 	return nil
 }
 
+// UpdateFeeRecipient fetches the fee distributor and the smoothing pool registration of the node, the address of the
+// fee distributor is derived from the node address and therefore only fetched once
+func (this *RocketpoolNode) UpdateFeeRecipient(contracts *rpFeeContracts) error {
+	nodeAddress := common.BytesToAddress(this.Address)
+	if this.FeeDistributorAddress == nil {
+		distributor := new(common.Address)
+		err := contracts.distributorFactory.Call(nil, distributor, "getProxyAddress", nodeAddress)
+		if err != nil {
+			return fmt.Errorf("error getting fee distributor of node %v: %w", nodeAddress.Hex(), err)
+		}
+		this.FeeDistributorAddress = distributor.Bytes()
+	}
+	optedIn := new(bool)
+	err := contracts.nodeManager.Call(nil, optedIn, "getSmoothingPoolRegistrationState", nodeAddress)
+	if err != nil {
+		return fmt.Errorf("error getting smoothing pool registration of node %v: %w", nodeAddress.Hex(), err)
+	}
+	this.SmoothingPoolOptedIn = *optedIn
+	return nil
+}
+
+// UpdateDepositCredit fetches the deposit credit of the node and the ETH it provided, borrowed and is able to borrow
+// with its rpl stake
+func (this *RocketpoolNode) UpdateDepositCredit(contracts *rpDepositContracts) error {
+	nodeAddress := common.BytesToAddress(this.Address)
+	var wg errgroup.Group
+	values := []struct {
+		contract *rocketpool.Contract
+		method   string
+		dst      **big.Int
+	}{
+		{contracts.nodeDeposit, "getNodeDepositCredit", &this.DepositCredit},
+		{contracts.nodeStaking, "getNodeETHMatched", &this.ETHMatched},
+		{contracts.nodeStaking, "getNodeETHProvided", &this.ETHProvided},
+		{contracts.nodeStaking, "getNodeETHMatchedLimit", &this.ETHMatchedLimit},
+	}
+	for _, v := range values {
+		v := v
+		wg.Go(func() error {
+			value := new(*big.Int)
+			err := v.contract.Call(nil, value, v.method, nodeAddress)
+			if err != nil {
+				return fmt.Errorf("error calling %v of node %v: %w", v.method, nodeAddress.Hex(), err)
+			}
+			*v.dst = *value
+			return nil
+		})
+	}
+	return wg.Wait()
+}
+
+// parseNullBigInt parses a nullable numeric column, nil is returned for null
+func parseNullBigInt(s *string) *big.Int {
+	if s == nil {
+		return nil
+	}
+	res, ok := new(big.Int).SetString(*s, 10)
+	if !ok {
+		return nil
+	}
+	return res
+}
+
+// nullBigIntString returns the value for a nullable numeric column
+func nullBigIntString(i *big.Int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return i.String()
+}
+
 type RocketpoolDAOProposal struct {
 	ID              uint64    `db:"id"`
 	DAO             string    `db:"dao"`
@@ -754,6 +1015,7 @@ type RocketpoolDAOProposal struct {
 	IsCancelled     bool      `db:"is_cancelled"`
 	IsExecuted      bool      `db:"is_executed"`
 	Payload         []byte    `db:"payload"`
+	PayloadDecoded  string    `db:"payload_decoded"`
 	State           string    `db:"state"`
 }
 
@@ -787,6 +1049,10 @@ func (this *RocketpoolDAOProposal) Update(rp *rocketpool.RocketPool) error {
 	this.IsCancelled = pd.IsCancelled
 	this.IsExecuted = pd.IsExecuted
 	this.Payload = pd.Payload
+	this.PayloadDecoded, err = decodeRocketpoolProposalPayload(pd.Payload)
+	if err != nil {
+		logrus.WithError(err).Debugf("error decoding payload of rocketpool-dao-proposal %v", pd.ID)
+	}
 	this.State = pd.State.String()
 	return nil
 }
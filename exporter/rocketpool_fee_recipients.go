@@ -0,0 +1,88 @@
+package exporter
+
+import (
+	"eth2-exporter/tracing"
+	"eth2-exporter/utils"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// rpFeeRecipientInitialDays is the number of days of blocks checked for fee recipient violations after a restart. The
+// violations are evaluated against the current fee distributor and smoothing pool registration of the nodes, older
+// blocks could be flagged because a node changed its registration since.
+const rpFeeRecipientInitialDays = 1
+
+// DetectFeeRecipientViolations flags the blocks of minipools whose el reward was paid neither to the fee distributor of
+// their node nor, if the node opted in, to the smoothing pool. Opted in nodes have to use the smoothing pool, all other
+// nodes their fee distributor. The fee recipient of blocks delivered by relays is the builder, the recipient of the
+// payment of the builder evaluated by the el rewards exporter is checked instead, so blocks are only checked once
+// their el reward is known.
+func (rp *RocketpoolExporter) DetectFeeRecipientViolations() (err error) {
+	t0 := time.Now()
+	defer func(t0 time.Time) {
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("detected rocketpool fee recipient violations")
+	}(t0)
+	ctx, span := tracing.StartSpan(rp.ctx, "rocketpool.detect_fee_recipient_violations")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if !utils.Config.Indexer.ElRewards.Enabled {
+		rp.logger.Debugf("rocketpool fee recipient violations require the el rewards exporter")
+		return nil
+	}
+
+	smoothingPool := common.HexToAddress(utils.Config.RocketpoolExporter.SmoothingPoolAddress)
+	if utils.Config.RocketpoolExporter.SmoothingPoolAddress == "" {
+		address, err := rp.API.GetAddress("rocketSmoothingPool")
+		if err != nil {
+			// the smoothing pool and the fee distributors were deployed together
+			rp.logger.WithError(err).Debugf("rocketpool smoothing pool not available")
+			return nil
+		}
+		smoothingPool = *address
+	}
+
+	var latestSlot uint64
+	// the latest slot up to which the el rewards of all canonical blocks are known
+	err = rp.DB.GetContext(ctx, &latestSlot, `
+		select coalesce(
+			(select min(slot) - 1 from blocks where status = '1' and exec_block_number is not null and exec_fee_reward is null),
+			(select max(slot) from blocks where status = '1'),
+			0)`)
+	if err != nil {
+		return err
+	}
+	if rp.FeeRecipientLastSlot == 0 {
+		initialSlots := rpFeeRecipientInitialDays * utils.EpochsPerDay() * utils.Config.Chain.SlotsPerEpoch
+		if latestSlot > initialSlots {
+			rp.FeeRecipientLastSlot = latestSlot - initialSlots
+		}
+	}
+	if latestSlot <= rp.FeeRecipientLastSlot {
+		return nil
+	}
+
+	res, err := rp.DB.ExecContext(ctx, `
+		insert into rocketpool_fee_recipient_violations (rocketpool_storage_address, slot, epoch, validatorindex, minipool_address, node_address, fee_recipient, expected_fee_recipient, smoothing_pool_opted_in)
+		select $1, b.slot, b.epoch, b.proposer, m.address, m.node_address, b.exec_fee_reward_recipient, e.expected, n.smoothing_pool_opted_in
+		from blocks b
+			inner join validators v on v.validatorindex = b.proposer
+			inner join rocketpool_minipools m on m.pubkey = v.pubkey and m.rocketpool_storage_address = $1
+			inner join rocketpool_nodes n on n.address = m.node_address and n.rocketpool_storage_address = $1
+			cross join lateral (select case when n.smoothing_pool_opted_in then $4::bytea else n.fee_distributor_address end as expected) e
+		where b.slot > $2 and b.slot <= $3 and b.status = '1'
+			and b.exec_fee_reward_recipient is not null
+			and n.fee_distributor_address is not null
+			and b.exec_fee_reward_recipient <> e.expected
+		on conflict (rocketpool_storage_address, slot) do nothing`,
+		rp.API.RocketStorageContract.Address.Bytes(), rp.FeeRecipientLastSlot, latestSlot, smoothingPool.Bytes())
+	if err != nil {
+		return err
+	}
+	if violations, err := res.RowsAffected(); err == nil && violations > 0 {
+		rp.logger.WithFields(logrus.Fields{"violations": violations, "fromSlot": rp.FeeRecipientLastSlot + 1, "toSlot": latestSlot}).Warnf("detected rocketpool fee recipient violations")
+	}
+	rp.FeeRecipientLastSlot = latestSlot
+	return nil
+}
@@ -0,0 +1,196 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"eth2-exporter/db"
+	"eth2-exporter/tracing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rocket-pool/rocketpool-go/rocketpool"
+	"github.com/sirupsen/logrus"
+)
+
+// odaoBlockRange is the number of eth1-blocks whose logs are requested at once, odaoMaxRangesPerRun limits the ranges
+// per run so a long backfill does not delay the regular updates
+const odaoBlockRange = 10000
+const odaoMaxRangesPerRun = 10
+
+// odaoContracts are the network contracts the oDAO members submit prices and balances to, consensus is reached once
+// enough members submitted the same values for a reference block and the Updated event is emitted
+var odaoContracts = []struct {
+	Kind           string
+	Contract       string
+	SubmittedEvent string
+	UpdatedEvent   string
+}{
+	{"prices", "rocketNetworkPrices", "PricesSubmitted", "PricesUpdated"},
+	{"balances", "rocketNetworkBalances", "BalancesSubmitted", "BalancesUpdated"},
+}
+
+// odaoValueColumns are the columns of the submitted values by the names of the event arguments
+var odaoValueColumns = []struct {
+	Column   string
+	Argument string
+}{
+	{"rpl_price", "rplPrice"},
+	{"total_eth", "totalEth"},
+	{"staking_eth", "stakingEth"},
+	{"reth_supply", "rethSupply"},
+}
+
+// UpdateODAOSubmissions indexes the price and balances submissions of the oDAO members and the consensus of the
+// submissions from the logs of the network contracts. Missing blocks since HistoryStartBlock are backfilled.
+func (rp *RocketpoolExporter) UpdateODAOSubmissions() (err error) {
+	t0 := time.Now()
+	defer func(t0 time.Time) {
+		rp.logger.WithFields(logrus.Fields{"duration": time.Since(t0)}).Debugf("updated rocketpool-odao-submissions")
+	}(t0)
+	_, span := tracing.StartSpan(rp.ctx, "rocketpool.update_odao_submissions")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	header, err := rp.Eth1Client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	latestBlock := header.Number.Uint64()
+
+	if rp.ODAOLastBlock == 0 {
+		err = rp.DB.Get(&rp.ODAOLastBlock, `
+			select greatest(
+				(select coalesce(max(eth1_block), 0) from rocketpool_odao_submissions where rocketpool_storage_address = $1),
+				(select coalesce(max(eth1_block), 0) from rocketpool_odao_consensus where rocketpool_storage_address = $1))`,
+			rp.API.RocketStorageContract.Address.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	fromBlock := rp.ODAOLastBlock + 1
+	if rp.ODAOLastBlock == 0 {
+		fromBlock = rp.HistoryStartBlock
+		if fromBlock == 0 && latestBlock > odaoBlockRange {
+			// without a known deployment block only the recent submissions are indexed
+			fromBlock = latestBlock - odaoBlockRange
+		}
+	}
+
+	contracts := make([]*rocketpool.Contract, len(odaoContracts))
+	for i, c := range odaoContracts {
+		contracts[i], err = rp.API.GetContract(c.Contract)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < odaoMaxRangesPerRun && fromBlock <= latestBlock; i++ {
+		toBlock := fromBlock + odaoBlockRange - 1
+		if toBlock > latestBlock {
+			toBlock = latestBlock
+		}
+		err = rp.saveODAOSubmissions(contracts, fromBlock, toBlock)
+		if err != nil {
+			return fmt.Errorf("error saving rocketpool-odao-submissions of blocks %v-%v: %w", fromBlock, toBlock, err)
+		}
+		rp.ODAOLastBlock = toBlock
+		fromBlock = toBlock + 1
+	}
+	return nil
+}
+
+// saveODAOSubmissions saves the submissions and consensus events of the eth1-blocks fromBlock to toBlock
+func (rp *RocketpoolExporter) saveODAOSubmissions(contracts []*rocketpool.Contract, fromBlock, toBlock uint64) error {
+	addresses := make([]common.Address, 0, len(contracts))
+	eventIDs := make([]common.Hash, 0, len(contracts)*2)
+	for i, c := range contracts {
+		addresses = append(addresses, *c.Address)
+		for _, name := range []string{odaoContracts[i].SubmittedEvent, odaoContracts[i].UpdatedEvent} {
+			event, ok := c.ABI.Events[name]
+			if !ok {
+				return fmt.Errorf("event %v not found in abi of %v", name, odaoContracts[i].Contract)
+			}
+			eventIDs = append(eventIDs, event.ID)
+		}
+	}
+
+	logs, err := rp.Eth1Client.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{eventIDs},
+	})
+	if err != nil {
+		return err
+	}
+
+	storageAddress := rp.API.RocketStorageContract.Address.Bytes()
+	submissions := [][]interface{}{}
+	consensus := [][]interface{}{}
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) == 0 {
+			continue
+		}
+		for i, c := range contracts {
+			if l.Address != *c.Address {
+				continue
+			}
+			kind := odaoContracts[i]
+			for _, name := range []string{kind.SubmittedEvent, kind.UpdatedEvent} {
+				if c.ABI.Events[name].ID != l.Topics[0] {
+					continue
+				}
+				values := map[string]interface{}{}
+				err = c.ABI.UnpackIntoMap(values, name, l.Data)
+				if err != nil {
+					return fmt.Errorf("error decoding %v event of tx %v: %w", name, l.TxHash.Hex(), err)
+				}
+				row := []interface{}{storageAddress, kind.Kind, odaoBigValue(values, "block"), l.BlockNumber, l.TxHash.Bytes(), odaoEventTime(values)}
+				for _, v := range odaoValueColumns {
+					row = append(row, odaoBigValue(values, v.Argument))
+				}
+				if name == kind.SubmittedEvent {
+					if len(l.Topics) < 2 {
+						return fmt.Errorf("%v event of tx %v has no member topic", name, l.TxHash.Hex())
+					}
+					member := common.BytesToAddress(l.Topics[1].Bytes())
+					submissions = append(submissions, append(row, member.Bytes()))
+				} else {
+					consensus = append(consensus, row)
+				}
+			}
+		}
+	}
+
+	columns := []string{"rocketpool_storage_address", "kind", "reference_block", "eth1_block", "tx_hash", "ts"}
+	for _, v := range odaoValueColumns {
+		columns = append(columns, v.Column)
+	}
+	err = db.BatchUpsert("rocketpool_odao_consensus", columns,
+		[]string{"rocketpool_storage_address", "kind", "reference_block"}, consensus)
+	if err != nil {
+		return err
+	}
+	return db.BatchUpsert("rocketpool_odao_submissions", append(columns, "member_address"),
+		[]string{"rocketpool_storage_address", "kind", "reference_block", "member_address"}, submissions)
+}
+
+// odaoBigValue returns the numeric event argument as decimal string or nil if the event has no such argument
+func odaoBigValue(values map[string]interface{}, name string) interface{} {
+	v, ok := values[name].(*big.Int)
+	if !ok {
+		return nil
+	}
+	return v.String()
+}
+
+// odaoEventTime returns the time argument of the event, it is the timestamp of the block the event was emitted in
+func odaoEventTime(values map[string]interface{}) time.Time {
+	v, ok := values["time"].(*big.Int)
+	if !ok {
+		return time.Unix(0, 0)
+	}
+	return time.Unix(v.Int64(), 0)
+}
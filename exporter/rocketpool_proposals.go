@@ -0,0 +1,186 @@
+package exporter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// rocketpoolProposalsABI contains the proposal methods of the rocketpool DAO contracts, the payload of a proposal is the
+// calldata of one of these methods which is executed on the DAO contract once the proposal passes
+const rocketpoolProposalsABI = `[
+	{"inputs":[{"name":"_id","type":"string"},{"name":"_url","type":"string"},{"name":"_nodeAddress","type":"address"}],"name":"proposalInvite","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_nodeAddress","type":"address"}],"name":"proposalLeave","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_nodeAddress","type":"address"},{"name":"_rplFine","type":"uint256"}],"name":"proposalKick","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_settingContractName","type":"string"},{"name":"_settingPath","type":"string"},{"name":"_value","type":"uint256"}],"name":"proposalSettingUint","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_settingContractName","type":"string"},{"name":"_settingPath","type":"string"},{"name":"_value","type":"bool"}],"name":"proposalSettingBool","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_settingContractName","type":"string"},{"name":"_settingPath","type":"string"},{"name":"_value","type":"address"}],"name":"proposalSettingAddress","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_settingContractNames","type":"string[]"},{"name":"_settingPaths","type":"string[]"},{"name":"_types","type":"uint8[]"},{"name":"_data","type":"bytes[]"}],"name":"proposalSettingMulti","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_trustedNodePercent","type":"uint256"},{"name":"_protocolPercent","type":"uint256"},{"name":"_nodePercent","type":"uint256"}],"name":"proposalSettingRewardsClaimers","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_type","type":"string"},{"name":"_name","type":"string"},{"name":"_contractAbi","type":"string"},{"name":"_contractAddress","type":"address"}],"name":"proposalUpgrade","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_invoiceID","type":"string"},{"name":"_recipientAddress","type":"address"},{"name":"_amount","type":"uint256"}],"name":"proposalTreasuryOneTimeSpend","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_contractName","type":"string"},{"name":"_recipientAddress","type":"address"},{"name":"_amountPerPeriod","type":"uint256"},{"name":"_periodLength","type":"uint256"},{"name":"_startTime","type":"uint256"},{"name":"_numPeriods","type":"uint256"}],"name":"proposalTreasuryNewContract","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_contractName","type":"string"},{"name":"_recipientAddress","type":"address"},{"name":"_amountPerPeriod","type":"uint256"},{"name":"_periodLength","type":"uint256"},{"name":"_numPeriods","type":"uint256"}],"name":"proposalTreasuryUpdateContract","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_id","type":"string"},{"name":"_memberAddress","type":"address"}],"name":"proposalSecurityInvite","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_memberAddress","type":"address"}],"name":"proposalSecurityKick","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_memberAddresses","type":"address[]"}],"name":"proposalSecurityKickMulti","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_existingMemberAddress","type":"address"},{"name":"_newMemberId","type":"string"},{"name":"_newMemberAddress","type":"address"}],"name":"proposalSecurityReplace","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// the setting types of proposalSettingMulti
+const (
+	rocketpoolSettingTypeUint uint8 = iota
+	rocketpoolSettingTypeBool
+	rocketpoolSettingTypeAddress
+)
+
+var rocketpoolProposals abi.ABI
+
+func init() {
+	var err error
+	rocketpoolProposals, err = abi.JSON(strings.NewReader(rocketpoolProposalsABI))
+	if err != nil {
+		panic(fmt.Sprintf("error parsing rocketpool proposals abi: %v", err))
+	}
+}
+
+// decodeRocketpoolProposalPayload returns a human readable description of the method and arguments the payload of a
+// rocketpool DAO proposal executes, for example "rocketDAOProtocolSettingsNode: set node.per.minipool.stake.minimum to 10%"
+func decodeRocketpoolProposalPayload(payload []byte) (string, error) {
+	if len(payload) < 4 {
+		return "", fmt.Errorf("payload of %v bytes is too short", len(payload))
+	}
+	method, err := rocketpoolProposals.MethodById(payload[:4])
+	if err != nil {
+		return "", err
+	}
+	args, err := method.Inputs.Unpack(payload[4:])
+	if err != nil {
+		return "", fmt.Errorf("error unpacking arguments of %v: %w", method.RawName, err)
+	}
+
+	switch method.RawName {
+	case "proposalSettingUint", "proposalSettingBool", "proposalSettingAddress":
+		return formatRocketpoolSetting(args[0].(string), args[1].(string), args[2]), nil
+	case "proposalSettingMulti":
+		names, paths, settingTypes, data := args[0].([]string), args[1].([]string), args[2].([]uint8), args[3].([][]byte)
+		if len(paths) != len(names) || len(settingTypes) != len(names) || len(data) != len(names) {
+			return "", fmt.Errorf("mismatching argument lengths of %v", method.RawName)
+		}
+		settings := make([]string, 0, len(names))
+		for i := range names {
+			value, err := unpackRocketpoolSettingValue(settingTypes[i], data[i])
+			if err != nil {
+				return "", fmt.Errorf("error unpacking setting %v of %v: %w", paths[i], method.RawName, err)
+			}
+			settings = append(settings, formatRocketpoolSetting(names[i], paths[i], value))
+		}
+		return strings.Join(settings, "; "), nil
+	case "proposalSettingRewardsClaimers":
+		return fmt.Sprintf("rocketDAOProtocolSettingsRewards: set rewards claimers to %v oDAO, %v protocol, %v node operators",
+			formatRocketpoolPercent(args[0].(*big.Int)), formatRocketpoolPercent(args[1].(*big.Int)), formatRocketpoolPercent(args[2].(*big.Int))), nil
+	case "proposalInvite":
+		return fmt.Sprintf("invite %v (%v) with node %v", args[0], args[1], args[2].(common.Address).Hex()), nil
+	case "proposalLeave":
+		return fmt.Sprintf("leave with node %v", args[0].(common.Address).Hex()), nil
+	case "proposalKick":
+		return fmt.Sprintf("kick node %v with a fine of %v RPL", args[0].(common.Address).Hex(), formatRocketpoolEther(args[1].(*big.Int))), nil
+	case "proposalUpgrade":
+		return fmt.Sprintf("%v contract %v to %v", args[0], args[1], args[3].(common.Address).Hex()), nil
+	case "proposalTreasuryOneTimeSpend":
+		return fmt.Sprintf("treasury: spend %v RPL once to %v for invoice %v", formatRocketpoolEther(args[2].(*big.Int)), args[1].(common.Address).Hex(), args[0]), nil
+	case "proposalTreasuryNewContract", "proposalTreasuryUpdateContract":
+		action := "create"
+		if method.RawName == "proposalTreasuryUpdateContract" {
+			action = "update"
+		}
+		return fmt.Sprintf("treasury: %v contract %v paying %v RPL every %v to %v for %v periods", action, args[0],
+			formatRocketpoolEther(args[2].(*big.Int)), time.Duration(args[3].(*big.Int).Int64())*time.Second, args[1].(common.Address).Hex(), args[len(args)-1]), nil
+	case "proposalSecurityInvite":
+		return fmt.Sprintf("invite %v with address %v to the security council", args[0], args[1].(common.Address).Hex()), nil
+	case "proposalSecurityKick":
+		return fmt.Sprintf("kick %v from the security council", args[0].(common.Address).Hex()), nil
+	case "proposalSecurityKickMulti":
+		members := args[0].([]common.Address)
+		addresses := make([]string, 0, len(members))
+		for _, m := range members {
+			addresses = append(addresses, m.Hex())
+		}
+		return fmt.Sprintf("kick %v from the security council", strings.Join(addresses, ", ")), nil
+	case "proposalSecurityReplace":
+		return fmt.Sprintf("replace %v with %v (%v) in the security council", args[0].(common.Address).Hex(), args[1], args[2].(common.Address).Hex()), nil
+	}
+	return "", fmt.Errorf("unsupported method %v", method.RawName)
+}
+
+// unpackRocketpoolSettingValue unpacks the abi encoded value of a setting of proposalSettingMulti
+func unpackRocketpoolSettingValue(settingType uint8, data []byte) (interface{}, error) {
+	var typeName string
+	switch settingType {
+	case rocketpoolSettingTypeUint:
+		typeName = "uint256"
+	case rocketpoolSettingTypeBool:
+		typeName = "bool"
+	case rocketpoolSettingTypeAddress:
+		typeName = "address"
+	default:
+		return nil, fmt.Errorf("unknown setting type %v", settingType)
+	}
+	t, err := abi.NewType(typeName, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := abi.Arguments{{Type: t}}.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// formatRocketpoolSetting formats a setting change, uint settings are shown as percentage, duration or amount depending
+// on the setting path as the contracts store percentages as fraction of 1e18
+func formatRocketpoolSetting(contract, path string, value interface{}) string {
+	var formatted string
+	switch v := value.(type) {
+	case *big.Int:
+		formatted = formatRocketpoolSettingUint(path, v)
+	case common.Address:
+		formatted = v.Hex()
+	default:
+		formatted = fmt.Sprintf("%v", v)
+	}
+	return fmt.Sprintf("%v: set %v to %v", contract, path, formatted)
+}
+
+func formatRocketpoolSettingUint(path string, value *big.Int) string {
+	p := strings.ToLower(path)
+	switch {
+	case strings.Contains(p, "percent") || strings.Contains(p, "fee") || strings.Contains(p, "rate") ||
+		strings.Contains(p, "threshold") || strings.Contains(p, "quorum") || strings.Contains(p, "share") ||
+		strings.Contains(p, "stake.m"):
+		return formatRocketpoolPercent(value)
+	case strings.Contains(p, "time") || strings.Contains(p, "period") || strings.Contains(p, "frequency") ||
+		strings.Contains(p, "duration") || strings.Contains(p, "delay") || strings.Contains(p, "window"):
+		if value.IsInt64() {
+			return (time.Duration(value.Int64()) * time.Second).String()
+		}
+	case strings.Contains(p, "amount") || strings.Contains(p, "deposit") || strings.Contains(p, "capacity") ||
+		strings.Contains(p, "balance") || strings.Contains(p, "bond"):
+		return formatRocketpoolEther(value)
+	}
+	return value.String()
+}
+
+// formatRocketpoolPercent formats a fraction of 1e18 as percentage
+func formatRocketpoolPercent(value *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(value), big.NewFloat(1e16)).Text('f', -1) + "%"
+}
+
+// formatRocketpoolEther formats an amount of wei with 18 decimals
+func formatRocketpoolEther(value *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(value), big.NewFloat(1e18)).Text('f', -1)
+}
@@ -0,0 +1,146 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rocket-pool/rocketpool-go/tokens"
+)
+
+// rethRateInterval is the minimum time between two recorded rETH rates
+const rethRateInterval = time.Hour
+
+const uniswapV2PairABI = `[
+	{"constant":true,"inputs":[],"name":"getReserves","outputs":[{"name":"_reserve0","type":"uint112"},{"name":"_reserve1","type":"uint112"},{"name":"_blockTimestampLast","type":"uint32"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[],"name":"token0","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+const uniswapV3PoolABI = `[
+	{"inputs":[],"name":"slot0","outputs":[{"name":"sqrtPriceX96","type":"uint160"},{"name":"tick","type":"int24"},{"name":"observationIndex","type":"uint16"},{"name":"observationCardinality","type":"uint16"},{"name":"observationCardinalityNext","type":"uint16"},{"name":"feeProtocol","type":"uint8"},{"name":"unlocked","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"token0","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// SaveRETHRate records the rETH exchange rate of the rocketpool contract and the rate of the configured DEX pools at the latest eth1-block
+func (rp *RocketpoolExporter) SaveRETHRate() error {
+	if time.Since(rp.RethLastTs) < rethRateInterval {
+		return nil
+	}
+
+	header, err := rp.Eth1Client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	opts := &bind.CallOpts{BlockNumber: header.Number}
+
+	rate := &types.RocketpoolRETHRate{
+		Eth1Block: header.Number.Uint64(),
+		Ts:        time.Unix(int64(header.Time), 0),
+	}
+	rate.ExchangeRate, err = tokens.GetRETHExchangeRate(rp.API, opts)
+	if err != nil {
+		return err
+	}
+
+	rethAddress, err := rp.API.GetAddress("rocketTokenRETH")
+	if err != nil {
+		return err
+	}
+
+	marketRateSum := 0.0
+	marketRateCount := 0
+	for _, pool := range utils.Config.RocketpoolExporter.RethPools {
+		poolRate, err := rp.getRETHPoolRate(pool, *rethAddress, opts)
+		if err != nil {
+			// a single broken pool should not prevent recording the exchange rate
+			logger.WithError(err).Errorf("error retrieving rETH rate of pool %v", pool.Address)
+			continue
+		}
+		marketRateSum += poolRate
+		marketRateCount++
+	}
+	if marketRateCount > 0 {
+		marketRate := marketRateSum / float64(marketRateCount)
+		rate.MarketRate = &marketRate
+	}
+
+	_, err = rp.DB.Exec(`
+		INSERT INTO rocketpool_reth_history (rocketpool_storage_address, eth1_block, ts, exchange_rate, market_rate)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rocketpool_storage_address, eth1_block) DO UPDATE SET
+			exchange_rate = excluded.exchange_rate,
+			market_rate = excluded.market_rate`,
+		rp.API.RocketStorageContract.Address.Bytes(), rate.Eth1Block, rate.Ts, rate.ExchangeRate, rate.MarketRate)
+	if err != nil {
+		return err
+	}
+	rp.RethLastTs = time.Now()
+	return nil
+}
+
+// getRETHPoolRate returns the price of rETH in ETH of a rETH/WETH pool, both tokens have 18 decimals so no scaling is needed
+func (rp *RocketpoolExporter) getRETHPoolRate(pool types.RocketpoolRethPool, rethAddress common.Address, opts *bind.CallOpts) (float64, error) {
+	var poolABI string
+	switch pool.Type {
+	case "uniswapv2":
+		poolABI = uniswapV2PairABI
+	case "uniswapv3":
+		poolABI = uniswapV3PoolABI
+	default:
+		return 0, fmt.Errorf("unknown pool type %v", pool.Type)
+	}
+	parsedABI, err := abi.JSON(strings.NewReader(poolABI))
+	if err != nil {
+		return 0, err
+	}
+	contract := bind.NewBoundContract(common.HexToAddress(pool.Address), parsedABI, rp.Eth1Client, nil, nil)
+
+	res := []interface{}{}
+	err = contract.Call(opts, &res, "token0")
+	if err != nil {
+		return 0, err
+	}
+	rethIsToken0 := res[0].(common.Address) == rethAddress
+
+	// price is the amount of token1 per token0
+	var price *big.Float
+	if pool.Type == "uniswapv2" {
+		res = []interface{}{}
+		err = contract.Call(opts, &res, "getReserves")
+		if err != nil {
+			return 0, err
+		}
+		reserve0, reserve1 := res[0].(*big.Int), res[1].(*big.Int)
+		if reserve0.Sign() == 0 || reserve1.Sign() == 0 {
+			return 0, errors.New("pool has no liquidity")
+		}
+		price = new(big.Float).Quo(new(big.Float).SetInt(reserve1), new(big.Float).SetInt(reserve0))
+	} else {
+		res = []interface{}{}
+		err = contract.Call(opts, &res, "slot0")
+		if err != nil {
+			return 0, err
+		}
+		sqrtPriceX96 := res[0].(*big.Int)
+		if sqrtPriceX96.Sign() == 0 {
+			return 0, errors.New("pool is not initialized")
+		}
+		sqrtPrice := new(big.Float).Quo(new(big.Float).SetInt(sqrtPriceX96), new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96)))
+		price = new(big.Float).Mul(sqrtPrice, sqrtPrice)
+	}
+
+	if !rethIsToken0 {
+		price = new(big.Float).Quo(big.NewFloat(1), price)
+	}
+	rate, _ := price.Float64()
+	return rate, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"eth2-exporter/db"
+	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
 	"strings"
@@ -29,73 +30,73 @@ type SSVExporterResponse struct {
 	} `json:"data"`
 }
 
-func ssvExporter() {
-	for {
-		err := exportSSV()
-		if err != nil {
-			logger.WithError(err).Error("error exporting ssv validators")
-		}
-		logger.Warning("connection to ssv-exporter closed, reconnecting")
-		time.Sleep(time.Second * 10)
+func init() {
+	RegisterProtocolExporter("ssv", newSSVProtocolExporter)
+}
+
+// ssvProtocolExporter queries the validators of the ssv-exporter every update-interval and tags them
+type ssvProtocolExporter struct {
+	address string
+	res     *SSVExporterResponse
+}
+
+// newSSVProtocolExporter creates the ssv exporter of the ssv-exporter websocket in the address param, it defaults to
+// SSVExporter.address
+func newSSVProtocolExporter(cfg types.ProtocolExporterConfig) (ProtocolExporter, error) {
+	address := cfg.Params["address"]
+	if address == "" {
+		address = utils.Config.SSVExporter.Address
+	}
+	if address == "" {
+		return nil, fmt.Errorf("no ssv-exporter address configured")
 	}
+	return &ssvProtocolExporter{address: address}, nil
+}
+
+func (e *ssvProtocolExporter) Name() string {
+	return "ssv"
 }
 
-func exportSSV() error {
-	c, _, err := websocket.DefaultDialer.Dial(utils.Config.SSVExporter.Address, nil)
+func (e *ssvProtocolExporter) Init() error {
+	return nil
+}
+
+func (e *ssvProtocolExporter) Update() error {
+	c, _, err := websocket.DefaultDialer.Dial(e.address, nil)
 	if err != nil {
 		return err
 	}
 	defer c.Close()
 
-	done := make(chan struct{})
-
-	go func() {
-		defer close(done)
-		for {
-			_, message, err := c.ReadMessage()
-			if err != nil {
-				logger.WithError(err).Error("error reading message from ssv-exporter")
-				return
-			}
-
-			t0 := time.Now()
-			res := SSVExporterResponse{}
-			err = json.Unmarshal(message, &res)
-			if err != nil {
-				logger.WithError(err).Error("error unmarshaling json from ssv-exporter")
-				continue
-			}
-			logger.WithFields(logrus.Fields{"number": len(res.Data)}).Infof("exporting ssv validators")
-			err = saveSSV(&res)
-			if err != nil {
-				logger.WithError(err).Error("error tagging ssv validators")
-				continue
-			}
-			logger.WithFields(logrus.Fields{"number": len(res.Data), "duration": time.Since(t0)}).Infof("tagged ssv validators")
-		}
-	}()
-
-	qryValidatorsTicker := time.NewTicker(time.Second * 600)
-	defer qryValidatorsTicker.Stop()
-
-	for {
-		err := c.WriteMessage(websocket.TextMessage, []byte(`{"type":"validator","filter":{"from":0}}`))
-		if err != nil {
-			return err
-		}
-		select {
-		case <-qryValidatorsTicker.C:
-			continue
-		case <-done:
-			return nil
-		}
+	err = c.WriteMessage(websocket.TextMessage, []byte(`{"type":"validator","filter":{"from":0}}`))
+	if err != nil {
+		return err
+	}
+	err = c.SetReadDeadline(time.Now().Add(time.Minute))
+	if err != nil {
+		return err
+	}
+	_, message, err := c.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("error reading message from ssv-exporter: %w", err)
 	}
 
+	res := &SSVExporterResponse{}
+	err = json.Unmarshal(message, res)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling json from ssv-exporter: %w", err)
+	}
+	e.res = res
 	return nil
 }
 
+func (e *ssvProtocolExporter) Save() error {
+	logger.WithFields(logrus.Fields{"number": len(e.res.Data)}).Infof("exporting ssv validators")
+	return saveSSV(e.res)
+}
+
 func saveSSV(res *SSVExporterResponse) error {
-	tx, err := db.DB.Beginx()
+	tx, err := db.ExporterDB.Beginx()
 	if err != nil {
 		return err
 	}
@@ -117,29 +118,17 @@ func saveSSV(res *SSVExporterResponse) error {
 		time.Sleep(time.Millisecond * 100)
 	}
 
-	batchSize := 5000
-	for b := 0; b < len(res.Data); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(res.Data) < end {
-			end = len(res.Data)
-		}
-		n := 1
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*n)
-		for i, d := range res.Data[start:end] {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, 'ssv')", i*n+1))
-			pubkey, err := hex.DecodeString(strings.Replace(d.Publickey, "0x", "", -1))
-			if err != nil {
-				return err
-			}
-			valueArgs = append(valueArgs, pubkey)
-		}
-		stmt := fmt.Sprintf(`insert into validator_tags (publickey, tag) values %s on conflict (publickey, tag) do nothing`, strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
+	rows := make([][]interface{}, 0, len(res.Data))
+	for _, d := range res.Data {
+		pubkey, err := hex.DecodeString(strings.Replace(d.Publickey, "0x", "", -1))
 		if err != nil {
 			return err
 		}
+		rows = append(rows, []interface{}{pubkey, "ssv"})
+	}
+	err = db.BatchUpsertTx(tx, "validator_tags", []string{"publickey", "tag"}, []string{"publickey", "tag"}, rows)
+	if err != nil {
+		return err
 	}
 
 	// currently the ssv-exporter also exports publickeys that are not actually part of the network
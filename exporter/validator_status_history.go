@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"eth2-exporter/db"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// validatorStatusHistoryLookback is the number of epochs before the head whose status transitions are updated on every
+// check, it covers reorgs and epochs the indexer missed while it was behind
+const validatorStatusHistoryLookback = 100
+
+// updateValidatorStatusHistory stores the status transitions of the recent epochs, the whole history is derived if no
+// transitions have been stored yet (e.g. after the validator_status_history table has been created)
+func updateValidatorStatusHistory(headEpoch uint64) {
+	start := time.Now()
+
+	startEpoch := uint64(0)
+	if headEpoch > validatorStatusHistoryLookback {
+		startEpoch = headEpoch - validatorStatusHistoryLookback
+	}
+	exists, err := db.HasValidatorStatusHistory()
+	if err != nil {
+		logger.WithError(err).Errorf("error checking for validator status history")
+		return
+	}
+	if !exists {
+		logger.Infof("backfilling validator status history up to epoch %v", headEpoch)
+		startEpoch = 0
+	}
+
+	updated, err := db.UpdateValidatorStatusHistory(startEpoch, headEpoch)
+	if err != nil {
+		logger.WithError(err).Errorf("error updating validator status history of epochs %v-%v", startEpoch, headEpoch)
+		return
+	}
+	logger.WithFields(logrus.Fields{"startEpoch": startEpoch, "endEpoch": headEpoch, "transitions": updated, "duration": time.Since(start)}).Infof("updated validator status history")
+}
@@ -0,0 +1,163 @@
+package featureflags
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/logging"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var logger = logging.NewLogger("featureflags")
+
+const (
+	// Rocketpool enables the rocketpool pages, api endpoints and charts
+	Rocketpool = "rocketpool"
+	// ExperimentalCharts enables the charts that are still under development
+	ExperimentalCharts = "experimental_charts"
+)
+
+type flag struct {
+	Description string
+	// Default returns the state of the flag if it is neither configured nor overridden
+	Default func() bool
+}
+
+var flags = map[string]flag{
+	Rocketpool: {
+		Description: "Rocket Pool pages, api endpoints and charts",
+		Default: func() bool {
+			return utils.Config.RocketpoolExporter.Enabled || utils.Config.RocketpoolExporter.StorageContractAddress != ""
+		},
+	},
+	ExperimentalCharts: {
+		Description: "Charts that are still under development",
+		Default:     func() bool { return false },
+	},
+}
+
+var overrides = map[string]*types.FeatureFlagOverride{}
+var overridesMux = &sync.RWMutex{}
+
+// Init loads the overrides of the feature flags and keeps them up to date, overrides set on other instances of the
+// frontend apply within a minute
+func Init() {
+	refreshOverrides()
+	go func() {
+		for {
+			time.Sleep(time.Minute)
+			refreshOverrides()
+		}
+	}()
+}
+
+func refreshOverrides() {
+	list, err := db.GetFeatureFlagOverrides()
+	if err != nil {
+		logger.Errorf("error retrieving feature flag overrides: %v", err)
+		return
+	}
+	m := make(map[string]*types.FeatureFlagOverride, len(list))
+	for _, o := range list {
+		m[o.Name] = o
+	}
+	overridesMux.Lock()
+	overrides = m
+	overridesMux.Unlock()
+}
+
+// Exists returns true if the feature flag is known
+func Exists(name string) bool {
+	_, ok := flags[name]
+	return ok
+}
+
+// Enabled returns the state of the feature flag, an override takes precedence over the config which takes precedence
+// over the default of the flag. Unknown flags are disabled.
+func Enabled(name string) bool {
+	overridesMux.RLock()
+	o, ok := overrides[name]
+	overridesMux.RUnlock()
+	if ok {
+		return o.Enabled
+	}
+	return configured(name)
+}
+
+// configured returns the state of the feature flag without overrides
+func configured(name string) bool {
+	f, ok := flags[name]
+	if !ok {
+		return false
+	}
+	if enabled, ok := utils.FeatureFlagsConfig()[name]; ok {
+		return enabled
+	}
+	return f.Default()
+}
+
+// All returns the state of all feature flags by name
+func All() map[string]bool {
+	all := make(map[string]bool, len(flags))
+	for name := range flags {
+		all[name] = Enabled(name)
+	}
+	return all
+}
+
+// List returns all feature flags with their configured state and override, sorted by name
+func List() []*types.FeatureFlag {
+	overridesMux.RLock()
+	defer overridesMux.RUnlock()
+
+	list := make([]*types.FeatureFlag, 0, len(flags))
+	for name, f := range flags {
+		ff := &types.FeatureFlag{
+			Name:        name,
+			Description: f.Description,
+			Configured:  configured(name),
+			Override:    overrides[name],
+		}
+		ff.Enabled = ff.Configured
+		if ff.Override != nil {
+			ff.Enabled = ff.Override.Enabled
+		}
+		list = append(list, ff)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// SetOverride overrides the state of the feature flag at runtime
+func SetOverride(name string, enabled bool, userID uint64) error {
+	err := db.SetFeatureFlagOverride(name, enabled, userID)
+	if err != nil {
+		return err
+	}
+	refreshOverrides()
+	return nil
+}
+
+// ClearOverride removes the override of the feature flag, the configured state applies again
+func ClearOverride(name string) error {
+	err := db.DeleteFeatureFlagOverride(name)
+	if err != nil {
+		return err
+	}
+	refreshOverrides()
+	return nil
+}
+
+// Require only passes requests to the handler if the feature flag is enabled, otherwise the feature is not found
+func Require(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled(name) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"net/http"
 )
 
-var advertisewithusTemplate = template.Must(template.New("advertisewithus").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/advertisewithus.html"))
+var advertisewithusTemplate = template.Must(template.New("advertisewithus").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/advertisewithus.html", "templates/components/captcha.html"))
 
 func AdvertiseWithUs(w http.ResponseWriter, r *http.Request) {
 	var err error
@@ -19,11 +19,11 @@ func AdvertiseWithUs(w http.ResponseWriter, r *http.Request) {
 	data := InitPageData(w, r, "advertisewithus", "/advertisewithus", "Adverstise With Us")
 
 	pageData := &types.AdvertiseWithUsPageData{}
-	pageData.RecaptchaKey = utils.Config.Frontend.RecaptchaSiteKey
+	pageData.Captcha = utils.GetCaptchaData("advertisewithus")
 
 	pageData.FlashMessage, err = utils.GetFlash(w, r, "ad_flash")
 	if err != nil {
-		logger.Errorf("error retrieving flashes for advertisewithusform %v", err)
+		requestLogger(r).Errorf("error retrieving flashes for advertisewithusform %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -31,7 +31,7 @@ func AdvertiseWithUs(w http.ResponseWriter, r *http.Request) {
 
 	err = advertisewithusTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -40,27 +40,18 @@ func AdvertiseWithUs(w http.ResponseWriter, r *http.Request) {
 func AdvertiseWithUsPost(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		utils.SetFlash(w, r, "ad_flash", "Error: invalid form submitted")
 		http.Redirect(w, r, "/advertisewithus", http.StatusSeeOther)
 		return
 	}
 
-	if len(utils.Config.Frontend.RecaptchaSecretKey) > 0 && len(utils.Config.Frontend.RecaptchaSiteKey) > 0 {
-		if len(r.FormValue("g-recaptcha-response")) == 0 {
-			utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
-			logger.Errorf("error no recaptca response present %v route: %v", r.URL.String(), r.FormValue("g-recaptcha-response"))
-			http.Redirect(w, r, "/pricing", http.StatusSeeOther)
-			return
-		}
-
-		valid, err := utils.ValidateReCAPTCHA(r.FormValue("g-recaptcha-response"))
-		if err != nil || !valid {
-			utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
-			logger.Errorf("error validating recaptcha %v route: %v", r.URL.String(), err)
-			http.Redirect(w, r, "/pricing", http.StatusSeeOther)
-			return
-		}
+	err = utils.ValidateCaptcha(r, "advertisewithus")
+	if err != nil {
+		utils.SetFlash(w, r, "ad_flash", "Error: Failed to create request")
+		requestLogger(r).Errorf("error validating captcha %v route: %v", r.URL.String(), err)
+		http.Redirect(w, r, "/advertisewithus", http.StatusSeeOther)
+		return
 	}
 
 	name := r.FormValue("name")
@@ -82,7 +73,7 @@ func AdvertiseWithUsPost(w http.ResponseWriter, r *http.Request) {
 
 	err = mail.SendMail("support@beaconcha.in", "New ad inquiry", msg, []types.EmailAttachment{})
 	if err != nil {
-		logger.Errorf("error sending ad form: %v", err)
+		requestLogger(r).Errorf("error sending ad form: %v", err)
 		utils.SetFlash(w, r, "ad_flash", "Error: unable to submit ad request")
 		http.Redirect(w, r, "/advertisewithus", http.StatusSeeOther)
 		return
@@ -16,10 +16,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	gorillacontext "github.com/gorilla/context"
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
@@ -39,6 +41,10 @@ import (
 // @description Key as a query string parameter: `curl https://beaconcha.in/api/v1/block/1?apikey=<your_key>`
 // @description
 // @description Key in a request header:  `curl -H 'apikey: <your_key>' https://beaconcha.in/api/v1/block/1`
+// @description
+// @description The validator, validators, block, epoch blocks and dashboard endpoints return only the fields listed in the comma
+// @description separated fields query parameter if it is set, e.g. `https://beaconcha.in/api/v1/validator/1?fields=validatorindex,balance`.
+// @description Fields of nested objects are selected with a dot, e.g. `fields=validators.balance,currentEpoch`.
 // @securitydefinitions.oauth2.accessCode OAuthAccessCode
 // @tokenurl https://beaconcha.in/user/token
 // @authorizationurl https://beaconcha.in/user/authorize
@@ -57,7 +63,7 @@ func ApiHealthz(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/plain")
 
-	lastEpoch, err := db.GetLatestEpoch()
+	lastEpoch, err := db.GetLatestEpoch(r.Context())
 
 	if err != nil {
 		http.Error(w, "Internal server error: could not retrieve latest epoch from the db", 503)
@@ -95,7 +101,7 @@ func ApiHealthzLoadbalancer(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/plain")
 
-	lastEpoch, err := db.GetLatestEpoch()
+	lastEpoch, err := db.GetLatestEpoch(r.Context())
 
 	if err != nil {
 		http.Error(w, "Internal server error: could not retrieve latest epoch from the db", 503)
@@ -141,12 +147,16 @@ func ApiEpoch(w http.ResponseWriter, r *http.Request) {
 		epoch = int64(services.LatestEpoch())
 	}
 
-	rows, err := db.DB.Query(`SELECT *, 
-		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '0') as scheduledblocks,
-		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '1') as proposedblocks,
-		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '2') as missedblocks,
-		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '3') as orphanedblocks
-		FROM epochs WHERE epoch = $1`, epoch)
+	// the block counts are taken from the epochs summary, the blocks of the head epoch are counted as its summary is
+	// only updated at the end of the epoch
+	rows, err := db.DB.QueryContext(r.Context(), `SELECT epochs.*, 
+		CASE WHEN s.epoch IS NOT NULL AND epochs.epoch < $2 THEN s.scheduledblocks ELSE (SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '0') END as scheduledblocks,
+		CASE WHEN s.epoch IS NOT NULL AND epochs.epoch < $2 THEN s.proposedblocks ELSE (SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '1') END as proposedblocks,
+		CASE WHEN s.epoch IS NOT NULL AND epochs.epoch < $2 THEN s.missedblocks ELSE (SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '2') END as missedblocks,
+		CASE WHEN s.epoch IS NOT NULL AND epochs.epoch < $2 THEN s.orphanedblocks ELSE (SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '3') END as orphanedblocks
+		FROM epochs
+		LEFT JOIN epochs_summary s ON s.epoch = epochs.epoch
+		WHERE epochs.epoch = $1`, epoch, services.LatestEpoch())
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -156,12 +166,56 @@ func ApiEpoch(w http.ResponseWriter, r *http.Request) {
 	returnQueryResults(rows, j, r)
 }
 
+// ApiEpochs godoc
+// @Summary Get the epochs, latest first
+// @Tags Epoch
+// @Description Returns the epochs starting with the latest one, use next_cursor to fetch the next page
+// @Produce  json
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor"
+// @Param  limit query int false "Number of results per page (max 100)"
+// @Success 200 {object} string
+// @Router /api/v1/epochs [get]
+func ApiEpochs(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+
+	page, err := parseApiPage(r, 1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorEpoch, err := page.cursorUint(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT epochs.*
+		FROM epochs
+		WHERE ($1::int IS NULL OR epoch < $1)
+		ORDER BY epoch DESC
+		LIMIT $2`, cursorEpoch, page.Limit+1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	defer rows.Close()
+
+	returnPaginatedQueryResults(rows, j, r, page, "epoch")
+}
+
 // ApiEpochBlocks godoc
 // @Summary Get epoch blocks by epoch number
 // @Tags Epoch
 // @Description Returns all blocks for a specified epoch
 // @Produce  json
 // @Param  epoch path string true "Epoch number or the string latest"
+// @Param  fields query string false "Comma separated fields of the blocks to return, e.g. slot,proposer,status"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor, enables cursor pagination"
+// @Param  limit query int false "Number of results per page (max 100), enables cursor pagination"
 // @Success 200 {object} string
 // @Router /api/v1/epoch/{epoch}/blocks [get]
 func ApiEpochBlocks(w http.ResponseWriter, r *http.Request) {
@@ -181,14 +235,49 @@ func ApiEpochBlocks(w http.ResponseWriter, r *http.Request) {
 		epoch = int64(services.LatestEpoch())
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks WHERE epoch = $1 ORDER BY slot", epoch)
+	page, err := parseApiPage(r, 2)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	if !page.Enabled {
+		rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks WHERE epoch = $1 ORDER BY slot", epoch)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		defer rows.Close()
+
+		returnQueryResults(rows, j, r)
+		return
+	}
+
+	// a slot can have an orphaned and a canonical block, so the keyset includes the block root
+	cursorSlot, err := page.cursorUint(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorBlockRoot, err := page.cursorBytes(1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT *
+		FROM blocks
+		WHERE epoch = $1 AND ($2::int IS NULL OR (slot, blockroot) > ($2, $3::bytea))
+		ORDER BY slot, blockroot
+		LIMIT $4`, epoch, cursorSlot, cursorBlockRoot, page.Limit+1)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
 	defer rows.Close()
 
-	returnQueryResults(rows, j, r)
+	returnPaginatedQueryResults(rows, j, r, page, "slot", "blockroot")
 }
 
 // ApiBlock godoc
@@ -197,6 +286,7 @@ func ApiEpochBlocks(w http.ResponseWriter, r *http.Request) {
 // @Description Returns a block by its slot or root hash
 // @Produce  json
 // @Param  slotOrHash path string true "Block slot or root hash or the string latest"
+// @Param  fields query string false "Comma separated fields of the block to return, e.g. slot,proposer,status"
 // @Success 200 {object} string
 // @Router /api/v1/block/{slotOrHash} [get]
 func ApiBlock(w http.ResponseWriter, r *http.Request) {
@@ -217,7 +307,7 @@ func ApiBlock(w http.ResponseWriter, r *http.Request) {
 		blockSlot = int64(services.LatestSlot())
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks WHERE slot = $1 OR blockroot = $2", blockSlot, blockRootHash)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks WHERE slot = $1 OR blockroot = $2", blockSlot, blockRootHash)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -233,6 +323,8 @@ func ApiBlock(w http.ResponseWriter, r *http.Request) {
 // @Description Returns the attestations included in a specific block
 // @Produce  json
 // @Param  slot path string true "Block slot"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor, enables cursor pagination"
+// @Param  limit query int false "Number of results per page (max 100), enables cursor pagination"
 // @Success 200 {object} string
 // @Router /api/v1/block/{slot}/attestations [get]
 func ApiBlockAttestations(w http.ResponseWriter, r *http.Request) {
@@ -248,14 +340,43 @@ func ApiBlockAttestations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks_attestations WHERE block_slot = $1 ORDER BY block_index", slot)
+	page, err := parseApiPage(r, 1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	if !page.Enabled {
+		rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks_attestations WHERE block_slot = $1 ORDER BY block_index", slot)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		defer rows.Close()
+
+		returnQueryResults(rows, j, r)
+		return
+	}
+
+	cursorBlockIndex, err := page.cursorUint(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT *
+		FROM blocks_attestations
+		WHERE block_slot = $1 AND ($2::int IS NULL OR block_index > $2)
+		ORDER BY block_index
+		LIMIT $3`, slot, cursorBlockIndex, page.Limit+1)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
 	defer rows.Close()
 
-	returnQueryResults(rows, j, r)
+	returnPaginatedQueryResults(rows, j, r, page, "block_index")
 }
 
 // ApiBlockDeposits godoc
@@ -279,7 +400,7 @@ func ApiBlockDeposits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks_deposits WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks_deposits WHERE block_slot = $1 ORDER BY block_index DESC", slot)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -300,7 +421,7 @@ func ApiValidatorQueue(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	j := json.NewEncoder(w)
 
-	rows, err := db.DB.Query("SELECT entering_validators_count as beaconchain_entering, exiting_validators_count as beaconchain_exiting FROM queue ORDER BY ts DESC LIMIT 1")
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT entering_validators_count as beaconchain_entering, exiting_validators_count as beaconchain_exiting FROM queue ORDER BY ts DESC LIMIT 1")
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -331,7 +452,7 @@ func ApiBlockAttesterSlashings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks_attesterslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks_attesterslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -362,7 +483,7 @@ func ApiBlockProposerSlashings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks_proposerslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks_proposerslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -393,7 +514,7 @@ func ApiBlockVoluntaryExits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM blocks_voluntaryexits WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM blocks_voluntaryexits WHERE block_slot = $1 ORDER BY block_index DESC", slot)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -429,9 +550,9 @@ func ApiSyncCommittee(w http.ResponseWriter, r *http.Request) {
 		period = utils.SyncPeriodOfEpoch(services.LatestEpoch()) + 1
 	}
 
-	rows, err := db.DB.Query(`SELECT period, period*$2 AS start_epoch, (period+1)*$2-1 AS end_epoch, ARRAY_AGG(validatorindex ORDER BY committeeindex) AS validators FROM sync_committees WHERE period = $1 GROUP BY period`, period, utils.Config.Chain.EpochsPerSyncCommitteePeriod)
+	rows, err := db.DB.QueryContext(r.Context(), `SELECT period, period*$2 AS start_epoch, (period+1)*$2-1 AS end_epoch, ARRAY_AGG(validatorindex ORDER BY committeeindex) AS validators FROM sync_committees WHERE period = $1 GROUP BY period`, period, utils.Config.Chain.EpochsPerSyncCommitteePeriod)
 	if err != nil {
-		logger.WithError(err).WithField("url", r.URL.String()).Errorf("error querying db")
+		requestLogger(r).WithError(err).WithField("url", r.URL.String()).Errorf("error querying db")
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
@@ -460,7 +581,7 @@ func ApiEth1Deposit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT * FROM eth1_deposits WHERE tx_hash = $1", eth1TxHash)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM eth1_deposits WHERE tx_hash = $1", eth1TxHash)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -472,7 +593,7 @@ func ApiEth1Deposit(w http.ResponseWriter, r *http.Request) {
 
 /*
 	Combined validator get, performance, attestationefficency, epoch, historic epoch and rpl
-	Not public documented
+	Not public documented, supports the fields parameter with the sections and fields of ApiDashboardFields
 */
 func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -481,7 +602,7 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body | err: %v", err)
+		requestLogger(r).Errorf("error reading body | err: %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not read body")
 		return
 	}
@@ -493,19 +614,44 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 
 	epoch := int64(services.LatestEpoch())
 
-	queryIndices, queryPubkeys, err := parseApiValidatorParam(parsedBody.IndicesOrPubKey, maxValidators)
-	if err != nil {
-		sendErrorResponse(j, r.URL.String(), err.Error())
-		return
-	}
-
-	if len(queryPubkeys) > 0 {
-		err := db.DB.Select(&queryIndices, "SELECT validatorindex FROM validators WHERE pubkey = ANY($1) ORDER BY validatorindex", queryPubkeys)
+	var queryIndices []uint64
+	if parsedBody.Tag != "" {
+		claims := getAuthClaims(r)
+		if claims == nil {
+			sendErrorResponse(j, r.URL.String(), "tag filter requires authorization")
+			return
+		}
+		name, err := parseUserTagName(parsedBody.Tag)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), err.Error())
+			return
+		}
+		queryIndices, err = db.GetUserTagValidatorIndices(r.Context(), claims.UserID, utils.GetNetwork(), name)
+		if err != nil {
+			sendUserTagError(j, r, err, "could not retrieve validators of tag")
+			return
+		}
+		if len(queryIndices) > maxValidators {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("only a maximum of %v validators can be requested", maxValidators))
+			return
+		}
+	} else {
+		var queryPubkeys pq.ByteaArray
+		queryIndices, queryPubkeys, err = parseApiValidatorParam(parsedBody.IndicesOrPubKey, maxValidators)
 		if err != nil {
-			logger.Errorf("dashboard could not resolve pubkeys to indices err: %v", err)
 			sendErrorResponse(j, r.URL.String(), err.Error())
 			return
 		}
+
+		if len(queryPubkeys) > 0 {
+			pubkeyIndices, err := db.GetValidatorIndicesByPubkeys(r.Context(), queryPubkeys)
+			queryIndices = append(queryIndices, pubkeyIndices...)
+			if err != nil {
+				requestLogger(r).Errorf("dashboard could not resolve pubkeys to indices err: %v", err)
+				sendErrorResponse(j, r.URL.String(), err.Error())
+				return
+			}
+		}
 	}
 
 	g, _ := errgroup.WithContext(context.Background())
@@ -543,7 +689,7 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 
 	err = g.Wait()
 	if err != nil {
-		logger.Errorf("dashboard %v", err)
+		requestLogger(r).Errorf("dashboard %v", err)
 		sendErrorResponse(j, r.URL.String(), err.Error())
 		return
 	}
@@ -647,6 +793,7 @@ func getEpoch(epoch int64) ([]interface{}, error) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  fields query string false "Comma separated fields of the validators to return, e.g. validatorindex,balance,status"
 // @Success 200 {object} string
 // @Router /api/v1/validator/{indexOrPubkey} [get]
 func ApiValidator(w http.ResponseWriter, r *http.Request) {
@@ -663,7 +810,7 @@ func ApiValidator(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT validatorindex, pubkey, withdrawableepoch, withdrawalcredentials, balance, effectivebalance, slashed, activationeligibilityepoch, activationepoch, exitepoch, lastattestationslot, status, validator_names.name FROM validators LEFT JOIN validator_names ON validator_names.publickey = validators.pubkey WHERE validatorindex = ANY($1) OR pubkey = ANY($2) ORDER BY validatorindex", pq.Array(queryIndices), queryPubkeys)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT validatorindex, pubkey, withdrawableepoch, withdrawalcredentials, balance, effectivebalance, slashed, activationeligibilityepoch, activationepoch, exitepoch, lastattestationslot, status, validator_names.name FROM validators LEFT JOIN validator_names ON validator_names.publickey = validators.pubkey WHERE validatorindex = ANY($1) OR pubkey = ANY($2) ORDER BY validatorindex", pq.Array(queryIndices), queryPubkeys)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -673,6 +820,88 @@ func ApiValidator(w http.ResponseWriter, r *http.Request) {
 	returnQueryResults(rows, j, r)
 }
 
+// ApiValidatorActivationStatus godoc
+// @Summary Get the activation status of up to 100 validators, including validators whose deposits are not yet processed by the beacon chain
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/activation [get]
+func ApiValidatorActivationStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	vars := mux.Vars(r)
+	maxValidators := getUserPremium(r).MaxValidators
+
+	queryIndices, queryPubkeys, err := parseApiValidatorParam(vars["indexOrPubkey"], maxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	validators := []struct {
+		Index                      uint64 `db:"validatorindex"`
+		PublicKey                  []byte `db:"pubkey"`
+		Status                     string `db:"status"`
+		ActivationEligibilityEpoch uint64 `db:"activationeligibilityepoch"`
+		ActivationEpoch            uint64 `db:"activationepoch"`
+	}{}
+	err = db.DB.SelectContext(r.Context(), &validators, `
+		SELECT validatorindex, pubkey, status, activationeligibilityepoch, activationepoch
+		FROM validators
+		WHERE validatorindex = ANY($1) OR pubkey = ANY($2)
+		ORDER BY validatorindex`, pq.Array(queryIndices), queryPubkeys)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := []interface{}{}
+	for _, v := range validators {
+		status := map[string]interface{}{
+			"validatorindex": v.Index,
+			"pubkey":         fmt.Sprintf("0x%x", v.PublicKey),
+			"status":         v.Status,
+		}
+		if v.ActivationEligibilityEpoch != 9223372036854775807 {
+			status["activationeligibilityepoch"] = v.ActivationEligibilityEpoch
+		}
+		if v.ActivationEpoch != 9223372036854775807 {
+			status["activationepoch"] = v.ActivationEpoch
+		}
+		data = append(data, status)
+	}
+
+	// public keys without a validator might still have deposits that are not yet processed by the beacon chain
+	pendingDeposits := []*types.Eth1PendingDeposit{}
+	err = db.DB.SelectContext(r.Context(), &pendingDeposits, `SELECT * FROM eth1_pending_deposits WHERE publickey = ANY($1) ORDER BY last_block_number`, queryPubkeys)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	for _, d := range pendingDeposits {
+		status := map[string]interface{}{
+			"pubkey":                    fmt.Sprintf("0x%x", d.PublicKey),
+			"status":                    "deposited",
+			"deposited_amount":          d.ValidAmount,
+			"estimated_inclusion_epoch": d.EstimatedInclusionEpoch,
+		}
+		if d.ValidAmount < d.Amount {
+			status["status"] = "deposited_invalid"
+		}
+		if d.QueuePosition != nil && d.EstimatedActivationEpoch != nil {
+			status["status"] = "deposited_valid"
+			status["queue_position"] = *d.QueuePosition
+			status["estimated_activation_epoch"] = *d.EstimatedActivationEpoch
+		}
+		data = append(data, status)
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
 // ApiValidatorDailyStats godoc
 // @Summary Get the daily validator stats by the validator index
 // @Tags Validator
@@ -688,7 +917,7 @@ func ApiValidatorDailyStats(w http.ResponseWriter, r *http.Request) {
 
 	index := vars["index"]
 
-	rows, err := db.DB.Query("SELECT * FROM validator_stats WHERE validatorindex = $1 ORDER BY day DESC", index)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT * FROM validator_stats WHERE validatorindex = $1 ORDER BY day DESC", index)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -703,6 +932,8 @@ func ApiValidatorDailyStats(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  eth1address path string true "Eth1 address from which the validator deposits were sent"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor, enables cursor pagination"
+// @Param  limit query int false "Number of results per page (max 100), enables cursor pagination"
 // @Success 200 {object} string
 // @Router /api/v1/validator/eth1/{address} [get]
 func ApiValidatorByEth1Address(w http.ResponseWriter, r *http.Request) {
@@ -719,21 +950,60 @@ func ApiValidatorByEth1Address(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT publickey, validatorindex, valid_signature FROM eth1_deposits LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey WHERE from_address = $1 GROUP BY publickey, validatorindex, valid_signature ORDER BY validatorindex;", eth1Address)
+	page, err := parseApiPage(r, 2)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	if !page.Enabled {
+		rows, err := db.DB.QueryContext(r.Context(), "SELECT publickey, validatorindex, valid_signature FROM eth1_deposits LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey WHERE from_address = $1 GROUP BY publickey, validatorindex, valid_signature ORDER BY validatorindex;", eth1Address)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		defer rows.Close()
+
+		returnQueryResults(rows, j, r)
+		return
+	}
+
+	// validators without index have not been activated yet, so the keyset uses the public key instead
+	cursorPubkey, err := page.cursorBytes(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorValidSignature, err := page.cursorBool(1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT publickey, validatorindex, valid_signature
+		FROM eth1_deposits
+		LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey
+		WHERE from_address = $1 AND ($2::bytea IS NULL OR (publickey, valid_signature) > ($2, $3::bool))
+		GROUP BY publickey, validatorindex, valid_signature
+		ORDER BY publickey, valid_signature
+		LIMIT $4`, eth1Address, cursorPubkey, cursorValidSignature, page.Limit+1)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
 	defer rows.Close()
 
-	returnQueryResults(rows, j, r)
+	returnPaginatedQueryResults(rows, j, r, page, "publickey", "valid_signature")
 }
 
 // ApiValidator godoc
-// @Summary Get the balance history (last 100 epochs) of up to 100 validators
+// @Summary Get the balance history of up to 100 validators, either the last 100 epochs or downsampled to days or weeks
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  resolution query string false "epoch (default), day or week, the day and week buckets contain the start, end, min and max balance"
+// @Param  limit query int false "Number of day or week buckets per validator, defaults to the whole history, at most 1000"
 // @Success 200 {object} string
 // @Router /api/v1/validator/{indexOrPubkey}/balancehistory [get]
 func ApiValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
@@ -750,14 +1020,95 @@ func ApiValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT validator_balances_p.* FROM validator_balances_p LEFT JOIN validators ON validators.validatorindex = validator_balances_p.validatorindex WHERE week >= ((SELECT MAX(epoch) FROM epochs)-100)/(225*7) AND (validators.validatorindex = ANY($1) OR validators.pubkey = ANY($2)) ORDER BY epoch DESC, validatorindex LIMIT 100", pq.Array(queryIndices), queryPubkeys)
+	indices, err := resolveApiValidatorIndices(r.Context(), queryIndices, queryPubkeys)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResults(rows, j, r)
+	resolution := r.URL.Query().Get("resolution")
+	if resolution != "" && resolution != "epoch" {
+		bucketDays, ok := apiBalanceHistoryResolutions[resolution]
+		if !ok {
+			sendErrorResponse(j, r.URL.String(), "invalid resolution provided, expected epoch, day or week")
+			return
+		}
+		sendValidatorBalanceHistoryBuckets(j, r, indices, bucketDays)
+		return
+	}
+
+	startEpoch := uint64(0)
+	if latestEpoch := services.LatestEpoch(); latestEpoch > 100 {
+		startEpoch = latestEpoch - 100
+	}
+	balances, err := db.Balances.GetBalanceHistory(indices, startEpoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving balance history for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sort.SliceStable(balances, func(i, j int) bool {
+		if balances[i].Epoch != balances[j].Epoch {
+			return balances[i].Epoch > balances[j].Epoch
+		}
+		return balances[i].Validatorindex < balances[j].Validatorindex
+	})
+	if len(balances) > 100 {
+		balances = balances[:100]
+	}
+
+	data := make([]interface{}, len(balances))
+	for i, b := range balances {
+		data[i] = b
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// apiBalanceHistoryResolutions are the bucket sizes in days of the downsampled balance history
+var apiBalanceHistoryResolutions = map[string]uint64{
+	"day":  1,
+	"week": 7,
+}
+
+// apiMaxBalanceHistoryBuckets is the maximum number of buckets per validator of the downsampled balance history
+const apiMaxBalanceHistoryBuckets = 1000
+
+// sendValidatorBalanceHistoryBuckets sends the balance history of the validators downsampled to buckets of bucketDays
+// days, the number of buckets is limited by the limit query parameter
+func sendValidatorBalanceHistoryBuckets(j *json.Encoder, r *http.Request, indices []uint64, bucketDays uint64) {
+	limit := uint64(apiMaxBalanceHistoryBuckets)
+	if r.URL.Query().Get("limit") != "" {
+		var err error
+		limit, err = strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+		if err != nil || limit == 0 || limit > apiMaxBalanceHistoryBuckets {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("invalid limit provided, expected 1 to %v", apiMaxBalanceHistoryBuckets))
+			return
+		}
+	}
+
+	startEpoch := uint64(0)
+	if epochs := limit * bucketDays * utils.EpochsPerDay(); services.LatestEpoch() > epochs {
+		startEpoch = services.LatestEpoch() - epochs
+	}
+	buckets, err := db.Balances.GetBalanceHistoryBuckets(indices, startEpoch, bucketDays)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving balance history buckets for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, 0, len(buckets))
+	perValidator := map[uint64]uint64{}
+	for _, b := range buckets {
+		// the range is not aligned to the buckets, so the partial bucket of the start epoch can exceed the limit
+		if perValidator[b.Validatorindex] >= limit {
+			continue
+		}
+		perValidator[b.Validatorindex]++
+		data = append(data, b)
+	}
+	sendOKResponse(j, r.URL.String(), data)
 }
 
 // ApiValidatorPerformance godoc
@@ -781,7 +1132,7 @@ func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT validator_performance.* FROM validator_performance LEFT JOIN validators ON validators.validatorindex = validator_performance.validatorindex WHERE validator_performance.validatorindex = ANY($1) OR validators.pubkey = ANY($2) ORDER BY validatorindex", pq.Array(queryIndices), queryPubkeys)
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT validator_performance.* FROM validator_performance LEFT JOIN validators ON validators.validatorindex = validator_performance.validatorindex WHERE validator_performance.validatorindex = ANY($1) OR validators.pubkey = ANY($2) ORDER BY validatorindex", pq.Array(queryIndices), queryPubkeys)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -796,6 +1147,7 @@ func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  index path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  formula query string false "Effectiveness formula: inclusion_distance (default) or altair"
 // @Success 200 {object} string
 // @Router /api/v1/validator/{indexOrPubkey}/attestationeffectiveness [get]
 func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request) {
@@ -818,7 +1170,13 @@ func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	rows, err := db.DB.Query(`
+	formula := r.URL.Query().Get("formula")
+	if formula != "" && formula != db.DefaultEffectivenessFormula {
+		sendValidatorsEffectiveness(j, r, formula, epoch, queryIndices, queryPubkeys)
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
 		SELECT aa.validatorindex, validators.pubkey, COALESCE(
 			1 / AVG(1 + inclusionslot - COALESCE((
 				SELECT MIN(slot)
@@ -835,7 +1193,7 @@ func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request
 		epoch, pq.Array(queryIndices), queryPubkeys)
 
 	if err != nil {
-		logger.Error(err)
+		requestLogger(r).Error(err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
@@ -844,6 +1202,183 @@ func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request
 	returnQueryResults(rows, j, r)
 }
 
+// sendValidatorsEffectiveness responds with the effectiveness of the validators according to formula in the same format as the default formula
+func sendValidatorsEffectiveness(j *json.Encoder, r *http.Request, formula string, epoch int64, queryIndices []uint64, queryPubkeys pq.ByteaArray) {
+	if db.GetEffectivenessFormula(formula) == nil {
+		sendErrorResponse(j, r.URL.String(), "invalid formula provided")
+		return
+	}
+
+	indices, err := db.GetValidatorIndicesByIndicesOrPubkeys(r.Context(), queryIndices, queryPubkeys)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	pubkeys, err := db.GetValidatorPublicKeys(r.Context(), indices)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	effectiveness, err := db.GetValidatorsEffectiveness(r.Context(), indices, uint64(epoch)+1, services.LatestEpoch())
+	if err != nil {
+		requestLogger(r).Errorf("error computing %v effectiveness: %v", formula, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := []interface{}{}
+	for _, index := range indices {
+		for _, e := range effectiveness[index] {
+			if e.Formula.Name != formula {
+				continue
+			}
+			data = append(data, map[string]interface{}{
+				"validatorindex":            index,
+				"pubkey":                    fmt.Sprintf("0x%x", pubkeys[index]),
+				"formula":                   formula,
+				"attestation_effectiveness": e.Effectiveness / 100,
+			})
+		}
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiValidatorAttestationCorrectness godoc
+// @Summary Get the share of wrong source, target and head votes of up to 100 validators
+// @Tags Validator
+// @Description Returns the number of evaluated attestation assignments and the number of wrong source, target and head votes of the validators, missed attestations count as wrong votes. Only finalized epochs are evaluated.
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  days query int false "Number of days (default 7, max 365)"
+// @Success 200 {object} types.ApiResponse{data=[]types.ValidatorAttestationCorrectness}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/attestationcorrectness [get]
+func ApiValidatorAttestationCorrectness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	vars := mux.Vars(r)
+	maxValidators := getUserPremium(r).MaxValidators
+
+	queryIndices, queryPubkeys, err := parseApiValidatorParam(vars["indexOrPubkey"], maxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	days := uint64(7)
+	if r.URL.Query().Get("days") != "" {
+		days, err = strconv.ParseUint(r.URL.Query().Get("days"), 10, 64)
+		if err != nil || days == 0 || days > 365 {
+			sendErrorResponse(j, r.URL.String(), "invalid days provided")
+			return
+		}
+	}
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	startEpoch := uint64(0)
+	if services.LatestEpoch() > days*epochsPerDay {
+		startEpoch = services.LatestEpoch() - days*epochsPerDay
+	}
+
+	indices, err := db.GetValidatorIndicesByIndicesOrPubkeys(r.Context(), queryIndices, queryPubkeys)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	correctness, err := db.GetValidatorsAttestationCorrectness(r.Context(), indices, startEpoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving attestation correctness for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(correctness))
+	for i, c := range correctness {
+		data[i] = c
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiValidatorEffectivenessHistory godoc
+// @Summary Get the daily attestation effectiveness of up to 100 validators, 1 = perfect effectiveness
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  formula query string false "Effectiveness formula: inclusion_distance (default) or altair"
+// @Param  days query int false "Number of days (default 30, max 365)"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/effectivenesshistory [get]
+func ApiValidatorEffectivenessHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	vars := mux.Vars(r)
+	q := r.URL.Query()
+	maxValidators := getUserPremium(r).MaxValidators
+
+	queryIndices, queryPubkeys, err := parseApiValidatorParam(vars["indexOrPubkey"], maxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	formula := db.DefaultEffectivenessFormula
+	if q.Get("formula") != "" {
+		formula = q.Get("formula")
+	}
+	if db.GetEffectivenessFormula(formula) == nil {
+		sendErrorResponse(j, r.URL.String(), "invalid formula provided")
+		return
+	}
+
+	days := uint64(30)
+	if q.Get("days") != "" {
+		days, err = strconv.ParseUint(q.Get("days"), 10, 64)
+		if err != nil || days == 0 || days > 365 {
+			sendErrorResponse(j, r.URL.String(), "invalid days provided")
+			return
+		}
+	}
+
+	if len(queryPubkeys) > 0 {
+		pubkeyIndices, err := db.GetValidatorIndicesByPubkeys(r.Context(), queryPubkeys)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		queryIndices = append(queryIndices, pubkeyIndices...)
+	}
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	startDay := uint64(0)
+	if currentDay := services.LatestEpoch() / epochsPerDay; currentDay > days {
+		startDay = currentDay - days
+	}
+
+	history, err := db.GetValidatorEffectivenessHistory(r.Context(), queryIndices, formula, startDay)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving %v effectiveness history: %v", formula, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(history))
+	for i, h := range history {
+		data[i] = map[string]interface{}{
+			"validatorindex":            h.ValidatorIndex,
+			"day":                       h.Day,
+			"formula":                   formula,
+			"attestation_effectiveness": h.Effectiveness / 100,
+		}
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
 // ApiValidatorAttestationEfficiency godoc
 // @Summary Get the current performance of up to 100 validators
 // @Tags Validator
@@ -873,7 +1408,7 @@ func ApiValidatorAttestationEfficiency(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := getAttestationEfficiencyQuery(epoch, queryIndices, queryPubkeys)
 	if err != nil {
-		logger.Error(err)
+		requestLogger(r).Error(err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
@@ -912,7 +1447,7 @@ func ApiValidatorLeaderboard(w http.ResponseWriter, r *http.Request) {
 
 	j := json.NewEncoder(w)
 
-	rows, err := db.DB.Query(`
+	rows, err := db.DB.QueryContext(r.Context(), `
 			SELECT 
 				validator_performance.*
 			FROM validator_performance 
@@ -931,6 +1466,8 @@ func ApiValidatorLeaderboard(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor, enables cursor pagination"
+// @Param  limit query int false "Number of results per page (max 100), enables cursor pagination"
 // @Success 200 {object} string
 // @Router /api/v1/validator/{indexOrPubkey}/deposits [get]
 func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
@@ -947,14 +1484,50 @@ func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT eth1_deposits.* FROM eth1_deposits LEFT JOIN validators ON validators.pubkey = eth1_deposits.publickey WHERE validators.validatorindex = ANY($1) or eth1_deposits.publickey = ANY($2)", pq.Array(queryIndices), queryPubkeys)
+	page, err := parseApiPage(r, 2)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	if !page.Enabled {
+		rows, err := db.DB.QueryContext(r.Context(), "SELECT eth1_deposits.* FROM eth1_deposits LEFT JOIN validators ON validators.pubkey = eth1_deposits.publickey WHERE validators.validatorindex = ANY($1) or eth1_deposits.publickey = ANY($2)", pq.Array(queryIndices), queryPubkeys)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		defer rows.Close()
+
+		returnQueryResults(rows, j, r)
+		return
+	}
+
+	cursorBlockNumber, err := page.cursorUint(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorMerkletreeIndex, err := page.cursorBytes(1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT eth1_deposits.*
+		FROM eth1_deposits
+		LEFT JOIN validators ON validators.pubkey = eth1_deposits.publickey
+		WHERE (validators.validatorindex = ANY($1) or eth1_deposits.publickey = ANY($2))
+			AND ($3::int IS NULL OR (eth1_deposits.block_number, eth1_deposits.merkletree_index) > ($3, $4::bytea))
+		ORDER BY eth1_deposits.block_number, eth1_deposits.merkletree_index
+		LIMIT $5`, pq.Array(queryIndices), queryPubkeys, cursorBlockNumber, cursorMerkletreeIndex, page.Limit+1)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
 	defer rows.Close()
 
-	returnQueryResults(rows, j, r)
+	returnPaginatedQueryResults(rows, j, r, page, "block_number", "merkletree_index")
 }
 
 // ApiValidatorAttestations godoc
@@ -962,6 +1535,8 @@ func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor, enables cursor pagination"
+// @Param  limit query int false "Number of results per page (max 100), enables cursor pagination"
 // @Success 200 {object} string
 // @Router /api/v1/validator/{indexOrPubkey}/attestations [get]
 func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
@@ -978,14 +1553,69 @@ func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT attestation_assignments_p.* FROM attestation_assignments_p LEFT JOIN validators ON validators.validatorindex = attestation_assignments_p.validatorindex WHERE (validators.validatorindex = ANY($1) OR validators.pubkey = ANY($2)) AND week >= $3 / 1575 AND epoch > $3 ORDER BY validatorindex, epoch desc LIMIT 100", pq.Array(queryIndices), queryPubkeys, services.LatestEpoch()-10)
+	page, err := parseApiPage(r, 2)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorValidatorIndex, err := page.cursorUint(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorEpoch, err := page.cursorUint(1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	indices, err := resolveApiValidatorIndices(r.Context(), queryIndices, queryPubkeys)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResults(rows, j, r)
+	// the keyset only covers the same epoch range as the legacy response, older assignments are available via the epoch endpoints
+	latestEpoch := services.LatestEpoch()
+	startEpoch := uint64(0)
+	if latestEpoch > 9 {
+		startEpoch = latestEpoch - 9
+	}
+	assignments, err := db.Attestations.GetAttestationAssignments(indices, startEpoch, latestEpoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving attestation assignments for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	if !page.Enabled {
+		if len(assignments) > 100 {
+			assignments = assignments[:100]
+		}
+		data := make([]interface{}, len(assignments))
+		for i, a := range assignments {
+			data[i] = a
+		}
+		sendOKResponse(j, r.URL.String(), data)
+		return
+	}
+
+	// the assignments are ordered by validatorindex and descending epoch, the page starts after the cursor
+	data := []interface{}{}
+	for _, a := range assignments {
+		if cursorValidatorIndex != nil && (a.Validatorindex < cursorValidatorIndex.(uint64) || (a.Validatorindex == cursorValidatorIndex.(uint64) && a.Epoch >= cursorEpoch.(uint64))) {
+			continue
+		}
+		data = append(data, a)
+		if uint64(len(data)) > page.Limit {
+			break
+		}
+	}
+
+	returnPaginatedResults(data, j, r, page, func(last interface{}) []interface{} {
+		a := last.(*types.ApiAttestationAssignment)
+		return []interface{}{a.Validatorindex, a.Epoch}
+	})
 }
 
 // ApiValidatorProposals godoc
@@ -993,6 +1623,8 @@ func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor, enables cursor pagination"
+// @Param  limit query int false "Number of results per page (max 100), enables cursor pagination"
 // @Success 200 {object} string
 // @Router /api/v1/validator/{indexOrPubkey}/proposals [get]
 func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
@@ -1009,7 +1641,86 @@ func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query("SELECT blocks.* FROM blocks LEFT JOIN validators on validators.validatorindex = blocks.proposer WHERE (proposer = ANY($1) OR validators.pubkey = ANY($2)) AND epoch > $3 ORDER BY proposer, epoch desc, slot desc LIMIT 100", pq.Array(queryIndices), queryPubkeys, services.LatestEpoch()-100)
+	page, err := parseApiPage(r, 2)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	if !page.Enabled {
+		rows, err := db.DB.QueryContext(r.Context(), "SELECT blocks.* FROM blocks LEFT JOIN validators on validators.validatorindex = blocks.proposer WHERE (proposer = ANY($1) OR validators.pubkey = ANY($2)) AND epoch > $3 ORDER BY proposer, epoch desc, slot desc LIMIT 100", pq.Array(queryIndices), queryPubkeys, services.LatestEpoch()-100)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		defer rows.Close()
+
+		returnQueryResults(rows, j, r)
+		return
+	}
+
+	cursorProposer, err := page.cursorUint(0)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	cursorSlot, err := page.cursorUint(1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	// like the legacy response only the proposals of the last 100 epochs are returned
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT blocks.*
+		FROM blocks
+		LEFT JOIN validators on validators.validatorindex = blocks.proposer
+		WHERE (proposer = ANY($1) OR validators.pubkey = ANY($2)) AND epoch > $6
+			AND ($3::int IS NULL OR blocks.proposer > $3 OR (blocks.proposer = $3 AND blocks.slot < $4))
+		ORDER BY proposer, slot desc
+		LIMIT $5`, pq.Array(queryIndices), queryPubkeys, cursorProposer, cursorSlot, page.Limit+1, services.LatestEpoch()-100)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	defer rows.Close()
+
+	returnPaginatedQueryResults(rows, j, r, page, "proposer", "slot")
+}
+
+// ApiValidatorFeeRecipients godoc
+// @Summary Get the fee recipients that up to 100 validators used in their proposed blocks
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} string
+// @Router /api/v1/validator/{indexOrPubkey}/feerecipients [get]
+func ApiValidatorFeeRecipients(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	vars := mux.Vars(r)
+	maxValidators := getUserPremium(r).MaxValidators
+
+	queryIndices, queryPubkeys, err := parseApiValidatorParam(vars["indexOrPubkey"], maxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT
+			blocks.proposer AS validatorindex,
+			blocks.exec_fee_recipient AS fee_recipient,
+			COUNT(*) AS blocks_count,
+			MIN(blocks.slot) AS first_slot,
+			MAX(blocks.slot) AS last_slot
+		FROM blocks
+		LEFT JOIN validators ON validators.validatorindex = blocks.proposer
+		WHERE (blocks.proposer = ANY($1) OR validators.pubkey = ANY($2)) AND blocks.status = '1' AND blocks.exec_fee_recipient IS NOT NULL
+		GROUP BY blocks.proposer, blocks.exec_fee_recipient
+		ORDER BY blocks.proposer, last_slot DESC`, pq.Array(queryIndices), queryPubkeys)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -1031,7 +1742,7 @@ func ApiGraffitiwall(w http.ResponseWriter, r *http.Request) {
 
 	j := json.NewEncoder(w)
 
-	rows, err := db.DB.Query("SELECT x, y, color, slot, validator FROM graffitiwall ORDER BY x, y LIMIT 1000000")
+	rows, err := db.DB.QueryContext(r.Context(), "SELECT x, y, color, slot, validator FROM graffitiwall ORDER BY x, y LIMIT 1000000")
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -1041,6 +1752,205 @@ func ApiGraffitiwall(w http.ResponseWriter, r *http.Request) {
 	returnQueryResults(rows, j, r)
 }
 
+// ApiAttestationAggregation godoc
+// @Summary Get the daily attestation aggregation statistics per consensus client of the block proposers, the client is guessed from the block graffiti
+// @Tags Charts
+// @Produce  json
+// @Param  start_day query int false "First day since genesis (default: 30 days before end_day)"
+// @Param  end_day query int false "Last day since genesis (default: current day)"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/attestations/aggregation/daily [get]
+func ApiAttestationAggregation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	endDay := services.LatestEpoch() / epochsPerDay
+	if q.Get("end_day") != "" {
+		d, err := strconv.ParseUint(q.Get("end_day"), 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid end_day provided")
+			return
+		}
+		endDay = d
+	}
+
+	startDay := uint64(0)
+	if endDay > 30 {
+		startDay = endDay - 30
+	}
+	if q.Get("start_day") != "" {
+		d, err := strconv.ParseUint(q.Get("start_day"), 10, 64)
+		if err != nil || d > endDay {
+			sendErrorResponse(j, r.URL.String(), "invalid start_day provided")
+			return
+		}
+		startDay = d
+	}
+	if endDay-startDay > 365 {
+		sendErrorResponse(j, r.URL.String(), "only a maximum of 365 days can be requested")
+		return
+	}
+
+	stats, err := db.GetDailyAttestationAggregationStats(r.Context(), startDay, endDay)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving daily attestation aggregation stats: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = map[string]interface{}{
+			"day":                      s.Day,
+			"client":                   s.Client,
+			"blocks":                   s.Blocks,
+			"aggregates":               s.Aggregates,
+			"committees":               s.Committees,
+			"aggregates_per_committee": s.AggregatesPerCommittee(),
+			"attestations":             s.Attestations,
+			"redundant_attestations":   s.RedundantAttestations,
+			"redundant_aggregates":     s.RedundantAggregates,
+			"redundancy_rate":          s.RedundancyRate(),
+			"bytes_saved":              s.BytesSaved,
+		}
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiRocketpoolRETHHistory godoc
+// @Summary Get the rETH exchange rate of the Rocketpool contract and the secondary-market rate of the configured DEX pools over time
+// @Tags Rocketpool
+// @Produce  json
+// @Param  start query int false "Start unix timestamp (default: 30 days before end)"
+// @Param  end query int false "End unix timestamp (default: now)"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/rocketpool/reth [get]
+func ApiRocketpoolRETHHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	end := time.Now()
+	if q.Get("end") != "" {
+		ts, err := strconv.ParseInt(q.Get("end"), 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid end provided")
+			return
+		}
+		end = time.Unix(ts, 0)
+	}
+
+	start := end.Add(-time.Hour * 24 * 30)
+	if q.Get("start") != "" {
+		ts, err := strconv.ParseInt(q.Get("start"), 10, 64)
+		if err != nil || time.Unix(ts, 0).After(end) {
+			sendErrorResponse(j, r.URL.String(), "invalid start provided")
+			return
+		}
+		start = time.Unix(ts, 0)
+	}
+	if end.Sub(start) > time.Hour*24*365 {
+		sendErrorResponse(j, r.URL.String(), "only a maximum of 365 days can be requested")
+		return
+	}
+
+	rates, err := db.GetRocketpoolRETHHistory(r.Context(), start, end)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving rocketpool rETH history: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(rates))
+	for i, rate := range rates {
+		data[i] = map[string]interface{}{
+			"eth1_block":    rate.Eth1Block,
+			"ts":            rate.Ts.Unix(),
+			"exchange_rate": rate.ExchangeRate,
+			"market_rate":   rate.MarketRate,
+			"premium":       rate.Premium(),
+		}
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiRocketpoolNodesHistory godoc
+// @Summary Get the rpl stake, collateral ratio (rpl stake as a multiple of the minimum stake) and minipool count of the Rocketpool nodes over time, summed over all nodes or of a single node. The snapshots are taken every configured number of eth1-blocks and backfilled since the deployment of the contracts.
+// @Tags Rocketpool
+// @Produce  json
+// @Param  start query int false "Start unix timestamp (default: 365 days before end)"
+// @Param  end query int false "End unix timestamp (default: now)"
+// @Param  node query string false "Address of a node"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/rocketpool/nodes/history [get]
+func ApiRocketpoolNodesHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	end := time.Now()
+	if q.Get("end") != "" {
+		ts, err := strconv.ParseInt(q.Get("end"), 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid end provided")
+			return
+		}
+		end = time.Unix(ts, 0)
+	}
+
+	start := end.Add(-time.Hour * 24 * 365)
+	if q.Get("start") != "" {
+		ts, err := strconv.ParseInt(q.Get("start"), 10, 64)
+		if err != nil || time.Unix(ts, 0).After(end) {
+			sendErrorResponse(j, r.URL.String(), "invalid start provided")
+			return
+		}
+		start = time.Unix(ts, 0)
+	}
+
+	var nodeAddress []byte
+	if q.Get("node") != "" {
+		if !common.IsHexAddress(q.Get("node")) {
+			sendErrorResponse(j, r.URL.String(), "invalid node address provided")
+			return
+		}
+		nodeAddress = common.HexToAddress(q.Get("node")).Bytes()
+	}
+
+	history, err := db.GetRocketpoolNodesHistory(r.Context(), start, end, nodeAddress)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving rocketpool nodes history: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(history))
+	for i, h := range history {
+		data[i] = map[string]interface{}{
+			"eth1_block":       h.Eth1Block,
+			"ts":               h.Ts.Unix(),
+			"nodes":            h.Nodes,
+			"rpl_stake":        h.RPLStake,
+			"min_rpl_stake":    h.MinRPLStake,
+			"max_rpl_stake":    h.MaxRPLStake,
+			"collateral_ratio": h.CollateralRatio(),
+			"minipools":        h.Minipools,
+		}
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
 // ApiChart godoc
 // @Summary Returns charts from the page https://beaconcha.in/charts as PNG
 // @Tags Charts
@@ -1056,7 +1966,7 @@ func ApiChart(w http.ResponseWriter, r *http.Request) {
 	chartName := vars["chart"]
 
 	var image []byte
-	err := db.DB.Get(&image, "SELECT image FROM chart_images WHERE name = $1", chartName)
+	err := db.DB.GetContext(r.Context(), &image, "SELECT image FROM chart_images WHERE name = $1", chartName)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "no data available for the requested chart")
 		return
@@ -1111,7 +2021,7 @@ func getTokenByCode(w http.ResponseWriter, r *http.Request) {
 	deviceName := getDeviceNameFromUA(r.Header.Get("User-Agent"))
 
 	// Check if redirect URI is correct
-	_, err := db.GetAppDataFromRedirectUri(redirectURI)
+	_, err := db.GetAppDataFromRedirectUri(r.Context(), redirectURI)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		utils.SendOAuthErrorResponse(j, r.URL.String(), utils.InvalidRequest, "redirect_uri do not match")
@@ -1122,9 +2032,9 @@ func getTokenByCode(w http.ResponseWriter, r *http.Request) {
 	codeHashed := utils.HashAndEncode(code)
 
 	// Check if code entry exists and isn't expired (codes expire after 5 minutes)
-	codeAuthData, err := db.GetUserAuthDataByAuthorizationCode(codeHashed)
+	codeAuthData, err := db.GetUserAuthDataByAuthorizationCode(r.Context(), codeHashed)
 	if err != nil {
-		logger.Errorf("Error hashed code can not be found in table: %v | Error: %v", codeHashed, err)
+		requestLogger(r).Errorf("Error hashed code can not be found in table: %v | Error: %v", codeHashed, err)
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.SendOAuthErrorResponse(j, r.URL.String(), utils.AccessDenied, "access_token or refresh_token invalid")
 		return
@@ -1142,14 +2052,14 @@ func getTokenByCode(w http.ResponseWriter, r *http.Request) {
 	refreshTokenHashed := utils.HashAndEncode(refreshToken) // save hashed in db
 
 	// save refreshtoken hashed in db
-	deviceID, errDb := db.InsertUserDevice(codeAuthData.UserID, refreshTokenHashed, deviceName, codeAuthData.AppID)
+	deviceID, errDb := db.InsertUserDevice(r.Context(), codeAuthData.UserID, refreshTokenHashed, deviceName, codeAuthData.AppID)
 	if errDb != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		utils.SendOAuthErrorResponse(j, r.URL.String(), utils.ServerError, "can not store auth info")
 		return
 	}
 
-	pkg, err := db.GetUserPremiumPackage(codeAuthData.UserID)
+	pkg, err := db.GetUserPremiumPackage(r.Context(), codeAuthData.UserID)
 	if err != nil {
 		pkg.Package = "standard"
 	}
@@ -1180,28 +2090,28 @@ func getTokenByRefresh(w http.ResponseWriter, r *http.Request) {
 	// hash refreshtoken
 	refreshTokenHashed := utils.HashAndEncode(refreshToken)
 
-	logger.Info("access token:", accessToken, "refreshToken: ", refreshToken)
+	requestLogger(r).Info("access token:", accessToken, "refreshToken: ", refreshToken)
 
 	// Extract userId from JWT. Note that this is just an unvalidated claim!
 	// Do not use userIDClaim as userID until confirmed by refreshToken validation
 	unsafeClaims, err := utils.UnsafeGetClaims(accessToken)
 	if err != nil {
-		logger.Errorf("Error access_token claim: %v", err)
+		requestLogger(r).Errorf("Error access_token claim: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
 		utils.SendOAuthErrorResponse(j, r.URL.String(), utils.InvalidRequest, "access_token validation failed")
 		return
 	}
 
 	// confirm all claims via db lookup and refreshtoken check
-	userID, err := db.GetByRefreshToken(unsafeClaims.UserID, unsafeClaims.AppID, unsafeClaims.DeviceID, refreshTokenHashed)
+	userID, err := db.GetByRefreshToken(r.Context(), unsafeClaims.UserID, unsafeClaims.AppID, unsafeClaims.DeviceID, refreshTokenHashed)
 	if err != nil {
-		logger.Errorf("Error refreshtoken check: %v | %v | %v", unsafeClaims.UserID, refreshTokenHashed, err)
+		requestLogger(r).Errorf("Error refreshtoken check: %v | %v | %v", unsafeClaims.UserID, refreshTokenHashed, err)
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.SendOAuthErrorResponse(j, r.URL.String(), utils.UnauthorizedClient, "invalid token credentials")
 		return
 	}
 
-	pkg, err := db.GetUserPremiumPackage(userID)
+	pkg, err := db.GetUserPremiumPackage(r.Context(), userID)
 	if err != nil {
 		pkg.Package = "standard"
 	}
@@ -1263,7 +2173,7 @@ func MobileNotificationUpdatePOST(w http.ResponseWriter, r *http.Request) {
 
 	claims := getAuthClaims(r)
 
-	err2 := db.MobileNotificatonTokenUpdate(claims.UserID, claims.DeviceID, notifyToken)
+	err2 := db.MobileNotificatonTokenUpdate(r.Context(), claims.UserID, claims.DeviceID, notifyToken)
 	if err2 != nil {
 		sendErrorResponse(j, r.URL.String(), "Can not save notify token")
 		return
@@ -1283,14 +2193,14 @@ func RegisterEthpoolSubscription(w http.ResponseWriter, r *http.Request) {
 	localSignature := hmacSign(fmt.Sprintf("ETHPOOL %v %v", pkg, ethpoolUserID))
 	if signature != localSignature {
 		w.WriteHeader(http.StatusInternalServerError)
-		logger.Errorf("signature missmatch %v | %v", signature, localSignature)
+		requestLogger(r).Errorf("signature missmatch %v | %v", signature, localSignature)
 		sendErrorResponse(j, r.URL.String(), "Unauthorized: signature not valid")
 		return
 	}
 
 	claims := getAuthClaims(r)
 
-	subscriptionCount, err := db.GetAppSubscriptionCount(claims.UserID)
+	subscriptionCount, err := db.GetAppSubscriptionCount(r.Context(), claims.UserID)
 	if err != nil || subscriptionCount >= 5 {
 		w.WriteHeader(http.StatusInternalServerError)
 		sendErrorResponse(j, r.URL.String(), "reached max subscription limit")
@@ -1309,9 +2219,9 @@ func RegisterEthpoolSubscription(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = db.InsertMobileSubscription(claims.UserID, parsedBase, parsedBase.Transaction.Type, parsedBase.Transaction.Receipt, 0, "", "")
+	err = db.InsertMobileSubscription(r.Context(), claims.UserID, parsedBase, parsedBase.Transaction.Type, parsedBase.Transaction.Receipt, 0, "", "")
 	if err != nil {
-		logger.Errorf("could not save subscription data %v", err)
+		requestLogger(r).Errorf("could not save subscription data %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		sendErrorResponse(j, r.URL.String(), "Can not save subscription data")
 		return
@@ -1336,14 +2246,14 @@ func RegisterMobileSubscriptions(w http.ResponseWriter, r *http.Request) {
 	err := json.Unmarshal(gorillacontext.Get(r, utils.JsonBodyNakedKey).([]byte), &parsedBase)
 
 	if err != nil {
-		logger.Errorf("error parsing body | err: %v %v", err)
+		requestLogger(r).Errorf("error parsing body | err: %v %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not parse body")
 		return
 	}
 
 	claims := getAuthClaims(r)
 
-	subscriptionCount, err := db.GetAppSubscriptionCount(claims.UserID)
+	subscriptionCount, err := db.GetAppSubscriptionCount(r.Context(), claims.UserID)
 	if err != nil || subscriptionCount >= 5 {
 		sendErrorResponse(j, r.URL.String(), "reached max subscription limit")
 		return
@@ -1364,15 +2274,15 @@ func RegisterMobileSubscriptions(w http.ResponseWriter, r *http.Request) {
 	validationResult, _ := exporter.VerifyReceipt(nil, verifyPackage)
 	parsedBase.Valid = validationResult.Valid
 
-	err = db.InsertMobileSubscription(claims.UserID, parsedBase, parsedBase.Transaction.Type, parsedBase.Transaction.Receipt, validationResult.ExpirationDate, validationResult.RejectReason, "")
+	err = db.InsertMobileSubscription(r.Context(), claims.UserID, parsedBase, parsedBase.Transaction.Type, parsedBase.Transaction.Receipt, validationResult.ExpirationDate, validationResult.RejectReason, "")
 	if err != nil {
-		logger.Errorf("could not save subscription data %v", err)
+		requestLogger(r).Errorf("could not save subscription data %v", err)
 		sendErrorResponse(j, r.URL.String(), "Can not save subscription data")
 		return
 	}
 
 	if parsedBase.Valid == false {
-		logger.Errorf("receipt is not valid %v", validationResult.RejectReason)
+		requestLogger(r).Errorf("receipt is not valid %v", validationResult.RejectReason)
 		sendErrorResponse(j, r.URL.String(), "receipt is not valid")
 		return
 	}
@@ -1380,15 +2290,8 @@ func RegisterMobileSubscriptions(w http.ResponseWriter, r *http.Request) {
 	OKResponse(w, r)
 }
 
-type PremiumUser struct {
-	Package                string
-	MaxValidators          int
-	MaxStats               uint64
-	MaxNodes               uint64
-	WidgetSupport          bool
-	NotificationThresholds bool
-	NoAds                  bool
-}
+// PremiumUser are the entitlements of the premium package of a user
+type PremiumUser = types.Entitlements
 
 func getUserPremium(r *http.Request) PremiumUser {
 	var pkg string = ""
@@ -1409,38 +2312,7 @@ func getUserPremium(r *http.Request) PremiumUser {
 }
 
 func GetUserPremiumByPackage(pkg string) PremiumUser {
-	result := PremiumUser{
-		Package:                "standard",
-		MaxValidators:          100,
-		MaxStats:               180,
-		MaxNodes:               1,
-		WidgetSupport:          false,
-		NotificationThresholds: false,
-		NoAds:                  false,
-	}
-
-	if pkg == "" || pkg == "standard" {
-		return result
-	}
-
-	result.Package = pkg
-	result.MaxStats = 43200
-	result.NotificationThresholds = true
-	result.NoAds = true
-
-	if result.Package != "plankton" {
-		result.WidgetSupport = true
-	}
-
-	if result.Package == "goldfish" {
-		result.MaxNodes = 2
-	}
-	if result.Package == "whale" {
-		result.MaxValidators = 300
-		result.MaxNodes = 10
-	}
-
-	return result
+	return utils.PackageEntitlements(pkg)
 }
 
 func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request) {
@@ -1465,7 +2337,7 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query(
+	rows, err := db.DB.QueryContext(r.Context(),
 		"SELECT pubkey, effectivebalance, slashed, activationeligibilityepoch, "+
 			"activationepoch, exitepoch, lastattestationslot, status, validator_performance.* FROM validators "+
 			"LEFT JOIN validator_performance ON validators.validatorindex = validator_performance.validatorindex "+
@@ -1522,7 +2394,7 @@ func MobileDeviceSettings(w http.ResponseWriter, r *http.Request) {
 
 	claims := getAuthClaims(r)
 
-	rows, err := db.MobileDeviceSettingsSelect(claims.UserID, claims.DeviceID)
+	rows, err := db.MobileDeviceSettingsSelect(r.Context(), claims.UserID, claims.DeviceID)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -1559,7 +2431,7 @@ func MobileDeviceSettingsPOST(w http.ResponseWriter, r *http.Request) {
 		customDeviceID := FormValueOrJSON(r, "id")
 		temp, err := strconv.ParseUint(customDeviceID, 10, 64)
 		if err != nil {
-			logger.Errorf("error parsing id %v | err: %v", customDeviceID, err)
+			requestLogger(r).Errorf("error parsing id %v | err: %v", customDeviceID, err)
 			sendErrorResponse(j, r.URL.String(), "could not parse id")
 			return
 		}
@@ -1575,9 +2447,9 @@ func MobileDeviceSettingsPOST(w http.ResponseWriter, r *http.Request) {
 		userID = claims.UserID
 	}
 
-	rows, err := db.MobileDeviceSettingsUpdate(userID, userDeviceID, notifyEnabled, active)
+	rows, err := db.MobileDeviceSettingsUpdate(r.Context(), userID, userDeviceID, notifyEnabled, active)
 	if err != nil {
-		logger.Errorf("could not retrieve db results err: %v", err)
+		requestLogger(r).Errorf("could not retrieve db results err: %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
@@ -1611,7 +2483,7 @@ func MobileTagedValidators(w http.ResponseWriter, r *http.Request) {
 		Network:        utils.GetNetwork(),
 	}
 
-	validators, err2 := db.GetTaggedValidators(filter)
+	validators, err2 := db.GetTaggedValidators(r.Context(), filter)
 	if err2 != nil {
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
@@ -1663,23 +2535,23 @@ func ClientStats(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	validator, err := db.GetStatsValidator(claims.UserID, limit, offset)
+	validator, err := db.GetStatsValidator(r.Context(), claims.UserID, limit, offset)
 	if err != nil {
-		logger.Errorf("validator stat error : %v", err)
+		requestLogger(r).Errorf("validator stat error : %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve validator stats from db")
 		return
 	}
 
-	node, err := db.GetStatsNode(claims.UserID, limit, offset)
+	node, err := db.GetStatsNode(r.Context(), claims.UserID, limit, offset)
 	if err != nil {
-		logger.Errorf("node stat error : %v", err)
+		requestLogger(r).Errorf("node stat error : %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve beaconnode stats from db")
 		return
 	}
 
-	system, err := db.GetStatsSystem(claims.UserID, limit, offset)
+	system, err := db.GetStatsSystem(r.Context(), claims.UserID, limit, offset)
 	if err != nil {
-		logger.Errorf("system stat error : %v", err)
+		requestLogger(r).Errorf("system stat error : %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve system stats from db")
 		return
 	}
@@ -1749,7 +2621,7 @@ func clientStatsPost(w http.ResponseWriter, r *http.Request, apiKey, machine str
 		return
 	}
 
-	userData, err := db.GetUserIdByApiKey(apiKey)
+	userData, err := db.GetUserIdByApiKey(r.Context(), apiKey)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "no user found with api key")
 		return
@@ -1757,7 +2629,7 @@ func clientStatsPost(w http.ResponseWriter, r *http.Request, apiKey, machine str
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body | err: %v", err)
+		requestLogger(r).Errorf("error reading body | err: %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not read body")
 		return
 	}
@@ -1768,7 +2640,7 @@ func clientStatsPost(w http.ResponseWriter, r *http.Request, apiKey, machine str
 		var jsonObject map[string]interface{}
 		err = json.Unmarshal(body, &jsonObject)
 		if err != nil {
-			logger.Errorf("Could not parse stats (meta stats) general | %v ", err)
+			requestLogger(r).Errorf("Could not parse stats (meta stats) general | %v ", err)
 			sendErrorResponse(j, r.URL.String(), "capi rate limit reached, one process per machine per user each minute is allowed.")
 			return
 		}
@@ -1776,7 +2648,7 @@ func clientStatsPost(w http.ResponseWriter, r *http.Request, apiKey, machine str
 	}
 
 	if len(jsonObjects) >= 10 {
-		logger.Errorf("Max number of stat entries are 10", err)
+		requestLogger(r).Errorf("Max number of stat entries are 10", err)
 		sendErrorResponse(j, r.URL.String(), "Max number of stat entries are 10")
 		return
 	}
@@ -1790,6 +2662,7 @@ func clientStatsPost(w http.ResponseWriter, r *http.Request, apiKey, machine str
 	}
 
 	if result {
+		inferValidatorHosting(r, userData.ID)
 		OKResponse(w, r)
 		return
 	}
@@ -1800,7 +2673,7 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 	var parsedMeta *types.StatsMeta
 	err := mapstructure.Decode(body, &parsedMeta)
 	if err != nil {
-		logger.Errorf("Could not parse stats (meta stats) | %v ", err)
+		requestLogger(r).Errorf("Could not parse stats (meta stats) | %v ", err)
 		sendErrorResponse(j, r.URL.String(), "could not parse meta")
 		return false
 	}
@@ -1819,37 +2692,37 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 
 	maxNodes := GetUserPremiumByPackage(userData.Product.String).MaxNodes
 
-	count, err := db.GetStatsMachineCount(userData.ID)
+	count, err := db.GetStatsMachineCount(r.Context(), userData.ID)
 	if err != nil {
-		logger.Errorf("Could not get max machine count| %v", err)
+		requestLogger(r).Errorf("Could not get max machine count| %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not get machine count")
 		return false
 	}
 
 	if count > maxNodes {
-		logger.Errorf("User has reached max machine count | %v", err)
+		requestLogger(r).Errorf("User has reached max machine count | %v", err)
 		sendErrorResponse(j, r.URL.String(), "reached max machine count")
 		return false
 	}
 
-	tx, err := db.NewTransaction()
+	tx, err := db.NewTransaction(r.Context())
 	if err != nil {
-		logger.Errorf("Could not transact | %v", err)
+		requestLogger(r).Errorf("Could not transact | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not store")
 		return false
 	}
 	defer tx.Rollback()
 
-	id, err := db.InsertStatsMeta(tx, userData.ID, parsedMeta)
+	id, err := db.InsertStatsMeta(r.Context(), tx, userData.ID, parsedMeta)
 	if err != nil {
 		if strings.Contains(err.Error(), "no partition of relation") {
-			db.CreateNewStatsMetaPartition()
+			db.CreateNewStatsMetaPartition(r.Context())
 			tx.Rollback()
-			tx, err = db.NewTransaction()
-			id, err = db.InsertStatsMeta(tx, userData.ID, parsedMeta)
+			tx, err = db.NewTransaction(r.Context())
+			id, err = db.InsertStatsMeta(r.Context(), tx, userData.ID, parsedMeta)
 		}
 		if err != nil {
-			logger.Errorf("Could not store stats (meta stats) | %v", err)
+			requestLogger(r).Errorf("Could not store stats (meta stats) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not store meta")
 			return false
 		}
@@ -1861,24 +2734,24 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 		err = mapstructure.Decode(body, &parsedResponse)
 
 		if err != nil {
-			logger.Errorf("Could not parse stats (system stats) | %v", err)
+			requestLogger(r).Errorf("Could not parse stats (system stats) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not parse system")
 			return false
 		}
-		_, err := db.InsertStatsSystem(
+		_, err := db.InsertStatsSystem(r.Context(),
 			tx,
 			id,
 			parsedResponse,
 		)
 		if err != nil {
-			logger.Errorf("Could not store stats (system stats) | %v", err)
+			requestLogger(r).Errorf("Could not store stats (system stats) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not store system")
 			return false
 		}
 
 		err = tx.Commit()
 		if err != nil {
-			logger.Errorf("Could not store (tx commit) | %v", err)
+			requestLogger(r).Errorf("Could not store (tx commit) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not store")
 			return false
 		}
@@ -1889,18 +2762,18 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 	err = mapstructure.Decode(body, &parsedGeneral)
 
 	if err != nil {
-		logger.Errorf("Could not parse stats (process stats) | %v", err)
+		requestLogger(r).Errorf("Could not parse stats (process stats) | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not parse process")
 		return false
 	}
 
-	processGeneralID, err := db.InsertStatsProcessGeneral(
+	processGeneralID, err := db.InsertStatsProcessGeneral(r.Context(),
 		tx,
 		id,
 		parsedGeneral,
 	)
 	if err != nil {
-		logger.Errorf("Could not store stats (global process stats) | %v", err)
+		requestLogger(r).Errorf("Could not store stats (global process stats) | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not store global process")
 		return false
 	}
@@ -1910,18 +2783,18 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 		err = mapstructure.Decode(body, &parsedValidator)
 
 		if err != nil {
-			logger.Errorf("Could not parse stats (validator stats) | %v", err)
+			requestLogger(r).Errorf("Could not parse stats (validator stats) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not parse validator")
 			return false
 		}
 
-		_, err := db.InsertStatsValidator(
+		_, err := db.InsertStatsValidator(r.Context(),
 			tx,
 			processGeneralID,
 			parsedValidator,
 		)
 		if err != nil {
-			logger.Errorf("Could not store stats (validatorstats) | %v", err)
+			requestLogger(r).Errorf("Could not store stats (validatorstats) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not store validator")
 			return false
 		}
@@ -1931,18 +2804,18 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 		err = mapstructure.Decode(body, &parsedNode)
 
 		if err != nil {
-			logger.Errorf("Could not parse stats (node stats) | %v", err)
+			requestLogger(r).Errorf("Could not parse stats (node stats) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not parse node")
 			return false
 		}
 
-		_, err := db.InsertStatsBeaconnode(
+		_, err := db.InsertStatsBeaconnode(r.Context(),
 			tx,
 			processGeneralID,
 			parsedNode,
 		)
 		if err != nil {
-			logger.Errorf("Could not store stats (beaconnode) | %v", err)
+			requestLogger(r).Errorf("Could not store stats (beaconnode) | %v", err)
 			sendErrorResponse(j, r.URL.String(), "could not store beaconnode")
 			return false
 		}
@@ -1950,7 +2823,7 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 
 	err = tx.Commit()
 	if err != nil {
-		logger.Errorf("Could not store (tx commit) | %v", err)
+		requestLogger(r).Errorf("Could not store (tx commit) | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not store")
 		return false
 	}
@@ -1968,7 +2841,7 @@ func APIDashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 
 	queryValidators, err := parseValidatorsFromQueryString(q.Get("validators"), 100)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error parsing validators from query string")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error parsing validators from query string")
 		http.Error(w, "Invalid query", 400)
 		return
 	}
@@ -2002,9 +2875,9 @@ func APIDashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 		ORDER BY epoch ASC`
 
 	data := []*types.DashboardValidatorBalanceHistory{}
-	err = db.DB.Select(&data, query, queryValidatorsArr, queryOffsetEpoch)
+	err = db.DB.SelectContext(r.Context(), &data, query, queryValidatorsArr, queryOffsetEpoch)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator balance history")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator balance history")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -2019,7 +2892,7 @@ func APIDashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(balanceHistoryChartData)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -2087,6 +2960,25 @@ func sendOKResponse(j *json.Encoder, route string, data []interface{}) {
 	return
 }
 
+// resolveApiValidatorIndices returns the indices of the validators given by index or pubkey for the repositories, which
+// only look up validators by index
+func resolveApiValidatorIndices(ctx context.Context, indices []uint64, pubkeys pq.ByteaArray) ([]uint64, error) {
+	pubkeyIndices, err := db.GetValidatorIndicesByPubkeys(ctx, pubkeys)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]uint64, 0, len(indices)+len(pubkeyIndices))
+	seen := make(map[uint64]bool, len(indices)+len(pubkeyIndices))
+	for _, index := range append(indices, pubkeyIndices...) {
+		if !seen[index] {
+			seen[index] = true
+			resolved = append(resolved, index)
+		}
+	}
+	return resolved, nil
+}
+
 func parseApiValidatorParam(origParam string, limit int) (indices []uint64, pubkeys pq.ByteaArray, err error) {
 	params := strings.Split(origParam, ",")
 	if len(params) > limit {
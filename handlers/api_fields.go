@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ApiFieldSet lists the fields of an api response that can be selected via the fields query parameter, object and
+// array fields whose own fields can be selected (e.g. fields=validators.balance) map to the set of their fields
+type ApiFieldSet map[string]ApiFieldSet
+
+// apiMaxSelectedFields limits the number of fields a request can select
+const apiMaxSelectedFields = 100
+
+func newApiFieldSet(fields ...string) ApiFieldSet {
+	set := make(ApiFieldSet, len(fields))
+	for _, f := range fields {
+		set[f] = nil
+	}
+	return set
+}
+
+var apiValidatorColumns = []string{"validatorindex", "pubkey", "withdrawableepoch", "withdrawalcredentials", "balance", "effectivebalance", "slashed", "activationeligibilityepoch", "activationepoch", "exitepoch", "lastattestationslot", "status", "name"}
+
+var apiEpochColumns = []string{"epoch", "blockscount", "proposerslashingscount", "attesterslashingscount", "attestationscount", "depositscount", "voluntaryexitscount", "validatorscount", "averagevalidatorbalance", "totalvalidatorbalance", "finalized", "eligibleether", "globalparticipationrate", "votedether"}
+
+// ApiValidatorFields are the fields of /api/v1/validator/{indexOrPubkey} and /api/v1/validators
+var ApiValidatorFields = newApiFieldSet(apiValidatorColumns...)
+
+// ApiBlockFields are the fields of /api/v1/block/{slotOrHash} and /api/v1/epoch/{epoch}/blocks
+var ApiBlockFields = newApiFieldSet(
+	"epoch", "slot", "blockroot", "parentroot", "stateroot", "signature", "randaoreveal", "graffiti", "graffiti_text",
+	"eth1data_depositroot", "eth1data_depositcount", "eth1data_blockhash", "syncaggregate_bits", "syncaggregate_signature",
+	"syncaggregate_participation", "proposerslashingscount", "attesterslashingscount", "attestationscount", "depositscount",
+	"voluntaryexitscount", "proposer", "status", "exec_block_hash", "exec_block_number", "exec_fee_recipient", "exec_gas_used",
+	"exec_gas_limit", "exec_base_fee_per_gas", "exec_transactions_count", "exec_fee_reward",
+)
+
+// ApiDashboardFields are the sections of /api/v1/app/dashboard and their fields
+var ApiDashboardFields = ApiFieldSet{
+	"validators":    newApiFieldSet(append(apiValidatorColumns, "performance1d", "performance7d", "performance31d", "performance365d", "rank7d")...),
+	"effectiveness": newApiFieldSet("validatorindex", "pubkey", "attestation_efficiency"),
+	"currentEpoch":  newApiFieldSet(apiEpochColumns...),
+	"olderEpoch":    newApiFieldSet(apiEpochColumns...),
+	"rocketpool":    newApiFieldSet("node_address", "minipool_address", "minipool_node_fee", "minipool_deposit_type", "minipool_status", "minipool_status_time", "node_timezone_location", "node_rpl_stake", "node_max_rpl_stake", "node_min_rpl_stake", "index"),
+}
+
+// String returns the sorted, dotted names of all selectable fields
+func (s ApiFieldSet) String() string {
+	names := []string{}
+	for name, nested := range s {
+		names = append(names, name)
+		for _, n := range strings.Split(nested.String(), ", ") {
+			if n != "" {
+				names = append(names, name+"."+n)
+			}
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// apiFieldSelection is the parsed fields query parameter, a nil selection selects the whole field
+type apiFieldSelection map[string]apiFieldSelection
+
+// parseApiFieldSelection validates the comma separated fields against the selectable fields of the response
+func parseApiFieldSelection(param string, fields ApiFieldSet) (apiFieldSelection, error) {
+	names := strings.Split(param, ",")
+	if len(names) > apiMaxSelectedFields {
+		return nil, fmt.Errorf("only a maximum of %v fields can be selected", apiMaxSelectedFields)
+	}
+
+	selection := apiFieldSelection{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		path := strings.Split(name, ".")
+		set := fields
+		sel := selection
+		for i, p := range path {
+			nested, exists := set[p]
+			if !exists {
+				return nil, fmt.Errorf("unknown field %v, supported fields: %v", name, fields)
+			}
+			if i == len(path)-1 {
+				// selecting the whole field overrides the selection of its nested fields
+				sel[p] = nil
+				break
+			}
+			if nested == nil {
+				return nil, fmt.Errorf("unknown field %v, supported fields: %v", name, fields)
+			}
+			current, selected := sel[p]
+			if selected && current == nil {
+				break
+			}
+			if !selected {
+				sel[p] = apiFieldSelection{}
+			}
+			sel = sel[p]
+			set = nested
+		}
+	}
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("no fields selected, supported fields: %v", fields)
+	}
+	return selection, nil
+}
+
+// filter removes the fields that are not selected from the objects and the objects in arrays of the value
+func (s apiFieldSelection) filter(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		filtered := make(map[string]interface{}, len(s))
+		for name, nested := range s {
+			value, exists := t[name]
+			if !exists {
+				continue
+			}
+			if nested != nil {
+				value = nested.filter(value)
+			}
+			filtered[name] = value
+		}
+		return filtered
+	case []interface{}:
+		for i := range t {
+			t[i] = s.filter(t[i])
+		}
+		return t
+	}
+	return v
+}
+
+// ApiFieldsSelection restricts the data of the api responses of the handler to the fields selected via the comma
+// separated fields query parameter (e.g. ?fields=validatorindex,balance), nested fields are selected with dots. The
+// parameter is validated against the documented fields before the handler is executed, error responses are passed on
+// unchanged.
+func ApiFieldsSelection(fields ApiFieldSet, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		param := r.URL.Query().Get("fields")
+		if param == "" || r.Method == http.MethodOptions {
+			h(w, r)
+			return
+		}
+
+		selection, err := parseApiFieldSelection(param, fields)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			sendErrorResponse(json.NewEncoder(w), r.URL.String(), err.Error())
+			return
+		}
+
+		rec := &apiFieldsRecorder{header: w.Header(), status: http.StatusOK}
+		h(rec, r)
+
+		body := rec.body.Bytes()
+		if rec.status == http.StatusOK {
+			body, err = filterApiResponse(body, selection)
+			if err != nil {
+				logger.Errorf("error selecting fields of the api response for route %v: %v", r.URL.String(), err)
+				body = rec.body.Bytes()
+			}
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(rec.status)
+		_, err = w.Write(body)
+		if err != nil {
+			logger.Debugf("error writing api response for route %v: %v", r.URL.String(), err)
+		}
+	}
+}
+
+// filterApiResponse applies the selection to the data of successful api responses
+func filterApiResponse(body []byte, selection apiFieldSelection) ([]byte, error) {
+	response := map[string]json.RawMessage{}
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, err
+	}
+	var status string
+	err = json.Unmarshal(response["status"], &status)
+	if err != nil || status != "OK" {
+		return body, nil
+	}
+
+	var data interface{}
+	dec := json.NewDecoder(bytes.NewReader(response["data"]))
+	// keep large integers (e.g. balances in wei) as they are
+	dec.UseNumber()
+	err = dec.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := json.Marshal(selection.filter(data))
+	if err != nil {
+		return nil, err
+	}
+	response["data"] = filtered
+
+	res, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, '\n'), nil
+}
+
+// apiFieldsRecorder buffers the response of the handler, headers are written to the original response directly
+type apiFieldsRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *apiFieldsRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *apiFieldsRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *apiFieldsRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
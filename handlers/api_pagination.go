@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const apiMaxPageSize = 100
+
+// apiPage holds the cursor pagination parameters of an api request, pagination is only enabled
+// if the request contains a cursor or a limit so that existing clients keep the previous responses
+type apiPage struct {
+	Enabled bool
+	Limit   uint64
+	Cursor  []string
+}
+
+// parseApiPage parses the cursor and limit query parameters, cursorLen is the number of keyset columns the cursor consists of
+func parseApiPage(r *http.Request, cursorLen int) (*apiPage, error) {
+	q := r.URL.Query()
+	page := &apiPage{Limit: apiMaxPageSize}
+
+	if q.Get("limit") != "" {
+		limit, err := strconv.ParseUint(q.Get("limit"), 10, 64)
+		if err != nil || limit == 0 {
+			return nil, errors.New("invalid limit provided")
+		}
+		if limit < apiMaxPageSize {
+			page.Limit = limit
+		}
+		page.Enabled = true
+	}
+
+	if q.Get("cursor") != "" {
+		cursor, err := decodeApiCursor(q.Get("cursor"), cursorLen)
+		if err != nil {
+			return nil, err
+		}
+		page.Cursor = cursor
+		page.Enabled = true
+	}
+
+	return page, nil
+}
+
+// encodeApiCursor encodes the keyset values of the last returned row into an opaque cursor
+func encodeApiCursor(values ...interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%v", v)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, ",")))
+}
+
+func decodeApiCursor(cursor string, n int) ([]string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor provided")
+	}
+	parts := strings.Split(string(b), ",")
+	if len(parts) != n {
+		return nil, errors.New("invalid cursor provided")
+	}
+	return parts, nil
+}
+
+// cursorUint returns the i-th keyset value of the cursor as integer or nil if the request has no cursor
+func (p *apiPage) cursorUint(i int) (interface{}, error) {
+	if p.Cursor == nil {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(p.Cursor[i], 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor provided")
+	}
+	return v, nil
+}
+
+// cursorBytes returns the i-th keyset value of the cursor as byte slice or nil if the request has no cursor
+func (p *apiPage) cursorBytes(i int) (interface{}, error) {
+	if p.Cursor == nil {
+		return nil, nil
+	}
+	v, err := hex.DecodeString(strings.TrimPrefix(p.Cursor[i], "0x"))
+	if err != nil {
+		return nil, errors.New("invalid cursor provided")
+	}
+	return v, nil
+}
+
+// cursorBool returns the i-th keyset value of the cursor as bool or nil if the request has no cursor
+func (p *apiPage) cursorBool(i int) (interface{}, error) {
+	if p.Cursor == nil {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(p.Cursor[i])
+	if err != nil {
+		return nil, errors.New("invalid cursor provided")
+	}
+	return v, nil
+}
+
+// returnPaginatedQueryResults returns the rows of a query that fetched up to page.Limit+1 rows, the cursor of the
+// next page is built from the cursorColumns of the last returned row
+func returnPaginatedQueryResults(rows *sql.Rows, j *json.Encoder, r *http.Request, page *apiPage, cursorColumns ...string) {
+	data, err := utils.SqlRowsToJSON(rows)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not parse db results")
+		return
+	}
+
+	hasMore := uint64(len(data)) > page.Limit
+	if hasMore {
+		data = data[:page.Limit]
+	}
+
+	nextCursor := ""
+	if hasMore {
+		last, ok := data[len(data)-1].(map[string]interface{})
+		if !ok {
+			sendErrorResponse(j, r.URL.String(), "could not parse db results")
+			return
+		}
+		values := make([]interface{}, len(cursorColumns))
+		for i, column := range cursorColumns {
+			values[i] = last[column]
+		}
+		nextCursor = encodeApiCursor(values...)
+	}
+
+	sendOKPageResponse(j, r.URL.String(), data, nextCursor, hasMore)
+}
+
+// returnPaginatedResults returns the results of a repository that fetched up to page.Limit+1 items, the cursor of the
+// next page is built from the keyset values cursorValues returns for the last returned item
+func returnPaginatedResults(data []interface{}, j *json.Encoder, r *http.Request, page *apiPage, cursorValues func(last interface{}) []interface{}) {
+	hasMore := uint64(len(data)) > page.Limit
+	if hasMore {
+		data = data[:page.Limit]
+	}
+
+	nextCursor := ""
+	if hasMore {
+		nextCursor = encodeApiCursor(cursorValues(data[len(data)-1])...)
+	}
+
+	sendOKPageResponse(j, r.URL.String(), data, nextCursor, hasMore)
+}
+
+// sendOKPageResponse always returns data as array, next_cursor is empty on the last page
+func sendOKPageResponse(j *json.Encoder, route string, data []interface{}, nextCursor string, hasMore bool) {
+	response := &types.ApiResponse{}
+	response.Status = "OK"
+	response.Data = data
+	response.NextCursor = &nextCursor
+	response.HasMore = &hasMore
+
+	err := j.Encode(response)
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", route, err)
+	}
+}
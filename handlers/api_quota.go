@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// apiQuotaRefreshInterval is the time the plan and the usage of an api key are cached, the requests in between are
+// counted locally
+const apiQuotaRefreshInterval = time.Minute
+
+// apiKeyQuota is the api plan of an api key and its usage of the last day and month
+type apiKeyQuota struct {
+	exists       bool
+	entitlements types.ApiEntitlements
+	daily        int
+	monthly      int
+	refreshed    time.Time
+}
+
+var apiKeyQuotas = map[string]*apiKeyQuota{}
+var apiKeyQuotasMux = &sync.Mutex{}
+
+// exceeded returns the exceeded quota or an empty string if the key may send another request
+func (q *apiKeyQuota) exceeded() string {
+	if q.entitlements.DailyQuota >= 0 && q.daily >= q.entitlements.DailyQuota {
+		return "daily"
+	}
+	if q.entitlements.MonthlyQuota >= 0 && q.monthly >= q.entitlements.MonthlyQuota {
+		return "monthly"
+	}
+	return ""
+}
+
+// getApiKeyQuota returns the cached quota of the api key, it is refreshed from the api plan of the user and the usage
+// recorded in api_statistics once the refresh interval passed
+func getApiKeyQuota(r *http.Request, apiKey string) (*apiKeyQuota, error) {
+	apiKeyQuotasMux.Lock()
+	q, ok := apiKeyQuotas[apiKey]
+	apiKeyQuotasMux.Unlock()
+	if ok && time.Since(q.refreshed) < apiQuotaRefreshInterval {
+		return q, nil
+	}
+
+	priceID, exists, err := db.GetApiKeyPriceID(r.Context(), apiKey)
+	if err != nil {
+		return nil, err
+	}
+	q = &apiKeyQuota{exists: exists, entitlements: utils.ApiPlanEntitlements(priceID), refreshed: time.Now()}
+	if exists {
+		stats, err := db.GetUserAPIKeyStatistics(r.Context(), &apiKey)
+		if err != nil {
+			return nil, err
+		}
+		if stats.Daily != nil {
+			q.daily = *stats.Daily
+		}
+		if stats.Monthly != nil {
+			q.monthly = *stats.Monthly
+		}
+	}
+
+	apiKeyQuotasMux.Lock()
+	apiKeyQuotas[apiKey] = q
+	apiKeyQuotasMux.Unlock()
+	return q, nil
+}
+
+// ApiQuotaMiddleware rejects the requests of api keys that used up the daily or monthly quota of their api plan.
+// Requests without an api key or with an unknown key are passed on, the quota of the free plan applies to keys
+// without api subscription.
+func ApiQuotaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.URL.Query().Get("apikey")
+		if apiKey == "" {
+			apiKey = r.Header.Get("apikey")
+		}
+		if apiKey == "" || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		q, err := getApiKeyQuota(r, apiKey)
+		if err != nil {
+			// the quota is not enforced if it cannot be determined, the api stays available
+			requestLogger(r).Errorf("error retrieving api quota: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !q.exists {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		apiKeyQuotasMux.Lock()
+		exceeded := q.exceeded()
+		if exceeded == "" {
+			q.daily++
+			q.monthly++
+		}
+		apiKeyQuotasMux.Unlock()
+		if exceeded != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			sendErrorResponse(json.NewEncoder(w), r.URL.String(), fmt.Sprintf("%v request quota of the %v api plan exceeded", exceeded, q.entitlements.Plan))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
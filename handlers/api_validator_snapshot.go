@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	validatorSnapshotDefaultPageSize = 1000
+	validatorSnapshotMaxPageSize     = 10000
+)
+
+// ApiEpochValidators godoc
+// @Summary Get the state of the validator registry at an epoch
+// @Tags Epoch
+// @Description Returns the status, balance and effective balance of all validators as of the epoch, reconstructed from the stored history. The json response is paginated, use next_cursor to fetch the next page. With format=csv the whole registry is returned as gzip compressed csv.
+// @Produce  json
+// @Param  epoch path string true "Epoch number or the string latest"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor"
+// @Param  limit query int false "Number of validators per page (default 1000, max 10000)"
+// @Param  format query string false "json (default) or csv"
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorSnapshot}
+// @Router /api/v1/epoch/{epoch}/validators [get]
+func ApiEpochValidators(w http.ResponseWriter, r *http.Request) {
+	j := json.NewEncoder(w)
+	vars := mux.Vars(r)
+	q := r.URL.Query()
+
+	epoch, err := strconv.ParseUint(vars["epoch"], 10, 64)
+	if vars["epoch"] == "latest" {
+		epoch, err = services.LatestEpoch(), nil
+	}
+	if err != nil || epoch > services.LatestEpoch() {
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorResponse(j, r.URL.String(), "invalid epoch provided")
+		return
+	}
+
+	switch q.Get("format") {
+	case "csv":
+		streamValidatorSnapshotCSV(w, r, epoch)
+		return
+	case "", "json":
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorResponse(j, r.URL.String(), "invalid format provided")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	limit, afterIndex, err := parseValidatorSnapshotPage(r)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	streamValidatorSnapshotJSON(w, r, epoch, afterIndex, limit)
+}
+
+// parseValidatorSnapshotPage parses the cursor and limit query parameters, the snapshot uses larger pages than the
+// other endpoints as it is meant to be downloaded completely
+func parseValidatorSnapshotPage(r *http.Request) (uint64, *uint64, error) {
+	q := r.URL.Query()
+
+	limit := uint64(validatorSnapshotDefaultPageSize)
+	if q.Get("limit") != "" {
+		l, err := strconv.ParseUint(q.Get("limit"), 10, 64)
+		if err != nil || l == 0 {
+			return 0, nil, errors.New("invalid limit provided")
+		}
+		limit = l
+		if limit > validatorSnapshotMaxPageSize {
+			limit = validatorSnapshotMaxPageSize
+		}
+	}
+
+	if q.Get("cursor") == "" {
+		return limit, nil, nil
+	}
+	cursor, err := decodeApiCursor(q.Get("cursor"), 1)
+	if err != nil {
+		return 0, nil, err
+	}
+	afterIndex, err := strconv.ParseUint(cursor[0], 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("invalid cursor provided")
+	}
+	return limit, &afterIndex, nil
+}
+
+// streamValidatorSnapshotJSON writes the page of the snapshot while it is read from the database, the response has the
+// same format as the other paginated endpoints. Errors after the first validator has been written can not be reported
+// to the client anymore, the response is truncated in that case.
+func streamValidatorSnapshotJSON(w http.ResponseWriter, r *http.Request, epoch uint64, afterIndex *uint64, limit uint64) {
+	count := uint64(0)
+	hasMore := false
+	var last uint64
+
+	_, err := w.Write([]byte(`{"status":"OK","data":[`))
+	if err != nil {
+		return
+	}
+	err = db.StreamValidatorSetSnapshot(r.Context(), epoch, afterIndex, limit+1, func(v *types.ApiValidatorSnapshot) error {
+		if count == limit {
+			hasMore = true
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			b = append([]byte(","), b...)
+		}
+		_, err = w.Write(b)
+		if err != nil {
+			return err
+		}
+		count++
+		last = v.Validatorindex
+		return nil
+	})
+	if err != nil {
+		requestLogger(r).Errorf("error streaming validator set snapshot of epoch %v for %v route: %v", epoch, r.URL.String(), err)
+		return
+	}
+
+	nextCursor := ""
+	if hasMore {
+		nextCursor = encodeApiCursor(last)
+	}
+	fmt.Fprintf(w, `],"next_cursor":%q,"has_more":%v}`, nextCursor, hasMore)
+}
+
+// streamValidatorSnapshotCSV writes the whole snapshot as gzip compressed csv while it is read from the database
+func streamValidatorSnapshotCSV(w http.ResponseWriter, r *http.Request, epoch uint64) {
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=validators_epoch_%v.csv.gz", epoch))
+
+	gz := gzip.NewWriter(w)
+	defer gz.Close()
+	c := csv.NewWriter(gz)
+	defer c.Flush()
+
+	optionalEpoch := func(e *uint64) string {
+		if e == nil {
+			return ""
+		}
+		return strconv.FormatUint(*e, 10)
+	}
+
+	err := c.Write([]string{"epoch", "validatorindex", "pubkey", "status", "balance", "effectivebalance", "slashed", "activationeligibilityepoch", "activationepoch", "exitepoch"})
+	if err != nil {
+		return
+	}
+	err = db.StreamValidatorSetSnapshot(r.Context(), epoch, nil, 0, func(v *types.ApiValidatorSnapshot) error {
+		return c.Write([]string{
+			strconv.FormatUint(v.Epoch, 10),
+			strconv.FormatUint(v.Validatorindex, 10),
+			v.Pubkey,
+			v.Status,
+			strconv.FormatUint(v.Balance, 10),
+			strconv.FormatUint(v.EffectiveBalance, 10),
+			strconv.FormatBool(v.Slashed),
+			optionalEpoch(v.ActivationEligibilityEpoch),
+			optionalEpoch(v.ActivationEpoch),
+			optionalEpoch(v.ExitEpoch),
+		})
+	})
+	if err != nil {
+		requestLogger(r).Errorf("error streaming validator set snapshot of epoch %v for %v route: %v", epoch, r.URL.String(), err)
+	}
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	apiMaxBulkValidators    = 5000
+	apiBulkValidatorsChunk  = 500
+	apiMaxBulkHistoryEpochs = 100
+	apiMaxBulkBodySize      = 1 << 20
+)
+
+// parseApiBulkValidatorsBody parses the validators of a BulkValidatorsRequest the same way parseApiValidatorParam parses the url parameter
+func parseApiBulkValidatorsBody(w http.ResponseWriter, r *http.Request) (*types.BulkValidatorsRequest, []uint64, pq.ByteaArray, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, apiMaxBulkBodySize))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not read body")
+	}
+
+	req := &types.BulkValidatorsRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid request body")
+	}
+	if len(req.IndicesOrPubkey) == 0 {
+		return nil, nil, nil, fmt.Errorf("no validators provided")
+	}
+	if len(req.IndicesOrPubkey) > apiMaxBulkValidators {
+		return nil, nil, nil, fmt.Errorf("only a maximum of %v validators are allowed", apiMaxBulkValidators)
+	}
+
+	params := make([]string, len(req.IndicesOrPubkey))
+	for i, v := range req.IndicesOrPubkey {
+		switch v := v.(type) {
+		case string:
+			params[i] = v
+		case float64:
+			params[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, nil, nil, fmt.Errorf("invalid validator-parameter: %v", v)
+		}
+	}
+
+	indices, pubkeys, err := parseApiValidatorParam(strings.Join(params, ","), apiMaxBulkValidators)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return req, indices, pubkeys, nil
+}
+
+// queryValidatorsChunked runs query in chunks of indices and pubkeys, the query receives the chunk of indices as $1,
+// the chunk of pubkeys as $2 and args from $3 on
+func queryValidatorsChunked(query string, indices []uint64, pubkeys pq.ByteaArray, args ...interface{}) ([]interface{}, error) {
+	data := []interface{}{}
+
+	queryChunk := func(chunkIndices []uint64, chunkPubkeys pq.ByteaArray) error {
+		rows, err := db.DB.Query(query, append([]interface{}{pq.Array(chunkIndices), chunkPubkeys}, args...)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		chunkData, err := utils.SqlRowsToJSON(rows)
+		if err != nil {
+			return err
+		}
+		data = append(data, chunkData...)
+		return nil
+	}
+
+	for i := 0; i < len(indices); i += apiBulkValidatorsChunk {
+		end := i + apiBulkValidatorsChunk
+		if end > len(indices) {
+			end = len(indices)
+		}
+		err := queryChunk(indices[i:end], pq.ByteaArray{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 0; i < len(pubkeys); i += apiBulkValidatorsChunk {
+		end := i + apiBulkValidatorsChunk
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+		err := queryChunk([]uint64{}, pubkeys[i:end])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// ApiValidatorsBulk godoc
+// @Summary Get up to 5000 validators by their index or public key
+// @Tags Validator
+// @Accept  json
+// @Produce  json
+// @Param  request body types.BulkValidatorsRequest true "Up to 5000 validator indicesOrPubkeys"
+// @Param  fields query string false "Comma separated fields of the validators to return, e.g. validatorindex,balance,status"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators [post]
+func ApiValidatorsBulk(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+
+	_, queryIndices, queryPubkeys, err := parseApiBulkValidatorsBody(w, r)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	data, err := queryValidatorsChunked("SELECT validatorindex, pubkey, withdrawableepoch, withdrawalcredentials, balance, effectivebalance, slashed, activationeligibilityepoch, activationepoch, exitepoch, lastattestationslot, status, validator_names.name FROM validators LEFT JOIN validator_names ON validator_names.publickey = validators.pubkey WHERE validatorindex = ANY($1) OR pubkey = ANY($2) ORDER BY validatorindex", queryIndices, queryPubkeys)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving bulk validators: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiValidatorsBulkBalanceHistory godoc
+// @Summary Get the balance history of up to 5000 validators for the given number of epochs (default 10, max 100)
+// @Tags Validator
+// @Accept  json
+// @Produce  json
+// @Param  request body types.BulkValidatorsRequest true "Up to 5000 validator indicesOrPubkeys and the number of epochs"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators/balancehistory [post]
+func ApiValidatorsBulkBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+
+	req, queryIndices, queryPubkeys, err := parseApiBulkValidatorsBody(w, r)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	epochs := req.Epochs
+	if epochs == 0 {
+		epochs = 10
+	}
+	if epochs > apiMaxBulkHistoryEpochs {
+		epochs = apiMaxBulkHistoryEpochs
+	}
+
+	startEpoch := uint64(0)
+	if latestEpoch := services.LatestEpoch(); latestEpoch > epochs {
+		startEpoch = latestEpoch - epochs
+	}
+
+	indices, err := resolveApiValidatorIndices(r.Context(), queryIndices, queryPubkeys)
+	if err != nil {
+		requestLogger(r).Errorf("error resolving bulk validator indices: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := []interface{}{}
+	for i := 0; i < len(indices); i += apiBulkValidatorsChunk {
+		end := i + apiBulkValidatorsChunk
+		if end > len(indices) {
+			end = len(indices)
+		}
+		balances, err := db.Balances.GetBalanceHistory(indices[i:end], startEpoch+1)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving bulk validator balance history: %v", err)
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		for _, b := range balances {
+			data = append(data, b)
+		}
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiValidatorsBulkPerformance godoc
+// @Summary Get the current performance of up to 5000 validators
+// @Tags Validator
+// @Accept  json
+// @Produce  json
+// @Param  request body types.BulkValidatorsRequest true "Up to 5000 validator indicesOrPubkeys"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators/performance [post]
+func ApiValidatorsBulkPerformance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+
+	_, queryIndices, queryPubkeys, err := parseApiBulkValidatorsBody(w, r)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	data, err := queryValidatorsChunked("SELECT validator_performance.* FROM validator_performance LEFT JOIN validators ON validators.validatorindex = validator_performance.validatorindex WHERE validator_performance.validatorindex = ANY($1) OR validators.pubkey = ANY($2) ORDER BY validatorindex", queryIndices, queryPubkeys)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving bulk validator performance: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
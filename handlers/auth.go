@@ -19,7 +19,7 @@ import (
 )
 
 var loginTemplate = template.Must(template.New("login").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/login.html"))
-var registerTemplate = template.Must(template.New("register").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/register.html"))
+var registerTemplate = template.Must(template.New("register").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/register.html", "templates/components/captcha.html"))
 var resetPasswordTemplate = template.Must(template.New("resetPassword").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/resetPassword.html"))
 var resendConfirmationTemplate = template.Must(template.New("resetPassword").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/resendConfirmation.html"))
 var requestResetPaswordTemplate = template.Must(template.New("resetPassword").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/requestResetPassword.html"))
@@ -34,19 +34,19 @@ func Register(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 
 	data := InitPageData(w, r, "register", "/register", "Register new account")
-	data.Data = types.AuthData{Flashes: utils.GetFlashes(w, r, authSessionName), CsrfField: csrf.TemplateField(r)}
+	data.Data = types.AuthData{Flashes: utils.GetFlashes(w, r, authSessionName), CsrfField: csrf.TemplateField(r), Captcha: utils.GetCaptchaData("register")}
 	data.Meta.NoTrack = true
 
 	err := registerTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
 // RegisterPost handles the register-formular to register a new user.
 func RegisterPost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.WithField("route", r.URL.String())
+	logger := requestLogger(r).WithField("route", r.URL.String())
 	session, err := utils.SessionStore.Get(r, authSessionName)
 	if err != nil {
 		logger.Errorf("error retrieving session: %v", err)
@@ -61,6 +61,15 @@ func RegisterPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = utils.ValidateCaptcha(r, "register")
+	if err != nil {
+		logger.Errorf("error validating captcha: %v", err)
+		session.AddFlash("Error: Captcha verification failed, please try again!")
+		session.Save(r, w)
+		http.Redirect(w, r, "/register", http.StatusSeeOther)
+		return
+	}
+
 	email := r.FormValue("email")
 	pwd := r.FormValue("password")
 
@@ -71,7 +80,7 @@ func RegisterPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := db.FrontendDB.Beginx()
+	tx, err := db.FrontendDB.BeginTxx(r.Context(), nil)
 	if err != nil {
 		logger.Errorf("error creating db-tx for registering user: %v", err)
 		session.AddFlash(authInternalServerErrorFlashMsg)
@@ -82,7 +91,7 @@ func RegisterPost(w http.ResponseWriter, r *http.Request) {
 	defer tx.Rollback()
 
 	var existingEmails int
-	err = tx.Get(&existingEmails, "SELECT COUNT(*) FROM users WHERE email = $1", email)
+	err = tx.GetContext(r.Context(), &existingEmails, "SELECT COUNT(*) FROM users WHERE email = $1", email)
 	if existingEmails > 0 {
 		session.AddFlash("Error: Email already exists!")
 		session.Save(r, w)
@@ -110,7 +119,7 @@ func RegisterPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(r.Context(), `
       INSERT INTO users (password, email, register_ts, api_key)
       VALUES ($1, $2, TO_TIMESTAMP($3), $4)`,
 		string(pHash), email, registerTs, apiKey,
@@ -156,7 +165,7 @@ func Login(w http.ResponseWriter, r *http.Request) {
 
 	err := loginTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
@@ -165,12 +174,12 @@ func Login(w http.ResponseWriter, r *http.Request) {
 func LoginPost(w http.ResponseWriter, r *http.Request) {
 	session, err := utils.SessionStore.Get(r, authSessionName)
 	if err != nil {
-		logger.Errorf("Error retrieving session for login route: %v", err)
+		requestLogger(r).Errorf("Error retrieving session for login route: %v", err)
 	}
 
 	err = r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		session.AddFlash(authInternalServerErrorFlashMsg)
 		session.Save(r, w)
 		http.Redirect(w, r, "/register", http.StatusSeeOther)
@@ -187,11 +196,12 @@ func LoginPost(w http.ResponseWriter, r *http.Request) {
 		Confirmed bool   `db:"email_confirmed"`
 		ProductID string `db:"product_id"`
 		Active    bool   `db:"active"`
+		Theme     string `db:"theme"`
 	}{}
 
-	err = db.FrontendDB.Get(&user, "SELECT users.id, email, password, email_confirmed, COALESCE(product_id, '') as product_id, COALESCE(active, false) as active FROM users left join users_app_subscriptions on users_app_subscriptions.user_id = users.id WHERE email = $1", email)
+	err = db.FrontendDB.GetContext(r.Context(), &user, "SELECT users.id, email, password, email_confirmed, COALESCE(product_id, '') as product_id, COALESCE(active, false) as active, COALESCE(theme, '') as theme FROM users left join users_app_subscriptions on users_app_subscriptions.user_id = users.id WHERE email = $1", email)
 	if err != nil {
-		logger.Errorf("error retrieving password for user %v: %v", email, err)
+		requestLogger(r).Errorf("error retrieving password for user %v: %v", email, err)
 		session.AddFlash("Error: Invalid email or password!")
 		session.Save(r, w)
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -217,28 +227,37 @@ func LoginPost(w http.ResponseWriter, r *http.Request) {
 		user.ProductID = ""
 	}
 
+	redirectURI, RedirectExists := session.Values["oauth_redirect_uri"]
+	state, stateExists := session.Values["state"]
+
+	utils.RotateSession(session)
 	session.Values["authenticated"] = true
 	session.Values["user_id"] = user.ID
 	session.Values["subscription"] = user.ProductID
+	if user.Theme != "" {
+		session.Values["theme"] = user.Theme
+	}
+	err = startUserSession(r, session, user.ID)
+	if err != nil {
+		requestLogger(r).Errorf("error starting session of user %v: %v", user.ID, err)
+		utils.RotateSession(session)
+		session.AddFlash(authInternalServerErrorFlashMsg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
 	// session.AddFlash("Successfully logged in")
 
 	session.Save(r, w)
-	logger.Println("login succeeded with session", session.Values["authenticated"], session.Values["user_id"], session.Values["subscription"])
-
-	redirectURI, RedirectExists := session.Values["oauth_redirect_uri"]
+	requestLogger(r).Println("login succeeded with session", session.Values["authenticated"], session.Values["user_id"], session.Values["subscription"])
 
 	if RedirectExists {
-		state, stateExists := session.Values["state"]
 		var stateParam = ""
 
 		if stateExists {
 			stateParam = "&state=" + state.(string)
 		}
 
-		delete(session.Values, "oauth_redirect_uri")
-		delete(session.Values, "state")
-		session.Save(r, w)
-
 		http.Redirect(w, r, "/user/authorize?redirect_uri="+redirectURI.(string)+stateParam, http.StatusSeeOther)
 		return
 	}
@@ -251,14 +270,19 @@ func LoginPost(w http.ResponseWriter, r *http.Request) {
 func Logout(w http.ResponseWriter, r *http.Request) {
 	session, err := utils.SessionStore.Get(r, authSessionName)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if key := sessionKey(session); key != "" {
+		err = db.RevokeUserSessionByKey(r.Context(), key)
+		if err != nil {
+			requestLogger(r).Errorf("error revoking session: %v", err)
+		}
+	}
+	utils.RotateSession(session)
 	session.Values["subscription"] = ""
 	session.Values["authenticated"] = false
-	delete(session.Values, "user_id")
-	delete(session.Values, "oauth_redirect_uri")
 	session.Save(r, w)
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
@@ -272,7 +296,7 @@ func ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	session, err := utils.SessionStore.Get(r, authSessionName)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -287,7 +311,7 @@ func ResetPassword(w http.ResponseWriter, r *http.Request) {
 		ProductID      string `db:"product_id"`
 		Active         bool   `db:"active"`
 	}{}
-	err = db.FrontendDB.Get(&dbUser, "SELECT users.id, email_confirmed, email, COALESCE(product_id, '') as product_id, COALESCE(active, false) as active FROM users LEFT JOIN users_app_subscriptions on users_app_subscriptions.user_id = users.id WHERE password_reset_hash = $1", hash)
+	err = db.FrontendDB.GetContext(r.Context(), &dbUser, "SELECT users.id, email_confirmed, email, COALESCE(product_id, '') as product_id, COALESCE(active, false) as active FROM users LEFT JOIN users_app_subscriptions on users_app_subscriptions.user_id = users.id WHERE password_reset_hash = $1", hash)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			session.AddFlash("Error: Invalid reset link, please retry.")
@@ -295,18 +319,20 @@ func ResetPassword(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/requestReset", http.StatusSeeOther)
 			return
 		}
-		logger.Errorf("error resetting password: %v", err)
+		requestLogger(r).Errorf("error resetting password: %v", err)
 		session.AddFlash(authInternalServerErrorFlashMsg)
 		session.Save(r, w)
 		http.Redirect(w, r, "/requestReset", http.StatusSeeOther)
 		return
 	}
 
+	utils.RotateSession(session)
+
 	// if the user has not confirmed her email yet, just confirm it since she clicked this reset-password-link that has been sent to her email aswell anyway
 	if !dbUser.EmailConfirmed {
-		_, err = db.FrontendDB.Exec("UPDATE users SET email_confirmed = 'TRUE' WHERE id = $1", dbUser.ID)
+		_, err = db.FrontendDB.ExecContext(r.Context(), "UPDATE users SET email_confirmed = 'TRUE' WHERE id = $1", dbUser.ID)
 		if err != nil {
-			logger.Errorf("error setting confirmed when user is resetting password: %v", err)
+			requestLogger(r).Errorf("error setting confirmed when user is resetting password: %v", err)
 			session.AddFlash(authInternalServerErrorFlashMsg)
 			session.Save(r, w)
 			http.Redirect(w, r, "/requestReset", http.StatusSeeOther)
@@ -323,6 +349,14 @@ func ResetPassword(w http.ResponseWriter, r *http.Request) {
 		user.Subscription = dbUser.ProductID
 	}
 
+	err = startUserSession(r, session, user.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error starting session of user %v: %v", user.UserID, err)
+		session.AddFlash(authInternalServerErrorFlashMsg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/requestReset", http.StatusSeeOther)
+		return
+	}
 	session.Values["authenticated"] = true
 	session.Values["user_id"] = user.UserID
 	session.Values["subscription"] = user.Subscription
@@ -334,14 +368,14 @@ func ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	err = resetPasswordTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
 // ResetPasswordPost resets the password to the value provided in the form, given that the user is authenticated.
 func ResetPasswordPost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.WithField("route", r.URL.String())
+	logger := requestLogger(r).WithField("route", r.URL.String())
 
 	user, session, err := getUserSession(r)
 	if err != nil {
@@ -376,7 +410,7 @@ func ResetPasswordPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = db.UpdatePassword(user.UserID, pHash)
+	err = db.UpdatePassword(r.Context(), user.UserID, pHash)
 	if err != nil {
 		logger.Errorf("error updating password for user: %v", err)
 		session.AddFlash(authInternalServerErrorFlashMsg)
@@ -385,9 +419,15 @@ func ResetPasswordPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// a reset password might have been compromised, all logins with it are ended
+	err = db.RevokeUserSessions(r.Context(), user.UserID, "")
+	if err != nil {
+		logger.Errorf("error revoking sessions of user %v: %v", user.UserID, err)
+	}
+
+	utils.RotateSession(session)
 	session.Values["subscription"] = ""
 	session.Values["authenticated"] = false
-	delete(session.Values, "user_id")
 
 	session.AddFlash("Your password has been updated successfully, please log in again!")
 
@@ -404,14 +444,14 @@ func RequestResetPassword(w http.ResponseWriter, r *http.Request) {
 	data.Meta.NoTrack = true
 	err := requestResetPaswordTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
 // RequestResetPasswordPost sends a password-reset-link to the provided (via form) email.
 func RequestResetPasswordPost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.WithField("route", r.URL.String())
+	logger := requestLogger(r).WithField("route", r.URL.String())
 
 	err := r.ParseForm()
 	if err != nil {
@@ -430,7 +470,7 @@ func RequestResetPasswordPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var exists int
-	err = db.FrontendDB.Get(&exists, "SELECT COUNT(*) FROM users WHERE email = $1", email)
+	err = db.FrontendDB.GetContext(r.Context(), &exists, "SELECT COUNT(*) FROM users WHERE email = $1", email)
 	if err != nil {
 		logger.Errorf("error retrieving user-count: %v", err)
 		utils.SetFlash(w, r, authSessionName, authInternalServerErrorFlashMsg)
@@ -467,7 +507,7 @@ func ResendConfirmation(w http.ResponseWriter, r *http.Request) {
 
 	err := resendConfirmationTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
@@ -476,7 +516,7 @@ func ResendConfirmation(w http.ResponseWriter, r *http.Request) {
 func ResendConfirmationPost(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		utils.SetFlash(w, r, authSessionName, authInternalServerErrorFlashMsg)
 		http.Redirect(w, r, "/resend", http.StatusSeeOther)
 		return
@@ -491,9 +531,9 @@ func ResendConfirmationPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var exists int
-	err = db.FrontendDB.Get(&exists, "SELECT COUNT(*) FROM users WHERE email = $1", email)
+	err = db.FrontendDB.GetContext(r.Context(), &exists, "SELECT COUNT(*) FROM users WHERE email = $1", email)
 	if err != nil {
-		logger.Errorf("error checking if user exists for email-confirmation: %v", err)
+		requestLogger(r).Errorf("error checking if user exists for email-confirmation: %v", err)
 		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong :( Please retry later")
 		http.Redirect(w, r, "/resend", http.StatusSeeOther)
 		return
@@ -508,7 +548,7 @@ func ResendConfirmationPost(w http.ResponseWriter, r *http.Request) {
 	var rateLimitError *types.RateLimitError
 	err = sendConfirmationEmail(email)
 	if err != nil && !errors.As(err, &rateLimitError) {
-		logger.Errorf("error sending confirmation-email: %v", err)
+		requestLogger(r).Errorf("error sending confirmation-email: %v", err)
 		utils.SetFlash(w, r, authSessionName, authInternalServerErrorFlashMsg)
 	} else if err != nil && errors.As(err, &rateLimitError) {
 		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: The ratelimit for sending emails has been exceeded, please try again in %v.", err.(*types.RateLimitError).TimeLeft.Round(time.Second)))
@@ -525,7 +565,7 @@ func ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 	hash := vars["hash"]
 
 	var isConfirmed = false
-	err := db.FrontendDB.Get(&isConfirmed, `
+	err := db.FrontendDB.GetContext(r.Context(), &isConfirmed, `
 	SELECT email_confirmed 
 	FROM users 
 	WHERE email_confirmation_hash = $1
@@ -542,7 +582,7 @@ func ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := db.FrontendDB.Exec("UPDATE users SET email_confirmed = 'TRUE' WHERE email_confirmation_hash = $1", hash)
+	res, err := db.FrontendDB.ExecContext(r.Context(), "UPDATE users SET email_confirmed = 'TRUE' WHERE email_confirmation_hash = $1", hash)
 	if err != nil {
 		utils.SetFlash(w, r, authSessionName, authInternalServerErrorFlashMsg)
 		http.Redirect(w, r, "/confirmation", http.StatusSeeOther)
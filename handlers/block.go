@@ -52,11 +52,11 @@ func Block(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		data.Meta.Title = fmt.Sprintf("%v - Slot %v - beaconcha.in - %v", utils.Config.Frontend.SiteName, slotOrHash, time.Now().Year())
 		data.Meta.Path = "/block/" + slotOrHash
-		logger.Errorf("error retrieving block data: %v", err)
+		requestLogger(r).Errorf("error retrieving block data: %v", err)
 		err = blockNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -65,7 +65,7 @@ func Block(w http.ResponseWriter, r *http.Request) {
 
 	blockPageData := types.BlockPageData{}
 	blockPageData.Mainnet = utils.Config.Chain.Mainnet
-	err = db.DB.Get(&blockPageData, `
+	err = db.DB.GetContext(r.Context(), &blockPageData, `
 		SELECT
 			blocks.epoch,
 			blocks.slot,
@@ -88,6 +88,13 @@ func Block(w http.ResponseWriter, r *http.Request) {
 			blocks.voluntaryexitscount,
 			blocks.proposer,
 			blocks.status,
+			blocks.exec_block_hash,
+			COALESCE(blocks.exec_block_number, 0) AS exec_block_number,
+			blocks.exec_fee_recipient,
+			COALESCE(blocks.exec_gas_used, 0) AS exec_gas_used,
+			COALESCE(blocks.exec_gas_limit, 0) AS exec_gas_limit,
+			COALESCE(blocks.exec_base_fee_per_gas, 0) AS exec_base_fee_per_gas,
+			blocks.exec_transactions_count,
 			COALESCE(validator_names.name, '') AS name
 		FROM blocks 
 		LEFT JOIN validators ON blocks.proposer = validators.validatorindex
@@ -98,11 +105,11 @@ func Block(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		data.Meta.Title = fmt.Sprintf("%v - Slot %v - beaconcha.in - %v", utils.Config.Frontend.SiteName, slotOrHash, time.Now().Year())
 		data.Meta.Path = "/block/" + slotOrHash
-		logger.Errorf("error retrieving block data: %v", err)
+		requestLogger(r).Errorf("error retrieving block data: %v", err)
 		err = blockNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -111,26 +118,31 @@ func Block(w http.ResponseWriter, r *http.Request) {
 
 	data.Meta.Title = fmt.Sprintf("%v - Slot %v - beaconcha.in - %v", utils.Config.Frontend.SiteName, blockPageData.Slot, time.Now().Year())
 	data.Meta.Path = fmt.Sprintf("/block/%v", blockPageData.Slot)
+	data.Meta.Image = previewImageURL(data.Meta.Path)
 
 	blockPageData.Ts = utils.SlotToTime(blockPageData.Slot)
 	blockPageData.SlashingsCount = blockPageData.AttesterSlashingsCount + blockPageData.ProposerSlashingsCount
+	if blockPageData.ExecGasLimit > 0 {
+		blockPageData.ExecGasUsedPercent = float64(blockPageData.ExecGasUsed) / float64(blockPageData.ExecGasLimit) * 100
+	}
+	blockPageData.ExecBaseFeePerGasGwei = float64(blockPageData.ExecBaseFeePerGas) / 1e9
 
-	err = db.DB.Get(&blockPageData.NextSlot, "SELECT slot FROM blocks WHERE slot > $1 ORDER BY slot LIMIT 1", blockPageData.Slot)
+	err = db.DB.GetContext(r.Context(), &blockPageData.NextSlot, "SELECT slot FROM blocks WHERE slot > $1 ORDER BY slot LIMIT 1", blockPageData.Slot)
 	if err == sql.ErrNoRows {
 		blockPageData.NextSlot = 0
 	} else if err != nil {
-		logger.Errorf("error retrieving next slot for block %v: %v", blockPageData.Slot, err)
+		requestLogger(r).Errorf("error retrieving next slot for block %v: %v", blockPageData.Slot, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	err = db.DB.Get(&blockPageData.PreviousSlot, "SELECT slot FROM blocks WHERE slot < $1 ORDER BY slot DESC LIMIT 1", blockPageData.Slot)
+	err = db.DB.GetContext(r.Context(), &blockPageData.PreviousSlot, "SELECT slot FROM blocks WHERE slot < $1 ORDER BY slot DESC LIMIT 1", blockPageData.Slot)
 	if err != nil {
-		logger.Errorf("error retrieving previous slot for block %v: %v", blockPageData.Slot, err)
+		requestLogger(r).Errorf("error retrieving previous slot for block %v: %v", blockPageData.Slot, err)
 		blockPageData.PreviousSlot = 0
 	}
 
 	var attestations []*types.BlockPageAttestation
-	rows, err := db.DB.Query(`
+	rows, err := db.DB.QueryContext(r.Context(), `
 		SELECT
 			block_slot,
 			block_index,
@@ -149,7 +161,7 @@ func Block(w http.ResponseWriter, r *http.Request) {
 		ORDER BY block_index`,
 		blockPageData.Slot)
 	if err != nil {
-		logger.Errorf("error retrieving block attestation data: %v", err)
+		requestLogger(r).Errorf("error retrieving block attestation data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -172,7 +184,7 @@ func Block(w http.ResponseWriter, r *http.Request) {
 			&attestation.TargetEpoch,
 			&attestation.TargetRoot)
 		if err != nil {
-			logger.Errorf("error scanning block attestation data: %v", err)
+			requestLogger(r).Errorf("error scanning block attestation data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -180,13 +192,13 @@ func Block(w http.ResponseWriter, r *http.Request) {
 	}
 	blockPageData.Attestations = attestations
 
-	rows, err = db.DB.Query(`
+	rows, err = db.DB.QueryContext(r.Context(), `
 		SELECT validators
 		FROM blocks_attestations
 		WHERE beaconblockroot = $1`,
 		blockPageData.BlockRoot)
 	if err != nil {
-		logger.Errorf("error retrieving block votes data: %v", err)
+		requestLogger(r).Errorf("error retrieving block votes data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -198,7 +210,7 @@ func Block(w http.ResponseWriter, r *http.Request) {
 		validators := pq.Int64Array{}
 		err := rows.Scan(&validators)
 		if err != nil {
-			logger.Errorf("error scanning votes validators data: %v", err)
+			requestLogger(r).Errorf("error scanning votes validators data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -215,14 +227,14 @@ func Block(w http.ResponseWriter, r *http.Request) {
 	blockPageData.VotingValidatorsCount = uint64(len(votesPerValidator))
 	blockPageData.VotesCount = uint64(votesCount)
 
-	err = db.DB.Select(&blockPageData.VoluntaryExits, "SELECT validatorindex, signature FROM blocks_voluntaryexits WHERE block_slot = $1", blockPageData.Slot)
+	err = db.DB.SelectContext(r.Context(), &blockPageData.VoluntaryExits, "SELECT validatorindex, signature FROM blocks_voluntaryexits WHERE block_slot = $1", blockPageData.Slot)
 	if err != nil {
-		logger.Errorf("error retrieving block deposit data: %v", err)
+		requestLogger(r).Errorf("error retrieving block deposit data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	err = db.DB.Select(&blockPageData.AttesterSlashings, `
+	err = db.DB.SelectContext(r.Context(), &blockPageData.AttesterSlashings, `
 		SELECT
 			block_slot,
 			block_index,
@@ -247,7 +259,7 @@ func Block(w http.ResponseWriter, r *http.Request) {
 		FROM blocks_attesterslashings
 		WHERE block_slot = $1`, blockPageData.Slot)
 	if err != nil {
-		logger.Errorf("error retrieving block attester slashings data: %v", err)
+		requestLogger(r).Errorf("error retrieving block attester slashings data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -262,20 +274,41 @@ func Block(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = db.DB.Select(&blockPageData.ProposerSlashings, "SELECT * FROM blocks_proposerslashings WHERE block_slot = $1", blockPageData.Slot)
+	err = db.DB.SelectContext(r.Context(), &blockPageData.ProposerSlashings, "SELECT * FROM blocks_proposerslashings WHERE block_slot = $1", blockPageData.Slot)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block proposer slashings data: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = db.DB.SelectContext(r.Context(), &blockPageData.SyncCommittee, "SELECT validatorindex FROM sync_committees WHERE period = $1 ORDER BY committeeindex", utils.SyncPeriodOfEpoch(blockPageData.Epoch))
 	if err != nil {
-		logger.Errorf("error retrieving block proposer slashings data: %v", err)
+		requestLogger(r).Errorf("error retrieving sync-committee of block %v: %v", blockPageData.Slot, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	err = db.DB.Select(&blockPageData.SyncCommittee, "SELECT validatorindex FROM sync_committees WHERE period = $1 ORDER BY committeeindex", utils.SyncPeriodOfEpoch(blockPageData.Epoch))
+	blockPageData.Packing, err = db.GetBlockPacking(r.Context(), blockPageData.Slot, blockPageData.BlockRoot)
 	if err != nil {
-		logger.Errorf("error retrieving sync-committee of block %v: %v", blockPageData.Slot, err)
+		requestLogger(r).Errorf("error retrieving packing of block %v: %v", blockPageData.Slot, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	blockStatus := "was scheduled to be proposed"
+	switch blockPageData.Status {
+	case 1:
+		blockStatus = "was proposed"
+	case 2:
+		blockStatus = "was missed"
+	case 3:
+		blockStatus = "was orphaned, it was proposed"
+	}
+	setStructuredData(data, fmt.Sprintf("Slot %v", blockPageData.Slot), fmt.Sprintf("%v", blockPageData.Slot),
+		fmt.Sprintf("The block of slot %v in epoch %v %v by validator %v at %v.",
+			blockPageData.Slot, blockPageData.Epoch, blockStatus, blockPageData.Proposer, blockPageData.Ts.UTC().Format(time.RFC1123)),
+		"Blocks", "/blocks", blockPageData.Ts)
+
 	data.Data = blockPageData
 
 	if utils.IsApiRequest(r) {
@@ -287,7 +320,7 @@ func Block(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -306,19 +339,19 @@ func BlockDepositData(w http.ResponseWriter, r *http.Request) {
 		blockRootHash = []byte{}
 		blockSlot, err = strconv.ParseInt(vars["slotOrHash"], 10, 64)
 		if err != nil {
-			logger.Errorf("error parsing slotOrHash url parameter %v, err: %v", vars["slotOrHash"], err)
+			requestLogger(r).Errorf("error parsing slotOrHash url parameter %v, err: %v", vars["slotOrHash"], err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	} else {
-		err = db.DB.Get(&blockSlot, `
+		err = db.DB.GetContext(r.Context(), &blockSlot, `
 		SELECT
 			blocks.slot
 		FROM blocks
 		WHERE blocks.blockroot = $1
 		`, blockRootHash)
 		if err != nil {
-			logger.Errorf("error querying for block slot with block root hash %v err: %v", blockRootHash, err)
+			requestLogger(r).Errorf("error querying for block slot with block root hash %v err: %v", blockRootHash, err)
 			http.Error(w, "Interal server error", http.StatusInternalServerError)
 			return
 		}
@@ -331,20 +364,20 @@ func BlockDepositData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -355,7 +388,7 @@ func BlockDepositData(w http.ResponseWriter, r *http.Request) {
 
 	var count uint64
 
-	err = db.DB.Get(&count, `
+	err = db.DB.GetContext(r.Context(), &count, `
 	SELECT 
 		count(*)
 	FROM
@@ -366,14 +399,14 @@ func BlockDepositData(w http.ResponseWriter, r *http.Request) {
 	 block_slot
 	`, blockSlot)
 	if err != nil {
-		logger.Errorf("error retrieving deposit count for slot %v", err)
+		requestLogger(r).Errorf("error retrieving deposit count for slot %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	var deposits []*types.BlockPageDeposit
 
-	err = db.DB.Select(&deposits, `
+	err = db.DB.SelectContext(r.Context(), &deposits, `
 		SELECT
 			publickey,
 			withdrawalcredentials,
@@ -386,7 +419,7 @@ func BlockDepositData(w http.ResponseWriter, r *http.Request) {
 		OFFSET $3`,
 		blockSlot, length, start)
 	if err != nil {
-		logger.Errorf("error retrieving block deposit data: %v", err)
+		requestLogger(r).Errorf("error retrieving block deposit data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -412,7 +445,7 @@ func BlockDepositData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error encoding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error encoding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -430,20 +463,20 @@ func BlockVoteData(w http.ResponseWriter, r *http.Request) {
 	if err != nil || len(slotOrHash) != 64 {
 		blockSlot, err = strconv.ParseInt(vars["slotOrHash"], 10, 64)
 		if err != nil {
-			logger.Errorf("error parsing slotOrHash url parameter %v, err: %v", vars["slotOrHash"], err)
+			requestLogger(r).Errorf("error parsing slotOrHash url parameter %v, err: %v", vars["slotOrHash"], err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
-		err = db.DB.Get(&blockRootHash, "select blocks.blockroot from blocks where blocks.slot = $1", blockSlot)
+		err = db.DB.GetContext(r.Context(), &blockRootHash, "select blocks.blockroot from blocks where blocks.slot = $1", blockSlot)
 		if err != nil {
-			logger.Errorf("error getting blockRootHash for slot %v: %v", blockSlot, err)
+			requestLogger(r).Errorf("error getting blockRootHash for slot %v: %v", blockSlot, err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	} else {
-		err = db.DB.Get(&blockSlot, `SELECT blocks.slot FROM blocks WHERE blocks.blockroot = $1`, blockRootHash)
+		err = db.DB.GetContext(r.Context(), &blockSlot, `SELECT blocks.slot FROM blocks WHERE blocks.blockroot = $1`, blockRootHash)
 		if err != nil {
-			logger.Errorf("error querying for block slot with block root hash %v err: %v", blockRootHash, err)
+			requestLogger(r).Errorf("error querying for block slot with block root hash %v err: %v", blockRootHash, err)
 			http.Error(w, "Interal server error", http.StatusInternalServerError)
 			return
 		}
@@ -460,20 +493,20 @@ func BlockVoteData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -489,13 +522,13 @@ func BlockVoteData(w http.ResponseWriter, r *http.Request) {
 		CommitteeIndex uint64        `db:"committeeindex"`
 	}
 	if search == "" {
-		err = db.DB.Get(&count, `SELECT count(*) FROM blocks_attestations WHERE beaconblockroot = $1`, blockRootHash)
+		err = db.DB.GetContext(r.Context(), &count, `SELECT count(*) FROM blocks_attestations WHERE beaconblockroot = $1`, blockRootHash)
 		if err != nil {
-			logger.Errorf("error retrieving deposit count for slot %v", err)
+			requestLogger(r).Errorf("error retrieving deposit count for slot %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
-		err = db.DB.Select(&votes, `
+		err = db.DB.SelectContext(r.Context(), &votes, `
 			SELECT
 				block_slot,
 				validators,
@@ -507,18 +540,18 @@ func BlockVoteData(w http.ResponseWriter, r *http.Request) {
 			OFFSET $3`,
 			blockRootHash, length, start)
 		if err != nil {
-			logger.Errorf("error retrieving block vote data: %v", err)
+			requestLogger(r).Errorf("error retrieving block vote data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	} else if searchIsUint64 {
-		err = db.DB.Get(&count, `SELECT count(*) FROM blocks_attestations WHERE beaconblockroot = $1 AND $2 = ANY(validators)`, blockRootHash, searchUint64)
+		err = db.DB.GetContext(r.Context(), &count, `SELECT count(*) FROM blocks_attestations WHERE beaconblockroot = $1 AND $2 = ANY(validators)`, blockRootHash, searchUint64)
 		if err != nil {
-			logger.Errorf("error retrieving deposit count for slot %v", err)
+			requestLogger(r).Errorf("error retrieving deposit count for slot %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
-		err = db.DB.Select(&votes, `
+		err = db.DB.SelectContext(r.Context(), &votes, `
 			SELECT
 				block_slot,
 				validators,
@@ -530,7 +563,7 @@ func BlockVoteData(w http.ResponseWriter, r *http.Request) {
 			OFFSET $4`,
 			blockRootHash, searchUint64, length, start)
 		if err != nil {
-			logger.Errorf("error retrieving block vote data: %v", err)
+			requestLogger(r).Errorf("error retrieving block vote data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -559,7 +592,7 @@ func BlockVoteData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error encoding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error encoding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"net/http"
+)
+
+// blockPackingDays is the number of days the daily packing efficiency by client is returned for
+const blockPackingDays = 30
+
+// ApiClientBlockPacking godoc
+// @Summary Get the daily packing efficiency of the blocks of the last 30 days by consensus client of the proposer, the client is guessed from the graffiti of the blocks. The attestations available to a block are the attestation duties within the inclusion window that had not been included by an earlier block and got included eventually, the available sync committee signatures are the members that signed in any block of the epoch.
+// @Tags Network
+// @Produce  json
+// @Success 200 {object} types.ApiResponse{data=[]types.BlockPackingStats}
+// @Router /api/v1/network/packing [get]
+func ApiClientBlockPacking(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	endDay := services.LatestEpoch() / utils.EpochsPerDay()
+	startDay := uint64(0)
+	if endDay >= blockPackingDays {
+		startDay = endDay - blockPackingDays + 1
+	}
+
+	stats, err := db.GetDailyBlockPackingStats(r.Context(), startDay, endDay)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block packing for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// clientBlockPropagationWindow is the time span the propagation stats by client are computed over
+const clientBlockPropagationWindow = time.Hour * 24 * 7
+
+// ApiValidatorBlockPropagation godoc
+// @Summary Get the propagation delays of the blocks proposed by up to 100 validators. The delay of a block is the time after the start of its slot the first of the beacon nodes observed by the explorer saw it, blocks first seen after the late threshold (by default a third of a slot) are late. Validators with at least 3 observed blocks of which at least half are late are flagged as chronically late.
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse{data=[]types.BlockPropagationStats}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/propagation [get]
+func ApiValidatorBlockPropagation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	indices, pubkeys, err := parseApiValidatorParam(mux.Vars(r)["indexOrPubkey"], getUserPremium(r).MaxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	stats, err := db.GetProposerBlockPropagation(r.Context(), indices, pubkeys)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block propagation for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiClientBlockPropagation godoc
+// @Summary Get the propagation delays of the blocks of the last 7 days by consensus client of the proposer, the client is guessed from the graffiti of the blocks. The delay of a block is the time after the start of its slot the first of the beacon nodes observed by the explorer saw it.
+// @Tags Network
+// @Produce  json
+// @Success 200 {object} types.ApiResponse{data=[]types.BlockPropagationStats}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/propagation [get]
+func ApiClientBlockPropagation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	stats, err := db.GetClientBlockPropagation(r.Context(), utils.TimeToSlot(uint64(time.Now().Add(-clientBlockPropagationWindow).Unix())))
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block propagation for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// blockTreeMaxSlots is the largest window of slots the block tree is returned for, the votes of every attestation of
+// the window are unnested
+const blockTreeMaxSlots = 64
+
+// ApiBlockTree godoc
+// @Summary Get the block tree of a window of recent slots for a fork-choice visualizer: the proposed and orphaned blocks with their parents, the latest head votes of the validators for each block and the weight of each branch (the effective balance of the votes for a block and its descendants in gwei), and the branches of orphaned blocks. The window defaults to the last 32 slots and is at most 64 slots.
+// @Tags Block
+// @Produce  json
+// @Param  start query int false "First slot of the window, defaults to 31 slots before the end"
+// @Param  end query int false "Last slot of the window, defaults to the latest slot"
+// @Success 200 {object} types.ApiResponse{data=types.BlockTree}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/slots/tree [get]
+func ApiBlockTree(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	var err error
+	endSlot := services.LatestSlot()
+	if e := q.Get("end"); e != "" {
+		endSlot, err = strconv.ParseUint(e, 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid end slot")
+			return
+		}
+	}
+	startSlot := uint64(0)
+	if endSlot >= 31 {
+		startSlot = endSlot - 31
+	}
+	if s := q.Get("start"); s != "" {
+		startSlot, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid start slot")
+			return
+		}
+	}
+	if startSlot > endSlot {
+		sendErrorResponse(j, r.URL.String(), "start slot must not be after the end slot")
+		return
+	}
+	if endSlot-startSlot >= blockTreeMaxSlots {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("the window must not be larger than %v slots", blockTreeMaxSlots))
+		return
+	}
+
+	tree, err := db.GetBlockTree(r.Context(), startSlot, endSlot)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block tree for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{tree})
+}
@@ -23,7 +23,7 @@ func Blocks(w http.ResponseWriter, r *http.Request) {
 	err := blocksTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -40,19 +40,19 @@ func BlocksData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -64,9 +64,9 @@ func BlocksData(w http.ResponseWriter, r *http.Request) {
 	var filteredCount uint64
 	var blocks []*types.BlocksPageDataBlocks
 
-	err = db.DB.Get(&totalCount, "SELECT COALESCE(MAX(slot) + 1,0) FROM blocks")
+	err = db.DB.GetContext(r.Context(), &totalCount, "SELECT COALESCE(MAX(slot) + 1,0) FROM blocks")
 	if err != nil {
-		logger.Errorf("error retrieving max slot number: %v", err)
+		requestLogger(r).Errorf("error retrieving max slot number: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -82,7 +82,7 @@ func BlocksData(w http.ResponseWriter, r *http.Request) {
 		if endSlot > 9223372036854775807 {
 			endSlot = 0
 		}
-		err = db.DB.Select(&blocks, `
+		err = db.DB.SelectContext(r.Context(), &blocks, `
 			SELECT 
 				blocks.epoch, 
 				blocks.slot, 
@@ -105,7 +105,7 @@ func BlocksData(w http.ResponseWriter, r *http.Request) {
 			WHERE blocks.slot >= $1 AND blocks.slot <= $2 
 			ORDER BY blocks.slot DESC`, endSlot, startSlot)
 		if err != nil {
-			logger.Errorf("error retrieving block data: %v", err)
+			requestLogger(r).Errorf("error retrieving block data: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -193,9 +193,9 @@ func BlocksData(w http.ResponseWriter, r *http.Request) {
 			LEFT JOIN (select count(*) from matched_slots) cnt(total_count) ON true
 			ORDER BY slot DESC LIMIT $%v OFFSET $%v`, searchBlocksQry, len(args)-1, len(args))
 
-		err = db.DB.Select(&blocks, qry, args...)
+		err = db.DB.SelectContext(r.Context(), &blocks, qry, args...)
 		if err != nil {
-			logger.Errorf("error retrieving block data (with search): %v", err)
+			requestLogger(r).Errorf("error retrieving block data (with search): %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -250,7 +250,7 @@ func BlocksData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var blsChangeTemplate = template.Must(template.New("blsChange").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/blsChange.html"))
+
+const (
+	blsChangeFlash          = "bls_change_flash"
+	blsChangeMaxSubmissions = 100
+)
+
+// BLSChange shows the tool to broadcast signed bls to execution changes and the progress of the changes submitted
+// through it, the list can be filtered with the validators query parameter
+func BLSChange(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "more", "/tools/blsChange", "BLS Withdrawal Credentials Change")
+
+	pageData := &types.BLSChangePageData{}
+	pageData.BroadcastEnabled = utils.Config.Frontend.BeaconNodeEndpoint != ""
+	pageData.FlashMessage, err = utils.GetFlash(w, r, blsChangeFlash)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving flashes for bls change %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	pageData.ValidatorsFilter = r.URL.Query().Get("validators")
+	validators, err := parseValidatorsFromQueryString(pageData.ValidatorsFilter, blsChangeMaxSubmissions)
+	if err != nil {
+		http.Error(w, "Invalid query", 400)
+		return
+	}
+
+	pageData.Changes, err = db.GetBLSChanges(r.Context(), validators, blsChangeMaxSubmissions)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving bls changes: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	if pageData.BroadcastEnabled {
+		pool, err := rpc.GetBLSToExecutionChangesPool(utils.Config.Frontend.BeaconNodeEndpoint)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving bls change pool: %v", err)
+			pageData.PoolUnavailable = true
+		}
+		pageData.PoolSize = len(pool)
+		inPool := make(map[uint64]bool, len(pool))
+		for _, change := range pool {
+			inPool[uint64(change.Message.ValidatorIndex)] = true
+		}
+		for _, change := range pageData.Changes {
+			change.InPool = inPool[change.Validatorindex]
+		}
+	}
+
+	data.Data = pageData
+
+	err = blsChangeTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// BLSChangePost validates the submitted signed bls to execution changes against the credentials of the validators and
+// broadcasts them via the beacon node, which verifies the signatures
+func BLSChangePost(w http.ResponseWriter, r *http.Request) {
+	if utils.Config.Frontend.BeaconNodeEndpoint == "" {
+		utils.SetFlash(w, r, blsChangeFlash, "Error: broadcasting bls changes is not available")
+		http.Redirect(w, r, "/tools/blsChange", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		requestLogger(r).Errorf("error parsing form: %v", err)
+		utils.SetFlash(w, r, blsChangeFlash, "Error: invalid form submitted")
+		http.Redirect(w, r, "/tools/blsChange", http.StatusSeeOther)
+		return
+	}
+
+	changes, err := parseBLSChanges(r.FormValue("changes"))
+	if err != nil {
+		utils.SetFlash(w, r, blsChangeFlash, fmt.Sprintf("Error: %v", err))
+		http.Redirect(w, r, "/tools/blsChange", http.StatusSeeOther)
+		return
+	}
+
+	validators := make([]uint64, len(changes))
+	for i, change := range changes {
+		validators[i] = uint64(change.Message.ValidatorIndex)
+	}
+	credentials, err := db.GetValidatorsWithdrawalCredentials(r.Context(), validators)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving withdrawal credentials for bls changes: %v", err)
+		utils.SetFlash(w, r, blsChangeFlash, "Error: could not retrieve the withdrawal credentials of the validators")
+		http.Redirect(w, r, "/tools/blsChange", http.StatusSeeOther)
+		return
+	}
+
+	for _, change := range changes {
+		err = validateBLSChange(change, credentials[uint64(change.Message.ValidatorIndex)])
+		if err != nil {
+			utils.SetFlash(w, r, blsChangeFlash, fmt.Sprintf("Error: validator %v: %v", uint64(change.Message.ValidatorIndex), err))
+			http.Redirect(w, r, "/tools/blsChange", http.StatusSeeOther)
+			return
+		}
+	}
+
+	err = rpc.SubmitBLSToExecutionChanges(utils.Config.Frontend.BeaconNodeEndpoint, changes)
+	if err != nil {
+		requestLogger(r).Warnf("error broadcasting bls changes: %v", err)
+		utils.SetFlash(w, r, blsChangeFlash, fmt.Sprintf("Error: the beacon node rejected the changes: %v", template.HTMLEscapeString(err.Error())))
+		http.Redirect(w, r, "/tools/blsChange", http.StatusSeeOther)
+		return
+	}
+
+	filter := make([]string, len(changes))
+	for i, change := range changes {
+		filter[i] = fmt.Sprintf("%d", uint64(change.Message.ValidatorIndex))
+		err = db.SaveBLSChangeSubmission(r.Context(), uint64(change.Message.ValidatorIndex), utils.MustParseHex(change.Message.FromBLSPubkey), utils.MustParseHex(change.Message.ToExecutionAddress), utils.MustParseHex(change.Signature))
+		if err != nil {
+			requestLogger(r).Errorf("error saving bls change submission of validator %v: %v", uint64(change.Message.ValidatorIndex), err)
+		}
+	}
+
+	utils.SetFlash(w, r, blsChangeFlash, fmt.Sprintf("%v bls changes have been broadcast, they will be included in one of the next blocks.", len(changes)))
+	http.Redirect(w, r, "/tools/blsChange?validators="+url.QueryEscape(strings.Join(filter, ",")), http.StatusSeeOther)
+}
+
+// parseBLSChanges parses a single or an array of signed bls to execution changes as produced by the staking deposit cli
+// or ethdo and checks the length of their fields
+func parseBLSChanges(input string) ([]*rpc.SignedBLSToExecutionChange, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("no bls changes provided")
+	}
+
+	changes := []*rpc.SignedBLSToExecutionChange{}
+	var err error
+	if strings.HasPrefix(input, "[") {
+		err = json.Unmarshal([]byte(input), &changes)
+	} else {
+		change := &rpc.SignedBLSToExecutionChange{}
+		err = json.Unmarshal([]byte(input), change)
+		changes = append(changes, change)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the bls changes are not valid json")
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no bls changes provided")
+	}
+	if len(changes) > blsChangeMaxSubmissions {
+		return nil, fmt.Errorf("only %v bls changes can be broadcast at once", blsChangeMaxSubmissions)
+	}
+
+	seen := make(map[uint64]bool, len(changes))
+	for i, change := range changes {
+		if seen[uint64(change.Message.ValidatorIndex)] {
+			return nil, fmt.Errorf("change %v: duplicate change for validator %v", i+1, uint64(change.Message.ValidatorIndex))
+		}
+		seen[uint64(change.Message.ValidatorIndex)] = true
+
+		if !isHexOfLength(change.Message.FromBLSPubkey, 48) {
+			return nil, fmt.Errorf("change %v: invalid from_bls_pubkey", i+1)
+		}
+		if !isHexOfLength(change.Message.ToExecutionAddress, 20) {
+			return nil, fmt.Errorf("change %v: invalid to_execution_address", i+1)
+		}
+		if !isHexOfLength(change.Signature, 96) {
+			return nil, fmt.Errorf("change %v: invalid signature", i+1)
+		}
+	}
+	return changes, nil
+}
+
+// validateBLSChange checks that the validator still has bls credentials and that they are derived from the bls key of
+// the change
+func validateBLSChange(change *rpc.SignedBLSToExecutionChange, credentials []byte) error {
+	if len(credentials) != 32 {
+		return fmt.Errorf("the validator does not exist")
+	}
+	if credentials[0] != utils.BLSWithdrawalPrefix {
+		return fmt.Errorf("the validator already has execution withdrawal credentials")
+	}
+	pubkeyHash := sha256.Sum256(utils.MustParseHex(change.Message.FromBLSPubkey))
+	if !bytes.Equal(pubkeyHash[1:], credentials[1:]) {
+		return fmt.Errorf("from_bls_pubkey does not match the withdrawal credentials of the validator")
+	}
+	return nil
+}
+
+func isHexOfLength(s string, length int) bool {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	return err == nil && len(b) == length
+}
@@ -15,9 +15,9 @@ func StakingCalculator(w http.ResponseWriter, r *http.Request) {
 
 	calculatorPageData := types.StakingCalculatorPageData{}
 
-	total, err := db.GetTotalEligibleEther()
+	total, err := db.GetTotalEligibleEther(r.Context())
 	if err != nil {
-		logger.WithError(err).Error("error getting total staked ether")
+		requestLogger(r).WithError(err).Error("error getting total staked ether")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -32,7 +32,7 @@ func StakingCalculator(w http.ResponseWriter, r *http.Request) {
 	// stakingCalculatorTemplate = template.Must(template.New("staking_estimator").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/calculator.html"))
 	err = stakingCalculatorTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
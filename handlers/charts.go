@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"eth2-exporter/db"
+	"eth2-exporter/featureflags"
+	"eth2-exporter/preview"
 	"eth2-exporter/services"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -17,6 +21,22 @@ var genericChartTemplate = template.Must(template.New("chart").Funcs(utils.GetTe
 var chartsUnavailableTemplate = template.Must(template.New("chart").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/chartsunavailable.html"))
 var slotVizTemplate = template.Must(template.New("slotViz").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/slotViz.html"))
 
+// chartFeatureFlags are the feature flags that must be enabled to show a chart
+var chartFeatureFlags = map[string]string{
+	"attestation_aggregation": featureflags.ExperimentalCharts,
+	"builder_market_share":    featureflags.ExperimentalCharts,
+	"cohort_apr":              featureflags.ExperimentalCharts,
+	"relay_auctions":          featureflags.ExperimentalCharts,
+	"rocketpool_reth_premium": featureflags.Rocketpool,
+	"wrong_head_rate":         featureflags.ExperimentalCharts,
+}
+
+// chartEnabled returns true if the feature flag of the chart is enabled or the chart has no feature flag
+func chartEnabled(chart string) bool {
+	flag, ok := chartFeatureFlags[chart]
+	return !ok || featureflags.Enabled(flag)
+}
+
 // Charts uses a go template for presenting the page to show charts
 func Charts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
@@ -27,19 +47,28 @@ func Charts(w http.ResponseWriter, r *http.Request) {
 	if chartsPageData == nil {
 		err := chartsUnavailableTemplate.ExecuteTemplate(w, "layout", data)
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 		return
 	}
 
-	data.Data = chartsPageData
+	themedCharts := make([]*types.ChartsPageDataChart, 0, len(*chartsPageData))
+	for _, c := range *chartsPageData {
+		if !chartEnabled(c.Path) {
+			continue
+		}
+		themedChart := *c
+		themedChart.Data = themedChartData(c.Data, data.Theme)
+		themedCharts = append(themedCharts, &themedChart)
+	}
+	data.Data = &themedCharts
 
 	chartsTemplate = template.Must(template.New("charts").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/charts.html"))
 	err := chartsTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -69,7 +98,7 @@ func GenericChart(w http.ResponseWriter, r *http.Request) {
 	if chartsPageData == nil {
 		err := chartsUnavailableTemplate.ExecuteTemplate(w, "layout", data)
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -78,7 +107,7 @@ func GenericChart(w http.ResponseWriter, r *http.Request) {
 
 	var chartData *types.GenericChartData
 	for _, d := range *chartsPageData {
-		if d.Path == chartVar {
+		if d.Path == chartVar && chartEnabled(d.Path) {
 			chartData = d.Data
 			break
 		}
@@ -91,17 +120,118 @@ func GenericChart(w http.ResponseWriter, r *http.Request) {
 
 	data.Meta.Title = fmt.Sprintf("%v - %v Chart - beaconcha.in - %v", chartData.Title, utils.Config.Frontend.SiteName, time.Now().Year())
 	data.Meta.Path = "/charts/" + chartVar
-	data.Data = chartData
+	data.Data = themedChartData(chartData, data.Theme)
 
 	genericChartTemplate = template.Must(template.New("chart").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/genericchart.html"))
 	err := genericChartTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 }
 
+// ChartImage renders a chart server-side as png or svg, the size can be set with the width and height query parameters
+// and the range of time charts with the start and end unix timestamps
+func ChartImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	chartVar := vars["chart"]
+	format := vars["format"]
+	q := r.URL.Query()
+
+	if utils.Config.Frontend.ChartRenderer == "screenshot" {
+		if format != "png" {
+			http.Error(w, "Only png images are available", http.StatusNotFound)
+			return
+		}
+		var image []byte
+		err := db.DB.GetContext(r.Context(), &image, "SELECT image FROM chart_images WHERE name = $1", chartVar)
+		if err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(image)
+		return
+	}
+
+	opts := &preview.ChartOptions{Width: 1200, Height: 600}
+	var err error
+	if q.Get("width") != "" {
+		opts.Width, err = strconv.Atoi(q.Get("width"))
+		if err != nil || opts.Width < 200 || opts.Width > 2000 {
+			http.Error(w, "Invalid width, must be between 200 and 2000", http.StatusBadRequest)
+			return
+		}
+	}
+	if q.Get("height") != "" {
+		opts.Height, err = strconv.Atoi(q.Get("height"))
+		if err != nil || opts.Height < 150 || opts.Height > 1500 {
+			http.Error(w, "Invalid height, must be between 150 and 1500", http.StatusBadRequest)
+			return
+		}
+	}
+	if q.Get("start") != "" {
+		ts, err := strconv.ParseInt(q.Get("start"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid start", http.StatusBadRequest)
+			return
+		}
+		opts.Start = time.Unix(ts, 0)
+	}
+	if q.Get("end") != "" {
+		ts, err := strconv.ParseInt(q.Get("end"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid end", http.StatusBadRequest)
+			return
+		}
+		opts.End = time.Unix(ts, 0)
+	}
+
+	chartsPageData := services.LatestChartsPageData()
+	if chartsPageData == nil {
+		http.Error(w, "Charts are not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	var chartData *types.GenericChartData
+	for _, d := range *chartsPageData {
+		if d.Path == chartVar && chartEnabled(d.Path) {
+			chartData = d.Data
+			break
+		}
+	}
+	if chartData == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	// the images are rendered on a dark background
+	chartData = themedChartData(chartData, utils.ThemeDark)
+
+	var img []byte
+	if format == "svg" {
+		img, err = preview.RenderChartSVG(chartData, opts)
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		img, err = preview.RenderChartPNG(chartData, opts)
+		w.Header().Set("Content-Type", "image/png")
+	}
+	if err == preview.ErrChartNotRenderable {
+		http.Error(w, "This chart can not be rendered as image", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error rendering chart %v for %v route: %v", chartVar, r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(img)
+}
+
 // SlotViz renders a single page with a d3 slot (block) visualisation
 func SlotViz(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
@@ -110,7 +240,7 @@ func SlotViz(w http.ResponseWriter, r *http.Request) {
 	data.Data = nil
 	err := slotVizTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"eth2-exporter/utils"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientIP returns the ip address of the client of the request. Behind a trusted proxy the address is read from the
+// configured header, the last address of a list (e.g. X-Forwarded-For) is the one the proxy appended itself.
+func clientIP(r *http.Request) string {
+	if header := utils.Config.Frontend.ClientIPHeader; header != "" {
+		values := strings.Split(r.Header.Get(header), ",")
+		if ip := strings.TrimSpace(values[len(values)-1]); ip != "" {
+			return ip
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return ip
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/price"
@@ -8,6 +9,7 @@ import (
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
+	"math/big"
 	"net/http"
 	"regexp"
 	"strings"
@@ -32,7 +34,7 @@ func GetValidatorOnlineThresholdSlot() uint64 {
 }
 
 // GetValidatorEarnings will return the earnings (last day, week, month and total) of selected validators
-func GetValidatorEarnings(validators []uint64, currency string) (*types.ValidatorEarnings, error) {
+func GetValidatorEarnings(ctx context.Context, validators []uint64, currency string) (*types.ValidatorEarnings, error) {
 	validatorsPQArray := pq.Array(validators)
 	latestEpoch := int64(services.LatestEpoch())
 	lastDayEpoch := latestEpoch - 225
@@ -76,6 +78,29 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 		return nil, err
 	}
 
+	// the balance of a validator that is consolidated into another one moves to the target once the source is
+	// withdrawable, it is counted like a deposit of the target (and a negative one of the source) if only one side of
+	// the consolidation is part of the selected validators
+	consolidations := []struct {
+		Epoch     int64
+		Amount    int64
+		Publickey []byte
+	}{}
+
+	err = db.DB.Select(&consolidations, `
+		SELECT src.withdrawableepoch AS epoch, CASE WHEN bcr.target_pubkey = sel.pubkey THEN 1 ELSE -1 END * COALESCE(vb.balance, 0) AS amount, sel.pubkey AS publickey
+		FROM blocks_consolidation_requests bcr
+		INNER JOIN blocks ON blocks.slot = bcr.block_slot AND blocks.blockroot = bcr.block_root AND blocks.status = '1'
+		INNER JOIN validators src ON src.pubkey = bcr.source_pubkey AND src.withdrawableepoch <= $2
+		INNER JOIN validators sel ON sel.validatorindex = ANY($1) AND (sel.pubkey = bcr.target_pubkey OR sel.pubkey = bcr.source_pubkey)
+		LEFT JOIN validator_balances_p vb ON vb.week = src.exitepoch / 1575 AND vb.epoch = src.exitepoch AND vb.validatorindex = src.validatorindex
+		WHERE bcr.source_pubkey != bcr.target_pubkey
+			AND (SELECT COUNT(*) FROM validators WHERE validatorindex = ANY($1) AND pubkey IN (bcr.source_pubkey, bcr.target_pubkey)) = 1`, validatorsPQArray, latestEpoch)
+	if err != nil {
+		return nil, err
+	}
+	deposits = append(deposits, consolidations...)
+
 	depositsMap := make(map[string]map[int64]int64)
 	for _, d := range deposits {
 		if _, exists := depositsMap[fmt.Sprintf("%x", d.Publickey)]; !exists {
@@ -128,7 +153,22 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 		earningsLastMonth += int64(balance.Balance) - int64(balance.Balance31d)
 	}
 
-	apr = (((float64(earningsLastWeek) / 1e9) / (float64(totalDeposits) / 1e9)) * 365) / 7
+	// the el rewards are paid in wei, the income is summed up exactly and only converted for the apr
+	elRewards, err := db.GetValidatorsElRewards(ctx, validators, lastDayEpoch, lastWeekEpoch, lastMonthEpoch)
+	if err != nil {
+		return nil, err
+	}
+	income := types.ValidatorIncomePeriods{
+		Total:     types.WeiFromGwei(earningsTotal).Add(elRewards.Total),
+		LastDay:   types.WeiFromGwei(earningsLastDay).Add(elRewards.LastDay),
+		LastWeek:  types.WeiFromGwei(earningsLastWeek).Add(elRewards.LastWeek),
+		LastMonth: types.WeiFromGwei(earningsLastMonth).Add(elRewards.LastMonth),
+	}
+
+	if totalDeposits != 0 {
+		weeklyReturn, _ := new(big.Rat).Quo(income.LastWeek.Rat(0), types.WeiFromGwei(totalDeposits).Rat(0)).Float64()
+		apr = weeklyReturn * 365 / 7
+	}
 	if apr < float64(-1) {
 		apr = float64(-1)
 	}
@@ -140,11 +180,13 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 		LastMonth:            earningsLastMonth,
 		APR:                  apr,
 		TotalDeposits:        totalDeposits,
-		LastDayFormatted:     utils.FormatIncome(earningsLastDay, currency),
-		LastWeekFormatted:    utils.FormatIncome(earningsLastWeek, currency),
-		LastMonthFormatted:   utils.FormatIncome(earningsLastMonth, currency),
-		TotalFormatted:       utils.FormatIncome(earningsTotal, currency),
+		LastDayFormatted:     utils.FormatIncomeWei(income.LastDay, currency),
+		LastWeekFormatted:    utils.FormatIncomeWei(income.LastWeek, currency),
+		LastMonthFormatted:   utils.FormatIncomeWei(income.LastMonth, currency),
+		TotalFormatted:       utils.FormatIncomeWei(income.Total, currency),
 		TotalChangeFormatted: utils.FormatIncome(earningsTotal+totalDeposits, currency),
+		El:                   *elRewards,
+		Income:               income,
 	}, nil
 }
 
@@ -161,7 +203,7 @@ func LatestState(w http.ResponseWriter, r *http.Request) {
 	err := json.NewEncoder(w).Encode(data)
 
 	if err != nil {
-		logger.Errorf("error sending latest index page data: %v", err)
+		requestLogger(r).Errorf("error sending latest index page data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/lib/pq"
+)
+
+const (
+	compareMaxSets     = 10
+	compareMaxDays     = 90
+	compareDefaultDays = 7
+	// compareRateLimit is the number of comparisons an api key can request per compareRateLimitWindow, a comparison
+	// aggregates the daily stats of whole pools
+	compareRateLimit       = 10
+	compareRateLimitWindow = time.Minute
+	// compareCacheSize is the number of comparisons that are cached, the daily stats only change once per day
+	compareCacheSize = 1000
+)
+
+var compareRequests = newRateLimiter(compareRateLimit, compareRateLimitWindow)
+var compareCache *lru.Cache
+
+func init() {
+	var err error
+	compareCache, err = lru.New(compareCacheSize)
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// compareCacheKey returns the key of the comparison of the sets over the days, the key contains the last exported
+// statistics day so that the comparisons are recomputed once a day has been exported
+func compareCacheKey(sets []*types.CompareSetRequest, startDay, endDay, lastDay uint64) (string, error) {
+	b, err := json.Marshal(sets)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	return fmt.Sprintf("%v:%v:%v:%x", lastDay, startDay, endDay, hash), nil
+}
+
+// parseCompareDate returns the day since genesis of a YYYY-MM-DD date
+func parseCompareDate(date string) (uint64, error) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %v", date)
+	}
+	if t.Unix() < int64(utils.Config.Chain.GenesisTimestamp) {
+		return 0, nil
+	}
+	return utils.TimeToDay(uint64(t.Unix())), nil
+}
+
+// parseCompareSet returns the indices, pubkeys and lower case tags of the validators of a set
+func parseCompareSet(set *types.CompareSetRequest) ([]uint64, pq.ByteaArray, []string, error) {
+	indices := []uint64{}
+	pubkeys := pq.ByteaArray{}
+	tags := []string{}
+
+	if len(set.IndicesOrPubkey) > 0 {
+		params := make([]string, len(set.IndicesOrPubkey))
+		for i, v := range set.IndicesOrPubkey {
+			switch v := v.(type) {
+			case string:
+				params[i] = v
+			case float64:
+				params[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				return nil, nil, nil, fmt.Errorf("invalid validator-parameter: %v", v)
+			}
+		}
+		var err error
+		indices, pubkeys, err = parseApiValidatorParam(strings.Join(params, ","), apiMaxBulkValidators)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if set.Tag != "" {
+		tags = append(tags, strings.ToLower(set.Tag))
+	}
+	// the pools of the deposit addresses are tagged as pool:<name>, rocketpool and ssv validators by their name
+	if set.Pool != "" {
+		tags = append(tags, strings.ToLower(set.Pool), "pool:"+strings.ToLower(set.Pool))
+	}
+	if len(indices) == 0 && len(pubkeys) == 0 && len(tags) == 0 {
+		return nil, nil, nil, fmt.Errorf("no validators, tag or pool provided for set %v", set.Name)
+	}
+	return indices, pubkeys, tags, nil
+}
+
+// ApiCompare godoc
+// @Summary Compare the performance of two or more sets of validators, e.g. rocketpool against lido, over a range of days. A set is given by validator indices or pubkeys, a validator tag or the name of a staking pool. The apr, effectiveness and missed duties are aggregated from the daily validator statistics, by default over the last 7 exported days (max 90 days). Requires an api key, every key can request 10 comparisons per minute.
+// @Tags Validator
+// @Accept  json
+// @Produce  json
+// @Param  apikey query string true "User API key, can be found on https://beaconcha.in/user/settings"
+// @Param  request body types.CompareRequest true "Up to 10 sets and the start_date and end_date (YYYY-MM-DD) of the comparison"
+// @Success 200 {object} types.ApiResponse{data=[]types.ValidatorSetComparison}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 429 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/compare [post]
+func ApiCompare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	apiKey := r.URL.Query().Get("apikey")
+	if apiKey == "" {
+		apiKey = r.Header.Get("apikey")
+	}
+	if apiKey == "" {
+		sendErrorResponse(j, r.URL.String(), "an api key is required")
+		return
+	}
+	_, err := db.GetUserIdByApiKey(r.Context(), apiKey)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "no user found with api key")
+		return
+	}
+	if limited, retryAfter := compareRequests.limited(apiKey); limited {
+		w.Header().Set("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()+1))
+		w.WriteHeader(http.StatusTooManyRequests)
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("only %v comparisons can be requested per minute", compareRateLimit))
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, apiMaxBulkBodySize))
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not read body")
+		return
+	}
+	req := &types.CompareRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "invalid request body")
+		return
+	}
+	if len(req.Sets) < 2 || len(req.Sets) > compareMaxSets {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("between 2 and %v sets are required", compareMaxSets))
+		return
+	}
+
+	var lastDay *uint64
+	err = db.DB.GetContext(r.Context(), &lastDay, "SELECT MAX(day) FROM validator_stats_status WHERE status")
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving last exported statistics day: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if lastDay == nil {
+		sendErrorResponse(j, r.URL.String(), "no statistics available yet")
+		return
+	}
+
+	endDay := *lastDay
+	if req.EndDate != "" {
+		endDay, err = parseCompareDate(req.EndDate)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), err.Error())
+			return
+		}
+	}
+	startDay := uint64(0)
+	if endDay >= compareDefaultDays {
+		startDay = endDay - compareDefaultDays + 1
+	}
+	if req.StartDate != "" {
+		startDay, err = parseCompareDate(req.StartDate)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), err.Error())
+			return
+		}
+	}
+	if startDay > endDay || endDay-startDay >= compareMaxDays {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("the range must contain between 1 and %v days", compareMaxDays))
+		return
+	}
+
+	key, err := compareCacheKey(req.Sets, startDay, endDay, *lastDay)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "invalid request body")
+		return
+	}
+	if cached, found := compareCache.Get(key); found {
+		sendOKResponse(j, r.URL.String(), cached.([]interface{}))
+		return
+	}
+
+	data := make([]interface{}, 0, len(req.Sets))
+	for i, set := range req.Sets {
+		if set.Name == "" {
+			set.Name = fmt.Sprintf("set %v", i+1)
+		}
+		indices, pubkeys, tags, err := parseCompareSet(set)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), err.Error())
+			return
+		}
+		comparison, err := db.GetValidatorSetComparison(r.Context(), indices, pubkeys, tags, startDay, endDay)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving comparison of validator set %v: %v", set.Name, err)
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		comparison.Name = set.Name
+		data = append(data, comparison)
+	}
+	compareCache.Add(key, data)
+
+	sendOKResponse(j, r.URL.String(), data)
+}
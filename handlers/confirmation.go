@@ -18,7 +18,7 @@ func Confirmation(w http.ResponseWriter, r *http.Request) {
 
 	// _, session, err := getUserSession(w, r)
 	// if err != nil {
-	// 	logger.Errorf("error retrieving session: %v", err)
+	// 	requestLogger(r).Errorf("error retrieving session: %v", err)
 	// 	http.Error(w, "Internal server error", http.StatusInternalServerError)
 	// 	return
 	// }
@@ -40,7 +40,7 @@ func Confirmation(w http.ResponseWriter, r *http.Request) {
 
 	err := confirmationTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/csrf"
+)
+
+// CsrfToken returns a csrf token in the X-CSRF-Token header, it is used by the scripts of pages that are not served with
+// a token to post to csrf protected routes
+func CsrfToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-CSRF-Token", csrf.Token(r))
+	w.WriteHeader(http.StatusNoContent)
+}
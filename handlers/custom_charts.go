@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	gorillacontext "github.com/gorilla/context"
+	"github.com/gorilla/mux"
+)
+
+const (
+	customChartMaxValidators = 100
+	customChartMaxDays       = 365
+	customChartMaxUserCharts = 50
+	customChartMaxTitle      = 100
+)
+
+var customChartIDRegex = regexp.MustCompile("^[0-9a-f]{16}$")
+
+// validateCustomChartDefinition checks the metric, scope, aggregation and range of the chart definition and fills in
+// the defaults of omitted fields
+func validateCustomChartDefinition(def *types.CustomChartDefinition) error {
+	def.Title = strings.TrimSpace(def.Title)
+	if len(def.Title) > customChartMaxTitle {
+		return fmt.Errorf("the title can be at most %v characters long", customChartMaxTitle)
+	}
+	if def.Aggregation == "" {
+		def.Aggregation = "sum"
+	}
+	if _, exists := db.CustomChartAggregations[def.Aggregation]; !exists {
+		return fmt.Errorf("invalid aggregation %v", def.Aggregation)
+	}
+
+	switch def.Scope {
+	case "network":
+		if _, exists := db.CustomChartNetworkMetrics[def.Metric]; !exists {
+			return fmt.Errorf("invalid network metric %v", def.Metric)
+		}
+		def.Validators = nil
+	case "validators":
+		if _, exists := db.CustomChartValidatorMetrics[def.Metric]; !exists {
+			return fmt.Errorf("invalid validator metric %v", def.Metric)
+		}
+		if len(def.Validators) == 0 {
+			return fmt.Errorf("no validators provided")
+		}
+		if len(def.Validators) > customChartMaxValidators {
+			return fmt.Errorf("only a maximum of %v validators can be charted", customChartMaxValidators)
+		}
+	default:
+		return fmt.Errorf("invalid scope %v, must be network or validators", def.Scope)
+	}
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	today := services.LatestEpoch() / epochsPerDay
+	if def.EndDay == 0 || def.EndDay > today {
+		def.EndDay = today
+	}
+	if def.StartDay == 0 && def.EndDay >= customChartMaxDays {
+		def.StartDay = def.EndDay - customChartMaxDays + 1
+	}
+	if def.StartDay > def.EndDay {
+		return fmt.Errorf("start_day must not be after end_day")
+	}
+	if def.EndDay-def.StartDay >= customChartMaxDays {
+		return fmt.Errorf("only a maximum of %v days can be charted", customChartMaxDays)
+	}
+	return nil
+}
+
+// customChartSeries returns the points of the chart as [day start in ms, value] pairs like the web charts expect them
+func customChartSeries(points []*types.CustomChartPoint) [][]float64 {
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	series := make([][]float64, len(points))
+	for i, p := range points {
+		series[i] = []float64{float64(utils.EpochToTime(p.Day*epochsPerDay).Unix() * 1000), p.Value}
+	}
+	return series
+}
+
+func customChartResponse(id string, def *types.CustomChartDefinition, points []*types.CustomChartPoint) map[string]interface{} {
+	res := map[string]interface{}{
+		"definition": def,
+		"series":     customChartSeries(points),
+	}
+	if id != "" {
+		res["id"] = id
+		res["url"] = fmt.Sprintf("/charts/custom/%v", id)
+	}
+	return res
+}
+
+// ApiCustomChart godoc
+// @Summary Execute a custom chart definition of a network or validator metric without saving it
+// @Tags Charts
+// @Accept  json
+// @Produce  json
+// @Param  definition body types.CustomChartDefinition true "The chart definition, scope is either network or validators"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/charts/custom [post]
+func ApiCustomChart(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<16))
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not read body")
+		return
+	}
+
+	def := &types.CustomChartDefinition{}
+	err = json.Unmarshal(body, def)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not parse body")
+		return
+	}
+	err = validateCustomChartDefinition(def)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	points, err := db.GetCustomChartSeries(r.Context(), def)
+	if err != nil {
+		requestLogger(r).Errorf("error executing custom chart: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{customChartResponse("", def, points)})
+}
+
+// ApiCustomChartSave godoc
+// @Summary Save a custom chart definition and return its series and the id it can be shared by
+// @Tags User
+// @Accept  json
+// @Produce  json
+// @Param  definition body types.CustomChartDefinition true "The chart definition, scope is either network or validators"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/charts [post]
+func ApiCustomChartSave(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	claims := getAuthClaims(r)
+
+	body, ok := gorillacontext.Get(r, utils.JsonBodyNakedKey).([]byte)
+	if !ok {
+		sendErrorResponse(j, r.URL.String(), "could not parse body")
+		return
+	}
+
+	def := &types.CustomChartDefinition{}
+	err := json.Unmarshal(body, def)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not parse body")
+		return
+	}
+	err = validateCustomChartDefinition(def)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	count, err := db.GetUserCustomChartCount(r.Context(), claims.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving custom chart count of user %v: %v", claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if count >= customChartMaxUserCharts {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("reached the maximum of %v saved charts", customChartMaxUserCharts))
+		return
+	}
+
+	points, err := db.GetCustomChartSeries(r.Context(), def)
+	if err != nil {
+		requestLogger(r).Errorf("error executing custom chart: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	id, err := db.SaveCustomChart(r.Context(), claims.UserID, def)
+	if err != nil {
+		requestLogger(r).Errorf("error saving custom chart of user %v: %v", claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not save chart")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{customChartResponse(id, def, points)})
+}
+
+// ApiCustomChartByID godoc
+// @Summary Get the definition and the series of a saved custom chart
+// @Tags Charts
+// @Produce  json
+// @Param  id path string true "Id of the saved chart"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/charts/custom/{id} [get]
+func ApiCustomChartByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	id := mux.Vars(r)["id"]
+	def, points, err := getCustomChart(r.Context(), id)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving custom chart %v: %v", id, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if def == nil {
+		sendErrorResponse(j, r.URL.String(), "chart not found")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{customChartResponse(id, def, points)})
+}
+
+// getCustomChart returns the definition and the series of a saved chart, a nil definition is returned if there is no
+// chart with the id
+func getCustomChart(ctx context.Context, id string) (*types.CustomChartDefinition, []*types.CustomChartPoint, error) {
+	if !customChartIDRegex.MatchString(id) {
+		return nil, nil, nil
+	}
+	def, err := db.GetCustomChart(ctx, id)
+	if err != nil || def == nil {
+		return nil, nil, err
+	}
+	points, err := db.GetCustomChartSeries(ctx, def)
+	if err != nil {
+		return nil, nil, err
+	}
+	return def, points, nil
+}
+
+// CustomChart renders a saved custom chart with the generic chart template
+func CustomChart(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "stats", "/charts", "Chart")
+
+	def, points, err := getCustomChart(r.Context(), id)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving custom chart %v: %v", id, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if def == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	title := def.Title
+	if title == "" {
+		title = fmt.Sprintf("%v of %v (%v)", strings.Title(strings.ReplaceAll(def.Metric, "_", " ")), def.Scope, def.Aggregation)
+	}
+	subtitle := "Network"
+	if def.Scope == "validators" {
+		subtitle = fmt.Sprintf("%v validators", len(def.Validators))
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                title,
+		Subtitle:             subtitle,
+		XAxisTitle:           "",
+		YAxisTitle:           strings.Title(strings.ReplaceAll(def.Metric, "_", " ")),
+		StackingMode:         "false",
+		Type:                 "line",
+		TooltipShared:        true,
+		TooltipFollowPointer: true,
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: fmt.Sprintf("%v (%v)", def.Metric, def.Aggregation),
+				Data: customChartSeries(points),
+			},
+		},
+	}
+
+	data.Meta.Title = fmt.Sprintf("%v - %v Chart - beaconcha.in - %v", title, utils.Config.Frontend.SiteName, time.Now().Year())
+	data.Meta.Path = "/charts/custom/" + id
+	data.Data = themedChartData(chartData, data.Theme)
+
+	genericChartTemplate = template.Must(template.New("chart").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/genericchart.html"))
+	err = genericChartTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
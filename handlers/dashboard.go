@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/price"
@@ -15,9 +17,14 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
 	"github.com/lib/pq"
 )
 
+// sharedDashboardIDLength is the length of the random ids of the links of shared dashboards
+const sharedDashboardIDLength = 32
+
 var dashboardTemplate = template.Must(template.New("dashboard").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/dashboard.html"))
 
 func parseValidatorsFromQueryString(str string, validatorLimit int) ([]uint64, error) {
@@ -52,12 +59,65 @@ func parseValidatorsFromQueryString(str string, validatorLimit int) ([]uint64, e
 	return validators, nil
 }
 
+// parseDashboardValidators returns the validators of the dashboard request, either the validators of the shared tag
+// given by the shared query parameter, of the tag of the logged in user given by the tag query parameter or the
+// validators of the validators query parameter
+func parseDashboardValidators(r *http.Request, validatorLimit int) ([]uint64, error) {
+	q := r.URL.Query()
+	if shareID := q.Get("shared"); shareID != "" {
+		_, validators, err := getSharedDashboardValidators(r.Context(), shareID)
+		return validators, err
+	}
+	tag := q.Get("tag")
+	if tag == "" {
+		return parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	}
+
+	user := getUser(r)
+	if !user.Authenticated {
+		return []uint64{}, fmt.Errorf("tag filter requires a logged in user")
+	}
+	name, err := parseUserTagName(tag)
+	if err != nil {
+		return []uint64{}, err
+	}
+	validators, err := db.GetUserTagValidatorIndices(r.Context(), user.UserID, utils.GetNetwork(), name)
+	if err != nil {
+		return []uint64{}, err
+	}
+	if len(validators) > validatorLimit {
+		return []uint64{}, fmt.Errorf("Too much validators")
+	}
+	return validators, nil
+}
+
+// getSharedDashboardValidators returns the name and the validators of the shared tag with the share id. The validators
+// are limited by the plan of the owner of the tag, not by the plan of the viewer.
+func getSharedDashboardValidators(ctx context.Context, shareID string) (string, []uint64, error) {
+	if len(shareID) != sharedDashboardIDLength {
+		return "", nil, db.ErrUserTagNotFound
+	}
+	ownerID, name, validators, err := db.GetSharedUserTagValidatorIndices(ctx, shareID, utils.GetNetwork())
+	if err != nil {
+		return "", nil, err
+	}
+	pkg, err := db.GetUserPremiumPackage(ctx, ownerID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", nil, fmt.Errorf("error retrieving premium package of the owner of the shared dashboard: %w", err)
+	}
+	if len(validators) > GetUserPremiumByPackage(pkg.Package).MaxValidators {
+		return "", nil, fmt.Errorf("Too much validators")
+	}
+	return name, validators, nil
+}
+
 func Dashboard(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 	validatorLimit := getUserPremium(r).MaxValidators
 
 	dashboardData := types.DashboardData{}
 	dashboardData.ValidatorLimit = validatorLimit
+	dashboardData.Csrf = csrf.Token(r)
 
 	data := InitPageData(w, r, "dashboard", "/dashboard", "Dashboard")
 	data.HeaderAd = true
@@ -65,7 +125,60 @@ func Dashboard(w http.ResponseWriter, r *http.Request) {
 
 	err := dashboardTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error executing template")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error executing template")
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// DashboardShared renders the read-only dashboard of a public user tag
+func DashboardShared(w http.ResponseWriter, r *http.Request) {
+	dashboardShared(w, r, false)
+}
+
+// DashboardSharedEmbed renders the read-only dashboard of a public user tag without the navigation so it can be
+// embedded in an iframe
+func DashboardSharedEmbed(w http.ResponseWriter, r *http.Request) {
+	dashboardShared(w, r, true)
+}
+
+func dashboardShared(w http.ResponseWriter, r *http.Request, embed bool) {
+	w.Header().Set("Content-Type", "text/html")
+
+	shareID := mux.Vars(r)["shareID"]
+	name, validators, err := getSharedDashboardValidators(r.Context(), shareID)
+	if err == db.ErrUserTagNotFound {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error retrieving shared dashboard")
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	// the validators of a tag are limited by the plan of its owner, the viewer must be able to load all of them
+	dashboardData := types.DashboardData{
+		ValidatorLimit:   len(validators),
+		Shared:           true,
+		SharedID:         shareID,
+		SharedName:       name,
+		SharedValidators: validators,
+	}
+
+	data := InitPageData(w, r, "dashboard", "/dashboard/shared/"+shareID, "Dashboard "+name)
+	// the shared dashboard is rendered like for a visitor, the settings and notifications of the viewer are not shown
+	data.User = &types.User{}
+	data.Embed = embed
+	if embed {
+		data.UpcomingFork = nil
+		data.NoAds = true
+	}
+	data.Data = dashboardData
+
+	err = dashboardTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error executing template")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -77,11 +190,10 @@ func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	queryValidators, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	queryValidators, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error parsing validators from query string")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error parsing validators from query string")
 		http.Error(w, "Invalid query", 400)
 		return
 	}
@@ -99,16 +211,16 @@ func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 	latestEpoch := services.LatestEpoch()
 
 	var incomeHistory []*types.ValidatorIncomeHistory
-	err = db.DB.Select(&incomeHistory, "SELECT day, COALESCE(SUM(start_balance),0) AS start_balance, COALESCE(SUM(end_balance),0) AS end_balance, COALESCE(SUM(deposits_amount), 0) AS deposits_amount FROM validator_stats WHERE validatorindex = ANY($1) GROUP BY day ORDER BY day;", queryValidatorsArr)
+	err = db.DB.SelectContext(r.Context(), &incomeHistory, "SELECT day, COALESCE(SUM(start_balance),0) AS start_balance, COALESCE(SUM(end_balance),0) AS end_balance, COALESCE(SUM(deposits_amount), 0) AS deposits_amount FROM validator_stats WHERE validatorindex = ANY($1) GROUP BY day ORDER BY day;", queryValidatorsArr)
 	if err != nil {
-		logger.Errorf("error retrieving validator balance history: %v", err)
+		requestLogger(r).Errorf("error retrieving validator balance history: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	var currentBalance uint64
-	err = db.DB.Get(&currentBalance, "SELECT SUM(balance) as balance FROM validators WHERE validatorindex = ANY($1)", queryValidatorsArr)
+	err = db.DB.GetContext(r.Context(), &currentBalance, "SELECT SUM(balance) as balance FROM validators WHERE validatorindex = ANY($1)", queryValidatorsArr)
 	if err != nil {
-		logger.Errorf("error retrieving validator current balance: %v", err)
+		requestLogger(r).Errorf("error retrieving validator current balance: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -146,7 +258,7 @@ func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(incomeHistoryChartData)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -155,9 +267,8 @@ func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 func DashboardDataProposals(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	filterArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	filterArr, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
 		http.Error(w, "Invalid query", 400)
 		return
@@ -169,13 +280,13 @@ func DashboardDataProposals(w http.ResponseWriter, r *http.Request) {
 		Status uint64
 	}{}
 
-	err = db.DB.Select(&proposals, `
+	err = db.DB.SelectContext(r.Context(), &proposals, `
 		SELECT slot, status
 		FROM blocks
 		WHERE proposer = ANY($1)
 		ORDER BY slot`, filter)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error retrieving block-proposals")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error retrieving block-proposals")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -190,7 +301,7 @@ func DashboardDataProposals(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(proposalsResult)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -199,35 +310,19 @@ func DashboardDataProposals(w http.ResponseWriter, r *http.Request) {
 func DashboardDataMissedAttestations(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	filterArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	filterArr, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
 		http.Error(w, "Invalid query", 400)
 		return
 	}
-	filter := pq.Array(filterArr)
-
-	missedAttestations := []struct {
-		Epoch          uint64
-		Validatorindex uint64
-	}{}
 
 	maxEpoch := services.LatestEpoch() - 1
 	minEpoch := utils.TimeToEpoch(time.Now().Add(time.Hour * 24 * -7))
 
-	err = db.DB.Select(&missedAttestations, `
-		SELECT epoch, validatorindex
-		FROM attestation_assignments_p
-		WHERE 
-			validatorindex = ANY($1) 
-			AND epoch <= $2 
-			AND epoch >= $3 
-			AND week <= $2 / 1575
-			AND week >= $3 / 1575
-			AND status = 0`, filter, maxEpoch, minEpoch)
+	missedAttestations, err := db.Attestations.GetMissedAttestations(filterArr, uint64(minEpoch), maxEpoch)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error retrieving daily proposed blocks blocks count")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error retrieving missed attestations")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -245,7 +340,7 @@ func DashboardDataMissedAttestations(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(result)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -256,9 +351,8 @@ func DashboardDataValidators(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	filterArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	filterArr, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
 		http.Error(w, "Invalid query", 400)
 		return
@@ -266,7 +360,7 @@ func DashboardDataValidators(w http.ResponseWriter, r *http.Request) {
 	filter := pq.Array(filterArr)
 
 	var validators []*types.ValidatorsPageDataValidators
-	err = db.DB.Select(&validators, `
+	err = db.DB.SelectContext(r.Context(), &validators, `
 		WITH
 			proposals AS (
 				SELECT validatorindex, pa.status, count(*)
@@ -300,7 +394,7 @@ func DashboardDataValidators(w http.ResponseWriter, r *http.Request) {
 		LIMIT $2`, filter, validatorLimit)
 
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator data")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator data")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -378,7 +472,7 @@ func DashboardDataValidators(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Errorf("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -387,17 +481,16 @@ func DashboardDataValidators(w http.ResponseWriter, r *http.Request) {
 func DashboardDataEarnings(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	queryValidators, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	queryValidators, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
 		http.Error(w, "Invalid query", 400)
 		return
 	}
 
-	earnings, err := GetValidatorEarnings(queryValidators, GetCurrency(r))
+	earnings, err := GetValidatorEarnings(r.Context(), queryValidators, GetCurrency(r))
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator earnings")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator earnings")
 		http.Error(w, "Internal server error", 503)
 	}
 
@@ -407,7 +500,7 @@ func DashboardDataEarnings(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(earnings)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Errorf("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -416,27 +509,26 @@ func DashboardDataEarnings(w http.ResponseWriter, r *http.Request) {
 func DashboardDataEffectiveness(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	filterArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	filterArr, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
-		logger.Errorf("error retrieving active validators %v", err)
+		requestLogger(r).Errorf("error retrieving active validators %v", err)
 		http.Error(w, "Invalid query", 400)
 		return
 	}
 	filter := pq.Array(filterArr)
 
 	var activeValidators pq.Int64Array
-	err = db.DB.Select(&activeValidators, `
+	err = db.DB.SelectContext(r.Context(), &activeValidators, `
 		SELECT validatorindex FROM validators where validatorindex = ANY($1) and activationepoch < $2 AND exitepoch > $2
 	`, filter, services.LatestEpoch())
 	if err != nil {
-		logger.Errorf("error retrieving active validators")
+		requestLogger(r).Errorf("error retrieving active validators")
 	}
 
 	var avgIncDistance []float64
 
-	err = db.DB.Select(&avgIncDistance, `
+	err = db.DB.SelectContext(r.Context(), &avgIncDistance, `
 	SELECT
 		(SELECT COALESCE(
 			AVG(1 + inclusionslot - COALESCE((
@@ -452,14 +544,14 @@ func DashboardDataEffectiveness(w http.ResponseWriter, r *http.Request) {
 	FROM unnest($2::int[]) AS index;
 	`, int64(services.LatestEpoch())-100, activeValidators)
 	if err != nil {
-		logger.Errorf("error retrieving AverageAttestationInclusionDistance: %v", err)
+		requestLogger(r).Errorf("error retrieving AverageAttestationInclusionDistance: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	err = json.NewEncoder(w).Encode(avgIncDistance)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -468,9 +560,8 @@ func DashboardDataEffectiveness(w http.ResponseWriter, r *http.Request) {
 func DashboardDataProposalsHistory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
 	validatorLimit := getUserPremium(r).MaxValidators
-	filterArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
+	filterArr, err := parseDashboardValidators(r, validatorLimit)
 	if err != nil {
 		http.Error(w, "Invalid query", 400)
 		return
@@ -485,13 +576,13 @@ func DashboardDataProposalsHistory(w http.ResponseWriter, r *http.Request) {
 		Orphaned       *uint64 `db:"orphaned_blocks"`
 	}{}
 
-	err = db.DB.Select(&proposals, `
+	err = db.DB.SelectContext(r.Context(), &proposals, `
 		SELECT validatorindex, day, proposed_blocks, missed_blocks, orphaned_blocks
 		FROM validator_stats
 		WHERE validatorindex = ANY($1) AND (proposed_blocks IS NOT NULL OR missed_blocks IS NOT NULL OR orphaned_blocks IS NOT NULL)
 		ORDER BY day DESC`, filter)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error retrieving validator_stats")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error retrieving validator_stats")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -519,7 +610,7 @@ func DashboardDataProposalsHistory(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(proposalsHistResult)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
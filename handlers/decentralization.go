@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"html/template"
+	"net/http"
+)
+
+var decentralizationTemplate = template.Must(template.New("decentralization").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/decentralization.html"))
+
+const decentralizationClustersLimit = 100
+
+// decentralizationDimension returns the dimension of the request, it defaults to the combined dimension
+func decentralizationDimension(r *http.Request) (string, bool) {
+	dimension := r.URL.Query().Get("dimension")
+	if dimension == "" {
+		return db.ClusterDimensionCombined, true
+	}
+	for _, d := range db.ClusterDimensions {
+		if d == dimension {
+			return dimension, true
+		}
+	}
+	return "", false
+}
+
+// Decentralization will return the decentralization dashboard using a go template
+func Decentralization(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	dimension, ok := decentralizationDimension(r)
+	if !ok {
+		http.Error(w, "Invalid dimension", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := db.GetDecentralizationStats(r.Context(), dimension)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving decentralization stats for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+	clusters, err := db.GetLatestValidatorClusters(r.Context(), dimension, decentralizationClustersLimit)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator clusters for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	pageData := &types.DecentralizationPageData{
+		Dimension:  dimension,
+		Dimensions: db.ClusterDimensions,
+		Clusters:   clusters,
+	}
+	if len(stats) > 0 {
+		pageData.Latest = stats[len(stats)-1]
+	}
+
+	data := InitPageData(w, r, "stats", "/network/decentralization", "Validator Set Decentralization")
+	data.Data = pageData
+
+	err = decentralizationTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiDecentralization godoc
+// @Summary Get the daily concentration of the stake of the validators clustered by deposit address, withdrawal credentials, fee recipient, graffiti or all of them combined. The nakamoto coefficient is the minimal number of clusters controlling more than a third of the stake. Clusters are estimated from on-chain data only.
+// @Tags Network
+// @Produce  json
+// @Param  dimension query string false "Dimension the validators are clustered by: combined (default), deposit_address, withdrawal_credentials, fee_recipient or graffiti"
+// @Success 200 {object} types.ApiResponse{data=[]types.DecentralizationStats}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/decentralization [get]
+func ApiDecentralization(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	dimension, ok := decentralizationDimension(r)
+	if !ok {
+		sendErrorResponse(j, r.URL.String(), "invalid dimension")
+		return
+	}
+
+	stats, err := db.GetDecentralizationStats(r.Context(), dimension)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving decentralization stats for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiDecentralizationClusters godoc
+// @Summary Get the largest validator clusters of the last computed day
+// @Tags Network
+// @Produce  json
+// @Param  dimension query string false "Dimension the validators are clustered by: combined (default), deposit_address, withdrawal_credentials, fee_recipient or graffiti"
+// @Success 200 {object} types.ApiResponse{data=[]types.ValidatorCluster}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/decentralization/clusters [get]
+func ApiDecentralizationClusters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	dimension, ok := decentralizationDimension(r)
+	if !ok {
+		sendErrorResponse(j, r.URL.String(), "invalid dimension")
+		return
+	}
+
+	clusters, err := db.GetLatestValidatorClusters(r.Context(), dimension, decentralizationClustersLimit)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator clusters for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(clusters))
+	for i, c := range clusters {
+		data[i] = c
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var depositLeaderboardTemplate = template.Must(template.New("depositleaderboard").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/depositLeaderboard.html"))
+
+// DepositLeaderboard returns the leaderboard of eth1-addresses ordered by their deposits using a go template
+func DepositLeaderboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "eth1Deposits", "/depositleaderboard", "Deposit Leaderboard")
+	data.HeaderAd = true
+
+	err := depositLeaderboardTemplate.ExecuteTemplate(w, "layout", data)
+
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// DepositLeaderboardData returns the leaderboard of eth1-addresses ordered by their deposits in json
+func DepositLeaderboardData(w http.ResponseWriter, r *http.Request) {
+	currency := GetCurrency(r)
+	w.Header().Set("Content-Type", "application/json")
+	q := r.URL.Query()
+
+	search := strings.Replace(q.Get("search[value]"), "0x", "", -1)
+	if len(search) > 128 {
+		search = search[:128]
+	}
+
+	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
+	if err != nil {
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
+	if err != nil {
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
+	if err != nil {
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+	if length > 100 {
+		length = 100
+	}
+
+	orderColumn := q.Get("order[0][column]")
+	orderByMap := map[string]string{
+		"0": "from_address",
+		"1": "deposit_count",
+		"2": "amount",
+		"3": "first_deposit_ts",
+		"4": "last_deposit_ts",
+	}
+	orderBy, exists := orderByMap[orderColumn]
+	if !exists {
+		orderBy = "amount"
+	}
+
+	orderDir := q.Get("order[0][dir]")
+
+	depositors, totalCount, err := db.GetEth1Depositors(r.Context(), search, length, start, orderBy, orderDir)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving eth1-depositor leaderboard data: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	tableData := make([][]interface{}, len(depositors))
+	for i, d := range depositors {
+		tableData[i] = []interface{}{
+			utils.FormatEth1AddressWithName(d.FromAddress, d.Name),
+			d.DepositCount,
+			utils.FormatBalance(d.Amount, currency),
+			utils.FormatTimestamp(d.FirstDepositTs.Unix()),
+			utils.FormatTimestamp(d.LastDepositTs.Unix()),
+		}
+	}
+
+	data := &types.DataTableResponse{
+		Draw:            draw,
+		RecordsTotal:    totalCount,
+		RecordsFiltered: totalCount,
+		Data:            tableData,
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiDepositLeaderboard godoc
+// @Summary Get the eth1-addresses with the largest deposits into the deposit contract
+// @Tags Eth1
+// @Produce  json
+// @Param  limit query int false "Number of addresses to return (max 100)"
+// @Param  cursor query string false "Cursor of the next page as returned in next_cursor"
+// @Param  offset query int false "Offset of the first address, deprecated in favor of cursor"
+// @Param  q query string false "Filter by address prefix or name"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/depositleaderboard [get]
+func ApiDepositLeaderboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	page, err := parseApiPage(r, 2)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	search := strings.Replace(q.Get("q"), "0x", "", -1)
+	if len(search) > 128 {
+		search = search[:128]
+	}
+
+	var depositors []*types.Eth1Depositor
+	if q.Get("offset") != "" {
+		offset, err := strconv.ParseUint(q.Get("offset"), 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid offset provided")
+			return
+		}
+		depositors, _, err = db.GetEth1Depositors(r.Context(), search, page.Limit, offset, "amount", "desc")
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving eth1-depositor leaderboard data: %v", err)
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		sendOKResponse(j, r.URL.String(), depositorsToApiData(depositors))
+		return
+	}
+
+	afterAmount := uint64(0)
+	var afterAddress []byte
+	if page.Cursor != nil {
+		afterAmount, err = strconv.ParseUint(page.Cursor[0], 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid cursor provided")
+			return
+		}
+		afterAddress, err = hex.DecodeString(strings.TrimPrefix(page.Cursor[1], "0x"))
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid cursor provided")
+			return
+		}
+	}
+
+	depositors, err = db.GetEth1DepositorsAfter(r.Context(), search, page.Limit+1, afterAmount, afterAddress)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving eth1-depositor leaderboard data: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	hasMore := uint64(len(depositors)) > page.Limit
+	nextCursor := ""
+	if hasMore {
+		depositors = depositors[:page.Limit]
+		last := depositors[len(depositors)-1]
+		nextCursor = encodeApiCursor(last.Amount, fmt.Sprintf("0x%x", last.FromAddress))
+	}
+
+	sendOKPageResponse(j, r.URL.String(), depositorsToApiData(depositors), nextCursor, hasMore)
+}
+
+func depositorsToApiData(depositors []*types.Eth1Depositor) []interface{} {
+	data := make([]interface{}, len(depositors))
+	for i, d := range depositors {
+		data[i] = map[string]interface{}{
+			"from_address":     fmt.Sprintf("0x%x", d.FromAddress),
+			"name":             d.Name,
+			"category":         d.Category,
+			"deposit_count":    d.DepositCount,
+			"amount":           d.Amount,
+			"first_deposit_ts": d.FirstDepositTs.Unix(),
+			"last_deposit_ts":  d.LastDepositTs.Unix(),
+		}
+	}
+	return data
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethpb "github.com/prysmaticlabs/prysm/proto/prysm/v1alpha1"
+)
+
+var depositToolTemplate = template.Must(template.New("depositTool").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/depositTool.html"))
+
+const (
+	depositToolFlash          = "deposit_tool_flash"
+	depositToolMaxSubmissions = 100
+	// depositToolTrackRateLimit is the number of deposits a single ip-address can track per depositToolTrackRateLimitWindow
+	depositToolTrackRateLimit       = 100
+	depositToolTrackRateLimitWindow = time.Hour
+)
+
+var depositToolTrackRequests = newRateLimiter(depositToolTrackRateLimit, depositToolTrackRateLimitWindow)
+
+const depositContractABI = `[{"inputs":[{"name":"pubkey","type":"bytes"},{"name":"withdrawal_credentials","type":"bytes"},{"name":"signature","type":"bytes"},{"name":"deposit_data_root","type":"bytes32"}],"name":"deposit","outputs":[],"stateMutability":"payable","type":"function"}]`
+
+var depositContract = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(depositContractABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// DepositTool shows the guided deposit page and the progress of the deposit transactions that were tracked through it,
+// the tracked transactions are selected with the tx query parameter
+func DepositTool(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	pageData := &types.DepositToolPageData{}
+	pageData.FlashMessage, err = utils.GetFlash(w, r, depositToolFlash)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving flashes for deposit tool %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	pageData.TxFilter = r.URL.Query().Get("tx")
+	if pageData.TxFilter != "" {
+		txHashes, err := parseDepositTxHashes(pageData.TxFilter)
+		if err != nil {
+			http.Error(w, "Invalid query", 400)
+			return
+		}
+		pageData.Submissions, err = db.GetDepositSubmissions(r.Context(), txHashes)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving deposit submissions: %v", err)
+			http.Error(w, "Internal server error", 503)
+			return
+		}
+	}
+
+	renderDepositTool(w, r, pageData)
+}
+
+// DepositToolPost verifies the uploaded deposit_data.json file and assembles the deposit contract transaction of every
+// deposit that can be sent. The transactions are signed and sent by the wallet of the user, the explorer never sees keys.
+func DepositToolPost(w http.ResponseWriter, r *http.Request) {
+	pageData := &types.DepositToolPageData{}
+
+	r.Body = http.MaxBytesReader(w, r.Body, depositVerifierMaxFileSize+1<<10)
+	file, header, err := r.FormFile("deposit_data")
+	if err != nil {
+		pageData.FlashMessage = "Error: please select a deposit_data.json file of at most 2 MB"
+		renderDepositTool(w, r, pageData)
+		return
+	}
+	defer file.Close()
+	pageData.FileName = header.Filename
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		pageData.FlashMessage = "Error: the file could not be read"
+		renderDepositTool(w, r, pageData)
+		return
+	}
+
+	pageData.Transactions, err = buildDepositTransactions(r.Context(), content)
+	if err != nil {
+		pageData.FlashMessage = fmt.Sprintf("Error: %v", err)
+	}
+	renderDepositTool(w, r, pageData)
+}
+
+// DepositToolTrack records the transaction a deposit of the guided deposit page was sent with so the page can follow the
+// deposit until the validator is active
+func DepositToolTrack(w http.ResponseWriter, r *http.Request) {
+	if limited, retryAfter := depositToolTrackRequests.limited(clientIP(r)); limited {
+		utils.SetFlash(w, r, depositToolFlash, fmt.Sprintf("Error: too many tracked deposits, try again in %.0f minutes", retryAfter.Minutes()+1))
+		http.Redirect(w, r, "/tools/deposit", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		requestLogger(r).Errorf("error parsing form: %v", err)
+		utils.SetFlash(w, r, depositToolFlash, "Error: invalid form submitted")
+		http.Redirect(w, r, "/tools/deposit", http.StatusSeeOther)
+		return
+	}
+
+	pubkey, err := decodeHexOfLength(r.FormValue("pubkey"), 48)
+	if err != nil {
+		utils.SetFlash(w, r, depositToolFlash, "Error: invalid pubkey")
+		http.Redirect(w, r, "/tools/deposit", http.StatusSeeOther)
+		return
+	}
+	txHash, err := decodeHexOfLength(strings.TrimSpace(r.FormValue("tx_hash")), 32)
+	if err != nil {
+		utils.SetFlash(w, r, depositToolFlash, "Error: invalid transaction hash")
+		http.Redirect(w, r, "/tools/deposit", http.StatusSeeOther)
+		return
+	}
+
+	err = db.SaveDepositSubmission(r.Context(), txHash, pubkey)
+	if err != nil {
+		requestLogger(r).Errorf("error saving deposit submission %x: %v", txHash, err)
+		utils.SetFlash(w, r, depositToolFlash, "Error: the deposit could not be tracked")
+		http.Redirect(w, r, "/tools/deposit", http.StatusSeeOther)
+		return
+	}
+
+	utils.SetFlash(w, r, depositToolFlash, "The deposit is tracked, it will be processed by the beacon chain once the transaction is included.")
+	http.Redirect(w, r, "/tools/deposit?tx="+url.QueryEscape(fmt.Sprintf("0x%x", txHash)), http.StatusSeeOther)
+}
+
+func renderDepositTool(w http.ResponseWriter, r *http.Request, pageData *types.DepositToolPageData) {
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "more", "/tools/deposit", "Deposit")
+	pageData.Network = utils.Config.Chain.Network
+	pageData.DepositContract = utils.Config.Indexer.Eth1DepositContractAddress
+	pageData.ChainID = utils.Config.Chain.Phase0.DepositChainID
+	data.Data = pageData
+
+	err := depositToolTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiDepositTransactions godoc
+// @Summary Build the deposit contract transactions of the deposits of a deposit_data.json file of the staking-deposit-cli. Every deposit is verified like by /api/v1/deposits/verify, deposits that are invalid or wasted get no transaction. A transaction contains the calldata and value in wei, an EIP-681 transaction request that can be shown as QR code, the params of a WalletConnect eth_sendTransaction request (the wallet sets the sender) and deep links to wallets.
+// @Tags Deposits
+// @Accept  json
+// @Produce  json
+// @Param  depositData body []object true "Content of the deposit_data.json file"
+// @Success 200 {object} types.ApiResponse{data=[]types.DepositTransaction}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/deposits/transactions [post]
+func ApiDepositTransactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	content, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, depositVerifierMaxFileSize))
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not read body")
+		return
+	}
+
+	transactions, err := buildDepositTransactions(r.Context(), content)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	data := make([]interface{}, len(transactions))
+	for i, tx := range transactions {
+		data[i] = tx
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// buildDepositTransactions verifies the deposits of a deposit_data.json file and assembles the transaction of every valid
+// deposit or deposit with warnings
+func buildDepositTransactions(ctx context.Context, content []byte) ([]*types.DepositTransaction, error) {
+	if utils.Config.Indexer.Eth1DepositContractAddress == "" {
+		return nil, fmt.Errorf("the deposit contract of this network is not configured")
+	}
+
+	results, err := verifyDepositData(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	entries := []*depositDataEntry{}
+	err = json.Unmarshal(content, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("the file is not a valid deposit_data.json file")
+	}
+
+	transactions := make([]*types.DepositTransaction, len(results))
+	for i, res := range results {
+		transactions[i] = &types.DepositTransaction{Verification: res}
+		if res.Status != "valid" && res.Status != "warning" {
+			continue
+		}
+		err = assembleDepositTransaction(entries[i], transactions[i])
+		if err != nil {
+			return nil, fmt.Errorf("deposit %v: %v", i+1, err)
+		}
+	}
+	return transactions, nil
+}
+
+// assembleDepositTransaction fills in the call of the deposit function of the deposit contract for a verified deposit,
+// deposits with withdrawal credentials of an unknown prefix are refused as their funds could never be withdrawn
+func assembleDepositTransaction(entry *depositDataEntry, tx *types.DepositTransaction) error {
+	data := &ethpb.Deposit_Data{
+		PublicKey:             utils.MustParseHex(entry.Pubkey),
+		WithdrawalCredentials: utils.MustParseHex(entry.WithdrawalCredentials),
+		Amount:                entry.Amount,
+		Signature:             utils.MustParseHex(entry.Signature),
+	}
+	err := checkDepositWithdrawalCredentials(data.WithdrawalCredentials)
+	if err != nil {
+		return err
+	}
+	root, err := data.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("the deposit data root can not be computed")
+	}
+	calldata, err := depositContract.Pack("deposit", data.PublicKey, data.WithdrawalCredentials, data.Signature, root)
+	if err != nil {
+		return fmt.Errorf("the deposit call can not be encoded: %v", err)
+	}
+
+	to := common.HexToAddress(utils.Config.Indexer.Eth1DepositContractAddress).Hex()
+	value := new(big.Int).Mul(new(big.Int).SetUint64(entry.Amount), big.NewInt(1e9))
+	chainID := utils.Config.Chain.Phase0.DepositChainID
+
+	tx.To = to
+	tx.Value = value.String()
+	tx.Data = hexutil.Encode(calldata)
+	tx.ChainID = chainID
+	tx.PaymentURI = fmt.Sprintf("ethereum:%s@%d/deposit?bytes=0x%x&bytes=0x%x&bytes=0x%x&bytes32=0x%x&value=%s", to, chainID, data.PublicKey, data.WithdrawalCredentials, data.Signature, root, tx.Value)
+	tx.WalletConnectRequest = &types.WalletConnectRequest{
+		ChainID: fmt.Sprintf("eip155:%d", chainID),
+		Method:  "eth_sendTransaction",
+		Params: []map[string]string{{
+			"to":    to,
+			"value": hexutil.EncodeBig(value),
+			"data":  tx.Data,
+		}},
+	}
+	tx.DeepLinks = map[string]string{
+		"metamask": "https://metamask.app.link/send/" + strings.TrimPrefix(tx.PaymentURI, "ethereum:"),
+	}
+	return nil
+}
+
+// checkDepositWithdrawalCredentials returns an error if the credentials are not bls (0x00), execution (0x01) or
+// compounding (0x02) credentials, the address of execution and compounding credentials has to be zero padded
+func checkDepositWithdrawalCredentials(credentials []byte) error {
+	if len(credentials) != 32 {
+		return fmt.Errorf("the withdrawal credentials must be 32 bytes long")
+	}
+	switch credentials[0] {
+	case utils.BLSWithdrawalPrefix:
+		return nil
+	case utils.Eth1AddressWithdrawalPrefix, utils.CompoundingWithdrawalPrefix:
+		for _, b := range credentials[1:12] {
+			if b != 0 {
+				return fmt.Errorf("the withdrawal credentials 0x%x are not zero padded", credentials)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("the withdrawal credentials have the unknown prefix 0x%02x, the deposited funds could not be withdrawn", credentials[0])
+	}
+}
+
+// parseDepositTxHashes parses a comma separated list of transaction hashes
+func parseDepositTxHashes(input string) ([][]byte, error) {
+	parts := strings.Split(input, ",")
+	if len(parts) > depositToolMaxSubmissions {
+		return nil, fmt.Errorf("only %v transactions can be tracked at once", depositToolMaxSubmissions)
+	}
+	txHashes := make([][]byte, 0, len(parts))
+	for _, p := range parts {
+		txHash, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(p), "0x"))
+		if err != nil || len(txHash) != 32 {
+			return nil, fmt.Errorf("invalid transaction hash %v", p)
+		}
+		txHashes = append(txHashes, txHash)
+	}
+	return txHashes, nil
+}
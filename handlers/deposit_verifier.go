@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	ethpb "github.com/prysmaticlabs/prysm/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+)
+
+var depositVerifierTemplate = template.Must(template.New("depositVerifier").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/depositVerifier.html"))
+
+const (
+	depositVerifierMaxFileSize = 2 << 20
+	depositVerifierMaxDeposits = 1000
+)
+
+// depositDataEntry is a single deposit of a deposit_data.json file as produced by the staking-deposit-cli
+type depositDataEntry struct {
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	DepositMessageRoot    string `json:"deposit_message_root"`
+	DepositDataRoot       string `json:"deposit_data_root"`
+	ForkVersion           string `json:"fork_version"`
+	NetworkName           string `json:"network_name"`
+	DepositCliVersion     string `json:"deposit_cli_version"`
+}
+
+// DepositVerifier shows the form to upload a deposit_data.json file
+func DepositVerifier(w http.ResponseWriter, r *http.Request) {
+	pageData := &types.DepositVerifierPageData{}
+	renderDepositVerifier(w, r, pageData)
+}
+
+// DepositVerifierPost verifies the uploaded deposit_data.json file and renders the result of every deposit
+func DepositVerifierPost(w http.ResponseWriter, r *http.Request) {
+	pageData := &types.DepositVerifierPageData{}
+
+	r.Body = http.MaxBytesReader(w, r.Body, depositVerifierMaxFileSize+1<<10)
+	file, header, err := r.FormFile("deposit_data")
+	if err != nil {
+		pageData.FlashMessage = "Error: please select a deposit_data.json file of at most 2 MB"
+		renderDepositVerifier(w, r, pageData)
+		return
+	}
+	defer file.Close()
+	pageData.FileName = header.Filename
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		pageData.FlashMessage = "Error: the file could not be read"
+		renderDepositVerifier(w, r, pageData)
+		return
+	}
+
+	pageData.Results, err = verifyDepositData(r.Context(), content)
+	if err != nil {
+		pageData.FlashMessage = fmt.Sprintf("Error: %v", err)
+		renderDepositVerifier(w, r, pageData)
+		return
+	}
+	for _, res := range pageData.Results {
+		switch res.Status {
+		case "valid":
+			pageData.ValidCount++
+		case "warning":
+			pageData.WarningCount++
+		case "invalid":
+			pageData.InvalidCount++
+		case "wasted":
+			pageData.WastedCount++
+		}
+	}
+	renderDepositVerifier(w, r, pageData)
+}
+
+func renderDepositVerifier(w http.ResponseWriter, r *http.Request, pageData *types.DepositVerifierPageData) {
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "more", "/tools/depositVerifier", "Deposit Data Verifier")
+	pageData.Network = utils.Config.Chain.Network
+	pageData.ForkVersion = fmt.Sprintf("%x", utils.DepositForkVersion())
+	data.Data = pageData
+
+	err := depositVerifierTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiDepositVerifier godoc
+// @Summary Verify the deposits of a deposit_data.json file of the staking-deposit-cli against the chain of the explorer. The status of every deposit is valid, warning, invalid (the deposit contract rejects it) or wasted (the deposit is accepted but the ether lost).
+// @Tags Deposits
+// @Accept  json
+// @Produce  json
+// @Param  depositData body []object true "Content of the deposit_data.json file"
+// @Success 200 {object} types.ApiResponse{data=[]types.DepositDataVerification}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/deposits/verify [post]
+func ApiDepositVerifier(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	content, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, depositVerifierMaxFileSize))
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), "could not read body")
+		return
+	}
+
+	results, err := verifyDepositData(r.Context(), content)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	data := make([]interface{}, len(results))
+	for i, res := range results {
+		data[i] = res
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// verifyDepositData parses the content of a deposit_data.json file and verifies the format, roots and signature of every
+// deposit as well as the state of already deposited keys
+func verifyDepositData(ctx context.Context, content []byte) ([]*types.DepositDataVerification, error) {
+	entries := []*depositDataEntry{}
+	err := json.Unmarshal(content, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("the file is not a valid deposit_data.json file")
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("the file does not contain any deposits")
+	}
+	if len(entries) > depositVerifierMaxDeposits {
+		return nil, fmt.Errorf("only %v deposits can be verified at once", depositVerifierMaxDeposits)
+	}
+
+	domain, err := utils.DepositDomain()
+	if err != nil {
+		logger.Errorf("error computing deposit domain: %v", err)
+		return nil, fmt.Errorf("the deposits can not be verified at the moment")
+	}
+	forkVersion := utils.DepositForkVersion()
+
+	results := make([]*types.DepositDataVerification, len(entries))
+	pubkeys := make([][]byte, 0, len(entries))
+	for i, entry := range entries {
+		res := &types.DepositDataVerification{
+			Pubkey:                entry.Pubkey,
+			WithdrawalCredentials: entry.WithdrawalCredentials,
+			Amount:                entry.Amount,
+			Errors:                []string{},
+			Warnings:              []string{},
+		}
+		results[i] = res
+
+		data, err := verifyDepositDataEntry(entry, res, domain, forkVersion)
+		if err != nil {
+			res.Errors = append(res.Errors, err.Error())
+			continue
+		}
+		pubkeys = append(pubkeys, data.PublicKey)
+	}
+
+	depositedKeys, err := db.GetDepositedKeys(ctx, pubkeys)
+	if err != nil {
+		logger.Errorf("error retrieving deposited keys: %v", err)
+		return nil, fmt.Errorf("could not retrieve db results")
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		res := results[i]
+		if len(res.Errors) > 0 {
+			res.Status = "invalid"
+			continue
+		}
+		pubkey := strings.TrimPrefix(strings.ToLower(entry.Pubkey), "0x")
+		credentials := utils.MustParseHex(entry.WithdrawalCredentials)
+		validSignature := true
+		for _, warning := range res.Warnings {
+			if warning == depositInvalidSignatureMsg {
+				validSignature = false
+			}
+		}
+
+		existing := depositedKeys[pubkey]
+		switch {
+		case existing != nil:
+			res.Validatorindex = existing.Validatorindex
+			checkTopUp(entry, existing, res)
+		case seen[pubkey]:
+			res.Warnings = append(res.Warnings, "the file contains multiple deposits for this key, all but the first are top-ups")
+		case !validSignature:
+			res.Errors = append(res.Errors, "the signature of the first deposit of a key has to be valid, the ether of this deposit would be lost")
+		case entry.Amount < utils.MaxEffectiveBalanceOfCredentials(nil):
+			res.Warnings = append(res.Warnings, fmt.Sprintf("the validator is only activated once it received %v", formatGweiAsEth(utils.MaxEffectiveBalanceOfCredentials(nil))))
+		}
+		if existing == nil && !seen[pubkey] && validSignature {
+			if entry.Amount > utils.MaxEffectiveBalanceOfCredentials(credentials) {
+				res.Warnings = append(res.Warnings, "the amount exceeds the max effective balance of the withdrawal credentials, the excess will not be staked")
+			}
+			// only an accepted first deposit creates the validator, the deposits after a rejected one are first deposits again
+			seen[pubkey] = true
+		}
+
+		switch {
+		case len(res.Errors) > 0:
+			res.Status = "wasted"
+		case len(res.Warnings) > 0:
+			res.Status = "warning"
+		default:
+			res.Status = "valid"
+		}
+	}
+	return results, nil
+}
+
+const depositInvalidSignatureMsg = "the signature is invalid for this network"
+
+// verifyDepositDataEntry checks the format of the deposit and the roots the deposit contract verifies, errors are returned
+// for deposits the contract rejects and warnings are added to the result for deposits it accepts
+func verifyDepositDataEntry(entry *depositDataEntry, res *types.DepositDataVerification, domain, forkVersion []byte) (*ethpb.Deposit_Data, error) {
+	pubkey, err := decodeHexOfLength(entry.Pubkey, 48)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pubkey")
+	}
+	credentials, err := decodeHexOfLength(entry.WithdrawalCredentials, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid withdrawal_credentials")
+	}
+	signature, err := decodeHexOfLength(entry.Signature, 96)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if entry.Amount < utils.Config.Chain.MinDepositAmount || entry.Amount < 1e9 {
+		return nil, fmt.Errorf("the amount is below the minimum deposit amount of 1 ETH")
+	}
+
+	data := &ethpb.Deposit_Data{
+		PublicKey:             pubkey,
+		WithdrawalCredentials: credentials,
+		Amount:                entry.Amount,
+		Signature:             signature,
+	}
+	dataRoot, err := data.HashTreeRoot()
+	if err != nil {
+		return nil, fmt.Errorf("the deposit data root can not be computed")
+	}
+	if entry.DepositDataRoot != "" && !strings.EqualFold(strings.TrimPrefix(entry.DepositDataRoot, "0x"), hex.EncodeToString(dataRoot[:])) {
+		return nil, fmt.Errorf("the deposit_data_root does not match the deposit, the deposit contract would reject it")
+	}
+	messageRoot, err := (&ethpb.DepositMessage{PublicKey: pubkey, WithdrawalCredentials: credentials, Amount: entry.Amount}).HashTreeRoot()
+	if err != nil {
+		return nil, fmt.Errorf("the deposit message root can not be computed")
+	}
+	if entry.DepositMessageRoot != "" && !strings.EqualFold(strings.TrimPrefix(entry.DepositMessageRoot, "0x"), hex.EncodeToString(messageRoot[:])) {
+		return nil, fmt.Errorf("the deposit_message_root does not match the deposit")
+	}
+
+	if entry.ForkVersion != "" && !strings.EqualFold(strings.TrimPrefix(entry.ForkVersion, "0x"), hex.EncodeToString(forkVersion)) {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("the deposit was created for fork version %v but %v uses %x", entry.ForkVersion, utils.Config.Chain.Network, forkVersion))
+	}
+	if entry.NetworkName != "" && utils.Config.Chain.Network != "" && !strings.EqualFold(entry.NetworkName, utils.Config.Chain.Network) {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("the deposit was created for %v but this explorer shows %v", entry.NetworkName, utils.Config.Chain.Network))
+	}
+	if err := depositutil.VerifyDepositSignature(data, domain); err != nil {
+		res.Warnings = append(res.Warnings, depositInvalidSignatureMsg)
+	}
+
+	switch credentials[0] {
+	case utils.BLSWithdrawalPrefix:
+		res.Warnings = append(res.Warnings, "the withdrawal credentials are bls credentials, they have to be changed to an execution address before withdrawals are possible")
+	case utils.Eth1AddressWithdrawalPrefix, utils.CompoundingWithdrawalPrefix:
+		if !bytes.Equal(credentials[1:12], make([]byte, 11)) {
+			return nil, fmt.Errorf("the withdrawal credentials are malformed, bytes 1 to 11 have to be zero")
+		}
+	default:
+		res.Warnings = append(res.Warnings, fmt.Sprintf("unknown withdrawal credentials prefix 0x%02x, the ether can not be withdrawn", credentials[0]))
+	}
+	return data, nil
+}
+
+// checkTopUp adds the problems of a deposit to an already deposited key to the result
+func checkTopUp(entry *depositDataEntry, existing *db.DepositedKey, res *types.DepositDataVerification) {
+	res.Warnings = append(res.Warnings, fmt.Sprintf("the key already received %v, this deposit is a top-up", formatGweiAsEth(existing.DepositedAmount)))
+	if !bytes.Equal(existing.WithdrawalCredentials, utils.MustParseHex(entry.WithdrawalCredentials)) {
+		res.Warnings = append(res.Warnings, "the withdrawal credentials differ from the ones of the validator, they are ignored for top-ups")
+	}
+	switch {
+	case strings.HasPrefix(existing.Status, "exit") || existing.Status == "slashed" || existing.Status == "slashing_offline" || existing.Status == "slashing_online":
+		res.Errors = append(res.Errors, "the validator has exited, the deposit will not be staked")
+	case existing.Validatorindex != nil && existing.Balance >= utils.MaxEffectiveBalanceOfCredentials(existing.WithdrawalCredentials):
+		res.Errors = append(res.Errors, "the validator already has the max effective balance, the deposit will not increase its rewards")
+	}
+}
+
+func decodeHexOfLength(s string, length int) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != length {
+		return nil, fmt.Errorf("expected %v bytes, got %v", length, len(b))
+	}
+	return b, nil
+}
+
+func formatGweiAsEth(gwei uint64) string {
+	return fmt.Sprintf("%v ETH", float64(gwei)/1e9)
+}
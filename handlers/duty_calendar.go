@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DashboardDataDuties returns the known and estimated upcoming proposals and sync committee periods of the validators
+// of the dashboard
+func DashboardDataDuties(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	validators, err := parseDashboardValidators(r, getUserPremium(r).MaxValidators)
+	if err != nil {
+		http.Error(w, "Invalid query", 400)
+		return
+	}
+
+	calendar, err := db.GetDutyCalendar(r.Context(), validators, services.LatestEpoch())
+	if err != nil {
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error retrieving duty calendar")
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(calendar)
+	if err != nil {
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error enconding json response")
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// DashboardDutiesCalendar returns the duty calendar of the validators of the dashboard as an iCalendar feed that
+// calendars can subscribe to
+func DashboardDutiesCalendar(w http.ResponseWriter, r *http.Request) {
+	validators, err := parseDashboardValidators(r, getUserPremium(r).MaxValidators)
+	if err != nil {
+		http.Error(w, "Invalid query", 400)
+		return
+	}
+
+	calendar, err := db.GetDutyCalendar(r.Context(), validators, services.LatestEpoch())
+	if err != nil {
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error retrieving duty calendar")
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=duties.ics")
+	_, err = w.Write([]byte(dutyCalendarICS(calendar)))
+	if err != nil {
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error writing duty calendar")
+	}
+}
+
+// dutyCalendarICS renders the calendar as iCalendar (RFC 5545), the uids are stable so that subscribed calendars update
+// estimated events in place
+func dutyCalendarICS(calendar *types.DutyCalendar) string {
+	const icsTimeFormat = "20060102T150405Z"
+	network := utils.Config.Chain.Network
+	domain := utils.Config.Frontend.SiteDomain
+	if domain == "" {
+		domain = "beaconcha.in"
+	}
+	now := time.Now().UTC().Format(icsTimeFormat)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//" + domain + "//Validator Duties//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + icsEscape(fmt.Sprintf("Validator duties (%v)", network)),
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		"X-PUBLISHED-TTL:PT1H",
+	}
+	for _, e := range calendar.Events {
+		var uid, summary, description string
+		switch e.Type {
+		case types.DutyCalendarProposal:
+			uid = fmt.Sprintf("proposal-%v-%v", *e.Slot, e.Validators[0])
+			summary = fmt.Sprintf("Block proposal of validator %v", e.Validators[0])
+			description = fmt.Sprintf("Validator %v proposes the block of slot %v.", e.Validators[0], *e.Slot)
+		case types.DutyCalendarSyncCommittee:
+			uid = fmt.Sprintf("sync-committee-%v", *e.Period)
+			summary = fmt.Sprintf("Sync committee duty of %v validator(s)", len(e.Validators))
+			description = fmt.Sprintf("Validators %v are members of the sync committee of period %v.", joinUint64(e.Validators), *e.Period)
+		case types.DutyCalendarEstimatedProposal:
+			uid = "estimated-proposal"
+			summary = "Estimated next block proposal"
+			description = fmt.Sprintf("Expected time of the next proposal of the validators (%.2f proposals per day on average). The actual slot is only known one epoch in advance.", calendar.ExpectedProposalsPerDay)
+		case types.DutyCalendarEstimatedSyncCommittee:
+			uid = "estimated-sync-committee"
+			summary = "Estimated next sync committee duty"
+			description = fmt.Sprintf("Expected next sync committee period of the validators (%.2f%% chance per period). The members are only known one period in advance.", e.Probability*100)
+		default:
+			continue
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%v-%v@%v", network, uid, domain),
+			"DTSTAMP:"+now,
+			"DTSTART:"+e.Start.UTC().Format(icsTimeFormat),
+			"DTEND:"+e.End.UTC().Format(icsTimeFormat),
+			"SUMMARY:"+icsEscape(summary),
+			"DESCRIPTION:"+icsEscape(description),
+		)
+		if e.Type == types.DutyCalendarEstimatedProposal || e.Type == types.DutyCalendarEstimatedSyncCommittee {
+			lines = append(lines, "STATUS:TENTATIVE", "TRANSP:TRANSPARENT")
+		} else {
+			lines = append(lines, "STATUS:CONFIRMED")
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(icsFold(l))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// icsEscape escapes a text value of an iCalendar property
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold folds lines longer than 75 octets, continuation lines start with a space
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		// do not split multi-byte characters
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+func joinUint64(values []uint64) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprintf("%v", v)
+	}
+	return strings.Join(s, ", ")
+}
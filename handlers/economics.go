@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ApiEconomics godoc
+// @Summary Get the daily deposit contract balance, effective stake, total validator balance, circulating supply, staking ratio, issuance and burn of the network. All amounts are in gwei, the circulating supply and staking ratio are only known for the days exported while a supply endpoint was configured.
+// @Tags Network
+// @Produce  json
+// @Success 200 {object} types.ApiResponse{data=[]types.EconomicsStats}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/economics [get]
+func ApiEconomics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	stats, err := db.GetEconomicsStats(r.Context())
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving economics stats for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiEconomicsChart godoc
+// @Summary Get the series of an economics chart: deposit_contract (deposit contract balance and effective stake in ether), staking_ratio (in percent) or issuance (daily issuance, burn and net issuance in ether). The timestamps of the data points are the ends of the days in milliseconds.
+// @Tags Network
+// @Produce  json
+// @Param  chart path string true "Name of the chart: deposit_contract, staking_ratio or issuance"
+// @Success 200 {object} types.ApiResponse{data=types.GenericChartData}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/economics/chart/{chart} [get]
+func ApiEconomicsChart(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	chart := mux.Vars(r)["chart"]
+	known := false
+	for _, c := range services.EconomicsCharts {
+		if c == chart {
+			known = true
+			break
+		}
+	}
+	if !known {
+		sendErrorResponse(j, r.URL.String(), "invalid chart")
+		return
+	}
+
+	chartData, err := services.EconomicsChartData(chart)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving economics chart %v for %v route: %v", chart, r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if chartData == nil {
+		sendErrorResponse(j, r.URL.String(), "no data available for the requested chart")
+		return
+	}
+	sendOKResponse(j, r.URL.String(), []interface{}{chartData})
+}
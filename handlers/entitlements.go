@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+	"strings"
+)
+
+// RequireEntitlement only passes requests of users whose premium package includes the entitlement to the handler
+func RequireEntitlement(entitlement types.Entitlement, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !getUserPremium(r).Has(entitlement) {
+			ErrorOrJSONResponse(w, r, "feature only available for premium users", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// watchlistLimitExceeded returns whether adding the validators with the hex encoded pubkeys would exceed the watchlist
+// limit of the premium package of the user, validators that are already watched are not counted again
+func watchlistLimitExceeded(r *http.Request, userID uint64, pubkeys []string) (bool, error) {
+	watched, err := db.GetWatchlistPublickeys(r.Context(), userID, utils.GetNetwork())
+	if err != nil {
+		return false, err
+	}
+	watchedSet := make(map[string]bool, len(watched))
+	for _, pubkey := range watched {
+		watchedSet[hex.EncodeToString(pubkey)] = true
+	}
+	added := 0
+	for _, pubkey := range pubkeys {
+		pubkey = strings.ToLower(strings.TrimPrefix(pubkey, "0x"))
+		if !watchedSet[pubkey] {
+			watchedSet[pubkey] = true
+			added++
+		}
+	}
+	return added > 0 && len(watched)+added > getUserPremium(r).MaxWatchedValidators, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"eth2-exporter/db"
+	"eth2-exporter/services"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
@@ -33,11 +34,11 @@ func Epoch(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		data.Meta.Title = fmt.Sprintf("%v - Epoch %v - beaconcha.in - %v", utils.Config.Frontend.SiteName, epochString, time.Now().Year())
 		data.Meta.Path = "/epoch/" + epochString
-		logger.Errorf("error parsing epoch index %v: %v", epochString, err)
+		requestLogger(r).Errorf("error parsing epoch index %v: %v", epochString, err)
 		err = epochNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -46,10 +47,11 @@ func Epoch(w http.ResponseWriter, r *http.Request) {
 
 	data.Meta.Title = fmt.Sprintf("%v - Epoch %v - beaconcha.in - %v", utils.Config.Frontend.SiteName, epoch, time.Now().Year())
 	data.Meta.Path = fmt.Sprintf("/epoch/%v", epoch)
+	data.Meta.Image = previewImageURL(data.Meta.Path)
 
 	epochPageData := types.EpochPageData{}
 
-	err = db.DB.Get(&epochPageData, `
+	err = db.DB.GetContext(r.Context(), &epochPageData, `
 		SELECT 
 			epoch, 
 			blockscount, 
@@ -67,18 +69,18 @@ func Epoch(w http.ResponseWriter, r *http.Request) {
 		FROM epochs 
 		WHERE epoch = $1`, epoch)
 	if err != nil {
-		//logger.Errorf("error getting epoch data: %v", err)
+		//requestLogger(r).Errorf("error getting epoch data: %v", err)
 		err = epochNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 		return
 	}
 
-	err = db.DB.Select(&epochPageData.Blocks, `
+	err = db.DB.SelectContext(r.Context(), &epochPageData.Blocks, `
 		SELECT 
 			blocks.slot, 
 			blocks.proposer, 
@@ -95,20 +97,37 @@ func Epoch(w http.ResponseWriter, r *http.Request) {
 		WHERE epoch = $1
 		ORDER BY blocks.slot DESC`, epoch)
 	if err != nil {
-		logger.Errorf("error epoch blocks data: %v", err)
+		requestLogger(r).Errorf("error epoch blocks data: %v", err)
 		err = epochNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 		return
 	}
 
+	// the summary of the head epoch is only updated at the end of the epoch, its blocks are counted at request time
+	summary, err := db.GetEpochSummary(r.Context(), epochPageData.Epoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving summary of epoch %v: %v", epochPageData.Epoch, err)
+	}
+	useSummary := summary != nil && epochPageData.Epoch < services.LatestEpoch()
+	if useSummary {
+		epochPageData.ScheduledCount = summary.ScheduledBlocks
+		epochPageData.ProposedCount = summary.ProposedBlocks
+		epochPageData.MissedCount = summary.MissedBlocks
+		epochPageData.OrphanedCount = summary.OrphanedBlocks
+		epochPageData.SyncParticipationRate = summary.SyncParticipationRate
+	}
+
 	for _, block := range epochPageData.Blocks {
 		block.Ts = utils.SlotToTime(block.Slot)
 
+		if useSummary {
+			continue
+		}
 		switch block.Status {
 		case 0:
 			epochPageData.ScheduledCount += 1
@@ -121,24 +140,36 @@ func Epoch(w http.ResponseWriter, r *http.Request) {
 			epochPageData.OrphanedCount += 1
 		}
 	}
-	epochPageData.SyncParticipationRate /= float64(epochPageData.ProposedCount)
+	if !useSummary {
+		epochPageData.SyncParticipationRate /= float64(epochPageData.ProposedCount)
+	}
 
 	epochPageData.Ts = utils.EpochToTime(epochPageData.Epoch)
 
-	err = db.DB.Get(&epochPageData.NextEpoch, "SELECT epoch FROM epochs WHERE epoch > $1 ORDER BY epoch LIMIT 1", epochPageData.Epoch)
+	err = db.DB.GetContext(r.Context(), &epochPageData.NextEpoch, "SELECT epoch FROM epochs WHERE epoch > $1 ORDER BY epoch LIMIT 1", epochPageData.Epoch)
 	if err == sql.ErrNoRows {
 		epochPageData.NextEpoch = 0
 	} else if err != nil {
-		logger.Errorf("error retrieving next epoch for epoch %v: %v", epochPageData.Epoch, err)
+		requestLogger(r).Errorf("error retrieving next epoch for epoch %v: %v", epochPageData.Epoch, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	err = db.DB.Get(&epochPageData.PreviousEpoch, "SELECT epoch FROM epochs WHERE epoch < $1 ORDER BY epoch DESC LIMIT 1", epochPageData.Epoch)
+	err = db.DB.GetContext(r.Context(), &epochPageData.PreviousEpoch, "SELECT epoch FROM epochs WHERE epoch < $1 ORDER BY epoch DESC LIMIT 1", epochPageData.Epoch)
 	if err != nil {
-		logger.Errorf("error retrieving previous epoch for epoch %v: %v", epochPageData.Epoch, err)
+		requestLogger(r).Errorf("error retrieving previous epoch for epoch %v: %v", epochPageData.Epoch, err)
 		epochPageData.PreviousEpoch = 0
 	}
 
+	finalized := "not finalized yet"
+	if epochPageData.Finalized {
+		finalized = "finalized"
+	}
+	setStructuredData(data, fmt.Sprintf("Epoch %v", epochPageData.Epoch), fmt.Sprintf("%v", epochPageData.Epoch),
+		fmt.Sprintf("Epoch %v of the beacon chain started at %v and is %v. It has %v proposed, %v missed and %v orphaned blocks with %v attestations, %v deposits and %v voluntary exits at a participation rate of %.2f%%.",
+			epochPageData.Epoch, epochPageData.Ts.UTC().Format(time.RFC1123), finalized, epochPageData.ProposedCount, epochPageData.MissedCount, epochPageData.OrphanedCount,
+			epochPageData.AttestationsCount, epochPageData.DepositsCount, epochPageData.VoluntaryExitsCount, epochPageData.GlobalParticipationRate*100),
+		"Epochs", "/epochs", epochPageData.Ts)
+
 	data.Data = epochPageData
 
 	if utils.IsApiRequest(r) {
@@ -149,7 +180,7 @@ func Epoch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -25,7 +25,7 @@ func Epochs(w http.ResponseWriter, r *http.Request) {
 	err := epochsTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -45,19 +45,19 @@ func EpochsData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -80,7 +80,7 @@ func EpochsData(w http.ResponseWriter, r *http.Request) {
 	var epochs []*types.EpochsPageData
 
 	if search == -1 {
-		err = db.DB.Select(&epochs, `
+		err = db.DB.SelectContext(r.Context(), &epochs, `
 			SELECT epoch, 
 				blockscount, 
 				proposerslashingscount, 
@@ -98,7 +98,7 @@ func EpochsData(w http.ResponseWriter, r *http.Request) {
 			WHERE epoch >= $1 AND epoch <= $2
 			ORDER BY epoch DESC`, endEpoch, startEpoch)
 	} else {
-		err = db.DB.Select(&epochs, `
+		err = db.DB.SelectContext(r.Context(), &epochs, `
 			SELECT epoch, 
 				blockscount, 
 				proposerslashingscount, 
@@ -117,14 +117,14 @@ func EpochsData(w http.ResponseWriter, r *http.Request) {
 			ORDER BY epoch DESC`, search)
 	}
 	if err != nil {
-		logger.Errorf("error retrieving epoch data: %v", err)
+		requestLogger(r).Errorf("error retrieving epoch data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	tableData := make([][]interface{}, len(epochs))
 	for i, b := range epochs {
-		// logger.Info("debug", b.Epoch, b.EligibleEther, b.VotedEther, b.GlobalParticipationRate, currency, utils.FormatBalance(b.EligibleEther, currency))
+		// requestLogger(r).Info("debug", b.Epoch, b.EligibleEther, b.VotedEther, b.GlobalParticipationRate, currency, utils.FormatBalance(b.EligibleEther, currency))
 		tableData[i] = []interface{}{
 			utils.FormatEpoch(b.Epoch),
 			utils.FormatTimestamp(utils.EpochToTime(b.Epoch).Unix()),
@@ -146,7 +146,7 @@ func EpochsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
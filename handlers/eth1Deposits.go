@@ -41,7 +41,7 @@ func Eth1Deposits(w http.ResponseWriter, r *http.Request) {
 	err := eth1DepositsTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -60,19 +60,19 @@ func Eth1DepositsData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -101,9 +101,9 @@ func Eth1DepositsData(w http.ResponseWriter, r *http.Request) {
 	latestEpoch := services.LatestEpoch()
 	validatorOnlineThresholdSlot := GetValidatorOnlineThresholdSlot()
 
-	deposits, depositCount, err := db.GetEth1DepositsJoinEth2Deposits(search, length, start, orderBy, orderDir, latestEpoch, validatorOnlineThresholdSlot)
+	deposits, depositCount, err := db.GetEth1DepositsJoinEth2Deposits(r.Context(), search, length, start, orderBy, orderDir, latestEpoch, validatorOnlineThresholdSlot)
 	if err != nil {
-		logger.Errorf("GetEth1Deposits error retrieving eth1_deposit data: %v", err)
+		requestLogger(r).Errorf("GetEth1Deposits error retrieving eth1_deposit data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -135,7 +135,7 @@ func Eth1DepositsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -155,7 +155,7 @@ func Eth1DepositsLeaderboard(w http.ResponseWriter, r *http.Request) {
 	err := eth1DepositsLeaderboardTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -172,19 +172,19 @@ func Eth1DepositsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -213,9 +213,9 @@ func Eth1DepositsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	latestEpoch := services.LatestEpoch()
 
-	deposits, depositCount, err := db.GetEth1DepositsLeaderboard(search, length, start, orderBy, orderDir, latestEpoch)
+	deposits, depositCount, err := db.GetEth1DepositsLeaderboard(r.Context(), search, length, start, orderBy, orderDir, latestEpoch)
 	if err != nil {
-		logger.Errorf("GetEth1Deposits error retrieving eth1_deposit leaderboard data: %v", err)
+		requestLogger(r).Errorf("GetEth1Deposits error retrieving eth1_deposit leaderboard data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -251,7 +251,7 @@ func Eth1DepositsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
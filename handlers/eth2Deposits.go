@@ -24,7 +24,7 @@ func Eth2Deposits(w http.ResponseWriter, r *http.Request) {
 	err := eth2DepositsTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -42,19 +42,19 @@ func Eth2DepositsData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -78,16 +78,16 @@ func Eth2DepositsData(w http.ResponseWriter, r *http.Request) {
 
 	orderDir := q.Get("order[0][dir]")
 
-	depositCount, err := db.GetEth2DepositsCount(search)
+	depositCount, err := db.GetEth2DepositsCount(r.Context(), search)
 	if err != nil {
-		logger.Errorf("error retrieving eth2_deposit count: %v", err)
+		requestLogger(r).Errorf("error retrieving eth2_deposit count: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
-	deposits, err := db.GetEth2Deposits(search, length, start, orderBy, orderDir)
+	deposits, err := db.GetEth2Deposits(r.Context(), search, length, start, orderBy, orderDir)
 	if err != nil {
-		logger.Errorf("error retrieving eth2_deposit data: %v", err)
+		requestLogger(r).Errorf("error retrieving eth2_deposit data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -114,7 +114,7 @@ func Eth2DepositsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
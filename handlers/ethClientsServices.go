@@ -25,13 +25,13 @@ func EthClientsServices(w http.ResponseWriter, r *http.Request) {
 	// pageData.Banner = ethclients.GetBannerClients()
 	if data.User.Authenticated {
 		var dbData []string
-		err = db.FrontendDB.Select(&dbData,
+		err = db.FrontendDB.SelectContext(r.Context(), &dbData,
 			`select event_filter
 			 from users_subscriptions 
 			 where user_id = $1 AND event_name=$2
 			`, data.User.UserID, string(types.EthClientUpdateEventName))
 		if err != nil {
-			logger.Errorf("error getting user subscriptions: %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error getting user subscriptions: %v route: %v", r.URL.String(), err)
 		}
 
 		for _, item := range dbData {
@@ -63,7 +63,7 @@ func EthClientsServices(w http.ResponseWriter, r *http.Request) {
 
 	err = ethClientsServicesTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
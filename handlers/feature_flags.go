@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"eth2-exporter/featureflags"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+)
+
+var featureFlagsTemplate = template.Must(template.New("user").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/user/featureFlags.html"))
+
+// AdminFeatureFlags will return the feature flags of the deployment using a go template, it is restricted to admins
+func AdminFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+	if !isAdminUser(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	data := InitPageData(w, r, "user", "/user/admin/featureflags", "Feature Flags")
+	data.Data = &types.FeatureFlagsPageData{
+		Flags:     featureflags.List(),
+		Flashes:   utils.GetFlashes(w, r, authSessionName),
+		CsrfField: csrf.TemplateField(r),
+	}
+	data.User = user
+
+	err := featureFlagsTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// AdminFeatureFlagsUpdate overrides a feature flag with the state form value "on" or "off", "config" removes the
+// override. It is restricted to admins.
+func AdminFeatureFlagsUpdate(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+	if !isAdminUser(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if !featureflags.Exists(name) {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+
+	var err error
+	state := r.FormValue("state")
+	switch state {
+	case "on", "off":
+		err = featureflags.SetOverride(name, state == "on", user.UserID)
+	case "config":
+		err = featureflags.ClearOverride(name)
+	default:
+		http.Error(w, "Invalid state", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error updating feature flag %v: %v", name, err)
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: Could not update feature flag %v.", name))
+	} else if state == "config" {
+		requestLogger(r).Infof("feature flag %v reset to config by user %v", name, user.UserID)
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Feature flag %v follows the config again.", name))
+	} else {
+		requestLogger(r).Infof("feature flag %v set to %v by user %v", name, state, user.UserID)
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Feature flag %v is now %v.", name, state))
+	}
+	http.Redirect(w, r, "/user/admin/featureflags", http.StatusSeeOther)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// parseFeeRecipient decodes a hex encoded eth1-address, an empty string is decoded to an empty address
+func parseFeeRecipient(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	if s == "" {
+		return nil, nil
+	}
+	address, err := hex.DecodeString(s)
+	if err != nil || len(address) != 20 {
+		return nil, errors.New("invalid fee recipient address")
+	}
+	return address, nil
+}
+
+// UserUpdateFeeRecipientPost saves the fee recipient the user expects the validators on their watchlist to use
+func UserUpdateFeeRecipientPost(w http.ResponseWriter, r *http.Request) {
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		requestLogger(r).Errorf("error parsing form: %v", err)
+		session.AddFlash(authInternalServerErrorFlashMsg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+		return
+	}
+
+	address, err := parseFeeRecipient(r.FormValue("fee_recipient"))
+	if err != nil {
+		session.AddFlash("Error: Invalid fee recipient address!")
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+		return
+	}
+
+	err = db.SetUserFeeRecipient(r.Context(), user.UserID, address, r.FormValue("allow_smoothing_pool") == "on")
+	if err != nil {
+		requestLogger(r).Errorf("error saving fee recipient for user %v: %v", user.UserID, err)
+		session.AddFlash(authInternalServerErrorFlashMsg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+		return
+	}
+
+	session.AddFlash("Your expected fee recipient has been updated successfully.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+}
+
+// ApiUserFeeRecipient godoc
+// @Summary Get the fee recipient the user expects the validators on their watchlist to use, the address is empty if the user has not configured one
+// @Tags User
+// @Produce  json
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/settings/feerecipient [get]
+func ApiUserFeeRecipient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	claims := getAuthClaims(r)
+
+	feeRecipient, err := db.GetUserFeeRecipient(r.Context(), claims.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving fee recipient for user %v: %v", claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := map[string]interface{}{
+		"address":              "",
+		"allow_smoothing_pool": false,
+	}
+	if feeRecipient != nil {
+		data["address"] = fmt.Sprintf("0x%x", feeRecipient.Address)
+		data["allow_smoothing_pool"] = feeRecipient.AllowSmoothingPool
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{data})
+}
+
+// ApiUserFeeRecipientPost godoc
+// @Summary Set the fee recipient the user expects the validators on their watchlist to use, an empty address removes it
+// @Tags User
+// @Produce  json
+// @Param address body string true "Expected fee recipient address"
+// @Param allow_smoothing_pool body string false "Submit \"on\" to also accept the Rocketpool smoothing pool for minipools"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/settings/feerecipient [post]
+func ApiUserFeeRecipientPost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	address, err := parseFeeRecipient(FormValueOrJSON(r, "address"))
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	claims := getAuthClaims(r)
+
+	err = db.SetUserFeeRecipient(r.Context(), claims.UserID, address, FormValueOrJSON(r, "allow_smoothing_pool") == "on")
+	if err != nil {
+		requestLogger(r).Errorf("error saving fee recipient for user %v: %v", claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not save fee recipient")
+		return
+	}
+
+	OKResponse(w, r)
+}
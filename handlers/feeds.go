@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// feedRateLimit is the number of feed requests a single ip-address can make per feedRateLimitWindow,
+// the feeds are independent of the api-keys and their quotas
+const feedRateLimit = 60
+const feedRateLimitWindow = time.Minute
+
+var feedRequests = newRateLimiter(feedRateLimit, feedRateLimitWindow)
+
+// feedRateLimited counts the request and returns the time until the next window if the ip-address exceeded the limit
+func feedRateLimited(r *http.Request) (bool, time.Duration) {
+	return feedRequests.limited(clientIP(r))
+}
+
+// Feed serves the pre-rendered slashings, exits and blocks feeds for bots, clients should send the received ETag
+// in the If-None-Match header to avoid downloading unchanged feeds
+func Feed(w http.ResponseWriter, r *http.Request) {
+	limited, retryAfter := feedRateLimited(r)
+	if limited {
+		w.Header().Set("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()+1))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	feed := services.GetBotFeed(mux.Vars(r)["feed"])
+	if feed == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%v", utils.Config.Chain.SecondsPerSlot))
+	w.Header().Set("ETag", feed.ETag)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Header.Get("If-None-Match") == feed.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(feed.Body)
+}
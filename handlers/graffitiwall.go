@@ -18,10 +18,10 @@ func Graffitiwall(w http.ResponseWriter, r *http.Request) {
 
 	var graffitiwallData []*types.GraffitiwallData
 
-	err = db.DB.Select(&graffitiwallData, "select x, y, color, slot, validator from graffitiwall")
+	err = db.DB.SelectContext(r.Context(), &graffitiwallData, "select x, y, color, slot, validator from graffitiwall")
 
 	if err != nil {
-		logger.Errorf("error retrieving block tree data: %v", err)
+		requestLogger(r).Errorf("error retrieving block tree data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -33,7 +33,7 @@ func Graffitiwall(w http.ResponseWriter, r *http.Request) {
 	err = graffitiwallTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
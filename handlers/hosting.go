@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var hostingTemplate = template.Must(template.New("hosting").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/hosting.html"))
+
+// hostingStatsDays is the number of days the daily hosting stats are returned for
+const hostingStatsDays = 180
+
+type hostingProviderRange struct {
+	provider string
+	network  *net.IPNet
+}
+
+var hostingProviderRanges []hostingProviderRange
+var hostingProviderRangesOnce sync.Once
+
+// hostingProviderOfIP returns the configured provider whose ip ranges contain the ip, an empty string is returned if
+// the ip is not in any range
+func hostingProviderOfIP(ip net.IP) string {
+	hostingProviderRangesOnce.Do(func() {
+		for _, p := range utils.Config.Frontend.HostingProviders {
+			for _, cidr := range p.Ranges {
+				_, network, err := net.ParseCIDR(cidr)
+				if err != nil {
+					logger.Errorf("error parsing ip range %v of hosting provider %v: %v", cidr, p.Name, err)
+					continue
+				}
+				hostingProviderRanges = append(hostingProviderRanges, hostingProviderRange{provider: p.Name, network: network})
+			}
+		}
+	})
+	for _, r := range hostingProviderRanges {
+		if r.network.Contains(ip) {
+			return r.provider
+		}
+	}
+	return ""
+}
+
+// inferValidatorHosting maps the ip-address of a node metrics submission to a hosting provider and sets it for the
+// validators whose owner opted in to infer their hosting, the ip-address itself is not stored
+func inferValidatorHosting(r *http.Request, userID uint64) {
+	ip := net.ParseIP(clientIP(r))
+	if ip == nil {
+		return
+	}
+	provider := hostingProviderOfIP(ip)
+	if provider == "" {
+		provider = db.HostingProviderOther
+	}
+	err := db.UpdateInferredValidatorHosting(r.Context(), userID, provider)
+	if err != nil {
+		requestLogger(r).Errorf("error updating inferred validator hosting of user %v: %v", userID, err)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatorHostingPost stores or removes the hosting the verified owner of a validator declared for it, with the
+// metrics source the provider is inferred from the next node metrics submission of the owner
+func ValidatorHostingPost(w http.ResponseWriter, r *http.Request) {
+	pubkeyHex := strings.TrimPrefix(strings.ToLower(mux.Vars(r)["pubkey"]), "0x")
+
+	user := getUser(r)
+	if !user.Authenticated {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	pubkey, err := hex.DecodeString(pubkeyHex)
+	if err != nil || len(pubkey) != 48 {
+		http.Error(w, "Invalid validator public key", http.StatusBadRequest)
+		return
+	}
+
+	ownership, err := db.GetValidatorOwnership(r.Context(), user.UserID, pubkey)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator ownership of user %v: %v", user.UserID, err)
+		utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while saving the hosting")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+	if ownership == nil {
+		utils.SetFlash(w, r, validatorEditFlash, "Error: only verified owners can declare the hosting of a validator")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	if r.FormValue("action") == "remove" {
+		err = db.DeleteValidatorHosting(r.Context(), user.UserID, pubkey)
+		if err != nil {
+			requestLogger(r).Errorf("error deleting validator hosting of user %v for validator %x: %v", user.UserID, pubkey, err)
+			utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while removing the hosting")
+			http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+			return
+		}
+		utils.SetFlash(w, r, validatorEditFlash, "The hosting of this validator has been removed from the analytics.")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	hosting := &types.ValidatorHosting{
+		UserID:             user.UserID,
+		ValidatorPublickey: pubkey,
+		Provider:           r.FormValue("provider"),
+		Region:             r.FormValue("region"),
+		Source:             r.FormValue("source"),
+		UpdatedTs:          time.Now(),
+	}
+	if !containsString(db.HostingRegions, hosting.Region) {
+		utils.SetFlash(w, r, validatorEditFlash, "Error: invalid hosting region")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+	switch hosting.Source {
+	case db.HostingSourceDeclared:
+		if !containsString(db.HostingProviders(), hosting.Provider) {
+			utils.SetFlash(w, r, validatorEditFlash, "Error: invalid hosting provider")
+			http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+			return
+		}
+	case db.HostingSourceMetrics:
+		// the provider is set by the next metrics submission, a previous inference is kept until then
+		previous, err := db.GetValidatorHosting(r.Context(), user.UserID, pubkey)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving validator hosting of user %v for validator %x: %v", user.UserID, pubkey, err)
+		}
+		hosting.Provider = ""
+		if previous != nil && previous.Source == db.HostingSourceMetrics {
+			hosting.Provider = previous.Provider
+		}
+	default:
+		utils.SetFlash(w, r, validatorEditFlash, "Error: invalid hosting source")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	err = db.SaveValidatorHosting(r.Context(), hosting)
+	if err != nil {
+		requestLogger(r).Errorf("error saving validator hosting of user %v for validator %x: %v", user.UserID, pubkey, err)
+		utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while saving the hosting")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	utils.SetFlash(w, r, validatorEditFlash, "The hosting of this validator has been saved, it is only published as part of aggregates.")
+	http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+}
+
+// Hosting will return the hosting analytics page using a go template
+func Hosting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	latest, err := db.GetLatestHostingStats(r.Context())
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving hosting stats for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	data := InitPageData(w, r, "stats", "/network/hosting", "Validator Hosting")
+	data.Data = &types.HostingPageData{
+		Latest:               latest,
+		MinUsers:             db.HostingStatsMinUsers,
+		EffectivenessFormula: db.GetEffectivenessFormula(db.DefaultEffectivenessFormula).Title,
+	}
+
+	err = hostingTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiHostingStats godoc
+// @Summary Get the daily attestation performance of the validators aggregated by the hosting provider and region their verified owners declared (or opted in to infer from their node metrics submissions) for the last 180 days. Only groups of several owners are published, smaller groups are merged into the other providers. The share is relative to all validators with a declared hosting, not to the validator set.
+// @Tags Network
+// @Produce  json
+// @Success 200 {object} types.ApiResponse{data=[]types.HostingStats}
+// @Router /api/v1/network/hosting [get]
+func ApiHostingStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	startDay := uint64(0)
+	if lastDay, exported, err := db.GetLastHostingStatsDay(r.Context()); err == nil && exported && lastDay >= hostingStatsDays {
+		startDay = lastDay - hostingStatsDays + 1
+	}
+
+	stats, err := db.GetHostingStats(r.Context(), startDay)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving hosting stats for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
@@ -21,7 +21,7 @@ func Imprint(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		logger.Errorf("error parsing imprint page template: %v", err)
+		requestLogger(r).Errorf("error parsing imprint page template: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -32,7 +32,7 @@ func Imprint(w http.ResponseWriter, r *http.Request) {
 	err = imprintTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
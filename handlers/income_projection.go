@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/price"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	incomeProjectionDefaultMonths = 24
+	incomeProjectionMaxMonths     = 120
+	daysPerMonth                  = 365.25 / 12
+)
+
+var incomeProjectionCurrencies = map[string]bool{"ETH": true, "USD": true, "EUR": true, "GBP": true, "CNY": true, "RUB": true, "CAD": true, "AUD": true, "JPY": true}
+
+// ApiValidatorIncomeProjection godoc
+// @Summary Project the future income of a validator from its income of the last 31 days and calculate when the hardware and hosting costs break even. If the latest fork activated within the last 31 days only the income since the fork is used. The participation_rate and eth_price parameters allow to calculate scenarios, rewards except the sync committee rewards are assumed to scale linearly with the participation rate of the network.
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Validator index or pubkey"
+// @Param  currency query string false "Currency of the costs and profits, defaults to USD"
+// @Param  eth_price query number false "Price of 1 ETH in the currency, defaults to the current price"
+// @Param  hardware_cost query number false "One-time hardware cost in the currency"
+// @Param  monthly_cost query number false "Monthly hosting cost in the currency"
+// @Param  participation_rate query number false "Assumed participation rate of the network between 0 and 1, defaults to the average of the last 31 days"
+// @Param  months query int false "Number of projected months, at most 120, defaults to 24"
+// @Success 200 {object} types.ApiResponse{data=types.ValidatorIncomeProjection}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/projection [get]
+func ApiValidatorIncomeProjection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	indices, pubkeys, err := parseApiValidatorParam(mux.Vars(r)["indexOrPubkey"], 1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	projection := &types.ValidatorIncomeProjection{Currency: "USD"}
+	if c := q.Get("currency"); c != "" {
+		if !incomeProjectionCurrencies[c] {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("invalid currency %v", c))
+			return
+		}
+		projection.Currency = c
+	}
+	projection.EthPrice = price.GetEthPrice(projection.Currency)
+
+	months := incomeProjectionDefaultMonths
+	if m := q.Get("months"); m != "" {
+		months, err = strconv.Atoi(m)
+		if err != nil || months < 1 || months > incomeProjectionMaxMonths {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("months must be between 1 and %v", incomeProjectionMaxMonths))
+			return
+		}
+	}
+
+	params := []struct {
+		name string
+		dst  *float64
+	}{
+		{"eth_price", &projection.EthPrice},
+		{"hardware_cost", &projection.HardwareCost},
+		{"monthly_cost", &projection.MonthlyCost},
+		{"participation_rate", &projection.ParticipationRate},
+	}
+	for _, p := range params {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		*p.dst, err = strconv.ParseFloat(v, 64)
+		if err != nil || *p.dst < 0 || math.IsInf(*p.dst, 0) || math.IsNaN(*p.dst) {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("invalid %v", p.name))
+			return
+		}
+	}
+	if projection.ParticipationRate > 1 {
+		sendErrorResponse(j, r.URL.String(), "participation_rate must be between 0 and 1")
+		return
+	}
+
+	var index uint64
+	var pubkey []byte
+	if len(pubkeys) > 0 {
+		pubkey = pubkeys[0]
+	} else {
+		index = indices[0]
+	}
+	history, err := db.GetValidatorIncomeHistory(r.Context(), index, pubkey)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving income history of validator %v: %v", mux.Vars(r)["indexOrPubkey"], err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if history == nil {
+		sendErrorResponse(j, r.URL.String(), "validator not found")
+		return
+	}
+
+	latestEpoch := services.LatestEpoch()
+	if history.ExitEpoch <= latestEpoch {
+		sendErrorResponse(j, r.URL.String(), "the validator has exited")
+		return
+	}
+	epochsPerDay := utils.EpochsPerDay()
+	if history.ActivationEpoch > latestEpoch || (latestEpoch-history.ActivationEpoch) < epochsPerDay {
+		sendErrorResponse(j, r.URL.String(), "the validator has not been active for a full day yet")
+		return
+	}
+
+	startEpoch := uint64(0)
+	if latestEpoch > epochsPerDay*31 {
+		startEpoch = latestEpoch - epochsPerDay*31
+	}
+	historicalIncome := history.Performance31d
+	historicalDays := (latestEpoch - history.ActivationEpoch) / epochsPerDay
+	if historicalDays > 31 {
+		historicalDays = 31
+	}
+
+	// the income from before the latest fork is not representative for the rewards after it, if the fork activated
+	// within the last 31 days the projection is based on the full days since the fork
+	fork := services.ForkOfEpoch(latestEpoch)
+	projection.Fork = fork.Name
+	if fork.Epoch > startEpoch && fork.Epoch > history.ActivationEpoch {
+		forkDay := utils.DayOfSlot(fork.Epoch*utils.Config.Chain.SlotsPerEpoch) + 1
+		income, days, err := db.GetValidatorIncomeSinceDay(r.Context(), history.Validatorindex, forkDay)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving income of validator %v since the %v fork: %v", history.Validatorindex, fork.Name, err)
+			sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		if days == 0 {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("the %v fork activated less than a full day ago, the income can be projected after the first full day", fork.Name))
+			return
+		}
+		historicalIncome, historicalDays, startEpoch = income, days, fork.Epoch
+	}
+
+	projection.HistoricalParticipation, err = db.GetAverageGlobalParticipationRate(r.Context(), startEpoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving average participation rate: %v", err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	projectValidatorIncome(projection, history, historicalIncome, historicalDays, services.RewardWeightsOfEpoch(latestEpoch), months)
+
+	sendOKResponse(j, r.URL.String(), []interface{}{projection})
+}
+
+// projectValidatorIncome fills in the projected income, profit and break-even time of the projection from the income
+// of the historical days. The attestation and proposal rewards are scaled linearly by the ratio of the assumed to the
+// historical participation rate, the sync committee rewards of the fork do not depend on the participation.
+func projectValidatorIncome(projection *types.ValidatorIncomeProjection, history *db.ValidatorIncomeHistory, historicalIncome int64, historicalDays uint64, weights services.ForkRewardWeights, months int) {
+	projection.Validatorindex = history.Validatorindex
+
+	projection.HistoricalDays = historicalDays
+	projection.HistoricalDailyIncome = historicalIncome / int64(projection.HistoricalDays)
+
+	if projection.ParticipationRate == 0 {
+		projection.ParticipationRate = projection.HistoricalParticipation
+	}
+	scale := 1.0
+	if projection.HistoricalParticipation > 0 {
+		scale = weights.Sync + (1-weights.Sync)*projection.ParticipationRate/projection.HistoricalParticipation
+	}
+	projection.DailyIncome = int64(float64(projection.HistoricalDailyIncome) * scale)
+	projection.MonthlyIncome = int64(float64(projection.DailyIncome) * daysPerMonth)
+	if history.EffectiveBalance > 0 {
+		projection.APR = float64(projection.DailyIncome) * 365 / float64(history.EffectiveBalance)
+	}
+
+	projection.MonthlyProfit = float64(projection.MonthlyIncome)/1e9*projection.EthPrice - projection.MonthlyCost
+	if projection.HardwareCost == 0 {
+		breakEven := 0.0
+		projection.BreakEvenMonths = &breakEven
+	} else if projection.MonthlyProfit > 0 {
+		breakEven := projection.HardwareCost / projection.MonthlyProfit
+		projection.BreakEvenMonths = &breakEven
+	}
+
+	projection.Months = make([]*types.ValidatorIncomeProjectionMonth, months)
+	for i := range projection.Months {
+		month := i + 1
+		projection.Months[i] = &types.ValidatorIncomeProjectionMonth{
+			Month:  month,
+			Income: projection.MonthlyIncome * int64(month),
+			Profit: projection.MonthlyProfit*float64(month) - projection.HardwareCost,
+		}
+	}
+}
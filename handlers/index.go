@@ -7,6 +7,8 @@ import (
 	"eth2-exporter/utils"
 	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 var indexTemplate = template.Must(template.New("index").Funcs(utils.GetTemplateFuncs()).ParseFiles(
@@ -43,12 +45,21 @@ func Index(w http.ResponseWriter, r *http.Request) {
 	err := indexTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 }
 
+// PersonalModeIndex redirects to the dashboard of the watched validators, it replaces the index page in personal mode
+func PersonalModeIndex(w http.ResponseWriter, r *http.Request) {
+	validators := make([]string, 0, len(utils.Config.Frontend.PersonalMode.Validators))
+	for _, v := range utils.Config.Frontend.PersonalMode.Validators {
+		validators = append(validators, strconv.FormatUint(v, 10))
+	}
+	http.Redirect(w, r, "/dashboard?validators="+strings.Join(validators, ","), http.StatusFound)
+}
+
 // IndexPageData will show the main "index" page in json format
 func IndexPageData(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -56,7 +67,7 @@ func IndexPageData(w http.ResponseWriter, r *http.Request) {
 	err := json.NewEncoder(w).Encode(services.LatestIndexPageData())
 
 	if err != nil {
-		logger.Errorf("error sending latest index page data: %v", err)
+		requestLogger(r).Errorf("error sending latest index page data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"net/http"
+)
+
+// getLanguage returns the language of the request, the preference saved in the session takes precedence over the language cookie and the Accept-Language header
+func getLanguage(r *http.Request) string {
+	if utils.SessionStore != nil {
+		session, err := utils.SessionStore.Get(r, authSessionName)
+		if err == nil {
+			if lang, ok := session.Values["language"].(string); ok && utils.IsSupportedLanguage(lang) {
+				return lang
+			}
+		}
+	}
+
+	if cookie, err := r.Cookie("language"); err == nil && utils.IsSupportedLanguage(cookie.Value) {
+		return cookie.Value
+	}
+
+	return utils.NegotiateLanguage(r.Header.Get("Accept-Language"))
+}
+
+// SetLanguage saves the language preference of the user in the session and in a cookie
+func SetLanguage(w http.ResponseWriter, r *http.Request) {
+	lang := r.FormValue("lang")
+	if !utils.IsSupportedLanguage(lang) {
+		http.Error(w, "Error: Unsupported language", http.StatusBadRequest)
+		return
+	}
+
+	if utils.SessionStore != nil {
+		session, err := utils.SessionStore.Get(r, authSessionName)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving session: %v", err)
+			http.Error(w, "Internal server error", 503)
+			return
+		}
+		session.Values["language"] = lang
+		err = session.Save(r, w)
+		if err != nil {
+			requestLogger(r).Errorf("error saving session: %v", err)
+			http.Error(w, "Internal server error", 503)
+			return
+		}
+	}
+
+	if user := getUser(r); user.Authenticated {
+		// the notification mails are sent in the language of the user
+		err := db.SetUserLanguage(r.Context(), user.UserID, lang)
+		if err != nil {
+			requestLogger(r).Errorf("error saving language of user %v: %v", user.UserID, err)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "language",
+		Value:    lang,
+		Path:     "/",
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -44,7 +44,7 @@ func LaunchMetricsData(w http.ResponseWriter, r *http.Request) {
 
 	// highEpoch := latestEpoch
 
-	err := db.DB.Select(&blks, `
+	err := db.DB.SelectContext(r.Context(), &blks, `
 	SELECT
 		b.slot,
 		case
@@ -67,7 +67,7 @@ func LaunchMetricsData(w http.ResponseWriter, r *http.Request) {
 	ORDER BY slot desc
 `, lookBack, services.LatestEpoch())
 	if err != nil {
-		logger.Errorf("error querying blocks table for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error querying blocks table for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -144,7 +144,7 @@ func LaunchMetricsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(res)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -1,5 +1,16 @@
 package handlers
 
-import "github.com/sirupsen/logrus"
+import (
+	"eth2-exporter/logging"
+	"net/http"
 
-var logger = logrus.New().WithField("module", "handlers")
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logging.NewLogger("handlers")
+
+// requestLogger returns the handlers logger with the id the request middleware assigned to the request attached, all
+// handlers log through it so their entries can be correlated with the request log
+func requestLogger(r *http.Request) *logrus.Entry {
+	return logging.WithRequest(logger, r)
+}
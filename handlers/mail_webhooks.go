@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"eth2-exporter/db"
+	"eth2-exporter/mail"
+	"eth2-exporter/utils"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// MailWebhook ingests the bounce and complaint webhooks of the mail providers, the reported addresses are added to the
+// mail suppression list to protect the sender reputation
+func MailWebhook(w http.ResponseWriter, r *http.Request) {
+	token := utils.Config.Frontend.Mail.WebhookToken
+	if token == "" || subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	provider := mux.Vars(r)["provider"]
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	feedback, err := mail.ParseFeedback(provider, body)
+	if err != nil {
+		requestLogger(r).Warnf("error parsing mail webhook of provider %v: %v", provider, err)
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	for _, f := range feedback {
+		err = db.AddMailSuppression(r.Context(), f.Email, f.Reason, provider, f.Details)
+		if err != nil {
+			requestLogger(r).Errorf("error adding %v to the mail suppression list: %v", f.Email, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		requestLogger(r).Infof("suppressing mails to %v after %v reported by %v", f.Email, f.Reason, provider)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -9,7 +9,7 @@ import (
 	"net/http"
 )
 
-var mobileTemplate = template.Must(template.New("mobilepage").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/mobilepage.html"))
+var mobileTemplate = template.Must(template.New("mobilepage").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/mobilepage.html", "templates/components/captcha.html"))
 
 func MobilePage(w http.ResponseWriter, r *http.Request) {
 	var err error
@@ -17,11 +17,11 @@ func MobilePage(w http.ResponseWriter, r *http.Request) {
 
 	data := InitPageData(w, r, "more", "/mobile", "Beaconchain Dashboard")
 	pageData := &types.AdvertiseWithUsPageData{}
-	pageData.RecaptchaKey = utils.Config.Frontend.RecaptchaSiteKey
+	pageData.Captcha = utils.GetCaptchaData("mobile")
 
 	pageData.FlashMessage, err = utils.GetFlash(w, r, "ad_flash")
 	if err != nil {
-		logger.Errorf("error retrieving flashes for mobile page %v", err)
+		requestLogger(r).Errorf("error retrieving flashes for mobile page %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -30,7 +30,7 @@ func MobilePage(w http.ResponseWriter, r *http.Request) {
 
 	err2 := mobileTemplate.ExecuteTemplate(w, "layout", data)
 	if err2 != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err2)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err2)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -39,27 +39,18 @@ func MobilePage(w http.ResponseWriter, r *http.Request) {
 func MobilePagePost(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		utils.SetFlash(w, r, "ad_flash", "Error: invalid form submitted")
 		http.Redirect(w, r, "/mobile", http.StatusSeeOther)
 		return
 	}
 
-	if len(utils.Config.Frontend.RecaptchaSecretKey) > 0 && len(utils.Config.Frontend.RecaptchaSiteKey) > 0 {
-		if len(r.FormValue("g-recaptcha-response")) == 0 {
-			utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
-			logger.Errorf("error no recaptca response present %v route: %v", r.URL.String(), r.FormValue("g-recaptcha-response"))
-			http.Redirect(w, r, "/pricing", http.StatusSeeOther)
-			return
-		}
-
-		valid, err := utils.ValidateReCAPTCHA(r.FormValue("g-recaptcha-response"))
-		if err != nil || !valid {
-			utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
-			logger.Errorf("error validating recaptcha %v route: %v", r.URL.String(), err)
-			http.Redirect(w, r, "/pricing", http.StatusSeeOther)
-			return
-		}
+	err = utils.ValidateCaptcha(r, "mobile")
+	if err != nil {
+		utils.SetFlash(w, r, "ad_flash", "Error: Failed to create request")
+		requestLogger(r).Errorf("error validating captcha %v route: %v", r.URL.String(), err)
+		http.Redirect(w, r, "/mobile", http.StatusSeeOther)
+		return
 	}
 
 	name := r.FormValue("name")
@@ -80,7 +71,7 @@ func MobilePagePost(w http.ResponseWriter, r *http.Request) {
 
 	err = mail.SendMail("support@beaconcha.in", "New app pool support inquiry", msg, []types.EmailAttachment{})
 	if err != nil {
-		logger.Errorf("error sending app pool form: %v", err)
+		requestLogger(r).Errorf("error sending app pool form: %v", err)
 		utils.SetFlash(w, r, "ad_flash", "Error: unable to submit app pool request")
 		http.Redirect(w, r, "/mobile", http.StatusSeeOther)
 		return
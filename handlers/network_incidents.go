@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+var networkIncidentsTemplate = template.Must(template.New("networkIncidents").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/network_incidents.html"))
+
+const networkIncidentsLimit = 100
+
+// NetworkIncidents will return the network incidents page using a go template
+func NetworkIncidents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	incidents, err := db.GetNetworkIncidents(r.Context(), networkIncidentsLimit)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving network incidents for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	data := InitPageData(w, r, "stats", "/network/incidents", "Network Incidents")
+	data.Data = &types.NetworkIncidentsPageData{
+		Incidents:              incidents,
+		ParticipationThreshold: utils.Config.Indexer.NetworkIncidents.ParticipationThreshold,
+		FinalityDelayThreshold: utils.Config.Indexer.NetworkIncidents.FinalityDelayThreshold,
+	}
+
+	err = networkIncidentsTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// NetworkIncidentsFeedJSON returns the most recent network incidents as json feed for status-page integrations
+func NetworkIncidentsFeedJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	j := json.NewEncoder(w)
+
+	incidents, err := db.GetNetworkIncidents(r.Context(), networkIncidentsLimit)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving network incidents for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, 0, len(incidents))
+	for _, incident := range incidents {
+		data = append(data, incident)
+	}
+
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+type networkIncidentsRss struct {
+	XMLName xml.Name                   `xml:"rss"`
+	Version string                     `xml:"version,attr"`
+	Channel networkIncidentsRssChannel `xml:"channel"`
+}
+
+type networkIncidentsRssChannel struct {
+	Title       string                    `xml:"title"`
+	Link        string                    `xml:"link"`
+	Description string                    `xml:"description"`
+	Items       []networkIncidentsRssItem `xml:"item"`
+}
+
+type networkIncidentsRssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+}
+
+// NetworkIncidentsFeedRSS returns the most recent network incidents as rss feed for status-page integrations
+func NetworkIncidentsFeedRSS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/rss+xml")
+
+	incidents, err := db.GetNetworkIncidents(r.Context(), networkIncidentsLimit)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving network incidents for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	link := fmt.Sprintf("https://%v/network/incidents", utils.Config.Frontend.SiteDomain)
+	feed := networkIncidentsRss{
+		Version: "2.0",
+		Channel: networkIncidentsRssChannel{
+			Title:       fmt.Sprintf("%v network incidents", utils.Config.Frontend.SiteName),
+			Link:        link,
+			Description: "Periods of low participation and non-finality detected on the beacon chain",
+			Items:       make([]networkIncidentsRssItem, 0, len(incidents)),
+		},
+	}
+
+	for _, incident := range incidents {
+		status := "ongoing"
+		if incident.Resolved {
+			status = "resolved"
+		}
+		feed.Channel.Items = append(feed.Channel.Items, networkIncidentsRssItem{
+			Title:       fmt.Sprintf("%v (%v): epochs %v - %v", formatNetworkIncidentType(incident.Type), status, incident.StartEpoch, incident.EndEpoch),
+			Link:        link,
+			Description: fmt.Sprintf("Minimum participation: %.2f%%, maximum finality delay: %v epochs", incident.MinParticipation*100, incident.MaxFinalityDelay),
+			GUID:        fmt.Sprintf("%v-%v-%v", incident.ID, incident.Type, status),
+			PubDate:     incident.UpdatedTs.Format(time.RFC1123Z),
+		})
+	}
+
+	w.Write([]byte(xml.Header))
+	err = xml.NewEncoder(w).Encode(feed)
+	if err != nil {
+		requestLogger(r).Errorf("error enconding rss response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+func formatNetworkIncidentType(incidentType string) string {
+	switch incidentType {
+	case db.NetworkIncidentLowParticipation:
+		return "Low participation"
+	case db.NetworkIncidentNonFinality:
+		return "Non-finality"
+	}
+	return incidentType
+}
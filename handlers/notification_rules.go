@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const maxNotificationRulesPerUser = 10
+
+// UserNotificationRules returns the custom notification rules of the user as json
+func UserNotificationRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	rules, err := db.GetNotificationRules(r.Context(), user.UserID, utils.GetNetwork())
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving notification rules for user %v: %v", user.UserID, err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// UserNotificationRulesAdd adds a custom notification rule for a single validator or, if no validator is given, all validators on the watchlist of the user
+func UserNotificationRulesAdd(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	rule := &types.NotificationRule{
+		UserID: user.UserID,
+		Name:   strings.TrimSpace(FormValueOrJSON(r, "name")),
+		Metric: types.NotificationRuleMetric(FormValueOrJSON(r, "metric")),
+	}
+	if rule.Name == "" || len(rule.Name) > 100 {
+		ErrorOrJSONResponse(w, r, "Invalid rule name", http.StatusBadRequest)
+		return
+	}
+
+	validMetric := false
+	for _, m := range types.NotificationRuleMetrics {
+		if m == rule.Metric {
+			validMetric = true
+		}
+	}
+	if !validMetric {
+		ErrorOrJSONResponse(w, r, "Invalid rule metric", http.StatusBadRequest)
+		return
+	}
+
+	threshold, err := strconv.ParseFloat(FormValueOrJSON(r, "threshold"), 64)
+	if err != nil || threshold <= 0 {
+		ErrorOrJSONResponse(w, r, "Invalid rule threshold", http.StatusBadRequest)
+		return
+	}
+	rule.Threshold = threshold
+
+	switch rule.Metric {
+	case types.NotificationRuleMetricBalanceDecrease:
+		// at most one week of epochs
+		rule.Window, err = strconv.ParseUint(FormValueOrJSON(r, "window"), 10, 64)
+		if err != nil || rule.Window == 0 || rule.Window > 1575 {
+			ErrorOrJSONResponse(w, r, "Invalid rule window, must be between 1 and 1575 epochs", http.StatusBadRequest)
+			return
+		}
+	case types.NotificationRuleMetricAttestationEffectiveness:
+		rule.Window, err = strconv.ParseUint(FormValueOrJSON(r, "window"), 10, 64)
+		if err != nil || rule.Window == 0 || rule.Window > 30 {
+			ErrorOrJSONResponse(w, r, "Invalid rule window, must be between 1 and 30 days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	validator := strings.Replace(FormValueOrJSON(r, "validator"), "0x", "", -1)
+	if validator != "" {
+		if len(validator) != 96 {
+			ErrorOrJSONResponse(w, r, "Invalid validator publickey", http.StatusBadRequest)
+			return
+		}
+		rule.ValidatorPublickey, err = hex.DecodeString(validator)
+		if err != nil {
+			ErrorOrJSONResponse(w, r, "Invalid validator publickey", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rules, err := db.GetNotificationRules(r.Context(), user.UserID, utils.GetNetwork())
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving notification rules for user %v: %v", user.UserID, err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if len(rules) >= maxNotificationRulesPerUser {
+		ErrorOrJSONResponse(w, r, "Maximum number of rules reached", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.AddNotificationRule(r.Context(), rule, utils.GetNetwork())
+	if err != nil {
+		requestLogger(r).Errorf("error adding notification rule for user %v: %v", user.UserID, err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	OKResponse(w, r)
+}
+
+// UserNotificationRulesDelete deletes a custom notification rule of the user
+func UserNotificationRulesDelete(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	ruleID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		ErrorOrJSONResponse(w, r, "Invalid rule id", http.StatusBadRequest)
+		return
+	}
+
+	err = db.DeleteNotificationRule(r.Context(), user.UserID, ruleID, utils.GetNetwork())
+	if err != nil {
+		requestLogger(r).Errorf("error deleting notification rule %v for user %v: %v", ruleID, user.UserID, err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	OKResponse(w, r)
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+)
+
+var notificationsLogTemplate = template.Must(template.New("user").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/user/notificationsLog.html"))
+
+const notificationsLogLimit = 200
+
+// isAdminUser returns true if the user is configured as admin of the frontend
+func isAdminUser(user *types.User) bool {
+	if user == nil || !user.Authenticated {
+		return false
+	}
+	for _, id := range utils.Config.Frontend.AdminUserIDs {
+		if id == user.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+// UserNotificationsLog will return the delivery history of the notifications of the user using a go template, admins
+// can inspect the history of other users with the user query parameter
+func UserNotificationsLog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+
+	pageData := &types.NotificationsLogPageData{
+		UserID:    user.UserID,
+		IsAdmin:   isAdminUser(user),
+		Flashes:   utils.GetFlashes(w, r, authSessionName),
+		CsrfField: csrf.TemplateField(r),
+	}
+	if q := r.URL.Query().Get("user"); q != "" && pageData.IsAdmin {
+		userID, err := strconv.ParseUint(q, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid user id", http.StatusBadRequest)
+			return
+		}
+		pageData.UserID = userID
+	}
+
+	if pageData.UserID == user.UserID {
+		pageData.TestChannels = []types.NotificationChannel{types.EmailNotificationChannel, types.PushNotificationChannel}
+	}
+
+	entries, err := db.GetUserNotificationsLog(r.Context(), pageData.UserID, utils.GetNetwork(), notificationsLogLimit)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving notifications log for user %v: %v", pageData.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	pageData.Entries = entries
+
+	data := InitPageData(w, r, "user", "/user/notifications/log", "Notification History")
+	data.Data = pageData
+	data.User = user
+
+	err = notificationsLogTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserNotificationsLogResend delivers a logged notification again, it is restricted to admins
+func UserNotificationsLogResend(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+	if !isAdminUser(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification id", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := services.ResendNotification(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error resending notification %v: %v", id, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if entry.Status == types.NotificationLogStatusSent {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Notification %v has been resent as notification %v.", id, entry.ID))
+	} else {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: Resending notification %v failed: %v", id, entry.ProviderResponse))
+	}
+	http.Redirect(w, r, fmt.Sprintf("/user/notifications/log?user=%v", entry.UserID), http.StatusSeeOther)
+}
+
+// testNotificationResult summarizes the delivery attempts of a test notification
+func testNotificationResult(entries []*types.NotificationLogEntry) (sent int, failures []string) {
+	for _, e := range entries {
+		if e.Status == types.NotificationLogStatusSent {
+			sent++
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%v (%v)", e.Status, e.ProviderResponse))
+	}
+	return sent, failures
+}
+
+// UserNotificationsTestPost sends a test notification on a channel to the recipients of the user, the result is shown
+// on the notification history
+func UserNotificationsTestPost(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+	channel := types.NotificationChannel(mux.Vars(r)["channel"])
+
+	entries, err := services.SendTestNotification(user.UserID, channel)
+	if err == services.ErrNoTestNotificationRecipient {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: No %v recipient is configured for your account.", channel))
+		http.Redirect(w, r, "/user/notifications/log", http.StatusSeeOther)
+		return
+	}
+	if rateLimitErr, ok := err.(*types.RateLimitError); ok {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: Test notifications can only be sent once per minute, please try again in %v.", rateLimitErr.TimeLeft.Round(time.Second)))
+		http.Redirect(w, r, "/user/notifications/log", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error sending %v test notification to user %v: %v", channel, user.UserID, err)
+		utils.SetFlash(w, r, authSessionName, "Error: Sending the test notification failed.")
+		http.Redirect(w, r, "/user/notifications/log", http.StatusSeeOther)
+		return
+	}
+
+	sent, failures := testNotificationResult(entries)
+	if len(failures) > 0 {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: The %v test notification was delivered to %v of %v recipients: %v", channel, sent, len(entries), strings.Join(failures, ", ")))
+	} else {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("The %v test notification has been delivered to %v recipients.", channel, sent))
+	}
+	http.Redirect(w, r, "/user/notifications/log", http.StatusSeeOther)
+}
+
+// ApiUserNotificationsTest godoc
+// @Summary Send a test notification through the delivery pipeline of the notifications to every recipient of the user on the channel (email or push), at most once per minute, and return the delivery attempts with their status and the response of the delivery provider
+// @Tags User
+// @Produce  json
+// @Param channel path string true "Channel of the test notification: email or push"
+// @Success 200 {object} types.ApiResponse{data=[]types.NotificationLogEntry}
+// @Failure 400 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/test/{channel} [post]
+func ApiUserNotificationsTest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	claims := getAuthClaims(r)
+	channel := types.NotificationChannel(mux.Vars(r)["channel"])
+
+	entries, err := services.SendTestNotification(claims.UserID, channel)
+	if err == services.ErrNoTestNotificationRecipient {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("no %v recipient is configured", channel))
+		return
+	}
+	if rateLimitErr, ok := err.(*types.RateLimitError); ok {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("test notifications can only be sent once per minute, try again in %v", rateLimitErr.TimeLeft.Round(time.Second)))
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error sending %v test notification to user %v: %v", channel, claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not send test notification")
+		return
+	}
+
+	data := make([]interface{}, len(entries))
+	for i, e := range entries {
+		data[i] = e
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
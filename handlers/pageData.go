@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	ethclients "eth2-exporter/ethClients"
+	"eth2-exporter/featureflags"
 	"eth2-exporter/price"
 	"eth2-exporter/services"
 	"eth2-exporter/types"
@@ -10,7 +11,6 @@ import (
 	"eth2-exporter/version"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gorilla/sessions"
@@ -63,8 +63,12 @@ func InitPageData(w http.ResponseWriter, r *http.Request, active, path, title st
 		CurrentSymbol:         GetCurrencySymbol(r),
 		ClientsUpdated:        ethclients.ClientsUpdated(),
 		Phase0:                utils.Config.Chain.Phase0,
-		Lang:                  "en-US",
-		NoAds:                 user.Authenticated && user.Subscription != "",
+		Lang:                  getLanguage(r),
+		Theme:                 getTheme(r),
+		NoAds:                 (user.Authenticated && user.Subscription != "") || utils.Config.Frontend.PersonalMode.Enabled,
+		FeatureFlags:          featureflags.All(),
+		UpcomingFork:          services.UpcomingFork(),
+		PersonalMode:          utils.Config.Frontend.PersonalMode.Enabled,
 	}
 	data.EthPrice = price.GetEthPrice(data.Currency)
 	data.ExchangeRate = price.GetEthPrice(data.Currency)
@@ -79,20 +83,6 @@ func InitPageData(w http.ResponseWriter, r *http.Request, active, path, title st
 	data.AudTruncPrice = utils.KFormatterEthPrice(data.AudRoundPrice)
 	data.JpyTruncPrice = utils.KFormatterEthPrice(data.JpyRoundPrice)
 
-	acceptedLangs := strings.Split(r.Header.Get("Accept-Language"), ",")
-	if len(acceptedLangs) > 0 {
-		if strings.Contains(acceptedLangs[0], "ru") || strings.Contains(acceptedLangs[0], "RU") {
-			data.Lang = "ru-RU"
-		}
-	}
-
-	for _, v := range r.Cookies() {
-		if v.Name == "language" {
-			data.Lang = v.Value
-			break
-		}
-	}
-
 	return data
 }
 
@@ -120,12 +110,12 @@ func getUserSession(r *http.Request) (*types.User, *sessions.Session, error) {
 	}
 	session, err := utils.SessionStore.Get(r, authSessionName)
 	if err != nil {
-		logger.Errorf("error getting session from sessionStore: %v", err)
+		requestLogger(r).Errorf("error getting session from sessionStore: %v", err)
 		return u, session, err
 	}
 	ok := false
 	u.Authenticated, ok = session.Values["authenticated"].(bool)
-	if !ok {
+	if !ok || utils.SessionExpired(session) {
 		u.Authenticated = false
 		return u, session, nil
 	}
@@ -134,6 +124,10 @@ func getUserSession(r *http.Request) (*types.User, *sessions.Session, error) {
 		u.Authenticated = false
 		return u, session, nil
 	}
+	if !userSessionActive(r, session, u.UserID) {
+		u.Authenticated = false
+		return u, session, nil
+	}
 	u.Subscription, ok = session.Values["subscription"].(string)
 	if !ok {
 		u.Subscription = ""
@@ -38,7 +38,7 @@ func Poap(w http.ResponseWriter, r *http.Request) {
 
 	err := poapTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -54,7 +54,7 @@ func PoapData(w http.ResponseWriter, r *http.Request) {
 	if latestPoapData != nil && (latestEpoch < latestPoapDataEpoch || latestEpoch == 0 || latestEpoch > utils.EpochOfSlot(poapMaxSlot)) {
 		err := json.NewEncoder(w).Encode(latestPoapData.(*types.DataTableResponse))
 		if err != nil {
-			logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -66,7 +66,7 @@ func PoapData(w http.ResponseWriter, r *http.Request) {
 		Blockcount     uint64
 		Validatorcount uint64
 	}{}
-	err := db.DB.Select(&sqlRes, `
+	err := db.DB.SelectContext(r.Context(), &sqlRes, `
 		select 
 			graffiti, 
 			count(*) as blockcount,
@@ -75,7 +75,7 @@ func PoapData(w http.ResponseWriter, r *http.Request) {
 		where slot <= $1 and graffiti like 'poap%'
 		group by graffiti`, poapMaxSlot)
 	if err != nil {
-		logger.Errorf("error retrieving poap data: %v", err)
+		requestLogger(r).Errorf("error retrieving poap data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -128,7 +128,7 @@ func PoapData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -28,7 +28,7 @@ func Pools(w http.ResponseWriter, r *http.Request) {
 
 	chartData, err := services.ChartHandlers["deposits_distribution"].DataFunc()
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -52,7 +52,7 @@ func Pools(w http.ResponseWriter, r *http.Request) {
 
 	err = poolsServicesTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -71,7 +71,7 @@ func GetAvgCurrentStreak(w http.ResponseWriter, r *http.Request) {
 
 	var sqlData []*string
 
-	err := db.DB.Select(&sqlData, `
+	err := db.DB.SelectContext(r.Context(), &sqlData, `
 			with 
 				matched_validators as (
 					SELECT v.validatorindex  
@@ -105,7 +105,7 @@ func GetAvgCurrentStreak(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(sqlData)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -118,7 +118,7 @@ func GetIncomePerEthChart(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewEncoder(w).Encode(services.GetIncomePerDepositedETHChart())
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -24,7 +24,7 @@ func PoolsRocketpool(w http.ResponseWriter, r *http.Request) {
 	err := poolsRocketpoolTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -35,19 +35,19 @@ func PoolsRocketpoolDataMinipools(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -81,7 +81,7 @@ func PoolsRocketpoolDataMinipools(w http.ResponseWriter, r *http.Request) {
 	recordsFiltered := uint64(0)
 	var minipools []types.RocketpoolPageDataMinipool
 	if search == "" {
-		err = db.DB.Select(&minipools, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &minipools, fmt.Sprintf(`
 			select 
 				rocketpool_minipools.*, 
 				validators.validatorindex as validator_index,
@@ -95,12 +95,12 @@ func PoolsRocketpoolDataMinipools(w http.ResponseWriter, r *http.Request) {
 			limit $1
 			offset $2`, orderBy, orderDir), length, start)
 		if err != nil {
-			logger.Errorf("error getting rocketpool-minipools from db: %v", err)
+			requestLogger(r).Errorf("error getting rocketpool-minipools from db: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 	} else {
-		err = db.DB.Select(&minipools, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &minipools, fmt.Sprintf(`
 			with matched_minipools as (
 				select address from rocketpool_minipools where encode(pubkey::bytea,'hex') like $3
 				union select address from rocketpool_minipools where encode(address::bytea,'hex') like $3
@@ -120,7 +120,7 @@ func PoolsRocketpoolDataMinipools(w http.ResponseWriter, r *http.Request) {
 			limit $1
 			offset $2`, orderBy, orderDir), length, start, search+"%", "%"+search+"%")
 		if err != nil {
-			logger.Errorf("error getting rocketpool-minipools from db (with search: %v): %v", search, err)
+			requestLogger(r).Errorf("error getting rocketpool-minipools from db (with search: %v): %v", search, err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -158,7 +158,7 @@ func PoolsRocketpoolDataMinipools(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -169,19 +169,19 @@ func PoolsRocketpoolDataNodes(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -195,11 +195,18 @@ func PoolsRocketpoolDataNodes(w http.ResponseWriter, r *http.Request) {
 
 	orderColumn := q.Get("order[0][column]")
 	orderByMap := map[string]string{
-		"0": "address",
-		"1": "timezone_location",
-		"2": "rpl_stake",
-		"3": "min_rpl_stake",
-		"4": "max_rpl_stake",
+		"0":  "address",
+		"1":  "timezone_location",
+		"2":  "rpl_stake",
+		"3":  "min_rpl_stake",
+		"4":  "max_rpl_stake",
+		"5":  "smoothing_pool_opted_in",
+		"6":  "fee_recipient_violations",
+		"7":  "deposit_credit",
+		"8":  "eth_matched",
+		"9":  "eth_matched_limit",
+		"10": "leb8_minipools",
+		"11": "collateralization",
 	}
 	orderBy, exists := orderByMap[orderColumn]
 	if !exists {
@@ -214,32 +221,68 @@ func PoolsRocketpoolDataNodes(w http.ResponseWriter, r *http.Request) {
 	recordsFiltered := uint64(0)
 	var dbResult []types.RocketpoolPageDataNode
 	if search == "" {
-		err = db.DB.Select(&dbResult, fmt.Sprintf(`
-			select rocketpool_nodes.*, cnt.total_count
+		err = db.DB.SelectContext(r.Context(), &dbResult, fmt.Sprintf(`
+			select rocketpool_nodes.*, cnt.total_count, coalesce(violations.cnt, 0) as fee_recipient_violations,
+				coalesce(minipools.leb8, 0) as leb8_minipools, coalesce(minipools.leb16, 0) as leb16_minipools,
+				rocketpool_nodes.rpl_stake * rpl_price.price / 1e18 / nullif(rocketpool_nodes.eth_matched, 0) as collateralization
 			from rocketpool_nodes
 			left join (select count(*) from rocketpool_nodes) cnt(total_count) ON true
-			order by %s %s
+			left join (
+				select rocketpool_storage_address, node_address, count(*) as cnt
+				from rocketpool_fee_recipient_violations
+				group by rocketpool_storage_address, node_address
+			) violations on violations.rocketpool_storage_address = rocketpool_nodes.rocketpool_storage_address and violations.node_address = rocketpool_nodes.address
+			left join (
+				select rocketpool_storage_address, node_address,
+					count(*) filter (where node_deposit_balance = 8e18) as leb8,
+					count(*) filter (where node_deposit_balance = 16e18) as leb16
+				from rocketpool_minipools
+				where status not in ('Withdrawable', 'Dissolved')
+				group by rocketpool_storage_address, node_address
+			) minipools on minipools.rocketpool_storage_address = rocketpool_nodes.rocketpool_storage_address and minipools.node_address = rocketpool_nodes.address
+			left join (
+				select rpl_price from rocketpool_odao_consensus where kind = 'prices' and rpl_price is not null order by reference_block desc limit 1
+			) rpl_price(price) on true
+			order by %s %s nulls last
 			limit $1
 			offset $2`, orderBy, orderDir), length, start)
 		if err != nil {
-			logger.Errorf("error getting rocketpool-nodes from db: %v", err)
+			requestLogger(r).Errorf("error getting rocketpool-nodes from db: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 	} else {
-		err = db.DB.Select(&dbResult, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &dbResult, fmt.Sprintf(`
 			with matched_nodes as (
 				select address from rocketpool_nodes where encode(address::bytea,'hex') like $3
 			)
-			select rocketpool_nodes.*, cnt.total_count
+			select rocketpool_nodes.*, cnt.total_count, coalesce(violations.cnt, 0) as fee_recipient_violations,
+				coalesce(minipools.leb8, 0) as leb8_minipools, coalesce(minipools.leb16, 0) as leb16_minipools,
+				rocketpool_nodes.rpl_stake * rpl_price.price / 1e18 / nullif(rocketpool_nodes.eth_matched, 0) as collateralization
 			from rocketpool_nodes
 			inner join matched_nodes on matched_nodes.address = rocketpool_nodes.address
 			left join (select count(*) from rocketpool_nodes) cnt(total_count) ON true
-			order by %s %s
+			left join (
+				select rocketpool_storage_address, node_address, count(*) as cnt
+				from rocketpool_fee_recipient_violations
+				group by rocketpool_storage_address, node_address
+			) violations on violations.rocketpool_storage_address = rocketpool_nodes.rocketpool_storage_address and violations.node_address = rocketpool_nodes.address
+			left join (
+				select rocketpool_storage_address, node_address,
+					count(*) filter (where node_deposit_balance = 8e18) as leb8,
+					count(*) filter (where node_deposit_balance = 16e18) as leb16
+				from rocketpool_minipools
+				where status not in ('Withdrawable', 'Dissolved')
+				group by rocketpool_storage_address, node_address
+			) minipools on minipools.rocketpool_storage_address = rocketpool_nodes.rocketpool_storage_address and minipools.node_address = rocketpool_nodes.address
+			left join (
+				select rpl_price from rocketpool_odao_consensus where kind = 'prices' and rpl_price is not null order by reference_block desc limit 1
+			) rpl_price(price) on true
+			order by %s %s nulls last
 			limit $1
 			offset $2`, orderBy, orderDir), length, start, search+"%")
 		if err != nil {
-			logger.Errorf("error getting rocketpool-nodes from db (with search: %v): %v", search, err)
+			requestLogger(r).Errorf("error getting rocketpool-nodes from db (with search: %v): %v", search, err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -259,6 +302,17 @@ func PoolsRocketpoolDataNodes(w http.ResponseWriter, r *http.Request) {
 		entry = append(entry, row.RPLStake)
 		entry = append(entry, row.MinRPLStake)
 		entry = append(entry, row.MaxRPLStake)
+		if len(row.FeeDistributorAddress) > 0 {
+			entry = append(entry, row.SmoothingPoolOptedIn)
+		} else {
+			entry = append(entry, nil)
+		}
+		entry = append(entry, row.FeeRecipientViolations)
+		entry = append(entry, row.DepositCredit)
+		entry = append(entry, row.ETHMatched)
+		entry = append(entry, row.ETHMatchedLimit)
+		entry = append(entry, []uint64{row.LEB8Minipools, row.LEB16Minipools})
+		entry = append(entry, row.Collateralization)
 		tableData = append(tableData, entry)
 	}
 
@@ -271,7 +325,7 @@ func PoolsRocketpoolDataNodes(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -282,19 +336,19 @@ func PoolsRocketpoolDataDAOProposals(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -329,7 +383,7 @@ func PoolsRocketpoolDataDAOProposals(w http.ResponseWriter, r *http.Request) {
 	recordsFiltered := uint64(0)
 	var dbResult []types.RocketpoolPageDataDAOProposal
 	if search == "" {
-		err = db.DB.Select(&dbResult, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &dbResult, fmt.Sprintf(`
 			select rocketpool_dao_proposals.*, cnt.total_count
 			from rocketpool_dao_proposals
 			left join (select count(*) from rocketpool_dao_proposals) cnt(total_count) ON true
@@ -337,16 +391,17 @@ func PoolsRocketpoolDataDAOProposals(w http.ResponseWriter, r *http.Request) {
 			limit $1
 			offset $2`, orderBy, orderDir), length, start)
 		if err != nil {
-			logger.Errorf("error getting rocketpool-proposals from db: %v", err)
+			requestLogger(r).Errorf("error getting rocketpool-proposals from db: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 	} else {
-		err = db.DB.Select(&dbResult, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &dbResult, fmt.Sprintf(`
 			with matched_proposals as (
 				select id from rocketpool_dao_proposals where cast(id as text) like $3
 				union select id from rocketpool_dao_proposals where dao like $5
 				union select id from rocketpool_dao_proposals where message ilike $5
+				union select id from rocketpool_dao_proposals where payload_decoded ilike $5
 				union select id from rocketpool_dao_proposals where state = $3
 				union select id from rocketpool_dao_proposals where encode(proposer_address::bytea,'hex') like $4
 			)
@@ -360,7 +415,7 @@ func PoolsRocketpoolDataDAOProposals(w http.ResponseWriter, r *http.Request) {
 			limit $1
 			offset $2`, orderBy, orderDir), length, start, search, search+"%", "%"+search+"%")
 		if err != nil {
-			logger.Errorf("error getting rocketpool-proposals from db (with search: %v): %v", search, err)
+			requestLogger(r).Errorf("error getting rocketpool-proposals from db (with search: %v): %v", search, err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -390,7 +445,9 @@ func PoolsRocketpoolDataDAOProposals(w http.ResponseWriter, r *http.Request) {
 		entry = append(entry, row.MemberSupported)
 		entry = append(entry, row.IsCancelled)
 		entry = append(entry, row.IsExecuted)
-		if len(row.Payload) > 4 {
+		if row.PayloadDecoded != "" {
+			entry = append(entry, fmt.Sprintf(`<span>%v</span><i class="fa fa-copy text-muted ml-2 p-1" role="button" data-toggle="tooltip" title="Copy raw payload to clipboard" data-clipboard-text="%x"></i>`, template.HTMLEscapeString(row.PayloadDecoded), row.Payload))
+		} else if len(row.Payload) > 4 {
 			entry = append(entry, fmt.Sprintf(`<span>%x…%x<span><i class="fa fa-copy text-muted ml-2 p-1" role="button" data-toggle="tooltip" title="Copy to clipboard" data-clipboard-text="%x"></i>`, row.Payload[:2], row.Payload[len(row.Payload)-2:], row.Payload))
 			// entry = append(entry, fmt.Sprintf(`<span>%x…%x<span> <button class="btn btn-dark text-white btn-sm" type="button" data-toggle="tooltip" title="" data-clipboard-text="%x" data-original-title="Copy to clipboard"><i class="fa fa-copy"></i></button>`, row.Payload[:2], row.Payload[len(row.Payload)-2:], row.Payload))
 			// entry = append(entry, fmt.Sprintf(`<span id="rocketpool-dao-proposal-payload-%v">%x…%x</span> <button></button>`, i, row.Payload[:2], row.Payload[len(row.Payload)-2:]))
@@ -411,7 +468,7 @@ func PoolsRocketpoolDataDAOProposals(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -422,19 +479,19 @@ func PoolsRocketpoolDataDAOMembers(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -469,7 +526,7 @@ func PoolsRocketpoolDataDAOMembers(w http.ResponseWriter, r *http.Request) {
 	recordsFiltered := uint64(0)
 	var dbResult []types.RocketpoolPageDataDAOMember
 	if search == "" {
-		err = db.DB.Select(&dbResult, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &dbResult, fmt.Sprintf(`
 			select rocketpool_dao_members.*, cnt.total_count
 			from rocketpool_dao_members
 			left join (select count(*) from rocketpool_dao_members) cnt(total_count) ON true
@@ -477,12 +534,12 @@ func PoolsRocketpoolDataDAOMembers(w http.ResponseWriter, r *http.Request) {
 			limit $1
 			offset $2`, orderBy, orderDir), length, start)
 		if err != nil {
-			logger.Errorf("error getting rocketpool-members from db: %v", err)
+			requestLogger(r).Errorf("error getting rocketpool-members from db: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 	} else {
-		err = db.DB.Select(&dbResult, fmt.Sprintf(`
+		err = db.DB.SelectContext(r.Context(), &dbResult, fmt.Sprintf(`
 			with matched_members as (
 				select address from rocketpool_dao_members where encode(address::bytea,'hex') like $3
 				union select address from rocketpool_dao_members where id ilike $4
@@ -496,7 +553,7 @@ func PoolsRocketpoolDataDAOMembers(w http.ResponseWriter, r *http.Request) {
 			limit $1
 			offset $2`, orderBy, orderDir), length, start, search+"%", "%"+search+"%")
 		if err != nil {
-			logger.Errorf("error getting rocketpool-members from db (with search: %v): %v", search, err)
+			requestLogger(r).Errorf("error getting rocketpool-members from db (with search: %v): %v", search, err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -530,7 +587,7 @@ func PoolsRocketpoolDataDAOMembers(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"database/sql"
+	"eth2-exporter/db"
+	"eth2-exporter/preview"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// previewImageURL returns the absolute url of the preview card of the page, social media sites do not accept relative urls
+func previewImageURL(path string) string {
+	return fmt.Sprintf("https://%v/preview%v.png", utils.Config.Frontend.SiteDomain, path)
+}
+
+func sendPreviewCard(w http.ResponseWriter, r *http.Request, card *preview.Card) {
+	card.Site = utils.Config.Frontend.SiteDomain
+	img, err := preview.Render(card)
+	if err != nil {
+		requestLogger(r).Errorf("error rendering preview card for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(img)
+}
+
+// PreviewValidator renders the social media preview card of a validator
+func PreviewValidator(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.ParseUint(mux.Vars(r)["index"], 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	validator := struct {
+		Balance uint64 `db:"balance"`
+		Status  string `db:"status"`
+	}{}
+	err = db.DB.GetContext(r.Context(), &validator, `SELECT balance, status FROM validators WHERE validatorindex = $1`, index)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator %v for preview card: %v", index, err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	effectiveness, err := db.GetValidatorAttestationInclusionEffectiveness(r.Context(), index, int64(services.LatestEpoch())-100)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving attestation inclusion effectiveness of validator %v for preview card: %v", index, err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	var balances []float64
+	err = db.DB.SelectContext(r.Context(), &balances, `
+		SELECT end_balance::float / 1e9 FROM (
+			SELECT day, end_balance FROM validator_stats WHERE validatorindex = $1 AND end_balance IS NOT NULL ORDER BY day DESC LIMIT 30
+		) b ORDER BY day`, index)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving balance history of validator %v for preview card: %v", index, err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	sendPreviewCard(w, r, &preview.Card{
+		Title:    fmt.Sprintf("Validator %v", index),
+		Subtitle: "Balance of the last 30 days",
+		Fields: []preview.Field{
+			{Label: "Balance", Value: fmt.Sprintf("%.4f ETH", float64(validator.Balance)/1e9)},
+			{Label: "Effectiveness", Value: fmt.Sprintf("%.2f%%", effectiveness)},
+			{Label: "Status", Value: validator.Status},
+		},
+		Sparkline: balances,
+	})
+}
+
+// PreviewBlock renders the social media preview card of a block
+func PreviewBlock(w http.ResponseWriter, r *http.Request) {
+	slot, err := strconv.ParseUint(mux.Vars(r)["slot"], 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	block := struct {
+		Epoch             uint64 `db:"epoch"`
+		Proposer          uint64 `db:"proposer"`
+		Status            string `db:"status"`
+		AttestationsCount uint64 `db:"attestationscount"`
+	}{}
+	err = db.DB.GetContext(r.Context(), &block, `
+		SELECT epoch, proposer, status, attestationscount
+		FROM blocks
+		WHERE slot = $1
+		ORDER BY status = '3', status = '2'
+		LIMIT 1`, slot)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block %v for preview card: %v", slot, err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	status := "Scheduled"
+	switch block.Status {
+	case "1":
+		status = "Proposed"
+	case "2":
+		status = "Missed"
+	case "3":
+		status = "Orphaned"
+	}
+
+	sendPreviewCard(w, r, &preview.Card{
+		Title:    fmt.Sprintf("Slot %v", slot),
+		Subtitle: fmt.Sprintf("Epoch %v", block.Epoch),
+		Fields: []preview.Field{
+			{Label: "Status", Value: status},
+			{Label: "Proposer", Value: fmt.Sprintf("%v", block.Proposer)},
+			{Label: "Attestations", Value: fmt.Sprintf("%v", block.AttestationsCount)},
+		},
+	})
+}
+
+// PreviewEpoch renders the social media preview card of an epoch
+func PreviewEpoch(w http.ResponseWriter, r *http.Request) {
+	epoch, err := strconv.ParseUint(mux.Vars(r)["epoch"], 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	epochData := struct {
+		BlocksCount             uint64  `db:"blockscount"`
+		Finalized               bool    `db:"finalized"`
+		GlobalParticipationRate float64 `db:"globalparticipationrate"`
+	}{}
+	err = db.DB.GetContext(r.Context(), &epochData, `SELECT blockscount, finalized, globalparticipationrate FROM epochs WHERE epoch = $1`, epoch)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving epoch %v for preview card: %v", epoch, err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	var participation []float64
+	err = db.DB.SelectContext(r.Context(), &participation, `
+		SELECT globalparticipationrate FROM epochs WHERE epoch > $1 AND epoch <= $2 ORDER BY epoch`, int64(epoch)-30, epoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving participation history of epoch %v for preview card: %v", epoch, err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	finalized := "No"
+	if epochData.Finalized {
+		finalized = "Yes"
+	}
+
+	sendPreviewCard(w, r, &preview.Card{
+		Title:    fmt.Sprintf("Epoch %v", epoch),
+		Subtitle: "Participation of the last 30 epochs",
+		Fields: []preview.Field{
+			{Label: "Participation", Value: fmt.Sprintf("%.2f%%", epochData.GlobalParticipationRate*100)},
+			{Label: "Blocks", Value: fmt.Sprintf("%v", epochData.BlocksCount)},
+			{Label: "Finalized", Value: finalized},
+		},
+		Sparkline: participation,
+	})
+}
@@ -17,6 +17,7 @@ var pricingTemplate = template.Must(template.New("pricing").Funcs(utils.GetTempl
 	"templates/layout.html",
 	"templates/payment/pricing.html",
 	"templates/svg/pricing.html",
+	"templates/components/captcha.html",
 ))
 
 var mobilePricingTemplate = template.Must(template.New("mobilepricing").Funcs(utils.GetTemplateFuncs()).ParseFiles(
@@ -43,21 +44,21 @@ func Pricing(w http.ResponseWriter, r *http.Request) {
 	data := InitPageData(w, r, "pricing", "/pricing", "API Pricing")
 
 	pageData := &types.ApiPricing{}
-	pageData.RecaptchaKey = utils.Config.Frontend.RecaptchaSiteKey
+	pageData.Captcha = utils.GetCaptchaData("pricing")
 	pageData.CsrfField = csrf.TemplateField(r)
 
 	pageData.User = data.User
 	pageData.FlashMessage, err = utils.GetFlash(w, r, "pricing_flash")
 	if err != nil {
-		logger.Errorf("error retrieving flashes for advertisewithusform %v", err)
+		requestLogger(r).Errorf("error retrieving flashes for advertisewithusform %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	if data.User.Authenticated {
-		subscription, err := db.StripeGetUserSubscription(data.User.UserID, utils.GROUP_API)
+		subscription, err := db.StripeGetUserSubscription(r.Context(), data.User.UserID, utils.GROUP_API)
 		if err != nil {
-			logger.Errorf("error retrieving user subscriptions %v", err)
+			requestLogger(r).Errorf("error retrieving user subscriptions %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -73,7 +74,7 @@ func Pricing(w http.ResponseWriter, r *http.Request) {
 
 	err = pricingTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -87,29 +88,28 @@ func MobilePricing(w http.ResponseWriter, r *http.Request) {
 	data := InitPageData(w, r, "premium", "/premium", "Premium Pricing")
 
 	pageData := &types.MobilePricing{}
-	pageData.RecaptchaKey = utils.Config.Frontend.RecaptchaSiteKey
 	pageData.CsrfField = csrf.TemplateField(r)
 
 	pageData.User = data.User
 	pageData.FlashMessage, err = utils.GetFlash(w, r, "pricing_flash")
 	if err != nil {
-		logger.Errorf("error retrieving flashes for advertisewithusform %v", err)
+		requestLogger(r).Errorf("error retrieving flashes for advertisewithusform %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	if data.User.Authenticated {
-		subscription, err := db.StripeGetUserSubscription(data.User.UserID, utils.GROUP_MOBILE)
+		subscription, err := db.StripeGetUserSubscription(r.Context(), data.User.UserID, utils.GROUP_MOBILE)
 		if err != nil {
-			logger.Errorf("error retrieving user subscriptions %v", err)
+			requestLogger(r).Errorf("error retrieving user subscriptions %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 		pageData.Subscription = subscription
 
-		premiumSubscription, err := db.GetUserPremiumSubscription(data.User.UserID)
+		premiumSubscription, err := db.GetUserPremiumSubscription(r.Context(), data.User.UserID)
 		if err != nil && err != sql.ErrNoRows {
-			logger.Errorf("error retrieving user subscriptions %v", err)
+			requestLogger(r).Errorf("error retrieving user subscriptions %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -125,7 +125,7 @@ func MobilePricing(w http.ResponseWriter, r *http.Request) {
 
 	err = mobilePricingTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -135,28 +135,19 @@ func MobilePricing(w http.ResponseWriter, r *http.Request) {
 func PricingPost(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		utils.SetFlash(w, r, "pricing_flash", "Error: invalid form submitted")
-		logger.Errorf("error parsing pricing request form for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error parsing pricing request form for %v route: %v", r.URL.String(), err)
 		http.Redirect(w, r, "/pricing", http.StatusSeeOther)
 		return
 	}
 
-	if len(utils.Config.Frontend.RecaptchaSecretKey) > 0 && len(utils.Config.Frontend.RecaptchaSiteKey) > 0 {
-		if len(r.FormValue("g-recaptcha-response")) == 0 {
-			utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
-			logger.Errorf("error no recaptca response present %v route: %v", r.URL.String(), r.FormValue("g-recaptcha-response"))
-			http.Redirect(w, r, "/pricing", http.StatusSeeOther)
-			return
-		}
-
-		valid, err := utils.ValidateReCAPTCHA(r.FormValue("g-recaptcha-response"))
-		if err != nil || !valid {
-			utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
-			logger.Errorf("error validating recaptcha %v route: %v", r.URL.String(), err)
-			http.Redirect(w, r, "/pricing", http.StatusSeeOther)
-			return
-		}
+	err = utils.ValidateCaptcha(r, "pricing")
+	if err != nil {
+		utils.SetFlash(w, r, "pricing_flash", "Error: Failed to create request")
+		requestLogger(r).Errorf("error validating captcha %v route: %v", r.URL.String(), err)
+		http.Redirect(w, r, "/pricing", http.StatusSeeOther)
+		return
 	}
 
 	name := r.FormValue("name")
@@ -178,7 +169,7 @@ func PricingPost(w http.ResponseWriter, r *http.Request) {
 
 	err = mail.SendMail("support@beaconcha.in", "New API usage inquiry", msg, []types.EmailAttachment{})
 	if err != nil {
-		logger.Errorf("error sending ad form: %v", err)
+		requestLogger(r).Errorf("error sending ad form: %v", err)
 		utils.SetFlash(w, r, "pricing_flash", "Error: unable to submit api request")
 		http.Redirect(w, r, "/pricing", http.StatusSeeOther)
 		return
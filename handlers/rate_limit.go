@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter counts the requests per key (e.g. an ip-address or api key) in fixed windows
+type rateLimiter struct {
+	limit       uint64
+	window      time.Duration
+	requests    map[string]uint64
+	windowStart time.Time
+	mux         sync.Mutex
+}
+
+func newRateLimiter(limit uint64, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:       limit,
+		window:      window,
+		requests:    map[string]uint64{},
+		windowStart: time.Now(),
+	}
+}
+
+// limited counts the request of the key and returns the time until the next window if the key exceeded the limit
+func (l *rateLimiter) limited(key string) (bool, time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.windowStart) >= l.window {
+		l.requests = map[string]uint64{}
+		l.windowStart = now
+	}
+
+	l.requests[key]++
+	if l.requests[key] > l.limit {
+		return true, l.windowStart.Add(l.window).Sub(now)
+	}
+	return false, 0
+}
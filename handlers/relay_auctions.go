@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// relayAuctionsMaxSlots is the maximum number of slots the auctions can be requested for at once
+const relayAuctionsMaxSlots = 100
+
+// ApiRelayAuctions godoc
+// @Summary Get the execution payload auctions of a range of slots over the indexed mev-boost relays. The bids are the highest bids of the builders received in time, the winning bid is the payload of the canonical block a relay delivered to the proposer and the second bid the highest bid of another builder. The value left on the table is the highest bid minus the value the proposer received. Values are in wei.
+// @Tags Network
+// @Produce  json
+// @Param  start_slot query int false "First slot (default: 99 slots before end_slot)"
+// @Param  end_slot query int false "Last slot (default: latest slot)"
+// @Success 200 {object} types.ApiResponse{data=[]types.RelayAuction}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/relays/auctions [get]
+func ApiRelayAuctions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	endSlot := services.LatestSlot()
+	if q.Get("end_slot") != "" {
+		s, err := strconv.ParseUint(q.Get("end_slot"), 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid end_slot provided")
+			return
+		}
+		endSlot = s
+	}
+
+	startSlot := uint64(0)
+	if endSlot >= relayAuctionsMaxSlots {
+		startSlot = endSlot - relayAuctionsMaxSlots + 1
+	}
+	if q.Get("start_slot") != "" {
+		s, err := strconv.ParseUint(q.Get("start_slot"), 10, 64)
+		if err != nil || s > endSlot {
+			sendErrorResponse(j, r.URL.String(), "invalid start_slot provided")
+			return
+		}
+		startSlot = s
+	}
+	if endSlot-startSlot >= relayAuctionsMaxSlots {
+		sendErrorResponse(j, r.URL.String(), "only a maximum of 100 slots can be requested")
+		return
+	}
+
+	auctions, err := db.GetRelayAuctions(r.Context(), startSlot, endSlot)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving relay auctions for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(auctions))
+	for i, a := range auctions {
+		data[i] = a
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiRelayAuctionStats godoc
+// @Summary Get the daily stats of the execution payload auctions over the indexed mev-boost relays: the number of auctions and of canonical blocks delivered by a relay, the average winning and second bid of these blocks and the value left on the table by the proposers. Values are in wei.
+// @Tags Network
+// @Produce  json
+// @Param  start_day query int false "First day since genesis (default: 29 days before end_day)"
+// @Param  end_day query int false "Last day since genesis (default: current day)"
+// @Success 200 {object} types.ApiResponse{data=[]types.RelayAuctionDayStats}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/relays/daily [get]
+func ApiRelayAuctionStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	endDay := services.LatestEpoch() / utils.EpochsPerDay()
+	if q.Get("end_day") != "" {
+		d, err := strconv.ParseUint(q.Get("end_day"), 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid end_day provided")
+			return
+		}
+		endDay = d
+	}
+
+	startDay := uint64(0)
+	if endDay >= 30 {
+		startDay = endDay - 29
+	}
+	if q.Get("start_day") != "" {
+		d, err := strconv.ParseUint(q.Get("start_day"), 10, 64)
+		if err != nil || d > endDay {
+			sendErrorResponse(j, r.URL.String(), "invalid start_day provided")
+			return
+		}
+		startDay = d
+	}
+	if endDay-startDay > 365 {
+		sendErrorResponse(j, r.URL.String(), "only a maximum of 365 days can be requested")
+		return
+	}
+
+	stats, err := db.GetDailyRelayAuctionStats(r.Context(), startDay, endDay, utils.EpochsPerDay()*utils.Config.Chain.SlotsPerEpoch)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving relay auction stats for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiBuilderMarketShare godoc
+// @Summary Get the share of the builders of the canonical blocks delivered by the indexed mev-boost relays in the last days
+// @Tags Network
+// @Produce  json
+// @Param  days query int false "Number of days (default: 7, maximum: 365)"
+// @Success 200 {object} types.ApiResponse{data=[]types.BuilderMarketShare}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/relays/builders [get]
+func ApiBuilderMarketShare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	days := uint64(7)
+	if r.URL.Query().Get("days") != "" {
+		d, err := strconv.ParseUint(r.URL.Query().Get("days"), 10, 64)
+		if err != nil || d == 0 || d > 365 {
+			sendErrorResponse(j, r.URL.String(), "invalid days provided")
+			return
+		}
+		days = d
+	}
+
+	endSlot := services.LatestSlot()
+	startSlot := uint64(0)
+	if slots := days * utils.EpochsPerDay() * utils.Config.Chain.SlotsPerEpoch; endSlot >= slots {
+		startSlot = endSlot - slots + 1
+	}
+
+	shares, err := db.GetBuilderMarketShare(r.Context(), startSlot, endSlot)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving builder market share for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(shares))
+	for i, s := range shares {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiValidatorRelayAuctions godoc
+// @Summary Get the sums of the execution payload auctions of the blocks proposed by up to 100 validators: the number of blocks and of blocks delivered by a mev-boost relay, the value the validator received and the value left on the table, the highest bid of the auction minus the received value. Values are in wei.
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse{data=[]types.ProposerAuctionStats}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/auctions [get]
+func ApiValidatorRelayAuctions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	indices, pubkeys, err := parseApiValidatorParam(mux.Vars(r)["indexOrPubkey"], getUserPremium(r).MaxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	stats, err := db.GetProposerAuctionStats(r.Context(), indices, pubkeys)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving relay auctions for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	data := make([]interface{}, len(stats))
+	for i, s := range stats {
+		data[i] = s
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
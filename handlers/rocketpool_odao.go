@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"html/template"
+	"net/http"
+)
+
+var poolsRocketpoolODAOTemplate = template.Must(template.New("rocketpool").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/pools_rocketpool_odao.html"))
+
+// rocketpoolODAORounds is the number of recent rounds the missed submissions of the oDAO members are counted over
+const rocketpoolODAORounds = 30
+
+// PoolsRocketpoolODAO returns the submission health of the rocketpool oDAO using a go template
+func PoolsRocketpoolODAO(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "pools/rocketpool", "/pools/rocketpool/odao", "Rocketpool oDAO Health")
+
+	members, err := db.GetRocketpoolODAOMembersHealth(r.Context(), rocketpoolODAORounds)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving rocketpool oDAO member health: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	pricesRounds, err := db.GetRocketpoolODAORounds(r.Context(), "prices", rocketpoolODAORounds)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving rocketpool oDAO price rounds: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	balancesRounds, err := db.GetRocketpoolODAORounds(r.Context(), "balances", rocketpoolODAORounds)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving rocketpool oDAO balances rounds: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data.Data = &types.RocketpoolODAOPageData{
+		Rounds:         rocketpoolODAORounds,
+		Members:        members,
+		PricesRounds:   pricesRounds,
+		BalancesRounds: balancesRounds,
+	}
+
+	err = poolsRocketpoolODAOTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
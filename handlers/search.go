@@ -45,7 +45,7 @@ func Search(w http.ResponseWriter, r *http.Request) {
 
 		err := searchNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -61,7 +61,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 	search := vars["search"]
 	search = strings.Replace(search, "0x", "", -1)
 
-	logger := logger.WithField("searchType", searchType)
+	logger := requestLogger(r).WithField("searchType", searchType)
 
 	var err error
 	var result interface{}
@@ -69,14 +69,14 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 	switch searchType {
 	case "blocks":
 		result = &types.SearchAheadBlocksResult{}
-		err = db.DB.Select(result, `
+		err = db.DB.SelectContext(r.Context(), result, `
 			SELECT slot, ENCODE(blockroot::bytea, 'hex') AS blockroot 
 			FROM blocks 
 			WHERE CAST(slot AS text) LIKE $1 OR ENCODE(blockroot::bytea, 'hex') LIKE $1
 			ORDER BY slot LIMIT 10`, search+"%")
 	case "graffiti":
 		graffiti := &types.SearchAheadGraffitiResult{}
-		err = db.DB.Select(graffiti, `
+		err = db.DB.SelectContext(r.Context(), graffiti, `
 			SELECT graffiti, count(*)
 			FROM blocks
 			WHERE graffiti_text ILIKE $1
@@ -91,11 +91,11 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 		result = graffiti
 	case "epochs":
 		result = &types.SearchAheadEpochsResult{}
-		err = db.DB.Select(result, "SELECT epoch FROM epochs WHERE CAST(epoch AS text) LIKE $1 ORDER BY epoch LIMIT 10", search+"%")
+		err = db.DB.SelectContext(r.Context(), result, "SELECT epoch FROM epochs WHERE CAST(epoch AS text) LIKE $1 ORDER BY epoch LIMIT 10", search+"%")
 	case "validators":
 		// find all validators that have a index, publickey or name like the search-query
 		result = &types.SearchAheadValidatorsResult{}
-		err = db.DB.Select(result, `
+		err = db.DB.SelectContext(r.Context(), result, `
 			SELECT
 				validatorindex AS index,
 				pubkeyhex AS pubkey
@@ -107,7 +107,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 			ORDER BY index LIMIT 10`, search+"%", "%"+search+"%")
 	case "eth1_addresses":
 		result = &types.SearchAheadEth1Result{}
-		err = db.DB.Select(result, `
+		err = db.DB.SelectContext(r.Context(), result, `
 			SELECT DISTINCT ENCODE(from_address::bytea, 'hex') as from_address
 			FROM eth1_deposits
 			WHERE ENCODE(from_address::bytea, 'hex') LIKE LOWER($1)
@@ -115,7 +115,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 	case "indexed_validators":
 		// find all validators that have a publickey or index like the search-query
 		result = &types.SearchAheadValidatorsResult{}
-		err = db.DB.Select(result, `
+		err = db.DB.SelectContext(r.Context(), result, `
 			SELECT validatorindex AS index, pubkeyhex AS pubkey
 			FROM validators
 			LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
@@ -130,7 +130,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 			ValidatorIndices pq.Int64Array `db:"validatorindices" json:"validator_indices"`
 			Count            uint64        `db:"count" json:"-"`
 		}{}
-		err = db.DB.Select(result, `
+		err = db.DB.SelectContext(r.Context(), result, `
 			SELECT from_address, COUNT(*), ARRAY_AGG(validatorindex) validatorindices FROM (
 				SELECT 
 					DISTINCT ON(validatorindex) validatorindex,
@@ -151,7 +151,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 			ValidatorIndices pq.Int64Array `db:"validatorindices" json:"validator_indices"`
 			Count            uint64        `db:"count" json:"-"`
 		}{}
-		err = db.DB.Select(&res, `
+		err = db.DB.SelectContext(r.Context(), &res, `
 			SELECT graffiti, COUNT(*), ARRAY_AGG(validatorindex) validatorindices FROM (
 				SELECT 
 					DISTINCT ON(validatorindex) validatorindex,
@@ -178,7 +178,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 			ValidatorIndices pq.Int64Array `db:"validatorindices" json:"validator_indices"`
 			Count            uint64        `db:"count" json:"-"`
 		}{}
-		err = db.DB.Select(&res, `
+		err = db.DB.SelectContext(r.Context(), &res, `
 			SELECT name, COUNT(*), ARRAY_AGG(validatorindex) validatorindices FROM (
 				SELECT
 					validatorindex,
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// sitemapPageSize is the maximum number of urls of a sitemap according to the sitemap protocol
+const sitemapPageSize = 50000
+
+type sitemapIndex struct {
+	XMLName  xml.Name           `xml:"sitemapindex"`
+	Xmlns    string             `xml:"xmlns,attr"`
+	Sitemaps []sitemapIndexItem `xml:"sitemap"`
+}
+
+type sitemapIndexItem struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+}
+
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// Robots returns the robots.txt that points crawlers to the sitemap index
+func Robots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintf(w, "User-agent: *\nAllow: /\n")
+	if utils.Config.Frontend.Crawlers.Enabled {
+		fmt.Fprintf(w, "\nSitemap: https://%v/sitemap.xml\n", utils.Config.Frontend.SiteDomain)
+	}
+}
+
+// SitemapIndex returns the index of the paginated sitemaps of the validators and the recent blocks
+func SitemapIndex(w http.ResponseWriter, r *http.Request) {
+	sitemap := services.GetSitemap()
+	if sitemap == nil {
+		http.Error(w, "Sitemap not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	lastMod := sitemap.Ts.UTC().Format(time.RFC3339)
+	index := sitemapIndex{Xmlns: sitemapXmlns}
+	for page := uint64(1); (page-1)*sitemapPageSize < sitemap.ValidatorCount; page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapIndexItem{
+			Loc:     fmt.Sprintf("https://%v/sitemaps/validators-%v.xml", utils.Config.Frontend.SiteDomain, page),
+			LastMod: lastMod,
+		})
+	}
+	for page := 1; (page-1)*sitemapPageSize < len(sitemap.BlockSlots); page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapIndexItem{
+			Loc:     fmt.Sprintf("https://%v/sitemaps/blocks-%v.xml", utils.Config.Frontend.SiteDomain, page),
+			LastMod: lastMod,
+		})
+	}
+
+	sendSitemapXML(w, r, index)
+}
+
+// Sitemap returns a page of the sitemap of the validators or of the recent blocks
+func Sitemap(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	page, err := strconv.ParseUint(vars["page"], 10, 64)
+	if err != nil || page == 0 {
+		http.Error(w, "Sitemap not found", http.StatusNotFound)
+		return
+	}
+	sitemap := services.GetSitemap()
+	if sitemap == nil {
+		http.Error(w, "Sitemap not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	start := (page - 1) * sitemapPageSize
+	urls := sitemapURLSet{Xmlns: sitemapXmlns}
+	switch vars["sitemap"] {
+	case "validators":
+		if start >= sitemap.ValidatorCount {
+			http.Error(w, "Sitemap not found", http.StatusNotFound)
+			return
+		}
+		// the balances of the validators change every epoch
+		lastMod := utils.EpochToTime(services.LatestEpoch()).UTC().Format(time.RFC3339)
+		for i := start; i < sitemap.ValidatorCount && i < start+sitemapPageSize; i++ {
+			urls.URLs = append(urls.URLs, sitemapURL{
+				Loc:        fmt.Sprintf("https://%v/validator/%v", utils.Config.Frontend.SiteDomain, i),
+				LastMod:    lastMod,
+				ChangeFreq: "hourly",
+			})
+		}
+	case "blocks":
+		if start >= uint64(len(sitemap.BlockSlots)) {
+			http.Error(w, "Sitemap not found", http.StatusNotFound)
+			return
+		}
+		for i := start; i < uint64(len(sitemap.BlockSlots)) && i < start+sitemapPageSize; i++ {
+			slot := sitemap.BlockSlots[i]
+			urls.URLs = append(urls.URLs, sitemapURL{
+				Loc:     fmt.Sprintf("https://%v/block/%v", utils.Config.Frontend.SiteDomain, slot),
+				LastMod: utils.SlotToTime(slot).UTC().Format(time.RFC3339),
+			})
+		}
+	default:
+		http.Error(w, "Sitemap not found", http.StatusNotFound)
+		return
+	}
+
+	sendSitemapXML(w, r, urls)
+}
+
+func sendSitemapXML(w http.ResponseWriter, r *http.Request, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(xml.Header))
+	err := xml.NewEncoder(w).Encode(v)
+	if err != nil {
+		requestLogger(r).Errorf("error encoding sitemap for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// slashingSimulatorMaxScenarios is the maximum number of scenarios of a slashing simulation
+const slashingSimulatorMaxScenarios = 20
+
+// ApiSlashingSimulator godoc
+// @Summary Simulate the financial impact of a slashing at the current network state for risk analysis: the initial penalty, the correlation penalty if a number of validators is slashed simultaneously, the penalties until the slashed validator is withdrawable and the attestation rewards it misses in that period, together with the reward of the whistleblower. All amounts are in Gwei.
+// @Tags Network
+// @Produce  json
+// @Param  effective_balance query number false "Effective balance of the slashed validator in ETH, defaults to 32"
+// @Param  scenarios query string false "Comma separated numbers of simultaneously slashed validators with the same effective balance (including the simulated one), defaults to 1, 100, 1000, 10000 and 1%, 10% and a third of the active validators"
+// @Success 200 {object} types.ApiResponse{data=types.SlashingSimulation}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/slashing/simulator [get]
+func ApiSlashingSimulator(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	maxEffectiveBalance := utils.Config.Chain.MaxEffectiveBalanceElectra
+	if maxEffectiveBalance == 0 {
+		maxEffectiveBalance = utils.Config.Chain.MaxEffectiveBalance
+	}
+	effectiveBalance := utils.Config.Chain.MaxEffectiveBalance
+	if utils.Config.Chain.MinActivationBalance != 0 {
+		effectiveBalance = utils.Config.Chain.MinActivationBalance
+	}
+	if b := q.Get("effective_balance"); b != "" {
+		eth, err := strconv.ParseFloat(b, 64)
+		if err != nil || !(eth > 0) || math.IsInf(eth, 0) || eth*1e9 > float64(maxEffectiveBalance) {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("effective_balance must be between 0 and %v ETH", maxEffectiveBalance/1e9))
+			return
+		}
+		// the effective balance is a multiple of the increment
+		increment := utils.Config.Chain.EffectiveBalanceIncrement
+		if increment == 0 {
+			increment = 1e9
+		}
+		effectiveBalance = uint64(eth*1e9) / increment * increment
+	}
+
+	scenarios := []uint64{}
+	if s := q.Get("scenarios"); s != "" {
+		for _, n := range strings.Split(s, ",") {
+			count, err := strconv.ParseUint(strings.TrimSpace(n), 10, 64)
+			if err != nil || count == 0 {
+				sendErrorResponse(j, r.URL.String(), fmt.Sprintf("invalid scenario %q, expected the number of slashed validators", n))
+				return
+			}
+			scenarios = append(scenarios, count)
+		}
+		if len(scenarios) > slashingSimulatorMaxScenarios {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("at most %v scenarios are supported", slashingSimulatorMaxScenarios))
+			return
+		}
+	}
+
+	simulation, err := services.SimulateSlashing(effectiveBalance, scenarios)
+	if err != nil {
+		requestLogger(r).Errorf("error simulating slashing for %v route: %v", r.URL.String(), err)
+		sendErrorResponse(j, r.URL.String(), "could not simulate the slashing")
+		return
+	}
+	sendOKResponse(j, r.URL.String(), []interface{}{simulation})
+}
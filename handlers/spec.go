@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/utils"
+	"html/template"
+	"net/http"
+)
+
+var specTemplate = template.Must(template.New("spec").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/spec.html"))
+
+// Spec will return the chain parameters the explorer runs with using a go template
+func Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "more", "/spec", "Chain Specification")
+	data.Data = utils.ChainSpec()
+
+	err := specTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiSpec godoc
+// @Summary Get the chain parameters the explorer runs with: network, genesis and, per fork, the fork epoch and the values of its preset named like in the preset file
+// @Tags Network
+// @Produce  json
+// @Success 200 {object} types.ApiResponse{data=types.ChainSpec}
+// @Router /api/v1/spec [get]
+func ApiSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	sendOKResponse(j, r.URL.String(), []interface{}{utils.ChainSpec()})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/events"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// sseStreamDuration is how long a stream stays open, it has to end before the write timeout of the http server,
+	// browsers reconnect right away and resume at the Last-Event-ID
+	sseStreamDuration = time.Second * 10
+	// sseRetryMs is the reconnection delay sent to the clients
+	sseRetryMs = 500
+	// sseHeartbeatInterval is the interval comments are sent at so proxies do not close idle streams
+	sseHeartbeatInterval = time.Second * 5
+	// sseMaxClients limits the number of concurrently open streams
+	sseMaxClients = 10000
+)
+
+// SseHeadEvent is the data of the head events of /sse/head
+type SseHeadEvent struct {
+	Slot               uint64 `json:"slot"`
+	Epoch              uint64 `json:"epoch"`
+	BlockRoot          string `json:"block_root"`
+	FinalizedEpoch     uint64 `json:"finalized_epoch"`
+	FinalizedBlockRoot string `json:"finalized_block_root"`
+	JustifiedEpoch     uint64 `json:"justified_epoch"`
+	JustifiedBlockRoot string `json:"justified_block_root"`
+	Ts                 int64  `json:"ts"`
+}
+
+// sseHeadHub fans the head events of the event bus out to the open streams, the bus has no way to unsubscribe so all
+// streams share a single subscription
+type sseHeadHub struct {
+	mux     sync.RWMutex
+	latest  *SseHeadEvent
+	clients map[chan *SseHeadEvent]bool
+}
+
+var headHub = &sseHeadHub{clients: map[chan *SseHeadEvent]bool{}}
+var headHubOnce = &sync.Once{}
+
+func (h *sseHeadHub) run() {
+	chainEvents, err := events.Subscribe()
+	if err != nil {
+		logger.WithError(err).Errorf("error subscribing to chain events, /sse/head will not stream any heads")
+		return
+	}
+	for e := range chainEvents {
+		if e.Name != types.ChainEventHead || e.Checkpoints == nil {
+			continue
+		}
+		head := &SseHeadEvent{
+			Slot:               e.Slot,
+			Epoch:              e.Epoch,
+			BlockRoot:          fmt.Sprintf("0x%x", e.Checkpoints.HeadBlockRoot),
+			FinalizedEpoch:     e.Checkpoints.FinalizedEpoch,
+			FinalizedBlockRoot: fmt.Sprintf("0x%x", e.Checkpoints.FinalizedBlockRoot),
+			JustifiedEpoch:     e.Checkpoints.JustifiedEpoch,
+			JustifiedBlockRoot: fmt.Sprintf("0x%x", e.Checkpoints.JustifiedBlockRoot),
+			Ts:                 e.Ts,
+		}
+
+		h.mux.Lock()
+		h.latest = head
+		for c := range h.clients {
+			// only the latest head matters, a client that has not consumed the previous one gets it replaced
+			select {
+			case <-c:
+			default:
+			}
+			c <- head
+		}
+		h.mux.Unlock()
+	}
+}
+
+func (h *sseHeadHub) subscribe() (chan *SseHeadEvent, *SseHeadEvent, error) {
+	headHubOnce.Do(func() {
+		go h.run()
+	})
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if len(h.clients) >= sseMaxClients {
+		return nil, nil, fmt.Errorf("too many open streams")
+	}
+	c := make(chan *SseHeadEvent, 1)
+	h.clients[c] = true
+	return c, h.latest, nil
+}
+
+func (h *sseHeadHub) unsubscribe(c chan *SseHeadEvent) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	delete(h.clients, c)
+}
+
+// SseHead streams the new heads with their finalized and justified checkpoints as server-sent events as soon as they
+// are indexed. The slot is the id of the events, the latest head is sent right away unless the client already received
+// it (Last-Event-ID). Streams are closed after a few seconds to stay within the write timeout of the server, EventSource
+// clients reconnect on their own.
+func SseHead(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c, latest, err := headHub.subscribe()
+	if err != nil {
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer headHub.unsubscribe(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disable the response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMs)
+
+	lastSlot, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	hasLastSlot := err == nil
+	send := func(head *SseHeadEvent) bool {
+		if hasLastSlot && head.Slot <= lastSlot {
+			return true
+		}
+		data, err := json.Marshal(head)
+		if err != nil {
+			requestLogger(r).Errorf("error serializing head event: %v", err)
+			return false
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: head\ndata: %s\n\n", head.Slot, data)
+		if err != nil {
+			return false
+		}
+		flusher.Flush()
+		lastSlot, hasLastSlot = head.Slot, true
+		return true
+	}
+
+	if latest != nil && !send(latest) {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	end := time.NewTimer(sseStreamDuration)
+	defer end.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-end.C:
+			return
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case head := <-c:
+			if !send(head) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"html/template"
+	"net/http"
+)
+
+var statusTemplate = template.Must(template.New("status").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/status.html"))
+
+// Status will return the status page of the explorer using a go template
+func Status(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "stats", "/status", "Explorer Status")
+	data.Data = services.LatestHealthStatus()
+
+	err := statusTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// StatusJSON returns the health status of the explorer as json, the status code is 503 if the explorer is unhealthy so it can be used by external monitors
+func StatusJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	status := services.LatestHealthStatus()
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	err := json.NewEncoder(w).Encode(status)
+	if err != nil {
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		return
+	}
+}
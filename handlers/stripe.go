@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"eth2-exporter/db"
@@ -44,7 +45,7 @@ func StripeCreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		logger.Errorf("error decoding json.NewDecoder.Decode: %v", err)
+		requestLogger(r).Errorf("error decoding json.NewDecoder.Decode: %v", err)
 		return
 	}
 	rq := "required"
@@ -53,21 +54,21 @@ func StripeCreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
 
 	if purchaseGroup == "" {
 		http.Error(w, "Error invalid price item provided. Must be the price ID of Sapphire, Emerald or Diamond", http.StatusBadRequest)
-		logger.Errorf("error invalid stripe price id provided: %v, expected one of [%v, %v, %v]", req.Price, utils.Config.Frontend.Stripe.Sapphire, utils.Config.Frontend.Stripe.Emerald, utils.Config.Frontend.Stripe.Diamond)
+		requestLogger(r).Errorf("error invalid stripe price id provided: %v, expected one of [%v, %v, %v]", req.Price, utils.Config.Frontend.Stripe.Sapphire, utils.Config.Frontend.Stripe.Emerald, utils.Config.Frontend.Stripe.Diamond)
 		return
 	}
 
 	// check if a subscription exists
-	subscription, err := db.StripeGetUserSubscription(user.UserID, purchaseGroup)
+	subscription, err := db.StripeGetUserSubscription(r.Context(), user.UserID, purchaseGroup)
 	if err != nil {
-		logger.Errorf("error retrieving user subscriptions %v", err)
+		requestLogger(r).Errorf("error retrieving user subscriptions %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	// don't let the user checkout another subscription in the same group
 	if subscription.Active != nil && *subscription.Active {
-		logger.Errorf("error there is an active subscription cannot create another one %v", err)
+		requestLogger(r).Errorf("error there is an active subscription cannot create another one %v", err)
 		w.WriteHeader(http.StatusBadRequest)
 		writeJSON(w, struct {
 			ErrorData string `json:"error"`
@@ -129,7 +130,7 @@ func StripeCreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
 
 	s, err := session.New(params)
 	if err != nil {
-		logger.WithError(err).Error("failed to create a new stripe checkout session")
+		requestLogger(r).WithError(err).Error("failed to create a new stripe checkout session")
 		w.WriteHeader(http.StatusBadRequest)
 		writeJSON(w, struct {
 			ErrorData string `json:"error"`
@@ -155,12 +156,12 @@ func StripeCustomerPortal(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		logger.WithError(err).Error("json.NewDecoder.Decode")
+		requestLogger(r).WithError(err).Error("json.NewDecoder.Decode")
 		return
 	}
 
 	var customerID string
-	err := db.FrontendDB.Get(&customerID, `
+	err := db.FrontendDB.GetContext(r.Context(), &customerID, `
 	SELECT
 		stripe_customer_id
 	FROM
@@ -170,7 +171,7 @@ func StripeCustomerPortal(w http.ResponseWriter, r *http.Request) {
 	`, user.UserID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		logger.WithError(err).Error("error could not retrieve stripe customer id")
+		requestLogger(r).WithError(err).Error("error could not retrieve stripe customer id")
 		return
 	}
 	// The URL to which the user is redirected when they are done managing
@@ -198,14 +199,14 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
-		logger.WithError(err).Error("error failed to read body for StripeWebhook")
+		requestLogger(r).WithError(err).Error("error failed to read body for StripeWebhook")
 		return
 	}
 
 	event, err := webhook.ConstructEvent(b, r.Header.Get("Stripe-Signature"), utils.Config.Frontend.Stripe.Webhook)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
-		logger.WithError(err).Error("error constructing webhook stripe signature event")
+		requestLogger(r).WithError(err).Error("error constructing webhook stripe signature event")
 		return
 	}
 
@@ -214,19 +215,19 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var customer stripe.Customer
 		err := json.Unmarshal(event.Data.Raw, &customer)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 		if customer.Email != "" {
-			err = db.StripeUpdateCustomerID(customer.Email, customer.ID)
+			err = db.StripeUpdateCustomerID(r.Context(), customer.Email, customer.ID)
 			if err != nil {
-				logger.WithError(err).Error("error could not update user with a stripe customerID ", customer.ID)
+				requestLogger(r).WithError(err).Error("error could not update user with a stripe customerID ", customer.ID)
 				http.Error(w, "error could not update user with a stripe customerID "+customer.ID+" err: "+err.Error(), 503)
 				return
 			}
 		} else {
-			logger.Error("error no email provided when creating stripe customer ", customer.ID)
+			requestLogger(r).Error("error no email provided when creating stripe customer ", customer.ID)
 			http.Error(w, "error no email provided when creating stripe customer "+customer.ID, 503)
 			return
 		}
@@ -235,20 +236,20 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var customer stripe.Customer
 		err := json.Unmarshal(event.Data.Raw, &customer)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON", err)
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON", err)
 			http.Error(w, "error parsing stripe webhook JSON", 503)
 			return
 		}
 
-		err = db.DisableAllSubscriptionsFromStripeUser(customer.ID)
+		err = db.DisableAllSubscriptionsFromStripeUser(r.Context(), customer.ID)
 		if err != nil {
-			logger.WithError(err).Error("error could not disable stripe mobile subs: " + customer.ID + "err: ")
+			requestLogger(r).WithError(err).Error("error could not disable stripe mobile subs: " + customer.ID + "err: ")
 			// log & continue anyway
 		}
 
-		err = db.StripeRemoveCustomer(customer.ID)
+		err = db.StripeRemoveCustomer(r.Context(), customer.ID)
 		if err != nil {
-			logger.WithError(err).Error("error could not delete user with customer ID: " + customer.ID + "err: ")
+			requestLogger(r).WithError(err).Error("error could not delete user with customer ID: " + customer.ID + "err: ")
 			http.Error(w, "error could not delete user with customer ID: "+customer.ID+"err: "+err.Error(), 503)
 			return
 		}
@@ -260,7 +261,7 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var session stripe.CheckoutSession
 		err := json.Unmarshal(event.Data.Raw, &session)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "error parsing stripe webhook JSON", 503)
 			return
 		}
@@ -268,12 +269,12 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		// if session.Customer.Email != "" {
 		// 	err = db.UpdateStripeCustomer(session.Customer.Email, session.Customer.ID)
 		// 	if err != nil {
-		// 		logger.WithError(err).Error("error could not update user with a stripe customerID")
+		// 		requestLogger(r).WithError(err).Error("error could not update user with a stripe customerID")
 		// 		http.Error(w, "Internal server error", 503)
 		// 		return
 		// 	}
 		// } else {
-		// 	logger.Error("the session object does not have a customer email", session, session.Customer)
+		// 	requestLogger(r).Error("the session object does not have a customer email", session, session.Customer)
 		// 	http.Error(w, "Internal server error", 503)
 		// 	return
 		// }
@@ -282,29 +283,29 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var subscription stripe.Subscription
 		err := json.Unmarshal(event.Data.Raw, &subscription)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "error parsing stripe webhook JSON", 503)
 			return
 		}
 
 		if subscription.Items == nil {
-			logger.WithError(err).Error("error creating subscription no items found", subscription)
+			requestLogger(r).WithError(err).Error("error creating subscription no items found", subscription)
 			http.Error(w, "error creating subscription no items found", 503)
 			return
 		}
 
 		if len(subscription.Items.Data) == 0 {
-			logger.WithError(err).Error("error creating subscription no items found", subscription)
+			requestLogger(r).WithError(err).Error("error creating subscription no items found", subscription)
 			http.Error(w, "error creating subscription no items found", 503)
 			return
 		}
 
 		// to handle race condition errors where subscription.updated is executed before customer.subscription.created, do nothing since it's already processed
-		_, err = db.StripeGetSubscription(subscription.ID)
+		_, err = db.StripeGetSubscription(r.Context(), subscription.ID)
 		if err == sql.ErrNoRows {
-			err = createNewStripeSubscription(subscription, event)
+			err = createNewStripeSubscription(r.Context(), subscription, event)
 			if err != nil {
-				logger.WithError(err).Error(err.Error(), event.Data.Object)
+				requestLogger(r).WithError(err).Error(err.Error(), event.Data.Object)
 				http.Error(w, "error "+err.Error()+" customer: "+subscription.Customer.ID, 503)
 				return
 			}
@@ -314,31 +315,31 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var subscription stripe.Subscription
 		err := json.Unmarshal(event.Data.Raw, &subscription)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "error parsing stripe webhook JSON", 503)
 			return
 		}
 
 		if subscription.Items == nil {
-			logger.Error("error updating subscription no items found", subscription)
+			requestLogger(r).Error("error updating subscription no items found", subscription)
 			http.Error(w, "error updating subscription no items found", 503)
 			return
 		}
 
 		if len(subscription.Items.Data) == 0 {
-			logger.Error("error updating subscription no items found", subscription)
+			requestLogger(r).Error("error updating subscription no items found", subscription)
 			http.Error(w, "error updating subscription no items found", 503)
 			return
 		}
 		priceID := subscription.Items.Data[0].Price.ID
 
-		currSub, err := db.StripeGetSubscription(subscription.ID)
+		currSub, err := db.StripeGetSubscription(r.Context(), subscription.ID)
 		if err == sql.ErrNoRows {
 			// subscription does not exist, create it
-			err = createNewStripeSubscription(subscription, event)
+			err = createNewStripeSubscription(r.Context(), subscription, event)
 			if err != nil {
-				logger.WithError(err).Error(err.Error(), event.Data.Object)
-				logger.Warn(" customer: " + subscription.Customer.ID + " | subscriptionID: " + subscription.ID + " | priceID: " + priceID)
+				requestLogger(r).WithError(err).Error(err.Error(), event.Data.Object)
+				requestLogger(r).Warn(" customer: " + subscription.Customer.ID + " | subscriptionID: " + subscription.ID + " | priceID: " + priceID)
 				http.Error(w, "error updating "+err.Error()+" customer: "+subscription.Customer.ID+" | subscriptionID: "+subscription.ID+" | priceID: "+priceID, 503)
 				return
 			}
@@ -350,30 +351,30 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		if err != nil && err != sql.ErrNoRows {
-			logger.WithError(err).Error("error getting subscription from database with id ", subscription.ID)
+			requestLogger(r).WithError(err).Error("error getting subscription from database with id ", subscription.ID)
 			http.Error(w, "error updating subscription could not get current subscription err:"+err.Error(), 503)
 		}
 
-		err = db.StripeUpdateSubscription(priceID, subscription.ID, event.Data.Raw)
+		err = db.StripeUpdateSubscription(r.Context(), priceID, subscription.ID, event.Data.Raw)
 		if err != nil {
-			logger.WithError(err).Error("error updating user subscription", subscription.ID)
+			requestLogger(r).WithError(err).Error("error updating user subscription", subscription.ID)
 			http.Error(w, "error updating user subscription, customer: "+subscription.Customer.ID, 503)
 			return
 		}
 
 		if utils.GetPurchaseGroup(priceID) == utils.GROUP_MOBILE {
-			err := db.ChangeProductIDFromStripe(subscription.ID, getCleanProductID(priceID))
+			err := db.ChangeProductIDFromStripe(r.Context(), subscription.ID, getCleanProductID(priceID))
 			if err != nil {
-				logger.WithError(err).Error("error updating stripe mobile subscription", subscription.ID)
+				requestLogger(r).WithError(err).Error("error updating stripe mobile subscription", subscription.ID)
 				http.Error(w, "error updating stripe mobile subscription customer: "+subscription.Customer.ID, 503)
 				return
 			}
 		}
 
 		if currSub.PriceID != nil && *currSub.PriceID != priceID && utils.GetPurchaseGroup(*currSub.PriceID) == utils.GetPurchaseGroup(priceID) {
-			email, err := db.StripeGetCustomerEmail(subscription.Customer.ID)
+			email, err := db.StripeGetCustomerEmail(r.Context(), subscription.Customer.ID)
 			if err != nil {
-				logger.WithError(err).Error("error retrieving customer email for subscription ", subscription.ID)
+				requestLogger(r).WithError(err).Error("error retrieving customer email for subscription ", subscription.ID)
 				http.Error(w, "error retrieving customer email for subscription err:"+err.Error(), 503)
 			}
 			emailCustomerAboutPlanChange(email, priceID)
@@ -384,28 +385,28 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var subscription stripe.Subscription
 		err := json.Unmarshal(event.Data.Raw, &subscription)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "error parsing stripe webhook JSON", 503)
 			return
 		}
 
-		err = db.StripeUpdateSubscriptionStatus(subscription.ID, false, &event.Data.Raw)
+		err = db.StripeUpdateSubscriptionStatus(r.Context(), subscription.ID, false, &event.Data.Raw)
 		if err != nil {
-			logger.WithError(err).Error("error while deactivating subscription", event.Data.Object)
+			requestLogger(r).WithError(err).Error("error while deactivating subscription", event.Data.Object)
 			http.Error(w, "error while deactivating subscription, customer:"+subscription.Customer.ID, 503)
 			return
 		}
 
 		if utils.GetPurchaseGroup(subscription.Items.Data[0].Price.ID) == utils.GROUP_MOBILE {
-			appSubID, err := db.GetUserSubscriptionIDByStripe(subscription.ID)
+			appSubID, err := db.GetUserSubscriptionIDByStripe(r.Context(), subscription.ID)
 			if err != nil {
-				logger.WithError(err).Error("error updating stripe mobile subscription, no users_app_subs id found for subscription id", subscription.ID)
+				requestLogger(r).WithError(err).Error("error updating stripe mobile subscription, no users_app_subs id found for subscription id", subscription.ID)
 				http.Error(w, "error updating stripe mobile subscription, no users_app_subs id  found for subscription id, customer: "+subscription.Customer.ID, 503)
 				return
 			}
 			now := time.Now()
 			nowTs := now.Unix()
-			db.UpdateUserSubscription(appSubID, false, nowTs, "user_canceled")
+			db.UpdateUserSubscription(r.Context(), appSubID, false, nowTs, "user_canceled")
 		}
 
 	// inform the user when the subscription will expire
@@ -416,44 +417,44 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var invoice stripe.Invoice
 		err := json.Unmarshal(event.Data.Raw, &invoice)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 
 		if invoice.Lines == nil {
-			logger.Warn("warning processing invoice and updating subscription no items found", invoice.ID)
+			requestLogger(r).Warn("warning processing invoice and updating subscription no items found", invoice.ID)
 			// http.Error(w, "error processing invoice and updating subscription no items found", 503)
 			return
 		}
 
 		if len(invoice.Lines.Data) == 0 {
-			logger.Warn("warning processing invoice and updating subscription no items found", invoice.ID)
+			requestLogger(r).Warn("warning processing invoice and updating subscription no items found", invoice.ID)
 			// http.Error(w, "error processing invoice and updating subscription no items found", 503)
 			return
 		}
 
 		if len(invoice.Lines.Data[0].Subscription) == 0 {
-			logger.Warn("error processing invoice and updating subscription no items found", invoice.ID)
+			requestLogger(r).Warn("error processing invoice and updating subscription no items found", invoice.ID)
 			// http.Error(w, "error processing invoice and updating subscription line items does not include a subscription", 503)
 			return
 		}
 
-		err = db.StripeUpdateSubscriptionStatus(invoice.Lines.Data[0].Subscription, true, nil)
+		err = db.StripeUpdateSubscriptionStatus(r.Context(), invoice.Lines.Data[0].Subscription, true, nil)
 		if err != nil {
-			logger.WithError(err).Error("error processing invoice failed to activate subscription for customer", invoice.Customer.ID)
+			requestLogger(r).WithError(err).Error("error processing invoice failed to activate subscription for customer", invoice.Customer.ID)
 			http.Error(w, "error proccesing invoice failed to activate subscription for customer", 503)
 			return
 		}
 
 		if utils.GetPurchaseGroup(invoice.Lines.Data[0].Price.ID) == utils.GROUP_MOBILE {
-			appSubID, err := db.GetUserSubscriptionIDByStripe(invoice.Lines.Data[0].Subscription)
+			appSubID, err := db.GetUserSubscriptionIDByStripe(r.Context(), invoice.Lines.Data[0].Subscription)
 			if err != nil {
-				logger.WithError(err).Error("error updating stripe mobile subscription (paid), no users_app_subs id found for subscription id", invoice.Lines.Data[0].Subscription)
+				requestLogger(r).WithError(err).Error("error updating stripe mobile subscription (paid), no users_app_subs id found for subscription id", invoice.Lines.Data[0].Subscription)
 				http.Error(w, "error updating stripe mobile subscription, no users_app_subs id  found for subscription id, customer: "+invoice.Customer.ID, 503)
 				return
 			}
-			db.UpdateUserSubscription(appSubID, true, 0, "")
+			db.UpdateUserSubscription(r.Context(), appSubID, true, 0, "")
 		}
 
 	case "invoice.payment_failed":
@@ -463,7 +464,7 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 		var invoice stripe.Invoice
 		err := json.Unmarshal(event.Data.Raw, &invoice)
 		if err != nil {
-			logger.WithError(err).Error("error parsing stripe webhook JSON")
+			requestLogger(r).WithError(err).Error("error parsing stripe webhook JSON")
 			http.Error(w, "error parsing stripe webhook JSON", 503)
 			return
 		}
@@ -474,14 +475,14 @@ func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func createNewStripeSubscription(subscription stripe.Subscription, event stripe.Event) error {
-	err := db.StripeCreateSubscription(subscription.Customer.ID, subscription.Items.Data[0].Price.ID, subscription.ID, event.Data.Raw)
+func createNewStripeSubscription(ctx context.Context, subscription stripe.Subscription, event stripe.Event) error {
+	err := db.StripeCreateSubscription(ctx, subscription.Customer.ID, subscription.Items.Data[0].Price.ID, subscription.ID, event.Data.Raw)
 	if err != nil {
 		return err
 	}
 
 	if utils.GetPurchaseGroup(subscription.Items.Data[0].Price.ID) == utils.GROUP_MOBILE {
-		userID, err := db.StripeGetCustomerUserId(subscription.Customer.ID)
+		userID, err := db.StripeGetCustomerUserId(ctx, subscription.Customer.ID)
 		if err != nil {
 			return err
 		}
@@ -496,7 +497,7 @@ func createNewStripeSubscription(subscription stripe.Subscription, event stripe.
 			},
 			Valid: false,
 		}
-		err = db.InsertMobileSubscription(userID, details, details.Transaction.Type, details.Transaction.Receipt, 0, "", subscription.ID)
+		err = db.InsertMobileSubscription(ctx, userID, details, details.Transaction.Type, details.Transaction.Receipt, 0, "", subscription.ID)
 		if err != nil {
 			return err
 		}
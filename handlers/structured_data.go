@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+)
+
+// setStructuredData describes the entity of a page for search engines, the description replaces the generic meta
+// description and the JSON-LD links the page to the list of the entities it belongs to
+func setStructuredData(data *types.PageData, name, identifier, description, listName, listPath string, modified time.Time) {
+	siteURL := fmt.Sprintf("https://%v", utils.Config.Frontend.SiteDomain)
+	data.Meta.Description = description
+	sd := &types.StructuredData{
+		Context:     "https://schema.org",
+		Type:        "WebPage",
+		Name:        name,
+		Description: description,
+		URL:         siteURL + data.Meta.Path,
+		About: types.StructuredDataEntity{
+			Type:        "Thing",
+			Name:        name,
+			Identifier:  identifier,
+			Description: description,
+		},
+		Breadcrumb: types.StructuredDataBreadcrumb{
+			Type: "BreadcrumbList",
+			ItemListElement: []types.StructuredDataBreadcrumbItem{
+				{Type: "ListItem", Position: 1, Name: utils.Config.Frontend.SiteName, Item: siteURL + "/"},
+				{Type: "ListItem", Position: 2, Name: listName, Item: siteURL + listPath},
+				{Type: "ListItem", Position: 3, Name: name, Item: siteURL + data.Meta.Path},
+			},
+		},
+	}
+	if !modified.IsZero() {
+		sd.DateModified = &modified
+	}
+	data.Meta.StructuredData = sd
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+)
+
+// getTheme returns the theme preference saved in the session, it is empty if no theme has been chosen and the browser decides
+func getTheme(r *http.Request) string {
+	if utils.SessionStore == nil {
+		return ""
+	}
+	session, err := utils.SessionStore.Get(r, authSessionName)
+	if err != nil {
+		return ""
+	}
+	theme, ok := session.Values["theme"].(string)
+	if !ok || !utils.IsValidTheme(theme) {
+		return ""
+	}
+	return theme
+}
+
+// SetTheme saves the theme preference in the session and, if the user is logged in, in the database
+func SetTheme(w http.ResponseWriter, r *http.Request) {
+	theme := r.FormValue("theme")
+	if !utils.IsValidTheme(theme) {
+		http.Error(w, "Error: Invalid theme", http.StatusBadRequest)
+		return
+	}
+
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	if user.Authenticated {
+		err = db.SetUserTheme(r.Context(), user.UserID, theme)
+		if err != nil {
+			requestLogger(r).Errorf("error saving theme for user %v: %v", user.UserID, err)
+			http.Error(w, "Internal server error", 503)
+			return
+		}
+	}
+
+	session.Values["theme"] = theme
+	err = session.Save(r, w)
+	if err != nil {
+		requestLogger(r).Errorf("error saving session: %v", err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ApiUserTheme godoc
+// @Summary Get the theme preference of the user so the mobile app can sync its appearance with the website, the theme is empty if the user has not chosen one
+// @Tags User
+// @Produce  json
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/settings/theme [get]
+func ApiUserTheme(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	claims := getAuthClaims(r)
+
+	theme, err := db.GetUserTheme(r.Context(), claims.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving theme for user %v: %v", claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{map[string]string{"theme": theme}})
+}
+
+// ApiUserThemePost godoc
+// @Summary Set the theme preference of the user, either light or dark
+// @Tags User
+// @Produce  json
+// @Param theme body string true "light or dark"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/settings/theme [post]
+func ApiUserThemePost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	theme := FormValueOrJSON(r, "theme")
+	if !utils.IsValidTheme(theme) {
+		sendErrorResponse(j, r.URL.String(), "invalid theme")
+		return
+	}
+
+	claims := getAuthClaims(r)
+
+	err := db.SetUserTheme(r.Context(), claims.UserID, theme)
+	if err != nil {
+		requestLogger(r).Errorf("error saving theme for user %v: %v", claims.UserID, err)
+		sendErrorResponse(j, r.URL.String(), "could not save theme")
+		return
+	}
+
+	OKResponse(w, r)
+}
+
+// themedChartData returns a copy of the chart with series colors taken from the palette of the theme, explicitly colored series keep their color
+func themedChartData(chart *types.GenericChartData, theme string) *types.GenericChartData {
+	colors := utils.ChartColors(theme)
+	themed := *chart
+	themed.Series = make([]*types.GenericChartDataSeries, len(chart.Series))
+	for i, s := range chart.Series {
+		series := *s
+		if series.Color == "" {
+			series.Color = colors[i%len(colors)]
+		}
+		themed.Series[i] = &series
+	}
+	return &themed
+}
@@ -52,23 +52,23 @@ func UserSettings(w http.ResponseWriter, r *http.Request) {
 
 	user, session, err := getUserSession(r)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	premiumSubscription, err := db.GetUserPremiumSubscription(user.UserID)
+	premiumSubscription, err := db.GetUserPremiumSubscription(r.Context(), user.UserID)
 	if err != nil && err != sql.ErrNoRows {
-		logger.Errorf("Error retrieving the premium subscriptions for user: %v %v", user.UserID, err)
+		requestLogger(r).Errorf("Error retrieving the premium subscriptions for user: %v %v", user.UserID, err)
 		session.Flashes("Error: Something went wrong.")
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
 		return
 	}
 
-	subscription, err := db.StripeGetUserSubscription(user.UserID, utils.GROUP_API)
+	subscription, err := db.StripeGetUserSubscription(r.Context(), user.UserID, utils.GROUP_API)
 	if err != nil && err != sql.ErrNoRows {
-		logger.Errorf("Error retrieving the subscriptions for user: %v %v", user.UserID, err)
+		requestLogger(r).Errorf("Error retrieving the subscriptions for user: %v %v", user.UserID, err)
 		session.Flashes("Error: Something went wrong.")
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -76,38 +76,37 @@ func UserSettings(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var pairedDevices []types.PairedDevice = nil
-	pairedDevices, err = db.GetUserDevicesByUserID(user.UserID)
+	pairedDevices, err = db.GetUserDevicesByUserID(r.Context(), user.UserID)
 	if err != nil && err != sql.ErrNoRows {
-		logger.Errorf("Error retrieving the paired devices for user: %v %v", user.UserID, err)
+		requestLogger(r).Errorf("Error retrieving the paired devices for user: %v %v", user.UserID, err)
 		pairedDevices = nil
 	}
-	statsSharing, err := db.GetUserMonitorSharingSetting(user.UserID)
+	statsSharing, err := db.GetUserMonitorSharingSetting(r.Context(), user.UserID)
 	if err != nil {
-		logger.Errorf("Error retrieving stats sharing setting: %v %v", user.UserID, err)
+		requestLogger(r).Errorf("Error retrieving stats sharing setting: %v %v", user.UserID, err)
 		statsSharing = false
 	}
-
-	maxDaily := 10000
-	maxMonthly := 30000
-	if subscription.PriceID != nil {
-		if *subscription.PriceID == utils.Config.Frontend.Stripe.Sapphire {
-			maxDaily = 100000
-			maxMonthly = 500000
-		} else if *subscription.PriceID == utils.Config.Frontend.Stripe.Emerald {
-			maxDaily = 200000
-			maxMonthly = 1000000
-		} else if *subscription.PriceID == utils.Config.Frontend.Stripe.Diamond {
-			maxDaily = -1
-			maxMonthly = 4000000
-		}
+	feeRecipient, err := db.GetUserFeeRecipient(r.Context(), user.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("Error retrieving expected fee recipient: %v %v", user.UserID, err)
+		feeRecipient = nil
 	}
+	deletionRequest, err := db.GetUserDeletionRequest(r.Context(), user.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("Error retrieving account deletion request: %v %v", user.UserID, err)
+		deletionRequest = nil
+	}
+
+	apiEntitlements := utils.ApiPlanEntitlements(subscription.PriceID)
+	maxDaily := apiEntitlements.DailyQuota
+	maxMonthly := apiEntitlements.MonthlyQuota
 
 	userSettingsData.ApiStatistics = &types.ApiStatistics{}
 
 	if subscription.ApiKey != nil && len(*subscription.ApiKey) > 0 {
-		apiStats, err := db.GetUserAPIKeyStatistics(subscription.ApiKey)
+		apiStats, err := db.GetUserAPIKeyStatistics(r.Context(), subscription.ApiKey)
 		if err != nil {
-			logger.Errorf("Error retrieving user api key usage: %v %v", user.UserID, err)
+			requestLogger(r).Errorf("Error retrieving user api key usage: %v %v", user.UserID, err)
 		}
 		if apiStats != nil {
 			userSettingsData.ApiStatistics = apiStats
@@ -124,6 +123,18 @@ func UserSettings(w http.ResponseWriter, r *http.Request) {
 	userSettingsData.Emerald = &utils.Config.Frontend.Stripe.Emerald
 	userSettingsData.Diamond = &utils.Config.Frontend.Stripe.Diamond
 	userSettingsData.ShareMonitoringData = statsSharing
+	userSettingsData.FeeRecipient = feeRecipient
+	userSettingsData.DeletionRequest = deletionRequest
+	userSettingsData.ApiEntitlements = apiEntitlements
+	userSettingsData.Sessions, err = db.GetUserSessions(r.Context(), user.UserID, sessionKey(session))
+	if err != nil {
+		requestLogger(r).Errorf("Error retrieving sessions of user: %v %v", user.UserID, err)
+	}
+	if premiumSubscription.Active {
+		userSettingsData.Entitlements = utils.PackageEntitlements(premiumSubscription.Package)
+	} else {
+		userSettingsData.Entitlements = utils.PackageEntitlements("")
+	}
 	userSettingsData.Flashes = utils.GetFlashes(w, r, authSessionName)
 	userSettingsData.CsrfField = csrf.TemplateField(r)
 
@@ -142,7 +153,7 @@ func UserSettings(w http.ResponseWriter, r *http.Request) {
 
 	err = userTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -153,9 +164,9 @@ func GenerateAPIKey(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 	user := getUser(r)
 
-	err := db.CreateAPIKey(user.UserID)
+	err := db.CreateAPIKey(r.Context(), user.UserID)
 	if err != nil {
-		logger.WithError(err).Error("Could not create API key for user")
+		requestLogger(r).WithError(err).Error("Could not create API key for user")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -170,7 +181,7 @@ func UserAuthorizeConfirm(w http.ResponseWriter, r *http.Request) {
 
 	user, session, err := getUserSession(r)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
@@ -187,15 +198,15 @@ func UserAuthorizeConfirm(w http.ResponseWriter, r *http.Request) {
 	session.Save(r, w)
 
 	if !user.Authenticated {
-		logger.Errorf("User not authorized")
+		requestLogger(r).Errorf("User not authorized")
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
-	appData, err := db.GetAppDataFromRedirectUri(redirectURI)
+	appData, err := db.GetAppDataFromRedirectUri(r.Context(), redirectURI)
 
 	if err != nil {
-		logger.Errorf("error app not found: %v: %v: %v", user.UserID, appData, err)
+		requestLogger(r).Errorf("error app not found: %v: %v: %v", user.UserID, appData, err)
 		utils.SetFlash(w, r, authSessionName, "Error: App not found. Is your redirect_uri correct and registered?")
 		session.Save(r, w)
 	} else {
@@ -212,7 +223,7 @@ func UserAuthorizeConfirm(w http.ResponseWriter, r *http.Request) {
 
 	err = authorizeTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		callback := appData.RedirectURI + "?error=temporarily_unaviable&error_description=err_template&state=" + state
 		http.Redirect(w, r, callback, http.StatusSeeOther)
 		return
@@ -245,28 +256,28 @@ func UserNotifications(w http.ResponseWriter, r *http.Request) {
 	userNotificationsData.CsrfField = csrf.TemplateField(r)
 
 	var watchlistIndices []uint64
-	err := db.DB.Select(&watchlistIndices, `
+	err := db.DB.SelectContext(r.Context(), &watchlistIndices, `
 	SELECT validators.validatorindex as index
 	FROM users_validators_tags
 	INNER JOIN validators
 	ON
 	  users_validators_tags.validator_publickey = validators.pubkey
 	WHERE user_id = $1 and tag = $2
-	`, user.UserID, types.ValidatorTagsWatchlist)
+	`, user.UserID, utils.GetNetwork()+":"+string(types.ValidatorTagsWatchlist))
 	if err != nil {
-		logger.Errorf("error retrieving watchlist validator count %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error retrieving watchlist validator count %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	var countSubscriptions int
-	err = db.FrontendDB.Get(&countSubscriptions, `
+	err = db.FrontendDB.GetContext(r.Context(), &countSubscriptions, `
 	SELECT count(*) as count
 	FROM users_subscriptions
 	WHERE user_id = $1
 	`, user.UserID)
 	if err != nil {
-		logger.Errorf("error retrieving subscription count %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error retrieving subscription count %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -288,7 +299,7 @@ func UserNotifications(w http.ResponseWriter, r *http.Request) {
 
 	err = notificationTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -331,7 +342,7 @@ func getValidatorTableData(userId uint64) (interface{}, error) {
 SELECT ENCODE(uvt.validator_publickey::bytea, 'hex') AS pubkey, us.event_name, extract( epoch from last_sent_ts)::Int as last_sent_ts, us.event_threshold
 FROM users_validators_tags uvt
 LEFT JOIN users_subscriptions us ON us.event_filter = ENCODE(uvt.validator_publickey::bytea, 'hex') AND us.user_id = uvt.user_id
-WHERE uvt.user_id = $1;`, userId)
+WHERE uvt.user_id = $1 AND uvt.tag = $2;`, userId, utils.GetNetwork()+":"+string(types.ValidatorTagsWatchlist))
 
 	if err != nil {
 		return validatordb, err
@@ -433,7 +444,7 @@ func RemoveAllValidatorsAndUnsubscribe(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body of request: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error reading body of request: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -441,14 +452,14 @@ func RemoveAllValidatorsAndUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	pubkeys := make([]string, 0)
 	err = json.Unmarshal(body, &pubkeys)
 	if err != nil {
-		logger.Errorf("error parsing request body: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error parsing request body: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	for _, item := range pubkeys {
-		err = db.RemoveFromWatchlist(user.UserID, item, utils.GetNetwork())
+		err = db.RemoveFromWatchlist(r.Context(), user.UserID, item, utils.GetNetwork())
 		if err != nil {
-			logger.Errorf("error removing from  watchlist: %v, %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error removing from  watchlist: %v, %v", r.URL.String(), err)
 			continue
 		}
 	}
@@ -463,7 +474,7 @@ func AddValidatorsAndSubscribe(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body of request: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error reading body of request: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -482,27 +493,37 @@ func AddValidatorsAndSubscribe(w http.ResponseWriter, r *http.Request) {
 	// pubkeys := make([]string, 0)
 	err = json.Unmarshal(body, &reqData)
 	if err != nil {
-		logger.Errorf("error parsing request body: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error parsing request body: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	if reqData.Pubkey == "" {
-		err := db.DB.Get(&reqData.Pubkey, "SELECT ENCODE(pubkey, 'hex') as pubkey from validators where validatorindex = $1", reqData.Index)
+		err := db.DB.GetContext(r.Context(), &reqData.Pubkey, "SELECT ENCODE(pubkey, 'hex') as pubkey from validators where validatorindex = $1", reqData.Index)
 		if err != nil {
-			logger.Errorf("error getting pubkey from validator index route: %v, %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error getting pubkey from validator index route: %v, %v", r.URL.String(), err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	}
 
 	if reqData.Pubkey == "" {
-		logger.Errorf("error invalid pubkey: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error invalid pubkey: %v, %v", r.URL.String(), err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	exceeded, err := watchlistLimitExceeded(r, user.UserID, []string{reqData.Pubkey})
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving watchlist of user %v: %v", user.UserID, err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	err = db.AddToWatchlist([]db.WatchlistEntry{{UserId: user.UserID, Validator_publickey: reqData.Pubkey}}, utils.GetNetwork())
+	if exceeded {
+		ErrorOrJSONResponse(w, r, "watchlist limit of your plan reached", http.StatusForbidden)
+		return
+	}
+	err = db.AddToWatchlist(r.Context(), []db.WatchlistEntry{{UserId: user.UserID, Validator_publickey: reqData.Pubkey}}, utils.GetNetwork())
 	if err != nil {
-		logger.Errorf("error adding to watchlist: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error adding to watchlist: %v, %v", r.URL.String(), err)
 		return
 	}
 
@@ -535,7 +556,7 @@ func UserUpdateSubscriptions(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body of request: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error reading body of request: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -555,13 +576,13 @@ func UserUpdateSubscriptions(w http.ResponseWriter, r *http.Request) {
 	// pubkeys := make([]string, 0)
 	err = json.Unmarshal(body, &reqData)
 	if err != nil {
-		logger.Errorf("error parsing request body: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error parsing request body: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	if len(reqData.Pubkeys) == 0 {
-		logger.Errorf("error invalid pubkey: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error invalid pubkey: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -573,11 +594,11 @@ func UserUpdateSubscriptions(w http.ResponseWriter, r *http.Request) {
 		net + ":" + string(types.ValidatorExecutedProposalEventName),
 		net + ":" + string(types.ValidatorGotSlashedEventName)})
 
-	_, err = db.FrontendDB.Exec(`
+	_, err = db.FrontendDB.ExecContext(r.Context(), `
 			DELETE FROM users_subscriptions WHERE user_id=$1 AND event_filter=ANY($2) AND event_name=ANY($3);
 		`, user.UserID, pqPubkeys, pqEventNames)
 	if err != nil {
-		logger.Errorf("error removing old events: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error removing old events: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -618,7 +639,7 @@ func UserUpdateMonitoringSubscriptions(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body of request: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error reading body of request: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -637,13 +658,13 @@ func UserUpdateMonitoringSubscriptions(w http.ResponseWriter, r *http.Request) {
 	// pubkeys := make([]string, 0)
 	err = json.Unmarshal(body, &reqData)
 	if err != nil {
-		logger.Errorf("error parsing request body: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error parsing request body: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	if len(reqData.Pubkeys) == 0 {
-		logger.Errorf("error invalid pubkey: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error invalid pubkey: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -656,11 +677,11 @@ func UserUpdateMonitoringSubscriptions(w http.ResponseWriter, r *http.Request) {
 		net + ":" + string(types.MonitoringMachineSwitchedToETH1FallbackEventName),
 		net + ":" + string(types.MonitoringMachineSwitchedToETH2FallbackEventName)})
 
-	_, err = db.FrontendDB.Exec(`
+	_, err = db.FrontendDB.ExecContext(r.Context(), `
 			DELETE FROM users_subscriptions WHERE user_id=$1 AND event_filter=ANY($2) AND event_name=ANY($3);
 		`, user.UserID, pqPubkeys, pqEventNames)
 	if err != nil {
-		logger.Errorf("error removing old events: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error removing old events: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -704,13 +725,13 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 	userNotificationsCenterData.Flashes = utils.GetFlashes(w, r, authSessionName)
 	userNotificationsCenterData.CsrfField = csrf.TemplateField(r)
 	var watchlistPubkeys [][]byte
-	err := db.FrontendDB.Select(&watchlistPubkeys, `
+	err := db.FrontendDB.SelectContext(r.Context(), &watchlistPubkeys, `
 	SELECT validator_publickey
 	FROM users_validators_tags
 	WHERE user_id = $1 and tag = $2
 	`, user.UserID, utils.GetNetwork()+":"+string(types.ValidatorTagsWatchlist))
 	if err != nil && err != sql.ErrNoRows {
-		logger.Errorf("error retrieving pubkeys from watchlist validator count %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error retrieving pubkeys from watchlist validator count %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -721,7 +742,7 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 	}
 
 	watchlist := []watchlistValidators{}
-	err = db.DB.Select(&watchlist, `
+	err = db.DB.SelectContext(r.Context(), &watchlist, `
 	SELECT 
 		validatorindex as index,
 		ENCODE(pubkey, 'hex') as pubkey
@@ -729,20 +750,20 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 	WHERE pubkey = ANY($1)
 	`, pq.ByteaArray(watchlistPubkeys))
 	if err != nil {
-		logger.Errorf("error retrieving watchlist indices validator count %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error retrieving watchlist indices validator count %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	var subscriptions []types.Subscription
-	err = db.FrontendDB.Select(&subscriptions, `
+	err = db.FrontendDB.SelectContext(r.Context(), &subscriptions, `
 	SELECT 
 		event_name, event_filter, last_sent_ts, last_sent_epoch, created_ts, created_epoch, event_threshold
 	FROM users_subscriptions
 	WHERE user_id = $1 AND (event_name like $2 OR event_name like 'monitoring%') AND event_name != $3
 	`, user.UserID, utils.GetNetwork()+":%", utils.GetNetwork()+":"+"validator_balance_decreased")
 	if err != nil {
-		logger.Errorf("error retrieving subscriptions for user %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error retrieving subscriptions for user %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -825,28 +846,28 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 	// type metrics
 	// metricsdb, err := getUserMetrics(user.UserID)
 	// if err != nil {
-	// 	logger.Errorf("error retrieving metrics data for users: %v ", user.UserID, err)
+	// 	requestLogger(r).Errorf("error retrieving metrics data for users: %v ", user.UserID, err)
 	// 	http.Error(w, "Internal server error", 503)
 	// 	return
 	// }
 
-	machines, err := db.GetStatsMachine(user.UserID)
+	machines, err := db.GetStatsMachine(r.Context(), user.UserID)
 	if err != nil {
-		logger.Errorf("error retrieving user machines: %v ", user.UserID, err)
+		requestLogger(r).Errorf("error retrieving user machines: %v ", user.UserID, err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	networkData, err := getUserNetworkEvents(user.UserID)
 	if err != nil {
-		logger.Errorf("error retrieving network data for users: %v ", user.UserID, err)
+		requestLogger(r).Errorf("error retrieving network data for users: %v ", user.UserID, err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	// validatorTableData, err := getValidatorTableData(user.UserID)
 	// if err != nil {
-	// 	logger.Errorf("error retrieving validators table data for users: %v ", user.UserID, err)
+	// 	requestLogger(r).Errorf("error retrieving validators table data for users: %v ", user.UserID, err)
 	// 	http.Error(w, "Internal server error", 503)
 	// 	return
 	// }
@@ -862,7 +883,7 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 
 	err = notificationsCenterTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -879,19 +900,19 @@ func UserNotificationsData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	// start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	// if err != nil {
-	// 	logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+	// 	requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 	// 	http.Error(w, "Internal server error", 503)
 	// 	return
 	// }
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -909,7 +930,7 @@ func UserNotificationsData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	wl := []watchlistSubscription{}
-	err = db.DB.Select(&wl, `
+	err = db.DB.SelectContext(r.Context(), &wl, `
 		SELECT 
 			validators.validatorindex as index,
 			users_validators_tags.validator_publickey as publickey,
@@ -921,11 +942,11 @@ func UserNotificationsData(w http.ResponseWriter, r *http.Request) {
 			AND ENCODE(users_validators_tags.validator_publickey::bytea, 'hex') = users_subscriptions.event_filter
 		LEFT JOIN validators
 			ON users_validators_tags.validator_publickey = validators.pubkey
-		WHERE users_validators_tags.user_id = $1
+		WHERE users_validators_tags.user_id = $1 AND users_validators_tags.tag = $2
 		GROUP BY users_validators_tags.user_id, users_validators_tags.validator_publickey, validators.validatorindex;
-		`, user.UserID)
+		`, user.UserID, utils.GetNetwork()+":"+string(types.ValidatorTagsWatchlist))
 	if err != nil {
-		logger.Errorf("error retrieving subscriptions for users: %v validators: %v", user.UserID, err)
+		requestLogger(r).Errorf("error retrieving subscriptions for users: %v validators: %v", user.UserID, err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -953,7 +974,7 @@ func UserNotificationsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -970,19 +991,19 @@ func UserSubscriptionsData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	// start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	// if err != nil {
-	// 	logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+	// 	requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 	// 	http.Error(w, "Internal server error", 503)
 	// 	return
 	// }
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -993,13 +1014,13 @@ func UserSubscriptionsData(w http.ResponseWriter, r *http.Request) {
 	user := getUser(r)
 
 	subs := []types.Subscription{}
-	err = db.FrontendDB.Select(&subs, `
+	err = db.FrontendDB.SelectContext(r.Context(), &subs, `
 			SELECT *
 			FROM users_subscriptions
 			WHERE user_id = $1
 	`, user.UserID)
 	if err != nil {
-		logger.Errorf("error retrieving subscriptions for users %v: %v", user.UserID, err)
+		requestLogger(r).Errorf("error retrieving subscriptions for users %v: %v", user.UserID, err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -1015,7 +1036,7 @@ func UserSubscriptionsData(w http.ResponseWriter, r *http.Request) {
 		if len(sub.EventFilter) == 96 {
 			h, err := hex.DecodeString(sub.EventFilter)
 			if err != nil {
-				logger.Errorf("Could not decode Pubkey %v", err)
+				requestLogger(r).Errorf("Could not decode Pubkey %v", err)
 			} else {
 				pubkey = utils.FormatPublicKey(h)
 			}
@@ -1044,7 +1065,7 @@ func UserSubscriptionsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -1052,7 +1073,7 @@ func UserSubscriptionsData(w http.ResponseWriter, r *http.Request) {
 }
 
 func UserAuthorizeConfirmPost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.WithField("route", r.URL.String())
+	logger := requestLogger(r).WithField("route", r.URL.String())
 
 	redirectURI := r.FormValue("redirect_uri")
 	state := r.FormValue("state")
@@ -1061,7 +1082,7 @@ func UserAuthorizeConfirmPost(w http.ResponseWriter, r *http.Request) {
 		stateAppend = "&state=" + state
 	}
 
-	appData, err := db.GetAppDataFromRedirectUri(redirectURI)
+	appData, err := db.GetAppDataFromRedirectUri(r.Context(), redirectURI)
 	if err != nil {
 		logger.Errorf("error app no found: %v %v", appData, err)
 		callback := redirectURI + "?error=invalid_request&error_description=missing_redirect_uri" + stateAppend
@@ -1090,7 +1111,7 @@ func UserAuthorizeConfirmPost(w http.ResponseWriter, r *http.Request) {
 		codeHashed := utils.HashAndEncode(code) // save hashed code in db
 		clientID := session.Values["client_id"].(string)
 
-		err2 := db.AddAuthorizeCode(user.UserID, codeHashed, clientID, appData.ID)
+		err2 := db.AddAuthorizeCode(r.Context(), user.UserID, codeHashed, clientID, appData.ID)
 		if err2 != nil {
 			logger.Errorf("error adding authorization code for user: %v %v", user.UserID, err2)
 			callback := appData.RedirectURI + "?error=server_error&error_description=err_db_storefail" + stateAppend
@@ -1112,7 +1133,7 @@ func UserAuthorizeConfirmPost(w http.ResponseWriter, r *http.Request) {
 }
 
 func UserDeletePost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.WithField("route", r.URL.String())
+	logger := requestLogger(r).WithField("route", r.URL.String())
 	user, session, err := getUserSession(r)
 	if err != nil {
 		logger.Errorf("error retrieving session: %v", err)
@@ -1120,17 +1141,18 @@ func UserDeletePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if user.Authenticated == true {
-		err := db.DeleteUserById(user.UserID)
+		req, err := db.ScheduleUserDeletion(r.Context(), user.UserID, userDeletionDelay)
 		if err != nil {
-			logger.Errorf("error deleting user by email for user: %v %v", user.UserID, err)
-			http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
-			session.Flashes("Error: Could not delete user.")
+			logger.Errorf("error scheduling deletion for user: %v %v", user.UserID, err)
+			session.AddFlash("Error: Could not delete user.")
 			session.Save(r, w)
 			http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
 			return
 		}
 
-		Logout(w, r)
+		session.AddFlash(fmt.Sprintf("Your account will be deleted on %v. You can cancel the deletion in your settings until then.", req.ScheduledTs.Format("2006-01-02")))
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
 	} else {
 		logger.Error("Trying to delete a unauthenticated user")
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -1141,16 +1163,16 @@ func UserDeletePost(w http.ResponseWriter, r *http.Request) {
 func UserUpdateFlagsPost(w http.ResponseWriter, r *http.Request) {
 	user, _, err := getUserSession(r)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	shareStats := FormValueOrJSON(r, "shareStats")
 
-	logger.Errorf("shareStats: %v", shareStats)
+	requestLogger(r).Errorf("shareStats: %v", shareStats)
 
-	err = db.SetUserMonitorSharingSetting(user.UserID, shareStats == "true")
+	err = db.SetUserMonitorSharingSetting(r.Context(), user.UserID, shareStats == "true")
 
 	http.Redirect(w, r, "/user/settings#app", http.StatusOK)
 }
@@ -1160,14 +1182,14 @@ func UserUpdatePasswordPost(w http.ResponseWriter, r *http.Request) {
 
 	user, session, err := getUserSession(r)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	err = r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		session.AddFlash(authInternalServerErrorFlashMsg)
 		session.Save(r, w)
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -1183,9 +1205,9 @@ func UserUpdatePasswordPost(w http.ResponseWriter, r *http.Request) {
 		Confirmed bool   `db:"email_confirmed"`
 	}{}
 
-	err = db.FrontendDB.Get(&currentUser, "SELECT id, email, password, email_confirmed FROM users WHERE id = $1", user.UserID)
+	err = db.FrontendDB.GetContext(r.Context(), &currentUser, "SELECT id, email, password, email_confirmed FROM users WHERE id = $1", user.UserID)
 	if err != nil {
-		logger.Errorf("error retrieving password for user %v: %v", user.UserID, err)
+		requestLogger(r).Errorf("error retrieving password for user %v: %v", user.UserID, err)
 		session.AddFlash("Error: Invalid password!")
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -1201,7 +1223,7 @@ func UserUpdatePasswordPost(w http.ResponseWriter, r *http.Request) {
 
 	err = bcrypt.CompareHashAndPassword([]byte(currentUser.Password), []byte(pwdOld))
 	if err != nil {
-		logger.Errorf("error verifying password for user %v: %v", currentUser.Email, err)
+		requestLogger(r).Errorf("error verifying password for user %v: %v", currentUser.Email, err)
 		session.AddFlash("Error: Invalid password!")
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -1210,21 +1232,40 @@ func UserUpdatePasswordPost(w http.ResponseWriter, r *http.Request) {
 
 	pHash, err := bcrypt.GenerateFromPassword([]byte(pwdNew), 10)
 	if err != nil {
-		logger.Errorf("error generating hash for password: %v", err)
+		requestLogger(r).Errorf("error generating hash for password: %v", err)
 		session.AddFlash(GenericUpdatePasswordError)
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
 		return
 	}
 
-	err = db.UpdatePassword(user.UserID, pHash)
+	err = db.UpdatePassword(r.Context(), user.UserID, pHash)
 	if err != nil {
-		logger.Errorf("error updating password for user: %v", err)
+		requestLogger(r).Errorf("error updating password for user: %v", err)
 		session.AddFlash("Error: Something went wrong updating your password 😕. If this error persists please contact <a href=\"https://support.bitfly.at/support/home\">support</a>")
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
 		return
 	}
+
+	// the logins with the old password are ended, only the session of the request continues with a new key
+	err = db.RevokeUserSessions(r.Context(), user.UserID, "")
+	if err != nil {
+		requestLogger(r).Errorf("error revoking sessions of user %v: %v", user.UserID, err)
+	}
+	utils.RotateSession(session)
+	err = startUserSession(r, session, user.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error starting session of user %v: %v", user.UserID, err)
+		session.Values["authenticated"] = false
+		session.AddFlash("Your password has been updated, please log in again!")
+		session.Save(r, w)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	session.Values["authenticated"] = true
+	session.Values["user_id"] = user.UserID
+	session.Values["subscription"] = user.Subscription
 	session.AddFlash("Password Updated Successfully ✔️")
 	session.Save(r, w)
 	http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -1234,14 +1275,14 @@ func UserUpdatePasswordPost(w http.ResponseWriter, r *http.Request) {
 func UserUpdateEmailPost(w http.ResponseWriter, r *http.Request) {
 	user, session, err := getUserSession(r)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	err = r.ParseForm()
 	if err != nil {
-		logger.Errorf("error parsing form: %v", err)
+		requestLogger(r).Errorf("error parsing form: %v", err)
 		session.AddFlash(authInternalServerErrorFlashMsg)
 		session.Save(r, w)
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -1260,7 +1301,7 @@ func UserUpdateEmailPost(w http.ResponseWriter, r *http.Request) {
 		Count int
 		Email string
 	}
-	err = db.FrontendDB.Get(&existingEmails, "SELECT email FROM users WHERE email = $1", email)
+	err = db.FrontendDB.GetContext(r.Context(), &existingEmails, "SELECT email FROM users WHERE email = $1", email)
 
 	if existingEmails.Email == email {
 		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
@@ -1275,7 +1316,7 @@ func UserUpdateEmailPost(w http.ResponseWriter, r *http.Request) {
 	var rateLimitError *types.RateLimitError
 	err = sendEmailUpdateConfirmation(user.UserID, email)
 	if err != nil {
-		logger.Errorf("error sending confirmation-email: %v", err)
+		requestLogger(r).Errorf("error sending confirmation-email: %v", err)
 		if errors.As(err, &rateLimitError) {
 			session.AddFlash(fmt.Sprintf("Error: The ratelimit for sending emails has been exceeded, please try again in %v.", err.(*types.RateLimitError).TimeLeft.Round(time.Second)))
 		} else {
@@ -1298,7 +1339,7 @@ func UserConfirmUpdateEmail(w http.ResponseWriter, r *http.Request) {
 
 	_, session, err := getUserSession(r)
 	if err != nil {
-		logger.Errorf("error retrieving session: %v", err)
+		requestLogger(r).Errorf("error retrieving session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1320,9 +1361,9 @@ func UserConfirmUpdateEmail(w http.ResponseWriter, r *http.Request) {
 		Confirmed bool      `db:"email_confirmed"`
 	}{}
 
-	err = db.FrontendDB.Get(&user, "SELECT id, email, email_confirmation_ts, email_confirmed FROM users WHERE email_confirmation_hash = $1", hash)
+	err = db.FrontendDB.GetContext(r.Context(), &user, "SELECT id, email, email_confirmation_ts, email_confirmed FROM users WHERE email_confirmation_hash = $1", hash)
 	if err != nil {
-		logger.Errorf("error retreiveing email for confirmation_hash %v %v", hash, err)
+		requestLogger(r).Errorf("error retreiveing email for confirmation_hash %v %v", hash, err)
 		utils.SetFlash(w, r, authSessionName, "Error: This confirmation link is invalid / outdated.")
 		http.Redirect(w, r, "/confirmation", http.StatusSeeOther)
 		return
@@ -1341,24 +1382,28 @@ func UserConfirmUpdateEmail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var emailExists string
-	err = db.FrontendDB.Get(&emailExists, "SELECT email FROM users WHERE email = $1", newEmail)
+	err = db.FrontendDB.GetContext(r.Context(), &emailExists, "SELECT email FROM users WHERE email = $1", newEmail)
 	if emailExists != "" {
 		utils.SetFlash(w, r, authSessionName, "Error: Email already exists. We could not update your email.")
 		http.Redirect(w, r, "/confirmation", http.StatusSeeOther)
 		return
 	}
 
-	_, err = db.FrontendDB.Exec(`UPDATE users SET email = $1 WHERE id = $2`, newEmail, user.ID)
+	_, err = db.FrontendDB.ExecContext(r.Context(), `UPDATE users SET email = $1 WHERE id = $2`, newEmail, user.ID)
 	if err != nil {
-		logger.Errorf("error: updating email for user: %v", err)
+		requestLogger(r).Errorf("error: updating email for user: %v", err)
 		utils.SetFlash(w, r, authSessionName, "Error: Could not Update Email.")
 		http.Redirect(w, r, "/confirmation", http.StatusSeeOther)
 		return
 	}
 
+	err = db.RevokeUserSessions(r.Context(), uint64(user.ID), "")
+	if err != nil {
+		requestLogger(r).Errorf("error revoking sessions of user %v: %v", user.ID, err)
+	}
+	utils.RotateSession(session)
 	session.Values["subscription"] = ""
 	session.Values["authenticated"] = false
-	delete(session.Values, "user_id")
 
 	utils.SetFlash(w, r, authSessionName, "Your email has been updated successfully! <br> You can log in with your new email.")
 	http.Redirect(w, r, "/confirmation", http.StatusSeeOther)
@@ -1445,45 +1490,72 @@ func UserValidatorWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 
 	balance := FormValueOrJSON(r, "balance_decreases")
 	if balance == "on" {
-		err := db.AddSubscription(user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorBalanceDecreasedEventName, pubKey, 0)
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorBalanceDecreasedEventName, pubKey, 0)
 		if err != nil {
-			logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorBalanceDecreasedEventName, pubKey, err)
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorBalanceDecreasedEventName, pubKey, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	}
 	slashed := FormValueOrJSON(r, "validator_slashed")
 	if slashed == "on" {
-		err := db.AddSubscription(user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorGotSlashedEventName, pubKey, 0)
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorGotSlashedEventName, pubKey, 0)
 		if err != nil {
-			logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	}
 	proposalSubmitted := FormValueOrJSON(r, "validator_proposal_submitted")
 	if proposalSubmitted == "on" {
-		err := db.AddSubscription(user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorExecutedProposalEventName, pubKey, 0)
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorExecutedProposalEventName, pubKey, 0)
 		if err != nil {
-			logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	}
 	proposalMissed := FormValueOrJSON(r, "validator_proposal_missed")
 	if proposalMissed == "on" {
-		err := db.AddSubscription(user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorMissedProposalEventName, pubKey, 0)
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorMissedProposalEventName, pubKey, 0)
+		if err != nil {
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
+			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	feeRecipientMismatch := FormValueOrJSON(r, "validator_fee_recipient_mismatch")
+	if feeRecipientMismatch == "on" {
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorFeeRecipientMismatchEventName, pubKey, 0)
+		if err != nil {
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorFeeRecipientMismatchEventName, pubKey, err)
+			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	rocketpoolFeeRecipientViolation := FormValueOrJSON(r, "rocketpool_fee_recipient_violation")
+	if rocketpoolFeeRecipientViolation == "on" {
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.RocketpoolFeeRecipientViolationEventName, pubKey, 0)
 		if err != nil {
-			logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.RocketpoolFeeRecipientViolationEventName, pubKey, err)
+			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	fullWithdrawal := FormValueOrJSON(r, "validator_full_withdrawal")
+	if fullWithdrawal == "on" {
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorFullWithdrawalEventName, pubKey, 0)
+		if err != nil {
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorFullWithdrawalEventName, pubKey, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	}
 	attestationMissed := FormValueOrJSON(r, "validator_attestation_missed")
 	if attestationMissed == "on" {
-		err := db.AddSubscription(user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorMissedAttestationEventName, pubKey, 0)
+		err := db.AddSubscription(r.Context(), user.UserID, utils.Config.Chain.Phase0.ConfigName, types.ValidatorMissedAttestationEventName, pubKey, 0)
 		if err != nil {
-			logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, types.ValidatorGotSlashedEventName, pubKey, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -1499,15 +1571,31 @@ func UserValidatorWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	exceeded, err := watchlistLimitExceeded(r, user.UserID, []string{pubKey})
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving watchlist of user %v: %v", user.UserID, err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if exceeded {
+		FlashRedirectOrJSONErrorResponse(w, r,
+			validatorEditFlash,
+			fmt.Sprintf("Error: You can follow at most %v validators with your current plan.", getUserPremium(r).MaxWatchedValidators),
+			"/validator/"+pubKey,
+			http.StatusSeeOther,
+		)
+		return
+	}
+
 	watchlistEntries := []db.WatchlistEntry{
 		{
 			UserId:              user.UserID,
 			Validator_publickey: pubKey,
 		},
 	}
-	err := db.AddToWatchlist(watchlistEntries, utils.GetNetwork())
+	err = db.AddToWatchlist(r.Context(), watchlistEntries, utils.GetNetwork())
 	if err != nil {
-		logger.Errorf("error adding validator to watchlist to db: %v", err)
+		requestLogger(r).Errorf("error adding validator to watchlist to db: %v", err)
 		FlashRedirectOrJSONErrorResponse(w, r,
 			validatorEditFlash,
 			"Error: Could not follow validator.",
@@ -1536,7 +1624,7 @@ func UserDashboardWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body of request: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error reading body of request: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1544,7 +1632,7 @@ func UserDashboardWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 	indices := make([]string, 0)
 	err = json.Unmarshal(body, &indices)
 	if err != nil {
-		logger.Errorf("error parsing request body: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error parsing request body: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1552,7 +1640,7 @@ func UserDashboardWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 	for _, i := range indices {
 		parsed, err := strconv.ParseInt(i, 10, 64)
 		if err != nil {
-			logger.Errorf("error could not parse validator indices: %v, %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error could not parse validator indices: %v, %v", r.URL.String(), err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -1560,7 +1648,7 @@ func UserDashboardWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	publicKeys := make([]string, 0)
-	db.DB.Select(&publicKeys, `
+	db.DB.SelectContext(r.Context(), &publicKeys, `
 	SELECT pubkeyhex as pubkey
 	FROM validators
 	WHERE validatorindex = ANY($1)
@@ -1574,9 +1662,20 @@ func UserDashboardWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 			Validator_publickey: key,
 		})
 	}
-	err = db.AddToWatchlist(watchListEntries, utils.GetNetwork())
+	exceeded, err := watchlistLimitExceeded(r, user.UserID, publicKeys)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving watchlist of user %v: %v", user.UserID, err)
+		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if exceeded {
+		ErrorOrJSONResponse(w, r, "watchlist limit of your plan reached", http.StatusForbidden)
+		return
+	}
+
+	err = db.AddToWatchlist(r.Context(), watchListEntries, utils.GetNetwork())
 	if err != nil {
-		logger.Errorf("error could not add validators to watchlist: %v, %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error could not add validators to watchlist: %v, %v", r.URL.String(), err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1621,9 +1720,9 @@ func UserValidatorWatchlistRemove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := db.RemoveFromWatchlist(user.UserID, pubKey, utils.GetNetwork())
+	err := db.RemoveFromWatchlist(r.Context(), user.UserID, pubKey, utils.GetNetwork())
 	if err != nil {
-		logger.Errorf("error deleting subscription: %v", err)
+		requestLogger(r).Errorf("error deleting subscription: %v", err)
 		FlashRedirectOrJSONErrorResponse(w, r,
 			validatorEditFlash,
 			"Error: Could not remove bookmark.",
@@ -1662,13 +1761,13 @@ func MultipleUsersNotificationsSubscribe(w http.ResponseWriter, r *http.Request)
 	var jsonObjects []SubIntent
 	err := json.Unmarshal(context.Get(r, utils.JsonBodyNakedKey).([]byte), &jsonObjects)
 	if err != nil {
-		logger.Errorf("Could not parse multiple notification subscription intent | %v", err)
+		requestLogger(r).Errorf("Could not parse multiple notification subscription intent | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not parse request")
 		return
 	}
 
 	if len(jsonObjects) > 100 {
-		logger.Errorf("Max number bundle subscribe is 100", err)
+		requestLogger(r).Errorf("Max number bundle subscribe is 100", err)
 		sendErrorResponse(j, r.URL.String(), "Max number bundle subscribe is 100")
 		return
 	}
@@ -1708,20 +1807,20 @@ func MultipleUsersNotificationsSubscribeWeb(w http.ResponseWriter, r *http.Reque
 	var jsonObjects []SubIntent
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logger.Errorf("error reading body %v URL: %v", err, r.URL.String())
+		requestLogger(r).Errorf("error reading body %v URL: %v", err, r.URL.String())
 		sendErrorResponse(j, r.URL.String(), "could not parse body")
 		return
 	}
 
 	err = json.Unmarshal(b, &jsonObjects)
 	if err != nil {
-		logger.Errorf("Could not parse multiple notification subscription intent | %v", err)
+		requestLogger(r).Errorf("Could not parse multiple notification subscription intent | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not parse request")
 		return
 	}
 
 	if len(jsonObjects) > 100 {
-		logger.Errorf("Max number bundle subscribe is 100", err)
+		requestLogger(r).Errorf("Max number bundle subscribe is 100", err)
 		sendErrorResponse(j, r.URL.String(), "Max number bundle subscribe is 100")
 		return
 	}
@@ -1757,7 +1856,7 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 
 	eventName, err := types.EventNameFromString(event)
 	if err != nil {
-		logger.Errorf("error invalid event name: %v event: %v", err, event)
+		requestLogger(r).Errorf("error invalid event name: %v event: %v", err, event)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return false
 	}
@@ -1766,7 +1865,7 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 	filterLen := len(filter)
 
 	if filterLen != 96 && filterLen != 0 && isPkey {
-		logger.Errorf("error invalid pubkey characters or length: %v", err)
+		requestLogger(r).Errorf("error invalid pubkey characters or length: %v", err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return false
 	}
@@ -1783,19 +1882,19 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 			Network:        utils.GetNetwork(),
 		}
 
-		myValidators, err2 := db.GetTaggedValidators(filter)
+		myValidators, err2 := db.GetTaggedValidators(r.Context(), filter)
 		if err2 != nil {
 			ErrorOrJSONResponse(w, r, "could not retrieve db results", http.StatusInternalServerError)
 			return false
 		}
 
-		maxValidators := userPremium.MaxValidators
+		maxValidators := getUserMaxNotificationValidators(r, user.UserID)
 
 		// not quite happy performance wise, placing a TODO here for future me
 		for i, v := range myValidators {
-			err = db.AddSubscription(user.UserID, utils.GetNetwork(), eventName, fmt.Sprintf("%v", hex.EncodeToString(v.ValidatorPublickey)), 0)
+			err = db.AddSubscription(r.Context(), user.UserID, utils.GetNetwork(), eventName, fmt.Sprintf("%v", hex.EncodeToString(v.ValidatorPublickey)), 0)
 			if err != nil {
-				logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
+				requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
 				ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 				return false
 			}
@@ -1818,9 +1917,9 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 		if eventName == types.EthClientUpdateEventName {
 			network = ""
 		}
-		err = db.AddSubscription(user.UserID, network, eventName, filter, threshold)
+		err = db.AddSubscription(r.Context(), user.UserID, network, eventName, filter, threshold)
 		if err != nil {
-			logger.Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
+			requestLogger(r).Errorf("error could not ADD subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return false
 		}
@@ -1841,13 +1940,13 @@ func MultipleUsersNotificationsUnsubscribe(w http.ResponseWriter, r *http.Reques
 	var jsonObjects []UnSubIntent
 	err := json.Unmarshal(context.Get(r, utils.JsonBodyNakedKey).([]byte), &jsonObjects)
 	if err != nil {
-		logger.Errorf("Could not parse multiple notification subscription intent | %v", err)
+		requestLogger(r).Errorf("Could not parse multiple notification subscription intent | %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not parse request")
 		return
 	}
 
 	if len(jsonObjects) > 100 {
-		logger.Errorf("Max number bundle unsubscribe is 100", err)
+		requestLogger(r).Errorf("Max number bundle unsubscribe is 100", err)
 		sendErrorResponse(j, r.URL.String(), "Max number bundle unsubscribe is 100")
 		return
 	}
@@ -1884,7 +1983,7 @@ func internUserNotificationsUnsubscribe(event, filter string, w http.ResponseWri
 
 	eventName, err := types.EventNameFromString(event)
 	if err != nil {
-		logger.Errorf("error invalid event name: %v event: %v", err, event)
+		requestLogger(r).Errorf("error invalid event name: %v event: %v", err, event)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return false
 	}
@@ -1893,7 +1992,7 @@ func internUserNotificationsUnsubscribe(event, filter string, w http.ResponseWri
 	filterLen := len(filter)
 
 	if len(filter) != 96 && filterLen != 0 && isPkey {
-		logger.Errorf("error invalid pubkey characters or length: %v", err)
+		requestLogger(r).Errorf("error invalid pubkey characters or length: %v", err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return false
 	}
@@ -1908,18 +2007,18 @@ func internUserNotificationsUnsubscribe(event, filter string, w http.ResponseWri
 			Network:        utils.GetNetwork(),
 		}
 
-		myValidators, err2 := db.GetTaggedValidators(filter)
+		myValidators, err2 := db.GetTaggedValidators(r.Context(), filter)
 		if err2 != nil {
 			ErrorOrJSONResponse(w, r, "could not retrieve db results", http.StatusInternalServerError)
 			return false
 		}
 
-		maxValidators := getUserPremium(r).MaxValidators
+		maxValidators := getUserMaxNotificationValidators(r, user.UserID)
 		// not quite happy performance wise, placing a TODO here for future me
 		for i, v := range myValidators {
-			err = db.DeleteSubscription(user.UserID, utils.GetNetwork(), eventName, fmt.Sprintf("%v", hex.EncodeToString(v.ValidatorPublickey)))
+			err = db.DeleteSubscription(r.Context(), user.UserID, utils.GetNetwork(), eventName, fmt.Sprintf("%v", hex.EncodeToString(v.ValidatorPublickey)))
 			if err != nil {
-				logger.Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
+				requestLogger(r).Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
 				ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 				return false
 			}
@@ -1930,9 +2029,9 @@ func internUserNotificationsUnsubscribe(event, filter string, w http.ResponseWri
 		}
 	} else {
 		// filtered one only
-		err = db.DeleteSubscription(user.UserID, utils.GetNetwork(), eventName, filter)
+		err = db.DeleteSubscription(r.Context(), user.UserID, utils.GetNetwork(), eventName, filter)
 		if err != nil {
-			logger.Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
+			requestLogger(r).Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return false
 		}
@@ -1953,7 +2052,7 @@ func UserNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 
 	eventName, err := types.EventNameFromString(event)
 	if err != nil {
-		logger.Errorf("error invalid event name: %v event: %v", err, event)
+		requestLogger(r).Errorf("error invalid event name: %v event: %v", err, event)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1962,7 +2061,7 @@ func UserNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	filterLen := len(filter)
 
 	if len(filter) != 96 && filterLen != 0 && isPkey {
-		logger.Errorf("error invalid pubkey characters or length: %v", err)
+		requestLogger(r).Errorf("error invalid pubkey characters or length: %v", err)
 		ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1977,19 +2076,19 @@ func UserNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 			Network:        utils.GetNetwork(),
 		}
 
-		myValidators, err2 := db.GetTaggedValidators(filter)
+		myValidators, err2 := db.GetTaggedValidators(r.Context(), filter)
 		if err2 != nil {
 			ErrorOrJSONResponse(w, r, "could not retrieve db results", http.StatusInternalServerError)
 			return
 		}
 
-		maxValidators := getUserPremium(r).MaxValidators
+		maxValidators := getUserMaxNotificationValidators(r, user.UserID)
 
 		// not quite happy performance wise, placing a TODO here for future me
 		for i, v := range myValidators {
-			err = db.DeleteSubscription(user.UserID, utils.GetNetwork(), eventName, fmt.Sprintf("%v", hex.EncodeToString(v.ValidatorPublickey)))
+			err = db.DeleteSubscription(r.Context(), user.UserID, utils.GetNetwork(), eventName, fmt.Sprintf("%v", hex.EncodeToString(v.ValidatorPublickey)))
 			if err != nil {
-				logger.Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
+				requestLogger(r).Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
 				ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 				return
 			}
@@ -2000,9 +2099,9 @@ func UserNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// filtered one only
-		err = db.DeleteSubscription(user.UserID, utils.GetNetwork(), eventName, filter)
+		err = db.DeleteSubscription(r.Context(), user.UserID, utils.GetNetwork(), eventName, filter)
 		if err != nil {
-			logger.Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
+			requestLogger(r).Errorf("error could not REMOVE subscription for user %v eventName %v eventfilter %v: %v", user.UserID, eventName, filter, err)
 			ErrorOrJSONResponse(w, r, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -2045,7 +2144,7 @@ func UserNotificationsSubscribed(w http.ResponseWriter, r *http.Request) {
 
 	err := decoder.Decode(req)
 	if err != nil && err != io.EOF {
-		logger.WithError(err).Error("error decoding request body")
+		requestLogger(r).WithError(err).Error("error decoding request body")
 		sendErrorResponse(j, r.URL.String(), "error decoding request body")
 		return
 	}
@@ -2099,7 +2198,7 @@ func UserNotificationsSubscribed(w http.ResponseWriter, r *http.Request) {
 	for _, en := range names {
 		n, err := types.EventNameFromString(en)
 		if err != nil {
-			logger.WithError(err).Errorf("error parsing provided event %v to a known event name type", en)
+			requestLogger(r).WithError(err).Errorf("error parsing provided event %v to a known event name type", en)
 			sendErrorResponse(j, r.URL.String(), "error invalid event name provided")
 		}
 		eventNames = append(eventNames, n)
@@ -2118,7 +2217,7 @@ func UserNotificationsSubscribed(w http.ResponseWriter, r *http.Request) {
 		JoinValidator: joinValidators,
 	}
 
-	subs, err := db.GetSubscriptions(queryFilter)
+	subs, err := db.GetSubscriptions(r.Context(), queryFilter)
 	if err != nil {
 		sendErrorResponse(j, r.URL.String(), "not authenticated")
 		return
@@ -2140,7 +2239,7 @@ func MobileDeviceDeletePOST(w http.ResponseWriter, r *http.Request) {
 		customDeviceID := FormValueOrJSON(r, "id")
 		temp, err := strconv.ParseUint(customDeviceID, 10, 64)
 		if err != nil {
-			logger.Errorf("error parsing id %v | err: %v", customDeviceID, err)
+			requestLogger(r).Errorf("error parsing id %v | err: %v", customDeviceID, err)
 			sendErrorResponse(j, r.URL.String(), "could not parse id")
 			return
 		}
@@ -2156,9 +2255,9 @@ func MobileDeviceDeletePOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := db.MobileDeviceDelete(userID, userDeviceID)
+	err := db.MobileDeviceDelete(r.Context(), userID, userDeviceID)
 	if err != nil {
-		logger.Errorf("could not retrieve db results err: %v", err)
+		requestLogger(r).Errorf("could not retrieve db results err: %v", err)
 		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
 		return
 	}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// userDeletionDelay is the time a user has to cancel the deletion of their account
+const userDeletionDelay = time.Hour * 24 * 30
+
+// UserDataExport returns all personal data stored about the user as a json file
+func UserDataExport(w http.ResponseWriter, r *http.Request) {
+	user, _, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	export, err := db.GetUserDataExport(r.Context(), user.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error exporting data of user %v: %v", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	export["exported_ts"] = time.Now().Unix()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"beaconchain-account-%v.json\"", time.Now().Format("2006-01-02")))
+	err = json.NewEncoder(w).Encode(export)
+	if err != nil {
+		requestLogger(r).Errorf("error encoding data export of user %v: %v", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserCancelDeletionPost cancels the pending deletion of the account of the user
+func UserCancelDeletionPost(w http.ResponseWriter, r *http.Request) {
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = db.CancelUserDeletion(r.Context(), user.UserID)
+	if err != nil {
+		requestLogger(r).Errorf("error cancelling deletion of user %v: %v", user.UserID, err)
+		session.AddFlash(authInternalServerErrorFlashMsg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+		return
+	}
+
+	session.AddFlash("The deletion of your account has been cancelled.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/mssola/user_agent"
+)
+
+// sessionDeviceName describes the browser and operating system of the user agent, e.g. "Firefox on Linux x86_64"
+func sessionDeviceName(userAgent string) string {
+	ua := user_agent.New(userAgent)
+	browser, _ := ua.Browser()
+	name := browser
+	if os := ua.OS(); os != "" {
+		name = fmt.Sprintf("%v on %v", browser, os)
+	}
+	if name == "" {
+		name = "Unknown"
+	}
+	if len(name) > 50 {
+		name = name[:50]
+	}
+	return name
+}
+
+// startUserSession stores a new session of the user and sets its key in the session cookie, the session values have to
+// be saved by the caller
+func startUserSession(r *http.Request, session *sessions.Session, userID uint64) error {
+	key, err := db.CreateUserSession(r.Context(), userID, sessionDeviceName(r.UserAgent()), r.UserAgent(), clientIP(r))
+	if err != nil {
+		return err
+	}
+	session.Values["session_key"] = key
+	return nil
+}
+
+// userSessionActive returns whether the session of the cookie has not been revoked
+func userSessionActive(r *http.Request, session *sessions.Session, userID uint64) bool {
+	key, ok := session.Values["session_key"].(string)
+	if !ok || key == "" {
+		return false
+	}
+	active, err := db.TouchUserSession(r.Context(), userID, key, clientIP(r))
+	if err != nil {
+		requestLogger(r).Errorf("error checking session of user %v: %v", userID, err)
+		return false
+	}
+	return active
+}
+
+// sessionKey returns the key of the session of the cookie
+func sessionKey(session *sessions.Session) string {
+	key, _ := session.Values["session_key"].(string)
+	return key
+}
+
+// UserSessionsData returns the active sessions of the user as json
+func UserSessionsData(w http.ResponseWriter, r *http.Request) {
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	userSessions, err := db.GetUserSessions(r.Context(), user.UserID, sessionKey(session))
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving sessions of user %v: %v", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(userSessions)
+	if err != nil {
+		requestLogger(r).Errorf("error encoding sessions of user %v: %v", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserSessionRevokePost ends a session of the user, e.g. the login on a lost device
+func UserSessionRevokePost(w http.ResponseWriter, r *http.Request) {
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid session id", http.StatusBadRequest)
+		return
+	}
+
+	err = db.RevokeUserSession(r.Context(), user.UserID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			session.AddFlash("Error: The session does not exist anymore.")
+		} else {
+			requestLogger(r).Errorf("error revoking session %v of user %v: %v", id, user.UserID, err)
+			session.AddFlash(authInternalServerErrorFlashMsg)
+		}
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+		return
+	}
+
+	session.AddFlash("The session has been logged out.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+}
+
+// UserSessionsRevokeOthersPost ends all sessions of the user except the one of the request
+func UserSessionsRevokeOthersPost(w http.ResponseWriter, r *http.Request) {
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = db.RevokeUserSessions(r.Context(), user.UserID, sessionKey(session))
+	if err != nil {
+		requestLogger(r).Errorf("error revoking sessions of user %v: %v", user.UserID, err)
+		session.AddFlash(authInternalServerErrorFlashMsg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+		return
+	}
+
+	session.AddFlash("All other sessions have been logged out.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/user/settings", http.StatusSeeOther)
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// userTagNameRegex restricts tag names so they can be used in urls and query parameters as they are
+var userTagNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,50}$`)
+
+// userTagsLimit is the maximum number of tags a user can define per network
+const userTagsLimit = 100
+
+func parseUserTagName(name string) (string, error) {
+	if !userTagNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid tag name, a tag name consists of 1 to 50 letters, digits, - and _")
+	}
+	return name, nil
+}
+
+// sendUserTagError sends the error response of a failed tag operation, errors other than a missing tag are logged
+func sendUserTagError(j *json.Encoder, r *http.Request, err error, message string) {
+	if err == db.ErrUserTagNotFound {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	requestLogger(r).Errorf("%v for route %v: %v", message, r.URL.String(), err)
+	sendErrorResponse(j, r.URL.String(), message)
+}
+
+// ApiUserTags godoc
+// @Summary Get the validator tags of the user and the number of validators of each tag
+// @Tags User
+// @Produce  json
+// @Success 200 {object} types.ApiResponse{data=[]types.UserValidatorTag}
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags [get]
+func ApiUserTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	claims := getAuthClaims(r)
+	tags, err := db.GetUserTags(r.Context(), claims.UserID, utils.GetNetwork())
+	if err != nil {
+		sendUserTagError(j, r, err, "could not retrieve tags")
+		return
+	}
+
+	data := make([]interface{}, 0, len(tags))
+	for _, t := range tags {
+		data = append(data, t)
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiUserTagCreate godoc
+// @Summary Create a validator tag
+// @Tags User
+// @Produce  json
+// @Param name body string true "Name of the tag, 1 to 50 letters, digits, - and _"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags [post]
+func ApiUserTagCreate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	name, err := parseUserTagName(FormValueOrJSON(r, "name"))
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	claims := getAuthClaims(r)
+	count, err := db.CountUserTags(r.Context(), claims.UserID, utils.GetNetwork())
+	if err != nil {
+		sendUserTagError(j, r, err, "could not create tag")
+		return
+	}
+	if count >= userTagsLimit {
+		sendErrorResponse(j, r.URL.String(), fmt.Sprintf("only a maximum of %v tags can be created", userTagsLimit))
+		return
+	}
+
+	err = db.CreateUserTag(r.Context(), claims.UserID, utils.GetNetwork(), name)
+	if err != nil {
+		sendUserTagError(j, r, err, "could not create tag")
+		return
+	}
+	OKResponse(w, r)
+}
+
+// ApiUserTagDelete godoc
+// @Summary Delete a validator tag, its validators are unassigned
+// @Tags User
+// @Produce  json
+// @Param tag path string true "Name of the tag"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags/{tag}/delete [post]
+func ApiUserTagDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	name, err := parseUserTagName(mux.Vars(r)["tag"])
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	claims := getAuthClaims(r)
+	err = db.DeleteUserTag(r.Context(), claims.UserID, utils.GetNetwork(), name)
+	if err != nil {
+		sendUserTagError(j, r, err, "could not delete tag")
+		return
+	}
+	OKResponse(w, r)
+}
+
+// ApiUserTagShare godoc
+// @Summary Make the dashboard of a validator tag public, everyone with the returned link can view the dashboard of the validators of the tag without logging in
+// @Tags User
+// @Produce  json
+// @Param tag path string true "Name of the tag"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags/{tag}/share [post]
+func ApiUserTagShare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	name, err := parseUserTagName(mux.Vars(r)["tag"])
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	claims := getAuthClaims(r)
+	shareID, err := db.ShareUserTag(r.Context(), claims.UserID, utils.GetNetwork(), name, utils.RandomString(sharedDashboardIDLength))
+	if err != nil {
+		sendUserTagError(j, r, err, "could not share tag")
+		return
+	}
+	url := fmt.Sprintf("https://%v/dashboard/shared/%v", utils.Config.Frontend.SiteDomain, shareID)
+	sendOKResponse(j, r.URL.String(), []interface{}{map[string]string{
+		"share_id":  shareID,
+		"url":       url,
+		"embed_url": url + "/embed",
+	}})
+}
+
+// ApiUserTagUnshare godoc
+// @Summary Make the dashboard of a validator tag private again, the shared links stop working
+// @Tags User
+// @Produce  json
+// @Param tag path string true "Name of the tag"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags/{tag}/unshare [post]
+func ApiUserTagUnshare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	name, err := parseUserTagName(mux.Vars(r)["tag"])
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	claims := getAuthClaims(r)
+	err = db.UnshareUserTag(r.Context(), claims.UserID, utils.GetNetwork(), name)
+	if err != nil {
+		sendUserTagError(j, r, err, "could not unshare tag")
+		return
+	}
+	OKResponse(w, r)
+}
+
+// ApiUserTagValidators godoc
+// @Summary Get the validators of a validator tag
+// @Tags User
+// @Produce  json
+// @Param tag path string true "Name of the tag"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags/{tag} [get]
+func ApiUserTagValidators(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	name, err := parseUserTagName(mux.Vars(r)["tag"])
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	claims := getAuthClaims(r)
+	pubkeys, err := db.GetUserTagValidators(r.Context(), claims.UserID, utils.GetNetwork(), name)
+	if err != nil {
+		sendUserTagError(j, r, err, "could not retrieve validators of tag")
+		return
+	}
+
+	// validators that have not been activated yet are not in the validators table and have no index
+	indices, err := db.GetValidatorIndicesOfPubkeys(r.Context(), pq.ByteaArray(pubkeys))
+	if err != nil {
+		sendUserTagError(j, r, err, "could not retrieve validators of tag")
+		return
+	}
+
+	data := make([]interface{}, 0, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		entry := map[string]interface{}{
+			"pubkey":         fmt.Sprintf("0x%x", pubkey),
+			"validatorindex": nil,
+		}
+		if index, exists := indices[string(pubkey)]; exists {
+			entry["validatorindex"] = index
+		}
+		data = append(data, entry)
+	}
+	sendOKResponse(j, r.URL.String(), data)
+}
+
+// ApiUserTagAddValidators godoc
+// @Summary Assign validators to a validator tag, a tag can have as many validators as the dashboard of the user
+// @Tags User
+// @Produce  json
+// @Param tag path string true "Name of the tag"
+// @Param validators body string true "Comma separated validator indices or public keys"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags/{tag}/add [post]
+func ApiUserTagAddValidators(w http.ResponseWriter, r *http.Request) {
+	apiUserTagUpdateValidators(w, r, true)
+}
+
+// ApiUserTagRemoveValidators godoc
+// @Summary Unassign validators from a validator tag
+// @Tags User
+// @Produce  json
+// @Param tag path string true "Name of the tag"
+// @Param validators body string true "Comma separated validator indices or public keys"
+// @Success 200 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/validator/tags/{tag}/remove [post]
+func ApiUserTagRemoveValidators(w http.ResponseWriter, r *http.Request) {
+	apiUserTagUpdateValidators(w, r, false)
+}
+
+func apiUserTagUpdateValidators(w http.ResponseWriter, r *http.Request, add bool) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	name, err := parseUserTagName(mux.Vars(r)["tag"])
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	maxValidators := getUserPremium(r).MaxValidators
+	indices, pubkeys, err := parseApiValidatorParam(FormValueOrJSON(r, "validators"), maxValidators)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+	// validators given by public key are kept even if they are not in the validators table yet (e.g. pending deposits)
+	resolved, err := db.GetValidatorPubkeys(r.Context(), indices, nil)
+	if err != nil {
+		sendUserTagError(j, r, err, "could not resolve validators")
+		return
+	}
+	resolved = append(resolved, pubkeys...)
+	if len(resolved) == 0 {
+		sendErrorResponse(j, r.URL.String(), "no validators found")
+		return
+	}
+
+	claims := getAuthClaims(r)
+	if add {
+		err = db.AddValidatorsToUserTag(r.Context(), claims.UserID, utils.GetNetwork(), name, resolved, maxValidators)
+	} else {
+		err = db.RemoveValidatorsFromUserTag(r.Context(), claims.UserID, utils.GetNetwork(), name, resolved)
+	}
+	if err != nil {
+		sendUserTagError(j, r, err, "could not update validators of tag")
+		return
+	}
+	OKResponse(w, r)
+}
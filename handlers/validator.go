@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/csrf"
 	"github.com/lib/pq"
 
 	"github.com/gorilla/mux"
@@ -32,6 +33,8 @@ var validatorTemplate = template.Must(template.New("validator").Funcs(utils.GetT
 	"templates/validator/overview.html",
 	"templates/validator/charts.html",
 	"templates/validator/countdown.html",
+	"templates/validator/projection.html",
+	"templates/validator/peers.html",
 
 	"templates/components/flashMessage.html",
 	"templates/components/rocket.html",
@@ -71,10 +74,11 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	data.HeaderAd = true
 	validatorPageData.NetworkStats = services.LatestIndexPageData()
 	validatorPageData.User = data.User
+	validatorPageData.CsrfField = csrf.TemplateField(r)
 
 	validatorPageData.FlashMessage, err = utils.GetFlash(w, r, validatorEditFlash)
 	if err != nil {
-		logger.Errorf("error retrieving flashes for validator %v: %v", vars["index"], err)
+		requestLogger(r).Errorf("error retrieving flashes for validator %v: %v", vars["index"], err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -83,18 +87,18 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	if strings.Contains(vars["index"], "0x") || len(vars["index"]) == 96 {
 		pubKey, err := hex.DecodeString(strings.Replace(vars["index"], "0x", "", -1))
 		if err != nil {
-			logger.Errorf("error parsing validator public key %v: %v", vars["index"], err)
+			requestLogger(r).Errorf("error parsing validator public key %v: %v", vars["index"], err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
-		index, err = db.GetValidatorIndex(pubKey)
+		index, err = db.GetValidatorIndex(r.Context(), pubKey)
 		if err != nil {
 			// the validator might only have a public key but no index yet
 			var name string
-			err = db.DB.Get(&name, `SELECT name FROM validator_names WHERE publickey = $1`, pubKey)
+			err = db.DB.GetContext(r.Context(), &name, `SELECT name FROM validator_names WHERE publickey = $1`, pubKey)
 			if err != nil {
 				if err != sql.ErrNoRows {
-					logger.Errorf("error getting validator-name from db for pubKey %v: %v", pubKey, err)
+					requestLogger(r).Errorf("error getting validator-name from db for pubKey %v: %v", pubKey, err)
 					http.Error(w, "Internal server error", http.StatusInternalServerError)
 					return
 				}
@@ -102,9 +106,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			} else {
 				validatorPageData.Name = name
 			}
-			deposits, err := db.GetValidatorDeposits(pubKey)
+			deposits, err := db.GetValidatorDeposits(r.Context(), pubKey)
 			if err != nil {
-				logger.Errorf("error getting validator-deposits from db: %v", err)
+				requestLogger(r).Errorf("error getting validator-deposits from db: %v", err)
 			}
 			validatorPageData.DepositsCount = uint64(len(deposits.Eth1Deposits))
 			if err != nil || len(deposits.Eth1Deposits) == 0 {
@@ -112,7 +116,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 				data.Meta.Path = fmt.Sprintf("/validator/%v", index)
 				err := validatorNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 				if err != nil {
-					logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+					requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 					http.Error(w, "Internal server error", http.StatusInternalServerError)
 					return
 				}
@@ -139,7 +143,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 
 			if *churnRate == 0 {
 				*churnRate = 4
-				logger.Warning("Churn rate not set in config using 4 as default please set minPerEpochChurnLimit")
+				requestLogger(r).Warning("Churn rate not set in config using 4 as default please set minPerEpochChurnLimit")
 			}
 			activationEstimate := (*pendingCount / *churnRate)*(utils.Config.Chain.Phase0.SecondsPerSlot*utils.Config.Chain.Phase0.SlotsPerEpoch) + uint64(latestDeposit)
 			validatorPageData.EstimatedActivationTs = int64(activationEstimate)
@@ -166,6 +170,18 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 				validatorPageData.Status = "deposited_valid"
 			}
 
+			pendingDeposit, err := db.GetEth1PendingDeposit(r.Context(), pubKey)
+			if err != nil {
+				requestLogger(r).Errorf("error getting pending deposit of validator %x from db: %v", pubKey, err)
+			} else if pendingDeposit != nil {
+				validatorPageData.PendingDeposit = pendingDeposit
+				validatorPageData.EstimatedInclusionTs = utils.EpochToTime(pendingDeposit.EstimatedInclusionEpoch)
+				if pendingDeposit.EstimatedActivationEpoch != nil {
+					validatorPageData.EstimatedActivationEpoch = *pendingDeposit.EstimatedActivationEpoch
+					validatorPageData.EstimatedActivationTs = utils.EpochToTime(*pendingDeposit.EstimatedActivationEpoch).Unix()
+				}
+			}
+
 			filter := db.WatchlistFilter{
 				UserId:         data.User.UserID,
 				Validators:     &pq.ByteaArray{validatorPageData.PublicKey},
@@ -173,9 +189,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 				JoinValidators: false,
 				Network:        utils.GetNetwork(),
 			}
-			watchlist, err := db.GetTaggedValidators(filter)
+			watchlist, err := db.GetTaggedValidators(r.Context(), filter)
 			if err != nil {
-				logger.Errorf("error getting tagged validators from db: %v", err)
+				requestLogger(r).Errorf("error getting tagged validators from db: %v", err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
@@ -189,7 +205,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 				err = validatorTemplate.ExecuteTemplate(w, "layout", data)
 			}
 			if err != nil {
-				logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+				requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
@@ -209,17 +225,19 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 
 	data.Meta.Title = fmt.Sprintf("%v - Validator %v - beaconcha.in - %v", utils.Config.Frontend.SiteName, index, time.Now().Year())
 	data.Meta.Path = fmt.Sprintf("/validator/%v", index)
+	data.Meta.Image = previewImageURL(data.Meta.Path)
 
-	// logger.Infof("retrieving data, elapsed: %v", time.Since(start))
+	// requestLogger(r).Infof("retrieving data, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
 	// we use MAX(validatorindex)+1 instead of COUNT(*) for querying the rank_count for performance-reasons
-	err = db.DB.Get(&validatorPageData, `
+	err = db.DB.GetContext(r.Context(), &validatorPageData, `
 		SELECT
 			validators.pubkey,
 			validators.validatorindex,
 			validators.withdrawableepoch,
 			validators.effectivebalance,
+			validators.withdrawalcredentials,
 			validators.slashed,
 			validators.activationeligibilityepoch,
 			validators.activationepoch,
@@ -243,13 +261,13 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	if err == sql.ErrNoRows {
 		err = validatorNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 		return
 	} else if err != nil {
-		logger.Errorf("error getting validator for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error getting validator for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -261,12 +279,12 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	validatorPageData.Epoch = services.LatestEpoch()
 	validatorPageData.Index = index
 	if err != nil {
-		logger.Errorf("error retrieving validator public key %v: %v", index, err)
+		requestLogger(r).Errorf("error retrieving validator public key %v: %v", index, err)
 
 		err := validatorNotFoundTemplate.ExecuteTemplate(w, "layout", data)
 
 		if err != nil {
-			logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+			requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -281,18 +299,43 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		Network:        utils.GetNetwork(),
 	}
 
-	watchlist, err := db.GetTaggedValidators(filter)
+	watchlist, err := db.GetTaggedValidators(r.Context(), filter)
 	if err != nil {
-		logger.Errorf("error getting tagged validators from db: %v", err)
+		requestLogger(r).Errorf("error getting tagged validators from db: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	validatorPageData.Watchlist = watchlist
 
-	deposits, err := db.GetValidatorDeposits(validatorPageData.PublicKey)
+	validatorPageData.OwnerVerified, err = db.IsValidatorOwnerVerified(r.Context(), validatorPageData.PublicKey)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator ownership verification: %v", err)
+	}
+	if data.User.Authenticated {
+		ownership, err := db.GetValidatorOwnership(r.Context(), data.User.UserID, validatorPageData.PublicKey)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving validator ownership of user %v: %v", data.User.UserID, err)
+		}
+		validatorPageData.OwnedByUser = ownership != nil
+		if validatorPageData.OwnedByUser {
+			validatorPageData.Hosting, err = db.GetValidatorHosting(r.Context(), data.User.UserID, validatorPageData.PublicKey)
+			if err != nil {
+				requestLogger(r).Errorf("error retrieving validator hosting of user %v: %v", data.User.UserID, err)
+			}
+			validatorPageData.HostingProviders = db.HostingProviders()
+			validatorPageData.HostingRegions = db.HostingRegions
+		}
+	}
+
+	validatorPageData.ConsolidatedInto, err = db.GetValidatorConsolidationTarget(r.Context(), validatorPageData.PublicKey)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving consolidation target of validator %v: %v", validatorPageData.Index, err)
+	}
+
+	deposits, err := db.GetValidatorDeposits(r.Context(), validatorPageData.PublicKey)
 	if err != nil {
-		logger.Errorf("error getting validator-deposits from db: %v", err)
+		requestLogger(r).Errorf("error getting validator-deposits from db: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -311,14 +354,30 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	validatorPageData.ExitTs = utils.EpochToTime(validatorPageData.ExitEpoch)
 	validatorPageData.WithdrawableTs = utils.EpochToTime(validatorPageData.WithdrawableEpoch)
 
+	// the sweep only withdraws validators with execution withdrawal credentials
+	if validatorPageData.ExitEpoch != 9223372036854775807 && len(validatorPageData.WithdrawalCredentials) > 0 && validatorPageData.WithdrawalCredentials[0] != 0x00 {
+		validatorPageData.FullWithdrawal, err = db.GetValidatorFullWithdrawal(r.Context(), validatorPageData.Index, validatorPageData.WithdrawableEpoch*utils.Config.Chain.SlotsPerEpoch)
+		if err != nil {
+			requestLogger(r).Errorf("error retrieving full withdrawal of validator %v: %v", validatorPageData.Index, err)
+		}
+		if validatorPageData.FullWithdrawal != nil {
+			validatorPageData.FullWithdrawal.Ts = utils.SlotToTime(validatorPageData.FullWithdrawal.Slot)
+		} else if validatorPageData.CurrentBalance > 0 {
+			validatorPageData.FullWithdrawal, err = services.EstimateFullWithdrawal(r.Context(), validatorPageData.Index, validatorPageData.WithdrawableEpoch)
+			if err != nil {
+				requestLogger(r).Errorf("error estimating full withdrawal of validator %v: %v", validatorPageData.Index, err)
+			}
+		}
+	}
+
 	proposals := []struct {
 		Slot   uint64
 		Status uint64
 	}{}
 
-	err = db.DB.Select(&proposals, "SELECT slot, status FROM blocks WHERE proposer = $1 ORDER BY slot", index)
+	err = db.DB.SelectContext(r.Context(), &proposals, "SELECT slot, status FROM blocks WHERE proposer = $1 ORDER BY slot", index)
 	if err != nil {
-		logger.Errorf("error retrieving block-proposals: %v", err)
+		requestLogger(r).Errorf("error retrieving block-proposals: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -347,7 +406,38 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		validatorPageData.UnmissedBlocksPercentage = 1.0
 	}
 
-	// logger.Infof("propoals data retrieved, elapsed: %v", time.Since(start))
+	validatorPageData.FeeRecipients, err = db.GetValidatorFeeRecipients(r.Context(), []uint64{index})
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving fee recipients of validator %v: %v", index, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	blockPropagation, err := db.GetProposerBlockPropagation(r.Context(), []uint64{index}, nil)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block propagation of validator %v: %v", index, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if len(blockPropagation) > 0 {
+		validatorPageData.BlockPropagation = blockPropagation[0]
+	}
+
+	validatorPageData.Packing, err = db.GetValidatorPacking(r.Context(), index)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving block packing of validator %v: %v", index, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	validatorPageData.PeerComparison, err = db.GetValidatorPeerComparison(r.Context(), index, validatorPeerComparisonDays)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving peer comparison of validator %v: %v", index, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// requestLogger(r).Infof("propoals data retrieved, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
 	// Every validator is scheduled to issue an attestation once per epoch
@@ -362,9 +452,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var lastStatsDay uint64
-	err = db.DB.Get(&lastStatsDay, "select coalesce(max(day),0) from validator_stats")
+	err = db.DB.GetContext(r.Context(), &lastStatsDay, "select coalesce(max(day),0) from validator_stats")
 	if err != nil {
-		logger.Errorf("error retrieving lastStatsDay: %v", err)
+		requestLogger(r).Errorf("error retrieving lastStatsDay: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -376,9 +466,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			OrphanedAttestations uint64 `db:"orphaned_attestations"`
 		}{}
 		if lastStatsDay > 0 {
-			err = db.DB.Get(&attestationStats, "select coalesce(sum(missed_attestations), 0) as missed_attestations, coalesce(sum(orphaned_attestations), 0) as orphaned_attestations from validator_stats where validatorindex = $1", index)
+			err = db.DB.GetContext(r.Context(), &attestationStats, "select coalesce(sum(missed_attestations), 0) as missed_attestations, coalesce(sum(orphaned_attestations), 0) as orphaned_attestations from validator_stats where validatorindex = $1", index)
 			if err != nil {
-				logger.Errorf("error retrieving validator attestationStats: %v", err)
+				requestLogger(r).Errorf("error retrieving validator attestationStats: %v", err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
@@ -389,9 +479,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			MissedAttestations   uint64 `db:"missed_attestations"`
 			OrphanedAttestations uint64 `db:"orphaned_attestations"`
 		}{}
-		err = db.DB.Get(&attestationStatsNotInStats, "select coalesce(sum(case when status = 0 then 1 else 0 end), 0) as missed_attestations, coalesce(sum(case when status = 3 then 1 else 0 end), 0) as orphaned_attestations from attestation_assignments_p where week >= $1/7 and epoch >= ($1+1)*225 and epoch < $2 and validatorindex = $3", lastStatsDay, services.LatestEpoch(), index)
+		err = db.DB.GetContext(r.Context(), &attestationStatsNotInStats, "select coalesce(sum(case when status = 0 then 1 else 0 end), 0) as missed_attestations, coalesce(sum(case when status = 3 then 1 else 0 end), 0) as orphaned_attestations from attestation_assignments_p where week >= $1/7 and epoch >= ($1+1)*225 and epoch < $2 and validatorindex = $3", lastStatsDay, services.LatestEpoch(), index)
 		if err != nil {
-			logger.Errorf("error retrieving validator attestationStatsAfterLastStatsDay: %v", err)
+			requestLogger(r).Errorf("error retrieving validator attestationStatsAfterLastStatsDay: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -401,13 +491,13 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		validatorPageData.UnmissedAttestationsPercentage = float64(validatorPageData.AttestationsCount-validatorPageData.MissedAttestationsCount) / float64(validatorPageData.AttestationsCount)
 	}
 
-	// logger.Infof("attestations data retrieved, elapsed: %v", time.Since(start))
+	// requestLogger(r).Infof("attestations data retrieved, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
 	var incomeHistory []*types.ValidatorIncomeHistory
-	err = db.DB.Select(&incomeHistory, "select day, coalesce(start_balance, 0) as start_balance, coalesce(end_balance, 0) as end_balance, coalesce(deposits_amount, 0) as deposits_amount from validator_stats where validatorindex = $1 order by day;", index)
+	err = db.DB.SelectContext(r.Context(), &incomeHistory, "select day, coalesce(start_balance, 0) as start_balance, coalesce(end_balance, 0) as end_balance, coalesce(deposits_amount, 0) as deposits_amount from validator_stats where validatorindex = $1 order by day;", index)
 	if err != nil {
-		logger.Errorf("error retrieving validator balance history: %v", err)
+		requestLogger(r).Errorf("error retrieving validator balance history: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -462,22 +552,22 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		validatorPageData.IncomeHistoryChartData = []*types.ChartDataPoint{}
 	}
 
-	// logger.Infof("balance history retrieved, elapsed: %v", time.Since(start))
+	// requestLogger(r).Infof("balance history retrieved, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
-	earnings, err := GetValidatorEarnings([]uint64{index}, GetCurrency(r))
+	earnings, err := GetValidatorEarnings(r.Context(), []uint64{index}, GetCurrency(r))
 	if err != nil {
-		logger.Errorf("error retrieving validator earnings: %v", err)
+		requestLogger(r).Errorf("error retrieving validator earnings: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	validatorPageData.Income1d = earnings.LastDay
-	validatorPageData.Income7d = earnings.LastWeek
-	validatorPageData.Income31d = earnings.LastMonth
+	validatorPageData.Income1d = earnings.Income.LastDay
+	validatorPageData.Income7d = earnings.Income.LastWeek
+	validatorPageData.Income31d = earnings.Income.LastMonth
 	validatorPageData.Apr = earnings.APR
 
-	// logger.Infof("income data retrieved, elapsed: %v", time.Since(start))
+	// requestLogger(r).Infof("income data retrieved, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
 	if validatorPageData.Slashed {
@@ -486,7 +576,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			Slasher uint64
 			Reason  string
 		}
-		err = db.DB.Get(&slashingInfo,
+		err = db.DB.GetContext(r.Context(), &slashingInfo,
 			`select block_slot as slot, proposer as slasher, 'Attestation Violation' as reason
 				from blocks_attesterslashings a1 left join blocks b1 on b1.slot = a1.block_slot
 				where b1.status = '1' and $1 = ANY(a1.attestation1_indices) and $1 = ANY(a1.attestation2_indices)
@@ -497,7 +587,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			limit 1`,
 			index)
 		if err != nil {
-			logger.Errorf("error retrieving validator slashing info: %v", err)
+			requestLogger(r).Errorf("error retrieving validator slashing info: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -506,17 +596,17 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		validatorPageData.SlashedFor = slashingInfo.Reason
 	}
 
-	err = db.DB.Get(&validatorPageData.SlashingsCount, `select COALESCE(sum(attesterslashingscount) + sum(proposerslashingscount), 0) from blocks where blocks.proposer = $1 and blocks.status = '1'`, index)
+	err = db.DB.GetContext(r.Context(), &validatorPageData.SlashingsCount, `select COALESCE(sum(attesterslashingscount) + sum(proposerslashingscount), 0) from blocks where blocks.proposer = $1 and blocks.status = '1'`, index)
 	if err != nil {
-		logger.Errorf("error retrieving slashings-count: %v", err)
+		requestLogger(r).Errorf("error retrieving slashings-count: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	// logger.Infof("slashing data retrieved, elapsed: %v", time.Since(start))
+	// requestLogger(r).Infof("slashing data retrieved, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
-	err = db.DB.Get(&validatorPageData.AverageAttestationInclusionDistance, `
+	err = db.DB.GetContext(r.Context(), &validatorPageData.AverageAttestationInclusionDistance, `
 		SELECT COALESCE(
 			AVG(1 + inclusionslot - COALESCE((
 				SELECT MIN(slot)
@@ -529,7 +619,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		WHERE aa.week >= $1 / 1575 AND aa.epoch > $1 AND aa.validatorindex = $2 AND aa.inclusionslot > 0
 		`, int64(validatorPageData.Epoch)-100, index)
 	if err != nil {
-		logger.Errorf("error retrieving AverageAttestationInclusionDistance: %v", err)
+		requestLogger(r).Errorf("error retrieving AverageAttestationInclusionDistance: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -538,12 +628,31 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		validatorPageData.AttestationInclusionEffectiveness = 1.0 / validatorPageData.AverageAttestationInclusionDistance * 100
 	}
 
+	effectivenessStartEpoch := uint64(0)
+	if validatorPageData.Epoch > 99 {
+		effectivenessStartEpoch = validatorPageData.Epoch - 99
+	}
+	effectiveness, err := db.GetValidatorsEffectiveness(r.Context(), []uint64{index}, effectivenessStartEpoch, validatorPageData.Epoch)
+	if err != nil {
+		requestLogger(r).Errorf("error computing effectiveness of validator %v: %v", index, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	validatorPageData.Effectiveness = effectiveness[index]
+	validatorPageData.EffectivenessFormula = db.DefaultEffectivenessFormula
+	for _, e := range validatorPageData.Effectiveness {
+		if e.Formula.Name == r.URL.Query().Get("effectiveness") {
+			validatorPageData.EffectivenessFormula = e.Formula.Name
+			validatorPageData.AttestationInclusionEffectiveness = e.Effectiveness
+		}
+	}
+
 	var attestationStreaks []struct {
 		Length uint64
 	}
-	err = db.DB.Select(&attestationStreaks, `select greatest(0,length) as length from validator_attestation_streaks where validatorindex = $1 and status = 1 order by start desc`, index)
+	err = db.DB.SelectContext(r.Context(), &attestationStreaks, `select greatest(0,length) as length from validator_attestation_streaks where validatorindex = $1 and status = 1 order by start desc`, index)
 	if err != nil {
-		logger.Errorf("error retrieving AttestationStreaks: %v", err)
+		requestLogger(r).Errorf("error retrieving AttestationStreaks: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -555,12 +664,12 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		validatorPageData.LongestAttestationStreak = attestationStreaks[0].Length
 	}
 
-	// logger.Infof("effectiveness data retrieved, elapsed: %v", time.Since(start))
+	// requestLogger(r).Infof("effectiveness data retrieved, elapsed: %v", time.Since(start))
 	// start = time.Now()
 
-	err = db.DB.Get(&validatorPageData.SyncCount, `SELECT count(*)*$1 FROM sync_committees WHERE validatorindex = $2`, utils.Config.Chain.EpochsPerSyncCommitteePeriod*utils.Config.Chain.SlotsPerEpoch, index)
+	err = db.DB.GetContext(r.Context(), &validatorPageData.SyncCount, `SELECT count(*)*$1 FROM sync_committees WHERE validatorindex = $2`, utils.Config.Chain.EpochsPerSyncCommitteePeriod*utils.Config.Chain.SlotsPerEpoch, index)
 	if err != nil {
-		logger.Errorf("error retrieving syncCount for validator %v: %v", index, err)
+		requestLogger(r).Errorf("error retrieving syncCount for validator %v: %v", index, err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -573,9 +682,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			OrphanedSync     uint64 `db:"orphaned_sync"`
 		}{}
 		if lastStatsDay > 0 {
-			err = db.DB.Get(&syncStats, "select coalesce(sum(participated_sync), 0) as participated_sync, coalesce(sum(missed_sync), 0) as missed_sync, coalesce(sum(orphaned_sync), 0) as orphaned_sync from validator_stats where validatorindex = $1", index)
+			err = db.DB.GetContext(r.Context(), &syncStats, "select coalesce(sum(participated_sync), 0) as participated_sync, coalesce(sum(missed_sync), 0) as missed_sync, coalesce(sum(orphaned_sync), 0) as orphaned_sync from validator_stats where validatorindex = $1", index)
 			if err != nil {
-				logger.Errorf("error retrieving validator syncStats: %v", err)
+				requestLogger(r).Errorf("error retrieving validator syncStats: %v", err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
@@ -588,9 +697,9 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			MissedSync       uint64 `db:"missed_sync"`
 			OrphanedSync     uint64 `db:"orphaned_sync"`
 		}{}
-		err = db.DB.Get(&syncStatsNotInStats, "select coalesce(sum(case when status = 0 then 1 else 0 end), 0) as scheduled_sync, coalesce(sum(case when status = 1 then 1 else 0 end), 0) as participated_sync, coalesce(sum(case when status = 2 then 1 else 0 end), 0) as missed_sync, coalesce(sum(case when status = 3 then 1 else 0 end), 0) as orphaned_sync from sync_assignments_p where week >= $1/7 and slot >= ($1+1)*225*32 and validatorindex = $2", lastStatsDay, index)
+		err = db.DB.GetContext(r.Context(), &syncStatsNotInStats, "select coalesce(sum(case when status = 0 then 1 else 0 end), 0) as scheduled_sync, coalesce(sum(case when status = 1 then 1 else 0 end), 0) as participated_sync, coalesce(sum(case when status = 2 then 1 else 0 end), 0) as missed_sync, coalesce(sum(case when status = 3 then 1 else 0 end), 0) as orphaned_sync from sync_assignments_p where week >= $1/7 and slot >= ($1+1)*225*32 and validatorindex = $2", lastStatsDay, index)
 		if err != nil {
-			logger.Errorf("error retrieving validator syncStatsAfterLastStatsDay: %v", err)
+			requestLogger(r).Errorf("error retrieving validator syncStatsAfterLastStatsDay: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -605,7 +714,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 
 	// add rocketpool-data if available
 	validatorPageData.Rocketpool = &types.RocketpoolValidatorPageData{}
-	err = db.DB.Get(validatorPageData.Rocketpool, `
+	err = db.DB.GetContext(r.Context(), validatorPageData.Rocketpool, `
 		SELECT
 			rplm.node_address      AS node_address,
 			rplm.address           AS minipool_address,
@@ -624,11 +733,21 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	if err == nil && (validatorPageData.Rocketpool.MinipoolAddress != nil || validatorPageData.Rocketpool.NodeAddress != nil) {
 		validatorPageData.IsRocketpool = true
 	} else if err != nil && err != sql.ErrNoRows {
-		logger.Errorf("error getting rocketpool-data for validator for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error getting rocketpool-data for validator for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	validatorName := fmt.Sprintf("Validator %v", index)
+	if validatorPageData.Name != "" {
+		validatorName = fmt.Sprintf("Validator %v (%v)", index, validatorPageData.Name)
+	}
+	setStructuredData(data, validatorName, fmt.Sprintf("0x%x", validatorPageData.PublicKey),
+		fmt.Sprintf("%v with the public key 0x%x is %v with a balance of %.4f ETH and an effective balance of %.0f ETH.",
+			validatorName, validatorPageData.PublicKey, strings.ReplaceAll(validatorPageData.Status, "_", " "),
+			float64(validatorPageData.CurrentBalance)/1e9, float64(validatorPageData.EffectiveBalance)/1e9),
+		"Validators", "/validators", utils.EpochToTime(services.LatestEpoch()))
+
 	data.Data = validatorPageData
 
 	if utils.IsApiRequest(r) {
@@ -639,7 +758,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -652,21 +771,21 @@ func ValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 
 	pubkey, err := hex.DecodeString(strings.Replace(vars["pubkey"], "0x", "", -1))
 	if err != nil {
-		logger.Errorf("error parsing validator public key %v: %v", vars["pubkey"], err)
+		requestLogger(r).Errorf("error parsing validator public key %v: %v", vars["pubkey"], err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	deposits, err := db.GetValidatorDeposits(pubkey)
+	deposits, err := db.GetValidatorDeposits(r.Context(), pubkey)
 	if err != nil {
-		logger.Errorf("error getting validator-deposits for %v: %v", vars["pubkey"], err)
+		requestLogger(r).Errorf("error getting validator-deposits for %v: %v", vars["pubkey"], err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	err = json.NewEncoder(w).Encode(deposits)
 	if err != nil {
-		logger.Errorf("error encoding validator-deposits for %v: %v", vars["pubkey"], err)
+		requestLogger(r).Errorf("error encoding validator-deposits for %v: %v", vars["pubkey"], err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -679,43 +798,24 @@ func ValidatorAttestationInclusionEffectiveness(w http.ResponseWriter, r *http.R
 	vars := mux.Vars(r)
 	index, err := strconv.ParseUint(vars["index"], 10, 64)
 	if err != nil {
-		logger.Errorf("error parsing validator index: %v", err)
+		requestLogger(r).Errorf("error parsing validator index: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	var avgIncDistance float64
-
-	err = db.DB.Get(&avgIncDistance, `
-	SELECT COALESCE(
-		AVG(1 + inclusionslot - COALESCE((
-			SELECT MIN(slot)
-			FROM blocks
-			WHERE slot > aa.attesterslot AND blocks.status = '1'
-		), 0)
-	), 0)
-	FROM attestation_assignments_p aa
-	INNER JOIN blocks ON blocks.slot = aa.inclusionslot AND blocks.status <> '3'
-	WHERE aa.week >= $1 / 1575 AND aa.epoch > $1 AND aa.validatorindex = $2 AND aa.inclusionslot > 0
-	`, int64(services.LatestEpoch())-100, index)
+	attestationInclusionEffectiveness, err := db.GetValidatorAttestationInclusionEffectiveness(r.Context(), index, int64(services.LatestEpoch())-100)
 	if err != nil {
-		logger.Errorf("error retrieving AverageAttestationInclusionDistance: %v", err)
+		requestLogger(r).Errorf("error retrieving AverageAttestationInclusionDistance: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	var attestationInclusionEffectiveness float64
-
-	if avgIncDistance > 0 {
-		attestationInclusionEffectiveness = 1.0 / avgIncDistance * 100
-	}
-
 	type resp struct {
 		Effectiveness float64 `json:"effectiveness"`
 	}
 	err = json.NewEncoder(w).Encode(resp{Effectiveness: attestationInclusionEffectiveness})
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -728,7 +828,7 @@ func ValidatorProposedBlocks(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	index, err := strconv.ParseUint(vars["index"], 10, 64)
 	if err != nil {
-		logger.Errorf("error parsing validator index: %v", err)
+		requestLogger(r).Errorf("error parsing validator index: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -737,19 +837,19 @@ func ValidatorProposedBlocks(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -759,9 +859,9 @@ func ValidatorProposedBlocks(w http.ResponseWriter, r *http.Request) {
 
 	var totalCount uint64
 
-	err = db.DB.Get(&totalCount, "SELECT COUNT(*) FROM blocks WHERE proposer = $1", index)
+	err = db.DB.GetContext(r.Context(), &totalCount, "SELECT COUNT(*) FROM blocks WHERE proposer = $1", index)
 	if err != nil {
-		logger.Errorf("error retrieving proposed blocks count: %v", err)
+		requestLogger(r).Errorf("error retrieving proposed blocks count: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -785,7 +885,7 @@ func ValidatorProposedBlocks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var blocks []*types.IndexPageDataBlocks
-	err = db.DB.Select(&blocks, `
+	err = db.DB.SelectContext(r.Context(), &blocks, `
 		SELECT 
 			blocks.epoch, 
 			blocks.slot, 
@@ -805,7 +905,7 @@ func ValidatorProposedBlocks(w http.ResponseWriter, r *http.Request) {
 		LIMIT $2 OFFSET $3`, index, length, start)
 
 	if err != nil {
-		logger.Errorf("error retrieving proposed blocks data: %v", err)
+		requestLogger(r).Errorf("error retrieving proposed blocks data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -835,7 +935,7 @@ func ValidatorProposedBlocks(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -848,7 +948,7 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	index, err := strconv.ParseUint(vars["index"], 10, 64)
 	if err != nil {
-		logger.Errorf("error parsing validator index: %v", err)
+		requestLogger(r).Errorf("error parsing validator index: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -857,19 +957,19 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	start, err := strconv.ParseInt(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	length, err := strconv.ParseInt(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -901,9 +1001,9 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 		ExitEpoch       uint64
 	}{}
 
-	err = db.DB.Get(&ae, "SELECT activationepoch, exitepoch FROM validators WHERE validatorindex = $1", index)
+	err = db.DB.GetContext(r.Context(), &ae, "SELECT activationepoch, exitepoch FROM validators WHERE validatorindex = $1", index)
 	if err != nil {
-		logger.Errorf("error retrieving attestations count: %v", err)
+		requestLogger(r).Errorf("error retrieving attestations count: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -921,8 +1021,20 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 	tableData := [][]interface{}{}
 
 	if totalCount > 0 {
+		// the epochs of the dropped weeks of attestation_assignments_p are read from the attestation bitfields
+		firstEpoch := int64(lastAttestationEpoch) - start - length + 1
+		lastEpoch := int64(lastAttestationEpoch) - start
+		retainedEpoch := int64(db.AttestationAssignmentsRetainedEpoch())
+		if firstEpoch < 0 {
+			firstEpoch = 0
+		}
+		queryFirstEpoch := firstEpoch
+		if queryFirstEpoch < retainedEpoch {
+			queryFirstEpoch = retainedEpoch
+		}
+
 		var blocks []*types.ValidatorAttestation
-		err = db.DB.Select(&blocks, `
+		err = db.DB.SelectContext(r.Context(), &blocks, `
 			SELECT 
 				aa.epoch, 
 				aa.attesterslot, 
@@ -938,11 +1050,11 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 				COALESCE(inclusionslot - (SELECT MIN(slot) FROM blocks WHERE slot > aa.attesterslot AND blocks.status = '1'), 0) as delay
 			FROM attestation_assignments_p aa
 			LEFT JOIN blocks on blocks.slot = aa.inclusionslot
-			WHERE validatorindex = $1 AND aa.epoch > $2 AND aa.epoch <= $3
-			ORDER BY `+orderBy+` `+orderDir, index, int64(lastAttestationEpoch)-start-length, int64(lastAttestationEpoch)-start)
+			WHERE validatorindex = $1 AND aa.epoch >= $2 AND aa.epoch <= $3
+			ORDER BY `+orderBy+` `+orderDir, index, queryFirstEpoch, lastEpoch)
 
 		if err != nil {
-			logger.Errorf("error retrieving validator attestations data: %v", err)
+			requestLogger(r).Errorf("error retrieving validator attestations data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -963,6 +1075,37 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 				utils.FormatInclusionDelay(b.InclusionSlot, b.Delay),
 			}
 		}
+
+		if firstEpoch < retainedEpoch && lastEpoch >= firstEpoch {
+			droppedLastEpoch := lastEpoch
+			if droppedLastEpoch >= retainedEpoch {
+				droppedLastEpoch = retainedEpoch - 1
+			}
+			dropped, err := db.Attestations.GetAttestationAssignments([]uint64{index}, uint64(firstEpoch), uint64(droppedLastEpoch))
+			if err != nil {
+				requestLogger(r).Errorf("error retrieving validator attestations data: %v", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			// the slot, committee and inclusion of these duties are not stored
+			droppedData := make([][]interface{}, len(dropped))
+			for i, a := range dropped {
+				droppedData[i] = []interface{}{
+					utils.FormatEpoch(a.Epoch),
+					"-",
+					utils.FormatAttestationStatus(a.Status),
+					utils.FormatTimestamp(utils.EpochToTime(a.Epoch).Unix()),
+					"-",
+					"-",
+					"-",
+				}
+			}
+			if orderBy == "epoch" && orderDir == "asc" {
+				tableData = append(droppedData, tableData...)
+			} else {
+				tableData = append(tableData, droppedData...)
+			}
+		}
 	}
 
 	data := &types.DataTableResponse{
@@ -974,7 +1117,7 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -987,7 +1130,7 @@ func ValidatorSlashings(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	index, err := strconv.ParseUint(vars["index"], 10, 64)
 	if err != nil {
-		logger.Errorf("error parsing validator index: %v", err)
+		requestLogger(r).Errorf("error parsing validator index: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -996,13 +1139,13 @@ func ValidatorSlashings(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	var totalCount uint64
-	err = db.DB.Get(&totalCount, `
+	err = db.DB.GetContext(r.Context(), &totalCount, `
 		select
 			(
 				select count(*) from blocks_attesterslashings a
@@ -1013,13 +1156,13 @@ func ValidatorSlashings(w http.ResponseWriter, r *http.Request) {
 				inner join blocks d on d.slot = c.block_slot and d.proposer = $1
 			)`, index)
 	if err != nil {
-		logger.Errorf("error retrieving totalCount of validator-slashings: %v", err)
+		requestLogger(r).Errorf("error retrieving totalCount of validator-slashings: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	var attesterSlashings []*types.ValidatorAttestationSlashing
-	err = db.DB.Select(&attesterSlashings, `
+	err = db.DB.SelectContext(r.Context(), &attesterSlashings, `
 		SELECT 
 			blocks.slot, 
 			blocks.epoch, 
@@ -1031,18 +1174,18 @@ func ValidatorSlashings(w http.ResponseWriter, r *http.Request) {
 		WHERE attestation1_indices IS NOT NULL AND attestation2_indices IS NOT NULL`, index)
 
 	if err != nil {
-		logger.Errorf("error retrieving validator attestations data: %v", err)
+		requestLogger(r).Errorf("error retrieving validator attestations data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	var proposerSlashings []*types.ValidatorProposerSlashing
-	err = db.DB.Select(&proposerSlashings, `
+	err = db.DB.SelectContext(r.Context(), &proposerSlashings, `
 		SELECT blocks.slot, blocks.epoch, blocks.proposer, blocks_proposerslashings.proposerindex 
 		FROM blocks_proposerslashings 
 		INNER JOIN blocks ON blocks.proposer = $1 AND blocks_proposerslashings.block_slot = blocks.slot`, index)
 	if err != nil {
-		logger.Errorf("error retrieving block proposer slashings data: %v", err)
+		requestLogger(r).Errorf("error retrieving block proposer slashings data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1053,7 +1196,7 @@ func ValidatorSlashings(w http.ResponseWriter, r *http.Request) {
 		inter := intersect.Simple(b.Attestestation1Indices, b.Attestestation2Indices)
 		slashedValidators := []uint64{}
 		if len(inter) == 0 {
-			logger.Warning("No intersection found for attestation violation")
+			requestLogger(r).Warning("No intersection found for attestation violation")
 		}
 		for _, v := range inter {
 			slashedValidators = append(slashedValidators, uint64(v.(int64)))
@@ -1095,7 +1238,7 @@ func ValidatorSlashings(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1109,7 +1252,7 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 
 	pubkeyDecoded, err := hex.DecodeString(pubkey)
 	if err != nil {
-		logger.Errorf("error parsing submitted pubkey %v: %v", pubkey, err)
+		requestLogger(r).Errorf("error parsing submitted pubkey %v: %v", pubkey, err)
 		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
 		http.Redirect(w, r, "/validator/"+pubkey, 301)
 		return
@@ -1122,11 +1265,34 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 
 	applyNameToAll := r.FormValue("apply-to-all")
 
+	// users that have verified their ownership of the validator can name it without signing a message
+	if applyNameToAll != "on" {
+		user := getUser(r)
+		if user.Authenticated {
+			ownership, err := db.GetValidatorOwnership(r.Context(), user.UserID, pubkeyDecoded)
+			if err != nil {
+				requestLogger(r).Errorf("error retrieving validator ownership of user %v: %v", user.UserID, err)
+			}
+			if ownership != nil {
+				err = saveValidatorName(pubkeyDecoded, name)
+				if err != nil {
+					requestLogger(r).Errorf("error saving validator name: %x: %v: %v", pubkeyDecoded, name, err)
+					utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while updating validator name")
+					http.Redirect(w, r, "/validator/"+pubkey, 301)
+					return
+				}
+				utils.SetFlash(w, r, validatorEditFlash, "Your custom name has been saved.")
+				http.Redirect(w, r, "/validator/"+pubkey, 301)
+				return
+			}
+		}
+	}
+
 	signature := r.FormValue("signature")
 	signatureWrapper := &types.MyCryptoSignature{}
 	err = json.Unmarshal([]byte(signature), signatureWrapper)
 	if err != nil {
-		logger.Errorf("error decoding submitted signature %v: %v", signature, err)
+		requestLogger(r).Errorf("error decoding submitted signature %v: %v", signature, err)
 		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
 		http.Redirect(w, r, "/validator/"+pubkey, 301)
 		return
@@ -1137,14 +1303,14 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 
 	signatureParsed, err := hex.DecodeString(strings.Replace(signatureWrapper.Sig, "0x", "", -1))
 	if err != nil {
-		logger.Errorf("error parsing submitted signature %v: %v", signatureWrapper.Sig, err)
+		requestLogger(r).Errorf("error parsing submitted signature %v: %v", signatureWrapper.Sig, err)
 		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
 		http.Redirect(w, r, "/validator/"+pubkey, 301)
 		return
 	}
 
 	if len(signatureParsed) != 65 {
-		logger.Errorf("signature must be 65 bytes long")
+		requestLogger(r).Errorf("signature must be 65 bytes long")
 		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
 		http.Redirect(w, r, "/validator/"+pubkey, 301)
 		return
@@ -1156,7 +1322,7 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 
 	recoveredPubkey, err := crypto.SigToPub(msgHash.Bytes(), signatureParsed)
 	if err != nil {
-		logger.Errorf("error recovering pubkey: %v", err)
+		requestLogger(r).Errorf("error recovering pubkey: %v", err)
 		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
 		http.Redirect(w, r, "/validator/"+pubkey, 301)
 		return
@@ -1164,9 +1330,9 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 	recoveredAddress := crypto.PubkeyToAddress(*recoveredPubkey)
 
 	var depositedAddress string
-	deposits, err := db.GetValidatorDeposits(pubkeyDecoded)
+	deposits, err := db.GetValidatorDeposits(r.Context(), pubkeyDecoded)
 	if err != nil {
-		logger.Errorf("error getting validator-deposits from db for signature verification: %v", err)
+		requestLogger(r).Errorf("error getting validator-deposits from db for signature verification: %v", err)
 		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
 		http.Redirect(w, r, "/validator/"+pubkey, 301)
 	}
@@ -1179,13 +1345,13 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 
 	if strings.ToLower(depositedAddress) == strings.ToLower(recoveredAddress.Hex()) {
 		if applyNameToAll == "on" {
-			res, err := db.DB.Exec(`
+			res, err := db.DB.ExecContext(r.Context(), `
 				INSERT INTO validator_names (publickey, name)
 				SELECT publickey, $1 as name
 				FROM (SELECT DISTINCT publickey FROM eth1_deposits WHERE from_address = $2 AND valid_signature) a
 				ON CONFLICT (publickey) DO UPDATE SET name = excluded.name`, name, recoveredAddress.Bytes())
 			if err != nil {
-				logger.Errorf("error saving validator name (apply to all): %x: %v: %v", pubkeyDecoded, name, err)
+				requestLogger(r).Errorf("error saving validator name (apply to all): %x: %v: %v", pubkeyDecoded, name, err)
 				utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while updating validator names")
 				http.Redirect(w, r, "/validator/"+pubkey, 301)
 				return
@@ -1195,12 +1361,9 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 			utils.SetFlash(w, r, validatorEditFlash, fmt.Sprintf("Your custom name has been saved for %v validator(s).", rowsAffected))
 			http.Redirect(w, r, "/validator/"+pubkey, 301)
 		} else {
-			_, err := db.DB.Exec(`
-				INSERT INTO validator_names (publickey, name) 
-				VALUES($2, $1) 
-				ON CONFLICT (publickey) DO UPDATE SET name = excluded.name`, name, pubkeyDecoded)
+			err := saveValidatorName(pubkeyDecoded, name)
 			if err != nil {
-				logger.Errorf("error saving validator name: %x: %v: %v", pubkeyDecoded, name, err)
+				requestLogger(r).Errorf("error saving validator name: %x: %v: %v", pubkeyDecoded, name, err)
 				utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while updating validator name")
 				http.Redirect(w, r, "/validator/"+pubkey, 301)
 				return
@@ -1217,6 +1380,14 @@ func ValidatorSave(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func saveValidatorName(pubkey []byte, name string) error {
+	_, err := db.DB.Exec(`
+		INSERT INTO validator_names (publickey, name) 
+		VALUES($2, $1) 
+		ON CONFLICT (publickey) DO UPDATE SET name = excluded.name`, name, pubkey)
+	return err
+}
+
 // ValidatorHistory returns a validators history in json
 func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -1225,7 +1396,7 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	index, err := strconv.ParseUint(vars["index"], 10, 64)
 	if err != nil {
-		logger.Errorf("error parsing validator index: %v", err)
+		requestLogger(r).Errorf("error parsing validator index: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1234,14 +1405,14 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1252,9 +1423,9 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 		ActivationEpoch uint64 `db:"activationepoch"`
 		ExitEpoch       uint64 `db:"exitepoch"`
 	}{}
-	err = db.DB.Get(&activationAndExitEpoch, "SELECT activationepoch, exitepoch FROM validators WHERE validatorindex = $1", index)
+	err = db.DB.GetContext(r.Context(), &activationAndExitEpoch, "SELECT activationepoch, exitepoch FROM validators WHERE validatorindex = $1", index)
 	if err != nil {
-		logger.Errorf("error retrieving activationAndExitEpoch for validator-history: %v", err)
+		requestLogger(r).Errorf("error retrieving activationAndExitEpoch for validator-history: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1287,7 +1458,7 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var validatorHistory []*types.ValidatorHistory
-	err = db.DB.Select(&validatorHistory, `
+	err = db.DB.SelectContext(r.Context(), &validatorHistory, `
 			SELECT 
 				vbalance.epoch, 
 				COALESCE(vbalance.balance - LAG(vbalance.balance) OVER (ORDER BY vbalance.epoch), 0) AS balancechange,
@@ -1304,7 +1475,7 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 			`, index, lookBack, currentEpoch-start)
 
 	if err != nil {
-		logger.Errorf("error retrieving validator history: %v", err)
+		requestLogger(r).Errorf("error retrieving validator history: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1356,7 +1527,7 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1379,13 +1550,13 @@ func ValidatorStatsTable(w http.ResponseWriter, r *http.Request) {
 	if strings.Contains(vars["index"], "0x") || len(vars["index"]) == 96 {
 		pubKey, err := hex.DecodeString(strings.Replace(vars["index"], "0x", "", -1))
 		if err != nil {
-			logger.Errorf("error parsing validator public key %v: %v", vars["index"], err)
+			requestLogger(r).Errorf("error parsing validator public key %v: %v", vars["index"], err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
-		index, err = db.GetValidatorIndex(pubKey)
+		index, err = db.GetValidatorIndex(r.Context(), pubKey)
 		if err != nil {
-			logger.Errorf("error parsing validator pubkey: %v", err)
+			requestLogger(r).Errorf("error parsing validator pubkey: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -1393,7 +1564,7 @@ func ValidatorStatsTable(w http.ResponseWriter, r *http.Request) {
 		// Request came with a validator index number
 		index, err = strconv.ParseUint(vars["index"], 10, 64)
 		if err != nil {
-			logger.Errorf("error parsing validator index: %v", err)
+			requestLogger(r).Errorf("error parsing validator index: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -1407,10 +1578,10 @@ func ValidatorStatsTable(w http.ResponseWriter, r *http.Request) {
 		Rows:           make([]*types.ValidatorStatsTableRow, 0),
 	}
 
-	err = db.DB.Select(&validatorStatsTablePageData.Rows, "SELECT * FROM validator_stats WHERE validatorindex = $1 ORDER BY day DESC", index)
+	err = db.DB.SelectContext(r.Context(), &validatorStatsTablePageData.Rows, "SELECT * FROM validator_stats WHERE validatorindex = $1 ORDER BY day DESC", index)
 
 	if err != nil {
-		logger.Errorf("error retrieving validator stats history: %v", err)
+		requestLogger(r).Errorf("error retrieving validator stats history: %v", err)
 		http.Error(w, "Validator not found", http.StatusNotFound)
 		return
 	}
@@ -1445,7 +1616,7 @@ func ValidatorStatsTable(w http.ResponseWriter, r *http.Request) {
 	err = validatorStatsTableTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1458,7 +1629,7 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	index, err := strconv.ParseUint(vars["index"], 10, 64)
 	if err != nil {
-		logger.Errorf("error parsing validator index: %v", err)
+		requestLogger(r).Errorf("error parsing validator index: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1467,19 +1638,19 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data draw-parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data draw-parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	start, err := strconv.ParseInt(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start start-parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start start-parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 	length, err := strconv.ParseInt(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length length-parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length length-parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1508,12 +1679,12 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 		TotalCount uint64 `db:"totalcount"`
 		MaxPeriod  uint64 `db:"maxperiod"`
 	}
-	err = db.DB.Select(&countData, `
+	err = db.DB.SelectContext(r.Context(), &countData, `
 		SELECT count(*)*$1 AS totalcount, max(period) AS maxperiod 
 		FROM sync_committees 
 		WHERE validatorindex = $2`, utils.Config.Chain.EpochsPerSyncCommitteePeriod*utils.Config.Chain.SlotsPerEpoch, index)
 	if err != nil {
-		logger.WithError(err).Errorf("error getting countData of sync-assignments")
+		requestLogger(r).WithError(err).Errorf("error getting countData of sync-assignments")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -1535,7 +1706,7 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 			Status            uint64  `db:"status"`
 			ParticipationRate float64 `db:"participation"`
 		}
-		err = db.DB.Select(&dbRows, `
+		err = db.DB.SelectContext(r.Context(), &dbRows, `
 			SELECT sa.slot, sa.status, COALESCE(b.syncaggregate_participation,0) AS participation
 			FROM sync_assignments_p sa
 			LEFT JOIN blocks b ON sa.slot = b.slot
@@ -1543,7 +1714,7 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 			ORDER BY `+orderBy+`
 			LIMIT $2 OFFSET $3`, index, length, start, futureSlotsThreshold)
 		if err != nil {
-			logger.Errorf("error retrieving validator sync participations data: %v", err)
+			requestLogger(r).Errorf("error retrieving validator sync participations data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -1573,7 +1744,7 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -36,26 +36,26 @@ func ValidatorRewards(w http.ResponseWriter, r *http.Request) {
 	data := InitPageData(w, r, "services", "/rewards", "Ethereum Validator Rewards")
 
 	var supportedCurrencies []string
-	err = db.DB.Select(&supportedCurrencies,
+	err = db.DB.SelectContext(r.Context(), &supportedCurrencies,
 		`select column_name 
 			from information_schema.columns 
 			where table_name = 'price'`)
 	if err != nil {
-		logger.Errorf("error getting eth1-deposits-distribution for stake pools: %w", err)
+		requestLogger(r).Errorf("error getting eth1-deposits-distribution for stake pools: %w", err)
 	}
 
 	var minTime time.Time
-	err = db.DB.Get(&minTime,
+	err = db.DB.GetContext(r.Context(), &minTime,
 		`select ts from price order by ts asc limit 1`)
 	if err != nil {
-		logger.Errorf("error getting min ts: %w", err)
+		requestLogger(r).Errorf("error getting min ts: %w", err)
 	}
 
 	data.Data = rewardsResp{Currencies: supportedCurrencies, CsrfField: csrf.TemplateField(r), MinDateTimestamp: uint64(minTime.Unix()), ShowSubscriptions: data.User.Authenticated}
 
 	err = validatorRewardsServicesTemplate.ExecuteTemplate(w, "layout", data)
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -111,7 +111,7 @@ func RewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 	validatorLimit := getUserPremium(r).MaxValidators
 	validatorArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
 	if err != nil {
-		logger.Errorf("error retrieving active validators %v", err)
+		requestLogger(r).Errorf("error retrieving active validators %v", err)
 		http.Error(w, "Invalid query", 400)
 		return
 	}
@@ -125,7 +125,7 @@ func RewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 		start, err = strconv.ParseUint(dateRange[0], 10, 64)
 		end, err = strconv.ParseUint(dateRange[1], 10, 64)
 		if err != nil {
-			logger.Errorf("error retrieving days range %v", err)
+			requestLogger(r).Errorf("error retrieving days range %v", err)
 			http.Error(w, "Invalid query", 400)
 			return
 		}
@@ -135,7 +135,7 @@ func RewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -147,7 +147,7 @@ func DownloadRewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 	validatorLimit := getUserPremium(r).MaxValidators
 	validatorArr, err := parseValidatorsFromQueryString(q.Get("validators"), validatorLimit)
 	if err != nil {
-		logger.Errorf("error retrieving active validators %v", err)
+		requestLogger(r).Errorf("error retrieving active validators %v", err)
 		http.Error(w, "Invalid query", 400)
 		return
 	}
@@ -161,7 +161,7 @@ func DownloadRewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 		start, err = strconv.ParseUint(dateRange[0], 10, 64)
 		end, err = strconv.ParseUint(dateRange[1], 10, 64)
 		if err != nil {
-			logger.Errorf("error retrieving days range %v", err)
+			requestLogger(r).Errorf("error retrieving days range %v", err)
 			http.Error(w, "Invalid query", 400)
 			return
 		}
@@ -182,7 +182,7 @@ func DownloadRewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 
 	_, err = w.Write(services.GeneratePdfReport(hist))
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error writing response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error writing response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -193,19 +193,19 @@ func RewardNotificationSubscribe(w http.ResponseWriter, r *http.Request) {
 	SetAutoContentType(w, r)
 	user := getUser(r)
 	if !user.Authenticated {
-		logger.WithField("route", r.URL.String()).Error("User not Authenticated")
+		requestLogger(r).WithField("route", r.URL.String()).Error("User not Authenticated")
 		http.Error(w, "Internal server error, User Not Authenticated", http.StatusInternalServerError)
 		return
 	}
 
 	var count uint64
-	err := db.FrontendDB.Get(&count,
+	err := db.FrontendDB.GetContext(r.Context(), &count,
 		`select count(event_name) 
 		from users_subscriptions 
 		where user_id=$1 AND event_name=$2;`, user.UserID, strings.ToLower(utils.GetNetwork())+":"+string(types.TaxReportEventName))
 
 	if err != nil || count >= 5 {
-		logger.WithField("route", r.URL.String()).Info(fmt.Sprintf("User Subscription limit (%v) reached %v", count, err))
+		requestLogger(r).WithField("route", r.URL.String()).Info(fmt.Sprintf("User Subscription limit (%v) reached %v", count, err))
 		http.Error(w, "Internal server error, User Subscription limit reached", http.StatusInternalServerError)
 		return
 	}
@@ -223,18 +223,18 @@ func RewardNotificationSubscribe(w http.ResponseWriter, r *http.Request) {
 	currency := q.Get("currency")
 
 	if validatorArr == "" || !isValidCurrency(currency) {
-		logger.WithField("route", r.URL.String()).Error("Bad Query")
+		requestLogger(r).WithField("route", r.URL.String()).Error("Bad Query")
 		http.Error(w, "Internal server error, Bad Query", http.StatusInternalServerError)
 		return
 	}
 
-	err = db.AddSubscription(user.UserID,
+	err = db.AddSubscription(r.Context(), user.UserID,
 		utils.Config.Chain.Phase0.ConfigName,
 		types.TaxReportEventName,
 		fmt.Sprintf("validators=%s&days=30&currency=%s", validatorArr, currency), 0)
 
 	if err != nil {
-		logger.Errorf("error updating user subscriptions: %v", err)
+		requestLogger(r).Errorf("error updating user subscriptions: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -244,7 +244,7 @@ func RewardNotificationSubscribe(w http.ResponseWriter, r *http.Request) {
 	}{Msg: "Subscription Updated"})
 
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -255,7 +255,7 @@ func RewardNotificationUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	SetAutoContentType(w, r)
 	user := getUser(r)
 	if !user.Authenticated {
-		logger.WithField("route", r.URL.String()).Error("User not Authenticated")
+		requestLogger(r).WithField("route", r.URL.String()).Error("User not Authenticated")
 		http.Error(w, "Internal server error, User Not Authenticated", http.StatusInternalServerError)
 		return
 	}
@@ -267,18 +267,18 @@ func RewardNotificationUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	currency := q.Get("currency")
 
 	if validatorArr == "" || !isValidCurrency(currency) {
-		logger.WithField("route", r.URL.String()).Error("Bad Query")
+		requestLogger(r).WithField("route", r.URL.String()).Error("Bad Query")
 		http.Error(w, "Internal server error, Bad Query", http.StatusInternalServerError)
 		return
 	}
 
-	err := db.DeleteSubscription(user.UserID,
+	err := db.DeleteSubscription(r.Context(), user.UserID,
 		utils.GetNetwork(),
 		types.TaxReportEventName,
 		fmt.Sprintf("validators=%s&days=30&currency=%s", validatorArr, currency))
 
 	if err != nil {
-		logger.Errorf("error deleting entry from user subscriptions: %v", err)
+		requestLogger(r).Errorf("error deleting entry from user subscriptions: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -288,7 +288,7 @@ func RewardNotificationUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	}{Msg: "Subscription Deleted"})
 
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -298,19 +298,19 @@ func RewardGetUserSubscriptions(w http.ResponseWriter, r *http.Request) {
 	SetAutoContentType(w, r)
 	user := getUser(r)
 	if !user.Authenticated {
-		logger.WithField("route", r.URL.String()).Error("User not Authenticated")
+		requestLogger(r).WithField("route", r.URL.String()).Error("User not Authenticated")
 		http.Error(w, "Internal server error, User Not Authenticated", http.StatusInternalServerError)
 		return
 	}
 
 	var count uint64
-	err := db.FrontendDB.Get(&count,
+	err := db.FrontendDB.GetContext(r.Context(), &count,
 		`select count(event_name) 
 		from users_subscriptions 
 		where user_id=$1 AND event_name=$2;`, user.UserID, strings.ToLower(utils.GetNetwork())+":"+string(types.TaxReportEventName))
 
 	if err != nil {
-		logger.WithField("route", r.URL.String()).Error("Failed to get User Subscriptions Count")
+		requestLogger(r).WithField("route", r.URL.String()).Error("Failed to get User Subscriptions Count")
 		http.Error(w, "Internal server error, Failed to get User Subscriptions Count", http.StatusInternalServerError)
 		return
 	}
@@ -323,7 +323,7 @@ func RewardGetUserSubscriptions(w http.ResponseWriter, r *http.Request) {
 	}{Data: data, Count: count})
 
 	if err != nil {
-		logger.WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
+		requestLogger(r).WithError(err).WithField("route", r.URL.String()).Error("error encoding json response")
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ApiValidatorLifecycle godoc
+// @Summary Get the timeline of a validator from its deposits to its exit in a single request: eth1 and beacon chain deposits, activation eligibility, activation, withdrawal credential changes, slashings, voluntary exit, exit, withdrawable epoch and full withdrawal together with the current (or final) balance. Events of epochs that have not been reached yet (e.g. a scheduled exit) are marked as scheduled. Until the full withdrawal of an exited validator has been included its slot is estimated from the progress of the withdrawal sweep and marked as estimated.
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Validator index or pubkey"
+// @Success 200 {object} types.ApiResponse{data=types.ValidatorLifecycle}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/lifecycle [get]
+func ApiValidatorLifecycle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	indices, pubkeys, err := parseApiValidatorParam(mux.Vars(r)["indexOrPubkey"], 1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	var index uint64
+	var pubkey []byte
+	if len(pubkeys) > 0 {
+		pubkey = pubkeys[0]
+	} else {
+		index = indices[0]
+	}
+	lifecycle, err := db.GetValidatorLifecycle(r.Context(), index, pubkey, services.LatestEpoch())
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving lifecycle of validator %v: %v", mux.Vars(r)["indexOrPubkey"], err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if lifecycle == nil {
+		sendErrorResponse(j, r.URL.String(), "validator not found")
+		return
+	}
+
+	err = addEstimatedFullWithdrawal(r.Context(), lifecycle)
+	if err != nil {
+		requestLogger(r).Errorf("error estimating full withdrawal of validator %v: %v", lifecycle.Validatorindex, err)
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{lifecycle})
+}
+
+// addEstimatedFullWithdrawal adds the estimated full withdrawal to the lifecycle of a withdrawable validator with
+// execution withdrawal credentials and a balance whose full withdrawal has not been included yet
+func addEstimatedFullWithdrawal(ctx context.Context, lifecycle *types.ValidatorLifecycle) error {
+	if lifecycle.Balance == 0 || strings.HasPrefix(lifecycle.WithdrawalCredentials, "0x00") {
+		return nil
+	}
+	var withdrawable *types.ValidatorLifecycleEvent
+	for _, e := range lifecycle.Events {
+		if e.Type == db.ValidatorLifecycleFullWithdrawal {
+			return nil
+		}
+		if e.Type == db.ValidatorLifecycleWithdrawable {
+			withdrawable = e
+		}
+	}
+	if withdrawable == nil {
+		return nil
+	}
+
+	withdrawal, err := services.EstimateFullWithdrawal(ctx, lifecycle.Validatorindex, *withdrawable.Epoch)
+	if err != nil || withdrawal == nil {
+		return err
+	}
+	epoch := utils.EpochOfSlot(withdrawal.Slot)
+	lifecycle.Events = append(lifecycle.Events, &types.ValidatorLifecycleEvent{
+		Type:      db.ValidatorLifecycleFullWithdrawal,
+		Ts:        withdrawal.Ts,
+		Epoch:     &epoch,
+		Slot:      &withdrawal.Slot,
+		Amount:    lifecycle.Balance,
+		Scheduled: true,
+		Estimated: true,
+	})
+	return nil
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+)
+
+const (
+	validatorOwnershipChallengeKey   = "validator_ownership_challenge"
+	validatorOwnershipChallengeTsKey = "validator_ownership_challenge_ts"
+	validatorOwnershipChallengeTTL   = time.Minute * 30
+)
+
+// ValidatorOwnershipChallenge returns the message the user has to sign to prove that they control the validator,
+// the challenge is stored in the session of the user and replaces any previous challenge
+func ValidatorOwnershipChallenge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !user.Authenticated {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	pubkey, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(mux.Vars(r)["pubkey"]), "0x"))
+	if err != nil || len(pubkey) != 48 {
+		http.Error(w, "Invalid validator public key", http.StatusBadRequest)
+		return
+	}
+
+	nonce := make([]byte, 16)
+	_, err = rand.Read(nonce)
+	if err != nil {
+		requestLogger(r).Errorf("error generating validator ownership nonce: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	message := fmt.Sprintf("I control the validator 0x%x and want to verify it for my beaconcha.in account %v. Nonce: %x", pubkey, user.UserID, nonce)
+
+	session.Values[validatorOwnershipChallengeKey] = message
+	session.Values[validatorOwnershipChallengeTsKey] = time.Now().Unix()
+	err = session.Save(r, w)
+	if err != nil {
+		requestLogger(r).Errorf("error saving validator ownership challenge: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(map[string]string{"message": message})
+	if err != nil {
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ValidatorOwnershipPost verifies the signature of the ownership challenge with either the key of the validator or the key
+// of its eth1 withdrawal address and stores the verified ownership
+func ValidatorOwnershipPost(w http.ResponseWriter, r *http.Request) {
+	pubkeyHex := strings.TrimPrefix(strings.ToLower(mux.Vars(r)["pubkey"]), "0x")
+
+	user, session, err := getUserSession(r)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !user.Authenticated {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	pubkey, err := hex.DecodeString(pubkeyHex)
+	if err != nil || len(pubkey) != 48 {
+		http.Error(w, "Invalid validator public key", http.StatusBadRequest)
+		return
+	}
+
+	message, _ := session.Values[validatorOwnershipChallengeKey].(string)
+	challengeTs, _ := session.Values[validatorOwnershipChallengeTsKey].(int64)
+	if message == "" || !strings.Contains(message, fmt.Sprintf("0x%x", pubkey)) || time.Since(time.Unix(challengeTs, 0)) > validatorOwnershipChallengeTTL {
+		utils.SetFlash(w, r, validatorEditFlash, "Error: the verification challenge has expired, please request a new one")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(r.FormValue("signature")), "0x"))
+	if err != nil {
+		utils.SetFlash(w, r, validatorEditFlash, "Error: the provided signature is invalid")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	ownership := &types.ValidatorOwnership{
+		UserID:             user.UserID,
+		ValidatorPublickey: pubkey,
+		Method:             types.ValidatorOwnershipMethod(r.FormValue("method")),
+		Message:            message,
+		Signature:          signature,
+		VerifiedTs:         time.Now(),
+	}
+
+	switch ownership.Method {
+	case types.ValidatorOwnershipMethodBLS:
+		err = verifyValidatorBLSSignature(pubkey, message, signature)
+	case types.ValidatorOwnershipMethodWithdrawalAddress:
+		ownership.Address, err = verifyWithdrawalAddressSignature(r.Context(), pubkey, message, signature)
+	default:
+		err = errors.New("invalid verification method")
+	}
+	if err != nil {
+		utils.SetFlash(w, r, validatorEditFlash, fmt.Sprintf("Error: %v", err))
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	err = db.SaveValidatorOwnership(r.Context(), ownership)
+	if err != nil {
+		requestLogger(r).Errorf("error saving validator ownership of user %v for validator %x: %v", user.UserID, pubkey, err)
+		utils.SetFlash(w, r, validatorEditFlash, "Error: Db error while saving the verification")
+		http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+		return
+	}
+
+	// a challenge can only be used once
+	delete(session.Values, validatorOwnershipChallengeKey)
+	delete(session.Values, validatorOwnershipChallengeTsKey)
+	session.Save(r, w)
+
+	utils.SetFlash(w, r, validatorEditFlash, "Your ownership of this validator has been verified.")
+	http.Redirect(w, r, "/validator/"+pubkeyHex, http.StatusSeeOther)
+}
+
+// verifyValidatorBLSSignature verifies that the message has been signed with the key of the validator
+func verifyValidatorBLSSignature(pubkey []byte, message string, signature []byte) error {
+	pub, err := bls.PublicKeyFromBytes(pubkey)
+	if err != nil {
+		return errors.New("invalid validator public key")
+	}
+	sig, err := bls.SignatureFromBytes(signature)
+	if err != nil {
+		return errors.New("the provided signature is invalid")
+	}
+	if !sig.Verify(pub, []byte(message)) {
+		return errors.New("the provided signature does not match the validator key")
+	}
+	return nil
+}
+
+// verifyWithdrawalAddressSignature verifies that the message has been signed (personal_sign) with the key of the eth1
+// withdrawal address (0x01 or 0x02 credentials) of the validator and returns the address
+func verifyWithdrawalAddressSignature(ctx context.Context, pubkey []byte, message string, signature []byte) ([]byte, error) {
+	credentials, err := db.GetValidatorWithdrawalCredentials(ctx, pubkey)
+	if err != nil {
+		logger.Errorf("error retrieving withdrawal credentials of validator %x: %v", pubkey, err)
+		return nil, errors.New("could not retrieve the withdrawal credentials of the validator")
+	}
+	withdrawalAddress := utils.WithdrawalAddressOfCredentials(credentials)
+	if withdrawalAddress == nil {
+		return nil, errors.New("the validator does not have an eth1 withdrawal address")
+	}
+
+	if len(signature) != 65 {
+		return nil, errors.New("the provided signature is invalid")
+	}
+	if signature[64] == 27 || signature[64] == 28 {
+		signature[64] -= 27
+	}
+
+	msgHash := crypto.Keccak256Hash([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message)) + message))
+	recoveredPubkey, err := crypto.SigToPub(msgHash.Bytes(), signature)
+	if err != nil {
+		return nil, errors.New("the provided signature is invalid")
+	}
+	recoveredAddress := crypto.PubkeyToAddress(*recoveredPubkey)
+	if !bytes.Equal(recoveredAddress.Bytes(), withdrawalAddress) {
+		return nil, errors.New("the provided signature does not match the withdrawal address")
+	}
+	return withdrawalAddress, nil
+}
+
+// getUserMaxNotificationValidators returns the number of validators a user can subscribe to at once, validators whose
+// ownership the user has verified do not count against the limit of their package
+func getUserMaxNotificationValidators(r *http.Request, userID uint64) int {
+	maxValidators := getUserPremium(r).MaxValidators
+	verifiedCount, err := db.GetUserVerifiedValidatorCount(r.Context(), userID)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving verified validator count of user %v: %v", userID, err)
+		return maxValidators
+	}
+	return maxValidators + verifiedCount
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// validatorPeerComparisonDays is the default number of days a validator is compared against its peers over
+const validatorPeerComparisonDays = 7
+
+// ApiValidatorPeerComparison godoc
+// @Summary Compare the apr, attestation effectiveness and inclusion distance of a validator against the medians of all validators and of the validators activated in the same window of 1575 epochs, so underperformance can be told apart from network-wide issues. The values are computed per day from the daily validator statistics, only validators active during the whole day are included.
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Validator index or pubkey"
+// @Param  days query int false "Number of days to compare, the last exported days are used (default 7, max 100)"
+// @Success 200 {object} types.ApiResponse{data=types.ValidatorPeerComparison}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/peers [get]
+func ApiValidatorPeerComparison(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	indices, pubkeys, err := parseApiValidatorParam(mux.Vars(r)["indexOrPubkey"], 1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	days := uint64(validatorPeerComparisonDays)
+	if q := r.URL.Query().Get("days"); q != "" {
+		days, err = strconv.ParseUint(q, 10, 64)
+		if err != nil || days == 0 || days > 100 {
+			sendErrorResponse(j, r.URL.String(), "invalid days parameter")
+			return
+		}
+	}
+
+	var index uint64
+	if len(pubkeys) > 0 {
+		index, err = db.GetValidatorIndex(r.Context(), pubkeys[0])
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "validator not found")
+			return
+		}
+	} else {
+		index = indices[0]
+	}
+
+	comparison, err := db.GetValidatorPeerComparison(r.Context(), index, days)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving peer comparison of validator %v: %v", index, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if comparison == nil {
+		sendErrorResponse(j, r.URL.String(), "validator not found")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{comparison})
+}
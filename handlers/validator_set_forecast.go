@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+)
+
+var validatorSetForecastTemplate = template.Must(template.New("forecast").Funcs(utils.GetTemplateFuncs()).ParseFiles("templates/layout.html", "templates/forecast.html"))
+
+const (
+	validatorSetForecastDefaultMonths = 12
+	validatorSetForecastMaxMonths     = 60
+	validatorSetForecastDefaultBand   = 0.5
+)
+
+// ValidatorSetForecast will return the validator set forecast page using a go template
+func ValidatorSetForecast(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "stats", "/network/forecast", "Validator Set Forecast")
+
+	err := validatorSetForecastTemplate.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+}
+
+// ApiValidatorSetForecast godoc
+// @Summary Project the number of active validators, the consensus layer APR and the lengths of the activation and exit queues over the next months. The deposit and exit rates of the last 30 days are extrapolated and the queues are processed at the churn limits of the fork active at the time. The low and high scenarios scale the deposit and exit rates by the band in opposite directions.
+// @Tags Network
+// @Produce  json
+// @Param  months query int false "Number of projected months, at most 60, defaults to 12"
+// @Param  band query number false "Relative deviation of the deposit and exit rates in the low and high scenario between 0 and 1, defaults to 0.5"
+// @Success 200 {object} types.ApiResponse{data=types.ValidatorSetForecast}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/forecast [get]
+func ApiValidatorSetForecast(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	q := r.URL.Query()
+
+	var err error
+	months := validatorSetForecastDefaultMonths
+	if m := q.Get("months"); m != "" {
+		months, err = strconv.Atoi(m)
+		if err != nil || months < 1 || months > validatorSetForecastMaxMonths {
+			sendErrorResponse(j, r.URL.String(), fmt.Sprintf("months must be between 1 and %v", validatorSetForecastMaxMonths))
+			return
+		}
+	}
+
+	band := validatorSetForecastDefaultBand
+	if b := q.Get("band"); b != "" {
+		band, err = strconv.ParseFloat(b, 64)
+		if err != nil || !(band >= 0 && band <= 1) {
+			sendErrorResponse(j, r.URL.String(), "band must be between 0 and 1")
+			return
+		}
+	}
+
+	forecast, err := services.GetValidatorSetForecast(months, band)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator set forecast: %v", err)
+		sendErrorResponse(j, r.URL.String(), "the forecast is not available yet")
+		return
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{forecast})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ApiValidatorStatusHistory godoc
+// @Summary Get the status transitions of a validator (deposited, pending, active, exiting, slashing, exited, slashed) with the epoch they happened at and the status of the validator at an epoch. The statuses are the ones of the validator set snapshot, online and offline states are not part of the history.
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Validator index or pubkey"
+// @Param  epoch query int false "Epoch the status is returned for, defaults to the latest epoch"
+// @Success 200 {object} types.ApiResponse{data=types.ValidatorStatusHistory}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/statushistory [get]
+func ApiValidatorStatusHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	indices, pubkeys, err := parseApiValidatorParam(mux.Vars(r)["indexOrPubkey"], 1)
+	if err != nil {
+		sendErrorResponse(j, r.URL.String(), err.Error())
+		return
+	}
+
+	history := &types.ValidatorStatusHistory{Epoch: services.LatestEpoch()}
+	if e := r.URL.Query().Get("epoch"); e != "" {
+		history.Epoch, err = strconv.ParseUint(e, 10, 64)
+		if err != nil {
+			sendErrorResponse(j, r.URL.String(), "invalid epoch")
+			return
+		}
+	}
+
+	var index uint64
+	var pubkey []byte
+	if len(pubkeys) > 0 {
+		pubkey = pubkeys[0]
+		index, err = db.GetValidatorIndex(r.Context(), pubkey)
+	} else {
+		index = indices[0]
+		pubkey, err = db.GetValidatorPublicKey(r.Context(), index)
+	}
+	if err == sql.ErrNoRows {
+		sendErrorResponse(j, r.URL.String(), "validator not found")
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving validator %v: %v", mux.Vars(r)["indexOrPubkey"], err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	history.Validatorindex = index
+	history.Pubkey = fmt.Sprintf("0x%x", pubkey)
+
+	history.Transitions, err = db.GetValidatorStatusHistory(r.Context(), index)
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving status history of validator %v: %v", index, err)
+		sendErrorResponse(j, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	for _, t := range history.Transitions {
+		if t.Epoch > history.Epoch {
+			break
+		}
+		history.Status = t.Status
+	}
+
+	sendOKResponse(j, r.URL.String(), []interface{}{history})
+}
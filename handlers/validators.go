@@ -25,10 +25,10 @@ func Validators(w http.ResponseWriter, r *http.Request) {
 	validatorsPageData := types.ValidatorsPageData{}
 	var validators []*types.ValidatorsPageDataValidators
 
-	err := db.DB.Select(&validators, `SELECT activationepoch, exitepoch, lastattestationslot, slashed FROM validators ORDER BY validatorindex`)
+	err := db.DB.SelectContext(r.Context(), &validators, `SELECT activationepoch, exitepoch, lastattestationslot, slashed FROM validators ORDER BY validatorindex`)
 
 	if err != nil {
-		logger.Errorf("error retrieving validators data: %v", err)
+		requestLogger(r).Errorf("error retrieving validators data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -69,7 +69,7 @@ func Validators(w http.ResponseWriter, r *http.Request) {
 	err = validatorsTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -177,19 +177,19 @@ func parseValidatorsDataQueryParams(r *http.Request) (*ValidatorsDataQueryParams
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		return nil, err
 	}
 
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		return nil, err
 	}
 
 	length, err := strconv.ParseInt(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		return nil, err
 	}
 	if length < 0 {
@@ -225,7 +225,7 @@ func ValidatorsData(w http.ResponseWriter, r *http.Request) {
 
 	dataQuery, err := parseValidatorsDataQueryParams(r)
 	if err != nil {
-		logger.Errorf("error parsing query-data: %v", err)
+		requestLogger(r).Errorf("error parsing query-data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -258,9 +258,9 @@ func ValidatorsData(w http.ResponseWriter, r *http.Request) {
 			LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
 			ORDER BY %s %s
 			LIMIT $1 OFFSET $2`, dataQuery.OrderBy, dataQuery.OrderDir)
-		err = db.DB.Select(&validators, qry, dataQuery.Length, dataQuery.Start)
+		err = db.DB.SelectContext(r.Context(), &validators, qry, dataQuery.Length, dataQuery.Start)
 		if err != nil {
-			logger.Errorf("error retrieving validators data: %v", err)
+			requestLogger(r).Errorf("error retrieving validators data: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -304,9 +304,9 @@ func ValidatorsData(w http.ResponseWriter, r *http.Request) {
 			%s
 			ORDER BY %s %s
 			LIMIT $%d OFFSET $%d`, searchQry, dataQuery.StateFilter, dataQuery.OrderBy, dataQuery.OrderDir, len(args)-1, len(args))
-		err = db.DB.Select(&validators, qry, args...)
+		err = db.DB.SelectContext(r.Context(), &validators, qry, args...)
 		if err != nil {
-			logger.Errorf("error retrieving validators data (with search): %v", err)
+			requestLogger(r).Errorf("error retrieving validators data (with search): %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
@@ -374,7 +374,7 @@ func ValidatorsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -24,7 +24,7 @@ func ValidatorsLeaderboard(w http.ResponseWriter, r *http.Request) {
 	err := validatorsLeaderboardTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -60,19 +60,19 @@ func ValidatorsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -101,7 +101,7 @@ func ValidatorsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 	var performanceData []*types.ValidatorPerformance
 
 	if search == "" {
-		err = db.DB.Select(&performanceData, `
+		err = db.DB.SelectContext(r.Context(), &performanceData, `
 			SELECT 
 				a.*,
 				validators.pubkey,
@@ -154,10 +154,10 @@ func ValidatorsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 				ORDER BY `+orderBy+` `+orderDir+`
 			) perf ON perf.validatorindex = v.validatorindex
 			LIMIT $%d OFFSET $%d`, searchQry, len(args)-1, len(args))
-		err = db.DB.Select(&performanceData, qry, args...)
+		err = db.DB.SelectContext(r.Context(), &performanceData, qry, args...)
 	}
 	if err != nil {
-		logger.Errorf("error retrieving performanceData data (search=%v): %v", search != "", err)
+		requestLogger(r).Errorf("error retrieving performanceData data (search=%v): %v", search != "", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -188,7 +188,7 @@ func ValidatorsLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -24,7 +24,7 @@ func ValidatorsSlashings(w http.ResponseWriter, r *http.Request) {
 	err := validatorsSlashingsTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -38,20 +38,20 @@ func ValidatorsSlashingsData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -60,7 +60,7 @@ func ValidatorsSlashingsData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var slashings []*types.ValidatorSlashing
-	err = db.DB.Select(&slashings, `
+	err = db.DB.SelectContext(r.Context(), &slashings, `
 		SELECT 
 			slot,
 			epoch,
@@ -98,10 +98,10 @@ func ValidatorsSlashingsData(w http.ResponseWriter, r *http.Request) {
 
 	tableData := make([][]interface{}, 0, len(slashings))
 
-	validatorNames, err := db.GetValidatorNames()
+	validatorNames, err := db.GetValidatorNames(r.Context())
 
 	if err != nil {
-		logger.Errorf("error retrieving validator names from the database: %v", err)
+		requestLogger(r).Errorf("error retrieving validator names from the database: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -113,7 +113,7 @@ func ValidatorsSlashingsData(w http.ResponseWriter, r *http.Request) {
 		if row.Type == "Attestation Violation" {
 			inter := intersect.Simple(row.Attestestation1Indices, row.Attestestation2Indices)
 			if len(inter) == 0 {
-				logger.Warningf("No intersection found for attestation violation, proposer: %v, slot: %v", row.Proposer, row.Slot)
+				requestLogger(r).Warningf("No intersection found for attestation violation, proposer: %v, slot: %v", row.Proposer, row.Slot)
 			}
 			for _, v := range inter {
 				slashedValidators = append(slashedValidators, uint64(v.(int64)))
@@ -133,9 +133,9 @@ func ValidatorsSlashingsData(w http.ResponseWriter, r *http.Request) {
 
 		tableData = append(tableData, entry)
 	}
-	records, err := db.GetSlashingCount()
+	records, err := db.GetSlashingCount(r.Context())
 	if err != nil {
-		logger.Errorf("GetSlashingCount failed to retrieve record count: %v", err)
+		requestLogger(r).Errorf("GetSlashingCount failed to retrieve record count: %v", err)
 		http.Error(w, "Internal server error", 503)
 	}
 
@@ -148,7 +148,7 @@ func ValidatorsSlashingsData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
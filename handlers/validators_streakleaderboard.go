@@ -24,7 +24,7 @@ func ValidatorsStreakLeaderboard(w http.ResponseWriter, r *http.Request) {
 	err := validatorsStreakLeaderboardTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -44,19 +44,19 @@ func ValidatorsStreakLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables data parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables data parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables start parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables start parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
 	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
 	if err != nil {
-		logger.Errorf("error converting datatables length parameter from string to int: %v", err)
+		requestLogger(r).Errorf("error converting datatables length parameter from string to int: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -94,7 +94,7 @@ func ValidatorsStreakLeaderboardData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if search == "" {
-		err = db.DB.Select(&sqlData, `
+		err = db.DB.SelectContext(r.Context(), &sqlData, `
 			with
 				longeststreaks as (
 					select validatorindex, start, length, rank() over(order by length desc)
@@ -121,7 +121,7 @@ func ValidatorsStreakLeaderboardData(w http.ResponseWriter, r *http.Request) {
 			left join (select count(*) from longeststreaks) cnt(totalcount) on true
 			order by `+orderBy+` `+orderDir+` limit $1 offset $2`, length, start)
 	} else {
-		err = db.DB.Select(&sqlData, `
+		err = db.DB.SelectContext(r.Context(), &sqlData, `
 			with 
 				matched_validators as (
 					select v.validatorindex, v.pubkey, coalesce(vn.name,'') as name
@@ -158,7 +158,7 @@ func ValidatorsStreakLeaderboardData(w http.ResponseWriter, r *http.Request) {
 			order by `+orderBy+` `+orderDir+` limit $1 offset $2`, length, start, "%"+search+"%", search+"%")
 	}
 	if err != nil {
-		logger.Errorf("error retrieving streaksData data (search=%v): %v", search != "", err)
+		requestLogger(r).Errorf("error retrieving streaksData data (search=%v): %v", search != "", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -194,7 +194,7 @@ func ValidatorsStreakLeaderboardData(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
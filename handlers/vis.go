@@ -28,7 +28,7 @@ func Vis(w http.ResponseWriter, r *http.Request) {
 	err = visTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -51,10 +51,10 @@ func VisBlocks(w http.ResponseWriter, r *http.Request) {
 
 	var chartData []*types.VisChartData
 
-	err = db.DB.Select(&chartData, "select slot, blockroot, parentroot, proposer from blocks where slot >= $1 and status in ('1', '2') order by slot desc limit 50;", sinceSlot)
+	err = db.DB.SelectContext(r.Context(), &chartData, "select slot, blockroot, parentroot, proposer from blocks where slot >= $1 and status in ('1', '2') order by slot desc limit 50;", sinceSlot)
 
 	if err != nil {
-		logger.Errorf("error retrieving block tree data: %v", err)
+		requestLogger(r).Errorf("error retrieving block tree data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -73,11 +73,11 @@ func VisBlocks(w http.ResponseWriter, r *http.Request) {
 		d.Difficulty = d.Slot
 	}
 
-	logger.Printf("returning %v blocks since %v", len(chartData), sinceSlot)
+	requestLogger(r).Printf("returning %v blocks since %v", len(chartData), sinceSlot)
 
 	err = json.NewEncoder(w).Encode(chartData)
 	if err != nil {
-		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -94,7 +94,7 @@ func VisVotes(w http.ResponseWriter, r *http.Request) {
 
 	var chartData []*types.VotesVisChartData
 
-	rows, err := db.DB.Query(`select blocks.slot, 
+	rows, err := db.DB.QueryContext(r.Context(), `select blocks.slot, 
        											ENCODE(blocks.blockroot::bytea, 'hex') AS blockroot, 
        											ENCODE(blocks.parentroot::bytea, 'hex') AS parentroot,
 												blocks_attestations.validators 
@@ -105,7 +105,7 @@ func VisVotes(w http.ResponseWriter, r *http.Request) {
 												order by blocks.slot desc LIMIT 10;`, sinceSlot)
 
 	if err != nil {
-		logger.Errorf("error retrieving votes tree data: %v", err)
+		requestLogger(r).Errorf("error retrieving votes tree data: %v", err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -116,14 +116,14 @@ func VisVotes(w http.ResponseWriter, r *http.Request) {
 		data := &types.VotesVisChartData{}
 		err := rows.Scan(&data.Slot, &data.BlockRoot, &data.ParentRoot, &data.Validators)
 		if err != nil {
-			logger.Errorf("error scanning votes tree data: %v", err)
+			requestLogger(r).Errorf("error scanning votes tree data: %v", err)
 			http.Error(w, "Internal server error", 503)
 			return
 		}
 		chartData = append(chartData, data)
 	}
 
-	logger.Printf("returning %v entries since %v", len(chartData), sinceSlot)
+	requestLogger(r).Printf("returning %v entries since %v", len(chartData), sinceSlot)
 
 	data := InitPageData(w, r, "vis", "/vis", "Votes")
 	data.HeaderAd = true
@@ -132,7 +132,7 @@ func VisVotes(w http.ResponseWriter, r *http.Request) {
 	err = visVotesTemplate.ExecuteTemplate(w, "layout", data)
 
 	if err != nil {
-		logger.Errorf("error executing template for %v route: %v", r.URL.String(), err)
+		requestLogger(r).Errorf("error executing template for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", 503)
 		return
 	}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bytes"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// widgetTheme holds the colors used to render an embeddable widget
+type widgetTheme struct {
+	Background color.RGBA
+	Line       color.RGBA
+	Fill       color.RGBA
+	Text       color.RGBA
+}
+
+var widgetThemes = map[string]widgetTheme{
+	"light": {
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Line:       color.RGBA{0x3e, 0x6b, 0xc2, 0xff},
+		Fill:       color.RGBA{0x3e, 0x6b, 0xc2, 0x33},
+		Text:       color.RGBA{0x21, 0x25, 0x29, 0xff},
+	},
+	"dark": {
+		Background: color.RGBA{0x18, 0x1a, 0x1b, 0xff},
+		Line:       color.RGBA{0x5b, 0xa0, 0xf2, 0xff},
+		Fill:       color.RGBA{0x5b, 0xa0, 0xf2, 0x33},
+		Text:       color.RGBA{0xe6, 0xe6, 0xe6, 0xff},
+	},
+}
+
+// widgetSeries is the data rendered by a widget, values are ordered from oldest to newest
+type widgetSeries struct {
+	Title  string
+	Values []float64
+	Label  string
+}
+
+// Widget renders an embeddable chart (validator balance sparkline, network participation, rocketpool apr) as svg or png
+func Widget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	q := r.URL.Query()
+
+	width := int(parseUintWithDefault(q.Get("width"), 300))
+	if width < 50 || width > 1200 {
+		http.Error(w, "Invalid width, must be between 50 and 1200", http.StatusBadRequest)
+		return
+	}
+	height := int(parseUintWithDefault(q.Get("height"), 80))
+	if height < 20 || height > 600 {
+		http.Error(w, "Invalid height, must be between 20 and 600", http.StatusBadRequest)
+		return
+	}
+	themeName := q.Get("theme")
+	if themeName == "" {
+		themeName = "light"
+	}
+	theme, ok := widgetThemes[themeName]
+	if !ok {
+		http.Error(w, "Invalid theme, must be light or dark", http.StatusBadRequest)
+		return
+	}
+
+	var series *widgetSeries
+	var err error
+	switch vars["type"] {
+	case "validator_balance":
+		index, parseErr := strconv.ParseUint(q.Get("validator"), 10, 64)
+		if parseErr != nil {
+			http.Error(w, "Invalid validator index", http.StatusBadRequest)
+			return
+		}
+		series, err = getValidatorBalanceWidgetSeries(index)
+	case "network_participation":
+		series, err = getNetworkParticipationWidgetSeries()
+	case "rocketpool_apr":
+		series, err = getRocketpoolAPRWidgetSeries()
+	default:
+		http.Error(w, "Widget not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		requestLogger(r).Errorf("error retrieving widget data for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", 503)
+		return
+	}
+
+	var body []byte
+	switch vars["format"] {
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		body = renderWidgetSVG(series, width, height, theme)
+	case "png":
+		w.Header().Set("Content-Type", "image/png")
+		body, err = renderWidgetPNG(series, width, height, theme)
+		if err != nil {
+			requestLogger(r).Errorf("error rendering png widget for %v route: %v", r.URL.String(), err)
+			http.Error(w, "Internal server error", 503)
+			return
+		}
+	default:
+		http.Error(w, "Widget format not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(body)
+}
+
+func getValidatorBalanceWidgetSeries(index uint64) (*widgetSeries, error) {
+	balances := []int64{}
+	err := db.DB.Select(&balances, `
+		SELECT COALESCE(end_balance, 0)
+		FROM (SELECT day, end_balance FROM validator_stats WHERE validatorindex = $1 ORDER BY day DESC LIMIT 30) s
+		ORDER BY day ASC`, index)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &widgetSeries{Title: fmt.Sprintf("Validator %v balance", index), Values: make([]float64, len(balances))}
+	for i, b := range balances {
+		series.Values[i] = float64(b) / 1e9
+	}
+	if len(series.Values) > 0 {
+		series.Label = fmt.Sprintf("%.4f ETH", series.Values[len(series.Values)-1])
+	}
+	return series, nil
+}
+
+func getNetworkParticipationWidgetSeries() (*widgetSeries, error) {
+	rates := []float64{}
+	err := db.DB.Select(&rates, `
+		SELECT COALESCE(globalparticipationrate, 0)
+		FROM (SELECT epoch, globalparticipationrate FROM epochs WHERE epoch < $1 ORDER BY epoch DESC LIMIT 100) e
+		ORDER BY epoch ASC`, services.LatestEpoch())
+	if err != nil {
+		return nil, err
+	}
+
+	series := &widgetSeries{Title: "Network participation", Values: rates}
+	if len(rates) > 0 {
+		series.Label = fmt.Sprintf("%.2f%%", rates[len(rates)-1]*100)
+	}
+	return series, nil
+}
+
+func getRocketpoolAPRWidgetSeries() (*widgetSeries, error) {
+	aprs := []float64{}
+	err := db.DB.Select(&aprs, `
+		SELECT apr FROM (
+			SELECT
+				vs.day,
+				COALESCE(SUM(vs.end_balance - vs.start_balance - COALESCE(vs.deposits_amount, 0))::float / NULLIF(COUNT(*) * 32e9, 0) * 365, 0) AS apr
+			FROM validator_stats vs
+			INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+			INNER JOIN rocketpool_minipools rpm ON rpm.pubkey = v.pubkey
+			WHERE vs.day >= (SELECT COALESCE(MAX(day), 0) - 30 FROM validator_stats)
+			GROUP BY vs.day
+		) a
+		ORDER BY day ASC`)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &widgetSeries{Title: "Rocketpool APR", Values: aprs}
+	if len(aprs) > 0 {
+		series.Label = fmt.Sprintf("%.2f%%", aprs[len(aprs)-1]*100)
+	}
+	return series, nil
+}
+
+// widgetPoints scales the series values into the given drawing area, the y-axis is inverted to match image coordinates
+func widgetPoints(values []float64, width, height, padding int) [][2]float64 {
+	points := make([][2]float64, len(values))
+	if len(values) == 0 {
+		return points
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	valueRange := max - min
+	if valueRange == 0 {
+		valueRange = 1
+	}
+
+	stepX := float64(width-2*padding) / math.Max(float64(len(values)-1), 1)
+	for i, v := range values {
+		points[i][0] = float64(padding) + float64(i)*stepX
+		points[i][1] = float64(height-padding) - (v-min)/valueRange*float64(height-2*padding)
+	}
+	return points
+}
+
+func widgetColorHex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func renderWidgetSVG(series *widgetSeries, width, height int, theme widgetTheme) []byte {
+	points := widgetPoints(series.Values, width, height, 4)
+
+	pointStrings := make([]string, len(points))
+	for i, p := range points {
+		pointStrings[i] = fmt.Sprintf("%.1f,%.1f", p[0], p[1])
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&buf, `<title>%v</title>`, series.Title)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%v"/>`, widgetColorHex(theme.Background))
+	if len(points) > 1 {
+		fmt.Fprintf(&buf, `<polygon points="%.1f,%d %v %.1f,%d" fill="%v" fill-opacity="%.2f"/>`, points[0][0], height, strings.Join(pointStrings, " "), points[len(points)-1][0], height, widgetColorHex(theme.Fill), float64(theme.Fill.A)/255)
+		fmt.Fprintf(&buf, `<polyline points="%v" fill="none" stroke="%v" stroke-width="1.5"/>`, strings.Join(pointStrings, " "), widgetColorHex(theme.Line))
+	}
+	if series.Label != "" && height >= 40 {
+		fmt.Fprintf(&buf, `<text x="6" y="16" font-family="sans-serif" font-size="12" fill="%v">%v: %v</text>`, widgetColorHex(theme.Text), series.Title, series.Label)
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+func renderWidgetPNG(series *widgetSeries, width, height int, theme widgetTheme) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill := blendWidgetColor(theme.Background, theme.Fill)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.SetRGBA(x, y, theme.Background)
+		}
+	}
+
+	points := widgetPoints(series.Values, width, height, 4)
+	for i := 1; i < len(points); i++ {
+		x0, y0 := points[i-1][0], points[i-1][1]
+		x1, y1 := points[i][0], points[i][1]
+
+		// fill the area below the line segment column by column
+		for x := int(x0); x <= int(x1); x++ {
+			y := y0
+			if x1 > x0 {
+				y = y0 + (y1-y0)*(float64(x)-x0)/(x1-x0)
+			}
+			for fy := int(y) + 1; fy < height; fy++ {
+				img.SetRGBA(x, fy, fill)
+			}
+		}
+
+		steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+		for s := 0; s <= steps; s++ {
+			x := int(x0 + (x1-x0)*float64(s)/float64(steps))
+			y := int(y0 + (y1-y0)*float64(s)/float64(steps))
+			img.SetRGBA(x, y, theme.Line)
+			img.SetRGBA(x, y+1, theme.Line)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blendWidgetColor blends the translucent color fg onto the opaque color bg
+func blendWidgetColor(bg, fg color.RGBA) color.RGBA {
+	a := float64(fg.A) / 255
+	return color.RGBA{
+		R: uint8(float64(fg.R)*a + float64(bg.R)*(1-a)),
+		G: uint8(float64(fg.G)*a + float64(bg.G)*(1-a)),
+		B: uint8(float64(fg.B)*a + float64(bg.B)*(1-a)),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,52 @@
+package httpcache
+
+import (
+	"eth2-exporter/types"
+	"net/http"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/mssola/user_agent"
+)
+
+var crawlerEntries *lru.Cache
+
+// initCrawlers creates the cache of the pages rendered for search engine crawlers, it is separate from the response
+// cache so crawling the long tail of validators and blocks does not evict the responses of the regular traffic
+func initCrawlers(cfg *types.Config) error {
+	if !cfg.Frontend.Crawlers.Enabled {
+		return nil
+	}
+	size := cfg.Frontend.Crawlers.CacheSize
+	if size <= 0 {
+		size = 10000
+	}
+	var err error
+	crawlerEntries, err = lru.New(size)
+	return err
+}
+
+// IsCrawler returns true if the request was sent by a search engine crawler or another bot according to its user agent
+func IsCrawler(r *http.Request) bool {
+	return user_agent.New(r.UserAgent()).Bot()
+}
+
+// Crawlers serves the page to search engine crawlers from a cache that is refreshed once per epoch, the page is rendered
+// without the cookies of the request and without middlewares so every crawler gets the same anonymous page. Requests of
+// browsers are passed to next, which is usually the page wrapped in middlewares that set cookies (e.g. csrf tokens).
+func Crawlers(page http.HandlerFunc, next http.Handler) http.Handler {
+	if crawlerEntries == nil {
+		return next
+	}
+	cached := handler(crawlerEntries, EpochBoundary, func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		r.Header.Del("Cookie")
+		page(w, r)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsCrawler(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cached(w, r)
+	})
+}
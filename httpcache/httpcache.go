@@ -0,0 +1,242 @@
+// Package httpcache caches the responses of idempotent endpoints until the next slot or epoch boundary, after which the
+// data they are built from changes. Expired responses are served while they are refreshed in the background so traffic
+// spikes at epoch transitions do not reach the database all at once.
+package httpcache
+
+import (
+	"context"
+	"eth2-exporter/logging"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+)
+
+var logger = logging.NewLogger("httpcache")
+
+// Boundary is the chain interval after which a cached response expires
+type Boundary int
+
+const (
+	// SlotBoundary expires responses at the start of the next slot
+	SlotBoundary Boundary = iota
+	// EpochBoundary expires responses at the start of the next epoch
+	EpochBoundary
+)
+
+// CacheHeader reports whether a response was served from the cache (HIT), served expired while being refreshed (STALE)
+// or computed by the handler (MISS)
+const CacheHeader = "X-Cache"
+
+// exportDelay is added to the boundaries as the data of a slot is exported shortly after the slot started, refreshing
+// right at the boundary would cache the data of the previous slot for another slot
+const exportDelay = time.Second * 4
+
+var entries *lru.Cache
+var maxStale time.Duration
+var group singleflight.Group
+
+type entry struct {
+	status     int
+	header     http.Header
+	body       []byte
+	expires    time.Time
+	staleUntil time.Time
+	refreshing int32
+}
+
+// Init creates the cache according to the config, if the cache is disabled the handler wrappers return the handlers
+// unchanged, so Init has to be called before the routes are registered
+func Init(cfg *types.Config) error {
+	maxStale = time.Second * time.Duration(cfg.Frontend.ResponseCache.MaxStaleSeconds)
+	if maxStale <= 0 {
+		maxStale = time.Second * time.Duration(cfg.Chain.SlotsPerEpoch*cfg.Chain.SecondsPerSlot)
+	}
+	err := initCrawlers(cfg)
+	if err != nil {
+		return err
+	}
+	if !cfg.Frontend.ResponseCache.Enabled {
+		return nil
+	}
+	size := cfg.Frontend.ResponseCache.Size
+	if size <= 0 {
+		size = 10000
+	}
+	entries, err = lru.New(size)
+	return err
+}
+
+// Slot caches the responses of the handler until the next slot
+func Slot(h http.HandlerFunc) http.HandlerFunc {
+	return Handler(SlotBoundary, h)
+}
+
+// Epoch caches the responses of the handler until the next epoch
+func Epoch(h http.HandlerFunc) http.HandlerFunc {
+	return Handler(EpochBoundary, h)
+}
+
+// Handler caches successful GET responses of the handler keyed by path, query parameters and the currency cookie until
+// the next boundary.
+// Concurrent misses of the same key are computed once, expired entries are served until maxStale has passed while a
+// single background request refreshes them.
+func Handler(boundary Boundary, h http.HandlerFunc) http.HandlerFunc {
+	if entries == nil {
+		return h
+	}
+	return handler(entries, boundary, h)
+}
+
+func handler(cache *lru.Cache, boundary Boundary, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			h(w, r)
+			return
+		}
+
+		key := cacheKey(r)
+		now := time.Now()
+		if cached, found := cache.Get(key); found {
+			e := cached.(*entry)
+			if now.Before(e.expires) {
+				e.write(w, "HIT")
+				return
+			}
+			if now.Before(e.staleUntil) {
+				if atomic.CompareAndSwapInt32(&e.refreshing, 0, 1) {
+					go refresh(cache, key, boundary, h, r)
+				}
+				e.write(w, "STALE")
+				return
+			}
+		}
+
+		res, _, _ := group.Do(key, func() (interface{}, error) {
+			return record(cache, key, boundary, h, r), nil
+		})
+		res.(*entry).write(w, "MISS")
+	}
+}
+
+// cacheKey returns the key of the response to the request, the pages format their values in the currency of the
+// currency cookie
+func cacheKey(r *http.Request) string {
+	key := r.URL.Path + "?" + r.URL.Query().Encode()
+	if cookie, err := r.Cookie("currency"); err == nil {
+		key += "#currency=" + cookie.Value
+	}
+	return key
+}
+
+// refresh executes the handler with a copy of the request that is not canceled when the original request finishes
+func refresh(cache *lru.Cache, key string, boundary Boundary, h http.HandlerFunc, r *http.Request) {
+	group.Do(key, func() (interface{}, error) {
+		return record(cache, key, boundary, h, r.Clone(detachedContext{r.Context()})), nil
+	})
+}
+
+// record executes the handler and stores the response if it is cacheable, panics of the handler are turned into an
+// error response as they would block the requests waiting for the same key
+func record(cache *lru.Cache, key string, boundary Boundary, h http.HandlerFunc, r *http.Request) *entry {
+	rec := &recorder{header: http.Header{}, status: http.StatusOK}
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				logger.Errorf("error executing handler of cached route %v: %v", key, err)
+				rec.header = http.Header{}
+				rec.status = http.StatusInternalServerError
+				rec.body = []byte("Internal server error")
+			}
+		}()
+		h(rec, r)
+	}()
+
+	expires := nextBoundary(boundary, time.Now())
+	e := &entry{
+		status:     rec.status,
+		header:     rec.header,
+		body:       rec.body,
+		expires:    expires,
+		staleUntil: expires.Add(maxStale),
+	}
+	if e.status == http.StatusOK && e.header.Get("Set-Cookie") == "" {
+		cache.Add(key, e)
+	} else if cached, found := cache.Peek(key); found {
+		// keep serving the previous response, the next request after the stale period retries
+		atomic.StoreInt32(&cached.(*entry).refreshing, 0)
+	}
+	return e
+}
+
+func (e *entry) write(w http.ResponseWriter, cacheStatus string) {
+	for k, v := range e.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set(CacheHeader, cacheStatus)
+	w.WriteHeader(e.status)
+	_, err := w.Write(e.body)
+	if err != nil {
+		logger.WithError(err).Debugf("error writing cached response")
+	}
+}
+
+// nextBoundary returns the start of the next slot or epoch after t plus the export delay
+func nextBoundary(boundary Boundary, t time.Time) time.Time {
+	period := utils.Config.Chain.SecondsPerSlot
+	if boundary == EpochBoundary {
+		period *= utils.Config.Chain.SlotsPerEpoch
+	}
+	genesis := int64(utils.Config.Chain.GenesisTimestamp)
+	now := t.Add(-exportDelay).Unix()
+	if now < genesis {
+		// before genesis the data does not change with slots, e.g. the deposits are refreshed every slot duration
+		return t.Add(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
+	}
+	next := genesis + ((now-genesis)/int64(period)+1)*int64(period)
+	return time.Unix(next, 0).Add(exportDelay)
+}
+
+type recorder struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body = append(r.body, b...)
+	return len(b), nil
+}
+
+// detachedContext keeps the values of the request context (e.g. the route variables) without its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,99 @@
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MockBeacon serves the recorded responses of the beacon api from a fixture directory, each response is stored in a
+// file named after the escaped path and query of the request. In record mode the requests are proxied to a real beacon
+// node and the responses are written to the fixture directory.
+type MockBeacon struct {
+	*httptest.Server
+	dir      string
+	upstream string
+	mu       sync.Mutex
+	misses   []string
+}
+
+// NewMockBeacon starts a mock beacon api serving the responses in dir, if upstream is set the responses are recorded
+// from the beacon node at upstream instead
+func NewMockBeacon(dir, upstream string) (*MockBeacon, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	b := &MockBeacon{dir: dir, upstream: strings.TrimSuffix(upstream, "/")}
+	b.Server = httptest.NewServer(http.HandlerFunc(b.serve))
+	return b, nil
+}
+
+// Misses returns the requests without a recorded response, they are answered with 404
+func (b *MockBeacon) Misses() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.misses...)
+}
+
+func (b *MockBeacon) serve(w http.ResponseWriter, r *http.Request) {
+	file := filepath.Join(b.dir, fixtureName(r.Method, r.URL.RequestURI()))
+	if b.upstream != "" {
+		b.record(w, r, file)
+		return
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		b.mu.Lock()
+		b.misses = append(b.misses, r.Method+" "+r.URL.RequestURI())
+		b.mu.Unlock()
+		http.Error(w, fmt.Sprintf("no recorded response for %v %v", r.Method, r.URL.RequestURI()), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// record proxies the request to the upstream node and stores successful responses
+func (b *MockBeacon) record(w http.ResponseWriter, r *http.Request, file string) {
+	req, err := http.NewRequest(r.Method, b.upstream+r.URL.RequestURI(), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if resp.StatusCode == http.StatusOK {
+		err = ioutil.WriteFile(file, data, 0644)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	w.Write(data)
+}
+
+// fixtureName returns the file name of the recorded response of a request, e.g.
+// "GET_%2Feth%2Fv1%2Fbeacon%2Fheaders%2Fhead.json"
+func fixtureName(method, requestURI string) string {
+	return method + "_" + url.PathEscape(requestURI) + ".json"
+}
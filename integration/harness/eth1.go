@@ -0,0 +1,182 @@
+package harness
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+)
+
+// Eth1Call is a recorded json-rpc call of the eth1 endpoint
+type Eth1Call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+type eth1Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type eth1Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// MockEth1 answers json-rpc calls of the eth1 endpoint with the calls recorded in a fixture file, calls are matched by
+// method and params. In record mode the calls are forwarded to a real endpoint and appended to the fixture file on Close.
+type MockEth1 struct {
+	*httptest.Server
+	file     string
+	upstream string
+	mu       sync.Mutex
+	calls    map[string]*Eth1Call
+	recorded []*Eth1Call
+	misses   []string
+}
+
+// NewMockEth1 starts a mock eth1 endpoint serving the calls recorded in file, if upstream is set the calls are recorded
+// from the endpoint at upstream instead
+func NewMockEth1(file, upstream string) (*MockEth1, error) {
+	e := &MockEth1{file: file, upstream: upstream, calls: map[string]*Eth1Call{}}
+	data, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &e.recorded)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding recorded eth1 calls %v: %w", file, err)
+		}
+		for _, c := range e.recorded {
+			e.calls[eth1CallKey(c.Method, c.Params)] = c
+		}
+	}
+	e.Server = httptest.NewServer(http.HandlerFunc(e.serve))
+	return e, nil
+}
+
+// Misses returns the calls without a recorded response, they are answered with a json-rpc error
+func (e *MockEth1) Misses() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.misses...)
+}
+
+// Close stops the server and writes the recorded calls in record mode
+func (e *MockEth1) Close() error {
+	e.Server.Close()
+	if e.upstream == "" {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	data, err := json.MarshalIndent(e.recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(e.file, data, 0644)
+}
+
+func (e *MockEth1) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	// geth sends batches as json arrays
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		reqs := []*eth1Request{}
+		err = json.Unmarshal(body, &reqs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := make([]*eth1Response, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, e.call(req))
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	req := &eth1Request{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(e.call(req))
+}
+
+func (e *MockEth1) call(req *eth1Request) *eth1Response {
+	key := eth1CallKey(req.Method, req.Params)
+	e.mu.Lock()
+	c, exists := e.calls[key]
+	e.mu.Unlock()
+
+	if !exists && e.upstream != "" {
+		var err error
+		c, err = e.forward(req)
+		if err != nil {
+			return &eth1Response{JSONRPC: "2.0", ID: req.ID, Error: eth1Error(err.Error())}
+		}
+		e.mu.Lock()
+		e.calls[key] = c
+		e.recorded = append(e.recorded, c)
+		e.mu.Unlock()
+		exists = true
+	}
+	if !exists {
+		e.mu.Lock()
+		e.misses = append(e.misses, key)
+		e.mu.Unlock()
+		return &eth1Response{JSONRPC: "2.0", ID: req.ID, Error: eth1Error("no recorded response for " + key)}
+	}
+	return &eth1Response{JSONRPC: "2.0", ID: req.ID, Result: c.Result, Error: c.Error}
+}
+
+func (e *MockEth1) forward(req *eth1Request) (*Eth1Call, error) {
+	body, err := json.Marshal(&eth1Request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: req.Method, Params: req.Params})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post(e.upstream, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	res := &eth1Response{}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response of %v: %w", req.Method, err)
+	}
+	return &Eth1Call{Method: req.Method, Params: req.Params, Result: res.Result, Error: res.Error}, nil
+}
+
+// eth1CallKey identifies a call by its method and its params in compact form
+func eth1CallKey(method string, params json.RawMessage) string {
+	compact := &bytes.Buffer{}
+	if len(params) == 0 || json.Compact(compact, params) != nil {
+		return method
+	}
+	return method + compact.String()
+}
+
+func eth1Error(message string) json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{"code": -32000, "message": message})
+	return data
+}
@@ -0,0 +1,234 @@
+// Package harness runs the indexer and the exporters against a simulated chain for integration tests. A chain fixture
+// is a directory with the config of the chain (config.yml), the recorded responses of the beacon api (beacon/), the
+// recorded calls of the eth1 endpoint (eth1.json) and optional seed data (seed.sql). The chain is served by a mock beacon
+// api and a mock eth1 endpoint and exported into a temporary database created from tables.sql.
+//
+// Fixtures are recorded by running the tests with INTEGRATION_RECORD_BEACON and INTEGRATION_RECORD_ETH1 set to the urls
+// of a real beacon node and eth1 endpoint, the requests are then proxied and their responses written to the fixture.
+package harness
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/events"
+	"eth2-exporter/exporter"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Chain is a running simulated chain, the explorer packages are configured to use it through utils.Config and the db
+// package, so only one chain can run per process at a time
+type Chain struct {
+	Beacon   *MockBeacon
+	Eth1     *MockEth1
+	Client   rpc.Client
+	Config   *types.Config
+	Postgres *Postgres
+	Database string
+	dir      string
+}
+
+// NewChain starts the simulated chain of the fixture directory and initializes the databases of the db package with a
+// temporary database. The working directory is changed to the root of the repository so that the chain presets and
+// tables.sql are found.
+func NewChain(fixtureDir string) (*Chain, error) {
+	dir, err := filepath.Abs(fixtureDir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := repositoryRoot()
+	if err != nil {
+		return nil, err
+	}
+	err = os.Chdir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Chain{dir: dir, Postgres: PostgresFromEnv()}
+	c.Beacon, err = NewMockBeacon(filepath.Join(dir, "beacon"), os.Getenv("INTEGRATION_RECORD_BEACON"))
+	if err != nil {
+		return nil, fmt.Errorf("error starting mock beacon api: %w", err)
+	}
+	c.Eth1, err = NewMockEth1(filepath.Join(dir, "eth1.json"), os.Getenv("INTEGRATION_RECORD_ETH1"))
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("error starting mock eth1 endpoint: %w", err)
+	}
+
+	c.Config = &types.Config{}
+	err = utils.ReadConfig(c.Config, filepath.Join(dir, "config.yml"))
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("error reading chain config: %w", err)
+	}
+	err = utils.ValidateChainConfig(c.Config)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	c.Database, err = c.Postgres.CreateTempDatabase()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.configure()
+	utils.Config = c.Config
+
+	db.MustInitDB(c.Postgres.User, c.Postgres.Password, c.Postgres.Host, c.Postgres.Port, c.Database)
+	db.MustInitFrontendDB(c.Postgres.User, c.Postgres.Password, c.Postgres.Host, c.Postgres.Port, c.Database, c.Config.Frontend.SessionSecret)
+	db.MustBootstrapSchema(filepath.Join(root, "tables.sql"))
+	db.MustInitRepositories()
+
+	err = c.seed()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	err = events.Init()
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("error initializing event bus: %w", err)
+	}
+
+	c.Client, err = rpc.NewLighthouseClient(c.Beacon.URL)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// configure points the config to the mocks and the temporary database
+func (c *Chain) configure() {
+	beaconURL, _ := url.Parse(c.Beacon.URL)
+	c.Config.Indexer.Node.Type = "lighthouse"
+	c.Config.Indexer.Node.Host = beaconURL.Hostname()
+	c.Config.Indexer.Node.Port = beaconURL.Port()
+	c.Config.Indexer.Node.Fallbacks = nil
+	c.Config.Indexer.Eth1Endpoint = c.Eth1.URL
+	c.Config.Indexer.Eth1FallbackEndpoints = nil
+	c.Config.Frontend.BeaconNodeEndpoint = c.Beacon.URL
+	c.Config.EventBus.Type = "local"
+
+	c.Config.Database.Username = c.Postgres.User
+	c.Config.Database.Password = c.Postgres.Password
+	c.Config.Database.Host = c.Postgres.Host
+	c.Config.Database.Port = c.Postgres.Port
+	c.Config.Database.Name = c.Database
+	c.Config.Frontend.Database.Username = c.Postgres.User
+	c.Config.Frontend.Database.Password = c.Postgres.Password
+	c.Config.Frontend.Database.Host = c.Postgres.Host
+	c.Config.Frontend.Database.Port = c.Postgres.Port
+	c.Config.Frontend.Database.Name = c.Database
+}
+
+// seed applies the seed.sql of the fixture if it exists
+func (c *Chain) seed() error {
+	seed, err := ioutil.ReadFile(filepath.Join(c.dir, "seed.sql"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = db.DB.Exec(string(seed))
+	if err != nil {
+		return fmt.Errorf("error applying seed data: %w", err)
+	}
+	return nil
+}
+
+// ExportEpochs exports the epochs from start to end (inclusive) like the indexer
+func (c *Chain) ExportEpochs(start, end uint64) error {
+	for epoch := start; epoch <= end; epoch++ {
+		err := exporter.ExportEpoch(epoch, c.Client)
+		if err != nil {
+			return fmt.Errorf("error exporting epoch %v: %w", epoch, err)
+		}
+	}
+	return nil
+}
+
+// MarkOrphanedBlocks compares the blocks of the epochs from start to end (inclusive) with the blocks of the beacon
+// node and marks the orphaned ones like the indexer
+func (c *Chain) MarkOrphanedBlocks(start, end uint64) error {
+	blocks, err := exporter.GetLastBlocks(start, end, c.Client)
+	if err != nil {
+		return fmt.Errorf("error retrieving blocks of epochs %v-%v: %w", start, end, err)
+	}
+	return exporter.MarkOrphanedBlocks(start, end, blocks)
+}
+
+// RunRocketpoolExporter runs a single update of the rocketpool exporter
+func (c *Chain) RunRocketpoolExporter() error {
+	return exporter.RunRocketpoolExporterOnce(false, ioutil.Discard)
+}
+
+// Misses returns the requests of the beacon api and eth1 endpoint without a recorded response, a test should fail
+// with them if the fixture is incomplete
+func (c *Chain) Misses() []string {
+	misses := []string{}
+	if c.Beacon != nil {
+		misses = append(misses, c.Beacon.Misses()...)
+	}
+	if c.Eth1 != nil {
+		misses = append(misses, c.Eth1.Misses()...)
+	}
+	return misses
+}
+
+// Close stops the mocks and drops the temporary database
+func (c *Chain) Close() error {
+	errs := []string{}
+	if c.Database != "" {
+		if db.DB != nil {
+			db.DB.Close()
+		}
+		if db.FrontendDB != nil {
+			db.FrontendDB.Close()
+		}
+		err := c.Postgres.DropDatabase(c.Database)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if c.Eth1 != nil {
+		err := c.Eth1.Close()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if c.Beacon != nil {
+		c.Beacon.Close()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error closing chain: %v", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// repositoryRoot returns the directory of the go.mod of the working directory or its parents
+func repositoryRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,56 @@
+package harness
+
+import (
+	"eth2-exporter/db"
+	"testing"
+)
+
+// TestMarkOrphanedBlocks exports the reorg fixture, the block of slot 3 has been reorged out and the block of slot 2,
+// marked as orphaned before, is canonical again
+func TestMarkOrphanedBlocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	conn, err := PostgresFromEnv().connect(PostgresFromEnv().Database)
+	if err != nil {
+		t.Skipf("skipping integration test, postgres is not available: %v", err)
+	}
+	conn.Close()
+
+	c, err := NewChain("testdata/reorg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := c.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	err = c.MarkOrphanedBlocks(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if misses := c.Misses(); len(misses) > 0 {
+		t.Fatalf("fixture is missing responses: %v", misses)
+	}
+
+	blocks := []struct {
+		Slot   uint64 `db:"slot"`
+		Status string `db:"status"`
+	}{}
+	err = db.DB.Select(&blocks, "SELECT slot, status FROM blocks WHERE epoch = 1 ORDER BY slot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[uint64]string{2: "1", 3: "3"}
+	if len(blocks) != len(expected) {
+		t.Fatalf("expected %v blocks, got %v", len(expected), len(blocks))
+	}
+	for _, b := range blocks {
+		if b.Status != expected[b.Slot] {
+			t.Errorf("expected status %v of the block of slot %v, got %v", expected[b.Slot], b.Slot, b.Status)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package harness
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres is the server the temporary databases are created on, it defaults to the database of the integration
+// docker-compose file and is overridden by the INTEGRATION_DB_* environment variables
+type Postgres struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	// Database is the existing database used to create and drop the temporary databases
+	Database string
+}
+
+// PostgresFromEnv returns the server configured by INTEGRATION_DB_HOST, INTEGRATION_DB_PORT, INTEGRATION_DB_USER,
+// INTEGRATION_DB_PASSWORD and INTEGRATION_DB_NAME
+func PostgresFromEnv() *Postgres {
+	return &Postgres{
+		Host:     envOr("INTEGRATION_DB_HOST", "localhost"),
+		Port:     envOr("INTEGRATION_DB_PORT", "5432"),
+		User:     envOr("INTEGRATION_DB_USER", "beaconchain"),
+		Password: envOr("INTEGRATION_DB_PASSWORD", "xxx"),
+		Database: envOr("INTEGRATION_DB_NAME", "beaconchain"),
+	}
+}
+
+func (p *Postgres) connect(name string) (*sqlx.DB, error) {
+	return sqlx.Connect("pgx", fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", p.User, p.Password, p.Host, p.Port, name))
+}
+
+// CreateTempDatabase creates an empty database with a random name
+func (p *Postgres) CreateTempDatabase() (string, error) {
+	conn, err := p.connect(p.Database)
+	if err != nil {
+		return "", fmt.Errorf("error connecting to %v: %w", p.Database, err)
+	}
+	defer conn.Close()
+
+	name := fmt.Sprintf("integration_%d_%d", time.Now().Unix(), rand.New(rand.NewSource(time.Now().UnixNano())).Intn(1e6))
+	_, err = conn.Exec("CREATE DATABASE " + name)
+	if err != nil {
+		return "", fmt.Errorf("error creating database %v: %w", name, err)
+	}
+	return name, nil
+}
+
+// DropDatabase drops a database created by CreateTempDatabase, open connections to it are terminated
+func (p *Postgres) DropDatabase(name string) error {
+	conn, err := p.connect(p.Database)
+	if err != nil {
+		return fmt.Errorf("error connecting to %v: %w", p.Database, err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()", name)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec("DROP DATABASE IF EXISTS " + name)
+	return err
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,9 @@
+-- blocks of epoch 1 as exported before the reorg, the block of slot 2 is marked as orphaned and the block of slot 3 as
+-- proposed
+INSERT INTO blocks (epoch, slot, blockroot, parentroot, stateroot, signature, eth1data_depositcount, proposerslashingscount,
+    attesterslashingscount, attestationscount, depositscount, voluntaryexitscount, proposer, status)
+VALUES
+    (1, 2, '\x2222222222222222222222222222222222222222222222222222222222222222', '\x1111111111111111111111111111111111111111111111111111111111111111',
+     '\xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb', '\x00', 0, 0, 0, 0, 0, 0, 2, '3'),
+    (1, 3, '\x3333333333333333333333333333333333333333333333333333333333333333', '\x2222222222222222222222222222222222222222222222222222222222222222',
+     '\xcccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc', '\x00', 0, 0, 0, 0, 0, 0, 3, '1');
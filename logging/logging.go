@@ -0,0 +1,145 @@
+// Package logging configures the logrus loggers of all modules and correlates log entries of a single http request or
+// exporter run by attaching a request_id or run field to them
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// RequestIDHeader is the header a request id is read from (e.g. set by a load balancer) and returned in
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDRegex = regexp.MustCompile("^[a-zA-Z0-9-]{1,64}$")
+
+// slowRequestThreshold is the duration above which requests are logged with warning level instead of debug level, it is
+// accessed atomically as Init is called again when the config is reloaded
+var slowRequestThreshold = int64(time.Second * 2)
+
+// NewLogger returns the logger of a module, all module loggers share the output, format and level configured by Init
+func NewLogger(module string) *logrus.Entry {
+	return logrus.StandardLogger().WithField("module", module)
+}
+
+// Init sets the format ("text" or "json") and the level (e.g. "info" or "debug") of all loggers and the duration above
+// which http requests are logged as slow, empty values keep the defaults
+func Init(format, level string, slowRequestThresholdMs int) error {
+	switch format {
+	case "", "text":
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	default:
+		return fmt.Errorf("invalid log format %v, must be text or json", format)
+	}
+
+	if level != "" {
+		l, err := logrus.ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("invalid log level %v: %v", level, err)
+		}
+		logrus.SetLevel(l)
+	}
+
+	if slowRequestThresholdMs > 0 {
+		atomic.StoreInt64(&slowRequestThreshold, int64(time.Millisecond*time.Duration(slowRequestThresholdMs)))
+	}
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// NewRunID returns a random id for a single run of an exporter, log entries of the run carry it in the run field
+func NewRunID() string {
+	return newID()
+}
+
+// RequestID returns the id the middleware assigned to the request, an empty string is returned for requests that did
+// not pass the middleware
+func RequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// WithRequest adds the id of the request to the log entry
+func WithRequest(entry *logrus.Entry, r *http.Request) *logrus.Entry {
+	return WithContext(entry, r.Context())
+}
+
+// WithContext adds the id of the request the context belongs to to the log entry, e.g. in helpers that are passed the
+// context of the request instead of the request
+func WithContext(entry *logrus.Entry, ctx context.Context) *logrus.Entry {
+	id, _ := ctx.Value(requestIDKey).(string)
+	if id == "" {
+		return entry
+	}
+	return entry.WithField("request_id", id)
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush passes flushes on to the wrapped writer, e.g. for streamed server-sent events
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// RequestIDMiddleware assigns an id to every request, returns it in the X-Request-ID header and logs the duration and
+// status of the request with it, requests slower than the configured threshold are logged as warnings
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	logger := NewLogger("http")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDRegex.MatchString(id) {
+			id = newID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey, id))
+
+		sw := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(sw, r)
+		duration := time.Since(start)
+
+		entry := logger.WithFields(logrus.Fields{
+			"request_id": id,
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"status":     sw.status,
+			"duration":   duration.Milliseconds(),
+		})
+		if duration > time.Duration(atomic.LoadInt64(&slowRequestThreshold)) {
+			entry.Warn("slow request")
+		} else {
+			entry.Debug("request")
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	FeedbackBounce    = "bounce"
+	FeedbackComplaint = "complaint"
+)
+
+// Feedback is a hard bounce or a complaint about a mail reported by the provider
+type Feedback struct {
+	Email   string
+	Reason  string
+	Details string
+}
+
+// ParseFeedback parses the payload of the bounce and complaint webhook of the provider. Only hard bounces and
+// complaints are returned, soft bounces resolve themselves and must not suppress the address.
+func ParseFeedback(provider string, body []byte) ([]*Feedback, error) {
+	switch provider {
+	case "ses":
+		return parseSESFeedback(body)
+	case "sendgrid":
+		return parseSendGridFeedback(body)
+	case "mailgun":
+		return parseMailgunFeedback(body)
+	default:
+		return nil, fmt.Errorf("unknown mail provider %v", provider)
+	}
+}
+
+// parseSESFeedback parses the sns notifications of ses, the subscription of the sns topic is confirmed on the first
+// request. Messages without a valid sns signature are rejected.
+func parseSESFeedback(body []byte) ([]*Feedback, error) {
+	snsMessage := &snsMessage{}
+	err := json.Unmarshal(body, snsMessage)
+	if err != nil {
+		return nil, err
+	}
+	err = snsMessage.verify()
+	if err != nil {
+		return nil, err
+	}
+
+	switch snsMessage.Type {
+	case "SubscriptionConfirmation":
+		return nil, confirmSNSSubscription(snsMessage.SubscribeURL)
+	case "Notification":
+	default:
+		return nil, nil
+	}
+
+	notification := struct {
+		NotificationType string `json:"notificationType"`
+		Bounce           struct {
+			BounceType        string `json:"bounceType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			ComplaintFeedbackType string `json:"complaintFeedbackType"`
+			ComplainedRecipients  []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+	}{}
+	err = json.Unmarshal([]byte(snsMessage.Message), &notification)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback := []*Feedback{}
+	switch notification.NotificationType {
+	case "Bounce":
+		if notification.Bounce.BounceType != "Permanent" {
+			return feedback, nil
+		}
+		for _, r := range notification.Bounce.BouncedRecipients {
+			feedback = append(feedback, &Feedback{Email: r.EmailAddress, Reason: FeedbackBounce, Details: r.DiagnosticCode})
+		}
+	case "Complaint":
+		for _, r := range notification.Complaint.ComplainedRecipients {
+			feedback = append(feedback, &Feedback{Email: r.EmailAddress, Reason: FeedbackComplaint, Details: notification.Complaint.ComplaintFeedbackType})
+		}
+	}
+	return feedback, nil
+}
+
+// confirmSNSSubscription visits the subscribe url of a sns subscription confirmation, only urls of sns are visited
+func confirmSNSSubscription(subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil {
+		return err
+	}
+	if !validSNSURL(u) {
+		return fmt.Errorf("invalid sns subscribe url %v", subscribeURL)
+	}
+
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error confirming sns subscription: status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// parseSendGridFeedback parses the events of the sendgrid event webhook
+func parseSendGridFeedback(body []byte) ([]*Feedback, error) {
+	events := []struct {
+		Email  string `json:"email"`
+		Event  string `json:"event"`
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}{}
+	err := json.Unmarshal(body, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback := []*Feedback{}
+	for _, e := range events {
+		switch {
+		// blocked bounces are temporary rejections of the receiving server
+		case e.Event == "bounce" && e.Type != "blocked":
+			feedback = append(feedback, &Feedback{Email: e.Email, Reason: FeedbackBounce, Details: e.Reason})
+		case e.Event == "spamreport":
+			feedback = append(feedback, &Feedback{Email: e.Email, Reason: FeedbackComplaint})
+		}
+	}
+	return feedback, nil
+}
+
+// parseMailgunFeedback parses a mailgun webhook of the failed or complained event
+func parseMailgunFeedback(body []byte) ([]*Feedback, error) {
+	payload := struct {
+		EventData struct {
+			Event          string `json:"event"`
+			Severity       string `json:"severity"`
+			Recipient      string `json:"recipient"`
+			DeliveryStatus struct {
+				Description string `json:"description"`
+				Message     string `json:"message"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}{}
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	e := payload.EventData
+	feedback := []*Feedback{}
+	switch {
+	case e.Event == "failed" && e.Severity == "permanent":
+		details := e.DeliveryStatus.Description
+		if details == "" {
+			details = e.DeliveryStatus.Message
+		}
+		feedback = append(feedback, &Feedback{Email: e.Recipient, Reason: FeedbackBounce, Details: details})
+	case e.Event == "complained":
+		feedback = append(feedback, &Feedback{Email: e.Recipient, Reason: FeedbackComplaint})
+	}
+	return feedback, nil
+}
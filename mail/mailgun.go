@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"context"
+	"eth2-exporter/utils"
+	"fmt"
+
+	"github.com/mailgun/mailgun-go/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// mailgunProvider sends the mails via the mailgun api
+type mailgunProvider struct {
+	mg *mailgun.MailgunImpl
+}
+
+func newMailgunProvider() *mailgunProvider {
+	return &mailgunProvider{mg: mailgun.NewMailgun(
+		utils.Config.Frontend.Mail.Mailgun.Domain,
+		utils.Config.Frontend.Mail.Mailgun.PrivateKey,
+	)}
+}
+
+func (p *mailgunProvider) Send(ctx context.Context, from string, message *Message) (string, error) {
+	msg := p.mg.NewMessage(from, message.Subject, message.Text, message.To)
+	if message.HTML != "" {
+		msg.SetHtml(message.HTML)
+	}
+	for _, att := range message.Attachments {
+		msg.AddBufferAttachment(att.Name, att.Attachment)
+	}
+
+	resp, id, err := p.mg.Send(ctx, msg)
+	if err != nil {
+		logrus.WithField("resp", resp).WithField("id", id).Errorf("error sending mail via mailgun: %v", err)
+		return resp, fmt.Errorf("error sending mail via mailgun: %w", err)
+	}
+	return fmt.Sprintf("%v (id %v)", resp, id), nil
+}
@@ -0,0 +1,214 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is an email with a plain text body and an optional html alternative
+type Message struct {
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []types.EmailAttachment
+}
+
+// Provider delivers emails, the returned string is the response of the provider (e.g. the message id) that is kept in
+// the notifications log
+type Provider interface {
+	Send(ctx context.Context, from string, message *Message) (string, error)
+}
+
+var provider Provider
+var providerErr error
+var providerOnce sync.Once
+
+// getProvider returns the provider of the config, it is created on first use
+func getProvider() (Provider, error) {
+	providerOnce.Do(func() {
+		provider, providerErr = newProvider(providerName())
+		if providerErr == nil {
+			logrus.Infof("sending mails via %v", providerName())
+		}
+	})
+	return provider, providerErr
+}
+
+// providerName returns the configured provider, configs without provider use smtp if a smtp user and mailgun if a
+// mailgun key is set
+func providerName() string {
+	cfg := utils.Config.Frontend.Mail
+	if cfg.Provider != "" {
+		return cfg.Provider
+	}
+	if cfg.SMTP.User != "" {
+		return "smtp"
+	}
+	if cfg.Mailgun.PrivateKey != "" {
+		return "mailgun"
+	}
+	return ""
+}
+
+// CheckProvider verifies the mail provider of the config without sending a mail and returns its name, the smtp
+// provider connects and authenticates with the smtp server, the other providers check that their settings are set.
+// An empty name is returned if no provider is configured.
+func CheckProvider() (string, error) {
+	name := providerName()
+	cfg := utils.Config.Frontend.Mail
+	switch name {
+	case "":
+		return "", nil
+	case "smtp":
+		return name, checkSMTP()
+	case "mailgun":
+		if cfg.Mailgun.Domain == "" || cfg.Mailgun.PrivateKey == "" {
+			return name, fmt.Errorf("frontend.mail.mailgun.domain and frontend.mail.mailgun.privateKey must be set")
+		}
+	case "sendgrid":
+		if cfg.SendGrid.ApiKey == "" {
+			return name, fmt.Errorf("frontend.mail.sendgrid.apiKey must be set")
+		}
+	}
+	if sender() == "" {
+		return name, fmt.Errorf("frontend.mail.sender must be set")
+	}
+	_, err := newProvider(name)
+	return name, err
+}
+
+func newProvider(name string) (Provider, error) {
+	switch name {
+	case "smtp":
+		return &smtpProvider{}, nil
+	case "mailgun":
+		return newMailgunProvider(), nil
+	case "ses":
+		p, err := newSESProvider()
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case "sendgrid":
+		return &sendGridProvider{}, nil
+	default:
+		return nil, fmt.Errorf("invalid config for mail-service: unknown provider %q", name)
+	}
+}
+
+// sender returns the from address of the mails, the sender of the config takes precedence over the provider specific
+// addresses of older configs
+func sender() string {
+	cfg := utils.Config.Frontend.Mail
+	if cfg.Sender != "" {
+		return cfg.Sender
+	}
+	if cfg.Mailgun.Sender != "" {
+		return cfg.Mailgun.Sender
+	}
+	return cfg.SMTP.User
+}
+
+// buildMIMEMessage encodes the message as multipart mime message for the providers that send raw messages
+func buildMIMEMessage(from string, message *Message) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	mixed := multipart.NewWriter(buf)
+
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	altBuf := &bytes.Buffer{}
+	alternative := multipart.NewWriter(altBuf)
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	for _, p := range parts {
+		if p.body == "" {
+			continue
+		}
+		w, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(base64Lines([]byte(p.body)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := alternative.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%s", alternative.Boundary())},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(altBuf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, att := range message.Attachments {
+		contentType := mime.TypeByExtension(filepath.Ext(att.Name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(base64Lines(att.Attachment))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mixed.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// base64Lines encodes the data as base64 with lines of 76 characters as required by mime
+func base64Lines(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	buf := &bytes.Buffer{}
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,87 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"eth2-exporter/utils"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridProvider sends the mails via the v3 mail send api of sendgrid with the api key of the config
+type sendGridProvider struct{}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content  string `json:"content"`
+	Filename string `json:"filename"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+func (p *sendGridProvider) Send(ctx context.Context, from string, message *Message) (string, error) {
+	req := &sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: message.To}}}},
+		From:             sendGridAddress{Email: from},
+		Subject:          message.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: message.Text}},
+	}
+	if message.HTML != "" {
+		req.Content = append(req.Content, sendGridContent{Type: "text/html", Value: message.HTML})
+	}
+	for _, att := range message.Attachments {
+		req.Attachments = append(req.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(att.Attachment),
+			Filename: att.Name,
+		})
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("error encoding mail: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", sendGridEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+utils.SendGridApiKey())
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("error sending mail via sendgrid: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// sendgrid answers with 202 and an empty body on success, the message id is returned as header
+	if resp.StatusCode != http.StatusAccepted {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return string(respBody), fmt.Errorf("error sending mail via sendgrid: status %v", resp.StatusCode)
+	}
+	return resp.Header.Get("X-Message-Id"), nil
+}
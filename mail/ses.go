@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"eth2-exporter/utils"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+// sesProvider sends the mails via amazon ses, the credentials are taken from the environment (AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY or the instance role)
+type sesProvider struct {
+	client *ses.SES
+}
+
+func newSESProvider() (*sesProvider, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(utils.Config.Frontend.Mail.SES.Region)})
+	if err != nil {
+		return nil, err
+	}
+	return &sesProvider{client: ses.New(sess)}, nil
+}
+
+func (p *sesProvider) Send(ctx context.Context, from string, message *Message) (string, error) {
+	msg, err := buildMIMEMessage(from, message)
+	if err != nil {
+		return "", fmt.Errorf("error encoding mail: %w", err)
+	}
+
+	out, err := p.client.SendRawEmailWithContext(ctx, &ses.SendRawEmailInput{
+		Source:       aws.String(from),
+		Destinations: []*string{aws.String(message.To)},
+		RawMessage:   &ses.RawMessage{Data: msg},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error sending mail via ses: %w", err)
+	}
+	return aws.StringValue(out.MessageId), nil
+}
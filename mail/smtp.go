@@ -0,0 +1,63 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"eth2-exporter/utils"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// smtpProvider sends the mails via the smtp server of the config, the smtp user is the default sender
+type smtpProvider struct{}
+
+func (p *smtpProvider) Send(ctx context.Context, from string, message *Message) (string, error) {
+	cfg := utils.Config.Frontend.Mail.SMTP
+	user, password := utils.SMTPCredentials()
+	auth := smtp.PlainAuth("", user, password, cfg.Host) // eg. userxyz123@gmail.com at smtp.gmail.com
+
+	msg, err := buildMIMEMessage(from, message)
+	if err != nil {
+		return "", fmt.Errorf("error encoding mail: %w", err)
+	}
+
+	err = smtp.SendMail(cfg.Server, auth, from, []string{message.To}, msg) // eg. smtp.gmail.com:587
+	if err != nil {
+		return "", fmt.Errorf("error sending mail via smtp: %w", err)
+	}
+	return "", nil
+}
+
+// checkSMTP connects to the smtp server of the config and authenticates with its credentials the same way SendMail does
+func checkSMTP() error {
+	cfg := utils.Config.Frontend.Mail.SMTP
+	if cfg.Server == "" {
+		return fmt.Errorf("frontend.mail.smtp.server must be set")
+	}
+	conn, err := net.DialTimeout("tcp", cfg.Server, time.Second*10)
+	if err != nil {
+		return fmt.Errorf("error connecting to smtp server %v: %w", cfg.Server, err)
+	}
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error connecting to smtp server %v: %w", cfg.Server, err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: cfg.Host})
+		if err != nil {
+			return fmt.Errorf("error starting tls with smtp server %v: %w", cfg.Server, err)
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok {
+		err = c.Auth(smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host))
+		if err != nil {
+			return fmt.Errorf("error authenticating as %v with smtp server %v: %w", cfg.User, cfg.Server, err)
+		}
+	}
+	return c.Quit()
+}
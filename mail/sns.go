@@ -0,0 +1,164 @@
+package mail
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"eth2-exporter/utils"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snsHostRegex matches the hosts of sns in all aws partitions, the signing certificates and subscribe urls have to be
+// served by them
+var snsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// snsCertificates caches the signing certificates of sns by url
+var snsCertificates = map[string]*x509.Certificate{}
+var snsCertificatesMux = &sync.Mutex{}
+
+// snsMessage is a message of sns as posted to http subscriptions
+type snsMessage struct {
+	Type             string
+	MessageId        string
+	Token            string
+	TopicArn         string
+	Subject          string
+	Message          string
+	Timestamp        string
+	SignatureVersion string
+	Signature        string
+	SigningCertURL   string
+	SubscribeURL     string
+}
+
+// verify checks the signature of the message with the signing certificate of sns, if a topic is configured the
+// message has to be published to it
+func (m *snsMessage) verify() error {
+	topic := utils.Config.Frontend.Mail.SES.TopicArn
+	if topic != "" && m.TopicArn != topic {
+		return fmt.Errorf("sns message of unexpected topic %v", m.TopicArn)
+	}
+
+	var hash crypto.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported sns signature version %q", m.SignatureVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid sns signature: %w", err)
+	}
+	cert, err := snsCertificate(m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("sns signing certificate has no rsa key")
+	}
+
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(m.stringToSign()))
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(m.stringToSign()))
+		digest = sum[:]
+	}
+	err = rsa.VerifyPKCS1v15(publicKey, hash, digest, signature)
+	if err != nil {
+		return fmt.Errorf("invalid sns signature: %w", err)
+	}
+	return nil
+}
+
+// stringToSign returns the fields of the message sns signs, in the order and format of the sns documentation
+func (m *snsMessage) stringToSign() string {
+	fields := [][2]string{{"Message", m.Message}, {"MessageId", m.MessageId}}
+	switch m.Type {
+	case "Notification":
+		if m.Subject != "" {
+			fields = append(fields, [2]string{"Subject", m.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", m.Timestamp}, [2]string{"TopicArn", m.TopicArn})
+	default:
+		fields = append(fields, [2]string{"SubscribeURL", m.SubscribeURL}, [2]string{"Timestamp", m.Timestamp},
+			[2]string{"Token", m.Token}, [2]string{"TopicArn", m.TopicArn})
+	}
+	fields = append(fields, [2]string{"Type", m.Type})
+
+	b := strings.Builder{}
+	for _, f := range fields {
+		b.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	return b.String()
+}
+
+// validSNSURL returns true if the url is a https url of sns
+func validSNSURL(u *url.URL) bool {
+	return u.Scheme == "https" && snsHostRegex.MatchString(u.Host)
+}
+
+// snsCertificate returns the signing certificate at the url, only certificates served by sns are accepted
+func snsCertificate(certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil {
+		return nil, err
+	}
+	if !validSNSURL(u) || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, fmt.Errorf("invalid sns signing certificate url %v", certURL)
+	}
+
+	snsCertificatesMux.Lock()
+	cert, ok := snsCertificates[certURL]
+	snsCertificatesMux.Unlock()
+	if ok && time.Now().Before(cert.NotAfter) {
+		return cert, nil
+	}
+
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error retrieving sns signing certificate: status %v", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, fmt.Errorf("invalid sns signing certificate")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("sns signing certificate is not valid")
+	}
+
+	snsCertificatesMux.Lock()
+	snsCertificates[certURL] = cert
+	snsCertificatesMux.Unlock()
+	return cert, nil
+}
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"bytes"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"html/template"
+	"path/filepath"
+	"sync"
+)
+
+var mailTemplates *template.Template
+var mailTemplatesErr error
+var mailTemplatesOnce sync.Once
+
+// getMailTemplates parses all templates of the templates directory of the mail config
+func getMailTemplates() (*template.Template, error) {
+	mailTemplatesOnce.Do(func() {
+		dir := utils.Config.Frontend.Mail.TemplatesDir
+		if dir == "" {
+			dir = "templates/mail"
+		}
+		mailTemplates, mailTemplatesErr = template.New("mail").Funcs(utils.GetTemplateFuncs()).ParseGlob(filepath.Join(dir, "*.html"))
+	})
+	return mailTemplates, mailTemplatesErr
+}
+
+// NotificationSection is the part of a notification mail with the notifications of one event
+type NotificationSection struct {
+	EventName     types.EventName
+	Notifications []string
+}
+
+type notificationSectionData struct {
+	Lang          string
+	EventName     types.EventName
+	Notifications []string
+}
+
+type notificationMailData struct {
+	Lang       string
+	SiteDomain string
+	Sections   []template.HTML
+}
+
+// RenderNotificationMail renders the html body of a notification mail in the language of the user. The section of an
+// event is rendered with the template "event_<event name>" if there is one and with "event_default" otherwise.
+func RenderNotificationMail(lang string, sections []*NotificationSection) (string, error) {
+	t, err := getMailTemplates()
+	if err != nil {
+		return "", err
+	}
+	if !utils.IsSupportedLanguage(lang) {
+		lang = utils.DefaultLanguage
+	}
+
+	data := &notificationMailData{
+		Lang:       lang,
+		SiteDomain: utils.Config.Frontend.SiteDomain,
+		Sections:   make([]template.HTML, 0, len(sections)),
+	}
+	for _, s := range sections {
+		name := "event_" + string(s.EventName)
+		if t.Lookup(name) == nil {
+			name = "event_default"
+		}
+		buf := &bytes.Buffer{}
+		err = t.ExecuteTemplate(buf, name, &notificationSectionData{Lang: lang, EventName: s.EventName, Notifications: s.Notifications})
+		if err != nil {
+			return "", err
+		}
+		data.Sections = append(data.Sections, template.HTML(buf.String()))
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.ExecuteTemplate(buf, "notification", data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"eth2-exporter/logging"
 	"eth2-exporter/version"
 	"net/http"
 	"regexp"
@@ -13,7 +14,6 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -42,9 +42,56 @@ var (
 		Name: "db_long_running_queries",
 		Help: "Counter of long-running-queries with datbase and query in labels",
 	}, []string{"database", "query"})
+	RPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpc_requests_total",
+		Help: "Total number of rpc requests by endpoint, method and status.",
+	}, []string{"endpoint", "method", "status"})
+	RPCEndpointHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rpc_endpoint_healthy",
+		Help: "Gauge that is 1 if the rpc endpoint is considered healthy and 0 otherwise.",
+	}, []string{"endpoint"})
+	DataIntegrityChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "data_integrity_checks",
+		Help: "Counter of data integrity checks comparing the database against the beacon-node by check",
+	}, []string{"check"})
+	DataIntegrityIssues = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "data_integrity_issues",
+		Help: "Counter of mismatches found between the database and the beacon-node by check",
+	}, []string{"check"})
+	BlockPropagationDelay = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "block_propagation_delay",
+		Help:    "Histogram of the time after the start of the slot blocks are first seen by node",
+		Buckets: []float64{0.5, 1, 2, 3, 4, 6, 8, 12},
+	}, []string{"node"})
+	HttpRequestDBQueries = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_db_queries",
+		Help:    "Histogram of the number of db queries executed per http request by path",
+		Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100, 200},
+	}, []string{"path"})
+	HttpRequestDBDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_db_duration",
+		Help:    "Histogram of the cumulative time spent in db queries per http request in seconds by path",
+		Buckets: []float64{.005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"path"})
+	LeaderElectionLeader = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "leader_election_leader",
+		Help: "Gauge that is 1 if this instance is the leader of the task and runs it",
+	}, []string{"task"})
+	DBPoolConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "db_pool_connections",
+		Help: "Gauge of the connections of a db pool by state (in_use, idle, max_open)",
+	}, []string{"pool", "state"})
+	DBPoolWaitCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "db_pool_wait_count",
+		Help: "Total number of connections of a db pool that had to be waited for",
+	}, []string{"pool"})
+	DBPoolWaitDuration = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "db_pool_wait_duration_seconds",
+		Help: "Total time spent waiting for connections of a db pool in seconds",
+	}, []string{"pool"})
 )
 
-var logger = logrus.New().WithField("module", "metrics")
+var logger = logging.NewLogger("metrics")
 
 func init() {
 	Version.WithLabelValues(version.Version).Set(1)
@@ -72,6 +119,21 @@ func MonitorDB(db *sqlx.DB) {
 	}
 }
 
+// MonitorDBPool regularly exports the stats of the connection pool, a wait count that keeps rising means the pool is
+// exhausted
+func MonitorDBPool(name string, db *sqlx.DB) {
+	t := time.NewTicker(time.Second * 15)
+	defer t.Stop()
+	for ; true; <-t.C {
+		stats := db.Stats()
+		DBPoolConnections.WithLabelValues(name, "in_use").Set(float64(stats.InUse))
+		DBPoolConnections.WithLabelValues(name, "idle").Set(float64(stats.Idle))
+		DBPoolConnections.WithLabelValues(name, "max_open").Set(float64(stats.MaxOpenConnections))
+		DBPoolWaitCount.WithLabelValues(name).Set(float64(stats.WaitCount))
+		DBPoolWaitDuration.WithLabelValues(name).Set(stats.WaitDuration.Seconds())
+	}
+}
+
 // HttpMiddleware implements mux.MiddlewareFunc.
 // This middleware uses the path template, so the label value will be /obj/{id} rather than /obj/123 which would risk a cardinality explosion.
 // See https://www.robustperception.io/prometheus-middleware-for-gorilla-mux
@@ -116,6 +178,13 @@ func (r *responseWriterDelegator) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Flush passes flushes on to the wrapped writer, e.g. for streamed server-sent events
+func (r *responseWriterDelegator) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Serve serves prometheus metrics on the given address under /metrics
 func Serve(addr string) error {
 	router := http.NewServeMux()
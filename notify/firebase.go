@@ -2,15 +2,15 @@ package notify
 
 import (
 	"context"
+	"eth2-exporter/logging"
 	"eth2-exporter/utils"
 
 	firebase "firebase.google.com/go"
 	"firebase.google.com/go/messaging"
-	"github.com/sirupsen/logrus"
 	"google.golang.org/api/option"
 )
 
-var logger = logrus.New().WithField("module", "notify").WithField("service", "firebase")
+var logger = logging.NewLogger("notify").WithField("service", "firebase")
 
 func SendPushBatch(messages []*messaging.Message) (*messaging.BatchResponse, error) {
 	credentialsPath := utils.Config.Notifications.FirebaseCredentialsPath
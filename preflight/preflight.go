@@ -0,0 +1,411 @@
+// Package preflight validates the config against the databases, nodes and services it points to, so that a broken
+// config is reported before the explorer starts instead of failing deep inside an exporter
+package preflight
+
+import (
+	"context"
+	"eth2-exporter/mail"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jmoiron/sqlx"
+)
+
+const checkTimeout = time.Second * 15
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusWarning Status = "warning"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Check is the result of a single check, Hint describes how to fix a warning or failure
+type Check struct {
+	Name    string
+	Status  Status
+	Message string
+	Hint    string
+}
+
+// Report is the result of all checks
+type Report struct {
+	Checks []*Check
+}
+
+func (r *Report) add(name string, status Status, message, hint string) {
+	r.Checks = append(r.Checks, &Check{Name: name, Status: status, Message: message, Hint: hint})
+}
+
+// Failed returns true if any check failed
+func (r *Report) Failed() bool {
+	for _, c := range r.Checks {
+		if c.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Warned returns true if any check has a warning
+func (r *Report) Warned() bool {
+	for _, c := range r.Checks {
+		if c.Status == StatusWarning {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the report in a human readable form
+func (r *Report) Print(w io.Writer) {
+	counts := map[Status]int{}
+	for _, c := range r.Checks {
+		counts[c.Status]++
+		fmt.Fprintf(w, "[%-7v] %v: %v\n", strings.ToUpper(string(c.Status)), c.Name, c.Message)
+		if c.Hint != "" && (c.Status == StatusFailed || c.Status == StatusWarning) {
+			fmt.Fprintf(w, "          -> %v\n", c.Hint)
+		}
+	}
+	fmt.Fprintf(w, "%v checks: %v ok, %v warnings, %v failed, %v skipped\n", len(r.Checks), counts[StatusOK], counts[StatusWarning], counts[StatusFailed], counts[StatusSkipped])
+}
+
+// Run checks the config and every database, node and service of the config that is used by the enabled components
+func Run(cfg *types.Config) *Report {
+	r := &Report{}
+	checkChainConfig(r, cfg)
+	checkDatabases(r, cfg)
+	checkBeaconNodes(r, cfg)
+	eth1Client := checkEth1Endpoints(r, cfg)
+	if eth1Client != nil {
+		defer eth1Client.Close()
+	}
+	checkRocketpool(r, cfg, eth1Client)
+	checkMail(r, cfg)
+	return r
+}
+
+func checkChainConfig(r *Report, cfg *types.Config) {
+	err := utils.ValidateChainConfig(cfg)
+	if err != nil {
+		r.add("chain config", StatusFailed, err.Error(), "set the missing parameters in the chain section or the preset files of the config")
+		return
+	}
+	r.add("chain config", StatusOK, fmt.Sprintf("network %v, %v slots per epoch, %v seconds per slot", cfg.Chain.Phase0.ConfigName, cfg.Chain.Phase0.SlotsPerEpoch, cfg.Chain.Phase0.SecondsPerSlot), "")
+}
+
+// checkDatabases connects to the explorer and the frontend database and checks that every table of the schema file
+// exists in one of them
+func checkDatabases(r *Report, cfg *types.Config) {
+	type database struct {
+		name                                   string
+		username, password, host, port, dbName string
+	}
+	databases := []database{
+		{"database", cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name},
+		{"frontend database", cfg.Frontend.Database.Username, cfg.Frontend.Database.Password, cfg.Frontend.Database.Host, cfg.Frontend.Database.Port, cfg.Frontend.Database.Name},
+	}
+
+	tables := map[string]bool{}
+	connected := 0
+	for _, d := range databases {
+		if d.host == "" {
+			r.add(d.name, StatusSkipped, "no host configured", "")
+			continue
+		}
+		dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", d.username, d.password, d.host, d.port, d.dbName)
+		existing, err := getDatabaseTables(dsn)
+		if err != nil {
+			r.add(d.name, StatusFailed, fmt.Sprintf("error connecting to %v:%v/%v: %v", d.host, d.port, d.dbName, err), "check the host, port, name and credentials of the database and that it accepts connections from this host")
+			continue
+		}
+		for _, t := range existing {
+			tables[t] = true
+		}
+		connected++
+		r.add(d.name, StatusOK, fmt.Sprintf("connected to %v:%v/%v, %v tables", d.host, d.port, d.dbName, len(existing)), "")
+	}
+	if connected < len(databases) {
+		r.add("database schema", StatusSkipped, "not all databases are reachable", "")
+		return
+	}
+
+	schemaPath := cfg.Chain.Bootstrap.SchemaPath
+	if schemaPath == "" {
+		schemaPath = "tables.sql"
+	}
+	schema, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		r.add("database schema", StatusWarning, fmt.Sprintf("error reading schema file: %v", err), "run the check from the root of the repository or set chain.bootstrap.schemaPath")
+		return
+	}
+	missing := []string{}
+	for _, m := range regexp.MustCompile(`(?i)create\s+table\s+(?:if\s+not\s+exists\s+)?([a-z0-9_]+)`).FindAllStringSubmatch(string(schema), -1) {
+		if !tables[strings.ToLower(m[1])] {
+			missing = append(missing, m[1])
+		}
+	}
+	if len(missing) > 0 {
+		// tables of disabled components might be missing on purpose, a missing table fails the component using it
+		sort.Strings(missing)
+		r.add("database schema", StatusWarning, fmt.Sprintf("%v tables of %v are missing: %v", len(missing), schemaPath, strings.Join(missing, ", ")), fmt.Sprintf("the database schema is older than the explorer, create the missing tables of %v", schemaPath))
+		return
+	}
+	r.add("database schema", StatusOK, fmt.Sprintf("all tables of %v exist", schemaPath), "")
+}
+
+func getDatabaseTables(dsn string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	conn, err := sqlx.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	tables := []string{}
+	err = conn.SelectContext(ctx, &tables, "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()")
+	return tables, err
+}
+
+// checkBeaconNodes checks that the beacon node of the frontend and the nodes of the indexer are reachable and follow
+// the chain of the config. Findings of the fallback nodes of the indexer are warnings, the indexer fails over to the
+// healthy nodes.
+func checkBeaconNodes(r *Report, cfg *types.Config) {
+	type beaconNode struct {
+		endpoint string
+		fallback bool
+	}
+	nodes := []beaconNode{}
+	if cfg.Frontend.Enabled && cfg.Frontend.BeaconNodeEndpoint != "" {
+		nodes = append(nodes, beaconNode{endpoint: strings.TrimSuffix(cfg.Frontend.BeaconNodeEndpoint, "/")})
+	}
+	if cfg.Indexer.Enabled {
+		for i, node := range append([]string{cfg.Indexer.Node.Host + ":" + cfg.Indexer.Node.Port}, cfg.Indexer.Node.Fallbacks...) {
+			if cfg.Indexer.Node.Type == "lighthouse" {
+				nodes = append(nodes, beaconNode{endpoint: "http://" + node, fallback: i > 0})
+				continue
+			}
+			// the grpc api of prysm is only checked for reachability
+			name := fmt.Sprintf("beacon node %v", node)
+			conn, err := net.DialTimeout("tcp", node, checkTimeout)
+			if err != nil {
+				r.add(name, failedStatus(i > 0), fmt.Sprintf("error connecting: %v", err), "check indexer.node.host, indexer.node.port and indexer.node.fallbacks")
+				continue
+			}
+			conn.Close()
+			r.add(name, StatusOK, "reachable", "")
+		}
+	}
+	if len(nodes) == 0 {
+		r.add("beacon node", StatusSkipped, "no beacon node endpoint configured", "")
+		return
+	}
+
+	for _, node := range nodes {
+		endpoint := node.endpoint
+		failed := failedStatus(node.fallback)
+		name := fmt.Sprintf("beacon node %v", endpoint)
+		depositContract := struct {
+			ChainID string `json:"chain_id"`
+			Address string `json:"address"`
+		}{}
+		err := utils.GetBeaconNodeData(endpoint+"/eth/v1/config/deposit_contract", &depositContract)
+		if err != nil {
+			r.add(name, failed, fmt.Sprintf("error retrieving deposit contract: %v", err), "check that the endpoint is the url of the standard beacon node api and that the node is running")
+			continue
+		}
+		chainID, err := strconv.ParseUint(depositContract.ChainID, 10, 64)
+		if err != nil {
+			r.add(name, failed, fmt.Sprintf("error parsing chain id %q: %v", depositContract.ChainID, err), "")
+			continue
+		}
+		if cfg.Chain.Phase0.DepositChainID != 0 && chainID != cfg.Chain.Phase0.DepositChainID {
+			r.add(name, failed, fmt.Sprintf("the node follows chain %v, the config is for chain %v", chainID, cfg.Chain.Phase0.DepositChainID), "point the endpoint to a node of the configured network or fix the chain config")
+			continue
+		}
+		if cfg.Chain.Phase0.DepositContractAddress != "" && !strings.EqualFold(depositContract.Address, cfg.Chain.Phase0.DepositContractAddress) {
+			r.add(name, failed, fmt.Sprintf("the deposit contract of the node is %v, the config has %v", depositContract.Address, cfg.Chain.Phase0.DepositContractAddress), "point the endpoint to a node of the configured network or fix the chain config")
+			continue
+		}
+
+		syncing := struct {
+			IsSyncing bool `json:"is_syncing"`
+		}{}
+		err = utils.GetBeaconNodeData(endpoint+"/eth/v1/node/syncing", &syncing)
+		if err != nil {
+			r.add(name, StatusWarning, fmt.Sprintf("chain %v, error retrieving sync status: %v", chainID, err), "")
+			continue
+		}
+		if syncing.IsSyncing {
+			r.add(name, StatusWarning, fmt.Sprintf("chain %v, the node is syncing", chainID), "the explorer lags behind the head until the node is synced")
+			continue
+		}
+		r.add(name, StatusOK, fmt.Sprintf("chain %v, synced", chainID), "")
+	}
+}
+
+// checkEth1Endpoints checks that the eth1 endpoints follow the chain of the config, a client of the first healthy
+// endpoint is returned for the following checks. Findings of the fallback endpoints are warnings.
+func checkEth1Endpoints(r *Report, cfg *types.Config) *ethclient.Client {
+	if cfg.Indexer.Eth1Endpoint == "" {
+		r.add("eth1 endpoint", StatusSkipped, "no eth1 endpoint configured", "")
+		return nil
+	}
+
+	var healthy *ethclient.Client
+	for i, endpoint := range append([]string{cfg.Indexer.Eth1Endpoint}, cfg.Indexer.Eth1FallbackEndpoints...) {
+		name := fmt.Sprintf("eth1 endpoint %v", endpoint)
+		failed := failedStatus(i > 0)
+		client, chainID, err := dialEth1(endpoint)
+		if err != nil {
+			r.add(name, failed, err.Error(), "check indexer.eth1Endpoint and indexer.eth1FallbackEndpoints and that the node is running")
+			continue
+		}
+		if cfg.Chain.Phase0.DepositChainID != 0 && chainID.Uint64() != cfg.Chain.Phase0.DepositChainID {
+			client.Close()
+			r.add(name, failed, fmt.Sprintf("the node follows chain %v, the config is for chain %v", chainID, cfg.Chain.Phase0.DepositChainID), "point the endpoint to a node of the configured network")
+			continue
+		}
+		if cfg.Indexer.Eth1DepositContractAddress != "" {
+			err = checkContractCode(client, cfg.Indexer.Eth1DepositContractAddress)
+			if err != nil {
+				client.Close()
+				r.add(name, failed, fmt.Sprintf("deposit contract: %v", err), "check indexer.eth1DepositContractAddress")
+				continue
+			}
+		}
+		r.add(name, StatusOK, fmt.Sprintf("chain %v", chainID), "")
+		if healthy == nil {
+			healthy = client
+		} else {
+			client.Close()
+		}
+	}
+	return healthy
+}
+
+// failedStatus returns the status of a failed check of an endpoint, a failing fallback endpoint is only a warning
+func failedStatus(fallback bool) Status {
+	if fallback {
+		return StatusWarning
+	}
+	return StatusFailed
+}
+
+func dialEth1(endpoint string) (*ethclient.Client, *big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("error retrieving chain id: %w", err)
+	}
+	return client, chainID, nil
+}
+
+// checkContractCode returns an error if the address is invalid or no contract is deployed at it
+func checkContractCode(client *ethclient.Client, address string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%q is not an address", address)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	code, err := client.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return fmt.Errorf("error retrieving code of %v: %w", address, err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no contract is deployed at %v", address)
+	}
+	return nil
+}
+
+const rocketStorageABI = `[{"inputs":[],"name":"getDeployedStatus","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"}]`
+
+// checkRocketpool checks that the storage contract of the rocketpool exporter is a deployed rocketpool storage contract
+func checkRocketpool(r *Report, cfg *types.Config, client *ethclient.Client) {
+	const name = "rocketpool storage contract"
+	if !cfg.RocketpoolExporter.Enabled {
+		r.add(name, StatusSkipped, "the rocketpool exporter is disabled", "")
+		return
+	}
+	if client == nil {
+		r.add(name, StatusFailed, "no healthy eth1 endpoint", "the rocketpool exporter requires indexer.eth1Endpoint")
+		return
+	}
+	address := cfg.RocketpoolExporter.StorageContractAddress
+	hint := "set rocketpoolExporter.storageContractAddress to the RocketStorage contract of the network"
+	err := checkContractCode(client, address)
+	if err != nil {
+		r.add(name, StatusFailed, err.Error(), hint)
+		return
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(rocketStorageABI))
+	if err != nil {
+		r.add(name, StatusFailed, fmt.Sprintf("error parsing abi: %v", err), "")
+		return
+	}
+	data, err := parsed.Pack("getDeployedStatus")
+	if err != nil {
+		r.add(name, StatusFailed, fmt.Sprintf("error packing call: %v", err), "")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	contract := common.HexToAddress(address)
+	res, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		r.add(name, StatusFailed, fmt.Sprintf("error calling getDeployedStatus of %v: %v", address, err), hint)
+		return
+	}
+	deployed, err := parsed.Unpack("getDeployedStatus", res)
+	if err != nil || len(deployed) != 1 {
+		r.add(name, StatusFailed, fmt.Sprintf("%v is not a rocketpool storage contract", address), hint)
+		return
+	}
+	if ok, _ := deployed[0].(bool); !ok {
+		r.add(name, StatusFailed, fmt.Sprintf("the rocketpool contracts of %v are not deployed yet", address), hint)
+		return
+	}
+	r.add(name, StatusOK, fmt.Sprintf("%v is deployed", address), "")
+}
+
+func checkMail(r *Report, cfg *types.Config) {
+	const name = "mail provider"
+	if !cfg.Frontend.Enabled {
+		r.add(name, StatusSkipped, "the frontend is disabled", "")
+		return
+	}
+	provider, err := mail.CheckProvider()
+	if provider == "" {
+		r.add(name, StatusWarning, "no mail provider configured, no mails are sent", "configure frontend.mail to send confirmation and notification mails")
+		return
+	}
+	if err != nil {
+		r.add(name, StatusFailed, fmt.Sprintf("%v: %v", provider, err), "check the credentials and the server of the provider in frontend.mail")
+		return
+	}
+	r.add(name, StatusOK, provider, "")
+}
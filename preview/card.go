@@ -0,0 +1,176 @@
+package preview
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// size of the card as recommended for OpenGraph and Twitter summary_large_image cards
+const (
+	cardWidth   = 1200
+	cardHeight  = 630
+	cardPadding = 60
+)
+
+var (
+	colorBackground = color.RGBA{0x18, 0x1a, 0x1f, 0xff}
+	colorAccent     = color.RGBA{0xf5, 0xa6, 0x23, 0xff}
+	colorText       = color.RGBA{0xf0, 0xf0, 0xf0, 0xff}
+	colorTextMuted  = color.RGBA{0x9a, 0x9e, 0xa6, 0xff}
+)
+
+// Field is a labeled value shown on a card
+type Field struct {
+	Label string
+	Value string
+}
+
+// Card is a social media preview card, at most three fields are shown, the sparkline is optional
+type Card struct {
+	Site      string
+	Title     string
+	Subtitle  string
+	Fields    []Field
+	Sparkline []float64
+}
+
+// Render renders the card as png
+func Render(c *Card) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(colorBackground), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, cardWidth, 12), image.NewUniform(colorAccent), image.Point{}, draw.Src)
+
+	drawText(img, cardPadding, 50, c.Site, colorTextMuted, 3)
+	drawText(img, cardPadding, 110, c.Title, colorText, 6)
+	drawText(img, cardPadding, 200, c.Subtitle, colorTextMuted, 3)
+
+	for i, f := range c.Fields {
+		if i >= 3 {
+			break
+		}
+		x := cardPadding + i*(cardWidth-2*cardPadding)/3
+		drawText(img, x, 280, f.Label, colorTextMuted, 3)
+		drawText(img, x, 330, f.Value, colorText, 5)
+	}
+
+	drawSparkline(img, image.Rect(cardPadding, 450, cardWidth-cardPadding, cardHeight-cardPadding), c.Sparkline)
+
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, img)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawText draws the text with its top left corner at x, y, the 7x13 pixel glyphs of the basic font are scaled up by scale
+func drawText(dst *image.RGBA, x, y int, s string, c color.Color, scale int) {
+	// the basic font only contains printable ascii characters
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return
+	}
+
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, s).Ceil()
+	maxWidth := (dst.Bounds().Dx() - cardPadding - x) / scale
+	if maxWidth <= 0 {
+		return
+	}
+	if width > maxWidth {
+		width = maxWidth
+	}
+	src := image.NewRGBA(image.Rect(0, 0, width, face.Height))
+	d := &font.Drawer{
+		Dst:  src,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	d.DrawString(s)
+
+	xdraw.NearestNeighbor.Scale(dst, image.Rect(x, y, x+width*scale, y+face.Height*scale), src, src.Bounds(), xdraw.Over, nil)
+}
+
+// drawSparkline draws the values as line scaled to fit into the rectangle
+func drawSparkline(dst *image.RGBA, r image.Rectangle, values []float64) {
+	if len(values) < 2 {
+		return
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	point := func(i int) (int, int) {
+		x := r.Min.X + i*r.Dx()/(len(values)-1)
+		y := r.Min.Y + r.Dy()/2
+		if max > min {
+			y = r.Max.Y - int((values[i]-min)/(max-min)*float64(r.Dy()))
+		}
+		return x, y
+	}
+
+	x0, y0 := point(0)
+	for i := 1; i < len(values); i++ {
+		x1, y1 := point(i)
+		drawLine(dst, x0, y0, x1, y1, 4, colorAccent)
+		x0, y0 = x1, y1
+	}
+}
+
+// drawLine draws a line of the given thickness using Bresenham's algorithm
+func drawLine(dst *image.RGBA, x0, y0, x1, y1, thickness int, c color.Color) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		draw.Draw(dst, image.Rect(x0-thickness/2, y0-thickness/2, x0+thickness/2+1, y0+thickness/2+1), image.NewUniform(c), image.Point{}, draw.Src)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
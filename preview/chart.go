@@ -0,0 +1,227 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"eth2-exporter/types"
+)
+
+// ErrChartNotRenderable is returned for charts without any series of [x, y] points (e.g. pie charts or word clouds)
+var ErrChartNotRenderable = errors.New("chart can not be rendered server-side")
+
+const (
+	chartTitleHeight  = 70
+	chartLegendHeight = 30
+	chartAxisHeight   = 40
+)
+
+// ChartOptions are the size of the rendered chart and the range of the x-axis, a zero Start or End does not limit the range
+type ChartOptions struct {
+	Width  int
+	Height int
+	Start  time.Time
+	End    time.Time
+}
+
+type chartSeries struct {
+	Name   string
+	Color  color.RGBA
+	Points [][]float64
+}
+
+// chartPlot holds the series of a chart and the bounds of their values
+type chartPlot struct {
+	Series     []chartSeries
+	MinX, MaxX float64
+	MinY, MaxY float64
+	TimeAxis   bool
+}
+
+// newChartPlot collects the [x, y] points of all series of the chart within the range of the options, time axes are
+// given in milliseconds like the web charts expect them
+func newChartPlot(chart *types.GenericChartData, opts *ChartOptions) (*chartPlot, error) {
+	p := &chartPlot{
+		MinX:     math.Inf(1),
+		MaxX:     math.Inf(-1),
+		MinY:     math.Inf(1),
+		MaxY:     math.Inf(-1),
+		TimeAxis: !chart.IsNormalChart,
+	}
+	for _, s := range chart.Series {
+		data, ok := s.Data.([][]float64)
+		if !ok {
+			continue
+		}
+		series := chartSeries{Name: s.Name, Color: parseChartColor(s.Color)}
+		for _, point := range data {
+			if len(point) < 2 {
+				continue
+			}
+			if p.TimeAxis && !opts.Start.IsZero() && point[0] < float64(opts.Start.Unix()*1000) {
+				continue
+			}
+			if p.TimeAxis && !opts.End.IsZero() && point[0] > float64(opts.End.Unix()*1000) {
+				continue
+			}
+			series.Points = append(series.Points, point)
+			p.MinX = math.Min(p.MinX, point[0])
+			p.MaxX = math.Max(p.MaxX, point[0])
+			p.MinY = math.Min(p.MinY, point[1])
+			p.MaxY = math.Max(p.MaxY, point[1])
+		}
+		p.Series = append(p.Series, series)
+	}
+	if len(p.Series) == 0 {
+		return nil, ErrChartNotRenderable
+	}
+	if math.IsInf(p.MinX, 1) {
+		// no points within the range, an empty plot is rendered
+		p.MinX, p.MaxX, p.MinY, p.MaxY = 0, 1, 0, 1
+	}
+	return p, nil
+}
+
+// point returns the position of the value within the rectangle of the plot area
+func (p *chartPlot) point(r image.Rectangle, x, y float64) (int, int) {
+	px := r.Min.X
+	if p.MaxX > p.MinX {
+		px += int((x - p.MinX) / (p.MaxX - p.MinX) * float64(r.Dx()))
+	}
+	py := r.Min.Y + r.Dy()/2
+	if p.MaxY > p.MinY {
+		py = r.Max.Y - int((y-p.MinY)/(p.MaxY-p.MinY)*float64(r.Dy()))
+	}
+	return px, py
+}
+
+func (p *chartPlot) formatX(x float64) string {
+	if p.TimeAxis {
+		return time.Unix(int64(x/1000), 0).UTC().Format("2006-01-02")
+	}
+	return formatChartValue(x)
+}
+
+func (p *chartPlot) plotArea(opts *ChartOptions) image.Rectangle {
+	return image.Rect(cardPadding, chartTitleHeight+chartLegendHeight, opts.Width-cardPadding/2, opts.Height-chartAxisHeight)
+}
+
+// RenderChartPNG renders the series of the chart as lines into a png image
+func RenderChartPNG(chart *types.GenericChartData, opts *ChartOptions) ([]byte, error) {
+	p, err := newChartPlot(chart, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(colorBackground), image.Point{}, draw.Src)
+
+	drawText(img, cardPadding/2, 15, chart.Title, colorText, 2)
+	drawText(img, cardPadding/2, 45, chart.YAxisTitle, colorTextMuted, 1)
+
+	legendX := cardPadding
+	for _, s := range p.Series {
+		draw.Draw(img, image.Rect(legendX, chartTitleHeight+3, legendX+10, chartTitleHeight+13), image.NewUniform(s.Color), image.Point{}, draw.Src)
+		drawText(img, legendX+15, chartTitleHeight, s.Name, colorText, 1)
+		legendX += 30 + len(s.Name)*7
+	}
+
+	area := p.plotArea(opts)
+	drawLine(img, area.Min.X, area.Max.Y, area.Max.X, area.Max.Y, 1, colorTextMuted)
+	drawLine(img, area.Min.X, area.Min.Y, area.Min.X, area.Max.Y, 1, colorTextMuted)
+	drawText(img, 2, area.Min.Y, formatChartValue(p.MaxY), colorTextMuted, 1)
+	drawText(img, 2, area.Max.Y-13, formatChartValue(p.MinY), colorTextMuted, 1)
+	drawText(img, area.Min.X, area.Max.Y+10, p.formatX(p.MinX), colorTextMuted, 1)
+	maxXLabel := p.formatX(p.MaxX)
+	drawText(img, area.Max.X-len(maxXLabel)*7, area.Max.Y+10, maxXLabel, colorTextMuted, 1)
+
+	for _, s := range p.Series {
+		for i := 1; i < len(s.Points); i++ {
+			x0, y0 := p.point(area, s.Points[i-1][0], s.Points[i-1][1])
+			x1, y1 := p.point(area, s.Points[i][0], s.Points[i][1])
+			drawLine(img, x0, y0, x1, y1, 2, s.Color)
+		}
+		if len(s.Points) == 1 {
+			x, y := p.point(area, s.Points[0][0], s.Points[0][1])
+			drawLine(img, x, y, x, y, 4, s.Color)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = png.Encode(buf, img)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderChartSVG renders the series of the chart as polylines into a svg image
+func RenderChartSVG(chart *types.GenericChartData, opts *ChartOptions) ([]byte, error) {
+	p, err := newChartPlot(chart, opts)
+	if err != nil {
+		return nil, err
+	}
+	area := p.plotArea(opts)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`, opts.Width, opts.Height, opts.Width, opts.Height)
+	fmt.Fprintf(buf, `<rect width="100%%" height="100%%" fill="%s"/>`, svgColor(colorBackground))
+	fmt.Fprintf(buf, `<text x="%d" y="35" font-size="24" fill="%s">%s</text>`, cardPadding/2, svgColor(colorText), html.EscapeString(chart.Title))
+	fmt.Fprintf(buf, `<text x="%d" y="58" font-size="13" fill="%s">%s</text>`, cardPadding/2, svgColor(colorTextMuted), html.EscapeString(chart.YAxisTitle))
+
+	legendX := cardPadding
+	for _, s := range p.Series {
+		fmt.Fprintf(buf, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, legendX, chartTitleHeight+3, svgColor(s.Color))
+		fmt.Fprintf(buf, `<text x="%d" y="%d" font-size="13" fill="%s">%s</text>`, legendX+15, chartTitleHeight+12, svgColor(colorText), html.EscapeString(s.Name))
+		legendX += 30 + len(s.Name)*7
+	}
+
+	fmt.Fprintf(buf, `<path d="M%d %d V%d H%d" fill="none" stroke="%s"/>`, area.Min.X, area.Min.Y, area.Max.Y, area.Max.X, svgColor(colorTextMuted))
+	fmt.Fprintf(buf, `<text x="%d" y="%d" font-size="12" fill="%s">%s</text>`, 2, area.Min.Y+12, svgColor(colorTextMuted), formatChartValue(p.MaxY))
+	fmt.Fprintf(buf, `<text x="%d" y="%d" font-size="12" fill="%s">%s</text>`, 2, area.Max.Y, svgColor(colorTextMuted), formatChartValue(p.MinY))
+	fmt.Fprintf(buf, `<text x="%d" y="%d" font-size="12" fill="%s">%s</text>`, area.Min.X, area.Max.Y+22, svgColor(colorTextMuted), p.formatX(p.MinX))
+	fmt.Fprintf(buf, `<text x="%d" y="%d" font-size="12" text-anchor="end" fill="%s">%s</text>`, area.Max.X, area.Max.Y+22, svgColor(colorTextMuted), p.formatX(p.MaxX))
+
+	for _, s := range p.Series {
+		points := make([]string, len(s.Points))
+		for i, point := range s.Points {
+			x, y := p.point(area, point[0], point[1])
+			points[i] = fmt.Sprintf("%d,%d", x, y)
+		}
+		fmt.Fprintf(buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), svgColor(s.Color))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// parseChartColor parses a hex color (#rrggbb) of a series, series without a valid color fall back to the accent color
+func parseChartColor(s string) color.RGBA {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return colorAccent
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return colorAccent
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func formatChartValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
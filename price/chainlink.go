@@ -0,0 +1,106 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const chainlinkAggregatorABI = `[
+	{"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"latestRoundData","outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}
+]`
+
+// chainlinkMaxAge is the age above which the answer of a feed is considered stale, the ETH feeds are updated at least
+// once per hour
+const chainlinkMaxAge = time.Hour * 2
+
+// chainlinkProvider reads the ETH price from the on-chain chainlink aggregators
+type chainlinkProvider struct {
+	client *ethclient.Client
+	abi    abi.ABI
+	feeds  map[string]common.Address
+}
+
+func newChainlinkProvider(endpoint string, feeds map[string]string) (*chainlinkProvider, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("no eth1 endpoint configured")
+	}
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("no feeds configured")
+	}
+	parsed, err := abi.JSON(strings.NewReader(chainlinkAggregatorABI))
+	if err != nil {
+		return nil, err
+	}
+	p := &chainlinkProvider{abi: parsed, feeds: map[string]common.Address{}}
+	for currency, address := range feeds {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("feed %q of %v is not an address", address, currency)
+		}
+		p.feeds[strings.ToUpper(currency)] = common.HexToAddress(address)
+	}
+	p.client, err = ethclient.Dial(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to eth1 endpoint: %w", err)
+	}
+	return p, nil
+}
+
+func (p *chainlinkProvider) Name() string {
+	return "chainlink"
+}
+
+func (p *chainlinkProvider) FetchPrices(currencies []string) (map[string]float64, error) {
+	prices := map[string]float64{}
+	for _, currency := range currencies {
+		feed, exists := p.feeds[currency]
+		if !exists {
+			continue
+		}
+		price, err := p.fetchFeed(feed)
+		if err != nil {
+			return nil, fmt.Errorf("error reading feed %v of %v: %w", feed.Hex(), currency, err)
+		}
+		prices[currency] = price
+	}
+	return prices, nil
+}
+
+func (p *chainlinkProvider) fetchFeed(feed common.Address) (float64, error) {
+	decimals, err := p.call(feed, "decimals")
+	if err != nil {
+		return 0, err
+	}
+	round, err := p.call(feed, "latestRoundData")
+	if err != nil {
+		return 0, err
+	}
+	answer, updatedAt := round[1].(*big.Int), round[3].(*big.Int)
+	if age := time.Since(time.Unix(updatedAt.Int64(), 0)); age > chainlinkMaxAge {
+		return 0, fmt.Errorf("answer is stale, last update %v ago", age.Round(time.Second))
+	}
+	price, _ := new(big.Float).Quo(new(big.Float).SetInt(answer), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals[0].(uint8))), nil))).Float64()
+	return price, nil
+}
+
+func (p *chainlinkProvider) call(feed common.Address, method string) ([]interface{}, error) {
+	data, err := p.abi.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	res, err := p.client.CallContract(ctx, ethereum.CallMsg{To: &feed, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %v: %w", method, err)
+	}
+	return p.abi.Unpack(method, res)
+}
@@ -0,0 +1,45 @@
+package price
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type coingeckoProvider struct {
+	client *http.Client
+}
+
+func newCoingeckoProvider() *coingeckoProvider {
+	return &coingeckoProvider{client: &http.Client{Timeout: time.Second * 10}}
+}
+
+func (p *coingeckoProvider) Name() string {
+	return "coingecko"
+}
+
+func (p *coingeckoProvider) FetchPrices(currencies []string) (map[string]float64, error) {
+	resp, err := p.client.Get("https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=" + strings.ToLower(strings.Join(currencies, ",")))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+
+	data := struct {
+		Ethereum map[string]float64 `json:"ethereum"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	prices := map[string]float64{}
+	for currency, price := range data.Ethereum {
+		prices[strings.ToUpper(currency)] = price
+	}
+	return prices, nil
+}
@@ -0,0 +1,83 @@
+package price
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// krakenPairs are the names of the ETH pairs in the responses of the kraken ticker by currency, kraken does not list
+// RUB and CNY
+var krakenPairs = map[string]string{
+	"USD": "XETHZUSD",
+	"EUR": "XETHZEUR",
+	"CAD": "XETHZCAD",
+	"JPY": "XETHZJPY",
+	"GBP": "XETHZGBP",
+	"AUD": "ETHAUD",
+}
+
+type krakenProvider struct {
+	client *http.Client
+}
+
+func newKrakenProvider() *krakenProvider {
+	return &krakenProvider{client: &http.Client{Timeout: time.Second * 10}}
+}
+
+func (p *krakenProvider) Name() string {
+	return "kraken"
+}
+
+func (p *krakenProvider) FetchPrices(currencies []string) (map[string]float64, error) {
+	pairs := []string{}
+	for _, currency := range currencies {
+		if pair, exists := krakenPairs[currency]; exists {
+			pairs = append(pairs, pair)
+		}
+	}
+	if len(pairs) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	resp, err := p.client.Get("https://api.kraken.com/0/public/Ticker?pair=" + strings.Join(pairs, ","))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+
+	data := struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			// Close is the price and volume of the last trade
+			Close []string `json:"c"`
+		} `json:"result"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(data.Error) > 0 {
+		return nil, fmt.Errorf("error response: %v", strings.Join(data.Error, ", "))
+	}
+
+	prices := map[string]float64{}
+	for _, currency := range currencies {
+		ticker, exists := data.Result[krakenPairs[currency]]
+		if !exists || len(ticker.Close) == 0 {
+			continue
+		}
+		price, err := strconv.ParseFloat(ticker.Close[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing price of %v: %w", currency, err)
+		}
+		prices[currency] = price
+	}
+	return prices, nil
+}
@@ -1,88 +1,187 @@
 package price
 
 import (
-	"encoding/json"
-	"net/http"
+	"errors"
+	"eth2-exporter/logging"
+	"eth2-exporter/types"
+	"fmt"
+	"math"
+	"strings"
 	"sync"
 	"time"
+)
+
+var logger = logging.NewLogger("price")
+
+// Currencies are the fiat currencies the ETH price is quoted in
+var Currencies = []string{"USD", "EUR", "RUB", "CNY", "CAD", "JPY", "GBP", "AUD"}
 
-	"github.com/sirupsen/logrus"
+// Provider is a source of the ETH price, FetchPrices returns the price per currency code and may omit currencies it
+// does not support
+type Provider interface {
+	Name() string
+	FetchPrices(currencies []string) (map[string]float64, error)
+}
+
+const (
+	defaultUpdateInterval   = time.Minute
+	defaultMaxChangePercent = 20
+	defaultStaleAfter       = time.Minute * 30
 )
 
-var logger = logrus.New().WithField("module", "price")
-
-type EthPrice struct {
-	Ethereum struct {
-		Cad float64 `json:"cad"`
-		Cny float64 `json:"cny"`
-		Eur float64 `json:"eur"`
-		Jpy float64 `json:"jpy"`
-		Rub float64 `json:"rub"`
-		Usd float64 `json:"usd"`
-		Gbp float64 `json:"gbp"`
-		Aud float64 `json:"aud"`
-	} `json:"ethereum"`
+type quote struct {
+	price    float64
+	provider string
+	updated  time.Time
 }
 
-var ethPrice = new(EthPrice)
+// errUnconfirmedPrice is returned for a price without a recent accepted price to compare it to until another provider
+// returned a price that agrees with it
+var errUnconfirmedPrice = errors.New("price is not confirmed by another provider yet")
+
+var providers []Provider
+var cfg types.PriceConfig
+var ethPrice = map[string]*quote{}
 var ethPriceMux = &sync.RWMutex{}
 
-func Init() {
+// Init starts updating the ETH price from the configured providers, coingecko is used if none are configured
+func Init(config types.PriceConfig) {
+	cfg = config
+	names := cfg.Providers
+	if len(names) == 0 {
+		names = []string{"coingecko"}
+	}
+	for _, name := range names {
+		p, err := newProvider(name)
+		if err != nil {
+			logger.Errorf("error initializing price provider %v: %v", name, err)
+			continue
+		}
+		providers = append(providers, p)
+	}
+	if len(providers) == 0 {
+		logger.Errorf("no price provider available, prices will not be updated")
+		return
+	}
 	go updateEthPrice()
 }
 
+func newProvider(name string) (Provider, error) {
+	switch strings.ToLower(name) {
+	case "coingecko":
+		return newCoingeckoProvider(), nil
+	case "kraken":
+		return newKrakenProvider(), nil
+	case "chainlink":
+		return newChainlinkProvider(cfg.Chainlink.Eth1Endpoint, cfg.Chainlink.Feeds)
+	}
+	return nil, fmt.Errorf("unknown price provider")
+}
+
 func updateEthPrice() {
-	for true {
+	interval := defaultUpdateInterval
+	if cfg.UpdateIntervalSeconds > 0 {
+		interval = time.Second * time.Duration(cfg.UpdateIntervalSeconds)
+	}
+	for {
 		fetchPrice()
-		time.Sleep(time.Minute)
+		time.Sleep(interval)
 	}
 }
 
+// fetchPrice updates the price of every currency from the first provider that returns a price within the sanity bounds,
+// currencies without an accepted price keep their last price. Without a recent accepted price (e.g. after the start) a
+// price is only accepted once two providers agree on it within the max change.
 func fetchPrice() {
-	client := &http.Client{Timeout: time.Second * 10}
-	resp, err := client.Get("https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd%2Ceur%2Crub%2Ccny%2Ccad%2Cjpy%2Cgbp%2Caud")
+	missing := Currencies
+	unconfirmed := map[string][]*quote{}
+	for _, p := range providers {
+		if len(missing) == 0 {
+			break
+		}
+		prices, err := p.FetchPrices(missing)
+		if err != nil {
+			logger.Errorf("error retrieving ETH price from %v: %v", p.Name(), err)
+			continue
+		}
+		stillMissing := []string{}
+		for _, currency := range missing {
+			price, exists := prices[currency]
+			if !exists {
+				stillMissing = append(stillMissing, currency)
+				continue
+			}
+			err := acceptPrice(p.Name(), currency, price, unconfirmed[currency])
+			if err == errUnconfirmedPrice {
+				unconfirmed[currency] = append(unconfirmed[currency], &quote{price: price, provider: p.Name()})
+				stillMissing = append(stillMissing, currency)
+			} else if err != nil {
+				logger.Warnf("rejecting ETH price of %v from %v: %v", currency, p.Name(), err)
+				stillMissing = append(stillMissing, currency)
+			}
+		}
+		missing = stillMissing
+	}
+	if len(missing) > 0 {
+		logger.Errorf("no provider returned a valid ETH price for %v, keeping the last prices", strings.Join(missing, ", "))
+	}
+}
 
-	if err != nil {
-		logger.Errorf("error retrieving ETH price: %v", err)
-		return
+// acceptPrice stores the price if it is positive and did not move by more than the max change since the last accepted
+// price. An outdated last price is not used as bound as the market may have moved in the meantime, without a recent last
+// price the price has to be within the max change of an unconfirmed price of another provider instead. With a single
+// provider there is nothing to confirm the price with, so it is accepted as is.
+func acceptPrice(provider, currency string, price float64, unconfirmed []*quote) error {
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return fmt.Errorf("invalid price %v", price)
+	}
+	maxChange := cfg.MaxChangePercent
+	if maxChange <= 0 {
+		maxChange = defaultMaxChangePercent
+	}
+	staleAfter := defaultStaleAfter
+	if cfg.StaleAfterMinutes > 0 {
+		staleAfter = time.Minute * time.Duration(cfg.StaleAfterMinutes)
 	}
 
 	ethPriceMux.Lock()
 	defer ethPriceMux.Unlock()
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&ethPrice)
-
-	if err != nil {
-		logger.Errorf("error decoding ETH price json response to struct: %v", err)
-		return
+	last := ethPrice[currency]
+	if last != nil && time.Since(last.updated) < staleAfter {
+		change := math.Abs(price-last.price) / last.price * 100
+		if change > maxChange {
+			return fmt.Errorf("price %v differs by %.1f%% from the last price %v of %v", price, change, last.price, last.provider)
+		}
+	} else if len(providers) > 1 {
+		confirmed := false
+		for _, q := range unconfirmed {
+			if math.Abs(price-q.price)/q.price*100 <= maxChange {
+				confirmed = true
+				break
+			}
+		}
+		if !confirmed {
+			return errUnconfirmedPrice
+		}
 	}
+	ethPrice[currency] = &quote{price: price, provider: provider, updated: time.Now()}
+	return nil
 }
 
+// GetEthPrice returns the last accepted ETH price in the currency, 0 if there is none yet and 1 for unknown currencies
 func GetEthPrice(currency string) float64 {
 	ethPriceMux.RLock()
 	defer ethPriceMux.RUnlock()
 
-	switch currency {
-	case "EUR":
-		return ethPrice.Ethereum.Eur
-	case "USD":
-		return ethPrice.Ethereum.Usd
-	case "RUB":
-		return ethPrice.Ethereum.Rub
-	case "CNY":
-		return ethPrice.Ethereum.Cny
-	case "CAD":
-		return ethPrice.Ethereum.Cad
-	case "AUD":
-		return ethPrice.Ethereum.Aud
-	case "JPY":
-		return ethPrice.Ethereum.Jpy
-	case "GBP":
-		return ethPrice.Ethereum.Gbp
-	default:
-		return 1
+	if q, exists := ethPrice[currency]; exists {
+		return q.price
+	}
+	for _, c := range Currencies {
+		if c == currency {
+			return 0
+		}
 	}
+	return 1
 }
 
 func GetEthRoundPrice(currency float64) uint64 {
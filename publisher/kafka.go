@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSink writes messages to a kafka topic, messages with the same key are written to the same partition
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaSink(brokers []string, topic string) (*KafkaSink, error) {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			BatchSize:    maxBatchSize,
+			BatchTimeout: time.Millisecond * 100,
+			RequiredAcks: kafka.RequireAll,
+		},
+	}, nil
+}
+
+func (s *KafkaSink) Publish(messages []*Message) error {
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, m := range messages {
+		kafkaMessages[i] = kafka.Message{Key: []byte(m.Key), Value: m.Value}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	return s.writer.WriteMessages(ctx, kafkaMessages...)
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,200 @@
+package publisher
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// MessageType is the type of the data of a message
+type MessageType string
+
+const (
+	MessageTypeBlock           MessageType = "block"
+	MessageTypeEpoch           MessageType = "epoch"
+	MessageTypeDeposit         MessageType = "deposit"
+	MessageTypeValidatorStatus MessageType = "validator_status"
+)
+
+// BlockMessage is published for every block (proposed, missed or orphaned) of an exported epoch
+type BlockMessage struct {
+	Slot                  uint64        `json:"slot"`
+	Epoch                 uint64        `json:"epoch"`
+	BlockRoot             hexutil.Bytes `json:"block_root"`
+	ParentRoot            hexutil.Bytes `json:"parent_root"`
+	StateRoot             hexutil.Bytes `json:"state_root"`
+	Proposer              uint64        `json:"proposer"`
+	Status                uint64        `json:"status"` // 0 = scheduled, 1 = proposed, 2 = missed, 3 = orphaned
+	Graffiti              hexutil.Bytes `json:"graffiti"`
+	AttestationsCount     int           `json:"attestations_count"`
+	DepositsCount         int           `json:"deposits_count"`
+	VoluntaryExitsCount   int           `json:"voluntary_exits_count"`
+	ProposerSlashingCount int           `json:"proposer_slashings_count"`
+	AttesterSlashingCount int           `json:"attester_slashings_count"`
+	ExecBlockNumber       *uint64       `json:"exec_block_number,omitempty"`
+	ExecBlockHash         hexutil.Bytes `json:"exec_block_hash,omitempty"`
+	ExecFeeRecipient      hexutil.Bytes `json:"exec_fee_recipient,omitempty"`
+	ExecTransactionsCount *uint64       `json:"exec_transactions_count,omitempty"`
+	Ts                    int64         `json:"ts"`
+}
+
+// EpochMessage is published for every exported epoch
+type EpochMessage struct {
+	Epoch                   uint64  `json:"epoch"`
+	BlocksCount             int     `json:"blocks_count"`
+	ValidatorsCount         int     `json:"validators_count"`
+	Finalized               bool    `json:"finalized"`
+	GlobalParticipationRate float32 `json:"global_participation_rate"`
+	VotedEther              uint64  `json:"voted_ether"`
+	EligibleEther           uint64  `json:"eligible_ether"`
+	Ts                      int64   `json:"ts"`
+}
+
+// DepositMessage is published for every deposit to the eth1 deposit contract
+type DepositMessage struct {
+	TxHash                hexutil.Bytes `json:"tx_hash"`
+	BlockNumber           uint64        `json:"block_number"`
+	FromAddress           hexutil.Bytes `json:"from_address"`
+	Pubkey                hexutil.Bytes `json:"pubkey"`
+	WithdrawalCredentials hexutil.Bytes `json:"withdrawal_credentials"`
+	Amount                uint64        `json:"amount"`
+	MerkletreeIndex       hexutil.Bytes `json:"merkletree_index"`
+	ValidSignature        bool          `json:"valid_signature"`
+	Removed               bool          `json:"removed"`
+	Ts                    int64         `json:"ts"`
+}
+
+// ValidatorStatusMessage is published when a validator becomes eligible for activation (pending), is activated (active),
+// is slashed (slashed), exits (exited) or can withdraw its balance (withdrawable) in the exported epoch
+type ValidatorStatusMessage struct {
+	Validatorindex uint64        `json:"validatorindex"`
+	Pubkey         hexutil.Bytes `json:"pubkey"`
+	Epoch          uint64        `json:"epoch"`
+	Status         string        `json:"status"`
+	Ts             int64         `json:"ts"`
+}
+
+// PublishEpoch publishes the blocks, the summary and the validator status changes of an exported epoch
+func PublishEpoch(data *types.EpochData) {
+	if !enabled() {
+		return
+	}
+	ts := utils.EpochToTime(data.Epoch).Unix()
+
+	blocksCount := 0
+	slashed := map[uint64]bool{}
+	for _, slot := range data.Blocks {
+		for _, b := range slot {
+			if b.Status == 1 {
+				blocksCount++
+			}
+			publishBlock(data.Epoch, b)
+			for _, s := range b.ProposerSlashings {
+				slashed[s.ProposerIndex] = true
+			}
+			for _, s := range b.AttesterSlashings {
+				attesters := map[uint64]bool{}
+				for _, i := range s.Attestation1.AttestingIndices {
+					attesters[i] = true
+				}
+				for _, i := range s.Attestation2.AttestingIndices {
+					if attesters[i] {
+						slashed[i] = true
+					}
+				}
+			}
+		}
+	}
+
+	epoch := &EpochMessage{
+		Epoch:           data.Epoch,
+		BlocksCount:     blocksCount,
+		ValidatorsCount: len(data.Validators),
+		Ts:              ts,
+	}
+	if data.EpochParticipationStats != nil {
+		epoch.Finalized = data.EpochParticipationStats.Finalized
+		epoch.GlobalParticipationRate = data.EpochParticipationStats.GlobalParticipationRate
+		epoch.VotedEther = data.EpochParticipationStats.VotedEther
+		epoch.EligibleEther = data.EpochParticipationStats.EligibleEther
+	}
+	publish(MessageTypeEpoch, fmt.Sprintf("%d", data.Epoch), epoch)
+
+	for _, v := range data.Validators {
+		statuses := []string{}
+		if v.ActivationEligibilityEpoch == data.Epoch {
+			statuses = append(statuses, "pending")
+		}
+		if v.ActivationEpoch == data.Epoch {
+			statuses = append(statuses, "active")
+		}
+		if slashed[v.Index] {
+			statuses = append(statuses, "slashed")
+		}
+		if v.ExitEpoch == data.Epoch {
+			statuses = append(statuses, "exited")
+		}
+		if v.WithdrawableEpoch == data.Epoch {
+			statuses = append(statuses, "withdrawable")
+		}
+		for _, status := range statuses {
+			publish(MessageTypeValidatorStatus, fmt.Sprintf("%d-%d-%s", v.Index, data.Epoch, status), &ValidatorStatusMessage{
+				Validatorindex: v.Index,
+				Pubkey:         v.PublicKey,
+				Epoch:          data.Epoch,
+				Status:         status,
+				Ts:             ts,
+			})
+		}
+	}
+}
+
+func publishBlock(epoch uint64, b *types.Block) {
+	m := &BlockMessage{
+		Slot:                  b.Slot,
+		Epoch:                 epoch,
+		BlockRoot:             b.BlockRoot,
+		ParentRoot:            b.ParentRoot,
+		StateRoot:             b.StateRoot,
+		Proposer:              b.Proposer,
+		Status:                b.Status,
+		Graffiti:              b.Graffiti,
+		AttestationsCount:     len(b.Attestations),
+		DepositsCount:         len(b.Deposits),
+		VoluntaryExitsCount:   len(b.VoluntaryExits),
+		ProposerSlashingCount: len(b.ProposerSlashings),
+		AttesterSlashingCount: len(b.AttesterSlashings),
+		Ts:                    utils.SlotToTime(b.Slot).Unix(),
+	}
+	if b.ExecutionPayload != nil {
+		m.ExecBlockNumber = &b.ExecutionPayload.BlockNumber
+		m.ExecBlockHash = b.ExecutionPayload.BlockHash
+		m.ExecFeeRecipient = b.ExecutionPayload.FeeRecipient
+		m.ExecTransactionsCount = &b.ExecutionPayload.TransactionsCount
+	}
+	publish(MessageTypeBlock, fmt.Sprintf("%d-%x", b.Slot, b.BlockRoot), m)
+}
+
+// PublishDeposits publishes the eth1-deposits that were just stored, removed deposits (e.g. of a reorged eth1-block) are
+// published with removed set to true
+func PublishDeposits(deposits []*types.Eth1Deposit) {
+	if !enabled() {
+		return
+	}
+	for _, d := range deposits {
+		publish(MessageTypeDeposit, fmt.Sprintf("%x-%x", d.TxHash, d.MerkletreeIndex), &DepositMessage{
+			TxHash:                d.TxHash,
+			BlockNumber:           d.BlockNumber,
+			FromAddress:           d.FromAddress,
+			Pubkey:                d.PublicKey,
+			WithdrawalCredentials: d.WithdrawalCredentials,
+			Amount:                d.Amount,
+			MerkletreeIndex:       d.MerkletreeIndex,
+			ValidSignature:        d.ValidSignature,
+			Removed:               d.Removed,
+			Ts:                    d.BlockTs,
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NatsSink publishes messages to a nats subject, the key is sent in the Key header
+type NatsSink struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func NewNatsSink(servers []string, subject string) (*NatsSink, error) {
+	conn, err := nats.Connect(strings.Join(servers, ","), nats.Name("beaconchain-explorer"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NatsSink{conn: conn, subject: subject}, nil
+}
+
+func (s *NatsSink) Publish(messages []*Message) error {
+	for _, m := range messages {
+		msg := nats.NewMsg(s.subject)
+		msg.Header.Set("Key", m.Key)
+		msg.Data = m.Value
+		err := s.conn.PublishMsg(msg)
+		if err != nil {
+			return err
+		}
+	}
+	// nats publishes asynchronously, flushing makes sure the batch reached the server before it is considered sent
+	return s.conn.FlushTimeout(time.Second * 30)
+}
+
+func (s *NatsSink) Close() error {
+	return s.conn.Drain()
+}
@@ -0,0 +1,206 @@
+// Package publisher streams the data the indexer stored (blocks, epochs, deposits and validator status changes) as json
+// messages to an external Kafka topic or NATS subject. Messages are delivered at least once, epochs that are exported
+// again (e.g. after a reorg) are published again, consumers can deduplicate them by their key.
+package publisher
+
+import (
+	"encoding/json"
+	"eth2-exporter/logging"
+	"eth2-exporter/types"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var logger = logging.NewLogger("publisher")
+
+// defaultBufferSize is the number of messages that can wait for the sink before publishing blocks the indexer
+const defaultBufferSize = 100000
+
+// maxRetryDelay caps the delay between the attempts of a failed batch, batches are retried until they are delivered
+const maxRetryDelay = time.Minute
+
+// closeTimeout is the time Close waits for the queued messages to be delivered
+const closeTimeout = time.Second * 30
+
+// maxBatchSize is the number of messages handed to the sink at once
+const maxBatchSize = 1000
+
+// Sink delivers messages to an external system
+type Sink interface {
+	Publish(messages []*Message) error
+	Close() error
+}
+
+// Message is a single message of the stream, the key identifies the underlying data (e.g. the slot and block root) and
+// is used to partition the kafka topic
+type Message struct {
+	Key   string
+	Value []byte
+}
+
+// envelope is the json encoding of every message
+type envelope struct {
+	Type    MessageType `json:"type"`
+	Network string      `json:"network"`
+	Data    interface{} `json:"data"`
+}
+
+var sink Sink
+var network string
+var queue chan *Message
+var done chan struct{}
+var closing chan struct{}
+var initMux = &sync.Mutex{}
+
+// stopping is closed when Close is called, it releases publish calls waiting for room in a full queue
+var stopping = make(chan struct{})
+var stoppingOnce = &sync.Once{}
+
+// lastFullLog is the last time a full queue was logged, it is logged at most once per minute
+var lastFullLog time.Time
+
+// Init connects to the sink configured in cfg.Publisher, messages published before Init or while the publisher is
+// disabled are discarded
+func Init(cfg *types.Config) error {
+	initMux.Lock()
+	defer initMux.Unlock()
+
+	if !cfg.Publisher.Enabled || sink != nil {
+		return nil
+	}
+	if cfg.Publisher.Topic == "" {
+		return fmt.Errorf("no publisher topic configured")
+	}
+	if len(cfg.Publisher.Brokers) == 0 {
+		return fmt.Errorf("no publisher brokers configured")
+	}
+
+	var err error
+	switch cfg.Publisher.Type {
+	case "kafka":
+		sink, err = NewKafkaSink(cfg.Publisher.Brokers, cfg.Publisher.Topic)
+	case "nats":
+		sink, err = NewNatsSink(cfg.Publisher.Brokers, cfg.Publisher.Topic)
+	default:
+		return fmt.Errorf("unknown publisher type %v", cfg.Publisher.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	bufferSize := cfg.Publisher.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	network = cfg.Chain.Network
+	queue = make(chan *Message, bufferSize)
+	done = make(chan struct{})
+	closing = make(chan struct{})
+	go send(sink, queue, done, closing)
+
+	logger.Infof("publishing to %v topic %v", cfg.Publisher.Type, cfg.Publisher.Topic)
+	return nil
+}
+
+// Close sends the queued messages and closes the connection to the sink, messages that could not be delivered within
+// closeTimeout are lost
+func Close() {
+	stoppingOnce.Do(func() { close(stopping) })
+	initMux.Lock()
+	defer initMux.Unlock()
+
+	if sink == nil {
+		return
+	}
+	close(queue)
+	select {
+	case <-done:
+	case <-time.After(closeTimeout):
+		close(closing)
+		<-done
+	}
+	err := sink.Close()
+	if err != nil {
+		logger.WithError(err).Errorf("error closing publisher sink")
+	}
+	sink = nil
+}
+
+// enabled returns true if Init connected to a sink, it is used to skip building messages nobody receives
+func enabled() bool {
+	initMux.Lock()
+	defer initMux.Unlock()
+	return sink != nil
+}
+
+// publish queues the message, if the sink can not keep up (e.g. while it is unreachable) it blocks the indexer until
+// there is room in the queue so no message is lost
+func publish(t MessageType, key string, data interface{}) {
+	initMux.Lock()
+	defer initMux.Unlock()
+
+	if sink == nil {
+		return
+	}
+	value, err := json.Marshal(&envelope{Type: t, Network: network, Data: data})
+	if err != nil {
+		logger.WithError(err).Errorf("error encoding %v message %v", t, key)
+		return
+	}
+	m := &Message{Key: key, Value: value}
+	select {
+	case queue <- m:
+	default:
+		if time.Since(lastFullLog) > time.Minute {
+			logger.Warnf("the publisher queue is full, waiting for the sink")
+			lastFullLog = time.Now()
+		}
+		select {
+		case queue <- m:
+		case <-stopping:
+			logger.Errorf("dropping %v message %v, the publisher is closing", t, key)
+		}
+	}
+}
+
+// send hands the queued messages to the sink in batches, failed batches are retried until they are delivered or the
+// publisher is closed
+func send(s Sink, queue <-chan *Message, done chan<- struct{}, closing <-chan struct{}) {
+	defer close(done)
+
+	batch := make([]*Message, 0, maxBatchSize)
+	for m := range queue {
+		batch = append(batch[:0], m)
+	collect:
+		for len(batch) < maxBatchSize {
+			select {
+			case m, ok := <-queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, m)
+			default:
+				break collect
+			}
+		}
+
+		for retry := 0; ; retry++ {
+			err := s.Publish(batch)
+			if err == nil {
+				break
+			}
+			logger.WithError(err).WithField("retry", retry).Errorf("error publishing %v messages", len(batch))
+			delay := time.Second * time.Duration(retry+1)
+			if delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			select {
+			case <-time.After(delay):
+			case <-closing:
+				logger.Errorf("dropping %v messages, the publisher was closed before they could be delivered", len(batch)+len(queue))
+				return
+			}
+		}
+	}
+}
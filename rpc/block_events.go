@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BlockEvent is a block event of the standard beacon node api, it is emitted when the node imports a block
+type BlockEvent struct {
+	Slot  uint64Str `json:"slot"`
+	Block string    `json:"block"`
+	// SeenAt is the local time the event was received, it is set by SubscribeBlockEvents
+	SeenAt time.Time `json:"-"`
+}
+
+// SubscribeBlockEvents subscribes to the block events of the standard beacon node api of the endpoint and calls f for
+// every event as soon as it is received. It blocks until the stream ends or fails and always returns an error.
+func SubscribeBlockEvents(endpoint string, f func(*BlockEvent)) error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/eth/v1/events?topics=block", endpoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// the stream is kept open, so no timeout is set on the client
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("error-response: %s", data)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	eventType := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if eventType != "block" {
+				continue
+			}
+			seenAt := time.Now()
+			event := &BlockEvent{}
+			err = json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), event)
+			if err != nil {
+				return fmt.Errorf("error decoding block event: %w", err)
+			}
+			event.SeenAt = seenAt
+			f(event)
+		case line == "":
+			eventType = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("event stream closed by the node")
+}
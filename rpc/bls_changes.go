@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// SignedBLSToExecutionChange is a signed change of the withdrawal credentials of a validator in the format of the
+// standard beacon node api
+type SignedBLSToExecutionChange struct {
+	Message struct {
+		ValidatorIndex     uint64Str `json:"validator_index"`
+		FromBLSPubkey      string    `json:"from_bls_pubkey"`
+		ToExecutionAddress string    `json:"to_execution_address"`
+	} `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type StandardBLSToExecutionChangesResponse struct {
+	Data []*SignedBLSToExecutionChange `json:"data"`
+}
+
+// SubmitBLSToExecutionChanges submits the signed changes to the operation pool of the beacon node, the node verifies the
+// signatures and returns an error describing the rejected changes
+func SubmitBLSToExecutionChanges(endpoint string, changes []*SignedBLSToExecutionChange) error {
+	body, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: time.Second * 30}
+	resp, err := client.Post(fmt.Sprintf("%s/eth/v1/beacon/pool/bls_to_execution_changes", endpoint), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(resp.Body)
+		parsed := struct {
+			Message  string `json:"message"`
+			Failures []struct {
+				Index   int    `json:"index"`
+				Message string `json:"message"`
+			} `json:"failures"`
+		}{}
+		if json.Unmarshal(data, &parsed) != nil || parsed.Message == "" {
+			return fmt.Errorf("error-response: %s", data)
+		}
+		if len(parsed.Failures) > 0 {
+			return fmt.Errorf("%v (change %v: %v)", parsed.Message, parsed.Failures[0].Index+1, parsed.Failures[0].Message)
+		}
+		return fmt.Errorf("%v", parsed.Message)
+	}
+	return nil
+}
+
+// GetBLSToExecutionChangesPool returns the changes in the operation pool of the beacon node that are not yet included
+// in a block
+func GetBLSToExecutionChangesPool(endpoint string) ([]*SignedBLSToExecutionChange, error) {
+	client := &http.Client{Timeout: time.Second * 30}
+	resp, err := client.Get(fmt.Sprintf("%s/eth/v1/beacon/pool/bls_to_execution_changes", endpoint))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error-response: %s", data)
+	}
+
+	parsed := &StandardBLSToExecutionChangesResponse{}
+	err = json.Unmarshal(data, parsed)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Data, nil
+}
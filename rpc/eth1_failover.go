@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"bytes"
+	"eth2-exporter/metrics"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+// DialEth1 connects to the first endpoint and fails over to the next endpoints if a request fails.
+// Failover is only supported for http endpoints, a single endpoint may use any transport supported by geth.
+func DialEth1(endpoints []string) (*gethRPC.Client, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no eth1 endpoint configured")
+	}
+	if len(endpoints) == 1 {
+		return gethRPC.Dial(endpoints[0])
+	}
+
+	transport, err := newEth1FailoverTransport(endpoints)
+	if err != nil {
+		return nil, err
+	}
+	return gethRPC.DialHTTPWithClient(endpoints[0], &http.Client{Transport: transport, Timeout: time.Minute})
+}
+
+// eth1FailoverTransport is a http.RoundTripper that sends each json-rpc request to the first healthy endpoint.
+// Endpoints that return a network error or a 429/5xx response are marked unhealthy until a health check succeeds.
+type eth1FailoverTransport struct {
+	endpoints []*url.URL
+	labels    []string
+	healthy   []bool
+	mux       *sync.RWMutex
+}
+
+func newEth1FailoverTransport(endpoints []string) (*eth1FailoverTransport, error) {
+	t := &eth1FailoverTransport{
+		endpoints: make([]*url.URL, len(endpoints)),
+		labels:    make([]string, len(endpoints)),
+		healthy:   make([]bool, len(endpoints)),
+		mux:       &sync.RWMutex{},
+	}
+	for i, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing eth1 endpoint %v: %w", i, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("eth1 endpoint %v uses scheme %v, only http and https endpoints support failover", i, u.Scheme)
+		}
+		t.endpoints[i] = u
+		// only the host is used as label as the path of hosted providers usually contains the api-key
+		t.labels[i] = u.Host
+		t.healthy[i] = true
+		metrics.RPCEndpointHealthy.WithLabelValues(u.Host).Set(1)
+	}
+	go t.healthCheck()
+	return t, nil
+}
+
+func (t *eth1FailoverTransport) setHealthy(i int, healthy bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.healthy[i] != healthy {
+		logger.Infof("marking eth1 endpoint %v as healthy: %v", t.labels[i], healthy)
+	}
+	t.healthy[i] = healthy
+	if healthy {
+		metrics.RPCEndpointHealthy.WithLabelValues(t.labels[i]).Set(1)
+	} else {
+		metrics.RPCEndpointHealthy.WithLabelValues(t.labels[i]).Set(0)
+	}
+}
+
+func (t *eth1FailoverTransport) healthCheck() {
+	body := []byte(`{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`)
+	client := &http.Client{Timeout: time.Second * 10}
+	ticker := time.NewTicker(time.Second * 15)
+	defer ticker.Stop()
+	for range ticker.C {
+		for i, endpoint := range t.endpoints {
+			resp, err := client.Post(endpoint.String(), "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.setHealthy(i, false)
+				continue
+			}
+			resp.Body.Close()
+			t.setHealthy(i, !isEth1FailoverStatus(resp.StatusCode))
+		}
+	}
+}
+
+func isEth1FailoverStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *eth1FailoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	method := "batch"
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		method = eth1RequestMethod(body)
+	}
+
+	t.mux.RLock()
+	order := make([]int, 0, len(t.endpoints))
+	for i := range t.endpoints {
+		if t.healthy[i] {
+			order = append(order, i)
+		}
+	}
+	t.mux.RUnlock()
+	if len(order) == 0 {
+		for i := range t.endpoints {
+			order = append(order, i)
+		}
+	}
+
+	var lastErr error
+	for _, i := range order {
+		r := req.Clone(req.Context())
+		r.URL = t.endpoints[i]
+		r.Host = t.endpoints[i].Host
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err == nil && !isEth1FailoverStatus(resp.StatusCode) {
+			metrics.RPCRequests.WithLabelValues(t.labels[i], method, "success").Inc()
+			return resp, nil
+		}
+		metrics.RPCRequests.WithLabelValues(t.labels[i], method, "error").Inc()
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("eth1 endpoint %v responded with status %v", t.labels[i], resp.StatusCode)
+			resp.Body.Close()
+		}
+		logger.Warnf("error sending request to eth1 endpoint %v, trying next endpoint: %v", t.labels[i], lastErr)
+		t.setHealthy(i, false)
+	}
+	return nil, lastErr
+}
+
+// eth1RequestMethod extracts the json-rpc method of a single request without decoding the params
+func eth1RequestMethod(body []byte) string {
+	idx := bytes.Index(body, []byte(`"method":"`))
+	if idx == -1 {
+		return "unknown"
+	}
+	method := string(body[idx+len(`"method":"`):])
+	if end := strings.IndexByte(method, '"'); end != -1 {
+		return method[:end]
+	}
+	return "unknown"
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// GetEthSupply returns the circulating supply in gwei from an etherscan compatible ethsupply endpoint (e.g.
+// https://api.etherscan.io/api?module=stats&action=ethsupply&apikey=...), which returns the supply in wei
+func GetEthSupply(endpoint string) (uint64, error) {
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error-response: %s", data)
+	}
+
+	parsed := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  string `json:"result"`
+	}{}
+	err = json.Unmarshal(data, &parsed)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing eth supply: %w", err)
+	}
+	if parsed.Status != "1" {
+		return 0, fmt.Errorf("error-response: %v: %v", parsed.Message, parsed.Result)
+	}
+
+	supply, ok := new(big.Int).SetString(parsed.Result, 10)
+	if !ok {
+		return 0, fmt.Errorf("invalid eth supply %v", parsed.Result)
+	}
+	return supply.Div(supply, big.NewInt(1e9)).Uint64(), nil
+}
@@ -0,0 +1,277 @@
+package rpc
+
+import (
+	"errors"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FailoverClient is a Client that distributes calls over multiple beacon-nodes.
+// Calls are sent to the first healthy node in order of priority, if a call fails because
+// of the node the node is marked unhealthy and the call is retried on the next healthy node.
+// Nodes are health-checked once per slot and are used again as soon as they recover.
+type FailoverClient struct {
+	clients   []Client
+	endpoints []string
+	healthy   []bool
+	mux       *sync.RWMutex
+}
+
+// NewFailoverClient is used to create a new FailoverClient, clients and endpoints are in order of priority and endpoints are only used as metric labels
+func NewFailoverClient(clients []Client, endpoints []string) (*FailoverClient, error) {
+	if len(clients) == 0 || len(clients) != len(endpoints) {
+		return nil, fmt.Errorf("invalid failover configuration with %v clients and %v endpoints", len(clients), len(endpoints))
+	}
+
+	fc := &FailoverClient{
+		clients:   clients,
+		endpoints: endpoints,
+		healthy:   make([]bool, len(clients)),
+		mux:       &sync.RWMutex{},
+	}
+	for i := range fc.healthy {
+		fc.healthy[i] = true
+		metrics.RPCEndpointHealthy.WithLabelValues(endpoints[i]).Set(1)
+	}
+	go fc.healthCheck()
+
+	return fc, nil
+}
+
+func (fc *FailoverClient) setHealthy(i int, healthy bool) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+	if fc.healthy[i] != healthy {
+		logger.Infof("marking beacon-node %v as healthy: %v", fc.endpoints[i], healthy)
+	}
+	fc.healthy[i] = healthy
+	if healthy {
+		metrics.RPCEndpointHealthy.WithLabelValues(fc.endpoints[i]).Set(1)
+	} else {
+		metrics.RPCEndpointHealthy.WithLabelValues(fc.endpoints[i]).Set(0)
+	}
+}
+
+// healthCheck marks nodes that respond and are at most one epoch behind the best node as healthy
+func (fc *FailoverClient) healthCheck() {
+	t := time.NewTicker(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
+	defer t.Stop()
+	for ; true; <-t.C {
+		headSlots := make([]uint64, len(fc.clients))
+		reachable := make([]bool, len(fc.clients))
+		wg := &sync.WaitGroup{}
+		for i, client := range fc.clients {
+			wg.Add(1)
+			go func(i int, client Client) {
+				defer wg.Done()
+				head, err := client.GetChainHead()
+				if err != nil {
+					logger.Warnf("health check of beacon-node %v failed: %v", fc.endpoints[i], err)
+					return
+				}
+				headSlots[i] = head.HeadSlot
+				reachable[i] = true
+			}(i, client)
+		}
+		wg.Wait()
+
+		bestHeadSlot := uint64(0)
+		for i := range fc.clients {
+			if reachable[i] && headSlots[i] > bestHeadSlot {
+				bestHeadSlot = headSlots[i]
+			}
+		}
+		for i := range fc.clients {
+			fc.setHealthy(i, reachable[i] && headSlots[i]+utils.Config.Chain.SlotsPerEpoch >= bestHeadSlot)
+		}
+	}
+}
+
+// do executes f on the healthy nodes in order of priority until it succeeds, if no node is healthy all nodes are tried
+func (fc *FailoverClient) do(method string, f func(client Client) error) error {
+	fc.mux.RLock()
+	order := make([]int, 0, len(fc.clients))
+	for i := range fc.clients {
+		if fc.healthy[i] {
+			order = append(order, i)
+		}
+	}
+	fc.mux.RUnlock()
+	if len(order) == 0 {
+		for i := range fc.clients {
+			order = append(order, i)
+		}
+	}
+
+	var err error
+	for _, i := range order {
+		err = f(fc.clients[i])
+		if err == nil {
+			metrics.RPCRequests.WithLabelValues(fc.endpoints[i], method, "success").Inc()
+			return nil
+		}
+		metrics.RPCRequests.WithLabelValues(fc.endpoints[i], method, "error").Inc()
+		if !isNodeFailure(err) {
+			// the other nodes would answer the same, e.g. a 404 for a slot without block
+			return err
+		}
+		logger.Warnf("error calling %v on beacon-node %v, trying next node: %v", method, fc.endpoints[i], err)
+		fc.setHealthy(i, false)
+	}
+	return err
+}
+
+// isNodeFailure returns whether the error is caused by the node rather than by the request. Transport errors, server
+// errors and rate limits are failures of the node, responses for data the node does not have (not found) or for
+// invalid requests are not.
+func isNodeFailure(err error) bool {
+	if errors.Is(err, notFoundErr) {
+		return false
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.ResourceExhausted, codes.Aborted:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// GetNewBlockChan merges the new blocks of all nodes into a single channel, blocks are only forwarded once
+func (fc *FailoverClient) GetNewBlockChan() chan *types.Block {
+	blkCh := make(chan *types.Block, 10)
+	seenMux := &sync.Mutex{}
+	seen := map[string]uint64{}
+	lastPrunedSlot := uint64(0)
+
+	for _, client := range fc.clients {
+		go func(ch chan *types.Block) {
+			for blk := range ch {
+				key := fmt.Sprintf("%v-%x", blk.Slot, blk.BlockRoot)
+				seenMux.Lock()
+				_, exists := seen[key]
+				if !exists {
+					seen[key] = blk.Slot
+				}
+				// forget about blocks that are older than 2 epochs so the map does not grow indefinitely
+				if blk.Slot > lastPrunedSlot+utils.Config.Chain.SlotsPerEpoch*2 {
+					for k, slot := range seen {
+						if slot+utils.Config.Chain.SlotsPerEpoch*2 < blk.Slot {
+							delete(seen, k)
+						}
+					}
+					lastPrunedSlot = blk.Slot
+				}
+				seenMux.Unlock()
+				if !exists {
+					blkCh <- blk
+				}
+			}
+		}(client.GetNewBlockChan())
+	}
+	return blkCh
+}
+
+// GetChainHead gets the chain head from the first healthy node
+func (fc *FailoverClient) GetChainHead() (*types.ChainHead, error) {
+	var res *types.ChainHead
+	err := fc.do("GetChainHead", func(client Client) (err error) {
+		res, err = client.GetChainHead()
+		return err
+	})
+	return res, err
+}
+
+// GetEpochData gets the epoch data from the first healthy node
+func (fc *FailoverClient) GetEpochData(epoch uint64) (*types.EpochData, error) {
+	var res *types.EpochData
+	err := fc.do("GetEpochData", func(client Client) (err error) {
+		res, err = client.GetEpochData(epoch)
+		return err
+	})
+	return res, err
+}
+
+// GetValidatorQueue gets the validator queue from the first healthy node
+func (fc *FailoverClient) GetValidatorQueue() (*types.ValidatorQueue, error) {
+	var res *types.ValidatorQueue
+	err := fc.do("GetValidatorQueue", func(client Client) (err error) {
+		res, err = client.GetValidatorQueue()
+		return err
+	})
+	return res, err
+}
+
+// GetEpochAssignments gets the epoch assignments from the first healthy node
+func (fc *FailoverClient) GetEpochAssignments(epoch uint64) (*types.EpochAssignments, error) {
+	var res *types.EpochAssignments
+	err := fc.do("GetEpochAssignments", func(client Client) (err error) {
+		res, err = client.GetEpochAssignments(epoch)
+		return err
+	})
+	return res, err
+}
+
+// GetBlocksBySlot gets the blocks of a slot from the first healthy node
+func (fc *FailoverClient) GetBlocksBySlot(slot uint64) ([]*types.Block, error) {
+	var res []*types.Block
+	err := fc.do("GetBlocksBySlot", func(client Client) (err error) {
+		res, err = client.GetBlocksBySlot(slot)
+		return err
+	})
+	return res, err
+}
+
+// GetValidatorParticipation gets the validator participation from the first healthy node
+func (fc *FailoverClient) GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
+	var res *types.ValidatorParticipation
+	err := fc.do("GetValidatorParticipation", func(client Client) (err error) {
+		res, err = client.GetValidatorParticipation(epoch)
+		return err
+	})
+	return res, err
+}
+
+// GetBlockStatusByEpoch gets the block status of an epoch from the first healthy node
+func (fc *FailoverClient) GetBlockStatusByEpoch(epoch uint64) ([]*types.CanonBlock, error) {
+	var res []*types.CanonBlock
+	err := fc.do("GetBlockStatusByEpoch", func(client Client) (err error) {
+		res, err = client.GetBlockStatusByEpoch(epoch)
+		return err
+	})
+	return res, err
+}
+
+// GetFinalityCheckpoints gets the finality checkpoints from the first healthy node
+func (fc *FailoverClient) GetFinalityCheckpoints(epoch uint64) (*types.FinalityCheckpoints, error) {
+	var res *types.FinalityCheckpoints
+	err := fc.do("GetFinalityCheckpoints", func(client Client) (err error) {
+		res, err = client.GetFinalityCheckpoints(epoch)
+		return err
+	})
+	return res, err
+}
+
+// GetSyncCommittee gets the sync committee from the first healthy node
+func (fc *FailoverClient) GetSyncCommittee(stateID string, epoch uint64) (*StandardSyncCommittee, error) {
+	var res *StandardSyncCommittee
+	err := fc.do("GetSyncCommittee", func(client Client) (err error) {
+		res, err = client.GetSyncCommittee(stateID, epoch)
+		return err
+	})
+	return res, err
+}
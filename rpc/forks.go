@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetForkEpochs returns the fork epochs of the <FORK>_FORK_EPOCH parameters of the spec of the beacon node by the lower
+// case name of the fork, forks that are not scheduled (FAR_FUTURE_EPOCH) are omitted
+func GetForkEpochs(endpoint string) (map[string]uint64, error) {
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Get(fmt.Sprintf("%s/eth/v1/config/spec", endpoint))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error-response: %s", data)
+	}
+
+	parsed := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	err = json.Unmarshal(data, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing spec: %w", err)
+	}
+
+	forks := map[string]uint64{}
+	for name, value := range parsed.Data {
+		if !strings.HasSuffix(name, "_FORK_EPOCH") {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		epoch, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %v of spec: %w", name, err)
+		}
+		if epoch == math.MaxUint64 {
+			continue
+		}
+		forks[strings.ToLower(strings.TrimSuffix(name, "_FORK_EPOCH"))] = epoch
+	}
+	return forks, nil
+}
@@ -88,13 +88,13 @@ func (lc *LighthouseClient) GetNewBlockChan() chan *types.Block {
 func (lc *LighthouseClient) GetChainHead() (*types.ChainHead, error) {
 	headResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/headers/head", lc.endpoint))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving chain head: %v", err)
+		return nil, fmt.Errorf("error retrieving chain head: %w", err)
 	}
 
 	var parsedHead StandardBeaconHeaderResponse
 	err = json.Unmarshal(headResp, &parsedHead)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing chain head: %v", err)
+		return nil, fmt.Errorf("error parsing chain head: %w", err)
 	}
 
 	id := parsedHead.Data.Header.Message.StateRoot
@@ -103,13 +103,13 @@ func (lc *LighthouseClient) GetChainHead() (*types.ChainHead, error) {
 	}
 	finalityResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%s/finality_checkpoints", lc.endpoint, id))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving finality checkpoints of head: %v", err)
+		return nil, fmt.Errorf("error retrieving finality checkpoints of head: %w", err)
 	}
 
 	var parsedFinality StandardFinalityCheckpointsResponse
 	err = json.Unmarshal(finalityResp, &parsedFinality)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing finality checkpoints of head: %v", err)
+		return nil, fmt.Errorf("error parsing finality checkpoints of head: %w", err)
 	}
 
 	return &types.ChainHead{
@@ -132,13 +132,13 @@ func (lc *LighthouseClient) GetValidatorQueue() (*types.ValidatorQueue, error) {
 	// pre-filter the status, to return much less validators, thus much faster!
 	validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/head/validators?status=pending_queued,exited", lc.endpoint))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validator for head valiqdator queue check: %v", err)
+		return nil, fmt.Errorf("error retrieving validator for head valiqdator queue check: %w", err)
 	}
 
 	var parsedValidators StandardValidatorsResponse
 	err = json.Unmarshal(validatorsResp, &parsedValidators)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing queue validators: %v", err)
+		return nil, fmt.Errorf("error parsing queue validators: %w", err)
 	}
 	// TODO: maybe track more status counts in the future?
 	activatingValidatorCount := uint64(0)
@@ -181,23 +181,23 @@ func (lc *LighthouseClient) GetEpochAssignments(epoch uint64) (*types.EpochAssig
 
 	proposerResp, err := lc.get(fmt.Sprintf("%s/eth/v1/validator/duties/proposer/%d", lc.endpoint, epoch))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving proposer duties: %v", err)
+		return nil, fmt.Errorf("error retrieving proposer duties: %w", err)
 	}
 	var parsedProposerResponse StandardProposerDutiesResponse
 	err = json.Unmarshal(proposerResp, &parsedProposerResponse)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing proposer duties: %v", err)
+		return nil, fmt.Errorf("error parsing proposer duties: %w", err)
 	}
 
 	// fetch the block root that the proposer data is dependent on
 	headerResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/headers/%s", lc.endpoint, parsedProposerResponse.DependentRoot))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving chain header: %v", err)
+		return nil, fmt.Errorf("error retrieving chain header: %w", err)
 	}
 	var parsedHeader StandardBeaconHeaderResponse
 	err = json.Unmarshal(headerResp, &parsedHeader)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing chain header: %v", err)
+		return nil, fmt.Errorf("error parsing chain header: %w", err)
 	}
 	depStateRoot := parsedHeader.Data.Header.Message.StateRoot
 
@@ -274,14 +274,14 @@ func (lc *LighthouseClient) GetEpochData(epoch uint64) (*types.EpochData, error)
 
 	validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%d/validators", lc.endpoint, epoch*utils.Config.Chain.SlotsPerEpoch))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validators for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error retrieving validators for epoch %v: %w", epoch, err)
 	}
 
 	var parsedValidators StandardValidatorsResponse
 	err = json.Unmarshal(validatorsResp, &parsedValidators)
 
 	if err != nil {
-		return nil, fmt.Errorf("error parsing epoch validators: %v", err)
+		return nil, fmt.Errorf("error parsing epoch validators: %w", err)
 	}
 
 	epoch1d := int64(epoch) - 225
@@ -494,26 +494,26 @@ func (lc *LighthouseClient) GetBlockByBlockroot(blockroot []byte) (*types.Block,
 			// no block found
 			return &types.Block{}, nil
 		}
-		return nil, fmt.Errorf("error retrieving headers for blockroot 0x%x: %v", blockroot, err)
+		return nil, fmt.Errorf("error retrieving headers for blockroot 0x%x: %w", blockroot, err)
 	}
 	var parsedHeaders StandardBeaconHeaderResponse
 	err = json.Unmarshal(resHeaders, &parsedHeaders)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing header-response for blockroot 0x%x: %v", blockroot, err)
+		return nil, fmt.Errorf("error parsing header-response for blockroot 0x%x: %w", blockroot, err)
 	}
 
 	slot := uint64(parsedHeaders.Data.Header.Message.Slot)
 
 	resp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/blocks/%s", lc.endpoint, parsedHeaders.Data.Root))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving block data at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error retrieving block data at slot %v: %w", slot, err)
 	}
 
 	var parsedResponse StandardV2BlockResponse
 	err = json.Unmarshal(resp, &parsedResponse)
 	if err != nil {
 		logger.Errorf("error parsing block data at slot %v: %v", parsedHeaders.Data.Header.Message.Slot, err)
-		return nil, fmt.Errorf("error parsing block-response at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error parsing block-response at slot %v: %w", slot, err)
 	}
 
 	return lc.blockFromResponse(&parsedHeaders, &parsedResponse)
@@ -527,24 +527,24 @@ func (lc *LighthouseClient) GetBlocksBySlot(slot uint64) ([]*types.Block, error)
 			// no block found
 			return []*types.Block{}, nil
 		}
-		return nil, fmt.Errorf("error retrieving headers at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error retrieving headers at slot %v: %w", slot, err)
 	}
 	var parsedHeaders StandardBeaconHeaderResponse
 	err = json.Unmarshal(resHeaders, &parsedHeaders)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing header-response at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error parsing header-response at slot %v: %w", slot, err)
 	}
 
 	resp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/blocks/%s", lc.endpoint, parsedHeaders.Data.Root))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving block data at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error retrieving block data at slot %v: %w", slot, err)
 	}
 
 	var parsedResponse StandardV2BlockResponse
 	err = json.Unmarshal(resp, &parsedResponse)
 	if err != nil {
 		logger.Errorf("error parsing block data at slot %v: %v", slot, err)
-		return nil, fmt.Errorf("error parsing block-response at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error parsing block-response at slot %v: %w", slot, err)
 	}
 
 	block, err := lc.blockFromResponse(&parsedHeaders, &parsedResponse)
@@ -600,6 +600,27 @@ func (lc *LighthouseClient) blockFromResponse(parsedHeaders *StandardBeaconHeade
 		}
 	}
 
+	if payload := parsedBlock.Message.Body.ExecutionPayload; payload != nil {
+		block.ExecutionPayload = &types.ExecutionPayload{
+			BlockHash:         utils.MustParseHex(payload.BlockHash),
+			BlockNumber:       uint64(payload.BlockNumber),
+			FeeRecipient:      utils.MustParseHex(payload.FeeRecipient),
+			GasUsed:           uint64(payload.GasUsed),
+			GasLimit:          uint64(payload.GasLimit),
+			BaseFeePerGas:     uint64(payload.BaseFeePerGas),
+			Timestamp:         uint64(payload.Timestamp),
+			TransactionsCount: uint64(len(payload.Transactions)),
+		}
+		for _, withdrawal := range payload.Withdrawals {
+			block.ExecutionPayload.Withdrawals = append(block.ExecutionPayload.Withdrawals, &types.Withdrawal{
+				Index:          uint64(withdrawal.Index),
+				ValidatorIndex: uint64(withdrawal.ValidatorIndex),
+				Address:        utils.MustParseHex(withdrawal.Address),
+				Amount:         uint64(withdrawal.Amount),
+			})
+		}
+	}
+
 	// TODO: this is legacy from old lighthouse API. Does it even still apply?
 	if block.Eth1Data.DepositCount > 2147483647 { // Sometimes the lighthouse node does return bogus data for the DepositCount value
 		block.Eth1Data.DepositCount = 0
@@ -687,7 +708,7 @@ func (lc *LighthouseClient) blockFromResponse(parsedHeaders *StandardBeaconHeade
 		aggregationBits := bitfield.Bitlist(a.AggregationBits)
 		assignments, err := lc.GetEpochAssignments(a.Data.Slot / utils.Config.Chain.SlotsPerEpoch)
 		if err != nil {
-			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %v", a.Data.Slot/utils.Config.Chain.SlotsPerEpoch, err)
+			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %w", a.Data.Slot/utils.Config.Chain.SlotsPerEpoch, err)
 		}
 
 		for i := uint64(0); i < aggregationBits.Len(); i++ {
@@ -724,6 +745,25 @@ func (lc *LighthouseClient) blockFromResponse(parsedHeaders *StandardBeaconHeade
 		}
 	}
 
+	for _, change := range parsedBlock.Message.Body.BLSToExecutionChanges {
+		block.BLSToExecutionChanges = append(block.BLSToExecutionChanges, &types.BLSToExecutionChange{
+			Validatorindex: uint64(change.Message.ValidatorIndex),
+			BlsPubkey:      utils.MustParseHex(change.Message.FromBLSPubkey),
+			Address:        utils.MustParseHex(change.Message.ToExecutionAddress),
+			Signature:      utils.MustParseHex(change.Signature),
+		})
+	}
+
+	if requests := parsedBlock.Message.Body.ExecutionRequests; requests != nil {
+		for _, consolidation := range requests.Consolidations {
+			block.ConsolidationRequests = append(block.ConsolidationRequests, &types.ConsolidationRequest{
+				SourceAddress: utils.MustParseHex(consolidation.SourceAddress),
+				SourcePubkey:  utils.MustParseHex(consolidation.SourcePubkey),
+				TargetPubkey:  utils.MustParseHex(consolidation.TargetPubkey),
+			})
+		}
+	}
+
 	return block, nil
 }
 
@@ -741,13 +781,13 @@ func syncCommitteeParticipation(bits []byte) float64 {
 func (lc *LighthouseClient) GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
 	resp, err := lc.get(fmt.Sprintf("%s/lighthouse/validator_inclusion/%d/global", lc.endpoint, epoch))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validator participation data for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error retrieving validator participation data for epoch %v: %w", epoch, err)
 	}
 
 	var parsedResponse LighthouseValidatorParticipationResponse
 	err = json.Unmarshal(resp, &parsedResponse)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing validator participation data for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error parsing validator participation data for epoch %v: %w", epoch, err)
 	}
 
 	return &types.ValidatorParticipation{
@@ -783,9 +823,21 @@ func (lc *LighthouseClient) GetSyncCommittee(stateID string, epoch uint64) (*Sta
 
 var notFoundErr = errors.New("not found 404")
 
+// httpStatusError is the error of a beacon node request that was answered with a status other than 200 and 404
+type httpStatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("error-response: %s", e.Body)
+}
+
 func (lc *LighthouseClient) get(url string) ([]byte, error) {
-	// t0 := time.Now()
-	// defer func() { fmt.Println(url, time.Since(t0)) }()
+	t0 := time.Now()
+	defer func() {
+		logger.WithFields(logrus.Fields{"url": url, "duration": time.Since(t0).Milliseconds()}).Debug("beacon node request")
+	}()
 	client := &http.Client{Timeout: time.Second * 120}
 
 	resp, err := client.Get(url)
@@ -801,7 +853,7 @@ func (lc *LighthouseClient) get(url string) ([]byte, error) {
 		if resp.StatusCode == http.StatusNotFound {
 			return nil, notFoundErr
 		}
-		return nil, fmt.Errorf("error-response: %s", data)
+		return nil, &httpStatusError{StatusCode: resp.StatusCode, Body: data}
 	}
 
 	return data, err
@@ -813,6 +865,11 @@ func (s *uint64Str) UnmarshalJSON(b []byte) error {
 	return Uint64Unmarshal((*uint64)(s), b)
 }
 
+// MarshalJSON encodes the uint64 as quoted decimal string like the standard beacon node api expects it
+func (s uint64Str) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%d\"", uint64(s))), nil
+}
+
 // Parse a uint64, with or without quotes, in any base, with common prefixes accepted to change base.
 func Uint64Unmarshal(v *uint64, b []byte) error {
 	if v == nil {
@@ -1016,6 +1073,43 @@ type SyncAggregate struct {
 	SyncCommitteeSignature string `json:"sync_committee_signature"`
 }
 
+type ExecutionPayload struct {
+	ParentHash    string    `json:"parent_hash"`
+	FeeRecipient  string    `json:"fee_recipient"`
+	StateRoot     string    `json:"state_root"`
+	ReceiptsRoot  string    `json:"receipts_root"`
+	LogsBloom     string    `json:"logs_bloom"`
+	PrevRandao    string    `json:"prev_randao"`
+	BlockNumber   uint64Str `json:"block_number"`
+	GasLimit      uint64Str `json:"gas_limit"`
+	GasUsed       uint64Str `json:"gas_used"`
+	Timestamp     uint64Str `json:"timestamp"`
+	ExtraData     string    `json:"extra_data"`
+	BaseFeePerGas uint64Str `json:"base_fee_per_gas"`
+	BlockHash     string    `json:"block_hash"`
+	Transactions  []string  `json:"transactions"`
+
+	// not present before capella blocks
+	Withdrawals []Withdrawal `json:"withdrawals,omitempty"`
+}
+
+type Withdrawal struct {
+	Index          uint64Str `json:"index"`
+	ValidatorIndex uint64Str `json:"validator_index"`
+	Address        string    `json:"address"`
+	Amount         uint64Str `json:"amount"`
+}
+
+type ConsolidationRequest struct {
+	SourceAddress string `json:"source_address"`
+	SourcePubkey  string `json:"source_pubkey"`
+	TargetPubkey  string `json:"target_pubkey"`
+}
+
+type ExecutionRequests struct {
+	Consolidations []ConsolidationRequest `json:"consolidations"`
+}
+
 type AnySignedBlock struct {
 	Message struct {
 		Slot          uint64Str `json:"slot"`
@@ -1034,6 +1128,15 @@ type AnySignedBlock struct {
 
 			// not present in phase0 blocks
 			SyncAggregate *SyncAggregate `json:"sync_aggregate,omitempty"`
+
+			// not present in phase0 and altair blocks
+			ExecutionPayload *ExecutionPayload `json:"execution_payload,omitempty"`
+
+			// not present before capella blocks
+			BLSToExecutionChanges []SignedBLSToExecutionChange `json:"bls_to_execution_changes,omitempty"`
+
+			// not present before electra blocks
+			ExecutionRequests *ExecutionRequests `json:"execution_requests,omitempty"`
 		} `json:"body"`
 	} `json:"message"`
 	Signature string `json:"signature"`
@@ -144,7 +144,7 @@ func (pc *PrysmClient) GetValidatorQueue() (*types.ValidatorQueue, error) {
 	validators, err := pc.client.GetValidatorQueue(context.Background(), &empty.Empty{})
 
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validator queue data: %v", err)
+		return nil, fmt.Errorf("error retrieving validator queue data: %w", err)
 	}
 
 	return &types.ValidatorQueue{
@@ -184,7 +184,7 @@ func (pc *PrysmClient) GetEpochAssignments(epoch uint64) (*types.EpochAssignment
 		validatorAssignmentRequest.PageToken = validatorAssignmentResponse.NextPageToken
 		validatorAssignmentResponse, err = pc.client.ListValidatorAssignments(context.Background(), validatorAssignmentRequest)
 		if err != nil {
-			return nil, fmt.Errorf("error retrieving validator assignment response for caching: %v", err)
+			return nil, fmt.Errorf("error retrieving validator assignment response for caching: %w", err)
 		}
 
 		validatorAssignmentes = append(validatorAssignmentes, validatorAssignmentResponse.Assignments...)
@@ -248,7 +248,7 @@ func (pc *PrysmClient) GetEpochData(epoch uint64) (*types.EpochData, error) {
 
 	data.ValidatorAssignmentes, err = pc.GetEpochAssignments(epoch)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving assignments for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error retrieving assignments for epoch %v: %w", epoch, err)
 	}
 	logger.Printf("retrieved validator assignment data for epoch %v", epoch)
 
@@ -363,7 +363,7 @@ func (pc *PrysmClient) GetEpochData(epoch uint64) (*types.EpochData, error) {
 
 	data.EpochParticipationStats, err = pc.GetValidatorParticipation(epoch)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving epoch participation statistics for epoch %v: %v", epoch, err)
+		return nil, fmt.Errorf("error retrieving epoch participation statistics for epoch %v: %w", epoch, err)
 	}
 
 	return data, nil
@@ -598,7 +598,7 @@ func (pc *PrysmClient) parsePhase0Block(block *ethpb.BeaconBlockContainerAltair)
 		aggregationBits := bitfield.Bitlist(a.AggregationBits)
 		assignments, err := pc.GetEpochAssignments(a.Data.Slot / utils.Config.Chain.SlotsPerEpoch)
 		if err != nil {
-			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %v", a.Data.Slot/utils.Config.Chain.SlotsPerEpoch, err)
+			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %w", a.Data.Slot/utils.Config.Chain.SlotsPerEpoch, err)
 		}
 
 		a.Attesters = make([]uint64, 0)
@@ -753,7 +753,7 @@ func (pc *PrysmClient) parseAltairBlock(block *ethpb.BeaconBlockContainerAltair)
 		aggregationBits := bitfield.Bitlist(a.AggregationBits)
 		assignments, err := pc.GetEpochAssignments(a.Data.Slot / utils.Config.Chain.SlotsPerEpoch)
 		if err != nil {
-			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %v", a.Data.Slot/utils.Config.Chain.SlotsPerEpoch, err)
+			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %w", a.Data.Slot/utils.Config.Chain.SlotsPerEpoch, err)
 		}
 
 		a.Attesters = make([]uint64, 0)
@@ -1,9 +1,8 @@
 package rpc
 
 import (
+	"eth2-exporter/logging"
 	"eth2-exporter/types"
-
-	"github.com/sirupsen/logrus"
 )
 
 // Client provides an interface for RPC clients
@@ -20,4 +19,4 @@ type Client interface {
 	GetSyncCommittee(stateID string, epoch uint64) (*StandardSyncCommittee, error)
 }
 
-var logger = logrus.New().WithField("module", "rpc")
+var logger = logging.NewLogger("rpc")
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"time"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/customer"
+)
+
+// accountDeletionWorker regularly deletes the user accounts whose scheduled deletion is due
+func accountDeletionWorker() {
+	for {
+		t0 := time.Now()
+		deleted, err := deleteScheduledUsers()
+		if err != nil {
+			logger.Errorf("error deleting scheduled user accounts: %v", err)
+		} else if deleted > 0 {
+			logger.WithField("deleted", deleted).WithField("duration", time.Since(t0)).Info("deleted scheduled user accounts")
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// deleteScheduledUsers deletes the accounts whose deletion is due and returns the number of deleted accounts, the
+// stripe customer of an account is deleted before its data so that the user is not billed after the deletion
+func deleteScheduledUsers() (int, error) {
+	users, err := db.GetDueUserDeletions(100)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, user := range users {
+		if user.StripeCustomerID.Valid && user.StripeCustomerID.String != "" {
+			err = deleteStripeCustomer(user.StripeCustomerID.String)
+			if err != nil {
+				// the account is kept until the customer is deleted, the deletion is retried in the next run
+				logger.Errorf("error deleting stripe customer of user %v: %v", user.UserID, err)
+				continue
+			}
+		}
+		err = db.DeleteUserData(user.UserID)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// deleteStripeCustomer deletes the stripe customer, stripe cancels the active subscriptions of a deleted customer
+// immediately and removes its payment methods
+func deleteStripeCustomer(customerID string) error {
+	if utils.Config.Frontend.Stripe.SecretKey == "" {
+		logger.Warnf("not deleting stripe customer %v, no stripe secret key configured", customerID)
+		return nil
+	}
+	client := customer.Client{B: stripe.GetBackend(stripe.APIBackend), Key: utils.Config.Frontend.Stripe.SecretKey}
+	_, err := client.Del(customerID, nil)
+	if stripeErr, ok := err.(*stripe.Error); ok && stripeErr.Code == stripe.ErrorCodeResourceMissing {
+		// the customer has already been deleted
+		return nil
+	}
+	return err
+}
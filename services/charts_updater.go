@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/metrics"
 	"eth2-exporter/types"
@@ -38,6 +39,16 @@ var ChartHandlers = map[string]chartHandler{
 	"deposits":                       {13, depositsChartData},
 	"deposits_distribution":          {13, depositsDistributionChartData},
 	"graffiti_wordcloud":             {14, graffitiCloudChartData},
+	"attestation_aggregation":        {15, attestationAggregationChartData},
+	"rocketpool_reth_premium":        {16, rocketpoolRETHPremiumChartData},
+	"cohort_apr":                     {17, cohortAPRChartData},
+	"wrong_head_rate":                {18, wrongHeadRateChartData},
+	"deposit_contract":               {19, func() (*types.GenericChartData, error) { return EconomicsChartData("deposit_contract") }},
+	"staking_ratio":                  {20, func() (*types.GenericChartData, error) { return EconomicsChartData("staking_ratio") }},
+	"issuance":                       {21, func() (*types.GenericChartData, error) { return EconomicsChartData("issuance") }},
+	"block_packing":                  {22, blockPackingChartData},
+	"relay_auctions":                 {23, relayAuctionsChartData},
+	"builder_market_share":           {24, builderMarketShareChartData},
 }
 
 // LatestChartsPageData returns the latest chart page data
@@ -76,6 +87,7 @@ func chartsPageDataUpdater() {
 		metrics.TaskDuration.WithLabelValues("service_charts_updater").Observe(time.Since(start).Seconds())
 		logger.WithField("epoch", latestEpoch).WithField("duration", time.Since(start)).Info("chartPageData update completed")
 		chartsPageData.Store(&data)
+		markCacheUpdated("charts")
 		prevEpoch = latestEpoch
 		if latestEpoch == 0 {
 			time.Sleep(time.Second * 60 * 10)
@@ -112,12 +124,21 @@ func getChartsPageData() ([]*types.ChartsPageDataChart, error) {
 	}()
 
 	pageCharts := []*types.ChartsPageDataChart{}
+	forkPlotLines := ForkChartPlotLines()
 
 	for chart := range chartHandlerResChan {
 		if chart.Error != nil {
 			logger.Errorf("error getting chart data for %v: %v", chart.Path, chart.Error)
 			continue
 		}
+		if chart.Data == nil {
+			// the chart is not available on this network
+			continue
+		}
+		if !chart.Data.IsNormalChart {
+			// mark the forks on the time axis so changes of the protocol are not mistaken for changes of the network
+			chart.Data.XAxisPlotLines = forkPlotLines
+		}
 		pageCharts = append(pageCharts, &types.ChartsPageDataChart{
 			Order: chart.Order,
 			Path:  chart.Path,
@@ -232,28 +253,49 @@ func blocksChartData() (*types.GenericChartData, error) {
 	return chartData, nil
 }
 
-func activeValidatorsChartData() (*types.GenericChartData, error) {
-	if LatestEpoch() == 0 {
-		return nil, fmt.Errorf("chart-data not available pre-genesis")
+// timescaleDailySeries returns a chart series with one point per day of the network_stats_daily continuous aggregate
+func timescaleDailySeries(value func(*types.NetworkStatsBucket) float64) ([][]float64, error) {
+	days, err := db.GetNetworkStatsDaily()
+	if err != nil {
+		return nil, err
 	}
 
-	rows := []struct {
-		Epoch           uint64
-		ValidatorsCount uint64
-	}{}
+	series := make([][]float64, 0, len(days))
+	for _, d := range days {
+		series = append(series, []float64{float64(d.Ts.Unix() * 1000), value(d)})
+	}
+	return series, nil
+}
 
-	err := db.DB.Select(&rows, "SELECT epoch, validatorscount FROM epochs ORDER BY epoch")
-	if err != nil {
-		return nil, err
+func activeValidatorsChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
 	}
 
 	dailyActiveValidators := [][]float64{}
+	if utils.Config.Timescale.Enabled {
+		var err error
+		dailyActiveValidators, err = timescaleDailySeries(func(d *types.NetworkStatsBucket) float64 { return float64(d.ValidatorsCount) })
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rows := []struct {
+			Epoch           uint64
+			ValidatorsCount uint64
+		}{}
 
-	for _, row := range rows {
-		day := float64(utils.EpochToTime(row.Epoch).Truncate(time.Hour*24).Unix() * 1000)
+		err := db.DB.Select(&rows, "SELECT epoch, validatorscount FROM epochs ORDER BY epoch")
+		if err != nil {
+			return nil, err
+		}
 
-		if len(dailyActiveValidators) == 0 || dailyActiveValidators[len(dailyActiveValidators)-1][0] != day {
-			dailyActiveValidators = append(dailyActiveValidators, []float64{day, float64(row.ValidatorsCount)})
+		for _, row := range rows {
+			day := float64(utils.EpochToTime(row.Epoch).Truncate(time.Hour*24).Unix() * 1000)
+
+			if len(dailyActiveValidators) == 0 || dailyActiveValidators[len(dailyActiveValidators)-1][0] != day {
+				dailyActiveValidators = append(dailyActiveValidators, []float64{day, float64(row.ValidatorsCount)})
+			}
 		}
 	}
 
@@ -280,23 +322,30 @@ func stakedEtherChartData() (*types.GenericChartData, error) {
 		return nil, fmt.Errorf("chart-data not available pre-genesis")
 	}
 
-	rows := []struct {
-		Epoch         uint64
-		EligibleEther uint64
-	}{}
-
-	err := db.DB.Select(&rows, "SELECT epoch, eligibleether FROM epochs ORDER BY epoch")
-	if err != nil {
-		return nil, err
-	}
-
 	dailyStakedEther := [][]float64{}
+	if utils.Config.Timescale.Enabled {
+		var err error
+		dailyStakedEther, err = timescaleDailySeries(func(d *types.NetworkStatsBucket) float64 { return float64(d.EligibleEther) / 1000000000 })
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rows := []struct {
+			Epoch         uint64
+			EligibleEther uint64
+		}{}
 
-	for _, row := range rows {
-		day := float64(utils.EpochToTime(row.Epoch).Truncate(time.Hour*24).Unix() * 1000)
+		err := db.DB.Select(&rows, "SELECT epoch, eligibleether FROM epochs ORDER BY epoch")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			day := float64(utils.EpochToTime(row.Epoch).Truncate(time.Hour*24).Unix() * 1000)
 
-		if len(dailyStakedEther) == 0 || dailyStakedEther[len(dailyStakedEther)-1][0] != day {
-			dailyStakedEther = append(dailyStakedEther, []float64{day, float64(row.EligibleEther) / 1000000000})
+			if len(dailyStakedEther) == 0 || dailyStakedEther[len(dailyStakedEther)-1][0] != day {
+				dailyStakedEther = append(dailyStakedEther, []float64{day, float64(row.EligibleEther) / 1000000000})
+			}
 		}
 	}
 
@@ -323,23 +372,32 @@ func averageBalanceChartData() (*types.GenericChartData, error) {
 		return nil, fmt.Errorf("chart-data not available pre-genesis")
 	}
 
-	rows := []struct {
-		Epoch                   uint64
-		AverageValidatorBalance uint64
-	}{}
-
-	err := db.DB.Select(&rows, "SELECT epoch, averagevalidatorbalance FROM epochs ORDER BY epoch")
-	if err != nil {
-		return nil, err
-	}
-
 	dailyAverageBalance := [][]float64{}
+	if utils.Config.Timescale.Enabled {
+		var err error
+		dailyAverageBalance, err = timescaleDailySeries(func(d *types.NetworkStatsBucket) float64 {
+			return utils.RoundDecimals(d.AverageValidatorBalance/1e9, 4)
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rows := []struct {
+			Epoch                   uint64
+			AverageValidatorBalance uint64
+		}{}
 
-	for _, row := range rows {
-		day := float64(utils.EpochToTime(row.Epoch).Truncate(time.Hour*24).Unix() * 1000)
+		err := db.DB.Select(&rows, "SELECT epoch, averagevalidatorbalance FROM epochs ORDER BY epoch")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			day := float64(utils.EpochToTime(row.Epoch).Truncate(time.Hour*24).Unix() * 1000)
 
-		if len(dailyAverageBalance) == 0 || dailyAverageBalance[len(dailyAverageBalance)-1][0] != day {
-			dailyAverageBalance = append(dailyAverageBalance, []float64{day, utils.RoundDecimals(float64(row.AverageValidatorBalance)/1e9, 4)})
+			if len(dailyAverageBalance) == 0 || dailyAverageBalance[len(dailyAverageBalance)-1][0] != day {
+				dailyAverageBalance = append(dailyAverageBalance, []float64{day, utils.RoundDecimals(float64(row.AverageValidatorBalance)/1e9, 4)})
+			}
 		}
 	}
 
@@ -414,23 +472,36 @@ func participationRateChartData() (*types.GenericChartData, error) {
 		return nil, fmt.Errorf("chart-data not available pre-genesis")
 	}
 
-	rows := []struct {
-		Epoch                   uint64
-		Globalparticipationrate float64
-	}{}
-
-	err := db.DB.Select(&rows, "SELECT epoch, globalparticipationrate FROM epochs WHERE epoch < $1 ORDER BY epoch", LatestEpoch())
-	if err != nil {
-		return nil, err
-	}
-
 	seriesData := [][]float64{}
+	if utils.Config.Timescale.Enabled {
+		// the hypertable is averaged hourly, the epochs table returns every epoch
+		buckets, err := db.GetParticipationRateBuckets(time.Hour, LatestEpoch())
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range buckets {
+			seriesData = append(seriesData, []float64{
+				float64(b.Ts.Unix() * 1000),
+				utils.RoundDecimals(b.GlobalParticipationRate*100, 2),
+			})
+		}
+	} else {
+		rows := []struct {
+			Epoch                   uint64
+			Globalparticipationrate float64
+		}{}
 
-	for _, row := range rows {
-		seriesData = append(seriesData, []float64{
-			float64(utils.EpochToTime(row.Epoch).Unix() * 1000),
-			utils.RoundDecimals(row.Globalparticipationrate*100, 2),
-		})
+		err := db.DB.Select(&rows, "SELECT epoch, globalparticipationrate FROM epochs WHERE epoch < $1 ORDER BY epoch", LatestEpoch())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			seriesData = append(seriesData, []float64{
+				float64(utils.EpochToTime(row.Epoch).Unix() * 1000),
+				utils.RoundDecimals(row.Globalparticipationrate*100, 2),
+			})
+		}
 	}
 
 	chartData := &types.GenericChartData{
@@ -1851,3 +1922,375 @@ func graffitiCloudChartData() (*types.GenericChartData, error) {
 
 	return chartData, nil
 }
+
+func attestationAggregationChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	stats, err := db.GetDailyAttestationAggregationStats(context.Background(), 0, LatestEpoch()/epochsPerDay)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []string{}
+	clientData := map[string][][]float64{}
+	for _, s := range stats {
+		if clientData[s.Client] == nil {
+			clients = append(clients, s.Client)
+		}
+		clientData[s.Client] = append(clientData[s.Client], []float64{
+			float64(utils.EpochToTime(s.Day*epochsPerDay).Unix() * 1000),
+			utils.RoundDecimals(s.RedundancyRate()*100, 2),
+		})
+	}
+
+	series := make([]*types.GenericChartDataSeries, 0, len(clients))
+	for _, client := range clients {
+		series = append(series, &types.GenericChartDataSeries{
+			Name: strings.Title(client),
+			Data: clientData[client],
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "Attestation Aggregation Redundancy",
+		Subtitle:     "Share of the votes included by the proposers of each client that had already been included in an earlier block of the same epoch.",
+		XAxisTitle:   "",
+		YAxisTitle:   "Redundant Votes [%]",
+		StackingMode: "false",
+		Type:         "line",
+		Series:       series,
+	}
+
+	return chartData, nil
+}
+
+func blockPackingChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	stats, err := db.GetDailyBlockPackingStats(context.Background(), 0, LatestEpoch()/epochsPerDay)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	clients := []string{}
+	clientData := map[string][][]float64{}
+	for _, s := range stats {
+		if clientData[s.Client] == nil {
+			clients = append(clients, s.Client)
+		}
+		clientData[s.Client] = append(clientData[s.Client], []float64{
+			float64(utils.EpochToTime(s.Day*epochsPerDay).Unix() * 1000),
+			utils.RoundDecimals(s.AttestationPackingEfficiency()*100, 2),
+		})
+	}
+
+	series := make([]*types.GenericChartDataSeries, 0, len(clients))
+	for _, client := range clients {
+		series = append(series, &types.GenericChartDataSeries{
+			Name: strings.Title(client),
+			Data: clientData[client],
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "Block Packing Efficiency",
+		Subtitle:     "Share of the attestations available to the proposers of each client that they included, available are the attestations within the inclusion window that had not been included by an earlier block and got included eventually.",
+		XAxisTitle:   "",
+		YAxisTitle:   "Packing Efficiency [%]",
+		StackingMode: "false",
+		Type:         "line",
+		Series:       series,
+	}
+
+	return chartData, nil
+}
+
+func wrongHeadRateChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	epochsPerDay := (24 * 60 * 60) / utils.Config.Chain.SlotsPerEpoch / utils.Config.Chain.SecondsPerSlot
+	stats, err := db.GetDailyAttestationCorrectnessStats(0, LatestEpoch()/epochsPerDay)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	headSeries := make([][]float64, 0, len(stats))
+	targetSeries := make([][]float64, 0, len(stats))
+	for _, s := range stats {
+		ts := float64(utils.EpochToTime(s.Day*epochsPerDay).Unix() * 1000)
+		headSeries = append(headSeries, []float64{ts, utils.RoundDecimals(s.WrongHeadRate()*100, 3)})
+		targetSeries = append(targetSeries, []float64{ts, utils.RoundDecimals(s.WrongTargetRate()*100, 3)})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "Wrong Head Votes",
+		Subtitle:     "Share of the included attestations that voted for a head or target that did not become canonical, a sudden increase often hints at a client bug.",
+		XAxisTitle:   "",
+		YAxisTitle:   "Wrong Votes [%]",
+		StackingMode: "false",
+		Type:         "line",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Wrong Head",
+				Data: headSeries,
+			},
+			{
+				Name: "Wrong Target",
+				Data: targetSeries,
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func rocketpoolRETHPremiumChartData() (*types.GenericChartData, error) {
+	rates, err := db.GetRocketpoolRETHHistory(context.Background(), time.Unix(0, 0), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, nil
+	}
+
+	premiumSeries := make([][]float64, 0, len(rates))
+	for _, r := range rates {
+		premium := r.Premium()
+		if premium == nil {
+			continue
+		}
+		premiumSeries = append(premiumSeries, []float64{
+			float64(r.Ts.Unix() * 1000),
+			utils.RoundDecimals(*premium*100, 3),
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "rETH Premium / Discount",
+		Subtitle:     "Premium (positive) or discount (negative) of the secondary-market price of rETH over the Rocketpool exchange rate, a premium favors minting and a discount favors buying rETH.",
+		XAxisTitle:   "",
+		YAxisTitle:   "Premium [%]",
+		StackingMode: "false",
+		Type:         "line",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Premium",
+				Data: premiumSeries,
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+// cohortAPRRollingDays is the number of days the apr of the cohort chart is averaged over
+const cohortAPRRollingDays = 7
+
+func cohortAPRChartData() (*types.GenericChartData, error) {
+	stats, err := db.GetCohortAPR()
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	epochsPerDay := utils.EpochsPerDay()
+	series := []*types.GenericChartDataSeries{}
+	// the stats are ordered by cohort and day, the apr of a day is the income of the last days over their effective balance
+	for first := 0; first < len(stats); {
+		cohort := stats[first].Cohort
+		data := [][]float64{}
+		j := first
+		for ; j < len(stats) && stats[j].Cohort == cohort; j++ {
+			var income int64
+			var effectiveBalance uint64
+			for k := j; k >= first && stats[j].Day-stats[k].Day < cohortAPRRollingDays; k-- {
+				income += stats[k].Income
+				effectiveBalance += stats[k].EffectiveBalance
+			}
+			if effectiveBalance > 0 {
+				data = append(data, []float64{
+					float64(utils.EpochToTime(stats[j].Day*epochsPerDay).Unix() * 1000),
+					utils.RoundDecimals(float64(income)/float64(effectiveBalance)*365*100, 2),
+				})
+			}
+		}
+		first = j
+		series = append(series, &types.GenericChartDataSeries{
+			Name: cohort,
+			Data: data,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "APR by Activation Cohort",
+		Subtitle:     fmt.Sprintf("Realized APR of the validators grouped by the month they were activated in, averaged over %v days. Only validators active during the whole day are included.", cohortAPRRollingDays),
+		XAxisTitle:   "",
+		YAxisTitle:   "APR [%]",
+		StackingMode: "false",
+		Type:         "line",
+		Series:       series,
+	}
+
+	return chartData, nil
+}
+
+// relayAuctionsChartDays is the number of days the relay auction charts show
+const relayAuctionsChartDays = 90
+
+// builderMarketShareChartBuilders is the number of builders shown in the market share chart, the others are summed up
+const builderMarketShareChartBuilders = 8
+
+func relayAuctionsChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	epochsPerDay := utils.EpochsPerDay()
+	endDay := LatestEpoch() / epochsPerDay
+	startDay := uint64(0)
+	if endDay >= relayAuctionsChartDays {
+		startDay = endDay - relayAuctionsChartDays + 1
+	}
+	stats, err := db.GetDailyRelayAuctionStats(context.Background(), startDay, endDay, epochsPerDay*utils.Config.Chain.SlotsPerEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	winningSeries := make([][]float64, 0, len(stats))
+	secondSeries := make([][]float64, 0, len(stats))
+	leftSeries := make([][]float64, 0, len(stats))
+	for _, s := range stats {
+		ts := float64(utils.EpochToTime(s.Day*epochsPerDay).Unix() * 1000)
+		winning, _ := s.AvgWinningBid.Rat(18).Float64()
+		second, _ := s.AvgSecondBid.Rat(18).Float64()
+		left, _ := s.ValueLeftOnTable.Rat(18).Float64()
+		winningSeries = append(winningSeries, []float64{ts, utils.RoundDecimals(winning, 5)})
+		secondSeries = append(secondSeries, []float64{ts, utils.RoundDecimals(second, 5)})
+		leftSeries = append(leftSeries, []float64{ts, utils.RoundDecimals(left, 5)})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "Relay Auctions",
+		Subtitle:     "Average winning and second highest bid of the blocks delivered by mev-boost relays and the daily value left on the table, the highest bid of the slot minus the value the proposer received.",
+		XAxisTitle:   "",
+		YAxisTitle:   "ETH",
+		StackingMode: "false",
+		Type:         "line",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Winning Bid",
+				Data: winningSeries,
+			},
+			{
+				Name: "Second Bid",
+				Data: secondSeries,
+			},
+			{
+				Name: "Value Left on the Table",
+				Data: leftSeries,
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func builderMarketShareChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	epochsPerDay := utils.EpochsPerDay()
+	endDay := LatestEpoch() / epochsPerDay
+	startDay := uint64(0)
+	if endDay >= relayAuctionsChartDays {
+		startDay = endDay - relayAuctionsChartDays + 1
+	}
+	shares, err := db.GetDailyBuilderMarketShare(startDay, endDay, epochsPerDay*utils.Config.Chain.SlotsPerEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) == 0 {
+		return nil, nil
+	}
+
+	// the builders with the most blocks get a series of their own
+	blocks := map[string]uint64{}
+	for _, s := range shares {
+		blocks[string(s.BuilderPubkey)] += s.Blocks
+	}
+	builders := make([]string, 0, len(blocks))
+	for b := range blocks {
+		builders = append(builders, b)
+	}
+	sort.Slice(builders, func(i, j int) bool { return blocks[builders[i]] > blocks[builders[j]] })
+	if len(builders) > builderMarketShareChartBuilders {
+		builders = builders[:builderMarketShareChartBuilders]
+	}
+	shown := map[string]bool{}
+	for _, b := range builders {
+		shown[b] = true
+	}
+
+	builderData := map[string][][]float64{}
+	otherData := [][]float64{}
+	for _, s := range shares {
+		ts := float64(utils.EpochToTime(s.Day*epochsPerDay).Unix() * 1000)
+		if !shown[string(s.BuilderPubkey)] {
+			if len(otherData) == 0 || otherData[len(otherData)-1][0] != ts {
+				otherData = append(otherData, []float64{ts, 0})
+			}
+			otherData[len(otherData)-1][1] += s.Share * 100
+			continue
+		}
+		builderData[string(s.BuilderPubkey)] = append(builderData[string(s.BuilderPubkey)], []float64{ts, utils.RoundDecimals(s.Share*100, 2)})
+	}
+
+	series := make([]*types.GenericChartDataSeries, 0, len(builders)+1)
+	for _, b := range builders {
+		series = append(series, &types.GenericChartDataSeries{
+			Name: fmt.Sprintf("%#x…%x", []byte(b)[:2], []byte(b)[len(b)-2:]),
+			Data: builderData[b],
+		})
+	}
+	if len(otherData) > 0 {
+		for _, d := range otherData {
+			d[1] = utils.RoundDecimals(d[1], 2)
+		}
+		series = append(series, &types.GenericChartDataSeries{
+			Name: "Other",
+			Data: otherData,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:        "Builder Market Share",
+		Subtitle:     "Daily share of the builders of the blocks delivered by mev-boost relays.",
+		XAxisTitle:   "",
+		YAxisTitle:   "Market Share [%]",
+		StackingMode: "normal",
+		Type:         "column",
+		Series:       series,
+	}
+
+	return chartData, nil
+}
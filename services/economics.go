@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+)
+
+// EconomicsCharts are the names of the charts of the economics stats
+var EconomicsCharts = []string{"deposit_contract", "staking_ratio", "issuance"}
+
+// EconomicsChartData returns the chart of the daily economics stats by name, nil if no day has been exported yet
+func EconomicsChartData(name string) (*types.GenericChartData, error) {
+	stats, err := db.GetEconomicsStats(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	epochsPerDay := utils.EpochsPerDay()
+	// the stats of a day are taken at its end
+	dayTs := func(day uint64) float64 {
+		return float64(utils.EpochToTime((day+1)*epochsPerDay).Unix() * 1000)
+	}
+	gweiToEth := func(gwei float64) float64 {
+		return utils.RoundDecimals(gwei/1e9, 4)
+	}
+
+	switch name {
+	case "deposit_contract":
+		deposited := make([][]float64, 0, len(stats))
+		staked := make([][]float64, 0, len(stats))
+		for _, s := range stats {
+			deposited = append(deposited, []float64{dayTs(s.Day), gweiToEth(float64(s.DepositContractBalance))})
+			staked = append(staked, []float64{dayTs(s.Day), gweiToEth(float64(s.TotalEffectiveBalance))})
+		}
+		return &types.GenericChartData{
+			Title:        "Deposit Contract Balance",
+			Subtitle:     "Ether deposited into the deposit contract compared to the effective balance of the active validators.",
+			YAxisTitle:   "Ether",
+			StackingMode: "false",
+			Type:         "line",
+			Series: []*types.GenericChartDataSeries{
+				{Name: "Deposit Contract", Data: deposited},
+				{Name: "Effective Stake", Data: staked},
+			},
+		}, nil
+	case "staking_ratio":
+		ratio := make([][]float64, 0, len(stats))
+		for _, s := range stats {
+			if s.StakingRatio == nil {
+				continue
+			}
+			ratio = append(ratio, []float64{dayTs(s.Day), utils.RoundDecimals(*s.StakingRatio*100, 2)})
+		}
+		return &types.GenericChartData{
+			Title:        "Staking Ratio",
+			Subtitle:     "Effective balance of the active validators over the circulating supply of ether.",
+			YAxisTitle:   "Staking Ratio [%]",
+			StackingMode: "false",
+			Type:         "line",
+			Series: []*types.GenericChartDataSeries{
+				{Name: "Staking Ratio", Data: ratio},
+			},
+		}, nil
+	case "issuance":
+		issuance := make([][]float64, 0, len(stats))
+		burn := make([][]float64, 0, len(stats))
+		net := make([][]float64, 0, len(stats))
+		for _, s := range stats {
+			issuance = append(issuance, []float64{dayTs(s.Day), gweiToEth(float64(s.Issuance))})
+			burn = append(burn, []float64{dayTs(s.Day), gweiToEth(-float64(s.Burn))})
+			net = append(net, []float64{dayTs(s.Day), gweiToEth(float64(s.Issuance) - float64(s.Burn))})
+		}
+		return &types.GenericChartData{
+			Title:        "Daily Issuance and Burn",
+			Subtitle:     "Consensus layer issuance (balance change of all validators minus deposits) and the burned base fees of the canonical blocks per day.",
+			YAxisTitle:   "Ether",
+			StackingMode: "false",
+			Type:         "column",
+			Series: []*types.GenericChartDataSeries{
+				{Name: "Issuance", Data: issuance},
+				{Name: "Burn", Data: burn},
+				{Name: "Net Issuance", Data: net, Type: "line"},
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown economics chart %v", name)
+}
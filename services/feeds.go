@@ -0,0 +1,143 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const botFeedVersion = 1
+const botFeedLength = 100
+
+// BotFeed is a pre-rendered feed that can be served to bots without touching the database
+type BotFeed struct {
+	Body []byte
+	ETag string
+}
+
+var botFeeds = map[string]*BotFeed{}
+var botFeedsMux = &sync.RWMutex{}
+
+// GetBotFeed returns the latest rendered version of the feed with the given name or nil if it has not been rendered yet
+func GetBotFeed(name string) *BotFeed {
+	botFeedsMux.RLock()
+	defer botFeedsMux.RUnlock()
+	return botFeeds[name]
+}
+
+func botFeedsUpdater() {
+	for {
+		for name, fetch := range map[string]func() (interface{}, error){
+			"slashings": getSlashingsFeed,
+			"exits":     getExitsFeed,
+			"blocks":    getBlocksFeed,
+		} {
+			items, err := fetch()
+			if err != nil {
+				logger.Errorf("error retrieving %v feed: %v", name, err)
+				continue
+			}
+			err = storeBotFeed(name, items)
+			if err != nil {
+				logger.Errorf("error rendering %v feed: %v", name, err)
+			}
+		}
+		markCacheUpdated("bot_feeds")
+		time.Sleep(time.Second * time.Duration(utils.Config.Chain.SecondsPerSlot))
+	}
+}
+
+func storeBotFeed(name string, items interface{}) error {
+	// the etag only depends on the items so that clients do not refetch a feed that only got a new timestamp
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	etag := fmt.Sprintf("\"%x\"", sha256.Sum256(itemsJSON))
+
+	botFeedsMux.RLock()
+	existing := botFeeds[name]
+	botFeedsMux.RUnlock()
+	if existing != nil && existing.ETag == etag {
+		return nil
+	}
+
+	body, err := json.Marshal(&types.FeedResponse{
+		Version:   botFeedVersion,
+		UpdatedTs: time.Now().Unix(),
+		Items:     items,
+	})
+	if err != nil {
+		return err
+	}
+
+	botFeedsMux.Lock()
+	botFeeds[name] = &BotFeed{Body: body, ETag: etag}
+	botFeedsMux.Unlock()
+	return nil
+}
+
+func getSlashingsFeed() (interface{}, error) {
+	slashings, err := db.GetRecentSlashings(botFeedLength)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*types.FeedSlashing, 0, len(slashings))
+	for _, s := range slashings {
+		item := &types.FeedSlashing{
+			Slot:              s.Slot,
+			Epoch:             s.Epoch,
+			Ts:                utils.SlotToTime(s.Slot).Unix(),
+			Type:              "attester",
+			Proposer:          s.Proposer,
+			SlashedValidators: []uint64{},
+		}
+		if s.Type == "Proposer Violation" {
+			item.Type = "proposer"
+			if s.SlashedValidator != nil {
+				item.SlashedValidators = append(item.SlashedValidators, *s.SlashedValidator)
+			}
+		} else {
+			indices := make(map[int64]bool, len(s.Attestestation1Indices))
+			for _, i := range s.Attestestation1Indices {
+				indices[i] = true
+			}
+			for _, i := range s.Attestestation2Indices {
+				if indices[i] {
+					item.SlashedValidators = append(item.SlashedValidators, uint64(i))
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func getExitsFeed() (interface{}, error) {
+	exits, err := db.GetRecentVoluntaryExits(botFeedLength)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exits {
+		e.Ts = utils.SlotToTime(e.Slot).Unix()
+	}
+	return exits, nil
+}
+
+func getBlocksFeed() (interface{}, error) {
+	blocks, err := db.GetRecentBlocks(botFeedLength)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		b.Ts = utils.SlotToTime(b.Slot).Unix()
+		b.BlockRoot = fmt.Sprintf("0x%x", b.Root)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// forkBannerDuration is how long before its activation an upcoming fork is announced in the banner of the pages
+const forkBannerDuration = time.Hour * 24 * 14
+
+// forkOrder is the order of the known forks, forks of the beacon node spec that are not listed here are sorted by epoch
+var forkOrder = []string{"phase0", "altair", "bellatrix", "capella", "deneb", "electra"}
+
+var forks atomic.Value
+
+// ForkRewardWeights are the shares of the ideal reward of a validator per epoch (effective balance * BASE_REWARD_FACTOR
+// / sqrt(total active balance)) that are paid for attestations, sync committee participation and block proposals
+type ForkRewardWeights struct {
+	Attestation float64
+	Sync        float64
+	Proposal    float64
+}
+
+// phase0RewardWeights: source, target and head are rewarded with a base reward (a quarter of the ideal reward) each,
+// the inclusion delay reward is 7/8 of a base reward and the remaining 1/8 goes to the proposer
+var phase0RewardWeights = ForkRewardWeights{Attestation: 31.0 / 32, Sync: 0, Proposal: 1.0 / 32}
+
+// altairRewardWeights are the participation flag weights (14 + 26 + 14), the sync reward weight (2) and the proposer
+// weight (8) over the weight denominator (64), they did not change in the later forks
+var altairRewardWeights = ForkRewardWeights{Attestation: 54.0 / 64, Sync: 2.0 / 64, Proposal: 8.0 / 64}
+
+// forksUpdater regularly merges the forks of the config with the schedule of the beacon node
+func forksUpdater() {
+	for {
+		time.Sleep(time.Minute * 10)
+		updateForks()
+	}
+}
+
+func updateForks() {
+	epochs := map[string]uint64{"phase0": 0, "altair": utils.Config.Chain.AltairForkEpoch}
+	sources := map[string]string{"phase0": "config", "altair": "config"}
+	configured := map[string]*uint64{
+		"bellatrix": utils.Config.Chain.BellatrixForkEpoch,
+		"capella":   utils.Config.Chain.CapellaForkEpoch,
+		"deneb":     utils.Config.Chain.DenebForkEpoch,
+		"electra":   utils.Config.Chain.ElectraForkEpoch,
+	}
+	for name, epoch := range configured {
+		if epoch != nil {
+			epochs[name] = *epoch
+			sources[name] = "config"
+		}
+	}
+
+	if utils.Config.Frontend.BeaconNodeEndpoint != "" {
+		nodeEpochs, err := rpc.GetForkEpochs(utils.Config.Frontend.BeaconNodeEndpoint)
+		if err != nil {
+			logger.WithError(err).Errorf("error retrieving fork schedule of the beacon node, using the configured forks")
+		}
+		for name, epoch := range nodeEpochs {
+			if configuredEpoch, exists := epochs[name]; exists && sources[name] == "config" {
+				if configuredEpoch != epoch {
+					logger.Warnf("the configured epoch %v of the %v fork differs from the epoch %v of the beacon node", configuredEpoch, name, epoch)
+				}
+				continue
+			}
+			epochs[name] = epoch
+			sources[name] = "node"
+		}
+	}
+
+	order := make(map[string]int, len(forkOrder))
+	for i, name := range forkOrder {
+		order[name] = i
+	}
+	res := make([]*types.Fork, 0, len(epochs))
+	for name, epoch := range epochs {
+		res = append(res, &types.Fork{
+			Name:   name,
+			Epoch:  epoch,
+			Ts:     utils.EpochToTime(epoch).Unix(),
+			Source: sources[name],
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Epoch != res[j].Epoch {
+			return res[i].Epoch < res[j].Epoch
+		}
+		oi, iKnown := order[res[i].Name]
+		oj, jKnown := order[res[j].Name]
+		if iKnown && jKnown {
+			return oi < oj
+		}
+		return iKnown
+	})
+	forks.Store(res)
+}
+
+// Forks returns the forks of the network ordered by activation, Active is set for the forks activated at the latest
+// epoch
+func Forks() []*types.Fork {
+	stored, ok := forks.Load().([]*types.Fork)
+	if !ok {
+		return []*types.Fork{}
+	}
+	latestEpoch := LatestEpoch()
+	res := make([]*types.Fork, len(stored))
+	for i, f := range stored {
+		fork := *f
+		fork.Active = fork.Epoch <= latestEpoch
+		res[i] = &fork
+	}
+	return res
+}
+
+// ForkOfEpoch returns the fork that is active at the epoch
+func ForkOfEpoch(epoch uint64) *types.Fork {
+	var res *types.Fork
+	for _, f := range Forks() {
+		if f.Epoch > epoch {
+			break
+		}
+		res = f
+	}
+	if res == nil {
+		return &types.Fork{Name: "phase0", Source: "config", Ts: utils.EpochToTime(0).Unix()}
+	}
+	return res
+}
+
+// IsForkActive returns true if the fork with the name is known and activates at or before the epoch
+func IsForkActive(name string, epoch uint64) bool {
+	for _, f := range Forks() {
+		if f.Name == name {
+			return f.Epoch <= epoch
+		}
+	}
+	return false
+}
+
+// UpcomingFork returns the next fork if it activates within the banner duration, nil otherwise
+func UpcomingFork() *types.Fork {
+	for _, f := range Forks() {
+		if f.Active {
+			continue
+		}
+		if time.Until(time.Unix(f.Ts, 0)) > forkBannerDuration {
+			return nil
+		}
+		return f
+	}
+	return nil
+}
+
+// RewardWeightsOfEpoch returns how the rewards of a validator are split at the epoch, see ForkRewardWeights. The weights
+// are only used by estimates (income projection, validator set forecast, slashing simulator). The historical income is
+// computed from the balances in validator_stats, which already reflect the rewards of the fork active at the time.
+func RewardWeightsOfEpoch(epoch uint64) ForkRewardWeights {
+	if epoch < utils.Config.Chain.AltairForkEpoch {
+		return phase0RewardWeights
+	}
+	return altairRewardWeights
+}
+
+// ForkChartPlotLines returns the markers of the activated forks (except genesis) for the time axis of charts
+func ForkChartPlotLines() []*types.ChartPlotLine {
+	res := []*types.ChartPlotLine{}
+	for _, f := range Forks() {
+		if !f.Active || f.Epoch == 0 {
+			continue
+		}
+		res = append(res, &types.ChartPlotLine{
+			Value: f.Ts * 1000,
+			Label: f.Name,
+		})
+	}
+	return res
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const maxIndexerLag = 2
+const maxReplicationDelay = time.Minute
+
+// exporters that did not succeed within their max age are reported as unhealthy
+var exporterMaxAge = map[string]time.Duration{
+	"indexer":               time.Minute * 15,
+	"eth1_deposits":         time.Minute * 15,
+	"validator_performance": time.Hour * 3,
+}
+
+const defaultExporterMaxAge = time.Minute * 30
+
+// exporters that did not succeed within the retired age have been disabled or removed and are not reported
+const exporterRetiredAge = time.Hour * 24 * 7
+
+var cacheMaxAge = map[string]time.Duration{
+	"index_page": time.Minute,
+	"stats":      time.Minute * 5,
+	"charts":     time.Minute * 30,
+}
+
+var cacheUpdatedTs = map[string]time.Time{}
+var cacheUpdatedTsMux = &sync.RWMutex{}
+
+var healthStatus atomic.Value
+
+// markCacheUpdated records that the in-memory cache with the given name has been refreshed
+func markCacheUpdated(name string) {
+	cacheUpdatedTsMux.Lock()
+	defer cacheUpdatedTsMux.Unlock()
+	cacheUpdatedTs[name] = time.Now()
+}
+
+func healthStatusUpdater() {
+	for {
+		time.Sleep(time.Second * 10)
+		updateHealthStatus()
+	}
+}
+
+func updateHealthStatus() {
+	status, err := getHealthStatus()
+	if err != nil {
+		logger.Errorf("error retrieving health status: %v", err)
+		return
+	}
+	healthStatus.Store(status)
+}
+
+func getHealthStatus() (*types.HealthStatus, error) {
+	now := time.Now()
+	status := &types.HealthStatus{
+		Ts:           now,
+		IndexedEpoch: LatestEpoch(),
+		HeadEpoch:    uint64(utils.TimeToEpoch(now)),
+	}
+
+	if status.HeadEpoch > status.IndexedEpoch {
+		status.IndexerLag = status.HeadEpoch - status.IndexedEpoch
+	}
+	status.IndexerHealthy = status.IndexerLag <= maxIndexerLag
+
+	replicationDelay, err := db.GetReplicationDelay()
+	if err != nil {
+		return nil, err
+	}
+	status.ReplicationDelaySeconds = replicationDelay.Seconds()
+	status.ReplicationHealthy = replicationDelay <= maxReplicationDelay
+
+	exporterStatuses, err := db.GetExporterStatuses(now.Add(-exporterRetiredAge))
+	if err != nil {
+		return nil, err
+	}
+	status.Exporters = make([]*types.ExporterHealth, 0, len(exporterStatuses))
+	for _, s := range exporterStatuses {
+		maxAge, exists := exporterMaxAge[s.Name]
+		if !exists {
+			maxAge = defaultExporterMaxAge
+		}
+		status.Exporters = append(status.Exporters, &types.ExporterHealth{
+			Name:          s.Name,
+			LastSuccessTs: s.LastSuccessTs,
+			Healthy:       now.Sub(s.LastSuccessTs) <= maxAge,
+		})
+	}
+
+	cacheUpdatedTsMux.RLock()
+	status.Caches = make([]*types.CacheHealth, 0, len(cacheUpdatedTs))
+	for name, ts := range cacheUpdatedTs {
+		status.Caches = append(status.Caches, &types.CacheHealth{
+			Name:      name,
+			UpdatedTs: ts,
+			Healthy:   now.Sub(ts) <= cacheMaxAge[name],
+		})
+	}
+	cacheUpdatedTsMux.RUnlock()
+	sort.Slice(status.Caches, func(i, j int) bool {
+		return status.Caches[i].Name < status.Caches[j].Name
+	})
+
+	status.Healthy = status.IndexerHealthy && status.ReplicationHealthy
+	for _, e := range status.Exporters {
+		status.Healthy = status.Healthy && e.Healthy
+	}
+	for _, c := range status.Caches {
+		status.Healthy = status.Healthy && c.Healthy
+	}
+
+	return status, nil
+}
+
+// LatestHealthStatus returns the latest health status of the explorer
+func LatestHealthStatus() *types.HealthStatus {
+	status := healthStatus.Load()
+	if status == nil {
+		return &types.HealthStatus{Ts: time.Now()}
+	}
+	return status.(*types.HealthStatus)
+}
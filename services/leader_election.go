@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/utils"
+	"hash/fnv"
+	"time"
+)
+
+// leaderElectionInterval is the interval followers retry to acquire the lock and leaders check their lock connection
+const leaderElectionInterval = time.Second * 10
+
+// RunAsLeader runs f once this instance is the leader of the task, so that tasks like the exporters run on a single
+// instance while the frontend is served from all of them. It blocks until f returns.
+//
+// The leader holds a postgres session level advisory lock of the task on a dedicated connection, postgres releases it
+// if the instance or its connection dies and another instance takes over. The tasks can not be stopped, so the process
+// exits if the lock connection is lost to not run a task on two instances. f is run immediately if leader election is
+// disabled.
+func RunAsLeader(task string, f func()) {
+	if !utils.Config.LeaderElection.Enabled {
+		f()
+		return
+	}
+
+	conn := acquireLeaderLock(task)
+	defer conn.Close()
+	logger.Infof("acquired leadership of task %v", task)
+	metrics.LeaderElectionLeader.WithLabelValues(task).Set(1)
+
+	done := make(chan struct{})
+	go watchLeaderLock(task, conn, done)
+	f()
+	close(done)
+
+	_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", leaderLockKey(task))
+	if err != nil {
+		logger.Errorf("error releasing leadership of task %v: %v", task, err)
+	}
+	metrics.LeaderElectionLeader.WithLabelValues(task).Set(0)
+}
+
+// acquireLeaderLock blocks until the advisory lock of the task is acquired and returns the connection holding it
+func acquireLeaderLock(task string) *sql.Conn {
+	metrics.LeaderElectionLeader.WithLabelValues(task).Set(0)
+	key := leaderLockKey(task)
+	first := true
+	for {
+		if !first {
+			time.Sleep(leaderElectionInterval)
+		}
+		first = false
+
+		conn, err := db.DB.Conn(context.Background())
+		if err != nil {
+			logger.Errorf("error retrieving connection for leader election of task %v: %v", task, err)
+			continue
+		}
+		var acquired bool
+		err = conn.QueryRowContext(context.Background(), "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired)
+		if err != nil {
+			logger.Errorf("error acquiring leadership of task %v: %v", task, err)
+			conn.Close()
+			continue
+		}
+		if acquired {
+			return conn
+		}
+		conn.Close()
+		logger.Debugf("task %v is run by another instance, waiting for leadership", task)
+	}
+}
+
+// watchLeaderLock exits the process if the connection holding the lock of the task is lost until done is closed
+func watchLeaderLock(task string, conn *sql.Conn, done chan struct{}) {
+	t := time.NewTicker(leaderElectionInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(context.Background(), leaderElectionInterval)
+			err := conn.PingContext(ctx)
+			cancel()
+			if err != nil {
+				logger.Fatalf("lost leadership of task %v, the lock connection failed: %v", task, err)
+			}
+		}
+	}
+}
+
+// leaderLockKey returns the advisory lock key of the task, the network is part of it in case instances of several
+// networks share a database
+func leaderLockKey(task string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("leader-election/" + utils.Config.Chain.Network + "/" + task))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// a rule fires at most once per cooldown, even if the condition is still met
+const notificationRuleCooldown = time.Hour * 24
+
+// notificationRuleViolation is a validator for which the condition of a rule is met
+type notificationRuleViolation struct {
+	ValidatorIndex uint64  `db:"validatorindex"`
+	Value          float64 `db:"value"`
+}
+
+type customRuleNotification struct {
+	SubscriptionID uint64
+	UserID         uint64
+	Epoch          uint64
+	Rule           types.NotificationRule
+	Violations     []notificationRuleViolation
+	// Diagnoses hint at the cause of a low attestation effectiveness of the violating validators
+	Diagnoses map[uint64]*types.ValidatorDiagnosis
+}
+
+func (n *customRuleNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *customRuleNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *customRuleNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *customRuleNotification) GetEventName() types.EventName {
+	return types.CustomRuleEventName
+}
+
+func (n *customRuleNotification) GetInfo(includeUrl bool) string {
+	parts := make([]string, 0, len(n.Violations))
+	for _, v := range n.Violations {
+		part := ""
+		switch n.Rule.Metric {
+		case types.NotificationRuleMetricBalanceDecrease:
+			part = fmt.Sprintf("Validator %v: balance decreased by %.4f ETH over the last %v epochs.", v.ValidatorIndex, v.Value, n.Rule.Window)
+		case types.NotificationRuleMetricAttestationEffectiveness:
+			part = fmt.Sprintf("Validator %v: attestation effectiveness of at most %.2f%% on each of the last %v days.", v.ValidatorIndex, v.Value, n.Rule.Window)
+			if d, ok := n.Diagnoses[v.ValidatorIndex]; ok {
+				if hints := validatorDiagnosisHints(d); len(hints) > 0 {
+					part += " " + strings.Join(hints, " ")
+				}
+			}
+		case types.NotificationRuleMetricRPLCollateral:
+			part = fmt.Sprintf("Validator %v: rpl collateral of the rocketpool node is at %.2f%%.", v.ValidatorIndex, v.Value)
+		}
+		if includeUrl {
+			part += getUrlPart(v.ValidatorIndex)
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("Your rule \"%v\" was triggered:\n%v", n.Rule.Name, strings.Join(parts, "\n"))
+}
+
+func (n *customRuleNotification) GetTitle() string {
+	return fmt.Sprintf("Rule triggered: %v", n.Rule.Name)
+}
+
+func (n *customRuleNotification) GetEventFilter() string {
+	return fmt.Sprintf("rule:%d", n.Rule.ID)
+}
+
+// collectCustomRuleNotifications evaluates the custom notification rules of all users against the aggregated stats
+func collectCustomRuleNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification) error {
+	latestEpoch := LatestEpoch()
+	rules, err := db.GetDueNotificationRules(utils.GetNetwork(), notificationRuleCooldown)
+	if err != nil {
+		return err
+	}
+
+	// the last day of validator_stats the diagnoses of effectiveness rules are based on, retrieved once per run
+	var lastStatsDay *uint64
+
+	watchlists := map[uint64][][]byte{}
+	for _, rule := range rules {
+		pubkeys := [][]byte{rule.ValidatorPublickey}
+		if rule.ValidatorPublickey == nil {
+			var exists bool
+			pubkeys, exists = watchlists[rule.UserID]
+			if !exists {
+				pubkeys, err = db.GetWatchlistPublickeys(context.Background(), rule.UserID, utils.GetNetwork())
+				if err != nil {
+					return err
+				}
+				watchlists[rule.UserID] = pubkeys
+			}
+		}
+		if len(pubkeys) == 0 {
+			continue
+		}
+
+		violations, err := evaluateNotificationRule(&rule.NotificationRule, pubkeys, latestEpoch)
+		if err != nil {
+			logger.Errorf("error evaluating notification rule %v: %v", rule.ID, err)
+			continue
+		}
+		if len(violations) == 0 {
+			continue
+		}
+
+		n := &customRuleNotification{
+			SubscriptionID: rule.SubscriptionID,
+			UserID:         rule.UserID,
+			Epoch:          latestEpoch,
+			Rule:           rule.NotificationRule,
+			Violations:     violations,
+		}
+		if rule.Metric == types.NotificationRuleMetricAttestationEffectiveness {
+			if lastStatsDay == nil {
+				var day uint64
+				err = db.DB.Get(&day, "SELECT COALESCE(MAX(day), 0) FROM validator_stats")
+				if err != nil {
+					logger.Errorf("error retrieving last day of validator stats: %v", err)
+				} else {
+					lastStatsDay = &day
+				}
+			}
+			if lastStatsDay != nil {
+				n.Diagnoses = diagnoseEffectivenessViolations(&rule.NotificationRule, violations, *lastStatsDay)
+			}
+		}
+		if _, exists := notificationsByUserID[rule.UserID]; !exists {
+			notificationsByUserID[rule.UserID] = map[types.EventName][]types.Notification{}
+		}
+		if _, exists := notificationsByUserID[rule.UserID][n.GetEventName()]; !exists {
+			notificationsByUserID[rule.UserID][n.GetEventName()] = []types.Notification{}
+		}
+		notificationsByUserID[rule.UserID][n.GetEventName()] = append(notificationsByUserID[rule.UserID][n.GetEventName()], n)
+	}
+
+	return nil
+}
+
+// evaluateNotificationRule returns the validators for which the condition of the rule is met
+func evaluateNotificationRule(rule *types.NotificationRule, pubkeys [][]byte, latestEpoch uint64) ([]notificationRuleViolation, error) {
+	violations := []notificationRuleViolation{}
+	var err error
+
+	switch rule.Metric {
+	case types.NotificationRuleMetricBalanceDecrease:
+		if latestEpoch < rule.Window+1 {
+			return violations, nil
+		}
+		// the balances of the latest epoch might not be exported yet
+		endEpoch := latestEpoch - 1
+		startEpoch := endEpoch - rule.Window
+		err = db.DB.Select(&violations, `
+			SELECT v.validatorindex, (vb_start.balance - vb_end.balance)::float / 1e9 AS value
+			FROM validators v
+			INNER JOIN validator_balances_p vb_start ON vb_start.validatorindex = v.validatorindex AND vb_start.week = $1 AND vb_start.epoch = $2
+			INNER JOIN validator_balances_p vb_end ON vb_end.validatorindex = v.validatorindex AND vb_end.week = $3 AND vb_end.epoch = $4
+			WHERE v.pubkey = ANY($5) AND vb_start.balance - vb_end.balance > $6
+			ORDER BY v.validatorindex`,
+			startEpoch/1575, startEpoch, endEpoch/1575, endEpoch, pq.ByteaArray(pubkeys), rule.Threshold*1e9)
+	case types.NotificationRuleMetricAttestationEffectiveness:
+		// validator_stats only contains completed days, the rule fires if even the best day of the window (the fewest
+		// missed attestations) is below the threshold
+		epochsPerDay := float64(24*60*60) / float64(utils.Config.Chain.SecondsPerSlot*utils.Config.Chain.SlotsPerEpoch)
+		err = db.DB.Select(&violations, `
+			SELECT v.validatorindex, (1 - MIN(COALESCE(vs.missed_attestations, 0))::float / $1) * 100 AS value
+			FROM validators v
+			INNER JOIN validator_stats vs ON vs.validatorindex = v.validatorindex
+			WHERE v.pubkey = ANY($2) AND vs.day > (SELECT COALESCE(MAX(day), 0) FROM validator_stats) - $3
+			GROUP BY v.validatorindex
+			HAVING COUNT(*) = $3 AND (1 - MIN(COALESCE(vs.missed_attestations, 0))::float / $1) * 100 < $4
+			ORDER BY v.validatorindex`,
+			epochsPerDay, pq.ByteaArray(pubkeys), rule.Window, rule.Threshold)
+	case types.NotificationRuleMetricRPLCollateral:
+		// the minimum rpl stake is 10% of the borrowed ETH
+		err = db.DB.Select(&violations, `
+			SELECT v.validatorindex, rpln.rpl_stake::float / rpln.min_rpl_stake::float * 10 AS value
+			FROM validators v
+			INNER JOIN rocketpool_minipools rplm ON rplm.pubkey = v.pubkey
+			INNER JOIN rocketpool_nodes rpln ON rpln.address = rplm.node_address
+			WHERE v.pubkey = ANY($1) AND rpln.min_rpl_stake > 0 AND rpln.rpl_stake::float / rpln.min_rpl_stake::float * 10 < $2
+			ORDER BY v.validatorindex`,
+			pq.ByteaArray(pubkeys), rule.Threshold)
+	default:
+		return nil, fmt.Errorf("unknown notification rule metric %v", rule.Metric)
+	}
+
+	return violations, err
+}
@@ -1,9 +1,11 @@
 package services
 
 import (
+	"bytes"
 	"encoding/hex"
 	"eth2-exporter/db"
 	ethclients "eth2-exporter/ethClients"
+	"eth2-exporter/events"
 	"eth2-exporter/mail"
 	"eth2-exporter/metrics"
 	"eth2-exporter/notify"
@@ -20,7 +22,15 @@ import (
 	"github.com/lib/pq"
 )
 
+// notificationEventsDebounce is the time the sender waits after the first chain event so that all events of an epoch are handled in one run
+const notificationEventsDebounce = time.Second * 5
+
 func notificationsSender() {
+	chainEvents, err := events.Subscribe()
+	if err != nil {
+		logger.WithError(err).Errorf("error subscribing to chain events, falling back to periodic notifications")
+	}
+
 	for {
 		// check if the explorer is not too far behind, if we set this value to close (10m) it could potentially never send any notifications
 		// if IsSyncing() {
@@ -53,7 +63,44 @@ func notificationsSender() {
 
 		logger.WithField("notifications", len(notifications)).WithField("duration", time.Since(start)).Info("notifications completed")
 		metrics.TaskDuration.WithLabelValues("service_notifications").Observe(time.Since(start).Seconds())
-		time.Sleep(time.Second * 120)
+		waitForChainEvents(chainEvents, time.Second*120)
+	}
+}
+
+// waitForChainEvents returns as soon as the indexer published a chain event or after timeout, the periodic scan
+// remains as fallback for events the bus lost or that are not published by the indexer
+func waitForChainEvents(chainEvents <-chan *types.ChainEvent, timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for waiting := true; waiting; {
+		select {
+		case <-timer.C:
+			return
+		case e, ok := <-chainEvents:
+			if !ok {
+				<-timer.C
+				return
+			}
+			// head events are published every slot and do not carry any notification data
+			if e.Name == types.ChainEventHead {
+				continue
+			}
+			logger.Infof("received %v event for epoch %v, collecting notifications", e.Name, e.Epoch)
+			waiting = false
+		}
+	}
+
+	time.Sleep(notificationEventsDebounce)
+	for {
+		select {
+		case _, ok := <-chainEvents:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
 	}
 }
 
@@ -88,6 +135,27 @@ func collectNotifications() map[uint64]map[types.EventName][]types.Notification
 	}
 	logger.Infof("Collecting block proposal missed notifications took: %v\n", time.Since(start))
 
+	// Fee recipient mismatches of proposed blocks
+	err = collectFeeRecipientNotifications(notificationsByUserID)
+	if err != nil {
+		logger.Errorf("error collecting validator_fee_recipient_mismatch notifications: %v", err)
+	}
+	logger.Infof("Collecting fee recipient notifications took: %v\n", time.Since(start))
+
+	// Rocketpool minipools not paying their node's fee distributor or the smoothing pool
+	err = collectRocketpoolFeeRecipientViolationNotifications(notificationsByUserID)
+	if err != nil {
+		logger.Errorf("error collecting rocketpool_fee_recipient_violation notifications: %v", err)
+	}
+	logger.Infof("Collecting rocketpool fee recipient violation notifications took: %v\n", time.Since(start))
+
+	// Full withdrawals of exited validators
+	err = collectFullWithdrawalNotifications(notificationsByUserID)
+	if err != nil {
+		logger.Errorf("error collecting validator_full_withdrawal notifications: %v", err)
+	}
+	logger.Infof("Collecting full withdrawal notifications took: %v\n", time.Since(start))
+
 	// Missed attestations
 	err = collectAttestationNotifications(notificationsByUserID, 0, types.ValidatorMissedAttestationEventName)
 	if err != nil {
@@ -101,6 +169,18 @@ func collectNotifications() map[uint64]map[types.EventName][]types.Notification
 		logger.Errorf("error collecting tax report notifications: %v", err)
 	}
 
+	// Custom user rules
+	err = collectCustomRuleNotifications(notificationsByUserID)
+	if err != nil {
+		logger.Errorf("error collecting custom rule notifications: %v", err)
+	}
+
+	// New deposits of tracked eth1-addresses
+	err = collectEth1DepositorNotifications(notificationsByUserID)
+	if err != nil {
+		logger.Errorf("error collecting eth1-depositor notifications: %v", err)
+	}
+
 	return notificationsByUserID
 }
 
@@ -148,8 +228,8 @@ func collectUserDbNotifications() map[uint64]map[types.EventName][]types.Notific
 }
 
 func sendNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB) {
-	sendEmailNotifications(notificationsByUserID, useDB)
-	sendPushNotifications(notificationsByUserID, useDB)
+	sendEmailNotifications(filterNotifications(notificationsByUserID, types.EmailNotificationChannel, useDB), useDB)
+	sendPushNotifications(filterNotifications(notificationsByUserID, types.PushNotificationChannel, useDB), useDB)
 	// sendWebhookNotifications(notificationsByUserID)
 }
 
@@ -179,28 +259,18 @@ func sendPushNotifications(notificationsByUserID map[uint64]map[types.EventName]
 			continue
 		}
 
-		go func(userTokens []string, userNotifications map[types.EventName][]types.Notification) {
+		go func(userID uint64, userTokens []string, userNotifications map[types.EventName][]types.Notification) {
 			var batch []*messaging.Message
+			var logEntries []*types.NotificationLogEntry
 			sentSubsByEpoch := map[uint64][]uint64{}
 
 			for _, ns := range userNotifications {
 				for _, n := range ns {
 					for _, userToken := range userTokens {
-
-						notification := new(messaging.Notification)
-						notification.Title = fmt.Sprintf("%s%s", getNetwork(), n.GetTitle())
-						notification.Body = n.GetInfo(false)
-
-						message := new(messaging.Message)
-						message.Notification = notification
-						message.Token = userToken
-
-						message.APNS = new(messaging.APNSConfig)
-						message.APNS.Payload = new(messaging.APNSPayload)
-						message.APNS.Payload.Aps = new(messaging.Aps)
-						message.APNS.Payload.Aps.Sound = "default"
-
-						batch = append(batch, message)
+						title := fmt.Sprintf("%s%s", getNetwork(), n.GetTitle())
+						body := n.GetInfo(false)
+						batch = append(batch, newPushMessage(title, body, userToken))
+						logEntries = append(logEntries, newNotificationLogEntry(userID, types.PushNotificationChannel, userToken, []string{string(n.GetEventName())}, title, body))
 					}
 
 					e := n.GetEpoch()
@@ -212,7 +282,11 @@ func sendPushNotifications(notificationsByUserID map[uint64]map[types.EventName]
 				}
 			}
 
-			_, err := notify.SendPushBatch(batch)
+			result, err := notify.SendPushBatch(batch)
+			for i, entry := range logEntries {
+				setPushNotificationLogResult(entry, result, i, err)
+			}
+			saveNotificationsLog(logEntries...)
 			if err != nil {
 				logger.Errorf("firebase batch job failed: %v", err)
 				return
@@ -224,7 +298,7 @@ func sendPushNotifications(notificationsByUserID map[uint64]map[types.EventName]
 					logger.Errorf("error updating sent-time of sent notifications: %v", err)
 				}
 			}
-		}(userTokens, userNotifications)
+		}(userID, userTokens, userNotifications)
 	}
 
 }
@@ -239,6 +313,11 @@ func sendEmailNotifications(notificationsByUserID map[uint64]map[types.EventName
 		logger.Errorf("error when sending eamil-notificaitons: could not get emails: %v", err)
 		return
 	}
+	languagesByUserID, err := db.GetUserLanguagesByIds(userIDs)
+	if err != nil {
+		logger.Errorf("error when sending email-notifications: could not get languages, using the default language: %v", err)
+		languagesByUserID = map[uint64]string{}
+	}
 
 	for userID, userNotifications := range notificationsByUserID {
 		userEmail, exists := emailsByUserID[userID]
@@ -246,12 +325,17 @@ func sendEmailNotifications(notificationsByUserID map[uint64]map[types.EventName
 			logger.Errorf("error when sending email-notification: could not find email for user %v", userID)
 			continue
 		}
-		go func(userEmail string, userNotifications map[types.EventName][]types.Notification) {
+		go func(userID uint64, userEmail, userLanguage string, userNotifications map[types.EventName][]types.Notification) {
 			sentSubsByEpoch := map[uint64][]uint64{}
 			subject := fmt.Sprintf("%s: Notification", utils.Config.Frontend.SiteDomain)
 			msg := ""
 			attachments := []types.EmailAttachment{}
+			eventNames := []string{}
+			sections := []*mail.NotificationSection{}
 			for event, ns := range userNotifications {
+				eventNames = append(eventNames, string(event))
+				section := &mail.NotificationSection{EventName: event}
+				sections = append(sections, section)
 				if len(msg) > 0 {
 					msg += "\n"
 				}
@@ -262,6 +346,7 @@ func sendEmailNotifications(notificationsByUserID map[uint64]map[types.EventName
 				msg += fmt.Sprintf("%s\n====\n\n", event_title)
 				for _, n := range ns {
 					msg += fmt.Sprintf("%s\n", n.GetInfo(true))
+					section.Notifications = append(section.Notifications, n.GetInfo(true))
 					e := n.GetEpoch()
 					if _, exists := sentSubsByEpoch[e]; !exists {
 						sentSubsByEpoch[e] = []uint64{n.GetSubscriptionID()}
@@ -279,8 +364,18 @@ func sendEmailNotifications(notificationsByUserID map[uint64]map[types.EventName
 			}
 			msg += fmt.Sprintf("\nBest regards\n\n%s", utils.Config.Frontend.SiteDomain)
 
-			err := mail.SendMailRateLimited(userEmail, subject, msg, attachments)
+			html, err := mail.RenderNotificationMail(userLanguage, sections)
 			if err != nil {
+				// the plain text version is still sent
+				logger.Errorf("error rendering notification-email: %v", err)
+			}
+
+			response, err := mail.SendMailRateLimited(&mail.Message{To: userEmail, Subject: subject, Text: msg, HTML: html, Attachments: attachments})
+			logEntry := newNotificationLogEntry(userID, types.EmailNotificationChannel, userEmail, eventNames, subject, msg)
+			setNotificationLogResult(logEntry, response, err)
+			saveNotificationsLog(logEntry)
+			// suppressed addresses are not retried, the notifications are marked as sent
+			if err != nil && err != mail.ErrSuppressed {
 				logger.Errorf("error sending notification-email: %v", err)
 				return
 			}
@@ -291,7 +386,7 @@ func sendEmailNotifications(notificationsByUserID map[uint64]map[types.EventName
 					logger.Errorf("error updating sent-time of sent notifications: %v", err)
 				}
 			}
-		}(userEmail, userNotifications)
+		}(userID, userEmail, languagesByUserID[userID], userNotifications)
 	}
 }
 
@@ -551,6 +646,441 @@ func (n *validatorProposalNotification) GetEventFilter() string {
 	return n.EventFilter
 }
 
+type validatorFeeRecipientNotification struct {
+	SubscriptionID       uint64
+	ValidatorIndex       uint64
+	Epoch                uint64
+	Slot                 uint64
+	FeeRecipient         []byte
+	ExpectedFeeRecipient []byte
+	EventFilter          string
+}
+
+func (n *validatorFeeRecipientNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *validatorFeeRecipientNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *validatorFeeRecipientNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *validatorFeeRecipientNotification) GetEventName() types.EventName {
+	return types.ValidatorFeeRecipientMismatchEventName
+}
+
+func (n *validatorFeeRecipientNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`Validator %v proposed block %v with the fee recipient 0x%x instead of the expected 0x%x.`, n.ValidatorIndex, n.Slot, n.FeeRecipient, n.ExpectedFeeRecipient)
+	if includeUrl {
+		return generalPart + getUrlPart(n.ValidatorIndex)
+	}
+	return generalPart
+}
+
+func (n *validatorFeeRecipientNotification) GetTitle() string {
+	return "Unexpected Fee Recipient"
+}
+
+func (n *validatorFeeRecipientNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+// collectFeeRecipientNotifications creates notifications for recently proposed blocks whose fee recipient does not match the one the subscriber expects.
+// Blocks of Rocketpool minipools paying to the smoothing pool are accepted if the subscriber allows it.
+func collectFeeRecipientNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification) error {
+	latestEpoch := LatestEpoch()
+
+	pubkeys, subMap, err := db.GetSubsForEventFilter(types.ValidatorFeeRecipientMismatchEventName)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for fee recipient mismatches %w", err)
+	}
+	if len(pubkeys) == 0 {
+		return nil
+	}
+
+	userIDs := []uint64{}
+	for _, subs := range subMap {
+		for _, sub := range subs {
+			if sub.UserID != nil {
+				userIDs = append(userIDs, *sub.UserID)
+			}
+		}
+	}
+	feeRecipients, err := db.GetUsersFeeRecipients(userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting expected fee recipients: %w", err)
+	}
+
+	var smoothingPoolAddress []byte
+	if utils.Config.RocketpoolExporter.SmoothingPoolAddress != "" {
+		smoothingPoolAddress, err = hex.DecodeString(strings.TrimPrefix(utils.Config.RocketpoolExporter.SmoothingPoolAddress, "0x"))
+		if err != nil {
+			return fmt.Errorf("error decoding rocketpool smoothing pool address: %w", err)
+		}
+	}
+
+	type dbResult struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		Epoch          uint64 `db:"epoch"`
+		Slot           uint64 `db:"slot"`
+		FeeRecipient   []byte `db:"exec_fee_recipient"`
+		IsRocketpool   bool   `db:"is_rocketpool"`
+		EventFilter    []byte `db:"pubkey"`
+	}
+
+	events := make([]dbResult, 0)
+	batchSize := 5000
+	dataLen := len(pubkeys)
+	for i := 0; i < dataLen; i += batchSize {
+		start := i
+		end := i + batchSize
+
+		if dataLen < end {
+			end = dataLen
+		}
+
+		var partial []dbResult
+		err = db.DB.Select(&partial, `
+			SELECT
+				v.validatorindex,
+				b.epoch,
+				b.slot,
+				b.exec_fee_recipient,
+				EXISTS (SELECT 1 FROM rocketpool_minipools rplm WHERE rplm.pubkey = v.pubkey) AS is_rocketpool,
+				v.pubkey
+			FROM validators v
+			INNER JOIN blocks b ON b.proposer = v.validatorindex AND b.epoch >= ($1 - 5)
+			WHERE v.pubkey = ANY($2) AND b.status = '1' AND b.exec_fee_recipient IS NOT NULL`, latestEpoch, pq.ByteaArray(pubkeys[start:end]))
+		if err != nil {
+			return err
+		}
+		events = append(events, partial...)
+	}
+
+	for _, event := range events {
+		subscribers, ok := subMap[hex.EncodeToString(event.EventFilter)]
+		if !ok {
+			return fmt.Errorf("error event returned that does not exist: %x", event.EventFilter)
+		}
+		for _, sub := range subscribers {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId or subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			if sub.LastEpoch != nil {
+				lastSentEpoch := *sub.LastEpoch
+				if lastSentEpoch >= event.Epoch || event.Epoch < sub.CreatedEpoch {
+					continue
+				}
+			}
+
+			expected, ok := feeRecipients[*sub.UserID]
+			if !ok || bytes.Equal(expected.Address, event.FeeRecipient) {
+				continue
+			}
+			if expected.AllowSmoothingPool && event.IsRocketpool && smoothingPoolAddress != nil && bytes.Equal(smoothingPoolAddress, event.FeeRecipient) {
+				continue
+			}
+
+			n := &validatorFeeRecipientNotification{
+				SubscriptionID:       *sub.ID,
+				ValidatorIndex:       event.ValidatorIndex,
+				Epoch:                event.Epoch,
+				Slot:                 event.Slot,
+				FeeRecipient:         event.FeeRecipient,
+				ExpectedFeeRecipient: expected.Address,
+				EventFilter:          hex.EncodeToString(event.EventFilter),
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			if _, exists := notificationsByUserID[*sub.UserID][n.GetEventName()]; !exists {
+				notificationsByUserID[*sub.UserID][n.GetEventName()] = []types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+		}
+	}
+
+	return nil
+}
+
+type rocketpoolFeeRecipientViolationNotification struct {
+	SubscriptionID       uint64
+	ValidatorIndex       uint64
+	Epoch                uint64
+	Slot                 uint64
+	NodeAddress          []byte
+	FeeRecipient         []byte
+	ExpectedFeeRecipient []byte
+	SmoothingPoolOptedIn bool
+	EventFilter          string
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetEventName() types.EventName {
+	return types.RocketpoolFeeRecipientViolationEventName
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetInfo(includeUrl bool) string {
+	expected := "fee distributor"
+	if n.SmoothingPoolOptedIn {
+		expected = "smoothing pool"
+	}
+	generalPart := fmt.Sprintf(`Rocketpool minipool validator %v of node 0x%x proposed block %v with the fee recipient 0x%x instead of the %v 0x%x of the node.`, n.ValidatorIndex, n.NodeAddress, n.Slot, n.FeeRecipient, expected, n.ExpectedFeeRecipient)
+	if includeUrl {
+		return generalPart + getUrlPart(n.ValidatorIndex)
+	}
+	return generalPart
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetTitle() string {
+	return "Rocketpool Fee Recipient Violation"
+}
+
+func (n *rocketpoolFeeRecipientViolationNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+// collectRocketpoolFeeRecipientViolationNotifications creates notifications for the fee recipient violations the
+// rocketpool exporter detected in recently proposed blocks of minipools
+func collectRocketpoolFeeRecipientViolationNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification) error {
+	latestEpoch := LatestEpoch()
+
+	pubkeys, subMap, err := db.GetSubsForEventFilter(types.RocketpoolFeeRecipientViolationEventName)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for rocketpool fee recipient violations %w", err)
+	}
+	if len(pubkeys) == 0 {
+		return nil
+	}
+
+	type dbResult struct {
+		ValidatorIndex       uint64 `db:"validatorindex"`
+		Epoch                uint64 `db:"epoch"`
+		Slot                 uint64 `db:"slot"`
+		NodeAddress          []byte `db:"node_address"`
+		FeeRecipient         []byte `db:"fee_recipient"`
+		ExpectedFeeRecipient []byte `db:"expected_fee_recipient"`
+		SmoothingPoolOptedIn bool   `db:"smoothing_pool_opted_in"`
+		EventFilter          []byte `db:"pubkey"`
+	}
+
+	events := make([]dbResult, 0)
+	batchSize := 5000
+	dataLen := len(pubkeys)
+	for i := 0; i < dataLen; i += batchSize {
+		start := i
+		end := i + batchSize
+
+		if dataLen < end {
+			end = dataLen
+		}
+
+		var partial []dbResult
+		err = db.DB.Select(&partial, `
+			SELECT
+				rfv.validatorindex,
+				rfv.epoch,
+				rfv.slot,
+				rfv.node_address,
+				rfv.fee_recipient,
+				rfv.expected_fee_recipient,
+				rfv.smoothing_pool_opted_in,
+				v.pubkey
+			FROM rocketpool_fee_recipient_violations rfv
+			INNER JOIN validators v ON v.validatorindex = rfv.validatorindex
+			WHERE v.pubkey = ANY($2) AND rfv.epoch >= ($1 - 5)`, latestEpoch, pq.ByteaArray(pubkeys[start:end]))
+		if err != nil {
+			return err
+		}
+		events = append(events, partial...)
+	}
+
+	for _, event := range events {
+		subscribers, ok := subMap[hex.EncodeToString(event.EventFilter)]
+		if !ok {
+			return fmt.Errorf("error event returned that does not exist: %x", event.EventFilter)
+		}
+		for _, sub := range subscribers {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId or subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			if sub.LastEpoch != nil {
+				lastSentEpoch := *sub.LastEpoch
+				if lastSentEpoch >= event.Epoch || event.Epoch < sub.CreatedEpoch {
+					continue
+				}
+			}
+
+			n := &rocketpoolFeeRecipientViolationNotification{
+				SubscriptionID:       *sub.ID,
+				ValidatorIndex:       event.ValidatorIndex,
+				Epoch:                event.Epoch,
+				Slot:                 event.Slot,
+				NodeAddress:          event.NodeAddress,
+				FeeRecipient:         event.FeeRecipient,
+				ExpectedFeeRecipient: event.ExpectedFeeRecipient,
+				SmoothingPoolOptedIn: event.SmoothingPoolOptedIn,
+				EventFilter:          hex.EncodeToString(event.EventFilter),
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			if _, exists := notificationsByUserID[*sub.UserID][n.GetEventName()]; !exists {
+				notificationsByUserID[*sub.UserID][n.GetEventName()] = []types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+		}
+	}
+
+	return nil
+}
+
+type validatorFullWithdrawalNotification struct {
+	SubscriptionID uint64
+	ValidatorIndex uint64
+	Epoch          uint64
+	Slot           uint64
+	Amount         uint64
+	Address        []byte
+	EventFilter    string
+}
+
+func (n *validatorFullWithdrawalNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *validatorFullWithdrawalNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *validatorFullWithdrawalNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *validatorFullWithdrawalNotification) GetEventName() types.EventName {
+	return types.ValidatorFullWithdrawalEventName
+}
+
+func (n *validatorFullWithdrawalNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`The balance of %.9f ETH of the exited validator %v has been withdrawn to 0x%x in slot %v.`, float64(n.Amount)/1e9, n.ValidatorIndex, n.Address, n.Slot)
+	if includeUrl {
+		return generalPart + getUrlPart(n.ValidatorIndex)
+	}
+	return generalPart
+}
+
+func (n *validatorFullWithdrawalNotification) GetTitle() string {
+	return "Validator Withdrawal"
+}
+
+func (n *validatorFullWithdrawalNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+// collectFullWithdrawalNotifications creates notifications for the full withdrawals of exited validators that were
+// included recently, withdrawals are full once the validator is withdrawable
+func collectFullWithdrawalNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification) error {
+	latestEpoch := LatestEpoch()
+
+	pubkeys, subMap, err := db.GetSubsForEventFilter(types.ValidatorFullWithdrawalEventName)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for full withdrawals %w", err)
+	}
+	if len(pubkeys) == 0 {
+		return nil
+	}
+
+	type dbResult struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		Epoch          uint64 `db:"epoch"`
+		Slot           uint64 `db:"slot"`
+		Amount         uint64 `db:"amount"`
+		Address        []byte `db:"address"`
+		EventFilter    []byte `db:"pubkey"`
+	}
+
+	events := make([]dbResult, 0)
+	batchSize := 5000
+	dataLen := len(pubkeys)
+	for i := 0; i < dataLen; i += batchSize {
+		start := i
+		end := i + batchSize
+
+		if dataLen < end {
+			end = dataLen
+		}
+
+		var partial []dbResult
+		err = db.DB.Select(&partial, `
+			SELECT
+				v.validatorindex,
+				b.epoch,
+				b.slot,
+				w.amount,
+				w.address,
+				v.pubkey
+			FROM validators v
+			INNER JOIN blocks_withdrawals w ON w.validatorindex = v.validatorindex AND w.block_slot >= ($1 - 5) * $3 AND w.block_slot >= v.withdrawableepoch * $3
+			INNER JOIN blocks b ON b.slot = w.block_slot AND b.blockroot = w.block_root AND b.status = '1'
+			WHERE v.pubkey = ANY($2)`, latestEpoch, pq.ByteaArray(pubkeys[start:end]), utils.Config.Chain.SlotsPerEpoch)
+		if err != nil {
+			return err
+		}
+		events = append(events, partial...)
+	}
+
+	for _, event := range events {
+		subscribers, ok := subMap[hex.EncodeToString(event.EventFilter)]
+		if !ok {
+			return fmt.Errorf("error event returned that does not exist: %x", event.EventFilter)
+		}
+		for _, sub := range subscribers {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId or subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			if sub.LastEpoch != nil {
+				lastSentEpoch := *sub.LastEpoch
+				if lastSentEpoch >= event.Epoch || event.Epoch < sub.CreatedEpoch {
+					continue
+				}
+			}
+
+			n := &validatorFullWithdrawalNotification{
+				SubscriptionID: *sub.ID,
+				ValidatorIndex: event.ValidatorIndex,
+				Epoch:          event.Epoch,
+				Slot:           event.Slot,
+				Amount:         event.Amount,
+				Address:        event.Address,
+				EventFilter:    hex.EncodeToString(event.EventFilter),
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			if _, exists := notificationsByUserID[*sub.UserID][n.GetEventName()]; !exists {
+				notificationsByUserID[*sub.UserID][n.GetEventName()] = []types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+		}
+	}
+
+	return nil
+}
+
 func collectAttestationNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, status uint64, eventName types.EventName) error {
 	latestEpoch := LatestEpoch()
 	latestSlot := LatestSlot()
@@ -1334,3 +1864,120 @@ func collectNetworkNotifications(notificationsByUserID map[uint64]map[types.Even
 
 	return nil
 }
+
+type eth1DepositorNotification struct {
+	SubscriptionID uint64
+	UserID         uint64
+	Epoch          uint64
+	Address        []byte
+	DepositCount   uint64
+	Amount         uint64
+	EventFilter    string
+}
+
+func (n *eth1DepositorNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *eth1DepositorNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *eth1DepositorNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *eth1DepositorNotification) GetEventName() types.EventName {
+	return types.Eth1DepositorDepositEventName
+}
+
+func (n *eth1DepositorNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`The eth1-address 0x%x made %v new deposits with a total of %.4f ETH.`, n.Address, n.DepositCount, float64(n.Amount)/1e9)
+	if includeUrl {
+		return generalPart + fmt.Sprintf(` For more information visit: https://%s/depositleaderboard?q=0x%x`, utils.Config.Frontend.SiteDomain, n.Address)
+	}
+	return generalPart
+}
+
+func (n *eth1DepositorNotification) GetTitle() string {
+	return "New deposits of a tracked address"
+}
+
+func (n *eth1DepositorNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+// collectEth1DepositorNotifications creates notifications for subscriptions of eth1-addresses that made deposits since the last notification was sent
+func collectEth1DepositorNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification) error {
+	var subscriptions []struct {
+		SubscriptionID uint64    `db:"id"`
+		UserID         uint64    `db:"user_id"`
+		EventFilter    string    `db:"event_filter"`
+		Since          time.Time `db:"since"`
+	}
+	err := db.FrontendDB.Select(&subscriptions, `
+		SELECT id, user_id, event_filter, COALESCE(last_sent_ts, created_ts) AS since
+		FROM users_subscriptions
+		WHERE event_name = $1`,
+		utils.GetNetwork()+":"+string(types.Eth1DepositorDepositEventName))
+	if err != nil {
+		return fmt.Errorf("error querying subscriptions, err: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	addresses := make([][]byte, 0, len(subscriptions))
+	since := time.Now()
+	for _, sub := range subscriptions {
+		address, err := hex.DecodeString(sub.EventFilter)
+		if err != nil || len(address) != 20 {
+			continue
+		}
+		addresses = append(addresses, address)
+		if sub.Since.Before(since) {
+			since = sub.Since
+		}
+	}
+
+	deposits, err := db.GetEth1DepositsSince(addresses, since)
+	if err != nil {
+		return fmt.Errorf("error getting eth1-deposits from database, err: %w", err)
+	}
+	depositsByAddress := map[string][]*db.Eth1AddressDeposit{}
+	for _, d := range deposits {
+		address := hex.EncodeToString(d.FromAddress)
+		depositsByAddress[address] = append(depositsByAddress[address], d)
+	}
+
+	latestEpoch := LatestEpoch()
+	for _, sub := range subscriptions {
+		n := &eth1DepositorNotification{
+			SubscriptionID: sub.SubscriptionID,
+			UserID:         sub.UserID,
+			Epoch:          latestEpoch,
+			EventFilter:    sub.EventFilter,
+		}
+		for _, d := range depositsByAddress[strings.ToLower(sub.EventFilter)] {
+			if !d.BlockTs.After(sub.Since) {
+				continue
+			}
+			n.Address = d.FromAddress
+			n.DepositCount++
+			n.Amount += d.Amount
+		}
+		if n.DepositCount == 0 {
+			continue
+		}
+
+		if _, exists := notificationsByUserID[sub.UserID]; !exists {
+			notificationsByUserID[sub.UserID] = map[types.EventName][]types.Notification{}
+		}
+		if _, exists := notificationsByUserID[sub.UserID][n.GetEventName()]; !exists {
+			notificationsByUserID[sub.UserID][n.GetEventName()] = []types.Notification{}
+		}
+		notificationsByUserID[sub.UserID][n.GetEventName()] = append(notificationsByUserID[sub.UserID][n.GetEventName()], n)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var defaultNotificationEscalationIntervals = []time.Duration{time.Hour, 6 * time.Hour, 12 * time.Hour}
+
+const defaultNotificationDedupReset = time.Hour
+
+// followUpNotification summarizes the repeats of an ongoing event that were not notified since the last notification
+type followUpNotification struct {
+	types.Notification
+	Since   time.Time
+	Repeats uint64
+}
+
+func (n *followUpNotification) GetTitle() string {
+	return fmt.Sprintf("%s (ongoing for %s)", n.Notification.GetTitle(), formatNotificationDuration(time.Since(n.Since)))
+}
+
+func (n *followUpNotification) GetInfo(includeUrl bool) string {
+	return fmt.Sprintf("Still ongoing for %s, %d repeats were not notified: %s", formatNotificationDuration(time.Since(n.Since)), n.Repeats, n.Notification.GetInfo(includeUrl))
+}
+
+// formatNotificationDuration formats a duration as hours or minutes, e.g. "12h" or "45m"
+func formatNotificationDuration(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh", int(d.Hours()))
+}
+
+func notificationDedupKey(n types.Notification) string {
+	return fmt.Sprintf("%s:%s", n.GetEventName(), n.GetEventFilter())
+}
+
+// notificationEscalationInterval returns the time to wait after the given number of follow-ups of an ongoing event
+// before the next follow-up is sent, the last configured interval is repeated
+func notificationEscalationInterval(followUps uint64) time.Duration {
+	intervals := defaultNotificationEscalationIntervals
+	if len(utils.Config.Notifications.Dedup.EscalationIntervalsMinutes) > 0 {
+		intervals = make([]time.Duration, 0, len(utils.Config.Notifications.Dedup.EscalationIntervalsMinutes))
+		for _, m := range utils.Config.Notifications.Dedup.EscalationIntervalsMinutes {
+			intervals = append(intervals, time.Duration(m)*time.Minute)
+		}
+	}
+	if followUps >= uint64(len(intervals)) {
+		return intervals[len(intervals)-1]
+	}
+	return intervals[followUps]
+}
+
+func notificationDedupReset() time.Duration {
+	if utils.Config.Notifications.Dedup.ResetMinutes > 0 {
+		return time.Duration(utils.Config.Notifications.Dedup.ResetMinutes) * time.Minute
+	}
+	return defaultNotificationDedupReset
+}
+
+func notificationsMaxPerHour(channel types.NotificationChannel) int {
+	switch channel {
+	case types.EmailNotificationChannel:
+		return utils.Config.Notifications.MaxPerHour.Email
+	case types.PushNotificationChannel:
+		return utils.Config.Notifications.MaxPerHour.Push
+	}
+	return 0
+}
+
+// filterNotifications returns the notifications that are sent on the channel. Repeats of an ongoing event are
+// suppressed and collapsed into follow-ups once the escalation interval has passed, and the notifications of a user are
+// throttled to the configured number per hour. Suppressed notifications are marked as sent, throttled ones are retried
+// in the next run. If the state can not be loaded the notifications are sent unfiltered.
+func filterNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, channel types.NotificationChannel, useDB *sqlx.DB) map[uint64]map[types.EventName][]types.Notification {
+	dedup := utils.Config.Notifications.Dedup.Enabled
+	maxPerHour := notificationsMaxPerHour(channel)
+	if (!dedup && maxPerHour <= 0) || len(notificationsByUserID) == 0 {
+		return notificationsByUserID
+	}
+
+	now := time.Now()
+	reset := notificationDedupReset()
+	userIDs := make([]uint64, 0, len(notificationsByUserID))
+	for userID := range notificationsByUserID {
+		userIDs = append(userIDs, userID)
+	}
+
+	states := map[uint64]map[string]*types.NotificationDedup{}
+	if dedup {
+		var err error
+		states, err = db.GetNotificationsDedup(userIDs, channel, now.Add(-reset))
+		if err != nil {
+			logger.Errorf("error getting notifications dedup state of channel %v, sending notifications without dedup: %v", channel, err)
+			return notificationsByUserID
+		}
+	}
+	sentCounts := map[uint64]int{}
+	if maxPerHour > 0 {
+		var err error
+		sentCounts, err = db.GetSentNotificationsCount(userIDs, utils.GetNetwork(), channel, now.Add(-time.Hour))
+		if err != nil {
+			logger.Errorf("error getting sent notifications count of channel %v, sending notifications without throttling: %v", channel, err)
+			maxPerHour = 0
+		}
+	}
+
+	filtered := map[uint64]map[types.EventName][]types.Notification{}
+	updatedStates := []*types.NotificationDedup{}
+	suppressedSubsByEpoch := map[uint64][]uint64{}
+	suppressed, throttled := 0, 0
+
+	for userID, userNotifications := range notificationsByUserID {
+		// the events are processed in a fixed order so that the same notifications are throttled in every run
+		eventNames := make([]types.EventName, 0, len(userNotifications))
+		for eventName := range userNotifications {
+			eventNames = append(eventNames, eventName)
+		}
+		sort.Slice(eventNames, func(i, j int) bool { return eventNames[i] < eventNames[j] })
+
+		// an email contains all notifications of a run, so it is throttled as a whole
+		remaining := -1
+		if maxPerHour > 0 {
+			remaining = maxPerHour - sentCounts[userID]
+			if remaining < 0 {
+				remaining = 0
+			}
+			if channel == types.EmailNotificationChannel && remaining > 0 {
+				remaining = -1
+			}
+		}
+
+		for _, eventName := range eventNames {
+			for _, n := range userNotifications[eventName] {
+				var state *types.NotificationDedup
+				if dedup {
+					key := notificationDedupKey(n)
+					state = states[userID][key]
+					if state == nil {
+						state = &types.NotificationDedup{UserID: userID, Channel: channel, DedupKey: key, FirstSeenTime: now}
+					} else if now.Sub(state.LastSentTime) < notificationEscalationInterval(state.FollowUps) {
+						state.LastSeenTime = now
+						state.Repeats++
+						updatedStates = append(updatedStates, state)
+						suppressedSubsByEpoch[n.GetEpoch()] = append(suppressedSubsByEpoch[n.GetEpoch()], n.GetSubscriptionID())
+						suppressed++
+						continue
+					}
+				}
+
+				if remaining == 0 {
+					throttled++
+					continue
+				}
+				if remaining > 0 {
+					remaining--
+				}
+
+				if state != nil {
+					if !state.LastSentTime.IsZero() {
+						n = &followUpNotification{Notification: n, Since: state.FirstSeenTime, Repeats: state.Repeats}
+						state.FollowUps++
+					}
+					state.LastSeenTime = now
+					state.LastSentTime = now
+					state.Repeats = 0
+					updatedStates = append(updatedStates, state)
+				}
+				if filtered[userID] == nil {
+					filtered[userID] = map[types.EventName][]types.Notification{}
+				}
+				filtered[userID][eventName] = append(filtered[userID][eventName], n)
+			}
+		}
+	}
+
+	// the state is saved before the notifications are sent, a failed delivery is not retried as a new occurrence but
+	// summarized in the next follow-up
+	if len(updatedStates) > 0 {
+		err := db.SaveNotificationsDedup(updatedStates, now.Add(-reset))
+		if err != nil {
+			logger.Errorf("error saving notifications dedup state of channel %v: %v", channel, err)
+		}
+	}
+	for epoch, subIDs := range suppressedSubsByEpoch {
+		err := db.UpdateSubscriptionsLastSent(subIDs, now, epoch, useDB)
+		if err != nil {
+			logger.Errorf("error updating sent-time of suppressed notifications: %v", err)
+		}
+	}
+	if suppressed > 0 || throttled > 0 {
+		logger.Infof("suppressed %v repeated and throttled %v notifications on channel %v", suppressed, throttled, channel)
+	}
+	return filtered
+}
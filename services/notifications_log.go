@@ -0,0 +1,208 @@
+package services
+
+import (
+	"crypto/sha256"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/mail"
+	"eth2-exporter/notify"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+
+	"firebase.google.com/go/messaging"
+)
+
+// newNotificationLogEntry returns the log entry of a notification that is about to be delivered, the payload hash
+// covers the subject and the payload
+func newNotificationLogEntry(userID uint64, channel types.NotificationChannel, recipient string, eventNames []string, subject, payload string) *types.NotificationLogEntry {
+	hash := sha256.Sum256([]byte(subject + "\n" + payload))
+	return &types.NotificationLogEntry{
+		UserID:      userID,
+		Network:     utils.GetNetwork(),
+		Channel:     channel,
+		Recipient:   recipient,
+		EventNames:  eventNames,
+		Subject:     subject,
+		Payload:     payload,
+		PayloadHash: hash[:],
+		CreatedTime: time.Now(),
+	}
+}
+
+// setNotificationLogResult sets the status of the log entry from the result of the delivery
+func setNotificationLogResult(entry *types.NotificationLogEntry, response string, err error) {
+	entry.ProviderResponse = response
+	if err == nil {
+		entry.Status = types.NotificationLogStatusSent
+		return
+	}
+
+	entry.Status = types.NotificationLogStatusFailed
+	if _, ok := err.(*types.RateLimitError); ok {
+		entry.Status = types.NotificationLogStatusRateLimited
+	}
+	if err == mail.ErrSuppressed {
+		entry.Status = types.NotificationLogStatusSuppressed
+	}
+	if entry.ProviderResponse != "" {
+		entry.ProviderResponse += ": "
+	}
+	entry.ProviderResponse += err.Error()
+}
+
+// setPushNotificationLogResult sets the status of the log entry of the i-th message of a push batch
+func setPushNotificationLogResult(entry *types.NotificationLogEntry, result *messaging.BatchResponse, i int, err error) {
+	if err != nil {
+		setNotificationLogResult(entry, "", err)
+		return
+	}
+	if result == nil || i >= len(result.Responses) {
+		setNotificationLogResult(entry, "", fmt.Errorf("push notifications are not configured"))
+		return
+	}
+	response := result.Responses[i]
+	if !response.Success {
+		setNotificationLogResult(entry, "", response.Error)
+		return
+	}
+	setNotificationLogResult(entry, response.MessageID, nil)
+}
+
+// saveNotificationsLog persists the log entries, failures are only logged as the notifications have been delivered
+// already
+func saveNotificationsLog(entries ...*types.NotificationLogEntry) {
+	err := db.SaveNotificationsLog(entries)
+	if err != nil {
+		logger.Errorf("error saving notifications log: %v", err)
+	}
+}
+
+// newPushMessage returns the push message with the given title and body for the device of the token
+func newPushMessage(title, body, token string) *messaging.Message {
+	notification := new(messaging.Notification)
+	notification.Title = title
+	notification.Body = body
+
+	message := new(messaging.Message)
+	message.Notification = notification
+	message.Token = token
+
+	message.APNS = new(messaging.APNSConfig)
+	message.APNS.Payload = new(messaging.APNSPayload)
+	message.APNS.Payload.Aps = new(messaging.Aps)
+	message.APNS.Payload.Aps.Sound = "default"
+	return message
+}
+
+// ResendNotification delivers the notification of a log entry again, on the same channel and to the same recipient.
+// The new delivery attempt is logged with a reference to the original entry, email attachments and the html version
+// of emails are not part of the log and are not resent.
+func ResendNotification(id uint64) (*types.NotificationLogEntry, error) {
+	original, err := db.GetNotificationLogEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := newNotificationLogEntry(original.UserID, original.Channel, original.Recipient, original.EventNames, original.Subject, original.Payload)
+	entry.Network = original.Network
+	entry.ResentFrom = &original.ID
+
+	switch original.Channel {
+	case types.EmailNotificationChannel:
+		response, err := mail.SendMailRateLimited(&mail.Message{To: original.Recipient, Subject: original.Subject, Text: original.Payload})
+		setNotificationLogResult(entry, response, err)
+	case types.PushNotificationChannel:
+		result, err := notify.SendPushBatch([]*messaging.Message{newPushMessage(original.Subject, original.Payload, original.Recipient)})
+		setPushNotificationLogResult(entry, result, 0, err)
+	default:
+		return nil, fmt.Errorf("unknown notification channel %v", original.Channel)
+	}
+
+	err = db.SaveNotificationsLog([]*types.NotificationLogEntry{entry})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// testNotificationCooldown is the time a user has to wait between two test notifications, test mails are not counted
+// towards the daily mail limit so that they do not use up the quota of the notifications
+const testNotificationCooldown = time.Minute
+
+// ErrNoTestNotificationRecipient is returned by SendTestNotification if the user has no recipient on the channel
+var ErrNoTestNotificationRecipient = errors.New("no recipient is configured for this channel")
+
+// SendTestNotification delivers a synthetic notification on the channel to every recipient of the user the same way
+// the notifications of their subscriptions are delivered, the delivery attempts are logged and returned. A
+// types.RateLimitError is returned if the last test notification of the user was sent less than the cooldown ago.
+func SendTestNotification(userID uint64, channel types.NotificationChannel) ([]*types.NotificationLogEntry, error) {
+	last, err := db.GetLastUserNotificationLogTime(userID, string(types.TestNotificationEventName))
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); last != nil && last.Add(testNotificationCooldown).After(now) {
+		return nil, &types.RateLimitError{TimeLeft: last.Add(testNotificationCooldown).Sub(now)}
+	}
+
+	title := fmt.Sprintf("%sTest notification", getNetwork())
+	info := fmt.Sprintf("This is a test notification of %v. The notifications of your subscriptions, e.g. slashing alerts, are delivered the same way.", utils.Config.Frontend.SiteDomain)
+	eventNames := []string{string(types.TestNotificationEventName)}
+
+	entries := []*types.NotificationLogEntry{}
+	switch channel {
+	case types.EmailNotificationChannel:
+		emails, err := db.GetUserEmailsByIds([]uint64{userID})
+		if err != nil {
+			return nil, err
+		}
+		email, exists := emails[userID]
+		if !exists || email == "" {
+			return nil, ErrNoTestNotificationRecipient
+		}
+		languages, err := db.GetUserLanguagesByIds([]uint64{userID})
+		if err != nil {
+			logger.Errorf("error retrieving language of user %v, using the default language: %v", userID, err)
+			languages = map[uint64]string{}
+		}
+
+		subject := fmt.Sprintf("%s: Test notification", utils.Config.Frontend.SiteDomain)
+		msg := fmt.Sprintf("%s\n====\n\n%s\n\nBest regards\n\n%s", types.TestNotificationEventName, info, utils.Config.Frontend.SiteDomain)
+		html, err := mail.RenderNotificationMail(languages[userID], []*mail.NotificationSection{{EventName: types.TestNotificationEventName, Notifications: []string{info}}})
+		if err != nil {
+			logger.Errorf("error rendering test notification-email: %v", err)
+		}
+
+		response, err := mail.SendMailUncounted(&mail.Message{To: email, Subject: subject, Text: msg, HTML: html})
+		entry := newNotificationLogEntry(userID, types.EmailNotificationChannel, email, eventNames, subject, msg)
+		setNotificationLogResult(entry, response, err)
+		entries = append(entries, entry)
+	case types.PushNotificationChannel:
+		tokens, err := db.GetUserPushTokenByIds([]uint64{userID})
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens[userID]) == 0 {
+			return nil, ErrNoTestNotificationRecipient
+		}
+
+		batch := make([]*messaging.Message, 0, len(tokens[userID]))
+		for _, token := range tokens[userID] {
+			batch = append(batch, newPushMessage(title, info, token))
+			entries = append(entries, newNotificationLogEntry(userID, types.PushNotificationChannel, token, eventNames, title, info))
+		}
+		result, err := notify.SendPushBatch(batch)
+		for i, entry := range entries {
+			setPushNotificationLogResult(entry, result, i, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown notification channel %v", channel)
+	}
+
+	err = db.SaveNotificationsLog(entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
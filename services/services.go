@@ -3,6 +3,7 @@ package services
 import (
 	"database/sql"
 	"eth2-exporter/db"
+	"eth2-exporter/logging"
 	"eth2-exporter/price"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -11,8 +12,6 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 var latestEpoch uint64
@@ -29,7 +28,7 @@ var latestStats atomic.Value
 var eth1BlockDepositReached atomic.Value
 var depositThresholdReached atomic.Value
 
-var logger = logrus.New().WithField("module", "services")
+var logger = logging.NewLogger("services")
 
 // Init will initialize the services
 func Init() {
@@ -45,6 +44,15 @@ func Init() {
 	}
 	ready.Wait()
 
+	updateHealthStatus()
+	go healthStatusUpdater()
+	updateForks()
+	go forksUpdater()
+	go botFeedsUpdater()
+	go accountDeletionWorker()
+	go validatorSetForecastUpdater()
+	go withdrawalSweepUpdater()
+
 	if utils.Config.Frontend.OnlyAPI {
 		return
 	}
@@ -53,12 +61,16 @@ func Init() {
 		go chartsPageDataUpdater()
 	}
 
+	if utils.Config.Frontend.Crawlers.Enabled {
+		go sitemapUpdater()
+	}
+
 	go statsUpdater()
 }
 
 func InitNotifications() {
 	logger.Infof("starting notifications-sender")
-	go notificationsSender()
+	go RunAsLeader("notifications_sender", notificationsSender)
 }
 
 func epochUpdater() {
@@ -139,6 +151,7 @@ func indexPageDataUpdater() {
 			continue
 		}
 		indexPageData.Store(data)
+		markCacheUpdated("index_page")
 		if firstRun {
 			ready.Done()
 			firstRun = false
@@ -355,12 +368,17 @@ func getIndexPageData() (*types.IndexPageData, error) {
 	data.EnteringValidators = queueCount.EnteringValidators
 	data.ExitingValidators = queueCount.ExitingValidators
 
-	var averageBalance float64
-	err = db.DB.Get(&averageBalance, "SELECT COALESCE(AVG(balance), 0) FROM validators")
+	// the average balance of the active validators is precomputed by the indexer, averaging the validators table does
+	// not scale with the size of the validator set
+	summary, err := db.GetLatestEpochSummary()
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving validator balance: %v", err)
+		return nil, fmt.Errorf("error retrieving latest epoch summary: %v", err)
+	}
+	averageBalance := uint64(0)
+	if summary != nil {
+		averageBalance = summary.AverageValidatorBalance
 	}
-	data.AverageBalance = string(utils.FormatBalance(uint64(averageBalance), currency))
+	data.AverageBalance = string(utils.FormatBalance(averageBalance, currency))
 
 	var epochLowerBound uint64
 	if epochLowerBound = 0; epoch > 1600 {
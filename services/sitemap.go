@@ -0,0 +1,57 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"sync/atomic"
+	"time"
+)
+
+var sitemap atomic.Value
+
+// sitemapUpdater refreshes the validators and recent blocks listed in the sitemaps once per epoch
+func sitemapUpdater() {
+	for {
+		s, err := getSitemap()
+		if err != nil {
+			logger.WithError(err).Errorf("error updating sitemap")
+		} else {
+			sitemap.Store(s)
+		}
+		time.Sleep(time.Second * time.Duration(utils.Config.Chain.SlotsPerEpoch*utils.Config.Chain.SecondsPerSlot))
+	}
+}
+
+func getSitemap() (*types.Sitemap, error) {
+	s := &types.Sitemap{Ts: time.Now()}
+
+	// we use MAX(validatorindex)+1 instead of COUNT(*) for performance-reasons
+	err := db.DB.Get(&s.ValidatorCount, "SELECT COALESCE(MAX(validatorindex) + 1, 0) FROM validators")
+	if err != nil {
+		return nil, err
+	}
+
+	window := utils.Config.Frontend.Crawlers.SitemapBlockSlots
+	if window == 0 {
+		window = 100800
+	}
+	startSlot := uint64(0)
+	if latest := LatestSlot(); latest > window {
+		startSlot = latest - window
+	}
+	err = db.DB.Select(&s.BlockSlots, "SELECT slot FROM blocks WHERE slot > $1 AND status = '1' ORDER BY slot DESC", startSlot)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetSitemap returns the content of the sitemaps or nil if it has not been loaded yet
+func GetSitemap() *types.Sitemap {
+	s, ok := sitemap.Load().(*types.Sitemap)
+	if !ok {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/prysmaticlabs/prysm/shared/mathutil"
+)
+
+// the slashing parameters of bellatrix and electra that are not part of the preset files of older configs, they are the
+// same in the mainnet and the minimal preset
+const (
+	minSlashingPenaltyQuotientBellatrix     = 32
+	proportionalSlashingMultiplierBellatrix = 3
+	minSlashingPenaltyQuotientElectra       = 4096
+	whistleblowerRewardQuotientElectra      = 4096
+	defaultEpochsPerSlashingsVector         = 8192
+)
+
+// slashingParameters returns the quotients of the initial penalty and the whistleblower reward and the multiplier of the
+// correlation penalty of the fork, electra is true for electra and the later forks which compute the correlation penalty
+// per effective balance increment
+func slashingParameters(fork string) (minPenaltyQuotient, whistleblowerQuotient, proportionalMultiplier uint64, electra bool) {
+	switch fork {
+	case "phase0":
+		return utils.Config.Chain.MinSlashingPenaltyQuotient, utils.Config.Chain.WhistleblowerRewardQuotient, utils.Config.Chain.PorportionalSlashingMultiplier, false
+	case "altair":
+		return utils.Config.Chain.MinSlashingPenaltyQuotientAltair, utils.Config.Chain.WhistleblowerRewardQuotient, utils.Config.Chain.ProportionalSlashingMultiplierAltair, false
+	case "bellatrix", "capella", "deneb":
+		return minSlashingPenaltyQuotientBellatrix, utils.Config.Chain.WhistleblowerRewardQuotient, proportionalSlashingMultiplierBellatrix, false
+	}
+	minPenaltyQuotient = utils.Config.Chain.MinSlashingPenaltyQuotientElectra
+	if minPenaltyQuotient == 0 {
+		minPenaltyQuotient = minSlashingPenaltyQuotientElectra
+	}
+	whistleblowerQuotient = utils.Config.Chain.WhistleblowerRewardQuotientElectra
+	if whistleblowerQuotient == 0 {
+		whistleblowerQuotient = whistleblowerRewardQuotientElectra
+	}
+	return minPenaltyQuotient, whistleblowerQuotient, proportionalSlashingMultiplierBellatrix, true
+}
+
+// SimulateSlashing simulates the slashing of a validator with the effective balance (in gwei) at the latest epoch. A
+// scenario is simulated for each number of simultaneously slashed validators, by default for 1, 100, 1000 and 10000
+// validators and 1%, 10% and a third of the active validators.
+func SimulateSlashing(effectiveBalance uint64, simultaneousSlashings []uint64) (*types.SlashingSimulation, error) {
+	latestEpoch := LatestEpoch()
+	state, err := db.GetSlashingNetworkState(latestEpoch, utils.EpochsPerDay())
+	if err != nil {
+		return nil, err
+	}
+	if state.TotalActiveBalance == 0 {
+		return nil, fmt.Errorf("no active validators at epoch %v", latestEpoch)
+	}
+
+	fork := ForkOfEpoch(latestEpoch).Name
+	minPenaltyQuotient, whistleblowerQuotient, proportionalMultiplier, electra := slashingParameters(fork)
+	if minPenaltyQuotient == 0 || whistleblowerQuotient == 0 {
+		return nil, fmt.Errorf("the slashing parameters of the %v fork are not configured", fork)
+	}
+	increment := utils.Config.Chain.EffectiveBalanceIncrement
+	if increment == 0 {
+		increment = 1e9
+	}
+	exitPeriodEpochs := utils.Config.Chain.EpochsPerSlashingsVector
+	if exitPeriodEpochs == 0 {
+		exitPeriodEpochs = defaultEpochsPerSlashingsVector
+	}
+
+	sim := &types.SlashingSimulation{
+		Epoch:                  latestEpoch,
+		Fork:                   fork,
+		EffectiveBalance:       effectiveBalance,
+		ActiveValidators:       state.ActiveValidators,
+		TotalActiveBalance:     state.TotalActiveBalance,
+		RecentlySlashedBalance: state.RecentlySlashedBalance,
+		Participation:          state.Participation,
+		InitialPenalty:         effectiveBalance / minPenaltyQuotient,
+		WhistleblowerReward:    effectiveBalance / whistleblowerQuotient,
+		ExitPeriodEpochs:       exitPeriodEpochs,
+	}
+
+	// a slashed validator is penalized like a validator that misses its attestations until it is withdrawable, since
+	// altair the source and target weights (14 + 26 of 64) are deducted, before altair source, target and head (3 of 4)
+	idealReward := effectiveBalance / increment * (increment * utils.Config.Chain.BaseRewardFactor / mathutil.IntegerSquareRoot(state.TotalActiveBalance))
+	penaltyWeight := 40.0 / 64
+	if latestEpoch < utils.Config.Chain.AltairForkEpoch {
+		penaltyWeight = 3.0 / 4
+	}
+	sim.ExitPeriodPenalties = uint64(float64(idealReward) * penaltyWeight * float64(exitPeriodEpochs))
+	sim.MissedRewards = uint64(float64(idealReward) * RewardWeightsOfEpoch(latestEpoch).Attestation * state.Participation * float64(exitPeriodEpochs))
+
+	if len(simultaneousSlashings) == 0 {
+		simultaneousSlashings = []uint64{1, 100, 1000, 10000, state.ActiveValidators / 100, state.ActiveValidators / 10, state.ActiveValidators / 3}
+	}
+	sort.Slice(simultaneousSlashings, func(i, j int) bool { return simultaneousSlashings[i] < simultaneousSlashings[j] })
+
+	for i, n := range simultaneousSlashings {
+		if n == 0 || (i > 0 && n == simultaneousSlashings[i-1]) {
+			continue
+		}
+		scenario := &types.SlashingSimulationScenario{
+			SimultaneousSlashings: n,
+			SlashedBalance:        state.RecentlySlashedBalance + n*effectiveBalance,
+		}
+		adjustedSlashedBalance := scenario.SlashedBalance * proportionalMultiplier
+		if adjustedSlashedBalance > state.TotalActiveBalance {
+			adjustedSlashedBalance = state.TotalActiveBalance
+		}
+		if electra {
+			scenario.CorrelationPenalty = adjustedSlashedBalance / (state.TotalActiveBalance / increment) * (effectiveBalance / increment)
+		} else {
+			// the numerator can exceed 64 bits
+			penalty := new(big.Int).Mul(new(big.Int).SetUint64(effectiveBalance/increment), new(big.Int).SetUint64(adjustedSlashedBalance))
+			penalty.Div(penalty, new(big.Int).SetUint64(state.TotalActiveBalance))
+			scenario.CorrelationPenalty = penalty.Uint64() * increment
+		}
+		scenario.TotalLoss = sim.InitialPenalty + scenario.CorrelationPenalty + sim.ExitPeriodPenalties + sim.MissedRewards
+		if effectiveBalance > 0 {
+			scenario.TotalLossRatio = float64(scenario.TotalLoss) / float64(effectiveBalance)
+		}
+		sim.Scenarios = append(sim.Scenarios, scenario)
+	}
+	return sim, nil
+}
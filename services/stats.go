@@ -22,6 +22,7 @@ func statsUpdater() {
 		}
 		logger.WithField("epoch", latestEpoch).WithField("duration", time.Since(now)).Info("stats update completed")
 		latestStats.Store(statResult)
+		markCacheUpdated("stats")
 		time.Sleep(sleepDuration)
 	}
 }
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+)
+
+// a drop of the average network participation by more than this (absolute) is considered a network wide problem
+const diagnosisNetworkDip = 0.05
+
+// the client of a validator is guessed from the graffiti of this many of its last blocks
+const diagnosisRecentBlocks = 5
+
+// at most this many validators of a triggered rule are diagnosed, the diagnosis takes a few queries per validator
+const maxDiagnosedViolations = 10
+
+// the validators with the same withdrawal credentials are only compared if there are at most this many of them, the
+// credentials of large staking operators are shared by too many unrelated machines for a meaningful hint
+const diagnosisMaxWithdrawalSiblings = 1000
+
+// DiagnoseValidator correlates an attestation effectiveness of the validator below the threshold (in percent) on the days
+// from startDay to endDay with the participation of the network in the same and the preceding days, the validators with
+// the same withdrawal credentials and the graffiti of its last blocks
+func DiagnoseValidator(validatorIndex, startDay, endDay uint64, threshold float64) (*types.ValidatorDiagnosis, error) {
+	if endDay < startDay {
+		return nil, fmt.Errorf("end day %v is before start day %v", endDay, startDay)
+	}
+	d := &types.ValidatorDiagnosis{
+		Validatorindex: validatorIndex,
+		StartDay:       startDay,
+		EndDay:         endDay,
+	}
+	epochsPerDay := utils.EpochsPerDay()
+	days := endDay - startDay + 1
+
+	var err error
+	d.NetworkParticipation, err = db.GetNetworkParticipation(startDay*epochsPerDay, (endDay+1)*epochsPerDay-1)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving network participation: %w", err)
+	}
+	if startDay >= days {
+		d.NetworkBaselineParticipation, err = db.GetNetworkParticipation((startDay-days)*epochsPerDay, startDay*epochsPerDay-1)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving baseline network participation: %w", err)
+		}
+		d.NetworkDipped = d.NetworkParticipation > 0 && d.NetworkBaselineParticipation-d.NetworkParticipation > diagnosisNetworkDip
+	}
+
+	d.WithdrawalCredentials, d.WithdrawalSiblings, d.WithdrawalSiblingsDropped, err = db.GetWithdrawalSiblingsEffectiveness(validatorIndex, startDay, endDay, threshold, diagnosisMaxWithdrawalSiblings)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving validators with the same withdrawal credentials: %w", err)
+	}
+
+	graffiti, err := db.GetValidatorRecentGraffiti(validatorIndex, diagnosisRecentBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving graffiti of recent blocks: %w", err)
+	}
+	d.RecentBlocks = uint64(len(graffiti))
+	clients := map[string]uint64{}
+	for _, g := range graffiti {
+		if client := utils.ClientFromGraffiti(g); client != "unknown" {
+			clients[client]++
+		}
+	}
+	for client, count := range clients {
+		if count > d.ClientBlocks || (count == d.ClientBlocks && client < d.Client) {
+			d.Client = client
+			d.ClientBlocks = count
+		}
+	}
+	if d.Client == "" && d.RecentBlocks > 0 {
+		d.Client = "unknown"
+	}
+
+	return d, nil
+}
+
+// validatorDiagnosisHints describes the findings of a diagnosis in sentences for notifications
+func validatorDiagnosisHints(d *types.ValidatorDiagnosis) []string {
+	hints := []string{}
+	if d.NetworkDipped {
+		hints = append(hints, fmt.Sprintf("The participation of the whole network dropped from %.1f%% to %.1f%% at the same time, the cause is likely not on your side.",
+			d.NetworkBaselineParticipation*100, d.NetworkParticipation*100))
+	}
+	if d.WithdrawalSiblings > 0 && d.WithdrawalSiblings <= diagnosisMaxWithdrawalSiblings {
+		switch {
+		case d.WithdrawalSiblingsDropped == 0:
+			hints = append(hints, fmt.Sprintf("None of the %v other validators with the same withdrawal address dropped, the problem is likely specific to this validator.", d.WithdrawalSiblings))
+		case d.WithdrawalSiblingsDropped*2 >= d.WithdrawalSiblings:
+			hints = append(hints, fmt.Sprintf("%v of the %v other validators with the same withdrawal address dropped as well, which points to a shared node or machine.", d.WithdrawalSiblingsDropped, d.WithdrawalSiblings))
+		default:
+			hints = append(hints, fmt.Sprintf("%v of the %v other validators with the same withdrawal address dropped as well.", d.WithdrawalSiblingsDropped, d.WithdrawalSiblings))
+		}
+	}
+	if d.Client != "" && d.Client != "unknown" {
+		hints = append(hints, fmt.Sprintf("The graffiti of %v of its last %v blocks suggests it runs %v, check for known issues of the client.", d.ClientBlocks, d.RecentBlocks, d.Client))
+	}
+	return hints
+}
+
+// diagnoseEffectivenessViolations diagnoses the validators for which an attestation effectiveness rule was triggered, the
+// window of the rule are the last days of validator_stats up to lastDay
+func diagnoseEffectivenessViolations(rule *types.NotificationRule, violations []notificationRuleViolation, lastDay uint64) map[uint64]*types.ValidatorDiagnosis {
+	if rule.Window == 0 || lastDay+1 < rule.Window {
+		return nil
+	}
+
+	diagnoses := map[uint64]*types.ValidatorDiagnosis{}
+	for i, v := range violations {
+		if i >= maxDiagnosedViolations {
+			break
+		}
+		d, err := DiagnoseValidator(v.ValidatorIndex, lastDay+1-rule.Window, lastDay, rule.Threshold)
+		if err != nil {
+			logger.Errorf("error diagnosing validator %v: %v", v.ValidatorIndex, err)
+			continue
+		}
+		diagnoses[v.ValidatorIndex] = d
+	}
+	return diagnoses
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// validatorSetForecastFitDays is the number of days the deposit and exit rates of the forecast are fitted to
+const validatorSetForecastFitDays = 30
+
+var validatorSetForecastModel atomic.Value
+
+// validatorSetForecastUpdater regularly fits the deposit and exit rates of the validator set forecast
+func validatorSetForecastUpdater() {
+	for {
+		model, err := fitValidatorSetForecast(validatorSetForecastFitDays)
+		if err != nil {
+			logger.WithError(err).Errorf("error fitting validator set forecast")
+			time.Sleep(time.Minute)
+			continue
+		}
+		validatorSetForecastModel.Store(model)
+		time.Sleep(time.Hour)
+	}
+}
+
+func fitValidatorSetForecast(fitDays uint64) (*types.ValidatorSetForecast, error) {
+	latestEpoch := LatestEpoch()
+	epochsPerDay := utils.EpochsPerDay()
+	startEpoch := uint64(0)
+	if latestEpoch > epochsPerDay*fitDays {
+		startEpoch = latestEpoch - epochsPerDay*fitDays
+	}
+	days := float64(latestEpoch-startEpoch) / float64(epochsPerDay)
+	if days < 1 {
+		return nil, fmt.Errorf("the chain has not been running for a day yet")
+	}
+
+	flows, err := db.GetValidatorSetFlows(startEpoch, latestEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &types.ValidatorSetForecast{
+		FitDays:          fitDays,
+		Epoch:            latestEpoch,
+		ActiveValidators: flows.ActiveValidators,
+		EnteringQueue:    flows.EnteringQueue,
+		ExitingQueue:     flows.ExitingQueue,
+		DailyDeposits:    float64(flows.Deposits) / days,
+		DailyExits:       float64(flows.Exits) / days,
+		Participation:    flows.Participation,
+		AverageBalance:   utils.Config.Chain.MaxEffectiveBalance,
+	}
+	if flows.ActiveValidators > 0 {
+		model.AverageBalance = flows.TotalActiveBalance / flows.ActiveValidators
+	}
+	activationChurn, exitChurn := forecastChurnLimits(model.Epoch, float64(model.ActiveValidators), model.AverageBalance)
+	model.ActivationChurn = uint64(activationChurn)
+	model.ChurnLimit = uint64(exitChurn)
+	model.APR = forecastAPR(model.Epoch, float64(model.ActiveValidators), model.AverageBalance, model.Participation)
+	return model, nil
+}
+
+// GetValidatorSetForecast projects the validator set over the given number of months, band is the relative deviation of
+// the deposit and exit rates in the low and high scenario
+func GetValidatorSetForecast(months int, band float64) (*types.ValidatorSetForecast, error) {
+	model, ok := validatorSetForecastModel.Load().(*types.ValidatorSetForecast)
+	if !ok {
+		return nil, fmt.Errorf("the validator set forecast has not been fitted yet")
+	}
+
+	forecast := *model
+	forecast.Band = band
+	low := projectValidatorSet(&forecast, months, forecast.DailyDeposits*(1-band), forecast.DailyExits*(1+band))
+	base := projectValidatorSet(&forecast, months, forecast.DailyDeposits, forecast.DailyExits)
+	high := projectValidatorSet(&forecast, months, forecast.DailyDeposits*(1+band), forecast.DailyExits*(1-band))
+
+	forecast.Months = make([]*types.ValidatorSetForecastMonth, months)
+	for i := range forecast.Months {
+		forecast.Months[i] = &types.ValidatorSetForecastMonth{
+			Month:            i + 1,
+			ActiveValidators: types.ForecastBand{Low: low[i].active, Base: base[i].active, High: high[i].active},
+			APR:              types.ForecastBand{Low: low[i].apr, Base: base[i].apr, High: high[i].apr},
+			EnteringQueue:    types.ForecastBand{Low: low[i].entering, Base: base[i].entering, High: high[i].entering},
+			ExitingQueue:     types.ForecastBand{Low: low[i].exiting, Base: base[i].exiting, High: high[i].exiting},
+		}
+	}
+	return &forecast, nil
+}
+
+type validatorSetProjection struct {
+	active   float64
+	entering float64
+	exiting  float64
+	apr      float64
+}
+
+// projectValidatorSet simulates the queues day by day, deposits join the activation queue and exits the exit queue and
+// both queues are processed at the churn limits of the current validator set size and the fork of the day
+func projectValidatorSet(model *types.ValidatorSetForecast, months int, dailyDeposits, dailyExits float64) []validatorSetProjection {
+	epochsPerDay := float64(utils.EpochsPerDay())
+	active := float64(model.ActiveValidators)
+	entering := float64(model.EnteringQueue)
+	exiting := float64(model.ExitingQueue)
+
+	projections := make([]validatorSetProjection, months)
+	totalDays := int(math.Round(float64(months) * 365.25 / 12))
+	for day, month := 1, 0; day <= totalDays; day++ {
+		epoch := model.Epoch + uint64(day)*utils.EpochsPerDay()
+		activationChurn, exitChurn := forecastChurnLimits(epoch, active, model.AverageBalance)
+
+		entering += dailyDeposits
+		activated := math.Min(entering, activationChurn*epochsPerDay)
+		entering -= activated
+
+		exiting += dailyExits
+		exited := math.Min(exiting, exitChurn*epochsPerDay)
+		exiting -= exited
+
+		active = math.Max(active+activated-exited, 0)
+
+		if day == int(math.Round(float64(month+1)*365.25/12)) {
+			projections[month] = validatorSetProjection{
+				active:   active,
+				entering: entering,
+				exiting:  exiting,
+				apr:      forecastAPR(epoch, active, model.AverageBalance, model.Participation),
+			}
+			month++
+		}
+	}
+	return projections
+}
+
+// forecastChurnLimits returns the number of validators that can be activated and exited per epoch with the rules of the
+// fork active at the epoch. Before electra both are limited by the phase0 churn of the validator set size, deneb caps
+// the activations (EIP-7514). Since electra the churn is a balance that is split into validators by the activation
+// balance of new validators and the average balance of exiting ones.
+func forecastChurnLimits(epoch uint64, active float64, averageBalance uint64) (activation, exit float64) {
+	chain := utils.Config.Chain
+	if IsForkActive("electra", epoch) {
+		quotient := float64(chain.ChurnLimitQuotient)
+		if quotient == 0 {
+			quotient = 65536
+		}
+		increment := float64(chain.EffectiveBalanceIncrement)
+		if increment == 0 {
+			increment = 1e9
+		}
+		minChurn := float64(chain.MinPerEpochChurnLimitElectra)
+		if minChurn == 0 {
+			minChurn = 128e9
+		}
+		maxChurn := float64(chain.MaxPerEpochActivationExitChurnLimit)
+		if maxChurn == 0 {
+			maxChurn = 256e9
+		}
+		churn := math.Max(minChurn, math.Floor(active*float64(averageBalance)/quotient/increment)*increment)
+		churn = math.Min(maxChurn, churn)
+
+		activationBalance := float64(chain.MinActivationBalance)
+		if activationBalance == 0 {
+			activationBalance = 32e9
+		}
+		exitBalance := float64(averageBalance)
+		if exitBalance == 0 {
+			exitBalance = activationBalance
+		}
+		return churn / activationBalance, churn / exitBalance
+	}
+
+	churn := float64(chain.MinPerEpochChurnLimit)
+	if chain.ChurnLimitQuotient > 0 {
+		churn = math.Max(churn, math.Floor(active/float64(chain.ChurnLimitQuotient)))
+	}
+	if churn == 0 {
+		churn = 4
+	}
+	activation = churn
+	if IsForkActive("deneb", epoch) {
+		maxActivation := float64(chain.MaxPerEpochActivationChurnLimit)
+		if maxActivation == 0 {
+			maxActivation = 8
+		}
+		activation = math.Min(activation, maxActivation)
+	}
+	return activation, churn
+}
+
+// forecastAPR returns the consensus layer APR of a validator at the given participation rate with the rewards of the
+// fork active at the epoch. A validator earns its ideal reward per epoch when all duties are fulfilled, the ideal reward
+// per gwei is BASE_REWARD_FACTOR / sqrt(total active balance). The attestation and proposal rewards scale with the
+// participation, the sync committee rewards do not.
+func forecastAPR(epoch uint64, active float64, averageBalance uint64, participation float64) float64 {
+	totalBalance := active * float64(averageBalance)
+	if totalBalance <= 0 {
+		return 0
+	}
+	epochsPerYear := 365.25 * float64(utils.EpochsPerDay())
+	weights := RewardWeightsOfEpoch(epoch)
+	return float64(utils.Config.Chain.BaseRewardFactor) / math.Sqrt(totalBalance) * epochsPerYear * (weights.Sync + (1-weights.Sync)*participation)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// withdrawalSweepWindowSlots is the number of recent slots the speed of the withdrawal sweep is measured over
+const withdrawalSweepWindowSlots = 256
+
+var withdrawalSweep atomic.Value
+
+// withdrawalSweepUpdater regularly updates the position and the speed of the withdrawal sweep that the full withdrawals
+// of exited validators are estimated from
+func withdrawalSweepUpdater() {
+	for {
+		sweep, err := getWithdrawalSweep()
+		if err != nil {
+			logger.WithError(err).Errorf("error updating withdrawal sweep")
+		} else if sweep != nil {
+			withdrawalSweep.Store(sweep)
+		}
+		time.Sleep(time.Minute)
+	}
+}
+
+func getWithdrawalSweep() (*types.WithdrawalSweep, error) {
+	latestSlot := LatestSlot()
+	startSlot := uint64(0)
+	if latestSlot > withdrawalSweepWindowSlots {
+		startSlot = latestSlot - withdrawalSweepWindowSlots
+	}
+
+	samples, validatorCount, err := db.GetWithdrawalSweepSamples(startSlot)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) < 2 {
+		// no withdrawals have been indexed recently (e.g. before capella)
+		return nil, nil
+	}
+	for _, s := range samples {
+		if s.ValidatorIndex >= validatorCount {
+			validatorCount = s.ValidatorIndex + 1
+		}
+	}
+
+	// the withdrawal index counts the withdrawals over all blocks, the sweep advances over the registry until it found
+	// the withdrawals of a block, its speed therefore depends on the number of validators it withdraws from
+	first, last := samples[0], samples[len(samples)-1]
+	if last.WithdrawalIndex <= first.WithdrawalIndex {
+		return nil, nil
+	}
+	sweptValidators, err := db.CountSweptValidators(context.Background(), 0, validatorCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WithdrawalSweep{
+		Slot:               last.Slot,
+		NextValidator:      (last.ValidatorIndex + 1) % validatorCount,
+		ValidatorCount:     validatorCount,
+		SweptValidators:    sweptValidators,
+		WithdrawalsPerSlot: float64(last.WithdrawalIndex-first.WithdrawalIndex) / float64(last.Slot-first.Slot),
+	}, nil
+}
+
+// GetWithdrawalSweep returns the latest position of the withdrawal sweep or nil if it is not known yet
+func GetWithdrawalSweep() *types.WithdrawalSweep {
+	sweep, ok := withdrawalSweep.Load().(*types.WithdrawalSweep)
+	if !ok {
+		return nil
+	}
+	return sweep
+}
+
+// EstimateFullWithdrawal estimates the slot the withdrawal sweep withdraws the balance of an exited validator at from
+// the number of withdrawals the sweep includes before it reaches the validator. The sweep only withdraws the validator
+// once it is withdrawable, if the sweep reaches the validator before its withdrawable epoch the withdrawal happens in a
+// later cycle. Nil is returned if the position of the sweep is not known.
+func EstimateFullWithdrawal(ctx context.Context, validatorIndex, withdrawableEpoch uint64) (*types.ValidatorFullWithdrawal, error) {
+	sweep := GetWithdrawalSweep()
+	if sweep == nil || validatorIndex >= sweep.ValidatorCount {
+		return nil, nil
+	}
+
+	withdrawals, err := db.CountSweptValidators(ctx, sweep.NextValidator, validatorIndex)
+	if err != nil {
+		return nil, err
+	}
+	slot := float64(sweep.Slot+1) + float64(withdrawals)/sweep.WithdrawalsPerSlot
+	withdrawableSlot := float64(withdrawableEpoch * utils.Config.Chain.SlotsPerEpoch)
+	if slot < withdrawableSlot && sweep.SweptValidators > 0 {
+		cycleSlots := float64(sweep.SweptValidators) / sweep.WithdrawalsPerSlot
+		slot += math.Ceil((withdrawableSlot-slot)/cycleSlots) * cycleSlots
+	}
+
+	estimatedSlot := uint64(math.Ceil(slot))
+	return &types.ValidatorFullWithdrawal{
+		Slot:      estimatedSlot,
+		Ts:        utils.SlotToTime(estimatedSlot),
+		Estimated: true,
+	}, nil
+}
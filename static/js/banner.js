@@ -159,5 +159,18 @@ function updateBanner() {
   })
 }
 
-// update the banner every 12 seconds
-setInterval(updateBanner, 12000)
+// update the banner whenever a new head is indexed, polling every 12 seconds remains as fallback for browsers without
+// server-sent events and for the case that no heads are streamed
+var lastHeadEvent = 0
+if (window.EventSource) {
+  var headEvents = new EventSource('/sse/head')
+  headEvents.addEventListener('head', function () {
+    lastHeadEvent = Date.now()
+    updateBanner()
+  })
+}
+setInterval(function () {
+  if (Date.now() - lastHeadEvent > 24000) {
+    updateBanner()
+  }
+}, 12000)
@@ -34,6 +34,9 @@ function appendBlocks(blocks) {
 
 var selectedBTNindex = null
 var VALLIMIT = 280
+// validators of a shared dashboard, the shared dashboard is read-only
+var SHARED_VALIDATORS = null
+var SHARED_ID = null
 function showValidatorHist (index) {
   if ($.fn.dataTable.isDataTable('#dash-validator-history-table')) {
         $('#dash-validator-history-table').DataTable().destroy();
@@ -121,7 +124,11 @@ function updateValidatorInfo(index) {
 }
 
 function getValidatorQueryString() {
-  return window.location.href.slice(window.location.href.indexOf("?"), window.location.href.length)
+  if (SHARED_ID) {
+    // the url of a shared dashboard has no query, the server resolves the validators of the tag
+    return '?shared=' + SHARED_ID
+  }
+  return window.location.search
 }
 
 var boxAnimationDirection=""
@@ -136,7 +143,7 @@ window.addEventListener('load', function() {
     overview.classList.toggle('d-none')
   })
 
-  searchInput.addEventListener('focus', function(ev) {
+  if (searchInput) searchInput.addEventListener('focus', function(ev) {
     var overview = document.getElementById('selected-validators-overview')
     if (document.querySelector('#selected-validators-input-button > span').textContent) {
       overview.classList.remove('d-none')
@@ -345,10 +352,9 @@ $(document).ready(function() {
     var errorIcon = $("<i class='fas fa-exclamation' style='width:15px;'></i>")
     fetch('/dashboard/save', {
       method: "POST",
-      // credentials: 'include',
       headers: {
-        'Content-Type': 'application/json'
-        // 'X-CSRF-Token': $("#bookmark-button").attr("csrf"),
+        'Content-Type': 'application/json',
+        'X-CSRF-Token': $("#bookmark-button").attr("csrf"),
       },
       body: JSON.stringify(state.validators),
     }).then(function(res) {
@@ -757,6 +763,11 @@ $(document).ready(function() {
     //   alert(`You can not add more than ${VALLIMIT} validators to your dashboard`)
     //   return
     // }
+    if (SHARED_VALIDATORS) {
+      state.validators = SHARED_VALIDATORS.map(String)
+      state.validators.sort(sortValidators)
+      return
+    }
     var usp = new URLSearchParams(window.location.search)
     var validatorsStr = usp.get('validators')
     if (!validatorsStr) {
@@ -838,6 +849,7 @@ $(document).ready(function() {
   }
 
   function removeValidator(index) {
+    if (SHARED_VALIDATORS) return
     boxAnimationDirection="out"
     for (var i = 0; i < state.validators.length; i++) {
       if (state.validators[i] === index) {
@@ -897,12 +909,19 @@ $(document).ready(function() {
       // alert(`Too many validators, you can not add more than ${VALLIMIT} validators to your dashboard!`)
       return
     }
-    localStorage.setItem('dashboard_validators', JSON.stringify(state.validators))
+    if (!SHARED_VALIDATORS) {
+      localStorage.setItem('dashboard_validators', JSON.stringify(state.validators))
+    }
     if(state.validators.length) {
       // console.log('length', state.validators)
       var qryStr = '?validators=' + state.validators.join(',')
-      var newUrl = window.location.pathname + qryStr
-      window.history.replaceState(null, 'Dashboard', newUrl)
+      if (SHARED_ID) {
+        // the validators of a shared dashboard are loaded by the server with the limit of the owner of the tag
+        qryStr = '?shared=' + SHARED_ID
+      } else {
+        var newUrl = window.location.pathname + qryStr
+        window.history.replaceState(null, 'Dashboard', newUrl)
+      }
     }
     var t0 = Date.now()
     if (state.validators && state.validators.length) {
@@ -912,9 +931,13 @@ $(document).ready(function() {
       //   appendBlocks(xBlocks.slice(0, state.validators.length * 3 - 1))
       // }
       document.querySelector('#rewards-button').style.visibility = "visible"
-      document.querySelector('#bookmark-button').style.visibility = "visible"
       document.querySelector('#copy-button').style.visibility = "visible"
-      document.querySelector('#clear-search').style.visibility = "visible"
+      if (!SHARED_VALIDATORS) {
+        document.querySelector('#bookmark-button').style.visibility = "visible"
+        document.querySelector('#clear-search').style.visibility = "visible"
+      }
+      document.querySelector('#calendar-button').style.visibility = "visible"
+      document.querySelector('#calendar-button').setAttribute('href', '/dashboard/duties.ics' + qryStr)
 
       $.ajax({
         url: '/dashboard/data/earnings' + qryStr,
@@ -985,8 +1008,11 @@ $(document).ready(function() {
     } else {
       document.querySelector('#copy-button').style.visibility = "hidden"
       document.querySelector('#rewards-button').style.visibility = "hidden"
-      document.querySelector('#bookmark-button').style.visibility = "hidden"
-      document.querySelector('#clear-search').style.visibility = "hidden"
+      if (!SHARED_VALIDATORS) {
+        document.querySelector('#bookmark-button').style.visibility = "hidden"
+        document.querySelector('#clear-search').style.visibility = "hidden"
+      }
+      document.querySelector('#calendar-button').style.visibility = "hidden"
       // window.location = "/dashboard"
     }
 
@@ -1025,7 +1051,7 @@ $(document).ready(function() {
     // }
     // document.getElementById('chart-holder').style.display = 'flex'
     if (state.validators && state.validators.length) {
-      var qryStr = '?validators=' + state.validators.join(',')
+      var qryStr = SHARED_ID ? '?shared=' + SHARED_ID : '?validators=' + state.validators.join(',')
       $.ajax({
         url: '/dashboard/data/balance' + qryStr,
         success: function(result) {
@@ -0,0 +1,31 @@
+// counts down to the activation of the upcoming fork announced in the fork banner
+;(function () {
+  var banner = document.getElementById('fork-banner')
+  var countdown = document.getElementById('fork-banner-countdown')
+  if (!banner || !countdown) return
+
+  var forkTs = parseInt(banner.getAttribute('data-fork-ts')) * 1000
+  var pad = function (n) {
+    return n < 10 ? '0' + n : '' + n
+  }
+
+  function updateCountdown() {
+    var left = Math.floor((forkTs - Date.now()) / 1000)
+    if (left <= 0) {
+      countdown.textContent = 'a few moments'
+      return false
+    }
+    var days = Math.floor(left / 86400)
+    var hours = Math.floor((left % 86400) / 3600)
+    var minutes = Math.floor((left % 3600) / 60)
+    var seconds = left % 60
+    countdown.textContent = (days > 0 ? days + 'd ' : '') + pad(hours) + 'h ' + pad(minutes) + 'm ' + pad(seconds) + 's'
+    return true
+  }
+
+  if (updateCountdown()) {
+    var interval = setInterval(function () {
+      if (!updateCountdown()) clearInterval(interval)
+    }, 1000)
+  }
+})()
@@ -17,17 +17,16 @@ $(document).ready(function() {
 // Theme switch
 function switchTheme(e) {
   var d1 = document.getElementById('app-theme');
+  var theme = 'dark'
   //checked is light
   if (e.target.checked) {
-    d1.href = "/theme/css/beacon-light.min.css"
-    document.documentElement.setAttribute('data-theme', 'light')
-    localStorage.setItem('theme', 'light')
-
-  } else { // dark theme
-    d1.href = "/theme/css/beacon-dark.min.css"
-    document.documentElement.setAttribute('data-theme', 'dark')
-    localStorage.setItem('theme', 'dark')
+    theme = 'light'
   }
+  d1.href = "/theme/css/beacon-" + theme + ".min.css"
+  document.documentElement.setAttribute('data-theme', theme)
+  localStorage.setItem('theme', theme)
+  // persist the preference server-side so it is applied to server-rendered charts and other devices
+  postWithCsrf('/theme', 'theme=' + theme)
 }
 $('#toggleSwitch').on('change', switchTheme)
 
@@ -1,6 +1,6 @@
 var csrfToken = ""
 
-const VALIDATOR_EVENTS = ['validator_attestation_missed', 'validator_proposal_missed', 'validator_proposal_submitted', 'validator_got_slashed']
+const VALIDATOR_EVENTS = ['validator_attestation_missed', 'validator_proposal_missed', 'validator_proposal_submitted', 'validator_got_slashed', 'validator_fee_recipient_mismatch', 'rocketpool_fee_recipient_violation', 'validator_full_withdrawal']
 
 const MONITORING_EVENTS = ['monitoring_machine_offline', 'monitoring_hdd_almostfull', 'monitoring_cpu_load']
 
@@ -493,6 +493,15 @@ function loadValidatorsData(data) {
                   case 'validator_got_slashed':
                     badgeColor = 'badge-light'
                     break
+                  case 'validator_fee_recipient_mismatch':
+                    badgeColor = 'badge-light'
+                    break
+                  case 'rocketpool_fee_recipient_violation':
+                    badgeColor = 'badge-warning'
+                    break
+                  case 'validator_full_withdrawal':
+                    badgeColor = 'badge-light'
+                    break
                 }
                 notifications += `<span style="font-size: 12px; font-weight: 500;" class="badge badge-pill ${badgeColor} ${textColor} badge-custom-size mr-1 my-1">${n.replace('validator', "").replaceAll('_', " ")}</span>`
               }
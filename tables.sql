@@ -34,6 +34,20 @@ create index idx_validators_pubkeyhex_pattern_pos on validators (pubkeyhex varch
 create index idx_validators_status on validators (status);
 create index idx_validators_balanceactivation on validators (balanceactivation);
 create index idx_validators_activationepoch on validators (activationepoch);
+create index idx_validators_withdrawalcredentials on validators (withdrawalcredentials);
+
+-- status transitions of the validators with the epoch they happened at, derived by the indexer from the lifecycle epochs,
+-- deposits, voluntary exits and slashings. The statuses are the ones of the validator set snapshot (deposited, pending,
+-- active, exiting, slashing, exited and slashed), every status is reached at most once.
+drop table if exists validator_status_history;
+create table validator_status_history
+(
+    validatorindex int         not null,
+    status         varchar(20) not null,
+    epoch          bigint      not null,
+    primary key (validatorindex, status)
+);
+create index idx_validator_status_history_epoch on validator_status_history (epoch);
 
 drop table if exists validator_names;
 create table validator_names
@@ -89,6 +103,20 @@ create table proposal_assignments
 );
 create index idx_proposal_assignments_epoch on proposal_assignments (epoch);
 
+/* The attestation duties of all validators of an epoch as bit vectors indexed by validator index, assigned has the bits
+   of the validators with a duty, included the bits of the validators whose attestation was included in a canonical
+   block of the epoch and included_next in a canonical block of the next epoch. The vectors keep the history of the
+   attestation_assignments_p rows once their weeks are dropped after the retention period */
+drop table if exists attestation_bitfields;
+create table attestation_bitfields
+(
+    epoch         int   not null,
+    assigned      bytea not null,
+    included      bytea not null,
+    included_next bytea not null,
+    primary key (epoch)
+);
+
 drop table if exists attestation_assignments_p;
 create table attestation_assignments_p
 (
@@ -99,6 +127,9 @@ create table attestation_assignments_p
     status         int not null, /* Can be 0 = scheduled, 1 executed, 2 missed */
     inclusionslot  int not null default 0, /* Slot this attestation was included for the first time */
     week           int not null,
+    source_correct bool, /* Correctness of the votes of the first included attestation, null until the epoch is finalized */
+    target_correct bool,
+    head_correct   bool,
     primary key (validatorindex, week, epoch)
 ) PARTITION BY LIST (week);
 
@@ -203,6 +234,14 @@ create table validator_stats_status
     primary key (day)
 );
 
+drop table if exists bulk_export_status;
+create table bulk_export_status
+(
+    day         int       not null,
+    exported_ts timestamp not null,
+    primary key (day)
+);
+
 drop table if exists validator_attestation_streaks;
 create table validator_attestation_streaks
 (
@@ -264,6 +303,33 @@ create table epochs
     primary key (epoch)
 );
 
+/* Precomputed per-epoch summary of the epochs and blocks tables, written by the indexer after every export so the epoch
+   pages and the front page do not aggregate the blocks and validators at request time */
+drop table if exists epochs_summary;
+create table epochs_summary
+(
+    epoch                   int    not null,
+    validatorscount         int    not null,
+    totalvalidatorbalance   bigint not null,
+    averagevalidatorbalance bigint not null,
+    eligibleether           bigint,
+    votedether              bigint,
+    globalparticipationrate float,
+    finalized               bool,
+    attestationscount       int    not null,
+    depositscount           int    not null,
+    voluntaryexitscount     int    not null,
+    proposerslashingscount  int    not null,
+    attesterslashingscount  int    not null,
+    scheduledblocks         int    not null,
+    proposedblocks          int    not null,
+    missedblocks            int    not null,
+    orphanedblocks          int    not null,
+    syncparticipationrate   float  not null,
+    updated_ts              timestamp without time zone not null,
+    primary key (epoch)
+);
+
 drop table if exists blocks;
 create table blocks
 (
@@ -289,9 +355,20 @@ create table blocks
     voluntaryexitscount         int   not null,
     proposer                    int   not null,
     status                      text  not null, /* Can be 0 = scheduled, 1 proposed, 2 missed, 3 orphaned */
+    exec_block_hash             bytea,
+    exec_block_number           int,
+    exec_fee_recipient          bytea,
+    exec_gas_used               bigint,
+    exec_gas_limit              bigint,
+    exec_base_fee_per_gas       bigint,
+    exec_transactions_count     int   not null default 0,
+    exec_fee_reward             numeric, /* Value the proposer received in wei, the payment of the builder for relay blocks, else the priority fees, null until evaluated by the el rewards exporter */
+    exec_fee_reward_recipient   bytea, /* Address exec_fee_reward was paid to, the recipient of the payment of the builder for relay blocks, else the fee recipient */
     primary key (slot, blockroot)
 );
 create index idx_blocks_proposer on blocks (proposer);
+create index idx_blocks_exec_fee_reward_missing on blocks (slot) where status = '1' and exec_block_number is not null and exec_fee_reward is null;
+create index idx_blocks_exec_fee_recipient on blocks (exec_fee_recipient);
 create index idx_blocks_epoch on blocks (epoch);
 create index idx_blocks_graffiti_text on blocks using gin (graffiti_text gin_trgm_ops);
 create index idx_blocks_blockrootstatus on blocks (blockroot, status);
@@ -378,6 +455,7 @@ create table blocks_deposits
     signature             bytea  not null,
     primary key (block_slot, block_index)
 );
+create index idx_blocks_deposits_publickey on blocks_deposits (publickey);
 
 drop table if exists blocks_voluntaryexits;
 create table blocks_voluntaryexits
@@ -390,6 +468,71 @@ create table blocks_voluntaryexits
     signature      bytea not null,
     primary key (block_slot, block_index)
 );
+create index idx_blocks_voluntaryexits_validatorindex on blocks_voluntaryexits (validatorindex);
+
+drop table if exists blocks_consolidation_requests;
+create table blocks_consolidation_requests
+(
+    block_slot     int   not null,
+    block_index    int   not null,
+    block_root     bytea not null default '',
+    source_address bytea not null,
+    source_pubkey  bytea not null,
+    target_pubkey  bytea not null,
+    primary key (block_slot, block_index)
+);
+create index idx_blocks_consolidation_requests_source_pubkey on blocks_consolidation_requests (source_pubkey);
+create index idx_blocks_consolidation_requests_target_pubkey on blocks_consolidation_requests (target_pubkey);
+
+drop table if exists blocks_bls_change;
+create table blocks_bls_change
+(
+    block_slot     int   not null,
+    block_index    int   not null,
+    block_root     bytea not null default '',
+    validatorindex int   not null,
+    signature      bytea not null,
+    pubkey         bytea not null,
+    address        bytea not null,
+    primary key (block_slot, block_index)
+);
+create index idx_blocks_bls_change_validatorindex on blocks_bls_change (validatorindex);
+
+drop table if exists deposit_submissions;
+create table deposit_submissions
+(
+    tx_hash      bytea                       not null,
+    pubkey       bytea                       not null,
+    submitted_ts timestamp without time zone not null,
+    primary key (tx_hash, pubkey)
+);
+create index idx_deposit_submissions_submitted_ts on deposit_submissions (submitted_ts);
+
+drop table if exists blocks_withdrawals;
+create table blocks_withdrawals
+(
+    block_slot      int    not null,
+    block_index     int    not null,
+    block_root      bytea  not null default '',
+    withdrawalindex bigint not null,
+    validatorindex  int    not null,
+    address         bytea  not null,
+    amount          bigint not null, /* in gwei */
+    primary key (block_slot, block_index)
+);
+create index idx_blocks_withdrawals_validatorindex on blocks_withdrawals (validatorindex);
+
+drop table if exists bls_change_submissions;
+create table bls_change_submissions
+(
+    validatorindex int                         not null,
+    pubkey         bytea                       not null,
+    address        bytea                       not null,
+    signature      bytea                       not null,
+    submitted_ts   timestamp without time zone not null,
+    primary key (validatorindex)
+);
+create index idx_bls_change_submissions_submitted_ts on bls_change_submissions (submitted_ts);
 
 drop table if exists network_liveness;
 create table network_liveness
@@ -432,6 +575,35 @@ create table eth1_deposits
     primary key (tx_hash, merkletree_index)
 );
 create index idx_eth1_deposits on eth1_deposits (publickey);
+create index idx_eth1_deposits_from_address on eth1_deposits (from_address);
+
+drop table if exists eth1_depositors;
+create table eth1_depositors
+(
+    from_address     bytea                       not null,
+    deposit_count    int                         not null,
+    amount           bigint                      not null,
+    first_deposit_ts timestamp without time zone not null,
+    last_deposit_ts  timestamp without time zone not null,
+    primary key (from_address)
+);
+create index idx_eth1_depositors_amount on eth1_depositors (amount);
+
+drop table if exists eth1_pending_deposits;
+create table eth1_pending_deposits
+(
+    publickey                  bytea                       not null,
+    from_address               bytea                       not null,
+    deposit_count              int                         not null,
+    amount                     bigint                      not null,
+    valid_amount               bigint                      not null,
+    last_block_number          int                         not null,
+    last_deposit_ts            timestamp without time zone not null,
+    queue_position             int,
+    estimated_inclusion_epoch  int                         not null,
+    estimated_activation_epoch int,
+    primary key (publickey)
+);
 
 drop table if exists users;
 create table users
@@ -447,6 +619,8 @@ create table users
     register_ts             timestamp without time zone,
     api_key                 character varying(256) unique,
     stripe_customer_id      character varying(256) unique,
+    theme                   character varying(10),
+    language                character varying(10),
     primary key (id, email)
 );
 
@@ -550,6 +724,16 @@ create table users_subscriptions
     primary key (user_id, event_name, event_filter)
 );
 
+drop table if exists users_fee_recipients;
+create table users_fee_recipients
+(
+    user_id              int                         not null,
+    address              bytea                       not null,
+    allow_smoothing_pool bool                        not null default false,
+    updated_ts           timestamp without time zone not null,
+    primary key (user_id)
+);
+
 drop table if exists users_notifications;
 create table users_notifications
 (
@@ -562,6 +746,60 @@ create table users_notifications
     primary key(user_id, event_name, event_filter, sent_ts)
 );
 
+drop table if exists users_notification_rules;
+create table users_notification_rules
+(
+    id                  serial                      not null,
+    user_id             int                         not null,
+    network             character varying(20)       not null,
+    name                character varying(100)      not null,
+    metric              character varying(50)       not null,
+    threshold           float                       not null,
+    window_size         int                         not null,
+    validator_publickey bytea,
+    created_ts          timestamp without time zone not null,
+    primary key (id)
+);
+create index idx_users_notification_rules_user_id on users_notification_rules (user_id);
+
+drop table if exists notifications_log;
+create table notifications_log
+(
+    id                serial                      not null,
+    user_id           int                         not null,
+    network           character varying(20)       not null,
+    channel           character varying(20)       not null,
+    recipient         text                        not null,
+    event_names       text[]                      not null,
+    subject           text                        not null,
+    payload           text                        not null,
+    payload_hash      bytea                       not null,
+    status            character varying(20)       not null,
+    provider_response text                        not null default '',
+    resent_from       int,
+    created_ts        timestamp without time zone not null,
+    primary key (id)
+);
+create index idx_notifications_log_user_id_created_ts on notifications_log (user_id, created_ts);
+
+/* The state of the repeats of a notification per user and channel, the dedup key is the event name and the event
+   filter. first_seen_ts is the start of the ongoing event, repeats are the occurrences that were not notified since
+   last_sent_ts and follow_ups the number of summaries sent for the ongoing event */
+drop table if exists notifications_dedup;
+create table notifications_dedup
+(
+    user_id       int                         not null,
+    channel       character varying(20)       not null,
+    dedup_key     text                        not null,
+    first_seen_ts timestamp without time zone not null,
+    last_seen_ts  timestamp without time zone not null,
+    last_sent_ts  timestamp without time zone not null,
+    repeats       int                         not null default 0,
+    follow_ups    int                         not null default 0,
+    primary key (user_id, channel, dedup_key)
+);
+create index idx_notifications_dedup_last_seen_ts on notifications_dedup (last_seen_ts);
+
 drop table if exists users_validators_tags;
 create table users_validators_tags
 (
@@ -571,6 +809,19 @@ create table users_validators_tags
     primary key (user_id, validator_publickey, tag)
 );
 
+/* User defined validator tags, the validators of a tag are stored in users_validators_tags with the tag
+   <network>:tag:<name>. The dashboard of a tag with a share id is public at /dashboard/shared/<share_id> */
+drop table if exists users_tags;
+create table users_tags
+(
+    user_id    int                         not null,
+    network    character varying(20)       not null,
+    name       character varying(50)       not null,
+    created_ts timestamp without time zone not null,
+    share_id   character varying(32) unique,
+    primary key (user_id, network, name)
+);
+
 drop table if exists validator_tags;
 create table validator_tags
 (
@@ -579,6 +830,27 @@ create table validator_tags
     primary key (publickey, tag)
 );
 
+drop table if exists mail_suppressions;
+create table mail_suppressions
+(
+    email      character varying(100)      not null,
+    reason     character varying(20)       not null,
+    provider   character varying(20)       not null,
+    details    text                        not null default '',
+    created_ts timestamp without time zone not null,
+    primary key (email)
+);
+
+drop table if exists feature_flags;
+create table feature_flags
+(
+    name       character varying(100)      not null,
+    enabled    bool                        not null,
+    updated_by int                         not null,
+    updated_ts timestamp without time zone not null,
+    primary key (name)
+);
+
 drop table if exists mails_sent;
 create table mails_sent
 (
@@ -789,6 +1061,7 @@ create table rocketpool_minipools
     deposit_type varchar(20) not null, -- none (invalid), full, half, empty .. see: https://github.com/rocket-pool/rocketpool/blob/683addf4ac/contracts/types/MinipoolDeposit.sol
     status text not null, -- Initialized, Prelaunch, Staking, Withdrawable, Dissolved .. see: https://github.com/rocket-pool/rocketpool/blob/683addf4ac/contracts/types/MinipoolStatus.sol
     status_time timestamp without time zone,
+    node_deposit_balance numeric, -- ETH bonded by the node in wei, 8 ETH for LEB8 and 16 ETH for 16-ETH minipools
 
     primary key(rocketpool_storage_address, address)
 );
@@ -803,10 +1076,105 @@ create table rocketpool_nodes
     rpl_stake numeric not null,
     min_rpl_stake numeric not null,
     max_rpl_stake numeric not null,
+    fee_distributor_address bytea, -- null before the redstone upgrade
+    smoothing_pool_opted_in bool not null default false,
+    deposit_credit numeric, -- wei, null before the atlas upgrade
+    eth_matched numeric, -- ETH borrowed from the deposit pool in wei, null before the atlas upgrade
+    eth_provided numeric, -- ETH bonded by the node in wei, null before the atlas upgrade
+    eth_matched_limit numeric, -- ETH the node can borrow with its rpl stake in wei, null before the atlas upgrade
 
     primary key(rocketpool_storage_address, address)
 );
 
+-- blocks of minipools that did not pay the execution layer rewards to the fee distributor of their node or, if the node
+-- opted in, to the smoothing pool
+drop table if exists rocketpool_fee_recipient_violations;
+create table rocketpool_fee_recipient_violations
+(
+    rocketpool_storage_address bytea not null,
+    slot int not null,
+    epoch int not null,
+    validatorindex int not null,
+    minipool_address bytea not null,
+    node_address bytea not null,
+    fee_recipient bytea not null,
+    expected_fee_recipient bytea not null,
+    smoothing_pool_opted_in bool not null,
+
+    primary key(rocketpool_storage_address, slot)
+);
+create index idx_rocketpool_fee_recipient_violations_node_address on rocketpool_fee_recipient_violations (node_address);
+create index idx_rocketpool_fee_recipient_violations_epoch on rocketpool_fee_recipient_violations (epoch);
+
+drop table if exists rocketpool_nodes_history;
+create table rocketpool_nodes_history
+(
+    rocketpool_storage_address bytea not null,
+    eth1_block int not null,
+    ts timestamp without time zone not null,
+
+    address bytea not null,
+    rpl_stake numeric not null,
+    min_rpl_stake numeric not null,
+    max_rpl_stake numeric not null,
+    minipool_count int not null,
+
+    primary key(rocketpool_storage_address, eth1_block, address)
+);
+create index idx_rocketpool_nodes_history_ts on rocketpool_nodes_history (ts);
+
+drop table if exists rocketpool_reth_history;
+create table rocketpool_reth_history
+(
+    rocketpool_storage_address bytea not null,
+    eth1_block int not null,
+    ts timestamp without time zone not null,
+
+    exchange_rate float not null, -- ETH per rETH when burning rETH at the rocketpool contract
+    market_rate float, -- ETH per rETH averaged over the configured DEX pools, null if no pool is configured
+
+    primary key(rocketpool_storage_address, eth1_block)
+);
+create index idx_rocketpool_reth_history_ts on rocketpool_reth_history (ts);
+
+drop table if exists rocketpool_odao_submissions;
+create table rocketpool_odao_submissions
+(
+    rocketpool_storage_address bytea not null,
+    kind varchar(20) not null, -- 'prices' or 'balances'
+    reference_block int not null, -- eth1-block the submitted values refer to
+    member_address bytea not null,
+
+    eth1_block int not null, -- eth1-block the submission was included in
+    tx_hash bytea not null,
+    ts timestamp without time zone not null,
+    rpl_price numeric,
+    total_eth numeric,
+    staking_eth numeric,
+    reth_supply numeric,
+
+    primary key(rocketpool_storage_address, kind, reference_block, member_address)
+);
+create index idx_rocketpool_odao_submissions_member on rocketpool_odao_submissions (member_address, kind, ts);
+
+drop table if exists rocketpool_odao_consensus;
+create table rocketpool_odao_consensus
+(
+    rocketpool_storage_address bytea not null,
+    kind varchar(20) not null,
+    reference_block int not null,
+
+    eth1_block int not null, -- eth1-block the consensus was reached in
+    tx_hash bytea not null,
+    ts timestamp without time zone not null,
+    rpl_price numeric,
+    total_eth numeric,
+    staking_eth numeric,
+    reth_supply numeric,
+
+    primary key(rocketpool_storage_address, kind, reference_block)
+);
+
 drop table if exists rocketpool_dao_proposals;
 create table rocketpool_dao_proposals
 (
@@ -828,6 +1196,7 @@ create table rocketpool_dao_proposals
     is_cancelled boolean not null,
     is_executed boolean not null,
     payload bytea not null,
+    payload_decoded text not null default '',
     state text not null,
 
     primary key(rocketpool_storage_address, id)
@@ -848,3 +1217,328 @@ create table rocketpool_dao_members
 
     primary key(rocketpool_storage_address, address)
 );
+
+drop table if exists network_participation;
+create table network_participation
+(
+    epoch              int   not null,
+    participation_rate float not null,
+    finality_delay     int   not null,
+    ts                 timestamp without time zone not null,
+    primary key (epoch)
+);
+
+drop table if exists network_incidents;
+create table network_incidents
+(
+    id                 serial,
+    type               varchar(40) not null, -- low_participation, non_finality
+    start_epoch        int         not null,
+    end_epoch          int         not null, -- last affected epoch, updated while the incident is ongoing
+    min_participation  float       not null,
+    max_finality_delay int         not null,
+    resolved           bool        not null default false,
+    created_ts         timestamp without time zone not null,
+    updated_ts         timestamp without time zone not null,
+    primary key (id)
+);
+create index idx_network_incidents_type_resolved on network_incidents (type, resolved);
+
+drop table if exists block_propagation;
+create table block_propagation
+(
+    blockroot bytea                       not null,
+    slot      int                         not null,
+    node      varchar(100)                not null, -- host of the beacon node endpoint that saw the block
+    seen_ts   timestamp without time zone not null, -- first time the node emitted the block event
+    delay_ms  int                         not null, -- seen_ts relative to the start of the slot
+    primary key (blockroot, node)
+);
+create index idx_block_propagation_slot on block_propagation (slot);
+
+drop table if exists decentralization_stats;
+create table decentralization_stats
+(
+    day               int         not null,
+    dimension         varchar(30) not null, -- combined, deposit_address, withdrawal_credentials, fee_recipient, graffiti
+    clusters          int         not null,
+    validators        int         not null,
+    stake             bigint      not null,
+    gini              float       not null,
+    nakamoto          int         not null, -- minimal number of clusters controlling more than 1/3 of the stake
+    top_cluster_share float       not null,
+    primary key (day, dimension)
+);
+
+drop table if exists validator_clusters;
+create table validator_clusters
+(
+    day        int         not null,
+    dimension  varchar(30) not null,
+    cluster    text        not null,
+    validators int         not null,
+    stake      bigint      not null,
+    primary key (day, dimension, cluster)
+);
+
+/* attestation performance of the validators with a declared hosting aggregated per provider and region */
+drop table if exists hosting_stats;
+create table hosting_stats
+(
+    day                      int          not null,
+    provider                 varchar(100) not null,
+    region                   varchar(30)  not null,
+    validators               int          not null,
+    share                    float        not null,
+    effectiveness            float        not null,
+    missed_attestations_rate float        not null,
+    primary key (day, provider, region)
+);
+
+/* realized income of the validators grouped by their activation month, only validators active during the whole day are included */
+drop table if exists cohort_apr;
+create table cohort_apr
+(
+    day               int        not null,
+    cohort            varchar(7) not null, /* activation month, e.g. 2020-12 */
+    validators        int        not null,
+    effective_balance bigint     not null, /* sum of the effective balances at the start of the day */
+    income            bigint     not null, /* balance change of the day minus deposits */
+    apr               float      not null,
+    primary key (day, cohort)
+);
+
+/* daily medians the validators are compared against, of all validators and of the validators activated in the same window of epochs */
+drop table if exists validator_peer_medians;
+create table validator_peer_medians
+(
+    day                int   not null,
+    activation_window  int   not null, /* activationepoch / 1575, -1 for all validators */
+    validators         int   not null,
+    apr                float not null, /* balance change of the day minus deposits over the effective balance, extrapolated to a year */
+    effectiveness      float,          /* in percent, default formula of validator_effectiveness */
+    inclusion_distance float,          /* average distance to the optimal inclusion slot */
+    primary key (day, activation_window)
+);
+
+/* daily supply side of the network, all amounts in gwei */
+drop table if exists economics_stats;
+create table economics_stats
+(
+    day                      int    not null,
+    deposit_contract_balance bigint not null, /* sum of all deposits to the deposit contract until the end of the day */
+    total_effective_balance  bigint not null, /* effective balance of the validators active at the end of the day */
+    total_balance            bigint not null, /* balance of all validators at the end of the day */
+    circulating_supply       bigint,          /* supply of the execution layer at the time the day was exported, null for backfilled days */
+    staking_ratio            float,           /* total_effective_balance over circulating_supply */
+    issuance                 bigint not null, /* balance change of all validators of the day minus the deposits */
+    burn                     bigint not null, /* base fees of the canonical blocks of the day */
+    primary key (day)
+);
+
+drop table if exists backfill_status;
+create table backfill_status
+(
+    epoch    int  not null,
+    verified bool not null,
+    ts       timestamp without time zone not null,
+    primary key (epoch)
+);
+
+drop table if exists data_integrity_issues;
+create table data_integrity_issues
+(
+    id             serial,
+    check_type     varchar(40) not null,
+    epoch          int         not null,
+    slot           int,
+    validatorindex int,
+    db_value       text,
+    node_value     text,
+    ts             timestamp without time zone not null,
+    primary key (id)
+);
+create index idx_data_integrity_issues_epoch on data_integrity_issues (epoch);
+
+drop table if exists exporter_status;
+create table exporter_status
+(
+    name            varchar(40) not null,
+    last_success_ts timestamp without time zone not null,
+    primary key (name)
+);
+
+drop table if exists attestation_aggregation_stats;
+create table attestation_aggregation_stats
+(
+    epoch                  int         not null,
+    client                 varchar(20) not null, /* consensus client of the proposers, guessed from the graffiti */
+    blocks                 int         not null,
+    aggregates             int         not null,
+    committees             int         not null, /* distinct committees the aggregates belong to */
+    attestations           int         not null, /* votes of all aggregates */
+    redundant_attestations int         not null, /* votes that were already included in an earlier block of the epoch */
+    redundant_aggregates   int         not null, /* aggregates that only contain already included votes */
+    bytes_saved            bigint      not null,
+    primary key (epoch, client)
+);
+
+drop table if exists attestation_correctness_stats;
+create table attestation_correctness_stats
+(
+    epoch        int not null,
+    assignments  int not null,
+    attestations int not null, /* assignments with an included attestation */
+    wrong_source int not null, /* assignments without a correct source vote, including the missed ones */
+    wrong_target int not null,
+    wrong_head   int not null,
+    primary key (epoch)
+);
+
+/* The highest bid of every builder per slot and relay from the bid traces of the data api of the mev-boost relays, bids
+   received too late to be chosen by the proposer are not considered, values in wei */
+drop table if exists relay_bids;
+create table relay_bids
+(
+    slot           int          not null,
+    relay          varchar(100) not null,
+    builder_pubkey bytea        not null,
+    block_hash     bytea        not null,
+    value          numeric      not null,
+    bids           int          not null,
+    primary key (slot, relay, builder_pubkey)
+);
+
+/* The execution payload auction of every slot over all relays: the payload of the canonical block a relay delivered to
+   the proposer (null for missed slots and locally built blocks) and the distribution of the highest bids of the builders,
+   values in wei */
+drop table if exists relay_auctions;
+create table relay_auctions
+(
+    slot           int          not null,
+    relay          varchar(100),
+    builder_pubkey bytea,
+    block_hash     bytea,
+    winning_bid    numeric,
+    second_bid     numeric,
+    top_bid        numeric,
+    median_bid     numeric,
+    bids           int          not null,
+    builders       int          not null,
+    primary key (slot)
+);
+
+drop table if exists blocks_packing;
+create table blocks_packing
+(
+    slot                   int    not null,
+    blockroot              bytea  not null,
+    epoch                  int    not null,
+    proposer               int    not null,
+    client                 text   not null, /* guessed from the graffiti */
+    attestations_included  int    not null, /* attestation duties included for the first time by the block */
+    attestations_available int    not null, /* duties within the inclusion window not included by an earlier block that got included eventually */
+    sync_included          int    not null,
+    sync_available         int    not null, /* sync committee members that signed in any block of the epoch */
+    primary key (slot, blockroot)
+);
+create index idx_blocks_packing_epoch on blocks_packing (epoch);
+create index idx_blocks_packing_proposer on blocks_packing (proposer);
+
+drop table if exists validator_packing;
+create table validator_packing
+(
+    validatorindex         int    not null,
+    blocks                 int    not null,
+    attestations_included  bigint not null,
+    attestations_available bigint not null,
+    sync_included          bigint not null,
+    sync_available         bigint not null,
+    primary key (validatorindex)
+);
+
+drop table if exists validator_effectiveness;
+create table validator_effectiveness
+(
+    validatorindex int         not null,
+    day            int         not null,
+    formula        varchar(40) not null,
+    effectiveness  float       not null, /* in percent */
+    primary key (validatorindex, day, formula)
+);
+
+drop table if exists users_validator_ownership;
+create table users_validator_ownership
+(
+    user_id             int                         not null,
+    validator_publickey bytea                       not null,
+    method              varchar(20)                 not null,
+    address             bytea,
+    message             text                        not null,
+    signature           bytea                       not null,
+    verified_ts         timestamp without time zone not null,
+    primary key (user_id, validator_publickey)
+);
+create index idx_users_validator_ownership_validator_publickey on users_validator_ownership (validator_publickey);
+
+/* opt-in hosting of validators declared by their verified owners, the ip-addresses of metrics submissions are not stored */
+drop table if exists users_validator_hosting;
+create table users_validator_hosting
+(
+    user_id             int                         not null,
+    validator_publickey bytea                       not null,
+    provider            varchar(100)                not null, -- empty until the first metrics submission if inferred
+    region              varchar(30)                 not null,
+    source              varchar(10)                 not null, -- declared or metrics
+    updated_ts          timestamp without time zone not null,
+    primary key (user_id, validator_publickey)
+);
+
+drop table if exists users_custom_charts;
+create table users_custom_charts
+(
+    id         varchar(20)                 not null,
+    user_id    int                         not null,
+    definition text                        not null,
+    created_ts timestamp without time zone not null,
+    primary key (id)
+);
+create index idx_users_custom_charts_user_id on users_custom_charts (user_id);
+
+/* the logins of the users, a session cookie is only valid as long as its key is stored here */
+drop table if exists users_sessions;
+create table users_sessions
+(
+    id          serial                      not null,
+    user_id     int                         not null,
+    session_key varchar(40)                 not null,
+    device      varchar(50)                 not null,
+    user_agent  text                        not null,
+    ip          varchar(45)                 not null,
+    created_ts  timestamp without time zone not null,
+    last_seen   timestamp without time zone not null,
+    primary key (id),
+    unique (session_key)
+);
+create index idx_users_sessions_user_id on users_sessions (user_id);
+
+drop table if exists users_deletion_requests;
+create table users_deletion_requests
+(
+    user_id      int                         not null,
+    requested_ts timestamp without time zone not null,
+    scheduled_ts timestamp without time zone not null,
+    primary key (user_id)
+);
+
+/* anonymized aggregates of deleted accounts */
+drop table if exists users_deleted;
+create table users_deleted
+(
+    id                  serial                      not null,
+    register_ts         timestamp without time zone,
+    deleted_ts          timestamp without time zone not null,
+    validators_count    int                         not null,
+    subscriptions_count int                         not null,
+    primary key (id)
+);
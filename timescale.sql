@@ -0,0 +1,61 @@
+/*
+ Optional TimescaleDB schema, import it after tables.sql into an explorer database with the timescaledb extension
+ available and enable timescale in the config. The exporter then mirrors the validator balances and the network stats
+ of every epoch into the hypertables and the long-range charts are computed from the continuous aggregates.
+ The epochs exported before timescale was enabled are mirrored and the aggregates refreshed over their whole range with
+ `backfill -timescale -slots.start 0 -slots.end <latest slot>`, the aggregate policies only refresh the last 3 days.
+ */
+create extension if not exists timescaledb;
+
+drop table if exists validator_balances_ts cascade;
+create table validator_balances_ts
+(
+    ts               timestamptz not null, /* start of the epoch */
+    epoch            int         not null,
+    validatorindex   int         not null,
+    balance          bigint      not null,
+    effectivebalance bigint      not null,
+    primary key (validatorindex, ts)
+);
+select create_hypertable('validator_balances_ts', 'ts', chunk_time_interval => interval '1 day');
+
+drop table if exists network_stats_ts cascade;
+create table network_stats_ts
+(
+    ts                      timestamptz not null, /* start of the epoch */
+    epoch                   int         not null,
+    validatorscount         int         not null,
+    eligibleether           bigint      not null,
+    averagevalidatorbalance bigint      not null,
+    globalparticipationrate float       not null,
+    primary key (ts)
+);
+select create_hypertable('network_stats_ts', 'ts', chunk_time_interval => interval '30 days');
+
+/* daily balances of every validator, the income of a day is the balance change minus the deposits of the day */
+create materialized view validator_balances_daily with (timescaledb.continuous) as
+select
+    time_bucket('1 day', ts)   as day,
+    validatorindex,
+    first(balance, ts)         as start_balance,
+    last(balance, ts)          as end_balance,
+    min(balance)               as min_balance,
+    max(balance)               as max_balance,
+    last(effectivebalance, ts) as end_effective_balance
+from validator_balances_ts
+group by day, validatorindex
+with no data;
+select add_continuous_aggregate_policy('validator_balances_daily', start_offset => interval '3 days', end_offset => interval '1 hour', schedule_interval => interval '1 hour');
+
+/* daily network stats, the counts are taken from the last epoch of the day */
+create materialized view network_stats_daily with (timescaledb.continuous) as
+select
+    time_bucket('1 day', ts)     as day,
+    last(validatorscount, ts)    as validatorscount,
+    last(eligibleether, ts)      as eligibleether,
+    avg(averagevalidatorbalance) as averagevalidatorbalance,
+    avg(globalparticipationrate) as globalparticipationrate
+from network_stats_ts
+group by day
+with no data;
+select add_continuous_aggregate_policy('network_stats_daily', start_offset => interval '3 days', end_offset => interval '1 hour', schedule_interval => interval '1 hour');
@@ -0,0 +1,204 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var registeredDrivers = map[string]string{}
+var registeredDriversMux = &sync.Mutex{}
+
+// TracedDriverName registers a wrapper of the sql driver that records a span for every query executed with a context
+// carrying a span (e.g. via sqlx SelectContext or ExecContext) and returns its name. Queries without a parent span are
+// not traced to not flood the exporter with unrelated root spans.
+func TracedDriverName(driverName string) (string, error) {
+	registeredDriversMux.Lock()
+	defer registeredDriversMux.Unlock()
+
+	if name, exists := registeredDrivers[driverName]; exists {
+		return name, nil
+	}
+
+	// sql.Open does not connect, it is only used to look up the registered driver
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return "", err
+	}
+	d := db.Driver()
+	db.Close()
+
+	name := driverName + "-traced"
+	sql.Register(name, &tracedDriver{Driver: d})
+	registeredDrivers[driverName] = name
+	return name, nil
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span, bool) {
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx, nil, false
+	}
+	ctx, span := StartSpan(ctx, "db.query",
+		semconv.DBSystemPostgreSQL,
+		semconv.DBStatementKey.String(query),
+		attribute.String("db.operation", queryOperation(query)))
+	return ctx, span, true
+}
+
+// queryOperation returns the first keyword of the query, e.g. select or insert
+func queryOperation(query string) string {
+	var op string
+	fmt.Sscan(query, &op)
+	return op
+}
+
+type tracedDriver struct {
+	driver.Driver
+}
+
+func (d *tracedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: c}, nil
+}
+
+type tracedConn struct {
+	driver.Conn
+}
+
+// Unwrap returns the connection of the wrapped driver, e.g. to use the pgx connection via sql.Conn.Raw
+func (c *tracedConn) Unwrap() driver.Conn {
+	return c.Conn
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span, traced := startQuerySpan(ctx, query)
+	res, err := execer.ExecContext(ctx, query, args)
+	if traced {
+		EndSpan(span, err)
+	}
+	return res, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span, traced := startQuerySpan(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if traced {
+		EndSpan(span, err)
+	}
+	return rows, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, query: query}, nil
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue passes the argument conversion on to the wrapped driver, pgx supports more types than the default
+// converter of database/sql (e.g. uint64 values above the int64 range)
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tracedStmt records a span for every execution of a prepared statement, e.g. of the statements the indexer prepares
+// for its bulk inserts
+type tracedStmt struct {
+	driver.Stmt
+	query string
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span, traced := startQuerySpan(ctx, s.query)
+	var res driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(namedValues(args))
+	}
+	if traced {
+		EndSpan(span, err)
+	}
+	return res, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span, traced := startQuerySpan(ctx, s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args))
+	}
+	if traced {
+		EndSpan(span, err)
+	}
+	return rows, err
+}
+
+// CheckNamedValue passes the argument conversion on to the wrapped statement like tracedConn.CheckNamedValue
+func (s *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValues returns the values of the arguments for statements that do not support contexts
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return values
+}
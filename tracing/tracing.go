@@ -0,0 +1,94 @@
+// Package tracing configures the opentelemetry tracer provider and provides the spans of http handlers, db queries and
+// exporter runs, spans are exported via otlp or to jaeger as configured
+package tracing
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "eth2-exporter"
+
+var enabled bool
+
+// Init sets up the global tracer provider according to the config, the returned function flushes and stops the
+// exporter and should be called on shutdown. If tracing is disabled all spans are no-ops.
+func Init(cfg *types.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !cfg.Tracing.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case "", "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+		if cfg.Tracing.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Tracing.Endpoint))
+		}
+		exporter, err = otlptracegrpc.New(context.Background(), opts...)
+	case "jaeger":
+		opts := []jaeger.CollectorEndpointOption{}
+		if cfg.Tracing.Endpoint != "" {
+			opts = append(opts, jaeger.WithEndpoint(cfg.Tracing.Endpoint))
+		}
+		exporter, err = jaeger.New(jaeger.WithCollectorEndpoint(opts...))
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %v, must be otlp or jaeger", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating %v trace exporter: %w", cfg.Tracing.Exporter, err)
+	}
+
+	serviceName := cfg.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = "eth2-explorer"
+	}
+	sampleRatio := cfg.Tracing.SampleRatio
+	if sampleRatio <= 0 {
+		sampleRatio = 1
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	enabled = true
+
+	return tp.Shutdown, nil
+}
+
+// Enabled returns whether spans are exported
+func Enabled() bool {
+	return enabled
+}
+
+// StartSpan starts a span as child of the span in the context
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records the error on the span, if any, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -1,8 +1,10 @@
 package types
 
 type ApiResponse struct {
-	Status string      `json:"status"`
-	Data   interface{} `json:"data"`
+	Status     string      `json:"status"`
+	Data       interface{} `json:"data"`
+	NextCursor *string     `json:"next_cursor,omitempty"`
+	HasMore    *bool       `json:"has_more,omitempty"`
 }
 
 type StatsSystem struct {
@@ -85,4 +87,111 @@ type UsersNotificationsRequest struct {
 
 type DashboardRequest struct {
 	IndicesOrPubKey string `json:"indicesOrPubkey"`
+	// Tag restricts the dashboard to the validators of a tag of the authenticated user
+	Tag string `json:"tag"`
+}
+
+// BulkValidatorsRequest is the body of the POST variants of the validator endpoints, validators can be given as
+// indices (number or string) or as hex encoded public keys
+type BulkValidatorsRequest struct {
+	IndicesOrPubkey []interface{} `json:"indicesOrPubkey"`
+	Epochs          uint64        `json:"epochs"`
+}
+
+// CompareRequest is the body of the validator set comparison, the dates are formatted as YYYY-MM-DD
+type CompareRequest struct {
+	Sets      []*CompareSetRequest `json:"sets"`
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+}
+
+// CompareSetRequest selects the validators of a set by their indices or pubkeys, by a tag of the validators (e.g.
+// rocketpool or ssv) or by the name of a staking pool (e.g. lido), the selections are combined
+type CompareSetRequest struct {
+	Name            string        `json:"name"`
+	IndicesOrPubkey []interface{} `json:"indicesOrPubkey"`
+	Tag             string        `json:"tag"`
+	Pool            string        `json:"pool"`
+}
+
+// FeedResponse is the envelope of the public bot feeds, the schema of the items only changes together with the version
+type FeedResponse struct {
+	Version   uint64      `json:"version"`
+	UpdatedTs int64       `json:"updated_ts"`
+	Items     interface{} `json:"items"`
+}
+
+type FeedSlashing struct {
+	Slot              uint64   `json:"slot"`
+	Epoch             uint64   `json:"epoch"`
+	Ts                int64    `json:"ts"`
+	Type              string   `json:"type"`
+	Proposer          uint64   `json:"proposer"`
+	SlashedValidators []uint64 `json:"slashed_validators"`
+}
+
+type FeedExit struct {
+	Slot           uint64 `db:"slot" json:"slot"`
+	Epoch          uint64 `db:"epoch" json:"epoch"`
+	Ts             int64  `db:"-" json:"ts"`
+	ValidatorIndex uint64 `db:"validatorindex" json:"validatorindex"`
+}
+
+type FeedBlock struct {
+	Slot      uint64 `db:"slot" json:"slot"`
+	Epoch     uint64 `db:"epoch" json:"epoch"`
+	Ts        int64  `db:"-" json:"ts"`
+	Proposer  uint64 `db:"proposer" json:"proposer"`
+	Status    string `db:"status" json:"status"`
+	BlockRoot string `db:"-" json:"block_root"`
+	Root      []byte `db:"blockroot" json:"-"`
+}
+
+// ApiValidatorBalance is a balance of a validator at an epoch as returned by the balance history endpoints, the fields
+// are named like the columns of validator_balances_p
+type ApiValidatorBalance struct {
+	Epoch            uint64 `db:"epoch" json:"epoch"`
+	Validatorindex   uint64 `db:"validatorindex" json:"validatorindex"`
+	Balance          uint64 `db:"balance" json:"balance"`
+	EffectiveBalance uint64 `db:"effectivebalance" json:"effectivebalance"`
+	Week             uint64 `db:"week" json:"week"`
+}
+
+// ApiValidatorBalanceBucket is the balance of a validator over a day or a week of the downsampled balance history
+type ApiValidatorBalanceBucket struct {
+	Validatorindex      uint64 `db:"validatorindex" json:"validatorindex"`
+	StartEpoch          uint64 `db:"start_epoch" json:"start_epoch"`
+	EndEpoch            uint64 `db:"end_epoch" json:"end_epoch"`
+	StartBalance        uint64 `db:"start_balance" json:"start_balance"`
+	EndBalance          uint64 `db:"end_balance" json:"end_balance"`
+	MinBalance          uint64 `db:"min_balance" json:"min_balance"`
+	MaxBalance          uint64 `db:"max_balance" json:"max_balance"`
+	EndEffectiveBalance uint64 `db:"end_effective_balance" json:"end_effective_balance"`
+}
+
+// ApiAttestationAssignment is an attestation duty of a validator as returned by the attestation endpoints, the fields
+// are named like the columns of attestation_assignments_p
+type ApiAttestationAssignment struct {
+	Epoch          uint64 `db:"epoch" json:"epoch"`
+	Validatorindex uint64 `db:"validatorindex" json:"validatorindex"`
+	AttesterSlot   uint64 `db:"attesterslot" json:"attesterslot"`
+	CommitteeIndex uint64 `db:"committeeindex" json:"committeeindex"`
+	Status         uint64 `db:"status" json:"status"` // 0 = scheduled, 1 executed, 2 missed
+	InclusionSlot  uint64 `db:"inclusionslot" json:"inclusionslot"`
+	Week           uint64 `db:"week" json:"week"`
+}
+
+// ApiValidatorSnapshot is the state of a validator in the registry at an epoch as returned by the validator set
+// snapshot endpoint, the exit epoch is only set if the exit had been initiated at the epoch
+type ApiValidatorSnapshot struct {
+	Epoch                      uint64  `db:"epoch" json:"epoch"`
+	Validatorindex             uint64  `db:"validatorindex" json:"validatorindex"`
+	Pubkey                     string  `db:"pubkey" json:"pubkey"`
+	Status                     string  `db:"status" json:"status"`
+	Balance                    uint64  `db:"balance" json:"balance"`
+	EffectiveBalance           uint64  `db:"effectivebalance" json:"effectivebalance"`
+	Slashed                    bool    `db:"slashed" json:"slashed"`
+	ActivationEligibilityEpoch *uint64 `db:"activationeligibilityepoch" json:"activationeligibilityepoch"`
+	ActivationEpoch            *uint64 `db:"activationepoch" json:"activationepoch"`
+	ExitEpoch                  *uint64 `db:"exitepoch" json:"exitepoch"`
 }
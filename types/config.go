@@ -12,6 +12,12 @@ type Config struct {
 		Name     string `yaml:"name" envconfig:"DB_NAME"`
 		Host     string `yaml:"host" envconfig:"DB_HOST"`
 		Port     string `yaml:"port" envconfig:"DB_PORT"`
+		// Pool is the pool of the frontend reads and of all components without a dedicated pool
+		Pool DatabasePool `yaml:"pool"`
+		// IndexerPool is used for the epoch and block writes of the indexer, the indexer shares Pool if it is not set
+		IndexerPool DatabasePool `yaml:"indexerPool"`
+		// ExporterPool is used by the eth1-deposit, rocketpool, ssv, sync committee and tag exporters, they share Pool if it is not set
+		ExporterPool DatabasePool `yaml:"exporterPool"`
 	} `yaml:"database"`
 	Chain struct {
 		// Deprecated Use Phase0 config CONFIG_NAME
@@ -31,8 +37,35 @@ type Config struct {
 		Phase0Path      string `yaml:"phase0path" envconfig:"CHAIN_PHASE0_PATH"`
 		AltairPath      string `yaml:"altairPath" envconfig:"CHAIN_ALTAIR_PATH"`
 		AltairForkEpoch uint64 `yaml:"altairForkEpoch" envconfig:"CHAIN_ALTAIR_FORK_EPOCH"`
+		// The epochs of the later forks are optional, forks that are not configured are taken from the spec of the
+		// beacon node at frontend.beaconNodeEndpoint
+		BellatrixForkEpoch *uint64 `yaml:"bellatrixForkEpoch" envconfig:"CHAIN_BELLATRIX_FORK_EPOCH"`
+		CapellaForkEpoch   *uint64 `yaml:"capellaForkEpoch" envconfig:"CHAIN_CAPELLA_FORK_EPOCH"`
+		DenebForkEpoch     *uint64 `yaml:"denebForkEpoch" envconfig:"CHAIN_DENEB_FORK_EPOCH"`
+		ElectraForkEpoch   *uint64 `yaml:"electraForkEpoch" envconfig:"CHAIN_ELECTRA_FORK_EPOCH"`
+		ElectraPath        string  `yaml:"electraPath" envconfig:"CHAIN_ELECTRA_PATH"`
+		// Bootstrap derives the chain config from the spec and the genesis of a standard beacon node api instead of the
+		// preset files, e.g. to run the explorer against a new devnet, and creates the schema in an empty database
+		Bootstrap struct {
+			Enabled bool `yaml:"enabled" envconfig:"CHAIN_BOOTSTRAP_ENABLED"`
+			// NodeEndpoint defaults to frontend.beaconNodeEndpoint
+			NodeEndpoint string `yaml:"nodeEndpoint" envconfig:"CHAIN_BOOTSTRAP_NODE_ENDPOINT"`
+			// SchemaPath defaults to tables.sql
+			SchemaPath string `yaml:"schemaPath" envconfig:"CHAIN_BOOTSTRAP_SCHEMA_PATH"`
+		} `yaml:"bootstrap"`
+		// SpecFromNode compares the chain config with the spec of a standard beacon node api at startup, parameters
+		// missing in the config and the preset files are taken from the node and the startup fails if a parameter
+		// differs, unless it is listed in the overrides
+		SpecFromNode struct {
+			Enabled bool `yaml:"enabled" envconfig:"CHAIN_SPEC_FROM_NODE_ENABLED"`
+			// NodeEndpoint defaults to frontend.beaconNodeEndpoint
+			NodeEndpoint string `yaml:"nodeEndpoint" envconfig:"CHAIN_SPEC_FROM_NODE_ENDPOINT"`
+			// Overrides are the spec parameters (e.g. SLOTS_PER_EPOCH) whose local value is kept if it differs from the node
+			Overrides []string `yaml:"overrides" envconfig:"CHAIN_SPEC_FROM_NODE_OVERRIDES"`
+		} `yaml:"specFromNode"`
 		Phase0
 		Altair
+		Electra
 	} `yaml:"chain"`
 	Indexer struct {
 		Enabled                     bool `yaml:"enabled" envconfig:"INDEXER_ENABLED"`
@@ -46,8 +79,12 @@ type Config struct {
 			Host     string `yaml:"host" envconfig:"INDEXER_NODE_HOST"`
 			Type     string `yaml:"type" envconfig:"INDEXER_NODE_TYPE"`
 			PageSize int32  `yaml:"pageSize" envconfig:"INDEXER_NODE_PAGE_SIZE"`
+			// Fallbacks are additional host:port addresses of nodes of the same type that are used if the primary node is unhealthy
+			Fallbacks []string `yaml:"fallbacks" envconfig:"INDEXER_NODE_FALLBACKS"`
 		} `yaml:"node"`
 		Eth1Endpoint string `yaml:"eth1Endpoint" envconfig:"INDEXER_ETH1_ENDPOINT"`
+		// Eth1FallbackEndpoints are additional http endpoints that are used if the eth1 endpoint is unhealthy
+		Eth1FallbackEndpoints []string `yaml:"eth1FallbackEndpoints" envconfig:"INDEXER_ETH1_FALLBACK_ENDPOINTS"`
 		// Deprecated Please use Phase0 config DEPOSIT_CONTRACT_ADDRESS
 		Eth1DepositContractAddress    string `yaml:"eth1DepositContractAddress" envconfig:"INDEXER_ETH1_DEPOSIT_CONTRACT_ADDRESS"`
 		Eth1DepositContractFirstBlock uint64 `yaml:"eth1DepositContractFirstBlock" envconfig:"INDEXER_ETH1_DEPOSIT_CONTRACT_FIRST_BLOCK"`
@@ -57,10 +94,100 @@ type Config struct {
 			EndEpoch   uint64   `yaml:"endEpoch" envconfig:"INDEXER_ONETIMEEXPORT_END_EPOCH"`
 			Epochs     []uint64 `yaml:"epochs" envconfig:"INDEXER_ONETIMEEXPORT_EPOCHS"`
 		} `yaml:"onetimeexport"`
+		// EpochPipeline overlaps fetching and saving of consecutive epochs when several epochs are exported, e.g. after
+		// downtime. FetchWorkers epochs are fetched concurrently and at most Depth fetched epochs wait to be saved, the
+		// epochs are always saved in order.
+		EpochPipeline struct {
+			FetchWorkers int `yaml:"fetchWorkers" envconfig:"INDEXER_EPOCH_PIPELINE_FETCH_WORKERS"`
+			Depth        int `yaml:"depth" envconfig:"INDEXER_EPOCH_PIPELINE_DEPTH"`
+		} `yaml:"epochPipeline"`
 		PubKeyTagsExporter struct {
 			Enabled bool `yaml:"enabled" envconfig:"PUBKEY_TAGS_EXPORTER_ENABLED"`
 		} `yaml:"pubkeyTagsExporter"`
+		NetworkIncidents struct {
+			Enabled                bool    `yaml:"enabled" envconfig:"INDEXER_NETWORK_INCIDENTS_ENABLED"`
+			ParticipationThreshold float64 `yaml:"participationThreshold" envconfig:"INDEXER_NETWORK_INCIDENTS_PARTICIPATION_THRESHOLD"`
+			FinalityDelayThreshold uint64  `yaml:"finalityDelayThreshold" envconfig:"INDEXER_NETWORK_INCIDENTS_FINALITY_DELAY_THRESHOLD"`
+		} `yaml:"networkIncidents"`
+		DataIntegrity struct {
+			Enabled           bool   `yaml:"enabled" envconfig:"INDEXER_DATA_INTEGRITY_ENABLED"`
+			IntervalSeconds   uint64 `yaml:"intervalSeconds" envconfig:"INDEXER_DATA_INTEGRITY_INTERVAL_SECONDS"`
+			BalanceSampleSize uint64 `yaml:"balanceSampleSize" envconfig:"INDEXER_DATA_INTEGRITY_BALANCE_SAMPLE_SIZE"`
+		} `yaml:"dataIntegrity"`
+		// BlockPropagation records when the blocks are first seen by each of the endpoints (urls of standard beacon node
+		// apis) to compute the propagation delay of the proposers and clients
+		BlockPropagation struct {
+			Enabled         bool     `yaml:"enabled" envconfig:"INDEXER_BLOCK_PROPAGATION_ENABLED"`
+			Endpoints       []string `yaml:"endpoints" envconfig:"INDEXER_BLOCK_PROPAGATION_ENDPOINTS"`
+			LateThresholdMs uint64   `yaml:"lateThresholdMs" envconfig:"INDEXER_BLOCK_PROPAGATION_LATE_THRESHOLD_MS"`
+			// RetentionDays is the number of days the observed blocks are kept, it defaults to 90 days
+			RetentionDays uint64 `yaml:"retentionDays" envconfig:"INDEXER_BLOCK_PROPAGATION_RETENTION_DAYS"`
+		} `yaml:"blockPropagation"`
+		// AttestationCorrectness evaluates the source, target and head votes of the attestations of every finalized
+		// epoch against the canonical chain
+		AttestationCorrectness struct {
+			Enabled    bool   `yaml:"enabled" envconfig:"INDEXER_ATTESTATION_CORRECTNESS_ENABLED"`
+			StartEpoch uint64 `yaml:"startEpoch" envconfig:"INDEXER_ATTESTATION_CORRECTNESS_START_EPOCH"`
+		} `yaml:"attestationCorrectness"`
+		// BlockPacking evaluates how many of the available attestations and sync committee signatures the proposers
+		// included, an epoch is evaluated once the next epoch is finalized
+		BlockPacking struct {
+			Enabled    bool   `yaml:"enabled" envconfig:"INDEXER_BLOCK_PACKING_ENABLED"`
+			StartEpoch uint64 `yaml:"startEpoch" envconfig:"INDEXER_BLOCK_PACKING_START_EPOCH"`
+		} `yaml:"blockPacking"`
+		// ElRewards stores the value the proposer of every canonical block received for the execution payload in wei, the
+		// payment of the builder for relay blocks, else the priority fees read from the receipts of the eth1 endpoint
+		// (eth_getBlockReceipts)
+		ElRewards struct {
+			Enabled bool `yaml:"enabled" envconfig:"INDEXER_EL_REWARDS_ENABLED"`
+		} `yaml:"elRewards"`
+		// RelayBids indexes the bid traces and delivered payloads of the data api of the mev-boost relays (urls) for the
+		// slot auction analytics, the highest bid of every builder per slot and relay is stored
+		RelayBids struct {
+			Enabled   bool     `yaml:"enabled" envconfig:"INDEXER_RELAY_BIDS_ENABLED"`
+			Relays    []string `yaml:"relays" envconfig:"INDEXER_RELAY_BIDS_RELAYS"`
+			StartSlot uint64   `yaml:"startSlot" envconfig:"INDEXER_RELAY_BIDS_START_SLOT"`
+		} `yaml:"relayBids"`
+		// AttestationAssignmentsRetentionWeeks drops the weeks of attestation_assignments_p that are older than the
+		// given number of weeks, the attestation history of older epochs is only kept in attestation_bitfields. The
+		// last 8 days and the weeks whose statistics, streaks or attestation correctness have not been exported are
+		// always kept. The frontend reads the epochs before the retention from attestation_bitfields, so it has to be
+		// configured with the same value. 0 keeps all weeks.
+		AttestationAssignmentsRetentionWeeks uint64 `yaml:"attestationAssignmentsRetentionWeeks" envconfig:"INDEXER_ATTESTATION_ASSIGNMENTS_RETENTION_WEEKS"`
 	} `yaml:"indexer"`
+	// Economics configures the daily economics stats of the statistics exporter, the circulating supply and with it the
+	// staking ratio are only stored if the url of an etherscan compatible ethsupply endpoint is set
+	Economics struct {
+		SupplyEndpoint string `yaml:"supplyEndpoint" envconfig:"ECONOMICS_SUPPLY_ENDPOINT"`
+	} `yaml:"economics"`
+	// Decentralization configures the validator clustering of the statistics exporter, the fee recipients of blocks of
+	// the listed addresses (e.g. builders and relays paying the proposer) are not used to cluster validators
+	Decentralization struct {
+		ExcludedFeeRecipients []string `yaml:"excludedFeeRecipients" envconfig:"DECENTRALIZATION_EXCLUDED_FEE_RECIPIENTS"`
+	} `yaml:"decentralization"`
+	// Timescale mirrors the validator balances and network stats of every epoch into the TimescaleDB hypertables of
+	// timescale.sql and computes the long-range charts from their continuous aggregates
+	Timescale struct {
+		Enabled bool `yaml:"enabled" envconfig:"TIMESCALE_ENABLED"`
+	} `yaml:"timescale"`
+	// ValidatorPubkeyCache shares the mapping of validator indices to public keys between the processes through the
+	// redis at the endpoint (host:port), lookups fall back to the database if it is not set or unreachable
+	ValidatorPubkeyCache struct {
+		RedisEndpoint string `yaml:"redisEndpoint" envconfig:"VALIDATOR_PUBKEY_CACHE_REDIS_ENDPOINT"`
+	} `yaml:"validatorPubkeyCache"`
+	// ReadBackends select the storage backend the api reads a table family from by the name of a registered repository
+	// driver and its data source name. The explorer database is used if no driver is set, the postgres driver reads from
+	// the explorer database as well unless a dsn (e.g. of a read replica) is set.
+	ReadBackends struct {
+		Balances struct {
+			Driver string `yaml:"driver" envconfig:"READ_BACKENDS_BALANCES_DRIVER"`
+			DSN    string `yaml:"dsn" envconfig:"READ_BACKENDS_BALANCES_DSN"`
+		} `yaml:"balances"`
+		Attestations struct {
+			Driver string `yaml:"driver" envconfig:"READ_BACKENDS_ATTESTATIONS_DRIVER"`
+			DSN    string `yaml:"dsn" envconfig:"READ_BACKENDS_ATTESTATIONS_DSN"`
+		} `yaml:"attestations"`
+	} `yaml:"readBackends"`
 	Frontend struct {
 		BeaconchainETHPoolBridgeSecret string `yaml:"beaconchainETHPoolBridgeSecret" envconfig:"FRONTEND_BEACONCHAIN_ETHPOOL_BRIDGE_SECRET"`
 		Kong                           string `yaml:"kong" envconfig:"FRONTEND_KONG"`
@@ -74,19 +201,49 @@ type Config struct {
 		// Imprint is deprecated place imprint file into the legal directory
 		Imprint      string `yaml:"imprint" envconfig:"FRONTEND_IMPRINT"`
 		LegalDir     string `yaml:"legalDir" envconfig:"FRONTEND_LEGAL"`
+		LocalesDir   string `yaml:"localesDir" envconfig:"FRONTEND_LOCALES_DIR"`
 		SiteDomain   string `yaml:"siteDomain" envconfig:"FRONTEND_SITE_DOMAIN"`
 		SiteName     string `yaml:"siteName" envconfig:"FRONTEND_SITE_NAME"`
 		SiteSubtitle string `yaml:"siteSubtitle" envconfig:"FRONTEND_SITE_SUBTITLE"`
-		Server       struct {
+		// ChartRenderer is either "native" to render /charts/{name}.png and .svg from the chart data or "screenshot" to serve
+		// the png images of the chartshotter, which ignore the size and range parameters
+		ChartRenderer string `yaml:"chartRenderer" envconfig:"FRONTEND_CHART_RENDERER"`
+		// BeaconNodeEndpoint is the url of the standard beacon node api signed messages of the tools (e.g. bls changes) are
+		// broadcast to
+		BeaconNodeEndpoint string `yaml:"beaconNodeEndpoint" envconfig:"FRONTEND_BEACON_NODE_ENDPOINT"`
+		// ClientIPHeader is the header the trusted proxy in front of the frontend (e.g. the load balancer) sets to the
+		// address of the client, e.g. "X-Forwarded-For". The address of the connection is used if it is empty.
+		ClientIPHeader string `yaml:"clientIPHeader" envconfig:"FRONTEND_CLIENT_IP_HEADER"`
+		// PersonalMode is a lightweight deployment next to the beacon node of a solo staker: accounts, notifications and
+		// the premium paths are disabled, the indexer only stores the watched validators (and the block headers and the
+		// attestations of the watched validators) and the dashboard of the watched validators is served at the root. The
+		// block packing and the block tree need all attestations and are disabled.
+		PersonalMode struct {
+			Enabled    bool     `yaml:"enabled" envconfig:"FRONTEND_PERSONAL_MODE_ENABLED"`
+			Validators []uint64 `yaml:"validators" envconfig:"FRONTEND_PERSONAL_MODE_VALIDATORS"`
+		} `yaml:"personalMode"`
+		// Captcha protects the signup and contact forms, the provider is "recaptcha" (v3), "hcaptcha" or "turnstile". The
+		// score threshold is the minimum score of recaptcha (0.5 if 0) and the maximum risk score of hcaptcha enterprise
+		// (ignored if 0), turnstile has no score. Routes restricts the protected forms, all forms are protected if it is
+		// empty. The recaptcha keys above are used with the recaptcha provider if no provider is set.
+		Captcha struct {
+			Provider       string   `yaml:"provider" envconfig:"FRONTEND_CAPTCHA_PROVIDER"`
+			SiteKey        string   `yaml:"siteKey" envconfig:"FRONTEND_CAPTCHA_SITE_KEY"`
+			SecretKey      string   `yaml:"secretKey" envconfig:"FRONTEND_CAPTCHA_SECRET_KEY"`
+			ScoreThreshold float64  `yaml:"scoreThreshold" envconfig:"FRONTEND_CAPTCHA_SCORE_THRESHOLD"`
+			Routes         []string `yaml:"routes" envconfig:"FRONTEND_CAPTCHA_ROUTES"`
+		} `yaml:"captcha"`
+		Server struct {
 			Port string `yaml:"port" envconfig:"FRONTEND_SERVER_PORT"`
 			Host string `yaml:"host" envconfig:"FRONTEND_SERVER_HOST"`
 		} `yaml:"server"`
 		Database struct {
-			Username string `yaml:"user" envconfig:"FRONTEND_DB_USERNAME"`
-			Password string `yaml:"password" envconfig:"FRONTEND_DB_PASSWORD"`
-			Name     string `yaml:"name" envconfig:"FRONTEND_DB_NAME"`
-			Host     string `yaml:"host" envconfig:"FRONTEND_DB_HOST"`
-			Port     string `yaml:"port" envconfig:"FRONTEND_DB_PORT"`
+			Username string       `yaml:"user" envconfig:"FRONTEND_DB_USERNAME"`
+			Password string       `yaml:"password" envconfig:"FRONTEND_DB_PASSWORD"`
+			Name     string       `yaml:"name" envconfig:"FRONTEND_DB_NAME"`
+			Host     string       `yaml:"host" envconfig:"FRONTEND_DB_HOST"`
+			Port     string       `yaml:"port" envconfig:"FRONTEND_DB_PORT"`
+			Pool     DatabasePool `yaml:"pool"`
 		} `yaml:"database"`
 		Stripe struct {
 			SecretKey string `yaml:"secretKey" envconfig:"FRONTEND_STRIPE_SECRET_KEY"`
@@ -99,13 +256,18 @@ type Config struct {
 			Plankton  string `yaml:"plankton" envconfig:"FRONTEND_STRIPE_PLANKTON"`
 			Webhook   string `yaml:"webhook" envconfig:"FRONTEND_STRIPE_WEBHOOK"`
 		}
-		SessionSecret          string `yaml:"sessionSecret" envconfig:"FRONTEND_SESSION_SECRET"`
-		JwtSigningSecret       string `yaml:"jwtSigningSecret" envconfig:"FRONTEND_JWT_SECRET"`
-		JwtIssuer              string `yaml:"jwtIssuer" envconfig:"FRONTEND_JWT_ISSUER"`
-		JwtValidityInMinutes   int    `yaml:"jwtValidityInMinutes" envconfig:"FRONTEND_JWT_VALIDITY_INMINUTES"`
-		MaxMailsPerEmailPerDay int    `yaml:"maxMailsPerEmailPerDay" envconfig:"FRONTEND_MAX_MAIL_PER_EMAIL_PER_DAY"`
+		SessionSecret          string   `yaml:"sessionSecret" envconfig:"FRONTEND_SESSION_SECRET"`
+		JwtSigningSecret       string   `yaml:"jwtSigningSecret" envconfig:"FRONTEND_JWT_SECRET"`
+		JwtIssuer              string   `yaml:"jwtIssuer" envconfig:"FRONTEND_JWT_ISSUER"`
+		JwtValidityInMinutes   int      `yaml:"jwtValidityInMinutes" envconfig:"FRONTEND_JWT_VALIDITY_INMINUTES"`
+		MaxMailsPerEmailPerDay int      `yaml:"maxMailsPerEmailPerDay" envconfig:"FRONTEND_MAX_MAIL_PER_EMAIL_PER_DAY"`
+		AdminUserIDs           []uint64 `yaml:"adminUserIds" envconfig:"FRONTEND_ADMIN_USER_IDS"`
 		Mail                   struct {
-			SMTP struct {
+			Provider     string `yaml:"provider" envconfig:"FRONTEND_MAIL_PROVIDER"`
+			Sender       string `yaml:"sender" envconfig:"FRONTEND_MAIL_SENDER"`
+			TemplatesDir string `yaml:"templatesDir" envconfig:"FRONTEND_MAIL_TEMPLATES_DIR"`
+			WebhookToken string `yaml:"webhookToken" envconfig:"FRONTEND_MAIL_WEBHOOK_TOKEN"`
+			SMTP         struct {
 				Server   string `yaml:"server" envconfig:"FRONTEND_MAIL_SMTP_SERVER"`
 				Host     string `yaml:"host" envconfig:"FRONTEND_MAIL_SMTP_HOST"`
 				User     string `yaml:"user" envconfig:"FRONTEND_MAIL_SMTP_USER"`
@@ -116,6 +278,13 @@ type Config struct {
 				PrivateKey string `yaml:"privateKey" envconfig:"FRONTEND_MAIL_MAILGUN_PRIVATE_KEY"`
 				Sender     string `yaml:"sender" envconfig:"FRONTEND_MAIL_MAILGUN_SENDER"`
 			} `yaml:"mailgun"`
+			SES struct {
+				Region   string `yaml:"region" envconfig:"FRONTEND_MAIL_SES_REGION"`
+				TopicArn string `yaml:"topicArn" envconfig:"FRONTEND_MAIL_SES_TOPIC_ARN"`
+			} `yaml:"ses"`
+			SendGrid struct {
+				ApiKey string `yaml:"apiKey" envconfig:"FRONTEND_MAIL_SENDGRID_API_KEY"`
+			} `yaml:"sendgrid"`
 		} `yaml:"mail"`
 		GATag                  string `yaml:"gatag" envconfig:"GATAG"`
 		VerifyAppSubs          bool   `yaml:"verifyAppSubscriptions" envconfig:"FRONTEND_VERIFY_APP_SUBSCRIPTIONS"`
@@ -133,16 +302,97 @@ type Config struct {
 			Timestamp uint64        `yaml:"timestamp" envconfig:"FRONTEND_COUNTDOWN_TIMESTAMP"`
 			Info      string        `yaml:"info" envconfig:"FRONTEND_COUNTDOWN_INFO"`
 		} `yaml:"countdown"`
+		// ResponseCache caches the responses of idempotent api and page data endpoints until the next slot or epoch
+		ResponseCache struct {
+			Enabled         bool `yaml:"enabled" envconfig:"FRONTEND_RESPONSE_CACHE_ENABLED"`
+			Size            int  `yaml:"size" envconfig:"FRONTEND_RESPONSE_CACHE_SIZE"`
+			MaxStaleSeconds int  `yaml:"maxStaleSeconds" envconfig:"FRONTEND_RESPONSE_CACHE_MAX_STALE_SECONDS"`
+		} `yaml:"responseCache"`
+		// Crawlers serves the validator, epoch and block pages to search engine crawlers from a cache that is refreshed once
+		// per epoch and publishes sitemaps of all validators and of the blocks of the last SitemapBlockSlots slots
+		Crawlers struct {
+			Enabled           bool   `yaml:"enabled" envconfig:"FRONTEND_CRAWLERS_ENABLED"`
+			CacheSize         int    `yaml:"cacheSize" envconfig:"FRONTEND_CRAWLERS_CACHE_SIZE"`
+			SitemapBlockSlots uint64 `yaml:"sitemapBlockSlots" envconfig:"FRONTEND_CRAWLERS_SITEMAP_BLOCK_SLOTS"`
+		} `yaml:"crawlers"`
+		// HostingProviders are the providers validator owners can declare hosting their validators at, the node metrics
+		// submissions of owners that opted in are mapped to a provider by its ip ranges (CIDR notation) without storing the ip
+		HostingProviders []struct {
+			Name   string   `yaml:"name"`
+			Ranges []string `yaml:"ranges"`
+		} `yaml:"hostingProviders"`
+		// FeatureFlags enables or disables features of the deployment, overrides in the feature_flags table take precedence
+		FeatureFlags map[string]bool `yaml:"featureFlags"`
+		// Session configures the session cookies, SameSite is "lax" (default), "strict" or "none" and MaxAgeSeconds limits
+		// the lifetime of a login
+		Session struct {
+			Secure        bool   `yaml:"secure" envconfig:"FRONTEND_SESSION_SECURE"`
+			SameSite      string `yaml:"sameSite" envconfig:"FRONTEND_SESSION_SAME_SITE"`
+			MaxAgeSeconds int    `yaml:"maxAgeSeconds" envconfig:"FRONTEND_SESSION_MAX_AGE_SECONDS"`
+		} `yaml:"session"`
+		// Cors is the cross-origin policy of the public api and of the api of logged in users
+		Cors struct {
+			Api     CORSPolicy `yaml:"api"`
+			UserApi CORSPolicy `yaml:"userApi"`
+		} `yaml:"cors"`
+		// SecurityHeaders are sent with every response, embeddable pages like the widgets may be framed by EmbedAncestors
+		SecurityHeaders struct {
+			Enabled               bool     `yaml:"enabled" envconfig:"FRONTEND_SECURITY_HEADERS_ENABLED"`
+			ContentSecurityPolicy string   `yaml:"contentSecurityPolicy" envconfig:"FRONTEND_SECURITY_HEADERS_CSP"`
+			HSTSMaxAge            uint64   `yaml:"hstsMaxAge" envconfig:"FRONTEND_SECURITY_HEADERS_HSTS_MAX_AGE"`
+			HSTSIncludeSubdomains bool     `yaml:"hstsIncludeSubdomains" envconfig:"FRONTEND_SECURITY_HEADERS_HSTS_INCLUDE_SUBDOMAINS"`
+			FrameOptions          string   `yaml:"frameOptions" envconfig:"FRONTEND_SECURITY_HEADERS_FRAME_OPTIONS"`
+			EmbedAncestors        []string `yaml:"embedAncestors" envconfig:"FRONTEND_SECURITY_HEADERS_EMBED_ANCESTORS"`
+		} `yaml:"securityHeaders"`
 	} `yaml:"frontend"`
+	Price   PriceConfig `yaml:"price"`
 	Metrics struct {
 		Enabled bool   `yaml:"enabled" envconfig:"METRICS_ENABLED"`
 		Address string `yaml:"address" envconfig:"METRICS_ADDRESS"`
 	} `yaml:"metrics"`
+	// LeaderElection runs the exporters, the historic price service and the notifications sender on a single instance,
+	// the instances elect a leader per task via postgres advisory locks
+	LeaderElection struct {
+		Enabled bool `yaml:"enabled" envconfig:"LEADER_ELECTION_ENABLED"`
+	} `yaml:"leaderElection"`
+	Logging struct {
+		Format                 string `yaml:"format" envconfig:"LOGGING_FORMAT"` // text or json
+		Level                  string `yaml:"level" envconfig:"LOGGING_LEVEL"`
+		SlowRequestThresholdMs int    `yaml:"slowRequestThresholdMs" envconfig:"LOGGING_SLOW_REQUEST_THRESHOLD_MS"`
+		// RequestQueryBudget and RequestQueryTimeBudgetMs are the number of db queries and the time spent in them above
+		// which a http request is logged with its route, 0 disables the check
+		RequestQueryBudget       int `yaml:"requestQueryBudget" envconfig:"LOGGING_REQUEST_QUERY_BUDGET"`
+		RequestQueryTimeBudgetMs int `yaml:"requestQueryTimeBudgetMs" envconfig:"LOGGING_REQUEST_QUERY_TIME_BUDGET_MS"`
+	} `yaml:"logging"`
+	Tracing struct {
+		Enabled     bool    `yaml:"enabled" envconfig:"TRACING_ENABLED"`
+		Exporter    string  `yaml:"exporter" envconfig:"TRACING_EXPORTER"` // otlp or jaeger
+		Endpoint    string  `yaml:"endpoint" envconfig:"TRACING_ENDPOINT"`
+		ServiceName string  `yaml:"serviceName" envconfig:"TRACING_SERVICE_NAME"`
+		SampleRatio float64 `yaml:"sampleRatio" envconfig:"TRACING_SAMPLE_RATIO"`
+	} `yaml:"tracing"`
 	Notifications struct {
 		Enabled                                       bool   `yaml:"enabled" envconfig:"FRONTEND_NOTIFICATIONS_ENABLED"`
 		UserDBNotifications                           bool   `yaml:"userDbNotifications" envconfig:"FRONTEND_USERDB_NOTIFICATIONS_ENABLED"`
 		FirebaseCredentialsPath                       string `yaml:"firebaseCredentialsPath" envconfig:"FRONTEND_NOTIFICATIONS_FIREBASE_CRED_PATH"`
 		ValidatorBalanceDecreasedNotificationsEnabled bool   `yaml:"validatorBalanceDecreasedNotificationsEnabled" envconfig:"FRONTEND_VALIDATOR_BALANCE_DECREASED_NOTIFICATIONS_ENABLED"`
+		// Dedup collapses the repeats of a notification with the same event and event filter (e.g. a validator that
+		// keeps missing attestations) per user and channel, the repeats are summarized in follow-ups that are sent after
+		// the escalation intervals
+		Dedup struct {
+			Enabled bool `yaml:"enabled" envconfig:"NOTIFICATIONS_DEDUP_ENABLED"`
+			// EscalationIntervalsMinutes are the minutes between the follow-ups of an ongoing event, the last interval is
+			// repeated, defaults to 60, 360 and 720
+			EscalationIntervalsMinutes []uint64 `yaml:"escalationIntervalsMinutes" envconfig:"NOTIFICATIONS_DEDUP_ESCALATION_INTERVALS_MINUTES"`
+			// ResetMinutes is how long an event has to be quiet before it is notified immediately again, defaults to 60
+			ResetMinutes uint64 `yaml:"resetMinutes" envconfig:"NOTIFICATIONS_DEDUP_RESET_MINUTES"`
+		} `yaml:"dedup"`
+		// MaxPerHour limits the notifications a user receives per channel and hour, throttled notifications are retried
+		// in the next run, 0 disables the limit of a channel
+		MaxPerHour struct {
+			Email int `yaml:"email" envconfig:"NOTIFICATIONS_MAX_PER_HOUR_EMAIL"`
+			Push  int `yaml:"push" envconfig:"NOTIFICATIONS_MAX_PER_HOUR_PUSH"`
+		} `yaml:"maxPerHour"`
 	} `yaml:"notifications"`
 	SSVExporter struct {
 		Enabled bool   `yaml:"enabled" envconfig:"SSV_EXPORTER_ENABLED"`
@@ -152,7 +402,103 @@ type Config struct {
 		Enabled                   bool   `yaml:"enabled" envconfig:"ROCKETPOOL_EXPORTER_ENABLED"`
 		StorageContractAddress    string `yaml:"storageContractAddress" envconfig:"ROCKETPOOL_EXPORTER_STORAGE_CONTRACT_ADDRESS"`
 		StorageContractFirstBlock uint64 `yaml:"storageContractFirstBlock" envconfig:"ROCKETPOOL_EXPORTER_STORAGE_CONTRACT_FIRST_BLOCK"`
+		HistoryBlockInterval      uint64 `yaml:"historyBlockInterval" envconfig:"ROCKETPOOL_EXPORTER_HISTORY_BLOCK_INTERVAL"`
+		SmoothingPoolAddress      string `yaml:"smoothingPoolAddress" envconfig:"ROCKETPOOL_EXPORTER_SMOOTHING_POOL_ADDRESS"`
+		// RethPools are the DEX pools of rETH and WETH the secondary-market price of rETH is averaged from
+		RethPools []RocketpoolRethPool `yaml:"rethPools"`
 	} `yaml:"rocketpoolExporter"`
+	EventBus struct {
+		// Type is either "local" to deliver events within the process or "postgres" to deliver them between processes via LISTEN/NOTIFY
+		Type string `yaml:"type" envconfig:"EVENT_BUS_TYPE"`
+	} `yaml:"eventBus"`
+	// Publisher streams the indexed blocks, epochs, deposits and validator status changes to an external kafka topic or nats subject
+	Publisher struct {
+		Enabled    bool     `yaml:"enabled" envconfig:"PUBLISHER_ENABLED"`
+		Type       string   `yaml:"type" envconfig:"PUBLISHER_TYPE"`       // kafka or nats
+		Brokers    []string `yaml:"brokers" envconfig:"PUBLISHER_BROKERS"` // host:port of the kafka brokers or urls of the nats servers
+		Topic      string   `yaml:"topic" envconfig:"PUBLISHER_TOPIC"`     // kafka topic or nats subject
+		BufferSize int      `yaml:"bufferSize" envconfig:"PUBLISHER_BUFFER_SIZE"`
+	} `yaml:"publisher"`
+	// BulkExport writes daily parquet partitions of the core tables to a gcs or s3 bucket, it runs in the statistics process
+	BulkExport struct {
+		Enabled     bool     `yaml:"enabled" envconfig:"BULK_EXPORT_ENABLED"`
+		Destination string   `yaml:"destination" envconfig:"BULK_EXPORT_DESTINATION"` // gs://bucket/prefix or s3://bucket/prefix
+		S3Region    string   `yaml:"s3Region" envconfig:"BULK_EXPORT_S3_REGION"`
+		Tables      []string `yaml:"tables" envconfig:"BULK_EXPORT_TABLES"`
+	} `yaml:"bulkExport"`
+	// BulkWriter overrides the method and batch size the db bulk writer upserts rows into a table with, by table name
+	BulkWriter struct {
+		Tables map[string]BulkWriterTableConfig `yaml:"tables"`
+	} `yaml:"bulkWriter"`
+	// ProtocolExporters holds the config of the registered protocol exporters (e.g. rocketpool, ssv, stakewise) by name
+	ProtocolExporters map[string]ProtocolExporterConfig `yaml:"protocolExporters"`
+	// Secrets configures how vault://, awskms:// and gcpsm:// references in string settings are resolved
+	Secrets struct {
+		CacheSeconds int `yaml:"cacheSeconds" envconfig:"SECRETS_CACHE_SECONDS"`
+		RenewSeconds int `yaml:"renewSeconds" envconfig:"SECRETS_RENEW_SECONDS"`
+		Vault        struct {
+			Address   string `yaml:"address" envconfig:"VAULT_ADDR"`
+			Token     string `yaml:"token" envconfig:"VAULT_TOKEN"`
+			Namespace string `yaml:"namespace" envconfig:"VAULT_NAMESPACE"`
+		} `yaml:"vault"`
+		AwsRegion string `yaml:"awsRegion" envconfig:"SECRETS_AWS_REGION"`
+	} `yaml:"secrets"`
+}
+
+// DatabasePool is the config of a db connection pool, zero values keep the defaults (unlimited open connections, 2 idle
+// connections, 30s idle time, 60s lifetime). StatementCacheMode is either "prepare" (default), "describe" or "none",
+// the latter two are required behind pgbouncer in transaction mode.
+type DatabasePool struct {
+	MaxOpenConns           int    `yaml:"maxOpenConns"`
+	MaxIdleConns           int    `yaml:"maxIdleConns"`
+	ConnMaxIdleTimeSeconds int    `yaml:"connMaxIdleTimeSeconds"`
+	ConnMaxLifetimeSeconds int    `yaml:"connMaxLifetimeSeconds"`
+	StatementCacheMode     string `yaml:"statementCacheMode"`
+}
+
+// BulkWriterTableConfig is the bulk writer config of a single table, Method is either "insert" (multi-row inserts) or
+// "copy" (COPY into a temporary table merged with a single upsert)
+type BulkWriterTableConfig struct {
+	Method    string `yaml:"method"`
+	BatchSize int    `yaml:"batchSize"`
+}
+
+// CORSPolicy is the cross-origin policy of a group of routes, without allowed origins any origin may read the responses
+// but no credentials are sent. Credentials are only allowed for the explicitly allowed origins.
+type CORSPolicy struct {
+	AllowedOrigins   []string `yaml:"allowedOrigins"`
+	AllowCredentials bool     `yaml:"allowCredentials"`
+}
+
+// PriceConfig configures the sources of the ETH price. Providers are tried in order (coingecko, kraken, chainlink) and a
+// currency missing from a provider is taken from the next one. A price that moved by more than MaxChangePercent since
+// the last accepted price is rejected unless the last accepted price is older than StaleAfterMinutes. Without a recent
+// accepted price (e.g. after the start) a price is only accepted once two providers agree on it within MaxChangePercent.
+type PriceConfig struct {
+	Providers             []string `yaml:"providers" envconfig:"PRICE_PROVIDERS"`
+	UpdateIntervalSeconds uint64   `yaml:"updateIntervalSeconds" envconfig:"PRICE_UPDATE_INTERVAL_SECONDS"`
+	MaxChangePercent      float64  `yaml:"maxChangePercent" envconfig:"PRICE_MAX_CHANGE_PERCENT"`
+	StaleAfterMinutes     uint64   `yaml:"staleAfterMinutes" envconfig:"PRICE_STALE_AFTER_MINUTES"`
+	Chainlink             struct {
+		Eth1Endpoint string `yaml:"eth1Endpoint" envconfig:"PRICE_CHAINLINK_ETH1_ENDPOINT"`
+		// Feeds are the addresses of the ETH/<currency> aggregators by currency, e.g. USD
+		Feeds map[string]string `yaml:"feeds"`
+	} `yaml:"chainlink"`
+}
+
+// RocketpoolRethPool is a DEX pool of rETH and WETH, Type is either "uniswapv2" or "uniswapv3"
+type RocketpoolRethPool struct {
+	Address string `yaml:"address"`
+	Type    string `yaml:"type"`
+}
+
+// ProtocolExporterConfig is the config of a single protocol exporter
+type ProtocolExporterConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// UpdateIntervalSeconds defaults to 60 seconds
+	UpdateIntervalSeconds uint64 `yaml:"updateIntervalSeconds"`
+	// Params are passed to the exporter as-is, e.g. contract addresses
+	Params map[string]string `yaml:"params"`
 }
 
 // Phase0 is the config for beacon chain phase0
@@ -250,3 +596,17 @@ type Altair struct {
 	EpochsPerSyncCommitteePeriod         uint64 `yaml:"EPOCHS_PER_SYNC_COMMITTEE_PERIOD"`
 	MinSyncCommitteeParticipants         uint64 `yaml:"MIN_SYNC_COMMITTEE_PARTICIPANTS"`
 }
+
+// https://github.com/ethereum/consensus-specs/blob/dev/presets/mainnet/electra.yaml
+type Electra struct {
+	MinActivationBalance               uint64 `yaml:"MIN_ACTIVATION_BALANCE"`                 // MinActivationBalance is the amount of Gwei a validator needs to be activated, also the max effective balance of validators without compounding credentials
+	MaxEffectiveBalanceElectra         uint64 `yaml:"MAX_EFFECTIVE_BALANCE_ELECTRA"`          // MaxEffectiveBalanceElectra is the maximal amount of Gwei that is effective for staking for validators with compounding credentials
+	MaxConsolidationRequestsPerPayload uint64 `yaml:"MAX_CONSOLIDATION_REQUESTS_PER_PAYLOAD"` // MaxConsolidationRequestsPerPayload is the maximal number of consolidation requests in an execution payload
+	MinSlashingPenaltyQuotientElectra  uint64 `yaml:"MIN_SLASHING_PENALTY_QUOTIENT_ELECTRA"`  // MinSlashingPenaltyQuotientElectra is the fraction of the effective balance that is deducted when a validator is slashed
+	WhistleblowerRewardQuotientElectra uint64 `yaml:"WHISTLEBLOWER_REWARD_QUOTIENT_ELECTRA"`  // WhistleblowerRewardQuotientElectra is the fraction of the effective balance of a slashed validator that is paid to the whistleblower
+
+	// Churn, the activation churn limit of deneb (EIP-7514) is listed here as there is no deneb config
+	MaxPerEpochActivationChurnLimit     uint64 `yaml:"MAX_PER_EPOCH_ACTIVATION_CHURN_LIMIT"`      // MaxPerEpochActivationChurnLimit is the maximal number of validators activated per epoch since deneb
+	MinPerEpochChurnLimitElectra        uint64 `yaml:"MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA"`         // MinPerEpochChurnLimitElectra is the minimal amount of Gwei activated and exited per epoch since electra
+	MaxPerEpochActivationExitChurnLimit uint64 `yaml:"MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT"` // MaxPerEpochActivationExitChurnLimit is the maximal amount of Gwei activated and exited per epoch since electra
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	ethpb "github.com/prysmaticlabs/prysm/proto/prysm/v1alpha1"
 )
 
@@ -94,26 +96,68 @@ type SyncAggregate struct {
 	SyncAggregateParticipation float64
 }
 
+// ExecutionPayload is a struct to hold the execution payload data of a block
+type ExecutionPayload struct {
+	BlockHash         []byte
+	BlockNumber       uint64
+	FeeRecipient      []byte
+	GasUsed           uint64
+	GasLimit          uint64
+	BaseFeePerGas     uint64
+	Timestamp         uint64
+	TransactionsCount uint64
+	Withdrawals       []*Withdrawal // warning: withdrawals are nil before capella
+}
+
+// Withdrawal is a struct to hold a withdrawal of the balance of a validator to its execution address that the withdrawal
+// sweep included in an execution payload
+type Withdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        []byte
+	Amount         uint64 // gwei
+}
+
 // Block is a struct to hold block data
 type Block struct {
-	Status            uint64
-	Proposer          uint64
-	BlockRoot         []byte
-	Slot              uint64
-	ParentRoot        []byte
-	StateRoot         []byte
-	Signature         []byte
-	RandaoReveal      []byte
-	Graffiti          []byte
-	Eth1Data          *Eth1Data
-	BodyRoot          []byte
-	ProposerSlashings []*ProposerSlashing
-	AttesterSlashings []*AttesterSlashing
-	Attestations      []*Attestation
-	Deposits          []*Deposit
-	VoluntaryExits    []*VoluntaryExit
-	SyncAggregate     *SyncAggregate // warning: sync aggregate may be nil, for phase0 blocks
-	Canonical         bool
+	Status                uint64
+	Proposer              uint64
+	BlockRoot             []byte
+	Slot                  uint64
+	ParentRoot            []byte
+	StateRoot             []byte
+	Signature             []byte
+	RandaoReveal          []byte
+	Graffiti              []byte
+	Eth1Data              *Eth1Data
+	BodyRoot              []byte
+	ProposerSlashings     []*ProposerSlashing
+	AttesterSlashings     []*AttesterSlashing
+	Attestations          []*Attestation
+	Deposits              []*Deposit
+	VoluntaryExits        []*VoluntaryExit
+	SyncAggregate         *SyncAggregate    // warning: sync aggregate may be nil, for phase0 blocks
+	ExecutionPayload      *ExecutionPayload // warning: execution payload may be nil, for phase0 and altair blocks
+	BLSToExecutionChanges []*BLSToExecutionChange
+	ConsolidationRequests []*ConsolidationRequest
+	Canonical             bool
+}
+
+// BLSToExecutionChange is a struct to hold a change of the bls withdrawal credentials of a validator to an execution
+// address
+type BLSToExecutionChange struct {
+	Validatorindex uint64
+	BlsPubkey      []byte
+	Address        []byte
+	Signature      []byte
+}
+
+// ConsolidationRequest is a struct to hold a request of the withdrawal address of a validator to consolidate it into
+// another validator, a request with the same source and target switches the validator to compounding credentials
+type ConsolidationRequest struct {
+	SourceAddress []byte
+	SourcePubkey  []byte
+	TargetPubkey  []byte
 }
 
 // Eth1Data is a struct to hold the ETH1 data
@@ -237,6 +281,21 @@ type Eth1Deposit struct {
 	ValidSignature        bool   `db:"valid_signature"`
 }
 
+// Eth1PendingDeposit holds the eth1-deposits of a public key that is not yet known to the beacon chain.
+// QueuePosition and EstimatedActivationEpoch are only set if enough ether has been deposited for the validator to be activated.
+type Eth1PendingDeposit struct {
+	PublicKey                []byte    `db:"publickey"`
+	FromAddress              []byte    `db:"from_address"`
+	DepositCount             uint64    `db:"deposit_count"`
+	Amount                   uint64    `db:"amount"`
+	ValidAmount              uint64    `db:"valid_amount"`
+	LastBlockNumber          uint64    `db:"last_block_number"`
+	LastDepositTs            time.Time `db:"last_deposit_ts"`
+	QueuePosition            *uint64   `db:"queue_position"`
+	EstimatedInclusionEpoch  uint64    `db:"estimated_inclusion_epoch"`
+	EstimatedActivationEpoch *uint64   `db:"estimated_activation_epoch"`
+}
+
 // Eth2Deposit is a struct to hold eth2-deposit data
 type Eth2Deposit struct {
 	BlockSlot             uint64 `db:"block_slot"`
@@ -414,3 +473,117 @@ type HistoricEthPrice struct {
 	Name   string `json:"name"`
 	Symbol string `json:"symbol"`
 }
+
+// DataIntegrityBlock is a struct to hold the fields of a block that are compared by the data integrity verifier
+type DataIntegrityBlock struct {
+	Slot              uint64 `db:"slot"`
+	BlockRoot         []byte `db:"blockroot"`
+	Proposer          uint64 `db:"proposer"`
+	AttestationsCount uint64 `db:"attestationscount"`
+}
+
+// DataIntegrityIssue is a struct to hold a mismatch found between the db and the beacon-node
+type DataIntegrityIssue struct {
+	CheckType      string  `db:"check_type"`
+	Epoch          uint64  `db:"epoch"`
+	Slot           *uint64 `db:"slot"`
+	ValidatorIndex *uint64 `db:"validatorindex"`
+	DBValue        string  `db:"db_value"`
+	NodeValue      string  `db:"node_value"`
+}
+
+// ChainEventName is the name of an event the indexer publishes on the event bus
+type ChainEventName string
+
+const (
+	ChainEventBlockProposed    ChainEventName = "block_proposed"
+	ChainEventValidatorSlashed ChainEventName = "validator_slashed"
+	ChainEventDepositSeen      ChainEventName = "deposit_seen"
+	// ChainEventHead is published whenever the indexer processed a new head, it carries the checkpoints of the head
+	ChainEventHead ChainEventName = "head"
+)
+
+// ChainEvent is published by the indexer as soon as it stored the underlying data, so that consumers
+// like the notification system do not have to wait for their next periodic scan
+type ChainEvent struct {
+	Name           ChainEventName    `json:"name"`
+	Epoch          uint64            `json:"epoch"`
+	Slot           uint64            `json:"slot,omitempty"`
+	ValidatorIndex uint64            `json:"validatorindex,omitempty"`
+	PublicKey      []byte            `json:"pubkey,omitempty"`
+	Ts             int64             `json:"ts"`
+	Checkpoints    *ChainCheckpoints `json:"checkpoints,omitempty"`
+}
+
+// ChainCheckpoints are the head block root and the finalized and justified checkpoints of a head event
+type ChainCheckpoints struct {
+	HeadBlockRoot      []byte `json:"head_block_root"`
+	FinalizedEpoch     uint64 `json:"finalized_epoch"`
+	FinalizedBlockRoot []byte `json:"finalized_block_root"`
+	JustifiedEpoch     uint64 `json:"justified_epoch"`
+	JustifiedBlockRoot []byte `json:"justified_block_root"`
+}
+
+// RocketpoolRETHRate is the rETH exchange rate of the rocketpool contract and the secondary-market rate at an eth1-block
+type RocketpoolRETHRate struct {
+	Eth1Block    uint64    `db:"eth1_block"`
+	Ts           time.Time `db:"ts"`
+	ExchangeRate float64   `db:"exchange_rate"`
+	MarketRate   *float64  `db:"market_rate"`
+}
+
+// RocketpoolNodesHistory is the rpl stake (in RPL) and the minipool count of the rocketpool nodes at an eth1-block,
+// summed over all nodes or of a single node
+type RocketpoolNodesHistory struct {
+	Eth1Block   uint64    `db:"eth1_block"`
+	Ts          time.Time `db:"ts"`
+	Nodes       uint64    `db:"nodes"`
+	RPLStake    float64   `db:"rpl_stake"`
+	MinRPLStake float64   `db:"min_rpl_stake"`
+	MaxRPLStake float64   `db:"max_rpl_stake"`
+	Minipools   uint64    `db:"minipools"`
+}
+
+// CollateralRatio returns the rpl stake as a multiple of the minimum stake, nil if no stake is required
+func (h *RocketpoolNodesHistory) CollateralRatio() *float64 {
+	if h.MinRPLStake == 0 {
+		return nil
+	}
+	ratio := h.RPLStake / h.MinRPLStake
+	return &ratio
+}
+
+// Premium returns the relative premium (positive) or discount (negative) of the market rate over the exchange rate
+func (r *RocketpoolRETHRate) Premium() *float64 {
+	if r.MarketRate == nil || r.ExchangeRate == 0 {
+		return nil
+	}
+	premium := *r.MarketRate/r.ExchangeRate - 1
+	return &premium
+}
+
+// RocketpoolODAOMemberHealth is the submission liveness of an oDAO member, missed submissions are counted over the
+// most recent rounds that started after the member joined
+type RocketpoolODAOMemberHealth struct {
+	Address            []byte     `db:"address"`
+	ID                 string     `db:"id"`
+	JoinedTime         time.Time  `db:"joined_time"`
+	LastPricesTime     *time.Time `db:"last_prices_ts"`
+	LastBalancesTime   *time.Time `db:"last_balances_ts"`
+	MissedPrices       uint64     `db:"missed_prices"`
+	MissedBalances     uint64     `db:"missed_balances"`
+	ConsideredPrices   uint64     `db:"considered_prices"`
+	ConsideredBalances uint64     `db:"considered_balances"`
+}
+
+// RocketpoolODAORound are the submissions of the oDAO members for a reference block, ConsensusTime is nil as long as
+// the submissions have not reached consensus
+type RocketpoolODAORound struct {
+	Kind               string     `db:"kind"`
+	ReferenceBlock     uint64     `db:"reference_block"`
+	FirstSubmission    time.Time  `db:"first_submission_ts"`
+	Submissions        uint64     `db:"submissions"`
+	ConsensusEth1Block *uint64    `db:"consensus_eth1_block"`
+	ConsensusTime      *time.Time `db:"consensus_ts"`
+	Value              *string    `db:"value"` // rpl price of price rounds and total eth of balances rounds
+}
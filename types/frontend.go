@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -33,6 +34,14 @@ const (
 	MonitoringMachineSwitchedToETH2FallbackEventName EventName = "monitoring_fallback_eth2inuse"
 	MonitoringMachineSwitchedToETH1FallbackEventName EventName = "monitoring_fallback_eth1inuse"
 	TaxReportEventName                               EventName = "user_tax_report"
+	CustomRuleEventName                              EventName = "custom_rule"
+	Eth1DepositorDepositEventName                    EventName = "eth1_depositor_deposit"
+	ValidatorFeeRecipientMismatchEventName           EventName = "validator_fee_recipient_mismatch"
+	RocketpoolFeeRecipientViolationEventName         EventName = "rocketpool_fee_recipient_violation"
+	ValidatorFullWithdrawalEventName                 EventName = "validator_full_withdrawal"
+	// TestNotificationEventName is the event of the test notifications users send to verify a channel, it can not be
+	// subscribed to
+	TestNotificationEventName EventName = "test_notification"
 )
 
 var EventNames = []EventName{
@@ -58,6 +67,10 @@ var EventNames = []EventName{
 	MonitoringMachineSwitchedToETH1FallbackEventName,
 	MonitoringMachineMemoryUsageEventName,
 	TaxReportEventName,
+	Eth1DepositorDepositEventName,
+	ValidatorFeeRecipientMismatchEventName,
+	RocketpoolFeeRecipientViolationEventName,
+	ValidatorFullWithdrawalEventName,
 }
 
 func GetDisplayableEventName(event EventName) string {
@@ -73,12 +86,441 @@ func EventNameFromString(event string) (EventName, error) {
 	return "", errors.Errorf("Could not convert event to string. %v is not a known event type", event)
 }
 
+// UserFeeRecipient is the fee recipient a user expects the validators on their watchlist to use
+type UserFeeRecipient struct {
+	UserID             uint64    `db:"user_id"`
+	Address            []byte    `db:"address"`
+	AllowSmoothingPool bool      `db:"allow_smoothing_pool"`
+	UpdatedTs          time.Time `db:"updated_ts"`
+}
+
+// ValidatorOwnershipMethod is the key a user signed the ownership challenge of a validator with
+type ValidatorOwnershipMethod string
+
+const (
+	ValidatorOwnershipMethodBLS               ValidatorOwnershipMethod = "bls"
+	ValidatorOwnershipMethodWithdrawalAddress ValidatorOwnershipMethod = "withdrawal_address"
+)
+
+// ValidatorOwnership is the proof of a user that they control a validator
+type ValidatorOwnership struct {
+	UserID             uint64                   `db:"user_id"`
+	ValidatorPublickey []byte                   `db:"validator_publickey"`
+	Method             ValidatorOwnershipMethod `db:"method"`
+	Address            []byte                   `db:"address"`
+	Message            string                   `db:"message"`
+	Signature          []byte                   `db:"signature"`
+	VerifiedTs         time.Time                `db:"verified_ts"`
+}
+
+// CustomChartDefinition is a user composed chart of a metric of the network or a set of validators over a range of days
+type CustomChartDefinition struct {
+	Title       string   `json:"title"`
+	Metric      string   `json:"metric"`
+	Scope       string   `json:"scope"`
+	Validators  []uint64 `json:"validators,omitempty"`
+	Aggregation string   `json:"aggregation"`
+	StartDay    uint64   `json:"start_day"`
+	EndDay      uint64   `json:"end_day"`
+}
+
+// CustomChartPoint is the aggregated value of the metric of a custom chart on a day
+type CustomChartPoint struct {
+	Day   uint64  `db:"day" json:"day"`
+	Value float64 `db:"value" json:"value"`
+}
+
+// ValidatorIncomeProjection is the income of a validator projected from its income of the last 31 days, amounts in ETH are
+// in gwei, costs and profits are in the currency of the projection
+type ValidatorIncomeProjection struct {
+	Validatorindex          uint64                            `json:"validatorindex"`
+	Fork                    string                            `json:"fork"`
+	HistoricalDays          uint64                            `json:"historical_days"`
+	HistoricalDailyIncome   int64                             `json:"historical_daily_income"`
+	HistoricalParticipation float64                           `json:"historical_participation_rate"`
+	ParticipationRate       float64                           `json:"participation_rate"`
+	Currency                string                            `json:"currency"`
+	EthPrice                float64                           `json:"eth_price"`
+	HardwareCost            float64                           `json:"hardware_cost"`
+	MonthlyCost             float64                           `json:"monthly_cost"`
+	DailyIncome             int64                             `json:"daily_income"`
+	MonthlyIncome           int64                             `json:"monthly_income"`
+	MonthlyProfit           float64                           `json:"monthly_profit"`
+	APR                     float64                           `json:"apr"`
+	BreakEvenMonths         *float64                          `json:"break_even_months"`
+	Months                  []*ValidatorIncomeProjectionMonth `json:"months"`
+}
+
+// ValidatorIncomeProjectionMonth is the cumulative projected income and profit after a number of months
+type ValidatorIncomeProjectionMonth struct {
+	Month  int     `json:"month"`
+	Income int64   `json:"income"`
+	Profit float64 `json:"profit"`
+}
+
+// ValidatorSetForecast projects the size of the validator set, the staking APR and the lengths of the activation and
+// exit queues from the deposit and exit rates of the last FitDays days. The low and high scenarios scale the deposit and
+// exit rates by the band in opposite directions, low is the scenario with the smallest validator set and therefore the
+// highest APR.
+type ValidatorSetForecast struct {
+	FitDays          uint64                       `json:"fit_days"`
+	Epoch            uint64                       `json:"epoch"`
+	ActiveValidators uint64                       `json:"active_validators"`
+	EnteringQueue    uint64                       `json:"entering_queue"`
+	ExitingQueue     uint64                       `json:"exiting_queue"`
+	AverageBalance   uint64                       `json:"average_effective_balance"`
+	DailyDeposits    float64                      `json:"daily_deposits"`
+	DailyExits       float64                      `json:"daily_exits"`
+	Participation    float64                      `json:"participation_rate"`
+	ChurnLimit       uint64                       `json:"churn_limit"`
+	ActivationChurn  uint64                       `json:"activation_churn_limit"`
+	APR              float64                      `json:"apr"`
+	Band             float64                      `json:"band"`
+	Months           []*ValidatorSetForecastMonth `json:"months"`
+}
+
+// ValidatorSetForecastMonth is the projected state of the validator set after a number of months
+type ValidatorSetForecastMonth struct {
+	Month            int          `json:"month"`
+	ActiveValidators ForecastBand `json:"active_validators"`
+	APR              ForecastBand `json:"apr"`
+	EnteringQueue    ForecastBand `json:"entering_queue"`
+	ExitingQueue     ForecastBand `json:"exiting_queue"`
+}
+
+// SlashingSimulation is the financial impact of a slashing of a validator with EffectiveBalance at the current network
+// state, amounts are in gwei. The penalties of the exit period accrue until the slashed validator is withdrawable
+// EPOCHS_PER_SLASHINGS_VECTOR epochs after the slashing, the missed rewards are the attestation rewards it would have
+// earned in that period at the current participation rate. The whistleblower reward is paid to the proposer of the block
+// that includes the slashing, it is not deducted from the slashed validator.
+type SlashingSimulation struct {
+	Epoch                  uint64                        `json:"epoch"`
+	Fork                   string                        `json:"fork"`
+	EffectiveBalance       uint64                        `json:"effective_balance"`
+	ActiveValidators       uint64                        `json:"active_validators"`
+	TotalActiveBalance     uint64                        `json:"total_active_balance"`
+	RecentlySlashedBalance uint64                        `json:"recently_slashed_balance"`
+	Participation          float64                       `json:"participation_rate"`
+	InitialPenalty         uint64                        `json:"initial_penalty"`
+	WhistleblowerReward    uint64                        `json:"whistleblower_reward"`
+	ExitPeriodEpochs       uint64                        `json:"exit_period_epochs"`
+	ExitPeriodPenalties    uint64                        `json:"exit_period_penalties"`
+	MissedRewards          uint64                        `json:"missed_rewards"`
+	Scenarios              []*SlashingSimulationScenario `json:"scenarios"`
+}
+
+// SlashingSimulationScenario is the correlation penalty of the slashed validator if SimultaneousSlashings validators
+// with the same effective balance (including itself) are slashed within the slashings window, SlashedBalance includes
+// the balance of the validators slashed recently. TotalLoss is the sum of all penalties and the missed rewards.
+type SlashingSimulationScenario struct {
+	SimultaneousSlashings uint64  `json:"simultaneous_slashings"`
+	SlashedBalance        uint64  `json:"slashed_balance"`
+	CorrelationPenalty    uint64  `json:"correlation_penalty"`
+	TotalLoss             uint64  `json:"total_loss"`
+	TotalLossRatio        float64 `json:"total_loss_ratio"`
+}
+
+// ForecastBand is a projected value in the low, base and high scenario of a forecast
+type ForecastBand struct {
+	Low  float64 `json:"low"`
+	Base float64 `json:"base"`
+	High float64 `json:"high"`
+}
+
+// WithdrawalSweep is the position of the withdrawal sweep over the validator registry at the latest block with
+// withdrawals, the sweep continues at NextValidator and includes WithdrawalsPerSlot withdrawals per slot on average.
+// SweptValidators is the number of validators it withdraws from in a full cycle over the registry.
+type WithdrawalSweep struct {
+	Slot               uint64  `json:"slot"`
+	NextValidator      uint64  `json:"next_validator"`
+	ValidatorCount     uint64  `json:"validator_count"`
+	SweptValidators    uint64  `json:"swept_validators"`
+	WithdrawalsPerSlot float64 `json:"withdrawals_per_slot"`
+}
+
+// ValidatorFullWithdrawal is the withdrawal of the balance of an exited validator, it is estimated from the position of
+// the withdrawal sweep until the withdrawal has been included (the amount is in gwei)
+type ValidatorFullWithdrawal struct {
+	Slot      uint64    `db:"block_slot" json:"slot"`
+	Ts        time.Time `db:"-" json:"ts"`
+	Amount    uint64    `db:"amount" json:"amount,omitempty"`
+	Estimated bool      `db:"-" json:"estimated"`
+}
+
+// ValidatorLifecycle is the timeline of a validator from its deposits to its exit, the events are ordered by time and
+// amounts are in gwei
+type ValidatorLifecycle struct {
+	Validatorindex        uint64                     `json:"validatorindex"`
+	Pubkey                string                     `json:"pubkey"`
+	WithdrawalCredentials string                     `json:"withdrawal_credentials"`
+	Status                string                     `json:"status"`
+	Slashed               bool                       `json:"slashed"`
+	Balance               uint64                     `json:"balance"`
+	EffectiveBalance      uint64                     `json:"effective_balance"`
+	Events                []*ValidatorLifecycleEvent `json:"events"`
+}
+
+// ValidatorLifecycleEvent is an event of a validator lifecycle. Eth1 deposits are identified by their block number and
+// transaction, beacon chain events by their epoch and, if they are included in a block, slot.
+type ValidatorLifecycleEvent struct {
+	Type                  string    `json:"type"`
+	Ts                    time.Time `json:"ts"`
+	Epoch                 *uint64   `json:"epoch,omitempty"`
+	Slot                  *uint64   `json:"slot,omitempty"`
+	BlockNumber           *uint64   `json:"block_number,omitempty"`
+	TxHash                string    `json:"tx_hash,omitempty"`
+	Address               string    `json:"address,omitempty"` // sender of eth1 deposits, execution address of credential changes
+	Amount                uint64    `json:"amount,omitempty"`
+	WithdrawalCredentials string    `json:"withdrawal_credentials,omitempty"`
+	ValidSignature        *bool     `json:"valid_signature,omitempty"`
+	SlashedBy             *uint64   `json:"slashed_by,omitempty"`
+	Reason                string    `json:"reason,omitempty"` // proposer_slashing or attester_slashing
+	Scheduled             bool      `json:"scheduled"`        // the epoch of the event has not been reached yet
+	Estimated             bool      `json:"estimated"`        // the slot of the event is estimated, e.g. of the full withdrawal
+}
+
+// ValidatorStatusHistory lists the status transitions of a validator, Status is the status at Epoch (the latest epoch by
+// default) or empty if the validator was not deposited yet
+type ValidatorStatusHistory struct {
+	Validatorindex uint64                       `json:"validatorindex"`
+	Pubkey         string                       `json:"pubkey"`
+	Epoch          uint64                       `json:"epoch"`
+	Status         string                       `json:"status"`
+	Transitions    []*ValidatorStatusTransition `json:"transitions"`
+}
+
+// ValidatorStatusTransition is the epoch a validator reached a status at
+type ValidatorStatusTransition struct {
+	Status string    `db:"status" json:"status"`
+	Epoch  uint64    `db:"epoch" json:"epoch"`
+	Ts     time.Time `db:"-" json:"ts"`
+}
+
+// ValidatorPeerComparison compares a validator against the medians of all validators (the network) and of the
+// validators activated in the same window of epochs (the cohort) over the last exported days. The summaries average the
+// daily values.
+type ValidatorPeerComparison struct {
+	Validatorindex         uint64                        `json:"validatorindex"`
+	ActivationWindowStart  uint64                        `json:"activation_window_start"` // first activation epoch of the cohort
+	ActivationWindowEnd    uint64                        `json:"activation_window_end"`
+	Validator              *ValidatorPeerStats           `json:"validator"`
+	Cohort                 *ValidatorPeerStats           `json:"cohort"`
+	Network                *ValidatorPeerStats           `json:"network"`
+	Days                   []*ValidatorPeerComparisonDay `json:"days"`
+	CohortValidatorsCount  uint64                        `json:"cohort_validators_count"`
+	NetworkValidatorsCount uint64                        `json:"network_validators_count"`
+}
+
+// ValidatorPeerComparisonDay holds the values of a validator and the medians it is compared against of a single day
+type ValidatorPeerComparisonDay struct {
+	Day       uint64              `json:"day"`
+	Validator *ValidatorPeerStats `json:"validator"`
+	Cohort    *ValidatorPeerStats `json:"cohort"`
+	Network   *ValidatorPeerStats `json:"network"`
+}
+
+// ValidatorPeerStats are the apr, the effectiveness (in percent) and the average distance to the optimal inclusion
+// slot of a validator or the medians of a group of validators, values that are unknown are nil
+type ValidatorPeerStats struct {
+	APR               float64  `db:"apr" json:"apr"`
+	Effectiveness     *float64 `db:"effectiveness" json:"effectiveness"`
+	InclusionDistance *float64 `db:"inclusion_distance" json:"inclusion_distance"`
+}
+
+// ValidatorDiagnosis correlates the low attestation effectiveness of a validator from StartDay to EndDay with the data
+// of the explorer to hint at the cause: a dip of the participation of the whole network, other validators with the same
+// withdrawal credentials dropping at the same time (a shared setup) and the client the graffiti of its last blocks
+// suggests
+type ValidatorDiagnosis struct {
+	Validatorindex               uint64  `json:"validatorindex"`
+	StartDay                     uint64  `json:"start_day"`
+	EndDay                       uint64  `json:"end_day"`
+	NetworkParticipation         float64 `json:"network_participation"`          // average global participation rate of the days
+	NetworkBaselineParticipation float64 `json:"network_baseline_participation"` // of the same number of days before
+	NetworkDipped                bool    `json:"network_dipped"`
+	WithdrawalCredentials        []byte  `json:"withdrawal_credentials"`
+	WithdrawalSiblings           uint64  `json:"withdrawal_siblings"`         // counted up to one more than the evaluated maximum
+	WithdrawalSiblingsDropped    uint64  `json:"withdrawal_siblings_dropped"` // 0 if there are more siblings than the maximum
+	Client                       string  `json:"client"`                      // empty if the validator has no recent blocks
+	ClientBlocks                 uint64  `json:"client_blocks"`
+	RecentBlocks                 uint64  `json:"recent_blocks"`
+}
+
+// ValidatorSetComparison are the aggregated daily statistics of a set of validators between the first and the last day
+// of a comparison. The apr is the income over the effective balance of the validator days extrapolated to a year, the
+// effectiveness is the average of the default formula.
+type ValidatorSetComparison struct {
+	Name                 string   `json:"name"`
+	StartDay             uint64   `json:"start_day"`
+	EndDay               uint64   `json:"end_day"`
+	Validators           uint64   `db:"validators" json:"validators"`
+	Income               int64    `db:"income" json:"income"`
+	APR                  float64  `db:"apr" json:"apr"`
+	Effectiveness        *float64 `db:"effectiveness" json:"effectiveness"`
+	MissedAttestations   uint64   `db:"missed_attestations" json:"missed_attestations"`
+	OrphanedAttestations uint64   `db:"orphaned_attestations" json:"orphaned_attestations"`
+	ProposedBlocks       uint64   `db:"proposed_blocks" json:"proposed_blocks"`
+	MissedBlocks         uint64   `db:"missed_blocks" json:"missed_blocks"`
+	OrphanedBlocks       uint64   `db:"orphaned_blocks" json:"orphaned_blocks"`
+	ParticipatedSync     uint64   `db:"participated_sync" json:"participated_sync"`
+	MissedSync           uint64   `db:"missed_sync" json:"missed_sync"`
+	ProposalMissRate     float64  `db:"proposal_miss_rate" json:"proposal_miss_rate"`
+	SyncMissRate         float64  `db:"sync_miss_rate" json:"sync_miss_rate"`
+}
+
+// BlockPropagationStats are the propagation delays of recent blocks of a proposer or client, the delay of a block is
+// the time after the start of its slot the first of the configured beacon nodes saw it
+type BlockPropagationStats struct {
+	Proposer        *uint64 `json:"proposer,omitempty"`
+	Client          string  `json:"client,omitempty"`
+	BlocksCount     uint64  `json:"blocks_count"`
+	LateBlocksCount uint64  `json:"late_blocks_count"`
+	AvgDelayMs      float64 `json:"avg_delay_ms"`
+	MedianDelayMs   float64 `json:"median_delay_ms"`
+	MaxDelayMs      uint64  `json:"max_delay_ms"`
+	// ChronicallyLate is set for proposers whose blocks are late at least half of the time
+	ChronicallyLate bool `json:"chronically_late"`
+}
+
+// RelayBid is the highest bid of a builder for a slot at a mev-boost relay, Bids is the number of bids the builder
+// submitted for the slot in time. Values are in wei.
+type RelayBid struct {
+	Slot          uint64 `db:"slot" json:"slot"`
+	Relay         string `db:"relay" json:"relay"`
+	BuilderPubkey []byte `db:"builder_pubkey" json:"builder_pubkey"`
+	BlockHash     []byte `db:"block_hash" json:"block_hash"`
+	Value         Wei    `db:"value" json:"value"`
+	Bids          uint64 `db:"bids" json:"bids"`
+}
+
+// RelayAuction is the auction of the execution payload of a slot over all indexed relays, the bids are the highest bids
+// of the builders. The winning bid is the payload of the canonical block a relay delivered to the proposer, the second
+// bid the highest bid of another builder. The value left on the table is the highest bid minus the value the proposer
+// received, the priority fees of a locally built block if no relay delivered it. Values are in wei, the winning bid is
+// nil for missed slots and locally built blocks, the value received is nil until the priority fees of a locally built
+// block are known.
+type RelayAuction struct {
+	Slot             uint64  `db:"slot" json:"slot"`
+	Proposer         *uint64 `db:"proposer" json:"proposer"`
+	Relay            *string `db:"relay" json:"relay"`
+	BuilderPubkey    []byte  `db:"builder_pubkey" json:"builder_pubkey"`
+	BlockHash        []byte  `db:"block_hash" json:"block_hash"`
+	WinningBid       *Wei    `db:"winning_bid" json:"winning_bid"`
+	SecondBid        *Wei    `db:"second_bid" json:"second_bid"`
+	TopBid           *Wei    `db:"top_bid" json:"top_bid"`
+	MedianBid        *Wei    `db:"median_bid" json:"median_bid"`
+	Bids             uint64  `db:"bids" json:"bids"`
+	Builders         uint64  `db:"builders" json:"builders"`
+	ValueReceived    *Wei    `db:"value_received" json:"value_received"`
+	ValueLeftOnTable *Wei    `db:"value_left_on_table" json:"value_left_on_table"`
+}
+
+// RelayAuctionDayStats aggregates the slot auctions of a day, RelayBlocks are the canonical blocks delivered by a relay
+// and the averages are taken over them. Values are in wei.
+type RelayAuctionDayStats struct {
+	Day              uint64 `db:"day" json:"day"`
+	Auctions         uint64 `db:"auctions" json:"auctions"`
+	RelayBlocks      uint64 `db:"relay_blocks" json:"relay_blocks"`
+	AvgWinningBid    Wei    `db:"avg_winning_bid" json:"avg_winning_bid"`
+	AvgSecondBid     Wei    `db:"avg_second_bid" json:"avg_second_bid"`
+	ValueLeftOnTable Wei    `db:"value_left_on_table" json:"value_left_on_table"`
+}
+
+// BuilderMarketShare is the number and share of the canonical blocks delivered by relays that a builder built
+type BuilderMarketShare struct {
+	Day           uint64  `db:"day" json:"day,omitempty"`
+	BuilderPubkey []byte  `db:"builder_pubkey" json:"builder_pubkey"`
+	Blocks        uint64  `db:"blocks" json:"blocks"`
+	Share         float64 `db:"share" json:"share"`
+}
+
+// ProposerAuctionStats sums the slot auctions of the blocks of a proposer, values are in wei
+type ProposerAuctionStats struct {
+	Proposer         uint64 `db:"proposer" json:"proposer"`
+	Blocks           uint64 `db:"blocks" json:"blocks"`
+	RelayBlocks      uint64 `db:"relay_blocks" json:"relay_blocks"`
+	ValueReceived    Wei    `db:"value_received" json:"value_received"`
+	ValueLeftOnTable Wei    `db:"value_left_on_table" json:"value_left_on_table"`
+}
+
+// BlockTree is the tree of the blocks of a window of slots for a fork-choice visualizer. Votes are the latest head votes
+// of the validators included in blocks of the window, the weight of a block is the effective balance of the votes for it
+// and all of its descendants like in LMD-GHOST.
+type BlockTree struct {
+	StartSlot     uint64           `json:"start_slot"`
+	EndSlot       uint64           `json:"end_slot"`
+	Blocks        []*BlockTreeNode `json:"blocks"`
+	OrphanedForks []*BlockTreeFork `json:"orphaned_forks"`
+}
+
+// BlockTreeNode is a block of a BlockTree, Status is either "canonical" or "orphaned"
+type BlockTreeNode struct {
+	Slot                  uint64 `db:"slot" json:"slot"`
+	BlockRoot             string `db:"-" json:"block_root"`
+	ParentRoot            string `db:"-" json:"parent_root"`
+	Proposer              uint64 `db:"proposer" json:"proposer"`
+	Status                string `db:"-" json:"status"`
+	Votes                 uint64 `db:"votes" json:"votes"`
+	VotesEffectiveBalance uint64 `db:"votes_effective_balance" json:"votes_effective_balance"`
+	Weight                uint64 `db:"-" json:"weight"`
+}
+
+// BlockTreeFork is a branch of orphaned blocks, Root is the first block of the branch and Parent the canonical block it
+// forked from
+type BlockTreeFork struct {
+	Root      string `json:"root"`
+	Parent    string `json:"parent"`
+	StartSlot uint64 `json:"start_slot"`
+	EndSlot   uint64 `json:"end_slot"`
+	Blocks    uint64 `json:"blocks"`
+	Weight    uint64 `json:"weight"`
+}
+
+// The types of the events of a duty calendar
+const (
+	DutyCalendarProposal               = "proposal"
+	DutyCalendarSyncCommittee          = "sync_committee"
+	DutyCalendarEstimatedProposal      = "estimated_proposal"
+	DutyCalendarEstimatedSyncCommittee = "estimated_sync_committee"
+)
+
+// DutyCalendar holds the upcoming duties of a set of validators, the estimates assume that proposers and sync committee
+// members are sampled proportionally to the effective balance
+type DutyCalendar struct {
+	ProposalProbabilityPerSlot        float64              `json:"proposal_probability_per_slot"`
+	ExpectedProposalsPerDay           float64              `json:"expected_proposals_per_day"`
+	SyncCommitteeProbabilityPerPeriod float64              `json:"sync_committee_probability_per_period"`
+	Events                            []*DutyCalendarEvent `json:"events"`
+}
+
+// DutyCalendarEvent is a known or estimated duty, estimated events have no validators and are placed at the expected
+// slot or period of the next duty
+type DutyCalendarEvent struct {
+	Type        string    `json:"type"`
+	Validators  []uint64  `json:"validators,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Slot        *uint64   `json:"slot,omitempty"`
+	Period      *uint64   `json:"period,omitempty"`
+	Probability float64   `json:"probability,omitempty"` // per slot for proposals, per period for sync committees
+}
+
 type Tag string
 
 const (
 	ValidatorTagsWatchlist Tag = "watchlist"
+	// ValidatorTagsUserPrefix prefixes the user defined tags in users_validators_tags
+	ValidatorTagsUserPrefix Tag = "tag:"
 )
 
+// UserValidatorTag is a tag a user defined to group validators
+type UserValidatorTag struct {
+	Name       string    `db:"name" json:"name"`
+	Validators uint64    `db:"validators" json:"validators"`
+	CreatedTs  time.Time `db:"created_ts" json:"created_ts"`
+	ShareID    *string   `db:"share_id" json:"share_id,omitempty"` // set if the dashboard of the tag is public
+}
+
 type Notification interface {
 	GetSubscriptionID() uint64
 	GetEventName() EventName
@@ -101,6 +543,91 @@ type Subscription struct {
 	EventThreshold float64    `db:"event_threshold"`
 }
 
+// NotificationRuleMetric is the metric a custom notification rule is evaluated on
+type NotificationRuleMetric string
+
+const (
+	// NotificationRuleMetricBalanceDecrease fires if the balance decreased by more than Threshold ETH over the last Window epochs
+	NotificationRuleMetricBalanceDecrease NotificationRuleMetric = "balance_decrease"
+	// NotificationRuleMetricAttestationEffectiveness fires if less than Threshold percent of the attestations were included on each of the last Window days
+	NotificationRuleMetricAttestationEffectiveness NotificationRuleMetric = "attestation_effectiveness"
+	// NotificationRuleMetricRPLCollateral fires if the rpl stake of the rocketpool node is less than Threshold percent of the borrowed ETH
+	NotificationRuleMetricRPLCollateral NotificationRuleMetric = "rpl_collateral"
+)
+
+var NotificationRuleMetrics = []NotificationRuleMetric{
+	NotificationRuleMetricBalanceDecrease,
+	NotificationRuleMetricAttestationEffectiveness,
+	NotificationRuleMetricRPLCollateral,
+}
+
+// NotificationRule is a user defined condition that is evaluated for a single validator or all validators on the users watchlist
+type NotificationRule struct {
+	ID                 uint64                 `db:"id" json:"id"`
+	UserID             uint64                 `db:"user_id" json:"-"`
+	Name               string                 `db:"name" json:"name"`
+	Metric             NotificationRuleMetric `db:"metric" json:"metric"`
+	Threshold          float64                `db:"threshold" json:"threshold"`
+	Window             uint64                 `db:"window_size" json:"window"`
+	ValidatorPublickey []byte                 `db:"validator_publickey" json:"validator_publickey,omitempty"`
+	CreatedTime        time.Time              `db:"created_ts" json:"created_ts"`
+}
+
+// NotificationChannel is the channel an outgoing notification is delivered on
+type NotificationChannel string
+
+const (
+	EmailNotificationChannel NotificationChannel = "email"
+	PushNotificationChannel  NotificationChannel = "push"
+)
+
+const (
+	NotificationLogStatusSent        = "sent"
+	NotificationLogStatusFailed      = "failed"
+	NotificationLogStatusRateLimited = "rate_limited"
+	NotificationLogStatusSuppressed  = "suppressed"
+)
+
+// NotificationLogEntry is a delivery attempt of an outgoing notification, the recipient is the email address or push
+// token the notification was sent to
+type NotificationLogEntry struct {
+	ID               uint64              `db:"id" json:"id"`
+	UserID           uint64              `db:"user_id" json:"-"`
+	Network          string              `db:"network" json:"network"`
+	Channel          NotificationChannel `db:"channel" json:"channel"`
+	Recipient        string              `db:"recipient" json:"-"`
+	EventNames       pq.StringArray      `db:"event_names" json:"event_names"`
+	Subject          string              `db:"subject" json:"subject"`
+	Payload          string              `db:"payload" json:"payload"`
+	PayloadHash      []byte              `db:"payload_hash" json:"payload_hash"`
+	Status           string              `db:"status" json:"status"`
+	ProviderResponse string              `db:"provider_response" json:"provider_response"`
+	ResentFrom       *uint64             `db:"resent_from" json:"resent_from,omitempty"`
+	CreatedTime      time.Time           `db:"created_ts" json:"created_ts"`
+}
+
+// NotificationDedup is the state of the repeats of a notification with the same dedup key of a user on a channel,
+// FirstSeenTime is the start of the ongoing event and Repeats are its occurrences that were not notified since
+// LastSentTime
+type NotificationDedup struct {
+	UserID        uint64              `db:"user_id"`
+	Channel       NotificationChannel `db:"channel"`
+	DedupKey      string              `db:"dedup_key"`
+	FirstSeenTime time.Time           `db:"first_seen_ts"`
+	LastSeenTime  time.Time           `db:"last_seen_ts"`
+	LastSentTime  time.Time           `db:"last_sent_ts"`
+	Repeats       uint64              `db:"repeats"`
+	FollowUps     uint64              `db:"follow_ups"`
+}
+
+// FeatureFlagOverride is the state of a feature flag set at runtime, it takes precedence over the config
+type FeatureFlagOverride struct {
+	Name        string    `db:"name"`
+	Enabled     bool      `db:"enabled"`
+	UpdatedBy   uint64    `db:"updated_by"`
+	UpdatedTime time.Time `db:"updated_ts"`
+}
+
 type TaggedValidators struct {
 	UserID             uint64 `db:"user_id"`
 	Tag                string `db:"tag"`
@@ -159,7 +686,73 @@ type UserWithPremium struct {
 	Product sql.NullString `db:"product_id"`
 }
 
+// Entitlement is a feature of the explorer and the mobile app that is only available in some premium packages
+type Entitlement string
+
+const (
+	// AdvancedNotificationsEntitlement allows notification thresholds and custom notification rules
+	AdvancedNotificationsEntitlement Entitlement = "advanced_notifications"
+	// WidgetsEntitlement allows the widgets of the mobile app
+	WidgetsEntitlement Entitlement = "widgets"
+	// NoAdsEntitlement hides the ads of the explorer
+	NoAdsEntitlement Entitlement = "no_ads"
+)
+
+// Entitlements are the limits and features granted by the premium package of a user, the package is bought via stripe
+// or the app stores
+type Entitlements struct {
+	Package                string
+	MaxValidators          int
+	MaxWatchedValidators   int
+	MaxStats               uint64
+	MaxNodes               uint64
+	WidgetSupport          bool
+	NotificationThresholds bool
+	NoAds                  bool
+}
+
+// Has returns true if the entitlements include the feature
+func (e Entitlements) Has(entitlement Entitlement) bool {
+	switch entitlement {
+	case AdvancedNotificationsEntitlement:
+		return e.NotificationThresholds
+	case WidgetsEntitlement:
+		return e.WidgetSupport
+	case NoAdsEntitlement:
+		return e.NoAds
+	}
+	return false
+}
+
+// ApiEntitlements are the request quotas granted by the api plan of a user, a quota of -1 is unlimited
+type ApiEntitlements struct {
+	Plan         string
+	DailyQuota   int
+	MonthlyQuota int
+}
+
 type EmailAttachment struct {
 	Attachment []byte
 	Name       string
 }
+
+// UserDeletionRequest is a pending deletion of a user account, the account is deleted once ScheduledTs has passed
+type UserDeletionRequest struct {
+	UserID      uint64    `db:"user_id"`
+	RequestedTs time.Time `db:"requested_ts"`
+	ScheduledTs time.Time `db:"scheduled_ts"`
+}
+
+// UserDeletionDue is a user whose account deletion is due with the stripe customer that has to be deleted first
+type UserDeletionDue struct {
+	UserID           uint64         `db:"user_id"`
+	StripeCustomerID sql.NullString `db:"stripe_customer_id"`
+}
+
+// Sitemap is the content of the sitemaps for search engine crawlers, the validators are listed by index and the
+// proposed blocks of the recent slots by slot starting with the latest one
+type Sitemap struct {
+	ValidatorCount uint64
+	BlockSlots     []uint64
+	Ts             time.Time
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Wei is an exact amount of ether in wei. Sums of gwei balances and wei execution rewards are aggregated in Wei so
+// they do not lose precision like float64, the zero value is 0 wei.
+type Wei struct {
+	i *big.Int
+}
+
+var weiPerGwei = big.NewInt(1e9)
+
+// NewWei returns the amount of wei, the value is copied
+func NewWei(wei *big.Int) Wei {
+	if wei == nil {
+		return Wei{}
+	}
+	return Wei{i: new(big.Int).Set(wei)}
+}
+
+// WeiFromGwei converts an amount of gwei as used for consensus layer balances to wei
+func WeiFromGwei(gwei int64) Wei {
+	return Wei{i: new(big.Int).Mul(big.NewInt(gwei), weiPerGwei)}
+}
+
+// WeiFromString parses a decimal amount of wei
+func WeiFromString(s string) (Wei, error) {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Wei{}, fmt.Errorf("invalid amount of wei: %q", s)
+	}
+	return Wei{i: i}, nil
+}
+
+// Int returns a copy of the amount of wei
+func (w Wei) Int() *big.Int {
+	if w.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(w.i)
+}
+
+// Add returns the sum of the amounts
+func (w Wei) Add(o Wei) Wei {
+	return Wei{i: new(big.Int).Add(w.Int(), o.Int())}
+}
+
+// Sub returns the difference of the amounts
+func (w Wei) Sub(o Wei) Wei {
+	return Wei{i: new(big.Int).Sub(w.Int(), o.Int())}
+}
+
+// Sign returns -1, 0 or 1 like big.Int.Sign
+func (w Wei) Sign() int {
+	if w.i == nil {
+		return 0
+	}
+	return w.i.Sign()
+}
+
+// Gwei returns the amount in gwei, fractions of a gwei are truncated
+func (w Wei) Gwei() int64 {
+	return new(big.Int).Quo(w.Int(), weiPerGwei).Int64()
+}
+
+// Rat returns the amount in the unit that has 10^decimals wei, e.g. 18 for ether
+func (w Wei) Rat(decimals int) *big.Rat {
+	return new(big.Rat).SetFrac(w.Int(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+}
+
+func (w Wei) String() string {
+	return w.Int().String()
+}
+
+// MarshalJSON encodes the amount as decimal string, json numbers are float64 in most clients
+func (w Wei) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.String())
+}
+
+// UnmarshalJSON decodes the amount from a decimal string or number
+func (w *Wei) UnmarshalJSON(b []byte) error {
+	var s string
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(b)
+	}
+	parsed, err := WeiFromString(s)
+	if err != nil {
+		return err
+	}
+	*w = parsed
+	return nil
+}
+
+// Value stores the amount in a numeric column
+func (w Wei) Value() (driver.Value, error) {
+	return w.String(), nil
+}
+
+// Scan reads the amount from a numeric column, null is 0 wei
+func (w *Wei) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*w = Wei{}
+		return nil
+	case int64:
+		*w = Wei{i: big.NewInt(v)}
+		return nil
+	case []byte:
+		return w.scanString(string(v))
+	case string:
+		return w.scanString(v)
+	default:
+		return fmt.Errorf("can not scan %T into Wei", src)
+	}
+}
+
+func (w *Wei) scanString(s string) error {
+	parsed, err := WeiFromString(s)
+	if err != nil {
+		return err
+	}
+	*w = parsed
+	return nil
+}
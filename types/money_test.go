@@ -0,0 +1,201 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestWeiConversions(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Wei
+		wantWei  string
+		wantGwei int64
+		wantSign int
+	}{
+		{name: "zero value", amount: Wei{}, wantWei: "0", wantGwei: 0, wantSign: 0},
+		{name: "nil big.Int", amount: NewWei(nil), wantWei: "0", wantGwei: 0, wantSign: 0},
+		{name: "gwei", amount: WeiFromGwei(32000000000), wantWei: "32000000000000000000", wantGwei: 32000000000, wantSign: 1},
+		{name: "negative gwei", amount: WeiFromGwei(-1), wantWei: "-1000000000", wantGwei: -1, wantSign: -1},
+		{name: "sub-gwei is truncated", amount: NewWei(big.NewInt(999999999)), wantWei: "999999999", wantGwei: 0, wantSign: 1},
+		{name: "negative sub-gwei is truncated towards zero", amount: NewWei(big.NewInt(-1500000000)), wantWei: "-1500000000", wantGwei: -1, wantSign: -1},
+		{name: "one wei", amount: NewWei(big.NewInt(1)), wantWei: "1", wantGwei: 0, wantSign: 1},
+		{name: "balance beyond int64 wei", amount: WeiFromGwei(120000000000000000), wantWei: "120000000000000000000000000", wantGwei: 120000000000000000, wantSign: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.String(); got != tt.wantWei {
+				t.Errorf("got %v wei, want %v", got, tt.wantWei)
+			}
+			if got := tt.amount.Gwei(); got != tt.wantGwei {
+				t.Errorf("got %v gwei, want %v", got, tt.wantGwei)
+			}
+			if got := tt.amount.Sign(); got != tt.wantSign {
+				t.Errorf("got sign %v, want %v", got, tt.wantSign)
+			}
+		})
+	}
+}
+
+func TestWeiArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Wei
+		wantAdd string
+		wantSub string
+	}{
+		{name: "zero values", a: Wei{}, b: Wei{}, wantAdd: "0", wantSub: "0"},
+		{name: "gwei and sub-gwei", a: WeiFromGwei(1), b: NewWei(big.NewInt(1)), wantAdd: "1000000001", wantSub: "999999999"},
+		{name: "negative result", a: NewWei(big.NewInt(1)), b: WeiFromGwei(1), wantAdd: "1000000001", wantSub: "-999999999"},
+		{name: "large balances", a: WeiFromGwei(120000000000000000), b: NewWei(big.NewInt(1)), wantAdd: "120000000000000000000000001", wantSub: "119999999999999999999999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Add(tt.b).String(); got != tt.wantAdd {
+				t.Errorf("got sum %v, want %v", got, tt.wantAdd)
+			}
+			if got := tt.a.Sub(tt.b).String(); got != tt.wantSub {
+				t.Errorf("got difference %v, want %v", got, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestWeiCopies(t *testing.T) {
+	i := big.NewInt(5)
+	w := NewWei(i)
+	i.SetInt64(6)
+	w.Int().SetInt64(7)
+	if w.String() != "5" {
+		t.Errorf("got %v wei, the amount must not change with the big.Int it was created from or returned", w.String())
+	}
+}
+
+func TestWeiRat(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Wei
+		decimals int
+		want     string
+	}{
+		{name: "one wei in ether", amount: NewWei(big.NewInt(1)), decimals: 18, want: "1/1000000000000000000"},
+		{name: "negative gwei in gwei", amount: WeiFromGwei(-3), decimals: 9, want: "-3/1"},
+		{name: "ether", amount: WeiFromGwei(1500000000), decimals: 18, want: "3/2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Rat(tt.decimals).String(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeiFromString(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "0", want: "0"},
+		{input: "-1", want: "-1"},
+		{input: "120000000123456789000000000", want: "120000000123456789000000000"},
+		{input: "1.5", wantErr: true},
+		{input: "1e18", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := WeiFromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("got %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWeiJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		wantJSON string
+		wantErr  bool
+	}{
+		{name: "string", input: `"120000000123456789000000000"`, want: "120000000123456789000000000", wantJSON: `"120000000123456789000000000"`},
+		{name: "number", input: `123`, want: "123", wantJSON: `"123"`},
+		{name: "negative string", input: `"-1"`, want: "-1", wantJSON: `"-1"`},
+		{name: "fraction", input: `1.5`, wantErr: true},
+		{name: "invalid string", input: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := Wei{}
+			err := json.Unmarshal([]byte(tt.input), &w)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if w.String() != tt.want {
+				t.Errorf("got %v, want %v", w.String(), tt.want)
+			}
+			b, err := json.Marshal(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.wantJSON {
+				t.Errorf("got json %s, want %s", b, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestWeiScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "null", src: nil, want: "0"},
+		{name: "int64", src: int64(-5), want: "-5"},
+		{name: "numeric bytes", src: []byte("120000000123456789000000000"), want: "120000000123456789000000000"},
+		{name: "numeric string", src: "42", want: "42"},
+		{name: "float", src: float64(1), wantErr: true},
+		{name: "fraction", src: "1.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := WeiFromGwei(1)
+			err := w.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if w.String() != tt.want {
+				t.Errorf("got %v, want %v", w.String(), tt.want)
+			}
+			v, err := w.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tt.want {
+				t.Errorf("got value %v, want %v", v, tt.want)
+			}
+		})
+	}
+}
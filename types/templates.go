@@ -55,7 +55,12 @@ type PageData struct {
 	IsUserClientUpdated   func(uint64) bool
 	Phase0                Phase0
 	Lang                  string
+	Theme                 string
 	NoAds                 bool
+	FeatureFlags          map[string]bool
+	UpcomingFork          *Fork
+	PersonalMode          bool
+	Embed                 bool // renders the page without the banner, navigation and footer for iframes
 }
 
 // Meta is a struct to hold metadata about the page
@@ -63,12 +68,49 @@ type Meta struct {
 	Title       string
 	Description string
 	Path        string
+	Image       string
 	Tlabel1     string
 	Tdata1      string
 	Tlabel2     string
 	Tdata2      string
 	GATag       string
 	NoTrack     bool
+	// StructuredData is rendered as JSON-LD so search engines can describe the entity of the page in their results
+	StructuredData *StructuredData
+}
+
+// StructuredData is the schema.org description of a page about a single entity (e.g. a validator, epoch or block)
+type StructuredData struct {
+	Context      string                   `json:"@context"`
+	Type         string                   `json:"@type"`
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description"`
+	URL          string                   `json:"url"`
+	DateModified *time.Time               `json:"dateModified,omitempty"`
+	About        StructuredDataEntity     `json:"about"`
+	Breadcrumb   StructuredDataBreadcrumb `json:"breadcrumb"`
+}
+
+// StructuredDataEntity is the entity a page is about
+type StructuredDataEntity struct {
+	Type        string `json:"@type"`
+	Name        string `json:"name"`
+	Identifier  string `json:"identifier"`
+	Description string `json:"description"`
+}
+
+// StructuredDataBreadcrumb is the path from the home page over the list of the entities to the page
+type StructuredDataBreadcrumb struct {
+	Type            string                         `json:"@type"`
+	ItemListElement []StructuredDataBreadcrumbItem `json:"itemListElement"`
+}
+
+// StructuredDataBreadcrumbItem is a page of a StructuredDataBreadcrumb
+type StructuredDataBreadcrumbItem struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Item     string `json:"item"`
 }
 
 // LatestState is a struct to hold data for the banner
@@ -270,6 +312,7 @@ type ValidatorPageData struct {
 	Balance7d                           uint64 `db:"balance7d"`
 	Balance31d                          uint64 `db:"balance31d"`
 	EffectiveBalance                    uint64 `db:"effectivebalance"`
+	WithdrawalCredentials               []byte `db:"withdrawalcredentials"`
 	Slashed                             bool   `db:"slashed"`
 	SlashedBy                           uint64
 	SlashedAt                           uint64
@@ -308,9 +351,9 @@ type ValidatorPageData struct {
 	MissedSyncCount                     uint64
 	OrphanedSyncCount                   uint64
 	UnmissedSyncPercentage              float64 // missed/(participated+orphaned)
-	Income1d                            int64
-	Income7d                            int64
-	Income31d                           int64
+	Income1d                            Wei
+	Income7d                            Wei
+	Income31d                           Wei
 	Rank7d                              int64 `db:"rank7d"`
 	RankCount                           int64 `db:"rank_count"`
 	RankPercentage                      float64
@@ -325,14 +368,30 @@ type ValidatorPageData struct {
 	User                                *User
 	AverageAttestationInclusionDistance float64
 	AttestationInclusionEffectiveness   float64
+	Effectiveness                       []*ValidatorEffectiveness
+	EffectivenessFormula                string
 	CsrfField                           template.HTML
 	NetworkStats                        *IndexPageData
 	EstimatedActivationTs               int64
 	InclusionDelay                      int64
+	PendingDeposit                      *Eth1PendingDeposit
+	FeeRecipients                       []*ValidatorFeeRecipient
+	BlockPropagation                    *BlockPropagationStats
+	Packing                             *ValidatorPacking
+	PeerComparison                      *ValidatorPeerComparison
+	EstimatedInclusionTs                time.Time
+	EstimatedActivationEpoch            uint64
 	CurrentAttestationStreak            uint64
 	LongestAttestationStreak            uint64
 	IsRocketpool                        bool
 	Rocketpool                          *RocketpoolValidatorPageData
+	OwnerVerified                       bool
+	OwnedByUser                         bool
+	ConsolidatedInto                    *uint64
+	FullWithdrawal                      *ValidatorFullWithdrawal
+	Hosting                             *ValidatorHosting
+	HostingProviders                    []string
+	HostingRegions                      []string
 }
 
 type RocketpoolValidatorPageData struct {
@@ -513,6 +572,15 @@ type VotesVisChartData struct {
 	Validators pq.Int64Array `db:"validators" json:"validators"`
 }
 
+// ValidatorFeeRecipient holds how often a validator used a fee recipient in its proposed blocks
+type ValidatorFeeRecipient struct {
+	Proposer     uint64 `db:"proposer"`
+	FeeRecipient []byte `db:"exec_fee_recipient"`
+	BlocksCount  uint64 `db:"blocks_count"`
+	FirstSlot    uint64 `db:"first_slot"`
+	LastSlot     uint64 `db:"last_slot"`
+}
+
 // BlockPageData is a struct block data used in the block page
 type BlockPageData struct {
 	Epoch                  uint64 `db:"epoch"`
@@ -540,12 +608,22 @@ type BlockPageData struct {
 	AttestationsCount      uint64  `db:"attestationscount"`
 	DepositsCount          uint64  `db:"depositscount"`
 	VoluntaryExitscount    uint64  `db:"voluntaryexitscount"`
+	ExecBlockHash          []byte  `db:"exec_block_hash"`
+	ExecBlockNumber        uint64  `db:"exec_block_number"`
+	ExecFeeRecipient       []byte  `db:"exec_fee_recipient"`
+	ExecGasUsed            uint64  `db:"exec_gas_used"`
+	ExecGasLimit           uint64  `db:"exec_gas_limit"`
+	ExecBaseFeePerGas      uint64  `db:"exec_base_fee_per_gas"`
+	ExecTransactionsCount  uint64  `db:"exec_transactions_count"`
+	ExecGasUsedPercent     float64
+	ExecBaseFeePerGasGwei  float64
 	SlashingsCount         uint64
 	VotesCount             uint64
 	VotingValidatorsCount  uint64
 	Mainnet                bool
 
 	SyncCommittee     []uint64
+	Packing           *BlockPacking
 	Attestations      []*BlockPageAttestation // Attestations included in this block
 	VoluntaryExits    []*BlockPageVoluntaryExits
 	Votes             []*BlockVote // Attestations that voted for that block
@@ -705,6 +783,29 @@ type EpochPageData struct {
 	OrphanedCount         uint64
 }
 
+// EpochSummary is the precomputed summary of an epoch the indexer writes to the epochs_summary table
+type EpochSummary struct {
+	Epoch                   uint64    `db:"epoch" json:"epoch"`
+	ValidatorsCount         uint64    `db:"validatorscount" json:"validatorscount"`
+	TotalValidatorBalance   uint64    `db:"totalvalidatorbalance" json:"totalvalidatorbalance"`
+	AverageValidatorBalance uint64    `db:"averagevalidatorbalance" json:"averagevalidatorbalance"`
+	EligibleEther           uint64    `db:"eligibleether" json:"eligibleether"`
+	VotedEther              uint64    `db:"votedether" json:"votedether"`
+	GlobalParticipationRate float64   `db:"globalparticipationrate" json:"globalparticipationrate"`
+	Finalized               bool      `db:"finalized" json:"finalized"`
+	AttestationsCount       uint64    `db:"attestationscount" json:"attestationscount"`
+	DepositsCount           uint64    `db:"depositscount" json:"depositscount"`
+	VoluntaryExitsCount     uint64    `db:"voluntaryexitscount" json:"voluntaryexitscount"`
+	ProposerSlashingsCount  uint64    `db:"proposerslashingscount" json:"proposerslashingscount"`
+	AttesterSlashingsCount  uint64    `db:"attesterslashingscount" json:"attesterslashingscount"`
+	ScheduledBlocks         uint64    `db:"scheduledblocks" json:"scheduledblocks"`
+	ProposedBlocks          uint64    `db:"proposedblocks" json:"proposedblocks"`
+	MissedBlocks            uint64    `db:"missedblocks" json:"missedblocks"`
+	OrphanedBlocks          uint64    `db:"orphanedblocks" json:"orphanedblocks"`
+	SyncParticipationRate   float64   `db:"syncparticipationrate" json:"syncparticipationrate"`
+	UpdatedTs               time.Time `db:"updated_ts" json:"-"`
+}
+
 // EpochPageMinMaxSlot is a struct for the min/max epoch data
 type EpochPageMinMaxSlot struct {
 	MinEpoch uint64
@@ -764,6 +865,8 @@ type GenericChartData struct {
 	ColumnDataGroupingApproximation string                    // "average", "averages", "open", "high", "low", "close" and "sum"
 	Series                          []*GenericChartDataSeries `json:"series"`
 	Drilldown                       interface{}               `json:"drilldown"`
+	// XAxisPlotLines mark events like forks on the time axis of the chart
+	XAxisPlotLines []*ChartPlotLine `json:"x_axis_plot_lines"`
 }
 
 type SeriesDataItem struct {
@@ -798,6 +901,11 @@ type DashboardData struct {
 	// Validators     [][]interface{}                  `json:"validators"`
 	Csrf           string `json:"csrf"`
 	ValidatorLimit int    `json:"valLimit"`
+	// Shared is set for the read-only dashboard of a public user tag, its validators can not be changed
+	Shared           bool     `json:"shared"`
+	SharedID         string   `json:"sharedId"`
+	SharedName       string   `json:"sharedName"`
+	SharedValidators []uint64 `json:"sharedValidators"`
 }
 
 // DashboardValidatorBalanceHistory is a struct to hold data for the balance-history on the dashboard-page
@@ -825,6 +933,18 @@ type ValidatorEarnings struct {
 	LastMonthFormatted      template.HTML `json:"lastMonthFormatted"`
 	TotalFormatted          template.HTML `json:"totalFormatted"`
 	TotalChangeFormatted    template.HTML `json:"totalChangeFormatted"`
+	// El are the el rewards of the proposed blocks, Income is the sum of the consensus layer earnings (Total,
+	// LastDay, ...) and the el rewards
+	El     ValidatorIncomePeriods `json:"el"`
+	Income ValidatorIncomePeriods `json:"income"`
+}
+
+// ValidatorIncomePeriods are exact amounts of income in total and of the last day, week and month
+type ValidatorIncomePeriods struct {
+	Total     Wei `db:"total" json:"total"`
+	LastDay   Wei `db:"last_day" json:"lastDay"`
+	LastWeek  Wei `db:"last_week" json:"lastWeek"`
+	LastMonth Wei `db:"last_month" json:"lastMonth"`
 }
 
 // ValidatorAttestationSlashing is a struct to hold data of an attestation-slashing
@@ -908,6 +1028,17 @@ type EthOneDepositLeaderboardData struct {
 	VoluntaryExitCount uint64 `db:"voluntary_exit_count"`
 }
 
+// Eth1Depositor holds the aggregated deposits of a single eth1-address
+type Eth1Depositor struct {
+	FromAddress    []byte    `db:"from_address"`
+	Name           string    `db:"name"`
+	Category       string    `db:"category"`
+	DepositCount   uint64    `db:"deposit_count"`
+	Amount         uint64    `db:"amount"`
+	FirstDepositTs time.Time `db:"first_deposit_ts"`
+	LastDepositTs  time.Time `db:"last_deposit_ts"`
+}
+
 type EthTwoDepositData struct {
 	BlockSlot             uint64 `db:"block_slot"`
 	BlockIndex            uint64 `db:"block_index"`
@@ -973,6 +1104,16 @@ type AuthData struct {
 	Email     string
 	State     string
 	CsrfField template.HTML
+	Captcha   *CaptchaData
+}
+
+// CaptchaData is the template data of the captcha of a form, invisible captchas are bound to the submit button and call
+// the onSubmit function of the page, visible ones render a widget inside the form
+type CaptchaData struct {
+	ScriptURL   string
+	SiteKey     string
+	WidgetClass string
+	Invisible   bool
 }
 
 type CsrfData struct {
@@ -990,6 +1131,21 @@ type UserSettingsPageData struct {
 	Diamond             *string
 	ShareMonitoringData bool
 	ApiStatistics       *ApiStatistics
+	FeeRecipient        *UserFeeRecipient
+	DeletionRequest     *UserDeletionRequest
+	Entitlements        Entitlements
+	ApiEntitlements     ApiEntitlements
+	Sessions            []*UserSession
+}
+
+// UserSession is a login of a user, Current marks the session of the request
+type UserSession struct {
+	ID        uint64    `db:"id" json:"id"`
+	Device    string    `db:"device" json:"device"`
+	IP        string    `db:"ip" json:"ip"`
+	CreatedTs time.Time `db:"created_ts" json:"created_ts"`
+	LastSeen  time.Time `db:"last_seen" json:"last_seen"`
+	Current   bool      `db:"-" json:"current"`
 }
 
 type PairedDevice struct {
@@ -1040,14 +1196,14 @@ type UserValidatorNotificationTableData struct {
 type AdvertiseWithUsPageData struct {
 	FlashMessage string
 	CsrfField    template.HTML
-	RecaptchaKey string
+	Captcha      *CaptchaData
 }
 
 type ApiPricing struct {
 	FlashMessage string
 	User         *User
 	CsrfField    template.HTML
-	RecaptchaKey string
+	Captcha      *CaptchaData
 	Subscription UserSubscription
 	StripePK     string
 	Sapphire     string
@@ -1059,7 +1215,6 @@ type MobilePricing struct {
 	FlashMessage         string
 	User                 *User
 	CsrfField            template.HTML
-	RecaptchaKey         string
 	Subscription         UserSubscription
 	StripePK             string
 	Plankton             string
@@ -1070,9 +1225,104 @@ type MobilePricing struct {
 
 type StakeWithUsPageData struct {
 	FlashMessage string
-	RecaptchaKey string
+	Captcha      *CaptchaData
 	NoAds        bool
 }
+
+// BLSChangePageData is a struct to hold data for the bls change tool page
+type BLSChangePageData struct {
+	FlashMessage     string
+	BroadcastEnabled bool
+	ValidatorsFilter string
+	Changes          []*BLSChange
+	PoolSize         int
+	PoolUnavailable  bool
+}
+
+// DepositVerifierPageData is a struct to hold data for the deposit data verifier page
+type DepositVerifierPageData struct {
+	FlashMessage string
+	Network      string
+	ForkVersion  string
+	FileName     string
+	Results      []*DepositDataVerification
+	ValidCount   int
+	WarningCount int
+	InvalidCount int
+	WastedCount  int
+}
+
+// DepositDataVerification is the result of verifying a single deposit of a deposit_data.json file, the status is one
+// of valid, warning, invalid (the deposit contract rejects it) or wasted (the deposit is accepted but the ether lost)
+type DepositDataVerification struct {
+	Pubkey                string   `json:"pubkey"`
+	WithdrawalCredentials string   `json:"withdrawal_credentials"`
+	Amount                uint64   `json:"amount"`
+	Validatorindex        *int64   `json:"validatorindex"`
+	Status                string   `json:"status"`
+	Errors                []string `json:"errors"`
+	Warnings              []string `json:"warnings"`
+}
+
+// DepositToolPageData is a struct to hold data for the guided deposit page
+type DepositToolPageData struct {
+	FlashMessage    string
+	Network         string
+	DepositContract string
+	ChainID         uint64
+	FileName        string
+	Transactions    []*DepositTransaction
+	TxFilter        string
+	Submissions     []*DepositSubmission
+}
+
+// DepositTransaction is the deposit contract transaction of a deposit of a deposit_data.json file, the transaction is
+// empty for deposits that are invalid or wasted. Value is in wei, PaymentURI is an EIP-681 transaction request that is
+// also the payload of the QR code.
+type DepositTransaction struct {
+	Verification         *DepositDataVerification `json:"verification"`
+	To                   string                   `json:"to,omitempty"`
+	Value                string                   `json:"value,omitempty"`
+	Data                 string                   `json:"data,omitempty"`
+	ChainID              uint64                   `json:"chain_id,omitempty"`
+	PaymentURI           string                   `json:"payment_uri,omitempty"`
+	WalletConnectRequest *WalletConnectRequest    `json:"walletconnect_request,omitempty"`
+	DeepLinks            map[string]string        `json:"deep_links,omitempty"`
+}
+
+// PaymentLink returns the EIP-681 transaction request as link, the ethereum scheme is not allowed in links of templates
+// by default
+func (tx *DepositTransaction) PaymentLink() template.URL {
+	return template.URL(tx.PaymentURI)
+}
+
+// WalletConnectRequest are the params of a WalletConnect session request, the wallet sets the sender of the transaction
+type WalletConnectRequest struct {
+	ChainID string              `json:"chainId"`
+	Method  string              `json:"method"`
+	Params  []map[string]string `json:"params"`
+}
+
+// DepositSubmission is a deposit transaction tracked with the guided deposit page and the progress of its validator
+type DepositSubmission struct {
+	TxHash         []byte    `db:"tx_hash" json:"tx_hash"`
+	Pubkey         []byte    `db:"pubkey" json:"pubkey"`
+	SubmittedTs    time.Time `db:"submitted_ts" json:"submitted_ts"`
+	BlockNumber    *uint64   `db:"block_number" json:"block_number"`
+	Validatorindex *uint64   `db:"validatorindex" json:"validatorindex"`
+	Status         string    `db:"status" json:"status"`
+}
+
+// BLSChange is a struct to hold a bls to execution change submitted through the explorer and its progress
+type BLSChange struct {
+	Validatorindex uint64    `db:"validatorindex" json:"validatorindex"`
+	Address        []byte    `db:"address" json:"address"`
+	SubmittedTs    time.Time `db:"submitted_ts" json:"submitted_ts"`
+	IncludedSlot   uint64    `db:"included_slot" json:"included_slot"`
+	Completed      bool      `db:"completed" json:"completed"`
+	InPool         bool      `json:"in_pool"`
+}
+
 type RateLimitError struct {
 	TimeLeft time.Duration
 }
@@ -1084,8 +1334,8 @@ func (e *RateLimitError) Error() string {
 type Empty struct {
 }
 
-// GoogleRecaptchaResponse ...
-type GoogleRecaptchaResponse struct {
+// CaptchaResponse is the response of the siteverify endpoint of recaptcha, hcaptcha and turnstile
+type CaptchaResponse struct {
 	Success            bool     `json:"success"`
 	ChallengeTimestamp string   `json:"challenge_ts"`
 	Hostname           string   `json:"hostname"`
@@ -1128,13 +1378,23 @@ type RocketpoolPageDataMinipool struct {
 }
 
 type RocketpoolPageDataNode struct {
-	TotalCount               uint64 `db:"total_count"`
-	RocketpoolStorageAddress []byte `db:"rocketpool_storage_address"`
-	Address                  []byte `db:"address"`
-	TimezoneLocation         string `db:"timezone_location"`
-	RPLStake                 string `db:"rpl_stake"`
-	MinRPLStake              string `db:"min_rpl_stake"`
-	MaxRPLStake              string `db:"max_rpl_stake"`
+	TotalCount               uint64   `db:"total_count"`
+	RocketpoolStorageAddress []byte   `db:"rocketpool_storage_address"`
+	Address                  []byte   `db:"address"`
+	TimezoneLocation         string   `db:"timezone_location"`
+	RPLStake                 string   `db:"rpl_stake"`
+	MinRPLStake              string   `db:"min_rpl_stake"`
+	MaxRPLStake              string   `db:"max_rpl_stake"`
+	FeeDistributorAddress    []byte   `db:"fee_distributor_address"`
+	SmoothingPoolOptedIn     bool     `db:"smoothing_pool_opted_in"`
+	FeeRecipientViolations   uint64   `db:"fee_recipient_violations"`
+	DepositCredit            *string  `db:"deposit_credit"`
+	ETHMatched               *string  `db:"eth_matched"`
+	ETHProvided              *string  `db:"eth_provided"`
+	ETHMatchedLimit          *string  `db:"eth_matched_limit"`
+	LEB8Minipools            uint64   `db:"leb8_minipools"`
+	LEB16Minipools           uint64   `db:"leb16_minipools"`
+	Collateralization        *float64 `db:"collateralization"`
 }
 
 type RocketpoolPageDataDAOProposal struct {
@@ -1156,6 +1416,7 @@ type RocketpoolPageDataDAOProposal struct {
 	IsCancelled              bool      `db:"is_cancelled"`
 	IsExecuted               bool      `db:"is_executed"`
 	Payload                  []byte    `db:"payload"`
+	PayloadDecoded           string    `db:"payload_decoded"`
 	State                    string    `db:"state"`
 }
 
@@ -1170,3 +1431,393 @@ type RocketpoolPageDataDAOMember struct {
 	RPLBondAmount            string    `db:"rpl_bond_amount"`
 	UnbondedValidatorCount   uint64    `db:"unbonded_validator_count"`
 }
+
+// NetworkIncident is a struct to hold the data of a detected network incident (low participation or non-finality)
+type NetworkIncident struct {
+	ID               uint64    `db:"id" json:"id"`
+	Type             string    `db:"type" json:"type"`
+	StartEpoch       uint64    `db:"start_epoch" json:"start_epoch"`
+	EndEpoch         uint64    `db:"end_epoch" json:"end_epoch"`
+	MinParticipation float64   `db:"min_participation" json:"min_participation"`
+	MaxFinalityDelay uint64    `db:"max_finality_delay" json:"max_finality_delay"`
+	Resolved         bool      `db:"resolved" json:"resolved"`
+	CreatedTs        time.Time `db:"created_ts" json:"created_ts"`
+	UpdatedTs        time.Time `db:"updated_ts" json:"updated_ts"`
+}
+
+// NotificationsLogPageData is the data of the notification history page of a user
+type NotificationsLogPageData struct {
+	UserID    uint64
+	Entries   []*NotificationLogEntry
+	IsAdmin   bool
+	Flashes   []interface{}
+	CsrfField template.HTML
+	// TestChannels are the channels a test notification can be sent on, only users viewing their own history can send one
+	TestChannels []NotificationChannel
+}
+
+// FeatureFlag is a feature flag as shown on the feature flags admin page
+type FeatureFlag struct {
+	Name        string
+	Description string
+	Configured  bool
+	Enabled     bool
+	Override    *FeatureFlagOverride
+}
+
+// FeatureFlagsPageData is the data of the feature flags admin page
+type FeatureFlagsPageData struct {
+	Flags     []*FeatureFlag
+	Flashes   []interface{}
+	CsrfField template.HTML
+}
+
+// NetworkIncidentsPageData is a struct to hold the data for the network incidents page
+type NetworkIncidentsPageData struct {
+	Incidents              []*NetworkIncident
+	ParticipationThreshold float64
+	FinalityDelayThreshold uint64
+}
+
+// DecentralizationStats is the concentration of the stake of the validators clustered by a dimension on a day
+type DecentralizationStats struct {
+	Day             uint64  `db:"day" json:"day"`
+	Dimension       string  `db:"dimension" json:"dimension"`
+	Clusters        uint64  `db:"clusters" json:"clusters"`
+	Validators      uint64  `db:"validators" json:"validators"`
+	Stake           uint64  `db:"stake" json:"stake"`
+	Gini            float64 `db:"gini" json:"gini"`
+	Nakamoto        uint64  `db:"nakamoto" json:"nakamoto"`
+	TopClusterShare float64 `db:"top_cluster_share" json:"top_cluster_share"`
+}
+
+// EconomicsStats are the daily deposit contract balance, staked ether, circulating supply, issuance and burn of the
+// network, all amounts are in gwei
+type EconomicsStats struct {
+	Day                    uint64   `db:"day" json:"day"`
+	DepositContractBalance uint64   `db:"deposit_contract_balance" json:"deposit_contract_balance"`
+	TotalEffectiveBalance  uint64   `db:"total_effective_balance" json:"total_effective_balance"`
+	TotalBalance           uint64   `db:"total_balance" json:"total_balance"`
+	CirculatingSupply      *uint64  `db:"circulating_supply" json:"circulating_supply"`
+	StakingRatio           *float64 `db:"staking_ratio" json:"staking_ratio"`
+	Issuance               int64    `db:"issuance" json:"issuance"`
+	Burn                   uint64   `db:"burn" json:"burn"`
+}
+
+// ValidatorCluster is a group of validators that likely belong to the same operator, the cluster is the first value of
+// the dimension the validators were grouped by, e.g. deposit_address:0x...
+type ValidatorCluster struct {
+	Day        uint64  `db:"day" json:"day"`
+	Dimension  string  `db:"dimension" json:"dimension"`
+	Cluster    string  `db:"cluster" json:"cluster"`
+	Validators uint64  `db:"validators" json:"validators"`
+	Stake      uint64  `db:"stake" json:"stake"`
+	Share      float64 `db:"share" json:"share"` // share of the stake of the clustered validators of the dimension
+}
+
+// DecentralizationPageData is a struct to hold the data for the decentralization page
+type DecentralizationPageData struct {
+	Dimension  string
+	Dimensions []string
+	Latest     *DecentralizationStats
+	Clusters   []*ValidatorCluster
+}
+
+// ValidatorHosting is the hosting provider and region a user declared for a validator they verified the ownership of,
+// with the source "metrics" the provider is inferred from the node metrics the user submits
+type ValidatorHosting struct {
+	UserID             uint64    `db:"user_id"`
+	ValidatorPublickey []byte    `db:"validator_publickey"`
+	Provider           string    `db:"provider"`
+	Region             string    `db:"region"`
+	Source             string    `db:"source"`
+	UpdatedTs          time.Time `db:"updated_ts"`
+}
+
+// HostingStats is the attestation performance of the validators hosted at a provider in a region on a day, only
+// aggregates of several users are published
+type HostingStats struct {
+	Day                    uint64  `db:"day" json:"day"`
+	Provider               string  `db:"provider" json:"provider"`
+	Region                 string  `db:"region" json:"region"`
+	Validators             uint64  `db:"validators" json:"validators"`
+	Share                  float64 `db:"share" json:"share"` // share of all validators with a declared hosting
+	Effectiveness          float64 `db:"effectiveness" json:"effectiveness"`
+	MissedAttestationsRate float64 `db:"missed_attestations_rate" json:"missed_attestations_rate"`
+}
+
+// HostingPageData is a struct to hold the data for the hosting analytics page
+type HostingPageData struct {
+	Latest               []*HostingStats
+	MinUsers             uint64
+	EffectivenessFormula string
+}
+
+// HealthStatus is the health of the explorer itself as shown on the status page
+type HealthStatus struct {
+	Healthy                 bool              `json:"healthy"`
+	Ts                      time.Time         `json:"ts"`
+	IndexedEpoch            uint64            `json:"indexed_epoch"`
+	HeadEpoch               uint64            `json:"head_epoch"`
+	IndexerLag              uint64            `json:"indexer_lag"`
+	IndexerHealthy          bool              `json:"indexer_healthy"`
+	ReplicationDelaySeconds float64           `json:"replication_delay_seconds"`
+	ReplicationHealthy      bool              `json:"replication_healthy"`
+	Exporters               []*ExporterHealth `json:"exporters"`
+	Caches                  []*CacheHealth    `json:"caches"`
+}
+
+// ExporterHealth is the health of a single exporter
+type ExporterHealth struct {
+	Name          string    `json:"name"`
+	LastSuccessTs time.Time `json:"last_success_ts"`
+	Healthy       bool      `json:"healthy"`
+}
+
+// CacheHealth is the health of a single in-memory cache of the frontend
+type CacheHealth struct {
+	Name      string    `json:"name"`
+	UpdatedTs time.Time `json:"updated_ts"`
+	Healthy   bool      `json:"healthy"`
+}
+
+// ExporterStatus is the last successful run of an exporter as saved in the db
+type ExporterStatus struct {
+	Name          string    `db:"name"`
+	LastSuccessTs time.Time `db:"last_success_ts"`
+}
+
+// AttestationAggregationStats holds the aggregate attestation packing of the blocks of a single client during an epoch or day
+type AttestationAggregationStats struct {
+	Epoch                 uint64 `db:"epoch" json:"epoch,omitempty"`
+	Day                   uint64 `db:"day" json:"day,omitempty"`
+	Client                string `db:"client" json:"client"`
+	Blocks                uint64 `db:"blocks" json:"blocks"`
+	Aggregates            uint64 `db:"aggregates" json:"aggregates"`
+	Committees            uint64 `db:"committees" json:"committees"`
+	Attestations          uint64 `db:"attestations" json:"attestations"`
+	RedundantAttestations uint64 `db:"redundant_attestations" json:"redundant_attestations"`
+	RedundantAggregates   uint64 `db:"redundant_aggregates" json:"redundant_aggregates"`
+	BytesSaved            uint64 `db:"bytes_saved" json:"bytes_saved"`
+}
+
+// RedundancyRate is the share of included votes that had already been included in an earlier block
+func (s *AttestationAggregationStats) RedundancyRate() float64 {
+	if s.Attestations == 0 {
+		return 0
+	}
+	return float64(s.RedundantAttestations) / float64(s.Attestations)
+}
+
+// AggregatesPerCommittee is the average number of aggregates that were included for the same committee
+func (s *AttestationAggregationStats) AggregatesPerCommittee() float64 {
+	if s.Committees == 0 {
+		return 0
+	}
+	return float64(s.Aggregates) / float64(s.Committees)
+}
+
+// PackingCounts are the attestations and sync committee signatures one or more blocks included and the ones that were
+// available to their proposers
+type PackingCounts struct {
+	AttestationsIncluded  uint64 `db:"attestations_included" json:"attestations_included"`
+	AttestationsAvailable uint64 `db:"attestations_available" json:"attestations_available"`
+	SyncIncluded          uint64 `db:"sync_included" json:"sync_included"`
+	SyncAvailable         uint64 `db:"sync_available" json:"sync_available"`
+}
+
+// AttestationPackingEfficiency is the share of the available attestations that were included
+func (c PackingCounts) AttestationPackingEfficiency() float64 {
+	if c.AttestationsAvailable == 0 {
+		return 0
+	}
+	return float64(c.AttestationsIncluded) / float64(c.AttestationsAvailable)
+}
+
+// SyncPackingEfficiency is the share of the available sync committee signatures that were included
+func (c PackingCounts) SyncPackingEfficiency() float64 {
+	if c.SyncAvailable == 0 {
+		return 0
+	}
+	return float64(c.SyncIncluded) / float64(c.SyncAvailable)
+}
+
+// BlockPacking is the packing efficiency of a canonical block, the client is guessed from the graffiti
+type BlockPacking struct {
+	Slot      uint64 `db:"slot" json:"slot"`
+	BlockRoot []byte `db:"blockroot" json:"blockroot"`
+	Epoch     uint64 `db:"epoch" json:"epoch"`
+	Proposer  uint64 `db:"proposer" json:"proposer"`
+	Client    string `db:"client" json:"client"`
+	PackingCounts
+}
+
+// ValidatorPacking is the packing efficiency of all evaluated blocks of a proposer
+type ValidatorPacking struct {
+	ValidatorIndex uint64 `db:"validatorindex" json:"validatorindex"`
+	Blocks         uint64 `db:"blocks" json:"blocks"`
+	PackingCounts
+}
+
+// BlockPackingStats is the packing efficiency of the blocks of a client on a day
+type BlockPackingStats struct {
+	Day    uint64 `db:"day" json:"day"`
+	Client string `db:"client" json:"client"`
+	Blocks uint64 `db:"blocks" json:"blocks"`
+	PackingCounts
+}
+
+// AttestationCorrectnessStats counts the wrong source, target and head votes of all attestation assignments of an
+// epoch or day, missed attestations count as wrong votes
+type AttestationCorrectnessStats struct {
+	Epoch        uint64 `db:"epoch" json:"epoch,omitempty"`
+	Day          uint64 `db:"day" json:"day,omitempty"`
+	Assignments  uint64 `db:"assignments" json:"assignments"`
+	Attestations uint64 `db:"attestations" json:"attestations"`
+	WrongSource  uint64 `db:"wrong_source" json:"wrong_source"`
+	WrongTarget  uint64 `db:"wrong_target" json:"wrong_target"`
+	WrongHead    uint64 `db:"wrong_head" json:"wrong_head"`
+}
+
+// WrongHeadRate is the share of the included attestations that voted for a wrong head, missed attestations are
+// excluded so the rate is not skewed by offline validators
+func (s *AttestationCorrectnessStats) WrongHeadRate() float64 {
+	if s.Attestations == 0 {
+		return 0
+	}
+	return float64(s.WrongHead-(s.Assignments-s.Attestations)) / float64(s.Attestations)
+}
+
+// WrongTargetRate is the share of the included attestations that voted for a wrong target
+func (s *AttestationCorrectnessStats) WrongTargetRate() float64 {
+	if s.Attestations == 0 {
+		return 0
+	}
+	return float64(s.WrongTarget-(s.Assignments-s.Attestations)) / float64(s.Attestations)
+}
+
+// ValidatorAttestationCorrectness counts the evaluated attestation assignments of a validator and its wrong votes,
+// missed attestations count as wrong votes
+type ValidatorAttestationCorrectness struct {
+	Validatorindex uint64 `db:"validatorindex" json:"validatorindex"`
+	Epochs         uint64 `db:"epochs" json:"epochs"`
+	Missed         uint64 `db:"missed" json:"missed"`
+	WrongSource    uint64 `db:"wrong_source" json:"wrong_source"`
+	WrongTarget    uint64 `db:"wrong_target" json:"wrong_target"`
+	WrongHead      uint64 `db:"wrong_head" json:"wrong_head"`
+}
+
+// WrongHeadRate is the share of the evaluated epochs the validator voted for a wrong head or missed the attestation
+func (c *ValidatorAttestationCorrectness) WrongHeadRate() float64 {
+	if c.Epochs == 0 {
+		return 0
+	}
+	return float64(c.WrongHead) / float64(c.Epochs)
+}
+
+// WrongTargetRate is the share of the evaluated epochs the validator voted for a wrong target or missed the attestation
+func (c *ValidatorAttestationCorrectness) WrongTargetRate() float64 {
+	if c.Epochs == 0 {
+		return 0
+	}
+	return float64(c.WrongTarget) / float64(c.Epochs)
+}
+
+// WrongSourceRate is the share of the evaluated epochs the validator voted for a wrong source or missed the attestation
+func (c *ValidatorAttestationCorrectness) WrongSourceRate() float64 {
+	if c.Epochs == 0 {
+		return 0
+	}
+	return float64(c.WrongSource) / float64(c.Epochs)
+}
+
+// EffectivenessFormula is a published formula to rate the attestation effectiveness of validators, Expression is a sql
+// aggregate over the attestation assignments of a validator that results in the effectiveness in percent
+type EffectivenessFormula struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Expression  string `json:"-"`
+}
+
+// ValidatorEffectiveness is the effectiveness of a validator according to a single formula
+type ValidatorEffectiveness struct {
+	Formula       *EffectivenessFormula
+	Effectiveness float64
+}
+
+// ValidatorEffectivenessDay is the effectiveness of a validator during a single day
+type ValidatorEffectivenessDay struct {
+	ValidatorIndex uint64  `db:"validatorindex" json:"validatorindex"`
+	Day            uint64  `db:"day" json:"day"`
+	Effectiveness  float64 `db:"effectiveness" json:"effectiveness"`
+}
+
+// CohortAPR is the realized APR of the validators activated in the same month (the cohort) during a single day, amounts
+// are in gwei
+type CohortAPR struct {
+	Day              uint64  `db:"day" json:"day"`
+	Cohort           string  `db:"cohort" json:"cohort"`
+	Validators       uint64  `db:"validators" json:"validators"`
+	EffectiveBalance uint64  `db:"effective_balance" json:"effective_balance"`
+	Income           int64   `db:"income" json:"income"`
+	APR              float64 `db:"apr" json:"apr"`
+}
+
+// ChainSpec holds the chain parameters the explorer runs with
+type ChainSpec struct {
+	Network          string             `json:"network"`
+	ConfigName       string             `json:"config_name"`
+	GenesisTimestamp uint64             `json:"genesis_timestamp"`
+	SlotsPerEpoch    uint64             `json:"slots_per_epoch"`
+	SecondsPerSlot   uint64             `json:"seconds_per_slot"`
+	Presets          []*ChainSpecPreset `json:"presets"`
+}
+
+// ChainSpecPreset holds the parameters of a fork, the fork epoch is nil if it is not configured
+type ChainSpecPreset struct {
+	Name       string                `json:"name"`
+	Path       string                `json:"path"`
+	ForkEpoch  *uint64               `json:"fork_epoch"`
+	ForkTs     *int64                `json:"fork_ts"`
+	Parameters []*ChainSpecParameter `json:"parameters"`
+}
+
+// Fork is a protocol upgrade of the network, the source is "config" or "node" depending on where its epoch is from
+type Fork struct {
+	Name   string `json:"name"`
+	Epoch  uint64 `json:"epoch"`
+	Ts     int64  `json:"ts"`
+	Source string `json:"source"`
+	Active bool   `json:"active"`
+}
+
+// ChartPlotLine is a vertical marker on the time axis of a chart, Value is the timestamp in milliseconds
+type ChartPlotLine struct {
+	Value int64  `json:"value"`
+	Label string `json:"label"`
+}
+
+// ChainSpecParameter is a single parameter of a preset, named like in the preset file
+type ChainSpecParameter struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// NetworkStatsBucket holds the network stats of the epochs of a time bucket of the timescale hypertables, the counts
+// are taken from the last epoch of the bucket
+type NetworkStatsBucket struct {
+	Ts                      time.Time `db:"ts"`
+	ValidatorsCount         uint64    `db:"validatorscount"`
+	EligibleEther           uint64    `db:"eligibleether"`
+	AverageValidatorBalance float64   `db:"averagevalidatorbalance"`
+	GlobalParticipationRate float64   `db:"globalparticipationrate"`
+}
+
+// RocketpoolODAOPageData is the data of the oDAO health page
+type RocketpoolODAOPageData struct {
+	Rounds         uint64
+	Members        []*RocketpoolODAOMemberHealth
+	PricesRounds   []*RocketpoolODAORound
+	BalancesRounds []*RocketpoolODAORound
+}
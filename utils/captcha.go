@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"encoding/json"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// captchaProvider describes a captcha service, all supported services verify the response token of a form with the
+// same siteverify form post
+type captchaProvider struct {
+	scriptURL     string
+	verifyURL     string
+	widgetClass   string
+	responseField string
+	invisible     bool
+}
+
+var captchaProviders = map[string]captchaProvider{
+	"recaptcha": {
+		scriptURL:     "https://www.google.com/recaptcha/api.js",
+		verifyURL:     "https://www.google.com/recaptcha/api/siteverify",
+		widgetClass:   "g-recaptcha",
+		responseField: "g-recaptcha-response",
+		invisible:     true,
+	},
+	"hcaptcha": {
+		scriptURL:     "https://js.hcaptcha.com/1/api.js",
+		verifyURL:     "https://api.hcaptcha.com/siteverify",
+		widgetClass:   "h-captcha",
+		responseField: "h-captcha-response",
+		invisible:     true,
+	},
+	"turnstile": {
+		scriptURL:     "https://challenges.cloudflare.com/turnstile/v0/api.js",
+		verifyURL:     "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+		widgetClass:   "cf-turnstile",
+		responseField: "cf-turnstile-response",
+	},
+}
+
+var captchaClient = &http.Client{Timeout: time.Second * 10}
+
+// captchaSettings returns the name, site key and secret key of the configured captcha provider, the recaptcha keys of
+// the frontend config are used if no provider is set
+func captchaSettings() (name, siteKey, secretKey string) {
+	c := Config.Frontend.Captcha
+	if c.Provider == "" {
+		return "recaptcha", Config.Frontend.RecaptchaSiteKey, Config.Frontend.RecaptchaSecretKey
+	}
+	return c.Provider, c.SiteKey, c.SecretKey
+}
+
+// CaptchaEnabled returns whether the form of the route (e.g. "register" or "advertisewithus") is protected by a captcha
+func CaptchaEnabled(route string) bool {
+	name, siteKey, secretKey := captchaSettings()
+	if _, ok := captchaProviders[name]; !ok || siteKey == "" || secretKey == "" {
+		return false
+	}
+	routes := Config.Frontend.Captcha.Routes
+	if len(routes) == 0 {
+		return true
+	}
+	for _, r := range routes {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCaptchaData returns the template data of the captcha of the form of the route, nil if the form is not protected
+func GetCaptchaData(route string) *types.CaptchaData {
+	if !CaptchaEnabled(route) {
+		return nil
+	}
+	name, siteKey, _ := captchaSettings()
+	provider := captchaProviders[name]
+	return &types.CaptchaData{
+		ScriptURL:   provider.scriptURL,
+		SiteKey:     siteKey,
+		WidgetClass: provider.widgetClass,
+		Invisible:   provider.invisible,
+	}
+}
+
+// ValidateCaptcha validates the captcha response of the submitted form of the route server side, nil is returned if the
+// form is not protected
+func ValidateCaptcha(r *http.Request, route string) error {
+	if !CaptchaEnabled(route) {
+		return nil
+	}
+	name, _, secretKey := captchaSettings()
+	provider := captchaProviders[name]
+
+	token := r.FormValue(provider.responseField)
+	if token == "" {
+		return fmt.Errorf("no %v response present", name)
+	}
+
+	resp, err := captchaClient.PostForm(provider.verifyURL, url.Values{
+		"secret":   {secretKey},
+		"response": {token},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var captchaResponse types.CaptchaResponse
+	err = json.NewDecoder(resp.Body).Decode(&captchaResponse)
+	if err != nil {
+		return fmt.Errorf("error decoding %v response: %w", name, err)
+	}
+	if !captchaResponse.Success {
+		return fmt.Errorf("%v response is invalid: %v", name, captchaResponse.ErrorCodes)
+	}
+
+	threshold := float32(Config.Frontend.Captcha.ScoreThreshold)
+	switch name {
+	case "recaptcha":
+		if threshold == 0 {
+			threshold = 0.5
+		}
+		if captchaResponse.Score < threshold {
+			return fmt.Errorf("recaptcha score %v is below the threshold of %v", captchaResponse.Score, threshold)
+		}
+	case "hcaptcha":
+		if threshold > 0 && captchaResponse.Score > threshold {
+			return fmt.Errorf("hcaptcha risk score %v is above the threshold of %v", captchaResponse.Score, threshold)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"encoding/json"
+	"eth2-exporter/types"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// bootstrapGenesisRetryInterval is how often the beacon node is asked for the genesis of a chain that has not started yet
+const bootstrapGenesisRetryInterval = time.Second * 10
+
+// errBeaconNodeNotFound is returned by GetBeaconNodeData if the resource does not exist (yet)
+var errBeaconNodeNotFound = fmt.Errorf("not found")
+
+// BootstrapNodeEndpoint returns the beacon node api the chain config is bootstrapped from
+func BootstrapNodeEndpoint(cfg *types.Config) string {
+	if cfg.Chain.Bootstrap.NodeEndpoint != "" {
+		return strings.TrimSuffix(cfg.Chain.Bootstrap.NodeEndpoint, "/")
+	}
+	return strings.TrimSuffix(cfg.Frontend.BeaconNodeEndpoint, "/")
+}
+
+// readChainConfigFromNode sets the phase0, altair and electra parameters from the spec of the beacon node and the
+// genesis time from its genesis, it waits for the genesis if the chain has not started yet. Chain settings of the config
+// file are kept, except for the preset parameters which are always taken from the node.
+func readChainConfigFromNode(cfg *types.Config) error {
+	endpoint := BootstrapNodeEndpoint(cfg)
+	if endpoint == "" {
+		return fmt.Errorf("chain.bootstrap is enabled without chain.bootstrap.nodeEndpoint or frontend.beaconNodeEndpoint")
+	}
+
+	spec, err := getNodeSpec(endpoint)
+	if err != nil {
+		return err
+	}
+	err = setSpecFields(&cfg.Chain.Phase0, spec)
+	if err != nil {
+		return err
+	}
+	err = setSpecFields(&cfg.Chain.Altair, spec)
+	if err != nil {
+		return err
+	}
+	err = setSpecFields(&cfg.Chain.Electra, spec)
+	if err != nil {
+		return err
+	}
+
+	genesis := struct {
+		GenesisTime string `json:"genesis_time"`
+	}{}
+	for {
+		err = GetBeaconNodeData(endpoint+"/eth/v1/beacon/genesis", &genesis)
+		if err != errBeaconNodeNotFound {
+			break
+		}
+		logrus.Infof("waiting for the genesis of the chain of beacon node %v", endpoint)
+		time.Sleep(bootstrapGenesisRetryInterval)
+	}
+	if err != nil {
+		return fmt.Errorf("error retrieving genesis of beacon node %v: %w", endpoint, err)
+	}
+	genesisTimestamp, err := strconv.ParseUint(genesis.GenesisTime, 10, 64)
+	if err != nil {
+		return fmt.Errorf("error parsing genesis time %v of beacon node %v: %w", genesis.GenesisTime, endpoint, err)
+	}
+
+	if cfg.Chain.GenesisTimestamp == 0 {
+		cfg.Chain.GenesisTimestamp = genesisTimestamp
+	} else if cfg.Chain.GenesisTimestamp != genesisTimestamp {
+		logrus.Warnf("the configured genesis timestamp %v differs from the genesis %v of beacon node %v", cfg.Chain.GenesisTimestamp, genesisTimestamp, endpoint)
+	}
+	if cfg.Chain.SlotsPerEpoch == 0 {
+		cfg.Chain.SlotsPerEpoch = cfg.Chain.Phase0.SlotsPerEpoch
+	}
+	if cfg.Chain.SecondsPerSlot == 0 {
+		cfg.Chain.SecondsPerSlot = cfg.Chain.Phase0.SecondsPerSlot
+	}
+	if cfg.Chain.MinGenesisActiveValidatorCount == 0 {
+		cfg.Chain.MinGenesisActiveValidatorCount = cfg.Chain.Phase0.MinGenesisActiveValidatorCount
+	}
+	if cfg.Chain.GenesisDelay == 0 {
+		cfg.Chain.GenesisDelay = cfg.Chain.Phase0.GenesisDelay
+	}
+	if cfg.Chain.Network == "" {
+		cfg.Chain.Network = cfg.Chain.Phase0.ConfigName
+	}
+	if epoch := specForkEpoch(spec, "ALTAIR_FORK_EPOCH"); cfg.Chain.AltairForkEpoch == 0 && epoch != nil {
+		cfg.Chain.AltairForkEpoch = *epoch
+	}
+	if cfg.Chain.BellatrixForkEpoch == nil {
+		cfg.Chain.BellatrixForkEpoch = specForkEpoch(spec, "BELLATRIX_FORK_EPOCH")
+	}
+	if cfg.Chain.CapellaForkEpoch == nil {
+		cfg.Chain.CapellaForkEpoch = specForkEpoch(spec, "CAPELLA_FORK_EPOCH")
+	}
+	if cfg.Chain.DenebForkEpoch == nil {
+		cfg.Chain.DenebForkEpoch = specForkEpoch(spec, "DENEB_FORK_EPOCH")
+	}
+	if cfg.Chain.ElectraForkEpoch == nil {
+		cfg.Chain.ElectraForkEpoch = specForkEpoch(spec, "ELECTRA_FORK_EPOCH")
+	}
+
+	logrus.Infof("bootstrapped chain config %v from beacon node %v with genesis %v", cfg.Chain.Phase0.ConfigName, endpoint, time.Unix(int64(genesisTimestamp), 0))
+	return nil
+}
+
+// specForkEpoch returns the epoch of a fork of the spec, nil is returned if the fork is not scheduled (FAR_FUTURE_EPOCH)
+func specForkEpoch(spec map[string]string, name string) *uint64 {
+	epoch, err := strconv.ParseUint(spec[name], 10, 64)
+	if err != nil || epoch == math.MaxUint64 {
+		return nil
+	}
+	return &epoch
+}
+
+// setSpecFields sets the fields of the preset the spec contains a parameter for, the parameters are matched by the yaml
+// tag of the fields
+func setSpecFields(preset interface{}, spec map[string]string) error {
+	v := reflect.ValueOf(preset).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		value, exists := spec[name]
+		if name == "" || !exists {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Uint64:
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("error parsing %v of spec: %w", name, err)
+			}
+			field.SetUint(parsed)
+		case reflect.String:
+			field.SetString(value)
+		}
+	}
+	return nil
+}
+
+// getNodeSpec returns the parameters of the spec of the beacon node
+func getNodeSpec(endpoint string) (map[string]string, error) {
+	// the parameters are strings, newer specs also contain lists (e.g. the blob schedule) which are not needed
+	rawSpec := map[string]interface{}{}
+	err := GetBeaconNodeData(endpoint+"/eth/v1/config/spec", &rawSpec)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving spec of beacon node %v: %w", endpoint, err)
+	}
+	spec := make(map[string]string, len(rawSpec))
+	for name, value := range rawSpec {
+		if str, ok := value.(string); ok {
+			spec[name] = str
+		}
+	}
+	return spec, nil
+}
+
+// GetBeaconNodeData decodes the data of a response of the standard beacon node api at url
+func GetBeaconNodeData(url string, data interface{}) error {
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errBeaconNodeNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error-response: %s", body)
+	}
+
+	parsed := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err = json.Unmarshal(body, &parsed)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(parsed.Data, data)
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateChainConfig checks that all chain parameters the explorer and its template functions depend on are set, the
+// returned error lists every missing parameter and where it is configured
+func ValidateChainConfig(cfg *types.Config) error {
+	missing := []string{}
+	if cfg.Chain.SlotsPerEpoch == 0 {
+		missing = append(missing, "chain.slotsPerEpoch")
+	}
+	if cfg.Chain.SecondsPerSlot == 0 {
+		missing = append(missing, "chain.secondsPerSlot")
+	}
+	if cfg.Chain.GenesisTimestamp == 0 {
+		missing = append(missing, "chain.genesisTimestamp")
+	}
+	if cfg.Chain.MaxEffectiveBalance == 0 {
+		missing = append(missing, fmt.Sprintf("MAX_EFFECTIVE_BALANCE of the phase0 preset (%v)", cfg.Chain.Phase0Path))
+	}
+	if cfg.Chain.SyncCommitteeSize == 0 {
+		missing = append(missing, fmt.Sprintf("SYNC_COMMITTEE_SIZE of the altair preset (%v)", cfg.Chain.AltairPath))
+	}
+	if cfg.Chain.EpochsPerSyncCommitteePeriod == 0 {
+		missing = append(missing, fmt.Sprintf("EPOCHS_PER_SYNC_COMMITTEE_PERIOD of the altair preset (%v)", cfg.Chain.AltairPath))
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("invalid chain configuration, the following parameters are missing or zero: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ChainConfigReady returns true once a chain config with the parameters needed to convert slots, epochs and days to
+// time has been loaded
+func ChainConfigReady() bool {
+	return Config != nil && Config.Chain.SlotsPerEpoch != 0 && Config.Chain.SecondsPerSlot != 0 && Config.Chain.GenesisTimestamp != 0
+}
+
+var chainTemplateFuncWarnings sync.Map
+
+// errChainConfigNotReady logs once per template function that it has been used without a valid chain config and returns
+// the error that aborts the rendering of templates which can not do without the function
+func errChainConfigNotReady(name string) error {
+	if _, warned := chainTemplateFuncWarnings.LoadOrStore(name, true); !warned {
+		logrus.Warnf("template function %v used before a valid chain config has been loaded", name)
+	}
+	return fmt.Errorf("template function %v requires the chain config (slotsPerEpoch, secondsPerSlot, genesisTimestamp)", name)
+}
+
+// chainTemplateFuncs are the template functions that depend on the chain config, formatting functions render a
+// placeholder instead of wrong epochs or timestamps while functions whose result is used further fail the rendering
+func chainTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"formatSlotToTimestamp": func(slot uint64) template.HTML {
+			if !ChainConfigReady() {
+				errChainConfigNotReady("formatSlotToTimestamp")
+				return "-"
+			}
+			return FormatSlotToTimestamp(slot)
+		},
+		"epochOfSlot": func(slot uint64) (uint64, error) {
+			if !ChainConfigReady() {
+				return 0, errChainConfigNotReady("epochOfSlot")
+			}
+			return EpochOfSlot(slot), nil
+		},
+		"dayToTime": func(day int64) (time.Time, error) {
+			if !ChainConfigReady() {
+				return time.Time{}, errChainConfigNotReady("dayToTime")
+			}
+			return DayToTime(day), nil
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// verifyChainConfigWithNode completes the chain config with the spec of the beacon node and returns an error listing
+// every parameter whose local value differs from the node and is not overridden. The local config is kept if the node
+// can not be reached.
+func verifyChainConfigWithNode(cfg *types.Config) error {
+	endpoint := strings.TrimSuffix(cfg.Chain.SpecFromNode.NodeEndpoint, "/")
+	if endpoint == "" {
+		endpoint = strings.TrimSuffix(cfg.Frontend.BeaconNodeEndpoint, "/")
+	}
+	if endpoint == "" {
+		return fmt.Errorf("chain.specFromNode is enabled without chain.specFromNode.nodeEndpoint or frontend.beaconNodeEndpoint")
+	}
+
+	spec, err := getNodeSpec(endpoint)
+	if err != nil {
+		logrus.WithError(err).Errorf("error verifying the chain config with the spec of the beacon node, using the local chain config")
+		return nil
+	}
+
+	overrides := make(map[string]bool, len(cfg.Chain.SpecFromNode.Overrides))
+	for _, name := range cfg.Chain.SpecFromNode.Overrides {
+		overrides[name] = true
+	}
+	mismatches := []string{}
+	mismatch := func(name string, local, node interface{}) {
+		if overrides[name] {
+			logrus.Warnf("the local value %v of %v overrides the value %v of the beacon node", local, name, node)
+			return
+		}
+		mismatches = append(mismatches, fmt.Sprintf("%v (local %v, node %v)", name, local, node))
+	}
+
+	for _, preset := range []interface{}{&cfg.Chain.Phase0, &cfg.Chain.Altair, &cfg.Chain.Electra} {
+		err = verifySpecFields(preset, spec, mismatch)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the deprecated chain settings and the fork epochs of the config file
+	verifyUint := func(name string, local *uint64, node uint64) {
+		if *local == 0 {
+			*local = node
+		} else if *local != node {
+			mismatch(name, *local, node)
+		}
+	}
+	verifyUint("SLOTS_PER_EPOCH", &cfg.Chain.SlotsPerEpoch, cfg.Chain.Phase0.SlotsPerEpoch)
+	verifyUint("SECONDS_PER_SLOT", &cfg.Chain.SecondsPerSlot, cfg.Chain.Phase0.SecondsPerSlot)
+	verifyUint("MIN_GENESIS_ACTIVE_VALIDATOR_COUNT", &cfg.Chain.MinGenesisActiveValidatorCount, cfg.Chain.Phase0.MinGenesisActiveValidatorCount)
+	verifyUint("GENESIS_DELAY", &cfg.Chain.GenesisDelay, cfg.Chain.Phase0.GenesisDelay)
+	if epoch := specForkEpoch(spec, "ALTAIR_FORK_EPOCH"); epoch != nil {
+		verifyUint("ALTAIR_FORK_EPOCH", &cfg.Chain.AltairForkEpoch, *epoch)
+	}
+	forks := map[string]**uint64{
+		"BELLATRIX_FORK_EPOCH": &cfg.Chain.BellatrixForkEpoch,
+		"CAPELLA_FORK_EPOCH":   &cfg.Chain.CapellaForkEpoch,
+		"DENEB_FORK_EPOCH":     &cfg.Chain.DenebForkEpoch,
+		"ELECTRA_FORK_EPOCH":   &cfg.Chain.ElectraForkEpoch,
+	}
+	for name, local := range forks {
+		node := specForkEpoch(spec, name)
+		switch {
+		case *local == nil:
+			*local = node
+		case node == nil:
+			mismatch(name, **local, "not scheduled")
+		case **local != *node:
+			mismatch(name, **local, *node)
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the chain config differs from the spec of beacon node %v, update the config and the preset files or list the parameters in chain.specFromNode.overrides: %v", endpoint, strings.Join(mismatches, ", "))
+	}
+	logrus.Infof("verified the chain config with the spec of beacon node %v", endpoint)
+	return nil
+}
+
+// verifySpecFields compares the fields of the preset with the spec, fields without a local value are set from the spec
+// and differing ones are passed to mismatch, overridden fields keep their local value
+func verifySpecFields(preset interface{}, spec map[string]string, mismatch func(name string, local, node interface{})) error {
+	v := reflect.ValueOf(preset).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		value, exists := spec[name]
+		if name == "" || !exists {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Uint64:
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("error parsing %v of spec: %w", name, err)
+			}
+			if field.Uint() == 0 {
+				field.SetUint(parsed)
+			} else if field.Uint() != parsed {
+				mismatch(name, field.Uint(), parsed)
+			}
+		case reflect.String:
+			if field.String() == "" {
+				field.SetString(value)
+			} else if !strings.EqualFold(field.String(), value) {
+				mismatch(name, field.String(), value)
+			}
+		}
+	}
+	return nil
+}
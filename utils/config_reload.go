@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// configPollInterval is the interval the modification time of the config file is checked at
+const configPollInterval = time.Second * 10
+
+var configReloadHooks = []func(cfg *types.Config){}
+var configReloadMux = &sync.Mutex{}
+
+// secretsRenewed is signalled by the secret renewal when the value of a referenced secret changed, the config watcher
+// then reloads the config to apply the new values
+var secretsRenewed = make(chan struct{}, 1)
+
+// reloadableConfigMux guards the settings of Config that are changed by a reload, code running concurrently to the
+// config watcher reads them through the accessors below. The maps are replaced and never modified by a reload.
+var reloadableConfigMux = &sync.RWMutex{}
+
+// FeatureFlagsConfig returns the configured feature flags
+func FeatureFlagsConfig() map[string]bool {
+	reloadableConfigMux.RLock()
+	defer reloadableConfigMux.RUnlock()
+	return Config.Frontend.FeatureFlags
+}
+
+// MaxMailsPerEmailPerDay returns the configured number of mails an address receives per day, 0 is unlimited
+func MaxMailsPerEmailPerDay() int {
+	reloadableConfigMux.RLock()
+	defer reloadableConfigMux.RUnlock()
+	return Config.Frontend.MaxMailsPerEmailPerDay
+}
+
+// RequestQueryBudgets returns the configured number of db queries and the time spent in them above which a request is
+// logged
+func RequestQueryBudgets() (queries int, timeMs int) {
+	reloadableConfigMux.RLock()
+	defer reloadableConfigMux.RUnlock()
+	return Config.Logging.RequestQueryBudget, Config.Logging.RequestQueryTimeBudgetMs
+}
+
+// ProtocolExportersConfig returns the configs of the protocol exporters by name
+func ProtocolExportersConfig() map[string]types.ProtocolExporterConfig {
+	reloadableConfigMux.RLock()
+	defer reloadableConfigMux.RUnlock()
+	return Config.ProtocolExporters
+}
+
+// SMTPCredentials returns the configured smtp user and password
+func SMTPCredentials() (user, password string) {
+	reloadableConfigMux.RLock()
+	defer reloadableConfigMux.RUnlock()
+	return Config.Frontend.Mail.SMTP.User, Config.Frontend.Mail.SMTP.Password
+}
+
+// SendGridApiKey returns the configured sendgrid api key
+func SendGridApiKey() string {
+	reloadableConfigMux.RLock()
+	defer reloadableConfigMux.RUnlock()
+	return Config.Frontend.Mail.SendGrid.ApiKey
+}
+
+// OnConfigReload registers fn to be called with the running config after a reload applied the reloadable settings
+func OnConfigReload(fn func(cfg *types.Config)) {
+	configReloadMux.Lock()
+	defer configReloadMux.Unlock()
+	configReloadHooks = append(configReloadHooks, fn)
+}
+
+// WatchConfig reloads the config on SIGHUP, whenever the config file is modified and when a renewed secret changed. Only
+// the settings that are safe to change at runtime are applied (log level, query budgets, feature flags, mail rate limit,
+// mail credentials and protocol exporter intervals), all other settings still require a restart.
+func WatchConfig(path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	lastModified := configModTime(path)
+	t := time.NewTicker(configPollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-hup:
+			logrus.Infof("received SIGHUP, reloading config %v", path)
+		case <-t.C:
+			modified := configModTime(path)
+			if !modified.After(lastModified) {
+				continue
+			}
+			lastModified = modified
+			logrus.Infof("config %v changed, reloading", path)
+		case <-secretsRenewed:
+			logrus.Infof("secrets of config %v changed, reloading", path)
+		}
+		err := ReloadConfig(path)
+		if err != nil {
+			logrus.WithError(err).Errorf("error reloading config %v, keeping the running config", path)
+		}
+	}
+}
+
+func configModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// ReloadConfig reads the config from the file and the environment and applies its reloadable settings to the running
+// config, the running config is not changed if the config can not be read
+func ReloadConfig(path string) error {
+	cfg := &types.Config{}
+	err := ReadConfig(cfg, path)
+	if err != nil {
+		return err
+	}
+
+	configReloadMux.Lock()
+	defer configReloadMux.Unlock()
+
+	applyReloadableConfig(Config, cfg)
+	for _, fn := range configReloadHooks {
+		fn(Config)
+	}
+	return nil
+}
+
+// applyReloadableConfig copies the settings that are safe to change at runtime from cfg to running and logs the changes
+func applyReloadableConfig(running, cfg *types.Config) {
+	reloadableConfigMux.Lock()
+	defer reloadableConfigMux.Unlock()
+
+	changed := func(name string, old, new interface{}) bool {
+		if reflect.DeepEqual(old, new) {
+			return false
+		}
+		logrus.WithFields(logrus.Fields{"setting": name, "old": old, "new": new}).Infof("config setting changed")
+		return true
+	}
+
+	if changed("logging.level", running.Logging.Level, cfg.Logging.Level) {
+		running.Logging.Level = cfg.Logging.Level
+	}
+	if changed("logging.slowRequestThresholdMs", running.Logging.SlowRequestThresholdMs, cfg.Logging.SlowRequestThresholdMs) {
+		running.Logging.SlowRequestThresholdMs = cfg.Logging.SlowRequestThresholdMs
+	}
+	if changed("logging.requestQueryBudget", running.Logging.RequestQueryBudget, cfg.Logging.RequestQueryBudget) {
+		running.Logging.RequestQueryBudget = cfg.Logging.RequestQueryBudget
+	}
+	if changed("logging.requestQueryTimeBudgetMs", running.Logging.RequestQueryTimeBudgetMs, cfg.Logging.RequestQueryTimeBudgetMs) {
+		running.Logging.RequestQueryTimeBudgetMs = cfg.Logging.RequestQueryTimeBudgetMs
+	}
+	if changed("frontend.featureFlags", running.Frontend.FeatureFlags, cfg.Frontend.FeatureFlags) {
+		running.Frontend.FeatureFlags = cfg.Frontend.FeatureFlags
+	}
+	if changed("frontend.maxMailsPerEmailPerDay", running.Frontend.MaxMailsPerEmailPerDay, cfg.Frontend.MaxMailsPerEmailPerDay) {
+		running.Frontend.MaxMailsPerEmailPerDay = cfg.Frontend.MaxMailsPerEmailPerDay
+	}
+
+	// credentials are usually secret references that are renewed at runtime, their values are never logged
+	changedSecret := func(name string, old, new string) bool {
+		if old == new {
+			return false
+		}
+		logrus.WithField("setting", name).Infof("config secret changed")
+		return true
+	}
+	if changedSecret("frontend.mail.smtp.user", running.Frontend.Mail.SMTP.User, cfg.Frontend.Mail.SMTP.User) {
+		running.Frontend.Mail.SMTP.User = cfg.Frontend.Mail.SMTP.User
+	}
+	if changedSecret("frontend.mail.smtp.password", running.Frontend.Mail.SMTP.Password, cfg.Frontend.Mail.SMTP.Password) {
+		running.Frontend.Mail.SMTP.Password = cfg.Frontend.Mail.SMTP.Password
+	}
+	if changedSecret("frontend.mail.sendgrid.apiKey", running.Frontend.Mail.SendGrid.ApiKey, cfg.Frontend.Mail.SendGrid.ApiKey) {
+		running.Frontend.Mail.SendGrid.ApiKey = cfg.Frontend.Mail.SendGrid.ApiKey
+	}
+	// the database and redis connections are opened at startup, new credentials are used after a restart
+	if running.Database.Password != cfg.Database.Password || running.Frontend.Database.Password != cfg.Frontend.Database.Password ||
+		running.ValidatorPubkeyCache.RedisEndpoint != cfg.ValidatorPubkeyCache.RedisEndpoint {
+		logrus.Warnf("database or redis credentials changed, they are applied after a restart")
+	}
+
+	// exporters can not be enabled or disabled at runtime, only the intervals of the running ones change. The map is
+	// replaced as it is read concurrently.
+	exporters := make(map[string]types.ProtocolExporterConfig, len(running.ProtocolExporters))
+	for name, e := range running.ProtocolExporters {
+		if c, ok := cfg.ProtocolExporters[name]; ok && changed("protocolExporters."+name+".updateIntervalSeconds", e.UpdateIntervalSeconds, c.UpdateIntervalSeconds) {
+			e.UpdateIntervalSeconds = c.UpdateIntervalSeconds
+		}
+		exporters[name] = e
+	}
+	running.ProtocolExporters = exporters
+}
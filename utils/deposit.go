@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	"github.com/prysmaticlabs/prysm/shared/params"
+)
+
+// depositForkVersions are the genesis fork versions of testnets whose deposits are not signed with the fork version of
+// the prysm beacon config
+var depositForkVersions = map[string][]byte{
+	"zinken":  {0x00, 0x00, 0x00, 0x03},
+	"toledo":  {0x00, 0x70, 0x1E, 0xD0},
+	"pyrmont": {0x00, 0x00, 0x20, 0x09},
+	"prater":  {0x00, 0x00, 0x10, 0x20},
+}
+
+// DepositForkVersion returns the fork version deposits of the configured network are signed with
+func DepositForkVersion() []byte {
+	if version, exists := depositForkVersions[Config.Chain.Network]; exists {
+		return version
+	}
+	return params.BeaconConfig().GenesisForkVersion
+}
+
+// DepositDomain returns the signature domain of deposits of the configured network
+func DepositDomain() ([]byte, error) {
+	cfg := params.BeaconConfig()
+	return helpers.ComputeDomain(cfg.DomainDeposit, DepositForkVersion(), cfg.ZeroHash[:])
+}
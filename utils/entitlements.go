@@ -0,0 +1,84 @@
+package utils
+
+import "eth2-exporter/types"
+
+// standardEntitlements are the entitlements of users without premium package
+var standardEntitlements = types.Entitlements{
+	Package:              "standard",
+	MaxValidators:        100,
+	MaxWatchedValidators: 100,
+	MaxStats:             180,
+	MaxNodes:             1,
+}
+
+// packageEntitlements are the entitlement tiers of the premium packages, unknown packages get the entitlements of the
+// empty package
+var packageEntitlements = map[string]types.Entitlements{
+	"plankton": {
+		MaxValidators:          100,
+		MaxWatchedValidators:   200,
+		MaxStats:               43200,
+		MaxNodes:               1,
+		NotificationThresholds: true,
+		NoAds:                  true,
+	},
+	"goldfish": {
+		MaxValidators:          100,
+		MaxWatchedValidators:   500,
+		MaxStats:               43200,
+		MaxNodes:               2,
+		WidgetSupport:          true,
+		NotificationThresholds: true,
+		NoAds:                  true,
+	},
+	"whale": {
+		MaxValidators:          300,
+		MaxWatchedValidators:   1000,
+		MaxStats:               43200,
+		MaxNodes:               10,
+		WidgetSupport:          true,
+		NotificationThresholds: true,
+		NoAds:                  true,
+	},
+	"": {
+		MaxValidators:          100,
+		MaxWatchedValidators:   200,
+		MaxStats:               43200,
+		MaxNodes:               1,
+		WidgetSupport:          true,
+		NotificationThresholds: true,
+		NoAds:                  true,
+	},
+}
+
+// PackageEntitlements returns the entitlements of the premium package. The package is not validated here, it is taken
+// from the stripe subscriptions and the app store receipts that the app subscription oracle verified.
+func PackageEntitlements(pkg string) types.Entitlements {
+	if pkg == "" || pkg == "standard" {
+		return standardEntitlements
+	}
+
+	e, ok := packageEntitlements[pkg]
+	if !ok {
+		e = packageEntitlements[""]
+	}
+	e.Package = pkg
+	return e
+}
+
+// ApiPlanEntitlements returns the request quotas of the api plan with the stripe price id, users without api plan use
+// the free tier. Only subscriptions that the stripe webhooks marked as active are passed in, the quotas are enforced
+// by handlers.ApiQuotaMiddleware.
+func ApiPlanEntitlements(priceID *string) types.ApiEntitlements {
+	if priceID != nil && *priceID != "" {
+		switch *priceID {
+		case Config.Frontend.Stripe.Sapphire:
+			return types.ApiEntitlements{Plan: "sapphire", DailyQuota: 100000, MonthlyQuota: 500000}
+		case Config.Frontend.Stripe.Emerald:
+			return types.ApiEntitlements{Plan: "emerald", DailyQuota: 200000, MonthlyQuota: 1000000}
+		case Config.Frontend.Stripe.Diamond:
+			return types.ApiEntitlements{Plan: "diamond", DailyQuota: -1, MonthlyQuota: 4000000}
+		}
+	}
+	return types.ApiEntitlements{Plan: "free", DailyQuota: 10000, MonthlyQuota: 30000}
+}
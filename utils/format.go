@@ -5,10 +5,12 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"eth2-exporter/price"
+	"eth2-exporter/types"
 	"fmt"
 	"html"
 	"html/template"
 	"math"
+	"math/big"
 	"net/url"
 	"strconv"
 	"strings"
@@ -82,21 +84,63 @@ func FormatAttestorAssignmentKey(AttesterSlot, CommitteeIndex, MemberIndex uint6
 
 // FormatBalance will return a string for a balance
 func FormatBalance(balanceInt uint64, currency string) template.HTML {
-	exchangeRate := ExchangeRateForCurrency(currency)
-	balance := float64(balanceInt) / float64(1e9)
+	return FormatBalanceWei(types.WeiFromGwei(int64(balanceInt)), currency)
+}
 
-	p := message.NewPrinter(language.English)
-	rb := []rune(p.Sprintf("%.2f", balance*exchangeRate))
-	// remove trailing zeros
-	if rb[len(rb)-2] == '.' || rb[len(rb)-3] == '.' {
-		for rb[len(rb)-1] == '0' {
-			rb = rb[:len(rb)-1]
+// FormatBalanceWei will return a string for an exact balance, unit is wei, Gwei, ETH or a fiat currency
+func FormatBalanceWei(amount types.Wei, unit string) template.HTML {
+	return template.HTML(trimTrailingZeros(FormatAmount(amount, unit, 2)) + " " + unit)
+}
+
+// FormatAmount renders the amount exactly in the unit with the number of decimals (rounded half away from zero) and
+// thousands separators. Unit is wei, Gwei, ETH or a fiat currency, which is converted with the current exchange rate.
+func FormatAmount(amount types.Wei, unit string, decimals int) string {
+	var value *big.Rat
+	switch strings.ToLower(unit) {
+	case "wei":
+		value = amount.Rat(0)
+	case "gwei":
+		value = amount.Rat(9)
+	case "eth":
+		value = amount.Rat(18)
+	default:
+		rate := new(big.Rat).SetFloat64(ExchangeRateForCurrency(unit))
+		if rate == nil {
+			rate = new(big.Rat)
 		}
-		if rb[len(rb)-1] == '.' {
-			rb = rb[:len(rb)-1]
+		value = new(big.Rat).Mul(amount.Rat(18), rate)
+	}
+
+	formatted := value.FloatString(decimals)
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+	integer, fraction := formatted, ""
+	if i := strings.IndexByte(formatted, '.'); i >= 0 {
+		integer, fraction = formatted[:i], formatted[i:]
+	}
+	grouped := []byte{}
+	for i := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped = append(grouped, ',')
 		}
+		grouped = append(grouped, integer[i])
 	}
-	return template.HTML(string(rb) + " " + currency)
+	if sign == "-" && strings.Trim(string(grouped)+fraction, "0.,") == "" {
+		// amounts that round to zero are not negative
+		sign = ""
+	}
+	return sign + string(grouped) + fraction
+}
+
+// trimTrailingZeros removes the trailing zeros of amounts with up to two decimals
+func trimTrailingZeros(s string) string {
+	if len(s) < 3 || (s[len(s)-2] != '.' && s[len(s)-3] != '.') {
+		return s
+	}
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
 }
 
 func FormatBalanceSql(balanceInt sql.NullInt64, currency string) template.HTML {
@@ -309,11 +353,13 @@ func FormatDepositAmount(balanceInt uint64, currency string) template.HTML {
 	return template.HTML(fmt.Sprintf("%.0f %v", balance*exchangeRate, currency))
 }
 
-// FormatEffectiveBalance will return the effective balance formated as string with 1 digit after the comma
+// FormatEffectiveBalance will return the effective balance formated as string with 1 digit after the comma, effective
+// balances above 32 ETH (compounding validators) are formated with thousands separators
 func FormatEffectiveBalance(balanceInt uint64, currency string) template.HTML {
 	exchangeRate := ExchangeRateForCurrency(currency)
 	balance := float64(balanceInt) / float64(1e9)
-	return template.HTML(fmt.Sprintf("%.1f %v", balance*exchangeRate, currency))
+	p := message.NewPrinter(language.English)
+	return template.HTML(p.Sprintf("%.1f %v", balance*exchangeRate, currency))
 }
 
 // FormatEpoch will return the epoch formated as html
@@ -339,6 +385,19 @@ func FormatEth1Address(addr []byte) template.HTML {
 	return template.HTML(fmt.Sprintf("<a href=\"https://etherchain.org/account/0x%x\" class=\"text-monospace\">%s…</a>%s", addr, eth1Addr.Hex()[:8], copyBtn))
 }
 
+// FormatWithdrawalCredentials will return the withdrawal credentials formated as html, the address of execution (0x01) and
+// compounding (0x02) credentials is linked and labeled with the type of the credentials
+func FormatWithdrawalCredentials(credentials []byte) template.HTML {
+	address := WithdrawalAddressOfCredentials(credentials)
+	if address == nil {
+		return template.HTML(fmt.Sprintf(`<span class="badge bg-secondary text-white mr-1" data-toggle="tooltip" title="BLS withdrawal credentials (0x00)">BLS</span>%s`, FormatHash(credentials)))
+	}
+	if HasCompoundingWithdrawalCredentials(credentials) {
+		return template.HTML(fmt.Sprintf(`<span class="badge bg-info text-white mr-1" data-toggle="tooltip" title="Compounding withdrawal credentials (0x02), the effective balance can grow up to %v">Compounding</span>%s`, FormatEffectiveBalance(MaxEffectiveBalanceOfCredentials(credentials), "ETH"), FormatEth1Address(address)))
+	}
+	return template.HTML(fmt.Sprintf(`<span class="badge bg-secondary text-white mr-1" data-toggle="tooltip" title="Execution withdrawal credentials (0x01)">Execution</span>%s`, FormatEth1Address(address)))
+}
+
 // FormatEth1Block will return the eth1-block formated as html
 func FormatEth1Block(block uint64) template.HTML {
 	if !Config.Chain.Mainnet {
@@ -503,35 +562,27 @@ func FormatParticipation(v float64) template.HTML {
 
 // FormatIncome will return a string for a balance
 func FormatIncome(balanceInt int64, currency string) template.HTML {
+	return FormatIncomeWei(types.WeiFromGwei(balanceInt), currency)
+}
 
-	exchangeRate := ExchangeRateForCurrency(currency)
-	balance := float64(balanceInt) / float64(1e9)
-
-	p := message.NewPrinter(language.English)
-
-	decimals := "%.2f"
-
-	if currency == "ETH" {
-		decimals = "%.5f"
-	}
-
-	rb := []rune(p.Sprintf(decimals, balance*exchangeRate))
-	// remove trailing zeros
-	if rb[len(rb)-2] == '.' || rb[len(rb)-3] == '.' {
-		for rb[len(rb)-1] == '0' {
-			rb = rb[:len(rb)-1]
-		}
-		if rb[len(rb)-1] == '.' {
-			rb = rb[:len(rb)-1]
-		}
+// FormatIncomeWei will return a string for an exact income, unit is wei, Gwei, ETH or a fiat currency
+func FormatIncomeWei(amount types.Wei, unit string) template.HTML {
+	var formatted string
+	switch strings.ToLower(unit) {
+	case "wei":
+		formatted = FormatAmount(amount, unit, 0)
+	case "eth":
+		formatted = FormatAmount(amount, unit, 5)
+	default:
+		formatted = trimTrailingZeros(FormatAmount(amount, unit, 2))
 	}
 
-	if balance > 0 {
-		return template.HTML(fmt.Sprintf(`<span class="text-success"><b>+%s %v</b></span>`, string(rb), currency))
-	} else if balance < 0 {
-		return template.HTML(fmt.Sprintf(`<span class="text-danger"><b>%s %v</b></span>`, string(rb), currency))
+	if amount.Sign() > 0 {
+		return template.HTML(fmt.Sprintf(`<span class="text-success"><b>+%s %v</b></span>`, formatted, unit))
+	} else if amount.Sign() < 0 {
+		return template.HTML(fmt.Sprintf(`<span class="text-danger"><b>%s %v</b></span>`, formatted, unit))
 	} else {
-		return template.HTML(fmt.Sprintf(`<b>%s %v</b>`, string(rb), currency))
+		return template.HTML(fmt.Sprintf(`<b>%s %v</b>`, formatted, unit))
 	}
 }
 
@@ -876,12 +927,6 @@ func DerefString(str *string) string {
 	return ""
 }
 
-// TrLang returns translated text based on language tag and text id
-func TrLang(lang string, key string) template.HTML {
-	I18n := getLocaliser()
-	return template.HTML(I18n.Tr(lang, key))
-}
-
 func KFormatterEthPrice(price uint64) string {
 	if price > 999 {
 		ethTruncPrice := fmt.Sprint(float64(int((float64(price)/float64(1000))*10))/float64(10)) + "k"
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"html/template"
+	"math/big"
+	"testing"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   types.Wei
+		unit     string
+		decimals int
+		want     string
+	}{
+		{name: "zero value", amount: types.Wei{}, unit: "ETH", decimals: 2, want: "0.00"},
+		{name: "ether", amount: types.WeiFromGwei(100000000000), unit: "ETH", decimals: 2, want: "100.00"},
+		{name: "unit is case insensitive", amount: types.WeiFromGwei(100000000000), unit: "eth", decimals: 2, want: "100.00"},
+		{name: "gwei with thousands separators", amount: types.WeiFromGwei(32000000000), unit: "Gwei", decimals: 0, want: "32,000,000,000"},
+		{name: "one gwei in ether", amount: types.WeiFromGwei(1), unit: "ETH", decimals: 2, want: "0.00"},
+		{name: "sub-gwei in gwei", amount: types.NewWei(big.NewInt(1)), unit: "Gwei", decimals: 9, want: "0.000000001"},
+		{name: "sub-gwei in wei", amount: types.NewWei(big.NewInt(-1)), unit: "wei", decimals: 0, want: "-1"},
+		{name: "negative sub-gwei rounding to zero is not negative", amount: types.NewWei(big.NewInt(-1)), unit: "Gwei", decimals: 2, want: "0.00"},
+		{name: "rounds half away from zero", amount: types.WeiFromGwei(5000000), unit: "ETH", decimals: 2, want: "0.01"},
+		{name: "negative rounds half away from zero", amount: types.WeiFromGwei(-5000000), unit: "ETH", decimals: 2, want: "-0.01"},
+		{name: "negative with thousands separators", amount: types.WeiFromGwei(-1234567890123), unit: "ETH", decimals: 2, want: "-1,234.57"},
+		{name: "rounding carries into the integer part", amount: types.WeiFromGwei(999999995000000), unit: "ETH", decimals: 2, want: "1,000,000.00"},
+		{name: "large balance in ether", amount: types.WeiFromGwei(120000000123456789), unit: "ETH", decimals: 5, want: "120,000,000.12346"},
+		{name: "large balance in wei", amount: types.WeiFromGwei(120000000123456789), unit: "wei", decimals: 0, want: "120,000,000,123,456,789,000,000,000"},
+		{name: "large negative balance", amount: types.WeiFromGwei(-120000000123456789), unit: "ETH", decimals: 2, want: "-120,000,000.12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatAmount(tt.amount, tt.unit, tt.decimals); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBalanceWei(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount types.Wei
+		unit   string
+		want   template.HTML
+	}{
+		{name: "zero value", amount: types.Wei{}, unit: "ETH", want: "0 ETH"},
+		{name: "trailing zeros are trimmed", amount: types.WeiFromGwei(32000000000), unit: "ETH", want: "32 ETH"},
+		{name: "trailing zero of the decimals is trimmed", amount: types.WeiFromGwei(32500000000), unit: "ETH", want: "32.5 ETH"},
+		{name: "one gwei in ether", amount: types.WeiFromGwei(1), unit: "ETH", want: "0 ETH"},
+		{name: "sub-gwei in gwei", amount: types.NewWei(big.NewInt(1)), unit: "Gwei", want: "0 Gwei"},
+		{name: "fraction of a gwei", amount: types.NewWei(big.NewInt(1500000000)), unit: "Gwei", want: "1.5 Gwei"},
+		{name: "negative sub-gwei", amount: types.NewWei(big.NewInt(-1)), unit: "ETH", want: "0 ETH"},
+		{name: "negative", amount: types.WeiFromGwei(-500000000), unit: "ETH", want: "-0.5 ETH"},
+		{name: "large balance", amount: types.WeiFromGwei(120000000123456789), unit: "ETH", want: "120,000,000.12 ETH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatBalanceWei(tt.amount, tt.unit); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatIncomeWei(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount types.Wei
+		unit   string
+		want   template.HTML
+	}{
+		{name: "zero value", amount: types.Wei{}, unit: "ETH", want: `<b>0.00000 ETH</b>`},
+		{name: "wei", amount: types.NewWei(big.NewInt(1)), unit: "wei", want: `<span class="text-success"><b>+1 wei</b></span>`},
+		{name: "ether", amount: types.WeiFromGwei(10000000), unit: "ETH", want: `<span class="text-success"><b>+0.01000 ETH</b></span>`},
+		{name: "negative", amount: types.WeiFromGwei(-500000000), unit: "ETH", want: `<span class="text-danger"><b>-0.50000 ETH</b></span>`},
+		{name: "negative sub-gwei", amount: types.NewWei(big.NewInt(-1)), unit: "ETH", want: `<span class="text-danger"><b>0.00000 ETH</b></span>`},
+		{name: "sub-gwei in gwei", amount: types.NewWei(big.NewInt(1)), unit: "Gwei", want: `<span class="text-success"><b>+0 Gwei</b></span>`},
+		{name: "fraction of a gwei", amount: types.NewWei(big.NewInt(1500000000)), unit: "Gwei", want: `<span class="text-success"><b>+1.5 Gwei</b></span>`},
+		{name: "large balance", amount: types.WeiFromGwei(120000000123456789), unit: "Gwei", want: `<span class="text-success"><b>+120,000,000,123,456,789 Gwei</b></span>`},
+		{name: "large negative balance", amount: types.WeiFromGwei(-120000000123456789), unit: "ETH", want: `<span class="text-danger"><b>-120,000,000.12346 ETH</b></span>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatIncomeWei(tt.amount, tt.unit); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -49,17 +49,21 @@ func ProcessSecrets(cfg interface{}) error {
 	}
 
 	for _, info := range infos {
-		hasPrefix := strings.HasPrefix(info.Field.String(), "projects/")
-		if !hasPrefix {
+		if info.Field.Kind() != reflect.String && !(info.Field.Kind() == reflect.Ptr && info.Field.Type().Elem().Kind() == reflect.String) {
 			continue
 		}
-		x, err := accessSecretVersion(info.Field.String())
-		if err != nil {
-			logrus.WithError(err).Error("error getting secret")
+		ref := reflect.Indirect(info.Field).String()
+		if !isSecretReference(ref) {
 			continue
 		}
-		if x == nil {
-			continue
+		x, err := resolveSecret(ref)
+		if err != nil {
+			if strings.HasPrefix(ref, legacySecretPrefix) {
+				// plain resource names were resolved on a best effort basis, the setting may not be a reference at all
+				logrus.WithError(err).Error("error getting secret")
+				continue
+			}
+			return fmt.Errorf("error resolving %v secret of setting %v: %w", secretScheme(ref), info.Key, err)
 		}
 
 		field := info.Field
@@ -73,7 +77,7 @@ func ProcessSecrets(cfg interface{}) error {
 			field = field.Elem()
 		}
 
-		field.SetString(*x)
+		field.SetString(x)
 	}
 
 	return err
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CORSPolicyMiddleware applies the cross-origin policy to the routes and answers preflight requests. Requests of
+// origins that are not allowed are still served, the browser refuses to hand the response to the page.
+func CORSPolicyMiddleware(policy types.CORSPolicy) func(http.Handler) http.Handler {
+	wildcard := len(policy.AllowedOrigins) == 0
+	allowed := make(map[string]bool, len(policy.AllowedOrigins))
+	for _, origin := range policy.AllowedOrigins {
+		if origin == "*" {
+			wildcard = true
+		}
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			switch {
+			case origin != "" && allowed[origin] && policy.AllowCredentials:
+				// wildcards are not accepted by browsers for requests with credentials, the request is echoed instead
+				w.Header().Add("Vary", "Origin")
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+				if method := r.Header.Get("Access-Control-Request-Method"); method != "" {
+					w.Header().Set("Access-Control-Allow-Methods", method)
+				}
+			case wildcard:
+				w.Header().Set("Access-Control-Allow-Headers", "*, Authorization")
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.Header().Set("Access-Control-Allow-Methods", "*")
+			case origin != "" && allowed[origin]:
+				w.Header().Add("Vary", "Origin")
+				w.Header().Set("Access-Control-Allow-Headers", "*, Authorization")
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Methods", "*")
+			default:
+				w.Header().Add("Vary", "Origin")
+			}
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SecurityHeadersMiddleware sets the configured security headers on every response, handlers wrapped in AllowEmbedding
+// replace the framing restrictions afterwards
+func SecurityHeadersMiddleware(next http.Handler) http.Handler {
+	cfg := Config.Frontend.SecurityHeaders
+	frameOptions := cfg.FrameOptions
+	if frameOptions == "" {
+		frameOptions = "SAMEORIGIN"
+	}
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Enabled {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			w.Header().Set("X-Frame-Options", frameOptions)
+			if cfg.ContentSecurityPolicy != "" {
+				w.Header().Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+			}
+			if hsts != "" {
+				w.Header().Set("Strict-Transport-Security", hsts)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AllowEmbedding lets the configured embed ancestors frame the responses of the handler, it overrides the framing
+// restrictions of SecurityHeadersMiddleware for widgets and chart images
+func AllowEmbedding(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if Config.Frontend.SecurityHeaders.Enabled {
+			ancestors := "*"
+			if len(Config.Frontend.SecurityHeaders.EmbedAncestors) > 0 {
+				ancestors = strings.Join(Config.Frontend.SecurityHeaders.EmbedAncestors, " ")
+			}
+			w.Header().Del("X-Frame-Options")
+			w.Header().Set("Content-Security-Policy", withFrameAncestors(Config.Frontend.SecurityHeaders.ContentSecurityPolicy, ancestors))
+		}
+		next(w, r)
+	}
+}
+
+// withFrameAncestors replaces the frame-ancestors directive of the content security policy
+func withFrameAncestors(csp, ancestors string) string {
+	directives := []string{}
+	for _, d := range strings.Split(csp, ";") {
+		d = strings.TrimSpace(d)
+		if d == "" || strings.HasPrefix(strings.ToLower(d), "frame-ancestors") {
+			continue
+		}
+		directives = append(directives, d)
+	}
+	return strings.Join(append(directives, "frame-ancestors "+ancestors), "; ")
+}
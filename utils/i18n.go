@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kataras/i18n"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// DefaultLanguage is used if no translation for the requested language exists
+const DefaultLanguage = "en-US"
+
+var localiser *i18n.I18n
+var localiserOnce sync.Once
+var languages []string
+var languageMatcher language.Matcher
+
+// getLocaliser loads all locales from the locales directory, every subdirectory (e.g. locales/en-US) is a language
+func getLocaliser() *i18n.I18n {
+	localiserOnce.Do(func() {
+		dir := Config.Frontend.LocalesDir
+		if dir == "" {
+			dir = "locales"
+		}
+
+		languages = []string{DefaultLanguage}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			logrus.Errorf("error reading locales directory %v: %v", dir, err)
+		}
+		names := []string{}
+		for _, f := range files {
+			if !f.IsDir() || f.Name() == DefaultLanguage {
+				continue
+			}
+			if _, err := language.Parse(f.Name()); err != nil {
+				logrus.Warnf("skipping locale %v: %v", f.Name(), err)
+				continue
+			}
+			names = append(names, f.Name())
+		}
+		sort.Strings(names)
+		languages = append(languages, names...)
+
+		languageTags := make([]language.Tag, 0, len(languages))
+		for _, l := range languages {
+			languageTags = append(languageTags, language.MustParse(l))
+		}
+		languageMatcher = language.NewMatcher(languageTags)
+
+		localiser, err = i18n.New(i18n.Glob(filepath.Join(dir, "*", "*")), languages...)
+		if err != nil {
+			logrus.Errorf("error loading locales from %v: %v", dir, err)
+		}
+	})
+	return localiser
+}
+
+// SupportedLanguages returns the tags of all languages there are locales for, the default language comes first
+func SupportedLanguages() []string {
+	getLocaliser()
+	return languages
+}
+
+// IsSupportedLanguage returns true if there are locales for the language
+func IsSupportedLanguage(lang string) bool {
+	for _, l := range SupportedLanguages() {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateLanguage returns the supported language that matches the Accept-Language header best
+func NegotiateLanguage(acceptLanguage string) string {
+	getLocaliser()
+	_, i := language.MatchStrings(languageMatcher, acceptLanguage)
+	return languages[i]
+}
+
+// LanguageName returns the name of the language in the language itself, e.g. "Deutsch" for de-DE
+func LanguageName(lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang
+	}
+	name := display.Self.Name(tag)
+	if name == "" {
+		return lang
+	}
+	return strings.Title(name)
+}
+
+// tr returns the translation of the key, or an empty string if there is none
+func tr(lang, key string) string {
+	l := getLocaliser()
+	if l == nil {
+		return ""
+	}
+	msg := l.Tr(lang, key)
+	if msg == key {
+		return ""
+	}
+	return msg
+}
+
+// TrLang returns translated text based on language tag and text id
+func TrLang(lang string, key string) template.HTML {
+	return template.HTML(tr(lang, key))
+}
+
+// TrLangPlural returns the plural form of the translated text that matches count, e.g. "key_one" or "key_other" (see CLDR plural rules).
+// Occurrences of %d in the text are replaced with count, if the language has no specific form the "key_other" form is used.
+func TrLangPlural(lang string, key string, count uint64) template.HTML {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.MustParse(DefaultLanguage)
+	}
+
+	form := "other"
+	switch plural.Cardinal.MatchPlural(tag, int(count), 0, 0, 0, 0) {
+	case plural.Zero:
+		form = "zero"
+	case plural.One:
+		form = "one"
+	case plural.Two:
+		form = "two"
+	case plural.Few:
+		form = "few"
+	case plural.Many:
+		form = "many"
+	}
+
+	msg := tr(lang, key+"_"+form)
+	if msg == "" {
+		msg = tr(lang, key+"_other")
+	}
+	return template.HTML(strings.Replace(msg, "%d", fmt.Sprintf("%d", count), -1))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"eth2-exporter/logging"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,7 +13,6 @@ import (
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/context"
-	"github.com/sirupsen/logrus"
 )
 
 const InvalidRequest = "invalid_request"
@@ -33,7 +33,7 @@ const MobileAuthorizedKey = "MobileAuthKey"
 const JsonBodyKey = "JsonBodyKey"
 const JsonBodyNakedKey = "JsonBodyNakedKey"
 
-var logger = logrus.New().WithField("module", "oauth")
+var logger = logging.NewLogger("oauth")
 var signingMethod = jwt.SigningMethodHS256
 
 // CustomClaims Structure of JWT body, contains standard JWT claims and userID as a custom claim
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// personalModeDisabledPaths are the path prefixes of the accounts, notifications and premium features and of the
+// network-wide attestation views (the block tree and the block packing), which are not served in personal mode
+var personalModeDisabledPaths = []string{
+	"/login",
+	"/logout",
+	"/register",
+	"/resend",
+	"/requestReset",
+	"/reset",
+	"/confirm",
+	"/confirmation",
+	"/settings",
+	"/user",
+	"/pricing",
+	"/premium",
+	"/mobile",
+	"/advertisewithus",
+	"/stakingServices",
+	"/dashboard/save",
+	"/api/v1/user",
+	"/api/v1/stripe",
+	"/api/v1/mail",
+	"/api/v1/stats",
+	"/api/v1/client",
+	"/api/v1/slots/tree",
+	"/api/v1/network/packing",
+}
+
+var personalModeWatched map[uint64]bool
+var personalModeWatchedOnce sync.Once
+
+// PersonalModeWatched returns true if the validator is stored by the indexer, which are all validators unless the
+// personal mode is enabled
+func PersonalModeWatched(index uint64) bool {
+	if !Config.Frontend.PersonalMode.Enabled {
+		return true
+	}
+	personalModeWatchedOnce.Do(func() {
+		personalModeWatched = make(map[uint64]bool, len(Config.Frontend.PersonalMode.Validators))
+		for _, v := range Config.Frontend.PersonalMode.Validators {
+			personalModeWatched[v] = true
+		}
+	})
+	return personalModeWatched[index]
+}
+
+// PersonalModeMiddleware answers the requests of the features that are disabled in personal mode with 404
+func PersonalModeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Config.Frontend.PersonalMode.Enabled {
+			for _, prefix := range personalModeDisabledPaths {
+				if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+					http.NotFound(w, r)
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,307 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"eth2-exporter/types"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	vaultSecretPrefix  = "vault://"
+	awsKmsSecretPrefix = "awskms://"
+	gcpSecretPrefix    = "gcpsm://"
+	// legacySecretPrefix are gcp secret manager resource names, they were the only supported references before
+	legacySecretPrefix = "projects/"
+
+	defaultSecretCacheTTL = time.Minute * 5
+)
+
+type secretStoreConfig struct {
+	cacheTTL       time.Duration
+	renewInterval  time.Duration
+	vaultAddress   string
+	vaultToken     string
+	vaultNamespace string
+	awsRegion      string
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+var secretStore = secretStoreConfig{cacheTTL: defaultSecretCacheTTL}
+var secretCache = map[string]*cachedSecret{}
+var secretMux = &sync.Mutex{}
+var secretRenewalOnce = &sync.Once{}
+
+var secretHttpClient = &http.Client{Timeout: time.Second * 30}
+
+// configureSecretStore applies the secrets settings of the config, they are read before the references are resolved
+// and therefore can not be references themselves
+func configureSecretStore(cfg *types.Config) {
+	store := secretStoreConfig{
+		cacheTTL:       time.Second * time.Duration(cfg.Secrets.CacheSeconds),
+		renewInterval:  time.Second * time.Duration(cfg.Secrets.RenewSeconds),
+		vaultAddress:   strings.TrimSuffix(cfg.Secrets.Vault.Address, "/"),
+		vaultToken:     cfg.Secrets.Vault.Token,
+		vaultNamespace: cfg.Secrets.Vault.Namespace,
+		awsRegion:      cfg.Secrets.AwsRegion,
+	}
+	if store.cacheTTL <= 0 {
+		store.cacheTTL = defaultSecretCacheTTL
+	}
+	if store.vaultAddress == "" {
+		store.vaultAddress = strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	}
+	if store.vaultToken == "" {
+		store.vaultToken = os.Getenv("VAULT_TOKEN")
+	}
+
+	secretMux.Lock()
+	secretStore = store
+	secretMux.Unlock()
+}
+
+// isSecretReference returns whether the value of a setting references a secret that has to be resolved
+func isSecretReference(value string) bool {
+	return strings.HasPrefix(value, vaultSecretPrefix) ||
+		strings.HasPrefix(value, awsKmsSecretPrefix) ||
+		strings.HasPrefix(value, gcpSecretPrefix) ||
+		strings.HasPrefix(value, legacySecretPrefix)
+}
+
+// resolveSecret returns the value of the referenced secret, values are cached so config reloads and several settings
+// referencing the same secret do not query the secret store each time
+func resolveSecret(ref string) (string, error) {
+	secretMux.Lock()
+	cached, ok := secretCache[ref]
+	store := secretStore
+	secretMux.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	value, ttl, err := fetchSecret(store, ref)
+	if err != nil {
+		if ok {
+			logrus.WithError(err).WithField("scheme", secretScheme(ref)).Warnf("error refreshing secret, using the expired cached value")
+			return cached.value, nil
+		}
+		return "", err
+	}
+
+	secretMux.Lock()
+	secretCache[ref] = &cachedSecret{value: value, expires: time.Now().Add(ttl)}
+	secretMux.Unlock()
+
+	if store.renewInterval > 0 {
+		secretRenewalOnce.Do(func() {
+			go renewSecrets()
+		})
+	}
+	return value, nil
+}
+
+// fetchSecret queries the secret store of the reference and returns the value and how long it may be cached for
+func fetchSecret(store secretStoreConfig, ref string) (string, time.Duration, error) {
+	switch {
+	case strings.HasPrefix(ref, vaultSecretPrefix):
+		return fetchVaultSecret(store, strings.TrimPrefix(ref, vaultSecretPrefix))
+	case strings.HasPrefix(ref, awsKmsSecretPrefix):
+		value, err := decryptAwsKmsSecret(store, strings.TrimPrefix(ref, awsKmsSecretPrefix))
+		return value, store.cacheTTL, err
+	case strings.HasPrefix(ref, gcpSecretPrefix):
+		value, err := accessSecretVersion(strings.TrimPrefix(ref, gcpSecretPrefix))
+		if err != nil {
+			return "", 0, err
+		}
+		return *value, store.cacheTTL, nil
+	case strings.HasPrefix(ref, legacySecretPrefix):
+		value, err := accessSecretVersion(ref)
+		if err != nil {
+			return "", 0, err
+		}
+		return *value, store.cacheTTL, nil
+	}
+	return "", 0, fmt.Errorf("unsupported secret reference")
+}
+
+type vaultSecretResponse struct {
+	LeaseDuration int                    `json:"lease_duration"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// fetchVaultSecret reads a field of a kv secret, the reference is the path of the secret and the field separated by a
+// "#" (e.g. "secret/data/explorer#dbPassword"), both kv v1 and v2 paths are supported
+func fetchVaultSecret(store secretStoreConfig, ref string) (string, time.Duration, error) {
+	if store.vaultAddress == "" || store.vaultToken == "" {
+		return "", 0, fmt.Errorf("vault address or token not configured")
+	}
+	split := strings.SplitN(ref, "#", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", 0, fmt.Errorf("vault reference must have the format vault://<path>#<field>")
+	}
+	path, field := split[0], split[1]
+
+	res := &vaultSecretResponse{}
+	err := vaultRequest(store, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), res)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading vault secret %v: %w", path, err)
+	}
+
+	data := res.Data
+	// kv v2 wraps the fields of the secret in data.data next to its metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", 0, fmt.Errorf("vault secret %v has no string field %v", path, field)
+	}
+
+	ttl := store.cacheTTL
+	if lease := time.Second * time.Duration(res.LeaseDuration); lease > 0 && lease < ttl {
+		ttl = lease
+	}
+	return value, ttl, nil
+}
+
+func vaultRequest(store secretStoreConfig, method, path string, res interface{}) error {
+	req, err := http.NewRequest(method, store.vaultAddress+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", store.vaultToken)
+	if store.vaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", store.vaultNamespace)
+	}
+
+	resp, err := secretHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// vault error responses only contain error messages, never secret material
+		return fmt.Errorf("vault responded with status %v: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.Unmarshal(body, res)
+}
+
+// decryptAwsKmsSecret decrypts the base64 encoded ciphertext with the kms key it was encrypted with
+func decryptAwsKmsSecret(store secretStoreConfig, ref string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", fmt.Errorf("error decoding aws kms ciphertext: %w", err)
+	}
+
+	awsConfig := &aws.Config{}
+	if store.awsRegion != "" {
+		awsConfig.Region = aws.String(store.awsRegion)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return "", fmt.Errorf("error creating aws session: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*45)
+	defer cancel()
+
+	res, err := kms.New(sess).DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil {
+		return "", fmt.Errorf("error decrypting aws kms ciphertext: %w", err)
+	}
+	return string(res.Plaintext), nil
+}
+
+// renewSecrets refreshes the cached secrets that expire before the next run and renews the vault token so it does not
+// expire while the process is running. If a value changed the config is reloaded, which applies the new values of the
+// reloadable settings (see WatchConfig).
+func renewSecrets() {
+	for {
+		secretMux.Lock()
+		store := secretStore
+		secretMux.Unlock()
+		if store.renewInterval <= 0 {
+			return
+		}
+		time.Sleep(store.renewInterval)
+
+		secretMux.Lock()
+		expiring := []string{}
+		usesVault := false
+		for ref, cached := range secretCache {
+			if strings.HasPrefix(ref, vaultSecretPrefix) {
+				usesVault = true
+			}
+			if time.Now().Add(store.renewInterval).After(cached.expires) {
+				expiring = append(expiring, ref)
+			}
+		}
+		secretMux.Unlock()
+
+		if usesVault {
+			err := vaultRequest(store, http.MethodPost, "/v1/auth/token/renew-self", nil)
+			if err != nil {
+				logrus.WithError(err).Warnf("error renewing vault token")
+			}
+		}
+
+		changed := false
+		for _, ref := range expiring {
+			value, ttl, err := fetchSecret(store, ref)
+			if err != nil {
+				// keep serving the cached value, it is replaced once the secret store is reachable again
+				logrus.WithError(err).WithField("scheme", secretScheme(ref)).Errorf("error renewing secret")
+				continue
+			}
+			secretMux.Lock()
+			if cached, ok := secretCache[ref]; ok && cached.value != value {
+				changed = true
+			}
+			secretCache[ref] = &cachedSecret{value: value, expires: time.Now().Add(ttl)}
+			secretMux.Unlock()
+		}
+
+		if changed {
+			select {
+			case secretsRenewed <- struct{}{}:
+			default:
+				// a reload is already pending
+			}
+		}
+	}
+}
+
+// secretScheme returns the scheme of the reference for logging, the reference itself may contain a ciphertext
+func secretScheme(ref string) string {
+	if i := strings.Index(ref, "://"); i >= 0 {
+		return ref[:i]
+	}
+	return "gcpsm"
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/sessions"
 )
@@ -10,10 +12,51 @@ import (
 // SessionStore is a securecookie-based session-store.
 var SessionStore *sessions.CookieStore
 
-// InitSessionStore initializes SessionStore with the given secret.
+// InitSessionStore initializes SessionStore with the given secret, the cookie options are taken from the config.
 func InitSessionStore(secret string) {
 	SessionStore = sessions.NewCookieStore([]byte(secret))
 	SessionStore.Options.HttpOnly = true
+	SessionStore.Options.Secure = Config.Frontend.Session.Secure
+	SessionStore.Options.SameSite = SessionSameSite()
+	if Config.Frontend.Session.MaxAgeSeconds > 0 {
+		SessionStore.Options.MaxAge = Config.Frontend.Session.MaxAgeSeconds
+	}
+}
+
+// SessionSameSite returns the configured SameSite mode of the session cookies, lax if not configured
+func SessionSameSite() http.SameSite {
+	switch strings.ToLower(Config.Frontend.Session.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// RotateSession starts over the values of the session on privilege changes (login, logout, password and email
+// changes) so nothing set before the change carries over, only the theme and language of the user are kept
+func RotateSession(session *sessions.Session) {
+	values := map[interface{}]interface{}{}
+	for _, key := range []string{"theme", "language"} {
+		if v, ok := session.Values[key]; ok {
+			values[key] = v
+		}
+	}
+	values["issued_ts"] = time.Now().Unix()
+	session.Values = values
+}
+
+// SessionExpired returns true if the session was issued longer than the configured max age ago, the max age of the
+// cookie alone is not enforced for cookies that are replayed
+func SessionExpired(session *sessions.Session) bool {
+	maxAge := Config.Frontend.Session.MaxAgeSeconds
+	if maxAge <= 0 {
+		return false
+	}
+	issued, ok := session.Values["issued_ts"].(int64)
+	return !ok || time.Since(time.Unix(issued, 0)) > time.Duration(maxAge)*time.Second
 }
 
 func SetFlash(w http.ResponseWriter, r *http.Request, name string, value string) {
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"reflect"
+	"strings"
+)
+
+// ChainSpec returns the chain parameters the explorer runs with. The presets are the structs embedded into the chain
+// config (Phase0, Altair, Electra, ...), their parameters are listed by their yaml name. The path of a preset is taken
+// from the <Preset>Path and its fork epoch from the <Preset>ForkEpoch field of the chain config, so presets of future
+// forks are listed without changes here.
+func ChainSpec() *types.ChainSpec {
+	chain := reflect.ValueOf(Config.Chain)
+	spec := &types.ChainSpec{
+		Network:          Config.Chain.Network,
+		ConfigName:       Config.Chain.ConfigName,
+		GenesisTimestamp: Config.Chain.GenesisTimestamp,
+		SlotsPerEpoch:    Config.Chain.SlotsPerEpoch,
+		SecondsPerSlot:   Config.Chain.SecondsPerSlot,
+		Presets:          []*types.ChainSpecPreset{},
+	}
+
+	for i := 0; i < chain.NumField(); i++ {
+		field := chain.Type().Field(i)
+		if !field.Anonymous || field.Type.Kind() != reflect.Struct {
+			continue
+		}
+
+		preset := &types.ChainSpecPreset{
+			Name:       strings.ToLower(field.Name),
+			Parameters: []*types.ChainSpecParameter{},
+		}
+		if path := chain.FieldByName(field.Name + "Path"); path.IsValid() && path.Kind() == reflect.String {
+			preset.Path = path.String()
+		}
+		if field.Name == "Phase0" {
+			genesis := uint64(0)
+			preset.ForkEpoch = &genesis
+		} else if forkEpoch := chain.FieldByName(field.Name + "ForkEpoch"); forkEpoch.IsValid() && forkEpoch.Kind() == reflect.Uint64 {
+			epoch := forkEpoch.Uint()
+			preset.ForkEpoch = &epoch
+		} else if forkEpoch.IsValid() && forkEpoch.Kind() == reflect.Ptr && !forkEpoch.IsNil() && forkEpoch.Elem().Kind() == reflect.Uint64 {
+			epoch := forkEpoch.Elem().Uint()
+			preset.ForkEpoch = &epoch
+		}
+		if preset.ForkEpoch != nil {
+			ts := EpochToTime(*preset.ForkEpoch).Unix()
+			preset.ForkTs = &ts
+		}
+
+		value := chain.Field(i)
+		for j := 0; j < value.NumField(); j++ {
+			name := strings.Split(field.Type.Field(j).Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			preset.Parameters = append(preset.Parameters, &types.ChainSpecParameter{
+				Name:  name,
+				Value: value.Field(j).Interface(),
+			})
+		}
+		spec.Presets = append(spec.Presets, preset)
+	}
+	return spec
+}
@@ -0,0 +1,27 @@
+package utils
+
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+// chart colors with enough contrast to the background of the respective theme
+var chartColors = map[string][]string{
+	ThemeLight: {"#7cb5ec", "#434348", "#90ed7d", "#f7a35c", "#8085e9", "#f15c80", "#e4d354", "#2b908f", "#f45b5b", "#91e8e1"},
+	ThemeDark:  {"#2b908f", "#90ee7e", "#f45b5b", "#7798bf", "#aaeeee", "#ff0066", "#eeaaee", "#55bf3b", "#df5353", "#7798bf"},
+}
+
+// IsValidTheme returns true if the theme is supported by the frontend
+func IsValidTheme(theme string) bool {
+	_, exists := chartColors[theme]
+	return exists
+}
+
+// ChartColors returns the chart color palette of the theme, the light palette is used for unknown themes
+func ChartColors(theme string) []string {
+	colors, exists := chartColors[theme]
+	if !exists {
+		return chartColors[ThemeLight]
+	}
+	return colors
+}
@@ -7,7 +7,6 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
-	"encoding/json"
 	"eth2-exporter/price"
 	"eth2-exporter/types"
 	"fmt"
@@ -17,7 +16,6 @@ import (
 	"math"
 	"math/big"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -31,7 +29,6 @@ import (
 	"golang.org/x/text/message"
 	"gopkg.in/yaml.v2"
 
-	"github.com/kataras/i18n"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
@@ -40,23 +37,9 @@ import (
 // Config is the globally accessible configuration
 var Config *types.Config
 
-var localiser *i18n.I18n
-
-// making sure language files are loaded only once
-func getLocaliser() *i18n.I18n {
-	if localiser == nil {
-		localiser, err := i18n.New(i18n.Glob("locales/*/*"), "en-US", "ru-RU")
-		if err != nil {
-			log.Println(err)
-		}
-		return localiser
-	}
-	return localiser
-}
-
 // GetTemplateFuncs will get the template functions
 func GetTemplateFuncs() template.FuncMap {
-	return template.FuncMap{
+	funcs := template.FuncMap{
 		"includeHTML":                             IncludeHTML,
 		"formatHTML":                              FormatMessageToHtml,
 		"formatBalance":                           FormatBalance,
@@ -65,7 +48,6 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatEffectiveBalance":                  FormatEffectiveBalance,
 		"formatBlockStatus":                       FormatBlockStatus,
 		"formatBlockSlot":                         FormatBlockSlot,
-		"formatSlotToTimestamp":                   FormatSlotToTimestamp,
 		"formatDepositAmount":                     FormatDepositAmount,
 		"formatEpoch":                             FormatEpoch,
 		"formatEth1Block":                         FormatEth1Block,
@@ -77,6 +59,9 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatBitvectorValidators":               formatBitvectorValidators,
 		"formatParticipation":                     FormatParticipation,
 		"formatIncome":                            FormatIncome,
+		"formatIncomeWei":                         FormatIncomeWei,
+		"formatBalanceWei":                        FormatBalanceWei,
+		"formatAmount":                            FormatAmount,
 		"formatMoney":                             FormatMoney,
 		"formatIncomeSql":                         FormatIncomeSql,
 		"formatSqlInt64":                          FormatSqlInt64,
@@ -99,10 +84,9 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatAttestationInclusionEffectiveness": FormatAttestationInclusionEffectiveness,
 		"formatValidatorTags":                     FormatValidatorTags,
 		"formatValidatorTag":                      FormatValidatorTag,
+		"formatWithdrawalCredentials":             FormatWithdrawalCredentials,
 		"formatRPL":                               FormatRPL,
 		"formatFloatWithPrecision":                FormatFloatWithPrecision,
-		"epochOfSlot":                             EpochOfSlot,
-		"dayToTime":                               DayToTime,
 		"contains":                                strings.Contains,
 		"roundDecimals":                           RoundDecimals,
 		"mod":                                     func(i, j int) bool { return i%j == 0 },
@@ -127,6 +111,9 @@ func GetTemplateFuncs() template.FuncMap {
 		},
 		"derefString":      DerefString,
 		"trLang":           TrLang,
+		"trLangPlural":     TrLangPlural,
+		"languages":        SupportedLanguages,
+		"languageName":     LanguageName,
 		"firstCharToUpper": func(s string) string { return strings.Title(s) },
 		"eqsp": func(a, b *string) bool {
 			if a != nil && b != nil {
@@ -137,6 +124,10 @@ func GetTemplateFuncs() template.FuncMap {
 		"stringsJoin":     strings.Join,
 		"formatAddCommas": FormatAddCommas,
 	}
+	for name, f := range chainTemplateFuncs() {
+		funcs[name] = f
+	}
+	return funcs
 }
 
 var LayoutPaths []string = []string{"templates/layout/layout.html", "templates/layout/nav.html"}
@@ -155,6 +146,18 @@ func GraffitiToSring(graffiti []byte) string {
 	return strings.Map(fixUtf, string(bytes.Trim(graffiti, "\x00")))
 }
 
+// ClientFromGraffiti guesses the consensus client of a block proposer by the default graffiti of the clients,
+// it returns "unknown" if the graffiti does not mention a client
+func ClientFromGraffiti(graffiti []byte) string {
+	g := strings.ToLower(GraffitiToSring(graffiti))
+	for _, client := range []string{"prysm", "lighthouse", "teku", "nimbus", "lodestar"} {
+		if strings.Contains(g, client) {
+			return client
+		}
+	}
+	return "unknown"
+}
+
 // FormatGraffitiString formats (and escapes) the graffiti
 func FormatGraffitiString(graffiti string) string {
 	return strings.Map(fixUtf, template.HTMLEscapeString(graffiti))
@@ -182,6 +185,45 @@ func TimeToSyncPeriod(t time.Time) uint64 {
 	return SyncPeriodOfEpoch(uint64(TimeToEpoch(t)))
 }
 
+// Withdrawal credential prefixes, see https://github.com/ethereum/consensus-specs/blob/dev/specs/electra/beacon-chain.md#withdrawal-prefixes
+const (
+	BLSWithdrawalPrefix         byte = 0x00
+	Eth1AddressWithdrawalPrefix byte = 0x01
+	CompoundingWithdrawalPrefix byte = 0x02
+)
+
+// HasCompoundingWithdrawalCredentials returns true if the credentials are compounding (0x02) credentials
+func HasCompoundingWithdrawalCredentials(credentials []byte) bool {
+	return len(credentials) == 32 && credentials[0] == CompoundingWithdrawalPrefix
+}
+
+// WithdrawalAddressOfCredentials returns the eth1 address of execution (0x01) or compounding (0x02) credentials, nil is
+// returned for bls credentials
+func WithdrawalAddressOfCredentials(credentials []byte) []byte {
+	if len(credentials) != 32 || (credentials[0] != Eth1AddressWithdrawalPrefix && credentials[0] != CompoundingWithdrawalPrefix) {
+		return nil
+	}
+	return credentials[12:]
+}
+
+// MaxEffectiveBalanceOfCredentials returns the max effective balance of a validator with the withdrawal credentials,
+// validators with compounding credentials can have an effective balance above 32 ETH since electra
+func MaxEffectiveBalanceOfCredentials(credentials []byte) uint64 {
+	if HasCompoundingWithdrawalCredentials(credentials) {
+		if Config.Chain.MaxEffectiveBalanceElectra == 0 {
+			return 2048e9
+		}
+		return Config.Chain.MaxEffectiveBalanceElectra
+	}
+	if Config.Chain.MinActivationBalance != 0 {
+		return Config.Chain.MinActivationBalance
+	}
+	if Config.Chain.MaxEffectiveBalance != 0 {
+		return Config.Chain.MaxEffectiveBalance
+	}
+	return 32e9
+}
+
 // EpochOfSlot returns the corresponding epoch of a slot
 func EpochOfSlot(slot uint64) uint64 {
 	return slot / Config.Chain.SlotsPerEpoch
@@ -202,6 +244,15 @@ func SlotToTime(slot uint64) time.Time {
 	return time.Unix(int64(Config.Chain.GenesisTimestamp+slot*Config.Chain.SecondsPerSlot), 0)
 }
 
+// LateBlockThreshold returns the delay after the start of a slot a block is considered late if it is first seen after,
+// it defaults to the attestation deadline of a third of a slot
+func LateBlockThreshold() time.Duration {
+	if Config.Indexer.BlockPropagation.LateThresholdMs > 0 {
+		return time.Millisecond * time.Duration(Config.Indexer.BlockPropagation.LateThresholdMs)
+	}
+	return time.Second * time.Duration(Config.Chain.SecondsPerSlot) / 3
+}
+
 // TimeToSlot returns time to slot in seconds
 func TimeToSlot(timestamp uint64) uint64 {
 	if Config.Chain.GenesisTimestamp > timestamp {
@@ -215,6 +266,15 @@ func EpochToTime(epoch uint64) time.Time {
 	return time.Unix(int64(Config.Chain.GenesisTimestamp+epoch*Config.Chain.SecondsPerSlot*Config.Chain.SlotsPerEpoch), 0)
 }
 
+// EpochsPerWeekPartition is the number of epochs of a week partition of the validator_balances_p,
+// attestation_assignments_p and sync_assignments_p tables, a week of mainnet epochs independent of the configured chain
+const EpochsPerWeekPartition = 1575
+
+// EpochsPerDay returns the number of epochs in a day
+func EpochsPerDay() uint64 {
+	return (24 * 60 * 60) / Config.Chain.SlotsPerEpoch / Config.Chain.SecondsPerSlot
+}
+
 // TimeToDay will return a days since genesis for an timestamp
 func TimeToDay(timestamp uint64) uint64 {
 	return uint64(time.Unix(int64(timestamp), 0).Sub(time.Unix(int64(Config.Chain.GenesisTimestamp), 0)).Hours() / 24)
@@ -254,6 +314,10 @@ func ReadConfig(cfg *types.Config, path string) error {
 		return err
 	}
 
+	if cfg.Chain.Bootstrap.Enabled {
+		return readChainConfigFromNode(cfg)
+	}
+
 	// decode phase0 config
 	if len(cfg.Chain.Phase0Path) == 0 {
 		cfg.Chain.Phase0Path = "config/phase0.yml"
@@ -290,6 +354,28 @@ func ReadConfig(cfg *types.Config, path string) error {
 		}
 	}
 
+	// decode electra config
+	if len(cfg.Chain.ElectraPath) == 0 {
+		cfg.Chain.ElectraPath = "config/electra.yml"
+	}
+	electra := &types.Electra{}
+	f, err = os.Open(cfg.Chain.ElectraPath)
+	if err != nil {
+		logrus.Errorf("error opening electra config file %v: %v", cfg.Chain.ElectraPath, err)
+	} else {
+		decoder := yaml.NewDecoder(f)
+		err = decoder.Decode(electra)
+		if err != nil {
+			logrus.Errorf("error decoding electra Config file %v: %v", cfg.Chain.ElectraPath, err)
+		} else {
+			cfg.Chain.Electra = *electra
+		}
+	}
+
+	if cfg.Chain.SpecFromNode.Enabled {
+		return verifyChainConfigWithNode(cfg)
+	}
+
 	return nil
 }
 
@@ -312,7 +398,10 @@ func readConfigEnv(cfg *types.Config) error {
 	return envconfig.Process("", cfg)
 }
 
+// readConfigSecrets replaces the settings referencing a secret (vault://, awskms://, gcpsm:// or a gcp secret manager
+// resource name) with the value of the secret
 func readConfigSecrets(cfg *types.Config) error {
+	configureSecretStore(cfg)
 	return ProcessSecrets(cfg)
 }
 
@@ -325,20 +414,6 @@ func MustParseHex(hexString string) []byte {
 	return data
 }
 
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Headers", "*, Authorization")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "*")
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-		next.ServeHTTP(w, r)
-		return
-	})
-}
-
 func IsApiRequest(r *http.Request) bool {
 	query, ok := r.URL.Query()["format"]
 	return ok && len(query) > 0 && query[0] == "json"
@@ -549,46 +624,16 @@ func Glob(dir string, ext string) ([]string, error) {
 	return files, err
 }
 
-// ValidateReCAPTCHA validates a ReCaptcha server side
-func ValidateReCAPTCHA(recaptchaResponse string) (bool, error) {
-	// Check this URL verification details from Google
-	// https://developers.google.com/recaptcha/docs/verify
-	req, err := http.PostForm("https://www.google.com/recaptcha/api/siteverify", url.Values{
-		"secret":   {Config.Frontend.RecaptchaSecretKey},
-		"response": {recaptchaResponse},
-	})
-	if err != nil { // Handle error from HTTP POST to Google reCAPTCHA verify server
-		return false, err
-	}
-	defer req.Body.Close()
-	body, err := ioutil.ReadAll(req.Body) // Read the response from Google
-	if err != nil {
-		return false, err
-	}
-
-	var googleResponse types.GoogleRecaptchaResponse
-	err = json.Unmarshal(body, &googleResponse) // Parse the JSON response from Google
-	if err != nil {
-		return false, err
-	}
-	if len(googleResponse.ErrorCodes) > 0 {
-		err = fmt.Errorf("Error validating ReCaptcha %v", googleResponse.ErrorCodes)
-	} else {
-		err = nil
-	}
-
-	if googleResponse.Score > 0.5 {
-		return true, err
-	}
-
-	return false, fmt.Errorf("Score too low threshold not reached, Score: %v - Required >0.5; %v", googleResponse.Score, err)
-}
-
 func BitAtVector(b []byte, i int) bool {
 	bb := b[i/8]
 	return (bb & (1 << uint(i%8))) > 0
 }
 
+// SetBitAtVector sets the bit at position i of a bit vector in the order read by BitAtVector
+func SetBitAtVector(b []byte, i int) {
+	b[i/8] |= 1 << uint(i%8)
+}
+
 func BitAtVectorReversed(b []byte, i int) bool {
 	bb := b[i/8]
 	return (bb & (1 << uint(7-(i%8)))) > 0